@@ -14,6 +14,7 @@ import (
 	_ "golang.org/x/image/bmp" // BMP decoder registration
 
 	"github.com/Faultbox/midgard-ro/internal/config"
+	"github.com/Faultbox/midgard-ro/internal/engine/pacing"
 	"github.com/Faultbox/midgard-ro/internal/engine/ui2d"
 	"github.com/Faultbox/midgard-ro/internal/game"
 	"github.com/Faultbox/midgard-ro/internal/game/states"
@@ -109,8 +110,12 @@ func main() {
 		zap.String("renderer", renderer),
 	)
 
-	// Enable VSync
-	_ = sdl.GLSetSwapInterval(1)
+	// VSync, as configured
+	if cfg.Graphics.VSync {
+		_ = sdl.GLSetSwapInterval(1)
+	} else {
+		_ = sdl.GLSetSwapInterval(0)
+	}
 
 	// Set initial viewport using actual drawable size (for HiDPI/Retina displays)
 	drawableW, drawableH := window.GLGetDrawableSize()
@@ -137,6 +142,7 @@ func main() {
 		logger.Error("failed to create ui2d backend", zap.Error(err))
 		os.Exit(1)
 	}
+	ui2dBackend.SetClipboard(sdlClipboard{})
 	g.SetUIBackend(ui2dBackend)
 
 	// Wire GRF asset loader to UI for texture-based skins
@@ -178,6 +184,7 @@ func main() {
 				}
 
 			case *sdl.MouseMotionEvent:
+				g.NoteActivity()
 				input := ui2dBackend.Input()
 				input.MouseX = float32(e.X)
 				input.MouseY = float32(e.Y)
@@ -190,6 +197,7 @@ func main() {
 				lastMouseX = float32(e.X)
 
 			case *sdl.MouseButtonEvent:
+				g.NoteActivity()
 				input := ui2dBackend.Input()
 				pressed := e.State == sdl.PRESSED
 				switch e.Button {
@@ -209,6 +217,7 @@ func main() {
 				}
 
 			case *sdl.MouseWheelEvent:
+				g.NoteActivity()
 				input := ui2dBackend.Input()
 				input.ScrollX = float32(e.X)
 				input.ScrollY = float32(e.Y)
@@ -220,10 +229,20 @@ func main() {
 				input.TextInput += e.GetText()
 
 			case *sdl.KeyboardEvent:
+				g.NoteActivity()
 				handleKeyEvent(e, ui2dBackend.Input(), &running, g)
 			}
 		}
 
+		// Pace the frame: the FPS cap, and throttling in the background
+		flags := window.GetFlags()
+		if !g.PaceFrame(pacing.Window{
+			Focused:   flags&sdl.WINDOW_INPUT_FOCUS != 0,
+			Minimized: flags&sdl.WINDOW_MINIMIZED != 0,
+		}) {
+			continue
+		}
+
 		// Clear screen
 		gl.ClearColor(0.1, 0.1, 0.15, 1.0)
 		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
@@ -252,6 +271,23 @@ func main() {
 	logger.Info("game closed normally")
 }
 
+// sdlClipboard is the system clipboard through SDL.
+type sdlClipboard struct{}
+
+func (sdlClipboard) Text() string {
+	text, err := sdl.GetClipboardText()
+	if err != nil {
+		logger.Debug("clipboard read failed", zap.Error(err))
+	}
+	return text
+}
+
+func (sdlClipboard) SetText(text string) {
+	if err := sdl.SetClipboardText(text); err != nil {
+		logger.Debug("clipboard write failed", zap.Error(err))
+	}
+}
+
 func handleKeyEvent(e *sdl.KeyboardEvent, input *ui2d.InputState, running *bool, g *game.Game) {
 	pressed := e.State == sdl.PRESSED
 	mod := sdl.GetModState()
@@ -281,8 +317,20 @@ func handleKeyEvent(e *sdl.KeyboardEvent, input *ui2d.InputState, running *bool,
 		input.KeyUp = pressed
 	case sdl.K_DOWN:
 		input.KeyDown = pressed
+	case sdl.K_HOME:
+		input.KeyHome = pressed
+	case sdl.K_END:
+		input.KeyEnd = pressed
 
 	// Function keys
+	case sdl.K_F10:
+		if pressed {
+			g.ToggleSettings()
+		}
+	case sdl.K_F11:
+		if pressed {
+			g.ToggleScreenshotGallery()
+		}
 	case sdl.K_F12:
 		if pressed {
 			g.HandleScreenshot()
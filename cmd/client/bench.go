@@ -0,0 +1,32 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/Faultbox/midgard-ro/internal/config"
+	"github.com/Faultbox/midgard-ro/internal/game"
+)
+
+// runBench runs the benchmark and writes its report as JSON to the output
+// file, or stdout.
+func runBench(g *game.Game, opts config.BenchOptions) error {
+	rep, err := g.RunBench(opts)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(rep, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding report: %w", err)
+	}
+	data = append(data, '\n')
+	if opts.Output == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(opts.Output, data, 0644); err != nil {
+		return fmt.Errorf("writing report: %w", err)
+	}
+	return nil
+}
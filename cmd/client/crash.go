@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/Faultbox/midgard-ro/internal/config"
+	"github.com/Faultbox/midgard-ro/internal/crash"
+	"github.com/Faultbox/midgard-ro/internal/game"
+	"github.com/Faultbox/midgard-ro/internal/logger"
+)
+
+// handleCrash writes a crash bundle and tells the user where to find it.
+// g may be nil if the crash happened before the game was created.
+func handleCrash(cfg *config.Config, g *game.Game, reason string, stack []byte) {
+	var gpuInfo string
+	if g != nil {
+		gpuInfo = g.GPUInfo()
+	}
+
+	if logger.Log != nil {
+		logger.Error("client crashed", zap.String("reason", reason), zap.ByteString("stack", stack))
+		logger.Sync()
+	}
+
+	dir, err := crash.NewReport(reason, stack, cfg, gpuInfo).Write(cfg.Logging.CrashDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write crash report: %v\n", err)
+		fmt.Fprintf(os.Stderr, "%s\n%s\n", reason, stack)
+		return
+	}
+
+	msg := fmt.Sprintf("Midgard RO has crashed.\n\n%s\n\nA crash report was saved to:\n%s", reason, dir)
+	fmt.Fprintln(os.Stderr, msg)
+	showCrashDialog("Midgard RO crashed", msg)
+}
+
+// showCrashDialog shows a native message box using the platform's dialog
+// tool. The SDL window may already be gone, so it does not rely on it.
+// Failures are ignored; the message is also printed to stderr.
+func showCrashDialog(title, msg string) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display dialog %q with title %q buttons {\"OK\"} with icon stop", msg, title)
+		cmd = exec.Command("osascript", "-e", script)
+	case "windows":
+		quote := func(s string) string { return "'" + strings.ReplaceAll(s, "'", "''") + "'" }
+		script := "Add-Type -AssemblyName PresentationFramework; [System.Windows.MessageBox]::Show(" +
+			quote(msg) + ", " + quote(title) + ", 'OK', 'Error')"
+		cmd = exec.Command("powershell", "-NoProfile", "-Command", script)
+	default:
+		for _, tool := range [][]string{
+			{"zenity", "--error", "--title", title, "--text", msg},
+			{"kdialog", "--title", title, "--error", msg},
+			{"xmessage", "-center", msg},
+		} {
+			if _, err := exec.LookPath(tool[0]); err == nil {
+				cmd = exec.Command(tool[0], tool[1:]...)
+				break
+			}
+		}
+	}
+	if cmd != nil {
+		_ = cmd.Run()
+	}
+}
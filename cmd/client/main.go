@@ -2,15 +2,19 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	_ "image/jpeg" // JPEG decoder registration
 	_ "image/png"  // PNG decoder registration
 	"os"
+	"runtime/debug"
 
 	"go.uber.org/zap"
 	_ "golang.org/x/image/bmp" // BMP decoder registration
 
 	"github.com/Faultbox/midgard-ro/internal/config"
+	"github.com/Faultbox/midgard-ro/internal/crash"
+	"github.com/Faultbox/midgard-ro/internal/engine/replay"
 	"github.com/Faultbox/midgard-ro/internal/game"
 	"github.com/Faultbox/midgard-ro/internal/logger"
 )
@@ -33,20 +37,68 @@ func main() {
 	}
 	defer logger.Sync()
 
+	// Write a crash bundle for any panic outside the frame loop
+	var g *game.Game
+	defer func() {
+		if r := recover(); r != nil {
+			handleCrash(cfg, g, fmt.Sprintf("panic: %v", r), debug.Stack())
+			os.Exit(2)
+		}
+	}()
+
 	logger.Info("=== Midgard RO Client ===")
-	logger.Sugar.Debugf("Config: %+v", cfg)
+	logger.Sugar.Debugf("Config: %+v", cfg.Redacted())
+
+	// A replay runs in a window of the recorded size, which the UI layout
+	// depends on
+	replayOpts := config.Replay()
+	var player *replay.Player
+	if replayOpts.Play != "" {
+		player, err = replay.Open(replayOpts.Play)
+		if err != nil {
+			logger.Error("failed to open replay", zap.Error(err))
+			os.Exit(1)
+		}
+		h := player.Header()
+		cfg.Graphics.Width, cfg.Graphics.Height = h.Width, h.Height
+		cfg.Graphics.Fullscreen = false
+	}
 
 	// Create and run game
-	g, err := game.New(cfg)
+	g, err = game.New(cfg)
 	if err != nil {
 		logger.Error("failed to create game", zap.Error(err))
 		os.Exit(1)
 	}
 	defer g.Close()
 
+	switch {
+	case player != nil:
+		g.StartReplay(player)
+	case replayOpts.Record != "":
+		if err := g.StartRecording(replayOpts.Record); err != nil {
+			logger.Error("failed to start recording", zap.Error(err))
+			os.Exit(1)
+		}
+	}
+
+	// Benchmark mode renders a map offline and exits
+	if opts := config.Bench(); opts.Map != "" {
+		if err := runBench(g, opts); err != nil {
+			logger.Error("benchmark failed", zap.Error(err))
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Run the game loop
 	if err := g.Run(); err != nil {
-		logger.Error("game error", zap.Error(err))
+		var panicErr *crash.PanicError
+		if errors.As(err, &panicErr) {
+			handleCrash(cfg, g, panicErr.Error(), panicErr.Stack)
+			os.Exit(2)
+		}
+		handleCrash(cfg, g, err.Error(), nil)
 		os.Exit(1)
 	}
 
@@ -13,6 +13,13 @@ var (
 	GATColorUnknown   = color.RGBA{R: 128, G: 128, B: 128, A: 255} // Gray - unknown type
 )
 
+// Sprite debug view colors
+var (
+	SprIndexZeroColor   = color.RGBA{R: 0, G: 0, B: 0, A: 255}     // Black - palette index 0 (transparent)
+	SprNoIndexColor     = color.RGBA{R: 90, G: 90, B: 90, A: 255}  // Gray - true-color pixel, no index
+	SprMagentaHighlight = color.RGBA{R: 0, G: 255, B: 120, A: 255} // Bright green - visible magenta key
+)
+
 // UI background colors
 var (
 	BackgroundColor   = [4]float32{0.1, 0.1, 0.12, 1.0}
@@ -32,3 +39,8 @@ var (
 	DefaultAmbientColor = [3]float32{0.55, 0.50, 0.50}
 	DefaultDiffuseColor = [3]float32{1.00, 1.00, 1.00}
 )
+
+// Keyboard focus colors (ADR-009 Stage 5)
+var (
+	FocusOutlineColor = [4]float32{0.35, 0.65, 1.0, 1.0} // Outline of the focused panel
+)
@@ -45,6 +45,17 @@ type Command struct {
 	Filter map[string]bool `json:"filter,omitempty"`
 }
 
+// glPixelsToImage converts RGBA pixels read back from OpenGL into an image,
+// flipping them vertically (OpenGL has its origin at the bottom-left).
+func glPixelsToImage(pixels []byte, width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		srcIdx := (height - 1 - y) * width * 4
+		copy(img.Pix[y*width*4:(y+1)*width*4], pixels[srcIdx:srcIdx+width*4])
+	}
+	return img
+}
+
 // captureScreenshot captures the current frame to a PNG file.
 func (app *App) captureScreenshot() {
 	// Get actual framebuffer size (handles HiDPI/Retina correctly)
@@ -69,18 +80,7 @@ func (app *App) captureScreenshot() {
 	gl.ReadPixels(0, 0, int32(width), int32(height), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(pixels))
 	gl.ReadBuffer(gl.BACK) // Restore default
 
-	// Create image (flip vertically - OpenGL has origin at bottom-left)
-	img := image.NewRGBA(image.Rect(0, 0, width, height))
-	for y := 0; y < height; y++ {
-		for x := 0; x < width; x++ {
-			srcIdx := ((height-1-y)*width + x) * 4
-			dstIdx := (y*width + x) * 4
-			img.Pix[dstIdx+0] = pixels[srcIdx+0] // R
-			img.Pix[dstIdx+1] = pixels[srcIdx+1] // G
-			img.Pix[dstIdx+2] = pixels[srcIdx+2] // B
-			img.Pix[dstIdx+3] = pixels[srcIdx+3] // A
-		}
-	}
+	img := glPixelsToImage(pixels, width, height)
 
 	// Generate filename with timestamp
 	timestamp := time.Now().Format("20060102-150405")
@@ -270,6 +270,11 @@ func (app *App) executeCommand(cmd Command) {
 		app.dumpState()
 		return // Skip notification, dumpState shows its own
 
+	case "map_report":
+		app.generateMapReport()
+		app.exportMapReport()
+		return // Skip notification, exportMapReport shows its own
+
 	default:
 		app.lastScreenshotMsg = fmt.Sprintf("Unknown command: %s", cmd.Action)
 	}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+	"github.com/sqweek/dialog"
+
+	"github.com/Faultbox/midgard-ro/pkg/grf"
+)
+
+// dataDirPollInterval is how often the overlaid data folder is checked for
+// edited files. A check stats the folder's directories; every file is
+// only rescanned now and then (see grf.Dir.Poll).
+const dataDirPollInterval = time.Second
+
+// openDataDirDialog picks a loose data folder to overlay on the archive,
+// without blocking the UI; render opens it on the main thread.
+func (app *App) openDataDirDialog() {
+	go func() {
+		dir, err := dialog.Directory().Title("Overlay Data Folder").Browse()
+		if err != nil {
+			if err != dialog.ErrCancelled {
+				fmt.Fprintf(os.Stderr, "Folder dialog error: %v\n", err)
+			}
+			return
+		}
+		app.pendingDataDir = dir
+	}()
+}
+
+// setDataDir overlays a loose data folder (holding data/...) on the
+// archive: its files are read instead of the archive's, and edits to them
+// refresh the preview. An empty path removes the overlay.
+func (app *App) setDataDir(path string) error {
+	app.dataDir = nil
+	if path == "" {
+		app.reloadPreview()
+		return nil
+	}
+	dir, err := grf.OpenDir(path)
+	if err != nil {
+		return err
+	}
+	app.dataDir = dir
+	app.lastDataDirPoll = time.Now()
+	app.reloadPreview()
+	app.showNotification(fmt.Sprintf("Overlaying %d files from %s", len(dir.List()), filepath.Base(path)))
+	return nil
+}
+
+// pollDataDir reloads the preview when the overlaid data folder's copy of
+// the previewed file, or of a file it's shown with, was edited.
+func (app *App) pollDataDir() {
+	if app.pendingDataDir != "" {
+		path := app.pendingDataDir
+		app.pendingDataDir = ""
+		if err := app.setDataDir(path); err != nil {
+			app.showNotification(fmt.Sprintf("Failed to open data folder: %v", err))
+		}
+	}
+	if app.dataDir == nil || time.Since(app.lastDataDirPoll) < dataDirPollInterval {
+		return
+	}
+	app.lastDataDirPoll = time.Now()
+
+	changed, err := app.dataDir.Poll()
+	if err != nil || len(changed) == 0 {
+		return
+	}
+	if app.previewShows(changed) {
+		app.reloadPreview()
+		app.showNotification(fmt.Sprintf("Reloaded %d edited files", len(changed)))
+	}
+}
+
+// previewShows reports whether the preview shows any of the named files:
+// the previewed file or its pair, like a sprite's ACT for its SPR.
+func (app *App) previewShows(names []string) bool {
+	if app.previewPath == "" {
+		return false
+	}
+	path := app.selectedOriginalPath
+	if path == "" {
+		path = app.previewPath
+	}
+	stem := strings.TrimSuffix(grf.NormalizePath(path), filepath.Ext(path))
+	for _, name := range names {
+		if strings.TrimSuffix(name, filepath.Ext(name)) == stem {
+			return true
+		}
+	}
+	return false
+}
+
+// reloadPreview loads the selected file's preview again.
+func (app *App) reloadPreview() {
+	if app.selectedPath != "" && app.previewPath == app.selectedPath {
+		app.loadPreview(app.selectedPath)
+	}
+}
+
+// renderDataDirMenu renders the Tools menu items of the data folder
+// overlay.
+func (app *App) renderDataDirMenu() {
+	if imgui.MenuItemBool("Overlay Data Folder...") {
+		app.openDataDirDialog()
+	}
+	if app.dataDir != nil && imgui.MenuItemBool("Remove Data Folder ("+filepath.Base(app.dataDir.Root())+")") {
+		app.setDataDir("")
+	}
+}
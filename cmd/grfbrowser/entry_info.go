@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+
+	"github.com/Faultbox/midgard-ro/pkg/grf"
+)
+
+// entryInfo is the archive table entry of the previewed file, with
+// checksums of its bytes as stored and as unpacked.
+type entryInfo struct {
+	path    string // Archive path (original encoding)
+	entry   grf.Entry
+	rawCRC  uint32
+	dataCRC uint32
+	err     error // Why the stored bytes couldn't be read or unpacked
+}
+
+// loadEntryInfo reads the previewed file's table entry and checksums it.
+// Unpacking is done here rather than through Archive.Read so a broken
+// entry reports what's wrong with it.
+func (app *App) loadEntryInfo(archivePath string) {
+	app.entryInfo = nil
+	entry, ok := app.archive.Stat(archivePath)
+	if !ok {
+		return
+	}
+	info := &entryInfo{path: archivePath, entry: entry}
+	app.entryInfo = info
+
+	raw, err := app.archive.ReadRaw(archivePath)
+	if err != nil {
+		info.err = err
+		return
+	}
+	info.rawCRC = crc32.ChecksumIEEE(raw)
+	switch {
+	case entry.Encryption() != "none":
+		info.err = fmt.Errorf("DES-encrypted (%s)", entry.Encryption())
+	case int(entry.CompressedSize) > len(raw):
+		info.err = fmt.Errorf("compressed size %d past the stored %d bytes", entry.CompressedSize, len(raw))
+	case !entry.Compressed():
+		info.dataCRC = crc32.ChecksumIEEE(raw[:entry.UncompressedSize])
+	default:
+		r, err := zlib.NewReader(bytes.NewReader(raw[:entry.CompressedSize]))
+		if err != nil {
+			info.err = fmt.Errorf("zlib: %w", err)
+			return
+		}
+		defer r.Close()
+		h := crc32.NewIEEE()
+		n, err := io.Copy(h, r)
+		switch {
+		case err != nil:
+			info.err = fmt.Errorf("zlib: %w after %d bytes", err, n)
+		case n != int64(entry.UncompressedSize):
+			info.err = fmt.Errorf("unpacked to %d bytes, table says %d", n, entry.UncompressedSize)
+		default:
+			info.dataCRC = h.Sum32()
+		}
+	}
+}
+
+// renderEntryInfo renders the previewed file's archive entry details.
+func (app *App) renderEntryInfo() {
+	info := app.entryInfo
+	if info == nil || !imgui.TreeNodeExStrV("Archive Entry Info", 0) {
+		return
+	}
+	e := info.entry
+
+	imgui.Text(fmt.Sprintf("Real size: %d bytes", e.UncompressedSize))
+	imgui.Text(fmt.Sprintf("Stored size: %d bytes (%d aligned, %d padding)",
+		e.CompressedSize, e.AlignedSize, int64(e.AlignedSize)-int64(e.CompressedSize)))
+	if e.Compressed() && e.UncompressedSize > 0 {
+		imgui.Text(fmt.Sprintf("Compression: zlib, %.1f%% of real size",
+			100*float64(e.CompressedSize)/float64(e.UncompressedSize)))
+	} else {
+		imgui.Text("Compression: none (stored)")
+	}
+	imgui.Text(fmt.Sprintf("Flags: 0x%02X", e.Flags))
+	imgui.Text("Encryption: " + e.Encryption())
+	imgui.Text(fmt.Sprintf("Offset: 0x%08X in table, 0x%08X in file", e.Offset, e.DataOffset()))
+	imgui.Text(fmt.Sprintf("Raw CRC32: %08X", info.rawCRC))
+	if info.err != nil {
+		imgui.TextColored(reportSeverityColor(ReportSeverityWarning), "Data: "+info.err.Error())
+	} else {
+		imgui.Text(fmt.Sprintf("Data CRC32: %08X", info.dataCRC))
+	}
+
+	if imgui.Button("Export Raw Entry") {
+		app.exportRawEntry()
+	}
+	imgui.TreePop()
+}
+
+// exportRawEntry writes the previewed file's stored bytes, as they are in
+// the archive, to <name>.raw in the screenshot directory.
+func (app *App) exportRawEntry() {
+	if app.entryInfo == nil {
+		return
+	}
+	raw, err := app.archive.ReadRaw(app.entryInfo.path)
+	if err != nil {
+		app.showNotification(fmt.Sprintf("Raw export failed: %v", err))
+		return
+	}
+	path := filepath.Join(app.screenshotDir, filepath.Base(filepath.FromSlash(app.selectedPath))+".raw")
+	if err := os.WriteFile(path, raw, 0644); err != nil {
+		app.showNotification(fmt.Sprintf("Raw export failed: %v", err))
+		return
+	}
+	app.showNotification("Raw entry saved: " + filepath.Base(path))
+	fmt.Printf("Raw entry saved: %s\n", path)
+}
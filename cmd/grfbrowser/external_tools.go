@@ -0,0 +1,348 @@
+// External tool hooks for GRF Browser: open the selected file in a
+// user-defined program and re-import it when the program saves.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+)
+
+// externalToolsVersion is bumped when the tools file format changes
+// incompatibly.
+const externalToolsVersion = 1
+
+// watchPollInterval is how often extracted files are checked for edits.
+const watchPollInterval = 500 * time.Millisecond
+
+// ExternalTool is a user-defined program that opens files of some types,
+// e.g. "Open with GIMP" for .bmp and .tga.
+type ExternalTool struct {
+	Name       string `json:"name"`
+	Extensions string `json:"extensions"` // Comma-separated, e.g. ".bmp, .tga"; empty matches all
+	Command    string `json:"command"`    // Program path or name on PATH
+	Args       string `json:"args"`       // Arguments with placeholders, see expandToolArgs
+	Watch      bool   `json:"watch"`      // Re-import the file when the tool saves it
+}
+
+// externalToolsFile is the on-disk tools configuration.
+type externalToolsFile struct {
+	Version int            `json:"version"`
+	Tools   []ExternalTool `json:"tools"`
+}
+
+// watchedFile is an extracted file being watched for edits.
+type watchedFile struct {
+	tempPath    string // Extracted copy the tool edits
+	archivePath string // Original archive path
+	displayPath string
+	modTime     time.Time
+}
+
+// Matches reports whether the tool handles files with the given path.
+func (t ExternalTool) Matches(path string) bool {
+	if strings.TrimSpace(t.Extensions) == "" {
+		return true
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range strings.Split(t.Extensions, ",") {
+		e = strings.ToLower(strings.TrimSpace(e))
+		if e != "" && !strings.HasPrefix(e, ".") {
+			e = "." + e
+		}
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// expandToolArgs splits args into arguments, honouring double quotes, and
+// substitutes placeholders:
+//
+//	{file} extracted file path    {dir}  its directory
+//	{name} file name              {grf}  path inside the archive
+//
+// The file path is appended when args has no {file}, so an empty args
+// string opens the file.
+func expandToolArgs(args, file, grfPath string) []string {
+	replacer := strings.NewReplacer(
+		"{file}", file,
+		"{dir}", filepath.Dir(file),
+		"{name}", filepath.Base(file),
+		"{grf}", grfPath,
+	)
+	parts := splitArgs(args)
+	out := make([]string, 0, len(parts)+1)
+	for _, p := range parts {
+		out = append(out, replacer.Replace(p))
+	}
+	if !strings.Contains(args, "{file}") {
+		out = append(out, file)
+	}
+	return out
+}
+
+// splitArgs splits a command line on spaces outside double quotes.
+func splitArgs(s string) []string {
+	var args []string
+	var cur strings.Builder
+	inQuotes, hasArg := false, false
+	for _, r := range s {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			hasArg = true
+		case (r == ' ' || r == '\t') && !inQuotes:
+			if hasArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				hasArg = false
+			}
+		default:
+			cur.WriteRune(r)
+			hasArg = true
+		}
+	}
+	if hasArg {
+		args = append(args, cur.String())
+	}
+	return args
+}
+
+// defaultExternalToolsPath returns where the tools configuration is stored.
+func defaultExternalToolsPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "midgard-ro", "grfbrowser_tools.json")
+}
+
+// loadExternalTools reads the tools configuration. A missing file is an
+// empty configuration.
+func loadExternalTools(path string) ([]ExternalTool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f externalToolsFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse external tools: %w", err)
+	}
+	if f.Version != externalToolsVersion {
+		return nil, fmt.Errorf("unsupported external tools version %d", f.Version)
+	}
+	return f.Tools, nil
+}
+
+// saveExternalTools writes the tools configuration.
+func saveExternalTools(path string, tools []ExternalTool) error {
+	data, err := json.MarshalIndent(externalToolsFile{Version: externalToolsVersion, Tools: tools}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal external tools: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create config dir: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("write external tools: %w", err)
+	}
+	return nil
+}
+
+// readFile reads a file from the archive, preferring a copy re-imported
+// from an external tool, then the overlaid data folder's.
+func (app *App) readFile(path string) ([]byte, error) {
+	if data, ok := app.fileOverrides[path]; ok {
+		return data, nil
+	}
+	if app.dataDir != nil && app.dataDir.Contains(path) {
+		if data, err := app.dataDir.Read(path); err == nil {
+			return data, nil
+		}
+	}
+	return app.archive.Read(path)
+}
+
+// toolsFor returns the configured tools that handle path.
+func (app *App) toolsFor(path string) []ExternalTool {
+	var tools []ExternalTool
+	for _, t := range app.externalTools {
+		if t.Command != "" && t.Matches(path) {
+			tools = append(tools, t)
+		}
+	}
+	return tools
+}
+
+// openWithTool extracts the selected file to a temp directory and launches
+// tool on it.
+func (app *App) openWithTool(tool ExternalTool) error {
+	if app.archive == nil || app.selectedPath == "" {
+		return errors.New("no file selected")
+	}
+	archivePath := app.selectedOriginalPath
+	if archivePath == "" {
+		archivePath = app.selectedPath
+	}
+
+	data, err := app.readFile(archivePath)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", app.selectedPath, err)
+	}
+	dir, err := os.MkdirTemp("", "grfbrowser-")
+	if err != nil {
+		return fmt.Errorf("create temp dir: %w", err)
+	}
+	app.toolTempDirs = append(app.toolTempDirs, dir)
+	file := filepath.Join(dir, filepath.Base(filepath.FromSlash(strings.ReplaceAll(app.selectedPath, "\\", "/"))))
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		return fmt.Errorf("extract file: %w", err)
+	}
+
+	cmd := exec.Command(tool.Command, expandToolArgs(tool.Args, file, app.selectedPath)...)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start %s: %w", tool.Name, err)
+	}
+	go func() { _ = cmd.Wait() }() // Reap the process when the tool exits
+
+	if tool.Watch {
+		info, err := os.Stat(file)
+		if err != nil {
+			return fmt.Errorf("stat extracted file: %w", err)
+		}
+		app.watchedFiles = append(app.watchedFiles, watchedFile{
+			tempPath:    file,
+			archivePath: archivePath,
+			displayPath: app.selectedPath,
+			modTime:     info.ModTime(),
+		})
+	}
+	return nil
+}
+
+// pollWatchedFiles re-imports extracted files an external tool has saved,
+// refreshing the preview if the file is selected.
+func (app *App) pollWatchedFiles() {
+	if len(app.watchedFiles) == 0 || time.Since(app.lastWatchPoll) < watchPollInterval {
+		return
+	}
+	app.lastWatchPoll = time.Now()
+
+	for i := range app.watchedFiles {
+		w := &app.watchedFiles[i]
+		info, err := os.Stat(w.tempPath)
+		if err != nil || !info.ModTime().After(w.modTime) {
+			continue
+		}
+		data, err := os.ReadFile(w.tempPath)
+		if err != nil {
+			continue // Possibly mid-write; retry on the next poll
+		}
+		w.modTime = info.ModTime()
+		app.fileOverrides[w.archivePath] = data
+		app.showNotification("Re-imported " + filepath.Base(w.tempPath))
+		if app.selectedPath == w.displayPath {
+			app.loadPreview(app.selectedPath)
+		}
+	}
+}
+
+// resetExternalEdits drops re-imported files and stops watching, e.g. when
+// another archive is opened.
+func (app *App) resetExternalEdits() {
+	clear(app.fileOverrides)
+	app.watchedFiles = nil
+}
+
+// cleanupToolTempDirs removes the directories files were extracted to.
+func (app *App) cleanupToolTempDirs() {
+	for _, dir := range app.toolTempDirs {
+		os.RemoveAll(dir)
+	}
+	app.toolTempDirs = nil
+}
+
+// renderOpenWithMenu lists the tools for the selected file as menu items.
+func (app *App) renderOpenWithMenu() {
+	var tools []ExternalTool
+	if app.selectedOriginalPath != "" { // Directories have no archive path
+		tools = app.toolsFor(app.selectedPath)
+	}
+	if len(tools) == 0 {
+		imgui.MenuItemBoolV("No tools for this file", "", false, false)
+	}
+	for _, tool := range tools {
+		if imgui.MenuItemBool("Open with " + tool.Name) {
+			if err := app.openWithTool(tool); err != nil {
+				app.showNotification(fmt.Sprintf("Open with %s failed: %v", tool.Name, err))
+			}
+		}
+	}
+	imgui.Separator()
+	if imgui.MenuItemBool("External Tools...") {
+		app.showToolsSettings = true
+	}
+}
+
+// renderToolsSettings draws the external tools editor.
+func (app *App) renderToolsSettings() {
+	if !app.showToolsSettings {
+		return
+	}
+	imgui.SetNextWindowSizeV(imgui.NewVec2(520, 420), imgui.CondFirstUseEver)
+	if imgui.BeginV("External Tools", &app.showToolsSettings, 0) {
+		imgui.TextWrapped("Placeholders in arguments: {file} extracted file, {dir} its folder, " +
+			"{name} file name, {grf} path in the archive. Without {file} the file is appended.")
+		imgui.Separator()
+
+		remove := -1
+		for i := range app.externalTools {
+			tool := &app.externalTools[i]
+			imgui.PushIDInt(int32(i))
+			label := tool.Name
+			if label == "" {
+				label = "(unnamed)"
+			}
+			if imgui.CollapsingHeaderTreeNodeFlags(label + "###tool") {
+				imgui.InputTextWithHint("Name", "Open with GIMP", &tool.Name, 0, nil)
+				imgui.InputTextWithHint("Extensions", ".bmp, .tga (empty = all)", &tool.Extensions, 0, nil)
+				imgui.InputTextWithHint("Command", "/usr/bin/gimp", &tool.Command, 0, nil)
+				imgui.InputTextWithHint("Arguments", "{file}", &tool.Args, 0, nil)
+				imgui.Checkbox("Re-import when saved", &tool.Watch)
+				if imgui.Button("Remove") {
+					remove = i
+				}
+			}
+			imgui.PopID()
+		}
+		if remove >= 0 {
+			app.externalTools = append(app.externalTools[:remove], app.externalTools[remove+1:]...)
+		}
+
+		imgui.Separator()
+		if imgui.Button("Add Tool") {
+			app.externalTools = append(app.externalTools, ExternalTool{Name: "New Tool", Args: "{file}"})
+		}
+		imgui.SameLine()
+		if imgui.Button("Save") {
+			if err := saveExternalTools(defaultExternalToolsPath(), app.externalTools); err != nil {
+				app.showNotification(fmt.Sprintf("Saving tools failed: %v", err))
+			} else {
+				app.showNotification("External tools saved")
+			}
+		}
+	}
+	imgui.End()
+}
@@ -189,8 +189,14 @@ func (app *App) renderFileTree() {
 		return
 	}
 
-	// File tree in child window for scrolling
+	// File tree in child window for scrolling. Taking the focus by key
+	// brings the selected file into view.
+	if app.takeFocus(panelTree) {
+		imgui.SetNextWindowFocus()
+		app.scrollToPath = app.selectedPath
+	}
 	if imgui.BeginChildStrV("FileTreeChild", imgui.NewVec2(0, 0), imgui.ChildFlagsBorders, imgui.WindowFlagsHorizontalScrollbar) {
+		app.trackFocus(panelTree, imgui.FocusedFlagsNone)
 		if app.fileTree != nil {
 			app.renderTreeNode(app.fileTree)
 		}
@@ -263,6 +269,27 @@ func (app *App) renderTreeNode(node *FileNode) {
 				app.selectedOriginalPath = child.OriginalPath
 			}
 
+			// Enter goes on to the preview; Shift+F10 or the Menu key open
+			// the context menu as a right-click does
+			if imgui.IsItemFocused() {
+				if itemEntered(0) {
+					app.focusPanel = panelPreview
+					app.focusPending = true
+				}
+				shiftF10 := imgui.KeyChord(imgui.ModShift) | imgui.KeyChord(imgui.KeyF10)
+				if imgui.IsKeyChordPressed(shiftF10) || imgui.IsKeyChordPressed(imgui.KeyChord(imgui.KeyMenu)) {
+					imgui.OpenPopupStr("##fileContext")
+				}
+			}
+
+			// Right-click: select and offer the external tools
+			if imgui.BeginPopupContextItemV("##fileContext", imgui.PopupFlagsMouseButtonRight) {
+				app.selectedPath = child.Path
+				app.selectedOriginalPath = child.OriginalPath
+				app.renderOpenWithMenu()
+				imgui.EndPopup()
+			}
+
 			// Scroll to this item if it matches scrollToPath
 			if app.scrollToPath != "" && child.Path == app.scrollToPath {
 				imgui.SetScrollHereY() // Scroll to center in view
@@ -0,0 +1,215 @@
+// Keyboard focus for GRF Browser (ADR-009 Stage 5): TAB moves between the
+// panels, the focused panel is outlined, and F1 lists the shortcuts.
+package main
+
+import "github.com/AllenDang/cimgui-go/imgui"
+
+// focusPanel is a panel TAB moves the keyboard focus to.
+type focusPanel int
+
+// The panels in TAB order.
+const (
+	panelSearch     focusPanel = iota // Search box and type filters
+	panelTree                         // File tree
+	panelPreview                      // Preview of the selected file
+	panelActions                      // ACT actions or 3D map controls
+	panelProperties                   // Properties of the selected map model
+	panelCount
+)
+
+// String names the panel, as the status bar shows it.
+func (p focusPanel) String() string {
+	switch p {
+	case panelSearch:
+		return "Search"
+	case panelTree:
+		return "Tree"
+	case panelPreview:
+		return "Preview"
+	case panelActions:
+		return "Actions"
+	case panelProperties:
+		return "Properties"
+	}
+	return "?"
+}
+
+// nextPanel returns the shown panel dir (1 or -1) steps from p in TAB
+// order, wrapping around. Search, Tree and Preview are always shown.
+func nextPanel(p focusPanel, dir int, shown [panelCount]bool) focusPanel {
+	n := int(panelCount)
+	for range n {
+		p = focusPanel(((int(p)+dir)%n + n) % n)
+		if p <= panelPreview || shown[p] {
+			return p
+		}
+	}
+	return panelSearch
+}
+
+// cameraKeyStep is how far, in mouse-drag pixels, an arrow key held for a
+// frame turns the 3D preview camera.
+const cameraKeyStep = 4
+
+// handleFocusKeys moves the focus on TAB (Shift+TAB backwards) between the
+// shown panels and toggles the shortcuts dialog on F1. Items are no TAB
+// stops (see render), so ImGui's own tabbing inside a window stays out of
+// the way.
+func (app *App) handleFocusKeys(shown [panelCount]bool) {
+	if imgui.IsKeyChordPressed(imgui.KeyChord(imgui.KeyF1)) {
+		app.showShortcuts = !app.showShortcuts
+	}
+	if app.showShortcuts && imgui.IsKeyChordPressed(imgui.KeyChord(imgui.KeyEscape)) {
+		app.showShortcuts = false
+	}
+	// A panel that closed gives the focus back to the preview
+	if !shown[app.focusPanel] && app.focusPanel > panelPreview {
+		app.focusPanel = panelPreview
+	}
+
+	io := imgui.CurrentIO()
+	if io.KeyCtrl() || io.KeyAlt() || !imgui.IsKeyPressedBool(imgui.KeyTab) {
+		return
+	}
+	dir := 1
+	if io.KeyShift() {
+		dir = -1
+	}
+	app.focusPanel = nextPanel(app.focusPanel, dir, shown)
+	app.focusPending = true
+	if app.focusPanel != panelSearch && io.WantTextInput() {
+		imgui.InternalClearActiveID() // Leave the search box
+	}
+}
+
+// takeFocus reports whether panel p is to take the focus this frame, as
+// TAB or Enter in the tree asked. The panel then focuses its window or
+// first item.
+func (app *App) takeFocus(p focusPanel) bool {
+	if !app.focusPending || app.focusPanel != p {
+		return false
+	}
+	app.focusPending = false
+	imgui.SetNavCursorVisible(true)
+	return true
+}
+
+// focusWindow gives panel p the focus when it's to take it. Call before
+// its window or child window begins.
+func (app *App) focusWindow(p focusPanel) {
+	if app.takeFocus(p) {
+		imgui.SetNextWindowFocus()
+	}
+}
+
+// trackFocus follows the focus to panel p, when its current window has
+// it because of a click, and outlines the window when p has the focus.
+func (app *App) trackFocus(p focusPanel, flags imgui.FocusedFlags) {
+	if imgui.IsWindowFocusedV(flags) && !app.focusPending {
+		app.focusPanel = p
+	}
+	if app.focusPanel == p {
+		pos := imgui.WindowPos()
+		size := imgui.WindowSize()
+		drawFocusOutline(pos, imgui.NewVec2(pos.X+size.X, pos.Y+size.Y))
+	}
+}
+
+// drawFocusOutline outlines the focused panel, over everything else.
+func drawFocusOutline(min, max imgui.Vec2) {
+	c := FocusOutlineColor
+	col := imgui.ColorU32Vec4(imgui.NewVec4(c[0], c[1], c[2], c[3]))
+	imgui.ForegroundDrawListViewportPtr().AddRectV(min, max, col, 0, 0, 2)
+}
+
+// cameraKeys returns the camera moves the keyboard asks of a 3D preview
+// while the preview has the focus: the arrows turn it as a mouse drag
+// would and +/- zoom it as the wheel does. The arrows are taken from
+// ImGui's navigation so they don't also move between the panel's items.
+func (app *App) cameraKeys() (dx, dy, wheel float32) {
+	if app.focusPanel != panelPreview || imgui.CurrentIO().WantTextInput() {
+		return 0, 0, 0
+	}
+	owner := imgui.IDStr("##CameraKeys")
+	for _, key := range []imgui.Key{imgui.KeyLeftArrow, imgui.KeyRightArrow, imgui.KeyUpArrow, imgui.KeyDownArrow} {
+		imgui.InternalSetKeyOwner(key, owner)
+	}
+	if imgui.IsKeyDown(imgui.KeyLeftArrow) {
+		dx -= cameraKeyStep
+	}
+	if imgui.IsKeyDown(imgui.KeyRightArrow) {
+		dx += cameraKeyStep
+	}
+	if imgui.IsKeyDown(imgui.KeyUpArrow) {
+		dy -= cameraKeyStep
+	}
+	if imgui.IsKeyDown(imgui.KeyDownArrow) {
+		dy += cameraKeyStep
+	}
+	if imgui.IsKeyChordPressed(imgui.KeyChord(imgui.KeyEqual)) || imgui.IsKeyChordPressed(imgui.KeyChord(imgui.KeyKeypadAdd)) {
+		wheel++
+	}
+	if imgui.IsKeyChordPressed(imgui.KeyChord(imgui.KeyMinus)) || imgui.IsKeyChordPressed(imgui.KeyChord(imgui.KeyKeypadSubtract)) {
+		wheel--
+	}
+	return dx, dy, wheel
+}
+
+// itemEntered reports whether Enter was pressed, with exactly the modifier
+// keys mods, on the item just drawn while it has the keyboard focus.
+func itemEntered(mods imgui.KeyChord) bool {
+	return imgui.IsItemFocused() &&
+		(imgui.IsKeyChordPressed(mods|imgui.KeyChord(imgui.KeyEnter)) || imgui.IsKeyChordPressed(mods|imgui.KeyChord(imgui.KeyKeypadEnter)))
+}
+
+// shortcuts are the keys the F1 dialog lists.
+var shortcuts = []struct {
+	keys, action string
+}{
+	{"Tab / Shift+Tab", "Move between Search, Tree, Preview and the side panels"},
+	{"Arrow keys", "Move through the focused list or panel"},
+	{"Left / Right", "Collapse or expand a folder in the tree"},
+	{"Enter", "Activate the item; on a file in the tree, go to its preview"},
+	{"Shift+Enter", "Isolate the model node, or focus the camera on the map model"},
+	{"Shift+F10 / Menu", "Open the context menu of the file in the tree"},
+	{"Alt", "Go to the menu bar"},
+	{"Ctrl+C", "Copy the selected file's name"},
+	{"Cmd+Ctrl+C", "Copy the selected file's path"},
+	{"Space", "Play or pause an animation"},
+	{"+ / - / 0", "Zoom the preview in, out, back to 100%"},
+	{"Arrows (3D preview)", "Turn the camera"},
+	{"W A S D Q E", "Move the 3D map camera"},
+	{"1 / 2 / 3", "Move, rotate or scale the selected map model"},
+	{"Ctrl+D", "Dump the GUI state; in the 3D map, also duplicate the selected model"},
+	{"Delete", "Delete the selected map model"},
+	{"Escape", "Deselect the map model, or close this dialog"},
+	{"F12", "Take a screenshot"},
+	{"F1", "Show or hide this dialog"},
+}
+
+// renderShortcuts draws the shortcuts dialog while it's open.
+func (app *App) renderShortcuts() {
+	if !app.showShortcuts {
+		return
+	}
+	viewport := imgui.MainViewport()
+	center := viewport.Center()
+	imgui.SetNextWindowPosV(center, imgui.CondAppearing, imgui.NewVec2(0.5, 0.5))
+	flags := imgui.WindowFlagsAlwaysAutoResize | imgui.WindowFlagsNoCollapse | imgui.WindowFlagsNoSavedSettings
+	if imgui.BeginV("Keyboard Shortcuts", &app.showShortcuts, flags) {
+		if imgui.BeginTableV("shortcutTable", 2, imgui.TableFlagsRowBg|imgui.TableFlagsBordersInnerV, imgui.NewVec2(0, 0), 0) {
+			for _, s := range shortcuts {
+				imgui.TableNextRow()
+				imgui.TableNextColumn()
+				imgui.Text(s.keys)
+				imgui.TableNextColumn()
+				imgui.Text(s.action)
+			}
+			imgui.EndTable()
+		}
+		if imgui.Button("Close") {
+			app.showShortcuts = false
+		}
+	}
+	imgui.End()
+}
@@ -0,0 +1,76 @@
+// GPU timer queries for measuring render passes in GRF Browser.
+package main
+
+import (
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// gpuTimer measures the GPU time of a pass with GL_TIME_ELAPSED queries.
+// Two queries alternate so a result is read a frame after it was issued,
+// without stalling the pipeline.
+type gpuTimer struct {
+	queries [2]uint32
+	pending [2]bool
+	current int
+	active  bool
+
+	avgMs float32 // Smoothed pass time
+}
+
+// begin starts timing. Skipped while the query to reuse has no result yet.
+func (t *gpuTimer) begin() {
+	if t.queries[0] == 0 {
+		gl.GenQueries(2, &t.queries[0])
+	}
+
+	q := t.queries[t.current]
+	if t.pending[t.current] {
+		var available uint64
+		gl.GetQueryObjectui64v(q, gl.QUERY_RESULT_AVAILABLE, &available)
+		if available == 0 {
+			return
+		}
+		var ns uint64
+		gl.GetQueryObjectui64v(q, gl.QUERY_RESULT, &ns)
+		t.record(float32(ns) / 1e6)
+		t.pending[t.current] = false
+	}
+
+	gl.BeginQuery(gl.TIME_ELAPSED, q)
+	t.active = true
+}
+
+// end stops timing the pass started by begin.
+func (t *gpuTimer) end() {
+	if !t.active {
+		return
+	}
+	gl.EndQuery(gl.TIME_ELAPSED)
+	t.active = false
+	t.pending[t.current] = true
+	t.current = 1 - t.current
+}
+
+// record folds a measurement into the smoothed average.
+func (t *gpuTimer) record(ms float32) {
+	if t.avgMs == 0 {
+		t.avgMs = ms
+		return
+	}
+	t.avgMs = t.avgMs*0.95 + ms*0.05
+}
+
+// reset clears the average, e.g. after switching render paths.
+func (t *gpuTimer) reset() {
+	t.avgMs = 0
+}
+
+// destroy releases the query objects.
+func (t *gpuTimer) destroy() {
+	if t.queries[0] != 0 {
+		gl.DeleteQueries(2, &t.queries[0])
+		t.queries = [2]uint32{}
+	}
+	t.pending = [2]bool{}
+	t.active = false
+}
@@ -0,0 +1,192 @@
+// Headless export mode for GRF Browser: map reports, minimaps and model
+// screenshots from scripts and CI, without the GUI.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/AllenDang/cimgui-go/backend"
+	"github.com/AllenDang/cimgui-go/backend/sdlbackend"
+	"github.com/go-gl/gl/v4.1-core/gl"
+
+	"github.com/Faultbox/midgard-ro/pkg/formats"
+	"github.com/Faultbox/midgard-ro/pkg/grf"
+)
+
+// headlessModelSize is the size of headless model screenshots.
+const headlessModelSize = 512
+
+// headlessOptions are the command-line options of a headless run.
+type headlessOptions struct {
+	grfPath   string
+	exportMap string
+	outDir    string
+	noGL      bool // Skip model screenshots, e.g. on CI runners without GPU
+}
+
+// runHeadless exports a map without opening a window: the map report as
+// JSON, the GAT as a minimap PNG and, when an OpenGL context can be
+// created, a screenshot of every model the map places. Without one it
+// falls back to the CPU-only exports.
+func runHeadless(opts headlessOptions) error {
+	if opts.grfPath == "" {
+		return errors.New("headless mode needs -grf")
+	}
+	if opts.exportMap == "" {
+		return errors.New("nothing to do: use -export-map")
+	}
+
+	archive, err := grf.Open(opts.grfPath)
+	if err != nil {
+		return fmt.Errorf("failed to open GRF: %w", err)
+	}
+	app := &App{archive: archive, grfPath: opts.grfPath}
+	defer archive.Close()
+
+	if err := os.MkdirAll(opts.outDir, 0755); err != nil {
+		return fmt.Errorf("creating output dir: %w", err)
+	}
+	return app.exportMapHeadless(opts)
+}
+
+// exportMapHeadless writes the report, minimap and model screenshots of
+// opts.exportMap to opts.outDir.
+func (app *App) exportMapHeadless(opts headlessOptions) error {
+	name := strings.TrimSuffix(opts.exportMap, ".rsw")
+	rswPath := "data\\" + name + ".rsw"
+	if !app.archive.Contains(rswPath) {
+		rswPath = "data/" + name + ".rsw"
+	}
+	data, err := app.readFile(rswPath)
+	if err != nil {
+		return fmt.Errorf("map not found in archive: %s", name)
+	}
+	rsw, err := formats.ParseRSW(data)
+	if err != nil {
+		return fmt.Errorf("parsing RSW: %w", err)
+	}
+
+	gnd, gat := app.loadMapGrids(rsw)
+	report := buildMapReport(name, rsw, gnd, gat, app.archive.Contains)
+	reportPath, err := writeMapReport(report, opts.outDir)
+	if err != nil {
+		return fmt.Errorf("writing report: %w", err)
+	}
+	fmt.Printf("Map report saved: %s (%d issues)\n", reportPath, len(report.Issues))
+
+	if gat != nil {
+		minimapPath := filepath.Join(opts.outDir, name+"-minimap.png")
+		if err := savePNG(minimapPath, gatImage(gat)); err != nil {
+			return fmt.Errorf("writing minimap: %w", err)
+		}
+		fmt.Printf("Minimap saved: %s\n", minimapPath)
+	}
+
+	if opts.noGL {
+		return nil
+	}
+	if err := initOffscreenGL(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: no OpenGL context, skipping model screenshots: %v\n", err)
+		return nil
+	}
+	return app.exportModelScreenshots(rsw, filepath.Join(opts.outDir, name+"-models"))
+}
+
+// exportModelScreenshots saves a screenshot of each model an RSW places,
+// named after the model's path. Models that fail to load are reported and
+// skipped.
+func (app *App) exportModelScreenshots(rsw *formats.RSW, dir string) error {
+	unique := make(map[string]bool)
+	for _, m := range rsw.GetModels() {
+		unique[m.ModelName] = true
+	}
+	names := make([]string, 0, len(unique))
+	for name := range unique {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating model dir: %w", err)
+	}
+	viewer, err := NewModelViewer(headlessModelSize, headlessModelSize)
+	if err != nil {
+		return fmt.Errorf("creating model viewer: %w", err)
+	}
+	defer viewer.Destroy()
+	viewer.SetShowAxes(false)
+
+	saved := 0
+	for _, name := range names {
+		data, err := app.readFile("data/model/" + name)
+		if err != nil {
+			continue // Listed in the report as missing
+		}
+		rsm, err := formats.ParseRSM(data)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing RSM %s: %v\n", euckrToUTF8(name), err)
+			continue
+		}
+		for _, w := range rsm.Warnings {
+			fmt.Fprintf(os.Stderr, "Warning: RSM %s damaged, %s\n", euckrToUTF8(name), w)
+		}
+		if err := viewer.LoadModel(rsm, app.readFile, true); err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading model %s: %v\n", euckrToUTF8(name), err)
+			continue
+		}
+		if err := savePNG(filepath.Join(dir, modelFileName(name)), viewer.Capture()); err != nil {
+			return fmt.Errorf("writing model screenshot: %w", err)
+		}
+		saved++
+	}
+	fmt.Printf("Model screenshots saved: %d of %d in %s\n", saved, len(names), dir)
+	return nil
+}
+
+// modelFileName turns an RSM path such as "내부소품\탁자.rsm" into a flat
+// PNG file name.
+func modelFileName(name string) string {
+	name = strings.TrimSuffix(euckrToUTF8(name), filepath.Ext(name))
+	name = strings.NewReplacer("\\", "_", "/", "_").Replace(name)
+	return name + ".png"
+}
+
+// initOffscreenGL makes an OpenGL context current on a hidden window. SDL
+// panics when no window can be created (no display), which is returned as
+// an error.
+func initOffscreenGL() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	b, err := backend.CreateBackend(sdlbackend.NewSDLBackend())
+	if err != nil {
+		return err
+	}
+	// cimgui-go maps SDL_WINDOW_HIDDEN to the "Transparent" flag
+	b.SetWindowFlags(sdlbackend.SDLWindowFlagsTransparent, 1)
+	b.CreateWindow("GRF Browser (headless)", headlessModelSize, headlessModelSize)
+	return gl.Init()
+}
+
+// savePNG encodes img as a PNG file.
+func savePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
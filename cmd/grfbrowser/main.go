@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -20,6 +21,7 @@ import (
 	"github.com/sqweek/dialog"
 	_ "golang.org/x/image/bmp" // BMP decoder registration
 
+	"github.com/Faultbox/midgard-ro/internal/engine/scene"
 	"github.com/Faultbox/midgard-ro/pkg/formats"
 	"github.com/Faultbox/midgard-ro/pkg/grf"
 )
@@ -30,8 +32,27 @@ func main() {
 	// Parse command line arguments
 	grfPath := flag.String("grf", "", "Path to GRF file to open")
 	debugMap := flag.String("map", "", "Map name to auto-load (e.g., 'prontera' for prontera.rsw)")
+	noRestore := flag.Bool("no-restore", false, "Do not restore the last session on startup")
+	headless := flag.Bool("headless", false, "Export without opening a window (requires -grf and -export-map)")
+	exportMap := flag.String("export-map", "", "Headless: map to export a report, minimap and model screenshots of")
+	outDir := flag.String("out", ".", "Headless: output directory")
+	noGL := flag.Bool("no-gl", false, "Headless: skip model screenshots, which need an OpenGL context")
 	flag.Parse()
 
+	if *headless {
+		err := runHeadless(headlessOptions{
+			grfPath:   *grfPath,
+			exportMap: *exportMap,
+			outDir:    *outDir,
+			noGL:      *noGL,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Create and run application
 	app := NewApp()
 	defer app.Close()
@@ -41,6 +62,8 @@ func main() {
 		if err := app.OpenGRF(*grfPath); err != nil {
 			fmt.Fprintf(os.Stderr, "Error opening GRF: %v\n", err)
 		}
+	} else if !*noRestore {
+		app.restoreLastSession()
 	}
 
 	// Auto-load map if specified (requires GRF to be loaded)
@@ -103,6 +126,7 @@ type App struct {
 	previewZoom     float32            // Zoom level for preview
 	previewSpeed    float32            // Animation playback speed (1.0 = normal)
 	previewLooping  bool               // Whether animation loops
+	previewSprView  sprView            // Sprite debug view (palette index, alpha, ...)
 
 	// Image preview state (ADR-009 Stage 4)
 	previewImage   *backend.Texture // Texture for image preview
@@ -134,7 +158,9 @@ type App struct {
 	previewGNDZoom float32          // Zoom level for GND view
 
 	// RSW preview state (ADR-011 Stage 3)
-	previewRSW *formats.RSW // Loaded RSW data
+	previewRSW    *formats.RSW // Loaded RSW data
+	mapReport     *MapReport   // Statistics and validation report for the map
+	mapReportMode bool         // Whether the report tab is shown instead of 2D info
 
 	// RSM preview state (ADR-012 Stage 2/3)
 	previewRSM          *formats.RSM // Loaded RSM 3D model data
@@ -142,14 +168,42 @@ type App struct {
 	magentaTransparency bool         // Enable magenta (255,0,255) as transparency key
 
 	// Map 3D viewer state (ADR-013)
-	mapViewer         *MapViewer // 3D map renderer
-	map3DViewMode     bool       // Whether 3D view is active for map
-	maxModelsLimit    int        // Max models to load (default 1500)
-	terrainBrightness float32    // Terrain brightness multiplier (default 1.0)
+	mapViewer         *MapViewer   // 3D map renderer
+	map3DViewMode     bool         // Whether 3D view is active for map
+	maxModelsLimit    int          // Max models to load (default 1500)
+	terrainBrightness float32      // Terrain brightness multiplier (default 1.0)
+	compareScene      *scene.Scene // Second map shown beside the first, nil for none
+	comparePath       string       // RSW shown in compareScene
 
 	// Scene debug UI state
 	modelFilterText     string // Filter text for model list
 	showPropertiesPanel bool   // Whether to show properties panel
+	mapEditPath         string // Where Save RSW writes the edited map ("" = default)
+
+	// External tools ("Open with ...")
+	externalTools     []ExternalTool
+	showToolsSettings bool
+	toolTempDirs      []string      // Extraction dirs, removed on exit
+	watchedFiles      []watchedFile // Extracted files re-imported on save
+	lastWatchPoll     time.Time
+	fileOverrides     map[string][]byte // Re-imported files by archive path
+
+	showResources bool // GPU resource inspector window
+
+	mergeWizard mergeWizard // Tools > Merge Archives
+
+	// Loose data folder overlaid on the archive (Tools > Overlay Data Folder)
+	dataDir         *grf.Dir
+	pendingDataDir  string // Folder selected from the dialog, opened on the main thread
+	lastDataDirPoll time.Time
+
+	// Table entry of the previewed file (Archive Entry Info)
+	entryInfo *entryInfo
+
+	// Keyboard focus (ADR-009 Stage 5)
+	focusPanel    focusPanel // Panel with the keyboard focus
+	focusPending  bool       // focusPanel was moved by key and must take the focus
+	showShortcuts bool       // F1 shortcuts dialog
 }
 
 var (
@@ -184,6 +238,7 @@ var koreanGlyphRanges = []imgui.Wchar{
 func NewApp() *App {
 	app := &App{
 		expandedPaths:       make(map[string]bool),
+		fileOverrides:       make(map[string][]byte),
 		filterSprites:       true,
 		filterAnimations:    true,
 		filterTextures:      true,
@@ -200,13 +255,18 @@ func NewApp() *App {
 		terrainBrightness:   1.0,  // Default terrain brightness
 	}
 
+	tools, err := loadExternalTools(defaultExternalToolsPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not load external tools: %v\n", err)
+	}
+	app.externalTools = tools
+
 	// Ensure screenshot directory exists (ADR-010)
 	if err := os.MkdirAll(app.screenshotDir, 0755); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: could not create screenshot dir: %v\n", err)
 	}
 
 	// Create backend using the proper wrapper
-	var err error
 	app.backend, err = backend.CreateBackend(sdlbackend.NewSDLBackend())
 	if err != nil {
 		panic(fmt.Sprintf("failed to create backend: %v", err))
@@ -271,8 +331,9 @@ func (app *App) loadKoreanFont() {
 	fmt.Printf("Loaded Korean font: %s\n", fontPath)
 }
 
-// Close cleans up resources.
+// Close saves the session and cleans up resources.
 func (app *App) Close() {
+	app.autoSaveWorkspace()
 	if app.modelViewer != nil {
 		app.modelViewer.Destroy()
 		app.modelViewer = nil
@@ -281,9 +342,12 @@ func (app *App) Close() {
 		app.mapViewer.Destroy()
 		app.mapViewer = nil
 	}
+	app.closeCompareMap()
+	app.closeMergeWizard()
 	if app.archive != nil {
 		app.archive.Close()
 	}
+	app.cleanupToolTempDirs()
 }
 
 // Run starts the main application loop.
@@ -338,6 +402,7 @@ func (app *App) OpenGRF(path string) error {
 	app.selectedPath = ""
 	app.selectedOriginalPath = ""
 	app.expandedPaths = make(map[string]bool)
+	app.resetExternalEdits()
 
 	// Clear any existing preview
 	app.clearPreview()
@@ -392,6 +457,10 @@ func (app *App) render() {
 	// Check for remote commands (ADR-010 Phase 3)
 	app.checkAndExecuteCommand()
 
+	// Re-import files saved by external tools or edited in the data folder
+	app.pollWatchedFiles()
+	app.pollDataDir()
+
 	// Process pending file dialog result (must be on main thread for SDL/Cocoa)
 	if app.pendingGRFPath != "" {
 		path := app.pendingGRFPath
@@ -413,9 +482,6 @@ func (app *App) render() {
 		app.dumpState()
 	}
 
-	// NOTE: TAB key navigates within tree (ImGui default behavior)
-	// TODO (Stage 5): Research ImGui ConfigFlags to implement custom TAB panel cycling
-
 	// Ctrl+C = copy filename only
 	// Cmd+Ctrl+C = copy full path (macOS friendly)
 	if app.selectedPath != "" {
@@ -509,6 +575,10 @@ func (app *App) render() {
 		}
 	}
 
+	// TAB moves between the panels (see handleFocusKeys), not the items
+	imgui.PushItemFlag(imgui.ItemFlagsNoTabStop, true)
+	defer imgui.PopItemFlag()
+
 	// Main menu bar
 	if imgui.BeginMainMenuBar() {
 		if imgui.BeginMenu("File") {
@@ -516,14 +586,48 @@ func (app *App) render() {
 				app.openFileDialog()
 			}
 			imgui.Separator()
+			if imgui.MenuItemBool("Save Workspace") {
+				if err := app.saveWorkspace(defaultWorkspacePath()); err != nil {
+					app.showNotification(fmt.Sprintf("Workspace save failed: %v", err))
+				} else {
+					app.showNotification("Workspace saved")
+				}
+			}
+			if imgui.MenuItemBool("Restore Last Session") {
+				app.restoreLastSession()
+			}
+			imgui.Separator()
 			if imgui.MenuItemBool("Exit") {
+				app.autoSaveWorkspace()
 				os.Exit(0)
 			}
 			imgui.EndMenu()
 		}
+		if imgui.BeginMenu("Tools") {
+			app.renderOpenWithMenu()
+			if imgui.MenuItemBool("GPU Resources...") {
+				app.showResources = true
+			}
+			if imgui.MenuItemBool("Merge Archives...") {
+				app.openMergeWizard()
+			}
+			imgui.Separator()
+			app.renderDataDirMenu()
+			imgui.EndMenu()
+		}
+		if imgui.BeginMenu("Help") {
+			if imgui.MenuItemBoolV("Keyboard Shortcuts", "F1", false, true) {
+				app.showShortcuts = true
+			}
+			imgui.EndMenu()
+		}
 		imgui.EndMainMenuBar()
 	}
 
+	app.renderToolsSettings()
+	app.renderResourceInspector()
+	app.renderMergeWizard()
+
 	// Get viewport work area (excludes menu bar)
 	viewport := imgui.MainViewport()
 	workPos := viewport.WorkPos()
@@ -543,6 +647,11 @@ func (app *App) render() {
 	// Show properties panel when a model is selected
 	showPropertiesPanel := app.showPropertiesPanel && app.mapViewer != nil && app.mapViewer.SelectedIdx >= 0
 
+	var shown [panelCount]bool
+	shown[panelActions] = showActionsPanel || showMapControlsPanel
+	shown[panelProperties] = showPropertiesPanel
+	app.handleFocusKeys(shown)
+
 	// Window flags for fixed panels
 	flags := imgui.WindowFlagsNoMove | imgui.WindowFlagsNoResize | imgui.WindowFlagsNoCollapse
 
@@ -550,6 +659,9 @@ func (app *App) render() {
 	imgui.SetNextWindowPos(workPos)
 	imgui.SetNextWindowSize(imgui.NewVec2(leftPanelWidth, contentHeight))
 	if imgui.BeginV("Files", nil, flags) {
+		if imgui.IsWindowFocused() && !app.focusPending {
+			app.focusPanel = panelSearch // Filters clicked
+		}
 		app.renderSearchAndFilter()
 		imgui.Separator()
 		app.renderFileTree()
@@ -568,7 +680,9 @@ func (app *App) render() {
 	// Center panel - Preview
 	imgui.SetNextWindowPos(imgui.NewVec2(workPos.X+leftPanelWidth, workPos.Y))
 	imgui.SetNextWindowSize(imgui.NewVec2(previewWidth, contentHeight))
+	app.focusWindow(panelPreview)
 	if imgui.BeginV("Preview", nil, flags) {
+		app.trackFocus(panelPreview, imgui.FocusedFlagsChildWindows)
 		app.renderPreview()
 	}
 	imgui.End()
@@ -577,7 +691,9 @@ func (app *App) render() {
 	if showActionsPanel {
 		imgui.SetNextWindowPos(imgui.NewVec2(workPos.X+leftPanelWidth+previewWidth, workPos.Y))
 		imgui.SetNextWindowSize(imgui.NewVec2(rightPanelWidth, contentHeight))
+		app.focusWindow(panelActions)
 		if imgui.BeginV("Actions", nil, flags) {
+			app.trackFocus(panelActions, imgui.FocusedFlagsChildWindows)
 			app.renderActionsPanel()
 		}
 		imgui.End()
@@ -588,7 +704,9 @@ func (app *App) render() {
 	if showMapControlsPanel {
 		imgui.SetNextWindowPos(imgui.NewVec2(controlsPanelX, workPos.Y))
 		imgui.SetNextWindowSize(imgui.NewVec2(rightPanelWidth, contentHeight))
+		app.focusWindow(panelActions)
 		if imgui.BeginV("Controls", nil, flags) {
+			app.trackFocus(panelActions, imgui.FocusedFlagsChildWindows)
 			app.renderMapControlsPanel()
 		}
 		imgui.End()
@@ -599,7 +717,9 @@ func (app *App) render() {
 	if showPropertiesPanel {
 		imgui.SetNextWindowPos(imgui.NewVec2(controlsPanelX, workPos.Y))
 		imgui.SetNextWindowSize(imgui.NewVec2(propertiesPanelWidth, contentHeight))
+		app.focusWindow(panelProperties)
 		if imgui.BeginV("Properties", nil, flags) {
+			app.trackFocus(panelProperties, imgui.FocusedFlagsChildWindows)
 			app.renderModelPropertiesPanel()
 		}
 		imgui.End()
@@ -614,6 +734,8 @@ func (app *App) render() {
 	}
 	imgui.End()
 
+	app.renderShortcuts()
+
 	// Screenshot notification overlay (ADR-010)
 	// Shows for 2 seconds after capture
 	if app.showScreenshotMsg && time.Since(app.screenshotMsgTime) < 2*time.Second {
@@ -638,9 +760,18 @@ func (app *App) renderSearchAndFilter() {
 	imgui.SameLine()
 
 	imgui.SetNextItemWidth(-1)
+	if app.takeFocus(panelSearch) {
+		imgui.SetKeyboardFocusHere()
+	}
 	if imgui.InputTextWithHint("##search", "Filter files...", &app.searchText, 0, nil) {
 		app.rebuildTree()
 	}
+	if imgui.IsItemFocused() && !app.focusPending {
+		app.focusPanel = panelSearch
+	}
+	if app.focusPanel == panelSearch {
+		drawFocusOutline(imgui.ItemRectMin(), imgui.ItemRectMax())
+	}
 
 	// Filter checkboxes in two columns using table
 	if imgui.TreeNodeExStrV("Filters", imgui.TreeNodeFlagsDefaultOpen) {
@@ -736,8 +867,10 @@ func (app *App) renderPreview() {
 	if app.previewPath != app.selectedPath {
 		app.loadPreview(app.selectedPath)
 	}
+	app.renderEntryInfo()
 
 	imgui.Separator()
+	app.renderParseWarnings(ext)
 
 	// Render based on file type
 	switch ext {
@@ -764,11 +897,45 @@ func (app *App) renderPreview() {
 	}
 }
 
+// renderParseWarnings lists what the parser skipped in a damaged file,
+// above its preview.
+func (app *App) renderParseWarnings(ext string) {
+	var warnings []formats.Warning
+	switch {
+	case ext == ".spr" && app.previewSPR != nil:
+		warnings = app.previewSPR.Warnings
+	case ext == ".act" && app.previewACT != nil:
+		warnings = app.previewACT.Warnings
+		if app.previewSPR != nil {
+			warnings = append(slices.Clip(warnings), app.previewSPR.Warnings...)
+		}
+	case ext == ".gnd" && app.previewGND != nil:
+		warnings = app.previewGND.Warnings
+	case ext == ".rsw" && app.previewRSW != nil:
+		warnings = app.previewRSW.Warnings
+	case ext == ".rsm" && app.previewRSM != nil:
+		warnings = app.previewRSM.Warnings
+	}
+	if len(warnings) == 0 {
+		return
+	}
+
+	label := fmt.Sprintf("Damaged file: %d problems##parsewarnings", len(warnings))
+	if imgui.TreeNodeExStrV(label, imgui.TreeNodeFlagsDefaultOpen) {
+		for _, w := range warnings {
+			imgui.TextColored(reportSeverityColor(ReportSeverityWarning), w.String())
+		}
+		imgui.TreePop()
+	}
+	imgui.Separator()
+}
+
 // loadPreview loads the preview for the given display path.
 // Uses selectedOriginalPath for archive reads (handles EUC-KR paths).
 func (app *App) loadPreview(displayPath string) {
 	// Clear previous preview
 	app.clearPreview()
+	app.entryInfo = nil
 	app.previewPath = displayPath
 
 	if app.archive == nil {
@@ -780,6 +947,7 @@ func (app *App) loadPreview(displayPath string) {
 	if archivePath == "" {
 		archivePath = displayPath // Fallback for ASCII paths
 	}
+	app.loadEntryInfo(archivePath)
 
 	ext := strings.ToLower(filepath.Ext(displayPath))
 	switch ext {
@@ -811,9 +979,7 @@ func (app *App) loadPreview(displayPath string) {
 func (app *App) clearPreview() {
 	// Release sprite textures
 	for _, tex := range app.previewTextures {
-		if tex != nil {
-			tex.Release()
-		}
+		releasePreviewTexture(tex)
 	}
 	app.previewTextures = nil
 	app.previewSPR = nil
@@ -823,10 +989,8 @@ func (app *App) clearPreview() {
 	app.previewPlaying = false
 
 	// Release image texture (Stage 4)
-	if app.previewImage != nil {
-		app.previewImage.Release()
-		app.previewImage = nil
-	}
+	releasePreviewTexture(app.previewImage)
+	app.previewImage = nil
 	app.previewImgSize = [2]int{0, 0}
 
 	// Clear text and hex preview (Stage 4)
@@ -839,20 +1003,18 @@ func (app *App) clearPreview() {
 
 	// Clear GAT preview (ADR-011)
 	app.previewGAT = nil
-	if app.previewGATTex != nil {
-		app.previewGATTex.Release()
-		app.previewGATTex = nil
-	}
+	releasePreviewTexture(app.previewGATTex)
+	app.previewGATTex = nil
 
 	// Clear GND preview (ADR-011 Stage 2)
 	app.previewGND = nil
-	if app.previewGNDTex != nil {
-		app.previewGNDTex.Release()
-		app.previewGNDTex = nil
-	}
+	releasePreviewTexture(app.previewGNDTex)
+	app.previewGNDTex = nil
 
 	// Clear RSW preview (ADR-011 Stage 3)
 	app.previewRSW = nil
+	app.mapReport = nil
+	app.closeCompareMap()
 
 	// Clear RSM preview (ADR-012 Stage 2/3)
 	app.previewRSM = nil
@@ -867,6 +1029,8 @@ func (app *App) renderStatusBar() {
 	} else {
 		imgui.Text("No GRF loaded")
 	}
+	imgui.SameLine()
+	imgui.TextDisabled(fmt.Sprintf("| Focus: %s | F1: Shortcuts", app.focusPanel))
 }
 
 // renderModelPropertiesPanel renders the properties panel for selected model.
@@ -890,8 +1054,8 @@ func (app *App) renderModelPropertiesPanel() {
 	imgui.Separator()
 
 	// Model name (convert from EUC-KR to UTF-8 for Korean display)
-	displayName := euckrToUTF8(model.modelName)
-	fullPath := "data/model/" + model.modelName
+	displayName := euckrToUTF8(model.Name())
+	fullPath := "data/model/" + model.Name()
 
 	imgui.Text("Model:")
 	imgui.TextWrapped(displayName)
@@ -922,27 +1086,27 @@ func (app *App) renderModelPropertiesPanel() {
 	imgui.Spacing()
 	imgui.Separator()
 
-	// Position
+	// Transform, editable in RSW coordinates
+	position, rotation, scale := model.position, model.rotation, model.scale
 	imgui.Text("Position:")
-	imgui.Text(fmt.Sprintf("  X: %.2f", model.position[0]))
-	imgui.Text(fmt.Sprintf("  Y: %.2f", model.position[1]))
-	imgui.Text(fmt.Sprintf("  Z: %.2f", model.position[2]))
+	imgui.SetNextItemWidth(-1)
+	edited := imgui.DragFloat3V("##Position", &position, 0.5, 0, 0, "%.2f", imgui.SliderFlagsNone)
 
 	imgui.Spacing()
 
-	// Rotation
 	imgui.Text("Rotation:")
-	imgui.Text(fmt.Sprintf("  X: %.1f", model.rotation[0]))
-	imgui.Text(fmt.Sprintf("  Y: %.1f", model.rotation[1]))
-	imgui.Text(fmt.Sprintf("  Z: %.1f", model.rotation[2]))
+	imgui.SetNextItemWidth(-1)
+	edited = imgui.DragFloat3V("##Rotation", &rotation, 0.5, -360, 360, "%.1f", imgui.SliderFlagsNone) || edited
 
 	imgui.Spacing()
 
 	// Scale with warning for negative
 	imgui.Text("Scale:")
-	imgui.Text(fmt.Sprintf("  X: %.3f", model.scale[0]))
-	imgui.Text(fmt.Sprintf("  Y: %.3f", model.scale[1]))
-	imgui.Text(fmt.Sprintf("  Z: %.3f", model.scale[2]))
+	imgui.SetNextItemWidth(-1)
+	edited = imgui.DragFloat3V("##Scale", &scale, 0.01, 0, 0, "%.3f", imgui.SliderFlagsNone) || edited
+	if edited {
+		app.mapViewer.SetModelTransform(app.mapViewer.SelectedIdx, position, rotation, scale)
+	}
 
 	if model.HasNegativeScale() {
 		imgui.Spacing()
@@ -1047,6 +1211,14 @@ func (app *App) renderModelPropertiesPanel() {
 	if imgui.ButtonV("Focus Camera", imgui.NewVec2(-1, 0)) {
 		app.mapViewer.FocusOnModel(app.mapViewer.SelectedIdx)
 	}
+
+	// Instance editing
+	if imgui.ButtonV("Duplicate", imgui.NewVec2(-1, 0)) {
+		app.duplicateSelectedModel()
+	}
+	if imgui.ButtonV("Delete", imgui.NewVec2(-1, 0)) {
+		app.mapViewer.DeleteModel(app.mapViewer.SelectedIdx)
+	}
 }
 
 // openModelInViewer switches from map view to model preview for the given path.
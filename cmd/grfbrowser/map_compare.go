@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/scene"
+	"github.com/Faultbox/midgard-ro/pkg/formats"
+)
+
+// loadCompareMap loads the map of the RSW at path into the compare scene,
+// shown beside the main map view. The scene keeps the map shown if the
+// new one fails to load.
+func (app *App) loadCompareMap(path string) error {
+	data, err := app.readFile(path)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	rsw, err := formats.ParseRSW(data)
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", path, err)
+	}
+	gndPath := "data/" + rsw.GndFile
+	gndData, err := app.readFile(gndPath)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", gndPath, err)
+	}
+	gnd, err := formats.ParseGND(gndData)
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", gndPath, err)
+	}
+
+	if app.compareScene == nil {
+		cfg := scene.DefaultConfig()
+		cfg.Width, cfg.Height = 512, 512
+		sc, err := scene.New(cfg)
+		if err != nil {
+			return fmt.Errorf("creating compare scene: %w", err)
+		}
+		app.compareScene = sc
+	}
+	if err := app.compareScene.LoadMap(gnd, rsw, app.readFile); err != nil {
+		if app.comparePath == "" {
+			app.closeCompareMap()
+		}
+		return fmt.Errorf("loading map: %w", err)
+	}
+	app.comparePath = path
+	return nil
+}
+
+// closeCompareMap releases the compare scene, leaving the main map alone.
+func (app *App) closeCompareMap() {
+	if app.compareScene != nil {
+		app.compareScene.Destroy()
+		app.compareScene = nil
+	}
+	app.comparePath = ""
+}
+
+// renderCompareView renders the compare map at the given size, seen
+// through the main view's camera so both maps move together.
+func (app *App) renderCompareView(width, height float32) {
+	cs := app.compareScene
+	cs.Brightness = app.mapViewer.Brightness
+	cs.Resize(int32(width), int32(height))
+	texID := cs.Render(app.mapViewer.Camera(), nil)
+
+	texRef := imgui.NewTextureRefTextureID(imgui.TextureID(texID))
+	imgui.ImageWithBgV(
+		*texRef,
+		imgui.NewVec2(width, height),
+		imgui.NewVec2(0, 1), // UV flipped
+		imgui.NewVec2(1, 0),
+		imgui.NewVec4(0.1, 0.1, 0.1, 1.0),
+		imgui.NewVec4(1, 1, 1, 1),
+	)
+}
+
+// renderCompareSection renders the map controls for picking a second map
+// to show side by side with the loaded one.
+func (app *App) renderCompareSection() {
+	imgui.Text("Compare")
+	imgui.Separator()
+
+	preview := "None"
+	if app.comparePath != "" {
+		preview = app.comparePath[strings.LastIndex(app.comparePath, "/")+1:]
+	}
+	imgui.SetNextItemWidth(-1)
+	if imgui.BeginCombo("##CompareMap", preview) {
+		if imgui.SelectableBool("None") {
+			app.closeCompareMap()
+		}
+		for _, f := range app.rswFiles() {
+			name := f[strings.LastIndex(f, "/")+1:]
+			if imgui.SelectableBoolV(name+"##"+f, f == app.comparePath, 0, imgui.NewVec2(0, 0)) && f != app.comparePath {
+				if err := app.loadCompareMap(f); err != nil {
+					fmt.Fprintf(os.Stderr, "Error loading compare map: %v\n", err)
+				}
+			}
+		}
+		imgui.EndCombo()
+	}
+	if app.compareScene != nil {
+		imgui.TextDisabled("Shown right, following the main camera")
+	}
+}
+
+// rswFiles returns the archive's map files, sorted.
+func (app *App) rswFiles() []string {
+	var files []string
+	for _, f := range app.flatFiles {
+		if strings.HasSuffix(strings.ToLower(f), ".rsw") {
+			files = append(files, f)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
@@ -0,0 +1,331 @@
+// Lightweight map editing: transform, duplicate and delete RSW model
+// instances with on-screen gizmos, then save the modified RSW.
+package main
+
+import (
+	"errors"
+	"fmt"
+	gomath "math"
+	"slices"
+
+	"github.com/Faultbox/midgard-ro/pkg/formats"
+	"github.com/Faultbox/midgard-ro/pkg/math"
+)
+
+// GizmoMode is what dragging a gizmo handle does to the selected model.
+type GizmoMode int
+
+const (
+	GizmoTranslate GizmoMode = iota
+	GizmoRotate
+	GizmoScale
+)
+
+// String returns the mode's name.
+func (m GizmoMode) String() string {
+	switch m {
+	case GizmoRotate:
+		return "Rotate"
+	case GizmoScale:
+		return "Scale"
+	default:
+		return "Move"
+	}
+}
+
+// Gizmo layout in screen pixels.
+const (
+	gizmoHandleLength = 70 // From the model's origin to each handle
+	gizmoHandleRadius = 7  // Grab distance and drawn size of a handle
+	gizmoDegreesPerPx = 0.5
+	gizmoScalePerPx   = 0.01
+	gizmoMinScale     = 0.01
+)
+
+// gizmoAxes are the world axes of the gizmo handles with their colors.
+var gizmoAxes = [3]struct {
+	dir   [3]float32
+	color [4]float32
+}{
+	{[3]float32{1, 0, 0}, [4]float32{0.95, 0.3, 0.3, 1}},
+	{[3]float32{0, 1, 0}, [4]float32{0.3, 0.9, 0.3, 1}},
+	{[3]float32{0, 0, 1}, [4]float32{0.35, 0.5, 1, 1}},
+}
+
+// gizmoDrag is an in-progress drag of a gizmo handle.
+type gizmoDrag struct {
+	active bool
+	axis   int
+	startX float32 // Mouse position the drag started at, in view pixels
+	startY float32
+	// The model's transform when the drag started; the drag always
+	// applies to it, so rounding doesn't accumulate
+	position [3]float32
+	rotation [3]float32
+	scale    [3]float32
+}
+
+// GizmoHandle is one axis handle of the selected model's gizmo, in view
+// pixels.
+type GizmoHandle struct {
+	OriginX, OriginY float32
+	X, Y             float32
+	Color            [4]float32
+	Active           bool // Being dragged
+}
+
+// modelWorldPos returns a model's position in world space.
+func (mv *MapViewer) modelWorldPos(model *MapModel) [3]float32 {
+	return [3]float32{
+		model.position[0] + mv.mapWidth/2,
+		-model.position[1],
+		model.position[2] + mv.mapHeight/2,
+	}
+}
+
+// projectToView projects a world position into view pixels, with y down.
+// It reports false for points behind the camera.
+func (mv *MapViewer) projectToView(p [3]float32, viewW, viewH float32) (x, y float32, ok bool) {
+	clip := mv.lastViewProj.MulVec4(math.Vec4{p[0], p[1], p[2], 1})
+	if clip[3] <= 0.0001 {
+		return 0, 0, false
+	}
+	x = (clip[0]/clip[3]*0.5 + 0.5) * viewW
+	y = (1 - (clip[1]/clip[3]*0.5 + 0.5)) * viewH
+	return x, y, true
+}
+
+// axisOnScreen returns the view-pixel direction of a world axis at the
+// selected model and how many pixels one world unit along it spans.
+func (mv *MapViewer) axisOnScreen(origin [3]float32, axis int, viewW, viewH float32) (dx, dy, pxPerUnit float32, ok bool) {
+	ox, oy, ok1 := mv.projectToView(origin, viewW, viewH)
+	dir := gizmoAxes[axis].dir
+	ex, ey, ok2 := mv.projectToView([3]float32{origin[0] + dir[0], origin[1] + dir[1], origin[2] + dir[2]}, viewW, viewH)
+	if !ok1 || !ok2 {
+		return 0, 0, 0, false
+	}
+	dx, dy = ex-ox, ey-oy
+	pxPerUnit = float32(gomath.Hypot(float64(dx), float64(dy)))
+	if pxPerUnit < 0.0001 {
+		return 0, 0, 0, false // Axis points at the camera
+	}
+	return dx / pxPerUnit, dy / pxPerUnit, pxPerUnit, true
+}
+
+// GizmoHandles returns the selected model's gizmo handles, or nil when
+// nothing editable is selected or it's off screen.
+func (mv *MapViewer) GizmoHandles(viewW, viewH float32) []GizmoHandle {
+	model := mv.GetModel(mv.SelectedIdx)
+	if model == nil || !model.Visible || mv.PlayMode {
+		return nil
+	}
+	origin := mv.modelWorldPos(model)
+	ox, oy, ok := mv.projectToView(origin, viewW, viewH)
+	if !ok {
+		return nil
+	}
+	var handles []GizmoHandle
+	for axis, a := range gizmoAxes {
+		dx, dy, _, ok := mv.axisOnScreen(origin, axis, viewW, viewH)
+		if !ok {
+			continue
+		}
+		handles = append(handles, GizmoHandle{
+			OriginX: ox, OriginY: oy,
+			X: ox + dx*gizmoHandleLength, Y: oy + dy*gizmoHandleLength,
+			Color:  a.color,
+			Active: mv.gizmoDrag.active && mv.gizmoDrag.axis == axis,
+		})
+	}
+	return handles
+}
+
+// BeginGizmoDrag starts dragging the gizmo handle under the mouse and
+// reports whether there was one.
+func (mv *MapViewer) BeginGizmoDrag(mouseX, mouseY, viewW, viewH float32) bool {
+	model := mv.GetModel(mv.SelectedIdx)
+	if model == nil || !model.Visible || mv.PlayMode {
+		return false
+	}
+	origin := mv.modelWorldPos(model)
+	ox, oy, ok := mv.projectToView(origin, viewW, viewH)
+	if !ok {
+		return false
+	}
+	for axis := range gizmoAxes {
+		dx, dy, _, ok := mv.axisOnScreen(origin, axis, viewW, viewH)
+		if !ok {
+			continue
+		}
+		hx, hy := ox+dx*gizmoHandleLength, oy+dy*gizmoHandleLength
+		if gomath.Hypot(float64(mouseX-hx), float64(mouseY-hy)) <= gizmoHandleRadius+3 {
+			mv.gizmoDrag = gizmoDrag{
+				active: true, axis: axis, startX: mouseX, startY: mouseY,
+				position: model.position, rotation: model.rotation, scale: model.scale,
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// GizmoDragging reports whether a gizmo handle is being dragged.
+func (mv *MapViewer) GizmoDragging() bool {
+	return mv.gizmoDrag.active
+}
+
+// UpdateGizmoDrag applies the drag to the mouse's current position. Moves
+// follow the mouse along the handle's axis; rotations and scales grow with
+// the distance dragged along it. uniform scales all three axes together.
+func (mv *MapViewer) UpdateGizmoDrag(mouseX, mouseY, viewW, viewH float32, uniform bool) {
+	d := &mv.gizmoDrag
+	model := mv.GetModel(mv.SelectedIdx)
+	if !d.active || model == nil {
+		d.active = false
+		return
+	}
+	origin := mv.modelWorldPos(&MapModel{position: d.position})
+	dx, dy, pxPerUnit, ok := mv.axisOnScreen(origin, d.axis, viewW, viewH)
+	if !ok {
+		return
+	}
+	along := (mouseX-d.startX)*dx + (mouseY-d.startY)*dy
+
+	position, rotation, scale := d.position, d.rotation, d.scale
+	switch mv.Gizmo {
+	case GizmoTranslate:
+		// World Y is the negated RSW altitude
+		delta := along / pxPerUnit
+		if d.axis == 1 {
+			delta = -delta
+		}
+		position[d.axis] += delta
+	case GizmoRotate:
+		rotation[d.axis] = float32(gomath.Mod(float64(rotation[d.axis]+along*gizmoDegreesPerPx), 360))
+	case GizmoScale:
+		factor := max(1+along*gizmoScalePerPx, gizmoMinScale)
+		if uniform {
+			for i := range scale {
+				scale[i] *= factor
+			}
+		} else {
+			scale[d.axis] *= factor
+		}
+	}
+	mv.SetModelTransform(mv.SelectedIdx, position, rotation, scale)
+}
+
+// EndGizmoDrag finishes the current gizmo drag.
+func (mv *MapViewer) EndGizmoDrag() {
+	mv.gizmoDrag.active = false
+}
+
+// SetModelTransform places a model, updating its RSW entry.
+func (mv *MapViewer) SetModelTransform(idx int, position, rotation, scale [3]float32) {
+	model := mv.GetModel(idx)
+	if model == nil {
+		return
+	}
+	if model.position == position && model.rotation == rotation && model.scale == scale {
+		return
+	}
+	model.position, model.rotation, model.scale = position, rotation, scale
+	mv.modelRenderer.SetTransform(model.MapModel, position, rotation, scale)
+	if model.rswRef != nil {
+		model.rswRef.Position, model.rswRef.Rotation, model.rswRef.Scale = position, rotation, scale
+	}
+	mv.Modified = true
+}
+
+// DeleteModel removes a model instance from the map and its RSW.
+func (mv *MapViewer) DeleteModel(idx int) {
+	model := mv.GetModel(idx)
+	if model == nil {
+		return
+	}
+	if mv.rsw != nil && model.rswRef != nil {
+		mv.rsw.Objects = slices.DeleteFunc(mv.rsw.Objects, func(obj formats.RSWObject) bool {
+			return obj.Model == model.rswRef
+		})
+	}
+	mv.modelRenderer.RemoveModel(model.MapModel)
+	mv.models = slices.Delete(mv.models, idx, idx+1)
+	mv.animatedModels = slices.DeleteFunc(mv.animatedModels, func(m *MapModel) bool { return m == model })
+	mv.buildModelGroups()
+
+	mv.gizmoDrag.active = false
+	switch {
+	case mv.SelectedIdx == idx:
+		mv.SelectedIdx = -1
+	case mv.SelectedIdx > idx:
+		mv.SelectedIdx--
+	}
+	mv.Modified = true
+}
+
+// DuplicateModel copies a model instance one tile over along X, adding
+// it to the RSW, and returns the copy's index, or -1 if it couldn't be
+// built.
+func (mv *MapViewer) DuplicateModel(idx int) int {
+	model := mv.GetModel(idx)
+	if model == nil || model.rsm == nil || model.rswRef == nil || mv.texLoader == nil {
+		return -1
+	}
+	ref := *model.rswRef
+	ref.Position[0] += max(mv.terrainTileZoom, 1)
+
+	dup := mv.buildMapModel(model.rsm, &ref, mv.texLoader)
+	if dup == nil {
+		return -1
+	}
+	for _, m := range mv.models {
+		dup.instanceID = max(dup.instanceID, m.instanceID+1)
+	}
+	if mv.rsw != nil {
+		mv.rsw.Objects = append(mv.rsw.Objects, formats.RSWObject{Type: formats.RSWObjectModel, Model: &ref})
+	}
+	mv.models = append(mv.models, dup)
+	if dup.Animated() {
+		mv.animatedModels = append(mv.animatedModels, dup)
+	}
+	mv.buildModelGroups()
+	mv.Modified = true
+	return len(mv.models) - 1
+}
+
+// EncodeRSW serializes the map's RSW with its edits, rebuilding its
+// quadtree around the models where they now are. Models that weren't
+// loaded count as a point at their position.
+func (mv *MapViewer) EncodeRSW() ([]byte, error) {
+	if mv.rsw == nil {
+		return nil, errors.New("no RSW loaded")
+	}
+	if mv.gnd != nil && mv.rsw.Version.AtLeast(2, 1) {
+		loaded := make(map[*formats.RSWModel]*MapModel, len(mv.models))
+		for _, m := range mv.models {
+			if m != nil {
+				loaded[m.rswRef] = m
+			}
+		}
+		var boxes [][2][3]float32
+		for _, ref := range mv.rsw.GetModels() {
+			m, ok := loaded[ref]
+			if !ok {
+				boxes = append(boxes, [2][3]float32{ref.Position, ref.Position})
+				continue
+			}
+			// World space is offset by half the map on X/Z and has Y up
+			b := m.Bounds()
+			boxes = append(boxes, [2][3]float32{
+				{b.Min.X - mv.mapWidth/2, -b.Max.Y, b.Min.Z - mv.mapHeight/2},
+				{b.Max.X - mv.mapWidth/2, -b.Min.Y, b.Max.Z - mv.mapHeight/2},
+			})
+		}
+		mv.rsw.BuildQuadtree(mv.gnd, boxes)
+	}
+	data, err := formats.EncodeRSW(mv.rsw)
+	if err != nil {
+		return nil, fmt.Errorf("encode RSW: %w", err)
+	}
+	return data, nil
+}
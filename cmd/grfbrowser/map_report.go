@@ -0,0 +1,461 @@
+// Map statistics and validation report for GRF Browser.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+
+	"github.com/Faultbox/midgard-ro/pkg/formats"
+)
+
+// Report severity levels.
+const (
+	ReportSeverityInfo    = "info"
+	ReportSeverityWarning = "warning"
+	ReportSeverityError   = "error"
+)
+
+// MapReport summarizes GND/RSW/GAT statistics and validation findings.
+// Serialized as JSON so map authors can diff reports in CI.
+type MapReport struct {
+	Map       string `json:"map"`
+	Generated string `json:"generated"`
+
+	Textures  []TextureUsage     `json:"textures"`
+	Lightmaps LightmapCoverage   `json:"lightmaps"`
+	Walkable  WalkabilityReport  `json:"walkable"`
+	Models    ModelReferenceInfo `json:"models"`
+	Water     WaterReport        `json:"water"`
+	Issues    []ReportIssue      `json:"issues"`
+}
+
+// TextureUsage counts surfaces referencing a GND texture.
+type TextureUsage struct {
+	Index    int    `json:"index"`
+	Name     string `json:"name"`
+	Surfaces int    `json:"surfaces"`
+	Missing  bool   `json:"missing"`
+}
+
+// LightmapCoverage describes how many surfaces reference a valid lightmap.
+type LightmapCoverage struct {
+	Lightmaps       int     `json:"lightmaps"`
+	Surfaces        int     `json:"surfaces"`
+	Covered         int     `json:"covered"`
+	InvalidRefs     int     `json:"invalidRefs"`
+	CoveragePercent float32 `json:"coveragePercent"`
+}
+
+// WalkabilityReport describes connected walkable regions of the GAT.
+type WalkabilityReport struct {
+	WalkableCells    int   `json:"walkableCells"`
+	Islands          int   `json:"islands"`
+	LargestIsland    int   `json:"largestIsland"`
+	UnreachableCells int   `json:"unreachableCells"`
+	IslandSizes      []int `json:"islandSizes,omitempty"`
+}
+
+// ModelReferenceInfo lists RSW model references and missing RSM files.
+type ModelReferenceInfo struct {
+	Instances    int      `json:"instances"`
+	UniqueModels int      `json:"uniqueModels"`
+	MissingFiles []string `json:"missingFiles,omitempty"`
+}
+
+// WaterReport holds water settings relative to the terrain altitude.
+type WaterReport struct {
+	Level       float32 `json:"level"`
+	Type        int32   `json:"type"`
+	MinAltitude float32 `json:"minAltitude"`
+	MaxAltitude float32 `json:"maxAltitude"`
+	WaterCells  int     `json:"waterCells"`
+}
+
+// ReportIssue is a single validation finding.
+type ReportIssue struct {
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// buildMapReport computes statistics and validation findings for a map.
+// gnd and gat may be nil when the files could not be loaded.
+// exists reports whether an archive path is present.
+func buildMapReport(name string, rsw *formats.RSW, gnd *formats.GND, gat *formats.GAT, exists func(string) bool) *MapReport {
+	report := &MapReport{
+		Map:       name,
+		Generated: time.Now().Format(time.RFC3339),
+	}
+
+	if gnd == nil {
+		report.addIssue(ReportSeverityError, "GND file could not be loaded")
+	} else {
+		report.addParseWarnings("GND", gnd.Warnings)
+		report.collectTextures(gnd, exists)
+		report.collectLightmaps(gnd)
+	}
+
+	if gat == nil {
+		report.addIssue(ReportSeverityError, "GAT file could not be loaded")
+	} else {
+		report.collectWalkability(gat)
+	}
+
+	if rsw != nil {
+		report.addParseWarnings("RSW", rsw.Warnings)
+		report.collectModels(rsw, exists)
+		report.collectWater(rsw, gnd, gat)
+	}
+
+	return report
+}
+
+// addIssue appends a validation finding.
+func (r *MapReport) addIssue(severity, format string, args ...any) {
+	r.Issues = append(r.Issues, ReportIssue{
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// addParseWarnings reports what the parser skipped in a damaged file.
+func (r *MapReport) addParseWarnings(file string, warnings []formats.Warning) {
+	for _, w := range warnings {
+		r.addIssue(ReportSeverityWarning, "%s damaged, %s", file, w)
+	}
+}
+
+// collectTextures counts surfaces per texture and flags missing texture files.
+func (r *MapReport) collectTextures(gnd *formats.GND, exists func(string) bool) {
+	counts := make([]int, len(gnd.Textures))
+	for _, surf := range gnd.Surfaces {
+		id := int(surf.TextureID)
+		if id >= 0 && id < len(counts) {
+			counts[id]++
+		}
+	}
+
+	r.Textures = make([]TextureUsage, len(gnd.Textures))
+	for i, name := range gnd.Textures {
+		missing := !exists("data/texture/" + name)
+		r.Textures[i] = TextureUsage{
+			Index:    i,
+			Name:     euckrToUTF8(name),
+			Surfaces: counts[i],
+			Missing:  missing,
+		}
+		if missing {
+			r.addIssue(ReportSeverityError, "missing texture: %s", euckrToUTF8(name))
+		} else if counts[i] == 0 {
+			r.addIssue(ReportSeverityInfo, "unused texture: %s", euckrToUTF8(name))
+		}
+	}
+}
+
+// collectLightmaps computes the share of surfaces with a valid lightmap reference.
+func (r *MapReport) collectLightmaps(gnd *formats.GND) {
+	lm := LightmapCoverage{
+		Lightmaps: len(gnd.Lightmaps),
+		Surfaces:  len(gnd.Surfaces),
+	}
+	for _, surf := range gnd.Surfaces {
+		id := int(surf.LightmapID)
+		if id >= 0 && id < len(gnd.Lightmaps) {
+			lm.Covered++
+		} else {
+			lm.InvalidRefs++
+		}
+	}
+	if lm.Surfaces > 0 {
+		lm.CoveragePercent = float32(lm.Covered) * 100 / float32(lm.Surfaces)
+	}
+	r.Lightmaps = lm
+
+	if lm.InvalidRefs > 0 {
+		r.addIssue(ReportSeverityWarning, "%d surfaces reference an invalid lightmap", lm.InvalidRefs)
+	}
+}
+
+// collectWalkability finds connected walkable islands in the GAT grid.
+// Cells outside the largest island are reported as unreachable.
+func (r *MapReport) collectWalkability(gat *formats.GAT) {
+	sizes := walkableIslands(gat)
+	wr := WalkabilityReport{Islands: len(sizes)}
+	for _, s := range sizes {
+		wr.WalkableCells += s
+	}
+	if len(sizes) > 0 {
+		wr.LargestIsland = sizes[0]
+		wr.UnreachableCells = wr.WalkableCells - sizes[0]
+	}
+	if len(sizes) > 1 {
+		wr.IslandSizes = sizes
+	}
+	r.Walkable = wr
+
+	if wr.UnreachableCells > 0 {
+		r.addIssue(ReportSeverityWarning, "%d walkable cells in %d islands are unreachable from the main area",
+			wr.UnreachableCells, wr.Islands-1)
+	}
+}
+
+// collectModels counts model references and flags missing RSM files.
+func (r *MapReport) collectModels(rsw *formats.RSW, exists func(string) bool) {
+	models := rsw.GetModels()
+	unique := make(map[string]bool)
+	for _, m := range models {
+		unique[m.ModelName] = true
+	}
+
+	var missing []string
+	for name := range unique {
+		if !exists("data/model/" + name) {
+			missing = append(missing, euckrToUTF8(name))
+		}
+	}
+	sort.Strings(missing)
+
+	r.Models = ModelReferenceInfo{
+		Instances:    len(models),
+		UniqueModels: len(unique),
+		MissingFiles: missing,
+	}
+	for _, name := range missing {
+		r.addIssue(ReportSeverityError, "missing model: %s", name)
+	}
+}
+
+// collectWater checks the water level against terrain altitude and GAT water cells.
+func (r *MapReport) collectWater(rsw *formats.RSW, gnd *formats.GND, gat *formats.GAT) {
+	wr := WaterReport{
+		Level: rsw.Water.Level,
+		Type:  rsw.Water.Type,
+	}
+	if gat != nil {
+		counts := gat.CountByType()
+		wr.WaterCells = counts[formats.GATWater] + counts[formats.GATWalkableWater]
+	}
+	if gnd != nil {
+		wr.MinAltitude, wr.MaxAltitude = gnd.GetAltitudeRange()
+	}
+	r.Water = wr
+
+	if gnd == nil {
+		return
+	}
+
+	// RO uses negative Y for height: larger altitude values are lower terrain.
+	// Water is visible where the terrain sits below the water plane.
+	switch {
+	case wr.WaterCells > 0 && wr.Level >= wr.MaxAltitude:
+		r.addIssue(ReportSeverityWarning, "GAT has %d water cells but water level %.2f is below all terrain",
+			wr.WaterCells, wr.Level)
+	case wr.Level <= wr.MinAltitude && wr.MinAltitude != wr.MaxAltitude:
+		r.addIssue(ReportSeverityWarning, "water level %.2f submerges the entire map", wr.Level)
+	}
+	if wr.Type < 0 || wr.Type > 7 {
+		r.addIssue(ReportSeverityWarning, "unusual water type %d", wr.Type)
+	}
+}
+
+// walkableIslands returns the sizes of 8-connected walkable regions, largest first.
+func walkableIslands(gat *formats.GAT) []int {
+	width := int(gat.Width)
+	height := int(gat.Height)
+	visited := make([]bool, width*height)
+
+	var sizes []int
+	var stack []int
+	for start := range gat.Cells {
+		if visited[start] || !gat.Cells[start].Type.IsWalkable() {
+			continue
+		}
+
+		size := 0
+		visited[start] = true
+		stack = append(stack[:0], start)
+		for len(stack) > 0 {
+			idx := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			size++
+
+			x, y := idx%width, idx/width
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					nx, ny := x+dx, y+dy
+					if nx < 0 || ny < 0 || nx >= width || ny >= height {
+						continue
+					}
+					n := ny*width + nx
+					if !visited[n] && gat.Cells[n].Type.IsWalkable() {
+						visited[n] = true
+						stack = append(stack, n)
+					}
+				}
+			}
+		}
+		sizes = append(sizes, size)
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+	return sizes
+}
+
+// generateMapReport loads GND/GAT for the previewed RSW and builds the report.
+func (app *App) generateMapReport() {
+	if app.previewRSW == nil || app.archive == nil {
+		return
+	}
+	rsw := app.previewRSW
+	gnd, gat := app.loadMapGrids(rsw)
+	name := strings.TrimSuffix(filepath.Base(app.selectedOriginalPath), filepath.Ext(app.selectedOriginalPath))
+	app.mapReport = buildMapReport(name, rsw, gnd, gat, app.archive.Contains)
+}
+
+// loadMapGrids loads the GND and GAT files an RSW refers to. Either is nil
+// when it could not be loaded.
+func (app *App) loadMapGrids(rsw *formats.RSW) (*formats.GND, *formats.GAT) {
+	var gnd *formats.GND
+	if data, err := app.readFile("data/" + rsw.GndFile); err == nil {
+		if gnd, err = formats.ParseGND(data); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing GND: %v\n", err)
+		}
+	}
+
+	gatFile := rsw.GatFile
+	if gatFile == "" && strings.HasSuffix(strings.ToLower(rsw.GndFile), ".gnd") {
+		gatFile = rsw.GndFile[:len(rsw.GndFile)-4] + ".gat"
+	}
+	var gat *formats.GAT
+	if data, err := app.readFile("data/" + gatFile); err == nil {
+		if gat, err = formats.ParseGAT(data); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing GAT: %v\n", err)
+		}
+	}
+	return gnd, gat
+}
+
+// exportMapReport writes the current map report as JSON to the screenshot directory.
+func (app *App) exportMapReport() {
+	if app.mapReport == nil {
+		return
+	}
+
+	reportPath, err := writeMapReport(app.mapReport, app.screenshotDir)
+	if err != nil {
+		app.showNotification(fmt.Sprintf("Report export failed: %v", err))
+		return
+	}
+
+	app.showNotification("Report saved: " + filepath.Base(reportPath))
+	fmt.Printf("Map report saved: %s\n", reportPath)
+}
+
+// writeMapReport writes a report as <map>-report.json in dir and returns
+// the file's path.
+func writeMapReport(report *MapReport, dir string) (string, error) {
+	jsonData, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	reportPath := filepath.Join(dir, fmt.Sprintf("%s-report.json", report.Map))
+	if err := os.WriteFile(reportPath, jsonData, 0644); err != nil {
+		return "", err
+	}
+	return reportPath, nil
+}
+
+// renderMapReport renders the map statistics and validation report.
+func (app *App) renderMapReport() {
+	if app.mapReport == nil {
+		app.generateMapReport()
+		if app.mapReport == nil {
+			imgui.TextDisabled("Report unavailable")
+			return
+		}
+	}
+	report := app.mapReport
+
+	if imgui.Button("Export JSON") {
+		app.exportMapReport()
+	}
+	imgui.SameLine()
+	if imgui.Button("Refresh##report") {
+		app.generateMapReport()
+		report = app.mapReport
+	}
+	imgui.Separator()
+
+	// Issues first so problems are visible without scrolling
+	if imgui.TreeNodeExStrV(fmt.Sprintf("Issues (%d)", len(report.Issues)), imgui.TreeNodeFlagsDefaultOpen) {
+		if len(report.Issues) == 0 {
+			imgui.TextColored(imgui.NewVec4(0.5, 1, 0.5, 1), "No issues found")
+		}
+		for _, issue := range report.Issues {
+			imgui.TextColored(reportSeverityColor(issue.Severity), fmt.Sprintf("[%s] %s", issue.Severity, issue.Message))
+		}
+		imgui.TreePop()
+	}
+
+	imgui.Separator()
+
+	if imgui.TreeNodeExStrV("Lightmaps", imgui.TreeNodeFlagsDefaultOpen) {
+		lm := report.Lightmaps
+		imgui.Text(fmt.Sprintf("Lightmaps: %d", lm.Lightmaps))
+		imgui.Text(fmt.Sprintf("Coverage: %d/%d surfaces (%.1f%%)", lm.Covered, lm.Surfaces, lm.CoveragePercent))
+		imgui.TreePop()
+	}
+
+	if imgui.TreeNodeExStrV("Walkability", imgui.TreeNodeFlagsDefaultOpen) {
+		wr := report.Walkable
+		imgui.Text(fmt.Sprintf("Walkable cells: %d", wr.WalkableCells))
+		imgui.Text(fmt.Sprintf("Islands: %d (largest: %d)", wr.Islands, wr.LargestIsland))
+		imgui.Text(fmt.Sprintf("Unreachable cells: %d", wr.UnreachableCells))
+		imgui.TreePop()
+	}
+
+	if imgui.TreeNodeExStrV("Water", imgui.TreeNodeFlagsDefaultOpen) {
+		wr := report.Water
+		imgui.Text(fmt.Sprintf("Level: %.2f (type %d)", wr.Level, wr.Type))
+		imgui.Text(fmt.Sprintf("Terrain altitude: %.1f to %.1f", wr.MinAltitude, wr.MaxAltitude))
+		imgui.Text(fmt.Sprintf("GAT water cells: %d", wr.WaterCells))
+		imgui.TreePop()
+	}
+
+	if imgui.TreeNodeExStrV("Models", imgui.TreeNodeFlagsDefaultOpen) {
+		mr := report.Models
+		imgui.Text(fmt.Sprintf("Instances: %d | Unique RSM: %d | Missing: %d",
+			mr.Instances, mr.UniqueModels, len(mr.MissingFiles)))
+		imgui.TreePop()
+	}
+
+	if imgui.TreeNodeExStrV(fmt.Sprintf("Surfaces per Texture (%d)", len(report.Textures)), imgui.TreeNodeFlagsNone) {
+		for _, tex := range report.Textures {
+			label := fmt.Sprintf("%d: %s - %d surfaces", tex.Index, tex.Name, tex.Surfaces)
+			if tex.Missing {
+				imgui.TextColored(reportSeverityColor(ReportSeverityError), label+" (missing)")
+			} else {
+				imgui.Text(label)
+			}
+		}
+		imgui.TreePop()
+	}
+}
+
+// reportSeverityColor returns the display color for a severity level.
+func reportSeverityColor(severity string) imgui.Vec4 {
+	switch severity {
+	case ReportSeverityError:
+		return imgui.NewVec4(1, 0.4, 0.4, 1)
+	case ReportSeverityWarning:
+		return imgui.NewVec4(1, 0.8, 0.3, 1)
+	default:
+		return imgui.NewVec4(0.7, 0.7, 0.7, 1)
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"image/png"
 	gomath "math"
 	"os"
+	"slices"
 	"sort"
+	"time"
 	"unsafe"
 
 	"github.com/go-gl/gl/v4.1-core/gl"
@@ -16,27 +18,22 @@ import (
 	"github.com/Faultbox/midgard-ro/internal/engine/camera"
 	"github.com/Faultbox/midgard-ro/internal/engine/character"
 	"github.com/Faultbox/midgard-ro/internal/engine/debug"
+	"github.com/Faultbox/midgard-ro/internal/engine/glstate"
 	"github.com/Faultbox/midgard-ro/internal/engine/lighting"
 	rsmmodel "github.com/Faultbox/midgard-ro/internal/engine/model"
 	"github.com/Faultbox/midgard-ro/internal/engine/picking"
+	"github.com/Faultbox/midgard-ro/internal/engine/scene"
+	sceneshaders "github.com/Faultbox/midgard-ro/internal/engine/scene/shaders"
 	"github.com/Faultbox/midgard-ro/internal/engine/shader"
 	"github.com/Faultbox/midgard-ro/internal/engine/shadow"
 	"github.com/Faultbox/midgard-ro/internal/engine/sprite"
 	"github.com/Faultbox/midgard-ro/internal/engine/terrain"
-	"github.com/Faultbox/midgard-ro/internal/engine/water"
+	"github.com/Faultbox/midgard-ro/internal/engine/texfilter"
 	"github.com/Faultbox/midgard-ro/internal/game/entity"
 	"github.com/Faultbox/midgard-ro/pkg/formats"
 	"github.com/Faultbox/midgard-ro/pkg/math"
 )
 
-// pointLightData stores extracted point light info for GPU upload.
-type pointLightData struct {
-	Position  [3]float32
-	Color     [3]float32
-	Range     float32
-	Intensity float32
-}
-
 // MapDiagnostics tracks loading statistics for debugging.
 type MapDiagnostics struct {
 	// RSW model stats
@@ -63,25 +60,29 @@ type MapDiagnostics struct {
 
 	// Failure details
 	FailedModels []string
+
+	// What the parsers skipped in damaged files, by file
+	ParseWarnings []string
+}
+
+// addParseWarnings records what the parser skipped in a damaged file.
+func (d *MapDiagnostics) addParseWarnings(file string, warnings []formats.Warning) {
+	for _, w := range warnings {
+		d.ParseWarnings = append(d.ParseWarnings, file+": "+w.String())
+	}
 }
 
-// MapModel represents a placed RSM model in the map.
+// MapModel represents a placed RSM model in the map: the scene model it
+// is drawn as, with what the browser shows and edits of it.
 type MapModel struct {
-	vao        uint32
-	vbo        uint32
-	ebo        uint32
-	indexCount int32
-	textures   []uint32
-	texGroups  []rsmmodel.TextureGroup
-	position   [3]float32
-	rotation   [3]float32
-	scale      [3]float32
+	*scene.MapModel // Drawn by the model renderer; its Visible is toggled here
+
+	position [3]float32
+	rotation [3]float32
+	scale    [3]float32
 	// Debug info
-	modelName  string
 	bbox       [6]float32 // minX, minY, minZ, maxX, maxY, maxZ (after centering)
 	instanceID int        // Unique instance ID for this model placement
-	// Visibility and selection
-	Visible bool // Whether this instance is rendered
 	// Stats for debugging
 	totalFaces   int
 	twoSideFaces int
@@ -89,11 +90,9 @@ type MapModel struct {
 	rsmVersion string
 	nodeCount  int
 	nodes      []rsmmodel.NodeDebugInfo
-	// Animation support
-	isAnimated bool              // Whether this model has keyframe animation
-	rsm        *formats.RSM      // Reference to RSM for animation rebuild
-	rswRef     *formats.RSWModel // Reference to RSW placement info
-	animLength int32             // Animation length in ms
+	// Map editing
+	rsm    *formats.RSM      // Reference to RSM for duplication
+	rswRef *formats.RSWModel // Reference to RSW placement info, updated by edits
 }
 
 // ModelGroup represents a group of model instances sharing the same RSM.
@@ -280,44 +279,16 @@ type MapViewer struct {
 	width        int32
 	height       int32
 
-	// Terrain shader
-	terrainProgram  uint32
-	locViewProj     int32
-	locLightDir     int32
-	locAmbient      int32
-	locDiffuse      int32
-	locTexture      int32
-	locLightmap     int32
-	locBrightness   int32
-	locLightOpacity int32
-	locFogUse       int32
-	locFogNear      int32
-	locFogFar       int32
-	locFogColor     int32
-
-	// Model shader
-	modelProgram     uint32
-	locModelMVP      int32
-	locModelLightDir int32
-	locModelAmbient  int32
-	locModelDiffuse  int32
-	locModelTexture  int32
-	locModelFogUse   int32
-	locModelFogNear  int32
-	locModelFogFar   int32
-	locModelFogColor int32
-
-	// Terrain mesh
-	terrainVAO    uint32
-	terrainVBO    uint32
-	terrainEBO    uint32
-	terrainGroups []terrain.TextureGroup
-
-	// Ground textures and lightmap
-	groundTextures   map[int]uint32
-	fallbackTex      uint32
-	lightmapAtlasTex uint32                 // GPU texture for lightmap atlas
-	lightmapAtlas    *terrain.LightmapAtlas // Lightmap atlas metadata for UV calculation
+	// The map is drawn with the client's scene renderers. The terrain also
+	// loads each ground texture on its own, so the texture array can be
+	// compared with one bind and draw per texture group.
+	terrainRenderer *scene.TerrainRenderer
+	modelRenderer   *scene.ModelRenderer
+	waterRenderer   *scene.WaterRenderer
+	spriteRenderer  *scene.SpriteRenderer
+	textures        *scene.TextureStreamer // Model textures
+	terrainTimer    gpuTimer
+	fallbackTex     uint32
 
 	// Placed models
 	models      []*MapModel
@@ -326,6 +297,15 @@ type MapViewer struct {
 	SelectedIdx int          // Currently selected model index (-1 = none)
 	ModelFilter string       // Filter string for model names
 
+	// Map editing: models are transformed, duplicated and deleted in the
+	// loaded RSW, which SaveRSW writes back out
+	rsw       *formats.RSW
+	gnd       *formats.GND // For rebuilding the RSW's quadtree on save
+	texLoader func(string) ([]byte, error)
+	Gizmo     GizmoMode // Public for UI toggle
+	gizmoDrag gizmoDrag
+	Modified  bool // The RSW has unsaved edits
+
 	// Debug options
 	ForceAllTwoSided bool // Force all faces to render as two-sided (debug)
 
@@ -345,15 +325,7 @@ type MapViewer struct {
 	WalkThroughBlocked bool // Allow walking through blocked cells
 
 	// Player character (Play mode)
-	Player            *PlayerCharacter
-	spriteProgram     uint32 // Shader for billboard sprites
-	locSpriteVP       int32  // viewProj uniform
-	locSpritePos      int32  // world position uniform
-	locSpriteSize     int32  // sprite size uniform
-	locSpriteCamRight int32  // camera right vector for billboard
-	locSpriteCamUp    int32  // camera up vector for billboard
-	locSpriteTex      int32  // texture uniform
-	locSpriteTint     int32  // color tint uniform
+	Player *PlayerCharacter
 
 	// GAT data for terrain collision
 	GAT *formats.GAT
@@ -379,22 +351,6 @@ type MapViewer struct {
 	terrainTilesX    int         // Number of tiles in X
 	terrainTilesZ    int         // Number of tiles in Z
 
-	// Water rendering (Stage 4 - ADR-014)
-	waterProgram   uint32
-	waterVAO       uint32
-	waterVBO       uint32
-	waterLevel     float32 // From RSW.Water.Level
-	hasWater       bool    // Whether map has water
-	locWaterMVP    int32
-	locWaterColor  int32
-	locWaterTime   int32
-	locWaterTex    int32
-	waterTime      float32  // Animation time
-	waterTextures  []uint32 // Animated water texture frames
-	waterAnimSpeed float32  // Animation speed from RSW
-	waterFrame     int      // Current animation frame
-	useWaterTex    bool     // Whether we have loaded water textures
-
 	// Model animation (Stage 1 - ADR-014)
 	modelAnimTime    float32     // Current animation time in ms
 	modelAnimPlaying bool        // Whether model animations are playing
@@ -407,41 +363,18 @@ type MapViewer struct {
 	FogColor   [3]float32
 
 	// Shadow mapping (Enhanced Graphics)
-	shadowMap                *shadow.Map
-	shadowProgram            uint32
-	ShadowsEnabled           bool  // Public for UI toggle
-	ShadowResolution         int32 // Shadow map resolution (default 2048)
-	lightViewProj            math.Mat4
-	locShadowLightViewProj   int32 // Shadow shader uniform
-	locShadowModel           int32 // Shadow shader model matrix
-	locTerrainLightViewProj  int32 // Terrain shader shadow uniform
-	locTerrainShadowMap      int32 // Terrain shader shadow map texture
-	locTerrainShadowsEnabled int32 // Terrain shader shadow toggle
-	locModelLightViewProj    int32 // Model shader shadow uniform
-	locModelModel            int32 // Model shader model matrix
-	locModelShadowMap        int32 // Model shader shadow map texture
-	locModelShadowsEnabled   int32 // Model shader shadow toggle
+	shadowMap              *shadow.Map
+	shadowProgram          uint32
+	ShadowsEnabled         bool  // Public for UI toggle
+	ShadowResolution       int32 // Shadow map resolution (default 2048)
+	lightViewProj          math.Mat4
+	locShadowLightViewProj int32 // Shadow shader uniform
+	locShadowModel         int32 // Shadow shader model matrix
 
 	// Point lights from RSW (Enhanced Graphics Phase 3)
-	pointLights         []pointLightData // Extracted from RSW
-	PointLightsEnabled  bool             // Public for UI toggle
-	PointLightIntensity float32          // Global intensity multiplier
-
-	// Terrain shader point light uniforms
-	locTerrainPointLightPositions   int32
-	locTerrainPointLightColors      int32
-	locTerrainPointLightRanges      int32
-	locTerrainPointLightIntensities int32
-	locTerrainPointLightCount       int32
-	locTerrainPointLightsEnabled    int32
-
-	// Model shader point light uniforms
-	locModelPointLightPositions   int32
-	locModelPointLightColors      int32
-	locModelPointLightRanges      int32
-	locModelPointLightIntensities int32
-	locModelPointLightCount       int32
-	locModelPointLightsEnabled    int32
+	pointLights         []scene.PointLight // Extracted from RSW
+	PointLightsEnabled  bool               // Public for UI toggle
+	PointLightIntensity float32            // Global intensity multiplier
 
 	// Selection bounding box rendering
 	bboxProgram  uint32
@@ -469,16 +402,15 @@ type MapViewer struct {
 // NewMapViewer creates a new 3D map viewer.
 func NewMapViewer(width, height int32) (*MapViewer, error) {
 	mv := &MapViewer{
-		width:          width,
-		height:         height,
-		groundTextures: make(map[int]uint32),
-		OrbitCam:       camera.NewOrbitCamera(),
-		FollowCam:      camera.NewThirdPersonCamera(),
-		MoveSpeed:      5.0,
-		MaxModels:      1500, // Default model limit
-		Brightness:     1.0,  // Default terrain brightness multiplier
-		ModelScale:     1.0,  // Default model scale (1.0 = original size)
-		SelectedIdx:    -1,   // No model selected initially
+		width:       width,
+		height:      height,
+		OrbitCam:    camera.NewOrbitCamera(),
+		FollowCam:   camera.NewThirdPersonCamera(),
+		MoveSpeed:   5.0,
+		MaxModels:   1500, // Default model limit
+		Brightness:  1.0,  // Default terrain brightness multiplier
+		ModelScale:  1.0,  // Default model scale (1.0 = original size)
+		SelectedIdx: -1,   // No model selected initially
 		// Default lighting (will be overwritten by RSW data)
 		lightDir:     [3]float32{0.5, 0.866, 0.0}, // 60 degrees elevation
 		ambientColor: [3]float32{0.3, 0.3, 0.3},
@@ -498,22 +430,14 @@ func NewMapViewer(width, height int32) (*MapViewer, error) {
 		return nil, fmt.Errorf("creating framebuffer: %w", err)
 	}
 
-	if err := mv.createTerrainShader(); err != nil {
-		return nil, fmt.Errorf("creating terrain shader: %w", err)
-	}
-
-	if err := mv.createModelShader(); err != nil {
-		return nil, fmt.Errorf("creating model shader: %w", err)
+	if err := mv.createRenderers(); err != nil {
+		return nil, fmt.Errorf("creating renderers: %w", err)
 	}
 
 	if err := mv.createBboxShader(); err != nil {
 		return nil, fmt.Errorf("creating bbox shader: %w", err)
 	}
 
-	if err := mv.createSpriteShader(); err != nil {
-		return nil, fmt.Errorf("creating sprite shader: %w", err)
-	}
-
 	if err := mv.createShadowShader(); err != nil {
 		return nil, fmt.Errorf("creating shadow shader: %w", err)
 	}
@@ -547,6 +471,7 @@ func (mv *MapViewer) createFramebuffer() error {
 	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
 	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
 	gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, mv.colorTexture, 0)
+	trackRenderTarget(mv.colorTexture, "map view", mv.width, mv.height)
 
 	// Create depth renderbuffer
 	gl.GenRenderbuffers(1, &mv.depthRBO)
@@ -581,94 +506,35 @@ func (mv *MapViewer) Resize(width, height int32) {
 	// Resize color texture
 	gl.BindTexture(gl.TEXTURE_2D, mv.colorTexture)
 	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA8, mv.width, mv.height, 0, gl.RGBA, gl.UNSIGNED_BYTE, nil)
+	trackRenderTarget(mv.colorTexture, "map view", mv.width, mv.height)
 
 	// Resize depth renderbuffer
 	gl.BindRenderbuffer(gl.RENDERBUFFER, mv.depthRBO)
 	gl.RenderbufferStorage(gl.RENDERBUFFER, gl.DEPTH_COMPONENT24, mv.width, mv.height)
 }
 
-// createTerrainShader compiles the terrain shader program.
-func (mv *MapViewer) createTerrainShader() error {
-	program, err := shader.CompileProgram(shaders.TerrainVertexShader, shaders.TerrainFragmentShader)
-	if err != nil {
-		return fmt.Errorf("terrain shader: %w", err)
+// createRenderers creates the scene renderers the map is drawn with.
+func (mv *MapViewer) createRenderers() error {
+	var err error
+	if mv.terrainRenderer, err = scene.NewTerrainRenderer(); err != nil {
+		return err
 	}
-	mv.terrainProgram = program
+	mv.terrainRenderer.CompareTextures = true
 
-	// Get uniform locations
-	mv.locViewProj = shader.GetUniform(program, "uViewProj")
-	mv.locLightDir = shader.GetUniform(program, "uLightDir")
-	mv.locAmbient = shader.GetUniform(program, "uAmbient")
-	mv.locDiffuse = shader.GetUniform(program, "uDiffuse")
-	mv.locTexture = shader.GetUniform(program, "uTexture")
-	mv.locLightmap = shader.GetUniform(program, "uLightmap")
-	mv.locBrightness = shader.GetUniform(program, "uBrightness")
-	mv.locLightOpacity = shader.GetUniform(program, "uLightOpacity")
-	mv.locFogUse = shader.GetUniform(program, "uFogUse")
-	mv.locFogNear = shader.GetUniform(program, "uFogNear")
-	mv.locFogFar = shader.GetUniform(program, "uFogFar")
-	mv.locFogColor = shader.GetUniform(program, "uFogColor")
-
-	// Shadow mapping uniforms
-	mv.locTerrainLightViewProj = shader.GetUniform(program, "uLightViewProj")
-	mv.locTerrainShadowMap = shader.GetUniform(program, "uShadowMap")
-	mv.locTerrainShadowsEnabled = shader.GetUniform(program, "uShadowsEnabled")
-
-	// Point light uniforms
-	mv.locTerrainPointLightPositions = shader.GetUniform(program, "uPointLightPositions")
-	mv.locTerrainPointLightColors = shader.GetUniform(program, "uPointLightColors")
-	mv.locTerrainPointLightRanges = shader.GetUniform(program, "uPointLightRanges")
-	mv.locTerrainPointLightIntensities = shader.GetUniform(program, "uPointLightIntensities")
-	mv.locTerrainPointLightCount = shader.GetUniform(program, "uPointLightCount")
-	mv.locTerrainPointLightsEnabled = shader.GetUniform(program, "uPointLightsEnabled")
-
-	return nil
-}
-
-// createModelShader compiles the RSM model shader program.
-func (mv *MapViewer) createModelShader() error {
-	program, err := shader.CompileProgram(shaders.ModelVertexShader, shaders.ModelFragmentShader)
-	if err != nil {
-		return fmt.Errorf("model shader: %w", err)
+	mv.textures = scene.NewTextureStreamer()
+	if mv.modelRenderer, err = scene.NewModelRenderer(mv.textures); err != nil {
+		return err
 	}
-	mv.modelProgram = program
-
-	// Get uniform locations
-	mv.locModelMVP = shader.GetUniform(program, "uMVP")
-	mv.locModelLightDir = shader.GetUniform(program, "uLightDir")
-	mv.locModelAmbient = shader.GetUniform(program, "uAmbient")
-	mv.locModelDiffuse = shader.GetUniform(program, "uDiffuse")
-	mv.locModelTexture = shader.GetUniform(program, "uTexture")
-	mv.locModelFogUse = shader.GetUniform(program, "uFogUse")
-	mv.locModelFogNear = shader.GetUniform(program, "uFogNear")
-	mv.locModelFogFar = shader.GetUniform(program, "uFogFar")
-	mv.locModelFogColor = shader.GetUniform(program, "uFogColor")
-
-	// Shadow mapping uniforms
-	mv.locModelLightViewProj = shader.GetUniform(program, "uLightViewProj")
-	mv.locModelModel = shader.GetUniform(program, "uModel")
-	mv.locModelShadowMap = shader.GetUniform(program, "uShadowMap")
-	mv.locModelShadowsEnabled = shader.GetUniform(program, "uShadowsEnabled")
-
-	// Point light uniforms
-	mv.locModelPointLightPositions = shader.GetUniform(program, "uPointLightPositions")
-	mv.locModelPointLightColors = shader.GetUniform(program, "uPointLightColors")
-	mv.locModelPointLightRanges = shader.GetUniform(program, "uPointLightRanges")
-	mv.locModelPointLightIntensities = shader.GetUniform(program, "uPointLightIntensities")
-	mv.locModelPointLightCount = shader.GetUniform(program, "uPointLightCount")
-	mv.locModelPointLightsEnabled = shader.GetUniform(program, "uPointLightsEnabled")
-
-	// Compile water shader
-	if err := mv.compileWaterShader(); err != nil {
-		return fmt.Errorf("water shader: %w", err)
+	if mv.waterRenderer, err = scene.NewWaterRenderer(); err != nil {
+		return err
 	}
-
-	return nil
+	mv.spriteRenderer, err = scene.NewSpriteRenderer()
+	return err
 }
 
 // createShadowShader compiles the shadow pass shader program.
 func (mv *MapViewer) createShadowShader() error {
-	program, err := shader.CompileProgram(shaders.ShadowVertexShader, shaders.ShadowFragmentShader)
+	program, err := shader.CompileProgram(sceneshaders.ShadowVertexShader, sceneshaders.ShadowFragmentShader)
 	if err != nil {
 		return fmt.Errorf("shadow shader: %w", err)
 	}
@@ -701,6 +567,7 @@ func (mv *MapViewer) createBboxShader() error {
 	gl.BindBuffer(gl.ARRAY_BUFFER, mv.bboxVBO)
 	// Allocate space for 24 vertices (12 lines), will be updated per-frame
 	gl.BufferData(gl.ARRAY_BUFFER, 24*3*4, nil, gl.DYNAMIC_DRAW)
+	trackBuffer(mv.bboxVBO, groupUI, "selection box", 24*3*4)
 
 	// Position attribute
 	gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, 3*4, 0)
@@ -734,8 +601,8 @@ func (mv *MapViewer) uploadTileGrid() {
 	// Clean up old resources
 	if mv.tileGridVAO != 0 {
 		gl.DeleteVertexArrays(1, &mv.tileGridVAO)
-		gl.DeleteBuffers(1, &mv.tileGridVBO)
-		gl.DeleteBuffers(1, &mv.tileGridEBO)
+		freeBuffer(mv.tileGridVBO)
+		freeBuffer(mv.tileGridEBO)
 	}
 
 	// Create VAO
@@ -751,6 +618,7 @@ func (mv *MapViewer) uploadTileGrid() {
 	vertexSize := int(unsafe.Sizeof(terrain.TileGridVertex{}))
 	gl.BufferData(gl.ARRAY_BUFFER, len(mv.tileGrid.Vertices)*vertexSize,
 		unsafe.Pointer(&mv.tileGrid.Vertices[0]), gl.STATIC_DRAW)
+	trackBuffer(mv.tileGridVBO, groupTerrain, "tile grid vertices", len(mv.tileGrid.Vertices)*vertexSize)
 
 	// Position attribute (location 0)
 	gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, int32(vertexSize), 0)
@@ -764,6 +632,7 @@ func (mv *MapViewer) uploadTileGrid() {
 	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, mv.tileGridEBO)
 	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(mv.tileGrid.Indices)*4,
 		unsafe.Pointer(&mv.tileGrid.Indices[0]), gl.STATIC_DRAW)
+	trackBuffer(mv.tileGridEBO, groupTerrain, "tile grid indices", len(mv.tileGrid.Indices)*4)
 
 	mv.tileGridCount = int32(len(mv.tileGrid.Indices))
 
@@ -777,22 +646,16 @@ func (mv *MapViewer) renderTileGrid(viewProj math.Mat4) {
 		return
 	}
 
-	// Save current GL state
-	var prevDepthFunc int32
-	var cullFaceEnabled bool
-	gl.GetIntegerv(gl.DEPTH_FUNC, &prevDepthFunc)
-	cullFaceEnabled = gl.IsEnabled(gl.CULL_FACE)
-
-	// Set up state for grid rendering:
+	// State for grid rendering, restored on return:
 	// 1. LEQUAL depth test - grid at same depth wins over terrain
-	// 2. Disable backface culling - ensures grid visible from all angles
+	// 2. No backface culling - ensures grid visible from all angles
 	// 3. Polygon offset - additional depth bias for reliability
-	gl.DepthFunc(gl.LEQUAL)
-	gl.Disable(gl.CULL_FACE)
-	gl.Enable(gl.BLEND)
-	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
-	gl.Enable(gl.POLYGON_OFFSET_FILL)
-	gl.PolygonOffset(-2.0, -2.0) // Negative values bring closer to camera
+	grid := glstate.Default.With(func(s *glstate.State) {
+		s.DepthFunc = gl.LEQUAL
+		s.OffsetFill = true
+		s.OffsetFactor, s.OffsetUnits = -2, -2 // Negative values bring closer to camera
+	})
+	defer glstate.Push(grid)()
 
 	// Use tile grid shader
 	gl.UseProgram(mv.tileGridProgram)
@@ -803,9 +666,10 @@ func (mv *MapViewer) renderTileGrid(viewProj math.Mat4) {
 	gl.DrawElements(gl.TRIANGLES, mv.tileGridCount, gl.UNSIGNED_INT, nil)
 
 	// Draw black grid lines (wireframe)
-	gl.Disable(gl.POLYGON_OFFSET_FILL)
-	gl.Enable(gl.POLYGON_OFFSET_LINE)
-	gl.PolygonOffset(-4.0, -4.0) // Push lines even closer to camera
+	glstate.Apply(grid.With(func(s *glstate.State) {
+		s.OffsetFill, s.OffsetLine = false, true
+		s.OffsetFactor, s.OffsetUnits = -4, -4 // Push lines even closer to camera
+	}))
 
 	// Use bbox shader for solid black lines
 	gl.UseProgram(mv.bboxProgram)
@@ -818,34 +682,6 @@ func (mv *MapViewer) renderTileGrid(viewProj math.Mat4) {
 	gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
 
 	gl.BindVertexArray(0)
-
-	// Restore all GL state
-	gl.Disable(gl.POLYGON_OFFSET_LINE)
-	gl.Disable(gl.BLEND)
-	gl.DepthFunc(uint32(prevDepthFunc))
-	if cullFaceEnabled {
-		gl.Enable(gl.CULL_FACE)
-	}
-}
-
-// createSpriteShader compiles the sprite billboard shader program.
-func (mv *MapViewer) createSpriteShader() error {
-	program, err := shader.CompileProgram(shaders.SpriteVertexShader, shaders.SpriteFragmentShader)
-	if err != nil {
-		return fmt.Errorf("sprite shader: %w", err)
-	}
-	mv.spriteProgram = program
-
-	// Get uniform locations
-	mv.locSpriteVP = shader.GetUniform(program, "uViewProj")
-	mv.locSpritePos = shader.GetUniform(program, "uWorldPos")
-	mv.locSpriteSize = shader.GetUniform(program, "uSpriteSize")
-	mv.locSpriteTex = shader.GetUniform(program, "uTexture")
-	mv.locSpriteTint = shader.GetUniform(program, "uTint")
-	mv.locSpriteCamRight = shader.GetUniform(program, "uCamRight")
-	mv.locSpriteCamUp = shader.GetUniform(program, "uCamUp")
-
-	return nil
 }
 
 // createFallbackTexture creates a simple white texture for missing textures.
@@ -857,69 +693,21 @@ func (mv *MapViewer) createFallbackTexture() {
 	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA, 1, 1, 0, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(white))
 	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
 	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
-}
-
-// compileWaterShader compiles the water rendering shader.
-func (mv *MapViewer) compileWaterShader() error {
-	program, err := shader.CompileProgram(shaders.WaterVertexShader, shaders.WaterFragmentShader)
-	if err != nil {
-		return fmt.Errorf("water shader: %w", err)
-	}
-	mv.waterProgram = program
-
-	// Get uniform locations
-	mv.locWaterMVP = shader.GetUniform(program, "uMVP")
-	mv.locWaterColor = shader.GetUniform(program, "uWaterColor")
-	mv.locWaterTime = shader.GetUniform(program, "uTime")
-	mv.locWaterTex = shader.GetUniform(program, "uWaterTex")
-
-	return nil
-}
-
-// loadWaterTextures loads water textures from GRF based on water type.
-func (mv *MapViewer) loadWaterTextures(_ int32, texLoader func(string) ([]byte, error)) {
-	// RO water textures are in data/texture/워터/ folder
-	// Format: water%03d.jpg (000-031 for 32 frames)
-
-	var textures []uint32
-
-	// Load 32 frames of water animation
-	for frame := 0; frame < 32; frame++ {
-		path := fmt.Sprintf("data/texture/워터/water%03d.jpg", frame)
-
-		data, err := texLoader(path)
-		if err != nil {
-			if frame == 0 {
-				fmt.Printf("Water texture not found: %s\n", path)
-			}
-			continue
-		}
-
-		// Decode and upload texture
-		img, err := decodeModelTexture(data, path, false)
-		if err != nil {
-			fmt.Printf("Failed to decode water texture: %s\n", path)
-			continue
-		}
-
-		texID := uploadModelTexture(img)
-		textures = append(textures, texID)
-	}
-
-	if len(textures) > 0 {
-		mv.waterTextures = textures
-		mv.useWaterTex = true
-		fmt.Printf("Loaded %d water texture frames\n", len(textures))
-	} else {
-		fmt.Println("No water textures found, using procedural water")
-		mv.useWaterTex = false
-	}
+	trackTexture(mv.fallbackTex, groupModels, "map fallback texture", 1, 1, 1, 4)
 }
 
 // LoadMap loads a GND/RSW map for rendering.
 func (mv *MapViewer) LoadMap(gnd *formats.GND, rsw *formats.RSW, texLoader func(string) ([]byte, error)) error {
 	// Clear old resources
 	mv.clearTerrain()
+	if rsw != mv.rsw {
+		mv.Modified = false // Reloading the same RSW keeps its edits
+	}
+	mv.rsw = rsw
+	mv.gnd = gnd
+	mv.texLoader = texLoader
+	mv.gizmoDrag = gizmoDrag{}
+	mv.SelectedIdx = -1
 
 	// Store map dimensions for coordinate conversion (RSW positions are centered)
 	mv.mapWidth = float32(gnd.Width) * gnd.Zoom
@@ -980,38 +768,30 @@ func (mv *MapViewer) LoadMap(gnd *formats.GND, rsw *formats.RSW, texLoader func(
 		}
 
 		// Extract point lights from RSW (Enhanced Graphics Phase 3)
-		mv.extractPointLights(rsw)
+		mv.pointLights = scene.RSWPointLights(rsw, mv.mapWidth, mv.mapHeight)
+		fmt.Printf("Extracted %d point lights from RSW\n", len(mv.pointLights))
 	}
 
-	// Load ground textures
-	mv.loadGroundTextures(gnd, texLoader)
-
-	// Build lightmap atlas (Stage 2)
-	mv.lightmapAtlas = terrain.BuildLightmapAtlas(gnd)
-	mv.uploadLightmapAtlas()
-
-	// Build terrain mesh
-	mesh := terrain.BuildMesh(gnd, mv.lightmapAtlas)
-	mv.terrainGroups = mesh.Groups
-	mv.minBounds = mesh.Bounds.Min
-	mv.maxBounds = mesh.Bounds.Max
-
-	// Upload to GPU
-	mv.uploadTerrainMesh(mesh.Vertices, mesh.Indices)
+	// Load ground textures, lightmap atlas and terrain mesh
+	if err := mv.terrainRenderer.LoadTerrain(gnd, texLoader); err != nil {
+		return fmt.Errorf("loading terrain: %w", err)
+	}
+	mv.minBounds = mv.terrainRenderer.MinBounds
+	mv.maxBounds = mv.terrainRenderer.MaxBounds
 
 	// Load RSM models from RSW (Stage 4)
 	if rsw != nil {
 		mv.loadModels(rsw, texLoader)
+		mv.Diagnostics.addParseWarnings("RSW", rsw.Warnings)
+	} else {
+		mv.Diagnostics = MapDiagnostics{}
 	}
+	mv.Diagnostics.addParseWarnings("GND", gnd.Warnings)
 
 	// Create water plane (Stage 4 - ADR-014)
 	if rsw != nil && rsw.Water.Level != 0 {
-		mv.createWaterPlane(gnd, rsw.Water.Level)
-		mv.loadWaterTextures(rsw.Water.Type, texLoader)
-		mv.waterAnimSpeed = float32(rsw.Water.AnimSpeed)
-		if mv.waterAnimSpeed == 0 {
-			mv.waterAnimSpeed = 30.0 // Fast animation speed for shimmering effect
-		}
+		mv.waterRenderer.SetupWater(rsw.Water.Level, mv.minBounds, mv.maxBounds, texLoader)
+		mv.waterRenderer.SetAnimSpeed(float32(rsw.Water.AnimSpeed))
 	}
 
 	// Set up fog (Stage 4 - ADR-014)
@@ -1038,182 +818,56 @@ func (mv *MapViewer) LoadMap(gnd *formats.GND, rsw *formats.RSW, texLoader func(
 	return nil
 }
 
-// extractPointLights extracts point lights from RSW for GPU upload.
-func (mv *MapViewer) extractPointLights(rsw *formats.RSW) {
-	mv.pointLights = nil
-	if rsw == nil {
-		return
-	}
-
-	rswLights := rsw.GetLights()
-	if len(rswLights) == 0 {
-		return
-	}
-
-	// Limit to max supported lights
-	count := len(rswLights)
-	if count > lighting.MaxPointLights {
-		count = lighting.MaxPointLights
-	}
-
-	mv.pointLights = make([]pointLightData, count)
-	for i := 0; i < count; i++ {
-		rswLight := rswLights[i]
-
-		// RSW positions are centered; same coordinate system as terrain
-		mv.pointLights[i] = pointLightData{
-			Position: [3]float32{
-				rswLight.Position[0],
-				rswLight.Position[1],
-				rswLight.Position[2],
-			},
-			Color: [3]float32{
-				clampf(rswLight.Color[0], 0, 1),
-				clampf(rswLight.Color[1], 0, 1),
-				clampf(rswLight.Color[2], 0, 1),
-			},
-			Range:     rswLight.Range,
-			Intensity: mv.PointLightIntensity,
-		}
-
-		// Ensure range is positive
-		if mv.pointLights[i].Range <= 0 {
-			mv.pointLights[i].Range = 100.0
-		}
-	}
-
-	fmt.Printf("Extracted %d point lights from RSW\n", len(mv.pointLights))
-}
-
-// clampf clamps a float32 to [min, max].
-func clampf(v, min, max float32) float32 {
-	if v < min {
-		return min
-	}
-	if v > max {
-		return max
-	}
-	return v
-}
-
-// uploadPointLightsToShader uploads point light data to the currently bound shader.
-func (mv *MapViewer) uploadPointLightsToShader(
-	locPositions, locColors, locRanges, locIntensities, locCount, locEnabled int32,
-) {
-	// Set enabled flag
-	if mv.PointLightsEnabled && len(mv.pointLights) > 0 {
-		gl.Uniform1i(locEnabled, 1)
-	} else {
-		gl.Uniform1i(locEnabled, 0)
-		gl.Uniform1i(locCount, 0)
-		return
-	}
-
-	// Set light count
-	count := int32(len(mv.pointLights))
-	gl.Uniform1i(locCount, count)
-
-	// Upload light arrays
-	// Positions: vec3 array
-	positions := make([]float32, lighting.MaxPointLights*3)
-	for i, light := range mv.pointLights {
-		positions[i*3+0] = light.Position[0]
-		positions[i*3+1] = light.Position[1]
-		positions[i*3+2] = light.Position[2]
-	}
-	gl.Uniform3fv(locPositions, lighting.MaxPointLights, &positions[0])
-
-	// Colors: vec3 array
-	colors := make([]float32, lighting.MaxPointLights*3)
-	for i, light := range mv.pointLights {
-		colors[i*3+0] = light.Color[0]
-		colors[i*3+1] = light.Color[1]
-		colors[i*3+2] = light.Color[2]
-	}
-	gl.Uniform3fv(locColors, lighting.MaxPointLights, &colors[0])
-
-	// Ranges: float array
-	ranges := make([]float32, lighting.MaxPointLights)
-	for i, light := range mv.pointLights {
-		ranges[i] = light.Range
-	}
-	gl.Uniform1fv(locRanges, lighting.MaxPointLights, &ranges[0])
-
-	// Intensities: float array (apply global intensity multiplier)
-	intensities := make([]float32, lighting.MaxPointLights)
-	for i, light := range mv.pointLights {
-		intensities[i] = light.Intensity * mv.PointLightIntensity
-	}
-	gl.Uniform1fv(locIntensities, lighting.MaxPointLights, &intensities[0])
-}
-
-// clearTerrain frees terrain GPU resources.
+// clearTerrain frees the loaded map's GPU resources.
 func (mv *MapViewer) clearTerrain() {
-	if mv.terrainVAO != 0 {
-		gl.DeleteVertexArrays(1, &mv.terrainVAO)
-		mv.terrainVAO = 0
-	}
-	if mv.terrainVBO != 0 {
-		gl.DeleteBuffers(1, &mv.terrainVBO)
-		mv.terrainVBO = 0
-	}
-	if mv.terrainEBO != 0 {
-		gl.DeleteBuffers(1, &mv.terrainEBO)
-		mv.terrainEBO = 0
-	}
-	for _, tex := range mv.groundTextures {
-		gl.DeleteTextures(1, &tex)
-	}
-	mv.groundTextures = make(map[int]uint32)
-	mv.terrainGroups = nil
-	if mv.lightmapAtlasTex != 0 {
-		gl.DeleteTextures(1, &mv.lightmapAtlasTex)
-		mv.lightmapAtlasTex = 0
-	}
-	mv.lightmapAtlas = nil
-
-	// Clear models
-	for _, model := range mv.models {
-		if model.vao != 0 {
-			gl.DeleteVertexArrays(1, &model.vao)
-		}
-		if model.vbo != 0 {
-			gl.DeleteBuffers(1, &model.vbo)
-		}
-		if model.ebo != 0 {
-			gl.DeleteBuffers(1, &model.ebo)
-		}
-		for _, tex := range model.textures {
-			gl.DeleteTextures(1, &tex)
-		}
-	}
+	mv.terrainTimer.reset()
+	mv.waterRenderer.Clear()
+	mv.modelRenderer.Reset(mv.fallbackTex, 0, 0)
 	mv.models = nil
 	mv.animatedModels = nil // Clear animated models list too
 	mv.modelAnimTime = 0    // Reset animation time
 }
 
-// loadGroundTextures loads textures from GRF.
-func (mv *MapViewer) loadGroundTextures(gnd *formats.GND, texLoader func(string) ([]byte, error)) {
-	for i, texPath := range gnd.Textures {
-		// Build full path
-		fullPath := "data/texture/" + texPath
+// TerrainStats reports how the terrain is drawn: draw calls per frame, the
+// texture array's layer count and size, and the smoothed GPU time in ms.
+func (mv *MapViewer) TerrainStats() (drawCalls, layers, layerSize int, gpuMs float32) {
+	layers, layerSize = mv.terrainRenderer.TextureStats()
+	return mv.terrainRenderer.DrawCalls(), layers, layerSize, mv.terrainTimer.avgMs
+}
 
-		data, err := texLoader(fullPath)
-		if err != nil {
-			continue
-		}
+// addResourceTotals adds the GPU memory the scene renderers hold for the
+// map, which the resource tracker doesn't see, to totals.
+func (mv *MapViewer) addResourceTotals(totals *[numResourceGroups]resourceTotals) {
+	totals[groupTerrain].addMemory(mv.terrainRenderer.Memory())
+	totals[groupTerrain].addMemory(mv.waterRenderer.Memory())
+	totals[groupModels].addMemory(mv.modelRenderer.Memory())
+	stats := mv.textures.Stats()
+	totals[groupModels].Textures += stats.Textures
+	totals[groupModels].TextureBytes += int64(stats.Resident)
+}
 
-		// Decode texture with magenta key enabled
-		// Some terrain textures (like Yuno railings) use magenta for transparency
-		img, err := decodeModelTexture(data, fullPath, true)
-		if err != nil {
-			continue
-		}
+// UseTextureArray reports whether the terrain is drawn from the texture
+// array.
+func (mv *MapViewer) UseTextureArray() bool {
+	return mv.terrainRenderer.UseTextureArray
+}
+
+// SetUseTextureArray switches between the texture array and per-texture
+// terrain rendering, restarting the GPU time average.
+func (mv *MapViewer) SetUseTextureArray(use bool) {
+	mv.terrainRenderer.UseTextureArray = use
+	mv.terrainTimer.reset()
+}
 
-		// Upload to GPU
-		texID := uploadModelTexture(img)
-		mv.groundTextures[i] = texID
+// SetTextureFilter changes how ground, model and water textures are
+// sampled and re-applies it to the loaded map's.
+func (mv *MapViewer) SetTextureFilter(f texfilter.Filter) {
+	if !texfilter.Set(f) {
+		return
 	}
+	mv.terrainRenderer.ApplyTextureFilter()
+	mv.waterRenderer.ApplyTextureFilter()
+	mv.textures.ApplyFilter()
 }
 
 // DebugModelPositioning enables debug output for model positioning issues.
@@ -1222,6 +876,8 @@ var DebugModelPositioning = false
 // loadModels loads RSM models from RSW object list.
 func (mv *MapViewer) loadModels(rsw *formats.RSW, texLoader func(string) ([]byte, error)) {
 	allModels := rsw.GetModels()
+	mv.modelRenderer.Reset(mv.fallbackTex, mv.mapWidth, mv.mapHeight)
+	mv.modelRenderer.ForceAllTwoSided = mv.ForceAllTwoSided
 
 	// Reset diagnostics
 	mv.Diagnostics = MapDiagnostics{
@@ -1264,6 +920,7 @@ func (mv *MapViewer) loadModels(rsw *formats.RSW, texLoader func(string) ([]byte
 				mv.Diagnostics.FailedModels = append(mv.Diagnostics.FailedModels, modelRef.ModelName+" (parse: "+err.Error()+")")
 				continue
 			}
+			mv.Diagnostics.addParseWarnings(modelRef.ModelName, rsm.Warnings)
 			rsmCache[rsmPath] = rsm
 		}
 
@@ -1274,7 +931,7 @@ func (mv *MapViewer) loadModels(rsw *formats.RSW, texLoader func(string) ([]byte
 			mv.models = append(mv.models, mapModel)
 			mv.Diagnostics.ModelsLoaded++
 			// Track animated models for animation updates
-			if mapModel.isAnimated {
+			if mapModel.Animated() {
 				mv.animatedModels = append(mv.animatedModels, mapModel)
 			}
 		} else {
@@ -1296,16 +953,20 @@ func (mv *MapViewer) buildModelGroups() {
 		if model == nil {
 			continue
 		}
-		groupMap[model.modelName] = append(groupMap[model.modelName], i)
+		groupMap[model.Name()] = append(groupMap[model.Name()], i)
 	}
 
 	// Convert to slice and sort by name
 	mv.ModelGroups = make([]ModelGroup, 0, len(groupMap))
 	for name, indices := range groupMap {
+		allVisible := true
+		for _, i := range indices {
+			allVisible = allVisible && mv.models[i].Visible
+		}
 		mv.ModelGroups = append(mv.ModelGroups, ModelGroup{
 			RSMName:    name,
 			Instances:  indices,
-			AllVisible: true,
+			AllVisible: allVisible,
 		})
 	}
 
@@ -1415,510 +1076,64 @@ func (mv *MapViewer) GetModelWorldPosition(idx int) (float32, float32, float32)
 	return model.position[0] + offsetX, -model.position[1], model.position[2] + offsetZ
 }
 
-// buildMapModel creates a MapModel from RSM data with world transform.
+// buildMapModel places an RSM model with the model renderer and records
+// its diagnostics.
 func (mv *MapViewer) buildMapModel(rsm *formats.RSM, ref *formats.RSWModel, texLoader func(string) ([]byte, error)) *MapModel {
 	if len(rsm.Nodes) == 0 {
 		return nil
 	}
 
-	// Track nodes
+	// Track nodes and faces
 	mv.Diagnostics.TotalNodes += len(rsm.Nodes)
+	modelTotalFaces, modelTwoSideFaces := rsmmodel.CountFaces(rsm)
+	mv.Diagnostics.TotalFaces += modelTotalFaces
+	mv.Diagnostics.TwoSidedFaces += modelTwoSideFaces
 
-	// Build mesh from all RSM nodes
-	var vertices []rsmmodel.Vertex
-	var indices []uint32
-	texGroups := make(map[int][]uint32)
-
-	// Load model textures
-	modelTextures := make([]uint32, len(rsm.Textures))
-	for i, texName := range rsm.Textures {
-		texPath := "data/texture/" + texName
-		data, err := texLoader(texPath)
-		if err != nil {
-			modelTextures[i] = mv.fallbackTex
-			mv.Diagnostics.TexturesMissing++
-			// Only add unique missing textures
-			found := false
-			for _, t := range mv.Diagnostics.MissingTextures {
-				if t == texName {
-					found = true
-					break
-				}
-			}
-			if !found {
-				mv.Diagnostics.MissingTextures = append(mv.Diagnostics.MissingTextures, texName)
-			}
-			continue
-		}
-		img, err := decodeModelTexture(data, texPath, true) // Use magenta key
-		if err != nil {
-			modelTextures[i] = mv.fallbackTex
-			mv.Diagnostics.TexturesMissing++
-			continue
-		}
-		modelTextures[i] = uploadModelTexture(img)
-		mv.Diagnostics.TexturesLoaded++
-	}
-
-	// Track bounding box for centering
-	var minVertX, minVertY, minVertZ float32 = 1e10, 1e10, 1e10
-	var maxVertX, maxVertY, maxVertZ float32 = -1e10, -1e10, -1e10
-
-	// Process each node
-	for i := range rsm.Nodes {
-		node := &rsm.Nodes[i]
-		baseIdx := uint32(len(vertices))
-
-		// Build node transform matrix (with parent hierarchy)
-		nodeMatrix := rsmmodel.BuildNodeMatrix(node, rsm, 0) // Initial pose (time=0)
-
-		// Check if we need to reverse winding due to negative scale
-		reverseWinding := ref.Scale[0]*ref.Scale[1]*ref.Scale[2] < 0
-
-		// Process faces
-		for _, face := range node.Faces {
-			// Track face stats
-			mv.Diagnostics.TotalFaces++
-			isTwoSided := face.TwoSide != 0
-			if isTwoSided {
-				mv.Diagnostics.TwoSidedFaces++
-			}
-
-			// Skip faces with insufficient vertices
-			if len(face.VertexIDs) < 3 {
-				continue
-			}
-
-			// Bounds check vertex IDs
-			validFace := true
-			for _, vid := range face.VertexIDs {
-				if int(vid) >= len(node.Vertices) {
-					validFace = false
-					break
-				}
-			}
-			if !validFace {
-				continue
-			}
-
-			// Calculate face normal from first 3 vertices
-			v0 := node.Vertices[face.VertexIDs[0]]
-			v1 := node.Vertices[face.VertexIDs[1]]
-			v2 := node.Vertices[face.VertexIDs[2]]
-			e1 := [3]float32{v1[0] - v0[0], v1[1] - v0[1], v1[2] - v0[2]}
-			e2 := [3]float32{v2[0] - v0[0], v2[1] - v0[1], v2[2] - v0[2]}
-			normalVec := rsmmodel.Cross(e1, e2)
-
-			// Degenerate triangle detection - skip if normal is too small
-			normalMag := float32(gomath.Sqrt(float64(normalVec[0]*normalVec[0] + normalVec[1]*normalVec[1] + normalVec[2]*normalVec[2])))
-			if normalMag < 1e-5 {
-				continue // Skip degenerate triangle
-			}
-
-			normal := [3]float32{normalVec[0] / normalMag, normalVec[1] / normalMag, normalVec[2] / normalMag}
-
-			// Helper to add face vertices
-			addFaceVertices := func(reverseOrder bool, flipNormal bool) uint32 {
-				faceBaseIdx := uint32(len(vertices))
-				faceNormal := normal
-				if flipNormal {
-					faceNormal = [3]float32{-normal[0], -normal[1], -normal[2]}
-				}
-
-				// Determine vertex order (RSM faces are always triangles = 3 vertices)
-				var vertIDs [3]uint16
-				var texIDs [3]uint16
-				if reverseOrder {
-					// Reverse vertex order for back face: 0,1,2 -> 2,1,0
-					vertIDs = [3]uint16{face.VertexIDs[2], face.VertexIDs[1], face.VertexIDs[0]}
-					texIDs = [3]uint16{face.TexCoordIDs[2], face.TexCoordIDs[1], face.TexCoordIDs[0]}
-				} else {
-					vertIDs = face.VertexIDs
-					texIDs = face.TexCoordIDs
-				}
-
-				for i := 0; i < 3; i++ {
-					vid := vertIDs[i]
-					v := node.Vertices[vid]
-
-					// Transform vertex position by node matrix
-					pos := rsmmodel.TransformPoint(nodeMatrix, v)
-
-					// Flip Y for RO coordinate system
-					pos[1] = -pos[1]
-
-					// Track bounding box
-					if pos[0] < minVertX {
-						minVertX = pos[0]
-					}
-					if pos[0] > maxVertX {
-						maxVertX = pos[0]
-					}
-					if pos[1] < minVertY {
-						minVertY = pos[1]
-					}
-					if pos[1] > maxVertY {
-						maxVertY = pos[1]
-					}
-					if pos[2] < minVertZ {
-						minVertZ = pos[2]
-					}
-					if pos[2] > maxVertZ {
-						maxVertZ = pos[2]
-					}
-
-					// Get texture coordinates
-					var uv [2]float32
-					if int(texIDs[i]) < len(node.TexCoords) {
-						tc := node.TexCoords[texIDs[i]]
-						uv = [2]float32{tc.U, tc.V}
-					}
-
-					vertices = append(vertices, rsmmodel.Vertex{
-						Position: pos,
-						Normal:   faceNormal,
-						TexCoord: uv,
-					})
-				}
-				return faceBaseIdx
-			}
-
-			// Add front face (with winding reversal if negative scale)
-			faceBaseIdx := addFaceVertices(reverseWinding, false)
-
-			// Add indices for triangle (RSM faces are always triangles)
-			// face.TextureID is index into node.TextureIDs
-			// node.TextureIDs[i] is the global index into rsm.Textures
-			globalTexIdx := 0
-			if int(face.TextureID) < len(node.TextureIDs) {
-				globalTexIdx = int(node.TextureIDs[face.TextureID])
-			}
-			texGroups[globalTexIdx] = append(texGroups[globalTexIdx],
-				faceBaseIdx,
-				faceBaseIdx+1,
-				faceBaseIdx+2,
-			)
-
-			// If TwoSide (or ForceAllTwoSided debug flag), add back face
-			if isTwoSided || mv.ForceAllTwoSided {
-				backFaceBaseIdx := addFaceVertices(!reverseWinding, true)
-				texGroups[globalTexIdx] = append(texGroups[globalTexIdx],
-					backFaceBaseIdx,
-					backFaceBaseIdx+1,
-					backFaceBaseIdx+2,
-				)
-			}
-		}
-		_ = baseIdx // Silence unused warning
-	}
-
-	// Track total vertices
-	mv.Diagnostics.TotalVertices += len(vertices)
-
-	if len(vertices) == 0 {
+	placed := mv.modelRenderer.AddModel(rsm, ref, texLoader)
+	if placed == nil {
 		return nil
 	}
+	mv.Diagnostics.TotalVertices += placed.VertexCount()
 
-	// Center models horizontally (X/Z) but NOT vertically (Y)
-	// Preserving Y offset fixes positioning of decorative elements attached to structures
-	centerX := (minVertX + maxVertX) / 2
-	centerZ := (minVertZ + maxVertZ) / 2
-	for i := range vertices {
-		vertices[i].Position[0] -= centerX
-		// Don't center Y - preserve original vertical offset from RSM
-		vertices[i].Position[2] -= centerZ
+	missing := placed.MissingTextures()
+	mv.Diagnostics.TexturesMissing += len(missing)
+	mv.Diagnostics.TexturesLoaded += len(rsm.Textures) - len(missing)
+	for _, texName := range missing {
+		// Only add unique missing textures
+		if !slices.Contains(mv.Diagnostics.MissingTextures, texName) {
+			mv.Diagnostics.MissingTextures = append(mv.Diagnostics.MissingTextures, texName)
+		}
 	}
 
-	// Store bounding box after centering (for debug visualization)
-	bboxAfter := [6]float32{
-		minVertX - centerX, minVertY, minVertZ - centerZ,
-		maxVertX - centerX, maxVertY, maxVertZ - centerZ,
-	}
+	b := placed.LocalBounds()
 
 	// Debug: log model centering info
 	if DebugModelPositioning {
-		height := maxVertY - minVertY
 		fmt.Printf("Model: %s | RSW pos: (%.1f,%.1f,%.1f) rot: (%.1f,%.1f,%.1f) | BBox: (%.1f,%.1f,%.1f)-(%.1f,%.1f,%.1f) | Height: %.1f\n",
 			ref.ModelName,
 			ref.Position[0], ref.Position[1], ref.Position[2],
 			ref.Rotation[0], ref.Rotation[1], ref.Rotation[2],
-			minVertX, minVertY, minVertZ, maxVertX, maxVertY, maxVertZ, height)
-	}
-
-	// Build texture groups
-	var groups []rsmmodel.TextureGroup
-	for texIdx, idxs := range texGroups {
-		if len(idxs) == 0 {
-			continue
-		}
-		groups = append(groups, rsmmodel.TextureGroup{
-			TextureIdx: texIdx,
-			StartIndex: int32(len(indices)),
-			IndexCount: int32(len(idxs)),
-		})
-		indices = append(indices, idxs...)
-	}
-
-	// Smooth normals for models (reduces faceted appearance)
-	rsmmodel.SmoothNormals(vertices)
-
-	// Count total and two-sided faces for this model
-	modelTotalFaces := 0
-	modelTwoSideFaces := 0
-	for i := range rsm.Nodes {
-		for _, face := range rsm.Nodes[i].Faces {
-			modelTotalFaces++
-			if face.TwoSide != 0 {
-				modelTwoSideFaces++
-			}
-		}
-	}
-
-	// Build node debug info
-	nodeDebugInfo := rsmmodel.BuildNodeDebugInfo(rsm)
-
-	// Check if model has animation
-	hasAnimation := rsmmodel.HasAnimation(rsm)
-
-	// Create GPU resources
-	model := &MapModel{
-		textures:     modelTextures,
-		texGroups:    groups,
-		position:     ref.Position,
-		rotation:     ref.Rotation,
-		scale:        ref.Scale,
-		modelName:    ref.ModelName,
-		bbox:         bboxAfter,
-		Visible:      true, // Visible by default
+			b.Min.X, b.Min.Y, b.Min.Z, b.Max.X, b.Max.Y, b.Max.Z, b.Max.Y-b.Min.Y)
+	}
+
+	return &MapModel{
+		MapModel: placed,
+		position: ref.Position,
+		rotation: ref.Rotation,
+		scale:    ref.Scale,
+		bbox: [6]float32{
+			b.Min.X, b.Min.Y, b.Min.Z,
+			b.Max.X, b.Max.Y, b.Max.Z,
+		},
 		totalFaces:   modelTotalFaces,
 		twoSideFaces: modelTwoSideFaces,
 		rsmVersion:   rsm.Version.String(),
 		nodeCount:    len(rsm.Nodes),
-		nodes:        nodeDebugInfo,
-		// Animation support
-		isAnimated: hasAnimation,
-		animLength: rsm.AnimLength,
-	}
-
-	// Store RSM reference for animated models (needed for mesh rebuild)
-	if hasAnimation {
-		model.rsm = rsm
-		model.rswRef = ref
-	}
-
-	// Upload mesh to GPU
-	gl.GenVertexArrays(1, &model.vao)
-	gl.BindVertexArray(model.vao)
-
-	gl.GenBuffers(1, &model.vbo)
-	gl.BindBuffer(gl.ARRAY_BUFFER, model.vbo)
-	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*int(unsafe.Sizeof(rsmmodel.Vertex{})), gl.Ptr(vertices), gl.STATIC_DRAW)
-
-	gl.GenBuffers(1, &model.ebo)
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, model.ebo)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
-
-	model.indexCount = int32(len(indices))
-
-	// Set vertex attributes (Position, Normal, TexCoord)
-	stride := int32(unsafe.Sizeof(rsmmodel.Vertex{}))
-	gl.EnableVertexAttribArray(0)
-	gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, stride, 0)
-	gl.EnableVertexAttribArray(1)
-	gl.VertexAttribPointerWithOffset(1, 3, gl.FLOAT, false, stride, 12)
-	gl.EnableVertexAttribArray(2)
-	gl.VertexAttribPointerWithOffset(2, 2, gl.FLOAT, false, stride, 24)
-
-	gl.BindVertexArray(0)
-
-	return model
-}
-
-// buildAnimatedModelMesh builds vertices and indices for an animated model at a given time.
-func (mv *MapViewer) buildAnimatedModelMesh(rsm *formats.RSM, ref *formats.RSWModel, animTimeMs float32) ([]rsmmodel.Vertex, []uint32, []rsmmodel.TextureGroup) {
-	var vertices []rsmmodel.Vertex
-	var indices []uint32
-	texGroups := make(map[int][]uint32)
-
-	// Process each node
-	for i := range rsm.Nodes {
-		node := &rsm.Nodes[i]
-
-		// Build node transform with animation time
-		nodeMatrix := rsmmodel.BuildNodeMatrix(node, rsm, animTimeMs)
-
-		// Check if we need to reverse winding
-		reverseWinding := ref.Scale[0]*ref.Scale[1]*ref.Scale[2] < 0
-
-		// Process faces
-		for _, face := range node.Faces {
-			if len(face.VertexIDs) < 3 {
-				continue
-			}
-
-			// Bounds check
-			validFace := true
-			for _, vid := range face.VertexIDs {
-				if int(vid) >= len(node.Vertices) {
-					validFace = false
-					break
-				}
-			}
-			if !validFace {
-				continue
-			}
-
-			// Calculate face normal
-			v0 := node.Vertices[face.VertexIDs[0]]
-			v1 := node.Vertices[face.VertexIDs[1]]
-			v2 := node.Vertices[face.VertexIDs[2]]
-			e1 := [3]float32{v1[0] - v0[0], v1[1] - v0[1], v1[2] - v0[2]}
-			e2 := [3]float32{v2[0] - v0[0], v2[1] - v0[1], v2[2] - v0[2]}
-			normalVec := rsmmodel.Cross(e1, e2)
-			normalMag := float32(gomath.Sqrt(float64(normalVec[0]*normalVec[0] + normalVec[1]*normalVec[1] + normalVec[2]*normalVec[2])))
-			if normalMag < 1e-5 {
-				continue
-			}
-			normal := [3]float32{normalVec[0] / normalMag, normalVec[1] / normalMag, normalVec[2] / normalMag}
-
-			// Helper to add face vertices
-			addFaceVerts := func(reverseOrder bool, flipNormal bool) uint32 {
-				faceBaseIdx := uint32(len(vertices))
-				faceNormal := normal
-				if flipNormal {
-					faceNormal = [3]float32{-normal[0], -normal[1], -normal[2]}
-				}
-
-				var vertIDs [3]uint16
-				var texIDs [3]uint16
-				if reverseOrder {
-					vertIDs = [3]uint16{face.VertexIDs[2], face.VertexIDs[1], face.VertexIDs[0]}
-					texIDs = [3]uint16{face.TexCoordIDs[2], face.TexCoordIDs[1], face.TexCoordIDs[0]}
-				} else {
-					vertIDs = face.VertexIDs
-					texIDs = face.TexCoordIDs
-				}
-
-				for j := 0; j < 3; j++ {
-					pos := node.Vertices[vertIDs[j]]
-					transformedPos := rsmmodel.TransformPoint(nodeMatrix, pos)
-					// Flip Y for RO coordinate system
-					transformedPos[1] = -transformedPos[1]
-
-					var uv [2]float32
-					if int(texIDs[j]) < len(node.TexCoords) {
-						tc := node.TexCoords[texIDs[j]]
-						uv = [2]float32{tc.U, tc.V}
-					}
-
-					vertices = append(vertices, rsmmodel.Vertex{
-						Position: transformedPos,
-						Normal:   faceNormal,
-						TexCoord: uv,
-					})
-				}
-				return faceBaseIdx
-			}
-
-			// Add front face
-			faceBaseIdx := addFaceVerts(reverseWinding, false)
-
-			// Get global texture index
-			globalTexIdx := 0
-			if int(face.TextureID) < len(node.TextureIDs) {
-				globalTexIdx = int(node.TextureIDs[face.TextureID])
-			}
-			texGroups[globalTexIdx] = append(texGroups[globalTexIdx],
-				faceBaseIdx, faceBaseIdx+1, faceBaseIdx+2)
-
-			// Add back face if two-sided
-			if face.TwoSide != 0 || mv.ForceAllTwoSided {
-				backIdx := addFaceVerts(!reverseWinding, true)
-				texGroups[globalTexIdx] = append(texGroups[globalTexIdx],
-					backIdx, backIdx+1, backIdx+2)
-			}
-		}
-	}
-
-	// Build indices and groups
-	var groups []rsmmodel.TextureGroup
-	for texIdx, idxs := range texGroups {
-		startIdx := int32(len(indices))
-		indices = append(indices, idxs...)
-		groups = append(groups, rsmmodel.TextureGroup{
-			TextureIdx: texIdx,
-			StartIndex: startIdx,
-			IndexCount: int32(len(idxs)),
-		})
+		nodes:        rsmmodel.BuildNodeDebugInfo(rsm),
+		// Keep the RSM and placement for map editing
+		rsm:    rsm,
+		rswRef: ref,
 	}
-
-	return vertices, indices, groups
-}
-
-// uploadTerrainMesh uploads mesh data to GPU.
-func (mv *MapViewer) uploadTerrainMesh(vertices []terrain.Vertex, indices []uint32) {
-	if len(vertices) == 0 {
-		return
-	}
-
-	// Create VAO
-	gl.GenVertexArrays(1, &mv.terrainVAO)
-	gl.BindVertexArray(mv.terrainVAO)
-
-	// Create VBO
-	gl.GenBuffers(1, &mv.terrainVBO)
-	gl.BindBuffer(gl.ARRAY_BUFFER, mv.terrainVBO)
-	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*int(unsafe.Sizeof(terrain.Vertex{})), gl.Ptr(vertices), gl.STATIC_DRAW)
-
-	// Create EBO
-	gl.GenBuffers(1, &mv.terrainEBO)
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, mv.terrainEBO)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
-
-	// Set vertex attributes
-	// terrain.Vertex: Position(12) + Normal(12) + TexCoord(8) + LightmapUV(8) + Color(16) = 56 bytes
-	stride := int32(unsafe.Sizeof(terrain.Vertex{}))
-
-	// Position (location 0) - offset 0
-	gl.EnableVertexAttribArray(0)
-	gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, stride, 0)
-
-	// Normal (location 1) - offset 12
-	gl.EnableVertexAttribArray(1)
-	gl.VertexAttribPointerWithOffset(1, 3, gl.FLOAT, false, stride, 12)
-
-	// TexCoord (location 2) - offset 24
-	gl.EnableVertexAttribArray(2)
-	gl.VertexAttribPointerWithOffset(2, 2, gl.FLOAT, false, stride, 24)
-
-	// LightmapUV (location 3) - offset 32
-	gl.EnableVertexAttribArray(3)
-	gl.VertexAttribPointerWithOffset(3, 2, gl.FLOAT, false, stride, 32)
-
-	// Color (location 4) - offset 40
-	gl.EnableVertexAttribArray(4)
-	gl.VertexAttribPointerWithOffset(4, 4, gl.FLOAT, false, stride, 40)
-
-	gl.BindVertexArray(0)
-}
-
-// uploadLightmapAtlas uploads the lightmap atlas texture to GPU.
-func (mv *MapViewer) uploadLightmapAtlas() {
-	if mv.lightmapAtlas == nil {
-		return
-	}
-
-	gl.GenTextures(1, &mv.lightmapAtlasTex)
-	gl.BindTexture(gl.TEXTURE_2D, mv.lightmapAtlasTex)
-	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA, mv.lightmapAtlas.Size, mv.lightmapAtlas.Size, 0,
-		gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(mv.lightmapAtlas.Data))
-
-	// Generate mipmaps for smooth lightmap at distance
-	gl.GenerateMipmap(gl.TEXTURE_2D)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR_MIPMAP_LINEAR)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
 }
 
 // renderShadowPass renders the scene to the shadow map for shadow calculations.
@@ -1937,49 +1152,22 @@ func (mv *MapViewer) renderShadowPass() {
 	// Render terrain to shadow map (terrain is at origin, identity model matrix)
 	identityMatrix := math.Identity()
 	gl.UniformMatrix4fv(mv.locShadowModel, 1, false, &identityMatrix[0])
-
-	gl.BindVertexArray(mv.terrainVAO)
-	for _, group := range mv.terrainGroups {
-		gl.DrawElementsWithOffset(gl.TRIANGLES, group.IndexCount, gl.UNSIGNED_INT, uintptr(group.StartIndex*4))
-	}
+	mv.terrainRenderer.RenderShadow()
 
 	// Render models to shadow map
-	offsetX := mv.mapWidth / 2
-	offsetZ := mv.mapHeight / 2
-
-	for _, model := range mv.models {
-		if model.vao == 0 || model.indexCount == 0 || !model.Visible {
-			continue
-		}
-
-		// Build model matrix (same as in renderModels)
-		worldX := model.position[0] + offsetX
-		worldY := -model.position[1]
-		worldZ := model.position[2] + offsetZ
-
-		modelMatrix := math.Identity()
-		modelMatrix = modelMatrix.Mul(math.Translate(worldX, worldY, worldZ))
-		modelMatrix = modelMatrix.Mul(math.RotateY(model.rotation[1] * gomath.Pi / 180))
-		modelMatrix = modelMatrix.Mul(math.RotateX(model.rotation[0] * gomath.Pi / 180))
-		modelMatrix = modelMatrix.Mul(math.RotateZ(model.rotation[2] * gomath.Pi / 180))
-		// Apply per-model scale multiplied by global ModelScale
-		modelMatrix = modelMatrix.Mul(math.Scale(
-			model.scale[0]*mv.ModelScale,
-			model.scale[1]*mv.ModelScale,
-			model.scale[2]*mv.ModelScale,
-		))
-
-		gl.UniformMatrix4fv(mv.locShadowModel, 1, false, &modelMatrix[0])
-
-		gl.BindVertexArray(model.vao)
-		for _, group := range model.texGroups {
-			gl.DrawElementsWithOffset(gl.TRIANGLES, group.IndexCount, gl.UNSIGNED_INT, uintptr(group.StartIndex*4))
-		}
-	}
+	mv.modelRenderer.RenderShadow(mv.shadowProgram, mv.locShadowModel)
 
 	// Unbind shadow map framebuffer
 	mv.shadowMap.Unbind()
-	gl.BindVertexArray(0)
+}
+
+// Camera returns the camera the map is viewed from: following the player
+// in play mode, orbiting the map otherwise.
+func (mv *MapViewer) Camera() camera.Camera {
+	if mv.PlayMode && mv.Player != nil {
+		return mv.FollowCam.At(mv.Player.WorldX, mv.Player.WorldY, mv.Player.WorldZ)
+	}
+	return mv.OrbitCam
 }
 
 // fitCamera positions camera to view entire map.
@@ -1992,24 +1180,19 @@ func (mv *MapViewer) fitCamera() {
 
 // Render renders the map to the framebuffer and returns the texture ID.
 func (mv *MapViewer) Render() uint32 {
-	if mv.terrainVAO == 0 {
+	if mv.mapWidth == 0 {
 		return mv.colorTexture
 	}
 
+	// Opaque, depth tested and unculled (winding order varies). Reset
+	// issues every call since ImGui draws between frames.
+	glstate.Reset(glstate.Opaque)
+
 	// Calculate view-projection matrix first (needed for shadow pass too)
 	aspect := float32(mv.width) / float32(mv.height)
 	proj := math.Perspective(45.0, aspect, 1.0, 10000.0)
 
-	var view math.Mat4
-	if mv.PlayMode && mv.Player != nil {
-		player := mv.Player
-		view = mv.FollowCam.ViewMatrix(player.WorldX, player.WorldY, player.WorldZ)
-	} else if mv.PlayMode {
-		view = mv.OrbitCam.ViewMatrix()
-	} else {
-		view = mv.OrbitCam.ViewMatrix()
-	}
-
+	view := mv.Camera().ViewMatrix()
 	viewProj := proj.Mul(view)
 
 	// Cache matrices for picking
@@ -2037,89 +1220,34 @@ func (mv *MapViewer) Render() uint32 {
 	gl.ClearColor(0.4, 0.6, 0.9, 1.0) // Sky blue
 	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 
-	// Enable depth test
-	gl.Enable(gl.DEPTH_TEST)
-	gl.DepthFunc(gl.LESS)
-
-	// Use terrain shader with RSW lighting data
-	gl.UseProgram(mv.terrainProgram)
-	gl.UniformMatrix4fv(mv.locViewProj, 1, false, &viewProj[0])
-	gl.Uniform3f(mv.locLightDir, mv.lightDir[0], mv.lightDir[1], mv.lightDir[2])
-	gl.Uniform3f(mv.locAmbient, mv.ambientColor[0], mv.ambientColor[1], mv.ambientColor[2])
-	gl.Uniform3f(mv.locDiffuse, mv.diffuseColor[0], mv.diffuseColor[1], mv.diffuseColor[2])
-	gl.Uniform1i(mv.locTexture, 0)
-	gl.Uniform1i(mv.locLightmap, 1)
-	gl.Uniform1f(mv.locBrightness, mv.Brightness)
-	gl.Uniform1f(mv.locLightOpacity, mv.lightOpacity)
-
-	// Shadow mapping uniforms for terrain
-	gl.UniformMatrix4fv(mv.locTerrainLightViewProj, 1, false, &mv.lightViewProj[0])
-	gl.Uniform1i(mv.locTerrainShadowMap, 2) // Shadow map on texture unit 2
-	if mv.ShadowsEnabled && mv.shadowMap != nil && mv.shadowMap.IsValid() {
-		gl.Uniform1i(mv.locTerrainShadowsEnabled, 1)
-	} else {
-		gl.Uniform1i(mv.locTerrainShadowsEnabled, 0)
-	}
-
-	// Fog uniforms
-	if mv.FogEnabled {
-		gl.Uniform1i(mv.locFogUse, 1)
-	} else {
-		gl.Uniform1i(mv.locFogUse, 0)
-	}
-	gl.Uniform1f(mv.locFogNear, mv.FogNear)
-	gl.Uniform1f(mv.locFogFar, mv.FogFar)
-	gl.Uniform3f(mv.locFogColor, mv.FogColor[0], mv.FogColor[1], mv.FogColor[2])
-
-	// Point light uniforms (terrain shader)
-	mv.uploadPointLightsToShader(
-		mv.locTerrainPointLightPositions,
-		mv.locTerrainPointLightColors,
-		mv.locTerrainPointLightRanges,
-		mv.locTerrainPointLightIntensities,
-		mv.locTerrainPointLightCount,
-		mv.locTerrainPointLightsEnabled,
-	)
-
-	// Bind lightmap atlas to texture unit 1
-	gl.ActiveTexture(gl.TEXTURE1)
-	if mv.lightmapAtlasTex != 0 {
-		gl.BindTexture(gl.TEXTURE_2D, mv.lightmapAtlasTex)
-	} else {
-		gl.BindTexture(gl.TEXTURE_2D, mv.fallbackTex)
-	}
-
-	// Bind shadow map to texture unit 2
-	if mv.shadowMap != nil && mv.shadowMap.IsValid() {
-		mv.shadowMap.BindTexture(gl.TEXTURE2)
-	}
-
-	// Disable face culling for terrain (test for winding issues)
-	gl.Disable(gl.CULL_FACE)
-
-	// Bind terrain VAO
-	gl.BindVertexArray(mv.terrainVAO)
-
-	// Render each texture group
-	gl.ActiveTexture(gl.TEXTURE0)
-	for _, group := range mv.terrainGroups {
-		tex, ok := mv.groundTextures[group.TextureID]
-		if !ok {
-			tex = mv.fallbackTex
-		}
-		gl.BindTexture(gl.TEXTURE_2D, tex)
-		gl.DrawElementsWithOffset(gl.TRIANGLES, group.IndexCount, gl.UNSIGNED_INT, uintptr(group.StartIndex*4))
+	// Render terrain with RSW lighting data
+	shadowsOn := mv.ShadowsEnabled && mv.shadowMap != nil && mv.shadowMap.IsValid()
+	var shadowMap *shadow.Map
+	if shadowsOn {
+		shadowMap = mv.shadowMap
 	}
-
-	gl.BindVertexArray(0)
+	mv.terrainTimer.begin()
+	mv.terrainRenderer.Render(viewProj, mv.lightDir, mv.ambientColor, mv.diffuseColor, mv.Brightness, mv.lightOpacity,
+		shadowsOn, mv.lightViewProj, shadowMap,
+		mv.PointLightsEnabled, mv.pointLights, mv.PointLightIntensity,
+		mv.FogEnabled, mv.FogNear, mv.FogFar, mv.FogColor)
+	mv.terrainTimer.end()
 
 	// Render tile grid (debug visualization)
 	if mv.TileGridEnabled && mv.tileGridVAO != 0 {
 		mv.renderTileGrid(viewProj)
 	}
 
-	// Render placed models
-	mv.renderModels(viewProj)
+	// Render placed models, streaming their textures toward the size
+	// they're seen at
+	mv.modelRenderer.SetModelScale(mv.ModelScale)
+	mv.modelRenderer.Render(viewProj, mv.lightDir, mv.ambientColor, mv.diffuseColor,
+		shadowsOn, mv.lightViewProj, shadowMap,
+		mv.PointLightsEnabled, mv.pointLights, mv.PointLightIntensity,
+		mv.FogEnabled, mv.FogNear, mv.FogFar, mv.FogColor)
+	now := time.Now()
+	mv.modelRenderer.RequestTextures(viewProj, float32(mv.width), float32(mv.height), now)
+	mv.textures.Update(now)
 
 	// Render player character (in Play mode)
 	if mv.PlayMode && mv.Player != nil {
@@ -2128,8 +1256,9 @@ func (mv *MapViewer) Render() uint32 {
 		mv.renderPlayerCharacter(viewProj)
 	}
 
-	// Render water (last, with transparency)
-	mv.renderWater(viewProj)
+	// Render water (last, with transparency), animated at ~60fps
+	mv.waterRenderer.Update(16.0)
+	mv.waterRenderer.Render(viewProj)
 
 	// Render selection bounding box (on top of everything)
 	mv.renderSelectionBbox(viewProj)
@@ -2143,7 +1272,7 @@ func (mv *MapViewer) Render() uint32 {
 // Uses camera-facing billboard + directional sprite selection for 3D illusion.
 func (mv *MapViewer) renderPlayerCharacter(viewProj math.Mat4) {
 	player := mv.Player
-	if player == nil || player.VAO == 0 {
+	if player == nil {
 		return
 	}
 
@@ -2152,7 +1281,9 @@ func (mv *MapViewer) renderPlayerCharacter(viewProj math.Mat4) {
 
 	// ========== STEP 1: Calculate camera-facing billboard vectors ==========
 	// Use render position for smooth visual appearance
-	camRight, camUp := character.BillboardVectors(mv.FollowCam.PosX, mv.FollowCam.PosZ, player.RenderX, player.RenderZ)
+	right, up := character.BillboardVectors(mv.FollowCam.PosX, mv.FollowCam.PosZ, player.RenderX, player.RenderZ)
+	camRight := math.Vec3{X: right[0], Y: right[1], Z: right[2]}
+	camUp := math.Vec3{X: up[0], Y: up[1], Z: up[2]}
 
 	// ========== STEP 2: Calculate visual direction with hysteresis ==========
 	cameraAngle := character.CameraAngleToPlayer(mv.FollowCam.PosX, mv.FollowCam.PosZ, player.RenderX, player.RenderZ)
@@ -2177,32 +1308,13 @@ func (mv *MapViewer) renderPlayerCharacter(viewProj math.Mat4) {
 				spriteWidth := float32(composite.Width) * player.SpriteScale
 				spriteHeight := float32(composite.Height) * player.SpriteScale
 
-				gl.Enable(gl.BLEND)
-				gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
-				gl.UseProgram(mv.spriteProgram)
+				defer glstate.Push(glstate.Default)()
 
 				// Position sprite at player location
 				// Billboard quad is foot-anchored (Y: 0 to 1), so no offset needed
-				posX := player.RenderX
-				posY := player.RenderY
-				posZ := player.RenderZ
-
-				gl.UniformMatrix4fv(mv.locSpriteVP, 1, false, &viewProj[0])
-				gl.Uniform3f(mv.locSpritePos, posX, posY, posZ)
-				gl.Uniform2f(mv.locSpriteSize, spriteWidth, spriteHeight)
-				gl.Uniform4f(mv.locSpriteTint, 1.0, 1.0, 1.0, 1.0)
-				gl.Uniform3f(mv.locSpriteCamRight, camRight[0], camRight[1], camRight[2])
-				gl.Uniform3f(mv.locSpriteCamUp, camUp[0], camUp[1], camUp[2])
-
-				gl.ActiveTexture(gl.TEXTURE0)
-				gl.BindTexture(gl.TEXTURE_2D, composite.Texture)
-				gl.Uniform1i(mv.locSpriteTex, 0)
-
-				gl.BindVertexArray(player.VAO)
-				gl.DrawArrays(gl.TRIANGLE_STRIP, 0, 4)
-				gl.BindVertexArray(0)
-
-				gl.Disable(gl.BLEND)
+				pos := [3]float32{player.RenderX, player.RenderY, player.RenderZ}
+				mv.spriteRenderer.Render(viewProj, camRight, camUp, pos, spriteWidth, spriteHeight,
+					composite.Texture, [4]float32{1.0, 1.0, 1.0, 1.0})
 				return // Done - composite rendered
 			}
 		}
@@ -2276,25 +1388,12 @@ func (mv *MapViewer) renderPlayerCharacter(viewProj math.Mat4) {
 		spriteWidth = -spriteWidth
 	}
 
-	gl.Enable(gl.BLEND)
-	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
-	gl.UseProgram(mv.spriteProgram)
+	defer glstate.Push(glstate.Default)()
 
-	gl.UniformMatrix4fv(mv.locSpriteVP, 1, false, &viewProj[0])
 	// Use render position for smooth interpolated movement
-	gl.Uniform3f(mv.locSpritePos, player.RenderX, player.RenderY, player.RenderZ)
-	gl.Uniform2f(mv.locSpriteSize, spriteWidth, spriteHeight)
-	gl.Uniform4f(mv.locSpriteTint, tint[0], tint[1], tint[2], tint[3])
-	gl.Uniform3f(mv.locSpriteCamRight, camRight[0], camRight[1], camRight[2])
-	gl.Uniform3f(mv.locSpriteCamUp, camUp[0], camUp[1], camUp[2])
-
-	gl.ActiveTexture(gl.TEXTURE0)
-	gl.BindTexture(gl.TEXTURE_2D, player.Textures[spriteID])
-	gl.Uniform1i(mv.locSpriteTex, 0)
-
-	gl.BindVertexArray(player.VAO)
-	gl.DrawArrays(gl.TRIANGLE_STRIP, 0, 4)
-	gl.BindVertexArray(0)
+	pos := [3]float32{player.RenderX, player.RenderY, player.RenderZ}
+	mv.spriteRenderer.Render(viewProj, camRight, camUp, pos, spriteWidth, spriteHeight,
+		player.Textures[spriteID], tint)
 
 	// Render head separately if no composite available
 	if player.HeadSPR != nil && player.HeadACT != nil && len(player.HeadTextures) > 0 && player.ACT != nil {
@@ -2372,68 +1471,42 @@ func (mv *MapViewer) renderPlayerCharacter(viewProj math.Mat4) {
 					totalOffsetX := offsetX + layerX
 
 					// Use render position for smooth interpolated movement
-					headPosX := player.RenderX + totalOffsetX*camRight[0]
-					headPosY := player.RenderY - (offsetY + layerY) + (bodyLayerY * player.SpriteScale * 0.35)
-					headPosZ := player.RenderZ + totalOffsetX*camRight[2]
-
-					gl.Disable(gl.DEPTH_TEST)
-					gl.Uniform3f(mv.locSpritePos, headPosX, headPosY, headPosZ)
-					gl.Uniform2f(mv.locSpriteSize, headWidth, headHeight)
-					gl.BindTexture(gl.TEXTURE_2D, player.HeadTextures[headSpriteID])
-					gl.BindVertexArray(player.VAO)
-					gl.DrawArrays(gl.TRIANGLE_STRIP, 0, 4)
-					gl.BindVertexArray(0)
-					gl.Enable(gl.DEPTH_TEST)
+					headPos := [3]float32{
+						player.RenderX + totalOffsetX*camRight.X,
+						player.RenderY - (offsetY + layerY) + (bodyLayerY * player.SpriteScale * 0.35),
+						player.RenderZ + totalOffsetX*camRight.Z,
+					}
+
+					restore := glstate.Push(glstate.Overlay)
+					mv.spriteRenderer.Render(viewProj, camRight, camUp, headPos, headWidth, headHeight,
+						player.HeadTextures[headSpriteID], tint)
+					restore()
 				}
 			}
 		}
 	}
-
-	gl.Disable(gl.BLEND)
 }
 
 // renderPlayerShadow renders the shadow ellipse on the ground under the player.
 func (mv *MapViewer) renderPlayerShadow(viewProj math.Mat4) {
 	player := mv.Player
-	if player == nil || player.ShadowVAO == 0 || player.ShadowTex == 0 {
+	if player == nil || player.ShadowTex == 0 {
 		return
 	}
 
-	// Enable blending for semi-transparent shadow
-	gl.Enable(gl.BLEND)
-	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
-
-	// Disable depth write so shadow doesn't occlude terrain
-	gl.DepthMask(false)
+	// Blend the semi-transparent shadow without writing depth, so it
+	// doesn't occlude terrain
+	defer glstate.Push(glstate.Translucent)()
 
-	// Use sprite shader (reuse for simplicity)
-	gl.UseProgram(mv.spriteProgram)
+	// Shadow position slightly above ground to avoid z-fighting, centered
+	// on the player: the sprite quad spans 0 to 1 along its up axis. Use
+	// render position for smooth interpolated movement
+	const size = 2 * sprite.DefaultShadowWorldSize
+	pos := [3]float32{player.RenderX, player.RenderY + 0.1, player.RenderZ - size/2}
 
-	// Shadow position slightly above ground to avoid z-fighting
-	// Use render position for smooth interpolated movement
-	shadowY := player.RenderY + 0.1
-
-	// Set uniforms - position the shadow flat on the ground
-	gl.UniformMatrix4fv(mv.locSpriteVP, 1, false, &viewProj[0])
-	gl.Uniform3f(mv.locSpritePos, player.RenderX, shadowY, player.RenderZ)
-	gl.Uniform2f(mv.locSpriteSize, 1.0, 1.0) // Size is baked into VBO
-	gl.Uniform4f(mv.locSpriteTint, 1.0, 1.0, 1.0, 1.0)
 	// Shadow is flat on ground (XZ plane), not camera-facing
-	gl.Uniform3f(mv.locSpriteCamRight, 1.0, 0.0, 0.0) // X axis
-	gl.Uniform3f(mv.locSpriteCamUp, 0.0, 0.0, 1.0)    // Z axis (flat)
-
-	// Bind shadow texture
-	gl.ActiveTexture(gl.TEXTURE0)
-	gl.BindTexture(gl.TEXTURE_2D, player.ShadowTex)
-	gl.Uniform1i(mv.locSpriteTex, 0)
-
-	// Draw shadow quad
-	gl.BindVertexArray(player.ShadowVAO)
-	gl.DrawArrays(gl.TRIANGLE_STRIP, 0, 4)
-	gl.BindVertexArray(0)
-
-	// Restore depth write
-	gl.DepthMask(true)
+	mv.spriteRenderer.Render(viewProj, math.Vec3{X: 1}, math.Vec3{Z: 1}, pos, size, size,
+		player.ShadowTex, [4]float32{1.0, 1.0, 1.0, 1.0})
 }
 
 // UpdatePlayerAnimation advances player animation frame based on time.
@@ -2441,59 +1514,6 @@ func (mv *MapViewer) UpdatePlayerAnimation(deltaMs float32) {
 	character.UpdateAnimation(mv.Player, deltaMs)
 }
 
-// renderWater renders the water plane with transparency.
-func (mv *MapViewer) renderWater(viewProj math.Mat4) {
-	if !mv.hasWater || mv.waterVAO == 0 {
-		return
-	}
-
-	// Update water animation time
-	mv.waterTime += 0.016
-
-	// Enable blending for transparency
-	gl.Enable(gl.BLEND)
-	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
-
-	// Use water shader
-	gl.UseProgram(mv.waterProgram)
-	gl.UniformMatrix4fv(mv.locWaterMVP, 1, false, &viewProj[0])
-
-	// Water color: fully opaque when using texture
-	gl.Uniform4f(mv.locWaterColor, 0.2, 0.4, 0.7, 1.0)
-
-	// Pass animation time and scroll speed
-	gl.Uniform1f(mv.locWaterTime, mv.waterTime)
-	locScrollSpeed := gl.GetUniformLocation(mv.waterProgram, gl.Str("uScrollSpeed\x00"))
-	gl.Uniform1f(locScrollSpeed, mv.waterAnimSpeed)
-
-	// Set up texture if we have water textures loaded
-	if mv.useWaterTex && len(mv.waterTextures) > 0 {
-		// Update animation frame based on time and speed
-		mv.waterFrame = water.CalculateAnimFrame(mv.waterTime, mv.waterAnimSpeed, len(mv.waterTextures))
-
-		// Bind water texture
-		gl.ActiveTexture(gl.TEXTURE0)
-		gl.BindTexture(gl.TEXTURE_2D, mv.waterTextures[mv.waterFrame])
-		gl.Uniform1i(mv.locWaterTex, 0)
-
-		// Tell shader to use texture
-		locUseTexture := gl.GetUniformLocation(mv.waterProgram, gl.Str("uUseTexture\x00"))
-		gl.Uniform1i(locUseTexture, 1)
-	} else {
-		// Tell shader to use procedural water
-		locUseTexture := gl.GetUniformLocation(mv.waterProgram, gl.Str("uUseTexture\x00"))
-		gl.Uniform1i(locUseTexture, 0)
-	}
-
-	// Render water quad
-	gl.BindVertexArray(mv.waterVAO)
-	gl.DrawArrays(gl.TRIANGLE_FAN, 0, 4)
-	gl.BindVertexArray(0)
-
-	// Disable blending
-	gl.Disable(gl.BLEND)
-}
-
 // renderSelectionBbox draws a wireframe bounding box around the selected model.
 func (mv *MapViewer) renderSelectionBbox(viewProj math.Mat4) {
 	if mv.SelectedIdx < 0 || mv.bboxVAO == 0 {
@@ -2521,8 +1541,8 @@ func (mv *MapViewer) renderSelectionBbox(viewProj math.Mat4) {
 	gl.BindBuffer(gl.ARRAY_BUFFER, mv.bboxVBO)
 	gl.BufferSubData(gl.ARRAY_BUFFER, 0, len(vertices)*4, unsafe.Pointer(&vertices[0]))
 
-	// Disable depth test to draw on top
-	gl.Disable(gl.DEPTH_TEST)
+	// Draw on top, without depth testing
+	defer glstate.Push(glstate.Overlay)()
 	gl.LineWidth(2.0)
 
 	// Draw
@@ -2534,8 +1554,6 @@ func (mv *MapViewer) renderSelectionBbox(viewProj math.Mat4) {
 	gl.DrawArrays(gl.LINES, 0, 24)
 	gl.BindVertexArray(0)
 
-	// Re-enable depth test
-	gl.Enable(gl.DEPTH_TEST)
 	gl.LineWidth(1.0)
 }
 
@@ -2580,110 +1598,6 @@ func (mv *MapViewer) PickModelAtScreen(screenX, screenY, viewWidth, viewHeight f
 	return bestIdx
 }
 
-// renderModels renders all placed RSM models.
-func (mv *MapViewer) renderModels(viewProj math.Mat4) {
-	if len(mv.models) == 0 {
-		return
-	}
-
-	gl.UseProgram(mv.modelProgram)
-	gl.Uniform3f(mv.locModelLightDir, mv.lightDir[0], mv.lightDir[1], mv.lightDir[2])
-	gl.Uniform3f(mv.locModelAmbient, mv.ambientColor[0], mv.ambientColor[1], mv.ambientColor[2])
-	gl.Uniform3f(mv.locModelDiffuse, mv.diffuseColor[0], mv.diffuseColor[1], mv.diffuseColor[2])
-	gl.Uniform1i(mv.locModelTexture, 0)
-
-	// Shadow mapping uniforms for models
-	gl.UniformMatrix4fv(mv.locModelLightViewProj, 1, false, &mv.lightViewProj[0])
-	gl.Uniform1i(mv.locModelShadowMap, 2) // Shadow map on texture unit 2
-	if mv.ShadowsEnabled && mv.shadowMap != nil && mv.shadowMap.IsValid() {
-		gl.Uniform1i(mv.locModelShadowsEnabled, 1)
-		mv.shadowMap.BindTexture(gl.TEXTURE2)
-	} else {
-		gl.Uniform1i(mv.locModelShadowsEnabled, 0)
-	}
-
-	// Fog uniforms for models
-	if mv.FogEnabled {
-		gl.Uniform1i(mv.locModelFogUse, 1)
-	} else {
-		gl.Uniform1i(mv.locModelFogUse, 0)
-	}
-	gl.Uniform1f(mv.locModelFogNear, mv.FogNear)
-	gl.Uniform1f(mv.locModelFogFar, mv.FogFar)
-	gl.Uniform3f(mv.locModelFogColor, mv.FogColor[0], mv.FogColor[1], mv.FogColor[2])
-
-	// Point light uniforms (model shader)
-	mv.uploadPointLightsToShader(
-		mv.locModelPointLightPositions,
-		mv.locModelPointLightColors,
-		mv.locModelPointLightRanges,
-		mv.locModelPointLightIntensities,
-		mv.locModelPointLightCount,
-		mv.locModelPointLightsEnabled,
-	)
-
-	gl.ActiveTexture(gl.TEXTURE0)
-
-	// RSW positions are centered at map origin (0,0,0)
-	// GND terrain spans from (0,0) to (mapWidth, mapHeight)
-	// Convert by adding map center offset
-	offsetX := mv.mapWidth / 2
-	offsetZ := mv.mapHeight / 2
-
-	for _, model := range mv.models {
-		if model.vao == 0 || model.indexCount == 0 || !model.Visible {
-			continue
-		}
-
-		// Convert RSW position to GND world coordinates:
-		// - RSW X (0 = center) -> World X = rswX + mapWidth/2
-		// - RSW Y (altitude) -> World Y = -rswY (same convention as GND: positive = lower)
-		// - RSW Z (0 = center) -> World Z = rswZ + mapHeight/2
-		worldX := model.position[0] + offsetX
-		worldY := -model.position[1]
-		worldZ := model.position[2] + offsetZ
-
-		// Build model matrix: translate first, then apply rotation and scale
-		// Order: T * Ry * Rx * Rz * BaseRot * S (applied right-to-left)
-		modelMatrix := math.Identity()
-
-		// Apply translation to world position
-		modelMatrix = modelMatrix.Mul(math.Translate(worldX, worldY, worldZ))
-
-		// Apply RSW rotations (in degrees)
-		// Note: RSW stores rotation as [X, Y, Z] in degrees
-		modelMatrix = modelMatrix.Mul(math.RotateY(model.rotation[1] * gomath.Pi / 180))
-		modelMatrix = modelMatrix.Mul(math.RotateX(model.rotation[0] * gomath.Pi / 180))
-		modelMatrix = modelMatrix.Mul(math.RotateZ(model.rotation[2] * gomath.Pi / 180))
-
-		// Apply per-model scale multiplied by global ModelScale
-		modelMatrix = modelMatrix.Mul(math.Scale(
-			model.scale[0]*mv.ModelScale,
-			model.scale[1]*mv.ModelScale,
-			model.scale[2]*mv.ModelScale,
-		))
-
-		// Combine with view-projection
-		mvp := viewProj.Mul(modelMatrix)
-		gl.UniformMatrix4fv(mv.locModelMVP, 1, false, &mvp[0])
-		gl.UniformMatrix4fv(mv.locModelModel, 1, false, &modelMatrix[0])
-
-		gl.BindVertexArray(model.vao)
-
-		// Render each texture group
-		for _, group := range model.texGroups {
-			tex := mv.fallbackTex
-			if group.TextureIdx >= 0 && group.TextureIdx < len(model.textures) {
-				tex = model.textures[group.TextureIdx]
-			}
-			gl.BindTexture(gl.TEXTURE_2D, tex)
-			gl.DrawElementsWithOffset(gl.TRIANGLES, group.IndexCount, gl.UNSIGNED_INT, uintptr(group.StartIndex*4))
-		}
-	}
-
-	gl.BindVertexArray(0)
-}
-
 // HandleMouseDrag handles mouse drag for camera rotation.
 func (mv *MapViewer) HandleMouseDrag(deltaX, deltaY float32) {
 	if mv.PlayMode {
@@ -2898,38 +1812,11 @@ func (mv *MapViewer) LoadPlayerCharacter(texLoader func(string) ([]byte, error))
 		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
 		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
 
+		w, h := int32(img.Width), int32(img.Height)
+		trackTexture(tex, groupSprites, fmt.Sprintf("%s #%d", sprPath, i), w, h, 1, textureBytes(w, h, 1, false))
 		player.Textures[i] = tex
 	}
 
-	// Create billboard VAO/VBO
-	gl.GenVertexArrays(1, &player.VAO)
-	gl.GenBuffers(1, &player.VBO)
-
-	gl.BindVertexArray(player.VAO)
-	gl.BindBuffer(gl.ARRAY_BUFFER, player.VBO)
-
-	// Billboard quad vertices: position (x,y) + texcoord (u,v)
-	// Positioned at feet, extending upward
-	vertices := []float32{
-		// Position (x, y)  TexCoord (u, v)
-		-0.5, 1.0, 0.0, 0.0, // Top-left (sprite top at Y=1)
-		0.5, 1.0, 1.0, 0.0, // Top-right
-		-0.5, 0.0, 0.0, 1.0, // Bottom-left (sprite bottom at Y=0)
-		0.5, 0.0, 1.0, 1.0, // Bottom-right
-	}
-
-	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.STATIC_DRAW)
-
-	// Position attribute (location 0)
-	gl.VertexAttribPointerWithOffset(0, 2, gl.FLOAT, false, 4*4, 0)
-	gl.EnableVertexAttribArray(0)
-
-	// TexCoord attribute (location 1)
-	gl.VertexAttribPointerWithOffset(1, 2, gl.FLOAT, false, 4*4, 2*4)
-	gl.EnableVertexAttribArray(1)
-
-	gl.BindVertexArray(0)
-
 	// Create shadow
 	mv.createPlayerShadow(player)
 
@@ -3039,6 +1926,8 @@ func (mv *MapViewer) LoadPlayerCharacterFromPath(texLoader func(string) ([]byte,
 		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
 		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
 
+		w, h := int32(img.Width), int32(img.Height)
+		trackTexture(tex, groupSprites, fmt.Sprintf("%s #%d", sprPath, i), w, h, 1, textureBytes(w, h, 1, false))
 		player.Textures[i] = tex
 	}
 
@@ -3059,6 +1948,8 @@ func (mv *MapViewer) LoadPlayerCharacterFromPath(texLoader func(string) ([]byte,
 			gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
 			gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
 
+			w, h := int32(img.Width), int32(img.Height)
+			trackTexture(tex, groupSprites, fmt.Sprintf("%s #%d", headSprPath, i), w, h, 1, textureBytes(w, h, 1, false))
 			player.HeadTextures[i] = tex
 		}
 
@@ -3167,6 +2058,8 @@ func (mv *MapViewer) LoadPlayerCharacterFromPath(texLoader func(string) ([]byte,
 					gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
 					gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
 					gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+					w, h := int32(paddedW), int32(paddedH)
+					trackTexture(tex, groupSprites, sprPath+" composite", w, h, 1, textureBytes(w, h, 1, false))
 
 					frames[frame] = CompositeFrame{
 						Texture: tex,
@@ -3186,27 +2079,6 @@ func (mv *MapViewer) LoadPlayerCharacterFromPath(texLoader func(string) ([]byte,
 		saveAllDirectionsSheet(spr, act, player.HeadSPR, player.HeadACT, "/tmp/all_directions.png")
 	}
 
-	// Create billboard VAO/VBO
-	gl.GenVertexArrays(1, &player.VAO)
-	gl.GenBuffers(1, &player.VBO)
-
-	gl.BindVertexArray(player.VAO)
-	gl.BindBuffer(gl.ARRAY_BUFFER, player.VBO)
-
-	vertices := []float32{
-		-0.5, 1.0, 0.0, 0.0,
-		0.5, 1.0, 1.0, 0.0,
-		-0.5, 0.0, 0.0, 1.0,
-		0.5, 0.0, 1.0, 1.0,
-	}
-
-	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.STATIC_DRAW)
-	gl.VertexAttribPointerWithOffset(0, 2, gl.FLOAT, false, 4*4, 0)
-	gl.EnableVertexAttribArray(0)
-	gl.VertexAttribPointerWithOffset(1, 2, gl.FLOAT, false, 4*4, 2*4)
-	gl.EnableVertexAttribArray(1)
-	gl.BindVertexArray(0)
-
 	// Create shadow
 	mv.createPlayerShadow(player)
 
@@ -3228,7 +2100,7 @@ func (mv *MapViewer) LoadPlayerCharacterFromPath(texLoader func(string) ([]byte,
 	return nil
 }
 
-// createPlayerShadow creates a shadow ellipse texture and VAO for the player.
+// createPlayerShadow creates a shadow ellipse texture for the player.
 func (mv *MapViewer) createPlayerShadow(player *PlayerCharacter) {
 	// Generate circular shadow texture pixels
 	size := sprite.DefaultShadowSize
@@ -3241,23 +2113,7 @@ func (mv *MapViewer) createPlayerShadow(player *PlayerCharacter) {
 		gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(pixels))
 	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
 	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
-
-	// Create shadow VAO/VBO (flat on ground)
-	gl.GenVertexArrays(1, &player.ShadowVAO)
-	gl.GenBuffers(1, &player.ShadowVBO)
-
-	gl.BindVertexArray(player.ShadowVAO)
-	gl.BindBuffer(gl.ARRAY_BUFFER, player.ShadowVBO)
-
-	// Shadow quad on XZ plane (Y=0), centered at origin
-	shadowVerts := sprite.GenerateShadowQuadVertices(sprite.DefaultShadowWorldSize)
-
-	gl.BufferData(gl.ARRAY_BUFFER, len(shadowVerts)*4, gl.Ptr(shadowVerts), gl.STATIC_DRAW)
-	gl.VertexAttribPointerWithOffset(0, 2, gl.FLOAT, false, 4*4, 0)
-	gl.EnableVertexAttribArray(0)
-	gl.VertexAttribPointerWithOffset(1, 2, gl.FLOAT, false, 4*4, 2*4)
-	gl.EnableVertexAttribArray(1)
-	gl.BindVertexArray(0)
+	trackTexture(player.ShadowTex, groupSprites, "player shadow", int32(size), int32(size), 1, textureBytes(int32(size), int32(size), 1, false))
 }
 
 // createProceduralPlayer creates a simple colored player marker when no sprite is available.
@@ -3283,24 +2139,9 @@ func (mv *MapViewer) createProceduralPlayer() error {
 		gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(pixels))
 	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
 	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+	trackTexture(tex, groupSprites, "procedural player", int32(width), int32(height), 1, textureBytes(int32(width), int32(height), 1, false))
 	player.Textures[0] = tex
 
-	// Create billboard VAO/VBO
-	gl.GenVertexArrays(1, &player.VAO)
-	gl.GenBuffers(1, &player.VBO)
-
-	gl.BindVertexArray(player.VAO)
-	gl.BindBuffer(gl.ARRAY_BUFFER, player.VBO)
-
-	vertices := sprite.GenerateBillboardQuadVertices()
-
-	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.STATIC_DRAW)
-	gl.VertexAttribPointerWithOffset(0, 2, gl.FLOAT, false, 4*4, 0)
-	gl.EnableVertexAttribArray(0)
-	gl.VertexAttribPointerWithOffset(1, 2, gl.FLOAT, false, 4*4, 2*4)
-	gl.EnableVertexAttribArray(1)
-	gl.BindVertexArray(0)
-
 	mv.Player = player
 
 	// Create shadow for the player
@@ -3406,17 +2247,17 @@ func (mv *MapViewer) GetLightDir() [3]float32 {
 
 // GetWaterAnimSpeed returns the current water animation speed.
 func (mv *MapViewer) GetWaterAnimSpeed() float32 {
-	return mv.waterAnimSpeed
+	return mv.waterRenderer.AnimSpeed()
 }
 
 // SetWaterAnimSpeed sets the water animation speed.
 func (mv *MapViewer) SetWaterAnimSpeed(speed float32) {
-	mv.waterAnimSpeed = speed
+	mv.waterRenderer.SetAnimSpeed(speed)
 }
 
 // HasWater returns whether the map has water.
 func (mv *MapViewer) HasWater() bool {
-	return mv.hasWater
+	return mv.waterRenderer.HasWater()
 }
 
 // --- Model Animation Functions ---
@@ -3432,47 +2273,14 @@ func (mv *MapViewer) UpdateModelAnimation(deltaMs float32) bool {
 
 	// Rebuild all animated models with new time
 	for _, model := range mv.animatedModels {
-		if model.rsm != nil && model.Visible {
-			mv.rebuildAnimatedModel(model, mv.modelAnimTime)
+		if model.Visible {
+			mv.modelRenderer.Animate(model.MapModel, mv.modelAnimTime)
 		}
 	}
 
 	return true
 }
 
-// rebuildAnimatedModel rebuilds a single model's mesh at the given animation time.
-func (mv *MapViewer) rebuildAnimatedModel(model *MapModel, animTimeMs float32) {
-	if model.rsm == nil || model.rswRef == nil {
-		return
-	}
-
-	// Loop animation time based on model's animation length
-	loopedTime := animTimeMs
-	if model.animLength > 0 {
-		loopedTime = float32(int(animTimeMs) % int(model.animLength))
-	}
-
-	// Build vertices and indices at current animation time
-	vertices, indices, groups := mv.buildAnimatedModelMesh(model.rsm, model.rswRef, loopedTime)
-	if len(vertices) == 0 {
-		return
-	}
-
-	// Update GPU buffers
-	gl.BindVertexArray(model.vao)
-
-	gl.BindBuffer(gl.ARRAY_BUFFER, model.vbo)
-	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*int(unsafe.Sizeof(rsmmodel.Vertex{})), gl.Ptr(vertices), gl.DYNAMIC_DRAW)
-
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, model.ebo)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.DYNAMIC_DRAW)
-
-	model.indexCount = int32(len(indices))
-	model.texGroups = groups
-
-	gl.BindVertexArray(0)
-}
-
 // PlayModelAnimation starts model animations.
 func (mv *MapViewer) PlayModelAnimation() {
 	mv.modelAnimPlaying = true
@@ -3509,7 +2317,7 @@ func (mv *MapViewer) GetModelInfo(idx int) (name string, pos, rot, scale [3]floa
 		return "", [3]float32{}, [3]float32{}, [3]float32{}, [6]float32{}
 	}
 	m := mv.models[idx]
-	return m.modelName, m.position, m.rotation, m.scale, m.bbox
+	return m.Name(), m.position, m.rotation, m.scale, m.bbox
 }
 
 // SetDebugMode enables/disables debug output for model positioning.
@@ -3527,63 +2335,51 @@ func (mv *MapViewer) GetDiffuseColor() [3]float32 {
 	return mv.diffuseColor
 }
 
-// Destroy frees all GPU resources.
-func (mv *MapViewer) Destroy() {
-	mv.clearTerrain()
-
-	if mv.fallbackTex != 0 {
-		gl.DeleteTextures(1, &mv.fallbackTex)
+// freePlayer releases the play mode character's GPU resources.
+func (mv *MapViewer) freePlayer() {
+	p := mv.Player
+	if p == nil {
+		return
 	}
-	if mv.terrainProgram != 0 {
-		gl.DeleteProgram(mv.terrainProgram)
+	for _, tex := range p.Textures {
+		freeTexture(tex)
 	}
-	if mv.modelProgram != 0 {
-		gl.DeleteProgram(mv.modelProgram)
+	for _, tex := range p.HeadTextures {
+		freeTexture(tex)
 	}
+	for _, frames := range p.CompositeFrames {
+		for _, f := range frames {
+			freeTexture(f.Texture)
+		}
+	}
+	freeTexture(p.ShadowTex)
+	mv.Player = nil
+}
+
+// Destroy frees all GPU resources.
+func (mv *MapViewer) Destroy() {
+	mv.clearTerrain()
+	mv.terrainTimer.destroy()
+	mv.freePlayer()
+	freeBuffer(mv.bboxVBO)
+	freeBuffer(mv.tileGridVBO)
+	freeBuffer(mv.tileGridEBO)
+
+	mv.terrainRenderer.Destroy()
+	mv.modelRenderer.Destroy()
+	mv.waterRenderer.Destroy()
+	mv.spriteRenderer.Destroy()
+	mv.textures.Destroy()
+	freeTexture(mv.fallbackTex)
 	if mv.fbo != 0 {
 		gl.DeleteFramebuffers(1, &mv.fbo)
 	}
-	if mv.colorTexture != 0 {
-		gl.DeleteTextures(1, &mv.colorTexture)
-	}
+	freeTexture(mv.colorTexture)
 	if mv.depthRBO != 0 {
 		gl.DeleteRenderbuffers(1, &mv.depthRBO)
 	}
 }
 
-// createWaterPlane creates a water surface plane at the specified height.
-func (mv *MapViewer) createWaterPlane(_ *formats.GND, waterLevel float32) {
-	// Delete old water if exists
-	if mv.waterVAO != 0 {
-		gl.DeleteVertexArrays(1, &mv.waterVAO)
-		gl.DeleteBuffers(1, &mv.waterVBO)
-	}
-
-	// Build water plane geometry using water package
-	plane := water.BuildPlaneWithPadding(
-		mv.minBounds[0], mv.maxBounds[0],
-		mv.minBounds[2], mv.maxBounds[2],
-		waterLevel, water.DefaultPadding,
-	)
-
-	// Create VAO/VBO
-	gl.GenVertexArrays(1, &mv.waterVAO)
-	gl.GenBuffers(1, &mv.waterVBO)
-
-	gl.BindVertexArray(mv.waterVAO)
-	gl.BindBuffer(gl.ARRAY_BUFFER, mv.waterVBO)
-	gl.BufferData(gl.ARRAY_BUFFER, len(plane.Vertices)*4, gl.Ptr(plane.Vertices), gl.STATIC_DRAW)
-
-	// Position attribute
-	gl.EnableVertexAttribArray(0)
-	gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, 3*4, 0)
-
-	gl.BindVertexArray(0)
-
-	mv.waterLevel = waterLevel
-	mv.hasWater = true
-}
-
 // PrintDiagnostics outputs map loading diagnostics to console.
 func (mv *MapViewer) PrintDiagnostics() {
 	d := mv.Diagnostics
@@ -3629,6 +2425,17 @@ func (mv *MapViewer) PrintDiagnostics() {
 		}
 	}
 
+	if len(d.ParseWarnings) > 0 {
+		fmt.Println("\nDamaged files (first 10):")
+		for i, w := range d.ParseWarnings {
+			if i >= 10 {
+				fmt.Printf("  ... and %d more\n", len(d.ParseWarnings)-10)
+				break
+			}
+			fmt.Printf("  - %s\n", w)
+		}
+	}
+
 	fmt.Println("\nLighting:")
 	fmt.Printf("  Light Dir:       (%.2f, %.2f, %.2f)\n", mv.lightDir[0], mv.lightDir[1], mv.lightDir[2])
 	fmt.Printf("  Ambient:         (%.2f, %.2f, %.2f)\n", mv.ambientColor[0], mv.ambientColor[1], mv.ambientColor[2])
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+	"github.com/sqweek/dialog"
+
+	"github.com/Faultbox/midgard-ro/pkg/grf"
+)
+
+// Steps of the merge wizard.
+const (
+	mergeStepArchives = iota
+	mergeStepConflicts
+	mergeStepWrite
+)
+
+// mergeWizard is the state of the Tools > Merge Archives window, which
+// combines archives into one: pick them in priority order, settle the files
+// several of them hold, then write the merged archive.
+type mergeWizard struct {
+	open   bool
+	step   int
+	paths  []string    // Archives to merge, lowest priority first
+	picked chan string // Files chosen in the open and save dialogs
+	saving bool        // The dialog open is the output's
+
+	merge     *grf.Merge // Opened for the conflicts step
+	conflicts []grf.Conflict
+	filter    string
+	output    string
+
+	// Writing runs in the background; the window follows it through these
+	writing  atomic.Bool
+	canceled atomic.Bool
+	done     atomic.Int64
+	total    atomic.Int64
+	result   chan error
+	status   string // Outcome of the last write
+}
+
+// openMergeWizard shows the merge wizard, starting from the open archive.
+func (app *App) openMergeWizard() {
+	w := &app.mergeWizard
+	if w.open {
+		return
+	}
+	*w = mergeWizard{open: true, picked: make(chan string, 1), result: make(chan error, 1)}
+	if app.grfPath != "" {
+		w.paths = []string{app.grfPath}
+	}
+}
+
+// closeMergeWizard releases the archives the wizard has open. A write in
+// progress is canceled.
+func (app *App) closeMergeWizard() {
+	w := &app.mergeWizard
+	if w.writing.Load() {
+		w.canceled.Store(true)
+		<-w.result
+		w.writing.Store(false)
+	}
+	if w.merge != nil {
+		w.merge.Close()
+		w.merge = nil
+	}
+	w.open = false
+}
+
+// browseMergeFile runs a file dialog without blocking the UI; the file
+// chosen arrives on w.picked.
+func (w *mergeWizard) browseMergeFile(save bool) {
+	w.saving = save
+	go func() {
+		d := dialog.File().Filter("GRF Archives", "grf", "gpf").Filter("All Files", "*")
+		var path string
+		var err error
+		if save {
+			path, err = d.Title("Save Merged Archive").Save()
+		} else {
+			path, err = d.Title("Add Archive to Merge").Load()
+		}
+		if err != nil {
+			if err != dialog.ErrCancelled {
+				fmt.Fprintf(os.Stderr, "File dialog error: %v\n", err)
+			}
+			return
+		}
+		w.picked <- path
+	}()
+}
+
+// renderMergeWizard renders the merge wizard window, if open.
+func (app *App) renderMergeWizard() {
+	w := &app.mergeWizard
+	if !w.open {
+		return
+	}
+	select {
+	case path := <-w.picked:
+		if w.saving {
+			w.output = path
+		} else {
+			w.paths = append(w.paths, path)
+		}
+	case err := <-w.result:
+		w.writing.Store(false)
+		switch {
+		case errors.Is(err, grf.ErrMergeCanceled):
+			w.status = "Merge canceled"
+		case err != nil:
+			w.status = fmt.Sprintf("Merge failed: %v", err)
+		default:
+			w.status = fmt.Sprintf("Merged %d files into %s", w.total.Load(), w.output)
+			app.showNotification(w.status)
+		}
+	default:
+	}
+
+	open := true
+	imgui.SetNextWindowSizeV(imgui.NewVec2(640, 480), imgui.CondFirstUseEver)
+	if imgui.BeginV("Merge Archives", &open, 0) {
+		switch w.step {
+		case mergeStepArchives:
+			app.renderMergeArchives()
+		case mergeStepConflicts:
+			app.renderMergeConflicts()
+		case mergeStepWrite:
+			app.renderMergeWrite()
+		}
+	}
+	imgui.End()
+	if !open {
+		app.closeMergeWizard()
+	}
+}
+
+// renderMergeArchives renders the first step: the archives to merge, in
+// priority order.
+func (app *App) renderMergeArchives() {
+	w := &app.mergeWizard
+	imgui.Text("Archives to merge")
+	imgui.TextDisabled("Where archives hold the same file, the one lower in the list wins")
+	imgui.Separator()
+
+	remove := -1
+	for i, path := range w.paths {
+		imgui.PushIDInt(int32(i))
+		imgui.BeginDisabledV(i == 0)
+		if imgui.ArrowButton("##up", imgui.DirUp) {
+			w.paths[i-1], w.paths[i] = w.paths[i], w.paths[i-1]
+		}
+		imgui.EndDisabled()
+		imgui.SameLine()
+		imgui.BeginDisabledV(i == len(w.paths)-1)
+		if imgui.ArrowButton("##down", imgui.DirDown) {
+			w.paths[i+1], w.paths[i] = w.paths[i], w.paths[i+1]
+		}
+		imgui.EndDisabled()
+		imgui.SameLine()
+		if imgui.SmallButton("Remove") {
+			remove = i
+		}
+		imgui.SameLine()
+		imgui.Text(fmt.Sprintf("%d. %s", i+1, path))
+		imgui.PopID()
+	}
+	if remove >= 0 {
+		w.paths = append(w.paths[:remove], w.paths[remove+1:]...)
+	}
+
+	if imgui.Button("Add Archive...") {
+		w.browseMergeFile(false)
+	}
+	imgui.Separator()
+	imgui.BeginDisabledV(len(w.paths) < 2)
+	if imgui.Button("Next: Conflicts") {
+		m, err := grf.OpenMerge(w.paths...)
+		if err != nil {
+			w.status = err.Error()
+		} else {
+			w.merge = m
+			w.conflicts = m.Conflicts()
+			w.status = ""
+			w.step = mergeStepConflicts
+		}
+	}
+	imgui.EndDisabled()
+	if w.status != "" {
+		imgui.TextColored(reportSeverityColor(ReportSeverityError), w.status)
+	}
+}
+
+// renderMergeConflicts renders the second step: the files several archives
+// hold, each with the archive its copy is kept from.
+func (app *App) renderMergeConflicts() {
+	w := &app.mergeWizard
+	names := make([]string, len(w.paths))
+	for i, path := range w.paths {
+		names[i] = filepath.Base(path)
+	}
+
+	imgui.Text(fmt.Sprintf("%d files are in more than one archive", len(w.conflicts)))
+	imgui.TextDisabled("Each keeps the copy of the archive picked, by default the one of highest priority")
+	imgui.SetNextItemWidth(-1)
+	imgui.InputTextWithHint("##mergefilter", "Filter conflicts...", &w.filter, 0, nil)
+
+	shown := w.conflicts
+	if w.filter != "" {
+		filter := strings.ToLower(w.filter)
+		shown = nil
+		for _, c := range w.conflicts {
+			if strings.Contains(c.Name, filter) {
+				shown = append(shown, c)
+			}
+		}
+	}
+
+	flags := imgui.TableFlagsBorders | imgui.TableFlagsRowBg | imgui.TableFlagsScrollY | imgui.TableFlagsResizable
+	avail := imgui.ContentRegionAvail()
+	if imgui.BeginTableV("##conflicts", 2, flags, imgui.NewVec2(0, avail.Y-imgui.FrameHeightWithSpacing()), 0) {
+		imgui.TableSetupScrollFreeze(0, 1)
+		imgui.TableSetupColumn("File")
+		imgui.TableSetupColumn("Keep from")
+		imgui.TableHeadersRow()
+
+		clipper := imgui.NewListClipper()
+		defer clipper.Destroy()
+		clipper.Begin(int32(len(shown)))
+		for clipper.Step() {
+			for i := clipper.DisplayStart(); i < clipper.DisplayEnd(); i++ {
+				c := shown[i]
+				imgui.TableNextRow()
+				imgui.TableNextColumn()
+				imgui.Text(c.Name)
+				imgui.TableNextColumn()
+				imgui.SetNextItemWidth(-1)
+				source := w.merge.Source(c.Name)
+				if imgui.BeginCombo("##keep"+c.Name, names[source]) {
+					for _, a := range c.Archives {
+						if imgui.SelectableBoolV(fmt.Sprintf("%d. %s", a+1, names[a]), a == source, 0, imgui.NewVec2(0, 0)) {
+							w.merge.Pick(c.Name, a)
+						}
+					}
+					imgui.EndCombo()
+				}
+			}
+		}
+		imgui.EndTable()
+	}
+
+	if imgui.Button("Back") {
+		w.merge.Close()
+		w.merge = nil
+		w.step = mergeStepArchives
+	}
+	imgui.SameLine()
+	if imgui.Button("Next: Write") {
+		if w.output == "" {
+			w.output = filepath.Join(filepath.Dir(w.paths[0]), "merged.grf")
+		}
+		w.status = ""
+		w.step = mergeStepWrite
+	}
+}
+
+// renderMergeWrite renders the last step: where the merged archive goes,
+// and the progress of writing it.
+func (app *App) renderMergeWrite() {
+	w := &app.mergeWizard
+	writing := w.writing.Load()
+
+	imgui.Text("Merged archive")
+	imgui.BeginDisabledV(writing)
+	imgui.SetNextItemWidth(-90)
+	imgui.InputTextWithHint("##mergeoutput", "Output path", &w.output, 0, nil)
+	imgui.SameLine()
+	if imgui.Button("Browse...") {
+		w.browseMergeFile(true)
+	}
+	imgui.EndDisabled()
+
+	if writing {
+		done, total := w.done.Load(), w.total.Load()
+		fraction := float32(0)
+		if total > 0 {
+			fraction = float32(done) / float32(total)
+		}
+		imgui.ProgressBarV(fraction, imgui.NewVec2(-1, 0), fmt.Sprintf("%d / %d files", done, total))
+		if imgui.Button("Cancel") {
+			w.canceled.Store(true)
+		}
+		return
+	}
+
+	if imgui.Button("Back") {
+		w.step = mergeStepConflicts
+	}
+	imgui.SameLine()
+	imgui.BeginDisabledV(w.output == "")
+	if imgui.Button("Merge") {
+		w.status = ""
+		w.done.Store(0)
+		w.total.Store(0)
+		w.canceled.Store(false)
+		w.writing.Store(true)
+		m, output := w.merge, w.output
+		go func() {
+			n, err := m.WriteTo(output, func(done, total int) bool {
+				w.done.Store(int64(done))
+				w.total.Store(int64(total))
+				return !w.canceled.Load()
+			})
+			if err == nil {
+				w.total.Store(int64(n))
+			}
+			w.result <- err
+		}()
+	}
+	imgui.EndDisabled()
+	if w.status != "" {
+		imgui.Text(w.status)
+	}
+}
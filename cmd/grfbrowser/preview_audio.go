@@ -15,7 +15,7 @@ import (
 
 // loadAudioPreview loads a WAV file for audio preview.
 func (app *App) loadAudioPreview(path string) {
-	data, err := app.archive.Read(path)
+	data, err := app.readFile(path)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error reading audio file: %v\n", err)
 		return
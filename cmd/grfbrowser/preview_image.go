@@ -9,7 +9,6 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/AllenDang/cimgui-go/backend"
 	"github.com/AllenDang/cimgui-go/imgui"
 	"golang.org/x/text/encoding/korean"
 	"golang.org/x/text/transform"
@@ -24,7 +23,7 @@ func decodeTGA(data []byte) (image.Image, error) {
 
 // loadImagePreview loads an image file (BMP, TGA, JPG, PNG) for preview.
 func (app *App) loadImagePreview(path string) {
-	data, err := app.archive.Read(path)
+	data, err := app.readFile(path)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error reading image: %v\n", err)
 		return
@@ -58,13 +57,13 @@ func (app *App) loadImagePreview(path string) {
 	}
 
 	// Create texture
-	app.previewImage = backend.NewTextureFromRgba(rgba)
+	app.previewImage = newPreviewTexture(rgba, groupUI, app.previewPath)
 	app.previewImgSize = [2]int{bounds.Dx(), bounds.Dy()}
 }
 
 // loadTextPreview loads a text file for preview.
 func (app *App) loadTextPreview(path string) {
-	data, err := app.archive.Read(path)
+	data, err := app.readFile(path)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error reading text file: %v\n", err)
 		return
@@ -90,7 +89,7 @@ func (app *App) loadTextPreview(path string) {
 
 // loadHexPreview loads raw bytes for hex preview.
 func (app *App) loadHexPreview(path string) {
-	data, err := app.archive.Read(path)
+	data, err := app.readFile(path)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error reading file: %v\n", err)
 		return
@@ -6,18 +6,19 @@ import (
 	"image"
 	"image/color"
 	"os"
+	"path/filepath"
 	"strings"
 
-	"github.com/AllenDang/cimgui-go/backend"
 	"github.com/AllenDang/cimgui-go/imgui"
 
 	"github.com/Faultbox/midgard-ro/internal/engine/character"
+	"github.com/Faultbox/midgard-ro/internal/engine/texfilter"
 	"github.com/Faultbox/midgard-ro/pkg/formats"
 )
 
 // loadGATPreview loads a GAT file for preview.
 func (app *App) loadGATPreview(path string) {
-	data, err := app.archive.Read(path)
+	data, err := app.readFile(path)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error reading GAT file: %v\n", err)
 		return
@@ -41,8 +42,12 @@ func (app *App) createGATTexture() {
 	if app.previewGAT == nil {
 		return
 	}
+	app.previewGATTex = newPreviewTexture(gatImage(app.previewGAT), groupUI, app.previewPath)
+}
 
-	gat := app.previewGAT
+// gatImage draws one pixel per GAT cell, colored by cell type, with north
+// up. Also used as the minimap of headless exports.
+func gatImage(gat *formats.GAT) *image.RGBA {
 	width := int(gat.Width)
 	height := int(gat.Height)
 
@@ -79,9 +84,7 @@ func (app *App) createGATTexture() {
 			rgba.SetRGBA(x, height-1-y, c)
 		}
 	}
-
-	// Create texture
-	app.previewGATTex = backend.NewTextureFromRgba(rgba)
+	return rgba
 }
 
 // renderGATPreview renders the GAT visualization.
@@ -169,7 +172,7 @@ func (app *App) renderGATPreview() {
 
 // loadGNDPreview loads a GND file for preview.
 func (app *App) loadGNDPreview(path string) {
-	data, err := app.archive.Read(path)
+	data, err := app.readFile(path)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error reading GND file: %v\n", err)
 		return
@@ -260,7 +263,7 @@ func (app *App) createGNDTexture() {
 	}
 
 	// Create texture
-	app.previewGNDTex = backend.NewTextureFromRgba(rgba)
+	app.previewGNDTex = newPreviewTexture(rgba, groupUI, app.previewPath)
 }
 
 // renderGNDPreview renders the GND visualization.
@@ -343,7 +346,7 @@ func (app *App) renderGNDPreview() {
 
 // loadRSWPreview loads a RSW file for preview.
 func (app *App) loadRSWPreview(path string) {
-	data, err := app.archive.Read(path)
+	data, err := app.readFile(path)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error reading RSW file: %v\n", err)
 		return
@@ -356,6 +359,7 @@ func (app *App) loadRSWPreview(path string) {
 	}
 
 	app.previewRSW = rsw
+	app.mapEditPath = ""
 
 	// Auto-reload 3D view if already in 3D mode
 	if app.map3DViewMode {
@@ -382,8 +386,22 @@ func (app *App) renderRSWPreview() {
 		app.initMap3DView()
 	}
 	imgui.SameLine()
+	if app.mapReportMode {
+		if imgui.Button("2D Info") {
+			app.mapReportMode = false
+		}
+		imgui.SameLine()
+		imgui.TextDisabled("Report")
+		imgui.Separator()
+		app.renderMapReport()
+		return
+	}
 	imgui.TextDisabled("2D Info")
 	imgui.SameLine()
+	if imgui.Button("Report") {
+		app.mapReportMode = true
+	}
+	imgui.SameLine()
 	// Max models slider
 	imgui.SetNextItemWidth(100)
 	maxModels := int32(app.maxModelsLimit)
@@ -541,7 +559,7 @@ func (app *App) initMap3DView() {
 	}
 
 	// Load GND data
-	gndData, err := app.archive.Read(gndPath)
+	gndData, err := app.readFile(gndPath)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error reading GND: %v\n", err)
 		return
@@ -569,7 +587,7 @@ func (app *App) initMap3DView() {
 
 	// Texture loader function
 	texLoader := func(path string) ([]byte, error) {
-		return app.archive.Read(path)
+		return app.readFile(path)
 	}
 
 	// Load map into viewer
@@ -615,6 +633,9 @@ func (app *App) renderMap3DView() {
 	if imgui.IsKeyDown(imgui.KeyQ) {
 		up = -1
 	}
+	if imgui.CurrentIO().WantTextInput() {
+		forward, right, up = 0, 0, 0 // Letters typed into a text field
+	}
 
 	if app.mapViewer.PlayMode {
 		// Always call in Play mode to update IsMoving state
@@ -633,6 +654,10 @@ func (app *App) renderMap3DView() {
 	if height < 100 {
 		height = 100
 	}
+	// Split the space with the compare map, if one is shown
+	if app.compareScene != nil {
+		width = max(100, (width-imgui.CurrentStyle().ItemSpacing().X)/2)
+	}
 
 	// Resize render target to match display size (prevents blurry scaling)
 	app.mapViewer.Resize(int32(width), int32(height))
@@ -664,12 +689,31 @@ func (app *App) renderMap3DView() {
 
 	// Get item position for click-to-select
 	itemMin := imgui.ItemRectMin()
+	hovered := imgui.IsItemHovered()
+
+	if app.compareScene != nil {
+		imgui.SameLine()
+		app.renderCompareView(width, height)
+	}
+
+	// Gizmo handles of the selected model take the mouse first
+	app.renderModelGizmo(itemMin, width, height, hovered)
+	if (hovered || app.focusPanel == panelPreview) && !app.mapViewer.PlayMode && !imgui.CurrentIO().WantTextInput() {
+		app.handleMapEditKeys()
+	}
+	dx, dy, wheel := app.cameraKeys()
+	if dx != 0 || dy != 0 {
+		app.mapViewer.HandleMouseDrag(dx, dy)
+	}
+	if wheel != 0 {
+		app.mapViewer.HandleMouseWheel(wheel)
+	}
 
 	// Handle mouse input on the image
-	if imgui.IsItemHovered() {
+	if hovered {
 		// Mouse drag for rotation
 		mousePos := imgui.MousePos()
-		if imgui.IsMouseDragging(imgui.MouseButtonLeft) {
+		if imgui.IsMouseDragging(imgui.MouseButtonLeft) && !app.mapViewer.GizmoDragging() {
 			deltaX := mousePos.X - mapViewerLastMousePos.X
 			deltaY := mousePos.Y - mapViewerLastMousePos.Y
 			app.mapViewer.HandleMouseDrag(deltaX, deltaY)
@@ -717,6 +761,138 @@ func (app *App) renderMap3DView() {
 	}
 }
 
+// renderModelGizmo draws the selected model's gizmo over the map image at
+// itemMin and drives drags of its handles.
+func (app *App) renderModelGizmo(itemMin imgui.Vec2, width, height float32, hovered bool) {
+	mv := app.mapViewer
+	mouse := imgui.MousePos()
+	localX, localY := mouse.X-itemMin.X, mouse.Y-itemMin.Y
+
+	if mv.GizmoDragging() {
+		if imgui.IsMouseDown(imgui.MouseButtonLeft) {
+			mv.UpdateGizmoDrag(localX, localY, width, height, imgui.CurrentIO().KeyShift())
+		} else {
+			mv.EndGizmoDrag()
+		}
+	} else if hovered && imgui.IsMouseClickedBool(imgui.MouseButtonLeft) && mv.BeginGizmoDrag(localX, localY, width, height) {
+		mapViewerWasDragging = true // Don't treat the release as a click
+	}
+
+	dl := imgui.WindowDrawList()
+	for _, h := range mv.GizmoHandles(width, height) {
+		col := imgui.ColorU32Vec4(imgui.NewVec4(h.Color[0], h.Color[1], h.Color[2], h.Color[3]))
+		thickness := float32(2)
+		if h.Active {
+			thickness = 4
+		}
+		origin := imgui.NewVec2(itemMin.X+h.OriginX, itemMin.Y+h.OriginY)
+		end := imgui.NewVec2(itemMin.X+h.X, itemMin.Y+h.Y)
+		dl.AddLineV(origin, end, col, thickness)
+		dl.AddCircleFilledV(end, gizmoHandleRadius, col, 12)
+	}
+}
+
+// handleMapEditKeys applies the map editing shortcuts: 1/2/3 pick the
+// gizmo mode, Ctrl+D duplicates the selected model, Delete removes it and
+// Escape deselects it.
+func (app *App) handleMapEditKeys() {
+	mv := app.mapViewer
+	for key, mode := range map[imgui.Key]GizmoMode{imgui.Key1: GizmoTranslate, imgui.Key2: GizmoRotate, imgui.Key3: GizmoScale} {
+		if imgui.IsKeyPressedBool(key) {
+			mv.Gizmo = mode
+		}
+	}
+	if mv.SelectedIdx < 0 {
+		return
+	}
+	if imgui.CurrentIO().KeyCtrl() && imgui.IsKeyPressedBool(imgui.KeyD) {
+		app.duplicateSelectedModel()
+	}
+	if imgui.IsKeyPressedBool(imgui.KeyDelete) {
+		mv.DeleteModel(mv.SelectedIdx)
+	}
+	if imgui.IsKeyPressedBool(imgui.KeyEscape) {
+		mv.SelectedIdx = -1
+		app.showPropertiesPanel = false
+	}
+}
+
+// duplicateSelectedModel copies the selected model and selects the copy.
+func (app *App) duplicateSelectedModel() {
+	if idx := app.mapViewer.DuplicateModel(app.mapViewer.SelectedIdx); idx >= 0 {
+		app.mapViewer.SelectedIdx = idx
+	} else {
+		app.showNotification("Model can't be duplicated")
+	}
+}
+
+// mapSavePath returns where Save RSW writes the edited map: the chosen
+// path, or the map's name under data/ in the screenshot directory, so the
+// folder can be dropped into a client.
+func (app *App) mapSavePath() string {
+	if app.mapEditPath != "" {
+		return app.mapEditPath
+	}
+	name := filepath.Base(strings.ReplaceAll(app.selectedOriginalPath, "\\", "/"))
+	if !strings.EqualFold(filepath.Ext(name), ".rsw") {
+		name = "map.rsw"
+	}
+	return filepath.Join(app.screenshotDir, "data", name)
+}
+
+// saveEditedRSW writes the edited map's RSW to mapSavePath.
+func (app *App) saveEditedRSW() error {
+	data, err := app.mapViewer.EncodeRSW()
+	if err != nil {
+		return err
+	}
+	path := app.mapSavePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create output dir: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("write RSW: %w", err)
+	}
+	app.mapViewer.Modified = false
+	return nil
+}
+
+// renderMapEditSection renders the map editing controls: gizmo mode and
+// saving the edited RSW.
+func (app *App) renderMapEditSection() {
+	mv := app.mapViewer
+	imgui.Text("Edit")
+	imgui.Separator()
+
+	for _, mode := range []GizmoMode{GizmoTranslate, GizmoRotate, GizmoScale} {
+		if mode != GizmoTranslate {
+			imgui.SameLine()
+		}
+		if imgui.RadioButtonBool(mode.String(), mv.Gizmo == mode) {
+			mv.Gizmo = mode
+		}
+	}
+	imgui.TextDisabled("1/2/3 mode, Shift scales evenly")
+	imgui.TextDisabled("Ctrl+D duplicate, Del delete")
+
+	path := app.mapSavePath()
+	imgui.SetNextItemWidth(-1)
+	if imgui.InputTextWithHint("##RSWSavePath", "Save path", &path, 0, nil) {
+		app.mapEditPath = path
+	}
+	label := "Save RSW"
+	if mv.Modified {
+		label = "Save RSW*"
+	}
+	if imgui.ButtonV(label+"##SaveRSW", imgui.NewVec2(-1, 0)) {
+		if err := app.saveEditedRSW(); err != nil {
+			app.showNotification(fmt.Sprintf("Save failed: %v", err))
+		} else {
+			app.showNotification("Saved " + app.mapSavePath())
+		}
+	}
+}
+
 // renderMapControlsPanel renders the map controls in the right panel.
 func (app *App) renderMapControlsPanel() {
 	if app.mapViewer == nil {
@@ -847,7 +1023,7 @@ func (app *App) renderMapControlsPanel() {
 				}
 
 				texLoader := func(path string) ([]byte, error) {
-					return app.archive.Read(path)
+					return app.readFile(path)
 				}
 
 				if spritePath != "" {
@@ -948,6 +1124,11 @@ func (app *App) renderMapControlsPanel() {
 	imgui.Spacing()
 	imgui.Spacing()
 
+	app.renderCompareSection()
+
+	imgui.Spacing()
+	imgui.Spacing()
+
 	// Lighting section
 	imgui.Text("Lighting")
 	imgui.Separator()
@@ -1010,6 +1191,52 @@ func (app *App) renderMapControlsPanel() {
 		imgui.SetTooltip("Show GAT tile grid (Korangar-style debug)\nGreen=Walkable, Red=Blocked, Blue=Water")
 	}
 
+	// Terrain texture array vs per-texture binds, with GPU timing to compare
+	useTexArray := app.mapViewer.UseTextureArray()
+	if imgui.Checkbox("Terrain Texture Array", &useTexArray) {
+		app.mapViewer.SetUseTextureArray(useTexArray)
+	}
+	imgui.SameLineV(0, 5)
+	imgui.TextDisabled("(?)")
+	if imgui.IsItemHovered() {
+		imgui.SetTooltip("Draw the terrain in one call from a texture array\ninstead of one bind and draw per ground texture")
+	}
+
+	// Texture filtering, as the client's texture_filter and anisotropy
+	filter := texfilter.Current()
+	changed := imgui.Checkbox("Mipmaps", &filter.Mipmaps)
+	imgui.SameLine()
+	imgui.BeginDisabledV(!filter.Mipmaps)
+	changed = imgui.Checkbox("Trilinear", &filter.Trilinear) || changed
+	imgui.EndDisabled()
+	imgui.SameLineV(0, 5)
+	imgui.TextDisabled("(?)")
+	if imgui.IsItemHovered() {
+		imgui.SetTooltip("Mipmaps: sample smaller copies of distant textures\nTrilinear: blend between them instead of switching")
+	}
+	imgui.SetNextItemWidth(120)
+	if imgui.BeginCombo("Anisotropy", fmt.Sprintf("%dx", filter.Anisotropy)) {
+		for level := 1; level <= texfilter.MaxAnisotropy; level *= 2 {
+			if imgui.SelectableBoolV(fmt.Sprintf("%dx", level), level == filter.Anisotropy, 0, imgui.NewVec2(0, 0)) {
+				filter.Anisotropy = level
+				changed = true
+			}
+		}
+		imgui.EndCombo()
+	}
+	imgui.SameLineV(0, 5)
+	imgui.TextDisabled("(?)")
+	if imgui.IsItemHovered() {
+		imgui.SetTooltip("Keeps the ground sharp at grazing angles; 1x turns it off")
+	}
+	if changed {
+		app.mapViewer.SetTextureFilter(filter.Normalized())
+	}
+
+	drawCalls, layers, layerSize, gpuMs := app.mapViewer.TerrainStats()
+	imgui.TextDisabled(fmt.Sprintf("Terrain: %d draw calls, %.2f ms GPU", drawCalls, gpuMs))
+	imgui.TextDisabled(fmt.Sprintf("Texture array: %d layers at %dx%d", layers, layerSize, layerSize))
+
 	imgui.Spacing()
 	imgui.Spacing()
 
@@ -1067,6 +1294,14 @@ func (app *App) renderMapControlsPanel() {
 	imgui.Spacing()
 	imgui.Spacing()
 
+	// Editing section (not while walking the map)
+	if !app.mapViewer.PlayMode {
+		app.renderMapEditSection()
+
+		imgui.Spacing()
+		imgui.Spacing()
+	}
+
 	// View toggle
 	if imgui.ButtonV("2D Info View", imgui.NewVec2(-1, 0)) {
 		app.map3DViewMode = false
@@ -1171,6 +1406,11 @@ func (app *App) renderMapControlsPanel() {
 							app.mapViewer.FocusOnModel(modelIdx)
 						}
 					}
+					if itemEntered(imgui.KeyChord(imgui.ModShift)) {
+						app.mapViewer.SelectedIdx = modelIdx
+						app.showPropertiesPanel = true
+						app.mapViewer.FocusOnModel(modelIdx)
+					}
 				}
 				imgui.TreePop()
 			}
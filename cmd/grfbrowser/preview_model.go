@@ -3,6 +3,7 @@ package main
 
 import (
 	"fmt"
+	gomath "math"
 	"os"
 	"time"
 
@@ -19,7 +20,7 @@ var lastRSMAnimTime time.Time
 
 // loadRSMPreview loads a RSM file for preview.
 func (app *App) loadRSMPreview(path string) {
-	data, err := app.archive.Read(path)
+	data, err := app.readFile(path)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error reading RSM file: %v\n", err)
 		return
@@ -46,7 +47,7 @@ func (app *App) loadRSMPreview(path string) {
 	// Load model into 3D viewer with texture loader
 	// Note: loadTextures() already builds the full path (data/texture/...)
 	textureLoader := func(fullPath string) ([]byte, error) {
-		return app.archive.Read(fullPath)
+		return app.readFile(fullPath)
 	}
 	if err := app.modelViewer.LoadModel(rsm, textureLoader, app.magentaTransparency); err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading model: %v\n", err)
@@ -136,19 +137,26 @@ func (app *App) renderRSMPreview() {
 				app.modelViewer.HandleMouseWheel(wheel)
 			}
 		}
+		dx, dy, wheel := app.cameraKeys()
+		if dx != 0 || dy != 0 {
+			app.modelViewer.HandleMouseDrag(dx, dy)
+		}
+		if wheel != 0 {
+			app.modelViewer.HandleMouseWheel(wheel)
+		}
 
 		// Controls row
 		if imgui.Button("Reset View") {
 			app.modelViewer.Reset()
 		}
 		imgui.SameLine()
-		imgui.TextDisabled("(Drag to rotate, scroll to zoom)")
+		imgui.TextDisabled("(Drag or arrows to rotate, scroll or +/- to zoom)")
 
 		// Magenta transparency checkbox
 		if imgui.Checkbox("Magenta Transparency", &app.magentaTransparency) {
 			// Reload model with new transparency setting
 			textureLoader := func(fullPath string) ([]byte, error) {
-				return app.archive.Read(fullPath)
+				return app.readFile(fullPath)
 			}
 			if err := app.modelViewer.LoadModel(rsm, textureLoader, app.magentaTransparency); err != nil {
 				fmt.Fprintf(os.Stderr, "Error reloading model: %v\n", err)
@@ -340,6 +348,7 @@ func (app *App) renderRSMPreview() {
 					}
 				}
 			}
+			app.renderRSMNodeInspector(rsm)
 			imgui.TreePop()
 		}
 	}
@@ -378,12 +387,27 @@ func (app *App) renderRSMNodeTree(rsm *formats.RSM, node *formats.RSMNode, depth
 	children := rsm.GetChildNodes(node.Name)
 	hasChildren := len(children) > 0
 
-	flags := imgui.TreeNodeFlagsNone
+	// Clicking the label selects the node; the arrow expands it
+	flags := imgui.TreeNodeFlagsOpenOnArrow | imgui.TreeNodeFlagsSpanAvailWidth
 	if !hasChildren {
 		flags |= imgui.TreeNodeFlagsLeaf | imgui.TreeNodeFlagsNoTreePushOnOpen
 	}
+	selected := app.modelViewer.SelectedNode() == node.Name
+	if selected {
+		flags |= imgui.TreeNodeFlagsSelected
+	}
 
 	isOpen := imgui.TreeNodeExStrV(label, flags)
+	if imgui.IsItemClicked() && !imgui.IsItemToggledOpen() || itemEntered(0) {
+		if selected {
+			app.modelViewer.SetSelectedNode("")
+		} else {
+			app.modelViewer.SetSelectedNode(node.Name)
+		}
+	}
+	if imgui.IsItemHovered() && imgui.IsMouseDoubleClicked(imgui.MouseButtonLeft) || itemEntered(imgui.KeyChord(imgui.ModShift)) {
+		app.modelViewer.IsolateNode(node.Name)
+	}
 
 	// Show node details on hover
 	if imgui.IsItemHovered() {
@@ -419,3 +443,48 @@ func (app *App) renderRSMNodeTree(rsm *formats.RSM, node *formats.RSMNode, depth
 		imgui.TreePop()
 	}
 }
+
+// renderRSMNodeInspector shows the selected node's transform and keyframes,
+// with buttons to isolate or hide it.
+func (app *App) renderRSMNodeInspector(rsm *formats.RSM) {
+	name := app.modelViewer.SelectedNode()
+	node := rsm.GetNodeByName(name)
+	imgui.Separator()
+	if node == nil {
+		imgui.TextDisabled("Click a node to inspect it, double-click to isolate")
+		return
+	}
+
+	imgui.Text(fmt.Sprintf("Selected: %s", node.Name))
+	if imgui.SmallButton("Isolate") {
+		app.modelViewer.IsolateNode(node.Name)
+	}
+	imgui.SameLine()
+	visible := app.modelViewer.GetNodeVisibility(node.Name)
+	toggle := "Hide"
+	if !visible {
+		toggle = "Show"
+	}
+	if imgui.SmallButton(toggle) {
+		app.modelViewer.SetNodeVisibility(node.Name, !visible)
+	}
+	imgui.SameLine()
+	if imgui.SmallButton("Deselect") {
+		app.modelViewer.SetSelectedNode("")
+	}
+
+	imgui.Text(fmt.Sprintf("Offset: (%.2f, %.2f, %.2f)", node.Offset[0], node.Offset[1], node.Offset[2]))
+	imgui.Text(fmt.Sprintf("Position: (%.2f, %.2f, %.2f)", node.Position[0], node.Position[1], node.Position[2]))
+	imgui.Text(fmt.Sprintf("Rotation: %.1f° about (%.2f, %.2f, %.2f)",
+		node.RotAngle*180/gomath.Pi, node.RotAxis[0], node.RotAxis[1], node.RotAxis[2]))
+	imgui.Text(fmt.Sprintf("Scale: (%.2f, %.2f, %.2f)", node.Scale[0], node.Scale[1], node.Scale[2]))
+	if imgui.TreeNodeExStrV("Matrix", imgui.TreeNodeFlagsNone) {
+		m := node.Matrix
+		for row := 0; row < 3; row++ {
+			imgui.Text(fmt.Sprintf("%7.3f %7.3f %7.3f", m[row*3], m[row*3+1], m[row*3+2]))
+		}
+		imgui.TreePop()
+	}
+	imgui.Text(fmt.Sprintf("Keyframes: pos %d, rot %d, scale %d",
+		len(node.PosKeys), len(node.RotKeys), len(node.ScaleKeys)))
+}
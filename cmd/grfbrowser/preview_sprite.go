@@ -11,12 +11,13 @@ import (
 	"github.com/AllenDang/cimgui-go/backend"
 	"github.com/AllenDang/cimgui-go/imgui"
 
+	"github.com/Faultbox/midgard-ro/internal/engine/sprite"
 	"github.com/Faultbox/midgard-ro/pkg/formats"
 )
 
 // loadSpritePreview loads a SPR file for preview.
 func (app *App) loadSpritePreview(path string) {
-	data, err := app.archive.Read(path)
+	data, err := app.readFile(path)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error reading sprite: %v\n", err)
 		return
@@ -29,18 +30,26 @@ func (app *App) loadSpritePreview(path string) {
 	}
 
 	app.previewSPR = spr
+	app.buildSpriteTextures()
+}
 
-	// Create textures for all images
+// buildSpriteTextures (re)creates the frame textures of the loaded sprite
+// in the current debug view.
+func (app *App) buildSpriteTextures() {
+	for _, tex := range app.previewTextures {
+		releasePreviewTexture(tex)
+	}
+	spr := app.previewSPR
 	app.previewTextures = make([]*backend.Texture, len(spr.Images))
-	for i, img := range spr.Images {
-		rgba := sprImageToRGBA(&img)
-		app.previewTextures[i] = backend.NewTextureFromRgba(rgba)
+	for i := range spr.Images {
+		rgba := sprViewImage(&spr.Images[i], app.previewSprView)
+		app.previewTextures[i] = newPreviewTexture(rgba, groupSprites, fmt.Sprintf("%s #%d", app.previewPath, i))
 	}
 }
 
 // loadAnimationPreview loads an ACT file for preview.
 func (app *App) loadAnimationPreview(path string) {
-	data, err := app.archive.Read(path)
+	data, err := app.readFile(path)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error reading animation: %v\n", err)
 		return
@@ -66,6 +75,8 @@ func (app *App) loadAnimationPreview(path string) {
 	}
 
 	if sprPath != "" {
+		// Debug views are only selectable in the sprite preview
+		app.previewSprView = sprViewNormal
 		app.loadSpritePreview(sprPath)
 	} else {
 		fmt.Fprintf(os.Stderr, "SPR file not found for: %s\n", path)
@@ -138,6 +149,26 @@ func (app *App) renderSpritePreview() {
 		app.previewZoom = 1.0
 	}
 
+	// Debug view
+	imgui.SetNextItemWidth(150)
+	if imgui.BeginCombo("View", sprViewNames[app.previewSprView]) {
+		for v := range sprViewCount {
+			if imgui.SelectableBoolV(sprViewNames[v], v == app.previewSprView, 0, imgui.NewVec2(0, 0)) && v != app.previewSprView {
+				app.previewSprView = v
+				app.buildSpriteTextures()
+			}
+		}
+		imgui.EndCombo()
+	}
+	if app.previewSprView != sprViewNormal && app.previewFrame < len(spr.Images) {
+		img := &spr.Images[app.previewFrame]
+		transparent, magenta := sprPixelStats(img)
+		imgui.Text(fmt.Sprintf("Transparent: %d px  Magenta: %d px", transparent, magenta))
+		if img.Indices == nil {
+			imgui.TextDisabled("True-color frame: no palette indices")
+		}
+	}
+
 	imgui.Separator()
 
 	// Display current frame centered in available space
@@ -168,10 +199,38 @@ func (app *App) renderSpritePreview() {
 				imgui.NewVec4(0.2, 0.2, 0.2, 1.0), // Dark gray background
 				imgui.NewVec4(1, 1, 1, 1),         // White tint (no tint)
 			)
+			if imgui.IsItemHovered() {
+				app.renderSpritePixelTooltip(&img)
+			}
 		}
 	}
 }
 
+// renderSpritePixelTooltip shows the palette index and color of the hovered
+// pixel of a sprite frame.
+func (app *App) renderSpritePixelTooltip(img *formats.SPRImage) {
+	origin := imgui.ItemRectMin()
+	mouse := imgui.MousePos()
+	x := int((mouse.X - origin.X) / app.previewZoom)
+	y := int((mouse.Y - origin.Y) / app.previewZoom)
+	if x < 0 || y < 0 || x >= int(img.Width) || y >= int(img.Height) {
+		return
+	}
+	i := y*int(img.Width) + x
+	if i*4+3 >= len(img.Pixels) {
+		return
+	}
+	px := img.Pixels[i*4 : i*4+4]
+
+	imgui.BeginTooltip()
+	imgui.Text(fmt.Sprintf("Pixel (%d, %d)", x, y))
+	if i < len(img.Indices) {
+		imgui.Text(fmt.Sprintf("Index: %d", img.Indices[i]))
+	}
+	imgui.Text(fmt.Sprintf("RGBA: %d, %d, %d, %d", px[0], px[1], px[2], px[3]))
+	imgui.EndTooltip()
+}
+
 // renderAnimationPreview renders the animation preview (frame display only, controls in Actions panel).
 func (app *App) renderAnimationPreview() {
 	if app.previewACT == nil {
@@ -327,17 +386,9 @@ func (app *App) renderACTFrame(frame *formats.Frame) {
 
 	// For now, just render the first valid layer's sprite
 	validLayerFound := false
-	allLayersEmpty := true
-
-	for _, layer := range frame.Layers {
-		if layer.SpriteID >= 0 {
-			allLayersEmpty = false
-			break
-		}
-	}
 
 	// Show informative message for empty frames (common in garment/accessory ACT files)
-	if allLayersEmpty {
+	if sprite.EmptyFrame(frame) {
 		imgui.TextDisabled("Frame has no sprites")
 		imgui.TextDisabled("(Accessory/garment overlay - uses base sprite)")
 		return
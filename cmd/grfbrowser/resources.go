@@ -0,0 +1,339 @@
+// GPU resource tracking for GRF Browser: textures and buffers are
+// registered when created and dropped when deleted, so the memory
+// inspector can list what is loaded and spot leaks from repeated previews.
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"image"
+	"maps"
+	"slices"
+
+	"github.com/AllenDang/cimgui-go/backend"
+	"github.com/AllenDang/cimgui-go/imgui"
+	"github.com/go-gl/gl/v4.1-core/gl"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/scene"
+)
+
+// resourceGroup is the subsystem a GPU resource belongs to.
+type resourceGroup int
+
+const (
+	groupTerrain resourceGroup = iota // Ground, lightmap, water and tile grid
+	groupModels                       // RSM models on the map and in the model viewer
+	groupSprites                      // Sprite previews and the play mode character
+	groupUI                           // Image and map previews, viewer render targets
+	numResourceGroups
+)
+
+// String returns the group name shown in the inspector.
+func (g resourceGroup) String() string {
+	switch g {
+	case groupTerrain:
+		return "Map terrain"
+	case groupModels:
+		return "Models"
+	case groupSprites:
+		return "Sprites"
+	case groupUI:
+		return "UI"
+	default:
+		return "Other"
+	}
+}
+
+// gpuResource describes a tracked texture or buffer.
+type gpuResource struct {
+	Group  resourceGroup
+	Source string // Archive path, or what the resource is for
+	Width  int32  // Textures only
+	Height int32
+	Layers int32 // Texture array layers, 1 for plain textures
+	Bytes  int64 // Estimated video memory
+}
+
+// resourceTracker holds the live GPU resources by OpenGL name. Textures
+// created through the ImGui backend have no GL name and are kept apart.
+type resourceTracker struct {
+	textures map[uint32]gpuResource
+	buffers  map[uint32]gpuResource
+	previews map[*backend.Texture]gpuResource
+}
+
+// gpuResources tracks every texture and buffer the browser creates.
+var gpuResources = &resourceTracker{
+	textures: make(map[uint32]gpuResource),
+	buffers:  make(map[uint32]gpuResource),
+	previews: make(map[*backend.Texture]gpuResource),
+}
+
+// textureBytes estimates the memory of an RGBA8 texture; a full mipmap
+// chain adds a third.
+func textureBytes(width, height, layers int32, mipmaps bool) int64 {
+	n := int64(width) * int64(height) * int64(layers) * 4
+	if mipmaps {
+		n += n / 3
+	}
+	return n
+}
+
+// trackTexture records a texture. Tracking an already known name updates
+// it, as after the texture is reallocated.
+func trackTexture(id uint32, group resourceGroup, source string, width, height, layers int32, bytes int64) {
+	if id == 0 {
+		return
+	}
+	gpuResources.textures[id] = gpuResource{
+		Group: group, Source: source,
+		Width: width, Height: height, Layers: layers,
+		Bytes: bytes,
+	}
+}
+
+// trackRenderTarget records a viewer's color texture, counting the depth
+// buffer that goes with it.
+func trackRenderTarget(id uint32, source string, width, height int32) {
+	trackTexture(id, groupUI, source, width, height, 1, int64(width)*int64(height)*8)
+}
+
+// trackBuffer records a vertex or index buffer of the given size.
+func trackBuffer(id uint32, group resourceGroup, source string, bytes int) {
+	if id == 0 {
+		return
+	}
+	gpuResources.buffers[id] = gpuResource{Group: group, Source: source, Bytes: int64(bytes)}
+}
+
+// freeTexture deletes a texture and stops tracking it.
+func freeTexture(id uint32) {
+	if id == 0 {
+		return
+	}
+	gl.DeleteTextures(1, &id)
+	delete(gpuResources.textures, id)
+}
+
+// freeBuffer deletes a buffer and stops tracking it.
+func freeBuffer(id uint32) {
+	if id == 0 {
+		return
+	}
+	gl.DeleteBuffers(1, &id)
+	delete(gpuResources.buffers, id)
+}
+
+// newPreviewTexture creates an ImGui texture from an image and tracks it.
+func newPreviewTexture(rgba *image.RGBA, group resourceGroup, source string) *backend.Texture {
+	tex := backend.NewTextureFromRgba(rgba)
+	w, h := int32(tex.Width), int32(tex.Height)
+	gpuResources.previews[tex] = gpuResource{
+		Group: group, Source: source,
+		Width: w, Height: h, Layers: 1,
+		Bytes: textureBytes(w, h, 1, false),
+	}
+	return tex
+}
+
+// releasePreviewTexture releases a texture from newPreviewTexture.
+func releasePreviewTexture(tex *backend.Texture) {
+	if tex == nil {
+		return
+	}
+	tex.Release()
+	delete(gpuResources.previews, tex)
+}
+
+// resourceTotals sums the resources of one group.
+type resourceTotals struct {
+	Textures, Buffers         int
+	TextureBytes, BufferBytes int64
+}
+
+// addMemory adds GPU memory held outside the tracker, by the scene
+// renderers, to the totals.
+func (t *resourceTotals) addMemory(m scene.Memory) {
+	t.Textures += m.Textures
+	t.Buffers += m.Buffers
+	t.TextureBytes += m.TextureBytes
+	t.BufferBytes += m.BufferBytes
+}
+
+// totals returns the resource counts and sizes per group.
+func (t *resourceTracker) totals() [numResourceGroups]resourceTotals {
+	var out [numResourceGroups]resourceTotals
+	for _, r := range t.textures {
+		out[r.Group].Textures++
+		out[r.Group].TextureBytes += r.Bytes
+	}
+	for _, r := range t.previews {
+		out[r.Group].Textures++
+		out[r.Group].TextureBytes += r.Bytes
+	}
+	for _, r := range t.buffers {
+		out[r.Group].Buffers++
+		out[r.Group].BufferBytes += r.Bytes
+	}
+	return out
+}
+
+// sortResources orders resources largest first.
+func sortResources(rs []gpuResource) []gpuResource {
+	slices.SortFunc(rs, func(a, b gpuResource) int {
+		if c := cmp.Compare(b.Bytes, a.Bytes); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Source, b.Source)
+	})
+	return rs
+}
+
+// formatBytes formats a byte count for display.
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
+// releasePreviewCache frees the preview textures and the model viewer,
+// deselecting the file so it isn't loaded again right away. The viewer is
+// recreated when the next model is opened.
+func (app *App) releasePreviewCache() {
+	app.clearPreview()
+	app.selectedPath, app.selectedOriginalPath, app.previewPath = "", "", ""
+	app.map3DViewMode = false
+	app.showPropertiesPanel = false
+	if app.modelViewer != nil {
+		app.modelViewer.Destroy()
+		app.modelViewer = nil
+	}
+}
+
+// releaseMapViewer frees the 3D map view and everything it loaded.
+func (app *App) releaseMapViewer() {
+	if app.mapViewer == nil {
+		return
+	}
+	app.map3DViewMode = false
+	app.showPropertiesPanel = false
+	app.mapViewer.Destroy()
+	app.mapViewer = nil
+}
+
+// renderResourceInspector draws the memory usage window.
+func (app *App) renderResourceInspector() {
+	if !app.showResources {
+		return
+	}
+	imgui.SetNextWindowSizeV(imgui.NewVec2(640, 480), imgui.CondFirstUseEver)
+	if imgui.BeginV("GPU Resources", &app.showResources, 0) {
+		if imgui.Button("Release Preview Cache") {
+			app.releasePreviewCache()
+		}
+		imgui.SameLine()
+		if imgui.Button("Release 3D Map") {
+			app.releaseMapViewer()
+		}
+		imgui.Separator()
+
+		totals := gpuResources.totals()
+		if app.mapViewer != nil {
+			app.mapViewer.addResourceTotals(&totals)
+		}
+		if app.modelViewer != nil {
+			app.modelViewer.addResourceTotals(&totals)
+		}
+		var all resourceTotals
+		for _, tot := range totals {
+			all.Textures += tot.Textures
+			all.Buffers += tot.Buffers
+			all.TextureBytes += tot.TextureBytes
+			all.BufferBytes += tot.BufferBytes
+		}
+		if imgui.BeginTableV("##totals", 4, imgui.TableFlagsBorders|imgui.TableFlagsRowBg, imgui.NewVec2(0, 0), 0) {
+			imgui.TableSetupColumn("Subsystem")
+			imgui.TableSetupColumn("Textures")
+			imgui.TableSetupColumn("Buffers")
+			imgui.TableSetupColumn("Total")
+			imgui.TableHeadersRow()
+			for g, tot := range totals {
+				renderTotalsRow(resourceGroup(g).String(), tot)
+			}
+			renderTotalsRow("All", all)
+			imgui.EndTable()
+		}
+
+		if imgui.CollapsingHeaderTreeNodeFlags(fmt.Sprintf("Textures (%d)###textures", all.Textures)) {
+			textures := slices.AppendSeq(slices.Collect(maps.Values(gpuResources.textures)), maps.Values(gpuResources.previews))
+			renderResourceTable("##textures", sortResources(textures), true)
+		}
+		if imgui.CollapsingHeaderTreeNodeFlags(fmt.Sprintf("Buffers (%d)###buffers", all.Buffers)) {
+			renderResourceTable("##buffers", sortResources(slices.Collect(maps.Values(gpuResources.buffers))), false)
+		}
+	}
+	imgui.End()
+}
+
+// renderTotalsRow draws one subsystem's line of the totals table.
+func renderTotalsRow(name string, tot resourceTotals) {
+	imgui.TableNextRow()
+	imgui.TableNextColumn()
+	imgui.Text(name)
+	imgui.TableNextColumn()
+	imgui.Text(fmt.Sprintf("%d / %s", tot.Textures, formatBytes(tot.TextureBytes)))
+	imgui.TableNextColumn()
+	imgui.Text(fmt.Sprintf("%d / %s", tot.Buffers, formatBytes(tot.BufferBytes)))
+	imgui.TableNextColumn()
+	imgui.Text(formatBytes(tot.TextureBytes + tot.BufferBytes))
+}
+
+// renderResourceTable lists resources with their source and size.
+func renderResourceTable(id string, resources []gpuResource, textures bool) {
+	columns := int32(3)
+	if textures {
+		columns = 4
+	}
+	flags := imgui.TableFlagsBorders | imgui.TableFlagsRowBg | imgui.TableFlagsScrollY | imgui.TableFlagsResizable
+	if !imgui.BeginTableV(id, columns, flags, imgui.NewVec2(0, 220), 0) {
+		return
+	}
+	imgui.TableSetupScrollFreeze(0, 1)
+	imgui.TableSetupColumn("Source")
+	imgui.TableSetupColumn("Subsystem")
+	if textures {
+		imgui.TableSetupColumn("Size")
+	}
+	imgui.TableSetupColumn("Memory")
+	imgui.TableHeadersRow()
+
+	clipper := imgui.NewListClipper()
+	defer clipper.Destroy()
+	clipper.Begin(int32(len(resources)))
+	for clipper.Step() {
+		for i := clipper.DisplayStart(); i < clipper.DisplayEnd(); i++ {
+			r := resources[i]
+			imgui.TableNextRow()
+			imgui.TableNextColumn()
+			imgui.Text(r.Source)
+			imgui.TableNextColumn()
+			imgui.Text(r.Group.String())
+			if textures {
+				imgui.TableNextColumn()
+				if r.Layers > 1 {
+					imgui.Text(fmt.Sprintf("%dx%d x%d", r.Width, r.Height, r.Layers))
+				} else {
+					imgui.Text(fmt.Sprintf("%dx%d", r.Width, r.Height))
+				}
+			}
+			imgui.TableNextColumn()
+			imgui.Text(formatBytes(r.Bytes))
+		}
+	}
+	imgui.EndTable()
+}
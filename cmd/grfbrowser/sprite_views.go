@@ -0,0 +1,115 @@
+// Sprite debug views for GRF Browser.
+package main
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/Faultbox/midgard-ro/pkg/formats"
+)
+
+// sprView selects how sprite frames are drawn in the preview.
+type sprView int
+
+const (
+	sprViewNormal  sprView = iota // Colors as the game draws them
+	sprViewIndex                  // Heatmap of palette indices
+	sprViewAlpha                  // Alpha channel as grayscale
+	sprViewMagenta                // Index 0 and magenta pixels highlighted
+	sprViewCount
+)
+
+var sprViewNames = [sprViewCount]string{
+	sprViewNormal:  "Normal",
+	sprViewIndex:   "Palette Index",
+	sprViewAlpha:   "Alpha Channel",
+	sprViewMagenta: "Magenta Key",
+}
+
+// sprViewImage renders a sprite frame for a debug view. Views that need
+// palette indices draw true-color frames gray, as they have none.
+func sprViewImage(img *formats.SPRImage, view sprView) *image.RGBA {
+	if view == sprViewNormal {
+		return sprImageToRGBA(img)
+	}
+
+	w, h := int(img.Width), int(img.Height)
+	out := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := range w * h {
+		if i*4+3 >= len(img.Pixels) {
+			break
+		}
+		px := color.RGBA{R: img.Pixels[i*4], G: img.Pixels[i*4+1], B: img.Pixels[i*4+2], A: img.Pixels[i*4+3]}
+		index, indexed := 0, i < len(img.Indices)
+		if indexed {
+			index = int(img.Indices[i])
+		}
+
+		var c color.RGBA
+		switch view {
+		case sprViewIndex:
+			switch {
+			case !indexed:
+				c = SprNoIndexColor
+			case index == 0:
+				c = SprIndexZeroColor
+			default:
+				c = heatmapColor(float32(index-1) / 254)
+			}
+		case sprViewAlpha:
+			c = color.RGBA{R: px.A, G: px.A, B: px.A, A: 255}
+		case sprViewMagenta:
+			switch {
+			case indexed && index == 0, !indexed && px.A == 0:
+				c = SprIndexZeroColor
+			case isMagentaKey(px):
+				c = SprMagentaHighlight
+			default:
+				// Dimmed grayscale, so the highlights stand out
+				gray := uint8((int(px.R)*3 + int(px.G)*6 + int(px.B)) / 20)
+				c = color.RGBA{R: gray, G: gray, B: gray, A: 255}
+			}
+		}
+		out.SetRGBA(i%w, i/w, c)
+	}
+	return out
+}
+
+// sprPixelStats counts a frame's palette index 0 pixels (or transparent
+// pixels of a true-color frame) and its visible magenta key pixels.
+func sprPixelStats(img *formats.SPRImage) (transparent, magenta int) {
+	for i := 0; i+3 < len(img.Pixels); i += 4 {
+		px := color.RGBA{R: img.Pixels[i], G: img.Pixels[i+1], B: img.Pixels[i+2], A: img.Pixels[i+3]}
+		switch {
+		case img.Indices != nil && img.Indices[i/4] == 0, img.Indices == nil && px.A == 0:
+			transparent++
+		case isMagentaKey(px):
+			magenta++
+		}
+	}
+	return transparent, magenta
+}
+
+// isMagentaKey reports whether a visible pixel is the magenta (255, 0, 255)
+// color key, which should have been transparent.
+func isMagentaKey(c color.RGBA) bool {
+	return c.A != 0 && c.R == 255 && c.G == 0 && c.B == 255
+}
+
+// heatmapColor maps t (0-1) from blue through green and yellow to red.
+func heatmapColor(t float32) color.RGBA {
+	t = min(max(t, 0), 1)
+	var r, g, b float32
+	switch {
+	case t < 1.0/3:
+		f := t * 3
+		r, g, b = 0, f, 1-f
+	case t < 2.0/3:
+		f := (t - 1.0/3) * 3
+		r, g, b = f, 1, 0
+	default:
+		f := (t - 2.0/3) * 3
+		r, g, b = 1, 1-f, 0
+	}
+	return color.RGBA{R: uint8(r * 255), G: uint8(g * 255), B: uint8(b * 255), A: 255}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// workspaceVersion is bumped when the workspace format changes incompatibly.
+const workspaceVersion = 1
+
+// Workspace is a saved browser session: open archive, tree state, selection
+// and map viewer camera. It is written on exit and restored on the next start.
+type Workspace struct {
+	Version              int      `json:"version"`
+	GRFPaths             []string `json:"grf_paths"`
+	SearchText           string   `json:"search_text"`
+	ExpandedPaths        []string `json:"expanded_paths"`
+	SelectedPath         string   `json:"selected_path"`
+	SelectedOriginalPath string   `json:"selected_original_path"`
+	Filters              struct {
+		Sprites    bool `json:"sprites"`
+		Animations bool `json:"animations"`
+		Textures   bool `json:"textures"`
+		Models     bool `json:"models"`
+		Maps       bool `json:"maps"`
+		Audio      bool `json:"audio"`
+		Other      bool `json:"other"`
+	} `json:"filters"`
+	Map *MapWorkspace `json:"map,omitempty"`
+}
+
+// MapWorkspace holds the 3D map viewer camera and visibility toggles.
+type MapWorkspace struct {
+	Center              [3]float32 `json:"center"`
+	Distance            float32    `json:"distance"`
+	RotationX           float32    `json:"rotation_x"`
+	RotationY           float32    `json:"rotation_y"`
+	FogEnabled          bool       `json:"fog_enabled"`
+	ShadowsEnabled      bool       `json:"shadows_enabled"`
+	PointLightsEnabled  bool       `json:"point_lights_enabled"`
+	PointLightIntensity float32    `json:"point_light_intensity"`
+	TileGridEnabled     bool       `json:"tile_grid_enabled"`
+	ForceAllTwoSided    bool       `json:"force_all_two_sided"`
+	WalkThroughBlocked  bool       `json:"walk_through_blocked"`
+	ModelScale          float32    `json:"model_scale"`
+	Brightness          float32    `json:"brightness"`
+	MaxModels           int        `json:"max_models"`
+	ModelFilter         string     `json:"model_filter"`
+}
+
+// defaultWorkspacePath returns the auto-save location for the last session.
+func defaultWorkspacePath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "midgard-ro", "grfbrowser_workspace.json")
+}
+
+// captureWorkspace snapshots the current session.
+func (app *App) captureWorkspace() *Workspace {
+	ws := &Workspace{
+		Version:              workspaceVersion,
+		SearchText:           app.searchText,
+		SelectedPath:         app.selectedPath,
+		SelectedOriginalPath: app.selectedOriginalPath,
+		ExpandedPaths:        make([]string, 0, len(app.expandedPaths)),
+	}
+	if app.grfPath != "" {
+		ws.GRFPaths = []string{app.grfPath}
+	}
+	for path, expanded := range app.expandedPaths {
+		if expanded {
+			ws.ExpandedPaths = append(ws.ExpandedPaths, path)
+		}
+	}
+	sort.Strings(ws.ExpandedPaths)
+
+	ws.Filters.Sprites = app.filterSprites
+	ws.Filters.Animations = app.filterAnimations
+	ws.Filters.Textures = app.filterTextures
+	ws.Filters.Models = app.filterModels
+	ws.Filters.Maps = app.filterMaps
+	ws.Filters.Audio = app.filterAudio
+	ws.Filters.Other = app.filterOther
+
+	if app.map3DViewMode && app.mapViewer != nil && app.mapViewer.OrbitCam != nil {
+		mv := app.mapViewer
+		cam := mv.OrbitCam
+		ws.Map = &MapWorkspace{
+			Center:              [3]float32{cam.CenterX, cam.CenterY, cam.CenterZ},
+			Distance:            cam.Distance,
+			RotationX:           cam.RotationX,
+			RotationY:           cam.RotationY,
+			FogEnabled:          mv.FogEnabled,
+			ShadowsEnabled:      mv.ShadowsEnabled,
+			PointLightsEnabled:  mv.PointLightsEnabled,
+			PointLightIntensity: mv.PointLightIntensity,
+			TileGridEnabled:     mv.TileGridEnabled,
+			ForceAllTwoSided:    mv.ForceAllTwoSided,
+			WalkThroughBlocked:  mv.WalkThroughBlocked,
+			ModelScale:          mv.ModelScale,
+			Brightness:          mv.Brightness,
+			MaxModels:           mv.MaxModels,
+			ModelFilter:         mv.ModelFilter,
+		}
+	}
+	return ws
+}
+
+// saveWorkspace writes the current session to path.
+func (app *App) saveWorkspace(path string) error {
+	data, err := json.MarshalIndent(app.captureWorkspace(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal workspace: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create workspace dir: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("write workspace: %w", err)
+	}
+	return nil
+}
+
+// loadWorkspace reads a saved session from path.
+func loadWorkspace(path string) (*Workspace, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var ws Workspace
+	if err := json.Unmarshal(data, &ws); err != nil {
+		return nil, fmt.Errorf("parse workspace: %w", err)
+	}
+	if ws.Version != workspaceVersion {
+		return nil, fmt.Errorf("unsupported workspace version %d", ws.Version)
+	}
+	return &ws, nil
+}
+
+// applyWorkspace restores a saved session. The archive is reopened first
+// since OpenGRF resets the tree and selection.
+func (app *App) applyWorkspace(ws *Workspace) error {
+	if len(ws.GRFPaths) == 0 {
+		return nil
+	}
+	if err := app.OpenGRF(ws.GRFPaths[0]); err != nil {
+		return err
+	}
+
+	app.filterSprites = ws.Filters.Sprites
+	app.filterAnimations = ws.Filters.Animations
+	app.filterTextures = ws.Filters.Textures
+	app.filterModels = ws.Filters.Models
+	app.filterMaps = ws.Filters.Maps
+	app.filterAudio = ws.Filters.Audio
+	app.filterOther = ws.Filters.Other
+	app.searchText = ws.SearchText
+	app.rebuildTree()
+
+	for _, path := range ws.ExpandedPaths {
+		app.expandedPaths[path] = true
+	}
+
+	if ws.SelectedPath == "" || !app.archive.Contains(ws.SelectedOriginalPath) {
+		return nil
+	}
+	app.selectedPath = ws.SelectedPath
+	app.selectedOriginalPath = ws.SelectedOriginalPath
+	app.scrollToPath = ws.SelectedPath
+	app.loadPreview(app.selectedPath)
+
+	if ws.Map != nil && app.previewRSW != nil {
+		app.maxModelsLimit = ws.Map.MaxModels
+		app.terrainBrightness = ws.Map.Brightness
+		app.initMap3DView()
+		app.applyMapWorkspace(ws.Map)
+	}
+	return nil
+}
+
+// applyMapWorkspace restores the map viewer camera and toggles after the map
+// has been loaded, since LoadMap resets them to map defaults.
+func (app *App) applyMapWorkspace(m *MapWorkspace) {
+	mv := app.mapViewer
+	if mv == nil || mv.OrbitCam == nil {
+		return
+	}
+	cam := mv.OrbitCam
+	cam.CenterX, cam.CenterY, cam.CenterZ = m.Center[0], m.Center[1], m.Center[2]
+	cam.Distance = m.Distance
+	cam.RotationX = m.RotationX
+	cam.RotationY = m.RotationY
+
+	mv.FogEnabled = m.FogEnabled
+	mv.ShadowsEnabled = m.ShadowsEnabled
+	mv.PointLightsEnabled = m.PointLightsEnabled
+	mv.PointLightIntensity = m.PointLightIntensity
+	mv.TileGridEnabled = m.TileGridEnabled
+	mv.ForceAllTwoSided = m.ForceAllTwoSided
+	mv.WalkThroughBlocked = m.WalkThroughBlocked
+	mv.ModelScale = m.ModelScale
+	mv.ModelFilter = m.ModelFilter
+}
+
+// restoreLastSession loads the auto-saved workspace, if any.
+func (app *App) restoreLastSession() {
+	ws, err := loadWorkspace(defaultWorkspacePath())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "Warning: could not load workspace: %v\n", err)
+		}
+		return
+	}
+	if err := app.applyWorkspace(ws); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not restore workspace: %v\n", err)
+	}
+}
+
+// autoSaveWorkspace saves the session on exit. Sessions without an open
+// archive are not saved so they don't overwrite the last useful one.
+func (app *App) autoSaveWorkspace() {
+	if app.archive == nil {
+		return
+	}
+	if err := app.saveWorkspace(defaultWorkspacePath()); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not save workspace: %v\n", err)
+	}
+}
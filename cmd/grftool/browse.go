@@ -0,0 +1,485 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Faultbox/midgard-ro/pkg/encoding"
+	"github.com/Faultbox/midgard-ro/pkg/grf"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Terminal attributes.
+const (
+	ansiReset   = "\x1b[0m"
+	ansiReverse = "\x1b[7m"
+	ansiBold    = "\x1b[1m"
+	ansiDim     = "\x1b[2m"
+)
+
+const browseHelp = "↑↓ move  ⏎ open  ⌫ up  / search  t text/hex  x extract  q quit"
+
+// browseItem is a row of the file list: a subdirectory or a file.
+type browseItem struct {
+	name  string // Shown name, relative to the directory or the full path in search results
+	path  string // Archive path; directories end in "/"
+	dir   bool
+	count int // Files under a directory
+}
+
+// browser is the bubbletea model of the terminal archive browser. Keys and
+// resizes update it and View draws it; the program owns the terminal.
+type browser struct {
+	archive *grf.Archive
+	name    string
+	files   []string // Every archive path, sorted
+	outDir  string   // Where extracted files go
+
+	dir       string // Directory shown, "" for the root or "data/sprite/"
+	search    string // Filters all files by substring when set
+	searching bool   // Typing the search
+	items     []browseItem
+	cursor    int
+	top       int // First list row shown
+	hex       bool
+	status    string
+	quit      bool
+	width     int // Terminal size, 0 until the first resize
+	height    int
+
+	// The selected file's contents, read once per selection
+	previewPath string
+	previewData []byte
+	previewErr  error
+}
+
+// cmdBrowse opens an archive in the terminal browser.
+func cmdBrowse(args []string) {
+	fs := flag.NewFlagSet("browse", flag.ExitOnError)
+	outDir := fs.String("o", ".", "Directory extracted files are written to")
+	fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: grftool browse [-o output_dir] <file.grf>")
+		os.Exit(1)
+	}
+
+	archive, err := grf.Open(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer archive.Close()
+
+	b := newBrowser(archive, filepath.Base(fs.Arg(0)), *outDir)
+	if _, err := tea.NewProgram(b, tea.WithAltScreen()).Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func newBrowser(archive *grf.Archive, name, outDir string) *browser {
+	files := archive.List()
+	sort.Strings(files)
+	b := &browser{archive: archive, name: name, files: files, outDir: outDir}
+	b.refresh()
+	return b
+}
+
+// Init implements tea.Model; the browser starts with nothing to do.
+func (b *browser) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model, applying key presses and resizes.
+func (b *browser) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		b.key(msg)
+		if b.quit {
+			return b, tea.Quit
+		}
+	case tea.WindowSizeMsg:
+		b.width, b.height = msg.Width, msg.Height
+	}
+	return b, nil
+}
+
+// View implements tea.Model. Nothing is drawn until the terminal size is
+// known.
+func (b *browser) View() string {
+	if b.width <= 0 || b.height <= 0 {
+		return ""
+	}
+	return strings.Join(b.view(b.width, b.height), "\n")
+}
+
+// refresh rebuilds the list for the directory or search.
+func (b *browser) refresh() {
+	b.items = b.items[:0]
+	if b.search != "" {
+		query := strings.ToLower(b.search)
+		for _, f := range b.files {
+			if strings.Contains(strings.ToLower(encoding.EUCKRStringToUTF8(f)), query) {
+				b.items = append(b.items, browseItem{name: f, path: f})
+			}
+		}
+	} else {
+		b.items = listDir(b.files, b.dir)
+	}
+	b.cursor = min(b.cursor, max(len(b.items)-1, 0))
+	b.top = min(b.top, b.cursor)
+}
+
+// listDir lists a directory of the sorted archive paths: subdirectories
+// first, then files.
+func listDir(files []string, dir string) []browseItem {
+	var dirs, items []browseItem
+	start := sort.SearchStrings(files, dir)
+	for _, f := range files[start:] {
+		if !strings.HasPrefix(f, dir) {
+			break
+		}
+		rest := f[len(dir):]
+		sub, _, isDir := strings.Cut(rest, "/")
+		if !isDir {
+			items = append(items, browseItem{name: rest, path: f})
+			continue
+		}
+		if n := len(dirs); n > 0 && dirs[n-1].name == sub {
+			dirs[n-1].count++
+			continue
+		}
+		dirs = append(dirs, browseItem{name: sub, path: dir + sub + "/", dir: true, count: 1})
+	}
+	return append(dirs, items...)
+}
+
+// selected returns the item under the cursor, or nil.
+func (b *browser) selected() *browseItem {
+	if b.cursor < 0 || b.cursor >= len(b.items) {
+		return nil
+	}
+	return &b.items[b.cursor]
+}
+
+// key applies a key press.
+func (b *browser) key(k tea.KeyMsg) {
+	if b.searching {
+		b.searchKey(k)
+		return
+	}
+	b.status = ""
+	switch k.String() {
+	case "q", "ctrl+c":
+		b.quit = true
+	case "up", "k":
+		b.move(-1)
+	case "down", "j":
+		b.move(1)
+	case "pgup":
+		b.move(-10)
+	case "pgdown":
+		b.move(10)
+	case "home", "g":
+		b.move(-len(b.items))
+	case "end", "G":
+		b.move(len(b.items))
+	case "enter", "right", "l":
+		if item := b.selected(); item != nil && item.dir {
+			b.dir = item.path
+			b.cursor, b.top = 0, 0
+			b.refresh()
+		}
+	case "backspace", "left", "h":
+		b.up()
+	case "/":
+		b.searching = true
+	case "esc":
+		if b.search != "" {
+			b.search = ""
+			b.cursor, b.top = 0, 0
+			b.refresh()
+		}
+	case "t":
+		b.hex = !b.hex
+	case "x":
+		b.extract()
+	}
+}
+
+// searchKey edits the search being typed.
+func (b *browser) searchKey(k tea.KeyMsg) {
+	switch k.String() {
+	case "enter":
+		b.searching = false
+		return
+	case "esc", "ctrl+c":
+		b.searching = false
+		b.search = ""
+	case "backspace":
+		if b.search != "" {
+			_, size := utf8.DecodeLastRuneInString(b.search)
+			b.search = b.search[:len(b.search)-size]
+		}
+	default:
+		// Typed or pasted text; keys with modifiers don't type
+		if (k.Type != tea.KeyRunes && k.Type != tea.KeySpace) || k.Alt {
+			return
+		}
+		b.search += string(k.Runes)
+	}
+	b.cursor, b.top = 0, 0
+	b.refresh()
+}
+
+// move moves the cursor by delta rows.
+func (b *browser) move(delta int) {
+	b.cursor = max(min(b.cursor+delta, len(b.items)-1), 0)
+}
+
+// up goes to the parent directory, selecting the one left.
+func (b *browser) up() {
+	if b.search != "" || b.dir == "" {
+		return
+	}
+	left := b.dir
+	b.dir = ""
+	if i := strings.LastIndex(strings.TrimSuffix(left, "/"), "/"); i >= 0 {
+		b.dir = left[:i+1]
+	}
+	b.cursor, b.top = 0, 0
+	b.refresh()
+	for i, item := range b.items {
+		if item.path == left {
+			b.cursor = i
+		}
+	}
+}
+
+// extract writes the selected file, or every file under the selected
+// directory, to the output directory, keeping archive paths.
+func (b *browser) extract() {
+	item := b.selected()
+	if item == nil {
+		return
+	}
+	paths := []string{item.path}
+	if item.dir {
+		paths = nil
+		for _, f := range b.files {
+			if strings.HasPrefix(f, item.path) {
+				paths = append(paths, f)
+			}
+		}
+	}
+	extracted := 0
+	for _, p := range paths {
+		if err := b.extractFile(p); err != nil {
+			b.status = fmt.Sprintf("Error: %v", err)
+			return
+		}
+		extracted++
+	}
+	b.status = fmt.Sprintf("Extracted %d file(s) to %s", extracted, b.outDir)
+}
+
+func (b *browser) extractFile(path string) error {
+	data, err := b.archive.Read(path)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	out := filepath.Join(b.outDir, filepath.FromSlash(encoding.EUCKRStringToUTF8(path)))
+	if err := os.MkdirAll(filepath.Dir(out), 0755); err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+	if err := os.WriteFile(out, data, 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", out, err)
+	}
+	return nil
+}
+
+// view draws the browser into w columns by h rows.
+func (b *browser) view(w, h int) []string {
+	h = max(h, 6)
+	listW := max(w*2/5, 20)
+	infoW := max(w-listW-1, 10)
+	rows := h - 3
+
+	lines := []string{ansiReverse + fit(fmt.Sprintf(" grftool browse  %s  %d files", b.name, len(b.files)), w) + ansiReset}
+	location := "/" + encoding.EUCKRStringToUTF8(b.dir)
+	if b.searching || b.search != "" {
+		location = fmt.Sprintf("Search: %s", b.search)
+		if b.searching {
+			location += "_"
+		}
+		location += fmt.Sprintf("  (%d matches)", len(b.items))
+	}
+	lines = append(lines, ansiBold+fit(" "+location, w)+ansiReset)
+
+	// Keep the cursor on screen
+	if b.cursor < b.top {
+		b.top = b.cursor
+	}
+	if b.cursor >= b.top+rows {
+		b.top = b.cursor - rows + 1
+	}
+
+	info := b.infoLines(infoW, rows)
+	for r := range rows {
+		left := ""
+		if i := b.top + r; i < len(b.items) {
+			item := b.items[i]
+			text := " " + encoding.EUCKRStringToUTF8(item.name)
+			if item.dir {
+				text += fmt.Sprintf("/  (%d)", item.count)
+			}
+			left = fit(text, listW)
+			if i == b.cursor {
+				left = ansiReverse + left + ansiReset
+			}
+		} else {
+			left = fit("", listW)
+		}
+		right := ""
+		if r < len(info) {
+			right = info[r]
+		}
+		lines = append(lines, left+ansiDim+"│"+ansiReset+right)
+	}
+
+	footer := browseHelp
+	if b.status != "" {
+		footer = b.status
+	}
+	return append(lines, ansiDim+fit(" "+footer, w)+ansiReset)
+}
+
+// infoLines describes the selected item: a directory's file count, or a
+// file's sizes and a preview of its contents.
+func (b *browser) infoLines(w, rows int) []string {
+	item := b.selected()
+	if item == nil {
+		return []string{fit(" (empty)", w)}
+	}
+	name := encoding.EUCKRStringToUTF8(item.path)
+	if item.dir {
+		return []string{fit(" "+name, w), fit(fmt.Sprintf(" Directory, %d file(s)", item.count), w)}
+	}
+
+	lines := []string{fit(" "+name, w)}
+	if entry, ok := b.archive.Stat(item.path); ok {
+		ratio := 100.0
+		if entry.UncompressedSize > 0 {
+			ratio = float64(entry.CompressedSize) / float64(entry.UncompressedSize) * 100
+		}
+		lines = append(lines,
+			fit(fmt.Sprintf(" Size %s, stored %s (%.0f%%)", formatSize(uint64(entry.UncompressedSize)), formatSize(uint64(entry.CompressedSize)), ratio), w),
+			fit(fmt.Sprintf(" Flags 0x%02x  Offset 0x%08x", entry.Flags, entry.Offset), w))
+	}
+	lines = append(lines, fit("", w))
+
+	if b.previewPath != item.path {
+		b.previewPath = item.path
+		b.previewData, b.previewErr = b.archive.Read(item.path)
+	}
+	if b.previewErr != nil {
+		return append(lines, fit(" "+b.previewErr.Error(), w))
+	}
+	preview := rows - len(lines)
+	if b.hex || !isText(b.previewData) {
+		return append(lines, hexLines(b.previewData, w, preview)...)
+	}
+	for _, line := range strings.Split(encoding.EUCKRToUTF8(b.previewData), "\n") {
+		if len(lines) >= rows {
+			break
+		}
+		line = strings.ReplaceAll(strings.TrimRight(line, "\r"), "\t", "    ")
+		lines = append(lines, fit(" "+line, w))
+	}
+	return lines
+}
+
+// hexLines dumps the start of data with as many bytes per row as fit.
+func hexLines(data []byte, w, rows int) []string {
+	perRow := 16
+	if w < 78 {
+		perRow = 8
+	}
+	var lines []string
+	for off := 0; off < len(data) && len(lines) < rows; off += perRow {
+		row := data[off:min(off+perRow, len(data))]
+		var hex, ascii strings.Builder
+		for i := range perRow {
+			if i < len(row) {
+				fmt.Fprintf(&hex, "%02x ", row[i])
+				c := row[i]
+				if c < 0x20 || c > 0x7e {
+					c = '.'
+				}
+				ascii.WriteByte(c)
+			} else {
+				hex.WriteString("   ")
+			}
+		}
+		lines = append(lines, fit(fmt.Sprintf(" %08x  %s %s", off, hex.String(), ascii.String()), w))
+	}
+	return lines
+}
+
+// isText reports whether data looks like text: no NULs and mostly
+// printable in its first few kilobytes.
+func isText(data []byte) bool {
+	sample := data[:min(len(data), 4096)]
+	if len(sample) == 0 {
+		return true
+	}
+	control := 0
+	for _, c := range sample {
+		switch {
+		case c == 0:
+			return false
+		case c < 0x20 && c != '\n' && c != '\r' && c != '\t':
+			control++
+		}
+	}
+	return control*20 < len(sample)
+}
+
+// formatSize formats a byte count for people.
+func formatSize(n uint64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// fit pads or cuts s to exactly w terminal columns, counting wide
+// (Hangul, CJK) characters as two.
+func fit(s string, w int) string {
+	var out strings.Builder
+	cols := 0
+	for _, r := range s {
+		if r < 0x20 {
+			r = ' '
+		}
+		rw := 1
+		if r >= 0x1100 {
+			rw = 2
+		}
+		if cols+rw > w {
+			break
+		}
+		out.WriteRune(r)
+		cols += rw
+	}
+	return out.String() + strings.Repeat(" ", w-cols)
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Faultbox/midgard-ro/pkg/grf"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func testBrowser(t *testing.T) *browser {
+	t.Helper()
+	a := grf.NewInMemory()
+	files := map[string][]byte{
+		"data/readme.txt":          []byte("hello\nworld\n"),
+		"data/sprite/poring.spr":   {0x53, 0x50, 0x00, 0x02},
+		"data/sprite/poring.act":   {0x41, 0x43, 0x00, 0x02},
+		"data/texture/grass.bmp":   {0x42, 0x4d},
+		"data/texture/ui/ok.bmp":   {0x42, 0x4d},
+		"data/texture/ui/skin.bmp": {0x42, 0x4d},
+	}
+	for name, data := range files {
+		if err := a.AddFile(name, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b := newBrowser(a, "test.grf", t.TempDir())
+	b.Update(tea.WindowSizeMsg{Width: 100, Height: 20})
+	return b
+}
+
+// press sends keys to the browser: names like "enter", or runes typed.
+func press(b *browser, keys ...string) tea.Cmd {
+	var cmd tea.Cmd
+	for _, k := range keys {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		switch k {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case "backspace":
+			msg = tea.KeyMsg{Type: tea.KeyBackspace}
+		case "up":
+			msg = tea.KeyMsg{Type: tea.KeyUp}
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		case "ctrl+c":
+			msg = tea.KeyMsg{Type: tea.KeyCtrlC}
+		}
+		_, cmd = b.Update(msg)
+	}
+	return cmd
+}
+
+func itemNames(b *browser) []string {
+	var names []string
+	for _, item := range b.items {
+		names = append(names, item.name)
+	}
+	return names
+}
+
+func TestListDir(t *testing.T) {
+	files := []string{"a/b/c.txt", "a/b/d.txt", "a/e.txt", "a/f/g.txt", "z.txt"}
+	tests := []struct {
+		dir  string
+		want []string
+	}{
+		{"", []string{"a/4", "z.txt"}},
+		{"a/", []string{"b/2", "f/1", "e.txt"}},
+		{"a/b/", []string{"c.txt", "d.txt"}},
+		{"q/", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, item := range listDir(files, tt.dir) {
+			name := item.name
+			if item.dir {
+				name = fmt.Sprintf("%s/%d", name, item.count)
+			}
+			got = append(got, name)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("listDir(%q) = %v, want %v", tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestBrowseNavigate(t *testing.T) {
+	b := testBrowser(t)
+	if got := strings.Join(itemNames(b), ","); got != "data" {
+		t.Fatalf("root = %s, want data", got)
+	}
+
+	press(b, "enter")
+	if b.dir != "data/" {
+		t.Fatalf("dir after enter = %q, want data/", b.dir)
+	}
+	if got := strings.Join(itemNames(b), ","); got != "sprite,texture,readme.txt" {
+		t.Fatalf("data/ = %s", got)
+	}
+
+	press(b, "down", "enter")
+	if b.dir != "data/texture/" {
+		t.Fatalf("dir = %q, want data/texture/", b.dir)
+	}
+	press(b, "down", "down", "down")
+	if b.cursor != 1 {
+		t.Errorf("cursor past the end = %d, want 1", b.cursor)
+	}
+
+	// Going up selects the directory left
+	press(b, "backspace")
+	if b.dir != "data/" || b.selected().path != "data/texture/" {
+		t.Errorf("after up: dir %q, selected %q", b.dir, b.selected().path)
+	}
+	press(b, "up", "up")
+	if b.cursor != 0 {
+		t.Errorf("cursor before the start = %d, want 0", b.cursor)
+	}
+}
+
+func TestBrowseSearch(t *testing.T) {
+	b := testBrowser(t)
+	press(b, "/", "p", "o", "r")
+	if !b.searching || b.search != "por" {
+		t.Fatalf("searching %v, search %q", b.searching, b.search)
+	}
+	if got := strings.Join(itemNames(b), ","); got != "data/sprite/poring.act,data/sprite/poring.spr" {
+		t.Errorf("matches = %s", got)
+	}
+
+	// While typing, letters are search text rather than commands
+	press(b, "q")
+	if b.quit || len(b.items) != 0 {
+		t.Errorf("q while searching: quit %v, %d matches", b.quit, len(b.items))
+	}
+	press(b, "backspace", "enter")
+	if b.searching || b.search != "por" {
+		t.Errorf("after enter: searching %v, search %q", b.searching, b.search)
+	}
+
+	// A paste types all its runes
+	press(b, "/", "backspace", "backspace", "backspace")
+	b.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("ui/"), Paste: true})
+	if got := len(b.items); b.search != "ui/" || got != 2 {
+		t.Errorf("pasted search %q, %d matches, want ui/, 2", b.search, got)
+	}
+	// Keys with alt don't type
+	b.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x"), Alt: true})
+	if b.search != "ui/" {
+		t.Errorf("alt+x typed: %q", b.search)
+	}
+
+	press(b, "enter", "esc")
+	if b.search != "" || b.dir != "" || len(b.items) != 1 {
+		t.Errorf("after esc: search %q, dir %q, %d items", b.search, b.dir, len(b.items))
+	}
+}
+
+func TestBrowseExtract(t *testing.T) {
+	b := testBrowser(t)
+	press(b, "enter", "down", "enter", "x") // data/texture/ui/, listed before grass.bmp
+	if !strings.HasPrefix(b.status, "Extracted 2 file(s)") {
+		t.Fatalf("status = %q", b.status)
+	}
+	for _, name := range []string{"ok.bmp", "skin.bmp"} {
+		if _, err := os.Stat(filepath.Join(b.outDir, "data", "texture", "ui", name)); err != nil {
+			t.Error(err)
+		}
+	}
+
+	// The status stays until the next key
+	press(b, "down")
+	if b.status != "" {
+		t.Errorf("status after a key = %q", b.status)
+	}
+}
+
+func TestBrowseQuit(t *testing.T) {
+	for _, key := range []string{"q", "ctrl+c"} {
+		b := testBrowser(t)
+		cmd := press(b, key)
+		if !b.quit || cmd == nil {
+			t.Fatalf("%s: quit %v", key, b.quit)
+		}
+		if _, ok := cmd().(tea.QuitMsg); !ok {
+			t.Errorf("%s: command is not tea.Quit", key)
+		}
+	}
+}
+
+func TestBrowseView(t *testing.T) {
+	b := testBrowser(t)
+	press(b, "enter", "down", "down") // data/readme.txt
+	view := b.View()
+	lines := strings.Split(view, "\n")
+	if len(lines) != 20 {
+		t.Fatalf("view has %d lines, want 20", len(lines))
+	}
+	for _, want := range []string{"test.grf", "/data/", "data/readme.txt", "Size 12 B", " hello", " world"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view is missing %q", want)
+		}
+	}
+
+	press(b, "t")
+	if view := b.View(); !strings.Contains(view, "68 65 6c 6c 6f") {
+		t.Errorf("hex view is missing the dump:\n%s", view)
+	}
+
+	// Nothing is drawn before the size is known
+	b.Update(tea.WindowSizeMsg{})
+	if view := b.View(); view != "" {
+		t.Errorf("view without a size = %q", view)
+	}
+}
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		s    string
+		w    int
+		want string
+	}{
+		{"abc", 5, "abc  "},
+		{"abcdef", 4, "abcd"},
+		{"a\tb", 3, "a b"},
+		{"가나다", 5, "가나 "}, // Hangul is two columns wide
+	}
+	for _, tt := range tests {
+		if got := fit(tt.s, tt.w); got != tt.want {
+			t.Errorf("fit(%q, %d) = %q, want %q", tt.s, tt.w, got, tt.want)
+		}
+	}
+}
+
+func TestIsText(t *testing.T) {
+	if !isText([]byte("line one\r\nline two\t\n")) {
+		t.Error("text reported as binary")
+	}
+	if isText([]byte{'a', 0, 'b'}) {
+		t.Error("data with a NUL reported as text")
+	}
+	if !isText(nil) {
+		t.Error("empty data reported as binary")
+	}
+}
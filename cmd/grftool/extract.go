@@ -0,0 +1,254 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Faultbox/midgard-ro/pkg/encoding"
+	"github.com/Faultbox/midgard-ro/pkg/grf"
+)
+
+// Output path encodings for -encode. GRF paths are EUC-KR bytes, which
+// most filesystems show as mojibake.
+const (
+	encodeUTF8   = "utf8"   // Transcode to UTF-8
+	encodeEUCKR  = "euckr"  // Keep the archive's bytes
+	encodeEscape = "escape" // Escape non-ASCII bytes as %XX, ASCII-only
+)
+
+// Policies for -collision, when an output file already exists or another
+// file of the archive was written to the same path.
+const (
+	collideOverwrite = "overwrite"
+	collideSkip      = "skip"
+	collideRename    = "rename" // Write next to it as name_2.ext, name_3.ext...
+)
+
+// extractor writes archive files to a directory.
+type extractor struct {
+	archive   *grf.Archive
+	outDir    string
+	encode    string
+	collision string
+	flat      bool // Write basenames only, without the archive's directories
+
+	// Output paths written so far, lowercased: two paths differing only in
+	// case are the same file on case-insensitive filesystems
+	written map[string]bool
+
+	// Summary statistics
+	extracted, overwritten, renamed, skipped, failed int
+
+	reencoded int   // Paths changed by -encode
+	bytes     int64 // Bytes written
+}
+
+func cmdExtract(args []string) {
+	fs := flag.NewFlagSet("extract", flag.ExitOnError)
+	encode := fs.String("encode", encodeUTF8, "Output path encoding: utf8, euckr (as stored) or escape")
+	collision := fs.String("collision", collideOverwrite, "When an output file exists: overwrite, skip or rename")
+	flat := fs.Bool("flat", false, "Write files by basename, without directories")
+	positional := parseInterspersed(fs, args)
+
+	if len(positional) < 2 {
+		fmt.Fprintln(os.Stderr, "Usage: grftool extract [-encode utf8|euckr|escape] [-collision overwrite|skip|rename] [-flat] <file.grf> <path> [output_dir]")
+		os.Exit(1)
+	}
+	switch *encode {
+	case encodeUTF8, encodeEUCKR, encodeEscape:
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown -encode %q: use utf8, euckr or escape\n", *encode)
+		os.Exit(1)
+	}
+	switch *collision {
+	case collideOverwrite, collideSkip, collideRename:
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown -collision %q: use overwrite, skip or rename\n", *collision)
+		os.Exit(1)
+	}
+
+	filePath := positional[1]
+	outputDir := "."
+	if len(positional) > 2 {
+		outputDir = positional[2]
+	}
+
+	archive, err := grf.Open(positional[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer archive.Close()
+
+	x := &extractor{
+		archive:   archive,
+		outDir:    outputDir,
+		encode:    *encode,
+		collision: *collision,
+		flat:      *flat,
+		written:   make(map[string]bool),
+	}
+
+	// A pattern matches basenames and keeps the directories; a single file
+	// is written by its basename
+	if strings.Contains(filePath, "*") {
+		x.extractPattern(filePath)
+	} else {
+		if !archive.Contains(filePath) {
+			fmt.Fprintf(os.Stderr, "File not found: %s\n", filePath)
+			os.Exit(1)
+		}
+		x.flat = true
+		x.extract(filePath)
+	}
+
+	x.printSummary()
+	if x.failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// extractPattern extracts the files whose basename matches pattern.
+func (x *extractor) extractPattern(pattern string) {
+	files := x.archive.List()
+	sort.Strings(files)
+	pattern = strings.ToLower(pattern)
+	for _, f := range files {
+		if matched, _ := filepath.Match(pattern, strings.ToLower(path.Base(f))); matched {
+			x.extract(f)
+		}
+	}
+}
+
+// extract writes one archive file, applying the encoding and collision
+// policy.
+func (x *extractor) extract(name string) {
+	rel := encodePath(name, x.encode)
+	if rel != name {
+		x.reencoded++
+	}
+	if x.flat {
+		rel = path.Base(rel)
+	}
+	out := filepath.Join(x.outDir, filepath.FromSlash(rel))
+	if !withinDir(x.outDir, out) {
+		fmt.Fprintf(os.Stderr, "Skipping %s: path leaves the output directory\n", name)
+		x.failed++
+		return
+	}
+
+	exists := x.taken(out)
+	if exists {
+		switch x.collision {
+		case collideSkip:
+			fmt.Printf("Skipped:   %s (exists)\n", out)
+			x.skipped++
+			return
+		case collideRename:
+			out = x.freeName(out)
+		}
+	}
+
+	data, err := x.archive.Read(name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", name, err)
+		x.failed++
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(out), 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating directory: %v\n", err)
+		x.failed++
+		return
+	}
+	if err := os.WriteFile(out, data, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", out, err)
+		x.failed++
+		return
+	}
+	x.written[strings.ToLower(out)] = true
+
+	switch {
+	case exists && x.collision == collideRename:
+		fmt.Printf("Extracted: %s (renamed)\n", out)
+		x.renamed++
+	case exists:
+		fmt.Printf("Extracted: %s (overwritten)\n", out)
+		x.overwritten++
+	default:
+		fmt.Printf("Extracted: %s\n", out)
+	}
+	x.extracted++
+	x.bytes += int64(len(data))
+}
+
+// taken reports whether an output path was written this run or exists.
+func (x *extractor) taken(out string) bool {
+	if x.written[strings.ToLower(out)] {
+		return true
+	}
+	_, err := os.Lstat(out)
+	return err == nil
+}
+
+// freeName returns the first of name_2.ext, name_3.ext... not taken.
+func (x *extractor) freeName(out string) string {
+	ext := filepath.Ext(out)
+	base := strings.TrimSuffix(out, ext)
+	for n := 2; ; n++ {
+		candidate := base + "_" + strconv.Itoa(n) + ext
+		if !x.taken(candidate) {
+			return candidate
+		}
+	}
+}
+
+// printSummary prints the extraction statistics.
+func (x *extractor) printSummary() {
+	fmt.Fprintf(os.Stderr, "\nExtracted %d files (%.2f MB) to %s\n", x.extracted, float64(x.bytes)/(1024*1024), x.outDir)
+	for _, s := range []struct {
+		label string
+		n     int
+	}{
+		{"Overwritten", x.overwritten},
+		{"Renamed", x.renamed},
+		{"Skipped", x.skipped},
+		{"Failed", x.failed},
+		{"Re-encoded paths", x.reencoded},
+	} {
+		if s.n > 0 {
+			fmt.Fprintf(os.Stderr, "  %-17s %d\n", s.label+":", s.n)
+		}
+	}
+}
+
+// encodePath converts an archive path to the output encoding.
+func encodePath(name, encode string) string {
+	switch encode {
+	case encodeUTF8:
+		return encoding.EUCKRStringToUTF8(name)
+	case encodeEscape:
+		var b strings.Builder
+		for i := 0; i < len(name); i++ {
+			if c := name[i]; c >= 0x80 || c < 0x20 || c == '%' {
+				fmt.Fprintf(&b, "%%%02X", c)
+			} else {
+				b.WriteByte(c)
+			}
+		}
+		return b.String()
+	}
+	return name
+}
+
+// withinDir reports whether out is inside dir, so archive paths with ".."
+// can't write elsewhere.
+func withinDir(dir, out string) bool {
+	rel, err := filepath.Rel(dir, out)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
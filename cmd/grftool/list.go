@@ -0,0 +1,181 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Faultbox/midgard-ro/pkg/grf"
+)
+
+// Orders for -sort.
+const (
+	sortName = "name"
+	sortSize = "size" // Largest first
+	sortExt  = "ext"  // By extension, then name
+)
+
+// listRow is a row of the listing: a file, or the totals of a directory's
+// files.
+type listRow struct {
+	path   string
+	files  int
+	size   uint64 // Uncompressed
+	stored uint64 // As stored in the archive
+}
+
+// ratio returns the stored size as a percentage of the uncompressed size.
+func (r *listRow) ratio() float64 {
+	if r.size == 0 {
+		return 100
+	}
+	return float64(r.stored) / float64(r.size) * 100
+}
+
+func cmdList(args []string) {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	limit := fs.Int("n", 0, "Limit output to N rows (0 = all)")
+	long := fs.Bool("l", false, "Long format: size, stored size and compression ratio")
+	order := fs.String("sort", sortName, "Sort by name, size (largest first) or ext")
+	dirsOnly := fs.Bool("dirs-only", false, "List the directories holding the files, not the files")
+	summary := fs.Bool("summary", false, "End with the totals of each directory")
+	positional := parseInterspersed(fs, args)
+
+	if len(positional) < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: grftool list [-l] [-sort name|size|ext] [-dirs-only] [-summary] [-n N] <file.grf> [pattern]")
+		os.Exit(1)
+	}
+	switch *order {
+	case sortName, sortSize, sortExt:
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown -sort %q: use name, size or ext\n", *order)
+		os.Exit(1)
+	}
+
+	archive, err := grf.Open(positional[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer archive.Close()
+
+	pattern := ""
+	if len(positional) > 1 {
+		pattern = strings.ToLower(positional[1])
+	}
+
+	var files []listRow
+	for _, f := range archive.List() {
+		if pattern != "" {
+			matched, _ := filepath.Match(pattern, strings.ToLower(path.Base(f)))
+			if !matched && !strings.Contains(strings.ToLower(f), pattern) {
+				continue
+			}
+		}
+		entry, _ := archive.Stat(f)
+		files = append(files, listRow{
+			path:   f,
+			files:  1,
+			size:   uint64(entry.UncompressedSize),
+			stored: uint64(entry.CompressedSize),
+		})
+	}
+
+	if *long {
+		// GRF entries carry no dates, only the archive file has one
+		if info, err := os.Stat(positional[0]); err == nil {
+			fmt.Fprintf(os.Stderr, "%s, modified %s\n", positional[0], info.ModTime().Format("2006-01-02 15:04"))
+		}
+	}
+
+	rows := files
+	if *dirsOnly {
+		rows = dirTotals(files)
+	}
+	sortRows(rows, *order)
+	shown := rows
+	if *limit > 0 && len(shown) > *limit {
+		shown = shown[:*limit]
+	}
+	for i := range shown {
+		printRow(&shown[i], *long, *dirsOnly)
+	}
+
+	if *summary {
+		dirs := dirTotals(files)
+		sortRows(dirs, *order)
+		total := listRow{path: "total"}
+		fmt.Println()
+		for i := range dirs {
+			printRow(&dirs[i], true, true)
+			total.files += dirs[i].files
+			total.size += dirs[i].size
+			total.stored += dirs[i].stored
+		}
+		printRow(&total, true, true)
+	}
+
+	if pattern != "" {
+		fmt.Fprintf(os.Stderr, "\n(%d files matched)\n", len(files))
+	}
+}
+
+// dirTotals adds up the files of each directory.
+func dirTotals(files []listRow) []listRow {
+	index := make(map[string]int)
+	var dirs []listRow
+	for _, f := range files {
+		dir := path.Dir(f.path)
+		i, ok := index[dir]
+		if !ok {
+			i = len(dirs)
+			index[dir] = i
+			dirs = append(dirs, listRow{path: dir})
+		}
+		t := &dirs[i]
+		t.files++
+		t.size += f.size
+		t.stored += f.stored
+	}
+	return dirs
+}
+
+// sortRows sorts rows in an -sort order, by path among equals.
+func sortRows(rows []listRow, order string) {
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := &rows[i], &rows[j]
+		switch order {
+		case sortSize:
+			if a.size != b.size {
+				return a.size > b.size
+			}
+		case sortExt:
+			if ea, eb := strings.ToLower(path.Ext(a.path)), strings.ToLower(path.Ext(b.path)); ea != eb {
+				return ea < eb
+			}
+		}
+		return a.path < b.path
+	})
+}
+
+// printRow prints a row: its path, after its sizes and ratio in long
+// format, and for a directory its file count.
+func printRow(r *listRow, long, dir bool) {
+	if !long {
+		if dir {
+			fmt.Println(r.path + "/")
+		} else {
+			fmt.Println(r.path)
+		}
+		return
+	}
+	files := ""
+	if dir {
+		files = fmt.Sprintf("%7d files  ", r.files)
+	}
+	fmt.Printf("%s%10s %10s %4.0f%%  %s\n", files, formatSize(r.size), formatSize(r.stored), r.ratio(), r.path)
+}
@@ -30,6 +30,14 @@ func main() {
 		cmdExtract(args)
 	case "search", "find":
 		cmdSearch(args)
+	case "patch":
+		cmdPatch(args)
+	case "mapimage":
+		cmdMapImage(args)
+	case "manifest":
+		cmdManifest(args)
+	case "browse":
+		cmdBrowse(args)
 	case "help", "-h", "--help":
 		printUsage()
 	default:
@@ -48,14 +56,34 @@ Usage:
 Commands:
   info <file.grf>                    Show archive information
   list <file.grf> [pattern]          List files (optional glob pattern)
+                                     (-l sizes and ratio, -sort name|size|ext, -dirs-only, -summary)
   extract <file.grf> <path> [output] Extract file(s) to directory
+                                     (-encode utf8|euckr|escape, -collision overwrite|skip|rename, -flat)
   search <file.grf> <pattern>        Search files by name pattern
+  patch apply <base.grf> <patch.gpf>...
+                                     Merge patches into a new archive (-o output)
+  patch create <old.grf> <new.grf> <out.gpf>
+                                     Create a patch with new and changed files
+  mapimage <file.grf> <map> [out.png]
+                                     Render a map's ground seen from above (-size max pixels)
+  manifest <file.grf>...             Write file and archive hashes (-o manifest.json)
+  manifest verify <manifest.json>    Check an installation against a manifest (-dir client)
+  browse <file.grf>                  Browse the archive in the terminal (-o extract dir)
 
 Examples:
   grftool info data.grf
   grftool list data.grf "*.spr"
+  grftool list -l -sort size -n 20 data.grf
+  grftool list -dirs-only -summary data.grf "*.bmp"
   grftool extract data.grf data/sprite/npc/npc.spr ./output
-  grftool search data.grf "prontera"`)
+  grftool extract -collision rename -flat data.grf "*.spr" ./sprites
+  grftool search data.grf "prontera"
+  grftool patch apply -o merged.grf data.grf 2024-01-01.gpf 2024-02-01.gpf
+  grftool patch create old.grf new.grf update.gpf
+  grftool mapimage -size 1024 data.grf prontera prontera.png
+  grftool manifest data.grf -o manifest.json
+  grftool manifest verify -dir /path/to/client manifest.json
+  grftool browse -o ./extracted data.grf`)
 }
 
 func cmdInfo(args []string) {
@@ -110,13 +138,13 @@ func cmdInfo(args []string) {
 	}
 }
 
-func cmdList(args []string) {
-	fs := flag.NewFlagSet("list", flag.ExitOnError)
-	limit := fs.Int("n", 0, "Limit output to N files (0 = all)")
+func cmdSearch(args []string) {
+	fs := flag.NewFlagSet("search", flag.ExitOnError)
+	limit := fs.Int("n", 50, "Limit results (0 = all)")
 	fs.Parse(args)
 
-	if fs.NArg() < 1 {
-		fmt.Fprintln(os.Stderr, "Usage: grftool list <file.grf> [pattern]")
+	if fs.NArg() < 2 {
+		fmt.Fprintln(os.Stderr, "Usage: grftool search <file.grf> <pattern>")
 		os.Exit(1)
 	}
 
@@ -128,160 +156,101 @@ func cmdList(args []string) {
 	defer archive.Close()
 
 	files := archive.List()
-	sort.Strings(files)
-
-	pattern := ""
-	if fs.NArg() > 1 {
-		pattern = strings.ToLower(fs.Arg(1))
-	}
+	pattern := strings.ToLower(fs.Arg(1))
 
 	count := 0
 	for _, f := range files {
-		if pattern != "" {
-			matched, _ := filepath.Match(pattern, strings.ToLower(filepath.Base(f)))
-			if !matched && !strings.Contains(strings.ToLower(f), pattern) {
-				continue
+		if strings.Contains(strings.ToLower(f), pattern) {
+			fmt.Println(f)
+			count++
+			if *limit > 0 && count >= *limit {
+				fmt.Fprintf(os.Stderr, "\n(showing first %d matches, use -n 0 for all)\n", *limit)
+				break
 			}
 		}
-		fmt.Println(f)
-		count++
-		if *limit > 0 && count >= *limit {
-			break
-		}
 	}
 
-	if pattern != "" {
-		fmt.Fprintf(os.Stderr, "\n(%d files matched)\n", count)
+	if count == 0 {
+		fmt.Fprintln(os.Stderr, "No files found")
+	} else if *limit == 0 || count < *limit {
+		fmt.Fprintf(os.Stderr, "\n(%d files found)\n", count)
 	}
 }
 
-func cmdExtract(args []string) {
-	fs := flag.NewFlagSet("extract", flag.ExitOnError)
-	fs.Parse(args)
-
-	if fs.NArg() < 2 {
-		fmt.Fprintln(os.Stderr, "Usage: grftool extract <file.grf> <path> [output_dir]")
+func cmdPatch(args []string) {
+	if len(args) < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: grftool patch <apply|create> [options]")
 		os.Exit(1)
 	}
 
-	grfPath := fs.Arg(0)
-	filePath := fs.Arg(1)
-	outputDir := "."
-	if fs.NArg() > 2 {
-		outputDir = fs.Arg(2)
-	}
-
-	archive, err := grf.Open(grfPath)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	switch args[0] {
+	case "apply":
+		cmdPatchApply(args[1:])
+	case "create":
+		cmdPatchCreate(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown patch command: %s\n", args[0])
 		os.Exit(1)
 	}
-	defer archive.Close()
-
-	// Check if it's a pattern
-	if strings.Contains(filePath, "*") {
-		extractPattern(archive, filePath, outputDir)
-		return
-	}
+}
 
-	// Single file extraction
-	if !archive.Contains(filePath) {
-		fmt.Fprintf(os.Stderr, "File not found: %s\n", filePath)
-		os.Exit(1)
-	}
+func cmdPatchApply(args []string) {
+	fs := flag.NewFlagSet("patch apply", flag.ExitOnError)
+	output := fs.String("o", "", "Output archive (default: <base>_patched.grf)")
+	fs.Parse(args)
 
-	data, err := archive.Read(filePath)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading file: %v\n", err)
+	if fs.NArg() < 2 {
+		fmt.Fprintln(os.Stderr, "Usage: grftool patch apply [-o output.grf] <base.grf> <patch.gpf>...")
 		os.Exit(1)
 	}
 
-	// Create output path
-	outputPath := filepath.Join(outputDir, filepath.Base(filePath))
-	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating directory: %v\n", err)
-		os.Exit(1)
+	basePath := fs.Arg(0)
+	outPath := *output
+	if outPath == "" {
+		outPath = strings.TrimSuffix(basePath, filepath.Ext(basePath)) + "_patched.grf"
 	}
 
-	if err := os.WriteFile(outputPath, data, 0644); err != nil {
-		fmt.Fprintf(os.Stderr, "Error writing file: %v\n", err)
+	result, err := grf.ApplyPatches(basePath, fs.Args()[1:], outPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("Extracted: %s (%d bytes)\n", outputPath, len(data))
+	fmt.Printf("Wrote %s (%d files)\n", outPath, result.Total)
+	fmt.Printf("  Added:    %d\n", len(result.Added))
+	fmt.Printf("  Replaced: %d\n", len(result.Replaced))
 }
 
-func extractPattern(archive *grf.Archive, pattern, outputDir string) {
-	files := archive.List()
-	pattern = strings.ToLower(pattern)
-
-	extracted := 0
-	for _, f := range files {
-		matched, _ := filepath.Match(pattern, strings.ToLower(filepath.Base(f)))
-		if !matched {
-			continue
-		}
-
-		data, err := archive.Read(f)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", f, err)
-			continue
-		}
-
-		// Preserve directory structure
-		outputPath := filepath.Join(outputDir, f)
-		if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
-			fmt.Fprintf(os.Stderr, "Error creating directory: %v\n", err)
-			continue
-		}
-
-		if err := os.WriteFile(outputPath, data, 0644); err != nil {
-			fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", outputPath, err)
-			continue
-		}
-
-		fmt.Printf("Extracted: %s\n", outputPath)
-		extracted++
-	}
-
-	fmt.Fprintf(os.Stderr, "\nExtracted %d files\n", extracted)
-}
-
-func cmdSearch(args []string) {
-	fs := flag.NewFlagSet("search", flag.ExitOnError)
-	limit := fs.Int("n", 50, "Limit results (0 = all)")
+func cmdPatchCreate(args []string) {
+	fs := flag.NewFlagSet("patch create", flag.ExitOnError)
+	verbose := fs.Bool("v", false, "List each patched file")
 	fs.Parse(args)
 
-	if fs.NArg() < 2 {
-		fmt.Fprintln(os.Stderr, "Usage: grftool search <file.grf> <pattern>")
+	if fs.NArg() < 3 {
+		fmt.Fprintln(os.Stderr, "Usage: grftool patch create [-v] <old.grf> <new.grf> <out.gpf>")
 		os.Exit(1)
 	}
 
-	archive, err := grf.Open(fs.Arg(0))
+	result, err := grf.CreatePatch(fs.Arg(0), fs.Arg(1), fs.Arg(2))
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
-	defer archive.Close()
-
-	files := archive.List()
-	pattern := strings.ToLower(fs.Arg(1))
 
-	count := 0
-	for _, f := range files {
-		if strings.Contains(strings.ToLower(f), pattern) {
-			fmt.Println(f)
-			count++
-			if *limit > 0 && count >= *limit {
-				fmt.Fprintf(os.Stderr, "\n(showing first %d matches, use -n 0 for all)\n", *limit)
-				break
-			}
+	if *verbose {
+		for _, f := range result.Added {
+			fmt.Printf("A %s\n", f)
+		}
+		for _, f := range result.Replaced {
+			fmt.Printf("M %s\n", f)
 		}
 	}
 
-	if count == 0 {
-		fmt.Fprintln(os.Stderr, "No files found")
-	} else if *limit == 0 || count < *limit {
-		fmt.Fprintf(os.Stderr, "\n(%d files found)\n", count)
+	fmt.Printf("Wrote %s (%d files)\n", fs.Arg(2), result.Total)
+	fmt.Printf("  Added:    %d\n", len(result.Added))
+	fmt.Printf("  Changed:  %d\n", len(result.Replaced))
+	if len(result.Removed) > 0 {
+		// GPF has no way to express deletions
+		fmt.Fprintf(os.Stderr, "Warning: %d files removed in %s cannot be expressed in a patch\n", len(result.Removed), fs.Arg(1))
 	}
 }
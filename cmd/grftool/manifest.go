@@ -0,0 +1,118 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Faultbox/midgard-ro/pkg/grf"
+)
+
+func cmdManifest(args []string) {
+	if len(args) > 0 && args[0] == "verify" {
+		cmdManifestVerify(args[1:])
+		return
+	}
+
+	fs := flag.NewFlagSet("manifest", flag.ExitOnError)
+	output := fs.String("o", "manifest.json", "Output manifest")
+	paths := parseInterspersed(fs, args)
+
+	if len(paths) < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: grftool manifest [-o manifest.json] <file.grf>...")
+		os.Exit(1)
+	}
+
+	// Archives are named relative to the manifest, the client directory
+	m, err := grf.BuildManifest(filepath.Dir(*output), paths...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := grf.WriteManifest(*output, m); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing manifest: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Wrote %s\n", *output)
+	for _, a := range m.Archives {
+		fmt.Printf("  %s  %s  (%d files)\n", a.SHA256, a.Name, len(a.Files))
+	}
+}
+
+func cmdManifestVerify(args []string) {
+	fs := flag.NewFlagSet("manifest verify", flag.ExitOnError)
+	dir := fs.String("dir", "", "Client directory (default: the manifest's directory)")
+	verbose := fs.Bool("v", false, "List each mismatched file")
+	paths := parseInterspersed(fs, args)
+
+	if len(paths) < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: grftool manifest verify [-dir client] [-v] <manifest.json>")
+		os.Exit(1)
+	}
+
+	m, err := grf.ReadManifest(paths[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	clientDir := *dir
+	if clientDir == "" {
+		clientDir = filepath.Dir(paths[0])
+	}
+
+	checks, err := m.Verify(clientDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	failed := 0
+	for _, c := range checks {
+		switch {
+		case c.Missing:
+			fmt.Printf("MISSING  %s\n", c.Name)
+		case c.Intact:
+			fmt.Printf("OK       %s\n", c.Name)
+		case c.OK():
+			fmt.Printf("OK       %s (repacked, all files match)\n", c.Name)
+		default:
+			fmt.Printf("FAILED   %s: %d changed, %d missing, %d extra\n", c.Name, len(c.Changed), len(c.Absent), len(c.Extra))
+			if *verbose {
+				for _, f := range c.Changed {
+					fmt.Printf("  M %s\n", f)
+				}
+				for _, f := range c.Absent {
+					fmt.Printf("  D %s\n", f)
+				}
+				for _, f := range c.Extra {
+					fmt.Printf("  A %s\n", f)
+				}
+			}
+		}
+		if !c.OK() {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "\n%d of %d archives failed verification\n", failed, len(checks))
+		os.Exit(1)
+	}
+}
+
+// parseInterspersed parses flags given before or after the positional
+// arguments, as in "manifest data.grf -o manifest.json", and returns the
+// positional arguments.
+func parseInterspersed(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		fs.Parse(args)
+		if fs.NArg() == 0 {
+			return positional
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"strings"
+
+	_ "golang.org/x/image/bmp" // BMP decoder registration
+
+	"github.com/Faultbox/midgard-ro/internal/engine/texture"
+	"github.com/Faultbox/midgard-ro/pkg/formats"
+	"github.com/Faultbox/midgard-ro/pkg/grf"
+)
+
+func cmdMapImage(args []string) {
+	fs := flag.NewFlagSet("mapimage", flag.ExitOnError)
+	size := fs.Int("size", 512, "Longest image side in pixels")
+	fs.Parse(args)
+
+	if fs.NArg() < 2 {
+		fmt.Fprintln(os.Stderr, "Usage: grftool mapimage [-size N] <file.grf> <map> [out.png]")
+		os.Exit(1)
+	}
+
+	mapName := strings.TrimSuffix(strings.ToLower(fs.Arg(1)), ".gnd")
+	outputPath := mapName + ".png"
+	if fs.NArg() > 2 {
+		outputPath = fs.Arg(2)
+	}
+
+	archive, err := grf.Open(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer archive.Close()
+
+	data, err := archive.Read("data/" + mapName + ".gnd")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	gnd, err := formats.ParseGND(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing %s.gnd: %v\n", mapName, err)
+		os.Exit(1)
+	}
+
+	texColors, missing := groundTextureColors(archive, gnd)
+	if missing > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %d of %d textures not loaded, using placeholder colors\n", missing, len(gnd.Textures))
+	}
+
+	w, h := *size, *size
+	if gnd.Width > gnd.Height {
+		h = max(1, *size*int(gnd.Height)/int(gnd.Width))
+	} else {
+		w = max(1, *size*int(gnd.Width)/int(gnd.Height))
+	}
+	img := gnd.RenderOverview(w, h, texColors)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding image: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(outputPath, buf.Bytes(), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing file: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Rendered: %s (%dx%d from %dx%d tiles)\n", outputPath, w, h, gnd.Width, gnd.Height)
+}
+
+// groundTextureColors returns the average color of each GND texture and how
+// many couldn't be loaded. Unloaded textures have a zero color.
+func groundTextureColors(archive *grf.Archive, gnd *formats.GND) ([]color.RGBA, int) {
+	colors := make([]color.RGBA, len(gnd.Textures))
+	missing := 0
+	for i, name := range gnd.Textures {
+		data, err := archive.Read("data/texture/" + name)
+		if err != nil {
+			missing++
+			continue
+		}
+		var img image.Image
+		if strings.HasSuffix(strings.ToLower(name), ".tga") {
+			img, err = texture.DecodeTGA(data)
+		} else {
+			img, _, err = image.Decode(bytes.NewReader(data))
+		}
+		if err != nil {
+			missing++
+			continue
+		}
+		colors[i] = formats.AverageColor(img)
+	}
+	return colors, missing
+}
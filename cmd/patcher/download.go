@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// progressFunc reports download progress; total is -1 when unknown.
+type progressFunc func(name string, done, total int64)
+
+// downloader fetches the patch list and patch files, resuming partial
+// downloads left in the cache directory by an interrupted run.
+type downloader struct {
+	client   *http.Client
+	fileURL  *url.URL // Base URL patch file names are resolved against
+	cacheDir string
+	progress progressFunc
+}
+
+// fetchList downloads and parses the patch list.
+func (d *downloader) fetchList(ctx context.Context, listURL string) ([]PatchEntry, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, listURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("patch list request: %w", err)
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching patch list: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching patch list: %s", resp.Status)
+	}
+	return parsePatchList(resp.Body)
+}
+
+// download fetches a patch into the cache directory and returns its path.
+// A completed file from an earlier run is reused if its checksum matches.
+func (d *downloader) download(ctx context.Context, e PatchEntry) (string, error) {
+	name := filepath.Base(filepath.FromSlash(e.Name))
+	dest := filepath.Join(d.cacheDir, name)
+	if _, err := os.Stat(dest); err == nil && e.Checksum != "" && verifyChecksum(dest, e.Checksum) == nil {
+		return dest, nil
+	}
+
+	part := dest + ".part"
+	if err := d.fetch(ctx, e.Name, part); err != nil {
+		return "", err
+	}
+	if err := verifyChecksum(part, e.Checksum); err != nil {
+		// Don't resume from corrupt data next time
+		os.Remove(part)
+		return "", err
+	}
+	if err := os.Rename(part, dest); err != nil {
+		return "", fmt.Errorf("finishing %s: %w", name, err)
+	}
+	return dest, nil
+}
+
+// fetch downloads name into path, appending to any partial file with a
+// Range request. Servers that ignore the range restart from scratch.
+func (d *downloader) fetch(ctx context.Context, name, path string) error {
+	ref, err := url.Parse(strings.TrimPrefix(name, "/"))
+	if err != nil {
+		return fmt.Errorf("invalid patch name %q: %w", name, err)
+	}
+	fileURL := d.fileURL.ResolveReference(ref)
+
+	var offset int64
+	if info, err := os.Stat(path); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL.String(), nil)
+	if err != nil {
+		return fmt.Errorf("request for %s: %w", name, err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("downloading %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		flags |= os.O_APPEND
+	case http.StatusOK:
+		flags |= os.O_TRUNC
+		offset = 0
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The partial file is already complete
+		return nil
+	default:
+		return fmt.Errorf("downloading %s: %s", name, resp.Status)
+	}
+
+	f, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer f.Close()
+
+	total := int64(-1)
+	if resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
+	}
+
+	var src io.Reader = resp.Body
+	if d.progress != nil {
+		src = &progressReader{r: resp.Body, name: name, done: offset, total: total, report: d.progress}
+	}
+	if _, err := io.Copy(f, src); err != nil {
+		return fmt.Errorf("downloading %s: %w", name, err)
+	}
+	return nil
+}
+
+// progressReader reports bytes read through a progressFunc.
+type progressReader struct {
+	r      io.Reader
+	name   string
+	done   int64
+	total  int64
+	report progressFunc
+}
+
+func (p *progressReader) Read(buf []byte) (int, error) {
+	n, err := p.r.Read(buf)
+	p.done += int64(n)
+	p.report(p.name, p.done, p.total)
+	return n, err
+}
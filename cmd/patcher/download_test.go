@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDownloadResumesPartialFile(t *testing.T) {
+	content := bytes.Repeat([]byte("patch data "), 100)
+	var sawRange string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sawRange = r.Header.Get("Range")
+		http.ServeContent(w, r, "update.gpf", time.Time{}, bytes.NewReader(content))
+	}))
+	defer srv.Close()
+
+	base, _ := url.Parse(srv.URL + "/")
+	cacheDir := t.TempDir()
+	d := &downloader{client: srv.Client(), fileURL: base, cacheDir: cacheDir}
+
+	// Simulate an interrupted earlier download
+	if err := os.WriteFile(filepath.Join(cacheDir, "update.gpf.part"), content[:300], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := d.download(context.Background(), PatchEntry{Index: 1, Name: "update.gpf"})
+	if err != nil {
+		t.Fatalf("download error: %v", err)
+	}
+	if sawRange != "bytes=300-" {
+		t.Errorf("Range header = %q, want bytes=300-", sawRange)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("downloaded %d bytes, want %d matching bytes", len(got), len(content))
+	}
+}
+
+func TestDownloadRejectsBadChecksum(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("corrupt"))
+	}))
+	defer srv.Close()
+
+	base, _ := url.Parse(srv.URL + "/")
+	cacheDir := t.TempDir()
+	d := &downloader{client: srv.Client(), fileURL: base, cacheDir: cacheDir}
+
+	entry := PatchEntry{Index: 1, Name: "update.gpf", Checksum: "5d41402abc4b2a76b9719d911017c592"}
+	if _, err := d.download(context.Background(), entry); err == nil {
+		t.Fatal("expected checksum error")
+	}
+	if _, err := os.Stat(filepath.Join(cacheDir, "update.gpf.part")); !os.IsNotExist(err) {
+		t.Error("corrupt partial download should be removed")
+	}
+}
+
+func TestPatchFileURL(t *testing.T) {
+	tests := []struct {
+		list, files, want string
+	}{
+		{"http://patch.example.com/ro/plist.txt", "", "http://patch.example.com/ro/"},
+		{"http://patch.example.com/plist.txt", "http://cdn.example.com/data", "http://cdn.example.com/data/"},
+	}
+	for _, tt := range tests {
+		u, err := patchFileURL(tt.list, tt.files)
+		if err != nil {
+			t.Errorf("patchFileURL(%q, %q) error: %v", tt.list, tt.files, err)
+			continue
+		}
+		if u.String() != tt.want {
+			t.Errorf("patchFileURL(%q, %q) = %s, want %s", tt.list, tt.files, u, tt.want)
+		}
+	}
+}
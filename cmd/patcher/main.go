@@ -0,0 +1,339 @@
+// patcher is the client launcher: it downloads new patches from the server's
+// patch list, merges them into the client GRF and starts the game.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Faultbox/midgard-ro/pkg/grf"
+)
+
+const stateFile = "patcher_state.json"
+
+// state is persisted in the client directory between runs.
+type state struct {
+	LastIndex int `json:"last_index"`
+}
+
+// options holds the command-line settings.
+type options struct {
+	listURL   string
+	fileURL   string
+	clientDir string
+	grfName   string
+	client    string
+	noLaunch  bool
+	keep      bool
+	timeout   time.Duration
+	clientArg []string
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.listURL, "list", "", "Patch list URL (e.g. http://patch.example.com/plist.txt)")
+	flag.StringVar(&opts.fileURL, "files", "", "Base URL for patch files (default: the patch list's directory)")
+	flag.StringVar(&opts.clientDir, "dir", ".", "Client directory")
+	flag.StringVar(&opts.grfName, "grf", "data.grf", "Archive patches are merged into, relative to -dir")
+	flag.StringVar(&opts.client, "client", "midgard", "Client executable to launch, relative to -dir")
+	flag.BoolVar(&opts.noLaunch, "no-launch", false, "Only patch, do not start the client")
+	flag.BoolVar(&opts.keep, "keep", false, "Keep downloaded patches after applying them")
+	flag.DurationVar(&opts.timeout, "timeout", 30*time.Second, "Timeout for stalled connections")
+	flag.Parse()
+	opts.clientArg = flag.Args()
+
+	if opts.listURL == "" {
+		fmt.Fprintln(os.Stderr, "Usage: patcher -list <plist url> [options] [-- client args]")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	restart, err := run(ctx, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if restart {
+		if err := restartSelf(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error restarting patcher: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if !opts.noLaunch {
+		if err := launch(opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error launching client: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// run downloads and applies pending patches. It returns true when the
+// patcher replaced its own executable and must be restarted.
+func run(ctx context.Context, opts options) (bool, error) {
+	self, err := os.Executable()
+	if err != nil {
+		return false, fmt.Errorf("locating patcher executable: %w", err)
+	}
+	// Left behind by a previous self-update
+	os.Remove(self + ".old")
+
+	fileURL, err := patchFileURL(opts.listURL, opts.fileURL)
+	if err != nil {
+		return false, err
+	}
+
+	cacheDir := filepath.Join(opts.clientDir, "patches")
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return false, fmt.Errorf("creating patch cache: %w", err)
+	}
+
+	st := loadState(opts.clientDir)
+	d := &downloader{
+		client:   &http.Client{Transport: &http.Transport{ResponseHeaderTimeout: opts.timeout}},
+		fileURL:  fileURL,
+		cacheDir: cacheDir,
+		progress: printProgress,
+	}
+
+	entries, err := d.fetchList(ctx, opts.listURL)
+	if err != nil {
+		return false, err
+	}
+	pending := pendingPatches(entries, st.LastIndex)
+	if len(pending) == 0 {
+		fmt.Println("Client is up to date")
+		return false, nil
+	}
+	for _, e := range pending {
+		if err := e.checkFormat(); err != nil {
+			return false, err
+		}
+	}
+	fmt.Printf("%d patches to apply\n", len(pending))
+
+	var archives, downloaded []string
+	var selfUpdate string
+	for _, e := range pending {
+		path, err := d.download(ctx, e)
+		fmt.Println()
+		if err != nil {
+			return false, err
+		}
+		downloaded = append(downloaded, path)
+
+		switch {
+		case e.IsArchive():
+			archives = append(archives, path)
+		case strings.EqualFold(filepath.Base(e.Name), filepath.Base(self)):
+			selfUpdate = path
+		default:
+			if err := installFile(opts.clientDir, e.Name, path); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	if len(archives) > 0 {
+		if err := mergeArchives(filepath.Join(opts.clientDir, opts.grfName), archives); err != nil {
+			return false, err
+		}
+	}
+	if selfUpdate != "" {
+		if err := replaceSelf(self, selfUpdate); err != nil {
+			return false, err
+		}
+	}
+
+	st.LastIndex = pending[len(pending)-1].Index
+	if err := saveState(opts.clientDir, st); err != nil {
+		return false, err
+	}
+
+	if !opts.keep {
+		for _, path := range downloaded {
+			os.Remove(path)
+		}
+	}
+	fmt.Printf("Patched to #%d\n", st.LastIndex)
+	return selfUpdate != "", nil
+}
+
+// patchFileURL returns the base URL for patch files, defaulting to the
+// directory containing the patch list.
+func patchFileURL(listURL, fileURL string) (*url.URL, error) {
+	base := fileURL
+	if base == "" {
+		base = listURL[:strings.LastIndex(listURL, "/")+1]
+	}
+	if !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+	u, err := url.Parse(base)
+	if err != nil {
+		return nil, fmt.Errorf("invalid patch file URL %q: %w", base, err)
+	}
+	return u, nil
+}
+
+// mergeArchives applies GPF patches to the client GRF. The merged archive is
+// written next to it and swapped in only once complete.
+func mergeArchives(grfPath string, patches []string) error {
+	if _, err := os.Stat(grfPath); errors.Is(err, os.ErrNotExist) {
+		// Fresh install: start from an empty archive
+		w, err := grf.Create(grfPath)
+		if err != nil {
+			return err
+		}
+		if err := w.Close(); err != nil {
+			return err
+		}
+	}
+
+	tmp := grfPath + ".new"
+	result, err := grf.ApplyPatches(grfPath, patches, tmp)
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("applying patches: %w", err)
+	}
+	if err := os.Rename(tmp, grfPath); err != nil {
+		return fmt.Errorf("replacing %s: %w", grfPath, err)
+	}
+	fmt.Printf("Updated %s: %d added, %d replaced\n", filepath.Base(grfPath), len(result.Added), len(result.Replaced))
+	return nil
+}
+
+// installFile copies a non-archive patch into the client directory.
+func installFile(clientDir, name, src string) error {
+	rel := filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("patch %s: path escapes the client directory", name)
+	}
+
+	dest := filepath.Join(clientDir, rel)
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("installing %s: %w", name, err)
+	}
+	if err := copyFile(src, dest, 0644); err != nil {
+		return fmt.Errorf("installing %s: %w", name, err)
+	}
+	fmt.Printf("Installed %s\n", rel)
+	return nil
+}
+
+// replaceSelf swaps in a new patcher executable. The running binary is
+// renamed rather than overwritten, which also works on Windows.
+func replaceSelf(self, update string) error {
+	staged := self + ".new"
+	if err := copyFile(update, staged, 0755); err != nil {
+		return fmt.Errorf("staging patcher update: %w", err)
+	}
+	// An earlier update's binary may still be there if it was running when
+	// the patcher started; Windows won't rename over it
+	if err := os.Remove(self + ".old"); err != nil && !errors.Is(err, os.ErrNotExist) {
+		os.Remove(staged)
+		return fmt.Errorf("removing previous patcher: %w", err)
+	}
+	if err := os.Rename(self, self+".old"); err != nil {
+		return fmt.Errorf("replacing patcher: %w", err)
+	}
+	if err := os.Rename(staged, self); err != nil {
+		// Put the old binary back so the install still works
+		os.Rename(self+".old", self)
+		return fmt.Errorf("replacing patcher: %w", err)
+	}
+	fmt.Println("Patcher updated, restarting")
+	return nil
+}
+
+// restartSelf starts the (updated) patcher with the same arguments.
+func restartSelf() error {
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(self, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd.Start()
+}
+
+// launch starts the game client without waiting for it.
+func launch(opts options) error {
+	client := opts.client
+	if !filepath.IsAbs(client) {
+		client = filepath.Join(opts.clientDir, client)
+	}
+	cmd := exec.Command(client, opts.clientArg...)
+	cmd.Dir = opts.clientDir
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	fmt.Printf("Started %s\n", filepath.Base(client))
+	return cmd.Process.Release()
+}
+
+func loadState(clientDir string) state {
+	var st state
+	data, err := os.ReadFile(filepath.Join(clientDir, stateFile))
+	if err != nil {
+		return st
+	}
+	if err := json.Unmarshal(data, &st); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring corrupt %s: %v\n", stateFile, err)
+		return state{}
+	}
+	return st
+}
+
+func saveState(clientDir string, st state) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(clientDir, stateFile), data, 0644); err != nil {
+		return fmt.Errorf("saving patch state: %w", err)
+	}
+	return nil
+}
+
+func copyFile(src, dest string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func printProgress(name string, done, total int64) {
+	if total > 0 {
+		fmt.Printf("\r%s: %.1f / %.1f MB (%d%%)", name, float64(done)/1024/1024, float64(total)/1024/1024, done*100/total)
+	} else {
+		fmt.Printf("\r%s: %.1f MB", name, float64(done)/1024/1024)
+	}
+}
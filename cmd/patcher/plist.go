@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bufio"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PatchEntry is one line of a patch list.
+type PatchEntry struct {
+	Index    int    // Monotonic patch number; entries at or below the last applied index are skipped
+	Name     string // File name relative to the patch server's file URL
+	Checksum string // Optional hex MD5 or SHA-256 of the file
+}
+
+// IsArchive reports whether the entry is merged into the GRF rather than
+// copied into the client directory.
+func (e PatchEntry) IsArchive() bool {
+	name := strings.ToLower(e.Name)
+	return strings.HasSuffix(name, ".gpf") || strings.HasSuffix(name, ".grf")
+}
+
+// unsupportedFormats are patch archives other patchers apply that this one
+// can't read. Installed as loose files they would leave the client
+// unpatched, so they're refused instead.
+var unsupportedFormats = []string{".thor", ".rgz"}
+
+// checkFormat returns an error if the entry is a patch archive in a
+// format the patcher can't apply.
+func (e PatchEntry) checkFormat() error {
+	ext := strings.ToLower(path.Ext(e.Name))
+	if slices.Contains(unsupportedFormats, ext) {
+		return fmt.Errorf("patch %s: unsupported patch format %s", e.Name, ext)
+	}
+	return nil
+}
+
+// parsePatchList parses a plist in the usual "<index> <file> [checksum]"
+// format. Blank lines and lines starting with // or # are ignored.
+func parsePatchList(r io.Reader) ([]PatchEntry, error) {
+	var entries []PatchEntry
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "//") || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: expected \"<index> <file>\"", line)
+		}
+		index, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid index %q", line, fields[0])
+		}
+
+		entry := PatchEntry{Index: index, Name: fields[1]}
+		if len(fields) > 2 {
+			entry.Checksum = strings.ToLower(fields[2])
+			if _, err := newChecksumHash(entry.Checksum); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading patch list: %w", err)
+	}
+	return entries, nil
+}
+
+// pendingPatches returns the entries newer than lastIndex, in index order.
+func pendingPatches(entries []PatchEntry, lastIndex int) []PatchEntry {
+	var pending []PatchEntry
+	for _, e := range entries {
+		if e.Index > lastIndex {
+			pending = append(pending, e)
+		}
+	}
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].Index < pending[j].Index
+	})
+	return pending
+}
+
+// newChecksumHash picks the hash matching a hex checksum's length.
+func newChecksumHash(sum string) (hash.Hash, error) {
+	if _, err := hex.DecodeString(sum); err != nil {
+		return nil, fmt.Errorf("invalid checksum %q", sum)
+	}
+	switch len(sum) {
+	case md5.Size * 2:
+		return md5.New(), nil
+	case sha256.Size * 2:
+		return sha256.New(), nil
+	default:
+		return nil, fmt.Errorf("unsupported checksum length %d", len(sum))
+	}
+}
+
+// verifyChecksum checks a downloaded file against the entry's checksum.
+// Entries without a checksum always pass.
+func verifyChecksum(path, sum string) error {
+	if sum == "" {
+		return nil
+	}
+	h, err := newChecksumHash(sum)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(h, f); err != nil {
+		return fmt.Errorf("hashing %s: %w", path, err)
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != sum {
+		return fmt.Errorf("checksum mismatch for %s: got %s, want %s", path, got, sum)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParsePatchList(t *testing.T) {
+	input := `// Server patches
+1 2024-01-01data.gpf
+# comment
+2 2024-02-01data.gpf 5d41402abc4b2a76b9719d911017c592
+
+3 patcher.exe
+`
+	entries, err := parsePatchList(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parsePatchList error: %v", err)
+	}
+
+	want := []PatchEntry{
+		{Index: 1, Name: "2024-01-01data.gpf"},
+		{Index: 2, Name: "2024-02-01data.gpf", Checksum: "5d41402abc4b2a76b9719d911017c592"},
+		{Index: 3, Name: "patcher.exe"},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("entries = %+v, want %+v", entries, want)
+	}
+}
+
+func TestParsePatchListErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"missing file", "1\n"},
+		{"bad index", "one data.gpf\n"},
+		{"bad checksum", "1 data.gpf xyz\n"},
+		{"wrong checksum length", "1 data.gpf abcd\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parsePatchList(strings.NewReader(tt.input)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestPendingPatches(t *testing.T) {
+	entries := []PatchEntry{
+		{Index: 3, Name: "c.gpf"},
+		{Index: 1, Name: "a.gpf"},
+		{Index: 2, Name: "b.gpf"},
+	}
+
+	got := pendingPatches(entries, 1)
+	if len(got) != 2 || got[0].Name != "b.gpf" || got[1].Name != "c.gpf" {
+		t.Errorf("pendingPatches(1) = %+v, want b.gpf then c.gpf", got)
+	}
+	if got := pendingPatches(entries, 3); len(got) != 0 {
+		t.Errorf("pendingPatches(3) = %+v, want none", got)
+	}
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hello")
+	if err := os.WriteFile(path, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		sum     string
+		wantErr bool
+	}{
+		{"no checksum", "", false},
+		{"md5", "5d41402abc4b2a76b9719d911017c592", false},
+		{"sha256", "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", false},
+		{"mismatch", "00000000000000000000000000000000", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyChecksum(path, tt.sum)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("verifyChecksum error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckFormat(t *testing.T) {
+	for _, name := range []string{"2024-01-01data.gpf", "data.grf", "patcher.exe", "data/sprite.spr"} {
+		if err := (PatchEntry{Name: name}).checkFormat(); err != nil {
+			t.Errorf("checkFormat(%s) = %v, want nil", name, err)
+		}
+	}
+	for _, name := range []string{"2024-01-01data.thor", "update.RGZ"} {
+		err := (PatchEntry{Name: name}).checkFormat()
+		if err == nil || !strings.Contains(err.Error(), "unsupported patch format") {
+			t.Errorf("checkFormat(%s) = %v, want an unsupported format error", name, err)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Faultbox/midgard-ro/internal/assets"
+	"github.com/Faultbox/midgard-ro/pkg/formats"
+)
+
+// spriteFile is a loaded SPR/ACT pair.
+type spriteFile struct {
+	path string // As given, without extension
+	name string // Base name, for the title and output files
+	spr  *formats.SPR
+	act  *formats.ACT
+}
+
+// loadSprite loads the SPR/ACT pair at path, from the GRF at grfPath or,
+// if that's empty, from disk. path may name either file of the pair, or
+// both without extension.
+func loadSprite(grfPath, path string) (*spriteFile, error) {
+	base := path
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".spr", ".act":
+		base = strings.TrimSuffix(path, filepath.Ext(path))
+	}
+
+	read := os.ReadFile
+	if grfPath != "" {
+		m := assets.NewManager()
+		defer m.Close()
+		if err := m.AddArchive(grfPath); err != nil {
+			return nil, fmt.Errorf("opening GRF: %w", err)
+		}
+		read = m.Load
+	}
+
+	sprData, err := readPair(read, base, ".spr")
+	if err != nil {
+		return nil, err
+	}
+	actData, err := readPair(read, base, ".act")
+	if err != nil {
+		return nil, err
+	}
+	spr, err := formats.ParseSPR(sprData)
+	if err != nil {
+		return nil, fmt.Errorf("parsing SPR: %w", err)
+	}
+	act, err := formats.ParseACT(actData)
+	if err != nil {
+		return nil, fmt.Errorf("parsing ACT: %w", err)
+	}
+	if len(act.Actions) == 0 {
+		return nil, errors.New("the ACT has no actions")
+	}
+
+	name := filepath.Base(strings.ReplaceAll(base, "\\", "/"))
+	return &spriteFile{path: base, name: name, spr: spr, act: act}, nil
+}
+
+// readPair reads base with an extension, in lower or upper case as the
+// file was packed.
+func readPair(read func(string) ([]byte, error), base, ext string) ([]byte, error) {
+	var err error
+	for _, e := range []string{ext, strings.ToUpper(ext)} {
+		var data []byte
+		if data, err = read(base + e); err == nil {
+			return data, nil
+		}
+	}
+	return nil, fmt.Errorf("reading %s%s: %w", base, ext, err)
+}
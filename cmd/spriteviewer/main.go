@@ -0,0 +1,58 @@
+// Sprite Viewer - a standalone viewer for Ragnarok Online sprites: an
+// SPR/ACT pair from a GRF or from disk, played back in a window or
+// rendered to PNG sheets from the command line. Lighter than GRF Browser
+// for when a sprite is all there is to look at.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+)
+
+func main() {
+	runtime.LockOSThread()
+
+	grfPath := flag.String("grf", "", "GRF to read the sprite from; without it the sprite is read from disk")
+	renderAll := flag.String("render-all-actions", "", "Render a PNG sheet of every action to this directory, without opening a window")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, `Usage: spriteviewer [options] <sprite>
+
+The sprite is the path of its .spr or .act, or of both without extension;
+the other file of the pair is found next to it.
+
+Examples:
+  spriteviewer ./poring.spr
+  spriteviewer -grf data.grf data/sprite/몬스터/poring
+  spriteviewer -grf data.grf -render-all-actions out/ data/sprite/몬스터/poring
+
+Options:`)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	sf, err := loadSprite(*grfPath, flag.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *renderAll != "" {
+		paths, err := renderAllActions(sf, *renderAll)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		for _, p := range paths {
+			fmt.Println(p)
+		}
+		return
+	}
+
+	newViewer(sf).run()
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/sprite"
+	"github.com/Faultbox/midgard-ro/pkg/formats"
+)
+
+// actionFrames composites every frame of an action into images of one
+// size, each sprite origin at the same spot, so they play back in place.
+// Returns nil if no frame draws anything.
+func actionFrames(spr *formats.SPR, act *formats.ACT, action int) []*image.RGBA {
+	frames := act.Actions[action].Frames
+	results := make([]sprite.CompositeResult, len(frames))
+	var left, top, right, bottom int // Extent around the origin
+	for i := range frames {
+		r := sprite.CompositeFrame(spr, act, action, i)
+		results[i] = r
+		if r.Width == 0 {
+			continue
+		}
+		left = max(left, r.OriginX)
+		top = max(top, r.OriginY)
+		right = max(right, r.Width-r.OriginX)
+		bottom = max(bottom, r.Height-r.OriginY)
+	}
+	if left+right == 0 || top+bottom == 0 {
+		return nil
+	}
+
+	images := make([]*image.RGBA, len(results))
+	for i, r := range results {
+		img := image.NewRGBA(image.Rect(0, 0, left+right, top+bottom))
+		x0, y0 := left-r.OriginX, top-r.OriginY
+		for y := range r.Height {
+			copy(img.Pix[(y0+y)*img.Stride+x0*4:], r.Pixels[y*r.Width*4:(y+1)*r.Width*4])
+		}
+		images[i] = img
+	}
+	return images
+}
+
+// actionSheet lays an action's frames out side by side, or returns nil if
+// no frame draws anything.
+func actionSheet(spr *formats.SPR, act *formats.ACT, action int) *image.RGBA {
+	frames := actionFrames(spr, act, action)
+	if len(frames) == 0 {
+		return nil
+	}
+	w, h := frames[0].Rect.Dx(), frames[0].Rect.Dy()
+	sheet := image.NewRGBA(image.Rect(0, 0, w*len(frames), h))
+	for i, f := range frames {
+		for y := range h {
+			copy(sheet.Pix[y*sheet.Stride+i*w*4:], f.Pix[y*f.Stride:y*f.Stride+w*4])
+		}
+	}
+	return sheet
+}
+
+// sheetName returns the file name of an action's sheet: the sprite's name,
+// the action's index and what it's called, e.g. poring_016_Attack_S.png.
+func sheetName(name string, action, total int) string {
+	label := strings.ReplaceAll(formats.GetActionName(action, total), " ", "_")
+	return fmt.Sprintf("%s_%03d_%s.png", name, action, label)
+}
+
+// renderAllActions writes a sheet of every action that draws anything to
+// dir, and returns the paths written.
+func renderAllActions(sf *spriteFile, dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating output dir: %w", err)
+	}
+	var paths []string
+	for a := range sf.act.Actions {
+		sheet := actionSheet(sf.spr, sf.act, a)
+		if sheet == nil {
+			continue
+		}
+		path := filepath.Join(dir, sheetName(sf.name, a, len(sf.act.Actions)))
+		if err := writePNG(path, sheet); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// writePNG writes an image to a PNG file.
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", path, err)
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return fmt.Errorf("encoding %s: %w", path, err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/AllenDang/cimgui-go/backend"
+	"github.com/AllenDang/cimgui-go/backend/sdlbackend"
+	"github.com/AllenDang/cimgui-go/imgui"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/sprite"
+	"github.com/Faultbox/midgard-ro/pkg/formats"
+)
+
+// actionListWidth is the width of the action list on the left.
+const actionListWidth = 220
+
+// viewer plays a sprite's actions in a window.
+type viewer struct {
+	backend backend.Backend[sdlbackend.SDLWindowFlags]
+	sf      *spriteFile
+
+	action int
+	frames []*backend.Texture // Of the action, nil until built
+	built  bool               // frames are of action
+	width  float32            // Of a frame
+	height float32
+
+	frame   int
+	elapsed time.Duration // Into the current frame
+	playing bool
+	loop    bool
+	speed   float32
+	zoom    float32
+	last    time.Time // When the last frame was drawn
+}
+
+// newViewer opens a window for a sprite.
+func newViewer(sf *spriteFile) *viewer {
+	v := &viewer{sf: sf, playing: true, loop: true, speed: 1, zoom: 2}
+
+	var err error
+	v.backend, err = backend.CreateBackend(sdlbackend.NewSDLBackend())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: creating backend: %v\n", err)
+		os.Exit(1)
+	}
+	v.backend.SetBgColor(imgui.NewVec4(0.1, 0.1, 0.12, 1.0))
+	v.backend.CreateWindow("Sprite Viewer - "+sf.name, 960, 640)
+	return v
+}
+
+// run shows the window until it's closed.
+func (v *viewer) run() {
+	v.backend.Run(v.render)
+	v.releaseFrames()
+}
+
+// render draws a frame of the window.
+func (v *viewer) render() {
+	now := time.Now()
+	if !v.last.IsZero() && v.playing {
+		v.advance(time.Duration(float32(now.Sub(v.last)) * v.speed))
+	}
+	v.last = now
+	if !v.built {
+		v.buildFrames()
+	}
+
+	viewport := imgui.MainViewport()
+	imgui.SetNextWindowPos(viewport.Pos())
+	imgui.SetNextWindowSize(viewport.Size())
+	flags := imgui.WindowFlagsNoDecoration | imgui.WindowFlagsNoMove | imgui.WindowFlagsNoSavedSettings
+	if imgui.BeginV("##SpriteViewer", nil, flags) {
+		v.renderActionList()
+		imgui.SameLine()
+		if imgui.BeginChildStrV("Preview", imgui.NewVec2(0, 0), imgui.ChildFlagsBorders, imgui.WindowFlagsNone) {
+			v.renderControls()
+			imgui.Separator()
+			v.renderFrame()
+		}
+		imgui.EndChild()
+	}
+	imgui.End()
+}
+
+// renderActionList lists the sprite's actions, by index and name.
+func (v *viewer) renderActionList() {
+	if imgui.BeginChildStrV("Actions", imgui.NewVec2(actionListWidth, 0), imgui.ChildFlagsBorders, imgui.WindowFlagsNone) {
+		total := len(v.sf.act.Actions)
+		for a := range v.sf.act.Actions {
+			label := fmt.Sprintf("%03d %s (%d)", a, formats.GetActionName(a, total), len(v.sf.act.Actions[a].Frames))
+			if imgui.SelectableBoolV(label, a == v.action, imgui.SelectableFlagsNone, imgui.NewVec2(0, 0)) && a != v.action {
+				v.selectAction(a)
+			}
+		}
+	}
+	imgui.EndChild()
+}
+
+// renderControls draws the playback controls.
+func (v *viewer) renderControls() {
+	n := len(v.sf.act.Actions[v.action].Frames)
+
+	playLabel := "Pause"
+	if !v.playing {
+		playLabel = "Play"
+	}
+	if imgui.ButtonV(playLabel+"###Play", imgui.NewVec2(60, 0)) {
+		if !v.playing && !v.loop && v.frame == n-1 {
+			v.frame = 0 // Play a finished action again
+		}
+		v.playing = !v.playing
+	}
+	imgui.SameLine()
+	if imgui.Button("<") {
+		v.step(-1)
+	}
+	imgui.SameLine()
+	if imgui.Button(">") {
+		v.step(1)
+	}
+	imgui.SameLine()
+	imgui.Checkbox("Loop", &v.loop)
+	imgui.SameLine()
+	imgui.Text(fmt.Sprintf("Frame %d / %d", v.frame+1, n))
+
+	imgui.SetNextItemWidth(160)
+	imgui.SliderFloatV("Speed", &v.speed, 0.1, 4, "%.1fx", imgui.SliderFlagsNone)
+	imgui.SameLine()
+	imgui.SetNextItemWidth(160)
+	imgui.SliderFloatV("Zoom", &v.zoom, 1, 8, "%.0fx", imgui.SliderFlagsNone)
+
+	interval := sprite.FrameInterval(v.sf.act, v.action/8, v.action%8)
+	imgui.TextDisabled(fmt.Sprintf("%s  |  %d sprites  |  %v per frame", v.sf.path, len(v.sf.spr.Images), interval))
+}
+
+// renderFrame draws the current frame, centered in what's left.
+func (v *viewer) renderFrame() {
+	if v.frame >= len(v.frames) {
+		imgui.TextDisabled("This action draws nothing.")
+		return
+	}
+	w, h := v.width*v.zoom, v.height*v.zoom
+	avail := imgui.ContentRegionAvail()
+	start := imgui.CursorPos()
+	if w < avail.X {
+		imgui.SetCursorPosX(start.X + (avail.X-w)/2)
+	}
+	if h < avail.Y {
+		imgui.SetCursorPosY(start.Y + (avail.Y-h)/2)
+	}
+	imgui.ImageWithBgV(
+		v.frames[v.frame].ID,
+		imgui.NewVec2(w, h),
+		imgui.NewVec2(0, 0),
+		imgui.NewVec2(1, 1),
+		imgui.NewVec4(0.2, 0.2, 0.2, 1.0),
+		imgui.NewVec4(1, 1, 1, 1),
+	)
+}
+
+// advance moves playback on by d, stopping on the last frame unless it
+// loops.
+func (v *viewer) advance(d time.Duration) {
+	n := len(v.sf.act.Actions[v.action].Frames)
+	if n == 0 {
+		return
+	}
+	interval := sprite.FrameInterval(v.sf.act, v.action/8, v.action%8)
+	v.elapsed += d
+	for v.elapsed >= interval {
+		v.elapsed -= interval
+		if v.frame == n-1 && !v.loop {
+			v.playing = false
+			v.elapsed = 0
+			return
+		}
+		v.frame = (v.frame + 1) % n
+	}
+}
+
+// step pauses and moves by delta frames, wrapping around.
+func (v *viewer) step(delta int) {
+	n := len(v.sf.act.Actions[v.action].Frames)
+	if n == 0 {
+		return
+	}
+	v.playing = false
+	v.elapsed = 0
+	v.frame = ((v.frame+delta)%n + n) % n
+}
+
+// selectAction switches to an action, from its first frame.
+func (v *viewer) selectAction(action int) {
+	v.action = action
+	v.frame = 0
+	v.elapsed = 0
+	v.built = false
+}
+
+// buildFrames uploads the current action's frames as textures.
+func (v *viewer) buildFrames() {
+	v.releaseFrames()
+	v.built = true
+	images := actionFrames(v.sf.spr, v.sf.act, v.action)
+	if len(images) == 0 {
+		return
+	}
+	v.width = float32(images[0].Rect.Dx())
+	v.height = float32(images[0].Rect.Dy())
+	v.frames = make([]*backend.Texture, len(images))
+	for i, img := range images {
+		v.frames[i] = backend.NewTextureFromRgba(img)
+	}
+}
+
+// releaseFrames frees the frame textures.
+func (v *viewer) releaseFrames() {
+	for _, tex := range v.frames {
+		tex.Release()
+	}
+	v.frames = nil
+}
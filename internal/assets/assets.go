@@ -4,16 +4,30 @@ package assets
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/Faultbox/midgard-ro/pkg/encoding"
 	"github.com/Faultbox/midgard-ro/pkg/grf"
 )
 
-// Manager handles asset loading from GRF files.
+// source is an archive or loose directory assets are loaded from.
+type source interface {
+	Read(path string) ([]byte, error)
+	Close() error
+}
+
+// Manager handles asset loading from GRF files and loose data
+// directories.
 type Manager struct {
-	archives []*grf.Archive
+	archives []source // By priority, lowest first
+	dirs     []*grf.Dir
 	cache    *Cache
 	mu       sync.RWMutex
+
+	// Files the watcher found edited, until Changes takes them
+	changesMu sync.Mutex
+	changes   []string
+	stop      chan struct{}
 }
 
 // NewManager creates a new asset manager.
@@ -30,14 +44,99 @@ func (m *Manager) AddArchive(path string) error {
 	if err != nil {
 		return fmt.Errorf("opening archive %s: %w", path, err)
 	}
+	m.Mount(archive)
+	return nil
+}
 
+// Mount adds an already open archive, e.g. one built in memory, with the
+// highest priority so far.
+func (m *Manager) Mount(archive *grf.Archive) {
 	m.mu.Lock()
 	m.archives = append(m.archives, archive)
 	m.mu.Unlock()
+}
 
+// AddDir adds a loose data directory with the highest priority so far, so
+// its files override the archives'. Watch picks up edits to it.
+func (m *Manager) AddDir(path string) error {
+	dir, err := grf.OpenDir(path)
+	if err != nil {
+		return fmt.Errorf("opening data directory %s: %w", path, err)
+	}
+	m.mu.Lock()
+	m.archives = append(m.archives, dir)
+	m.dirs = append(m.dirs, dir)
+	m.mu.Unlock()
 	return nil
 }
 
+// Watch polls the loose data directories for edited files every interval
+// in the background (see grf.Dir.Poll), dropping them from the cache so
+// the next Load reads them again. Changes returns what was edited. Does
+// nothing without directories or when already watching.
+func (m *Manager) Watch(interval time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.dirs) == 0 || m.stop != nil {
+		return
+	}
+	m.stop = make(chan struct{})
+	go func(stop <-chan struct{}) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if changed := m.poll((*grf.Dir).Poll); len(changed) > 0 {
+					m.changesMu.Lock()
+					m.changes = append(m.changes, changed...)
+					m.changesMu.Unlock()
+				}
+			}
+		}
+	}(m.stop)
+}
+
+// Changes returns the normalized names (see grf.NormalizePath) of the
+// files the watcher found edited since the last call, for callers to
+// reload what they made from them.
+func (m *Manager) Changes() []string {
+	m.changesMu.Lock()
+	defer m.changesMu.Unlock()
+	changed := m.changes
+	m.changes = nil
+	return changed
+}
+
+// PollChanges rescans the loose data directories now and returns the
+// files edited since the last scan, dropped from the cache.
+func (m *Manager) PollChanges() []string {
+	return m.poll((*grf.Dir).Changes)
+}
+
+// poll checks each loose data directory with check and drops the files
+// it reports edited from the cache.
+func (m *Manager) poll(check func(*grf.Dir) ([]string, error)) []string {
+	m.mu.RLock()
+	dirs := m.dirs
+	m.mu.RUnlock()
+
+	var changed []string
+	for _, dir := range dirs {
+		names, err := check(dir)
+		if err != nil {
+			continue // Retried on the next poll
+		}
+		changed = append(changed, names...)
+	}
+	if len(changed) > 0 {
+		m.cache.Invalidate(changed)
+	}
+	return changed
+}
+
 // Load loads a file from the archives.
 //
 // Path encoding: GRFs store Korean folder/file names as raw EUC-KR bytes (the
@@ -82,10 +181,15 @@ func (m *Manager) Close() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if m.stop != nil {
+		close(m.stop)
+		m.stop = nil
+	}
 	for _, archive := range m.archives {
 		archive.Close()
 	}
 	m.archives = nil
+	m.dirs = nil
 	m.cache.Clear()
 }
 
@@ -127,6 +231,22 @@ func (c *Cache) Set(key string, data []byte) {
 	c.data[key] = data
 }
 
+// Invalidate drops the items of the named files, matched by their
+// normalized names (see grf.NormalizePath).
+func (c *Cache) Invalidate(names []string) {
+	drop := make(map[string]bool, len(names))
+	for _, name := range names {
+		drop[name] = true
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.data {
+		if drop[grf.NormalizePath(key)] {
+			delete(c.data, key)
+		}
+	}
+}
+
 // Clear clears the cache.
 func (c *Cache) Clear() {
 	c.mu.Lock()
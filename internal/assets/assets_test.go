@@ -0,0 +1,92 @@
+package assets
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Faultbox/midgard-ro/pkg/encoding"
+	"github.com/Faultbox/midgard-ro/pkg/grf"
+)
+
+// memArchive builds an in-memory archive of files.
+func memArchive(t *testing.T, files map[string]string) *grf.Archive {
+	t.Helper()
+	a := grf.NewInMemory()
+	for name, content := range files {
+		if err := a.AddFile(name, []byte(content)); err != nil {
+			t.Fatalf("AddFile(%s): %v", name, err)
+		}
+	}
+	return a
+}
+
+func TestLoad(t *testing.T) {
+	korean := string(encoding.UTF8ToEUCKR(`data\texture\유저인터페이스\basic.bmp`))
+
+	m := NewManager()
+	m.Mount(memArchive(t, map[string]string{"data/a.txt": "base", "data/b.txt": "only base"}))
+	m.Mount(memArchive(t, map[string]string{"data/a.txt": "patch", korean: "ui"}))
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"data/a.txt", "patch"}, // Later archives take priority
+		{"data\\B.txt", "only base"},
+		{`data\texture\유저인터페이스\basic.bmp`, "ui"}, // UTF-8 falls back to EUC-KR
+	}
+	for _, tt := range tests {
+		data, err := m.Load(tt.path)
+		if err != nil || string(data) != tt.want {
+			t.Errorf("Load(%q) = %q, %v; want %q", tt.path, data, err, tt.want)
+		}
+	}
+	if _, err := m.Load("data/missing.txt"); err == nil {
+		t.Error("Load(missing) succeeded")
+	}
+}
+
+func TestDirOverrideAndChanges(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "data", "a.txt")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("loose"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewManager()
+	defer m.Close()
+	m.Mount(memArchive(t, map[string]string{"data/a.txt": "archive", "data/b.txt": "archive"}))
+	if err := m.AddDir(root); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.AddDir(filepath.Join(root, "missing")); err == nil {
+		t.Error("AddDir(missing) succeeded")
+	}
+
+	if data, err := m.Load(`data\a.txt`); err != nil || string(data) != "loose" {
+		t.Errorf("Load(a) = %q, %v; want the directory's copy", data, err)
+	}
+	if data, err := m.Load("data/b.txt"); err != nil || string(data) != "archive" {
+		t.Errorf("Load(b) = %q, %v; want the archive's copy", data, err)
+	}
+
+	if err := os.WriteFile(path, []byte("edited"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if changed := m.PollChanges(); !reflect.DeepEqual(changed, []string{"data/a.txt"}) {
+		t.Errorf("PollChanges = %v, want [data/a.txt]", changed)
+	}
+	if data, err := m.Load(`data\a.txt`); err != nil || string(data) != "edited" {
+		t.Errorf("Load(a) after the edit = %q, %v; want edited", data, err)
+	}
+}
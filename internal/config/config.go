@@ -1,21 +1,39 @@
 // Package config handles game configuration loading and management.
 package config
 
-import "time"
+import (
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
 
 // Config holds all game settings.
 type Config struct {
-	Graphics GraphicsConfig `yaml:"graphics"`
-	Audio    AudioConfig    `yaml:"audio"`
-	Network  NetworkConfig  `yaml:"network"`
-	Game     GameConfig     `yaml:"game"`
-	Data     DataConfig     `yaml:"data"`
-	Logging  LoggingConfig  `yaml:"logging"`
+	Graphics      GraphicsConfig      `yaml:"graphics"`
+	Audio         AudioConfig         `yaml:"audio"`
+	Network       NetworkConfig       `yaml:"network"`
+	Game          GameConfig          `yaml:"game"`
+	Accessibility AccessibilityConfig `yaml:"accessibility"`
+	Nameplates    NameplatesConfig    `yaml:"nameplates"`
+	Data          DataConfig          `yaml:"data"`
+	Logging       LoggingConfig       `yaml:"logging"`
+
+	path      string     // File the config was loaded from, used by Persist
+	persisted *yaml.Node // Settings as loaded or last persisted
 }
 
 // DataConfig holds game data file paths.
 type DataConfig struct {
 	GRFPaths []string `yaml:"grf_paths"` // Paths to GRF archives
+
+	// DataDirs are loose data folders read over the GRF archives, holding
+	// e.g. data/texture/... They're watched, so files edited there show
+	// in the running client.
+	DataDirs []string `yaml:"data_dirs"`
+
+	// WarpTables are rAthena warp scripts, files or directories, that the
+	// area map (Alt+V) reads the exits between maps from
+	WarpTables []string `yaml:"warp_tables"`
 }
 
 // GraphicsConfig holds display and rendering settings.
@@ -24,7 +42,33 @@ type GraphicsConfig struct {
 	Height     int  `yaml:"height"`
 	Fullscreen bool `yaml:"fullscreen"`
 	VSync      bool `yaml:"vsync"`
-	FPSLimit   int  `yaml:"fps_limit"`
+	FPSLimit   int  `yaml:"fps_limit"` // Frame rate cap, 0 for none
+
+	// Slow down to a few frames a second while the window is unfocused or
+	// minimized
+	BackgroundThrottle bool `yaml:"background_throttle"`
+
+	UIScale float32 `yaml:"ui_scale"` // UI scale factor (0.75 - 2.0), independent of resolution
+
+	Auras bool `yaml:"auras"` // Draw level and job auras around characters
+
+	// Sampling of ground, model and water textures
+	TextureFilter string `yaml:"texture_filter"` // "trilinear", "bilinear" or "none"
+	Anisotropy    int    `yaml:"anisotropy"`     // 1 - 16; 1 turns it off
+
+	// TextureKeys are the transparent pixels of textures, by category
+	// ("model", "terrain", "water", "ui") or texture path: "none",
+	// "magenta", "palette0" or "magenta+palette0".
+	TextureKeys map[string]string `yaml:"texture_keys"`
+
+	// Post-processing of the 3D view, before the UI is drawn over it
+	Gamma        float32 `yaml:"gamma"`         // 0.5 - 2.5; above 1 lifts dark maps
+	Brightness   float32 `yaml:"brightness"`    // 0.5 - 2.0
+	FXAA         bool    `yaml:"fxaa"`          // Smooth jagged edges
+	ColorGrading bool    `yaml:"color_grading"` // Grade colors through ColorLUT
+	ColorLUT     string  `yaml:"color_lut"`     // PNG lookup table: a strip of N slices of NxN
+
+	GLDebug bool `yaml:"gl_debug"` // Log OpenGL errors by subsystem (always on in -tags gldebug builds)
 }
 
 // AudioConfig holds audio settings.
@@ -33,14 +77,34 @@ type AudioConfig struct {
 	MusicVolume  float32 `yaml:"music_volume"`
 	SFXVolume    float32 `yaml:"sfx_volume"`
 	Muted        bool    `yaml:"muted"`
+
+	// Rolloff overrides how sounds fade with distance, by category
+	// ("effect", "ambient", "ui")
+	Rolloff map[string]RolloffConfig `yaml:"rolloff"`
+}
+
+// RolloffConfig holds a sound category's distance fade, in cells.
+type RolloffConfig struct {
+	Curve       string  `yaml:"curve"`        // "none", "linear" or "inverse"
+	MinDistance float32 `yaml:"min_distance"` // Full volume up to here
+	MaxDistance float32 `yaml:"max_distance"` // Silent beyond here
 }
 
 // NetworkConfig holds server connection settings.
 type NetworkConfig struct {
-	LoginServer    string        `yaml:"login_server"`
+	LoginServer    string        `yaml:"login_server"` // host:port, IPv6 literals as [::1]:6900
 	ConnectTimeout time.Duration `yaml:"connect_timeout"`
 	Username       string        `yaml:"username"`
 	Password       string        `yaml:"password"`
+	Proxy          ProxyConfig   `yaml:"proxy"`
+}
+
+// ProxyConfig holds optional proxy settings applied to login/char/map connections.
+type ProxyConfig struct {
+	Type     string `yaml:"type"`    // "" (direct), "socks5" or "http"
+	Address  string `yaml:"address"` // Proxy host:port
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
 }
 
 // GameConfig holds gameplay settings.
@@ -48,23 +112,97 @@ type GameConfig struct {
 	Language string `yaml:"language"`
 	ShowFPS  bool   `yaml:"show_fps"`
 	ShowPing bool   `yaml:"show_ping"`
+
+	// StringOverrides is a file of client messages replacing the GRF's and
+	// the language's, by msgstringtable.txt index ("1#Server closed#")
+	StringOverrides string `yaml:"string_overrides"`
+
+	ScreenshotDir    string `yaml:"screenshot_dir"`     // Output directory for F12 captures
+	ScreenshotHideUI bool   `yaml:"screenshot_hide_ui"` // Capture the scene without the HUD
+
+	DevCommands  bool `yaml:"dev_commands"`  // Enable developer chat commands (/cell, /pip, /desync, /skill)
+	BuffWarnings bool `yaml:"buff_warnings"` // Warn in chat 10 seconds before a buff wears off
+
+	// InputBuffer is how many attack and skill presses made during the
+	// current action are queued to fire when it ends (1 - 2).
+	InputBuffer int `yaml:"input_buffer"`
+
+	// AwayAfter is how long without input marks the player away (AFK),
+	// 0 never. IdleFidgets is how often units standing idle play a fidget
+	// from their sprite's extra actions, 0 never.
+	AwayAfter   time.Duration `yaml:"away_after"`
+	IdleFidgets time.Duration `yaml:"idle_fidgets"`
+
+	InstantDialogText bool `yaml:"instant_dialog_text"` // Show NPC dialog pages at once instead of typing them out
+
+	// NotifyDuration is how long each notification toast shows, and
+	// MaxNotifications how many show at once. DoNotDisturb hides them
+	// from the start; /dnd toggles it in game.
+	NotifyDuration   time.Duration `yaml:"notify_duration"`
+	MaxNotifications int           `yaml:"max_notifications"`
+	DoNotDisturb     bool          `yaml:"do_not_disturb"`
+
+	// SpriteFallbacks is the chain tried, in order, when a player's body
+	// sprite is missing: "job" (the plain job body, for costumes and
+	// mounts), "base_job" (the jobs it grows from) and "novice".
+	SpriteFallbacks []string `yaml:"sprite_fallbacks"`
+
+	// The server's day/night cycle (rAthena's day_duration and
+	// night_duration), to predict nightfall between its announcements.
+	// Zero if the server has none.
+	DayDuration   time.Duration `yaml:"day_duration"`
+	NightDuration time.Duration `yaml:"night_duration"`
+}
+
+// AccessibilityConfig holds display options for players with color vision
+// deficiencies or sensitivity to flashing.
+type AccessibilityConfig struct {
+	Palette         string  `yaml:"palette"`           // "default", "deuteranopia" or "protanopia"
+	DamageTextScale float32 `yaml:"damage_text_scale"` // Damage number size (1.0 - 2.0)
+	ReduceFlashes   bool    `yaml:"reduce_flashes"`    // Skip full-screen flashes
+
+	CameraEffects     bool    `yaml:"camera_effects"`      // Shake and zoom the camera on hits and NPC dialogs
+	CameraEffectScale float32 `yaml:"camera_effect_scale"` // Strength of camera effects (0.25 - 2.0)
+}
+
+// NameplatesConfig holds when unit names show over the scene. Each unit
+// type shows its name "always", on "hover" or "never".
+type NameplatesConfig struct {
+	Self     string `yaml:"self" json:"self"`
+	Players  string `yaml:"players" json:"players"`
+	Party    string `yaml:"party" json:"party"` // Party members, with an HP bar if PartyHP is set
+	NPCs     string `yaml:"npcs" json:"npcs"`
+	Monsters string `yaml:"monsters" json:"monsters"`
+
+	Guild   bool `yaml:"guild" json:"guild"`       // Show guild name and position lines under player names
+	PartyHP bool `yaml:"party_hp" json:"party_hp"` // Show party members' HP bars
+
+	MaxDistance int `yaml:"max_distance" json:"max_distance"` // Hide names farther than this many cells (0 = no limit)
 }
 
 // LoggingConfig holds logging settings.
 type LoggingConfig struct {
-	Level   string `yaml:"level"`
-	LogFile string `yaml:"log_file"`
+	Level    string `yaml:"level"`
+	LogFile  string `yaml:"log_file"`
+	CrashDir string `yaml:"crash_dir"` // Where crash bundles are written
 }
 
 // Default returns a Config with sensible default values.
 func Default() *Config {
 	return &Config{
 		Graphics: GraphicsConfig{
-			Width:      1280,
-			Height:     720,
-			Fullscreen: false,
-			VSync:      true,
-			FPSLimit:   0,
+			Width:              1280,
+			Height:             720,
+			Fullscreen:         false,
+			VSync:              true,
+			FPSLimit:           0,
+			BackgroundThrottle: true,
+			UIScale:            1.0,
+			Auras:              true,
+			TextureFilter:      "trilinear",
+			Anisotropy:         8,
+			Gamma:              1.0,
+			Brightness:         1.0,
 		},
 		Audio: AudioConfig{
 			MasterVolume: 0.8,
@@ -80,13 +218,63 @@ func Default() *Config {
 			Language: "en",
 			ShowFPS:  false,
 			ShowPing: false,
+
+			ScreenshotDir: "data/Screenshots",
+			BuffWarnings:  true,
+			InputBuffer:   1,
+			AwayAfter:     10 * time.Minute,
+			IdleFidgets:   8 * time.Second,
+
+			NotifyDuration:   4 * time.Second,
+			MaxNotifications: 4,
+
+			SpriteFallbacks: []string{"job", "base_job", "novice"},
+		},
+		Accessibility: AccessibilityConfig{
+			Palette:         "default",
+			DamageTextScale: 1.0,
+
+			CameraEffects:     true,
+			CameraEffectScale: 1.0,
+		},
+		Nameplates: NameplatesConfig{
+			Self:        "always",
+			Players:     "always",
+			Party:       "always",
+			NPCs:        "always",
+			Monsters:    "hover",
+			Guild:       true,
+			PartyHP:     true,
+			MaxDistance: 15,
 		},
 		Data: DataConfig{
-			GRFPaths: []string{"data.grf"},
+			GRFPaths:   []string{"data.grf"},
+			WarpTables: []string{"docker/rathena/build/rathena/npc/warps"},
 		},
 		Logging: LoggingConfig{
-			Level:   "info",
-			LogFile: "",
+			Level:    "info",
+			LogFile:  "",
+			CrashDir: "crashes",
 		},
 	}
 }
+
+// redactedValue replaces secrets in Redacted copies.
+const redactedValue = "<redacted>"
+
+// Redacted returns a copy of the config with passwords masked,
+// safe to include in crash reports and logs.
+func (c *Config) Redacted() *Config {
+	out := *c
+	out.Data.GRFPaths = append([]string(nil), c.Data.GRFPaths...)
+	out.Data.WarpTables = append([]string(nil), c.Data.WarpTables...)
+	out.Data.DataDirs = append([]string(nil), c.Data.DataDirs...)
+	out.Game.SpriteFallbacks = append([]string(nil), c.Game.SpriteFallbacks...)
+	if out.Network.Password != "" {
+		out.Network.Password = redactedValue
+	}
+	if out.Network.Proxy.Password != "" {
+		out.Network.Proxy.Password = redactedValue
+	}
+	return &out
+}
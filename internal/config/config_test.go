@@ -23,6 +23,12 @@ func TestDefault(t *testing.T) {
 	if !cfg.Graphics.VSync {
 		t.Error("expected vsync to be true by default")
 	}
+	if !cfg.Graphics.BackgroundThrottle {
+		t.Error("expected background throttling by default")
+	}
+	if cfg.Graphics.TextureFilter != "trilinear" || cfg.Graphics.Anisotropy != 8 {
+		t.Errorf("expected trilinear filtering at 8x, got %s at %dx", cfg.Graphics.TextureFilter, cfg.Graphics.Anisotropy)
+	}
 
 	// Test audio defaults
 	if cfg.Audio.MasterVolume != 0.8 {
@@ -47,6 +53,36 @@ func TestDefault(t *testing.T) {
 	if cfg.Game.ShowFPS {
 		t.Error("expected show_fps to be false by default")
 	}
+	if cfg.Game.ScreenshotDir != "data/Screenshots" {
+		t.Errorf("expected screenshot dir 'data/Screenshots', got %s", cfg.Game.ScreenshotDir)
+	}
+
+	// Test accessibility defaults
+	if cfg.Accessibility.Palette != "default" {
+		t.Errorf("expected palette 'default', got %s", cfg.Accessibility.Palette)
+	}
+	if cfg.Accessibility.DamageTextScale != 1.0 {
+		t.Errorf("expected damage text scale 1.0, got %f", cfg.Accessibility.DamageTextScale)
+	}
+	if cfg.Accessibility.ReduceFlashes {
+		t.Error("expected reduce_flashes to be false by default")
+	}
+	if !cfg.Accessibility.CameraEffects || cfg.Accessibility.CameraEffectScale != 1.0 {
+		t.Errorf("expected camera effects on at 1.0, got %v at %f",
+			cfg.Accessibility.CameraEffects, cfg.Accessibility.CameraEffectScale)
+	}
+
+	// Test nameplate defaults
+	if cfg.Nameplates.Players != "always" || cfg.Nameplates.Monsters != "hover" {
+		t.Errorf("expected players 'always' and monsters 'hover', got %s and %s",
+			cfg.Nameplates.Players, cfg.Nameplates.Monsters)
+	}
+	if !cfg.Nameplates.Guild || !cfg.Nameplates.PartyHP {
+		t.Error("expected guild lines and party HP to be shown by default")
+	}
+	if cfg.Nameplates.MaxDistance != 15 {
+		t.Errorf("expected nameplate max distance 15, got %d", cfg.Nameplates.MaxDistance)
+	}
 
 	// Test logging defaults
 	if cfg.Logging.Level != "info" {
@@ -55,6 +91,9 @@ func TestDefault(t *testing.T) {
 	if cfg.Logging.LogFile != "" {
 		t.Errorf("expected empty log file, got %s", cfg.Logging.LogFile)
 	}
+	if cfg.Logging.CrashDir != "crashes" {
+		t.Errorf("expected crash dir 'crashes', got %s", cfg.Logging.CrashDir)
+	}
 }
 
 func TestLoadFromFile(t *testing.T) {
@@ -69,6 +108,8 @@ graphics:
   fullscreen: true
   vsync: false
   fps_limit: 144
+  ui_scale: 1.5
+  gl_debug: true
 
 audio:
   master_volume: 0.5
@@ -79,6 +120,9 @@ audio:
 network:
   login_server: "game.server.com:6900"
   connect_timeout: 5s
+  proxy:
+    type: "socks5"
+    address: "127.0.0.1:1080"
 
 game:
   language: "ja"
@@ -116,6 +160,12 @@ logging:
 	if cfg.Graphics.FPSLimit != 144 {
 		t.Errorf("expected fps limit 144, got %d", cfg.Graphics.FPSLimit)
 	}
+	if cfg.Graphics.UIScale != 1.5 {
+		t.Errorf("expected ui scale 1.5, got %f", cfg.Graphics.UIScale)
+	}
+	if !cfg.Graphics.GLDebug {
+		t.Error("expected gl debug to be true")
+	}
 
 	if cfg.Audio.MasterVolume != 0.5 {
 		t.Errorf("expected master volume 0.5, got %f", cfg.Audio.MasterVolume)
@@ -127,6 +177,9 @@ logging:
 	if cfg.Network.LoginServer != "game.server.com:6900" {
 		t.Errorf("expected server game.server.com:6900, got %s", cfg.Network.LoginServer)
 	}
+	if cfg.Network.Proxy.Type != "socks5" || cfg.Network.Proxy.Address != "127.0.0.1:1080" {
+		t.Errorf("expected socks5 proxy at 127.0.0.1:1080, got %+v", cfg.Network.Proxy)
+	}
 
 	if cfg.Game.Language != "ja" {
 		t.Errorf("expected language 'ja', got %s", cfg.Game.Language)
@@ -143,6 +196,99 @@ logging:
 	}
 }
 
+func TestPersist(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte("graphics:\n  width: 800\n"), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg := Default()
+	if err := loadFromFile(cfg, configPath); err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	cfg.path = configPath
+
+	// Runtime change is written back to the file it came from
+	cfg.Graphics.UIScale = 1.25
+	if err := cfg.Persist(); err != nil {
+		t.Fatalf("Persist failed: %v", err)
+	}
+
+	reloaded := Default()
+	if err := loadFromFile(reloaded, configPath); err != nil {
+		t.Fatalf("failed to reload config: %v", err)
+	}
+	if reloaded.Graphics.UIScale != 1.25 || reloaded.Graphics.Width != 800 {
+		t.Errorf("unexpected reloaded graphics config: %+v", reloaded.Graphics)
+	}
+}
+
+func TestPersistOnlyChanges(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	content := "# Player settings\ngraphics:\n  width: 800 # small screen\n  ui_scale: 1.0\nnetwork:\n  username: player\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	*flagConfig = configPath
+	*flagWidth = 1920
+	defer func() {
+		*flagConfig = ""
+		*flagWidth = 0
+	}()
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	// A password typed in and the width from the flag stay out of the
+	// file; the settings changed at runtime go in
+	cfg.Network.Password = "secret"
+	cfg.Graphics.UIScale = 1.5
+	cfg.Game.Language = "de"
+	if err := cfg.Persist(); err != nil {
+		t.Fatalf("Persist failed: %v", err)
+	}
+	cfg.Audio.Muted = true
+	if err := cfg.Persist(); err != nil {
+		t.Fatalf("second Persist failed: %v", err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Player settings\ngraphics:\n  width: 800 # small screen\n  ui_scale: 1.5\nnetwork:\n  username: player\ngame:\n  language: de\naudio:\n  muted: true\n"
+	if string(data) != want {
+		t.Errorf("persisted file:\n%s\nwant:\n%s", data, want)
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := Default()
+	cfg.Network.Username = "player"
+	cfg.Network.Password = "hunter2"
+	cfg.Network.Proxy.Password = "proxypass"
+
+	red := cfg.Redacted()
+	if red.Network.Password == "hunter2" || red.Network.Proxy.Password == "proxypass" {
+		t.Errorf("passwords not redacted: %+v", red.Network)
+	}
+	if red.Network.Username != "player" {
+		t.Errorf("expected username to be kept, got %q", red.Network.Username)
+	}
+
+	// Original must be untouched
+	if cfg.Network.Password != "hunter2" || cfg.Network.Proxy.Password != "proxypass" {
+		t.Error("Redacted modified the original config")
+	}
+
+	// Empty passwords stay empty so reports show they were not set
+	if Default().Redacted().Network.Password != "" {
+		t.Error("expected empty password to stay empty")
+	}
+}
+
 func TestLoadFromFileInvalid(t *testing.T) {
 	// Create temporary config file with invalid YAML
 	tmpDir := t.TempDir()
@@ -10,8 +10,28 @@ var (
 	flagFullscreen = flag.Bool("fullscreen", false, "Run in fullscreen mode")
 	flagWidth      = flag.Int("width", 0, "Window width")
 	flagHeight     = flag.Int("height", 0, "Window height")
+
+	flagBench       = flag.String("bench", "", "Benchmark rendering a map (e.g. prontera) and exit")
+	flagBenchFrames = flag.Int("frames", 2000, "Frames to measure with --bench")
+	flagBenchOut    = flag.String("bench-out", "", "Write the --bench report to this file instead of stdout")
+
+	flagRecord = flag.String("record", "", "Record input and server traffic to a replay file")
+	flagReplay = flag.String("replay", "", "Play back a replay file recorded with --record")
 )
 
+// BenchOptions are the benchmark mode flags.
+type BenchOptions struct {
+	Map    string // Map to render, "" when not benchmarking
+	Frames int
+	Output string // Report file, "" for stdout
+}
+
+// ReplayOptions are the session recording and replay flags.
+type ReplayOptions struct {
+	Record string // Replay file to record into, "" when not recording
+	Play   string // Replay file to play back, "" when not replaying
+}
+
 // ParseFlags parses command-line flags. Call this early in main().
 func ParseFlags() {
 	flag.Parse()
@@ -22,6 +42,16 @@ func ConfigPath() string {
 	return *flagConfig
 }
 
+// Bench returns the benchmark mode flags.
+func Bench() BenchOptions {
+	return BenchOptions{Map: *flagBench, Frames: *flagBenchFrames, Output: *flagBenchOut}
+}
+
+// Replay returns the session recording and replay flags.
+func Replay() ReplayOptions {
+	return ReplayOptions{Record: *flagRecord, Play: *flagReplay}
+}
+
 // applyFlags applies CLI flag overrides to the config.
 func applyFlags(cfg *Config) {
 	if *flagDebug {
@@ -24,11 +24,19 @@ func Load() (*Config, error) {
 		if err := loadFromFile(cfg, configPath); err != nil {
 			return nil, fmt.Errorf("loading config from %s: %w", configPath, err)
 		}
+		cfg.path = configPath
 	}
 
 	// Apply CLI flags (highest priority)
 	applyFlags(cfg)
 
+	// Persist writes what changes from here on
+	persisted, err := settingsNode(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("encoding config: %w", err)
+	}
+	cfg.persisted = persisted
+
 	return cfg, nil
 }
 
@@ -1,8 +1,12 @@
 package config
 
 import (
+	"bytes"
+	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 
 	"gopkg.in/yaml.v3"
 )
@@ -40,3 +44,178 @@ func (c *Config) SaveTo(path string) error {
 
 	return os.WriteFile(path, data, 0644)
 }
+
+// secretPaths are the settings Persist never writes.
+var secretPaths = [][]string{
+	{"network", "password"},
+	{"network", "proxy", "password"},
+}
+
+// settingChange is a setting changed at runtime: its path of YAML keys
+// and its new value.
+type settingChange struct {
+	path  []string
+	value *yaml.Node
+}
+
+// Persist writes the settings changed at runtime since the config was
+// loaded or last persisted back to the file it was loaded from, or to the
+// user's config directory if it was not loaded from a file. Only those
+// settings are written, into the file as it is on disk: CLI flag
+// overrides and passwords stay out of it, and its other lines and
+// comments are kept.
+func (c *Config) Persist() error {
+	path := c.path
+	if path == "" {
+		path = filepath.Join(ConfigDir(), "config.yaml")
+	}
+
+	base := c.persisted
+	if base == nil {
+		// Not loaded through Load: what the file alone gives is the base
+		loaded := Default()
+		if err := loadFromFile(loaded, path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		var err error
+		if base, err = settingsNode(loaded); err != nil {
+			return err
+		}
+	}
+	current, err := settingsNode(c)
+	if err != nil {
+		return err
+	}
+
+	var changes []settingChange
+	for _, ch := range diffSettings(nil, base, current, nil) {
+		if !slices.ContainsFunc(secretPaths, func(p []string) bool { return slices.Equal(p, ch.path) }) {
+			changes = append(changes, ch)
+		}
+	}
+	if len(changes) > 0 {
+		if err := updateFile(path, changes); err != nil {
+			return err
+		}
+	}
+	c.persisted = current
+	return nil
+}
+
+// settingsNode returns the YAML tree of the config's settings.
+func settingsNode(c *Config) (*yaml.Node, error) {
+	var n yaml.Node
+	if err := n.Encode(c); err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// diffSettings appends to changes the settings under path that differ
+// between old and cur. Mappings of the same keys, the config's sections,
+// are compared key by key; anything else is compared whole.
+func diffSettings(path []string, old, cur *yaml.Node, changes []settingChange) []settingChange {
+	if old.Kind == yaml.MappingNode && cur.Kind == yaml.MappingNode && sameKeys(old, cur) {
+		for i := 0; i+1 < len(cur.Content); i += 2 {
+			key := append(slices.Clip(path), cur.Content[i].Value)
+			changes = diffSettings(key, old.Content[i+1], cur.Content[i+1], changes)
+		}
+		return changes
+	}
+	if !sameNode(old, cur) {
+		changes = append(changes, settingChange{path: path, value: cur})
+	}
+	return changes
+}
+
+// sameKeys reports whether two mappings have the same keys in order.
+func sameKeys(a, b *yaml.Node) bool {
+	if len(a.Content) != len(b.Content) {
+		return false
+	}
+	for i := 0; i < len(a.Content); i += 2 {
+		if a.Content[i].Value != b.Content[i].Value {
+			return false
+		}
+	}
+	return true
+}
+
+// sameNode reports whether two YAML trees hold the same values.
+func sameNode(a, b *yaml.Node) bool {
+	if a.Kind != b.Kind || a.Tag != b.Tag || a.Value != b.Value || len(a.Content) != len(b.Content) {
+		return false
+	}
+	for i := range a.Content {
+		if !sameNode(a.Content[i], b.Content[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// updateFile sets changed settings in the YAML file at path, creating it
+// if it doesn't exist.
+func updateFile(path string, changes []settingChange) error {
+	var doc yaml.Node
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return err
+	default:
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return err
+		}
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	for _, ch := range changes {
+		setSetting(doc.Content[0], ch.path, ch.value)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// setSetting sets the value at path under a mapping, adding the keys and
+// sections missing.
+func setSetting(m *yaml.Node, path []string, value *yaml.Node) {
+	for i, key := range path {
+		var next *yaml.Node
+		for j := 0; j+1 < len(m.Content); j += 2 {
+			if m.Content[j].Value == key {
+				next = m.Content[j+1]
+				break
+			}
+		}
+		last := i == len(path)-1
+		if next == nil {
+			next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, next)
+		}
+		if last {
+			// The comments on the line stay with the setting
+			head, line := next.HeadComment, next.LineComment
+			*next = *value
+			next.HeadComment, next.LineComment = head, line
+			return
+		}
+		if next.Kind != yaml.MappingNode {
+			*next = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		m = next
+	}
+}
@@ -0,0 +1,132 @@
+// Package crash writes crash bundles with the context needed to debug
+// client panics and fatal errors: stack trace, redacted config, recent
+// log lines, recent packet opcodes and GPU information.
+package crash
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Faultbox/midgard-ro/internal/config"
+	"github.com/Faultbox/midgard-ro/internal/logger"
+	"github.com/Faultbox/midgard-ro/internal/network"
+)
+
+// Bundle file names.
+const (
+	ReportFile  = "crash.txt"
+	ConfigFile  = "config.yaml"
+	LogFile     = "log.txt"
+	PacketsFile = "packets.txt"
+)
+
+// PanicError wraps a recovered panic so it can be returned as an error
+// from the main loop and reported with its original stack.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Report is the content of a crash bundle.
+type Report struct {
+	Time     time.Time
+	Reason   string // Panic value or fatal error message
+	Stack    []byte
+	Config   *config.Config // Redacted before writing
+	GPUInfo  string
+	LogLines []string
+	Packets  []network.PacketRecord
+}
+
+// NewReport builds a report for reason, collecting recent log lines and
+// packets from the logger and network history.
+func NewReport(reason string, stack []byte, cfg *config.Config, gpuInfo string) *Report {
+	return &Report{
+		Time:     time.Now(),
+		Reason:   reason,
+		Stack:    stack,
+		Config:   cfg,
+		GPUInfo:  gpuInfo,
+		LogLines: logger.RecentLines(),
+		Packets:  network.RecentPackets(),
+	}
+}
+
+// BundleName returns the folder name for a crash at t.
+func BundleName(t time.Time) string {
+	return "crash-" + t.Format("20060102-150405")
+}
+
+// Write creates a timestamped bundle folder under dir and returns its path.
+func (r *Report) Write(dir string) (string, error) {
+	if dir == "" {
+		dir = "."
+	}
+	path := filepath.Join(dir, BundleName(r.Time))
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return "", fmt.Errorf("creating crash folder: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(path, ReportFile), []byte(r.summary()), 0644); err != nil {
+		return path, fmt.Errorf("writing %s: %w", ReportFile, err)
+	}
+
+	if r.Config != nil {
+		data, err := yaml.Marshal(r.Config.Redacted())
+		if err != nil {
+			return path, fmt.Errorf("encoding config: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(path, ConfigFile), data, 0644); err != nil {
+			return path, fmt.Errorf("writing %s: %w", ConfigFile, err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(path, LogFile), []byte(joinLines(r.LogLines)), 0644); err != nil {
+		return path, fmt.Errorf("writing %s: %w", LogFile, err)
+	}
+
+	packets := make([]string, len(r.Packets))
+	for i, p := range r.Packets {
+		packets[i] = p.String()
+	}
+	if err := os.WriteFile(filepath.Join(path, PacketsFile), []byte(joinLines(packets)), 0644); err != nil {
+		return path, fmt.Errorf("writing %s: %w", PacketsFile, err)
+	}
+
+	return path, nil
+}
+
+// summary formats the main crash.txt report.
+func (r *Report) summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Midgard RO crash report\n")
+	fmt.Fprintf(&b, "Time:    %s\n", r.Time.Format(time.RFC3339))
+	fmt.Fprintf(&b, "Go:      %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	gpu := r.GPUInfo
+	if gpu == "" {
+		gpu = "unavailable"
+	}
+	fmt.Fprintf(&b, "GPU:     %s\n", gpu)
+	fmt.Fprintf(&b, "Reason:  %s\n", r.Reason)
+	if len(r.Stack) > 0 {
+		fmt.Fprintf(&b, "\nStack trace:\n%s\n", r.Stack)
+	}
+	return b.String()
+}
+
+func joinLines(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
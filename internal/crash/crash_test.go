@@ -0,0 +1,76 @@
+package crash
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Faultbox/midgard-ro/internal/config"
+	"github.com/Faultbox/midgard-ro/internal/network"
+)
+
+func TestBundleName(t *testing.T) {
+	ts := time.Date(2026, 3, 7, 9, 5, 2, 0, time.UTC)
+	if got := BundleName(ts); got != "crash-20260307-090502" {
+		t.Errorf("BundleName() = %q", got)
+	}
+}
+
+func TestReportWrite(t *testing.T) {
+	cfg := config.Default()
+	cfg.Network.Password = "hunter2"
+
+	r := &Report{
+		Time:     time.Date(2026, 3, 7, 9, 5, 2, 0, time.UTC),
+		Reason:   "panic: index out of range",
+		Stack:    []byte("goroutine 1 [running]:\nmain.main()"),
+		Config:   cfg,
+		GPUInfo:  "Test Vendor / Test Renderer / 4.1",
+		LogLines: []string{"line one", "line two"},
+		Packets: []network.PacketRecord{
+			{Time: time.Now(), Dir: network.DirSend, ID: 0x0064, Len: 55},
+		},
+	}
+
+	dir, err := r.Write(t.TempDir())
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if filepath.Base(dir) != "crash-20260307-090502" {
+		t.Errorf("unexpected bundle folder %q", dir)
+	}
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("reading %s: %v", name, err)
+		}
+		return string(data)
+	}
+
+	report := read(ReportFile)
+	for _, want := range []string{"index out of range", "Test Renderer", "main.main()"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("%s missing %q", ReportFile, want)
+		}
+	}
+
+	if cfgText := read(ConfigFile); strings.Contains(cfgText, "hunter2") {
+		t.Error("config in crash bundle contains the password")
+	}
+	if logs := read(LogFile); logs != "line one\nline two\n" {
+		t.Errorf("unexpected log file %q", logs)
+	}
+	if packets := read(PacketsFile); !strings.Contains(packets, "send 0x0064 len=55") {
+		t.Errorf("unexpected packets file %q", packets)
+	}
+}
+
+func TestPanicError(t *testing.T) {
+	err := &PanicError{Value: "boom"}
+	if err.Error() != "panic: boom" {
+		t.Errorf("Error() = %q", err.Error())
+	}
+}
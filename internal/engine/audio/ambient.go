@@ -0,0 +1,237 @@
+package audio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gopxl/beep/v2"
+	"github.com/gopxl/beep/v2/wav"
+)
+
+// maxAmbientVoices is how many emitters are heard at once. The loudest
+// win; a map's dozens of emitters are mostly out of range anyway.
+const maxAmbientVoices = 8
+
+// Emitter is a looping sound placed on a map, like a waterfall, a crowd or
+// birds, from the map's RSW sound objects.
+type Emitter struct {
+	File    string // Sound asset; emitters playing the same one share its samples
+	X, Y, Z float64
+	Volume  float64 // 0-1
+	Range   float64 // Silent beyond, in world units; 0 uses the ambient rolloff's
+	Cycle   float64 // Seconds from one start to the next; shorter loops back to back
+}
+
+// clip is a sound decoded once at the output sample rate.
+type clip [][2]float32
+
+// ambientVoice is an emitter being heard, playing its clip once per
+// period.
+type ambientVoice struct {
+	emitter  int
+	clip     clip
+	period   int // Samples from one start to the next
+	pos      int
+	gain     float64 // Volume applied, with the listener's distance
+	distance float64
+	started  time.Time
+}
+
+// ambience mixes the emitters nearest the listener. Emitters share
+// decoded clips, and only maxAmbientVoices of them play, so a map full of
+// them costs no more than a few sound effects.
+type ambience struct {
+	mu         sync.Mutex
+	sampleRate float64
+	emitters   []Emitter
+	clips      []clip // By emitter, nil when its sound couldn't be decoded
+	voices     []ambientVoice
+}
+
+// set replaces the emitters, decoding each sound once with load. Emitters
+// whose sound fails to load stay silent; the failures are returned.
+func (a *ambience) set(emitters []Emitter, load func(file string) ([]byte, error)) error {
+	decoded := make(map[string]clip)
+	clips := make([]clip, len(emitters))
+	var errs []error
+	for i, e := range emitters {
+		c, ok := decoded[e.File]
+		if !ok {
+			var err error
+			if c, err = a.decode(e.File, load); err != nil {
+				errs = append(errs, err)
+			}
+			decoded[e.File] = c
+		}
+		clips[i] = c
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.emitters, a.clips, a.voices = emitters, clips, nil
+	return errors.Join(errs...)
+}
+
+func (a *ambience) decode(file string, load func(string) ([]byte, error)) (clip, error) {
+	data, err := load(file)
+	if err != nil {
+		return nil, fmt.Errorf("loading %s: %w", file, err)
+	}
+	streamer, format, err := wav.Decode(io.NopCloser(bytes.NewReader(data)))
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", file, err)
+	}
+	defer streamer.Close()
+	var src beep.Streamer = streamer
+	if rate := beep.SampleRate(a.sampleRate); format.SampleRate != rate {
+		src = beep.Resample(4, format.SampleRate, rate, streamer)
+	}
+
+	var c clip
+	buf := make([][2]float64, 4096)
+	for {
+		n, ok := src.Stream(buf)
+		for _, s := range buf[:n] {
+			c = append(c, [2]float32{float32(s[0]), float32(s[1])})
+		}
+		if !ok {
+			break
+		}
+	}
+	if len(c) == 0 {
+		return nil, fmt.Errorf("decoding %s: no samples", file)
+	}
+	return c, nil
+}
+
+// update picks the emitters heard from the listener and sets their
+// volumes. Emitters still heard keep playing where they were.
+func (a *ambience) update(listener [3]float64, rolloff Rolloff, volume float64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	type heard struct {
+		emitter        int
+		gain, distance float64
+	}
+	var candidates []heard
+	for i, e := range a.emitters {
+		if a.clips[i] == nil {
+			continue
+		}
+		d := distanceBetween(listener, [3]float64{e.X, e.Y, e.Z})
+		if g := emitterGain(e, rolloff, d); g > 0 {
+			candidates = append(candidates, heard{i, g, d})
+		}
+	}
+	slices.SortStableFunc(candidates, func(x, y heard) int {
+		switch {
+		case x.gain > y.gain:
+			return -1
+		case x.gain < y.gain:
+			return 1
+		}
+		return 0
+	})
+	candidates = candidates[:min(len(candidates), maxAmbientVoices)]
+
+	voices := make([]ambientVoice, 0, len(candidates))
+	for _, c := range candidates {
+		i := slices.IndexFunc(a.voices, func(v ambientVoice) bool { return v.emitter == c.emitter })
+		var v ambientVoice
+		if i >= 0 {
+			v = a.voices[i]
+		} else {
+			clip := a.clips[c.emitter]
+			v = ambientVoice{
+				emitter: c.emitter,
+				clip:    clip,
+				period:  max(len(clip), int(a.emitters[c.emitter].Cycle*a.sampleRate)),
+				started: time.Now(),
+			}
+		}
+		v.gain, v.distance = c.gain*volume, c.distance
+		voices = append(voices, v)
+	}
+	a.voices = voices
+}
+
+// emitterGain returns the volume of an emitter heard from distance d: the
+// ambient rolloff, stretched or cut to the emitter's own range.
+func emitterGain(e Emitter, rolloff Rolloff, d float64) float64 {
+	if e.Range > 0 {
+		rolloff.MaxDistance = e.Range
+		rolloff.MinDistance = min(rolloff.MinDistance, e.Range)
+	}
+	if d >= rolloff.MaxDistance {
+		return 0
+	}
+	return rolloff.Gain(d) * e.Volume
+}
+
+// Stream implements beep.Streamer. The ambience never ends; it's silence
+// while no emitter is heard.
+func (a *ambience) Stream(samples [][2]float64) (int, bool) {
+	clear(samples)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for vi := range a.voices {
+		v := &a.voices[vi]
+		for i := range samples {
+			if v.pos < len(v.clip) {
+				s := v.clip[v.pos]
+				samples[i][0] += float64(s[0]) * v.gain
+				samples[i][1] += float64(s[1]) * v.gain
+			}
+			v.pos = (v.pos + 1) % v.period
+		}
+	}
+	return len(samples), true
+}
+
+// Err implements beep.Streamer.
+func (a *ambience) Err() error {
+	return nil
+}
+
+// heard returns the emitters playing as voices, for the debug overlay.
+func (a *ambience) heard() []Voice {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	voices := make([]Voice, 0, len(a.voices))
+	for _, v := range a.voices {
+		voices = append(voices, Voice{
+			Name:     a.emitters[v.emitter].File,
+			Category: CategoryAmbient,
+			Distance: v.distance,
+			Gain:     v.gain,
+			Started:  v.started,
+		})
+	}
+	return voices
+}
+
+// SetAmbient replaces the map's ambient sounds, loading each sound once
+// with load; nil emitters silence them. Emitters whose sound can't be
+// loaded stay silent, and the failures are returned.
+func (m *Manager) SetAmbient(emitters []Emitter, load func(file string) ([]byte, error)) error {
+	err := m.ambience.set(emitters, load)
+	m.updateAmbient()
+	return err
+}
+
+// updateAmbient re-picks the ambient sounds heard after the listener or a
+// volume changed.
+func (m *Manager) updateAmbient() {
+	m.mu.RLock()
+	listener := m.listener
+	rolloff := m.rolloffs[CategoryAmbient]
+	volume := m.masterVolume * m.sfxVolLevel
+	m.mu.RUnlock()
+	m.ambience.update(listener, rolloff, volume)
+}
@@ -0,0 +1,105 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+// pcmWAV returns a mono 16-bit WAV of n samples at full scale.
+func pcmWAV(rate, n int) []byte {
+	var b bytes.Buffer
+	le := func(v any) { binary.Write(&b, binary.LittleEndian, v) }
+	b.WriteString("RIFF")
+	le(uint32(36 + 2*n))
+	b.WriteString("WAVEfmt ")
+	le(uint32(16))
+	le(uint16(1)) // PCM
+	le(uint16(1)) // Mono
+	le(uint32(rate))
+	le(uint32(rate * 2))
+	le(uint16(2))
+	le(uint16(16))
+	b.WriteString("data")
+	le(uint32(2 * n))
+	for range n {
+		le(int16(math.MaxInt16))
+	}
+	return b.Bytes()
+}
+
+func TestEmitterGain(t *testing.T) {
+	linear := Rolloff{Curve: RolloffLinear, MinDistance: 20, MaxDistance: 100}
+	tests := []struct {
+		name    string
+		e       Emitter
+		rolloff Rolloff
+		d       float64
+		want    float64
+	}{
+		{"close", Emitter{Volume: 0.5, Range: 60}, linear, 10, 0.5},
+		{"own range", Emitter{Volume: 1, Range: 60}, linear, 40, 0.5},
+		{"past own range", Emitter{Volume: 1, Range: 60}, linear, 70, 0},
+		{"rolloff range", Emitter{Volume: 1}, linear, 60, 0.5},
+		{"range under min", Emitter{Volume: 1, Range: 10}, linear, 5, 1},
+		{"no rolloff still cut off", Emitter{Volume: 1, Range: 60}, Rolloff{}, 70, 0},
+	}
+	for _, tt := range tests {
+		if got := emitterGain(tt.e, tt.rolloff, tt.d); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: emitterGain() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAmbience(t *testing.T) {
+	const rate = 1000
+	loads := map[string]int{}
+	load := func(file string) ([]byte, error) {
+		loads[file]++
+		return pcmWAV(rate, 10), nil
+	}
+
+	a := &ambience{sampleRate: rate}
+	var emitters []Emitter
+	for i := range maxAmbientVoices + 2 {
+		emitters = append(emitters, Emitter{File: "river.wav", X: float64(i * 10), Volume: 1, Range: 200, Cycle: 0.02})
+	}
+	emitters = append(emitters, Emitter{File: "birds.wav", X: 500, Volume: 1, Range: 100})
+	if err := a.set(emitters, load); err != nil {
+		t.Fatal(err)
+	}
+	if loads["river.wav"] != 1 || loads["birds.wav"] != 1 {
+		t.Errorf("loads = %v, want each sound decoded once", loads)
+	}
+
+	rolloff := Rolloff{Curve: RolloffLinear, MaxDistance: 100}
+	a.update([3]float64{}, rolloff, 1)
+	heard := a.heard()
+	if len(heard) != maxAmbientVoices {
+		t.Fatalf("heard %d emitters, want %d", len(heard), maxAmbientVoices)
+	}
+	if heard[0].Distance != 0 || heard[len(heard)-1].Distance != 70 {
+		t.Errorf("heard from %v to %v, want the nearest first", heard[0].Distance, heard[len(heard)-1].Distance)
+	}
+
+	// The clip plays, then waits out the rest of its 20-sample cycle
+	buf := make([][2]float64, 25)
+	a.voices = a.voices[:1]
+	a.Stream(buf)
+	if buf[9][0] < 0.99 || buf[10][0] != 0 || buf[20][0] < 0.99 {
+		t.Errorf("stream = %v, %v, %v; want sound, gap, sound", buf[9][0], buf[10][0], buf[20][0])
+	}
+
+	// Still heard after moving: it carries on where it was
+	a.update([3]float64{1, 0, 0}, rolloff, 1)
+	if a.voices[0].emitter != 0 || a.voices[0].pos != 5 {
+		t.Errorf("voice = emitter %d at %d, want 0 at 5", a.voices[0].emitter, a.voices[0].pos)
+	}
+
+	// Out of everything's range
+	a.update([3]float64{0, 0, 1000}, rolloff, 1)
+	if len(a.heard()) != 0 {
+		t.Errorf("heard %v out of range", a.heard())
+	}
+}
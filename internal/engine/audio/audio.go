@@ -37,17 +37,37 @@ type Manager struct {
 	bgmVolLevel  float64
 	sfxVolLevel  float64
 
-	// SFX mixer for concurrent sound effects
+	// SFX mixer for concurrent sound effects, played through the map's
+	// acoustics
 	sfxMixer *beep.Mixer
+	sfxEnv   *envFilter
+
+	// The map's looping ambient sounds, mixed in with sound effects
+	ambience *ambience
+
+	// Positional sound: where the listener is and how each category fades
+	listener [3]float64
+	rolloffs [categoryCount]Rolloff
+
+	// Sound effects playing, for the debug overlay
+	voiceMu   sync.Mutex
+	voices    []trackedVoice
+	nextVoice uint64
 }
 
 // New creates a new audio manager.
 func New() *Manager {
+	mixer := &beep.Mixer{}
+	amb := &ambience{sampleRate: float64(DefaultSampleRate)}
+	mixer.Add(amb)
 	return &Manager{
 		masterVolume: 1.0,
 		bgmVolLevel:  0.7,
 		sfxVolLevel:  1.0,
-		sfxMixer:     &beep.Mixer{},
+		sfxMixer:     mixer,
+		sfxEnv:       newEnvFilter(mixer, float64(DefaultSampleRate)),
+		ambience:     amb,
+		rolloffs:     DefaultRolloffs,
 	}
 }
 
@@ -67,7 +87,7 @@ func (m *Manager) Init() error {
 	}
 
 	// Start SFX mixer
-	speaker.Play(m.sfxMixer)
+	speaker.Play(m.sfxEnv)
 
 	m.initialized = true
 	return nil
@@ -288,7 +308,7 @@ func (m *Manager) stopBGMInternal() {
 	speaker.Clear()
 	// Re-add SFX mixer after clearing
 	if m.initialized {
-		speaker.Play(m.sfxMixer)
+		speaker.Play(m.sfxEnv)
 	}
 	m.bgmPlaying = false
 	if m.bgmStreamer != nil {
@@ -334,16 +354,30 @@ func (m *Manager) GetBGMPath() string {
 	return m.bgmPath
 }
 
-// PlaySFX plays a sound effect from WAV data.
+// PlaySFX plays an interface sound effect from WAV data.
 func (m *Manager) PlaySFX(data []byte) error {
+	return m.PlaySound(data, SoundSource{Category: CategoryUI})
+}
+
+// PlaySound plays a sound effect from WAV data, fading positional sounds
+// by their category's rolloff. Sounds too far away to hear are skipped.
+func (m *Manager) PlaySound(data []byte, src SoundSource) error {
 	m.mu.RLock()
 	initialized := m.initialized
-	sfxVol := m.masterVolume * m.sfxVolLevel
+	voice := Voice{Name: src.Name, Category: src.Category, Gain: 1, Started: time.Now()}
+	if src.Positional && src.Category >= 0 && src.Category < categoryCount {
+		voice.Distance = m.distance(src.X, src.Y, src.Z)
+		voice.Gain = m.rolloffs[src.Category].Gain(voice.Distance)
+	}
+	sfxVol := m.masterVolume * m.sfxVolLevel * voice.Gain
 	m.mu.RUnlock()
 
 	if !initialized {
 		return fmt.Errorf("audio not initialized")
 	}
+	if voice.Gain <= 0 {
+		return nil
+	}
 
 	// Decode WAV
 	streamer, format, err := wav.Decode(io.NopCloser(bytes.NewReader(data)))
@@ -368,7 +402,8 @@ func (m *Manager) PlaySFX(data []byte) error {
 	}
 
 	// Add to mixer (concurrent playback)
-	m.sfxMixer.Add(volStreamer)
+	id := m.addVoice(voice)
+	m.sfxMixer.Add(beep.Seq(volStreamer, beep.Callback(func() { m.removeVoice(id) })))
 
 	return nil
 }
@@ -0,0 +1,325 @@
+package audio
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gopxl/beep/v2"
+)
+
+// Category groups sound effects that share a distance rolloff.
+type Category int
+
+const (
+	CategoryUI      Category = iota // Interface sounds, never attenuated
+	CategoryEffect                  // Combat and skill sounds in the world
+	CategoryAmbient                 // Looping environment sounds
+	categoryCount
+)
+
+// String returns the category's name.
+func (c Category) String() string {
+	switch c {
+	case CategoryUI:
+		return "UI"
+	case CategoryEffect:
+		return "Effect"
+	case CategoryAmbient:
+		return "Ambient"
+	default:
+		return "Unknown"
+	}
+}
+
+// RolloffCurve is how a sound fades between its rolloff distances.
+type RolloffCurve int
+
+const (
+	RolloffNone    RolloffCurve = iota // Full volume at any distance
+	RolloffLinear                      // Straight fade to silence at MaxDistance
+	RolloffInverse                     // MinDistance/d, cut off at MaxDistance
+)
+
+// ParseRolloffCurve parses a curve name: "none", "linear" or "inverse".
+func ParseRolloffCurve(name string) (RolloffCurve, bool) {
+	switch strings.ToLower(name) {
+	case "none":
+		return RolloffNone, true
+	case "linear":
+		return RolloffLinear, true
+	case "inverse":
+		return RolloffInverse, true
+	}
+	return RolloffNone, false
+}
+
+// ParseCategory parses a category name: "ui", "effect" or "ambient".
+func ParseCategory(name string) (Category, bool) {
+	for c := range categoryCount {
+		if strings.EqualFold(name, c.String()) {
+			return c, true
+		}
+	}
+	return 0, false
+}
+
+// Rolloff configures how a category's sounds fade with distance from the
+// listener, in world units (5 per tile).
+type Rolloff struct {
+	Curve       RolloffCurve
+	MinDistance float64 // Full volume up to here
+	MaxDistance float64 // Silent beyond here
+}
+
+// Gain returns the volume factor (0-1) of a sound at distance d.
+func (r Rolloff) Gain(d float64) float64 {
+	if r.Curve == RolloffNone || d <= r.MinDistance {
+		return 1
+	}
+	if d >= r.MaxDistance {
+		return 0
+	}
+	switch r.Curve {
+	case RolloffLinear:
+		return 1 - (d-r.MinDistance)/(r.MaxDistance-r.MinDistance)
+	case RolloffInverse:
+		return r.MinDistance / d
+	}
+	return 1
+}
+
+// DefaultRolloffs are the rolloffs of each category until configured.
+var DefaultRolloffs = [categoryCount]Rolloff{
+	CategoryUI:      {Curve: RolloffNone},
+	CategoryEffect:  {Curve: RolloffInverse, MinDistance: 25, MaxDistance: 150},
+	CategoryAmbient: {Curve: RolloffLinear, MinDistance: 20, MaxDistance: 100},
+}
+
+// Environment is the acoustics applied to sound effects on a map: a
+// reverb tail and a low-pass filter that muffles highs indoors.
+type Environment struct {
+	Name      string
+	ReverbMix float64 // Wet level of the reverb, 0 for none
+	Decay     float64 // Reverb feedback (0-1); higher rings longer
+	DelayMs   float64 // Spacing of the first echoes
+	LowPassHz float64 // Low-pass cutoff, 0 for none
+}
+
+// Environment presets by kind of map.
+var (
+	EnvOutdoor = Environment{Name: "Outdoor"}
+	EnvIndoor  = Environment{Name: "Indoor", ReverbMix: 0.18, Decay: 0.35, DelayMs: 23, LowPassHz: 9000}
+	EnvCave    = Environment{Name: "Cave", ReverbMix: 0.35, Decay: 0.6, DelayMs: 47, LowPassHz: 4500}
+)
+
+// EnvironmentForMap picks the environment of a map from its name:
+// dungeons and caves echo, interiors ("_in" maps) get a small room.
+func EnvironmentForMap(mapName string) Environment {
+	name := strings.TrimSuffix(strings.ToLower(mapName), ".gat")
+	switch {
+	case strings.Contains(name, "_dun"), strings.Contains(name, "_sew"),
+		strings.HasPrefix(name, "anthell"), strings.HasPrefix(name, "treasure"):
+		return EnvCave
+	case strings.HasSuffix(name, "_in"), strings.Contains(name, "_in0"), strings.HasPrefix(name, "in_"):
+		return EnvIndoor
+	}
+	return EnvOutdoor
+}
+
+// combDelays are the reverb's comb filter lengths relative to DelayMs;
+// mutually prime-ish ratios keep the echoes from ringing metallic.
+var combDelays = [...]float64{1, 1.37, 1.71, 2.03}
+
+// envFilter applies the current Environment to a stream.
+type envFilter struct {
+	mu         sync.Mutex
+	src        beep.Streamer
+	sampleRate float64
+	env        Environment
+
+	lowA  float64    // Low-pass smoothing factor, 1 when off
+	low   [2]float64 // Low-pass state per channel
+	combs [len(combDelays)]comb
+}
+
+// comb is a feedback delay line.
+type comb struct {
+	buf [][2]float64
+	pos int
+}
+
+func newEnvFilter(src beep.Streamer, sampleRate float64) *envFilter {
+	f := &envFilter{src: src, sampleRate: sampleRate}
+	f.setEnvironment(EnvOutdoor)
+	return f
+}
+
+// setEnvironment switches the acoustics, dropping any reverb tail.
+func (f *envFilter) setEnvironment(env Environment) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.env = env
+	f.lowA = 1
+	if env.LowPassHz > 0 {
+		f.lowA = 1 - math.Exp(-2*math.Pi*env.LowPassHz/f.sampleRate)
+	}
+	for i, ratio := range combDelays {
+		n := int(env.DelayMs * ratio * f.sampleRate / 1000)
+		f.combs[i] = comb{}
+		if env.ReverbMix > 0 && n > 0 {
+			f.combs[i].buf = make([][2]float64, n)
+		}
+	}
+}
+
+// Stream implements beep.Streamer.
+func (f *envFilter) Stream(samples [][2]float64) (int, bool) {
+	n, ok := f.src.Stream(samples)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.lowA == 1 && f.env.ReverbMix <= 0 {
+		return n, ok
+	}
+	wet := f.env.ReverbMix / float64(len(f.combs))
+	for i := range samples[:n] {
+		for ch := range 2 {
+			x := samples[i][ch]
+			f.low[ch] += f.lowA * (x - f.low[ch])
+			out := f.low[ch]
+			for c := range f.combs {
+				cb := &f.combs[c]
+				if cb.buf == nil {
+					continue
+				}
+				echo := cb.buf[cb.pos][ch]
+				cb.buf[cb.pos][ch] = f.low[ch] + echo*f.env.Decay
+				out += echo * wet
+			}
+			samples[i][ch] = out
+		}
+		for c := range f.combs {
+			if cb := &f.combs[c]; cb.buf != nil {
+				cb.pos = (cb.pos + 1) % len(cb.buf)
+			}
+		}
+	}
+	return n, ok
+}
+
+// Err implements beep.Streamer.
+func (f *envFilter) Err() error {
+	return f.src.Err()
+}
+
+// SoundSource describes a sound effect being played.
+type SoundSource struct {
+	Name       string // Shown in the voice list, e.g. the asset path
+	Category   Category
+	Positional bool // Fade with distance from the listener by X, Y, Z
+	X, Y, Z    float64
+}
+
+// Voice is a sound effect that's playing.
+type Voice struct {
+	Name     string
+	Category Category
+	Distance float64 // From the listener when it started, 0 if not positional
+	Gain     float64 // Distance attenuation applied, 0-1
+	Started  time.Time
+}
+
+// SetListener moves the listener positional sounds are heard from.
+func (m *Manager) SetListener(x, y, z float64) {
+	m.mu.Lock()
+	m.listener = [3]float64{x, y, z}
+	m.mu.Unlock()
+	m.updateAmbient()
+}
+
+// SetRolloff configures how a category's sounds fade with distance.
+func (m *Manager) SetRolloff(c Category, r Rolloff) {
+	if c < 0 || c >= categoryCount {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rolloffs[c] = r
+}
+
+// GetRolloff returns a category's distance rolloff.
+func (m *Manager) GetRolloff(c Category) Rolloff {
+	if c < 0 || c >= categoryCount {
+		return Rolloff{}
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.rolloffs[c]
+}
+
+// SetEnvironment applies a map's acoustics to sound effects. Music is
+// left dry.
+func (m *Manager) SetEnvironment(env Environment) {
+	if m.GetEnvironment() == env {
+		return
+	}
+	m.sfxEnv.setEnvironment(env)
+}
+
+// GetEnvironment returns the acoustics sound effects are played with.
+func (m *Manager) GetEnvironment() Environment {
+	m.sfxEnv.mu.Lock()
+	defer m.sfxEnv.mu.Unlock()
+	return m.sfxEnv.env
+}
+
+// Voices returns the sound effects playing, oldest first, then the
+// ambient sounds heard, loudest first.
+func (m *Manager) Voices() []Voice {
+	m.voiceMu.Lock()
+	voices := make([]Voice, 0, len(m.voices))
+	for _, v := range m.voices {
+		voices = append(voices, v.Voice)
+	}
+	m.voiceMu.Unlock()
+	return append(voices, m.ambience.heard()...)
+}
+
+// trackedVoice is a playing voice with its removal key.
+type trackedVoice struct {
+	Voice
+	id uint64
+}
+
+// addVoice records a voice as playing and returns its id.
+func (m *Manager) addVoice(v Voice) uint64 {
+	m.voiceMu.Lock()
+	defer m.voiceMu.Unlock()
+	m.nextVoice++
+	m.voices = append(m.voices, trackedVoice{Voice: v, id: m.nextVoice})
+	return m.nextVoice
+}
+
+// removeVoice forgets a voice that finished. It runs on the speaker's
+// goroutine, so it takes only voiceMu, never mu.
+func (m *Manager) removeVoice(id uint64) {
+	m.voiceMu.Lock()
+	defer m.voiceMu.Unlock()
+	for i, v := range m.voices {
+		if v.id == id {
+			m.voices = append(m.voices[:i], m.voices[i+1:]...)
+			return
+		}
+	}
+}
+
+// distance returns how far a point is from the listener.
+func (m *Manager) distance(x, y, z float64) float64 {
+	return distanceBetween(m.listener, [3]float64{x, y, z})
+}
+
+func distanceBetween(a, b [3]float64) float64 {
+	dx, dy, dz := b[0]-a[0], b[1]-a[1], b[2]-a[2]
+	return math.Sqrt(dx*dx + dy*dy + dz*dz)
+}
@@ -0,0 +1,126 @@
+package audio
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gopxl/beep/v2"
+)
+
+func TestRolloffGain(t *testing.T) {
+	linear := Rolloff{Curve: RolloffLinear, MinDistance: 10, MaxDistance: 30}
+	inverse := Rolloff{Curve: RolloffInverse, MinDistance: 10, MaxDistance: 100}
+	tests := []struct {
+		name string
+		r    Rolloff
+		d    float64
+		want float64
+	}{
+		{"none far", Rolloff{Curve: RolloffNone}, 1000, 1},
+		{"linear inside min", linear, 5, 1},
+		{"linear halfway", linear, 20, 0.5},
+		{"linear at max", linear, 30, 0},
+		{"inverse inside min", inverse, 10, 1},
+		{"inverse double", inverse, 20, 0.5},
+		{"inverse beyond max", inverse, 150, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.r.Gain(tt.d); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: Gain(%v) = %v, want %v", tt.name, tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestEnvironmentForMap(t *testing.T) {
+	tests := []struct {
+		mapName string
+		want    string
+	}{
+		{"prontera", "Outdoor"},
+		{"prt_fild08.gat", "Outdoor"},
+		{"prt_in", "Indoor"},
+		{"payon_in01", "Indoor"},
+		{"in_orcs01", "Indoor"},
+		{"pay_dun00", "Cave"},
+		{"PRT_SEWB1", "Cave"},
+		{"anthell02", "Cave"},
+	}
+	for _, tt := range tests {
+		if got := EnvironmentForMap(tt.mapName).Name; got != tt.want {
+			t.Errorf("EnvironmentForMap(%q) = %s, want %s", tt.mapName, got, tt.want)
+		}
+	}
+}
+
+func TestParseRolloffConfig(t *testing.T) {
+	if c, ok := ParseCategory("Effect"); !ok || c != CategoryEffect {
+		t.Errorf("ParseCategory(Effect) = %v, %v", c, ok)
+	}
+	if _, ok := ParseCategory("music"); ok {
+		t.Error("ParseCategory(music) should fail")
+	}
+	if c, ok := ParseRolloffCurve("inverse"); !ok || c != RolloffInverse {
+		t.Errorf("ParseRolloffCurve(inverse) = %v, %v", c, ok)
+	}
+	if _, ok := ParseRolloffCurve("log"); ok {
+		t.Error("ParseRolloffCurve(log) should fail")
+	}
+}
+
+// impulse streams a single full-scale sample, then silence.
+func impulse(n int) beep.Streamer {
+	sent := false
+	return beep.StreamerFunc(func(samples [][2]float64) (int, bool) {
+		for i := range samples {
+			samples[i] = [2]float64{}
+		}
+		if !sent && len(samples) > 0 {
+			samples[0] = [2]float64{1, 1}
+			sent = true
+		}
+		return len(samples), true
+	})
+}
+
+func TestEnvFilter(t *testing.T) {
+	const rate = 44100
+	buf := make([][2]float64, rate/4)
+
+	// Outdoors passes the sound through untouched
+	f := newEnvFilter(impulse(len(buf)), rate)
+	f.Stream(buf)
+	if buf[0][0] != 1 || buf[1][0] != 0 {
+		t.Errorf("outdoor impulse = %v, %v; want 1, 0", buf[0][0], buf[1][0])
+	}
+
+	// A cave muffles the impulse and echoes it later
+	f = newEnvFilter(impulse(len(buf)), rate)
+	f.setEnvironment(EnvCave)
+	f.Stream(buf)
+	if buf[0][0] >= 1 {
+		t.Errorf("cave impulse peak = %v, want it low-passed below 1", buf[0][0])
+	}
+	echo := int(EnvCave.DelayMs * rate / 1000)
+	if buf[echo][0] == 0 {
+		t.Error("cave reverb produced no echo")
+	}
+}
+
+func TestManagerRolloffAndVoices(t *testing.T) {
+	m := New()
+	if got := m.GetRolloff(CategoryEffect); got != DefaultRolloffs[CategoryEffect] {
+		t.Errorf("default effect rolloff = %+v", got)
+	}
+	r := Rolloff{Curve: RolloffLinear, MinDistance: 1, MaxDistance: 2}
+	m.SetRolloff(CategoryAmbient, r)
+	if got := m.GetRolloff(CategoryAmbient); got != r {
+		t.Errorf("ambient rolloff = %+v, want %+v", got, r)
+	}
+
+	id := m.addVoice(Voice{Name: "a.wav"})
+	m.addVoice(Voice{Name: "b.wav"})
+	m.removeVoice(id)
+	if v := m.Voices(); len(v) != 1 || v[0].Name != "b.wav" {
+		t.Errorf("voices = %+v, want only b.wav", v)
+	}
+}
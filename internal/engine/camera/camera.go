@@ -7,6 +7,16 @@ import (
 	"github.com/Faultbox/midgard-ro/pkg/math"
 )
 
+// Camera is a view of the world a renderer draws from. The scene, the map
+// viewer and the model viewer all render through it, whichever camera
+// drives the view.
+type Camera interface {
+	// Position returns the eye position in world space.
+	Position() math.Vec3
+	// ViewMatrix returns the world-to-view matrix.
+	ViewMatrix() math.Mat4
+}
+
 // OrbitCamera orbits around a center point.
 type OrbitCamera struct {
 	// Center point to orbit around
@@ -139,6 +149,14 @@ func (c *OrbitCamera) FitToBounds(minX, minY, minZ, maxX, maxY, maxZ float32) {
 	c.RotationY = 0.0
 }
 
+// lookAtHeight raises the third-person camera's look-at point from the
+// target's feet to the character's centre.
+const lookAtHeight = 30
+
+// CollisionFunc reports how far along the segment from..to the first
+// obstacle lies, as a fraction 0..1, or 1 if the segment is clear.
+type CollisionFunc func(from, to math.Vec3) float32
+
 // ThirdPersonCamera follows a target from behind.
 type ThirdPersonCamera struct {
 	// Camera orientation
@@ -154,6 +172,21 @@ type ThirdPersonCamera struct {
 	YawSensitivity  float32
 	ZoomSensitivity float32
 
+	// Collision, when set, keeps the camera in front of whatever blocks the
+	// view of the target. Pulling in is fast so walls and hills are never
+	// seen through; restoring the distance once clear is slow.
+	Collide              CollisionFunc
+	CollisionPadding     float32 // Gap kept in front of the obstacle
+	MinCollisionDistance float32 // Closest the camera is pulled in
+	PullInRate           float32 // Smoothing rates, 1/s
+	RestoreRate          float32
+
+	// Effects are the shakes and zooms played over the view.
+	Effects Effects
+
+	viewDistance float32 // Distance after collision, 0 before the first Update
+	blocked      bool
+
 	// Cached position for external access
 	PosX, PosY, PosZ float32
 }
@@ -168,47 +201,121 @@ func NewThirdPersonCamera() *ThirdPersonCamera {
 		MaxDistance:     800.0,
 		YawSensitivity:  0.005,
 		ZoomSensitivity: 0.1,
+
+		CollisionPadding:     5,
+		MinCollisionDistance: 25,
+		PullInRate:           20,
+		RestoreRate:          3,
+
+		Effects: Effects{Scale: 1},
+	}
+}
+
+// Update ages the camera effects, pulls the camera in when the view of the
+// target is blocked and eases it back out to Distance once clear. Without
+// Collide the distance is left alone.
+func (c *ThirdPersonCamera) Update(targetX, targetY, targetZ, dt float32) {
+	c.Effects.Update(dt)
+	if c.Collide == nil {
+		c.viewDistance = 0
+		return
 	}
+
+	focus := math.Vec3{X: targetX, Y: targetY + lookAtHeight, Z: targetZ}
+	ideal := c.positionAt(targetX, targetY, targetZ, c.Distance)
+	want := c.Distance
+	hit := c.Collide(focus, ideal)
+	c.blocked = hit < 1
+	if c.blocked {
+		want = min(max(hit*c.Distance-c.CollisionPadding, c.MinCollisionDistance), c.Distance)
+	}
+
+	if c.viewDistance == 0 {
+		c.viewDistance = want
+		return
+	}
+	rate := c.RestoreRate
+	if want < c.viewDistance {
+		rate = c.PullInRate
+	}
+	c.viewDistance = math.Damp(c.viewDistance, want, rate, dt)
+}
+
+// ViewDistance returns the distance the camera is actually at, which is
+// shorter than Distance while something blocks the view.
+func (c *ThirdPersonCamera) ViewDistance() float32 {
+	if c.viewDistance == 0 {
+		return c.Distance
+	}
+	return c.viewDistance
 }
 
-// Position calculates camera position based on target position.
+// Position calculates camera position based on target position, with the
+// camera effects applied.
 func (c *ThirdPersonCamera) Position(targetX, targetY, targetZ float32) math.Vec3 {
+	shake, zoom, focus := c.Effects.Offset()
+	pos := c.positionAt(targetX+focus.X, targetY+focus.Y, targetZ+focus.Z, c.ViewDistance()*(1-zoom)).Add(shake)
+
+	// Cache for external access
+	c.PosX = pos.X
+	c.PosY = pos.Y
+	c.PosZ = pos.Z
+
+	return pos
+}
+
+// positionAt returns the camera position at distance from the target.
+func (c *ThirdPersonCamera) positionAt(targetX, targetY, targetZ, distance float32) math.Vec3 {
 	// Calculate camera offset from target using yaw for rotation
-	offsetY := c.Distance * float32(gomath.Sin(float64(c.Pitch)))
-	horizDist := c.Distance * float32(gomath.Cos(float64(c.Pitch)))
+	offsetY := distance * float32(gomath.Sin(float64(c.Pitch)))
+	horizDist := distance * float32(gomath.Cos(float64(c.Pitch)))
 	offsetX := horizDist * float32(gomath.Sin(float64(c.Yaw)))
 	offsetZ := horizDist * float32(gomath.Cos(float64(c.Yaw)))
 
 	// Camera position: behind and above target
-	pos := math.Vec3{
+	return math.Vec3{
 		X: targetX - offsetX,
 		Y: targetY + offsetY,
 		Z: targetZ - offsetZ,
 	}
-
-	// Cache for external access
-	c.PosX = pos.X
-	c.PosY = pos.Y
-	c.PosZ = pos.Z
-
-	return pos
 }
 
 // ViewMatrix returns the view matrix for this camera looking at target.
 func (c *ThirdPersonCamera) ViewMatrix(targetX, targetY, targetZ float32) math.Mat4 {
 	pos := c.Position(targetX, targetY, targetZ)
 
-	// Look at target position (slightly above for character center)
+	// Look at target position (slightly above for character center),
+	// moved along with the camera by its effects
+	shake, _, focus := c.Effects.Offset()
 	target := math.Vec3{
 		X: targetX,
-		Y: targetY + 30, // Look at character center, not feet
+		Y: targetY + lookAtHeight,
 		Z: targetZ,
-	}
+	}.Add(focus).Add(shake)
 
 	up := math.Vec3{X: 0, Y: 1, Z: 0}
 	return math.LookAt(pos, target, up)
 }
 
+// At returns the camera following a target at a position, as a Camera.
+func (c *ThirdPersonCamera) At(targetX, targetY, targetZ float32) Camera {
+	return following{c, targetX, targetY, targetZ}
+}
+
+// following is a ThirdPersonCamera bound to its target's position.
+type following struct {
+	cam     *ThirdPersonCamera
+	x, y, z float32
+}
+
+func (f following) Position() math.Vec3 {
+	return f.cam.Position(f.x, f.y, f.z)
+}
+
+func (f following) ViewMatrix() math.Mat4 {
+	return f.cam.ViewMatrix(f.x, f.y, f.z)
+}
+
 // HandleYaw rotates camera horizontally around target.
 func (c *ThirdPersonCamera) HandleYaw(deltaX float32) {
 	c.Yaw -= deltaX * c.YawSensitivity
@@ -223,6 +330,16 @@ func (c *ThirdPersonCamera) HandleZoom(delta float32) {
 	if c.Distance > c.MaxDistance {
 		c.Distance = c.MaxDistance
 	}
+
+	// Zooming is immediate while the view is clear; while blocked the
+	// camera can only come closer until Update finds room again.
+	if c.viewDistance != 0 {
+		if c.blocked {
+			c.viewDistance = min(c.viewDistance, c.Distance)
+		} else {
+			c.viewDistance = c.Distance
+		}
+	}
 }
 
 // ForwardDirection returns the camera's forward direction on the XZ plane.
@@ -0,0 +1,16 @@
+package camera
+
+import "testing"
+
+func TestThirdPersonAt(t *testing.T) {
+	c := NewThirdPersonCamera()
+	c.Yaw = 0.7
+	var cam Camera = c.At(100, 5, -40)
+
+	if got, want := cam.ViewMatrix(), c.ViewMatrix(100, 5, -40); got != want {
+		t.Errorf("ViewMatrix = %v, want %v", got, want)
+	}
+	if got, want := cam.Position(), c.Position(100, 5, -40); got != want {
+		t.Errorf("Position = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,184 @@
+package camera
+
+import (
+	gomath "math"
+
+	"github.com/Faultbox/midgard-ro/pkg/math"
+)
+
+// Limits of Effects.Scale offered in the settings.
+const (
+	MinEffectScale = 0.25
+	MaxEffectScale = 2.0
+)
+
+// ClampEffectScale limits a camera effect strength to the supported range.
+func ClampEffectScale(scale float32) float32 {
+	return min(max(scale, MinEffectScale), MaxEffectScale)
+}
+
+// EffectKind is what a camera effect does to the view.
+type EffectKind int
+
+// Camera effect kinds.
+const (
+	// EffectShake jitters the camera; Amplitude is in world units.
+	EffectShake EffectKind = iota
+	// EffectZoom brings the camera closer by Amplitude, a fraction of its
+	// distance, and shifts its look-at point toward Focus.
+	EffectZoom
+)
+
+// shakeRate is how fast shakes jitter, in radians/s of their main wave.
+const shakeRate = 45
+
+// Effect is a time-limited camera modifier. It eases in and out over Ease
+// seconds, or with no Ease starts at full strength and decays over
+// Duration, which suits punches and shakes. A Duration of zero holds the
+// effect until released.
+type Effect struct {
+	Kind      EffectKind
+	Amplitude float32
+	Duration  float32 // Seconds, 0 to hold until Effects.Release
+	Ease      float32 // Seconds to ease in and out
+	Focus     math.Vec3
+
+	elapsed  float32
+	released float32 // Elapsed time when released, -1 while held
+}
+
+// Shake returns a shake of amplitude world units decaying over duration
+// seconds.
+func Shake(amplitude, duration float32) Effect {
+	return Effect{Kind: EffectShake, Amplitude: amplitude, Duration: duration}
+}
+
+// ZoomPunch returns a quick zoom in by amount (a fraction of the distance)
+// that springs back over duration seconds.
+func ZoomPunch(amount, duration float32) Effect {
+	return Effect{Kind: EffectZoom, Amplitude: amount, Duration: duration}
+}
+
+// ZoomTo returns a zoom in by amount that eases the view toward focus (an
+// offset from the camera's target) over ease seconds and holds until
+// released.
+func ZoomTo(focus math.Vec3, amount, ease float32) Effect {
+	return Effect{Kind: EffectZoom, Amplitude: amount, Ease: ease, Focus: focus}
+}
+
+// EffectID identifies an effect added to Effects, to release it.
+type EffectID int
+
+// Effects is a stack of camera effects. Their offsets add up.
+type Effects struct {
+	// Scale multiplies every effect's amplitude; 0 turns effects off.
+	Scale float32
+
+	active []Effect
+	ids    []EffectID
+	nextID EffectID
+	time   float32
+}
+
+// Add starts an effect and returns its ID. Nothing is added while Scale is
+// zero.
+func (fx *Effects) Add(e Effect) EffectID {
+	if fx.Scale <= 0 {
+		return 0
+	}
+	fx.nextID++
+	e.elapsed, e.released = 0, -1
+	fx.active = append(fx.active, e)
+	fx.ids = append(fx.ids, fx.nextID)
+	return fx.nextID
+}
+
+// Release eases out a held effect. Unknown IDs are ignored.
+func (fx *Effects) Release(id EffectID) {
+	for i, eid := range fx.ids {
+		if eid == id && fx.active[i].released < 0 {
+			fx.active[i].released = fx.active[i].elapsed
+		}
+	}
+}
+
+// Clear drops all effects at once.
+func (fx *Effects) Clear() {
+	fx.active, fx.ids = fx.active[:0], fx.ids[:0]
+}
+
+// Active returns the number of effects running.
+func (fx *Effects) Active() int {
+	return len(fx.active)
+}
+
+// Update ages the effects by dt seconds, dropping finished ones.
+func (fx *Effects) Update(dt float32) {
+	fx.time += dt
+	n := 0
+	for i := range fx.active {
+		e := &fx.active[i]
+		e.elapsed += dt
+		if e.done() {
+			continue
+		}
+		fx.active[n], fx.ids[n] = *e, fx.ids[i]
+		n++
+	}
+	fx.active, fx.ids = fx.active[:n], fx.ids[:n]
+}
+
+// done reports whether the effect has run its course.
+func (e *Effect) done() bool {
+	if e.Duration > 0 {
+		return e.elapsed >= e.Duration
+	}
+	return e.released >= 0 && e.elapsed-e.released >= e.Ease
+}
+
+// weight returns how strong the effect is now, 0 to 1.
+func (e *Effect) weight() float32 {
+	if e.Ease <= 0 {
+		if e.Duration <= 0 {
+			return 1
+		}
+		return max(1-e.elapsed/e.Duration, 0)
+	}
+	w := min(e.elapsed/e.Ease, 1)
+	if e.Duration > 0 {
+		w = min(w, (e.Duration-e.elapsed)/e.Ease)
+	}
+	if e.released >= 0 {
+		w = min(w, 1-(e.elapsed-e.released)/e.Ease)
+	}
+	w = max(w, 0)
+	return w * w * (3 - 2*w) // Smoothstep
+}
+
+// Offset returns the sum of the effects now: the camera's shake, how much
+// closer it comes as a fraction of its distance, and the shift of its
+// look-at point.
+func (fx *Effects) Offset() (shake math.Vec3, zoom float32, focus math.Vec3) {
+	if fx.Scale <= 0 {
+		return
+	}
+	for i := range fx.active {
+		e := &fx.active[i]
+		w := e.weight()
+		switch e.Kind {
+		case EffectShake:
+			a := e.Amplitude * w * w * fx.Scale
+			t := float64(fx.time * shakeRate)
+			shake.X += a * float32(gomath.Sin(t)+0.5*gomath.Sin(2.3*t))
+			shake.Y += a * float32(gomath.Sin(1.7*t+1)) * 0.6
+			shake.Z += a * float32(gomath.Cos(1.3*t)+0.5*gomath.Sin(2.9*t))
+		case EffectZoom:
+			zoom += e.Amplitude * w * fx.Scale
+			focus = focus.Add(e.Focus.Scale(w))
+		}
+	}
+	return shake, min(zoom, maxEffectZoom), focus
+}
+
+// maxEffectZoom keeps stacked zooms from taking the camera into its target.
+const maxEffectZoom = 0.6
@@ -0,0 +1,95 @@
+package camera
+
+import (
+	"testing"
+
+	"github.com/Faultbox/midgard-ro/pkg/math"
+)
+
+func TestEffectsDecay(t *testing.T) {
+	fx := Effects{Scale: 1}
+	fx.Add(ZoomPunch(0.2, 1))
+
+	tests := []struct {
+		dt   float32
+		want float32
+	}{
+		{0, 0.2},
+		{0.5, 0.1},
+		{0.25, 0.05},
+		{0.25, 0},
+	}
+	for i, tt := range tests {
+		fx.Update(tt.dt)
+		if _, zoom, _ := fx.Offset(); abs(zoom-tt.want) > 1e-5 {
+			t.Errorf("step %d: zoom = %v, want %v", i, zoom, tt.want)
+		}
+	}
+	if fx.Active() != 0 {
+		t.Errorf("Active = %d after the punch ended, want 0", fx.Active())
+	}
+}
+
+func TestEffectsHoldAndRelease(t *testing.T) {
+	fx := Effects{Scale: 1}
+	focus := math.Vec3{X: 10}
+	id := fx.Add(ZoomTo(focus, 0.3, 0.5))
+	fx.Add(ZoomPunch(0.1, 0.2))
+
+	fx.Update(0.25)
+	if _, _, f := fx.Offset(); f.X != 5 {
+		t.Errorf("focus halfway through easing in = %v, want 5", f.X)
+	}
+	fx.Update(10)
+	if _, zoom, f := fx.Offset(); zoom != 0.3 || f.X != 10 {
+		t.Errorf("held zoom = %v toward %v, want 0.3 toward 10", zoom, f.X)
+	}
+	if fx.Active() != 1 {
+		t.Errorf("Active = %d with the punch over, want 1", fx.Active())
+	}
+
+	fx.Release(id)
+	fx.Update(0.25)
+	if _, zoom, _ := fx.Offset(); zoom != 0.15 {
+		t.Errorf("zoom halfway through easing out = %v, want 0.15", zoom)
+	}
+	fx.Update(0.25)
+	if fx.Active() != 0 {
+		t.Errorf("Active = %d after release, want 0", fx.Active())
+	}
+}
+
+func TestEffectsOff(t *testing.T) {
+	fx := Effects{}
+	if id := fx.Add(Shake(5, 1)); id != 0 || fx.Active() != 0 {
+		t.Errorf("Add with scale 0 = %d, %d active", id, fx.Active())
+	}
+
+	fx.Scale = 1
+	fx.Add(Shake(5, 1))
+	fx.Update(0.1)
+	if shake, _, _ := fx.Offset(); shake == (math.Vec3{}) {
+		t.Error("shake has no offset")
+	}
+	fx.Scale = 0
+	if shake, _, _ := fx.Offset(); shake != (math.Vec3{}) {
+		t.Errorf("shake with scale 0 = %v", shake)
+	}
+}
+
+func TestStackedZoomLimit(t *testing.T) {
+	fx := Effects{Scale: 2}
+	for range 5 {
+		fx.Add(ZoomPunch(0.3, 1))
+	}
+	if _, zoom, _ := fx.Offset(); zoom != maxEffectZoom {
+		t.Errorf("stacked zoom = %v, want %v", zoom, maxEffectZoom)
+	}
+}
+
+func abs(v float32) float32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -38,14 +38,10 @@ type Player struct {
 	CompositeMaxHeight int  // Max height across all composites (for consistent sizing)
 
 	// Billboard rendering
-	VAO         uint32
-	VBO         uint32
 	SpriteScale float32 // Scale factor for sprite (default 1.0)
 
 	// Shadow
 	ShadowTex uint32 // Shadow texture (ellipse)
-	ShadowVAO uint32
-	ShadowVBO uint32
 }
 
 // TerrainQuery provides terrain information for character movement.
@@ -0,0 +1,57 @@
+// Package clock is the time game logic runs on. It is wall time, except
+// while a session is recorded or replayed: then it starts from the
+// recording's start time and advances by a fixed step each frame, so a
+// replay sees the same times, animation phases and timeouts as the
+// recording whatever the speed it runs at.
+//
+// The clock isn't locked. SetFixedStep, SetRealTime and Advance are meant
+// to run between frames on the goroutine running them, and reads from any
+// other goroutine would race with them; in fixed-step mode such reads
+// would also see time that only moves a frame at a time. Code off the
+// frame loop, such as network I/O, uses the time package.
+package clock
+
+import "time"
+
+var (
+	fixed bool          // Fixed-step mode
+	step  time.Duration // Time a frame advances the clock in fixed-step mode
+	now   time.Time     // Current time in fixed-step mode
+)
+
+// Now returns the current time.
+func Now() time.Time {
+	if fixed {
+		return now
+	}
+	return time.Now()
+}
+
+// Since returns the time elapsed since t.
+func Since(t time.Time) time.Duration {
+	return Now().Sub(t)
+}
+
+// SetFixedStep switches to fixed-step mode, starting at start.
+func SetFixedStep(start time.Time, frameStep time.Duration) {
+	fixed, step, now = true, frameStep, start
+}
+
+// SetRealTime switches back to wall time.
+func SetRealTime() {
+	fixed = false
+}
+
+// Step returns the time a frame advances the clock, and false when the
+// clock runs on wall time.
+func Step() (time.Duration, bool) {
+	return step, fixed
+}
+
+// Advance moves a fixed-step clock on by one frame. Call it once at the
+// start of each frame.
+func Advance() {
+	if fixed {
+		now = now.Add(step)
+	}
+}
@@ -0,0 +1,33 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFixedStep(t *testing.T) {
+	defer SetRealTime()
+
+	start := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	SetFixedStep(start, 16*time.Millisecond)
+	if got := Now(); !got.Equal(start) {
+		t.Errorf("Now() = %v, want %v", got, start)
+	}
+	for range 3 {
+		Advance()
+	}
+	if got := Since(start); got != 48*time.Millisecond {
+		t.Errorf("Since(start) after 3 frames = %v, want 48ms", got)
+	}
+	if step, ok := Step(); !ok || step != 16*time.Millisecond {
+		t.Errorf("Step() = %v, %v", step, ok)
+	}
+
+	SetRealTime()
+	if _, ok := Step(); ok {
+		t.Error("Step() reports fixed-step on wall time")
+	}
+	if Since(start) < time.Hour {
+		t.Error("Now() still returns the fixed-step time")
+	}
+}
@@ -7,7 +7,10 @@ import (
 	"image/png"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
+	"unicode"
 )
 
 // ScreenshotCapture handles screenshot capture functionality.
@@ -116,3 +119,134 @@ func (sc *ScreenshotCapture) GenerateFilename() string {
 	}
 	return filename
 }
+
+// ScreenshotEntry describes a saved screenshot on disk.
+type ScreenshotEntry struct {
+	Path    string
+	Name    string
+	ModTime time.Time
+}
+
+// ScreenshotName builds a filename of the form map_character_YYYYMMDD-HHMMSS.png.
+// Empty parts are omitted and unsafe characters are replaced with underscores.
+func ScreenshotName(mapName, charName string, t time.Time) string {
+	mapName = strings.TrimSuffix(strings.TrimSuffix(mapName, ".gat"), ".rsw")
+
+	var parts []string
+	for _, p := range []string{mapName, charName} {
+		if s := sanitizeFilenamePart(p); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "screenshot")
+	}
+	parts = append(parts, t.Format("20060102-150405"))
+	return strings.Join(parts, "_") + ".png"
+}
+
+// sanitizeFilenamePart keeps letters, digits, '-' and '.', mapping anything else to '_'.
+func sanitizeFilenamePart(s string) string {
+	s = strings.TrimSpace(s)
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == '.':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return strings.Trim(b.String(), "_.")
+}
+
+// ListScreenshots returns up to limit PNG screenshots in dir, newest first.
+// A limit <= 0 returns all of them. latest.png is skipped since it duplicates another entry.
+func ListScreenshots(dir string, limit int) ([]ScreenshotEntry, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading screenshot dir: %w", err)
+	}
+
+	var entries []ScreenshotEntry
+	for _, de := range dirEntries {
+		name := de.Name()
+		if de.IsDir() || !strings.EqualFold(filepath.Ext(name), ".png") || strings.EqualFold(name, "latest.png") {
+			continue
+		}
+		info, err := de.Info()
+		if err != nil {
+			continue
+		}
+		entries = append(entries, ScreenshotEntry{
+			Path:    filepath.Join(dir, name),
+			Name:    name,
+			ModTime: info.ModTime(),
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].ModTime.Equal(entries[j].ModTime) {
+			return entries[i].ModTime.After(entries[j].ModTime)
+		}
+		return entries[i].Name > entries[j].Name
+	})
+
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+// Thumbnail downscales img to fit within maxW x maxH, preserving aspect ratio.
+// Each output pixel is the average of the source pixels it covers.
+func Thumbnail(img image.Image, maxW, maxH int) *image.RGBA {
+	b := img.Bounds()
+	srcW, srcH := b.Dx(), b.Dy()
+	if srcW == 0 || srcH == 0 || maxW <= 0 || maxH <= 0 {
+		return image.NewRGBA(image.Rect(0, 0, 0, 0))
+	}
+
+	w, h := srcW, srcH
+	if w > maxW {
+		h = h * maxW / w
+		w = maxW
+	}
+	if h > maxH {
+		w = w * maxH / h
+		h = maxH
+	}
+	w = max(w, 1)
+	h = max(h, 1)
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		sy0 := b.Min.Y + y*srcH/h
+		sy1 := max(b.Min.Y+(y+1)*srcH/h, sy0+1)
+		for x := 0; x < w; x++ {
+			sx0 := b.Min.X + x*srcW/w
+			sx1 := max(b.Min.X+(x+1)*srcW/w, sx0+1)
+
+			var r, g, bl, a, n uint32
+			for sy := sy0; sy < sy1; sy++ {
+				for sx := sx0; sx < sx1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r += cr
+					g += cg
+					bl += cb
+					a += ca
+					n++
+				}
+			}
+			off := dst.PixOffset(x, y)
+			dst.Pix[off+0] = uint8(r / n >> 8)
+			dst.Pix[off+1] = uint8(g / n >> 8)
+			dst.Pix[off+2] = uint8(bl / n >> 8)
+			dst.Pix[off+3] = uint8(a / n >> 8)
+		}
+	}
+	return dst
+}
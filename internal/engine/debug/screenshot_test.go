@@ -0,0 +1,86 @@
+package debug
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestScreenshotName(t *testing.T) {
+	ts := time.Date(2024, 3, 9, 14, 5, 7, 0, time.UTC)
+
+	tests := []struct {
+		mapName  string
+		charName string
+		want     string
+	}{
+		{"prontera.gat", "Alice", "prontera_Alice_20240309-140507.png"},
+		{"prontera", "", "prontera_20240309-140507.png"},
+		{"", "", "screenshot_20240309-140507.png"},
+		{"moc_fild01", "Sir Knight", "moc_fild01_Sir_Knight_20240309-140507.png"},
+		{"pay_dun00", "../evil/name", "pay_dun00_evil_name_20240309-140507.png"},
+		{"geffen", "검사", "geffen_검사_20240309-140507.png"},
+	}
+
+	for _, tt := range tests {
+		if got := ScreenshotName(tt.mapName, tt.charName, ts); got != tt.want {
+			t.Errorf("ScreenshotName(%q, %q) = %q, want %q", tt.mapName, tt.charName, got, tt.want)
+		}
+	}
+}
+
+func TestListScreenshots(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Now().Add(-time.Hour)
+
+	files := []string{"a.png", "b.png", "c.png", "latest.png", "notes.txt"}
+	for i, name := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		mt := base.Add(time.Duration(i) * time.Minute)
+		if err := os.Chtimes(path, mt, mt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := ListScreenshots(dir, 2)
+	if err != nil {
+		t.Fatalf("ListScreenshots: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Name != "c.png" || entries[1].Name != "b.png" {
+		t.Errorf("unexpected entries: %+v", entries)
+	}
+
+	entries, err = ListScreenshots(filepath.Join(dir, "missing"), 0)
+	if err != nil || len(entries) != 0 {
+		t.Errorf("missing dir: entries=%v err=%v", entries, err)
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 400; x++ {
+			src.Set(x, y, color.RGBA{R: 200, G: 100, B: 50, A: 255})
+		}
+	}
+
+	thumb := Thumbnail(src, 160, 120)
+	if b := thumb.Bounds(); b.Dx() != 160 || b.Dy() != 80 {
+		t.Fatalf("thumbnail size = %dx%d, want 160x80", b.Dx(), b.Dy())
+	}
+	if c := thumb.RGBAAt(10, 10); c != (color.RGBA{R: 200, G: 100, B: 50, A: 255}) {
+		t.Errorf("thumbnail pixel = %v", c)
+	}
+
+	// Images smaller than the bounds are not upscaled
+	small := Thumbnail(image.NewRGBA(image.Rect(0, 0, 32, 16)), 160, 120)
+	if b := small.Bounds(); b.Dx() != 32 || b.Dy() != 16 {
+		t.Errorf("small thumbnail size = %dx%d, want 32x16", b.Dx(), b.Dy())
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !gldebug
+
+package gldebug
+
+// buildEnabled turns GL error checking on regardless of the runtime flag.
+// Build with -tags gldebug to set it.
+const buildEnabled = false
@@ -0,0 +1,6 @@
+//go:build gldebug
+
+package gldebug
+
+// buildEnabled turns GL error checking on regardless of the runtime flag.
+const buildEnabled = true
@@ -0,0 +1,88 @@
+package gldebug
+
+import (
+	"strings"
+	"unsafe"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// Init sets up GL error checking on the current context if it's enabled,
+// and returns the mode in use. Call it after gl.Init.
+func Init() Mode {
+	sections = sections[:0]
+	if !Enabled() {
+		mode = ModeOff
+		return mode
+	}
+	drv = glDriver{}
+	if !hasKHRDebug() {
+		mode = ModeSweep
+		return mode
+	}
+
+	gl.Enable(gl.DEBUG_OUTPUT)
+	// Synchronous output calls back from inside the offending GL call, so
+	// the section stack still names the subsystem responsible.
+	gl.Enable(gl.DEBUG_OUTPUT_SYNCHRONOUS)
+	gl.DebugMessageCallback(onDebugMessage, nil)
+	// Drop errors raised before the callback was installed
+	for range maxSweep {
+		if gl.GetError() == gl.NO_ERROR {
+			break
+		}
+	}
+	mode = ModeCallback
+	return mode
+}
+
+// hasKHRDebug reports whether the context has the KHR_debug entry points:
+// core since OpenGL 4.3, otherwise through the extension. macOS stops at
+// 4.1 without it.
+func hasKHRDebug() bool {
+	var major, minor int32
+	gl.GetIntegerv(gl.MAJOR_VERSION, &major)
+	gl.GetIntegerv(gl.MINOR_VERSION, &minor)
+	if major > 4 || (major == 4 && minor >= 3) {
+		return true
+	}
+	var count int32
+	gl.GetIntegerv(gl.NUM_EXTENSIONS, &count)
+	for i := range uint32(count) {
+		if gl.GoStr(gl.GetStringi(gl.EXTENSIONS, i)) == "GL_KHR_debug" {
+			return true
+		}
+	}
+	return false
+}
+
+// onDebugMessage is the KHR_debug callback.
+func onDebugMessage(source, gltype, id, severity uint32, length int32, message string, userParam unsafe.Pointer) {
+	switch gltype {
+	case gl.DEBUG_TYPE_PUSH_GROUP, gl.DEBUG_TYPE_POP_GROUP:
+		return // Our own sections
+	}
+	sev := SeverityInfo
+	switch {
+	case gltype == gl.DEBUG_TYPE_ERROR || severity == gl.DEBUG_SEVERITY_HIGH:
+		sev = SeverityError
+	case severity == gl.DEBUG_SEVERITY_MEDIUM:
+		sev = SeverityWarning
+	}
+	receive(id, sev, strings.TrimSpace(message))
+}
+
+// glDriver is the driver backed by the current GL context.
+type glDriver struct{}
+
+func (glDriver) GetError() uint32 {
+	return gl.GetError()
+}
+
+func (glDriver) PushGroup(label string) {
+	gl.PushDebugGroup(gl.DEBUG_SOURCE_APPLICATION, 0, int32(len(label)), gl.Str(label+"\x00"))
+}
+
+func (glDriver) PopGroup() {
+	gl.PopDebugGroup()
+}
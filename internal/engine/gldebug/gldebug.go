@@ -0,0 +1,216 @@
+// Package gldebug reports OpenGL errors together with the subsystem that
+// caused them.
+//
+// Rendering code wraps its GL work in labeled sections:
+//
+//	defer gldebug.Section("terrain")()
+//
+// When checking is on (the gldebug build tag or SetEnabled), Init picks
+// the best mode the driver offers: a KHR_debug message callback, with
+// sections pushed as debug groups, or else a glGetError sweep at the end
+// of every section. When it is off, sections cost a function call.
+//
+// The open sections are a stack in unlocked package state, and the debug
+// callback reads it from inside the failing GL call. Sections therefore
+// only label errors correctly when they're opened and closed on the
+// goroutine making the GL calls they wrap.
+package gldebug
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/Faultbox/midgard-ro/internal/logger"
+)
+
+// Mode is how GL errors are detected.
+type Mode int
+
+const (
+	ModeOff      Mode = iota // No checking
+	ModeSweep                // glGetError after each section
+	ModeCallback             // KHR_debug message callback
+)
+
+// String returns the mode's name for logs.
+func (m Mode) String() string {
+	switch m {
+	case ModeSweep:
+		return "glGetError sweep"
+	case ModeCallback:
+		return "KHR_debug callback"
+	}
+	return "off"
+}
+
+// maxSweep bounds an error sweep. glGetError keeps returning an error
+// when there is no current context, which would otherwise never end.
+const maxSweep = 16
+
+// Unlabeled is the subsystem of GL errors raised outside any section.
+const Unlabeled = "unlabeled"
+
+// Report is a GL error or a driver debug message.
+type Report struct {
+	Subsystem string // Innermost section at the time, or Unlabeled
+	Code      uint32 // GL error code, or the debug message ID
+	Message   string
+	Severity  Severity
+}
+
+// Severity ranks a report.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+// driver is the part of GL gldebug talks to, faked in tests.
+type driver interface {
+	GetError() uint32
+	PushGroup(label string)
+	PopGroup()
+}
+
+var (
+	runtimeEnabled bool
+	mode           Mode
+	drv            driver
+	sections       []string
+	sink           = logReport
+)
+
+// SetEnabled turns GL error checking on or off at runtime, e.g. from the
+// config. It takes effect at the next Init.
+func SetEnabled(enabled bool) {
+	runtimeEnabled = enabled
+}
+
+// Enabled reports whether GL error checking was requested, by build tag
+// or at runtime.
+func Enabled() bool {
+	return buildEnabled || runtimeEnabled
+}
+
+// Active returns the mode Init picked.
+func Active() Mode {
+	return mode
+}
+
+// Section starts a labeled section of GL work and returns the function
+// ending it. Errors raised inside are reported under subsystem; errors
+// still pending from before are drained first and reported under the
+// enclosing section. Sections nest.
+func Section(subsystem string) func() {
+	switch mode {
+	case ModeSweep:
+		sweep(current())
+		sections = append(sections, subsystem)
+		return func() {
+			sweep(subsystem)
+			popSection()
+		}
+	case ModeCallback:
+		sections = append(sections, subsystem)
+		drv.PushGroup(subsystem)
+		return func() {
+			drv.PopGroup()
+			popSection()
+		}
+	}
+	return func() {}
+}
+
+// Check drains pending GL errors and reports them under subsystem. It's
+// for one-off checks outside sections, such as after resource uploads;
+// with the callback errors are already reported as they happen.
+func Check(subsystem string) {
+	if mode == ModeSweep {
+		sweep(subsystem)
+	}
+}
+
+// sweep reports every pending glGetError code under subsystem.
+func sweep(subsystem string) {
+	for range maxSweep {
+		code := drv.GetError()
+		if code == 0 {
+			return
+		}
+		sink(Report{
+			Subsystem: subsystem,
+			Code:      code,
+			Message:   errorName(code),
+			Severity:  SeverityError,
+		})
+	}
+}
+
+// receive reports a driver debug message under the innermost section.
+// The driver calls back synchronously, while the offending call is on the
+// stack, so that is the section that raised it.
+func receive(id uint32, severity Severity, message string) {
+	sink(Report{
+		Subsystem: current(),
+		Code:      id,
+		Message:   message,
+		Severity:  severity,
+	})
+}
+
+// current returns the innermost section's subsystem.
+func current() string {
+	if len(sections) == 0 {
+		return Unlabeled
+	}
+	return sections[len(sections)-1]
+}
+
+func popSection() {
+	if len(sections) > 0 {
+		sections = sections[:len(sections)-1]
+	}
+}
+
+// errorName returns the name of a glGetError code.
+func errorName(code uint32) string {
+	switch code {
+	case 0x0500:
+		return "GL_INVALID_ENUM"
+	case 0x0501:
+		return "GL_INVALID_VALUE"
+	case 0x0502:
+		return "GL_INVALID_OPERATION"
+	case 0x0503:
+		return "GL_STACK_OVERFLOW"
+	case 0x0504:
+		return "GL_STACK_UNDERFLOW"
+	case 0x0505:
+		return "GL_OUT_OF_MEMORY"
+	case 0x0506:
+		return "GL_INVALID_FRAMEBUFFER_OPERATION"
+	}
+	return fmt.Sprintf("GL error 0x%04X", code)
+}
+
+// logReport logs r with its subsystem.
+func logReport(r Report) {
+	if logger.Log == nil {
+		return
+	}
+	fields := []zap.Field{
+		zap.String("subsystem", r.Subsystem),
+		zap.String("code", fmt.Sprintf("0x%04X", r.Code)),
+	}
+	switch r.Severity {
+	case SeverityError:
+		logger.Error("GL: "+r.Message, fields...)
+	case SeverityWarning:
+		logger.Warn("GL: "+r.Message, fields...)
+	default:
+		logger.Debug("GL: "+r.Message, fields...)
+	}
+}
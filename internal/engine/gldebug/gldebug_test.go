@@ -0,0 +1,188 @@
+package gldebug
+
+import (
+	"reflect"
+	"testing"
+)
+
+// fakeDriver queues GL errors and records debug groups.
+type fakeDriver struct {
+	errors []uint32
+	groups []string // Open groups
+	calls  []string
+}
+
+func (f *fakeDriver) GetError() uint32 {
+	if len(f.errors) == 0 {
+		return 0
+	}
+	code := f.errors[0]
+	f.errors = f.errors[1:]
+	return code
+}
+
+func (f *fakeDriver) PushGroup(label string) {
+	f.groups = append(f.groups, label)
+	f.calls = append(f.calls, "push "+label)
+}
+
+func (f *fakeDriver) PopGroup() {
+	f.calls = append(f.calls, "pop "+f.groups[len(f.groups)-1])
+	f.groups = f.groups[:len(f.groups)-1]
+}
+
+// useFake switches the package to m over a fake driver for one test and
+// returns the driver and the reports it collects.
+func useFake(t *testing.T, m Mode) (*fakeDriver, *[]Report) {
+	t.Helper()
+	f := &fakeDriver{}
+	var reports []Report
+	oldMode, oldDrv, oldSink := mode, drv, sink
+	mode, drv, sections = m, f, nil
+	sink = func(r Report) { reports = append(reports, r) }
+	t.Cleanup(func() {
+		mode, drv, sink, sections = oldMode, oldDrv, oldSink, nil
+	})
+	return f, &reports
+}
+
+func subsystems(reports []Report) []string {
+	var out []string
+	for _, r := range reports {
+		out = append(out, r.Subsystem)
+	}
+	return out
+}
+
+func TestSectionSweep(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(f *fakeDriver)
+		want []string
+	}{
+		{
+			name: "clean section",
+			run: func(f *fakeDriver) {
+				Section("terrain")()
+			},
+		},
+		{
+			name: "error inside section",
+			run: func(f *fakeDriver) {
+				end := Section("terrain")
+				f.errors = append(f.errors, 0x0502)
+				end()
+			},
+			want: []string{"terrain"},
+		},
+		{
+			name: "pending error goes to the enclosing section",
+			run: func(f *fakeDriver) {
+				f.errors = append(f.errors, 0x0500)
+				end := Section("models")
+				f.errors = append(f.errors, 0x0501)
+				end()
+			},
+			want: []string{Unlabeled, "models"},
+		},
+		{
+			name: "nested sections",
+			run: func(f *fakeDriver) {
+				outer := Section("scene")
+				f.errors = append(f.errors, 0x0500)
+				inner := Section("water")
+				f.errors = append(f.errors, 0x0501, 0x0505)
+				inner()
+				f.errors = append(f.errors, 0x0502)
+				outer()
+			},
+			want: []string{"scene", "water", "water", "scene"},
+		},
+		{
+			name: "check outside sections",
+			run: func(f *fakeDriver) {
+				f.errors = append(f.errors, 0x0506)
+				Check("texture upload")
+			},
+			want: []string{"texture upload"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, reports := useFake(t, ModeSweep)
+			tt.run(f)
+			if got := subsystems(*reports); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("subsystems = %v, want %v", got, tt.want)
+			}
+			if len(sections) != 0 {
+				t.Errorf("sections left open: %v", sections)
+			}
+		})
+	}
+}
+
+func TestSweepIsBounded(t *testing.T) {
+	f, reports := useFake(t, ModeSweep)
+	for range 100 {
+		f.errors = append(f.errors, 0x0502)
+	}
+	Check("lost context")
+	if len(*reports) != maxSweep {
+		t.Errorf("got %d reports, want %d", len(*reports), maxSweep)
+	}
+}
+
+func TestSectionCallback(t *testing.T) {
+	f, reports := useFake(t, ModeCallback)
+
+	outer := Section("scene")
+	inner := Section("water")
+	receive(7, SeverityError, "invalid texture")
+	inner()
+	receive(8, SeverityWarning, "slow path")
+	f.errors = append(f.errors, 0x0502)
+	Check("scene") // The callback already reports errors
+	outer()
+	receive(9, SeverityInfo, "buffer usage")
+
+	wantCalls := []string{"push scene", "push water", "pop water", "pop scene"}
+	if !reflect.DeepEqual(f.calls, wantCalls) {
+		t.Errorf("calls = %v, want %v", f.calls, wantCalls)
+	}
+	want := []Report{
+		{Subsystem: "water", Code: 7, Message: "invalid texture", Severity: SeverityError},
+		{Subsystem: "scene", Code: 8, Message: "slow path", Severity: SeverityWarning},
+		{Subsystem: Unlabeled, Code: 9, Message: "buffer usage", Severity: SeverityInfo},
+	}
+	if !reflect.DeepEqual(*reports, want) {
+		t.Errorf("reports = %+v, want %+v", *reports, want)
+	}
+}
+
+func TestSectionOff(t *testing.T) {
+	f, reports := useFake(t, ModeOff)
+	f.errors = append(f.errors, 0x0502)
+	Section("terrain")()
+	Check("terrain")
+	if len(*reports) != 0 || len(f.calls) != 0 || len(f.errors) != 1 {
+		t.Errorf("disabled checking touched GL: reports %v, calls %v", *reports, f.calls)
+	}
+}
+
+func TestErrorName(t *testing.T) {
+	tests := []struct {
+		code uint32
+		want string
+	}{
+		{0x0500, "GL_INVALID_ENUM"},
+		{0x0502, "GL_INVALID_OPERATION"},
+		{0x0506, "GL_INVALID_FRAMEBUFFER_OPERATION"},
+		{0x1234, "GL error 0x1234"},
+	}
+	for _, tt := range tests {
+		if got := errorName(tt.code); got != tt.want {
+			t.Errorf("errorName(0x%04X) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package glstate
+
+import "github.com/go-gl/gl/v4.1-core/gl"
+
+// Initial is the state of a fresh GL context.
+var Initial = State{
+	DepthWrite: true,
+	DepthFunc:  gl.LESS,
+	BlendSrc:   gl.ONE,
+	BlendDst:   gl.ZERO,
+	CullFace:   gl.BACK,
+}
+
+// Presets for the common kinds of pass.
+var (
+	// Default is the world's baseline: depth tested and written, alpha
+	// blended, no culling since RO's winding order varies.
+	Default = State{
+		DepthTest:  true,
+		DepthWrite: true,
+		DepthFunc:  gl.LESS,
+		Blend:      true,
+		BlendSrc:   gl.SRC_ALPHA,
+		BlendDst:   gl.ONE_MINUS_SRC_ALPHA,
+		CullFace:   gl.BACK,
+	}
+
+	// Opaque draws without blending.
+	Opaque = Default.With(func(s *State) { s.Blend = false })
+
+	// Translucent blends over the scene without hiding what's drawn later
+	// behind it.
+	Translucent = Default.With(func(s *State) { s.DepthWrite = false })
+
+	// Additive brightens what's behind it, for glows and auras.
+	Additive = Translucent.With(func(s *State) { s.BlendDst = gl.ONE })
+
+	// Decal draws translucent geometry flush with the surface under it,
+	// pulled forward so it doesn't z-fight.
+	Decal = Translucent.With(func(s *State) {
+		s.OffsetFill = true
+		s.OffsetFactor, s.OffsetUnits = -1, -1
+	})
+
+	// Overlay draws over everything, for boards and the 2D UI.
+	Overlay = Default.With(func(s *State) { s.DepthTest = false })
+
+	// ShadowDepth renders depth only from the light, culling front faces
+	// to keep surfaces from shadowing themselves.
+	ShadowDepth = Opaque.With(func(s *State) {
+		s.Cull = true
+		s.CullFace = gl.FRONT
+	})
+)
+
+// glCaps maps capabilities to their GL enums.
+var glCaps = [...]uint32{
+	capDepthTest:  gl.DEPTH_TEST,
+	capBlend:      gl.BLEND,
+	capCull:       gl.CULL_FACE,
+	capOffsetFill: gl.POLYGON_OFFSET_FILL,
+	capOffsetLine: gl.POLYGON_OFFSET_LINE,
+}
+
+// glDriver is the driver backed by the current GL context.
+type glDriver struct{}
+
+func (glDriver) SetCap(cap uint32, on bool) {
+	if on {
+		gl.Enable(glCaps[cap])
+	} else {
+		gl.Disable(glCaps[cap])
+	}
+}
+
+func (glDriver) DepthMask(on bool) {
+	gl.DepthMask(on)
+}
+
+func (glDriver) DepthFunc(fn uint32) {
+	gl.DepthFunc(fn)
+}
+
+func (glDriver) BlendFunc(src, dst uint32) {
+	gl.BlendFunc(src, dst)
+}
+
+func (glDriver) CullFace(face uint32) {
+	gl.CullFace(face)
+}
+
+func (glDriver) PolygonOffset(factor, units float32) {
+	gl.PolygonOffset(factor, units)
+}
+
+func (glDriver) Query() State {
+	var depthWrite bool
+	var depthFunc, blendSrc, blendDst, cullFace int32
+	gl.GetBooleanv(gl.DEPTH_WRITEMASK, &depthWrite)
+	gl.GetIntegerv(gl.DEPTH_FUNC, &depthFunc)
+	gl.GetIntegerv(gl.BLEND_SRC_RGB, &blendSrc)
+	gl.GetIntegerv(gl.BLEND_DST_RGB, &blendDst)
+	gl.GetIntegerv(gl.CULL_FACE_MODE, &cullFace)
+	var factor, units float32
+	gl.GetFloatv(gl.POLYGON_OFFSET_FACTOR, &factor)
+	gl.GetFloatv(gl.POLYGON_OFFSET_UNITS, &units)
+	return State{
+		DepthTest:    gl.IsEnabled(gl.DEPTH_TEST),
+		DepthWrite:   depthWrite,
+		DepthFunc:    uint32(depthFunc),
+		Blend:        gl.IsEnabled(gl.BLEND),
+		BlendSrc:     uint32(blendSrc),
+		BlendDst:     uint32(blendDst),
+		Cull:         gl.IsEnabled(gl.CULL_FACE),
+		CullFace:     uint32(cullFace),
+		OffsetFill:   gl.IsEnabled(gl.POLYGON_OFFSET_FILL),
+		OffsetLine:   gl.IsEnabled(gl.POLYGON_OFFSET_LINE),
+		OffsetFactor: factor,
+		OffsetUnits:  units,
+	}
+}
@@ -0,0 +1,151 @@
+// Package glstate tracks the fixed-function OpenGL state renderers depend
+// on (depth, blending, culling, polygon offset) and changes only what
+// differs between passes.
+//
+// A renderer declares the state it needs and restores what it found:
+//
+//	defer glstate.Push(glstate.Translucent)()
+//
+// or, with a labeled section for gldebug:
+//
+//	defer glstate.Pass{Name: "water", State: glstate.Translucent}.Begin()()
+//
+// Code that changes GL state behind the tracker's back (a UI library,
+// a third-party backend) must call Sync or Reset before tracked passes
+// run again. The tracker mirrors a single GL context: state changed on
+// another context, or on a goroutine other than the one issuing the
+// tracked passes, is invisible to it and leaves it out of sync.
+package glstate
+
+import "github.com/Faultbox/midgard-ro/internal/engine/gldebug"
+
+// State is the render state a pass requires.
+type State struct {
+	DepthTest  bool
+	DepthWrite bool
+	DepthFunc  uint32
+
+	Blend    bool
+	BlendSrc uint32
+	BlendDst uint32
+
+	Cull     bool
+	CullFace uint32
+
+	OffsetFill   bool // Polygon offset for filled polygons
+	OffsetLine   bool // Polygon offset for wireframe lines
+	OffsetFactor float32
+	OffsetUnits  float32
+}
+
+// With returns a copy of s changed by fn, for one-off variations of the
+// presets:
+//
+//	glstate.Opaque.With(func(s *glstate.State) { s.DepthFunc = gl.LEQUAL })
+func (s State) With(fn func(*State)) State {
+	fn(&s)
+	return s
+}
+
+// driver is the part of GL glstate talks to, faked in tests.
+type driver interface {
+	SetCap(cap uint32, on bool)
+	DepthMask(on bool)
+	DepthFunc(fn uint32)
+	BlendFunc(src, dst uint32)
+	CullFace(face uint32)
+	PolygonOffset(factor, units float32)
+	Query() State
+}
+
+// Capabilities toggled by SetCap.
+const (
+	capDepthTest uint32 = iota
+	capBlend
+	capCull
+	capOffsetFill
+	capOffsetLine
+)
+
+var (
+	drv     driver = glDriver{}
+	current        = Initial
+)
+
+// Current returns the state the tracker believes GL is in.
+func Current() State {
+	return current
+}
+
+// Apply switches GL to s, issuing calls only for what differs from the
+// current state, and returns the state it replaced.
+func Apply(s State) State {
+	prev := current
+	set(s, false)
+	return prev
+}
+
+// Push applies s and returns the function restoring the state before it.
+func Push(s State) func() {
+	prev := Apply(s)
+	return func() { Apply(prev) }
+}
+
+// Reset switches GL to s with every call issued, for the start of a frame
+// or after state was changed outside the tracker.
+func Reset(s State) {
+	set(s, true)
+}
+
+// Sync reads the actual GL state into the tracker after code outside it
+// may have changed it.
+func Sync() {
+	current = drv.Query()
+}
+
+// Pass is a labeled render pass and the state it draws with.
+type Pass struct {
+	Name  string
+	State State
+}
+
+// Begin applies the pass's state inside a gldebug section and returns
+// the function that ends the section and restores the previous state.
+func (p Pass) Begin() func() {
+	endSection := gldebug.Section(p.Name)
+	restore := Push(p.State)
+	return func() {
+		restore()
+		endSection()
+	}
+}
+
+func set(s State, force bool) {
+	c := current
+	setCap := func(cap uint32, have, want bool) {
+		if force || have != want {
+			drv.SetCap(cap, want)
+		}
+	}
+	setCap(capDepthTest, c.DepthTest, s.DepthTest)
+	if force || c.DepthWrite != s.DepthWrite {
+		drv.DepthMask(s.DepthWrite)
+	}
+	if force || c.DepthFunc != s.DepthFunc {
+		drv.DepthFunc(s.DepthFunc)
+	}
+	setCap(capBlend, c.Blend, s.Blend)
+	if force || c.BlendSrc != s.BlendSrc || c.BlendDst != s.BlendDst {
+		drv.BlendFunc(s.BlendSrc, s.BlendDst)
+	}
+	setCap(capCull, c.Cull, s.Cull)
+	if force || c.CullFace != s.CullFace {
+		drv.CullFace(s.CullFace)
+	}
+	setCap(capOffsetFill, c.OffsetFill, s.OffsetFill)
+	setCap(capOffsetLine, c.OffsetLine, s.OffsetLine)
+	if force || c.OffsetFactor != s.OffsetFactor || c.OffsetUnits != s.OffsetUnits {
+		drv.PolygonOffset(s.OffsetFactor, s.OffsetUnits)
+	}
+	current = s
+}
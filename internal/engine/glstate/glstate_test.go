@@ -0,0 +1,143 @@
+package glstate
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// fakeDriver records the calls glstate makes and tracks the state they
+// leave GL in.
+type fakeDriver struct {
+	state State
+	calls []string
+}
+
+func (f *fakeDriver) SetCap(cap uint32, on bool) {
+	f.calls = append(f.calls, fmt.Sprintf("cap %d %v", cap, on))
+	switch cap {
+	case capDepthTest:
+		f.state.DepthTest = on
+	case capBlend:
+		f.state.Blend = on
+	case capCull:
+		f.state.Cull = on
+	case capOffsetFill:
+		f.state.OffsetFill = on
+	case capOffsetLine:
+		f.state.OffsetLine = on
+	}
+}
+
+func (f *fakeDriver) DepthMask(on bool) {
+	f.calls = append(f.calls, fmt.Sprintf("depthmask %v", on))
+	f.state.DepthWrite = on
+}
+
+func (f *fakeDriver) DepthFunc(fn uint32) {
+	f.calls = append(f.calls, fmt.Sprintf("depthfunc %#x", fn))
+	f.state.DepthFunc = fn
+}
+
+func (f *fakeDriver) BlendFunc(src, dst uint32) {
+	f.calls = append(f.calls, fmt.Sprintf("blendfunc %#x %#x", src, dst))
+	f.state.BlendSrc, f.state.BlendDst = src, dst
+}
+
+func (f *fakeDriver) CullFace(face uint32) {
+	f.calls = append(f.calls, fmt.Sprintf("cullface %#x", face))
+	f.state.CullFace = face
+}
+
+func (f *fakeDriver) PolygonOffset(factor, units float32) {
+	f.calls = append(f.calls, fmt.Sprintf("offset %v %v", factor, units))
+	f.state.OffsetFactor, f.state.OffsetUnits = factor, units
+}
+
+func (f *fakeDriver) Query() State {
+	return f.state
+}
+
+// useFake switches the package to a fake driver in the initial GL state
+// for one test.
+func useFake(t *testing.T) *fakeDriver {
+	t.Helper()
+	f := &fakeDriver{state: Initial}
+	oldDrv, oldCurrent := drv, current
+	drv, current = f, Initial
+	t.Cleanup(func() { drv, current = oldDrv, oldCurrent })
+	return f
+}
+
+func TestApplyOnlyChangesDiff(t *testing.T) {
+	f := useFake(t)
+	Reset(Default)
+	f.calls = nil
+
+	prev := Apply(Translucent)
+	if want := []string{"depthmask false"}; !reflect.DeepEqual(f.calls, want) {
+		t.Errorf("calls = %v, want %v", f.calls, want)
+	}
+	if prev != Default {
+		t.Errorf("Apply returned %+v, want Default", prev)
+	}
+
+	f.calls = nil
+	Apply(Translucent)
+	if len(f.calls) != 0 {
+		t.Errorf("reapplying issued %v, want nothing", f.calls)
+	}
+}
+
+func TestPushRestores(t *testing.T) {
+	f := useFake(t)
+	Reset(Default)
+
+	restore := Push(Decal)
+	if f.state != Decal {
+		t.Errorf("GL state = %+v, want Decal", f.state)
+	}
+	inner := Push(Overlay)
+	inner()
+	if f.state != Decal {
+		t.Errorf("after nested restore GL state = %+v, want Decal", f.state)
+	}
+	restore()
+	if f.state != Default || Current() != Default {
+		t.Errorf("after restore GL state = %+v, tracked %+v, want Default", f.state, Current())
+	}
+}
+
+func TestResetAndSync(t *testing.T) {
+	f := useFake(t)
+	Reset(Initial)
+	if len(f.calls) == 0 {
+		t.Error("Reset issued no calls")
+	}
+
+	// Something outside the tracker turns blending on
+	f.state.Blend = true
+	Sync()
+	if !Current().Blend {
+		t.Error("Sync missed the outside change")
+	}
+	f.calls = nil
+	Apply(Initial)
+	if want := []string{fmt.Sprintf("cap %d false", capBlend)}; !reflect.DeepEqual(f.calls, want) {
+		t.Errorf("calls = %v, want %v", f.calls, want)
+	}
+}
+
+func TestPassBegin(t *testing.T) {
+	f := useFake(t)
+	Reset(Default)
+
+	end := Pass{Name: "auras", State: Additive}.Begin()
+	if f.state != Additive {
+		t.Errorf("GL state in pass = %+v, want Additive", f.state)
+	}
+	end()
+	if f.state != Default {
+		t.Errorf("GL state after pass = %+v, want Default", f.state)
+	}
+}
@@ -15,7 +15,11 @@ func BuildMesh(rsm *formats.RSM, opts BuildOptions) *Mesh {
 
 	var vertices []Vertex
 	var indices []uint32
-	texGroups := make(map[int][]uint32)
+	type groupKey struct {
+		texIdx   int
+		selected bool
+	}
+	texGroups := make(map[groupKey][]uint32)
 
 	// Track bounding box
 	bounds := Bounds{
@@ -26,6 +30,10 @@ func BuildMesh(rsm *formats.RSM, opts BuildOptions) *Mesh {
 	// Process each node
 	for i := range rsm.Nodes {
 		node := &rsm.Nodes[i]
+		if opts.HiddenNodes[node.Name] {
+			continue
+		}
+		selected := opts.SelectedNode != "" && node.Name == opts.SelectedNode
 
 		// Build node transform matrix with animation time
 		nodeMatrix := BuildNodeMatrix(node, rsm, opts.AnimTimeMs)
@@ -120,13 +128,14 @@ func BuildMesh(rsm *formats.RSM, opts BuildOptions) *Mesh {
 			if int(face.TextureID) < len(node.TextureIDs) {
 				globalTexIdx = int(node.TextureIDs[face.TextureID])
 			}
-			texGroups[globalTexIdx] = append(texGroups[globalTexIdx],
+			key := groupKey{globalTexIdx, selected}
+			texGroups[key] = append(texGroups[key],
 				faceBaseIdx, faceBaseIdx+1, faceBaseIdx+2)
 
 			// If TwoSide or ForceAllTwoSided, add back face
 			if isTwoSided || opts.ForceAllTwoSided {
 				backFaceBaseIdx := addFaceVertices(!opts.ReverseWinding, true)
-				texGroups[globalTexIdx] = append(texGroups[globalTexIdx],
+				texGroups[key] = append(texGroups[key],
 					backFaceBaseIdx, backFaceBaseIdx+1, backFaceBaseIdx+2)
 			}
 		}
@@ -138,12 +147,13 @@ func BuildMesh(rsm *formats.RSM, opts BuildOptions) *Mesh {
 
 	// Build texture groups and final index buffer
 	var groups []TextureGroup
-	for texIdx, idxs := range texGroups {
+	for key, idxs := range texGroups {
 		if len(idxs) == 0 {
 			continue
 		}
 		groups = append(groups, TextureGroup{
-			TextureIdx: texIdx,
+			TextureIdx: key.texIdx,
+			Selected:   key.selected,
 			StartIndex: int32(len(indices)),
 			IndexCount: int32(len(idxs)),
 		})
@@ -0,0 +1,110 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/Faultbox/midgard-ro/pkg/formats"
+)
+
+// testRSM returns a model of one node: a one-sided triangle on texture 1,
+// a two-sided one on texture 0 and a degenerate one.
+func testRSM() *formats.RSM {
+	return &formats.RSM{
+		Textures: []string{"a.bmp", "b.bmp"},
+		Nodes: []formats.RSMNode{{
+			Name:       "root",
+			TextureIDs: []int32{1, 0},
+			Matrix:     [9]float32{1, 0, 0, 0, 1, 0, 0, 0, 1},
+			Scale:      [3]float32{1, 1, 1},
+			Vertices:   [][3]float32{{0, 0, 0}, {10, 0, 0}, {0, -4, 6}, {10, -4, 6}},
+			TexCoords:  []formats.RSMTexCoord{{U: 0, V: 0}, {U: 1, V: 0}, {U: 0, V: 1}},
+			Faces: []formats.RSMFace{
+				{VertexIDs: [3]uint16{0, 1, 2}, TexCoordIDs: [3]uint16{0, 1, 2}, TextureID: 0},
+				{VertexIDs: [3]uint16{1, 3, 2}, TexCoordIDs: [3]uint16{1, 2, 0}, TextureID: 1, TwoSide: 1},
+				{VertexIDs: [3]uint16{0, 0, 1}},
+			},
+		}},
+	}
+}
+
+func TestBuildMesh(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      BuildOptions
+		wantVerts int
+		wantByTex map[int]int32 // Index count per texture
+	}{
+		{"two-sided faces", BuildOptions{}, 9, map[int]int32{1: 3, 0: 6}},
+		{"all two-sided", BuildOptions{ForceAllTwoSided: true}, 12, map[int]int32{1: 6, 0: 6}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mesh := BuildMesh(testRSM(), tt.opts)
+			if mesh == nil {
+				t.Fatal("BuildMesh returned nil")
+			}
+			if len(mesh.Vertices) != tt.wantVerts {
+				t.Errorf("%d vertices, want %d", len(mesh.Vertices), tt.wantVerts)
+			}
+			got := map[int]int32{}
+			for _, g := range mesh.Groups {
+				got[g.TextureIdx] = g.IndexCount
+			}
+			for tex, n := range tt.wantByTex {
+				if got[tex] != n {
+					t.Errorf("texture %d has %d indices, want %d", tex, got[tex], n)
+				}
+			}
+			// Y is flipped into the world's up
+			if mesh.Bounds.Min != [3]float32{0, 0, 0} || mesh.Bounds.Max != [3]float32{10, 4, 6} {
+				t.Errorf("Bounds = %+v", mesh.Bounds)
+			}
+		})
+	}
+
+	if mesh := BuildMesh(&formats.RSM{}, BuildOptions{}); mesh != nil {
+		t.Error("BuildMesh built a mesh of a model without nodes")
+	}
+}
+
+func TestCenterMeshXZ(t *testing.T) {
+	mesh := BuildMesh(testRSM(), BuildOptions{})
+	x, z := CenterMeshXZ(mesh.Vertices, &mesh.Bounds)
+	if x != 5 || z != 3 {
+		t.Errorf("centered by %v, %v, want 5, 3", x, z)
+	}
+	if mesh.Bounds.Min != [3]float32{-5, 0, -3} || mesh.Bounds.Max != [3]float32{5, 4, 3} {
+		t.Errorf("Bounds = %+v", mesh.Bounds)
+	}
+	if p := mesh.Vertices[0].Position; p != [3]float32{-5, 0, -3} {
+		t.Errorf("first vertex at %v", p)
+	}
+}
+
+func TestBuildMeshNodes(t *testing.T) {
+	rsm := testRSM()
+	child := rsm.Nodes[0]
+	child.Name, child.Parent = "child", "root"
+	rsm.Nodes = append(rsm.Nodes, child)
+
+	mesh := BuildMesh(rsm, BuildOptions{HiddenNodes: map[string]bool{"root": true}})
+	if len(mesh.Vertices) != 9 {
+		t.Errorf("%d vertices with root hidden, want the child's 9", len(mesh.Vertices))
+	}
+	if mesh := BuildMesh(rsm, BuildOptions{HiddenNodes: map[string]bool{"root": true, "child": true}}); mesh != nil {
+		t.Error("BuildMesh built a mesh with every node hidden")
+	}
+
+	mesh = BuildMesh(rsm, BuildOptions{SelectedNode: "child"})
+	var selected, unselected int32
+	for _, g := range mesh.Groups {
+		if g.Selected {
+			selected += g.IndexCount
+		} else {
+			unselected += g.IndexCount
+		}
+	}
+	if selected != 9 || unselected != 9 {
+		t.Errorf("%d selected and %d unselected indices, want 9 each", selected, unselected)
+	}
+}
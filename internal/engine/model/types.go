@@ -13,6 +13,7 @@ type TextureGroup struct {
 	TextureIdx int
 	StartIndex int32
 	IndexCount int32
+	Selected   bool // Triangles of BuildOptions.SelectedNode
 }
 
 // Mesh holds the complete model mesh data ready for GPU upload.
@@ -54,4 +55,9 @@ type BuildOptions struct {
 	ForceAllTwoSided bool
 	// AnimTimeMs is the animation time in milliseconds for animated models.
 	AnimTimeMs float32
+	// HiddenNodes names nodes whose faces are left out of the mesh.
+	HiddenNodes map[string]bool
+	// SelectedNode names a node whose faces are grouped apart, marked
+	// Selected, so they can be highlighted.
+	SelectedNode string
 }
@@ -0,0 +1,79 @@
+// Package pacing paces the frame loop: an optional frame rate cap, kept by
+// sleeping rather than relying on vsync, and throttling while the window
+// is in the background so a game left running doesn't drain a laptop's
+// battery.
+//
+// The loop asks Until before each frame and sleeps what it returns, then
+// calls Begin when the frame starts. Throttled frames still run the game's
+// update, so the network is serviced and keep-alives go out; when the
+// window comes back the next frame runs at once.
+package pacing
+
+import "time"
+
+// Background frame rates.
+const (
+	UnfocusedFPS = 10 // Visible but another window has focus
+	MinimizedFPS = 1
+)
+
+// PollInterval is the longest a loop sleeps at a time while minimized, so
+// it notices the window being restored.
+const PollInterval = 50 * time.Millisecond
+
+// Limits are the frame rate caps the settings step through, 0 for none.
+var Limits = []int{0, 30, 60, 120, 144, 240}
+
+// NextLimit returns the cap after limit in Limits, wrapping around. A cap
+// not in the list steps to none.
+func NextLimit(limit int) int {
+	for i, l := range Limits {
+		if l == limit {
+			return Limits[(i+1)%len(Limits)]
+		}
+	}
+	return Limits[0]
+}
+
+// Window is the state of the window that decides the pace.
+type Window struct {
+	Focused   bool
+	Minimized bool
+}
+
+// Pacer decides when the next frame runs.
+type Pacer struct {
+	Limit    int  // Frame rate cap, 0 for none
+	Throttle bool // Slow down while the window is unfocused or minimized
+
+	last time.Time // When the last frame started
+}
+
+// Interval returns the shortest time between frames for a window, 0 if
+// frames run as fast as the display allows.
+func (p *Pacer) Interval(w Window) time.Duration {
+	switch {
+	case p.Throttle && w.Minimized:
+		return time.Second / MinimizedFPS
+	case p.Throttle && !w.Focused:
+		return time.Second / UnfocusedFPS
+	case p.Limit > 0:
+		return time.Second / time.Duration(p.Limit)
+	}
+	return 0
+}
+
+// Until returns how long from now the next frame is due, 0 or less if it
+// is. It goes by the window as it is now, so a window regaining focus
+// needn't wait out the background pace.
+func (p *Pacer) Until(now time.Time, w Window) time.Duration {
+	if p.last.IsZero() {
+		return 0
+	}
+	return p.last.Add(p.Interval(w)).Sub(now)
+}
+
+// Begin records that a frame started at now.
+func (p *Pacer) Begin(now time.Time) {
+	p.last = now
+}
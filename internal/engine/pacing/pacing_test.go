@@ -0,0 +1,56 @@
+package pacing
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPacer(t *testing.T) {
+	focused := Window{Focused: true}
+	unfocused := Window{}
+	minimized := Window{Minimized: true}
+
+	start := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	p := Pacer{Limit: 50, Throttle: true}
+	if d := p.Until(start, focused); d > 0 {
+		t.Errorf("first frame waits %v", d)
+	}
+	p.Begin(start)
+
+	tests := []struct {
+		name  string
+		after time.Duration
+		w     Window
+		want  time.Duration
+	}{
+		{"capped", 5 * time.Millisecond, focused, 15 * time.Millisecond},
+		{"unfocused", 5 * time.Millisecond, unfocused, 95 * time.Millisecond},
+		{"minimized", 5 * time.Millisecond, minimized, 995 * time.Millisecond},
+		// Coming back doesn't wait out the background pace
+		{"refocused", 500 * time.Millisecond, focused, -480 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := p.Until(start.Add(tt.after), tt.w); got != tt.want {
+			t.Errorf("%s: Until = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// No cap, no throttling: frames are always due
+	p.Limit, p.Throttle = 0, false
+	for _, w := range []Window{focused, unfocused, minimized} {
+		if got := p.Interval(w); got != 0 {
+			t.Errorf("unpaced Interval(%+v) = %v", w, got)
+		}
+	}
+}
+
+func TestNextLimit(t *testing.T) {
+	for i, l := range Limits {
+		if got, want := NextLimit(l), Limits[(i+1)%len(Limits)]; got != want {
+			t.Errorf("NextLimit(%d) = %d, want %d", l, got, want)
+		}
+	}
+	if got := NextLimit(75); got != 0 {
+		t.Errorf("NextLimit(75) = %d, want 0", got)
+	}
+}
@@ -0,0 +1,27 @@
+package perf
+
+import "github.com/go-gl/gl/v4.1-core/gl"
+
+// glDriver times passes with GL_TIMESTAMP queries, core since OpenGL 3.3.
+// Unlike GL_TIME_ELAPSED queries they may nest.
+type glDriver struct{}
+
+func (glDriver) NewQuery() uint32 {
+	var q uint32
+	gl.GenQueries(1, &q)
+	return q
+}
+
+func (glDriver) Timestamp(query uint32) {
+	gl.QueryCounter(query, gl.TIMESTAMP)
+}
+
+func (glDriver) Result(query uint32) uint64 {
+	var ns uint64
+	gl.GetQueryObjectui64v(query, gl.QUERY_RESULT, &ns)
+	return ns
+}
+
+func (glDriver) DeleteQuery(query uint32) {
+	gl.DeleteQueries(1, &query)
+}
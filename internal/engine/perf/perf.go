@@ -0,0 +1,157 @@
+// Package perf measures rendering performance: how long labeled render
+// passes take on the GPU, and the frame-time summaries the benchmark mode
+// reports.
+//
+// Rendering code wraps each pass:
+//
+//	defer perf.Pass("terrain")()
+//
+// When timing is on, a pass records GPU timestamps at its start and end.
+// The GPU runs behind the CPU, so EndFrame reads results back a few
+// frames later rather than stalling the pipeline. When it is off,
+// passes cost a function call.
+//
+// Passes issue timestamp queries into the current GL context, and the
+// recorded frames are unlocked package state, so Pass, EndFrame and Flush
+// must run on the goroutine rendering the frames they time.
+package perf
+
+import "time"
+
+// Latency is how many frames are timed at once: a frame's timing is read
+// back Latency-1 frames after it's recorded, by when the GPU has long
+// finished it and reading doesn't stall.
+const Latency = 4
+
+// Frame is one frame's GPU time by pass. A pass run more than once in a
+// frame, e.g. for a second camera, adds up.
+type Frame map[string]time.Duration
+
+// driver is the part of GL perf talks to, faked in tests.
+type driver interface {
+	NewQuery() uint32
+	Timestamp(query uint32)
+	Result(query uint32) uint64 // Nanoseconds; waits for the GPU if needed
+	DeleteQuery(query uint32)
+}
+
+// mark is a pass recorded in a frame: its name and its start and end
+// timestamp queries.
+type mark struct {
+	name       string
+	begin, end uint32
+}
+
+var (
+	enabled bool
+	drv     driver
+	frames  [Latency][]mark // Ring of recorded frames
+	current int             // Frame being recorded
+	free    []uint32        // Queries ready for reuse
+)
+
+// Start turns GPU timing on. Call it after gl.Init.
+func Start() {
+	start(glDriver{})
+}
+
+func start(d driver) {
+	drv = d
+	enabled = true
+	current = 0
+	for i := range frames {
+		frames[i] = frames[i][:0]
+	}
+}
+
+// Stop turns GPU timing off and releases its queries.
+func Stop() {
+	if !enabled {
+		return
+	}
+	for i := range frames {
+		for _, m := range frames[i] {
+			free = append(free, m.begin, m.end)
+		}
+		frames[i] = frames[i][:0]
+	}
+	for _, q := range free {
+		drv.DeleteQuery(q)
+	}
+	free = free[:0]
+	enabled = false
+}
+
+// Enabled reports whether GPU timing is on.
+func Enabled() bool {
+	return enabled
+}
+
+// Pass starts timing a labeled pass and returns the function ending it.
+// Passes may nest.
+func Pass(name string) func() {
+	if !enabled {
+		return func() {}
+	}
+	m := mark{name: name, begin: query()}
+	drv.Timestamp(m.begin)
+	return func() {
+		m.end = query()
+		drv.Timestamp(m.end)
+		frames[current] = append(frames[current], m)
+	}
+}
+
+// query returns a free timestamp query.
+func query() uint32 {
+	if n := len(free); n > 0 {
+		q := free[n-1]
+		free = free[:n-1]
+		return q
+	}
+	return drv.NewQuery()
+}
+
+// EndFrame finishes recording the current frame and returns the timing of
+// the frame recorded Latency-1 frames before it, once there is one.
+func EndFrame() (Frame, bool) {
+	if !enabled {
+		return nil, false
+	}
+	current = (current + 1) % Latency
+	if len(frames[current]) == 0 {
+		return nil, false
+	}
+	f := collect(current)
+	return f, true
+}
+
+// Flush reads back every frame still in flight, oldest first, for the end
+// of a measurement. It waits for the GPU to finish them.
+func Flush() []Frame {
+	if !enabled {
+		return nil
+	}
+	var out []Frame
+	for i := 1; i <= Latency; i++ {
+		slot := (current + i) % Latency
+		if len(frames[slot]) > 0 {
+			out = append(out, collect(slot))
+		}
+	}
+	return out
+}
+
+// collect reads back a recorded frame and frees its queries.
+func collect(slot int) Frame {
+	f := make(Frame)
+	for _, m := range frames[slot] {
+		begin, end := drv.Result(m.begin), drv.Result(m.end)
+		if end > begin {
+			f[m.name] += time.Duration(end - begin)
+		}
+		free = append(free, m.begin, m.end)
+	}
+	frames[slot] = frames[slot][:0]
+	return f
+}
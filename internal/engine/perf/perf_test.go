@@ -0,0 +1,154 @@
+package perf
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeDriver hands out queries and stamps them from a clock the test
+// advances.
+type fakeDriver struct {
+	now     uint64
+	stamps  map[uint32]uint64
+	next    uint32
+	deleted int
+}
+
+func (f *fakeDriver) NewQuery() uint32 {
+	f.next++
+	return f.next
+}
+
+func (f *fakeDriver) Timestamp(q uint32) {
+	f.stamps[q] = f.now
+}
+
+func (f *fakeDriver) Result(q uint32) uint64 {
+	return f.stamps[q]
+}
+
+func (f *fakeDriver) DeleteQuery(uint32) {
+	f.deleted++
+}
+
+// useFake starts timing over a fake driver for one test.
+func useFake(t *testing.T) *fakeDriver {
+	t.Helper()
+	f := &fakeDriver{stamps: make(map[uint32]uint64)}
+	start(f)
+	t.Cleanup(func() {
+		Stop()
+		drv = nil
+	})
+	return f
+}
+
+// runFrame records a frame with a terrain pass of n ms holding a nested
+// decals pass of 1 ms, and a second terrain pass of 1 ms.
+func runFrame(f *fakeDriver, n uint64) (Frame, bool) {
+	const ms = uint64(time.Millisecond)
+	endTerrain := Pass("terrain")
+	f.now += ms
+	endDecals := Pass("decals")
+	f.now += ms
+	endDecals()
+	f.now += (n - 2) * ms
+	endTerrain()
+
+	end := Pass("terrain")
+	f.now += ms
+	end()
+	return EndFrame()
+}
+
+func TestPassLatency(t *testing.T) {
+	f := useFake(t)
+	for i := 1; i < Latency; i++ {
+		if _, ok := runFrame(f, 5); ok {
+			t.Fatalf("frame %d returned timing before Latency frames", i)
+		}
+	}
+	got, ok := runFrame(f, 5)
+	if !ok {
+		t.Fatal("no timing after Latency frames")
+	}
+	want := Frame{"terrain": 6 * time.Millisecond, "decals": time.Millisecond}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("frame = %v, want %v", got, want)
+	}
+
+	// Queries are reused rather than created every frame
+	created := f.next
+	for range 10 {
+		runFrame(f, 5)
+	}
+	if f.next != created {
+		t.Errorf("created %d more queries in steady state", f.next-created)
+	}
+}
+
+func TestFlush(t *testing.T) {
+	f := useFake(t)
+	runFrame(f, 3)
+	runFrame(f, 4)
+	got := Flush()
+	if len(got) != 2 || got[0]["terrain"] != 4*time.Millisecond || got[1]["terrain"] != 5*time.Millisecond {
+		t.Errorf("Flush() = %v, want the 4 ms frame then the 5 ms one", got)
+	}
+	if more := Flush(); len(more) != 0 {
+		t.Errorf("second Flush() = %v, want nothing", more)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	f := useFake(t)
+	runFrame(f, 3)
+	Stop()
+	if f.deleted != int(f.next) {
+		t.Errorf("Stop() deleted %d of %d queries", f.deleted, f.next)
+	}
+	Pass("terrain")()
+	if _, ok := EndFrame(); ok || Enabled() {
+		t.Errorf("timing while stopped")
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	var samples []time.Duration
+	for i := 100; i >= 1; i-- {
+		samples = append(samples, time.Duration(i)*time.Millisecond)
+	}
+	got := Summarize(samples)
+	want := Summary{P50: 50, P95: 95, Mean: 50.5, Max: 100}
+	if got != want {
+		t.Errorf("Summarize() = %+v, want %+v", got, want)
+	}
+	if samples[0] != 100*time.Millisecond {
+		t.Errorf("Summarize() sorted its input")
+	}
+
+	if got := Summarize([]time.Duration{3 * time.Millisecond}); got != (Summary{3, 3, 3, 3}) {
+		t.Errorf("one sample: %+v", got)
+	}
+	if got := Summarize(nil); got != (Summary{}) {
+		t.Errorf("no samples: %+v", got)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	var r Recorder
+	r.AddFrame(10 * time.Millisecond)
+	r.AddFrame(20 * time.Millisecond)
+	r.AddGPU(Frame{"terrain": 4 * time.Millisecond, "models": 2 * time.Millisecond})
+	r.AddGPU(Frame{"terrain": 6 * time.Millisecond})
+
+	rep := Report{Map: "prontera"}
+	r.Fill(&rep)
+	if rep.Frames != 2 || rep.Frame.Max != 20 {
+		t.Errorf("frames = %d, summary %+v", rep.Frames, rep.Frame)
+	}
+	if rep.Passes["terrain"].Mean != 5 || rep.Passes["models"].Max != 2 {
+		t.Errorf("passes = %+v", rep.Passes)
+	}
+}
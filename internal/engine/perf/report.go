@@ -0,0 +1,88 @@
+package perf
+
+import (
+	"slices"
+	"time"
+)
+
+// Summary is the distribution of a set of timings, in milliseconds.
+type Summary struct {
+	P50  float64 `json:"p50"`
+	P95  float64 `json:"p95"`
+	Mean float64 `json:"mean"`
+	Max  float64 `json:"max"`
+}
+
+// Summarize returns the distribution of samples. Percentiles are nearest
+// rank, so they are always a measured time.
+func Summarize(samples []time.Duration) Summary {
+	if len(samples) == 0 {
+		return Summary{}
+	}
+	sorted := slices.Clone(samples)
+	slices.Sort(sorted)
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	return Summary{
+		P50:  ms(percentile(sorted, 50)),
+		P95:  ms(percentile(sorted, 95)),
+		Mean: ms(total / time.Duration(len(sorted))),
+		Max:  ms(sorted[len(sorted)-1]),
+	}
+}
+
+// percentile returns the nearest-rank p-th percentile of sorted samples.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100 // ceil(p/100 * n)
+	return sorted[max(rank, 1)-1]
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// Report is the result of a benchmark run, written as JSON so runs can be
+// compared across commits.
+type Report struct {
+	Map      string             `json:"map"`
+	Frames   int                `json:"frames"`
+	Width    int                `json:"width"`
+	Height   int                `json:"height"`
+	GPU      string             `json:"gpu"`
+	Revision string             `json:"revision,omitempty"` // VCS revision the client was built from
+	Frame    Summary            `json:"frame_ms"`           // CPU time between frames
+	Passes   map[string]Summary `json:"passes_ms"`          // GPU time by pass
+}
+
+// Recorder collects frame and pass times over a benchmark run.
+type Recorder struct {
+	frames []time.Duration
+	passes map[string][]time.Duration
+}
+
+// AddFrame records the time one frame took.
+func (r *Recorder) AddFrame(d time.Duration) {
+	r.frames = append(r.frames, d)
+}
+
+// AddGPU records a frame's GPU pass times.
+func (r *Recorder) AddGPU(f Frame) {
+	if r.passes == nil {
+		r.passes = make(map[string][]time.Duration)
+	}
+	for name, d := range f {
+		r.passes[name] = append(r.passes[name], d)
+	}
+}
+
+// Fill sets the report's frame and pass summaries.
+func (r *Recorder) Fill(rep *Report) {
+	rep.Frames = len(r.frames)
+	rep.Frame = Summarize(r.frames)
+	rep.Passes = make(map[string]Summary, len(r.passes))
+	for name, samples := range r.passes {
+		rep.Passes[name] = Summarize(samples)
+	}
+}
@@ -64,6 +64,18 @@ func ScreenToRay(screenX, screenY, viewportW, viewportH float32, invViewProj mat
 	return Ray{Origin: origin, Direction: dir}
 }
 
+// WorldToScreen projects a world-space point to screen pixel coordinates,
+// the inverse of ScreenToRay. ok is false for points behind the camera.
+func WorldToScreen(p [3]float32, viewportW, viewportH float32, viewProj math.Mat4) (screenX, screenY float32, ok bool) {
+	clip := viewProj.MulVec4(math.Vec4{p[0], p[1], p[2], 1.0})
+	if clip[3] <= 0 {
+		return 0, 0, false
+	}
+	ndcX := clip[0] / clip[3]
+	ndcY := clip[1] / clip[3]
+	return (ndcX + 1.0) * 0.5 * viewportW, (1.0 - ndcY) * 0.5 * viewportH, true
+}
+
 // IntersectPlaneY intersects a ray with a horizontal plane at the given Y level.
 // Returns the intersection point (X, Z) and whether the intersection is valid.
 func (r Ray) IntersectPlaneY(planeY float32) (x, z float32, ok bool) {
@@ -154,6 +166,50 @@ func (r Ray) IntersectAABB(box AABB) (t float32, hit bool) {
 	return tmin, true
 }
 
+// IntersectBillboard tests ray intersection with a camera-facing quad whose
+// bottom edge is centred on bottom and which extends width along right and
+// height along up (both unit vectors). Returns the distance to the hit.
+func (r Ray) IntersectBillboard(bottom, right, up [3]float32, width, height float32) (t float32, hit bool) {
+	normal := cross(right, up)
+	denom := dot(r.Direction, normal)
+	if gomath.Abs(float64(denom)) < 1e-6 {
+		return 0, false // Ray parallel to the quad
+	}
+
+	toQuad := sub(bottom, r.Origin)
+	t = dot(toQuad, normal) / denom
+	if t < 0 {
+		return 0, false // Quad behind ray origin
+	}
+
+	p := [3]float32{
+		r.Origin[0] + t*r.Direction[0] - bottom[0],
+		r.Origin[1] + t*r.Direction[1] - bottom[1],
+		r.Origin[2] + t*r.Direction[2] - bottom[2],
+	}
+	x, y := dot(p, right), dot(p, up)
+	if x < -width/2 || x > width/2 || y < 0 || y > height {
+		return 0, false
+	}
+	return t, true
+}
+
+func dot(a, b [3]float32) float32 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
+
+func sub(a, b [3]float32) [3]float32 {
+	return [3]float32{a[0] - b[0], a[1] - b[1], a[2] - b[2]}
+}
+
+func cross(a, b [3]float32) [3]float32 {
+	return [3]float32{
+		a[1]*b[2] - a[2]*b[1],
+		a[2]*b[0] - a[0]*b[2],
+		a[0]*b[1] - a[1]*b[0],
+	}
+}
+
 // NewAABB creates an AABB from min and max corners, handling negative scales.
 func NewAABB(minX, minY, minZ, maxX, maxY, maxZ float32) AABB {
 	box := AABB{
@@ -0,0 +1,36 @@
+package picking
+
+import "testing"
+
+func TestIntersectBillboard(t *testing.T) {
+	// A 10x4 board standing on (0, 20, 0), facing +Z
+	bottom := [3]float32{0, 20, 0}
+	right := [3]float32{1, 0, 0}
+	up := [3]float32{0, 1, 0}
+
+	tests := []struct {
+		name  string
+		ray   Ray
+		hit   bool
+		wantT float32
+	}{
+		{"centre", Ray{Origin: [3]float32{0, 22, 50}, Direction: [3]float32{0, 0, -1}}, true, 50},
+		{"right edge", Ray{Origin: [3]float32{4.9, 20.1, 10}, Direction: [3]float32{0, 0, -1}}, true, 10},
+		{"past the side", Ray{Origin: [3]float32{5.5, 22, 50}, Direction: [3]float32{0, 0, -1}}, false, 0},
+		{"below the bottom", Ray{Origin: [3]float32{0, 19, 50}, Direction: [3]float32{0, 0, -1}}, false, 0},
+		{"above the top", Ray{Origin: [3]float32{0, 24.5, 50}, Direction: [3]float32{0, 0, -1}}, false, 0},
+		{"behind the origin", Ray{Origin: [3]float32{0, 22, 50}, Direction: [3]float32{0, 0, 1}}, false, 0},
+		{"parallel", Ray{Origin: [3]float32{-20, 22, 0}, Direction: [3]float32{1, 0, 0}}, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, hit := tt.ray.IntersectBillboard(bottom, right, up, 10, 4)
+			if hit != tt.hit {
+				t.Fatalf("hit = %v, want %v", hit, tt.hit)
+			}
+			if hit && got != tt.wantT {
+				t.Errorf("t = %v, want %v", got, tt.wantT)
+			}
+		})
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/go-gl/gl/v4.1-core/gl"
 
 	"github.com/Faultbox/midgard-ro/internal/engine/character"
+	"github.com/Faultbox/midgard-ro/internal/engine/glstate"
 	"github.com/Faultbox/midgard-ro/internal/engine/scene/shaders"
 	"github.com/Faultbox/midgard-ro/internal/engine/shader"
 	"github.com/Faultbox/midgard-ro/internal/engine/sprite"
@@ -49,6 +50,13 @@ type Renderer struct {
 
 	// Scale applied to (texturePixelsW, texturePixelsH) to get world units.
 	scale float32
+
+	// Sink lowers the billboard below the character's position, e.g. into
+	// water.
+	Sink float32
+
+	// Tint multiplies the billboard's colors, e.g. for a hit flash.
+	Tint [4]float32
 }
 
 // New creates a renderer with a procedural humanoid texture.
@@ -56,6 +64,7 @@ type Renderer struct {
 func New() (*Renderer, error) {
 	r := &Renderer{
 		scale: sprite.DefaultProceduralScale,
+		Tint:  [4]float32{1, 1, 1, 1},
 	}
 
 	// Compile sprite shader (same source scene.SpriteRenderer uses).
@@ -124,14 +133,13 @@ func (r *Renderer) Render(viewProj math.Mat4, char *entity.Character, camPosX, c
 	spriteW := float32(r.width) * r.scale
 	spriteH := float32(r.height) * r.scale
 
-	gl.Enable(gl.BLEND)
-	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	defer glstate.Push(glstate.Default)()
 	gl.UseProgram(r.program)
 
 	gl.UniformMatrix4fv(r.locViewProj, 1, false, &viewProj[0])
-	gl.Uniform3f(r.locWorldPos, char.RenderX, char.RenderY, char.RenderZ)
+	gl.Uniform3f(r.locWorldPos, char.RenderX, char.RenderY-r.Sink, char.RenderZ)
 	gl.Uniform2f(r.locSpriteSize, spriteW, spriteH)
-	gl.Uniform4f(r.locTint, 1.0, 1.0, 1.0, 1.0)
+	gl.Uniform4f(r.locTint, r.Tint[0], r.Tint[1], r.Tint[2], r.Tint[3])
 	gl.Uniform3f(r.locCamRight, right[0], right[1], right[2])
 	gl.Uniform3f(r.locCamUp, up[0], up[1], up[2])
 
@@ -142,8 +150,6 @@ func (r *Renderer) Render(viewProj math.Mat4, char *entity.Character, camPosX, c
 	gl.BindVertexArray(r.vao)
 	gl.DrawArrays(gl.TRIANGLE_STRIP, 0, 4)
 	gl.BindVertexArray(0)
-
-	gl.Disable(gl.BLEND)
 }
 
 // Destroy releases all GL resources owned by the renderer.
@@ -0,0 +1,223 @@
+package postfx
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/framebuffer"
+	"github.com/Faultbox/midgard-ro/internal/engine/glstate"
+	"github.com/Faultbox/midgard-ro/internal/engine/perf"
+	"github.com/Faultbox/midgard-ro/internal/engine/postfx/shaders"
+	"github.com/Faultbox/midgard-ro/internal/engine/shader"
+)
+
+// passState draws the full-screen passes: every pixel is replaced, so
+// there's nothing to depth test or blend.
+var passState = glstate.Opaque.With(func(s *glstate.State) {
+	s.DepthTest = false
+	s.DepthWrite = false
+})
+
+// Chain runs the post-processing passes over a scene texture.
+type Chain struct {
+	targets [2]*framebuffer.Framebuffer
+	last    *framebuffer.Framebuffer // Written by the last Apply, nil if it passed the scene through
+	vao     uint32                   // Empty; the vertex shader generates the triangle
+
+	grade         uint32
+	locGradeTex   int32
+	locLUT        int32
+	locUseLUT     int32
+	locLUTSize    int32
+	locGamma      int32
+	locBrightness int32
+
+	fxaa         uint32
+	locFXAATex   int32
+	locTexelSize int32
+
+	lut     uint32 // 3D color grading texture, 0 when none is loaded
+	lutSize int
+}
+
+// New creates a chain rendering at the given size.
+func New(width, height int32) (*Chain, error) {
+	c := &Chain{}
+	for i := range c.targets {
+		fb, err := framebuffer.New(width, height)
+		if err != nil {
+			c.Destroy()
+			return nil, fmt.Errorf("creating target: %w", err)
+		}
+		c.targets[i] = fb
+	}
+
+	var err error
+	c.grade, err = shader.CompileProgram(shaders.FullscreenVertexShader, shaders.GradeFragmentShader)
+	if err != nil {
+		c.Destroy()
+		return nil, fmt.Errorf("grade shader: %w", err)
+	}
+	c.locGradeTex = shader.GetUniform(c.grade, "uTexture")
+	c.locLUT = shader.GetUniform(c.grade, "uLUT")
+	c.locUseLUT = shader.GetUniform(c.grade, "uUseLUT")
+	c.locLUTSize = shader.GetUniform(c.grade, "uLUTSize")
+	c.locGamma = shader.GetUniform(c.grade, "uGamma")
+	c.locBrightness = shader.GetUniform(c.grade, "uBrightness")
+
+	c.fxaa, err = shader.CompileProgram(shaders.FullscreenVertexShader, shaders.FXAAFragmentShader)
+	if err != nil {
+		c.Destroy()
+		return nil, fmt.Errorf("fxaa shader: %w", err)
+	}
+	c.locFXAATex = shader.GetUniform(c.fxaa, "uTexture")
+	c.locTexelSize = shader.GetUniform(c.fxaa, "uTexelSize")
+
+	gl.GenVertexArrays(1, &c.vao)
+	return c, nil
+}
+
+// Apply runs the passes s enables over the scene texture src and returns
+// the texture to show: src itself when no pass is enabled.
+func (c *Chain) Apply(src uint32, s Settings) uint32 {
+	s = s.Clamped()
+	effects := s.effects(c.lut != 0)
+	c.last = nil
+	if len(effects) == 0 {
+		return src
+	}
+
+	gl.BindVertexArray(c.vao)
+	defer gl.BindVertexArray(0)
+
+	in := src
+	for i, e := range effects {
+		target := c.targets[i%2]
+		endTimer := perf.Pass(e.String())
+		endPass := glstate.Pass{Name: e.String(), State: passState}.Begin()
+		restore := target.BindWithViewport()
+		c.draw(e, in, s, target)
+		restore()
+		endPass()
+		endTimer()
+		in, c.last = target.ColorTexture(), target
+	}
+	return in
+}
+
+// draw runs one pass reading from texture in into the bound target.
+func (c *Chain) draw(e effect, in uint32, s Settings, target *framebuffer.Framebuffer) {
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, in)
+
+	switch e {
+	case effectGrade:
+		gl.UseProgram(c.grade)
+		gl.Uniform1i(c.locGradeTex, 0)
+		gl.Uniform1f(c.locGamma, s.Gamma)
+		gl.Uniform1f(c.locBrightness, s.Brightness)
+		useLUT := s.Grading && c.lut != 0
+		gl.Uniform1i(c.locUseLUT, boolInt(useLUT))
+		if useLUT {
+			gl.ActiveTexture(gl.TEXTURE1)
+			gl.BindTexture(gl.TEXTURE_3D, c.lut)
+			gl.Uniform1i(c.locLUT, 1)
+			gl.Uniform1f(c.locLUTSize, float32(c.lutSize))
+			gl.ActiveTexture(gl.TEXTURE0)
+		}
+	case effectFXAA:
+		gl.UseProgram(c.fxaa)
+		gl.Uniform1i(c.locFXAATex, 0)
+		w, h := target.Size()
+		gl.Uniform2f(c.locTexelSize, 1/float32(w), 1/float32(h))
+	}
+	gl.DrawArrays(gl.TRIANGLES, 0, 3)
+}
+
+// SetLUT loads a color grading lookup table in the strip layout: N slices
+// of NxN side by side, one per blue level, with red across and green down.
+// A nil image removes the table.
+func (c *Chain) SetLUT(img image.Image) error {
+	if img == nil {
+		c.deleteLUT()
+		return nil
+	}
+	size, texels, err := lutTexels(img)
+	if err != nil {
+		return err
+	}
+	c.deleteLUT()
+
+	gl.GenTextures(1, &c.lut)
+	gl.BindTexture(gl.TEXTURE_3D, c.lut)
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
+	gl.TexImage3D(gl.TEXTURE_3D, 0, gl.RGB8, int32(size), int32(size), int32(size), 0,
+		gl.RGB, gl.UNSIGNED_BYTE, gl.Ptr(texels))
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 4)
+	gl.TexParameteri(gl.TEXTURE_3D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_3D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	for _, wrap := range []uint32{gl.TEXTURE_WRAP_S, gl.TEXTURE_WRAP_T, gl.TEXTURE_WRAP_R} {
+		gl.TexParameteri(gl.TEXTURE_3D, wrap, gl.CLAMP_TO_EDGE)
+	}
+	gl.BindTexture(gl.TEXTURE_3D, 0)
+	c.lutSize = size
+	return nil
+}
+
+// HasLUT reports whether a color grading table is loaded.
+func (c *Chain) HasLUT() bool {
+	return c.lut != 0
+}
+
+// Output returns the framebuffer holding the last Apply's result, or nil
+// when it passed the scene through unchanged.
+func (c *Chain) Output() *framebuffer.Framebuffer {
+	return c.last
+}
+
+// Resize updates the render targets to the scene size.
+func (c *Chain) Resize(width, height int32) {
+	for _, fb := range c.targets {
+		fb.Resize(width, height)
+	}
+}
+
+// Destroy releases all OpenGL resources.
+func (c *Chain) Destroy() {
+	for i, fb := range c.targets {
+		if fb != nil {
+			fb.Destroy()
+			c.targets[i] = nil
+		}
+	}
+	c.last = nil
+	if c.grade != 0 {
+		gl.DeleteProgram(c.grade)
+		c.grade = 0
+	}
+	if c.fxaa != 0 {
+		gl.DeleteProgram(c.fxaa)
+		c.fxaa = 0
+	}
+	if c.vao != 0 {
+		gl.DeleteVertexArrays(1, &c.vao)
+		c.vao = 0
+	}
+	c.deleteLUT()
+}
+
+func (c *Chain) deleteLUT() {
+	if c.lut != 0 {
+		gl.DeleteTextures(1, &c.lut)
+		c.lut, c.lutSize = 0, 0
+	}
+}
+
+func boolInt(b bool) int32 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,100 @@
+// Package postfx runs screen-space post-processing over the rendered scene
+// before the UI is drawn on top: gamma and brightness (many RO maps are
+// dark on modern displays), color grading through a lookup table, and
+// FXAA. Each effect is a full-screen pass; the chain runs the enabled ones
+// in order, ping-ponging between two render targets.
+package postfx
+
+import (
+	"fmt"
+	"image"
+)
+
+// Gamma and brightness ranges offered in the settings.
+const (
+	MinGamma      = 0.5
+	MaxGamma      = 2.5
+	MinBrightness = 0.5
+	MaxBrightness = 2.0
+)
+
+// Settings are the post-processing choices.
+type Settings struct {
+	Gamma      float32 // Gamma correction; above 1 lifts dark tones, 1 leaves them
+	Brightness float32 // Multiplier on the final color, 1 leaves it unchanged
+	Grading    bool    // Apply the color grading LUT, when one is loaded
+	FXAA       bool    // Fast approximate anti-aliasing
+}
+
+// DefaultSettings leave the scene as rendered.
+var DefaultSettings = Settings{Gamma: 1, Brightness: 1}
+
+// Clamped returns s with gamma and brightness in their ranges. Zero
+// values, as from an old config file, become the defaults.
+func (s Settings) Clamped() Settings {
+	if s.Gamma == 0 {
+		s.Gamma = DefaultSettings.Gamma
+	}
+	if s.Brightness == 0 {
+		s.Brightness = DefaultSettings.Brightness
+	}
+	s.Gamma = max(MinGamma, min(s.Gamma, MaxGamma))
+	s.Brightness = max(MinBrightness, min(s.Brightness, MaxBrightness))
+	return s
+}
+
+// effect is a post-processing pass.
+type effect int
+
+const (
+	effectGrade effect = iota // Gamma, brightness and the LUT
+	effectFXAA
+)
+
+// String returns the pass name used for GL debug sections and timings.
+func (e effect) String() string {
+	switch e {
+	case effectGrade:
+		return "postfx grade"
+	case effectFXAA:
+		return "postfx fxaa"
+	default:
+		return "postfx"
+	}
+}
+
+// effects returns the passes s needs, in order. Grading runs first so
+// FXAA finds edges in the colors that are shown.
+func (s Settings) effects(hasLUT bool) []effect {
+	var out []effect
+	if s.Gamma != 1 || s.Brightness != 1 || (s.Grading && hasLUT) {
+		out = append(out, effectGrade)
+	}
+	if s.FXAA {
+		out = append(out, effectFXAA)
+	}
+	return out
+}
+
+// lutTexels converts a color grading LUT from the common strip layout to
+// the texels of a 3D texture, RGB with red varying fastest. The strip is
+// size slices of size×size laid side by side, one per blue level, each
+// with red across and green down; an identity strip maps every color to
+// itself.
+func lutTexels(img image.Image) (size int, texels []byte, err error) {
+	b := img.Bounds()
+	size = b.Dy()
+	if size < 2 || b.Dx() != size*size {
+		return 0, nil, fmt.Errorf("lut is %dx%d, want a strip of N slices of NxN", b.Dx(), b.Dy())
+	}
+	texels = make([]byte, 0, size*size*size*3)
+	for blue := range size {
+		for green := range size {
+			for red := range size {
+				r, g, bl, _ := img.At(b.Min.X+blue*size+red, b.Min.Y+green).RGBA()
+				texels = append(texels, byte(r>>8), byte(g>>8), byte(bl>>8))
+			}
+		}
+	}
+	return size, texels, nil
+}
@@ -0,0 +1,71 @@
+package postfx
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestSettingsClamped(t *testing.T) {
+	tests := []struct {
+		in, want Settings
+	}{
+		{Settings{}, DefaultSettings},
+		{Settings{Gamma: 1.8, Brightness: 1.2, FXAA: true}, Settings{Gamma: 1.8, Brightness: 1.2, FXAA: true}},
+		{Settings{Gamma: 9, Brightness: 0.1}, Settings{Gamma: MaxGamma, Brightness: MinBrightness}},
+	}
+	for _, tt := range tests {
+		if got := tt.in.Clamped(); got != tt.want {
+			t.Errorf("%+v.Clamped() = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSettingsEffects(t *testing.T) {
+	tests := []struct {
+		name   string
+		s      Settings
+		hasLUT bool
+		want   []effect
+	}{
+		{"defaults", DefaultSettings, true, nil},
+		{"gamma", Settings{Gamma: 1.4, Brightness: 1}, false, []effect{effectGrade}},
+		{"grading without lut", Settings{Gamma: 1, Brightness: 1, Grading: true}, false, nil},
+		{"grading", Settings{Gamma: 1, Brightness: 1, Grading: true}, true, []effect{effectGrade}},
+		{"fxaa last", Settings{Gamma: 1, Brightness: 1.5, FXAA: true}, false, []effect{effectGrade, effectFXAA}},
+	}
+	for _, tt := range tests {
+		if got := tt.s.effects(tt.hasLUT); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: effects = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLUTTexels(t *testing.T) {
+	// Identity strip of 2 slices of 2x2
+	const size = 2
+	img := image.NewRGBA(image.Rect(0, 0, size*size, size))
+	for b := range size {
+		for g := range size {
+			for r := range size {
+				img.Set(b*size+r, g, color.RGBA{uint8(r * 255), uint8(g * 255), uint8(b * 255), 255})
+			}
+		}
+	}
+	n, texels, err := lutTexels(img)
+	if err != nil || n != size {
+		t.Fatalf("lutTexels = %d, %v", n, err)
+	}
+	want := []byte{
+		0, 0, 0, 255, 0, 0, 0, 255, 0, 255, 255, 0, // Blue 0: red fastest, then green
+		0, 0, 255, 255, 0, 255, 0, 255, 255, 255, 255, 255,
+	}
+	if !reflect.DeepEqual(texels, want) {
+		t.Errorf("texels = %v, want %v", texels, want)
+	}
+
+	if _, _, err := lutTexels(image.NewRGBA(image.Rect(0, 0, 10, 4))); err == nil {
+		t.Error("lutTexels accepted a 10x4 image")
+	}
+}
@@ -0,0 +1,20 @@
+// Package shaders provides embedded GLSL shader sources for
+// post-processing.
+package shaders
+
+import _ "embed"
+
+// FullscreenVertexShader draws a triangle covering the screen.
+//
+//go:embed fullscreen.vert
+var FullscreenVertexShader string
+
+// GradeFragmentShader applies gamma, brightness and the color grading LUT.
+//
+//go:embed grade.frag
+var GradeFragmentShader string
+
+// FXAAFragmentShader applies fast approximate anti-aliasing.
+//
+//go:embed fxaa.frag
+var FXAAFragmentShader string
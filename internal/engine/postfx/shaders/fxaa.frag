@@ -0,0 +1,55 @@
+#version 410 core
+// FXAA after Timothy Lottes' FXAA 3.11 console version: blur along the
+// edge found from the luma of the neighbouring pixels.
+in vec2 vTexCoord;
+
+uniform sampler2D uTexture;
+uniform vec2 uTexelSize;
+
+out vec4 FragColor;
+
+const float SPAN_MAX = 8.0;
+const float REDUCE_MUL = 1.0 / 8.0;
+const float REDUCE_MIN = 1.0 / 128.0;
+
+float luma(vec3 c) {
+    return dot(c, vec3(0.299, 0.587, 0.114));
+}
+
+void main() {
+    vec3 rgbNW = texture(uTexture, vTexCoord + vec2(-1.0, -1.0) * uTexelSize).rgb;
+    vec3 rgbNE = texture(uTexture, vTexCoord + vec2(1.0, -1.0) * uTexelSize).rgb;
+    vec3 rgbSW = texture(uTexture, vTexCoord + vec2(-1.0, 1.0) * uTexelSize).rgb;
+    vec3 rgbSE = texture(uTexture, vTexCoord + vec2(1.0, 1.0) * uTexelSize).rgb;
+    vec3 rgbM = texture(uTexture, vTexCoord).rgb;
+
+    float lumaNW = luma(rgbNW);
+    float lumaNE = luma(rgbNE);
+    float lumaSW = luma(rgbSW);
+    float lumaSE = luma(rgbSE);
+    float lumaM = luma(rgbM);
+    float lumaMin = min(lumaM, min(min(lumaNW, lumaNE), min(lumaSW, lumaSE)));
+    float lumaMax = max(lumaM, max(max(lumaNW, lumaNE), max(lumaSW, lumaSE)));
+
+    vec2 dir = vec2(
+        -((lumaNW + lumaNE) - (lumaSW + lumaSE)),
+        (lumaNW + lumaSW) - (lumaNE + lumaSE));
+    float dirReduce = max((lumaNW + lumaNE + lumaSW + lumaSE) * 0.25 * REDUCE_MUL, REDUCE_MIN);
+    float rcpDirMin = 1.0 / (min(abs(dir.x), abs(dir.y)) + dirReduce);
+    dir = clamp(dir * rcpDirMin, vec2(-SPAN_MAX), vec2(SPAN_MAX)) * uTexelSize;
+
+    vec3 rgbA = 0.5 * (
+        texture(uTexture, vTexCoord + dir * (1.0 / 3.0 - 0.5)).rgb +
+        texture(uTexture, vTexCoord + dir * (2.0 / 3.0 - 0.5)).rgb);
+    vec3 rgbB = rgbA * 0.5 + 0.25 * (
+        texture(uTexture, vTexCoord + dir * -0.5).rgb +
+        texture(uTexture, vTexCoord + dir * 0.5).rgb);
+
+    // The wider sample strayed past the edge into other colors
+    float lumaB = luma(rgbB);
+    if (lumaB < lumaMin || lumaB > lumaMax) {
+        FragColor = vec4(rgbA, 1.0);
+    } else {
+        FragColor = vec4(rgbB, 1.0);
+    }
+}
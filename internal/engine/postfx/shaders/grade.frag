@@ -0,0 +1,27 @@
+#version 410 core
+in vec2 vTexCoord;
+
+uniform sampler2D uTexture;
+uniform sampler3D uLUT;
+uniform bool uUseLUT;
+uniform float uLUTSize;
+uniform float uGamma;
+uniform float uBrightness;
+
+out vec4 FragColor;
+
+void main() {
+    vec3 color = texture(uTexture, vTexCoord).rgb;
+
+    color = pow(max(color, vec3(0.0)), vec3(1.0 / uGamma));
+    color = clamp(color * uBrightness, 0.0, 1.0);
+
+    if (uUseLUT) {
+        // Sample texel centers so the ends of the range aren't blended
+        // with the clamped border
+        vec3 coord = color * ((uLUTSize - 1.0) / uLUTSize) + 0.5 / uLUTSize;
+        color = texture(uLUT, coord).rgb;
+    }
+
+    FragColor = vec4(color, 1.0);
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/go-gl/gl/v4.1-core/gl"
 
+	"github.com/Faultbox/midgard-ro/internal/engine/glstate"
 	"github.com/Faultbox/midgard-ro/internal/logger"
 )
 
@@ -53,8 +54,7 @@ func New(cfg Config) (*Renderer, error) {
 	)
 
 	// Setup default OpenGL state
-	gl.Enable(gl.DEPTH_TEST)
-	gl.DepthFunc(gl.LESS)
+	glstate.Reset(glstate.Opaque)
 	gl.ClearColor(0.1, 0.1, 0.15, 1.0) // Dark blue-gray background
 
 	// Create shader program
@@ -0,0 +1,174 @@
+package replay
+
+import (
+	"bufio"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"time"
+)
+
+// Player plays a replay file back.
+type Player struct {
+	file   *os.File
+	dec    *gob.Decoder
+	header Header
+
+	next    *Frame     // Frame to play next, nil at the end
+	pending []NetEvent // Traffic of the frames played, not yet read
+	conns   int        // Replay connections opened so far
+	frame   int        // Frames played
+}
+
+// Open opens a replay file.
+func Open(path string) (*Player, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open replay: %w", err)
+	}
+	r := bufio.NewReader(f)
+	head := make([]byte, len(magic))
+	if _, err := io.ReadFull(r, head); err != nil || string(head) != magic {
+		f.Close()
+		return nil, fmt.Errorf("open replay %s: %w", path, ErrFormat)
+	}
+	p := &Player{file: f, dec: gob.NewDecoder(r)}
+	if err := p.dec.Decode(&p.header); err != nil || p.header.Version != Version {
+		f.Close()
+		return nil, fmt.Errorf("open replay %s: %w", path, ErrFormat)
+	}
+	p.readNext()
+	return p, nil
+}
+
+// readNext reads the frame to play next. A recording cut short by a
+// crash ends at its last whole frame.
+func (p *Player) readNext() {
+	var f Frame
+	if err := p.dec.Decode(&f); err != nil {
+		p.next = nil
+		return
+	}
+	p.next = &f
+}
+
+// Header returns the recording's header.
+func (p *Player) Header() Header {
+	return p.header
+}
+
+// Input returns the input of the frame to play next, and false at the
+// end of the replay.
+func (p *Player) Input() (Input, bool) {
+	if p.next == nil {
+		return Input{}, false
+	}
+	return p.next.Input, true
+}
+
+// Advance plays the next frame: the traffic read during it becomes
+// readable on the replay connections. Returns false at the end of the
+// replay.
+func (p *Player) Advance() bool {
+	if p.next == nil {
+		return false
+	}
+	p.pending = append(p.pending, p.next.Net...)
+	p.frame++
+	p.readNext()
+	return true
+}
+
+// Frame returns the number of frames played.
+func (p *Player) Frame() int {
+	return p.frame
+}
+
+// Close closes the replay file.
+func (p *Player) Close() error {
+	return p.file.Close()
+}
+
+// Conn returns a connection standing in for the next one the recording
+// opened. Its reads give what the recorded one read, once the frame it
+// was read in has been played; its writes go nowhere.
+func (p *Player) Conn() net.Conn {
+	c := &replayConn{player: p, id: p.conns}
+	p.conns++
+	return c
+}
+
+// replayConn is a connection replaying a recorded one.
+type replayConn struct {
+	player *Player
+	id     int
+	closed bool
+}
+
+// Read returns the bytes pending for the connection, io.EOF where the
+// recorded connection failed, and a timeout when nothing is pending.
+func (c *replayConn) Read(b []byte) (int, error) {
+	if c.closed {
+		return 0, net.ErrClosed
+	}
+	p := c.player
+	n := 0
+	for i := 0; i < len(p.pending) && n < len(b); {
+		ev := &p.pending[i]
+		if ev.Conn != c.id {
+			i++
+			continue
+		}
+		if ev.Closed {
+			if n > 0 {
+				break
+			}
+			p.pending = append(p.pending[:i], p.pending[i+1:]...)
+			return 0, io.EOF
+		}
+		copied := copy(b[n:], ev.Data)
+		n += copied
+		if ev.Data = ev.Data[copied:]; len(ev.Data) == 0 {
+			p.pending = append(p.pending[:i], p.pending[i+1:]...)
+		}
+	}
+	if n == 0 {
+		return 0, os.ErrDeadlineExceeded
+	}
+	return n, nil
+}
+
+func (c *replayConn) Write(b []byte) (int, error) {
+	if c.closed {
+		return 0, net.ErrClosed
+	}
+	return len(b), nil
+}
+
+// Close drops what is still pending for the connection.
+func (c *replayConn) Close() error {
+	c.closed = true
+	p := c.player
+	kept := p.pending[:0]
+	for _, ev := range p.pending {
+		if ev.Conn != c.id {
+			kept = append(kept, ev)
+		}
+	}
+	p.pending = kept
+	return nil
+}
+
+func (c *replayConn) LocalAddr() net.Addr              { return replayAddr{} }
+func (c *replayConn) RemoteAddr() net.Addr             { return replayAddr{} }
+func (c *replayConn) SetDeadline(time.Time) error      { return nil }
+func (c *replayConn) SetReadDeadline(time.Time) error  { return nil }
+func (c *replayConn) SetWriteDeadline(time.Time) error { return nil }
+
+// replayAddr is the address of replay connections.
+type replayAddr struct{}
+
+func (replayAddr) Network() string { return "replay" }
+func (replayAddr) String() string  { return "replay" }
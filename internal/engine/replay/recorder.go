@@ -0,0 +1,93 @@
+package replay
+
+import (
+	"bufio"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+)
+
+// Recorder writes a replay file.
+type Recorder struct {
+	file  *os.File
+	w     *bufio.Writer
+	enc   *gob.Encoder
+	frame Frame
+	conns int // Connections opened so far
+}
+
+// Create starts a recording in path.
+func Create(path string, h Header) (*Recorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("create replay: %w", err)
+	}
+	r := &Recorder{file: f, w: bufio.NewWriter(f)}
+	r.enc = gob.NewEncoder(r.w)
+	h.Version = Version
+	if _, err := r.w.WriteString(magic); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("write replay header: %w", err)
+	}
+	if err := r.enc.Encode(h); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("write replay header: %w", err)
+	}
+	return r, nil
+}
+
+// SetInput sets the input of the current frame.
+func (r *Recorder) SetInput(in Input) {
+	r.frame.Input = in
+}
+
+// EndFrame writes the current frame and starts the next.
+func (r *Recorder) EndFrame() error {
+	err := r.enc.Encode(&r.frame)
+	if err == nil {
+		err = r.w.Flush()
+	}
+	r.frame = Frame{}
+	if err != nil {
+		return fmt.Errorf("write replay frame: %w", err)
+	}
+	return nil
+}
+
+// Close ends the recording.
+func (r *Recorder) Close() error {
+	err := r.w.Flush()
+	if closeErr := r.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Conn wraps a new server connection to record what is read from it.
+func (r *Recorder) Conn(conn net.Conn) net.Conn {
+	c := &recordConn{Conn: conn, rec: r, id: r.conns}
+	r.conns++
+	return c
+}
+
+// recordConn records its reads into the current frame.
+type recordConn struct {
+	net.Conn
+	rec *Recorder
+	id  int
+}
+
+func (c *recordConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		data := append([]byte(nil), b[:n]...)
+		c.rec.frame.Net = append(c.rec.frame.Net, NetEvent{Conn: c.id, Data: data})
+	}
+	var netErr net.Error
+	if err != nil && !(errors.As(err, &netErr) && netErr.Timeout()) {
+		c.rec.frame.Net = append(c.rec.frame.Net, NetEvent{Conn: c.id, Closed: true})
+	}
+	return n, err
+}
@@ -0,0 +1,69 @@
+// Package replay records a session frame by frame, the input and the
+// bytes the servers sent, and plays it back to reproduce a bug report
+// locally. Playback needs no server: replay connections stand in for the
+// recorded ones and give back, frame for frame, what was read from them.
+//
+// The game makes playback deterministic: the clock runs at the recorded
+// fixed step from the recorded start time, and the random source is
+// seeded like the recording's.
+//
+// A replay file is the magic string followed by a gob stream: the Header,
+// then one Frame per frame. Each frame is flushed as it ends, so a
+// recording interrupted by a crash keeps the frames up to it.
+package replay
+
+import (
+	"errors"
+	"time"
+)
+
+// magic starts every replay file.
+const magic = "MIDGARD-REPLAY\n"
+
+// Version is the file format version. Replays of other versions are
+// refused.
+const Version = 1
+
+// ErrFormat is returned when opening a file that isn't a replay of this
+// version.
+var ErrFormat = errors.New("not a replay file of this version")
+
+// Header describes a recording.
+type Header struct {
+	Version       int
+	Start         time.Time     // Clock time of the first frame
+	Step          time.Duration // Fixed time step between frames
+	Seed          uint64        // Random source seed
+	Width, Height int           // Window size, which the UI layout depends on
+}
+
+// Modifier key bits of Input.Mods.
+const (
+	ModCtrl uint8 = 1 << iota
+	ModShift
+	ModAlt
+	ModSuper
+)
+
+// Input is the input state during a frame.
+type Input struct {
+	MouseX, MouseY float32 // Relative to the window
+	Buttons        uint8   // Bit n set while mouse button n is down
+	WheelX, WheelY float32
+	Keys           []int32 // Keys held down
+	Mods           uint8   // Modifier keys held down, Mod* bits
+	Text           string  // Characters typed
+}
+
+// NetEvent is what a read from a server connection gave.
+type NetEvent struct {
+	Conn   int    // Connection, numbered in the order they were opened
+	Data   []byte // Bytes read
+	Closed bool   // The read failed, leaving the connection unusable
+}
+
+// Frame is a recorded frame.
+type Frame struct {
+	Input Input
+	Net   []NetEvent
+}
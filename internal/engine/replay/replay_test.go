@@ -0,0 +1,138 @@
+package replay
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// scriptConn is a server connection whose reads return scripted results,
+// one per read.
+type scriptConn struct {
+	net.Conn
+	reads []string // "" for a timeout
+}
+
+func (c *scriptConn) Read(b []byte) (int, error) {
+	if len(c.reads) == 0 {
+		return 0, io.EOF
+	}
+	data := c.reads[0]
+	c.reads = c.reads[1:]
+	if data == "" {
+		return 0, os.ErrDeadlineExceeded
+	}
+	return copy(b, data), nil
+}
+
+func TestRecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.replay")
+	header := Header{Start: time.Unix(1700000000, 0).UTC(), Step: time.Second / 60, Seed: 7, Width: 1280, Height: 720}
+	rec, err := Create(path, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Frame 0: a key down; the login server sends two packets
+	// Frame 1: nothing read, the login connection closes
+	// Frame 2: the char server connection sends its first bytes
+	login := rec.Conn(&scriptConn{reads: []string{"abc", "", "xyz"}})
+	char := rec.Conn(&scriptConn{reads: []string{"ch"}})
+	buf := make([]byte, 16)
+	inputs := []Input{
+		{MouseX: 10, MouseY: 20, Buttons: 1, Keys: []int32{546}, Mods: ModShift, Text: "a"},
+		{MouseX: 11, MouseY: 20, WheelY: -1},
+		{},
+	}
+	for i, in := range inputs {
+		rec.SetInput(in)
+		switch i {
+		case 0:
+			login.Read(buf)
+		case 1:
+			login.Read(buf)
+			login.Read(buf)
+			login.Read(buf)
+		case 2:
+			char.Read(buf)
+		}
+		if err := rec.EndFrame(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	header.Version = Version
+	if got := p.Header(); got != header {
+		t.Errorf("Header() = %+v, want %+v", got, header)
+	}
+
+	read := func(c net.Conn) (string, error) {
+		n, err := c.Read(buf)
+		return string(buf[:n]), err
+	}
+	loginReplay, charReplay := p.Conn(), p.Conn()
+	for i, want := range inputs {
+		in, ok := p.Input()
+		if !ok || !reflect.DeepEqual(in, want) {
+			t.Errorf("frame %d: Input() = %+v, %v, want %+v", i, in, ok, want)
+		}
+		// Nothing is readable before its frame is played
+		if i == 0 {
+			if _, err := read(loginReplay); !errors.Is(err, os.ErrDeadlineExceeded) {
+				t.Errorf("read before the frame: %v, want a timeout", err)
+			}
+		}
+		if !p.Advance() {
+			t.Fatalf("replay ended at frame %d", i)
+		}
+		switch i {
+		case 0:
+			if got, err := read(loginReplay); got != "abc" || err != nil {
+				t.Errorf("frame 0 read = %q, %v", got, err)
+			}
+		case 1:
+			if got, err := read(loginReplay); got != "xyz" || err != nil {
+				t.Errorf("frame 1 read = %q, %v", got, err)
+			}
+			if _, err := read(loginReplay); err != io.EOF {
+				t.Errorf("frame 1 read after close = %v, want EOF", err)
+			}
+			if _, err := read(charReplay); !errors.Is(err, os.ErrDeadlineExceeded) {
+				t.Errorf("char read before its traffic = %v", err)
+			}
+		case 2:
+			if got, err := read(charReplay); got != "ch" || err != nil {
+				t.Errorf("frame 2 read = %q, %v", got, err)
+			}
+		}
+	}
+	if _, ok := p.Input(); ok || p.Advance() {
+		t.Error("replay went on past its last frame")
+	}
+	if p.Frame() != len(inputs) {
+		t.Errorf("Frame() = %d, want %d", p.Frame(), len(inputs))
+	}
+}
+
+func TestOpenNotReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path); !errors.Is(err, ErrFormat) {
+		t.Errorf("Open() = %v, want ErrFormat", err)
+	}
+}
@@ -0,0 +1,33 @@
+// Package rng is the random source for game logic and effects. Drawing
+// from it rather than math/rand lets a replay, seeded like its recording,
+// make the same random choices.
+//
+// The source isn't locked, and draws from goroutines other than the one
+// running the frames would interleave differently on every run and break
+// replays anyway. Code off the frame loop uses math/rand/v2 instead.
+package rng
+
+import "math/rand/v2"
+
+// src is seeded from the runtime until Seed is called.
+var src = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+
+// Seed restarts the sequence from seed.
+func Seed(seed uint64) {
+	src = rand.New(rand.NewPCG(seed, seed))
+}
+
+// Float32 returns a number in [0, 1).
+func Float32() float32 {
+	return src.Float32()
+}
+
+// Range returns a number in [lo, hi).
+func Range(lo, hi float32) float32 {
+	return lo + src.Float32()*(hi-lo)
+}
+
+// IntN returns a number in [0, n). It panics if n <= 0.
+func IntN(n int) int {
+	return src.IntN(n)
+}
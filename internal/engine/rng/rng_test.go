@@ -0,0 +1,26 @@
+package rng
+
+import "testing"
+
+func TestSeed(t *testing.T) {
+	draw := func() [3]float32 {
+		return [3]float32{Float32(), Range(-2, 2), float32(IntN(100))}
+	}
+
+	Seed(42)
+	first := draw()
+	Seed(42)
+	if again := draw(); again != first {
+		t.Errorf("reseeded draws = %v, want %v", again, first)
+	}
+	Seed(43)
+	if other := draw(); other == first {
+		t.Error("a different seed repeated the draws")
+	}
+
+	for range 100 {
+		if v := Range(-2, 2); v < -2 || v >= 2 {
+			t.Fatalf("Range(-2, 2) = %v", v)
+		}
+	}
+}
@@ -0,0 +1,406 @@
+package scene
+
+import (
+	"fmt"
+	gomath "math"
+	"time"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/clock"
+	"github.com/Faultbox/midgard-ro/internal/engine/glstate"
+	"github.com/Faultbox/midgard-ro/internal/engine/scene/shaders"
+	"github.com/Faultbox/midgard-ro/internal/engine/shader"
+	"github.com/Faultbox/midgard-ro/pkg/math"
+)
+
+const (
+	// maxAuras caps the characters wearing an aura; Set ignores new auras
+	// past it.
+	maxAuras = 128
+
+	// auraFadeIn is how long a new aura takes to fade in, e.g. on reaching
+	// the level that brings it.
+	auraFadeIn = 500 * time.Millisecond
+
+	// auraLift raises ground rings off the ground against z-fighting.
+	auraLift = 0.3
+
+	// auraVertexFloats is position (3), UV (2), color (4) and shape (1).
+	auraVertexFloats = 10
+)
+
+// AuraStyles is a set of aura looks worn by one character. Styles stack,
+// each adding its own layers.
+type AuraStyles uint8
+
+const (
+	AuraBlue      AuraStyles = 1 << iota // Classic level aura: ground ring, light column, rising sparks
+	AuraGold                             // The level aura in gold
+	AuraSpirit                           // Violet spirits circling at chest height
+	AuraStarlight                        // Golden motes drifting up
+	AuraTeleport                         // Blue column of light, played once as a unit warps in
+)
+
+// auraShape selects how a quad is drawn; values match aura.frag.
+type auraShape int
+
+const (
+	auraShapeRing   auraShape = iota // Flat ring on the ground
+	auraShapeColumn                  // Soft vertical beam, fading upwards
+	auraShapeGlow                    // Round soft glow
+)
+
+// auraLayerKind is how a layer moves.
+type auraLayerKind int
+
+const (
+	auraRing   auraLayerKind = iota // Ground ring pulsing in size and brightness
+	auraColumn                      // Beam standing on the feet, facing the camera
+	auraRise                        // Motes rising from the ground and fading out
+	auraOrbit                       // Glows circling the body
+)
+
+// auraLayer is one part of an aura style.
+type auraLayer struct {
+	kind   auraLayerKind
+	color  [4]float32
+	radius float32 // Ring radius, beam half width, mote spread or orbit radius
+	height float32 // Beam height, how high motes rise, or orbit height
+	size   float32 // Half size of motes and orbiting glows
+	count  int     // Motes or orbiting glows
+	period float32 // Seconds per pulse, rise or orbit
+}
+
+// auraLayers are the layers of each style, drawn in order.
+var auraLayers = map[AuraStyles][]auraLayer{
+	AuraBlue: {
+		{kind: auraRing, color: [4]float32{0.45, 0.7, 1, 0.8}, radius: 9, period: 2},
+		{kind: auraColumn, color: [4]float32{0.5, 0.75, 1, 0.35}, radius: 6, height: 30},
+		{kind: auraRise, color: [4]float32{0.7, 0.9, 1, 0.9}, radius: 8, height: 28, size: 1.2, count: 12, period: 2.5},
+	},
+	AuraGold: {
+		{kind: auraRing, color: [4]float32{1, 0.8, 0.35, 0.8}, radius: 9, period: 2},
+		{kind: auraColumn, color: [4]float32{1, 0.85, 0.45, 0.35}, radius: 6, height: 30},
+		{kind: auraRise, color: [4]float32{1, 0.92, 0.6, 0.9}, radius: 8, height: 28, size: 1.2, count: 12, period: 2.5},
+	},
+	AuraSpirit: {
+		{kind: auraOrbit, color: [4]float32{0.75, 0.5, 1, 0.9}, radius: 7, height: 14, size: 2.5, count: 3, period: 3},
+	},
+	AuraStarlight: {
+		{kind: auraRise, color: [4]float32{1, 0.9, 0.5, 0.9}, radius: 6, height: 22, size: 1, count: 8, period: 3},
+	},
+	AuraTeleport: {
+		{kind: auraRing, color: [4]float32{0.4, 0.65, 1, 0.9}, radius: 7, period: 0.5},
+		{kind: auraColumn, color: [4]float32{0.45, 0.7, 1, 0.7}, radius: 5, height: 38},
+		{kind: auraRise, color: [4]float32{0.75, 0.9, 1, 1}, radius: 5, height: 36, size: 1.3, count: 16, period: 0.8},
+	},
+}
+
+// Aura is the idle effect around a character, such as the level 99 aura.
+type Aura struct {
+	Styles   AuraStyles
+	Position [3]float32 // World position of the character's feet
+}
+
+// auraEntry is a live aura.
+type auraEntry struct {
+	Aura
+	born time.Time
+	seen bool // Set since the last Sweep
+}
+
+// auraBurst is an aura played once, such as the teleport column.
+type auraBurst struct {
+	Aura
+	born     time.Time
+	duration time.Duration
+}
+
+// AuraRenderer draws character auras as layered, procedurally animated
+// quads with additive blending. All auras are batched into one draw.
+type AuraRenderer struct {
+	program     uint32
+	locViewProj int32
+
+	vao, vbo uint32
+	vboBytes int // Allocated size of vbo
+
+	auras    map[uint32]*auraEntry
+	bursts   []auraBurst
+	start    time.Time // Animation epoch
+	vertices []float32 // Batch scratch
+}
+
+// NewAuraRenderer creates an aura renderer.
+func NewAuraRenderer() (*AuraRenderer, error) {
+	program, err := shader.CompileProgram(shaders.AuraVertexShader, shaders.AuraFragmentShader)
+	if err != nil {
+		return nil, fmt.Errorf("aura shader: %w", err)
+	}
+	ar := &AuraRenderer{
+		program:     program,
+		locViewProj: shader.GetUniform(program, "uViewProj"),
+		auras:       make(map[uint32]*auraEntry),
+		start:       clock.Now(),
+	}
+
+	gl.GenVertexArrays(1, &ar.vao)
+	gl.BindVertexArray(ar.vao)
+	gl.GenBuffers(1, &ar.vbo)
+	gl.BindBuffer(gl.ARRAY_BUFFER, ar.vbo)
+
+	const stride = auraVertexFloats * 4
+	gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, stride, 0)
+	gl.EnableVertexAttribArray(0)
+	gl.VertexAttribPointerWithOffset(1, 2, gl.FLOAT, false, stride, 3*4)
+	gl.EnableVertexAttribArray(1)
+	gl.VertexAttribPointerWithOffset(2, 4, gl.FLOAT, false, stride, 5*4)
+	gl.EnableVertexAttribArray(2)
+	gl.VertexAttribPointerWithOffset(3, 1, gl.FLOAT, false, stride, 9*4)
+	gl.EnableVertexAttribArray(3)
+
+	gl.BindVertexArray(0)
+	return ar, nil
+}
+
+// Set adds or moves the aura with the given ID, such as the wearer's
+// entity ID. Auras not Set again before the next Sweep are removed, so
+// callers set the auras they see each frame and sweep the rest.
+func (ar *AuraRenderer) Set(id uint32, a Aura, now time.Time) {
+	if a.Styles == 0 {
+		ar.Remove(id)
+		return
+	}
+	e, ok := ar.auras[id]
+	if !ok {
+		if len(ar.auras) >= maxAuras {
+			return
+		}
+		e = &auraEntry{born: now}
+		ar.auras[id] = e
+	}
+	e.Aura = a
+	e.seen = true
+}
+
+// Sweep removes the auras not Set since the last Sweep.
+func (ar *AuraRenderer) Sweep() {
+	for id, e := range ar.auras {
+		if !e.seen {
+			delete(ar.auras, id)
+			continue
+		}
+		e.seen = false
+	}
+}
+
+// Remove removes an aura. Unknown IDs are ignored.
+func (ar *AuraRenderer) Remove(id uint32) {
+	delete(ar.auras, id)
+}
+
+// Burst plays an aura once at its position for d: it flares up, then
+// fades out over the second half. Bursts past maxAuras are ignored.
+func (ar *AuraRenderer) Burst(a Aura, d time.Duration, now time.Time) {
+	if a.Styles == 0 || d <= 0 || len(ar.bursts) >= maxAuras {
+		return
+	}
+	ar.bursts = append(ar.bursts, auraBurst{Aura: a, born: now, duration: d})
+}
+
+// pruneBursts removes the bursts that are over.
+func (ar *AuraRenderer) pruneBursts(now time.Time) {
+	live := ar.bursts[:0]
+	for _, b := range ar.bursts {
+		if now.Sub(b.born) < b.duration {
+			live = append(live, b)
+		}
+	}
+	clear(ar.bursts[len(live):])
+	ar.bursts = live
+}
+
+// burstFade returns the alpha of a burst of duration d at age: up over
+// the first tenth, full, then down over the second half.
+func burstFade(age, d time.Duration) float32 {
+	t := float32(age) / float32(d)
+	switch {
+	case t < 0 || t >= 1:
+		return 0
+	case t < 0.1:
+		return t / 0.1
+	case t > 0.5:
+		return (1 - t) / 0.5
+	}
+	return 1
+}
+
+// Clear removes every aura and burst.
+func (ar *AuraRenderer) Clear() {
+	clear(ar.auras)
+	ar.bursts = nil
+}
+
+// Count returns the number of auras.
+func (ar *AuraRenderer) Count() int {
+	return len(ar.auras)
+}
+
+// Render draws the auras depth-tested against the world but without
+// writing depth, so characters drawn afterwards stand inside them.
+func (ar *AuraRenderer) Render(viewProj, view math.Mat4) {
+	now := clock.Now()
+	ar.pruneBursts(now)
+	if len(ar.auras) == 0 && len(ar.bursts) == 0 || ar.vao == 0 {
+		return
+	}
+
+	t := float32(now.Sub(ar.start).Seconds())
+	camRight := [3]float32{view[0], view[4], view[8]}
+	camUp := [3]float32{view[1], view[5], view[9]}
+	ar.vertices = ar.vertices[:0]
+	for _, e := range ar.auras {
+		fade := min(float32(now.Sub(e.born))/float32(auraFadeIn), 1)
+		ar.vertices = appendAuraMesh(ar.vertices, &e.Aura, t, fade, camRight, camUp)
+	}
+	for i := range ar.bursts {
+		b := &ar.bursts[i]
+		age := now.Sub(b.born)
+		ar.vertices = appendAuraMesh(ar.vertices, &b.Aura, float32(age.Seconds()), burstFade(age, b.duration), camRight, camUp)
+	}
+	if len(ar.vertices) == 0 {
+		return
+	}
+
+	gl.BindBuffer(gl.ARRAY_BUFFER, ar.vbo)
+	size := len(ar.vertices) * 4
+	if size > ar.vboBytes {
+		gl.BufferData(gl.ARRAY_BUFFER, size, gl.Ptr(ar.vertices), gl.DYNAMIC_DRAW)
+		ar.vboBytes = size
+	} else {
+		gl.BufferSubData(gl.ARRAY_BUFFER, 0, size, gl.Ptr(ar.vertices))
+	}
+
+	gl.UseProgram(ar.program)
+	gl.UniformMatrix4fv(ar.locViewProj, 1, false, &viewProj[0])
+
+	// Additive: overlapping layers and auras brighten like light
+	defer glstate.Push(glstate.Additive)()
+
+	gl.BindVertexArray(ar.vao)
+	gl.DrawArrays(gl.TRIANGLES, 0, int32(len(ar.vertices)/auraVertexFloats))
+	gl.BindVertexArray(0)
+}
+
+// appendAuraMesh appends the quads of an aura's layers at animation time t
+// (seconds) to verts. fade scales every layer's alpha. Motes and glows
+// face the camera along camRight and camUp; beams only turn around the
+// vertical axis.
+func appendAuraMesh(verts []float32, a *Aura, t, fade float32, camRight, camUp [3]float32) []float32 {
+	feet := a.Position
+	for style := AuraBlue; style <= AuraTeleport; style <<= 1 {
+		if a.Styles&style == 0 {
+			continue
+		}
+		for i := range auraLayers[style] {
+			l := &auraLayers[style][i]
+			color := l.color
+			color[3] *= fade
+			switch l.kind {
+			case auraRing:
+				pulse := float32(gomath.Sin(float64(2 * gomath.Pi * t / l.period)))
+				r := l.radius * (1 + 0.08*pulse)
+				color[3] *= 0.75 + 0.25*pulse
+				c := [3]float32{feet[0], feet[1] + auraLift, feet[2]}
+				verts = appendAuraQuad(verts, c, [3]float32{r, 0, 0}, [3]float32{0, 0, r}, color, auraShapeRing)
+
+			case auraColumn:
+				right := horizontal(camRight, l.radius)
+				c := [3]float32{feet[0], feet[1] + l.height/2, feet[2]}
+				verts = appendAuraQuad(verts, c, right, [3]float32{0, l.height / 2, 0}, color, auraShapeColumn)
+
+			case auraRise:
+				for k := range l.count {
+					// Golden-angle spread so motes don't line up
+					phase := fract(t/l.period + float32(k)*0.618)
+					angle := float64(k) * 2.39996
+					spread := l.radius * (0.35 + 0.65*fract(float32(k)*0.377))
+					p := [3]float32{
+						feet[0] + float32(gomath.Cos(angle))*spread,
+						feet[1] + phase*l.height,
+						feet[2] + float32(gomath.Sin(angle))*spread,
+					}
+					c := color
+					c[3] *= float32(gomath.Sin(gomath.Pi * float64(phase)))
+					verts = appendAuraQuad(verts, p, scale3(camRight, l.size), scale3(camUp, l.size), c, auraShapeGlow)
+				}
+
+			case auraOrbit:
+				for k := range l.count {
+					angle := 2 * gomath.Pi * float64(t/l.period+float32(k)/float32(l.count))
+					bob := float32(gomath.Sin(2*angle + float64(k)))
+					p := [3]float32{
+						feet[0] + float32(gomath.Cos(angle))*l.radius,
+						feet[1] + l.height + 2*bob,
+						feet[2] + float32(gomath.Sin(angle))*l.radius,
+					}
+					verts = appendAuraQuad(verts, p, scale3(camRight, l.size), scale3(camUp, l.size), color, auraShapeGlow)
+				}
+			}
+		}
+	}
+	return verts
+}
+
+// appendAuraQuad appends the two triangles of a quad centred on c with
+// half-extent vectors u and v, UVs running -1..1 across it.
+func appendAuraQuad(verts []float32, c, u, v [3]float32, color [4]float32, shape auraShape) []float32 {
+	s := float32(shape)
+	corner := func(su, sv float32) {
+		verts = append(verts,
+			c[0]+u[0]*su+v[0]*sv, c[1]+u[1]*su+v[1]*sv, c[2]+u[2]*su+v[2]*sv,
+			su, sv, color[0], color[1], color[2], color[3], s)
+	}
+	corner(-1, -1)
+	corner(1, -1)
+	corner(1, 1)
+	corner(-1, -1)
+	corner(1, 1)
+	corner(-1, 1)
+	return verts
+}
+
+// horizontal returns v flattened onto the ground plane with length n.
+func horizontal(v [3]float32, n float32) [3]float32 {
+	l := float32(gomath.Hypot(float64(v[0]), float64(v[2])))
+	if l < 1e-6 {
+		return [3]float32{n, 0, 0}
+	}
+	return [3]float32{v[0] / l * n, 0, v[2] / l * n}
+}
+
+func scale3(v [3]float32, s float32) [3]float32 {
+	return [3]float32{v[0] * s, v[1] * s, v[2] * s}
+}
+
+func fract(x float32) float32 {
+	return x - float32(gomath.Floor(float64(x)))
+}
+
+// Destroy releases all resources.
+func (ar *AuraRenderer) Destroy() {
+	ar.Clear()
+	if ar.vao != 0 {
+		gl.DeleteVertexArrays(1, &ar.vao)
+		ar.vao = 0
+	}
+	if ar.vbo != 0 {
+		gl.DeleteBuffers(1, &ar.vbo)
+		ar.vbo = 0
+	}
+	if ar.program != 0 {
+		gl.DeleteProgram(ar.program)
+		ar.program = 0
+	}
+}
@@ -0,0 +1,123 @@
+package scene
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAuraSweep(t *testing.T) {
+	now := time.Unix(1000, 0)
+	ar := &AuraRenderer{auras: make(map[uint32]*auraEntry)}
+
+	ar.Set(1, Aura{Styles: AuraBlue}, now)
+	ar.Set(2, Aura{Styles: AuraSpirit}, now)
+	ar.Sweep()
+	if ar.Count() != 2 {
+		t.Fatalf("count after first sweep = %d, want 2", ar.Count())
+	}
+
+	// Only auras set again survive the next sweep, keeping when they began
+	later := now.Add(time.Second)
+	ar.Set(1, Aura{Styles: AuraBlue, Position: [3]float32{5, 0, 5}}, later)
+	ar.Sweep()
+	if ar.Count() != 1 || ar.auras[1] == nil {
+		t.Fatalf("after second sweep: %d auras, want only 1", ar.Count())
+	}
+	if ar.auras[1].born != now || ar.auras[1].Position[0] != 5 {
+		t.Errorf("moved aura = %+v, want born %v at x 5", ar.auras[1], now)
+	}
+
+	// Setting no styles removes the aura
+	ar.Set(1, Aura{}, later)
+	if ar.Count() != 0 {
+		t.Errorf("count after clearing styles = %d, want 0", ar.Count())
+	}
+
+	for id := range uint32(maxAuras + 1) {
+		ar.Set(id, Aura{Styles: AuraGold}, now)
+	}
+	if ar.Count() != maxAuras {
+		t.Errorf("count = %d, want the cap %d", ar.Count(), maxAuras)
+	}
+}
+
+func TestAuraBurst(t *testing.T) {
+	now := time.Unix(1000, 0)
+	ar := &AuraRenderer{auras: make(map[uint32]*auraEntry)}
+	ar.Burst(Aura{Styles: AuraTeleport}, time.Second, now)
+	ar.Burst(Aura{Styles: AuraTeleport}, 2*time.Second, now)
+	ar.Burst(Aura{}, time.Second, now) // Nothing to play
+
+	ar.pruneBursts(now.Add(1500 * time.Millisecond))
+	if len(ar.bursts) != 1 || ar.bursts[0].duration != 2*time.Second {
+		t.Fatalf("bursts after 1.5s = %+v, want the 2s one", ar.bursts)
+	}
+
+	fades := []struct {
+		age  time.Duration
+		want float32
+	}{
+		{0, 0},
+		{50 * time.Millisecond, 0.5},
+		{300 * time.Millisecond, 1},
+		{750 * time.Millisecond, 0.5},
+		{time.Second, 0},
+	}
+	for _, f := range fades {
+		if got := burstFade(f.age, time.Second); got < f.want-1e-4 || got > f.want+1e-4 {
+			t.Errorf("burstFade(%v) = %v, want %v", f.age, got, f.want)
+		}
+	}
+}
+
+func TestAppendAuraMesh(t *testing.T) {
+	right, up := [3]float32{1, 0, 0}, [3]float32{0, 1, 0}
+	quads := func(styles AuraStyles) int {
+		n := 0
+		for style, layers := range auraLayers {
+			if styles&style == 0 {
+				continue
+			}
+			for _, l := range layers {
+				n += max(l.count, 1)
+			}
+		}
+		return n
+	}
+
+	tests := []struct {
+		name   string
+		styles AuraStyles
+	}{
+		{"level", AuraBlue},
+		{"rebirth", AuraGold},
+		{"job only", AuraSpirit},
+		{"stacked", AuraGold | AuraStarlight},
+		{"teleport", AuraTeleport},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &Aura{Styles: tt.styles, Position: [3]float32{100, 10, 200}}
+			verts := appendAuraMesh(nil, a, 1.3, 1, right, up)
+			if want := quads(tt.styles) * 6 * auraVertexFloats; len(verts) != want {
+				t.Fatalf("got %d floats, want %d", len(verts), want)
+			}
+			for i := 0; i < len(verts); i += auraVertexFloats {
+				x, y, z := verts[i], verts[i+1], verts[i+2]
+				if x < 80 || x > 120 || y < 8 || y > 50 || z < 180 || z > 220 {
+					t.Fatalf("vertex (%v, %v, %v) far from the wearer", x, y, z)
+				}
+			}
+		})
+	}
+
+	// Fading scales every vertex's alpha
+	a := &Aura{Styles: AuraBlue}
+	full := appendAuraMesh(nil, a, 0.5, 1, right, up)
+	half := appendAuraMesh(nil, a, 0.5, 0.5, right, up)
+	for i := 8; i < len(full); i += auraVertexFloats {
+		if d := half[i] - full[i]/2; d > 1e-6 || d < -1e-6 {
+			t.Fatalf("alpha at vertex %d = %v, want %v", i/auraVertexFloats, half[i], full[i]/2)
+		}
+	}
+}
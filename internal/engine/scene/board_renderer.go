@@ -0,0 +1,267 @@
+package scene
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"slices"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"go.uber.org/zap"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/glstate"
+	"github.com/Faultbox/midgard-ro/internal/engine/picking"
+	"github.com/Faultbox/midgard-ro/internal/engine/ui2d"
+	"github.com/Faultbox/midgard-ro/internal/logger"
+	"github.com/Faultbox/midgard-ro/pkg/math"
+)
+
+const (
+	// boardFontSize is the point size board text is rasterized at.
+	boardFontSize = 11.0
+
+	// boardWorldScale converts board texture pixels to world units.
+	boardWorldScale = 0.1
+
+	// boardMaxTextWidth caps a board's text in pixels; longer text is cut
+	// off, like the original client's fixed-size signs.
+	boardMaxTextWidth = 180
+
+	boardPadX = 6
+	boardPadY = 3
+)
+
+// BoardKind selects a board's look.
+type BoardKind int
+
+const (
+	BoardChatroom BoardKind = iota // Chat room title over its owner
+	BoardVending                   // Shop title over a vendor
+	BoardEvent                     // NPC event sign
+)
+
+// boardStyle is the look of a board kind.
+type boardStyle struct {
+	background, border, text color.RGBA
+}
+
+var boardStyles = map[BoardKind]boardStyle{
+	BoardChatroom: {
+		background: color.RGBA{255, 255, 255, 230},
+		border:     color.RGBA{60, 100, 200, 255},
+		text:       color.RGBA{20, 30, 60, 255},
+	},
+	BoardVending: {
+		background: color.RGBA{242, 242, 235, 240},
+		border:     color.RGBA{100, 100, 115, 255},
+		text:       color.RGBA{25, 25, 40, 255},
+	},
+	BoardEvent: {
+		background: color.RGBA{255, 235, 160, 240},
+		border:     color.RGBA{140, 95, 40, 255},
+		text:       color.RGBA{60, 35, 10, 255},
+	},
+}
+
+// Board is a text panel floating in the world, such as a shop title over a
+// vendor. Boards face the camera, draw over world geometry so they stay
+// readable, and can be picked with a ray.
+type Board struct {
+	Kind     BoardKind
+	Text     string
+	Position [3]float32 // World position of the board's bottom centre
+}
+
+// boardEntry is a board with its rasterized texture.
+type boardEntry struct {
+	Board
+	texture       uint32
+	width, height float32 // World units
+	dirty         bool    // Text or kind changed since the texture was made
+}
+
+// BoardRenderer draws world-space boards as billboards.
+type BoardRenderer struct {
+	boards map[uint32]*boardEntry
+	face   font.Face
+	order  []*boardEntry // Draw order scratch
+}
+
+// NewBoardRenderer creates a board renderer. Text uses the system font,
+// or a built-in ASCII font if none is found.
+func NewBoardRenderer() *BoardRenderer {
+	face, err := ui2d.NewSystemFace(boardFontSize)
+	if err != nil {
+		logger.Warn("board font unavailable, using built-in font", zap.Error(err))
+		face = basicfont.Face7x13
+	}
+	return &BoardRenderer{
+		boards: make(map[uint32]*boardEntry),
+		face:   face,
+	}
+}
+
+// Set adds or updates the board with the given ID. Moving a board is cheap;
+// changing its text or kind re-rasterizes it on the next render.
+func (br *BoardRenderer) Set(id uint32, b Board) {
+	e, ok := br.boards[id]
+	if !ok {
+		br.boards[id] = &boardEntry{Board: b, dirty: true}
+		return
+	}
+	if e.Text != b.Text || e.Kind != b.Kind {
+		e.dirty = true
+	}
+	e.Board = b
+}
+
+// Remove removes a board. Unknown IDs are ignored.
+func (br *BoardRenderer) Remove(id uint32) {
+	if e, ok := br.boards[id]; ok {
+		deleteBoardTexture(e)
+		delete(br.boards, id)
+	}
+}
+
+// Clear removes every board.
+func (br *BoardRenderer) Clear() {
+	for _, e := range br.boards {
+		deleteBoardTexture(e)
+	}
+	clear(br.boards)
+}
+
+// Count returns the number of boards.
+func (br *BoardRenderer) Count() int {
+	return len(br.boards)
+}
+
+// Pick returns the ID of the nearest board the ray hits, for boards facing
+// a camera with the given right and up vectors.
+func (br *BoardRenderer) Pick(ray picking.Ray, camRight, camUp [3]float32) (uint32, bool) {
+	var bestID uint32
+	bestT := float32(-1)
+	for id, e := range br.boards {
+		if e.texture == 0 {
+			continue // Not drawn yet
+		}
+		t, hit := ray.IntersectBillboard(e.Position, camRight, camUp, e.width, e.height)
+		if hit && (bestT < 0 || t < bestT) {
+			bestID, bestT = id, t
+		}
+	}
+	return bestID, bestT >= 0
+}
+
+// Render draws the boards far to near without depth testing, so they stay
+// readable over walls and each other.
+func (br *BoardRenderer) Render(sr *SpriteRenderer, viewProj, view math.Mat4) {
+	if len(br.boards) == 0 {
+		return
+	}
+
+	camRight := math.Vec3{X: view[0], Y: view[4], Z: view[8]}
+	camUp := math.Vec3{X: view[1], Y: view[5], Z: view[9]}
+
+	br.order = br.order[:0]
+	for _, e := range br.boards {
+		if e.dirty {
+			br.upload(e)
+		}
+		br.order = append(br.order, e)
+	}
+	depth := func(e *boardEntry) float32 {
+		p := e.Position
+		return viewProj[3]*p[0] + viewProj[7]*p[1] + viewProj[11]*p[2] + viewProj[15]
+	}
+	slices.SortFunc(br.order, func(a, b *boardEntry) int {
+		da, db := depth(a), depth(b)
+		switch {
+		case da > db:
+			return -1
+		case da < db:
+			return 1
+		}
+		return 0
+	})
+
+	defer glstate.Push(glstate.Overlay)()
+	white := [4]float32{1, 1, 1, 1}
+	for _, e := range br.order {
+		sr.Render(viewProj, camRight, camUp, e.Position, e.width, e.height, e.texture, white)
+	}
+}
+
+// upload rasterizes a board's text into its texture.
+func (br *BoardRenderer) upload(e *boardEntry) {
+	img := rasterizeBoard(e.Text, boardStyles[e.Kind], br.face)
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+
+	if e.texture == 0 {
+		gl.GenTextures(1, &e.texture)
+	}
+	gl.BindTexture(gl.TEXTURE_2D, e.texture)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA8, int32(w), int32(h), 0, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(img.Pix))
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+
+	e.width = float32(w) * boardWorldScale
+	e.height = float32(h) * boardWorldScale
+	e.dirty = false
+}
+
+// Destroy releases the board textures.
+func (br *BoardRenderer) Destroy() {
+	br.Clear()
+}
+
+func deleteBoardTexture(e *boardEntry) {
+	if e.texture != 0 {
+		gl.DeleteTextures(1, &e.texture)
+		e.texture = 0
+	}
+}
+
+// rasterizeBoard draws a board's panel and text, cutting text wider than
+// boardMaxTextWidth off with "..".
+func rasterizeBoard(text string, style boardStyle, face font.Face) *image.RGBA {
+	text = fitBoardText(text, face)
+	metrics := face.Metrics()
+	textW := font.MeasureString(face, text).Ceil()
+	w := textW + 2*boardPadX
+	h := metrics.Height.Ceil() + 2*boardPadY
+
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), image.NewUniform(style.border), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(1, 1, w-1, h-1), image.NewUniform(style.background), image.Point{}, draw.Src)
+
+	d := font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(style.text),
+		Face: face,
+		Dot:  fixed.P(boardPadX, boardPadY+metrics.Ascent.Ceil()),
+	}
+	d.DrawString(text)
+	return img
+}
+
+// fitBoardText shortens text with ".." until it fits boardMaxTextWidth.
+func fitBoardText(text string, face font.Face) string {
+	const maxW = fixed.Int26_6(boardMaxTextWidth << 6)
+	if font.MeasureString(face, text) <= maxW {
+		return text
+	}
+	runes := []rune(text)
+	for n := len(runes) - 1; n > 0; n-- {
+		if s := string(runes[:n]) + ".."; font.MeasureString(face, s) <= maxW {
+			return s
+		}
+	}
+	return ".."
+}
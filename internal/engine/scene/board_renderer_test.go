@@ -0,0 +1,61 @@
+package scene
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/image/font/basicfont"
+)
+
+func TestRasterizeBoard(t *testing.T) {
+	face := basicfont.Face7x13 // 7 pixels per character, 13 per line
+	style := boardStyles[BoardVending]
+
+	img := rasterizeBoard("Potions", style, face)
+	if w, h := img.Bounds().Dx(), img.Bounds().Dy(); w != 7*7+2*boardPadX || h != 13+2*boardPadY {
+		t.Errorf("size = %dx%d, want %dx%d", w, h, 7*7+2*boardPadX, 13+2*boardPadY)
+	}
+	if got := img.RGBAAt(0, 0); got != style.border {
+		t.Errorf("corner = %v, want border %v", got, style.border)
+	}
+	if got := img.RGBAAt(2, 2); got != style.background {
+		t.Errorf("inside = %v, want background %v", got, style.background)
+	}
+
+	long := rasterizeBoard(strings.Repeat("x", 100), style, face)
+	if w := long.Bounds().Dx(); w > boardMaxTextWidth+2*boardPadX {
+		t.Errorf("long text width = %d, want at most %d", w, boardMaxTextWidth+2*boardPadX)
+	}
+}
+
+func TestFitBoardText(t *testing.T) {
+	face := basicfont.Face7x13
+
+	if got := fitBoardText("Cheap Potions", face); got != "Cheap Potions" {
+		t.Errorf("short text = %q, want unchanged", got)
+	}
+	// 180 pixels hold 25 characters: 23 plus ".."
+	got := fitBoardText(strings.Repeat("x", 40), face)
+	if want := strings.Repeat("x", 23) + ".."; got != want {
+		t.Errorf("long text = %q, want %q", got, want)
+	}
+}
+
+func TestBoardRendererSet(t *testing.T) {
+	br := &BoardRenderer{boards: make(map[uint32]*boardEntry)}
+	br.Set(7, Board{Kind: BoardVending, Text: "Shop"})
+	br.boards[7].dirty = false
+
+	// Moving keeps the texture, new text re-rasterizes it
+	br.Set(7, Board{Kind: BoardVending, Text: "Shop", Position: [3]float32{5, 0, 5}})
+	if br.boards[7].dirty {
+		t.Error("moving a board marked it dirty")
+	}
+	br.Set(7, Board{Kind: BoardVending, Text: "Sale"})
+	if !br.boards[7].dirty {
+		t.Error("changing the text didn't mark the board dirty")
+	}
+	if br.Count() != 1 {
+		t.Errorf("Count() = %d, want 1", br.Count())
+	}
+}
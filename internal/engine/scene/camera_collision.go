@@ -0,0 +1,88 @@
+package scene
+
+import (
+	gomath "math"
+
+	"github.com/Faultbox/midgard-ro/pkg/math"
+)
+
+const (
+	// cameraBlockerMinSize is how wide and tall a model must be to block
+	// the camera, so lamps, fences and shrubs don't make it jump.
+	cameraBlockerMinSize = 20
+
+	// cameraTerrainStep is how far apart the terrain is sampled along the
+	// camera's line of sight, half a GAT cell.
+	cameraTerrainStep = 2.5
+
+	// cameraTerrainClearance keeps the line of sight this far above ground.
+	cameraTerrainClearance = 3
+
+	// cameraTerrainRefine is how many bisection steps narrow down where
+	// the line of sight meets the ground, so the camera moves smoothly
+	// rather than in cameraTerrainStep jumps.
+	cameraTerrainRefine = 6
+)
+
+// blocksCamera reports whether a model with these bounds is large enough
+// to block the camera.
+func blocksCamera(bounds math.AABB) bool {
+	size := bounds.Size()
+	return size.Y >= cameraBlockerMinSize && max(size.X, size.Z) >= cameraBlockerMinSize
+}
+
+// CameraObstruction reports how far along the segment from..to the first
+// hill or large model lies, as a fraction 0..1, or 1 if it's clear. Models
+// the segment starts inside are ignored, so standing under an arch or
+// in a building's loose bounding box doesn't pull the camera in.
+func (s *Scene) CameraObstruction(from, to math.Vec3) float32 {
+	t := terrainHit(from, to, s.GetTerrainHeight)
+	return min(t, boxesHit(s.models.blockers, from, to))
+}
+
+// terrainHit returns the fraction along from..to where the segment first
+// comes within cameraTerrainClearance of the ground, or 1 if it never does.
+func terrainHit(from, to math.Vec3, height func(x, z float32) float32) float32 {
+	d := to.Sub(from)
+	steps := int(gomath.Ceil(float64(d.XZ().Length() / cameraTerrainStep)))
+	steps = max(steps, 1)
+
+	below := func(t float32) bool {
+		p := from.Add(d.Scale(t))
+		return p.Y < height(p.X, p.Z)+cameraTerrainClearance
+	}
+	prev := float32(0)
+	for i := 1; i <= steps; i++ {
+		t := float32(i) / float32(steps)
+		if below(t) {
+			lo, hi := prev, t
+			for range cameraTerrainRefine {
+				mid := (lo + hi) / 2
+				if below(mid) {
+					hi = mid
+				} else {
+					lo = mid
+				}
+			}
+			return lo
+		}
+		prev = t
+	}
+	return 1
+}
+
+// boxesHit returns the fraction along from..to where the segment first
+// enters one of boxes, or 1 if it misses them all. Boxes containing from
+// are skipped.
+func boxesHit(boxes []math.AABB, from, to math.Vec3) float32 {
+	best := float32(1)
+	for _, b := range boxes {
+		if b.Contains(from) {
+			continue
+		}
+		if t, ok := b.SegmentHit(from, to); ok && t < best {
+			best = t
+		}
+	}
+	return best
+}
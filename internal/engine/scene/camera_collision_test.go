@@ -0,0 +1,72 @@
+package scene
+
+import (
+	"testing"
+
+	"github.com/Faultbox/midgard-ro/pkg/math"
+)
+
+func TestTerrainHit(t *testing.T) {
+	flat := func(x, z float32) float32 { return 0 }
+	hill := func(x, z float32) float32 {
+		if x >= 50 {
+			return 40
+		}
+		return 0
+	}
+	tests := []struct {
+		name     string
+		height   func(x, z float32) float32
+		from, to math.Vec3
+		want     float32
+	}{
+		{"clear", flat, math.Vec3{X: 0, Y: 30, Z: 0}, math.Vec3{X: 100, Y: 130, Z: 0}, 1},
+		{"hill", hill, math.Vec3{X: 0, Y: 30, Z: 0}, math.Vec3{X: 100, Y: 30, Z: 0}, 0.5},
+		{"into the ground", flat, math.Vec3{X: 0, Y: 30, Z: 0}, math.Vec3{X: 100, Y: -30, Z: 0}, 0.45},
+		{"vertical", flat, math.Vec3{X: 0, Y: 30, Z: 0}, math.Vec3{X: 0, Y: 100, Z: 0}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := terrainHit(tt.from, tt.to, tt.height)
+			if abs(got-tt.want) > 0.01 {
+				t.Errorf("terrainHit() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBoxesHit(t *testing.T) {
+	wall := math.AABB{Min: math.Vec3{X: 40, Y: 0, Z: -50}, Max: math.Vec3{X: 60, Y: 100, Z: 50}}
+	around := math.AABB{Min: math.Vec3{X: -10, Y: 0, Z: -10}, Max: math.Vec3{X: 10, Y: 100, Z: 10}}
+	from, to := math.Vec3{X: 0, Y: 30, Z: 0}, math.Vec3{X: 100, Y: 30, Z: 0}
+
+	if got := boxesHit(nil, from, to); got != 1 {
+		t.Errorf("no boxes: got %v, want 1", got)
+	}
+	if got := boxesHit([]math.AABB{around}, from, to); got != 1 {
+		t.Errorf("box around the start: got %v, want 1", got)
+	}
+	if got := boxesHit([]math.AABB{around, wall}, from, to); abs(got-0.4) > 1e-5 {
+		t.Errorf("wall: got %v, want 0.4", got)
+	}
+}
+
+func TestBlocksCamera(t *testing.T) {
+	tests := []struct {
+		name string
+		size math.Vec3
+		want bool
+	}{
+		{"building", math.Vec3{X: 60, Y: 50, Z: 40}, true},
+		{"long wall", math.Vec3{X: 100, Y: 30, Z: 4}, true},
+		{"lamp post", math.Vec3{X: 4, Y: 40, Z: 4}, false},
+		{"fence", math.Vec3{X: 50, Y: 8, Z: 2}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := blocksCamera(math.AABB{Max: tt.size}); got != tt.want {
+				t.Errorf("blocksCamera(%v) = %v, want %v", tt.size, got, tt.want)
+			}
+		})
+	}
+}
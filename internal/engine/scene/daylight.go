@@ -0,0 +1,35 @@
+package scene
+
+// Night light, as multipliers of the map's own: moonlight is dim and
+// blue, and most of it comes from the ambient term.
+var (
+	nightAmbient = [3]float32{0.55, 0.6, 0.85}
+	nightDiffuse = [3]float32{0.25, 0.3, 0.5}
+)
+
+// daylight returns the ambient and diffuse light of the map at the
+// current darkness, and the point light intensity: lamps fade in as it
+// gets dark, other lights are always on.
+func (s *Scene) daylight() (ambient, diffuse [3]float32, lamps float32) {
+	ambient = nightTint(s.AmbientColor, nightAmbient, s.Darkness)
+	diffuse = nightTint(s.DiffuseColor, nightDiffuse, s.Darkness)
+	lamps = s.PointLightIntensity
+	if s.NightLamps {
+		lamps *= clamp01(s.Darkness)
+	}
+	return ambient, diffuse, lamps
+}
+
+// nightTint scales color toward night, by darkness from 0 (unchanged) to
+// 1 (fully night).
+func nightTint(color, night [3]float32, darkness float32) [3]float32 {
+	d := clamp01(darkness)
+	for i := range color {
+		color[i] *= 1 + (night[i]-1)*d
+	}
+	return color
+}
+
+func clamp01(v float32) float32 {
+	return min(max(v, 0), 1)
+}
@@ -0,0 +1,33 @@
+package scene
+
+import "testing"
+
+func TestDaylight(t *testing.T) {
+	s := &Scene{
+		MapInstance: &MapInstance{
+			AmbientColor: [3]float32{0.4, 0.4, 0.4},
+			DiffuseColor: [3]float32{1, 1, 1},
+		},
+		PointLightIntensity: 2,
+	}
+
+	ambient, diffuse, lamps := s.daylight()
+	if ambient != s.AmbientColor || diffuse != s.DiffuseColor || lamps != 2 {
+		t.Errorf("day = %v %v %v, want the map's light unchanged", ambient, diffuse, lamps)
+	}
+
+	s.Darkness = 1
+	s.NightLamps = true
+	ambient, diffuse, lamps = s.daylight()
+	if ambient[2] <= ambient[0] || diffuse[0] >= 0.5 {
+		t.Errorf("night ambient %v diffuse %v, want dim blue light", ambient, diffuse)
+	}
+	if lamps != 2 {
+		t.Errorf("night lamps = %v, want 2", lamps)
+	}
+
+	s.Darkness = 0
+	if _, _, lamps = s.daylight(); lamps != 0 {
+		t.Errorf("day lamps = %v, want off", lamps)
+	}
+}
@@ -0,0 +1,305 @@
+package scene
+
+import (
+	"fmt"
+	gomath "math"
+	"time"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/clock"
+	"github.com/Faultbox/midgard-ro/internal/engine/glstate"
+	"github.com/Faultbox/midgard-ro/internal/engine/scene/shaders"
+	"github.com/Faultbox/midgard-ro/internal/engine/shader"
+	"github.com/Faultbox/midgard-ro/pkg/math"
+)
+
+const (
+	// maxDecals caps live decals; spawning past it evicts an old one.
+	maxDecals = 256
+
+	// decalCellSize is the grid step decals are cut into so they follow
+	// the ground, one GAT cell.
+	decalCellSize = 5.0
+
+	// decalMaxCells caps a decal's grid per side, so huge decals only
+	// follow the ground more coarsely.
+	decalMaxCells = 24
+
+	// decalLift raises decals off the ground against z-fighting.
+	decalLift = 0.2
+
+	// decalVertexFloats is position (3), UV (2), color (4) and shape (1).
+	decalVertexFloats = 10
+)
+
+// DecalShape selects how a decal is drawn inside its square.
+type DecalShape int
+
+const (
+	DecalDisc      DecalShape = iota // Filled circle, brighter at the rim (casting circle)
+	DecalRing                        // Circle outline (AoE telegraph, loot ring)
+	DecalSquare                      // Square with a solid border (ground skill cells)
+	DecalSplat                       // Irregular blotch (blood splat)
+	DecalFootprint                   // Narrow oval along the rotation (footprint)
+)
+
+// Decal is a mark projected onto the ground: it's cut into a grid that
+// follows the terrain height, so it drapes over slopes and steps.
+type Decal struct {
+	Shape    DecalShape
+	X, Z     float32 // World position of the centre
+	Radius   float32 // Half the decal's width, world units
+	Rotation float32 // Radians around the vertical axis
+	Color    [4]float32
+	Lifetime time.Duration // 0 keeps the decal until it's removed
+	FadeIn   time.Duration
+	FadeOut  time.Duration // Over the end of Lifetime
+}
+
+// DecalID identifies a spawned decal. Zero is never used.
+type DecalID uint32
+
+// decalEntry is a live decal.
+type decalEntry struct {
+	Decal
+	id   DecalID
+	born time.Time
+}
+
+// alpha returns the decal's fade at time now: 0 before it fades in or
+// once it has expired, 1 in between.
+func (e *decalEntry) alpha(now time.Time) float32 {
+	age := now.Sub(e.born)
+	a := float32(1)
+	if e.FadeIn > 0 && age < e.FadeIn {
+		a = float32(max(age, 0)) / float32(e.FadeIn)
+	}
+	if e.Lifetime > 0 {
+		left := e.Lifetime - age
+		if left <= 0 {
+			return 0
+		}
+		if e.FadeOut > 0 && left < e.FadeOut {
+			a *= float32(left) / float32(e.FadeOut)
+		}
+	}
+	return a
+}
+
+// expired reports whether a timed decal's lifetime is over.
+func (e *decalEntry) expired(now time.Time) bool {
+	return e.Lifetime > 0 && now.Sub(e.born) >= e.Lifetime
+}
+
+// DecalRenderer draws ground decals. All decals are batched into one
+// vertex buffer and drawn with a single call.
+type DecalRenderer struct {
+	program     uint32
+	locViewProj int32
+
+	vao, vbo uint32
+	vboBytes int // Allocated size of vbo
+
+	decals   []*decalEntry // Oldest first
+	nextID   DecalID
+	height   func(x, z float32) float32
+	vertices []float32 // Batch scratch
+}
+
+// NewDecalRenderer creates a decal renderer. height samples the ground
+// height at a world position.
+func NewDecalRenderer(height func(x, z float32) float32) (*DecalRenderer, error) {
+	program, err := shader.CompileProgram(shaders.DecalVertexShader, shaders.DecalFragmentShader)
+	if err != nil {
+		return nil, fmt.Errorf("decal shader: %w", err)
+	}
+	dr := &DecalRenderer{
+		program:     program,
+		locViewProj: shader.GetUniform(program, "uViewProj"),
+		height:      height,
+	}
+
+	gl.GenVertexArrays(1, &dr.vao)
+	gl.BindVertexArray(dr.vao)
+	gl.GenBuffers(1, &dr.vbo)
+	gl.BindBuffer(gl.ARRAY_BUFFER, dr.vbo)
+
+	const stride = decalVertexFloats * 4
+	gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, stride, 0)
+	gl.EnableVertexAttribArray(0)
+	gl.VertexAttribPointerWithOffset(1, 2, gl.FLOAT, false, stride, 3*4)
+	gl.EnableVertexAttribArray(1)
+	gl.VertexAttribPointerWithOffset(2, 4, gl.FLOAT, false, stride, 5*4)
+	gl.EnableVertexAttribArray(2)
+	gl.VertexAttribPointerWithOffset(3, 1, gl.FLOAT, false, stride, 9*4)
+	gl.EnableVertexAttribArray(3)
+
+	gl.BindVertexArray(0)
+	return dr, nil
+}
+
+// Add spawns a decal born at now. When the pool is full the oldest timed
+// decal is evicted, so persistent markers outlast splats and footprints;
+// if every decal is persistent the oldest goes.
+func (dr *DecalRenderer) Add(d Decal, now time.Time) DecalID {
+	if len(dr.decals) >= maxDecals {
+		evict := 0
+		for i, e := range dr.decals {
+			if e.Lifetime > 0 {
+				evict = i
+				break
+			}
+		}
+		dr.removeAt(evict)
+	}
+	dr.nextID++
+	if dr.nextID == 0 {
+		dr.nextID++
+	}
+	dr.decals = append(dr.decals, &decalEntry{Decal: d, id: dr.nextID, born: now})
+	return dr.nextID
+}
+
+// Remove removes a decal. Unknown or expired IDs are ignored.
+func (dr *DecalRenderer) Remove(id DecalID) {
+	for i, e := range dr.decals {
+		if e.id == id {
+			dr.removeAt(i)
+			return
+		}
+	}
+}
+
+func (dr *DecalRenderer) removeAt(i int) {
+	copy(dr.decals[i:], dr.decals[i+1:])
+	dr.decals[len(dr.decals)-1] = nil
+	dr.decals = dr.decals[:len(dr.decals)-1]
+}
+
+// Clear removes every decal.
+func (dr *DecalRenderer) Clear() {
+	clear(dr.decals)
+	dr.decals = dr.decals[:0]
+}
+
+// Count returns the number of live decals.
+func (dr *DecalRenderer) Count() int {
+	return len(dr.decals)
+}
+
+// prune drops decals whose lifetime is over.
+func (dr *DecalRenderer) prune(now time.Time) {
+	kept := dr.decals[:0]
+	for _, e := range dr.decals {
+		if !e.expired(now) {
+			kept = append(kept, e)
+		}
+	}
+	clear(dr.decals[len(kept):])
+	dr.decals = kept
+}
+
+// Render draws the decals over the terrain, depth-tested against it but
+// without writing depth, so later geometry still covers them.
+func (dr *DecalRenderer) Render(viewProj math.Mat4) {
+	now := clock.Now()
+	dr.prune(now)
+	if len(dr.decals) == 0 || dr.vao == 0 {
+		return
+	}
+
+	dr.vertices = dr.vertices[:0]
+	for _, e := range dr.decals {
+		if a := e.alpha(now); a > 0 {
+			dr.vertices = appendDecalMesh(dr.vertices, &e.Decal, a, dr.height)
+		}
+	}
+	if len(dr.vertices) == 0 {
+		return
+	}
+
+	gl.BindBuffer(gl.ARRAY_BUFFER, dr.vbo)
+	size := len(dr.vertices) * 4
+	if size > dr.vboBytes {
+		gl.BufferData(gl.ARRAY_BUFFER, size, gl.Ptr(dr.vertices), gl.DYNAMIC_DRAW)
+		dr.vboBytes = size
+	} else {
+		gl.BufferSubData(gl.ARRAY_BUFFER, 0, size, gl.Ptr(dr.vertices))
+	}
+
+	gl.UseProgram(dr.program)
+	gl.UniformMatrix4fv(dr.locViewProj, 1, false, &viewProj[0])
+
+	defer glstate.Push(glstate.Decal)()
+
+	gl.BindVertexArray(dr.vao)
+	gl.DrawArrays(gl.TRIANGLES, 0, int32(len(dr.vertices)/decalVertexFloats))
+	gl.BindVertexArray(0)
+}
+
+// appendDecalMesh appends a decal's triangles to verts. The decal's square
+// is cut into a grid of about decalCellSize cells whose corners are lifted
+// to the ground height, with UVs running -1..1 across it for the shader.
+func appendDecalMesh(verts []float32, d *Decal, alpha float32, height func(x, z float32) float32) []float32 {
+	if d.Radius <= 0 {
+		return verts
+	}
+	n := int(gomath.Ceil(float64(2 * d.Radius / decalCellSize)))
+	n = min(max(n, 1), decalMaxCells)
+
+	sin, cos := gomath.Sincos(float64(d.Rotation))
+	sinR, cosR := float32(sin)*d.Radius, float32(cos)*d.Radius
+
+	// Grid corners, row by row: x, y, z, u, v
+	corners := make([][5]float32, 0, (n+1)*(n+1))
+	for j := 0; j <= n; j++ {
+		v := -1 + 2*float32(j)/float32(n)
+		for i := 0; i <= n; i++ {
+			u := -1 + 2*float32(i)/float32(n)
+			x := d.X + u*cosR - v*sinR
+			z := d.Z + u*sinR + v*cosR
+			var y float32
+			if height != nil {
+				y = height(x, z)
+			}
+			corners = append(corners, [5]float32{x, y + decalLift, z, u, v})
+		}
+	}
+
+	shape := float32(d.Shape)
+	c := d.Color
+	vertex := func(k int) {
+		p := &corners[k]
+		verts = append(verts, p[0], p[1], p[2], p[3], p[4], c[0], c[1], c[2], c[3]*alpha, shape)
+	}
+	for j := range n {
+		for i := range n {
+			k := j*(n+1) + i
+			vertex(k)
+			vertex(k + 1)
+			vertex(k + n + 2)
+			vertex(k)
+			vertex(k + n + 2)
+			vertex(k + n + 1)
+		}
+	}
+	return verts
+}
+
+// Destroy releases all resources.
+func (dr *DecalRenderer) Destroy() {
+	dr.Clear()
+	if dr.vao != 0 {
+		gl.DeleteVertexArrays(1, &dr.vao)
+		dr.vao = 0
+	}
+	if dr.vbo != 0 {
+		gl.DeleteBuffers(1, &dr.vbo)
+		dr.vbo = 0
+	}
+	if dr.program != 0 {
+		gl.DeleteProgram(dr.program)
+		dr.program = 0
+	}
+}
@@ -0,0 +1,129 @@
+package scene
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDecalAlpha(t *testing.T) {
+	born := time.Unix(1000, 0)
+	timed := Decal{Lifetime: 4 * time.Second, FadeIn: time.Second, FadeOut: 2 * time.Second}
+	tests := []struct {
+		name  string
+		decal Decal
+		age   time.Duration
+		want  float32
+	}{
+		{"fading in", timed, 500 * time.Millisecond, 0.5},
+		{"full", timed, 1500 * time.Millisecond, 1},
+		{"fading out", timed, 3 * time.Second, 0.5},
+		{"expired", timed, 4 * time.Second, 0},
+		{"persistent", Decal{}, time.Hour, 1},
+		{"persistent fading in", Decal{FadeIn: time.Second}, 250 * time.Millisecond, 0.25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &decalEntry{Decal: tt.decal, born: born}
+			if got := e.alpha(born.Add(tt.age)); got != tt.want {
+				t.Errorf("alpha = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecalPool(t *testing.T) {
+	now := time.Unix(1000, 0)
+	dr := &DecalRenderer{}
+
+	ring := dr.Add(Decal{Shape: DecalRing}, now)
+	splat := dr.Add(Decal{Shape: DecalSplat, Lifetime: time.Second}, now)
+	for dr.Count() < maxDecals {
+		dr.Add(Decal{Shape: DecalRing}, now)
+	}
+
+	// Full: the oldest timed decal goes before older persistent ones
+	dr.Add(Decal{Shape: DecalDisc}, now)
+	if dr.Count() != maxDecals {
+		t.Fatalf("count = %d, want %d", dr.Count(), maxDecals)
+	}
+	if dr.decals[0].id != ring {
+		t.Errorf("persistent ring was evicted")
+	}
+	for _, e := range dr.decals {
+		if e.id == splat {
+			t.Errorf("timed splat wasn't evicted")
+		}
+	}
+
+	// No timed decals left: the oldest goes
+	dr.Add(Decal{Shape: DecalDisc}, now)
+	if dr.decals[0].id == ring {
+		t.Errorf("oldest decal wasn't evicted")
+	}
+
+	dr.Clear()
+	short := dr.Add(Decal{Lifetime: time.Second}, now)
+	long := dr.Add(Decal{Lifetime: time.Minute}, now)
+	dr.prune(now.Add(2 * time.Second))
+	if dr.Count() != 1 || dr.decals[0].id != long {
+		t.Errorf("after prune: %d decals, want only %d", dr.Count(), long)
+	}
+	dr.Remove(short) // Already gone
+	dr.Remove(long)
+	if dr.Count() != 0 {
+		t.Errorf("count after Remove = %d, want 0", dr.Count())
+	}
+}
+
+func TestAppendDecalMesh(t *testing.T) {
+	slope := func(x, z float32) float32 { return x / 10 }
+	tests := []struct {
+		name   string
+		radius float32
+		cells  int // Per side
+	}{
+		{"small", 1, 1},
+		{"one cell", 2.5, 1},
+		{"grid", 10, 4},
+		{"capped", 1000, decalMaxCells},
+		{"empty", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Decal{Shape: DecalRing, X: 50, Z: 50, Radius: tt.radius, Color: [4]float32{1, 0, 0, 0.8}}
+			verts := appendDecalMesh(nil, d, 0.5, slope)
+			if want := tt.cells * tt.cells * 6 * decalVertexFloats; len(verts) != want {
+				t.Fatalf("got %d floats, want %d", len(verts), want)
+			}
+			for i := 0; i < len(verts); i += decalVertexFloats {
+				v := verts[i : i+decalVertexFloats]
+				if y := slope(v[0], v[2]) + decalLift; v[1] != y {
+					t.Fatalf("vertex %d at y=%v, want %v on the ground", i/decalVertexFloats, v[1], y)
+				}
+				if v[3] < -1 || v[3] > 1 || v[4] < -1 || v[4] > 1 {
+					t.Fatalf("vertex %d UV (%v, %v) outside -1..1", i/decalVertexFloats, v[3], v[4])
+				}
+				if v[8] != 0.4 || v[9] != float32(DecalRing) {
+					t.Fatalf("vertex %d alpha %v shape %v, want 0.4 and %d", i/decalVertexFloats, v[8], v[9], DecalRing)
+				}
+			}
+		})
+	}
+}
+
+func TestAppendDecalMeshRotation(t *testing.T) {
+	d := &Decal{X: 10, Z: 20, Radius: 2, Rotation: 3.14159265 / 2}
+	verts := appendDecalMesh(nil, d, 1, nil)
+	// First vertex is the (-1, -1) corner, rotated a quarter turn
+	x, z := verts[0], verts[2]
+	if abs(x-12) > 1e-4 || abs(z-18) > 1e-4 {
+		t.Errorf("corner at (%v, %v), want (12, 18)", x, z)
+	}
+}
+
+func abs(v float32) float32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
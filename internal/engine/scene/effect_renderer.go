@@ -0,0 +1,297 @@
+package scene
+
+import (
+	"time"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/clock"
+	"github.com/Faultbox/midgard-ro/internal/engine/sprite"
+	"github.com/Faultbox/midgard-ro/pkg/formats"
+	"github.com/Faultbox/midgard-ro/pkg/math"
+)
+
+// mapEffectSprites maps RSW effect IDs, which are the client's effect
+// numbers, to the ambient sprite effects under data/sprite/이팩트/. IDs not
+// listed here are particle/STR effects and are not rendered by
+// EffectRenderer.
+var mapEffectSprites = map[int32]string{
+	44: "smoke",     // Chimney and campfire smoke
+	45: "firefly",   // Fireflies over grass and water
+	46: "sandwind",  // Blowing sand in deserts
+	47: "torch_01",  // Wall torch flame
+	48: "spraypond", // Fountain and pond spray
+}
+
+// effectPhase returns the start offset in ms of the index'th map effect
+// within an animation cycle. The offsets are spread across the cycle so
+// effects don't animate in lockstep.
+func effectPhase(index int, cycle float32) float32 {
+	return float32(index*137%1000) / 1000 * cycle
+}
+
+const (
+	// maxMapEffects limits effect billboards for performance, like the model cap.
+	maxMapEffects = 500
+
+	// effectSpriteScale converts sprite pixels to world units.
+	effectSpriteScale = 0.25
+
+	// ACT intervals are in game ticks of 24ms; 4 ticks is the client default.
+	effectTickMs          = 24.0
+	defaultEffectInterval = 4.0
+)
+
+// effectFrame is one pre-composited animation frame.
+type effectFrame struct {
+	texture uint32
+	width   float32 // World units
+	height  float32
+	originX float32 // Sprite origin within the frame, in world units
+	originY float32
+}
+
+// effectAnim is a loaded looping effect animation shared by all instances.
+type effectAnim struct {
+	frames     []effectFrame
+	intervalMs float32
+}
+
+// MapEffect represents a sprite effect placed by the RSW.
+type MapEffect struct {
+	anim     *effectAnim
+	position [3]float32
+	phase    float32 // Animation start offset in ms
+	oneShot  bool    // Plays once from phase, then is removed
+	EffectID int32
+	Visible  bool
+}
+
+// EffectRenderer renders animated sprite effects (torches, flames, smoke)
+// placed on the map as camera-facing billboards.
+type EffectRenderer struct {
+	effects []*MapEffect
+	anims   map[string]*effectAnim
+	start   time.Time
+}
+
+// NewEffectRenderer creates a new effect renderer.
+func NewEffectRenderer() *EffectRenderer {
+	return &EffectRenderer{
+		anims: make(map[string]*effectAnim),
+		start: clock.Now(),
+	}
+}
+
+// LoadEffects spawns billboards for all sprite-based effects in the RSW.
+func (er *EffectRenderer) LoadEffects(rsw *formats.RSW, texLoader func(string) ([]byte, error), mapWidth, mapHeight float32) {
+	er.clearEffects()
+	er.start = clock.Now()
+
+	for _, src := range rsw.GetEffects() {
+		if len(er.effects) >= maxMapEffects {
+			break
+		}
+		name, ok := mapEffectSprites[src.EffectID]
+		if !ok {
+			continue
+		}
+		anim, ok := er.anims[name]
+		if !ok {
+			anim = er.loadAnim(name, texLoader)
+			// Cache failures too so a missing sprite is only looked up once
+			er.anims[name] = anim
+		}
+		if anim == nil {
+			continue
+		}
+
+		phase := effectPhase(len(er.effects), anim.intervalMs*float32(len(anim.frames)))
+
+		// Same RSW -> world conversion as models
+		er.effects = append(er.effects, &MapEffect{
+			anim: anim,
+			position: [3]float32{
+				src.Position[0] + mapWidth/2,
+				-src.Position[1],
+				src.Position[2] + mapHeight/2,
+			},
+			phase:    phase,
+			EffectID: src.EffectID,
+			Visible:  true,
+		})
+	}
+}
+
+// SpawnOneShot plays a sprite effect once at a world position, e.g. the
+// level-up effect on a character. Returns false if the sprite is unavailable.
+func (er *EffectRenderer) SpawnOneShot(name string, position [3]float32, texLoader func(string) ([]byte, error)) bool {
+	anim, ok := er.anims[name]
+	if !ok {
+		anim = er.loadAnim(name, texLoader)
+		er.anims[name] = anim
+	}
+	if anim == nil {
+		return false
+	}
+	er.effects = append(er.effects, &MapEffect{
+		anim:     anim,
+		position: position,
+		phase:    -float32(clock.Since(er.start).Milliseconds()),
+		oneShot:  true,
+		Visible:  true,
+	})
+	return true
+}
+
+// loadAnim loads and pre-composites every frame of the first action of an effect sprite.
+func (er *EffectRenderer) loadAnim(name string, texLoader func(string) ([]byte, error)) *effectAnim {
+	base := "data/sprite/이팩트/" + name
+	sprData, err := texLoader(base + ".spr")
+	if err != nil {
+		return nil
+	}
+	actData, err := texLoader(base + ".act")
+	if err != nil {
+		return nil
+	}
+	spr, err := formats.ParseSPR(sprData)
+	if err != nil {
+		return nil
+	}
+	act, err := formats.ParseACT(actData)
+	if err != nil || len(act.Actions) == 0 {
+		return nil
+	}
+
+	interval := float32(defaultEffectInterval)
+	if len(act.Intervals) > 0 && act.Intervals[0] > 0 {
+		interval = act.Intervals[0]
+	}
+	anim := &effectAnim{intervalMs: interval * effectTickMs}
+
+	for i := range act.Actions[0].Frames {
+		result := sprite.CompositeFrame(spr, act, 0, i)
+		if result.Width == 0 || result.Height == 0 {
+			continue
+		}
+		anim.frames = append(anim.frames, effectFrame{
+			texture: uploadEffectTexture(result),
+			width:   float32(result.Width) * effectSpriteScale,
+			height:  float32(result.Height) * effectSpriteScale,
+			originX: float32(result.OriginX) * effectSpriteScale,
+			originY: float32(result.OriginY) * effectSpriteScale,
+		})
+	}
+	if len(anim.frames) == 0 {
+		return nil
+	}
+	return anim
+}
+
+func uploadEffectTexture(result sprite.CompositeResult) uint32 {
+	var tex uint32
+	gl.GenTextures(1, &tex)
+	gl.BindTexture(gl.TEXTURE_2D, tex)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA8, int32(result.Width), int32(result.Height), 0,
+		gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(result.Pixels))
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	return tex
+}
+
+// Count returns the number of spawned effects.
+func (er *EffectRenderer) Count() int {
+	return len(er.effects)
+}
+
+// Render queues all visible effects as billboards, to be drawn back to
+// front with the scene's other transparent draws.
+func (er *EffectRenderer) Render(q *transparencyQueue, viewProj, view math.Mat4) {
+	if len(er.effects) == 0 {
+		return
+	}
+
+	// Camera basis from the view matrix rows (column-major)
+	camRight := math.Vec3{X: view[0], Y: view[4], Z: view[8]}
+	camUp := math.Vec3{X: view[1], Y: view[5], Z: view[9]}
+	elapsed := float32(clock.Since(er.start).Milliseconds())
+	tint := [4]float32{1, 1, 1, 1}
+	finished := false
+
+	for _, effect := range er.effects {
+		if effect == nil || !effect.Visible {
+			continue
+		}
+		anim := effect.anim
+		idx := int((elapsed + effect.phase) / anim.intervalMs)
+		if effect.oneShot && idx >= len(anim.frames) {
+			effect.Visible = false
+			finished = true
+			continue
+		}
+		frame := &anim.frames[idx%len(anim.frames)]
+
+		// The billboard quad is anchored at its bottom-center; shift it so the
+		// sprite origin lands on the effect position.
+		dx := frame.width/2 - frame.originX
+		dy := frame.originY - frame.height
+		pos := [3]float32{
+			effect.position[0] + camRight.X*dx + camUp.X*dy,
+			effect.position[1] + camRight.Y*dx + camUp.Y*dy,
+			effect.position[2] + camRight.Z*dx + camUp.Z*dy,
+		}
+		q.add(transparentDraw{
+			viewProj: viewProj,
+			camRight: camRight,
+			camUp:    camUp,
+			worldPos: pos,
+			width:    frame.width,
+			height:   frame.height,
+			texture:  frame.texture,
+			tint:     tint,
+			soft:     effectSoftDistance,
+		})
+	}
+
+	if finished {
+		er.removeFinished()
+	}
+}
+
+// removeFinished drops one-shot effects that have played through.
+func (er *EffectRenderer) removeFinished() {
+	kept := er.effects[:0]
+	for _, effect := range er.effects {
+		if effect.oneShot && !effect.Visible {
+			continue
+		}
+		kept = append(kept, effect)
+	}
+	for i := len(kept); i < len(er.effects); i++ {
+		er.effects[i] = nil
+	}
+	er.effects = kept
+}
+
+func (er *EffectRenderer) clearEffects() {
+	for _, anim := range er.anims {
+		if anim == nil {
+			continue
+		}
+		for _, frame := range anim.frames {
+			if frame.texture != 0 {
+				gl.DeleteTextures(1, &frame.texture)
+			}
+		}
+	}
+	er.anims = make(map[string]*effectAnim)
+	er.effects = nil
+}
+
+// Destroy releases all resources.
+func (er *EffectRenderer) Destroy() {
+	er.clearEffects()
+}
@@ -0,0 +1,62 @@
+package scene
+
+import "testing"
+
+func TestMapEffectSprites(t *testing.T) {
+	tests := []struct {
+		id   int32
+		want string
+	}{
+		{44, "smoke"},
+		{47, "torch_01"},
+		{48, "spraypond"},
+		{0, ""}, // A hit effect
+		{-1, ""},
+	}
+	for _, tt := range tests {
+		name, ok := mapEffectSprites[tt.id]
+		if name != tt.want || ok != (tt.want != "") {
+			t.Errorf("effect %d = %q, %v; want %q", tt.id, name, ok, tt.want)
+		}
+	}
+}
+
+func TestEffectPhase(t *testing.T) {
+	const cycle = 480
+	seen := make(map[float32]bool)
+	for i := range 100 {
+		phase := effectPhase(i, cycle)
+		if phase < 0 || phase >= cycle {
+			t.Fatalf("effect %d phase %v outside [0, %v)", i, phase, cycle)
+		}
+		if seen[phase] {
+			t.Errorf("effect %d shares phase %v with an earlier one", i, phase)
+		}
+		seen[phase] = true
+	}
+}
+
+func TestEffectRemoveFinished(t *testing.T) {
+	anim := &effectAnim{frames: make([]effectFrame, 2), intervalMs: 100}
+	torch := &MapEffect{anim: anim, Visible: true}
+	culled := &MapEffect{anim: anim}
+	done := &MapEffect{anim: anim, oneShot: true}
+	playing := &MapEffect{anim: anim, oneShot: true, Visible: true}
+	er := &EffectRenderer{effects: []*MapEffect{torch, done, culled, playing}}
+	all := er.effects
+
+	er.removeFinished()
+	want := []*MapEffect{torch, culled, playing}
+	if len(er.effects) != len(want) {
+		t.Fatalf("%d effects left, want %d", len(er.effects), len(want))
+	}
+	for i, e := range want {
+		if er.effects[i] != e {
+			t.Errorf("effect %d is not the one kept", i)
+		}
+	}
+	// The slot freed doesn't hold on to the finished effect
+	if all[3] != nil {
+		t.Error("finished effect still referenced past the end")
+	}
+}
@@ -0,0 +1,230 @@
+package scene
+
+import (
+	"fmt"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/lighting"
+	"github.com/Faultbox/midgard-ro/internal/engine/terrain"
+	"github.com/Faultbox/midgard-ro/pkg/formats"
+)
+
+// MapInstance is a map loaded onto the GPU: its terrain, models, water and
+// sprite effects, with the lighting and ground data they are rendered and
+// queried with. A scene shows one instance and can hold a second loaded
+// ahead of time, so switching to that map is instant.
+type MapInstance struct {
+	// Name the map was preloaded under; empty for maps loaded with LoadMap
+	Name string
+
+	// Renderers
+	terrain *TerrainRenderer
+	models  *ModelRenderer
+	water   *WaterRenderer
+	effects *EffectRenderer
+
+	// Lighting
+	LightDir     [3]float32
+	AmbientColor [3]float32
+	DiffuseColor [3]float32
+	LightOpacity float32
+
+	// Point lights
+	PointLights []PointLight
+
+	// Map bounds
+	MinBounds [3]float32
+	MaxBounds [3]float32
+
+	// Map dimensions
+	MapWidth  float32
+	MapHeight float32
+
+	// Terrain height data
+	terrainAltitudes [][]float32
+	terrainTileZoom  float32
+	terrainTilesX    int
+	terrainTilesZ    int
+
+	// GAT collision data
+	GAT *formats.GAT
+}
+
+// newMapInstance creates an empty map under the default light. Its model
+// textures are streamed through textures, which a scene's instances share
+// so the textures two maps have in common are uploaded once.
+func newMapInstance(name string, textures *TextureStreamer) (*MapInstance, error) {
+	m := &MapInstance{
+		Name:         name,
+		LightDir:     [3]float32{0.5, 0.866, 0.0},
+		AmbientColor: [3]float32{0.3, 0.3, 0.3},
+		DiffuseColor: [3]float32{1.0, 1.0, 1.0},
+		LightOpacity: 1.0,
+		effects:      NewEffectRenderer(),
+	}
+
+	var err error
+	m.terrain, err = NewTerrainRenderer()
+	if err != nil {
+		m.Destroy()
+		return nil, fmt.Errorf("creating terrain renderer: %w", err)
+	}
+
+	m.models, err = NewModelRenderer(textures)
+	if err != nil {
+		m.Destroy()
+		return nil, fmt.Errorf("creating model renderer: %w", err)
+	}
+
+	m.water, err = NewWaterRenderer()
+	if err != nil {
+		m.Destroy()
+		return nil, fmt.Errorf("creating water renderer: %w", err)
+	}
+
+	return m, nil
+}
+
+// load loads terrain data from GND and RSW. fallbackTex stands in for
+// model textures that can't be loaded.
+func (m *MapInstance) load(gnd *formats.GND, rsw *formats.RSW, texLoader func(string) ([]byte, error), fallbackTex uint32) error {
+	// Store map dimensions
+	m.MapWidth = float32(gnd.Width) * gnd.Zoom
+	m.MapHeight = float32(gnd.Height) * gnd.Zoom
+
+	fmt.Printf("=== Scene LoadMap ===\n")
+	fmt.Printf("GND: %dx%d tiles, zoom=%.1f\n", gnd.Width, gnd.Height, gnd.Zoom)
+	fmt.Printf("Map size: %.0fx%.0f world units\n", m.MapWidth, m.MapHeight)
+
+	// Build heightmap for terrain height queries
+	hm := terrain.BuildHeightmap(gnd)
+	m.terrainAltitudes = hm.Altitudes
+	m.terrainTilesX = hm.TilesX
+	m.terrainTilesZ = hm.TilesZ
+	m.terrainTileZoom = hm.TileZoom
+
+	// Load GAT for collision
+	if rsw != nil && rsw.GndFile != "" {
+		gatPath := "data/" + rsw.GndFile
+		if len(gatPath) > 4 {
+			gatPath = gatPath[:len(gatPath)-4] + ".gat"
+		}
+		gatData, err := texLoader(gatPath)
+		if err == nil {
+			m.GAT, _ = formats.ParseGAT(gatData)
+		}
+	}
+
+	// Extract lighting from RSW
+	if rsw != nil {
+		m.LightDir = lighting.SunDirection(rsw.Light.Longitude, rsw.Light.Latitude)
+		m.AmbientColor = rsw.Light.Ambient
+		m.DiffuseColor = rsw.Light.Diffuse
+		m.LightOpacity = rsw.Light.Opacity
+		if m.LightOpacity <= 0 {
+			m.LightOpacity = 1.0
+		}
+		fmt.Printf("Lighting: LightDir(%.2f,%.2f,%.2f) Ambient(%.2f,%.2f,%.2f) Diffuse(%.2f,%.2f,%.2f) Opacity=%.2f\n",
+			m.LightDir[0], m.LightDir[1], m.LightDir[2],
+			m.AmbientColor[0], m.AmbientColor[1], m.AmbientColor[2],
+			m.DiffuseColor[0], m.DiffuseColor[1], m.DiffuseColor[2],
+			m.LightOpacity)
+
+		// Ensure minimum ambient
+		minAmbient := float32(0.3)
+		for i := 0; i < 3; i++ {
+			if m.AmbientColor[i] < minAmbient {
+				m.AmbientColor[i] = minAmbient
+			}
+		}
+
+		// Extract point lights
+		m.PointLights = RSWPointLights(rsw, m.MapWidth, m.MapHeight)
+	}
+
+	// Load terrain
+	if err := m.terrain.LoadTerrain(gnd, texLoader); err != nil {
+		return fmt.Errorf("loading terrain: %w", err)
+	}
+
+	// Get bounds from terrain
+	m.MinBounds = m.terrain.MinBounds
+	m.MaxBounds = m.terrain.MaxBounds
+	fmt.Printf("Terrain bounds: Min(%.0f,%.0f,%.0f) Max(%.0f,%.0f,%.0f)\n",
+		m.MinBounds[0], m.MinBounds[1], m.MinBounds[2],
+		m.MaxBounds[0], m.MaxBounds[1], m.MaxBounds[2])
+	layers, size := m.terrain.TextureStats()
+	fmt.Printf("Terrain groups: %d (1 draw call, %d texture layers at %dx%d)\n",
+		len(m.terrain.groups), layers, size, size)
+
+	// Load models
+	if rsw != nil {
+		models := rsw.GetModels()
+		fmt.Printf("RSW has %d models\n", len(models))
+		if err := m.models.LoadModels(rsw, texLoader, fallbackTex, m.MapWidth, m.MapHeight, m.terrainAltitudes, m.terrainTileZoom, m.terrainTilesX, m.terrainTilesZ); err != nil {
+			return fmt.Errorf("loading models: %w", err)
+		}
+		fmt.Printf("Loaded %d models\n", len(m.models.models))
+		tex := m.models.textures.Stats()
+		fmt.Printf("Model textures: %d (%d KB uploaded of %d KB at full resolution)\n",
+			tex.Textures, tex.Resident>>10, tex.FullBytes>>10)
+
+		m.effects.LoadEffects(rsw, texLoader, m.MapWidth, m.MapHeight)
+		fmt.Printf("Loaded %d sprite effects (of %d RSW effects)\n", m.effects.Count(), len(rsw.GetEffects()))
+	}
+
+	// Load water
+	if rsw != nil && rsw.Water.Level > 0 {
+		m.water.SetupWater(rsw.Water.Level, m.MinBounds, m.MaxBounds, texLoader)
+	}
+
+	return nil
+}
+
+// RSWPointLights returns an RSW's light sources as point lights in world
+// coordinates on a mapWidth x mapHeight map, up to MaxPointLights. Colors
+// are clamped to 0-1, and lights without a range are given 100.
+func RSWPointLights(rsw *formats.RSW, mapWidth, mapHeight float32) []PointLight {
+	var lights []PointLight
+	for _, light := range rsw.GetLights() {
+		if len(lights) == MaxPointLights {
+			break
+		}
+		pl := PointLight{
+			// RSW positions are centered on the map
+			Position:  [3]float32{light.Position[0] + mapWidth/2, light.Position[1], light.Position[2] + mapHeight/2},
+			Range:     light.Range,
+			Intensity: 1.0,
+		}
+		for i, c := range light.Color {
+			pl.Color[i] = min(max(c, 0), 1)
+		}
+		if pl.Range <= 0 {
+			pl.Range = 100
+		}
+		lights = append(lights, pl)
+	}
+	return lights
+}
+
+// applyTextureFilter re-applies the texture filter to the ground and water
+// textures; model textures are the scene's streamer's.
+func (m *MapInstance) applyTextureFilter() {
+	m.terrain.ApplyTextureFilter()
+	m.water.ApplyTextureFilter()
+}
+
+// Destroy releases the map's GPU resources.
+func (m *MapInstance) Destroy() {
+	if m.terrain != nil {
+		m.terrain.Destroy()
+	}
+	if m.models != nil {
+		m.models.Destroy()
+	}
+	if m.water != nil {
+		m.water.Destroy()
+	}
+	if m.effects != nil {
+		m.effects.Destroy()
+	}
+}
@@ -0,0 +1,125 @@
+package scene
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Faultbox/midgard-ro/pkg/formats"
+)
+
+// testScene returns a scene showing a map, without GPU resources: its
+// instances have no renderers to release.
+func testScene() *Scene {
+	return &Scene{
+		MapInstance:   &MapInstance{MapWidth: 100},
+		decalRenderer: &DecalRenderer{},
+		auraRenderer:  &AuraRenderer{auras: make(map[uint32]*auraEntry)},
+		boardRenderer: &BoardRenderer{boards: make(map[uint32]*boardEntry)},
+	}
+}
+
+func TestScenePreload(t *testing.T) {
+	s := testScene()
+	shown := s.MapInstance
+	if got := s.Preloaded(); got != "" {
+		t.Fatalf("Preloaded() = %q before preloading", got)
+	}
+
+	first := &MapInstance{Name: "prontera"}
+	s.preload(first)
+	if got := s.Preloaded(); got != "prontera" {
+		t.Errorf("Preloaded() = %q, want prontera", got)
+	}
+
+	// A second preload replaces the first
+	next := &MapInstance{Name: "geffen", MapWidth: 200}
+	s.preload(next)
+	if got := s.Preloaded(); got != "geffen" {
+		t.Errorf("Preloaded() = %q, want geffen", got)
+	}
+	if s.MapInstance != shown || s.MapWidth != 100 {
+		t.Error("preloading changed the map shown")
+	}
+}
+
+func TestSceneShowPreloaded(t *testing.T) {
+	s := testScene()
+	now := time.Unix(1000, 0)
+	s.decalRenderer.Add(Decal{Shape: DecalRing}, now)
+	s.auraRenderer.Set(1, Aura{Styles: AuraBlue}, now)
+	s.boardRenderer.Set(1, Board{Text: "Potions"})
+	if n := s.decalRenderer.Count() + s.auraRenderer.Count() + s.boardRenderer.Count(); n != 3 {
+		t.Fatalf("%d decals, auras and boards placed, want 3", n)
+	}
+
+	next := &MapInstance{Name: "geffen", MapWidth: 200}
+	s.preload(next)
+	if s.ShowPreloaded("payon") {
+		t.Fatal("showed a map that isn't preloaded")
+	}
+	if s.MapWidth != 100 || s.Preloaded() != "geffen" {
+		t.Fatal("a failed ShowPreloaded changed the maps")
+	}
+
+	if !s.ShowPreloaded("geffen") {
+		t.Fatal("ShowPreloaded(geffen) = false")
+	}
+	if s.MapInstance != next || s.MapWidth != 200 {
+		t.Error("the map preloaded isn't shown")
+	}
+	if s.Preloaded() != "" {
+		t.Errorf("Preloaded() = %q after showing it", s.Preloaded())
+	}
+	if n := s.decalRenderer.Count() + s.auraRenderer.Count() + s.boardRenderer.Count(); n != 0 {
+		t.Errorf("%d decals, auras and boards of the map left still placed", n)
+	}
+	if s.ShowPreloaded("geffen") {
+		t.Error("showed a preloaded map twice")
+	}
+}
+
+func TestSceneDropPreloaded(t *testing.T) {
+	s := testScene()
+	shown := s.MapInstance
+	s.DropPreloaded() // Nothing preloaded
+
+	s.preload(&MapInstance{Name: "geffen"})
+	s.DropPreloaded()
+	if s.Preloaded() != "" {
+		t.Errorf("Preloaded() = %q after dropping it", s.Preloaded())
+	}
+	if s.ShowPreloaded("geffen") {
+		t.Error("showed a dropped map")
+	}
+	if s.MapInstance != shown {
+		t.Error("dropping changed the map shown")
+	}
+}
+
+func TestRSWPointLights(t *testing.T) {
+	light := func(l formats.RSWLightSource) formats.RSWObject {
+		return formats.RSWObject{Type: formats.RSWObjectLight, Light: &l}
+	}
+	rsw := &formats.RSW{Objects: []formats.RSWObject{
+		light(formats.RSWLightSource{Position: [3]float32{-10, 5, 20}, Color: [3]float32{0.5, 1.5, -1}, Range: 40}),
+		{Type: formats.RSWObjectModel, Model: &formats.RSWModel{}},
+		light(formats.RSWLightSource{Color: [3]float32{1, 1, 1}}),
+	}}
+
+	got := RSWPointLights(rsw, 200, 100)
+	want := []PointLight{
+		{Position: [3]float32{90, 5, 70}, Color: [3]float32{0.5, 1, 0}, Range: 40, Intensity: 1},
+		{Position: [3]float32{100, 0, 50}, Color: [3]float32{1, 1, 1}, Range: 100, Intensity: 1},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("RSWPointLights = %+v, want %+v", got, want)
+	}
+
+	for range MaxPointLights {
+		rsw.Objects = append(rsw.Objects, light(formats.RSWLightSource{Range: 10}))
+	}
+	if n := len(RSWPointLights(rsw, 200, 100)); n != MaxPointLights {
+		t.Errorf("%d point lights, want the %d supported", n, MaxPointLights)
+	}
+}
@@ -2,11 +2,10 @@
 package scene
 
 import (
-	"bytes"
 	"fmt"
-	"image"
 	gomath "math"
-	"strings"
+	"slices"
+	"time"
 	"unsafe"
 
 	"github.com/go-gl/gl/v4.1-core/gl"
@@ -15,7 +14,6 @@ import (
 	"github.com/Faultbox/midgard-ro/internal/engine/scene/shaders"
 	"github.com/Faultbox/midgard-ro/internal/engine/shader"
 	"github.com/Faultbox/midgard-ro/internal/engine/shadow"
-	"github.com/Faultbox/midgard-ro/internal/engine/texture"
 	"github.com/Faultbox/midgard-ro/pkg/formats"
 	"github.com/Faultbox/midgard-ro/pkg/math"
 )
@@ -32,9 +30,50 @@ type MapModel struct {
 	rotation   [3]float32
 	scale      [3]float32
 	modelName  string
+	bounds     math.AABB // World space
 	Visible    bool
+
+	// What the mesh was built from, for animation rebuilds
+	rsm         *formats.RSM
+	animated    bool
+	reverse     bool            // Faces wound in reverse, for a negative scale
+	animTime    float32         // Milliseconds into the animation the mesh is at
+	hidden      map[string]bool // Nodes left out of the mesh
+	selected    string          // Node whose faces are tinted
+	center      [2]float32      // X/Z offset the mesh was centered by
+	local       math.AABB       // Model space, after centering
+	vertexCount int
+	missing     []string // Textures that couldn't be loaded
 }
 
+// Name returns the RSM file the model was placed from.
+func (m *MapModel) Name() string { return m.modelName }
+
+// LocalBounds returns the model's bounds in model space, centered on X/Z
+// as it is drawn.
+func (m *MapModel) LocalBounds() math.AABB { return m.local }
+
+// VertexCount returns the number of vertices in the model's mesh.
+func (m *MapModel) VertexCount() int { return m.vertexCount }
+
+// MissingTextures returns the model's textures that couldn't be loaded and
+// are drawn with the fallback.
+func (m *MapModel) MissingTextures() []string { return m.missing }
+
+// Animated reports whether the model has keyframe animation.
+func (m *MapModel) Animated() bool { return m.animated }
+
+// Bounds returns the model's bounds in world space.
+func (m *MapModel) Bounds() math.AABB { return m.bounds }
+
+// Center returns the X/Z offset the model's mesh was centered by, so a
+// model placed at it sits where its RSM puts it.
+func (m *MapModel) Center() [2]float32 { return m.center }
+
+// selectionTint is the highlight of a model's selected node, mixed in by
+// its alpha.
+var selectionTint = [4]float32{1, 0.55, 0.1, 0.5}
+
 // ModelRenderer handles rendering of RSM models.
 type ModelRenderer struct {
 	// Shader
@@ -54,6 +93,7 @@ type ModelRenderer struct {
 	locLightViewProj  int32
 	locShadowMap      int32
 	locShadowsEnabled int32
+	locTint           int32
 
 	// Point light uniforms
 	locPointLightPositions   int32
@@ -66,6 +106,9 @@ type ModelRenderer struct {
 	// Models
 	models []*MapModel
 
+	// World bounds of models large enough to block the camera
+	blockers []math.AABB
+
 	// Map dimensions for coordinate conversion
 	mapWidth  float32
 	mapHeight float32
@@ -73,14 +116,23 @@ type ModelRenderer struct {
 	// Fallback texture
 	fallbackTex uint32
 
+	// Shared texture manager model textures are streamed through
+	textures *TextureStreamer
+
+	// Multiplier on every model's scale
+	modelScale float32
+
 	// Force all faces to render as two-sided
 	ForceAllTwoSided bool
 }
 
-// NewModelRenderer creates a new model renderer.
-func NewModelRenderer() (*ModelRenderer, error) {
+// NewModelRenderer creates a new model renderer loading its textures
+// through textures.
+func NewModelRenderer(textures *TextureStreamer) (*ModelRenderer, error) {
 	mr := &ModelRenderer{
 		ForceAllTwoSided: true,
+		textures:         textures,
+		modelScale:       1,
 	}
 
 	program, err := shader.CompileProgram(shaders.ModelVertexShader, shaders.ModelFragmentShader)
@@ -103,6 +155,7 @@ func NewModelRenderer() (*ModelRenderer, error) {
 	mr.locLightViewProj = shader.GetUniform(program, "uLightViewProj")
 	mr.locShadowMap = shader.GetUniform(program, "uShadowMap")
 	mr.locShadowsEnabled = shader.GetUniform(program, "uShadowsEnabled")
+	mr.locTint = shader.GetUniform(program, "uTint")
 
 	// Point light uniforms
 	mr.locPointLightPositions = shader.GetUniform(program, "uPointLightPositions")
@@ -119,10 +172,7 @@ func NewModelRenderer() (*ModelRenderer, error) {
 func (mr *ModelRenderer) LoadModels(rsw *formats.RSW, texLoader func(string) ([]byte, error), fallbackTex uint32,
 	mapWidth, mapHeight float32, terrainAltitudes [][]float32, terrainTileZoom float32, terrainTilesX, terrainTilesZ int) error {
 
-	mr.clearModels()
-	mr.fallbackTex = fallbackTex
-	mr.mapWidth = mapWidth
-	mr.mapHeight = mapHeight
+	mr.Reset(fallbackTex, mapWidth, mapHeight)
 
 	allModels := rsw.GetModels()
 
@@ -151,201 +201,221 @@ func (mr *ModelRenderer) LoadModels(rsw *formats.RSW, texLoader func(string) ([]
 			rsmCache[rsmPath] = rsm
 		}
 
-		mapModel := mr.buildMapModel(rsm, modelRef, texLoader)
-		if mapModel != nil {
-			mr.models = append(mr.models, mapModel)
-		}
+		mr.AddModel(rsm, modelRef, texLoader)
 	}
 
 	return nil
 }
 
-func (mr *ModelRenderer) buildMapModel(rsm *formats.RSM, ref *formats.RSWModel, texLoader func(string) ([]byte, error)) *MapModel {
-	if len(rsm.Nodes) == 0 {
+// Reset removes all models and sets up for placing a map's: RSW positions
+// are centered on a mapWidth x mapHeight map, and fallbackTex stands in for
+// textures that can't be loaded.
+func (mr *ModelRenderer) Reset(fallbackTex uint32, mapWidth, mapHeight float32) {
+	mr.clearModels()
+	mr.fallbackTex = fallbackTex
+	mr.mapWidth = mapWidth
+	mr.mapHeight = mapHeight
+}
+
+// AddModel places an RSM model as an RSW entry places it. Returns nil if
+// the model has no mesh.
+func (mr *ModelRenderer) AddModel(rsm *formats.RSM, ref *formats.RSWModel, texLoader func(string) ([]byte, error)) *MapModel {
+	model := mr.buildMapModel(rsm, ref, texLoader)
+	if model == nil {
 		return nil
 	}
-
-	var vertices []rsmmodel.Vertex
-	var indices []uint32
-	texGroups := make(map[int][]uint32)
-
-	// Load model textures
-	modelTextures := make([]uint32, len(rsm.Textures))
-	for i, texName := range rsm.Textures {
-		texPath := "data/texture/" + texName
-		data, err := texLoader(texPath)
-		if err != nil {
-			modelTextures[i] = mr.fallbackTex
-			continue
-		}
-		img, err := mr.decodeTexture(data, texPath)
-		if err != nil {
-			modelTextures[i] = mr.fallbackTex
-			continue
-		}
-		modelTextures[i] = mr.uploadTexture(img)
+	mr.models = append(mr.models, model)
+	if blocksCamera(model.bounds) {
+		mr.blockers = append(mr.blockers, model.bounds)
 	}
+	return model
+}
 
-	// Track bounding box
-	var minX, minY, minZ float32 = 1e10, 1e10, 1e10
-	var maxX, maxY, maxZ float32 = -1e10, -1e10, -1e10
-
-	// Process each node
-	reverseWinding := ref.Scale[0]*ref.Scale[1]*ref.Scale[2] < 0
+// RemoveModel removes a placed model and releases its resources.
+func (mr *ModelRenderer) RemoveModel(model *MapModel) {
+	i := slices.Index(mr.models, model)
+	if i < 0 {
+		return
+	}
+	mr.freeModel(model)
+	mr.models = slices.Delete(mr.models, i, i+1)
+	mr.updateBlockers()
+}
 
-	for i := range rsm.Nodes {
-		node := &rsm.Nodes[i]
-		nodeMatrix := rsmmodel.BuildNodeMatrix(node, rsm, 0)
+// SetTransform moves, rotates and scales a placed model, in RSW
+// coordinates.
+func (mr *ModelRenderer) SetTransform(model *MapModel, position, rotation, scale [3]float32) {
+	model.position, model.rotation, model.scale = position, rotation, scale
+	model.bounds = model.local.Transform(mr.buildModelMatrix(model, mr.mapWidth/2, mr.mapHeight/2))
+	mr.updateBlockers()
+}
 
-		for _, face := range node.Faces {
-			if len(face.VertexIDs) < 3 {
-				continue
-			}
+// SetModelScale sets a multiplier on every model's scale.
+func (mr *ModelRenderer) SetModelScale(scale float32) {
+	if scale == mr.modelScale {
+		return
+	}
+	mr.modelScale = scale
+	for _, model := range mr.models {
+		model.bounds = model.local.Transform(mr.buildModelMatrix(model, mr.mapWidth/2, mr.mapHeight/2))
+	}
+	mr.updateBlockers()
+}
 
-			// Bounds check
-			validFace := true
-			for _, vid := range face.VertexIDs {
-				if int(vid) >= len(node.Vertices) {
-					validFace = false
-					break
-				}
-			}
-			if !validFace {
-				continue
-			}
+// updateBlockers recollects the models that block the camera.
+func (mr *ModelRenderer) updateBlockers() {
+	mr.blockers = mr.blockers[:0]
+	for _, model := range mr.models {
+		if blocksCamera(model.bounds) {
+			mr.blockers = append(mr.blockers, model.bounds)
+		}
+	}
+}
 
-			// Calculate normal
-			v0 := node.Vertices[face.VertexIDs[0]]
-			v1 := node.Vertices[face.VertexIDs[1]]
-			v2 := node.Vertices[face.VertexIDs[2]]
-			e1 := [3]float32{v1[0] - v0[0], v1[1] - v0[1], v1[2] - v0[2]}
-			e2 := [3]float32{v2[0] - v0[0], v2[1] - v0[1], v2[2] - v0[2]}
-			normalVec := rsmmodel.Cross(e1, e2)
+// Animate rebuilds an animated model's mesh at timeMs into its animation,
+// looping it.
+func (mr *ModelRenderer) Animate(model *MapModel, timeMs float32) {
+	if model.rsm.AnimLength > 0 {
+		timeMs = float32(int(timeMs) % int(model.rsm.AnimLength))
+	}
+	mr.Pose(model, timeMs)
+}
 
-			normalMag := float32(gomath.Sqrt(float64(normalVec[0]*normalVec[0] + normalVec[1]*normalVec[1] + normalVec[2]*normalVec[2])))
-			if normalMag < 1e-5 {
-				continue
-			}
-			normal := [3]float32{normalVec[0] / normalMag, normalVec[1] / normalMag, normalVec[2] / normalMag}
+// Pose rebuilds an animated model's mesh at timeMs into its animation,
+// without looping it.
+func (mr *ModelRenderer) Pose(model *MapModel, timeMs float32) {
+	if !model.animated || model.vao == 0 {
+		return
+	}
+	model.animTime = timeMs
+	mr.rebuild(model)
+}
 
-			addFaceVertices := func(reverseOrder bool, flipNormal bool) uint32 {
-				faceBaseIdx := uint32(len(vertices))
-				faceNormal := normal
-				if flipNormal {
-					faceNormal = [3]float32{-normal[0], -normal[1], -normal[2]}
-				}
+// SetNodes rebuilds a model's mesh without its hidden nodes and with the
+// selected node's faces tinted; "" selects none. Its bounds shrink to the
+// nodes left.
+func (mr *ModelRenderer) SetNodes(model *MapModel, hidden map[string]bool, selected string) {
+	if model.vao == 0 {
+		return
+	}
+	model.hidden, model.selected = hidden, selected
+	if mesh := mr.rebuild(model); mesh != nil {
+		model.local = toAABB(mesh.Bounds)
+		model.bounds = model.local.Transform(mr.buildModelMatrix(model, mr.mapWidth/2, mr.mapHeight/2))
+		mr.updateBlockers()
+	}
+}
 
-				var vertIDs, texIDs [3]uint16
-				if reverseOrder {
-					vertIDs = [3]uint16{face.VertexIDs[2], face.VertexIDs[1], face.VertexIDs[0]}
-					texIDs = [3]uint16{face.TexCoordIDs[2], face.TexCoordIDs[1], face.TexCoordIDs[0]}
-				} else {
-					vertIDs = face.VertexIDs
-					texIDs = face.TexCoordIDs
-				}
+// rebuild builds a model's mesh again as its animation time and nodes
+// say and uploads it. A model with no faces left draws nothing. Returns
+// the mesh, or nil if it has no faces.
+func (mr *ModelRenderer) rebuild(model *MapModel) *rsmmodel.Mesh {
+	mesh := rsmmodel.BuildMesh(model.rsm, rsmmodel.BuildOptions{
+		ReverseWinding:   model.reverse,
+		ForceAllTwoSided: mr.ForceAllTwoSided,
+		AnimTimeMs:       model.animTime,
+		HiddenNodes:      model.hidden,
+		SelectedNode:     model.selected,
+	})
+	if mesh == nil || len(mesh.Indices) == 0 {
+		model.indexCount, model.vertexCount, model.texGroups = 0, 0, nil
+		return nil
+	}
+	// Centered as the first build was, so the model stays in place
+	for i := range mesh.Vertices {
+		mesh.Vertices[i].Position[0] -= model.center[0]
+		mesh.Vertices[i].Position[2] -= model.center[1]
+	}
+	mesh.Bounds.Min[0] -= model.center[0]
+	mesh.Bounds.Max[0] -= model.center[0]
+	mesh.Bounds.Min[2] -= model.center[1]
+	mesh.Bounds.Max[2] -= model.center[1]
 
-				for j := 0; j < 3; j++ {
-					vid := vertIDs[j]
-					v := node.Vertices[vid]
-					pos := rsmmodel.TransformPoint(nodeMatrix, v)
-					pos[1] = -pos[1]
-
-					// Track bounds
-					if pos[0] < minX {
-						minX = pos[0]
-					}
-					if pos[0] > maxX {
-						maxX = pos[0]
-					}
-					if pos[1] < minY {
-						minY = pos[1]
-					}
-					if pos[1] > maxY {
-						maxY = pos[1]
-					}
-					if pos[2] < minZ {
-						minZ = pos[2]
-					}
-					if pos[2] > maxZ {
-						maxZ = pos[2]
-					}
-
-					var uv [2]float32
-					if int(texIDs[j]) < len(node.TexCoords) {
-						tc := node.TexCoords[texIDs[j]]
-						uv = [2]float32{tc.U, tc.V}
-					}
-
-					vertices = append(vertices, rsmmodel.Vertex{
-						Position: pos,
-						Normal:   faceNormal,
-						TexCoord: uv,
-					})
-				}
-				return faceBaseIdx
-			}
+	gl.BindVertexArray(model.vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, model.vbo)
+	gl.BufferData(gl.ARRAY_BUFFER, len(mesh.Vertices)*int(unsafe.Sizeof(rsmmodel.Vertex{})),
+		unsafe.Pointer(&mesh.Vertices[0]), gl.DYNAMIC_DRAW)
+	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, model.ebo)
+	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(mesh.Indices)*4, unsafe.Pointer(&mesh.Indices[0]), gl.DYNAMIC_DRAW)
+	gl.BindVertexArray(0)
 
-			faceBaseIdx := addFaceVertices(reverseWinding, false)
-			globalTexIdx := 0
-			if int(face.TextureID) < len(node.TextureIDs) {
-				globalTexIdx = int(node.TextureIDs[face.TextureID])
-			}
-			texGroups[globalTexIdx] = append(texGroups[globalTexIdx], faceBaseIdx, faceBaseIdx+1, faceBaseIdx+2)
+	model.indexCount = int32(len(mesh.Indices))
+	model.vertexCount = len(mesh.Vertices)
+	model.texGroups = mesh.Groups
+	return mesh
+}
 
-			// Add back face for two-sided
-			if face.TwoSide != 0 || mr.ForceAllTwoSided {
-				backFaceBaseIdx := addFaceVertices(!reverseWinding, true)
-				texGroups[globalTexIdx] = append(texGroups[globalTexIdx], backFaceBaseIdx, backFaceBaseIdx+1, backFaceBaseIdx+2)
-			}
-		}
+// Memory returns the GPU memory the placed models' meshes hold. Their
+// textures are the texture streamer's.
+func (mr *ModelRenderer) Memory() Memory {
+	var m Memory
+	vertexSize := int(unsafe.Sizeof(rsmmodel.Vertex{}))
+	for _, model := range mr.models {
+		m.addBuffer(model.vertexCount * vertexSize)
+		m.addBuffer(int(model.indexCount) * 4)
 	}
+	return m
+}
 
-	if len(vertices) == 0 {
+func (mr *ModelRenderer) buildMapModel(rsm *formats.RSM, ref *formats.RSWModel, texLoader func(string) ([]byte, error)) *MapModel {
+	reverse := ref.Scale[0]*ref.Scale[1]*ref.Scale[2] < 0
+	mesh := rsmmodel.BuildMesh(rsm, rsmmodel.BuildOptions{
+		ReverseWinding:   reverse,
+		ForceAllTwoSided: mr.ForceAllTwoSided,
+	})
+	if mesh == nil || len(mesh.Indices) == 0 {
 		return nil
 	}
+	centerX, centerZ := rsmmodel.CenterMeshXZ(mesh.Vertices, &mesh.Bounds)
 
-	// Center model
-	centerX := (minX + maxX) / 2
-	centerZ := (minZ + maxZ) / 2
-	for i := range vertices {
-		vertices[i].Position[0] -= centerX
-		vertices[i].Position[2] -= centerZ
-	}
-
-	// Build texture groups
-	var groups []rsmmodel.TextureGroup
-	for texIdx, idxs := range texGroups {
-		if len(idxs) == 0 {
+	// Load model textures
+	var missing []string
+	modelTextures := make([]uint32, len(rsm.Textures))
+	for i, texName := range rsm.Textures {
+		tex, err := mr.textures.Acquire("data/texture/"+texName, texLoader)
+		if err != nil {
+			modelTextures[i] = mr.fallbackTex
+			missing = append(missing, texName)
 			continue
 		}
-		groups = append(groups, rsmmodel.TextureGroup{
-			TextureIdx: texIdx,
-			StartIndex: int32(len(indices)),
-			IndexCount: int32(len(idxs)),
-		})
-		indices = append(indices, idxs...)
+		modelTextures[i] = tex
 	}
 
-	// Smooth normals
-	rsmmodel.SmoothNormals(vertices)
-
 	// Create GPU resources
 	model := &MapModel{
 		textures:  modelTextures,
-		texGroups: groups,
+		texGroups: mesh.Groups,
 		position:  ref.Position,
 		rotation:  ref.Rotation,
 		scale:     ref.Scale,
 		modelName: ref.ModelName,
 		Visible:   true,
+
+		rsm:         rsm,
+		animated:    rsmmodel.HasAnimation(rsm),
+		reverse:     reverse,
+		center:      [2]float32{centerX, centerZ},
+		vertexCount: len(mesh.Vertices),
+		missing:     missing,
 	}
 
+	model.local = toAABB(mesh.Bounds)
+	model.bounds = model.local.Transform(mr.buildModelMatrix(model, mr.mapWidth/2, mr.mapHeight/2))
+
 	// Upload mesh
-	mr.uploadMesh(model, vertices, indices)
+	mr.uploadMesh(model, mesh.Vertices, mesh.Indices)
 
 	return model
 }
 
+// toAABB converts mesh bounds to a box.
+func toAABB(b rsmmodel.Bounds) math.AABB {
+	return math.AABB{
+		Min: math.Vec3{X: b.Min[0], Y: b.Min[1], Z: b.Min[2]},
+		Max: math.Vec3{X: b.Max[0], Y: b.Max[1], Z: b.Max[2]},
+	}
+}
+
 func (mr *ModelRenderer) uploadMesh(model *MapModel, vertices []rsmmodel.Vertex, indices []uint32) {
 	gl.GenVertexArrays(1, &model.vao)
 	gl.BindVertexArray(model.vao)
@@ -373,36 +443,6 @@ func (mr *ModelRenderer) uploadMesh(model *MapModel, vertices []rsmmodel.Vertex,
 	gl.BindVertexArray(0)
 }
 
-func (mr *ModelRenderer) decodeTexture(data []byte, path string) (*image.RGBA, error) {
-	lowerPath := strings.ToLower(path)
-	var img image.Image
-	var err error
-	if strings.HasSuffix(lowerPath, ".tga") {
-		img, err = texture.DecodeTGA(data)
-	} else {
-		img, _, err = image.Decode(bytes.NewReader(data))
-	}
-	if err != nil {
-		return nil, err
-	}
-	return texture.ImageToRGBA(img, true), nil
-}
-
-func (mr *ModelRenderer) uploadTexture(img *image.RGBA) uint32 {
-	var texID uint32
-	gl.GenTextures(1, &texID)
-	gl.BindTexture(gl.TEXTURE_2D, texID)
-	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA, int32(img.Bounds().Dx()), int32(img.Bounds().Dy()), 0, gl.RGBA, gl.UNSIGNED_BYTE, unsafe.Pointer(&img.Pix[0]))
-	gl.GenerateMipmap(gl.TEXTURE_2D)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR_MIPMAP_LINEAR)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.REPEAT)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.REPEAT)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAX_LEVEL, 4)
-	gl.TexParameterf(gl.TEXTURE_2D, gl.TEXTURE_MAX_ANISOTROPY, 8.0)
-	return texID
-}
-
 // Render renders all visible models.
 func (mr *ModelRenderer) Render(viewProj math.Mat4, lightDir, ambient, diffuse [3]float32,
 	shadowsEnabled bool, lightViewProj math.Mat4, shadowMap *shadow.Map,
@@ -476,6 +516,8 @@ func (mr *ModelRenderer) Render(viewProj math.Mat4, lightDir, ambient, diffuse [
 
 	gl.ActiveTexture(gl.TEXTURE0)
 	gl.Uniform1i(mr.locTexture, 0)
+	gl.Uniform4f(mr.locTint, 0, 0, 0, 0)
+	tinted := false
 
 	// Render each model
 	offsetX := mr.mapWidth / 2
@@ -502,6 +544,14 @@ func (mr *ModelRenderer) Render(viewProj math.Mat4, lightDir, ambient, diffuse [
 				tex = model.textures[group.TextureIdx]
 			}
 			gl.BindTexture(gl.TEXTURE_2D, tex)
+			if group.Selected != tinted {
+				tint := [4]float32{}
+				if group.Selected {
+					tint = selectionTint
+				}
+				gl.Uniform4fv(mr.locTint, 1, &tint[0])
+				tinted = group.Selected
+			}
 			gl.DrawElementsWithOffset(gl.TRIANGLES, group.IndexCount, gl.UNSIGNED_INT, uintptr(group.StartIndex*4))
 		}
 	}
@@ -527,11 +577,65 @@ func (mr *ModelRenderer) buildModelMatrix(model *MapModel, offsetX, offsetZ floa
 	result = result.Mul(math.RotateZ(float32(rotZ)))
 
 	// Scale
-	result = result.Mul(math.Scale(model.scale[0], model.scale[1], model.scale[2]))
+	s := mr.modelScale
+	result = result.Mul(math.Scale(model.scale[0]*s, model.scale[1]*s, model.scale[2]*s))
 
 	return result
 }
 
+// RequestTextures tells the texture streamer how large each visible
+// model's textures are on a width x height viewport, so the ones seen up
+// close refine first.
+func (mr *ModelRenderer) RequestTextures(viewProj math.Mat4, width, height float32, now time.Time) {
+	for _, model := range mr.models {
+		if model == nil || !model.Visible {
+			continue
+		}
+		size := screenSize(model.bounds, viewProj, width, height)
+		if size <= 0 {
+			continue
+		}
+		for _, group := range model.texGroups {
+			if group.TextureIdx >= 0 && group.TextureIdx < len(model.textures) {
+				mr.textures.Request(model.textures[group.TextureIdx], size, now)
+			}
+		}
+	}
+}
+
+// screenSize returns how many pixels a world box spans at its largest on
+// a width x height viewport, or 0 if it's off screen. A box reaching
+// behind the camera counts as filling the viewport.
+func screenSize(b math.AABB, viewProj math.Mat4, width, height float32) float32 {
+	minX, minY := float32(gomath.MaxFloat32), float32(gomath.MaxFloat32)
+	maxX, maxY := -minX, -minY
+	for i := range 8 {
+		corner := math.Vec4{b.Min.X, b.Min.Y, b.Min.Z, 1}
+		if i&1 != 0 {
+			corner[0] = b.Max.X
+		}
+		if i&2 != 0 {
+			corner[1] = b.Max.Y
+		}
+		if i&4 != 0 {
+			corner[2] = b.Max.Z
+		}
+		clip := viewProj.MulVec4(corner)
+		if clip[3] <= 1e-4 {
+			return max(width, height)
+		}
+		x, y := clip[0]/clip[3], clip[1]/clip[3]
+		minX, maxX = min(minX, x), max(maxX, x)
+		minY, maxY = min(minY, y), max(maxY, y)
+	}
+	if maxX < -1 || minX > 1 || maxY < -1 || minY > 1 {
+		return 0
+	}
+	w := (min(maxX, 1) - max(minX, -1)) / 2 * width
+	h := (min(maxY, 1) - max(minY, -1)) / 2 * height
+	return max(w, h)
+}
+
 // RenderShadow renders all models to the shadow map.
 func (mr *ModelRenderer) RenderShadow(shadowProgram uint32, locModel int32) {
 	offsetX := mr.mapWidth / 2
@@ -553,25 +657,34 @@ func (mr *ModelRenderer) RenderShadow(shadowProgram uint32, locModel int32) {
 
 func (mr *ModelRenderer) clearModels() {
 	for _, model := range mr.models {
-		if model == nil {
-			continue
-		}
-		if model.vao != 0 {
-			gl.DeleteVertexArrays(1, &model.vao)
-		}
-		if model.vbo != 0 {
-			gl.DeleteBuffers(1, &model.vbo)
-		}
-		if model.ebo != 0 {
-			gl.DeleteBuffers(1, &model.ebo)
-		}
-		for _, tex := range model.textures {
-			if tex != 0 && tex != mr.fallbackTex {
-				gl.DeleteTextures(1, &tex)
-			}
+		if model != nil {
+			mr.freeModel(model)
 		}
 	}
 	mr.models = nil
+	mr.blockers = nil
+}
+
+// freeModel releases a model's mesh and its hold on its textures.
+func (mr *ModelRenderer) freeModel(model *MapModel) {
+	if model.vao != 0 {
+		gl.DeleteVertexArrays(1, &model.vao)
+		model.vao = 0
+	}
+	if model.vbo != 0 {
+		gl.DeleteBuffers(1, &model.vbo)
+		model.vbo = 0
+	}
+	if model.ebo != 0 {
+		gl.DeleteBuffers(1, &model.ebo)
+		model.ebo = 0
+	}
+	for _, tex := range model.textures {
+		if tex != mr.fallbackTex {
+			mr.textures.Release(tex)
+		}
+	}
+	model.textures = nil
 }
 
 // Destroy releases all resources.
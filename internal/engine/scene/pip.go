@@ -0,0 +1,104 @@
+package scene
+
+import (
+	"fmt"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/framebuffer"
+	"github.com/Faultbox/midgard-ro/internal/engine/shadow"
+	"github.com/Faultbox/midgard-ro/pkg/math"
+)
+
+// PiPMode selects the camera used for the picture-in-picture debug view.
+type PiPMode int
+
+const (
+	PiPOff     PiPMode = iota
+	PiPTopDown         // Orthographic tactical view centered on the target
+	PiPSunView         // The shadow map's light camera, for shadow debugging
+)
+
+// String returns a display name for the mode.
+func (m PiPMode) String() string {
+	switch m {
+	case PiPTopDown:
+		return "top-down"
+	case PiPSunView:
+		return "sun"
+	default:
+		return "off"
+	}
+}
+
+// Next returns the mode after m, wrapping back to PiPOff.
+func (m PiPMode) Next() PiPMode {
+	return (m + 1) % (PiPSunView + 1)
+}
+
+const (
+	// PiP render target size in pixels
+	pipWidth  = 320
+	pipHeight = 240
+
+	// Top-down view: half-height of the visible area and camera altitude, in world units
+	pipTopDownRadius = 150.0
+	pipTopDownHeight = 1000.0
+)
+
+// pictureInPicture holds the secondary view's render target.
+type pictureInPicture struct {
+	mode        PiPMode
+	framebuffer *framebuffer.Framebuffer
+}
+
+// SetPiPMode sets the picture-in-picture camera. The render target is
+// created on first use.
+func (s *Scene) SetPiPMode(mode PiPMode) error {
+	if mode != PiPOff && s.pip.framebuffer == nil {
+		fb, err := framebuffer.New(pipWidth, pipHeight)
+		if err != nil {
+			return fmt.Errorf("creating pip framebuffer: %w", err)
+		}
+		s.pip.framebuffer = fb
+	}
+	s.pip.mode = mode
+	return nil
+}
+
+// PiPMode returns the current picture-in-picture camera.
+func (s *Scene) PiPMode() PiPMode {
+	return s.pip.mode
+}
+
+// RenderPiP renders the picture-in-picture view around a world target and
+// returns its color texture, or 0 when disabled. Call it after the main
+// render so the shadow map is up to date.
+func (s *Scene) RenderPiP(targetX, targetY, targetZ float32, extras func(viewProj math.Mat4)) uint32 {
+	if s.pip.mode == PiPOff || s.pip.framebuffer == nil {
+		return 0
+	}
+	view, proj := s.pipCamera(math.Vec3{X: targetX, Y: targetY, Z: targetZ})
+	return s.RenderCamera(view, proj, s.pip.framebuffer, extras)
+}
+
+// PiPTexture returns the last rendered picture-in-picture texture, or 0 when disabled.
+func (s *Scene) PiPTexture() uint32 {
+	if s.pip.mode == PiPOff || s.pip.framebuffer == nil {
+		return 0
+	}
+	return s.pip.framebuffer.ColorTexture()
+}
+
+// pipCamera returns the view and projection matrices for the current mode.
+func (s *Scene) pipCamera(target math.Vec3) (view, proj math.Mat4) {
+	if s.pip.mode == PiPSunView {
+		return shadow.DirectionalLightView(s.LightDir, shadow.AABB{Min: s.MinBounds, Max: s.MaxBounds})
+	}
+
+	// Looking straight down, so -Z (map north) is up in the view
+	eye := math.Vec3{X: target.X, Y: target.Y + pipTopDownHeight, Z: target.Z}
+	view = math.LookAt(eye, target, math.Vec3{X: 0, Y: 0, Z: -1})
+	halfH := float32(pipTopDownRadius)
+	halfW := halfH * pipWidth / pipHeight
+	proj = math.Ortho(-halfW, halfW, -halfH, halfH, 1.0, pipTopDownHeight*2)
+	return view, proj
+}
@@ -4,16 +4,23 @@ package scene
 
 import (
 	"fmt"
+	"image"
+	"time"
 
 	"github.com/go-gl/gl/v4.1-core/gl"
 
 	"github.com/Faultbox/midgard-ro/internal/engine/camera"
+	"github.com/Faultbox/midgard-ro/internal/engine/clock"
 	"github.com/Faultbox/midgard-ro/internal/engine/framebuffer"
-	"github.com/Faultbox/midgard-ro/internal/engine/lighting"
+	"github.com/Faultbox/midgard-ro/internal/engine/glstate"
+	"github.com/Faultbox/midgard-ro/internal/engine/perf"
+	"github.com/Faultbox/midgard-ro/internal/engine/picking"
+	"github.com/Faultbox/midgard-ro/internal/engine/postfx"
 	"github.com/Faultbox/midgard-ro/internal/engine/scene/shaders"
 	"github.com/Faultbox/midgard-ro/internal/engine/shader"
 	"github.com/Faultbox/midgard-ro/internal/engine/shadow"
 	"github.com/Faultbox/midgard-ro/internal/engine/terrain"
+	"github.com/Faultbox/midgard-ro/internal/engine/texfilter"
 	"github.com/Faultbox/midgard-ro/pkg/formats"
 	"github.com/Faultbox/midgard-ro/pkg/math"
 )
@@ -29,6 +36,30 @@ type PointLight struct {
 	Intensity float32
 }
 
+// Memory is what a renderer holds on the GPU: its textures and buffers,
+// and their estimated size in bytes.
+type Memory struct {
+	Textures, Buffers         int
+	TextureBytes, BufferBytes int64
+}
+
+// addTexture counts a texture of the given size and layers, RGBA, with a
+// third more for mipmaps.
+func (m *Memory) addTexture(width, height, layers int, mipmaps bool) {
+	n := int64(width) * int64(height) * int64(layers) * 4
+	if mipmaps {
+		n += n / 3
+	}
+	m.Textures++
+	m.TextureBytes += n
+}
+
+// addBuffer counts a buffer of n bytes.
+func (m *Memory) addBuffer(n int) {
+	m.Buffers++
+	m.BufferBytes += int64(n)
+}
+
 // Config contains scene configuration options.
 type Config struct {
 	Width              int32
@@ -59,11 +90,26 @@ type Scene struct {
 	// Framebuffer for offscreen rendering
 	framebuffer *framebuffer.Framebuffer
 
+	// Post-processing over the main render, and the texture it produced
+	PostFX postfx.Settings
+	post   *postfx.Chain
+	output uint32
+
+	// The map shown, whose lighting, bounds and ground data are the
+	// scene's, and a map loaded ahead to switch to (nil for none)
+	*MapInstance
+	preloaded *MapInstance
+
 	// Renderers
-	terrainRenderer *TerrainRenderer
-	modelRenderer   *ModelRenderer
-	waterRenderer   *WaterRenderer
-	spriteRenderer  *SpriteRenderer
+	spriteRenderer *SpriteRenderer
+	boardRenderer  *BoardRenderer
+	decalRenderer  *DecalRenderer
+	auraRenderer   *AuraRenderer
+
+	// Transparent billboards of the render in progress, drawn back to
+	// front, and each target's depth for them to fade into
+	transparent transparencyQueue
+	depthCopies map[*framebuffer.Framebuffer]*depthCopy
 
 	// Shadow mapping
 	shadowMap              *shadow.Map
@@ -72,17 +118,16 @@ type Scene struct {
 	locShadowModel         int32
 
 	// Lighting
-	LightDir     [3]float32
-	AmbientColor [3]float32
-	DiffuseColor [3]float32
-	LightOpacity float32
-	Brightness   float32
+	Brightness float32
 
 	// Point lights
-	PointLights         []PointLight
 	PointLightsEnabled  bool
 	PointLightIntensity float32
 
+	// Time of day
+	Darkness   float32 // 0 in full day to 1 in full night
+	NightLamps bool    // Point lights are lamps, lit only at night
+
 	// Fog settings
 	FogEnabled bool
 	FogNear    float32
@@ -93,46 +138,38 @@ type Scene struct {
 	ShadowsEnabled bool
 	lightViewProj  math.Mat4
 
-	// Last computed view-projection matrix (set by RenderWithView).
+	// Secondary debug view (picture-in-picture)
+	pip pictureInPicture
+
+	// Last computed view-projection matrix (set by Render).
 	// Exposed for picking — see LastViewProj().
 	lastViewProj math.Mat4
-
-	// Map bounds
-	MinBounds [3]float32
-	MaxBounds [3]float32
-
-	// Map dimensions
-	MapWidth  float32
-	MapHeight float32
-
-	// Terrain height data
-	terrainAltitudes [][]float32
-	terrainTileZoom  float32
-	terrainTilesX    int
-	terrainTilesZ    int
-
-	// GAT collision data
-	GAT *formats.GAT
+	lastView     math.Mat4 // Orients billboards for PickBoard
 
 	// Fallback texture
 	fallbackTex uint32
+
+	// Recycled sprite textures for entities
+	entityTextures *TexturePool
+
+	// Streamed model textures
+	textures *TextureStreamer
 }
 
 // New creates a new scene with the given configuration.
 func New(cfg Config) (*Scene, error) {
 	s := &Scene{
-		config: cfg,
-		// Default lighting
-		LightDir:     [3]float32{0.5, 0.866, 0.0},
-		AmbientColor: [3]float32{0.3, 0.3, 0.3},
-		DiffuseColor: [3]float32{1.0, 1.0, 1.0},
-		LightOpacity: 1.0,
-		Brightness:   1.0,
+		config:     cfg,
+		Brightness: 1.0,
 		// Shadow/light settings
 		ShadowsEnabled:      cfg.ShadowsEnabled,
 		PointLightsEnabled:  cfg.PointLightsEnabled,
 		PointLightIntensity: 1.0,
 		FogEnabled:          cfg.FogEnabled,
+		PostFX:              postfx.DefaultSettings,
+		entityTextures:      NewTexturePool(DefaultTexturePoolSize),
+		textures:            NewTextureStreamer(),
+		depthCopies:         make(map[*framebuffer.Framebuffer]*depthCopy),
 	}
 
 	// Create framebuffer
@@ -142,6 +179,12 @@ func New(cfg Config) (*Scene, error) {
 		return nil, fmt.Errorf("creating framebuffer: %w", err)
 	}
 
+	s.post, err = postfx.New(cfg.Width, cfg.Height)
+	if err != nil {
+		s.Destroy()
+		return nil, fmt.Errorf("creating post-processing: %w", err)
+	}
+
 	// Create shadow map
 	s.shadowMap = shadow.NewMap(cfg.ShadowResolution)
 	if s.shadowMap == nil {
@@ -155,30 +198,32 @@ func New(cfg Config) (*Scene, error) {
 	}
 
 	// Create renderers
-	s.terrainRenderer, err = NewTerrainRenderer()
+	s.MapInstance, err = newMapInstance("", s.textures)
 	if err != nil {
 		s.Destroy()
-		return nil, fmt.Errorf("creating terrain renderer: %w", err)
+		return nil, err
 	}
 
-	s.modelRenderer, err = NewModelRenderer()
+	s.spriteRenderer, err = NewSpriteRenderer()
 	if err != nil {
 		s.Destroy()
-		return nil, fmt.Errorf("creating model renderer: %w", err)
+		return nil, fmt.Errorf("creating sprite renderer: %w", err)
 	}
 
-	s.waterRenderer, err = NewWaterRenderer()
+	s.decalRenderer, err = NewDecalRenderer(s.groundHeight)
 	if err != nil {
 		s.Destroy()
-		return nil, fmt.Errorf("creating water renderer: %w", err)
+		return nil, fmt.Errorf("creating decal renderer: %w", err)
 	}
 
-	s.spriteRenderer, err = NewSpriteRenderer()
+	s.auraRenderer, err = NewAuraRenderer()
 	if err != nil {
 		s.Destroy()
-		return nil, fmt.Errorf("creating sprite renderer: %w", err)
+		return nil, fmt.Errorf("creating aura renderer: %w", err)
 	}
 
+	s.boardRenderer = NewBoardRenderer()
+
 	// Create fallback texture
 	s.createFallbackTexture()
 
@@ -205,131 +250,93 @@ func (s *Scene) createFallbackTexture() {
 	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
 }
 
-// LoadMap loads terrain data from GND and RSW.
+// LoadMap loads a map from GND and RSW and shows it in place of the one
+// shown. If loading fails, the shown map stays.
 func (s *Scene) LoadMap(gnd *formats.GND, rsw *formats.RSW, texLoader func(string) ([]byte, error)) error {
-	// Store map dimensions
-	s.MapWidth = float32(gnd.Width) * gnd.Zoom
-	s.MapHeight = float32(gnd.Height) * gnd.Zoom
-
-	fmt.Printf("=== Scene LoadMap ===\n")
-	fmt.Printf("GND: %dx%d tiles, zoom=%.1f\n", gnd.Width, gnd.Height, gnd.Zoom)
-	fmt.Printf("Map size: %.0fx%.0f world units\n", s.MapWidth, s.MapHeight)
-
-	// Build heightmap for terrain height queries
-	hm := terrain.BuildHeightmap(gnd)
-	s.terrainAltitudes = hm.Altitudes
-	s.terrainTilesX = hm.TilesX
-	s.terrainTilesZ = hm.TilesZ
-	s.terrainTileZoom = hm.TileZoom
-
-	// Load GAT for collision
-	if rsw != nil && rsw.GndFile != "" {
-		gatPath := "data/" + rsw.GndFile
-		if len(gatPath) > 4 {
-			gatPath = gatPath[:len(gatPath)-4] + ".gat"
-		}
-		gatData, err := texLoader(gatPath)
-		if err == nil {
-			s.GAT, _ = formats.ParseGAT(gatData)
-		}
-	}
-
-	// Extract lighting from RSW
-	if rsw != nil {
-		s.LightDir = lighting.SunDirection(rsw.Light.Longitude, rsw.Light.Latitude)
-		s.AmbientColor = rsw.Light.Ambient
-		s.DiffuseColor = rsw.Light.Diffuse
-		s.LightOpacity = rsw.Light.Opacity
-		if s.LightOpacity <= 0 {
-			s.LightOpacity = 1.0
-		}
-		fmt.Printf("Lighting: LightDir(%.2f,%.2f,%.2f) Ambient(%.2f,%.2f,%.2f) Diffuse(%.2f,%.2f,%.2f) Opacity=%.2f\n",
-			s.LightDir[0], s.LightDir[1], s.LightDir[2],
-			s.AmbientColor[0], s.AmbientColor[1], s.AmbientColor[2],
-			s.DiffuseColor[0], s.DiffuseColor[1], s.DiffuseColor[2],
-			s.LightOpacity)
-
-		// Ensure minimum ambient
-		minAmbient := float32(0.3)
-		for i := 0; i < 3; i++ {
-			if s.AmbientColor[i] < minAmbient {
-				s.AmbientColor[i] = minAmbient
-			}
-		}
-
-		// Extract point lights
-		s.extractPointLights(rsw)
+	m, err := s.loadInstance("", gnd, rsw, texLoader)
+	if err != nil {
+		return err
 	}
+	s.show(m)
+	return nil
+}
 
-	// Load terrain
-	if err := s.terrainRenderer.LoadTerrain(gnd, texLoader, s.fallbackTex); err != nil {
-		return fmt.Errorf("loading terrain: %w", err)
+// PreloadMap loads a map alongside the one shown, under name, replacing
+// any map preloaded before. ShowPreloaded then switches to it at once.
+func (s *Scene) PreloadMap(name string, gnd *formats.GND, rsw *formats.RSW, texLoader func(string) ([]byte, error)) error {
+	m, err := s.loadInstance(name, gnd, rsw, texLoader)
+	if err != nil {
+		return err
 	}
+	s.preload(m)
+	return nil
+}
 
-	// Get bounds from terrain
-	s.MinBounds = s.terrainRenderer.MinBounds
-	s.MaxBounds = s.terrainRenderer.MaxBounds
-	fmt.Printf("Terrain bounds: Min(%.0f,%.0f,%.0f) Max(%.0f,%.0f,%.0f)\n",
-		s.MinBounds[0], s.MinBounds[1], s.MinBounds[2],
-		s.MaxBounds[0], s.MaxBounds[1], s.MaxBounds[2])
-	fmt.Printf("Terrain groups: %d\n", len(s.terrainRenderer.groups))
-
-	// Load models
-	if rsw != nil {
-		models := rsw.GetModels()
-		fmt.Printf("RSW has %d models\n", len(models))
-		if err := s.modelRenderer.LoadModels(rsw, texLoader, s.fallbackTex, s.MapWidth, s.MapHeight, s.terrainAltitudes, s.terrainTileZoom, s.terrainTilesX, s.terrainTilesZ); err != nil {
-			return fmt.Errorf("loading models: %w", err)
-		}
-		fmt.Printf("Loaded %d models\n", len(s.modelRenderer.models))
-	}
+// preload holds m as the map preloaded, releasing the one held before.
+func (s *Scene) preload(m *MapInstance) {
+	s.DropPreloaded()
+	s.preloaded = m
+}
 
-	// Load water
-	if rsw != nil && rsw.Water.Level > 0 {
-		s.waterRenderer.SetupWater(rsw.Water.Level, s.MinBounds, s.MaxBounds, texLoader)
+// Preloaded returns the name of the map preloaded, or "" for none.
+func (s *Scene) Preloaded() string {
+	if s.preloaded == nil {
+		return ""
 	}
-
-	return nil
+	return s.preloaded.Name
 }
 
-func (s *Scene) extractPointLights(rsw *formats.RSW) {
-	s.PointLights = nil
-	lights := rsw.GetLights()
-	for _, light := range lights {
-		pl := PointLight{
-			Position:  light.Position,
-			Color:     light.Color,
-			Range:     light.Range,
-			Intensity: 1.0,
-		}
-		// Convert RSW coordinates to world coordinates
-		pl.Position[0] = pl.Position[0] + s.MapWidth/2
-		pl.Position[2] = pl.Position[2] + s.MapHeight/2
-		s.PointLights = append(s.PointLights, pl)
+// ShowPreloaded shows the map preloaded under name, releasing the one
+// shown. It returns false, changing nothing, if that map isn't preloaded.
+func (s *Scene) ShowPreloaded(name string) bool {
+	if s.preloaded == nil || s.preloaded.Name != name {
+		return false
 	}
+	m := s.preloaded
+	s.preloaded = nil
+	s.show(m)
+	return true
 }
 
-// Render renders the scene to the framebuffer using an OrbitCamera.
-func (s *Scene) Render(cam *camera.OrbitCamera) uint32 {
-	return s.RenderWithView(cam.ViewMatrix())
+// DropPreloaded releases the preloaded map, if any.
+func (s *Scene) DropPreloaded() {
+	if s.preloaded != nil {
+		s.preloaded.Destroy()
+		s.preloaded = nil
+	}
 }
 
-// RenderWithThirdPerson renders the scene using a ThirdPersonCamera following a target.
-func (s *Scene) RenderWithThirdPerson(cam *camera.ThirdPersonCamera, targetX, targetY, targetZ float32) uint32 {
-	return s.RenderWithViewExtras(cam.ViewMatrix(targetX, targetY, targetZ), nil)
+// loadInstance loads a map into a new instance, released if loading fails.
+func (s *Scene) loadInstance(name string, gnd *formats.GND, rsw *formats.RSW, texLoader func(string) ([]byte, error)) (*MapInstance, error) {
+	m, err := newMapInstance(name, s.textures)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.load(gnd, rsw, texLoader, s.fallbackTex); err != nil {
+		m.Destroy()
+		return nil, err
+	}
+	return m, nil
 }
 
-// RenderWithThirdPersonExtras is RenderWithThirdPerson plus an extras callback
-// that runs inside the scene framebuffer (after world rendering, before
-// unbind) — use this to draw billboards/overlays that need to appear in the
-// composited scene texture.
-func (s *Scene) RenderWithThirdPersonExtras(cam *camera.ThirdPersonCamera, targetX, targetY, targetZ float32, extras func(viewProj math.Mat4)) uint32 {
-	return s.RenderWithViewExtras(cam.ViewMatrix(targetX, targetY, targetZ), extras)
+// show makes m the map shown, releasing the previous one with the decals,
+// auras and boards placed on it.
+func (s *Scene) show(m *MapInstance) {
+	if s.MapInstance != nil {
+		s.MapInstance.Destroy()
+	}
+	s.MapInstance = m
+	s.decalRenderer.Clear()
+	s.auraRenderer.Clear()
+	s.boardRenderer.Clear()
 }
 
-// RenderWithView renders the scene with a pre-computed view matrix.
-func (s *Scene) RenderWithView(view math.Mat4) uint32 {
-	return s.RenderWithViewExtras(view, nil)
+// Render renders the scene from a camera to the framebuffer and returns
+// its texture. extras, if not nil, runs inside the scene framebuffer after
+// the world is drawn, before it's unbound, so callers can draw billboards
+// and overlays (e.g. the player sprite) into the composited texture.
+func (s *Scene) Render(cam camera.Camera, extras func(viewProj math.Mat4)) uint32 {
+	return s.renderView(cam.ViewMatrix(), extras)
 }
 
 // LastViewProj returns the most recently used view-projection matrix.
@@ -338,16 +345,13 @@ func (s *Scene) LastViewProj() math.Mat4 {
 	return s.lastViewProj
 }
 
-// RenderWithViewExtras renders the scene with a pre-computed view matrix and
-// an optional extras callback that runs in the scene framebuffer just before
-// it is unbound, so callers can draw additional content (e.g. player sprite,
-// effects) into the composited scene texture.
-func (s *Scene) RenderWithViewExtras(view math.Mat4, extras func(viewProj math.Mat4)) uint32 {
+// renderView renders the scene with a view matrix, as Render.
+func (s *Scene) renderView(view math.Mat4, extras func(viewProj math.Mat4)) uint32 {
 	// Calculate view/projection matrices
 	aspect := float32(s.config.Width) / float32(s.config.Height)
 	proj := math.Perspective(0.785398, aspect, 1.0, 10000.0) // 45 degrees FOV
-	viewProj := proj.Mul(view)
-	s.lastViewProj = viewProj
+	s.lastViewProj = proj.Mul(view)
+	s.lastView = view
 
 	// Calculate light view projection for shadows
 	if s.ShadowsEnabled && s.shadowMap != nil {
@@ -363,46 +367,128 @@ func (s *Scene) RenderWithViewExtras(view math.Mat4, extras func(viewProj math.M
 		s.renderShadowPass()
 	}
 
-	// Bind main framebuffer
-	restore := s.framebuffer.BindWithViewport()
+	tex := s.RenderCamera(view, proj, s.framebuffer, extras)
+
+	// Post-process the main view only; the picture-in-picture is a debug aid
+	s.output = s.post.Apply(tex, s.PostFX)
+	gl.Flush() // As in RenderCamera, commit the passes before the UI samples them
+	return s.output
+}
+
+// SetColorLUT loads the color grading lookup table applied when PostFX
+// enables grading, a strip of N slices of NxN; nil removes it.
+func (s *Scene) SetColorLUT(img image.Image) error {
+	if err := s.post.SetLUT(img); err != nil {
+		return fmt.Errorf("color lut: %w", err)
+	}
+	return nil
+}
+
+// SetTextureFilter changes how the ground, model and water textures are
+// sampled, re-applying the policy to those already loaded.
+func (s *Scene) SetTextureFilter(f texfilter.Filter) {
+	if !texfilter.Set(f) {
+		return
+	}
+	s.applyTextureFilter()
+	if s.preloaded != nil {
+		s.preloaded.applyTextureFilter()
+	}
+	s.textures.ApplyFilter()
+}
+
+// RenderCamera renders the world from an arbitrary camera into target and
+// returns its color texture. It reuses the shadow map from the last main
+// render, so secondary views (picture-in-picture) must be rendered after it.
+func (s *Scene) RenderCamera(view, proj math.Mat4, target *framebuffer.Framebuffer, extras func(viewProj math.Mat4)) uint32 {
+	viewProj := proj.Mul(view)
+
+	// Bind target framebuffer
+	restore := target.BindWithViewport()
 	defer restore()
 
 	// Clear with sky blue (matches grfbrowser)
-	s.framebuffer.Clear(0.4, 0.6, 0.9, 1.0)
+	target.Clear(0.4, 0.6, 0.9, 1.0)
 
-	// Enable depth testing
-	gl.Enable(gl.DEPTH_TEST)
-	gl.DepthFunc(gl.LESS)
+	// Depth tested, alpha blended and unculled (winding order varies).
+	// Reset issues every call in case the UI left state behind.
+	glstate.Reset(glstate.Default)
 
-	// Enable alpha blending
-	gl.Enable(gl.BLEND)
-	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
-
-	// Disable face culling for terrain (winding order varies)
-	gl.Disable(gl.CULL_FACE)
+	// The map's light, darkened by the time of day
+	ambient, diffuse, lamps := s.daylight()
 
 	// Render terrain
-	s.terrainRenderer.Render(viewProj, s.LightDir, s.AmbientColor, s.DiffuseColor, s.Brightness, s.LightOpacity,
+	end := pass("terrain", glstate.Default)
+	s.terrain.Render(viewProj, s.LightDir, ambient, diffuse, s.Brightness, s.LightOpacity,
 		s.ShadowsEnabled, s.lightViewProj, s.shadowMap,
-		s.PointLightsEnabled, s.PointLights, s.PointLightIntensity,
+		s.PointLightsEnabled, s.PointLights, lamps,
 		s.FogEnabled, s.FogNear, s.FogFar, s.FogColor)
+	end()
+
+	// Ground decals (telegraphs, loot rings) drape over the terrain
+	end = pass("decals", glstate.Decal)
+	s.decalRenderer.Render(viewProj)
+	end()
 
 	// Render models
-	s.modelRenderer.Render(viewProj, s.LightDir, s.AmbientColor, s.DiffuseColor,
+	end = pass("models", glstate.Default)
+	s.models.Render(viewProj, s.LightDir, ambient, diffuse,
 		s.ShadowsEnabled, s.lightViewProj, s.shadowMap,
-		s.PointLightsEnabled, s.PointLights, s.PointLightIntensity,
+		s.PointLightsEnabled, s.PointLights, lamps,
 		s.FogEnabled, s.FogNear, s.FogFar, s.FogColor)
-
-	// Render water
-	if s.waterRenderer.HasWater() {
-		s.waterRenderer.Render(viewProj)
-	}
-
-	// Run extras (e.g. player billboard) inside the framebuffer.
+	end()
+
+	// Stream model textures toward the size they're seen at
+	end = pass("textures", glstate.Default)
+	width, height := target.Size()
+	s.models.RequestTextures(viewProj, float32(width), float32(height), clock.Now())
+	s.textures.Update(clock.Now())
+	end()
+
+	// Copy the opaque world's depth for transparent draws to fade into
+	end = pass("depth copy", glstate.Default)
+	dc := s.depthCopies[target]
+	if dc == nil {
+		dc = &depthCopy{}
+		s.depthCopies[target] = dc
+	}
+	dc.capture(width, height)
+	soft := softDepthFor(dc, proj)
+	end()
+
+	// Render water, the farthest of the transparent layers: everything
+	// else transparent stands on or above it
+	if s.water.HasWater() {
+		end = pass("water", glstate.Default)
+		s.water.render(viewProj, soft)
+		end()
+	}
+
+	// Character auras, before the extras draw the characters inside them
+	end = pass("auras", glstate.Additive)
+	s.auraRenderer.Render(viewProj, view)
+	end()
+
+	// Run extras (e.g. player billboard) inside the framebuffer. Sprites
+	// they render through RenderSprite join the transparency queue.
+	s.transparent.begin(view)
 	if extras != nil {
+		end = pass("extras", glstate.Default)
 		extras(viewProj)
+		end()
 	}
 
+	// Map sprite effects (torches, flames) and sprites, back to front
+	end = pass("transparent", glstate.Translucent)
+	s.effects.Render(&s.transparent, viewProj, view)
+	s.transparent.flush(s.spriteRenderer, soft)
+	end()
+
+	// Boards (shop titles, chat rooms) over everything in the world
+	end = pass("boards", glstate.Overlay)
+	s.boardRenderer.Render(s.spriteRenderer, viewProj, view)
+	end()
+
 	// Force a GL flush before returning so that any writes made by world
 	// renderers OR by the extras callback are committed to the FBO's
 	// color texture before the imgui display step samples it.
@@ -416,13 +502,26 @@ func (s *Scene) RenderWithViewExtras(view math.Mat4, extras func(viewProj math.M
 	// added a flush; with it the sprite shows correctly.
 	gl.Flush()
 
-	return s.framebuffer.ColorTexture()
+	return target.ColorTexture()
+}
+
+// pass starts a labeled render pass drawing with state and returns the
+// function ending it, which restores the state before: GL errors inside
+// are reported under name and, when timing is on, its GPU time is measured.
+func pass(name string, state glstate.State) func() {
+	endTimer := perf.Pass(name)
+	endPass := glstate.Pass{Name: name, State: state}.Begin()
+	return func() {
+		endPass()
+		endTimer()
+	}
 }
 
 func (s *Scene) renderShadowPass() {
 	if s.shadowMap == nil {
 		return
 	}
+	defer pass("shadows", glstate.ShadowDepth)()
 
 	s.shadowMap.Bind()
 	gl.Clear(gl.DEPTH_BUFFER_BIT)
@@ -433,17 +532,88 @@ func (s *Scene) renderShadowPass() {
 	// Render terrain to shadow map
 	identity := math.Identity()
 	gl.UniformMatrix4fv(s.locShadowModel, 1, false, &identity[0])
-	s.terrainRenderer.RenderShadow()
+	s.terrain.RenderShadow()
 
 	// Render models to shadow map
-	s.modelRenderer.RenderShadow(s.shadowProgram, s.locShadowModel)
+	s.models.RenderShadow(s.shadowProgram, s.locShadowModel)
 
 	s.shadowMap.Unbind()
 }
 
-// RenderSprite renders a sprite at the given world position.
+// RenderSprite renders a sprite at the given world position. Called from
+// render extras, it's queued and drawn once they return, back to front
+// with the scene's other transparent draws, its feet fading into the
+// ground.
 func (s *Scene) RenderSprite(viewProj math.Mat4, camRight, camUp math.Vec3, worldPos [3]float32, width, height float32, textureID uint32, tint [4]float32) {
-	s.spriteRenderer.Render(viewProj, camRight, camUp, worldPos, width, height, textureID, tint)
+	s.transparent.add(transparentDraw{
+		viewProj: viewProj,
+		camRight: camRight,
+		camUp:    camUp,
+		worldPos: worldPos,
+		width:    width,
+		height:   height,
+		texture:  textureID,
+		tint:     tint,
+		soft:     spriteSoftDistance,
+	})
+}
+
+// SpawnEffect plays a one-shot sprite effect from data/sprite/이팩트/ at a
+// world position. Returns false if the effect sprite could not be loaded.
+func (s *Scene) SpawnEffect(name string, x, y, z float32, texLoader func(string) ([]byte, error)) bool {
+	return s.effects.SpawnOneShot(name, [3]float32{x, y, z}, texLoader)
+}
+
+// SetBoard adds or updates a world-space board, keyed by an ID of the
+// caller's choosing such as the owning character's.
+func (s *Scene) SetBoard(id uint32, b Board) {
+	s.boardRenderer.Set(id, b)
+}
+
+// RemoveBoard removes a board. Unknown IDs are ignored.
+func (s *Scene) RemoveBoard(id uint32) {
+	s.boardRenderer.Remove(id)
+}
+
+// PickBoard returns the ID of the nearest board under a ray, as the boards
+// faced the camera in the last main render.
+func (s *Scene) PickBoard(ray picking.Ray) (uint32, bool) {
+	v := s.lastView
+	return s.boardRenderer.Pick(ray, [3]float32{v[0], v[4], v[8]}, [3]float32{v[1], v[5], v[9]})
+}
+
+// SpawnDecal adds a ground decal and returns its ID for RemoveDecal. Timed
+// decals remove themselves once their lifetime is over.
+func (s *Scene) SpawnDecal(d Decal) DecalID {
+	return s.decalRenderer.Add(d, clock.Now())
+}
+
+// RemoveDecal removes a ground decal. Unknown IDs are ignored.
+func (s *Scene) RemoveDecal(id DecalID) {
+	s.decalRenderer.Remove(id)
+}
+
+// SetAura adds or moves the aura around a character, keyed by an ID of the
+// caller's choosing such as the character's. Auras not set again before
+// SweepAuras are removed.
+func (s *Scene) SetAura(id uint32, a Aura) {
+	s.auraRenderer.Set(id, a, clock.Now())
+}
+
+// BurstAura plays an aura once at its position for d, such as the
+// teleport column of a unit warping in.
+func (s *Scene) BurstAura(a Aura, d time.Duration) {
+	s.auraRenderer.Burst(a, d, clock.Now())
+}
+
+// SweepAuras removes the auras not set since the last sweep.
+func (s *Scene) SweepAuras() {
+	s.auraRenderer.Sweep()
+}
+
+// ClearAuras removes every aura.
+func (s *Scene) ClearAuras() {
+	s.auraRenderer.Clear()
 }
 
 // FramebufferSize returns the scene framebuffer dimensions in pixels.
@@ -469,6 +639,7 @@ func (s *Scene) Resize(width, height int32) {
 	s.config.Width = width
 	s.config.Height = height
 	s.framebuffer.Resize(width, height)
+	s.post.Resize(width, height)
 }
 
 // GetTerrainHeight returns the terrain height at the given world coordinates.
@@ -489,6 +660,24 @@ func (s *Scene) GetTerrainHeight(worldX, worldZ float32) float32 {
 	return -s.terrainAltitudes[tileX][tileZ]
 }
 
+// groundHeight returns the walkable ground height at the given world
+// coordinates, interpolated across GAT cells so decals follow slopes.
+func (s *Scene) groundHeight(worldX, worldZ float32) float32 {
+	if s.GAT == nil {
+		return s.GetTerrainHeight(worldX, worldZ)
+	}
+	return terrain.GetInterpolatedHeight(s.GAT, worldX, worldZ)
+}
+
+// WaterHeight returns the height of the water surface, and false on maps
+// without water.
+func (s *Scene) WaterHeight() (float32, bool) {
+	if !s.water.HasWater() {
+		return 0, false
+	}
+	return -s.water.waterLevel, true
+}
+
 // IsWalkable returns whether the given tile coordinates are walkable.
 func (s *Scene) IsWalkable(tileX, tileY int) bool {
 	if s.GAT == nil {
@@ -502,16 +691,39 @@ func (s *Scene) FallbackTexture() uint32 {
 	return s.fallbackTex
 }
 
-// ColorTexture returns the rendered color texture.
+// ReloadTextures loads the model textures of edited files again. names
+// are normalized file names (see grf.NormalizePath).
+func (s *Scene) ReloadTextures(names []string) int {
+	return s.textures.Reload(names)
+}
+
+// EntityTextures returns the pool entity sprite textures are taken from.
+func (s *Scene) EntityTextures() *TexturePool {
+	return s.entityTextures
+}
+
+// TextureStats returns a snapshot of the streamed model textures.
+func (s *Scene) TextureStats() TextureStats {
+	return s.textures.Stats()
+}
+
+// ColorTexture returns the rendered color texture, post-processed.
 func (s *Scene) ColorTexture() uint32 {
+	if s.output != 0 {
+		return s.output
+	}
 	return s.framebuffer.ColorTexture()
 }
 
 // CaptureImage captures the current rendered scene as RGBA pixel data.
 // Returns the pixel data and dimensions. Pixels are in correct orientation (top-to-bottom).
 func (s *Scene) CaptureImage() ([]byte, int32, int32) {
-	width, height := s.framebuffer.Size()
-	pixels := s.framebuffer.ReadPixels()
+	fb := s.framebuffer
+	if out := s.post.Output(); out != nil {
+		fb = out // Capture what's on screen
+	}
+	width, height := fb.Size()
+	pixels := fb.ReadPixels()
 
 	// Flip vertically (OpenGL has origin at bottom-left, we need top-left)
 	rowSize := int(width) * 4
@@ -527,18 +739,34 @@ func (s *Scene) CaptureImage() ([]byte, int32, int32) {
 
 // Destroy releases all resources.
 func (s *Scene) Destroy() {
-	if s.terrainRenderer != nil {
-		s.terrainRenderer.Destroy()
-	}
-	if s.modelRenderer != nil {
-		s.modelRenderer.Destroy()
-	}
-	if s.waterRenderer != nil {
-		s.waterRenderer.Destroy()
+	if s.MapInstance != nil {
+		s.MapInstance.Destroy()
 	}
+	s.DropPreloaded()
 	if s.spriteRenderer != nil {
 		s.spriteRenderer.Destroy()
 	}
+	for _, dc := range s.depthCopies {
+		dc.destroy()
+	}
+	s.depthCopies = nil
+	if s.boardRenderer != nil {
+		s.boardRenderer.Destroy()
+	}
+	if s.decalRenderer != nil {
+		s.decalRenderer.Destroy()
+	}
+	if s.auraRenderer != nil {
+		s.auraRenderer.Destroy()
+	}
+	if s.post != nil {
+		s.post.Destroy()
+		s.post = nil
+	}
+	if s.pip.framebuffer != nil {
+		s.pip.framebuffer.Destroy()
+		s.pip.framebuffer = nil
+	}
 	if s.shadowMap != nil {
 		s.shadowMap.Destroy()
 	}
@@ -551,4 +779,6 @@ func (s *Scene) Destroy() {
 	if s.fallbackTex != 0 {
 		gl.DeleteTextures(1, &s.fallbackTex)
 	}
+	s.entityTextures.Destroy()
+	s.textures.Destroy()
 }
@@ -0,0 +1,37 @@
+#version 410 core
+in vec2 vTexCoord;
+in vec4 vColor;
+flat in int vShape;
+
+out vec4 FragColor;
+
+// Shapes match scene.auraShape
+const int SHAPE_RING = 0;
+const int SHAPE_COLUMN = 1;
+const int SHAPE_GLOW = 2;
+
+void main() {
+    float a;
+
+    if (vShape == SHAPE_RING) {
+        // Soft band with a faint glow inside it
+        float d = length(vTexCoord);
+        float band = smoothstep(0.6, 0.8, d) * (1.0 - smoothstep(0.85, 1.0, d));
+        a = max(band, 0.15 * (1.0 - smoothstep(0.0, 0.8, d)));
+    } else if (vShape == SHAPE_COLUMN) {
+        // Brightest in the middle and at the feet, gone at the top
+        float across = 1.0 - vTexCoord.x * vTexCoord.x;
+        float up = 1.0 - smoothstep(-1.0, 1.0, vTexCoord.y);
+        a = across * across * up;
+    } else {
+        // Glow falling off from a bright core
+        float d = length(vTexCoord);
+        a = (1.0 - smoothstep(0.0, 1.0, d)) * (1.0 - smoothstep(0.0, 1.0, d));
+    }
+
+    a *= vColor.a;
+    if (a < 0.01) {
+        discard;
+    }
+    FragColor = vec4(vColor.rgb, a);
+}
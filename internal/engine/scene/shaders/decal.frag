@@ -0,0 +1,44 @@
+#version 410 core
+in vec2 vTexCoord;
+in vec4 vColor;
+flat in int vShape;
+
+out vec4 FragColor;
+
+// Shapes match scene.DecalShape
+const int SHAPE_DISC = 0;
+const int SHAPE_RING = 1;
+const int SHAPE_SQUARE = 2;
+const int SHAPE_SPLAT = 3;
+const int SHAPE_FOOTPRINT = 4;
+
+void main() {
+    float d = length(vTexCoord);
+    float a;
+
+    if (vShape == SHAPE_RING) {
+        // Thin outline with soft edges
+        a = smoothstep(0.78, 0.86, d) * (1.0 - smoothstep(0.94, 1.0, d));
+    } else if (vShape == SHAPE_SQUARE) {
+        // Faint fill with a solid border
+        float edge = max(abs(vTexCoord.x), abs(vTexCoord.y));
+        a = mix(0.35, 1.0, smoothstep(0.82, 0.9, edge)) * (1.0 - smoothstep(0.96, 1.0, edge));
+    } else if (vShape == SHAPE_SPLAT) {
+        // Blotch with a wobbly edge; rotation varies it between decals
+        float angle = atan(vTexCoord.y, vTexCoord.x);
+        float r = 0.72 + 0.14 * sin(angle * 5.0) + 0.08 * sin(angle * 11.0 + 1.3);
+        a = 1.0 - smoothstep(r - 0.08, r, d);
+    } else if (vShape == SHAPE_FOOTPRINT) {
+        // Narrow oval along the decal's V axis
+        a = 1.0 - smoothstep(0.8, 1.0, length(vTexCoord * vec2(1.8, 1.0)));
+    } else {
+        // Disc, brighter toward the rim like a casting circle
+        a = mix(0.5, 1.0, smoothstep(0.5, 0.9, d)) * (1.0 - smoothstep(0.9, 1.0, d));
+    }
+
+    a *= vColor.a;
+    if (a < 0.01) {
+        discard;
+    }
+    FragColor = vec4(vColor.rgb, a);
+}
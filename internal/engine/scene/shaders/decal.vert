@@ -0,0 +1,18 @@
+#version 410 core
+layout (location = 0) in vec3 aPosition;
+layout (location = 1) in vec2 aTexCoord; // -1..1 across the decal
+layout (location = 2) in vec4 aColor;    // Alpha already faded
+layout (location = 3) in float aShape;
+
+uniform mat4 uViewProj;
+
+out vec2 vTexCoord;
+out vec4 vColor;
+flat out int vShape;
+
+void main() {
+    vTexCoord = aTexCoord;
+    vColor = aColor;
+    vShape = int(aShape + 0.5);
+    gl_Position = uViewProj * vec4(aPosition, 1.0);
+}
@@ -52,3 +52,23 @@ var ShadowVertexShader string
 //
 //go:embed shadow.frag
 var ShadowFragmentShader string
+
+// DecalVertexShader is the vertex shader for terrain decals.
+//
+//go:embed decal.vert
+var DecalVertexShader string
+
+// DecalFragmentShader is the fragment shader for terrain decals.
+//
+//go:embed decal.frag
+var DecalFragmentShader string
+
+// AuraVertexShader is the vertex shader for character auras.
+//
+//go:embed aura.vert
+var AuraVertexShader string
+
+// AuraFragmentShader is the fragment shader for character auras.
+//
+//go:embed aura.frag
+var AuraFragmentShader string
@@ -10,6 +10,7 @@ uniform vec3 uLightDir;
 uniform vec3 uAmbient;
 uniform vec3 uDiffuse;
 uniform bool uShadowsEnabled;        // Toggle for real-time shadows
+uniform vec4 uTint;                  // Highlight color, mixed in by its alpha
 
 // Fog uniforms (roBrowser style)
 uniform bool uFogUse;
@@ -140,6 +141,9 @@ void main() {
     vec3 warmTint = vec3(1.08, 1.02, 0.92);  // Stronger warm/golden shift
     color = color * warmTint;
 
+    // Highlight, e.g. the node selected in a model inspector
+    color = mix(color, uTint.rgb, uTint.a);
+
     // Apply fog (roBrowser formula using smoothstep)
     if (uFogUse) {
         float depth = gl_FragCoord.z / gl_FragCoord.w;
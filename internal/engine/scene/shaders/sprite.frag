@@ -4,8 +4,18 @@ in vec2 vTexCoord;
 uniform sampler2D uTexture;
 uniform vec4 uTint;
 
+// Soft edges: the opaque scene's depth, the projection terms turning it
+// back into view depth, and how far in front of it the sprite fades in
+uniform sampler2D uDepthTex;
+uniform vec2 uDepthParams;
+uniform float uSoftDistance; // 0 for hard edges
+
 out vec4 FragColor;
 
+float viewDepth(float depth) {
+    return uDepthParams.y / (depth * 2.0 - 1.0 + uDepthParams.x);
+}
+
 void main() {
     vec4 texColor = texture(uTexture, vTexCoord);
 
@@ -15,4 +25,9 @@ void main() {
     }
 
     FragColor = texColor * uTint;
+
+    if (uSoftDistance > 0.0) {
+        float scene = viewDepth(texelFetch(uDepthTex, ivec2(gl_FragCoord.xy), 0).r);
+        FragColor.a *= clamp((scene - viewDepth(gl_FragCoord.z)) / uSoftDistance, 0.0, 1.0);
+    }
 }
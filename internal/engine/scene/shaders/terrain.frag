@@ -3,10 +3,13 @@ in vec3 vNormal;
 in vec2 vTexCoord;
 in vec2 vLightmapUV;
 in vec4 vColor;
+flat in float vTexLayer;
 in vec3 vWorldPos;
 in vec4 vLightSpacePos;
 
-uniform sampler2D uTexture;
+uniform sampler2D uTexture;            // One ground texture, drawn per group
+uniform sampler2DArray uTextureArray;  // All ground textures, one layer each
+uniform bool uUseTextureArray;         // Sample uTextureArray instead of uTexture
 uniform sampler2D uLightmap;
 uniform sampler2DShadow uShadowMap;  // Shadow map with comparison mode
 uniform vec3 uLightDir;
@@ -108,7 +111,9 @@ float calculateShadow() {
 }
 
 void main() {
-    vec4 texColor = texture(uTexture, vTexCoord);
+    vec4 texColor = uUseTextureArray
+        ? texture(uTextureArray, vec3(vTexCoord, vTexLayer))
+        : texture(uTexture, vTexCoord);
 
     // Discard transparent pixels (magenta key areas)
     if (texColor.a < 0.5) {
@@ -4,6 +4,7 @@ layout (location = 1) in vec3 aNormal;
 layout (location = 2) in vec2 aTexCoord;
 layout (location = 3) in vec2 aLightmapUV;
 layout (location = 4) in vec4 aColor;
+layout (location = 5) in float aTexLayer;   // Ground texture array layer
 
 uniform mat4 uViewProj;
 uniform mat4 uLightViewProj;  // For shadow mapping
@@ -12,6 +13,7 @@ out vec3 vNormal;
 out vec2 vTexCoord;
 out vec2 vLightmapUV;
 out vec4 vColor;
+flat out float vTexLayer;
 out vec3 vWorldPos;           // World position for shadow calculation
 out vec4 vLightSpacePos;      // Position in light space for shadow lookup
 
@@ -20,6 +22,7 @@ void main() {
     vTexCoord = aTexCoord;
     vLightmapUV = aLightmapUV;
     vColor = aColor;
+    vTexLayer = aTexLayer;
     vWorldPos = aPosition;
     vLightSpacePos = uLightViewProj * vec4(aPosition, 1.0);
     gl_Position = uViewProj * vec4(aPosition, 1.0);
@@ -7,6 +7,11 @@ uniform float uScrollSpeed;
 uniform sampler2D uWaterTex;
 uniform int uUseTexture;
 
+// Soft shorelines, as sprites fade into the scene (see sprite.frag)
+uniform sampler2D uDepthTex;
+uniform vec2 uDepthParams;
+uniform float uSoftDistance; // 0 for hard edges
+
 out vec4 FragColor;
 
 // Hash function for pseudo-random noise (fallback)
@@ -38,6 +43,10 @@ float fbm(vec2 p, float time) {
     return value;
 }
 
+float viewDepth(float depth) {
+    return uDepthParams.y / (depth * 2.0 - 1.0 + uDepthParams.x);
+}
+
 void main() {
     // Scale world position for texture coordinates - tile the texture
     // RO tiles water texture approximately every 50-100 world units
@@ -71,4 +80,9 @@ void main() {
 
         FragColor = vec4(waterColor, uWaterColor.a);
     }
+
+    if (uSoftDistance > 0.0) {
+        float scene = viewDepth(texelFetch(uDepthTex, ivec2(gl_FragCoord.xy), 0).r);
+        FragColor.a *= clamp((scene - viewDepth(gl_FragCoord.z)) / uSoftDistance, 0.0, 1.0);
+    }
 }
@@ -7,6 +7,7 @@ import (
 
 	"github.com/go-gl/gl/v4.1-core/gl"
 
+	"github.com/Faultbox/midgard-ro/internal/engine/glstate"
 	"github.com/Faultbox/midgard-ro/internal/engine/scene/shaders"
 	"github.com/Faultbox/midgard-ro/internal/engine/shader"
 	"github.com/Faultbox/midgard-ro/pkg/math"
@@ -25,6 +26,9 @@ type SpriteRenderer struct {
 	locCamUp      int32
 	locTexture    int32
 	locTint       int32
+	locDepthTex   int32
+	locDepth      int32
+	locSoft       int32
 
 	// Billboard quad mesh
 	vao uint32
@@ -49,6 +53,9 @@ func NewSpriteRenderer() (*SpriteRenderer, error) {
 	sr.locCamUp = shader.GetUniform(program, "uCamUp")
 	sr.locTexture = shader.GetUniform(program, "uTexture")
 	sr.locTint = shader.GetUniform(program, "uTint")
+	sr.locDepthTex = shader.GetUniform(program, "uDepthTex")
+	sr.locDepth = shader.GetUniform(program, "uDepthParams")
+	sr.locSoft = shader.GetUniform(program, "uSoftDistance")
 
 	// Create billboard quad
 	sr.createQuad()
@@ -87,20 +94,34 @@ func (sr *SpriteRenderer) createQuad() {
 	gl.BindVertexArray(0)
 }
 
-// Render renders a sprite at the given world position.
+// Render renders a sprite at the given world position, with hard edges.
 func (sr *SpriteRenderer) Render(viewProj math.Mat4, camRight, camUp math.Vec3, worldPos [3]float32, width, height float32, textureID uint32, tint [4]float32) {
+	sr.draw(viewProj, camRight, camUp, worldPos, width, height, textureID, tint, 0)
+}
+
+// bindSceneDepth sets the depth soft edges fade into.
+func (sr *SpriteRenderer) bindSceneDepth(depth softDepth) {
 	if sr.vao == 0 {
 		return
 	}
-
 	gl.UseProgram(sr.program)
+	gl.ActiveTexture(gl.TEXTURE1)
+	gl.BindTexture(gl.TEXTURE_2D, depth.texture)
+	gl.Uniform1i(sr.locDepthTex, 1)
+	gl.Uniform2f(sr.locDepth, depth.params[0], depth.params[1])
+}
+
+// draw renders a sprite, fading it in over soft world units in front of
+// the scene depth bound last; 0 draws hard edges.
+func (sr *SpriteRenderer) draw(viewProj math.Mat4, camRight, camUp math.Vec3, worldPos [3]float32, width, height float32, textureID uint32, tint [4]float32, soft float32) {
+	if sr.vao == 0 {
+		return
+	}
 
-	// Enable blending
-	gl.Enable(gl.BLEND)
-	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	gl.UseProgram(sr.program)
 
-	// Disable depth writing for sprites (but keep depth testing)
-	gl.DepthMask(false)
+	// Don't write depth for sprites, but keep the pass's depth test
+	defer glstate.Push(glstate.Current().With(func(s *glstate.State) { s.DepthWrite = false }))()
 
 	// Set uniforms
 	gl.UniformMatrix4fv(sr.locViewProj, 1, false, &viewProj[0])
@@ -109,6 +130,7 @@ func (sr *SpriteRenderer) Render(viewProj math.Mat4, camRight, camUp math.Vec3,
 	gl.Uniform3f(sr.locCamRight, camRight.X, camRight.Y, camRight.Z)
 	gl.Uniform3f(sr.locCamUp, camUp.X, camUp.Y, camUp.Z)
 	gl.Uniform4f(sr.locTint, tint[0], tint[1], tint[2], tint[3])
+	gl.Uniform1f(sr.locSoft, soft)
 
 	// Bind texture
 	gl.ActiveTexture(gl.TEXTURE0)
@@ -119,9 +141,6 @@ func (sr *SpriteRenderer) Render(viewProj math.Mat4, camRight, camUp math.Vec3,
 	gl.BindVertexArray(sr.vao)
 	gl.DrawArrays(gl.TRIANGLES, 0, 6)
 	gl.BindVertexArray(0)
-
-	// Restore depth writing
-	gl.DepthMask(true)
 }
 
 // Destroy releases all resources.
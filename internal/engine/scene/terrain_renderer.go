@@ -2,10 +2,8 @@
 package scene
 
 import (
-	"bytes"
 	"fmt"
 	"image"
-	"strings"
 	"unsafe"
 
 	"github.com/go-gl/gl/v4.1-core/gl"
@@ -14,6 +12,7 @@ import (
 	"github.com/Faultbox/midgard-ro/internal/engine/shader"
 	"github.com/Faultbox/midgard-ro/internal/engine/shadow"
 	"github.com/Faultbox/midgard-ro/internal/engine/terrain"
+	"github.com/Faultbox/midgard-ro/internal/engine/texfilter"
 	"github.com/Faultbox/midgard-ro/internal/engine/texture"
 	"github.com/Faultbox/midgard-ro/pkg/formats"
 	"github.com/Faultbox/midgard-ro/pkg/math"
@@ -30,6 +29,8 @@ type TerrainRenderer struct {
 	locAmbient      int32
 	locDiffuse      int32
 	locTexture      int32
+	locTextureArray int32
+	locUseTexArray  int32
 	locLightmap     int32
 	locBrightness   int32
 	locLightOpacity int32
@@ -57,11 +58,30 @@ type TerrainRenderer struct {
 	ebo    uint32
 	groups []terrain.TextureGroup
 
-	// Textures
-	groundTextures   map[int]uint32
+	// Textures: all ground textures in one array, so the terrain is a
+	// single draw call instead of one bind and draw per texture group
+	groundTexArray   uint32
+	groundTexLayers  int
+	groundTexSize    int
 	lightmapAtlasTex uint32
 	lightmapAtlas    *terrain.LightmapAtlas
 
+	// Each ground texture on its own, by GND texture index, loaded for
+	// CompareTextures
+	groundTextures map[int]uint32
+
+	// CompareTextures also loads each ground texture on its own, so that
+	// turning UseTextureArray off draws the terrain one bind and draw per
+	// texture group, to compare the two. Set it before LoadTerrain.
+	CompareTextures bool
+
+	// UseTextureArray draws the terrain from the texture array. It is
+	// only turned off for comparison, with CompareTextures.
+	UseTextureArray bool
+
+	// GPU memory held by the loaded terrain
+	memory Memory
+
 	// Bounds
 	MinBounds [3]float32
 	MaxBounds [3]float32
@@ -69,9 +89,7 @@ type TerrainRenderer struct {
 
 // NewTerrainRenderer creates a new terrain renderer.
 func NewTerrainRenderer() (*TerrainRenderer, error) {
-	tr := &TerrainRenderer{
-		groundTextures: make(map[int]uint32),
-	}
+	tr := &TerrainRenderer{UseTextureArray: true}
 
 	program, err := shader.CompileProgram(shaders.TerrainVertexShader, shaders.TerrainFragmentShader)
 	if err != nil {
@@ -85,6 +103,8 @@ func NewTerrainRenderer() (*TerrainRenderer, error) {
 	tr.locAmbient = shader.GetUniform(program, "uAmbient")
 	tr.locDiffuse = shader.GetUniform(program, "uDiffuse")
 	tr.locTexture = shader.GetUniform(program, "uTexture")
+	tr.locTextureArray = shader.GetUniform(program, "uTextureArray")
+	tr.locUseTexArray = shader.GetUniform(program, "uUseTextureArray")
 	tr.locLightmap = shader.GetUniform(program, "uLightmap")
 	tr.locBrightness = shader.GetUniform(program, "uBrightness")
 	tr.locLightOpacity = shader.GetUniform(program, "uLightOpacity")
@@ -110,12 +130,12 @@ func NewTerrainRenderer() (*TerrainRenderer, error) {
 }
 
 // LoadTerrain loads terrain data from GND.
-func (tr *TerrainRenderer) LoadTerrain(gnd *formats.GND, texLoader func(string) ([]byte, error), fallbackTex uint32) error {
+func (tr *TerrainRenderer) LoadTerrain(gnd *formats.GND, texLoader func(string) ([]byte, error)) error {
 	// Clear old resources
 	tr.clearTerrain()
 
 	// Load ground textures
-	tr.loadGroundTextures(gnd, texLoader, fallbackTex)
+	tr.loadGroundTextures(gnd, texLoader)
 
 	// Build lightmap atlas
 	tr.lightmapAtlas = terrain.BuildLightmapAtlas(gnd)
@@ -133,7 +153,10 @@ func (tr *TerrainRenderer) LoadTerrain(gnd *formats.GND, texLoader func(string)
 	return nil
 }
 
-func (tr *TerrainRenderer) loadGroundTextures(gnd *formats.GND, texLoader func(string) ([]byte, error), fallbackTex uint32) {
+// loadGroundTextures decodes the GND textures and uploads them as a texture
+// array. Textures that fail to load become white layers.
+func (tr *TerrainRenderer) loadGroundTextures(gnd *formats.GND, texLoader func(string) ([]byte, error)) {
+	images := make([]*image.RGBA, len(gnd.Textures))
 	for i, texPath := range gnd.Textures {
 		fullPath := "data/texture/" + texPath
 
@@ -144,59 +167,87 @@ func (tr *TerrainRenderer) loadGroundTextures(gnd *formats.GND, texLoader func(s
 			data, err = texLoader(fullPath)
 		}
 		if err != nil {
-			tr.groundTextures[i] = fallbackTex
 			continue
 		}
 
 		img, err := tr.decodeTexture(data, texPath)
 		if err != nil {
-			tr.groundTextures[i] = fallbackTex
 			continue
 		}
+		images[i] = img
+	}
 
-		tr.groundTextures[i] = tr.uploadTexture(img)
+	if len(images) == 0 {
+		return
+	}
+	arr := terrain.BuildTextureArray(images, terrain.MaxTextureLayerSize)
+	tr.groundTexArray = uploadTextureArray(arr)
+	tr.groundTexLayers = arr.Layers
+	tr.groundTexSize = arr.Size
+	tr.memory.addTexture(arr.Size, arr.Size, arr.Layers, true)
+
+	if tr.CompareTextures {
+		tr.groundTextures = make(map[int]uint32, len(images))
+		for i, img := range images {
+			if img == nil {
+				continue
+			}
+			tr.groundTextures[i] = uploadGroundTexture(img)
+			tr.memory.addTexture(img.Bounds().Dx(), img.Bounds().Dy(), 1, true)
+		}
 	}
 }
 
 func (tr *TerrainRenderer) decodeTexture(data []byte, path string) (*image.RGBA, error) {
-	lowerPath := strings.ToLower(path)
-
-	var img image.Image
-	var err error
-
-	if strings.HasSuffix(lowerPath, ".tga") {
-		img, err = texture.DecodeTGA(data)
-	} else {
-		img, _, err = image.Decode(bytes.NewReader(data))
-	}
-
+	img, err := texture.Load(data, path, texture.CategoryTerrain)
 	if err != nil {
 		return nil, fmt.Errorf("decode %s: %w", path, err)
 	}
+	return img, nil
+}
+
+// uploadTextureArray uploads a ground texture array with mipmaps.
+func uploadTextureArray(arr *terrain.TextureArray) uint32 {
+	var texID uint32
+	gl.GenTextures(1, &texID)
+	gl.BindTexture(gl.TEXTURE_2D_ARRAY, texID)
 
-	return texture.ImageToRGBA(img, true), nil
+	gl.TexImage3D(gl.TEXTURE_2D_ARRAY, 0, gl.RGBA,
+		int32(arr.Size), int32(arr.Size), int32(arr.Layers),
+		0, gl.RGBA, gl.UNSIGNED_BYTE, unsafe.Pointer(&arr.Data[0]))
+
+	texfilter.GenerateMipmaps(gl.TEXTURE_2D_ARRAY)
+	texfilter.Apply(gl.TEXTURE_2D_ARRAY)
+	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_WRAP_S, gl.REPEAT)
+	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_WRAP_T, gl.REPEAT)
+
+	gl.BindTexture(gl.TEXTURE_2D_ARRAY, 0)
+	return texID
 }
 
-func (tr *TerrainRenderer) uploadTexture(img *image.RGBA) uint32 {
+// uploadGroundTexture uploads a ground texture on its own, with mipmaps.
+func uploadGroundTexture(img *image.RGBA) uint32 {
 	var texID uint32
 	gl.GenTextures(1, &texID)
 	gl.BindTexture(gl.TEXTURE_2D, texID)
-
-	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA,
-		int32(img.Bounds().Dx()), int32(img.Bounds().Dy()),
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA, int32(img.Bounds().Dx()), int32(img.Bounds().Dy()),
 		0, gl.RGBA, gl.UNSIGNED_BYTE, unsafe.Pointer(&img.Pix[0]))
-
-	gl.GenerateMipmap(gl.TEXTURE_2D)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR_MIPMAP_LINEAR)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	texfilter.GenerateMipmaps(gl.TEXTURE_2D)
+	texfilter.Apply(gl.TEXTURE_2D)
 	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.REPEAT)
 	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.REPEAT)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAX_LEVEL, 4)
-	gl.TexParameterf(gl.TEXTURE_2D, gl.TEXTURE_MAX_ANISOTROPY, 8.0)
-
 	return texID
 }
 
+// ApplyTextureFilter re-applies the texture filtering policy to the ground
+// textures.
+func (tr *TerrainRenderer) ApplyTextureFilter() {
+	texfilter.ApplyTo(gl.TEXTURE_2D_ARRAY, tr.groundTexArray)
+	for _, tex := range tr.groundTextures {
+		texfilter.ApplyTo(gl.TEXTURE_2D, tex)
+	}
+}
+
 func (tr *TerrainRenderer) uploadLightmapAtlas() {
 	if tr.lightmapAtlas == nil || len(tr.lightmapAtlas.Data) == 0 {
 		return
@@ -214,6 +265,7 @@ func (tr *TerrainRenderer) uploadLightmapAtlas() {
 	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
 	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
 	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	tr.memory.addTexture(int(tr.lightmapAtlas.Size), int(tr.lightmapAtlas.Size), 1, false)
 }
 
 func (tr *TerrainRenderer) uploadTerrainMesh(vertices []terrain.Vertex, indices []uint32) {
@@ -246,12 +298,18 @@ func (tr *TerrainRenderer) uploadTerrainMesh(vertices []terrain.Vertex, indices
 	gl.VertexAttribPointerWithOffset(4, 4, gl.FLOAT, false, int32(vertexSize), 10*4)
 	gl.EnableVertexAttribArray(4)
 
+	// TexLayer (location 5)
+	gl.VertexAttribPointerWithOffset(5, 1, gl.FLOAT, false, int32(vertexSize), 14*4)
+	gl.EnableVertexAttribArray(5)
+
 	// EBO
 	gl.GenBuffers(1, &tr.ebo)
 	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, tr.ebo)
 	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, unsafe.Pointer(&indices[0]), gl.STATIC_DRAW)
 
 	gl.BindVertexArray(0)
+	tr.memory.addBuffer(len(vertices) * vertexSize)
+	tr.memory.addBuffer(len(indices) * 4)
 }
 
 // Render renders the terrain.
@@ -333,21 +391,60 @@ func (tr *TerrainRenderer) Render(viewProj math.Mat4, lightDir, ambient, diffuse
 	gl.BindTexture(gl.TEXTURE_2D, tr.lightmapAtlasTex)
 	gl.Uniform1i(tr.locLightmap, 1)
 
-	// Draw each texture group
+	// Sampler types can't share a texture unit
+	gl.Uniform1i(tr.locTexture, 0)
+	gl.Uniform1i(tr.locTextureArray, 3)
+
 	gl.BindVertexArray(tr.vao)
+	if tr.drawsFromArray() {
+		// Draw all texture groups at once; each vertex picks its array layer
+		gl.Uniform1i(tr.locUseTexArray, 1)
+		gl.ActiveTexture(gl.TEXTURE3)
+		gl.BindTexture(gl.TEXTURE_2D_ARRAY, tr.groundTexArray)
+		gl.DrawElements(gl.TRIANGLES, tr.indexCount(), gl.UNSIGNED_INT, nil)
+	} else {
+		gl.Uniform1i(tr.locUseTexArray, 0)
+		gl.ActiveTexture(gl.TEXTURE0)
+		for _, group := range tr.groups {
+			gl.BindTexture(gl.TEXTURE_2D, tr.groundTextures[group.TextureID])
+			gl.DrawElementsWithOffset(gl.TRIANGLES, group.IndexCount, gl.UNSIGNED_INT, uintptr(group.StartIndex*4))
+		}
+	}
 	gl.ActiveTexture(gl.TEXTURE0)
-	gl.Uniform1i(tr.locTexture, 0)
+	gl.BindVertexArray(0)
+}
+
+// drawsFromArray reports whether the terrain is drawn from the texture
+// array rather than one texture per group.
+func (tr *TerrainRenderer) drawsFromArray() bool {
+	return tr.UseTextureArray || tr.groundTextures == nil
+}
 
+// DrawCalls returns the number of draw calls the terrain takes.
+func (tr *TerrainRenderer) DrawCalls() int {
+	if tr.drawsFromArray() {
+		return 1
+	}
+	return len(tr.groups)
+}
+
+// indexCount returns the number of indices across all texture groups.
+func (tr *TerrainRenderer) indexCount() int32 {
+	var total int32
 	for _, group := range tr.groups {
-		tex, ok := tr.groundTextures[group.TextureID]
-		if !ok {
-			continue
-		}
-		gl.BindTexture(gl.TEXTURE_2D, tex)
-		gl.DrawElementsWithOffset(gl.TRIANGLES, group.IndexCount, gl.UNSIGNED_INT, uintptr(group.StartIndex*4))
+		total += group.IndexCount
 	}
+	return total
+}
 
-	gl.BindVertexArray(0)
+// TextureStats returns the ground texture array's layer count and layer size.
+func (tr *TerrainRenderer) TextureStats() (layers, size int) {
+	return tr.groundTexLayers, tr.groundTexSize
+}
+
+// Memory returns the GPU memory the loaded terrain holds.
+func (tr *TerrainRenderer) Memory() Memory {
+	return tr.memory
 }
 
 // RenderShadow renders the terrain to the shadow map.
@@ -357,11 +454,7 @@ func (tr *TerrainRenderer) RenderShadow() {
 	}
 
 	gl.BindVertexArray(tr.vao)
-	var totalIndices int32
-	for _, group := range tr.groups {
-		totalIndices += group.IndexCount
-	}
-	gl.DrawElements(gl.TRIANGLES, totalIndices, gl.UNSIGNED_INT, nil)
+	gl.DrawElements(gl.TRIANGLES, tr.indexCount(), gl.UNSIGNED_INT, nil)
 	gl.BindVertexArray(0)
 }
 
@@ -378,16 +471,20 @@ func (tr *TerrainRenderer) clearTerrain() {
 		gl.DeleteBuffers(1, &tr.ebo)
 		tr.ebo = 0
 	}
+	if tr.groundTexArray != 0 {
+		gl.DeleteTextures(1, &tr.groundTexArray)
+		tr.groundTexArray = 0
+	}
+	tr.groundTexLayers, tr.groundTexSize = 0, 0
 	for _, tex := range tr.groundTextures {
-		if tex != 0 {
-			gl.DeleteTextures(1, &tex)
-		}
+		gl.DeleteTextures(1, &tex)
 	}
-	tr.groundTextures = make(map[int]uint32)
+	tr.groundTextures = nil
 	if tr.lightmapAtlasTex != 0 {
 		gl.DeleteTextures(1, &tr.lightmapAtlasTex)
 		tr.lightmapAtlasTex = 0
 	}
+	tr.memory = Memory{}
 }
 
 // Destroy releases all resources.
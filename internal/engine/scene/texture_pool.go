@@ -0,0 +1,101 @@
+package scene
+
+import (
+	"github.com/go-gl/gl/v4.1-core/gl"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/sprite"
+)
+
+// DefaultTexturePoolSize is how many released textures a TexturePool keeps
+// per size for reuse.
+const DefaultTexturePoolSize = 32
+
+// TexturePool recycles RGBA textures by size. Entity sprites are composited
+// into textures of a few common sizes; reusing the GL objects as actors come
+// and go avoids texture creation and storage allocation mid-frame.
+type TexturePool struct {
+	free    map[[2]int32][]uint32 // Released textures by width, height
+	sizes   map[uint32][2]int32   // Size of every texture handed out
+	maxFree int                   // Per size
+}
+
+// NewTexturePool creates a pool keeping up to maxFree released textures of
+// each size.
+func NewTexturePool(maxFree int) *TexturePool {
+	return &TexturePool{
+		free:    make(map[[2]int32][]uint32),
+		sizes:   make(map[uint32][2]int32),
+		maxFree: maxFree,
+	}
+}
+
+// Acquire returns a width x height RGBA texture with clamped, nearest
+// filtering. Its contents are undefined; upload with TexSubImage2D.
+func (p *TexturePool) Acquire(width, height int32) uint32 {
+	size := [2]int32{width, height}
+	if free := p.free[size]; len(free) > 0 {
+		tex := free[len(free)-1]
+		p.free[size] = free[:len(free)-1]
+		return tex
+	}
+
+	var tex uint32
+	gl.GenTextures(1, &tex)
+	gl.BindTexture(gl.TEXTURE_2D, tex)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA8, width, height, 0, gl.RGBA, gl.UNSIGNED_BYTE, nil)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+
+	p.sizes[tex] = size
+	return tex
+}
+
+// Upload acquires a texture of a composited sprite's size and fills it
+// with the sprite's pixels. Returns 0 for an empty sprite.
+func (p *TexturePool) Upload(result sprite.CompositeResult) uint32 {
+	if result.Width == 0 || result.Height == 0 {
+		return 0
+	}
+	tex := p.Acquire(int32(result.Width), int32(result.Height))
+	gl.BindTexture(gl.TEXTURE_2D, tex)
+	gl.TexSubImage2D(gl.TEXTURE_2D, 0, 0, 0, int32(result.Width), int32(result.Height),
+		gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(result.Pixels))
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+	return tex
+}
+
+// Release returns a texture from Acquire to the pool, deleting it if the
+// pool already holds enough of its size. Unknown textures are ignored.
+func (p *TexturePool) Release(tex uint32) {
+	size, ok := p.sizes[tex]
+	if !ok {
+		return
+	}
+	if len(p.free[size]) >= p.maxFree {
+		delete(p.sizes, tex)
+		gl.DeleteTextures(1, &tex)
+		return
+	}
+	p.free[size] = append(p.free[size], tex)
+}
+
+// Stats returns the number of textures handed out and waiting for reuse.
+func (p *TexturePool) Stats() (live, free int) {
+	for _, f := range p.free {
+		free += len(f)
+	}
+	return len(p.sizes) - free, free
+}
+
+// Destroy deletes every texture the pool created, including ones still in
+// use.
+func (p *TexturePool) Destroy() {
+	for tex := range p.sizes {
+		gl.DeleteTextures(1, &tex)
+	}
+	clear(p.sizes)
+	clear(p.free)
+}
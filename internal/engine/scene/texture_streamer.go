@@ -0,0 +1,401 @@
+package scene
+
+import (
+	"cmp"
+	"fmt"
+	"image"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/texfilter"
+	"github.com/Faultbox/midgard-ro/internal/engine/texture"
+	"github.com/Faultbox/midgard-ro/pkg/grf"
+)
+
+const (
+	// DefaultTextureBudget is the streamed texture memory, in bytes, past
+	// which off-screen textures lose their high mips.
+	DefaultTextureBudget = 128 << 20
+
+	// DefaultTextureUploadBudget is how many bytes of mip levels are
+	// uploaded per frame while refining textures.
+	DefaultTextureUploadBudget = 1 << 20
+
+	// streamInitialSize is the largest side of the mip level uploaded when
+	// a texture loads; finer levels stream in once it's seen up close.
+	streamInitialSize = 64
+
+	// streamIdleTime is how long a texture stays off screen before its
+	// high mips may be evicted.
+	streamIdleTime = 10 * time.Second
+)
+
+// TextureStreamer is the scene's texture manager for model textures.
+// Loading a town's textures at full resolution on map entry spikes memory
+// and load time, so only the low mips are uploaded up front. Textures
+// then refine toward the size they're seen at on screen, a few levels a
+// frame, and when resident memory passes the budget the high mips of
+// textures that have been off screen for a while are evicted. Textures
+// are shared by path, so one used by many models loads once.
+type TextureStreamer struct {
+	textures map[string]*streamedTexture // By lower-case path
+	byID     map[uint32]*streamedTexture
+	resident int // Bytes of uploaded mip levels
+
+	Budget       int  // Resident bytes before off-screen textures are evicted
+	UploadBudget int  // Bytes uploaded per Update while refining
+	Unkeyed      bool // Load textures without keying out transparent pixels
+}
+
+// streamedTexture is one texture with a partially uploaded mip chain.
+// Level 0 is the full resolution; base is the finest level on the GPU.
+type streamedTexture struct {
+	id            uint32
+	path          string
+	load          func(string) ([]byte, error)
+	width, height int       // Level 0
+	levels        int       // In the full chain, down to 1x1
+	initial       int       // Level uploaded on load, never evicted
+	base          int       // Finest uploaded level
+	want          int       // Finest level requested since the last Update
+	seen          float32   // Largest screen size requested since the last Update
+	lastSeen      time.Time // Last requested
+	mips          [][]byte  // Decoded chain, kept while levels are pending
+	refs          int
+}
+
+// TextureStats describes the streamer's textures, for debug overlays.
+type TextureStats struct {
+	Textures  int // Loaded textures
+	Partial   int // Textures without their full-resolution level uploaded
+	Resident  int // Bytes of uploaded mip levels
+	FullBytes int // Bytes every texture would take at full resolution
+}
+
+// NewTextureStreamer creates a texture manager with the default budgets.
+func NewTextureStreamer() *TextureStreamer {
+	return &TextureStreamer{
+		textures:     make(map[string]*streamedTexture),
+		byID:         make(map[uint32]*streamedTexture),
+		Budget:       DefaultTextureBudget,
+		UploadBudget: DefaultTextureUploadBudget,
+	}
+}
+
+// Acquire returns the GL texture for path, loading it through load the
+// first time with only its low mips uploaded. Each call takes a reference
+// that Release gives back.
+func (ts *TextureStreamer) Acquire(path string, load func(string) ([]byte, error)) (uint32, error) {
+	key := strings.ToLower(path)
+	if t, ok := ts.textures[key]; ok {
+		t.refs++
+		return t.id, nil
+	}
+
+	img, err := loadTexture(path, load, !ts.Unkeyed)
+	if err != nil {
+		return 0, err
+	}
+	t := &streamedTexture{path: path, load: load, refs: 1}
+	gl.GenTextures(1, &t.id)
+	ts.upload(t, img)
+	ts.textures[key] = t
+	ts.byID[t.id] = t
+	return t.id, nil
+}
+
+// upload uploads the low mips of img to t's GL texture, replacing what it
+// held.
+func (ts *TextureStreamer) upload(t *streamedTexture, img *image.RGBA) {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	t.mips = buildMipChain(img.Pix, w, h)
+	t.width, t.height = w, h
+	t.levels = len(t.mips)
+	t.initial = initialMipLevel(w, h)
+	t.base, t.want = t.initial, t.levels
+
+	gl.BindTexture(gl.TEXTURE_2D, t.id)
+	for level := t.levels - 1; level >= t.initial; level-- {
+		ts.uploadLevel(t, level)
+	}
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_BASE_LEVEL, int32(t.base))
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAX_LEVEL, int32(t.levels-1))
+	texfilter.Apply(gl.TEXTURE_2D)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.REPEAT)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.REPEAT)
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+
+	if t.base == 0 {
+		t.mips = nil
+	}
+}
+
+// Reload loads the textures of edited files again, into the GL textures
+// they had. names are normalized file names (see grf.NormalizePath).
+// Returns the number of textures reloaded.
+func (ts *TextureStreamer) Reload(names []string) int {
+	n := 0
+	for _, t := range ts.textures {
+		if !slices.Contains(names, grf.NormalizePath(t.path)) {
+			continue
+		}
+		img, err := loadTexture(t.path, t.load, !ts.Unkeyed)
+		if err != nil {
+			continue // Keep the old look until the file loads again
+		}
+		ts.resident -= t.residentBytes()
+		ts.upload(t, img)
+		n++
+	}
+	return n
+}
+
+// ApplyFilter re-applies the texture filtering policy to every texture.
+func (ts *TextureStreamer) ApplyFilter() {
+	for id := range ts.byID {
+		texfilter.ApplyTo(gl.TEXTURE_2D, id)
+	}
+}
+
+// Release gives back a reference from Acquire, deleting the texture when
+// no model uses it. Unknown textures are ignored.
+func (ts *TextureStreamer) Release(id uint32) {
+	t, ok := ts.byID[id]
+	if !ok {
+		return
+	}
+	t.refs--
+	if t.refs > 0 {
+		return
+	}
+	ts.resident -= t.residentBytes()
+	delete(ts.byID, id)
+	delete(ts.textures, strings.ToLower(t.path))
+	gl.DeleteTextures(1, &id)
+}
+
+// Request notes that a texture is drawn this frame, covering about
+// screenPx pixels at its largest, so Update can refine it.
+func (ts *TextureStreamer) Request(id uint32, screenPx float32, now time.Time) {
+	t, ok := ts.byID[id]
+	if !ok {
+		return
+	}
+	t.want = min(t.want, wantedMipLevel(t.width, t.height, screenPx))
+	t.seen = max(t.seen, screenPx)
+	t.lastSeen = now
+}
+
+// Update uploads the finer mip levels of the textures requested since the
+// last call, largest on screen first and within the upload budget, then
+// evicts high mips of idle textures if memory is over budget.
+func (ts *TextureStreamer) Update(now time.Time) {
+	budget := ts.UploadBudget
+	for _, t := range ts.refineOrder() {
+		if budget <= 0 {
+			break
+		}
+		if t.mips == nil {
+			// Evicted earlier; decode the texture again
+			img, err := loadTexture(t.path, t.load, !ts.Unkeyed)
+			if err != nil {
+				t.want = t.base // Don't retry every frame
+				continue
+			}
+			t.mips = buildMipChain(img.Pix, t.width, t.height)
+			budget -= len(t.mips[0])
+		}
+
+		gl.BindTexture(gl.TEXTURE_2D, t.id)
+		for t.base > t.want && budget > 0 {
+			ts.uploadLevel(t, t.base-1)
+			budget -= len(t.mips[t.base-1])
+			t.base--
+		}
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_BASE_LEVEL, int32(t.base))
+		if t.base == 0 {
+			t.mips = nil
+		}
+	}
+
+	for _, t := range ts.evictionOrder(now) {
+		if ts.resident <= ts.Budget {
+			break
+		}
+		ts.evict(t)
+	}
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+
+	for _, t := range ts.textures {
+		t.want = t.levels
+		t.seen = 0
+	}
+}
+
+// refineOrder returns the textures requested at a finer level than is
+// uploaded, largest on screen first.
+func (ts *TextureStreamer) refineOrder() []*streamedTexture {
+	var out []*streamedTexture
+	for _, t := range ts.textures {
+		if t.want < t.base {
+			out = append(out, t)
+		}
+	}
+	slices.SortFunc(out, func(a, b *streamedTexture) int {
+		if c := cmp.Compare(b.seen, a.seen); c != 0 {
+			return c
+		}
+		return strings.Compare(a.path, b.path)
+	})
+	return out
+}
+
+// evictionOrder returns the textures with high mips to spare that have
+// been off screen past streamIdleTime, longest unseen first.
+func (ts *TextureStreamer) evictionOrder(now time.Time) []*streamedTexture {
+	var out []*streamedTexture
+	for _, t := range ts.textures {
+		if t.base < t.initial && now.Sub(t.lastSeen) > streamIdleTime {
+			out = append(out, t)
+		}
+	}
+	slices.SortFunc(out, func(a, b *streamedTexture) int {
+		if c := a.lastSeen.Compare(b.lastSeen); c != 0 {
+			return c
+		}
+		return strings.Compare(a.path, b.path)
+	})
+	return out
+}
+
+// evict drops a texture's levels finer than its initial one.
+func (ts *TextureStreamer) evict(t *streamedTexture) {
+	gl.BindTexture(gl.TEXTURE_2D, t.id)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_BASE_LEVEL, int32(t.initial))
+	for level := t.base; level < t.initial; level++ {
+		// A zero-sized image frees the level's storage
+		gl.TexImage2D(gl.TEXTURE_2D, int32(level), gl.RGBA8, 0, 0, 0, gl.RGBA, gl.UNSIGNED_BYTE, nil)
+		w, h := mipSize(t.width, t.height, level)
+		ts.resident -= w * h * 4
+	}
+	t.base = t.initial
+	t.mips = nil
+}
+
+// uploadLevel uploads one mip level to the bound texture.
+func (ts *TextureStreamer) uploadLevel(t *streamedTexture, level int) {
+	w, h := mipSize(t.width, t.height, level)
+	gl.TexImage2D(gl.TEXTURE_2D, int32(level), gl.RGBA8, int32(w), int32(h), 0, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(t.mips[level]))
+	ts.resident += w * h * 4
+}
+
+// Stats returns a snapshot of the streamed textures.
+func (ts *TextureStreamer) Stats() TextureStats {
+	stats := TextureStats{Textures: len(ts.textures), Resident: ts.resident}
+	for _, t := range ts.textures {
+		if t.base > 0 {
+			stats.Partial++
+		}
+		for level := range t.levels {
+			w, h := mipSize(t.width, t.height, level)
+			stats.FullBytes += w * h * 4
+		}
+	}
+	return stats
+}
+
+// Destroy deletes every texture, including ones still referenced.
+func (ts *TextureStreamer) Destroy() {
+	for id := range ts.byID {
+		gl.DeleteTextures(1, &id)
+	}
+	clear(ts.textures)
+	clear(ts.byID)
+	ts.resident = 0
+}
+
+// residentBytes returns the size of a texture's uploaded levels.
+func (t *streamedTexture) residentBytes() int {
+	n := 0
+	for level := t.base; level < t.levels; level++ {
+		w, h := mipSize(t.width, t.height, level)
+		n += w * h * 4
+	}
+	return n
+}
+
+// loadTexture reads and decodes a model texture, keying out its
+// transparent pixels as the texture policy says if keyed.
+func loadTexture(path string, load func(string) ([]byte, error), keyed bool) (*image.RGBA, error) {
+	data, err := load(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading %s: %w", path, err)
+	}
+	img, err := texture.Decode(data, path)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", path, err)
+	}
+	key := texture.KeyNone
+	if keyed {
+		key = texture.KeyFor(texture.CategoryModel, path)
+	}
+	rgba := texture.ToRGBA(img, key)
+	if len(rgba.Pix) == 0 {
+		return nil, fmt.Errorf("decoding %s: empty image", path)
+	}
+	return rgba, nil
+}
+
+// mipSize returns the size of a mip level of a width x height texture.
+func mipSize(width, height, level int) (int, int) {
+	return max(width>>level, 1), max(height>>level, 1)
+}
+
+// initialMipLevel returns the finest level no larger than
+// streamInitialSize on either side.
+func initialMipLevel(width, height int) int {
+	level := 0
+	for max(width, height)>>level > streamInitialSize {
+		level++
+	}
+	return level
+}
+
+// wantedMipLevel returns the level that samples about one texel per pixel
+// for a texture covering screenPx pixels.
+func wantedMipLevel(width, height int, screenPx float32) int {
+	size, level := max(width, height), 0
+	for size>>(level+1) > 0 && float32(size>>(level+1)) >= screenPx {
+		level++
+	}
+	return level
+}
+
+// buildMipChain box-filters RGBA pixels down to 1x1, returning every level
+// with level 0 the pixels themselves.
+func buildMipChain(pix []byte, width, height int) [][]byte {
+	chain := [][]byte{pix}
+	w, h := width, height
+	for w > 1 || h > 1 {
+		nw, nh := max(w/2, 1), max(h/2, 1)
+		next := make([]byte, nw*nh*4)
+		src := chain[len(chain)-1]
+		for y := range nh {
+			y0, y1 := min(y*2, h-1), min(y*2+1, h-1)
+			for x := range nw {
+				x0, x1 := min(x*2, w-1), min(x*2+1, w-1)
+				for c := range 4 {
+					sum := int(src[(y0*w+x0)*4+c]) + int(src[(y0*w+x1)*4+c]) +
+						int(src[(y1*w+x0)*4+c]) + int(src[(y1*w+x1)*4+c])
+					next[(y*nw+x)*4+c] = uint8((sum + 2) / 4)
+				}
+			}
+		}
+		chain = append(chain, next)
+		w, h = nw, nh
+	}
+	return chain
+}
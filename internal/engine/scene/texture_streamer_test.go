@@ -0,0 +1,128 @@
+package scene
+
+import (
+	gomath "math"
+	"testing"
+	"time"
+
+	"github.com/Faultbox/midgard-ro/pkg/math"
+)
+
+func TestBuildMipChain(t *testing.T) {
+	// 4x2 with a white left half and transparent black right half
+	pix := make([]byte, 4*2*4)
+	for y := range 2 {
+		for x := range 2 {
+			copy(pix[(y*4+x)*4:], []byte{255, 255, 255, 255})
+		}
+	}
+	chain := buildMipChain(pix, 4, 2)
+	if len(chain) != 3 {
+		t.Fatalf("got %d levels, want 3 (4x2, 2x1, 1x1)", len(chain))
+	}
+	for level, want := range []int{32, 8, 4} {
+		if len(chain[level]) != want {
+			t.Errorf("level %d has %d bytes, want %d", level, len(chain[level]), want)
+		}
+	}
+	if got := chain[1][:4]; got[0] != 255 || got[3] != 255 {
+		t.Errorf("level 1 left texel = %v, want opaque white", got)
+	}
+	if got := chain[2]; got[0] != 128 || got[3] != 128 {
+		t.Errorf("level 2 texel = %v, want the half-white average", got)
+	}
+}
+
+func TestMipLevels(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		screenPx      float32
+		initial, want int
+	}{
+		{"small texture", 32, 32, 200, 0, 0},
+		{"close up", 256, 256, 300, 2, 0},
+		{"mid distance", 256, 256, 100, 2, 1},
+		{"far away", 256, 256, 10, 2, 4},
+		{"off screen", 256, 128, 0, 2, 8},
+		{"wide", 512, 64, 64, 3, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := initialMipLevel(tt.width, tt.height); got != tt.initial {
+				t.Errorf("initialMipLevel = %d, want %d", got, tt.initial)
+			}
+			if got := wantedMipLevel(tt.width, tt.height, tt.screenPx); got != tt.want {
+				t.Errorf("wantedMipLevel(%v) = %d, want %d", tt.screenPx, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTextureStreamerOrder(t *testing.T) {
+	now := time.Unix(1000, 0)
+	ts := NewTextureStreamer()
+	add := func(id uint32, path string, base int, lastSeen time.Time) *streamedTexture {
+		tex := &streamedTexture{id: id, path: path, width: 256, height: 256, levels: 9, initial: 2, base: base, want: 9, lastSeen: lastSeen}
+		ts.textures[path] = tex
+		ts.byID[id] = tex
+		return tex
+	}
+	near := add(1, "near.bmp", 2, now)
+	far := add(2, "far.bmp", 2, now)
+	add(3, "sharp.bmp", 0, now.Add(-time.Minute))
+	add(4, "recent.bmp", 0, now.Add(-time.Second))
+	add(5, "unrefined.bmp", 2, now.Add(-time.Hour))
+
+	ts.Request(1, 300, now)
+	ts.Request(2, 100, now)
+	ts.Request(2, 20, now) // A second model further away doesn't lower the want
+	ts.Request(99, 300, now)
+	if near.want != 0 || far.want != 1 {
+		t.Fatalf("wants = %d, %d; want 0, 1", near.want, far.want)
+	}
+
+	refine := ts.refineOrder()
+	if len(refine) != 2 || refine[0] != near || refine[1] != far {
+		t.Errorf("refine order = %v, want the larger on screen first", paths(refine))
+	}
+
+	// Only textures with high mips, off screen long enough, are evicted
+	evict := ts.evictionOrder(now)
+	if len(evict) != 1 || evict[0].path != "sharp.bmp" {
+		t.Errorf("eviction order = %v, want [sharp.bmp]", paths(evict))
+	}
+}
+
+func TestScreenSize(t *testing.T) {
+	view := math.LookAt(math.Vec3{X: 0, Y: 0, Z: 100}, math.Vec3{}, math.Vec3{Y: 1})
+	viewProj := math.Perspective(gomath.Pi/2, 1, 1, 1000).Mul(view)
+	box := func(x, half float32) math.AABB {
+		return math.AABB{
+			Min: math.Vec3{X: x - half, Y: -half, Z: -half},
+			Max: math.Vec3{X: x + half, Y: half, Z: half},
+		}
+	}
+
+	near := screenSize(box(0, 10), viewProj, 800, 800)
+	if near < 60 || near > 120 {
+		t.Errorf("box in view spans %v px, want about 80", near)
+	}
+	if far := screenSize(box(0, 1), viewProj, 800, 800); far >= near {
+		t.Errorf("smaller box spans %v px, not less than %v", far, near)
+	}
+	if off := screenSize(box(1000, 10), viewProj, 800, 800); off != 0 {
+		t.Errorf("box out of view spans %v px, want 0", off)
+	}
+	if around := screenSize(box(0, 200), viewProj, 800, 600); around != 800 {
+		t.Errorf("box around the camera spans %v px, want the viewport's 800", around)
+	}
+}
+
+func paths(textures []*streamedTexture) []string {
+	var out []string
+	for _, t := range textures {
+		out = append(out, t.path)
+	}
+	return out
+}
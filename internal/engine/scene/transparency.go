@@ -0,0 +1,134 @@
+package scene
+
+import (
+	"sort"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+
+	"github.com/Faultbox/midgard-ro/pkg/math"
+)
+
+// Soft edge distances, in world units: how far in front of the opaque
+// world a transparent draw fades in, instead of being cut off where it
+// meets it.
+const (
+	effectSoftDistance = 3.0 // Flames and smoke licking the ground
+	spriteSoftDistance = 1.5 // Characters' feet and dropped items
+	waterSoftDistance  = 4.0 // Shorelines
+)
+
+// transparentDraw is a billboard waiting in the transparency queue.
+type transparentDraw struct {
+	depth    float32 // View depth, larger is farther
+	viewProj math.Mat4
+	camRight math.Vec3
+	camUp    math.Vec3
+	worldPos [3]float32
+	width    float32
+	height   float32
+	texture  uint32
+	tint     [4]float32
+	soft     float32
+}
+
+// transparencyQueue collects a render's transparent billboards, effects
+// and sprites alike, to draw them back to front once the opaque world is
+// done. They don't write depth, so drawn in any other order a far one
+// would paint over a near one.
+type transparencyQueue struct {
+	view  math.Mat4
+	draws []transparentDraw
+}
+
+// begin empties the queue for a render seen through view.
+func (q *transparencyQueue) begin(view math.Mat4) {
+	q.view = view
+	q.draws = q.draws[:0]
+}
+
+// add queues a billboard; its depth is that of its anchor.
+func (q *transparencyQueue) add(d transparentDraw) {
+	d.depth = viewDepth(q.view, d.worldPos)
+	q.draws = append(q.draws, d)
+}
+
+// sort orders the queue back to front. Draws at the same depth keep the
+// order they were added in, so a frame sorts like the last one.
+func (q *transparencyQueue) sort() {
+	sort.SliceStable(q.draws, func(i, j int) bool {
+		return q.draws[i].depth > q.draws[j].depth
+	})
+}
+
+// flush draws the queue back to front, fading each draw into the scene
+// depth, and empties it.
+func (q *transparencyQueue) flush(sr *SpriteRenderer, depth softDepth) {
+	q.sort()
+	sr.bindSceneDepth(depth)
+	for i := range q.draws {
+		d := &q.draws[i]
+		sr.draw(d.viewProj, d.camRight, d.camUp, d.worldPos, d.width, d.height, d.texture, d.tint, d.soft)
+	}
+	q.draws = q.draws[:0]
+}
+
+// viewDepth returns how far in front of the camera a world position is.
+func viewDepth(view math.Mat4, p [3]float32) float32 {
+	// The view matrix's third row (column-major) gives view-space Z, which
+	// points back at the camera
+	return -(view[2]*p[0] + view[6]*p[1] + view[10]*p[2] + view[14])
+}
+
+// softDepth is what transparent draws need to fade into the opaque world:
+// its depth, and the projection terms turning it back into view depth.
+// A zero texture draws hard edges.
+type softDepth struct {
+	texture uint32
+	params  [2]float32 // Projection's [10] and [14]
+}
+
+// softDepthFor returns the soft edge parameters for a depth copy seen
+// through proj. Orthographic views (the picture-in-picture) draw hard
+// edges: their depth is linear, and the fade is tuned for perspective.
+func softDepthFor(dc *depthCopy, proj math.Mat4) softDepth {
+	if dc == nil || proj[11] == 0 {
+		return softDepth{}
+	}
+	return softDepth{texture: dc.texture, params: [2]float32{proj[10], proj[14]}}
+}
+
+// depthCopy is a copy of a render target's depth buffer as it stands once
+// the opaque world is drawn. The depth attachment can't be sampled while
+// it's being drawn with, so soft edges read the copy.
+type depthCopy struct {
+	texture uint32
+	width   int32
+	height  int32
+}
+
+// capture copies the bound framebuffer's depth, sized width by height.
+func (c *depthCopy) capture(width, height int32) {
+	if c.texture == 0 {
+		gl.GenTextures(1, &c.texture)
+		gl.BindTexture(gl.TEXTURE_2D, c.texture)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	}
+	gl.BindTexture(gl.TEXTURE_2D, c.texture)
+	if width != c.width || height != c.height {
+		c.width, c.height = width, height
+		gl.TexImage2D(gl.TEXTURE_2D, 0, gl.DEPTH_COMPONENT24, width, height, 0, gl.DEPTH_COMPONENT, gl.UNSIGNED_INT, nil)
+	}
+	gl.CopyTexSubImage2D(gl.TEXTURE_2D, 0, 0, 0, 0, 0, width, height)
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+}
+
+// destroy releases the copy's texture.
+func (c *depthCopy) destroy() {
+	if c.texture != 0 {
+		gl.DeleteTextures(1, &c.texture)
+		c.texture = 0
+	}
+}
@@ -0,0 +1,46 @@
+package scene
+
+import (
+	"testing"
+
+	"github.com/Faultbox/midgard-ro/pkg/math"
+)
+
+func TestTransparencyQueueSort(t *testing.T) {
+	// Looking down -Z from z = 10
+	view := math.LookAt(math.Vec3{X: 0, Y: 0, Z: 10}, math.Vec3{}, math.Vec3{X: 0, Y: 1, Z: 0})
+	if d := viewDepth(view, [3]float32{3, -2, 4}); d < 5.99 || d > 6.01 {
+		t.Errorf("viewDepth = %v, want 6", d)
+	}
+
+	var q transparencyQueue
+	q.begin(view)
+	for i, z := range []float32{5, -20, 5, 9, -20} {
+		q.add(transparentDraw{worldPos: [3]float32{0, 0, z}, texture: uint32(i + 1)})
+	}
+	q.sort()
+
+	// Far to near; equally far draws keep the order they came in
+	want := []uint32{2, 5, 1, 3, 4}
+	for i, d := range q.draws {
+		if d.texture != want[i] {
+			t.Fatalf("sorted draw %d = %d, want order %v", i, d.texture, want)
+		}
+	}
+
+	q.begin(view)
+	if len(q.draws) != 0 {
+		t.Errorf("begin left %d draws queued", len(q.draws))
+	}
+}
+
+func TestSoftDepthFor(t *testing.T) {
+	dc := &depthCopy{texture: 7}
+	persp := math.Perspective(1, 1.5, 1, 1000)
+	if got := softDepthFor(dc, persp); got.texture != 7 || got.params != [2]float32{persp[10], persp[14]} {
+		t.Errorf("perspective = %+v", got)
+	}
+	if got := softDepthFor(dc, math.Ortho(-1, 1, -1, 1, 1, 100)); got.texture != 0 {
+		t.Errorf("orthographic = %+v, want hard edges", got)
+	}
+}
@@ -2,16 +2,16 @@
 package scene
 
 import (
-	"bytes"
 	"fmt"
 	"image"
-	"strings"
 	"unsafe"
 
 	"github.com/go-gl/gl/v4.1-core/gl"
 
+	"github.com/Faultbox/midgard-ro/internal/engine/glstate"
 	"github.com/Faultbox/midgard-ro/internal/engine/scene/shaders"
 	"github.com/Faultbox/midgard-ro/internal/engine/shader"
+	"github.com/Faultbox/midgard-ro/internal/engine/texfilter"
 	"github.com/Faultbox/midgard-ro/internal/engine/texture"
 	"github.com/Faultbox/midgard-ro/pkg/math"
 )
@@ -27,6 +27,9 @@ type WaterRenderer struct {
 	locTime       int32
 	locWaterTex   int32
 	locUseTexture int32
+	locDepthTex   int32
+	locDepth      int32
+	locSoft       int32
 
 	// Mesh
 	vao uint32
@@ -40,6 +43,9 @@ type WaterRenderer struct {
 	waterFrame     int
 	useWaterTex    bool
 	waterAnimSpeed float32
+
+	// GPU memory held by the water plane and its textures
+	memory Memory
 }
 
 // NewWaterRenderer creates a new water renderer.
@@ -60,12 +66,17 @@ func NewWaterRenderer() (*WaterRenderer, error) {
 	wr.locTime = shader.GetUniform(program, "uTime")
 	wr.locWaterTex = shader.GetUniform(program, "uWaterTex")
 	wr.locUseTexture = shader.GetUniform(program, "uUseTexture")
+	wr.locDepthTex = shader.GetUniform(program, "uDepthTex")
+	wr.locDepth = shader.GetUniform(program, "uDepthParams")
+	wr.locSoft = shader.GetUniform(program, "uSoftDistance")
 
 	return wr, nil
 }
 
-// SetupWater creates a water plane at the specified level.
+// SetupWater creates a water plane at the specified level, replacing the
+// one set up before.
 func (wr *WaterRenderer) SetupWater(level float32, minBounds, maxBounds [3]float32, texLoader func(string) ([]byte, error)) {
+	wr.Clear()
 	wr.waterLevel = level
 	wr.hasWater = true
 
@@ -108,6 +119,7 @@ func (wr *WaterRenderer) createWaterPlane(minBounds, maxBounds [3]float32) {
 	gl.EnableVertexAttribArray(0)
 
 	gl.BindVertexArray(0)
+	wr.memory.addBuffer(len(vertices) * 4)
 }
 
 func (wr *WaterRenderer) loadWaterTextures(texLoader func(string) ([]byte, error)) {
@@ -130,6 +142,7 @@ func (wr *WaterRenderer) loadWaterTextures(texLoader func(string) ([]byte, error
 
 		texID := wr.uploadTexture(img)
 		textures = append(textures, texID)
+		wr.memory.addTexture(img.Bounds().Dx(), img.Bounds().Dy(), 1, true)
 	}
 
 	if len(textures) > 0 {
@@ -139,18 +152,7 @@ func (wr *WaterRenderer) loadWaterTextures(texLoader func(string) ([]byte, error
 }
 
 func (wr *WaterRenderer) decodeTexture(data []byte, path string) (*image.RGBA, error) {
-	lowerPath := strings.ToLower(path)
-	var img image.Image
-	var err error
-	if strings.HasSuffix(lowerPath, ".tga") {
-		img, err = texture.DecodeTGA(data)
-	} else {
-		img, _, err = image.Decode(bytes.NewReader(data))
-	}
-	if err != nil {
-		return nil, err
-	}
-	return texture.ImageToRGBA(img, false), nil
+	return texture.Load(data, path, texture.CategoryWater)
 }
 
 func (wr *WaterRenderer) uploadTexture(img *image.RGBA) uint32 {
@@ -158,19 +160,43 @@ func (wr *WaterRenderer) uploadTexture(img *image.RGBA) uint32 {
 	gl.GenTextures(1, &texID)
 	gl.BindTexture(gl.TEXTURE_2D, texID)
 	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA, int32(img.Bounds().Dx()), int32(img.Bounds().Dy()), 0, gl.RGBA, gl.UNSIGNED_BYTE, unsafe.Pointer(&img.Pix[0]))
-	gl.GenerateMipmap(gl.TEXTURE_2D)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR_MIPMAP_LINEAR)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	texfilter.GenerateMipmaps(gl.TEXTURE_2D)
+	texfilter.Apply(gl.TEXTURE_2D)
 	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.REPEAT)
 	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.REPEAT)
 	return texID
 }
 
+// ApplyTextureFilter re-applies the texture filtering policy to the water
+// animation frames.
+func (wr *WaterRenderer) ApplyTextureFilter() {
+	for _, tex := range wr.waterTextures {
+		texfilter.ApplyTo(gl.TEXTURE_2D, tex)
+	}
+}
+
 // HasWater returns whether water is enabled.
 func (wr *WaterRenderer) HasWater() bool {
 	return wr.hasWater
 }
 
+// AnimSpeed returns the water animation's speed, in frames per second.
+func (wr *WaterRenderer) AnimSpeed() float32 {
+	return wr.waterAnimSpeed
+}
+
+// SetAnimSpeed sets the water animation's speed, in frames per second.
+func (wr *WaterRenderer) SetAnimSpeed(speed float32) {
+	if speed > 0 {
+		wr.waterAnimSpeed = speed
+	}
+}
+
+// Memory returns the GPU memory the water holds.
+func (wr *WaterRenderer) Memory() Memory {
+	return wr.memory
+}
+
 // Update updates water animation.
 func (wr *WaterRenderer) Update(deltaTime float32) {
 	if !wr.hasWater {
@@ -186,17 +212,22 @@ func (wr *WaterRenderer) Update(deltaTime float32) {
 	}
 }
 
-// Render renders the water plane.
+// Render renders the water plane, with hard shorelines.
 func (wr *WaterRenderer) Render(viewProj math.Mat4) {
+	wr.render(viewProj, softDepth{})
+}
+
+// render renders the water plane, its shorelines fading into the scene
+// depth.
+func (wr *WaterRenderer) render(viewProj math.Mat4, depth softDepth) {
 	if !wr.hasWater || wr.vao == 0 {
 		return
 	}
 
 	gl.UseProgram(wr.program)
 
-	// Enable blending for transparency
-	gl.Enable(gl.BLEND)
-	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	// Blend for transparency
+	defer glstate.Push(glstate.Default)()
 
 	// Set uniforms
 	gl.UniformMatrix4fv(wr.locMVP, 1, false, &viewProj[0])
@@ -213,13 +244,25 @@ func (wr *WaterRenderer) Render(viewProj math.Mat4) {
 		gl.Uniform1i(wr.locUseTexture, 0)
 	}
 
+	// Shallows fade out where the water meets the ground
+	var soft float32
+	if depth.texture != 0 {
+		soft = waterSoftDistance
+	}
+	gl.ActiveTexture(gl.TEXTURE1)
+	gl.BindTexture(gl.TEXTURE_2D, depth.texture)
+	gl.Uniform1i(wr.locDepthTex, 1)
+	gl.Uniform2f(wr.locDepth, depth.params[0], depth.params[1])
+	gl.Uniform1f(wr.locSoft, soft)
+	gl.ActiveTexture(gl.TEXTURE0)
+
 	gl.BindVertexArray(wr.vao)
 	gl.DrawArrays(gl.TRIANGLES, 0, 6)
 	gl.BindVertexArray(0)
 }
 
-// Destroy releases all resources.
-func (wr *WaterRenderer) Destroy() {
+// Clear removes the water plane and releases its resources.
+func (wr *WaterRenderer) Clear() {
 	if wr.vao != 0 {
 		gl.DeleteVertexArrays(1, &wr.vao)
 		wr.vao = 0
@@ -234,6 +277,15 @@ func (wr *WaterRenderer) Destroy() {
 		}
 	}
 	wr.waterTextures = nil
+	wr.useWaterTex = false
+	wr.hasWater = false
+	wr.waterTime, wr.waterFrame = 0, 0
+	wr.memory = Memory{}
+}
+
+// Destroy releases all resources.
+func (wr *WaterRenderer) Destroy() {
+	wr.Clear()
 	if wr.program != 0 {
 		gl.DeleteProgram(wr.program)
 		wr.program = 0
@@ -33,6 +33,14 @@ func (b AABB) Radius() float32 {
 // lightDir is the normalized direction TO the light (sun direction).
 // sceneBounds is the AABB of the scene to be shadowed.
 func CalculateDirectionalLightMatrix(lightDir [3]float32, sceneBounds AABB) math.Mat4 {
+	view, proj := DirectionalLightView(lightDir, sceneBounds)
+	return proj.Mul(view)
+}
+
+// DirectionalLightView returns the separate view and projection matrices
+// used by CalculateDirectionalLightMatrix, e.g. to render the scene from the
+// sun's point of view when debugging shadows.
+func DirectionalLightView(lightDir [3]float32, sceneBounds AABB) (view, proj math.Mat4) {
 	center := sceneBounds.Center()
 	radius := sceneBounds.Radius()
 
@@ -54,7 +62,7 @@ func CalculateDirectionalLightMatrix(lightDir [3]float32, sceneBounds AABB) math
 	}
 
 	// View matrix: look from light position towards scene center
-	view := math.LookAt(lightPos, center, up)
+	view = math.LookAt(lightPos, center, up)
 
 	// Orthographic projection sized to encompass the scene
 	// Add padding to avoid edge artifacts
@@ -63,9 +71,9 @@ func CalculateDirectionalLightMatrix(lightDir [3]float32, sceneBounds AABB) math
 	near := 0.1
 	far := lightDistance + radius + padding
 
-	proj := math.Ortho(-halfSize, halfSize, -halfSize, halfSize, float32(near), float32(far))
+	proj = math.Ortho(-halfSize, halfSize, -halfSize, halfSize, float32(near), float32(far))
 
-	return proj.Mul(view)
+	return view, proj
 }
 
 // CalculateTightLightMatrix computes a tighter light matrix based on visible frustum.
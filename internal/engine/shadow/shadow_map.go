@@ -3,6 +3,8 @@ package shadow
 
 import (
 	"github.com/go-gl/gl/v4.1-core/gl"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/glstate"
 )
 
 // Map represents a shadow map framebuffer for directional light shadows.
@@ -12,6 +14,7 @@ type Map struct {
 	DepthTexture uint32   // Depth texture for shadow sampling
 	Resolution   int32    // Shadow map resolution (width = height)
 	prevViewport [4]int32 // Saved viewport for restore
+	restoreState func()   // Restores the GL state Bind replaced
 }
 
 // DefaultResolution is the default shadow map resolution.
@@ -100,25 +103,22 @@ func (sm *Map) Bind() {
 	gl.Viewport(0, 0, sm.Resolution, sm.Resolution)
 	gl.Clear(gl.DEPTH_BUFFER_BIT)
 
-	// Enable depth testing
-	gl.Enable(gl.DEPTH_TEST)
-	gl.DepthFunc(gl.LESS)
-
-	// Enable front-face culling to reduce shadow acne
-	gl.Enable(gl.CULL_FACE)
-	gl.CullFace(gl.FRONT)
+	// Depth testing with front-face culling to reduce shadow acne
+	sm.restoreState = glstate.Push(glstate.ShadowDepth)
 }
 
 // Unbind unbinds the shadow map framebuffer.
-// Restores viewport and back-face culling.
+// Restores the viewport and the render state from before Bind.
 func (sm *Map) Unbind() {
 	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
 
 	// Restore previous viewport
 	gl.Viewport(sm.prevViewport[0], sm.prevViewport[1], sm.prevViewport[2], sm.prevViewport[3])
 
-	// Restore normal culling
-	gl.CullFace(gl.BACK)
+	if sm.restoreState != nil {
+		sm.restoreState()
+		sm.restoreState = nil
+	}
 }
 
 // BindTexture binds the shadow map depth texture to the specified texture unit.
@@ -2,14 +2,53 @@
 package sprite
 
 import (
+	"slices"
+	"time"
+
 	"github.com/Faultbox/midgard-ro/pkg/formats"
 )
 
+// ACT frame timing: intervals count ticks of actTick, and actions without
+// one play at defaultActInterval.
+const (
+	actTick            = 24 * time.Millisecond
+	defaultActInterval = 4
+)
+
 // CompositeResult holds the result of sprite compositing.
 type CompositeResult struct {
-	Pixels []byte // RGBA pixels
-	Width  int    // Image width
-	Height int    // Image height
+	Pixels  []byte // RGBA pixels
+	Width   int    // Image width
+	Height  int    // Image height
+	OriginX int    // Sprite origin (feet) within the image
+	OriginY int
+}
+
+// Layer is one sprite of a character's layer stack, e.g. a cart, the body
+// (or rider and mount, which share one body sprite) and the head.
+type Layer struct {
+	SPR *formats.SPR
+	ACT *formats.ACT
+
+	// Attach aligns the layer's anchor point with the anchor point of the
+	// last unattached layer before it, the way heads sit on bodies.
+	Attach bool
+
+	// FirstFrame always draws frame 0 of the action. Head frames past the
+	// first don't carry matching anchor points.
+	FirstFrame bool
+
+	// Garment marks an overlay, wings or a cape, animated by its own ACT:
+	// it draws Frame of the action (see FrameAt) rather than the body's
+	// frame. Garment ACTs leave frames empty where the overlay is hidden;
+	// those, and actions without frames, draw nothing rather than emptying
+	// the whole sprite.
+	Garment bool
+	Frame   int
+
+	// Behind draws an attached layer under the layer it attaches to, the
+	// way a garment hangs behind a body facing the camera.
+	Behind bool
 }
 
 // CompositeSprites creates a single RGBA image by compositing body and head sprites.
@@ -19,129 +58,89 @@ func CompositeSprites(
 	headSPR *formats.SPR, headACT *formats.ACT,
 	action, direction, frame int,
 ) CompositeResult {
-	// Get body action/frame
-	bodyActionIdx := action*8 + direction
-	if bodyActionIdx >= len(bodyACT.Actions) {
-		bodyActionIdx = direction % len(bodyACT.Actions)
-	}
-	bodyAction := &bodyACT.Actions[bodyActionIdx]
-	if len(bodyAction.Frames) == 0 {
-		return CompositeResult{}
-	}
-	bodyFrameIdx := frame % len(bodyAction.Frames)
-	bodyFrame := &bodyAction.Frames[bodyFrameIdx]
-
-	// Get head action/frame (always use frame 0 for stability)
-	headActionIdx := action*8 + direction
-	if headActionIdx >= len(headACT.Actions) {
-		headActionIdx = direction % len(headACT.Actions)
-	}
-	headAction := &headACT.Actions[headActionIdx]
-	if len(headAction.Frames) == 0 {
-		return CompositeResult{}
-	}
-	// Always use frame 0 for head - it has the matching anchor points
-	headFrame := &headAction.Frames[0]
+	return CompositeLayers([]Layer{
+		{SPR: bodySPR, ACT: bodyACT},
+		{SPR: headSPR, ACT: headACT, Attach: true, FirstFrame: true},
+	}, action, direction, frame)
+}
 
-	// Find body layer bounds
-	var bodyMinX, bodyMinY, bodyMaxX, bodyMaxY int
-	bodyMinX, bodyMinY = 10000, 10000
-	bodyMaxX, bodyMaxY = -10000, -10000
+// placedLayer is a layer's resolved frame and offset from the sprite origin.
+type placedLayer struct {
+	spr     *formats.SPR
+	frame   *formats.Frame
+	offsetX int
+	offsetY int
+}
 
-	for _, layer := range bodyFrame.Layers {
-		if layer.SpriteID < 0 || int(layer.SpriteID) >= len(bodySPR.Images) {
+// CompositeLayers creates a single RGBA image from a layer stack, drawn in
+// order (the first layer is at the bottom). Layers without SPR or ACT are
+// skipped; if a layer's action has no frames the result is empty, unless
+// it's a garment's.
+func CompositeLayers(layers []Layer, action, direction, frame int) CompositeResult {
+	placed := make([]placedLayer, 0, len(layers))
+	var anchorX, anchorY int // Anchor of the last unattached layer
+	base := 0                // Where in placed the last unattached layer is
+	for _, l := range layers {
+		if l.SPR == nil || l.ACT == nil || len(l.ACT.Actions) == 0 {
 			continue
 		}
-		img := &bodySPR.Images[layer.SpriteID]
-		x, y := int(layer.X), int(layer.Y)
-		w, h := int(img.Width), int(img.Height)
-
-		// Layer position is center of sprite
-		left := x - w/2
-		top := y - h/2
-		right := left + w
-		bottom := top + h
-
-		if left < bodyMinX {
-			bodyMinX = left
-		}
-		if top < bodyMinY {
-			bodyMinY = top
-		}
-		if right > bodyMaxX {
-			bodyMaxX = right
-		}
-		if bottom > bodyMaxY {
-			bodyMaxY = bottom
+		act := &l.ACT.Actions[actionIndex(l.ACT, action, direction)]
+		if len(act.Frames) == 0 {
+			if l.Garment {
+				continue
+			}
+			return CompositeResult{}
 		}
-	}
-
-	// Get body anchor point (where head attaches)
-	var bodyAnchorX, bodyAnchorY int
-	if len(bodyFrame.AnchorPoints) > 0 {
-		bodyAnchorX = int(bodyFrame.AnchorPoints[0].X)
-		bodyAnchorY = int(bodyFrame.AnchorPoints[0].Y)
-	}
-
-	// Get head anchor point
-	var headAnchorX, headAnchorY int
-	if len(headFrame.AnchorPoints) > 0 {
-		headAnchorX = int(headFrame.AnchorPoints[0].X)
-		headAnchorY = int(headFrame.AnchorPoints[0].Y)
-	}
-
-	// Calculate head offset: head anchor aligns with body anchor
-	headOffsetX := bodyAnchorX - headAnchorX
-	headOffsetY := bodyAnchorY - headAnchorY
-
-	// Find head layer bounds (relative to head origin + offset)
-	var headMinX, headMinY, headMaxX, headMaxY int
-	headMinX, headMinY = 10000, 10000
-	headMaxX, headMaxY = -10000, -10000
-
-	for _, layer := range headFrame.Layers {
-		if layer.SpriteID < 0 || int(layer.SpriteID) >= len(headSPR.Images) {
-			continue
+		var f *formats.Frame
+		switch {
+		case l.Garment:
+			f = &act.Frames[max(l.Frame, 0)%len(act.Frames)]
+			if EmptyFrame(f) {
+				continue
+			}
+		case l.FirstFrame:
+			f = &act.Frames[0]
+		default:
+			f = &act.Frames[frame%len(act.Frames)]
 		}
-		img := &headSPR.Images[layer.SpriteID]
-		x, y := int(layer.X)+headOffsetX, int(layer.Y)+headOffsetY
-		w, h := int(img.Width), int(img.Height)
 
-		left := x - w/2
-		top := y - h/2
-		right := left + w
-		bottom := top + h
-
-		if left < headMinX {
-			headMinX = left
-		}
-		if top < headMinY {
-			headMinY = top
+		var fx, fy int
+		if len(f.AnchorPoints) > 0 {
+			fx, fy = int(f.AnchorPoints[0].X), int(f.AnchorPoints[0].Y)
 		}
-		if right > headMaxX {
-			headMaxX = right
+		p := placedLayer{spr: l.SPR, frame: f}
+		if l.Attach {
+			p.offsetX, p.offsetY = anchorX-fx, anchorY-fy
+		} else {
+			anchorX, anchorY = fx, fy
+			base = len(placed)
 		}
-		if bottom > headMaxY {
-			headMaxY = bottom
+		if l.Attach && l.Behind {
+			placed = slices.Insert(placed, base, p)
+			base++
+			continue
 		}
+		placed = append(placed, p)
 	}
 
-	// Combine bounds
-	minX := bodyMinX
-	if headMinX < minX {
-		minX = headMinX
-	}
-	minY := bodyMinY
-	if headMinY < minY {
-		minY = headMinY
-	}
-	maxX := bodyMaxX
-	if headMaxX > maxX {
-		maxX = headMaxX
-	}
-	maxY := bodyMaxY
-	if headMaxY > maxY {
-		maxY = headMaxY
+	// Find the bounds of all layers around the sprite origin
+	minX, minY := 10000, 10000
+	maxX, maxY := -10000, -10000
+	for _, p := range placed {
+		for i := range p.frame.Layers {
+			layer := &p.frame.Layers[i]
+			img := layerImage(p.spr, layer)
+			if img == nil {
+				continue
+			}
+			w, h := int(img.Width), int(img.Height)
+			left := int(layer.X) + p.offsetX - w/2
+			top := int(layer.Y) + p.offsetY - h/2
+			minX = min(minX, left)
+			minY = min(minY, top)
+			maxX = max(maxX, left+w)
+			maxY = max(maxY, top+h)
+		}
 	}
 
 	// Handle empty sprites
@@ -156,100 +155,128 @@ func CompositeSprites(
 	originY := -minY
 	pixels := make([]byte, width*height*4)
 
-	// Helper to blit a sprite layer onto canvas
-	blitLayer := func(spr *formats.SPR, layer *formats.Layer, offsetX, offsetY int) {
-		if layer.SpriteID < 0 || int(layer.SpriteID) >= len(spr.Images) {
-			return
+	for _, p := range placed {
+		for i := range p.frame.Layers {
+			blitLayer(pixels, width, height, p.spr, &p.frame.Layers[i], p.offsetX+originX, p.offsetY+originY)
 		}
-		img := &spr.Images[layer.SpriteID]
-		imgW, imgH := int(img.Width), int(img.Height)
+	}
 
-		// SPR images are already converted to RGBA format
-		rgba := img.Pixels
-		if len(rgba) == 0 {
-			return
-		}
+	return CompositeResult{
+		Pixels:  pixels,
+		Width:   width,
+		Height:  height,
+		OriginX: originX,
+		OriginY: originY,
+	}
+}
+
+// blitLayer alpha-blends a sprite layer onto a width x height canvas, with
+// the layer's center shifted by offsetX, offsetY.
+func blitLayer(pixels []byte, width, height int, spr *formats.SPR, layer *formats.Layer, offsetX, offsetY int) {
+	img := layerImage(spr, layer)
+	if img == nil {
+		return
+	}
+	imgW, imgH := int(img.Width), int(img.Height)
+	rgba := img.Pixels
+
+	// Layer center position + offset
+	cx := int(layer.X) + offsetX
+	cy := int(layer.Y) + offsetY
+
+	// Check if layer should be mirrored (horizontal flip)
+	mirrored := layer.IsMirrored()
+
+	for py := 0; py < imgH; py++ {
+		for px := 0; px < imgW; px++ {
+			dx := cx + px - imgW/2
+			dy := cy + py - imgH/2
+			if dx < 0 || dx >= width || dy < 0 || dy >= height {
+				continue
+			}
 
-		// Layer center position + offset
-		cx := int(layer.X) + offsetX + originX
-		cy := int(layer.Y) + offsetY + originY
-
-		// Check if layer should be mirrored (horizontal flip)
-		mirrored := layer.IsMirrored()
-
-		// Blit with alpha blending
-		for py := 0; py < imgH; py++ {
-			for px := 0; px < imgW; px++ {
-				dx := cx + px - imgW/2
-				dy := cy + py - imgH/2
-				if dx < 0 || dx >= width || dy < 0 || dy >= height {
-					continue
-				}
-
-				// Source pixel - flip X if mirrored
-				srcX := px
-				if mirrored {
-					srcX = imgW - 1 - px
-				}
-				srcIdx := (py*imgW + srcX) * 4
-				dstIdx := (dy*width + dx) * 4
-
-				// Source pixel
-				sr, sg, sb, sa := rgba[srcIdx], rgba[srcIdx+1], rgba[srcIdx+2], rgba[srcIdx+3]
-				if sa == 0 {
-					continue // Fully transparent
-				}
-
-				// Alpha blend
-				if sa == 255 {
-					pixels[dstIdx] = sr
-					pixels[dstIdx+1] = sg
-					pixels[dstIdx+2] = sb
-					pixels[dstIdx+3] = sa
-				} else {
-					// Simple alpha blend
-					da := pixels[dstIdx+3]
-					outA := sa + da*(255-sa)/255
-					if outA > 0 {
-						pixels[dstIdx] = byte((int(sr)*int(sa) + int(pixels[dstIdx])*int(da)*(255-int(sa))/255) / int(outA))
-						pixels[dstIdx+1] = byte((int(sg)*int(sa) + int(pixels[dstIdx+1])*int(da)*(255-int(sa))/255) / int(outA))
-						pixels[dstIdx+2] = byte((int(sb)*int(sa) + int(pixels[dstIdx+2])*int(da)*(255-int(sa))/255) / int(outA))
-						pixels[dstIdx+3] = outA
-					}
-				}
+			// Source pixel - flip X if mirrored
+			srcX := px
+			if mirrored {
+				srcX = imgW - 1 - px
+			}
+			srcIdx := (py*imgW + srcX) * 4
+			dstIdx := (dy*width + dx) * 4
+
+			sr, sg, sb, sa := rgba[srcIdx], rgba[srcIdx+1], rgba[srcIdx+2], rgba[srcIdx+3]
+			if sa == 0 {
+				continue // Fully transparent
+			}
+
+			// Alpha blend
+			if sa == 255 {
+				pixels[dstIdx] = sr
+				pixels[dstIdx+1] = sg
+				pixels[dstIdx+2] = sb
+				pixels[dstIdx+3] = sa
+				continue
+			}
+			da := pixels[dstIdx+3]
+			outA := sa + da*(255-sa)/255
+			if outA > 0 {
+				pixels[dstIdx] = byte((int(sr)*int(sa) + int(pixels[dstIdx])*int(da)*(255-int(sa))/255) / int(outA))
+				pixels[dstIdx+1] = byte((int(sg)*int(sa) + int(pixels[dstIdx+1])*int(da)*(255-int(sa))/255) / int(outA))
+				pixels[dstIdx+2] = byte((int(sb)*int(sa) + int(pixels[dstIdx+2])*int(da)*(255-int(sa))/255) / int(outA))
+				pixels[dstIdx+3] = outA
 			}
 		}
 	}
+}
 
-	// Draw body layers first (bottom)
-	for _, layer := range bodyFrame.Layers {
-		if layer.SpriteID >= 0 {
-			blitLayer(bodySPR, &layer, 0, 0)
-		}
+// actionIndex returns the ACT action for an action/direction combo. Sprites
+// with fewer actions, e.g. single-action items, fall back to a direction of
+// their first actions.
+func actionIndex(act *formats.ACT, action, direction int) int {
+	idx := action*8 + direction
+	if idx >= len(act.Actions) {
+		idx = direction % len(act.Actions)
+	}
+	return idx
+}
+
+// FrameAt returns the frame of an action/direction combo showing elapsed
+// into it, played at the ACT's interval for the action and looping.
+func FrameAt(act *formats.ACT, action, direction int, elapsed time.Duration) int {
+	n := GetActionFrameCount(act, action, direction)
+	if n == 0 || elapsed <= 0 {
+		return 0
 	}
+	return int(elapsed/FrameInterval(act, action, direction)) % n
+}
 
-	// Draw head layers on top
-	for _, layer := range headFrame.Layers {
-		if layer.SpriteID >= 0 {
-			blitLayer(headSPR, &layer, headOffsetX, headOffsetY)
+// FrameInterval returns how long each frame of an action/direction combo
+// shows for, going by the ACT's interval for the action.
+func FrameInterval(act *formats.ACT, action, direction int) time.Duration {
+	interval := float32(defaultActInterval)
+	if len(act.Actions) > 0 {
+		idx := actionIndex(act, action, direction)
+		if idx < len(act.Intervals) && act.Intervals[idx] > 0 {
+			interval = act.Intervals[idx]
 		}
 	}
+	return time.Duration(interval * float32(actTick))
+}
 
-	return CompositeResult{
-		Pixels: pixels,
-		Width:  width,
-		Height: height,
+// EmptyFrame reports whether a frame draws no sprite, which garment and
+// accessory ACTs use for the frames their overlay is hidden in.
+func EmptyFrame(f *formats.Frame) bool {
+	for i := range f.Layers {
+		if f.Layers[i].SpriteID >= 0 {
+			return false
+		}
 	}
+	return true
 }
 
 // GetActionFrameCount returns the number of frames for an action/direction combo.
 func GetActionFrameCount(act *formats.ACT, action, direction int) int {
-	actionIdx := action*8 + direction
-	if actionIdx >= len(act.Actions) {
-		actionIdx = direction % len(act.Actions)
-	}
-	if actionIdx >= len(act.Actions) {
+	if len(act.Actions) == 0 {
 		return 0
 	}
-	return len(act.Actions[actionIdx].Frames)
+	return len(act.Actions[actionIndex(act, action, direction)].Frames)
 }
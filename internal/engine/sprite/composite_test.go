@@ -0,0 +1,97 @@
+package sprite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Faultbox/midgard-ro/pkg/formats"
+)
+
+// solidSPR returns a sprite of one opaque 2x2 image of a color.
+func solidSPR(r, g, b byte) *formats.SPR {
+	img := formats.SPRImage{Width: 2, Height: 2, Pixels: make([]byte, 2*2*4)}
+	for i := 0; i < len(img.Pixels); i += 4 {
+		img.Pixels[i], img.Pixels[i+1], img.Pixels[i+2], img.Pixels[i+3] = r, g, b, 255
+	}
+	return &formats.SPR{Images: []formats.SPRImage{img}, IndexedCount: 1}
+}
+
+// frameOf returns a frame drawing sprite image id at the origin, -1 for
+// none.
+func frameOf(id int32) formats.Frame {
+	return formats.Frame{
+		Layers:       []formats.Layer{{SpriteID: id, ScaleX: 1, ScaleY: 1}},
+		AnchorPoints: []formats.AnchorPoint{{}},
+	}
+}
+
+func TestCompositeGarment(t *testing.T) {
+	body := Layer{
+		SPR: solidSPR(255, 0, 0),
+		ACT: &formats.ACT{Actions: []formats.Action{{Frames: []formats.Frame{frameOf(0)}}}},
+	}
+	robe := Layer{
+		SPR:     solidSPR(0, 0, 255),
+		ACT:     &formats.ACT{Actions: []formats.Action{{Frames: []formats.Frame{frameOf(0), frameOf(-1)}}}},
+		Attach:  true,
+		Garment: true,
+	}
+	// The color at the sprite origin
+	top := func(layers ...Layer) (byte, bool) {
+		r := CompositeLayers(layers, 0, 0, 0)
+		if r.Width == 0 {
+			return 0, false
+		}
+		return r.Pixels[(r.OriginY*r.Width+r.OriginX)*4], true
+	}
+
+	if c, _ := top(body, robe); c != 0 {
+		t.Errorf("garment in front: red %d on top, want the garment", c)
+	}
+	behind := robe
+	behind.Behind = true
+	if c, _ := top(body, behind); c != 255 {
+		t.Errorf("garment behind: red %d on top, want the body", c)
+	}
+
+	// Empty garment frames and actions leave the body showing
+	hidden := robe
+	hidden.Frame = 1
+	if c, ok := top(body, hidden); !ok || c != 255 {
+		t.Errorf("empty garment frame: red %d, %v, want the body", c, ok)
+	}
+	none := robe
+	none.ACT = &formats.ACT{Actions: []formats.Action{{}}}
+	if c, ok := top(body, none); !ok || c != 255 {
+		t.Errorf("garment action without frames: red %d, %v, want the body", c, ok)
+	}
+}
+
+func TestFrameAt(t *testing.T) {
+	act := &formats.ACT{
+		Actions:   []formats.Action{{Frames: make([]formats.Frame, 3)}, {Frames: make([]formats.Frame, 2)}},
+		Intervals: []float32{2, 0},
+	}
+	tests := []struct {
+		action  int
+		elapsed time.Duration
+		want    int
+	}{
+		{0, 0, 0},
+		{0, 47 * time.Millisecond, 0},
+		{0, 48 * time.Millisecond, 1},
+		{0, 150 * time.Millisecond, 0}, // Looped
+		// No interval plays at the default
+		{1, 95 * time.Millisecond, 0},
+		{1, 96 * time.Millisecond, 1},
+	}
+	for _, tt := range tests {
+		// Single-direction actions: direction picks the action
+		if got := FrameAt(act, 0, tt.action, tt.elapsed); got != tt.want {
+			t.Errorf("FrameAt(action %d, %v) = %d, want %d", tt.action, tt.elapsed, got, tt.want)
+		}
+	}
+	if got := FrameInterval(act, 0, 0); got != 48*time.Millisecond {
+		t.Errorf("FrameInterval = %v, want 48ms", got)
+	}
+}
@@ -0,0 +1,172 @@
+package sprite
+
+import (
+	"math"
+
+	"github.com/Faultbox/midgard-ro/pkg/formats"
+)
+
+// CompositeFrame renders a single ACT frame of one sprite into an RGBA image.
+// Unlike CompositeSprites it has no head/body pairing, which makes it suitable
+// for standalone effect sprites (torches, flames, smoke). Layers are scaled,
+// mirrored and rotated about their centers as the ACT says, and their colors
+// are applied as a multiplicative tint.
+func CompositeFrame(spr *formats.SPR, act *formats.ACT, action, frame int) CompositeResult {
+	if spr == nil || act == nil || len(act.Actions) == 0 {
+		return CompositeResult{}
+	}
+	if action < 0 || action >= len(act.Actions) {
+		action = 0
+	}
+	frames := act.Actions[action].Frames
+	if len(frames) == 0 {
+		return CompositeResult{}
+	}
+	layers := frames[frame%len(frames)].Layers
+
+	// Find layer bounds relative to the sprite origin
+	placed := make([]layerPlacement, 0, len(layers))
+	minX, minY := 10000, 10000
+	maxX, maxY := -10000, -10000
+	for i := range layers {
+		img := layerImage(spr, &layers[i])
+		if img == nil {
+			continue
+		}
+		p, ok := placeLayer(&layers[i], img)
+		if !ok {
+			continue
+		}
+		placed = append(placed, p)
+		minX = min(minX, p.minX)
+		minY = min(minY, p.minY)
+		maxX = max(maxX, p.maxX)
+		maxY = max(maxY, p.maxY)
+	}
+	if minX >= maxX || minY >= maxY {
+		return CompositeResult{}
+	}
+
+	width := maxX - minX
+	height := maxY - minY
+	pixels := make([]byte, width*height*4)
+
+	for _, p := range placed {
+		img, color := p.img, p.layer.Color
+		imgW := int(img.Width)
+		for y := p.minY; y < p.maxY; y++ {
+			for x := p.minX; x < p.maxX; x++ {
+				srcX, srcY, ok := p.source(float64(x)+0.5, float64(y)+0.5)
+				if !ok {
+					continue
+				}
+				srcIdx := (srcY*imgW + srcX) * 4
+				sa := int(img.Pixels[srcIdx+3]) * int(color[3]) / 255
+				if sa == 0 {
+					continue
+				}
+				dstIdx := ((y-minY)*width + x - minX) * 4
+				da := int(pixels[dstIdx+3])
+				outA := sa + da*(255-sa)/255
+				for c := 0; c < 3; c++ {
+					src := int(img.Pixels[srcIdx+c]) * int(color[c]) / 255
+					pixels[dstIdx+c] = byte((src*sa + int(pixels[dstIdx+c])*da*(255-sa)/255) / outA)
+				}
+				pixels[dstIdx+3] = byte(outA)
+			}
+		}
+	}
+
+	return CompositeResult{
+		Pixels:  pixels,
+		Width:   width,
+		Height:  height,
+		OriginX: -minX,
+		OriginY: -minY,
+	}
+}
+
+// layerImage returns the SPR image referenced by a layer, or nil if the layer
+// is empty or out of range. RGBA layers index past the palette images.
+func layerImage(spr *formats.SPR, layer *formats.Layer) *formats.SPRImage {
+	idx := int(layer.SpriteID)
+	if idx < 0 {
+		return nil
+	}
+	if layer.SpriteType == 1 {
+		idx += spr.IndexedCount
+	}
+	if idx >= len(spr.Images) {
+		return nil
+	}
+	img := &spr.Images[idx]
+	if len(img.Pixels) < int(img.Width)*int(img.Height)*4 {
+		return nil
+	}
+	return img
+}
+
+// layerPlacement is where a layer's image lands relative to the sprite
+// origin: its bounds, and what maps a point back into the image.
+type layerPlacement struct {
+	layer                  *formats.Layer
+	img                    *formats.SPRImage
+	minX, minY, maxX, maxY int     // Bounds, in sprite pixels
+	cx, cy                 float64 // Image center
+	sx, sy                 float64 // Scale, sx negative when mirrored
+	sin, cos               float64 // Clockwise rotation
+}
+
+// placeLayer places a layer's image: scaled, then mirrored, then rotated
+// clockwise about its center. Returns false for a layer scaled to nothing.
+func placeLayer(layer *formats.Layer, img *formats.SPRImage) (layerPlacement, bool) {
+	w, h := int(img.Width), int(img.Height)
+	p := layerPlacement{
+		layer: layer,
+		img:   img,
+		// Unscaled images keep their odd pixel right of and below the center
+		cx: float64(int(layer.X)-w/2) + float64(w)/2,
+		cy: float64(int(layer.Y)-h/2) + float64(h)/2,
+		sx: float64(layer.ScaleX),
+		sy: float64(layer.ScaleY),
+	}
+	if math.Abs(p.sx*float64(w)) < 0.5 || math.Abs(p.sy*float64(h)) < 0.5 {
+		return layerPlacement{}, false
+	}
+	if layer.IsMirrored() {
+		p.sx = -p.sx
+	}
+	p.sin, p.cos = math.Sincos(float64(layer.Rotation) * math.Pi / 180)
+
+	// Bounds of the transformed corners; the slack keeps float error from
+	// adding a row
+	const slack = 1e-6
+	hw, hh := float64(w)/2*p.sx, float64(h)/2*p.sy
+	x0, y0 := math.Inf(1), math.Inf(1)
+	x1, y1 := math.Inf(-1), math.Inf(-1)
+	for _, c := range [4][2]float64{{-hw, -hh}, {hw, -hh}, {-hw, hh}, {hw, hh}} {
+		x := p.cx + c[0]*p.cos - c[1]*p.sin
+		y := p.cy + c[0]*p.sin + c[1]*p.cos
+		x0, y0 = min(x0, x), min(y0, y)
+		x1, y1 = max(x1, x), max(y1, y)
+	}
+	p.minX, p.minY = int(math.Floor(x0+slack)), int(math.Floor(y0+slack))
+	p.maxX, p.maxY = int(math.Ceil(x1-slack)), int(math.Ceil(y1-slack))
+	return p, true
+}
+
+// source returns the image pixel drawn at a point relative to the sprite
+// origin, or false if the layer doesn't cover it.
+func (p *layerPlacement) source(x, y float64) (int, int, bool) {
+	dx, dy := x-p.cx, y-p.cy
+	u := (dx*p.cos+dy*p.sin)/p.sx + float64(p.img.Width)/2
+	v := (dy*p.cos-dx*p.sin)/p.sy + float64(p.img.Height)/2
+	if u < 0 || v < 0 {
+		return 0, 0, false
+	}
+	srcX, srcY := int(u), int(v)
+	if srcX >= int(p.img.Width) || srcY >= int(p.img.Height) {
+		return 0, 0, false
+	}
+	return srcX, srcY, true
+}
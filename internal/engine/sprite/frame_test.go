@@ -0,0 +1,101 @@
+package sprite
+
+import (
+	"testing"
+
+	"github.com/Faultbox/midgard-ro/pkg/formats"
+)
+
+// stripSPR returns a sprite of one opaque image one pixel high, a pixel of
+// each red value given.
+func stripSPR(reds ...byte) *formats.SPR {
+	img := formats.SPRImage{Width: uint16(len(reds)), Height: 1, Pixels: make([]byte, len(reds)*4)}
+	for i, r := range reds {
+		img.Pixels[i*4], img.Pixels[i*4+3] = r, 255
+	}
+	return &formats.SPR{Images: []formats.SPRImage{img}, IndexedCount: 1}
+}
+
+// compositeLayer composites a frame of a single layer of a sprite.
+func compositeLayer(spr *formats.SPR, layer formats.Layer) CompositeResult {
+	layer.Color = [4]uint8{255, 255, 255, 255}
+	act := &formats.ACT{Actions: []formats.Action{{Frames: []formats.Frame{{Layers: []formats.Layer{layer}}}}}}
+	return CompositeFrame(spr, act, 0, 0)
+}
+
+// reds returns the red of each pixel of a result, row by row.
+func reds(r CompositeResult) []byte {
+	out := make([]byte, 0, r.Width*r.Height)
+	for i := 0; i < len(r.Pixels); i += 4 {
+		out = append(out, r.Pixels[i])
+	}
+	return out
+}
+
+func TestCompositeFrameTransform(t *testing.T) {
+	tests := []struct {
+		name             string
+		spr              *formats.SPR
+		layer            formats.Layer
+		width, height    int
+		originX, originY int
+		want             []byte
+	}{
+		{
+			name:  "unscaled",
+			spr:   stripSPR(10, 20, 30),
+			layer: formats.Layer{X: 5, ScaleX: 1, ScaleY: 1},
+			width: 3, height: 1, originX: -4, originY: 0,
+			want: []byte{10, 20, 30},
+		},
+		{
+			name:  "scaled",
+			spr:   stripSPR(10, 20),
+			layer: formats.Layer{ScaleX: 2, ScaleY: 3},
+			width: 4, height: 3, originX: 2, originY: 1,
+			want: []byte{10, 10, 20, 20, 10, 10, 20, 20, 10, 10, 20, 20},
+		},
+		{
+			name:  "mirrored",
+			spr:   stripSPR(10, 20, 30),
+			layer: formats.Layer{Flags: 1, ScaleX: 1, ScaleY: 1},
+			width: 3, height: 1, originX: 1, originY: 0,
+			want: []byte{30, 20, 10},
+		},
+		{
+			name:  "rotated clockwise",
+			spr:   stripSPR(10, 20, 30),
+			layer: formats.Layer{ScaleX: 1, ScaleY: 1, Rotation: 90},
+			width: 1, height: 3, originX: 0, originY: 1,
+			want: []byte{10, 20, 30},
+		},
+		{
+			name:  "rotated half a turn",
+			spr:   stripSPR(10, 20, 30),
+			layer: formats.Layer{ScaleX: 1, ScaleY: 1, Rotation: 180},
+			width: 3, height: 1, originX: 1, originY: 0,
+			want: []byte{30, 20, 10},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := compositeLayer(tt.spr, tt.layer)
+			if r.Width != tt.width || r.Height != tt.height {
+				t.Fatalf("size %dx%d, want %dx%d", r.Width, r.Height, tt.width, tt.height)
+			}
+			if r.OriginX != tt.originX || r.OriginY != tt.originY {
+				t.Errorf("origin %d,%d, want %d,%d", r.OriginX, r.OriginY, tt.originX, tt.originY)
+			}
+			if got := reds(r); string(got) != string(tt.want) {
+				t.Errorf("pixels %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompositeFrameScaledAway(t *testing.T) {
+	r := compositeLayer(stripSPR(10, 20), formats.Layer{ScaleX: 0, ScaleY: 1})
+	if r.Width != 0 || r.Height != 0 {
+		t.Errorf("layer scaled to nothing composited to %dx%d", r.Width, r.Height)
+	}
+}
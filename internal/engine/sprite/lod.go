@@ -0,0 +1,93 @@
+package sprite
+
+import (
+	"time"
+
+	"github.com/Faultbox/midgard-ro/pkg/math"
+)
+
+// AnimLevel is how closely an actor's sprite animation is kept up.
+type AnimLevel uint8
+
+const (
+	AnimFull    AnimLevel = iota // Every frame change composited
+	AnimReduced                  // Frames advance at AnimLOD.ReducedInterval
+	AnimFrozen                   // Off screen: the last composite is kept
+)
+
+// AnimLOD decides how much compositing work an actor's animation gets. Off
+// screen actors keep their state up to date but generate no frames, actors
+// beyond NearDist animate at a reduced rate, and an actor coming back on
+// screen is composited again at once.
+//
+// The zero value animates every actor on screen at full rate.
+type AnimLOD struct {
+	NearDist        float32       // World units; actors within animate at full rate, 0 for all
+	ReducedInterval time.Duration // Time between frames beyond NearDist
+}
+
+// DefaultAnimLOD returns the animation LOD the game starts with: full rate
+// within 10 cells of the player, 5 frames a second beyond.
+func DefaultAnimLOD() AnimLOD {
+	const cellSize = 5.0
+	return AnimLOD{
+		NearDist:        10 * cellSize,
+		ReducedInterval: 200 * time.Millisecond,
+	}
+}
+
+// Level returns the animation level of an actor dist (world units) from
+// the player.
+func (l AnimLOD) Level(onScreen bool, dist float32) AnimLevel {
+	switch {
+	case !onScreen:
+		return AnimFrozen
+	case l.NearDist > 0 && dist > l.NearDist && l.ReducedInterval > 0:
+		return AnimReduced
+	}
+	return AnimFull
+}
+
+// Due reports whether an actor at a level, whose frame last advanced at
+// last, advances to its current frame now.
+func (l AnimLOD) Due(level AnimLevel, last, now time.Time) bool {
+	switch level {
+	case AnimReduced:
+		return now.Sub(last) >= l.ReducedInterval
+	case AnimFrozen:
+		return false
+	}
+	return true
+}
+
+// AnimStats counts the actors at each animation level, and the composites
+// generated, over a frame.
+type AnimStats struct {
+	Full, Reduced, Frozen int
+	Composited            int
+}
+
+// Count counts an actor at a level.
+func (s *AnimStats) Count(level AnimLevel) {
+	switch level {
+	case AnimFull:
+		s.Full++
+	case AnimReduced:
+		s.Reduced++
+	case AnimFrozen:
+		s.Frozen++
+	}
+}
+
+// OnScreen reports whether the point p projects within margin (in
+// normalized device units, where the screen spans -1 to 1) of the screen,
+// margin making room for the sprite drawn above its feet.
+func OnScreen(viewProj math.Mat4, p [3]float32, margin float32) bool {
+	clip := viewProj.MulVec4(math.Vec4{p[0], p[1], p[2], 1})
+	if clip[3] <= 0 {
+		return false
+	}
+	x, y := clip[0]/clip[3], clip[1]/clip[3]
+	limit := 1 + margin
+	return x >= -limit && x <= limit && y >= -limit && y <= limit
+}
@@ -0,0 +1,73 @@
+package sprite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Faultbox/midgard-ro/pkg/math"
+)
+
+func TestAnimLODLevel(t *testing.T) {
+	lod := AnimLOD{NearDist: 50, ReducedInterval: 200 * time.Millisecond}
+	tests := []struct {
+		name     string
+		onScreen bool
+		dist     float32
+		want     AnimLevel
+	}{
+		{"near", true, 10, AnimFull},
+		{"far", true, 80, AnimReduced},
+		{"off screen", false, 10, AnimFrozen},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lod.Level(tt.onScreen, tt.dist); got != tt.want {
+				t.Errorf("Level = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if got := (AnimLOD{}).Level(true, 1000); got != AnimFull {
+		t.Errorf("zero LOD level = %v, want full", got)
+	}
+}
+
+func TestAnimLODDue(t *testing.T) {
+	lod := AnimLOD{NearDist: 50, ReducedInterval: 200 * time.Millisecond}
+	last := time.Unix(1000, 0)
+
+	if !lod.Due(AnimFull, last, last) {
+		t.Error("full rate not due")
+	}
+	if lod.Due(AnimReduced, last, last.Add(100*time.Millisecond)) {
+		t.Error("reduced rate due before its interval")
+	}
+	if !lod.Due(AnimReduced, last, last.Add(200*time.Millisecond)) {
+		t.Error("reduced rate not due after its interval")
+	}
+	if lod.Due(AnimFrozen, last, last.Add(time.Hour)) {
+		t.Error("frozen due")
+	}
+}
+
+func TestOnScreen(t *testing.T) {
+	vp := math.Identity()
+	tests := []struct {
+		p    [3]float32
+		want bool
+	}{
+		{[3]float32{0, 0, 0}, true},
+		{[3]float32{1.1, 0, 0}, true}, // Within the margin
+		{[3]float32{1.5, 0, 0}, false},
+		{[3]float32{0, -2, 0}, false},
+	}
+	for _, tt := range tests {
+		if got := OnScreen(vp, tt.p, 0.2); got != tt.want {
+			t.Errorf("OnScreen(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+
+	behind := math.Mat4{}
+	if OnScreen(behind, [3]float32{}, 0.2) {
+		t.Error("point with w = 0 on screen")
+	}
+}
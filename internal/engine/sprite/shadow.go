@@ -98,19 +98,6 @@ func GenerateCircularShadow(size int, maxOpacity float32) []byte {
 	return pixels
 }
 
-// GenerateShadowQuadVertices creates vertex data for a shadow quad on the XZ plane.
-// Returns vertices in format: [x, z, u, v] for each of 4 corners.
-// shadowSize is half the width/height in world units.
-func GenerateShadowQuadVertices(shadowSize float32) []float32 {
-	return []float32{
-		// Position (x, z)  TexCoord (u, v)
-		-shadowSize, -shadowSize, 0.0, 0.0,
-		shadowSize, -shadowSize, 1.0, 0.0,
-		-shadowSize, shadowSize, 0.0, 1.0,
-		shadowSize, shadowSize, 1.0, 1.0,
-	}
-}
-
 // DefaultShadowSize is the default shadow texture size in pixels.
 const DefaultShadowSize = 24
 
@@ -147,6 +147,11 @@ func BuildMesh(gnd *formats.GND, atlas *LightmapAtlas) *Mesh {
 			IndexCount: int32(len(texIndices)),
 		})
 		indices = append(indices, texIndices...)
+
+		// Walls and tops never share vertices across textures
+		for _, idx := range texIndices {
+			vertices[idx].TexLayer = float32(texID)
+		}
 	}
 
 	// Smooth normals to eliminate hard edges between tiles
@@ -0,0 +1,118 @@
+package terrain
+
+import (
+	"image"
+)
+
+// MaxTextureLayerSize caps the layer size of a ground texture array. RO ground
+// textures are 256x256 or 512x512; larger ones are downsampled.
+const MaxTextureLayerSize = 512
+
+// minTextureLayerSize is the layer size used when no texture could be loaded.
+const minTextureLayerSize = 8
+
+// TextureArray holds ground textures resampled to one size, ready for upload
+// as a 2D texture array so the terrain draws with a single texture bind.
+// Layer i is GND texture i; Vertex.TexLayer selects it.
+type TextureArray struct {
+	Data   []byte // RGBA pixel data, layer after layer
+	Size   int    // Layer width and height in pixels (power of two)
+	Layers int
+}
+
+// BuildTextureArray resamples textures into a texture array. The layer size
+// is the smallest power of two that fits the largest texture, capped at
+// maxSize. Nil entries (textures that failed to load) become opaque white
+// layers, matching the fallback texture of the per-texture path.
+func BuildTextureArray(textures []*image.RGBA, maxSize int) *TextureArray {
+	largest := 0
+	for _, img := range textures {
+		if img == nil {
+			continue
+		}
+		b := img.Bounds()
+		largest = max(largest, b.Dx(), b.Dy())
+	}
+
+	size := minTextureLayerSize
+	for size < largest && size < maxSize {
+		size *= 2
+	}
+
+	layerBytes := size * size * 4
+	arr := &TextureArray{
+		Data:   make([]byte, layerBytes*len(textures)),
+		Size:   size,
+		Layers: len(textures),
+	}
+	for i, img := range textures {
+		layer := arr.Data[i*layerBytes : (i+1)*layerBytes]
+		if img == nil || img.Bounds().Empty() {
+			for j := range layer {
+				layer[j] = 255
+			}
+			continue
+		}
+		resampleRGBA(layer, size, img)
+	}
+	return arr
+}
+
+// resampleRGBA bilinearly resamples src into the size x size dst, wrapping
+// at the edges since ground textures tile. Halving averages 2x2 blocks.
+func resampleRGBA(dst []byte, size int, src *image.RGBA) {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	pix := src.Pix[src.PixOffset(b.Min.X, b.Min.Y):]
+	if sw == size && sh == size {
+		for y := range size {
+			copy(dst[y*size*4:], pix[y*src.Stride:y*src.Stride+size*4])
+		}
+		return
+	}
+
+	scaleX := float32(sw) / float32(size)
+	scaleY := float32(sh) / float32(size)
+	for y := range size {
+		fy := (float32(y)+0.5)*scaleY - 0.5
+		y0 := floorInt(fy)
+		ty := fy - float32(y0)
+		y1 := wrapIndex(y0+1, sh)
+		y0 = wrapIndex(y0, sh)
+
+		for x := range size {
+			fx := (float32(x)+0.5)*scaleX - 0.5
+			x0 := floorInt(fx)
+			tx := fx - float32(x0)
+			x1 := wrapIndex(x0+1, sw)
+			x0 = wrapIndex(x0, sw)
+
+			p00 := pix[y0*src.Stride+x0*4:]
+			p10 := pix[y0*src.Stride+x1*4:]
+			p01 := pix[y1*src.Stride+x0*4:]
+			p11 := pix[y1*src.Stride+x1*4:]
+			out := dst[(y*size+x)*4:]
+			for c := range 4 {
+				top := float32(p00[c])*(1-tx) + float32(p10[c])*tx
+				bottom := float32(p01[c])*(1-tx) + float32(p11[c])*tx
+				out[c] = uint8(top*(1-ty) + bottom*ty + 0.5)
+			}
+		}
+	}
+}
+
+func floorInt(f float32) int {
+	i := int(f)
+	if f < 0 && float32(i) != f {
+		i--
+	}
+	return i
+}
+
+func wrapIndex(i, n int) int {
+	i %= n
+	if i < 0 {
+		i += n
+	}
+	return i
+}
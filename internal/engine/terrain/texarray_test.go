@@ -0,0 +1,81 @@
+package terrain
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func solidRGBA(w, h int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestBuildTextureArray(t *testing.T) {
+	red := color.RGBA{255, 0, 0, 255}
+	blue := color.RGBA{0, 0, 255, 128}
+
+	tests := []struct {
+		name     string
+		textures []*image.RGBA
+		maxSize  int
+		wantSize int
+	}{
+		{"mixed sizes use the largest", []*image.RGBA{solidRGBA(256, 256, red), solidRGBA(512, 512, blue)}, 512, 512},
+		{"non power of two rounds up", []*image.RGBA{solidRGBA(200, 100, red)}, 512, 256},
+		{"capped at max size", []*image.RGBA{solidRGBA(1024, 1024, red)}, 512, 512},
+		{"all missing", []*image.RGBA{nil, nil}, 512, minTextureLayerSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			arr := BuildTextureArray(tt.textures, tt.maxSize)
+			if arr.Size != tt.wantSize {
+				t.Errorf("Size = %d, want %d", arr.Size, tt.wantSize)
+			}
+			if arr.Layers != len(tt.textures) {
+				t.Errorf("Layers = %d, want %d", arr.Layers, len(tt.textures))
+			}
+			if want := arr.Size * arr.Size * 4 * arr.Layers; len(arr.Data) != want {
+				t.Errorf("len(Data) = %d, want %d", len(arr.Data), want)
+			}
+		})
+	}
+}
+
+func TestBuildTextureArrayLayerContent(t *testing.T) {
+	red := color.RGBA{255, 0, 0, 255}
+	blue := color.RGBA{0, 0, 255, 128}
+	arr := BuildTextureArray([]*image.RGBA{solidRGBA(4, 4, red), nil, solidRGBA(16, 16, blue)}, 512)
+
+	layerBytes := arr.Size * arr.Size * 4
+	for layer, want := range []color.RGBA{red, {255, 255, 255, 255}, blue} {
+		data := arr.Data[layer*layerBytes : (layer+1)*layerBytes]
+		for i := 0; i < len(data); i += 4 {
+			got := color.RGBA{data[i], data[i+1], data[i+2], data[i+3]}
+			if got != want {
+				t.Fatalf("layer %d pixel %d = %v, want %v", layer, i/4, got, want)
+			}
+		}
+	}
+}
+
+func TestResampleHalvesByAveraging(t *testing.T) {
+	// 2x2 checker of black and white halves to mid grey
+	src := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	src.SetRGBA(0, 0, color.RGBA{255, 255, 255, 255})
+	src.SetRGBA(1, 1, color.RGBA{255, 255, 255, 255})
+	src.SetRGBA(1, 0, color.RGBA{0, 0, 0, 255})
+	src.SetRGBA(0, 1, color.RGBA{0, 0, 0, 255})
+
+	dst := make([]byte, 4)
+	resampleRGBA(dst, 1, src)
+	if dst[0] < 127 || dst[0] > 128 || dst[3] != 255 {
+		t.Errorf("resampled pixel = %v, want mid grey", dst)
+	}
+}
@@ -8,6 +8,7 @@ type Vertex struct {
 	TexCoord   [2]float32
 	LightmapUV [2]float32
 	Color      [4]float32
+	TexLayer   float32 // Ground texture index, the layer in a TextureArray
 }
 
 // TextureGroup groups triangles by texture for batched rendering.
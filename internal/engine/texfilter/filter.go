@@ -0,0 +1,102 @@
+// Package texfilter is the sampling policy of world textures (the ground,
+// models and water): whether they're mipmapped, whether mip levels blend,
+// and the anisotropy that keeps the ground sharp at grazing angles instead
+// of shimmering.
+//
+// The policy is global, like the GL context it configures. Renderers apply
+// it to the textures they create and again to the ones they keep when it
+// changes. It is held in an unlocked variable, so Set must not race with
+// Current or with Apply and ApplyTo, which issue GL calls and run wherever
+// the textures are created.
+package texfilter
+
+import "fmt"
+
+// Filter is a texture sampling policy.
+type Filter struct {
+	Mipmaps    bool // Sample smaller mip levels for distant texels
+	Trilinear  bool // Blend between mip levels rather than pick the nearest
+	Anisotropy int  // Anisotropic filtering level, 1 for none
+}
+
+// Default is trilinear filtering with 8x anisotropy.
+var Default = Filter{Mipmaps: true, Trilinear: true, Anisotropy: 8}
+
+// MaxAnisotropy is the highest anisotropy level offered. The GPU clamps it
+// further to its own limit.
+const MaxAnisotropy = 16
+
+// MaxMipLevel is the smallest mip level generated mipmaps are sampled
+// down to: level 4 of a 256x256 texture is 16x16, below which texels
+// bleed across the whole texture.
+const MaxMipLevel = 4
+
+// Filter modes, as configured.
+const (
+	ModeNone      = "none" // No mipmaps
+	ModeBilinear  = "bilinear"
+	ModeTrilinear = "trilinear"
+)
+
+// Parse builds a filter from its configured mode and anisotropy level. An
+// empty mode is trilinear and a zero level Default's.
+func Parse(mode string, anisotropy int) (Filter, error) {
+	f := Default
+	switch mode {
+	case ModeTrilinear, "":
+	case ModeBilinear:
+		f.Trilinear = false
+	case ModeNone:
+		f.Mipmaps, f.Trilinear = false, false
+	default:
+		return Default, fmt.Errorf("unknown texture filter %q", mode)
+	}
+	if anisotropy != 0 {
+		f.Anisotropy = anisotropy
+	}
+	return f.Normalized(), nil
+}
+
+// Mode returns the filter's mode as Parse takes it.
+func (f Filter) Mode() string {
+	switch {
+	case !f.Mipmaps:
+		return ModeNone
+	case !f.Trilinear:
+		return ModeBilinear
+	}
+	return ModeTrilinear
+}
+
+// Normalized returns f with its anisotropy rounded down to a power of two
+// between 1 and MaxAnisotropy.
+func (f Filter) Normalized() Filter {
+	level := 1
+	for level*2 <= min(f.Anisotropy, MaxAnisotropy) {
+		level *= 2
+	}
+	f.Anisotropy = level
+	if !f.Mipmaps {
+		f.Trilinear = false
+	}
+	return f
+}
+
+// current is the policy textures are created with.
+var current = Default
+
+// Current returns the policy in effect.
+func Current() Filter {
+	return current
+}
+
+// Set makes f the policy in effect, reporting whether it changed so the
+// caller re-applies it to the textures it keeps.
+func Set(f Filter) bool {
+	f = f.Normalized()
+	if f == current {
+		return false
+	}
+	current = f
+	return true
+}
@@ -0,0 +1,52 @@
+package texfilter
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		mode       string
+		anisotropy int
+		want       Filter
+		wantErr    bool
+	}{
+		{"", 0, Default, false},
+		{"trilinear", 16, Filter{Mipmaps: true, Trilinear: true, Anisotropy: 16}, false},
+		{"bilinear", 4, Filter{Mipmaps: true, Anisotropy: 4}, false},
+		{"none", 1, Filter{Anisotropy: 1}, false},
+		{"trilinear", 6, Filter{Mipmaps: true, Trilinear: true, Anisotropy: 4}, false},
+		{"trilinear", 64, Filter{Mipmaps: true, Trilinear: true, Anisotropy: 16}, false},
+		{"trilinear", -2, Filter{Mipmaps: true, Trilinear: true, Anisotropy: 1}, false},
+		{"anisotropic", 8, Default, true},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.mode, tt.anisotropy)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Parse(%q, %d) = %+v, %v, want %+v (error %v)", tt.mode, tt.anisotropy, got, err, tt.want, tt.wantErr)
+		}
+		if err == nil && tt.mode != "" && got.Mode() != tt.mode {
+			t.Errorf("Parse(%q, %d).Mode() = %q", tt.mode, tt.anisotropy, got.Mode())
+		}
+	}
+}
+
+func TestSet(t *testing.T) {
+	defer Set(Default)
+
+	if Set(Default) {
+		t.Error("Set(Default) reported a change from the default")
+	}
+	if !Set(Filter{Mipmaps: true, Anisotropy: 3}) {
+		t.Error("Set didn't report a change")
+	}
+	if got, want := Current(), (Filter{Mipmaps: true, Anisotropy: 2}); got != want {
+		t.Errorf("Current() = %+v, want %+v", got, want)
+	}
+	if Set(Filter{Mipmaps: true, Anisotropy: 2}) {
+		t.Error("Set of the same policy reported a change")
+	}
+	// Without mipmaps there are no levels to blend
+	Set(Filter{Trilinear: true, Anisotropy: 1})
+	if Current().Trilinear {
+		t.Error("Set kept trilinear filtering without mipmaps")
+	}
+}
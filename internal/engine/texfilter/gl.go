@@ -0,0 +1,48 @@
+package texfilter
+
+import "github.com/go-gl/gl/v4.1-core/gl"
+
+// gpuMaxAnisotropy is the GPU's anisotropy limit, queried once.
+var gpuMaxAnisotropy float32
+
+// minFilter returns the GL minification filter of f.
+func (f Filter) minFilter() int32 {
+	switch {
+	case !f.Mipmaps:
+		return gl.LINEAR
+	case !f.Trilinear:
+		return gl.LINEAR_MIPMAP_NEAREST
+	}
+	return gl.LINEAR_MIPMAP_LINEAR
+}
+
+// Apply sets the current policy's sampling on the texture bound to target,
+// TEXTURE_2D or TEXTURE_2D_ARRAY.
+func Apply(target uint32) {
+	if gpuMaxAnisotropy == 0 {
+		gl.GetFloatv(gl.MAX_TEXTURE_MAX_ANISOTROPY, &gpuMaxAnisotropy)
+		gpuMaxAnisotropy = max(gpuMaxAnisotropy, 1)
+	}
+	gl.TexParameteri(target, gl.TEXTURE_MIN_FILTER, current.minFilter())
+	gl.TexParameteri(target, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	gl.TexParameterf(target, gl.TEXTURE_MAX_ANISOTROPY, min(float32(current.Anisotropy), gpuMaxAnisotropy))
+}
+
+// ApplyTo binds tex to target and applies the current policy to it, for
+// textures kept across a policy change.
+func ApplyTo(target, tex uint32) {
+	if tex == 0 {
+		return
+	}
+	gl.BindTexture(target, tex)
+	Apply(target)
+	gl.BindTexture(target, 0)
+}
+
+// GenerateMipmaps builds the mip chain of the texture bound to target,
+// sampled down to MaxMipLevel. Chains are built whatever the policy, so
+// turning mipmaps on later needs no re-upload.
+func GenerateMipmaps(target uint32) {
+	gl.GenerateMipmap(target)
+	gl.TexParameteri(target, gl.TEXTURE_MAX_LEVEL, MaxMipLevel)
+}
@@ -0,0 +1,281 @@
+package texture
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+
+	_ "golang.org/x/image/bmp" // BMP decoder registration
+)
+
+// The transparency policy decides which pixels of a texture are keyed out
+// by the kind of resource it is, rather than by each loader. Like
+// texfilter's sampling policy it's global and is to be configured before
+// textures load.
+
+// Key is the set of rules finding a texture's transparent pixels.
+type Key uint8
+
+const (
+	KeyMagenta     Key = 1 << iota // (255, 0, 255) pixels, see IsMagentaKey
+	KeyPaletteZero                 // Palette index 0 of paletted images (8-bit BMPs)
+
+	KeyNone Key = 0 // Alpha as stored
+)
+
+// Key names, as configured.
+const (
+	keyNameNone        = "none"
+	keyNameMagenta     = "magenta"
+	keyNamePaletteZero = "palette0"
+)
+
+// ParseKey parses a configured key: "none", "magenta", "palette0" or both
+// of the last joined by "+".
+func ParseKey(s string) (Key, error) {
+	if s == keyNameNone {
+		return KeyNone, nil
+	}
+	var k Key
+	for name := range strings.SplitSeq(s, "+") {
+		switch name {
+		case keyNameMagenta:
+			k |= KeyMagenta
+		case keyNamePaletteZero:
+			k |= KeyPaletteZero
+		default:
+			return KeyNone, fmt.Errorf("unknown transparency key %q", s)
+		}
+	}
+	return k, nil
+}
+
+// String returns the key as ParseKey takes it.
+func (k Key) String() string {
+	var names []string
+	if k&KeyMagenta != 0 {
+		names = append(names, keyNameMagenta)
+	}
+	if k&KeyPaletteZero != 0 {
+		names = append(names, keyNamePaletteZero)
+	}
+	if len(names) == 0 {
+		return keyNameNone
+	}
+	return strings.Join(names, "+")
+}
+
+// Category is the kind of resource a texture belongs to.
+type Category uint8
+
+const (
+	CategoryModel   Category = iota // RSM model textures
+	CategoryTerrain                 // GND ground textures
+	CategoryWater                   // Water animation frames
+	CategoryUI                      // Interface images
+	categoryCount
+)
+
+var categoryNames = [categoryCount]string{"model", "terrain", "water", "ui"}
+
+// String returns the category's name, as configured.
+func (c Category) String() string {
+	if c < categoryCount {
+		return categoryNames[c]
+	}
+	return "unknown"
+}
+
+// defaultKeys are the keys of each category: magenta marks holes in
+// models, ground decals such as Yuno's railings and interface images,
+// while water is opaque and keying it would punch holes in the sea.
+var defaultKeys = [categoryCount]Key{
+	CategoryModel:   KeyMagenta,
+	CategoryTerrain: KeyMagenta,
+	CategoryWater:   KeyNone,
+	CategoryUI:      KeyMagenta,
+}
+
+var (
+	categoryKeys = defaultKeys
+	overrideKeys = map[string]Key{} // By normalized path
+)
+
+// normalizePath lowercases a GRF path and turns its backslashes to
+// slashes, so overrides match however the path is spelled.
+func normalizePath(path string) string {
+	return strings.ToLower(strings.ReplaceAll(path, "\\", "/"))
+}
+
+// SetCategoryKey sets the key of a category's textures.
+func SetCategoryKey(c Category, k Key) {
+	if c < categoryCount {
+		categoryKeys[c] = k
+	}
+}
+
+// SetOverride sets the key of the texture at path, over its category's.
+func SetOverride(path string, k Key) {
+	overrideKeys[normalizePath(path)] = k
+}
+
+// ResetPolicy restores the default keys and drops the overrides.
+func ResetPolicy() {
+	categoryKeys = defaultKeys
+	clear(overrideKeys)
+}
+
+// KeyFor returns the key of the texture at path of a category.
+func KeyFor(c Category, path string) Key {
+	if k, ok := overrideKeys[normalizePath(path)]; ok {
+		return k
+	}
+	if c < categoryCount {
+		return categoryKeys[c]
+	}
+	return KeyNone
+}
+
+// Configure applies configured keys. Each entry names a category ("model",
+// "terrain", "water", "ui") or a texture path, and its key.
+func Configure(keys map[string]string) error {
+	for name, value := range keys {
+		k, err := ParseKey(value)
+		if err != nil {
+			return fmt.Errorf("texture key for %s: %w", name, err)
+		}
+		if c, ok := parseCategory(name); ok {
+			SetCategoryKey(c, k)
+		} else {
+			SetOverride(name, k)
+		}
+	}
+	return nil
+}
+
+func parseCategory(name string) (Category, bool) {
+	for c, n := range categoryNames {
+		if n == name {
+			return Category(c), true
+		}
+	}
+	return 0, false
+}
+
+// Decode decodes a BMP, JPEG, PNG or TGA texture, by its path's extension.
+func Decode(data []byte, path string) (image.Image, error) {
+	// TGA has no image package decoder to register
+	if strings.HasSuffix(strings.ToLower(path), ".tga") {
+		return DecodeTGA(data)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+// Load decodes the texture at path of a category and keys out its
+// transparent pixels as the policy says.
+func Load(data []byte, path string, c Category) (*image.RGBA, error) {
+	img, err := Decode(data, path)
+	if err != nil {
+		return nil, err
+	}
+	return ToRGBA(img, KeyFor(c, path)), nil
+}
+
+// ToRGBA converts an image to RGBA, making the pixels key finds
+// transparent and bleeding their neighbors' colors into every transparent
+// pixel (see Bleed).
+func ToRGBA(img image.Image, key Key) *image.RGBA {
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(bounds)
+	paletted, _ := img.(*image.Paletted)
+
+	transparent := false
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+			switch {
+			case key&KeyPaletteZero != 0 && paletted != nil && paletted.ColorIndexAt(x, y) == 0:
+				c = color.RGBA{}
+			case key&KeyMagenta != 0 && IsMagentaKey(c.R, c.G, c.B):
+				c = color.RGBA{}
+			}
+			transparent = transparent || c.A == 0
+			rgba.SetRGBA(x, y, c)
+		}
+	}
+	if transparent {
+		Bleed(rgba)
+	}
+	return rgba
+}
+
+// bleedPasses is how many pixels deep Bleed colors the transparent areas:
+// enough for the coarsest mip level sampled (texfilter.MaxMipLevel, where
+// a texel covers 16x16 pixels) to average no black into the edges.
+const bleedPasses = 16
+
+// Bleed colors the fully transparent pixels of img near visible ones with
+// the average of their colored neighbors, leaving them transparent. Keyed
+// pixels would otherwise keep their black, or the key's magenta, and
+// filtering and mipmapping would blend it into halos around the edges.
+func Bleed(img *image.RGBA) {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	colored := make([]bool, w*h)
+	var pending []int // Transparent pixels still without a color
+	for y := range h {
+		for x := range w {
+			i := y*w + x
+			if img.Pix[img.PixOffset(bounds.Min.X+x, bounds.Min.Y+y)+3] != 0 {
+				colored[i] = true
+			} else {
+				pending = append(pending, i)
+			}
+		}
+	}
+
+	type fill struct {
+		i       int
+		r, g, b uint8
+	}
+	var fills []fill
+	for range bleedPasses {
+		fills = fills[:0]
+		kept := pending[:0]
+		for _, i := range pending {
+			x, y := i%w, i/w
+			var r, g, b, n int
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					nx, ny := x+dx, y+dy
+					if nx < 0 || ny < 0 || nx >= w || ny >= h || !colored[ny*w+nx] {
+						continue
+					}
+					o := img.PixOffset(bounds.Min.X+nx, bounds.Min.Y+ny)
+					r += int(img.Pix[o])
+					g += int(img.Pix[o+1])
+					b += int(img.Pix[o+2])
+					n++
+				}
+			}
+			if n == 0 {
+				kept = append(kept, i)
+				continue
+			}
+			fills = append(fills, fill{i, uint8(r / n), uint8(g / n), uint8(b / n)})
+		}
+		if len(fills) == 0 {
+			return
+		}
+		// Colored after the pass, so each pass reaches one pixel further
+		for _, f := range fills {
+			o := img.PixOffset(bounds.Min.X+f.i%w, bounds.Min.Y+f.i/w)
+			img.Pix[o], img.Pix[o+1], img.Pix[o+2] = f.r, f.g, f.b
+			colored[f.i] = true
+		}
+		pending = kept
+	}
+}
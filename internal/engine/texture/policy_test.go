@@ -0,0 +1,117 @@
+package texture
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Key
+		wantErr bool
+	}{
+		{"none", KeyNone, false},
+		{"magenta", KeyMagenta, false},
+		{"palette0", KeyPaletteZero, false},
+		{"magenta+palette0", KeyMagenta | KeyPaletteZero, false},
+		{"pink", KeyNone, true},
+		{"", KeyNone, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseKey(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseKey(%q) = %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+		if err == nil && got.String() != tt.in {
+			t.Errorf("%v.String() = %q, want %q", got, got.String(), tt.in)
+		}
+	}
+}
+
+func TestKeyFor(t *testing.T) {
+	t.Cleanup(ResetPolicy)
+
+	if got := KeyFor(CategoryModel, "data/texture/a.bmp"); got != KeyMagenta {
+		t.Errorf("model key = %v, want magenta", got)
+	}
+	if got := KeyFor(CategoryWater, "data/texture/water/a.jpg"); got != KeyNone {
+		t.Errorf("water key = %v, want none", got)
+	}
+
+	err := Configure(map[string]string{
+		"water":                  "magenta",
+		`data\texture\Holes.bmp`: "none",
+	})
+	if err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	if got := KeyFor(CategoryWater, "data/texture/water/a.jpg"); got != KeyMagenta {
+		t.Errorf("configured water key = %v, want magenta", got)
+	}
+	if got := KeyFor(CategoryModel, "DATA/TEXTURE/holes.BMP"); got != KeyNone {
+		t.Errorf("overridden key = %v, want none", got)
+	}
+	if err := Configure(map[string]string{"model": "pink"}); err == nil {
+		t.Error("Configure accepted an unknown key")
+	}
+}
+
+func TestToRGBAKeys(t *testing.T) {
+	pal := color.Palette{
+		color.RGBA{R: 0, G: 0, B: 0, A: 255},     // Index 0: black, not magenta
+		color.RGBA{R: 255, G: 0, B: 255, A: 255}, // Magenta
+		color.RGBA{R: 200, G: 100, B: 50, A: 255},
+	}
+	img := image.NewPaletted(image.Rect(0, 0, 3, 1), pal)
+	img.Pix = []uint8{0, 1, 2}
+
+	tests := []struct {
+		key  Key
+		want [3]uint8 // Alpha of each pixel
+	}{
+		{KeyNone, [3]uint8{255, 255, 255}},
+		{KeyMagenta, [3]uint8{255, 0, 255}},
+		{KeyPaletteZero, [3]uint8{0, 255, 255}},
+		{KeyMagenta | KeyPaletteZero, [3]uint8{0, 0, 255}},
+	}
+	for _, tt := range tests {
+		rgba := ToRGBA(img, tt.key)
+		for x, want := range tt.want {
+			if got := rgba.RGBAAt(x, 0).A; got != want {
+				t.Errorf("key %v: alpha of pixel %d = %d, want %d", tt.key, x, got, want)
+			}
+		}
+	}
+}
+
+func TestBleed(t *testing.T) {
+	// A visible orange pixel at the left of a transparent row
+	img := image.NewRGBA(image.Rect(0, 0, 20, 1))
+	img.SetRGBA(0, 0, color.RGBA{R: 200, G: 100, B: 50, A: 255})
+
+	Bleed(img)
+
+	for x := 1; x <= bleedPasses; x++ {
+		c := img.RGBAAt(x, 0)
+		if c != (color.RGBA{R: 200, G: 100, B: 50}) {
+			t.Fatalf("pixel %d = %v, want the visible color, transparent", x, c)
+		}
+	}
+	if c := img.RGBAAt(bleedPasses+1, 0); c != (color.RGBA{}) {
+		t.Errorf("pixel past the bleed = %v, want transparent black", c)
+	}
+}
+
+func TestBleedAveragesNeighbors(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 3, 1))
+	img.SetRGBA(0, 0, color.RGBA{R: 200, A: 255})
+	img.SetRGBA(2, 0, color.RGBA{B: 100, A: 255})
+
+	Bleed(img)
+
+	if c := img.RGBAAt(1, 0); c != (color.RGBA{R: 100, B: 50}) {
+		t.Errorf("middle pixel = %v, want the neighbors' average", c)
+	}
+}
@@ -162,47 +162,3 @@ const (
 func IsMagentaKey(r, g, b uint8) bool {
 	return r >= 250 && g <= 10 && b >= 250
 }
-
-// ApplyMagentaKey modifies an RGBA image in-place, making magenta pixels transparent.
-// Also sets RGB to black on transparent pixels to prevent color bleeding during filtering.
-func ApplyMagentaKey(img *image.RGBA) {
-	bounds := img.Bounds()
-	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
-		for x := bounds.Min.X; x < bounds.Max.X; x++ {
-			i := img.PixOffset(x, y)
-			r, g, b := img.Pix[i], img.Pix[i+1], img.Pix[i+2]
-			if IsMagentaKey(r, g, b) {
-				// Set to transparent black
-				img.Pix[i] = 0
-				img.Pix[i+1] = 0
-				img.Pix[i+2] = 0
-				img.Pix[i+3] = 0
-			}
-		}
-	}
-}
-
-// ImageToRGBA converts any image.Image to *image.RGBA.
-// If applyMagentaKey is true, magenta pixels are made transparent.
-func ImageToRGBA(img image.Image, applyMagentaKey bool) *image.RGBA {
-	bounds := img.Bounds()
-	rgba := image.NewRGBA(bounds)
-
-	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
-		for x := bounds.Min.X; x < bounds.Max.X; x++ {
-			c := img.At(x, y)
-			r16, g16, b16, a16 := c.RGBA()
-			// Convert from 16-bit to 8-bit
-			r8, g8, b8, a8 := uint8(r16>>8), uint8(g16>>8), uint8(b16>>8), uint8(a16>>8)
-
-			if applyMagentaKey && IsMagentaKey(r8, g8, b8) {
-				// Set to transparent black
-				r8, g8, b8, a8 = 0, 0, 0, 0
-			}
-
-			rgba.SetRGBA(x, y, color.RGBA{R: r8, G: g8, B: b8, A: a8})
-		}
-	}
-
-	return rgba
-}
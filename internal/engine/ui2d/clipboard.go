@@ -0,0 +1,65 @@
+package ui2d
+
+import (
+	"runtime"
+	"strings"
+	"unicode"
+)
+
+// Clipboard is the system clipboard text inputs copy to and paste from.
+// The platform layer provides it; ui2d itself knows nothing of SDL.
+type Clipboard interface {
+	Text() string
+	SetText(text string)
+}
+
+// memClipboard is a clipboard private to the process, used until the
+// platform's is set.
+type memClipboard struct {
+	text string
+}
+
+func (m *memClipboard) Text() string        { return m.text }
+func (m *memClipboard) SetText(text string) { m.text = text }
+
+// middleClickPaste reports whether a middle click pastes the last text
+// selected, as X11 and Wayland desktops do.
+var middleClickPaste = runtime.GOOS == "linux"
+
+// SetClipboard sets the clipboard text inputs use.
+func (c *Context) SetClipboard(cb Clipboard) {
+	c.clipboard = cb
+}
+
+// CopyText puts text on the clipboard, e.g. a chat line the player
+// right-clicked.
+func (c *Context) CopyText(text string) {
+	if text == "" {
+		return
+	}
+	c.clipboardOrDefault().SetText(text)
+}
+
+// clipboardOrDefault returns the clipboard, setting up the process one if
+// none was set.
+func (c *Context) clipboardOrDefault() Clipboard {
+	if c.clipboard == nil {
+		c.clipboard = &memClipboard{}
+	}
+	return c.clipboard
+}
+
+// SanitizePaste makes pasted text fit a single-line input: line breaks and
+// tabs become spaces and other control characters are dropped.
+func SanitizePaste(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\t':
+			return ' '
+		case unicode.IsControl(r) || r == unicode.ReplacementChar:
+			return -1
+		}
+		return r
+	}, text)
+}
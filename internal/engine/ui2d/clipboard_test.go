@@ -0,0 +1,21 @@
+package ui2d
+
+import "testing"
+
+func TestSanitizePaste(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"hello", "hello"},
+		{"two\r\nlines\n", "two lines "},
+		{"tab\there", "tab here"},
+		{"bell\a esc\x1b[0m", "bell esc[0m"},
+		{"bad \xff byte", "bad  byte"},
+		{"안녕", "안녕"},
+	}
+	for _, tt := range tests {
+		if got := SanitizePaste(tt.in); got != tt.want {
+			t.Errorf("SanitizePaste(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -38,16 +38,22 @@ var (
 	ColorButtonBevelLo = Color{0.30, 0.30, 0.35, 1}
 	// Input fields: white fill on the white BMP body, recessed bevel
 	// (inverted from buttons — dark on top/left, light on bottom/right).
-	// Focused border tints blue (RO accent rgb 53,93,204).
+	// Focused border tints blue (RO accent rgb 53,93,204), selected text
+	// sits on a paler blue.
 	ColorInputBg          = Color{1.00, 1.00, 1.00, 1}
 	ColorInputBorder      = Color{0.55, 0.55, 0.58, 1}
 	ColorInputBorderFocus = Color{0.21, 0.36, 0.80, 1}
+	ColorSelection        = Color{0.65, 0.78, 0.98, 1}
 	// ColorText is the default text color, tuned for legibility on the cream
 	// win_msgbox.bmp body (which is the dominant text surface).
 	ColorText       = Color{0.1, 0.1, 0.15, 1}
 	ColorTextOnDark = Color{0.9, 0.9, 0.9, 1}
 	ColorTextDim    = Color{0.4, 0.4, 0.5, 1}
 	ColorHighlight  = Color{0.2, 0.6, 0.9, 1}
+	// Tooltips float over both the scene and white windows, so they use a
+	// dark translucent box with light text.
+	ColorTooltipBg     = Color{0.05, 0.05, 0.08, 0.92}
+	ColorTooltipBorder = Color{0.45, 0.45, 0.55, 1}
 )
 
 // RGBA creates a color from 8-bit RGBA values (0-255).
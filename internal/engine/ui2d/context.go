@@ -1,6 +1,11 @@
 package ui2d
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
 
 // Context is the main UI context that manages rendering and input.
 type Context struct {
@@ -17,18 +22,79 @@ type Context struct {
 	// Current window being drawn
 	currentWindow *WindowState
 
+	// Bounds of the windows drawn so far this frame
+	frameWindows []Rect
+
 	// Current listbox being drawn (nil if not in a listbox)
 	currentListBox *ListBoxState
 
 	// Default window skin (nine-slice frame texture)
 	defaultSkin *NineSlice
 
+	// Tooltips: hover tracking and the last widget's bounds for ItemTooltip
+	tooltips   tooltipState
+	lastItemID string
+	lastItem   Rect
+	now        func() time.Time
+
+	// Animation clock: frames begun, and the time since the last frame
+	frame     uint64
+	lastBegin time.Time
+	dt        time.Duration
+
+	// windowEffect is set while the current window's open effect is pushed
+	windowEffect bool
+
+	// Notification toasts, oldest first
+	toasts []toast
+
+	// Text inputs: the editing state of each, the one being selected with
+	// the mouse, the clipboard, and the text last selected, which a middle
+	// click pastes
+	edits     map[string]*TextEdit
+	selecting string
+	clipboard Clipboard
+	primary   string
+
 	// Layout state
 	cursorX float32
 	cursorY float32
 	rowH    float32
+
+	// UI scale. Layout and hit-testing happen in UI units; the renderer's
+	// projection maps the logical viewport (physical / scale) onto the full
+	// framebuffer, so text, widgets and input all scale together.
+	scale          float32
+	viewportW      int
+	viewportH      int
+	rawMouseX      float32
+	rawMouseY      float32
+	mouseConverted bool
 }
 
+// Window animations: windows grow from windowOpenScale and fade in as
+// they open, and shrink and fade out as they close.
+const (
+	WindowOpenDuration  = 150 * time.Millisecond
+	WindowCloseDuration = 120 * time.Millisecond
+	windowOpenScale     = 0.92
+)
+
+// windowGoneFrames is how many frames a window must go undrawn to count as
+// closed. A single skipped frame, such as a screenshot taken without the
+// HUD, neither closes nor reopens it.
+const windowGoneFrames = 2
+
+// maxFrameDelta bounds the time animations advance in one frame, so a
+// hitch doesn't skip them.
+const maxFrameDelta = 100 * time.Millisecond
+
+// UI scale limits.
+const (
+	MinScale = 0.75
+	MaxScale = 2.0
+)
+
 // WindowState holds state for a UI window.
 //
 // Dragged becomes true the first time the user moves the window; once set,
@@ -42,6 +108,11 @@ type WindowState struct {
 	Moving  bool
 	Dragged bool
 	Skin    *NineSlice // Per-window skin override (nil uses default)
+
+	title      string
+	drawnFrame uint64 // Last frame the window was drawn, 0 if never
+	anim       Tween  // Open (toward 1) or close (toward 0) animation
+	closing    bool   // Fading out after the caller stopped drawing it
 }
 
 // NewContext creates a new UI context.
@@ -52,9 +123,14 @@ func NewContext(width, height int) (*Context, error) {
 	}
 
 	return &Context{
-		renderer: r,
-		input:    &InputState{},
-		windows:  make(map[string]*WindowState),
+		renderer:  r,
+		input:     &InputState{},
+		windows:   make(map[string]*WindowState),
+		tooltips:  tooltipState{delay: DefaultTooltipDelay},
+		now:       time.Now,
+		scale:     1.0,
+		viewportW: width,
+		viewportH: height,
 	}, nil
 }
 
@@ -70,9 +146,48 @@ func (c *Context) Renderer() *Renderer {
 	return c.renderer
 }
 
-// Resize updates the screen size.
+// Resize updates the screen size. width and height are window pixels;
+// the renderer works in UI units (pixels divided by the UI scale).
 func (c *Context) Resize(width, height int) {
-	c.renderer.Resize(width, height)
+	c.viewportW = width
+	c.viewportH = height
+	c.renderer.Resize(int(float32(width)/c.scale), int(float32(height)/c.scale))
+}
+
+// ViewportSize returns the screen size in window pixels.
+func (c *Context) ViewportSize() (int, int) {
+	return c.viewportW, c.viewportH
+}
+
+// SetScale sets the UI scale factor, clamped to [MinScale, MaxScale].
+func (c *Context) SetScale(scale float32) {
+	c.scale = ClampScale(scale)
+	c.Resize(c.viewportW, c.viewportH)
+}
+
+// Scale returns the current UI scale factor.
+func (c *Context) Scale() float32 {
+	return c.scale
+}
+
+// ToUI converts window pixel coordinates to UI units, for placing
+// screen-anchored elements such as nameplates.
+func (c *Context) ToUI(x, y float32) (float32, float32) {
+	return x / c.scale, y / c.scale
+}
+
+// ClampScale limits a UI scale factor to the supported range.
+// Zero or negative values fall back to 1.0.
+func ClampScale(scale float32) float32 {
+	switch {
+	case scale <= 0:
+		return 1.0
+	case scale < MinScale:
+		return MinScale
+	case scale > MaxScale:
+		return MaxScale
+	}
+	return scale
 }
 
 // Input returns the input state for modification.
@@ -86,15 +201,46 @@ func (c *Context) SetDefaultWindowSkin(skin *NineSlice) {
 }
 
 // Begin starts a new UI frame.
+//
+// Input providers write mouse coordinates in window pixels. For the
+// duration of the frame they are converted to UI units so widgets can
+// hit-test against their own layout; End restores the raw values.
 func (c *Context) Begin() {
+	c.rawMouseX, c.rawMouseY = c.input.MouseX, c.input.MouseY
+	c.input.MouseX, c.input.MouseY = c.ToUI(c.rawMouseX, c.rawMouseY)
+	c.mouseConverted = true
+
 	c.input.Update()
 	c.renderer.Begin()
+	c.lastItemID = ""
+	c.frameWindows = c.frameWindows[:0]
+	c.windowEffect = false
+	c.tick()
+}
+
+// tick advances the animation clock by a frame.
+func (c *Context) tick() {
+	now := c.now()
+	c.dt = 0
+	if !c.lastBegin.IsZero() {
+		c.dt = min(now.Sub(c.lastBegin), maxFrameDelta)
+	}
+	c.lastBegin = now
+	c.frame++
 }
 
 // End finishes the UI frame.
 func (c *Context) End() {
+	c.renderClosingWindows()
+	c.renderToasts()
+	c.renderTooltip()
 	c.renderer.End()
 	c.input.EndFrame()
+
+	if c.mouseConverted {
+		c.input.MouseX, c.input.MouseY = c.rawMouseX, c.rawMouseY
+		c.mouseConverted = false
+	}
 }
 
 // BeginWindow starts a new window.
@@ -129,6 +275,13 @@ func (c *Context) BeginWindow(id string, x, y, w, h float32, title string) bool
 		return false
 	}
 
+	if c.windowEffect {
+		// The previous window wasn't ended
+		c.renderer.PopEffect()
+		c.windowEffect = false
+	}
+	c.animateWindow(ws)
+	ws.title = title
 	c.currentWindow = ws
 
 	// Handle window dragging (title bar is top 25 pixels)
@@ -153,7 +306,88 @@ func (c *Context) BeginWindow(id string, x, y, w, h float32, title string) bool
 		}
 	}
 
-	// Draw window background
+	c.frameWindows = append(c.frameWindows, Rect{ws.X, ws.Y, ws.W, ws.H})
+
+	if !ws.anim.Done() {
+		c.renderer.PushEffect(windowEffect(ws))
+		c.windowEffect = true
+	}
+	c.drawWindowFrame(ws, title)
+
+	// Set cursor for content (below title bar, with padding)
+	c.cursorX = ws.X + 8
+	c.cursorY = ws.Y + titleBarH + 8
+	c.rowH = 0
+
+	return true
+}
+
+// animateWindow starts a window's open animation if it wasn't shown, or
+// advances the running one. A window reopened while closing grows back
+// from where it was.
+func (c *Context) animateWindow(ws *WindowState) {
+	shown := ws.drawnFrame != 0 && c.frame-ws.drawnFrame <= windowGoneFrames
+	switch {
+	case ws.drawnFrame == c.frame:
+		// Drawn twice this frame
+	case ws.closing:
+		ws.anim = NewTween(ws.anim.Value(), 1, WindowOpenDuration, EaseOutCubic)
+		ws.closing = false
+	case !shown:
+		ws.anim = NewTween(0, 1, WindowOpenDuration, EaseOutCubic)
+	default:
+		ws.anim.Update(c.dt)
+	}
+	ws.drawnFrame = c.frame
+}
+
+// windowEffect returns the effect of a window's animation: scaled about
+// its center and faded by the animation's value.
+func windowEffect(ws *WindowState) Effect {
+	v := clamp01(ws.anim.Value())
+	return Effect{
+		OriginX: ws.X + ws.W/2,
+		OriginY: ws.Y + ws.H/2,
+		Scale:   lerp(windowOpenScale, 1, v),
+		Alpha:   v,
+	}
+}
+
+// renderClosingWindows fades out the frames of windows that stopped being
+// drawn. Their content is gone with the caller, so only the frame and
+// title remain.
+func (c *Context) renderClosingWindows() {
+	var closing []*WindowState
+	for _, ws := range c.windows {
+		if !ws.Open || ws.drawnFrame == 0 {
+			continue
+		}
+		gone := c.frame - ws.drawnFrame
+		if gone == windowGoneFrames && !ws.closing {
+			ws.anim = NewTween(ws.anim.Value(), 0, WindowCloseDuration, EaseInQuad)
+			ws.closing = true
+		} else if ws.closing && gone > windowGoneFrames {
+			ws.anim.Update(c.dt)
+		}
+		if ws.closing {
+			if ws.anim.Done() {
+				ws.closing = false
+				continue
+			}
+			closing = append(closing, ws)
+		}
+	}
+	sort.Slice(closing, func(i, j int) bool { return closing[i].ID < closing[j].ID })
+	for _, ws := range closing {
+		c.renderer.PushEffect(windowEffect(ws))
+		c.drawWindowFrame(ws, ws.title)
+		c.renderer.PopEffect()
+	}
+}
+
+// drawWindowFrame draws a window's background and title bar.
+func (c *Context) drawWindowFrame(ws *WindowState, title string) {
+	titleBarH := float32(25)
 	skin := ws.Skin
 	if skin == nil {
 		skin = c.defaultSkin
@@ -183,17 +417,75 @@ func (c *Context) BeginWindow(id string, x, y, w, h float32, title string) bool
 		textY := ws.Y + (barH-textH)/2
 		c.renderer.DrawText(ws.X+8, textY, title, scale, ColorText)
 	}
+}
 
-	// Set cursor for content (below title bar, with padding)
-	c.cursorX = ws.X + 8
-	c.cursorY = ws.Y + titleBarH + 8
-	c.rowH = 0
+// MouseOverWindow reports whether the mouse is over a window drawn so far
+// this frame, e.g. to tell a drop onto the game world from one onto the UI.
+func (c *Context) MouseOverWindow() bool {
+	for _, r := range c.frameWindows {
+		if r.Contains(c.input.MouseX, c.input.MouseY) {
+			return true
+		}
+	}
+	return false
+}
 
-	return true
+// TextInputFocused reports whether a text input has keyboard focus, so
+// keys typed are text rather than shortcuts.
+func (c *Context) TextInputFocused() bool {
+	return c.activeWidget != "" && !c.input.MouseLeftDown
+}
+
+// WindowRect returns the current window's rectangle, where the user may
+// have dragged it, for drawing custom content inside. Zero outside a
+// window.
+func (c *Context) WindowRect() Rect {
+	if c.currentWindow == nil {
+		return Rect{}
+	}
+	ws := c.currentWindow
+	return Rect{ws.X, ws.Y, ws.W, ws.H}
+}
+
+// MovedWindows returns where the user has dragged windows to, by window
+// ID, for saving a layout.
+func (c *Context) MovedWindows() map[string][2]float32 {
+	moved := make(map[string][2]float32)
+	for id, ws := range c.windows {
+		if ws.Dragged {
+			moved[id] = [2]float32{ws.X, ws.Y}
+		}
+	}
+	return moved
+}
+
+// PlaceWindow puts a window at x, y as if the user had dragged it there,
+// e.g. to restore a saved layout. The caller's position for it in
+// BeginWindow is ignored from then on.
+func (c *Context) PlaceWindow(id string, x, y float32) {
+	ws, ok := c.windows[id]
+	if !ok {
+		ws = &WindowState{ID: id, Open: true}
+		c.windows[id] = ws
+	}
+	ws.X, ws.Y = x, y
+	ws.Dragged = true
+}
+
+// ResetWindowPositions forgets where windows were dragged, so they go back
+// to their callers' positions.
+func (c *Context) ResetWindowPositions() {
+	for _, ws := range c.windows {
+		ws.Dragged = false
+	}
 }
 
 // EndWindow ends the current window.
 func (c *Context) EndWindow() {
+	if c.windowEffect {
+		c.renderer.PopEffect()
+		c.windowEffect = false
+	}
 	c.currentWindow = nil
 }
 
@@ -275,6 +567,7 @@ func (c *Context) Button(id string, width float32, label string) bool {
 	textX := x + (width-textW)/2
 	textY := y + (h-textH)/2
 	c.renderer.DrawText(textX, textY, label, scale, ColorText)
+	c.setLastItem(fullID, rect)
 
 	// Advance cursor
 	c.cursorX += width + 4
@@ -298,13 +591,36 @@ func (c *Context) LabelColored(text string, color Color) {
 	c.renderer.DrawText(c.cursorX, c.cursorY, text, scale, color)
 
 	// Advance cursor
-	w, _ := c.renderer.MeasureText(text, scale)
+	w, h := c.renderer.MeasureText(text, scale)
+	c.setLastItem("", Rect{c.cursorX, c.cursorY, w, h})
 	c.cursorX += w + 4
 }
 
-// TextInput draws a text input field.
+// SetKeyboardFocus focuses the text input with the given id in the current
+// window, as if it had been clicked. Call it before drawing the input.
+func (c *Context) SetKeyboardFocus(id string) {
+	if c.currentWindow == nil {
+		return
+	}
+	c.activeWidget = c.currentWindow.ID + "_" + id
+}
+
+// HasKeyboardFocus reports whether the input id in the current window has
+// keyboard focus.
+func (c *Context) HasKeyboardFocus(id string) bool {
+	return c.currentWindow != nil && c.activeWidget == c.currentWindow.ID+"_"+id
+}
+
+// TextInput draws a text input field. Text can be selected with the
+// mouse or with Shift and the arrow keys, then cut, copied and pasted.
 // Returns (current value, changed, submitted).
 func (c *Context) TextInput(id string, width float32, value string) (string, bool, bool) {
+	return c.textField(id, width, value, false)
+}
+
+// textField draws a text input field, masked for passwords. A password
+// can be pasted but never copied or cut.
+func (c *Context) textField(id string, width float32, value string, password bool) (string, bool, bool) {
 	if c.currentWindow == nil {
 		return value, false, false
 	}
@@ -321,54 +637,131 @@ func (c *Context) TextInput(id string, width float32, value string) (string, boo
 
 	fullID := c.currentWindow.ID + "_" + id
 	rect := Rect{x, y, width, h}
+	edit := c.textEdit(fullID)
+	edit.SetText(value)
 
-	// Check interaction
-	hovered := rect.Contains(c.input.MouseX, c.input.MouseY)
-	focused := c.activeWidget == fullID
-	changed := false
-	submitted := false
-
-	if hovered && c.input.MouseLeftPressed {
+	scale := float32(1.0)
+	measure := func(s string) float32 {
+		w, _ := c.renderer.MeasureText(s, scale)
+		return w
+	}
+	shown := func() []rune {
+		if password {
+			return []rune(strings.Repeat("*", len(edit.text)))
+		}
+		return edit.text
+	}
+	textX := x + 4
+	in := c.input
+
+	// Check interaction: a click places the cursor, Shift-click or a drag
+	// selects
+	hovered := rect.Contains(in.MouseX, in.MouseY)
+	if hovered && in.MouseLeftPressed {
+		c.activeWidget, c.selecting = fullID, fullID
+		edit.SetCursor(caretIndex(shown(), in.MouseX-textX, measure), in.KeyShift)
+	} else if c.selecting == fullID {
+		if in.MouseLeftDown {
+			edit.SetCursor(caretIndex(shown(), in.MouseX-textX, measure), true)
+		} else {
+			c.selecting = ""
+		}
+	}
+	if middleClickPaste && hovered && in.MouseMiddlePressed && c.primary != "" {
 		c.activeWidget = fullID
+		edit.SetCursor(caretIndex(shown(), in.MouseX-textX, measure), false)
+		edit.Insert(SanitizePaste(c.primary))
 	}
 
-	// Handle text input when focused
+	focused := c.activeWidget == fullID
+	submitted := false
 	if focused {
-		if len(c.input.TextInput) > 0 {
-			value += c.input.TextInput
-			changed = true
-		}
-		if c.input.KeyBackspacePressed && len(value) > 0 {
-			value = value[:len(value)-1]
-			changed = true
-		}
-		if c.input.KeyEnterPressed {
-			submitted = true
-		}
-		if c.input.KeyEscapePressed {
-			c.activeWidget = ""
+		submitted = c.editKeys(edit, password)
+		if !password && edit.HasSelection() {
+			c.primary = edit.SelectedText()
 		}
 	}
 
 	drawSunkenInput(c.renderer, x, y, width, h, focused)
 
-	// Draw text value
-	scale := float32(1.0)
+	// Draw the selection behind the text, then the cursor
+	text := shown()
 	_, textH := c.renderer.MeasureText("Ag", scale) // representative height
 	textY := y + (h-textH)/2
-	c.renderer.DrawText(x+4, textY, value, scale, ColorText)
-
-	// Draw cursor when focused
+	if focused && edit.HasSelection() {
+		start, end := edit.Selection()
+		x0, x1 := measure(string(text[:start])), measure(string(text[:end]))
+		c.renderer.DrawRect(textX+x0, y+4, x1-x0, h-8, ColorSelection)
+	}
+	c.renderer.DrawText(textX, textY, string(text), scale, ColorText)
 	if focused {
-		textW, _ := c.renderer.MeasureText(value, scale)
-		cursorX := x + 4 + textW
+		cursorX := textX + measure(string(text[:edit.Cursor()]))
 		c.renderer.DrawRect(cursorX, y+4, 2, h-8, ColorText)
 	}
+	c.setLastItem(fullID, rect)
 
 	// Advance cursor
 	c.cursorX += width + 4
 
-	return value, changed, submitted
+	newValue := edit.Text()
+	return newValue, newValue != value, submitted
+}
+
+// textEdit returns the editing state of a text input, creating it the
+// first time the input is drawn.
+func (c *Context) textEdit(fullID string) *TextEdit {
+	if c.edits == nil {
+		c.edits = make(map[string]*TextEdit)
+	}
+	edit, ok := c.edits[fullID]
+	if !ok {
+		edit = &TextEdit{}
+		c.edits[fullID] = edit
+	}
+	return edit
+}
+
+// editKeys applies this frame's typing and editing keys to the focused
+// text input, reporting whether Enter submitted it.
+func (c *Context) editKeys(edit *TextEdit, password bool) bool {
+	in := c.input
+	if in.KeySelectAll {
+		edit.SelectAll()
+	}
+	if (in.KeyCopy || in.KeyCut) && !password && edit.HasSelection() {
+		c.clipboardOrDefault().SetText(edit.SelectedText())
+		if in.KeyCut {
+			edit.Insert("")
+		}
+	}
+	if in.KeyPaste {
+		edit.Insert(SanitizePaste(c.clipboardOrDefault().Text()))
+	}
+	if len(in.TextInput) > 0 {
+		edit.Insert(in.TextInput)
+	}
+	if in.KeyBackspacePressed {
+		edit.Backspace()
+	}
+	if in.KeyDeletePressed {
+		edit.Delete()
+	}
+	if in.KeyLeftPressed {
+		edit.Move(-1, in.KeyShift)
+	}
+	if in.KeyRightPressed {
+		edit.Move(1, in.KeyShift)
+	}
+	if in.KeyHomePressed {
+		edit.SetCursor(0, in.KeyShift)
+	}
+	if in.KeyEndPressed {
+		edit.SetCursor(len(edit.text), in.KeyShift)
+	}
+	if in.KeyEscapePressed {
+		c.activeWidget = ""
+	}
+	return in.KeyEnterPressed
 }
 
 // drawSunkenInput renders a text-input field as a recessed (sunken) box on
@@ -453,24 +846,26 @@ func (c *Context) ProgressBar(fraction float32, width, height float32, label str
 		textY := y + (height-textH)/2
 		c.renderer.DrawText(textX, textY, label, scale, ColorText)
 	}
+	c.setLastItem("", Rect{x, y, width, height})
 
 	// Advance cursor
 	c.cursorX = c.currentWindow.X + 8
 	c.cursorY += height + 4
 }
 
-// PasswordInput draws a password input field with masked characters.
-// Returns (current value, changed, submitted).
-func (c *Context) PasswordInput(id string, width float32, value string) (string, bool, bool) {
+// Slider draws a horizontal slider for an integer between lo and hi.
+// Clicking the track jumps to that value and dragging follows the mouse.
+// Returns (current value, changed).
+func (c *Context) Slider(id string, width float32, value, lo, hi int) (int, bool) {
 	if c.currentWindow == nil {
-		return value, false, false
+		return value, false
 	}
 
 	x := c.cursorX
 	y := c.cursorY
 	h := c.rowH
 	if h == 0 {
-		h = 28
+		h = 20
 	}
 	if width == 0 {
 		width = c.currentWindow.W - 16
@@ -478,63 +873,79 @@ func (c *Context) PasswordInput(id string, width float32, value string) (string,
 
 	fullID := c.currentWindow.ID + "_" + id
 	rect := Rect{x, y, width, h}
-
-	// Check interaction
 	hovered := rect.Contains(c.input.MouseX, c.input.MouseY)
-	focused := c.activeWidget == fullID
-	changed := false
-	submitted := false
 
-	if hovered && c.input.MouseLeftPressed {
-		c.activeWidget = fullID
+	if hovered {
+		c.hotWidget = fullID
+		if c.input.MouseLeftPressed {
+			c.activeWidget = fullID
+		}
 	}
 
-	// Handle text input when focused
-	if focused {
-		if len(c.input.TextInput) > 0 {
-			value += c.input.TextInput
-			changed = true
-		}
-		if c.input.KeyBackspacePressed && len(value) > 0 {
-			value = value[:len(value)-1]
-			changed = true
-		}
-		if c.input.KeyEnterPressed {
-			submitted = true
+	changed := false
+	if c.activeWidget == fullID {
+		if v := sliderValue(c.input.MouseX, x, width, lo, hi); v != value {
+			value, changed = v, true
 		}
-		if c.input.KeyEscapePressed {
+		if c.input.MouseLeftReleased || !c.input.MouseLeftDown {
 			c.activeWidget = ""
 		}
 	}
 
-	// Draw input field
-	drawSunkenInput(c.renderer, x, y, width, h, focused)
-
-	// Draw masked text (dots instead of characters)
-	scale := float32(1.0)
-	maskedText := ""
-	for range value {
-		maskedText += "*"
+	// Track, filled up to the value
+	c.renderer.DrawRect(x, y, width, h, ColorInputBg)
+	c.renderer.DrawRectOutline(x, y, width, h, 1, ColorPanelBorder)
+	fraction := float32(0)
+	if hi > lo {
+		fraction = float32(max(lo, min(value, hi))-lo) / float32(hi-lo)
+	}
+	if fillWidth := (width - 2) * fraction; fillWidth > 0 {
+		c.renderer.DrawRect(x+1, y+1, fillWidth, h-2, ColorHighlight)
 	}
-	_, textH := c.renderer.MeasureText("Ag", scale)
-	textY := y + (h-textH)/2
-	c.renderer.DrawText(x+4, textY, maskedText, scale, ColorText)
 
-	// Draw cursor when focused
-	if focused {
-		textW, _ := c.renderer.MeasureText(maskedText, scale)
-		cursorX := x + 4 + textW
-		c.renderer.DrawRect(cursorX, y+4, 2, h-8, ColorText)
+	// Knob
+	knobW := float32(8)
+	knobColor := ColorButtonNormal
+	if c.activeWidget == fullID {
+		knobColor = ColorButtonActive
+	} else if hovered {
+		knobColor = ColorButtonHover
 	}
+	knobX := x + (width-knobW)*fraction
+	c.renderer.DrawRect(knobX, y, knobW, h, knobColor)
+	c.renderer.DrawRectOutline(knobX, y, knobW, h, 1, ColorPanelBorder)
+	c.setLastItem(fullID, rect)
 
 	// Advance cursor
 	c.cursorX += width + 4
 
-	return value, changed, submitted
+	return value, changed
+}
+
+// sliderValue returns the value a slider spanning lo to hi over a track
+// at x, width wide, has with the mouse at mouseX.
+func sliderValue(mouseX, x, width float32, lo, hi int) int {
+	if hi <= lo || width <= 0 {
+		return lo
+	}
+	f := max(0, min((mouseX-x)/width, 1))
+	return lo + int(f*float32(hi-lo)+0.5)
+}
+
+// PasswordInput draws a password input field with masked characters.
+// Returns (current value, changed, submitted).
+func (c *Context) PasswordInput(id string, width float32, value string) (string, bool, bool) {
+	return c.textField(id, width, value, true)
 }
 
 // Selectable draws a selectable item and returns true if clicked.
 func (c *Context) Selectable(id string, label string, selected bool) bool {
+	return c.SelectableColored(id, label, selected, ColorText)
+}
+
+// SelectableColored draws a selectable item with a colored label and
+// returns true if clicked.
+func (c *Context) SelectableColored(id string, label string, selected bool, color Color) bool {
 	if c.currentWindow == nil {
 		return false
 	}
@@ -594,7 +1005,8 @@ func (c *Context) Selectable(id string, label string, selected bool) bool {
 	scale := float32(1.0)
 	_, textH := c.renderer.MeasureText(label, scale)
 	textY := y + (h-textH)/2
-	c.renderer.DrawText(x+4, textY, label, scale, ColorText)
+	c.renderer.DrawText(x+4, textY, label, scale, color)
+	c.setLastItem(fullID, rect)
 
 	// Advance cursor to next row
 	if c.currentListBox != nil {
@@ -690,6 +1102,7 @@ func (c *Context) ButtonDisabled(id string, width float32, label string) {
 	textX := x + (width-textW)/2
 	textY := y + (h-textH)/2
 	c.renderer.DrawText(textX, textY, label, scale, ColorTextDim)
+	c.setLastItem(c.currentWindow.ID+"_"+id, Rect{x, y, width, h})
 
 	// Advance cursor
 	c.cursorX += width + 4
@@ -749,6 +1162,7 @@ func (c *Context) Checkbox(id string, label string, checked bool) bool {
 
 	// Advance cursor
 	labelW, _ := c.renderer.MeasureText(label, scale)
+	c.setLastItem(fullID, Rect{x, y, boxSize + 8 + labelW, boxSize})
 	c.cursorX += boxSize + 8 + labelW + 8
 
 	return checked
@@ -771,6 +1185,23 @@ func (c *Context) LabelCentered(text string) {
 	c.renderer.DrawText(x, c.cursorY, text, scale, ColorText)
 }
 
+// Image draws a texture at the cursor with the given size.
+func (c *Context) Image(texID uint32, width, height float32) {
+	if c.currentWindow == nil {
+		return
+	}
+
+	if texID != 0 {
+		c.renderer.DrawImage(texID, c.cursorX, c.cursorY, width, height, ColorWhite)
+	} else {
+		c.renderer.DrawRect(c.cursorX, c.cursorY, width, height, ColorPanelBorder)
+	}
+	c.setLastItem("", Rect{c.cursorX, c.cursorY, width, height})
+
+	// Advance cursor
+	c.cursorX += width + 4
+}
+
 // GetScreenSize returns the current screen dimensions.
 func (c *Context) GetScreenSize() (float32, float32) {
 	w, h := c.renderer.GetScreenSize()
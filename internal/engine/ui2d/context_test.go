@@ -0,0 +1,93 @@
+package ui2d
+
+import "testing"
+
+func TestClampScale(t *testing.T) {
+	tests := []struct {
+		in   float32
+		want float32
+	}{
+		{0, 1.0},
+		{-1, 1.0},
+		{0.5, MinScale},
+		{0.75, 0.75},
+		{1.25, 1.25},
+		{2.0, 2.0},
+		{3.0, MaxScale},
+	}
+
+	for _, tt := range tests {
+		if got := ClampScale(tt.in); got != tt.want {
+			t.Errorf("ClampScale(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMouseOverWindow(t *testing.T) {
+	c := &Context{
+		input:        &InputState{},
+		frameWindows: []Rect{{X: 10, Y: 10, W: 100, H: 50}},
+	}
+
+	tests := []struct {
+		x, y float32
+		want bool
+	}{
+		{50, 30, true},
+		{10, 10, true},
+		{110, 30, false},
+		{5, 30, false},
+	}
+
+	for _, tt := range tests {
+		c.input.MouseX, c.input.MouseY = tt.x, tt.y
+		if got := c.MouseOverWindow(); got != tt.want {
+			t.Errorf("MouseOverWindow() at (%v, %v) = %v, want %v", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestWindowPlacement(t *testing.T) {
+	c := &Context{windows: map[string]*WindowState{
+		"chat":      {ID: "chat", X: 10, Y: 500, Open: true},
+		"inventory": {ID: "inventory", X: 300, Y: 40, Open: true, Dragged: true},
+	}}
+
+	moved := c.MovedWindows()
+	if len(moved) != 1 || moved["inventory"] != [2]float32{300, 40} {
+		t.Errorf("MovedWindows() = %v, want only inventory at (300, 40)", moved)
+	}
+
+	c.PlaceWindow("chat", 20, 30)
+	c.PlaceWindow("status", 50, 60) // Not drawn yet
+	moved = c.MovedWindows()
+	if moved["chat"] != [2]float32{20, 30} || moved["status"] != [2]float32{50, 60} {
+		t.Errorf("after PlaceWindow: %v", moved)
+	}
+
+	c.ResetWindowPositions()
+	if moved := c.MovedWindows(); len(moved) != 0 {
+		t.Errorf("after ResetWindowPositions: %v", moved)
+	}
+}
+
+func TestSliderValue(t *testing.T) {
+	tests := []struct {
+		mouseX float32
+		lo, hi int
+		want   int
+	}{
+		{10, 1, 30, 1},   // Left end of the track
+		{110, 1, 30, 30}, // Right end
+		{60, 0, 10, 5},
+		{64, 0, 10, 5}, // Rounds to the nearest step
+		{-50, 1, 30, 1},
+		{500, 1, 30, 30},
+		{60, 5, 5, 5}, // Nothing to slide between
+	}
+	for _, tt := range tests {
+		if got := sliderValue(tt.mouseX, 10, 100, tt.lo, tt.hi); got != tt.want {
+			t.Errorf("sliderValue(%v, %d, %d) = %d, want %d", tt.mouseX, tt.lo, tt.hi, got, tt.want)
+		}
+	}
+}
@@ -90,21 +90,7 @@ var systemFontPaths = []string{
 // NewFont loads a system TTF and builds the glyph atlas. Returns nil if
 // no usable font is found; callers should treat that as "no text".
 func NewFont() *Font {
-	data, err := loadSystemFont()
-	if err != nil {
-		return nil
-	}
-
-	parsed, err := opentype.Parse(data)
-	if err != nil {
-		return nil
-	}
-
-	face, err := opentype.NewFace(parsed, &opentype.FaceOptions{
-		Size:    fontSize,
-		DPI:     fontDPI,
-		Hinting: font.HintingFull,
-	})
+	face, err := NewSystemFace(fontSize)
 	if err != nil {
 		return nil
 	}
@@ -147,6 +133,28 @@ func NewFont() *Font {
 	return f
 }
 
+// NewSystemFace loads a system TTF as a face of the given point size, for
+// text rasterized outside the UI's glyph atlas (e.g. world-space boards).
+func NewSystemFace(size float64) (font.Face, error) {
+	data, err := loadSystemFont()
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := opentype.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse system font: %w", err)
+	}
+	face, err := opentype.NewFace(parsed, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     fontDPI,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create font face: %w", err)
+	}
+	return face, nil
+}
+
 // rasterize generates the glyph for r, places it in the atlas via
 // shelf-pack, uploads the new region, and caches the metrics. Returns
 // the cached glyph (which may be nil if the rune has no representation
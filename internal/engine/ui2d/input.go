@@ -66,13 +66,27 @@ type InputState struct {
 	prevKeyBackspace bool
 	prevKeyDelete    bool
 	prevKeyEnter     bool
+	prevKeyTab       bool
 	prevKeyEscape    bool
+	prevKeyUp        bool
+	prevKeyDown      bool
+	prevKeyLeft      bool
+	prevKeyRight     bool
+	prevKeyHome      bool
+	prevKeyEnd       bool
 
 	// Key pressed this frame (edge detected)
 	KeyBackspacePressed bool
 	KeyDeletePressed    bool
 	KeyEnterPressed     bool
+	KeyTabPressed       bool
 	KeyEscapePressed    bool
+	KeyUpPressed        bool
+	KeyDownPressed      bool
+	KeyLeftPressed      bool
+	KeyRightPressed     bool
+	KeyHomePressed      bool
+	KeyEndPressed       bool
 }
 
 // Update prepares input state for a new frame.
@@ -95,7 +109,14 @@ func (i *InputState) Update() {
 	i.KeyBackspacePressed = i.KeyBackspace && !i.prevKeyBackspace
 	i.KeyDeletePressed = i.KeyDelete && !i.prevKeyDelete
 	i.KeyEnterPressed = i.KeyEnter && !i.prevKeyEnter
+	i.KeyTabPressed = i.KeyTab && !i.prevKeyTab
 	i.KeyEscapePressed = i.KeyEscape && !i.prevKeyEscape
+	i.KeyUpPressed = i.KeyUp && !i.prevKeyUp
+	i.KeyDownPressed = i.KeyDown && !i.prevKeyDown
+	i.KeyLeftPressed = i.KeyLeft && !i.prevKeyLeft
+	i.KeyRightPressed = i.KeyRight && !i.prevKeyRight
+	i.KeyHomePressed = i.KeyHome && !i.prevKeyHome
+	i.KeyEndPressed = i.KeyEnd && !i.prevKeyEnd
 
 	// Store current state for next frame
 	i.prevMouseLeft = i.MouseLeftDown
@@ -106,7 +127,14 @@ func (i *InputState) Update() {
 	i.prevKeyBackspace = i.KeyBackspace
 	i.prevKeyDelete = i.KeyDelete
 	i.prevKeyEnter = i.KeyEnter
+	i.prevKeyTab = i.KeyTab
 	i.prevKeyEscape = i.KeyEscape
+	i.prevKeyUp = i.KeyUp
+	i.prevKeyDown = i.KeyDown
+	i.prevKeyLeft = i.KeyLeft
+	i.prevKeyRight = i.KeyRight
+	i.prevKeyHome = i.KeyHome
+	i.prevKeyEnd = i.KeyEnd
 }
 
 // EndFrame clears per-frame input state.
@@ -0,0 +1,121 @@
+package ui2d
+
+// Palette holds the colors that carry gameplay meaning: HP bars, damage
+// numbers and ground indicators. The color-blind palettes avoid telling
+// states apart by red against green alone, using blue, orange and yellow
+// hues that stay distinct with red or green cone deficiencies.
+type Palette struct {
+	Name  string // Config value
+	Label string // Shown in the settings window
+
+	// HP bar fill by remaining fraction: above half, above a quarter, below
+	HPHigh, HPMid, HPLow Color
+
+	// Damage numbers
+	DamageDealt Color // Damage the player deals
+	DamageTaken Color // Damage the player takes
+	Heal        Color
+
+	// Ground indicators
+	GroundTarget Color // Skill target area
+	GroundDanger Color // Area that hurts the player
+}
+
+// Palette names, as stored in the config.
+const (
+	PaletteNameDefault      = "default"
+	PaletteNameDeuteranopia = "deuteranopia"
+	PaletteNameProtanopia   = "protanopia"
+)
+
+var (
+	// PaletteDefault is the classic green/yellow/red look.
+	PaletteDefault = Palette{
+		Name:         PaletteNameDefault,
+		Label:        "Standard",
+		HPHigh:       Color{0.2, 0.9, 0.2, 1},
+		HPMid:        Color{1.0, 0.8, 0.2, 1},
+		HPLow:        Color{1.0, 0.2, 0.2, 1},
+		DamageDealt:  Color{1, 1, 1, 1},
+		DamageTaken:  Color{1.0, 0.3, 0.3, 1},
+		Heal:         Color{0.3, 1.0, 0.3, 1},
+		GroundTarget: Color{0.3, 0.9, 0.3, 0.5},
+		GroundDanger: Color{1.0, 0.2, 0.2, 0.5},
+	}
+
+	// PaletteDeuteranopia replaces green with blue and red with orange, for
+	// players who can't tell red from green (the most common deficiency).
+	PaletteDeuteranopia = Palette{
+		Name:         PaletteNameDeuteranopia,
+		Label:        "Deuteranopia (red-green)",
+		HPHigh:       Color{0.0, 0.45, 0.70, 1},
+		HPMid:        Color{0.94, 0.89, 0.26, 1},
+		HPLow:        Color{0.90, 0.62, 0.0, 1},
+		DamageDealt:  Color{1, 1, 1, 1},
+		DamageTaken:  Color{0.90, 0.62, 0.0, 1},
+		Heal:         Color{0.34, 0.71, 0.91, 1},
+		GroundTarget: Color{0.0, 0.45, 0.70, 0.5},
+		GroundDanger: Color{0.90, 0.62, 0.0, 0.5},
+	}
+
+	// PaletteProtanopia is like PaletteDeuteranopia, but keeps warning
+	// colors bright: without red cones, reds and dark oranges look nearly
+	// black against the ground.
+	PaletteProtanopia = Palette{
+		Name:         PaletteNameProtanopia,
+		Label:        "Protanopia (red-weak)",
+		HPHigh:       Color{0.34, 0.71, 0.91, 1},
+		HPMid:        Color{0.94, 0.89, 0.26, 1},
+		HPLow:        Color{1.0, 0.65, 0.0, 1},
+		DamageDealt:  Color{1, 1, 1, 1},
+		DamageTaken:  Color{1.0, 0.65, 0.0, 1},
+		Heal:         Color{0.34, 0.71, 0.91, 1},
+		GroundTarget: Color{0.34, 0.71, 0.91, 0.5},
+		GroundDanger: Color{0.94, 0.89, 0.26, 0.5},
+	}
+)
+
+// Palettes lists the selectable palettes in menu order.
+var Palettes = []Palette{PaletteDefault, PaletteDeuteranopia, PaletteProtanopia}
+
+// PaletteByName returns the palette with the given name. Unknown names,
+// e.g. from a hand-edited config, fall back to PaletteDefault.
+func PaletteByName(name string) Palette {
+	for _, p := range Palettes {
+		if p.Name == name {
+			return p
+		}
+	}
+	return PaletteDefault
+}
+
+// HPColor returns the HP bar color for a remaining HP fraction (0-1).
+func (p Palette) HPColor(fraction float32) Color {
+	switch {
+	case fraction > 0.5:
+		return p.HPHigh
+	case fraction > 0.25:
+		return p.HPMid
+	}
+	return p.HPLow
+}
+
+// Damage number scale limits, relative to the normal size.
+const (
+	MinDamageTextScale = 1.0
+	MaxDamageTextScale = 2.0
+)
+
+// ClampDamageTextScale limits a damage number scale to the supported
+// range. Zero or negative values fall back to 1.0.
+func ClampDamageTextScale(scale float32) float32 {
+	switch {
+	case scale <= 0:
+		return 1.0
+	case scale < MinDamageTextScale:
+		return MinDamageTextScale
+	case scale > MaxDamageTextScale:
+		return MaxDamageTextScale
+	}
+	return scale
+}
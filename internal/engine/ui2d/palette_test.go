@@ -0,0 +1,74 @@
+package ui2d
+
+import "testing"
+
+func TestPaletteByName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"default", PaletteNameDefault},
+		{"deuteranopia", PaletteNameDeuteranopia},
+		{"protanopia", PaletteNameProtanopia},
+		{"", PaletteNameDefault},
+		{"tritanopia", PaletteNameDefault},
+	}
+
+	for _, tt := range tests {
+		if got := PaletteByName(tt.name).Name; got != tt.want {
+			t.Errorf("PaletteByName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPaletteHPColor(t *testing.T) {
+	p := PaletteDeuteranopia
+	tests := []struct {
+		fraction float32
+		want     Color
+	}{
+		{1, p.HPHigh},
+		{0.51, p.HPHigh},
+		{0.5, p.HPMid},
+		{0.3, p.HPMid},
+		{0.25, p.HPLow},
+		{0, p.HPLow},
+	}
+
+	for _, tt := range tests {
+		if got := p.HPColor(tt.fraction); got != tt.want {
+			t.Errorf("HPColor(%v) = %v, want %v", tt.fraction, got, tt.want)
+		}
+	}
+}
+
+// The color-blind palettes must not use strong reds, which those players
+// can't tell from green or see as near black.
+func TestColorBlindPalettesAvoidPureRed(t *testing.T) {
+	for _, p := range []Palette{PaletteDeuteranopia, PaletteProtanopia} {
+		for _, c := range []Color{p.HPHigh, p.HPMid, p.HPLow, p.DamageTaken, p.GroundDanger} {
+			if c.R > 0.5 && c.G < 0.5 {
+				t.Errorf("%s: color %v reads as red", p.Name, c)
+			}
+		}
+	}
+}
+
+func TestClampDamageTextScale(t *testing.T) {
+	tests := []struct {
+		in   float32
+		want float32
+	}{
+		{0, 1.0},
+		{-1, 1.0},
+		{0.5, MinDamageTextScale},
+		{1.5, 1.5},
+		{3.0, MaxDamageTextScale},
+	}
+
+	for _, tt := range tests {
+		if got := ClampDamageTextScale(tt.in); got != tt.want {
+			t.Errorf("ClampDamageTextScale(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"unsafe"
 
 	"github.com/go-gl/gl/v4.1-core/gl"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/glstate"
 )
 
 // imageDrawCall represents a batched image draw call.
@@ -60,6 +62,44 @@ type Renderer struct {
 
 	// Font for text rendering
 	font *Font
+
+	// Effect applied to everything drawn, and the effects it was pushed
+	// over
+	effect  transform
+	effects []transform
+}
+
+// Effect scales what's drawn about an origin, offsets and fades it, such
+// as a window growing in as it opens.
+type Effect struct {
+	OriginX, OriginY float32
+	Scale            float32
+	OffsetX, OffsetY float32
+	Alpha            float32
+}
+
+// transform maps a point p to p*scale + (tx, ty) and multiplies alpha.
+type transform struct {
+	scale, tx, ty, alpha float32
+}
+
+// identity is the transform of drawing without effects.
+var identity = transform{scale: 1, alpha: 1}
+
+// then returns the transform applying t, then outer.
+func (t transform) then(outer transform) transform {
+	return transform{
+		scale: t.scale * outer.scale,
+		tx:    t.tx*outer.scale + outer.tx,
+		ty:    t.ty*outer.scale + outer.ty,
+		alpha: t.alpha * outer.alpha,
+	}
+}
+
+// apply transforms a rectangle and color.
+func (t transform) apply(x, y, w, h float32, c Color) (float32, float32, float32, float32, Color) {
+	c.A *= t.alpha
+	return x*t.scale + t.tx, y*t.scale + t.ty, w * t.scale, h * t.scale, c
 }
 
 // New creates a new 2D UI renderer.
@@ -70,6 +110,7 @@ func New(width, height int) (*Renderer, error) {
 		solidVertices: make([]float32, 0, 4096),
 		textVertices:  make([]float32, 0, 4096),
 		imageVertices: make([]float32, 0, 4096),
+		effect:        identity,
 	}
 
 	// Create solid color shader
@@ -136,6 +177,8 @@ func (r *Renderer) GetScreenSize() (int, int) {
 
 // Begin starts a new UI frame.
 func (r *Renderer) Begin() {
+	r.effect = identity
+	r.effects = r.effects[:0]
 	r.solidVertices = r.solidVertices[:0]
 	r.textVertices = r.textVertices[:0]
 	r.imageVertices = r.imageVertices[:0]
@@ -144,19 +187,8 @@ func (r *Renderer) Begin() {
 
 // End finishes the UI frame and renders all queued elements.
 func (r *Renderer) End() {
-	// Save OpenGL state
-	var prevBlend int32
-	var prevDepth int32
-	var prevCull int32
-	gl.GetIntegerv(gl.BLEND, &prevBlend)
-	gl.GetIntegerv(gl.DEPTH_TEST, &prevDepth)
-	gl.GetIntegerv(gl.CULL_FACE, &prevCull)
-
-	// Setup state for 2D rendering
-	gl.Enable(gl.BLEND)
-	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
-	gl.Disable(gl.DEPTH_TEST)
-	gl.Disable(gl.CULL_FACE)
+	// Blended 2D over everything, restoring the 3D state after
+	defer glstate.Push(glstate.Overlay)()
 
 	proj := r.orthoMatrix(0, float32(r.screenWidth), float32(r.screenHeight), 0, -1, 1)
 
@@ -217,16 +249,13 @@ func (r *Renderer) End() {
 	gl.BindVertexArray(0)
 	gl.BindTexture(gl.TEXTURE_2D, 0)
 	gl.UseProgram(0)
+}
 
-	if prevBlend == gl.FALSE {
-		gl.Disable(gl.BLEND)
-	}
-	if prevDepth == gl.TRUE {
-		gl.Enable(gl.DEPTH_TEST)
-	}
-	if prevCull == gl.TRUE {
-		gl.Enable(gl.CULL_FACE)
-	}
+// Flush renders everything queued so far and starts a new batch, so later
+// draws (tooltips, popups) appear above all earlier text and quads.
+func (r *Renderer) Flush() {
+	r.End()
+	r.Begin()
 }
 
 // Close releases renderer resources.
@@ -272,6 +301,28 @@ func (r *Renderer) Close() {
 	}
 }
 
+// PushEffect applies e to what's drawn until the matching PopEffect,
+// within any effects already pushed.
+func (r *Renderer) PushEffect(e Effect) {
+	r.effects = append(r.effects, r.effect)
+	inner := transform{
+		scale: e.Scale,
+		tx:    e.OriginX - e.OriginX*e.Scale + e.OffsetX,
+		ty:    e.OriginY - e.OriginY*e.Scale + e.OffsetY,
+		alpha: e.Alpha,
+	}
+	r.effect = inner.then(r.effect)
+}
+
+// PopEffect removes the effect pushed last.
+func (r *Renderer) PopEffect() {
+	if len(r.effects) == 0 {
+		return
+	}
+	r.effect = r.effects[len(r.effects)-1]
+	r.effects = r.effects[:len(r.effects)-1]
+}
+
 // DrawRect draws a filled rectangle.
 func (r *Renderer) DrawRect(x, y, width, height float32, color Color) {
 	r.addQuad(x, y, width, height, color)
@@ -301,6 +352,7 @@ func (r *Renderer) DrawPanel(x, y, width, height float32, bg, border Color) {
 func (r *Renderer) addQuad(x, y, w, h float32, c Color) {
 	// Two triangles forming a quad
 	// Vertex format: x, y, z, r, g, b, a (7 floats)
+	x, y, w, h, c = r.effect.apply(x, y, w, h, c)
 
 	// Triangle 1
 	r.solidVertices = append(r.solidVertices,
@@ -320,6 +372,7 @@ func (r *Renderer) addQuad(x, y, w, h float32, c Color) {
 func (r *Renderer) addTexturedQuad(x, y, w, h float32, u0, v0, u1, v1 float32, c Color) {
 	// Two triangles forming a quad
 	// Vertex format: x, y, z, u, v, r, g, b, a (9 floats)
+	x, y, w, h, c = r.effect.apply(x, y, w, h, c)
 
 	// Triangle 1
 	r.textVertices = append(r.textVertices,
@@ -387,14 +440,8 @@ func (r *Renderer) DrawSceneTexture(x, y, w, h float32, textureID uint32) {
 		return
 	}
 
-	// Save state
-	var prevBlend, prevDepth int32
-	gl.GetIntegerv(gl.BLEND, &prevBlend)
-	gl.GetIntegerv(gl.DEPTH_TEST, &prevDepth)
-
-	// Setup state for 2D rendering
-	gl.Disable(gl.BLEND) // No blending for scene - it's opaque
-	gl.Disable(gl.DEPTH_TEST)
+	// 2D without blending: the scene is opaque
+	defer glstate.Push(glstate.Overlay.With(func(s *glstate.State) { s.Blend = false }))()
 
 	// Use scene shader (full RGBA sampling)
 	gl.UseProgram(r.sceneShader)
@@ -436,13 +483,6 @@ func (r *Renderer) DrawSceneTexture(x, y, w, h float32, textureID uint32) {
 	gl.BindVertexArray(0)
 	gl.BindTexture(gl.TEXTURE_2D, 0)
 	gl.UseProgram(0)
-
-	if prevBlend == gl.TRUE {
-		gl.Enable(gl.BLEND)
-	}
-	if prevDepth == gl.TRUE {
-		gl.Enable(gl.DEPTH_TEST)
-	}
 }
 
 // orthoMatrix creates an orthographic projection matrix.
@@ -733,6 +773,7 @@ func (r *Renderer) DrawImageUV(texID uint32, x, y, w, h, u0, v0, u1, v1 float32,
 // addImageQuad adds a textured quad to the image vertex buffer.
 func (r *Renderer) addImageQuad(x, y, w, h, u0, v0, u1, v1 float32, c Color) {
 	// Same vertex format as text: pos(3) + uv(2) + color(4) = 9 floats
+	x, y, w, h, c = r.effect.apply(x, y, w, h, c)
 	r.imageVertices = append(r.imageVertices,
 		x, y, 0, u0, v0, c.R, c.G, c.B, c.A,
 		x+w, y, 0, u1, v0, c.R, c.G, c.B, c.A,
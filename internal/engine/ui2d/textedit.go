@@ -0,0 +1,135 @@
+package ui2d
+
+// TextEdit is the editing state of a single-line text input: its text,
+// the cursor and the selection. Positions count runes, so the cursor never
+// lands inside a multi-byte character.
+//
+// The selection runs between the anchor and the cursor; it's empty when
+// they're equal.
+type TextEdit struct {
+	text   []rune
+	cursor int
+	anchor int
+}
+
+// SetText replaces the text if it differs, putting the cursor at the end.
+// Inputs call it with the caller's value each frame, so the text set by
+// the caller wins over the edit's own.
+func (e *TextEdit) SetText(text string) {
+	if text == string(e.text) {
+		return
+	}
+	e.text = []rune(text)
+	e.cursor = len(e.text)
+	e.anchor = e.cursor
+}
+
+// Text returns the text.
+func (e *TextEdit) Text() string {
+	return string(e.text)
+}
+
+// Cursor returns the cursor position.
+func (e *TextEdit) Cursor() int {
+	return e.cursor
+}
+
+// Selection returns the selected range, start first.
+func (e *TextEdit) Selection() (start, end int) {
+	return min(e.anchor, e.cursor), max(e.anchor, e.cursor)
+}
+
+// HasSelection reports whether any text is selected.
+func (e *TextEdit) HasSelection() bool {
+	return e.anchor != e.cursor
+}
+
+// SelectedText returns the selected text.
+func (e *TextEdit) SelectedText() string {
+	start, end := e.Selection()
+	return string(e.text[start:end])
+}
+
+// SelectAll selects the whole text.
+func (e *TextEdit) SelectAll() {
+	e.anchor, e.cursor = 0, len(e.text)
+}
+
+// SetCursor moves the cursor to pos. With extend the selection grows or
+// shrinks to it, as with Shift held or a mouse drag; otherwise it's
+// cleared.
+func (e *TextEdit) SetCursor(pos int, extend bool) {
+	e.cursor = max(0, min(pos, len(e.text)))
+	if !extend {
+		e.anchor = e.cursor
+	}
+}
+
+// Move moves the cursor by delta runes. Without extend, moving off a
+// selection collapses it to the side moved toward.
+func (e *TextEdit) Move(delta int, extend bool) {
+	if !extend && e.HasSelection() {
+		start, end := e.Selection()
+		if delta < 0 {
+			e.SetCursor(start, false)
+		} else {
+			e.SetCursor(end, false)
+		}
+		return
+	}
+	e.SetCursor(e.cursor+delta, extend)
+}
+
+// Insert replaces the selection with s, or types it at the cursor.
+func (e *TextEdit) Insert(s string) {
+	start, end := e.Selection()
+	ins := []rune(s)
+	text := make([]rune, 0, len(e.text)-(end-start)+len(ins))
+	text = append(text, e.text[:start]...)
+	text = append(text, ins...)
+	e.text = append(text, e.text[end:]...)
+	e.SetCursor(start+len(ins), false)
+}
+
+// Backspace erases the selection, or the rune before the cursor.
+func (e *TextEdit) Backspace() {
+	if !e.HasSelection() {
+		if e.cursor == 0 {
+			return
+		}
+		e.anchor = e.cursor - 1
+	}
+	e.Insert("")
+}
+
+// Delete erases the selection, or the rune after the cursor.
+func (e *TextEdit) Delete() {
+	if !e.HasSelection() {
+		if e.cursor == len(e.text) {
+			return
+		}
+		e.anchor = e.cursor + 1
+	}
+	e.Insert("")
+}
+
+// Cut removes the selected text and returns it.
+func (e *TextEdit) Cut() string {
+	s := e.SelectedText()
+	e.Insert("")
+	return s
+}
+
+// caretIndex returns the rune position in text nearest to x, measured
+// from the text's left edge with measure.
+func caretIndex(text []rune, x float32, measure func(string) float32) int {
+	prev := float32(0)
+	for i := range text {
+		w := measure(string(text[:i+1]))
+		if x < (prev+w)/2 {
+			return i
+		}
+		prev = w
+	}
+	return len(text)
+}
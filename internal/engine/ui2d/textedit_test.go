@@ -0,0 +1,72 @@
+package ui2d
+
+import "testing"
+
+func TestTextEdit(t *testing.T) {
+	var e TextEdit
+	e.SetText("héllo")
+	if e.Cursor() != 5 {
+		t.Fatalf("Cursor() = %d after SetText, want 5", e.Cursor())
+	}
+
+	steps := []struct {
+		name string
+		do   func()
+		want string
+		sel  string
+	}{
+		{"select back", func() { e.Move(-1, true); e.Move(-1, true) }, "héllo", "lo"},
+		{"type over", func() { e.Insert("p") }, "hélp", ""},
+		{"backspace", func() { e.Backspace() }, "hél", ""},
+		{"home", func() { e.SetCursor(0, false); e.Insert(">") }, ">hél", ""},
+		{"delete", func() { e.Delete() }, ">él", ""},
+		{"select word", func() { e.SetCursor(1, false); e.SetCursor(3, true) }, ">él", "él"},
+		{"collapse left", func() { e.Move(-1, false); e.Insert("[") }, ">[él", ""},
+		{"select all", func() { e.SelectAll() }, ">[él", ">[él"},
+		{"cut", func() {
+			if got := e.Cut(); got != ">[él" {
+				t.Errorf("Cut() = %q", got)
+			}
+		}, "", ""},
+		{"empty erase", func() { e.Backspace(); e.Delete() }, "", ""},
+	}
+	for _, s := range steps {
+		s.do()
+		if got := e.Text(); got != s.want {
+			t.Errorf("%s: Text() = %q, want %q", s.name, got, s.want)
+		}
+		if got := e.SelectedText(); got != s.sel {
+			t.Errorf("%s: SelectedText() = %q, want %q", s.name, got, s.sel)
+		}
+	}
+
+	// The caller's value replaces the text, unchanged values keep the cursor
+	e.SetText("abc")
+	e.SetCursor(1, false)
+	e.SetText("abc")
+	if e.Cursor() != 1 {
+		t.Errorf("SetText with the same text moved the cursor to %d", e.Cursor())
+	}
+}
+
+func TestCaretIndex(t *testing.T) {
+	// Every rune is 10 wide
+	measure := func(s string) float32 { return float32(len([]rune(s))) * 10 }
+	text := []rune("abc")
+	tests := []struct {
+		x    float32
+		want int
+	}{
+		{-5, 0},
+		{4, 0},
+		{6, 1},
+		{24, 2},
+		{26, 3},
+		{100, 3},
+	}
+	for _, tt := range tests {
+		if got := caretIndex(text, tt.x, measure); got != tt.want {
+			t.Errorf("caretIndex(%v) = %d, want %d", tt.x, got, tt.want)
+		}
+	}
+}
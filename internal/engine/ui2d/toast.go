@@ -0,0 +1,84 @@
+package ui2d
+
+import "time"
+
+// Toast timing: toasts slide up and fade in, stay a while, then fade out.
+const (
+	ToastSlideIn = 200 * time.Millisecond
+	ToastHold    = 2400 * time.Millisecond
+	ToastFadeOut = 400 * time.Millisecond
+)
+
+// Toast layout, in UI units.
+const (
+	maxToasts       = 4
+	toastPadding    = 5
+	toastGap        = 4
+	toastSlide      = 16 // How far toasts slide up as they appear
+	toastBottomEdge = 60 // Distance of the newest toast from the bottom
+)
+
+// toast is a notification shown at the bottom of the screen for a while.
+type toast struct {
+	text  string
+	color Color
+	anim  Sequence // Visibility: 0 hidden, 1 fully shown
+}
+
+// Toast shows a short notification above the bottom of the screen. It
+// fades out on its own; the oldest toast makes way once more than
+// maxToasts are shown.
+func (c *Context) Toast(text string, color Color) {
+	if len(c.toasts) == maxToasts {
+		c.toasts = c.toasts[1:]
+	}
+	c.toasts = append(c.toasts, toast{
+		text:  text,
+		color: color,
+		anim: Chain(
+			NewTween(0, 1, ToastSlideIn, EaseOutCubic),
+			Hold(1, ToastHold),
+			NewTween(1, 0, ToastFadeOut, EaseInQuad),
+		),
+	})
+}
+
+// updateToasts advances the toasts by dt, dropping the finished ones.
+func (c *Context) updateToasts(dt time.Duration) {
+	kept := c.toasts[:0]
+	for _, t := range c.toasts {
+		t.anim.Update(dt)
+		if !t.anim.Done() {
+			kept = append(kept, t)
+		}
+	}
+	c.toasts = kept
+}
+
+// renderToasts draws the toasts centered at the bottom of the screen,
+// newest at the bottom.
+func (c *Context) renderToasts() {
+	c.updateToasts(c.dt)
+	if len(c.toasts) == 0 {
+		return
+	}
+	screenW, screenH := c.GetScreenSize()
+	y := screenH - toastBottomEdge
+	for i := len(c.toasts) - 1; i >= 0; i-- {
+		t := &c.toasts[i]
+		v := clamp01(t.anim.Value())
+		textW, textH := c.renderer.MeasureText(t.text, 1)
+		w, h := textW+toastPadding*4, textH+toastPadding*2
+		x := (screenW - w) / 2
+
+		slide := float32(0)
+		if t.anim.Step() == 0 {
+			slide = (1 - v) * toastSlide
+		}
+		c.renderer.PushEffect(Effect{Scale: 1, OffsetY: slide, Alpha: v})
+		c.renderer.DrawRect(x, y, w, h, ColorPanelBg)
+		c.renderer.DrawText(x+toastPadding*2, y+toastPadding, t.text, 1, t.color)
+		c.renderer.PopEffect()
+		y -= h + toastGap
+	}
+}
@@ -0,0 +1,276 @@
+package ui2d
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultTooltipDelay is how long the mouse must rest on a widget before its
+// tooltip appears.
+const DefaultTooltipDelay = 500 * time.Millisecond
+
+// Tooltip layout, in UI units.
+const (
+	tooltipPadding    = 6
+	tooltipLineGap    = 2
+	tooltipIconGap    = 6
+	tooltipOffsetX    = 16 // Keeps the box clear of the cursor
+	tooltipOffsetY    = 20
+	tooltipEdgeMargin = 4
+)
+
+// TooltipSpan is a run of text in one color.
+type TooltipSpan struct {
+	Text  string
+	Color Color
+}
+
+// TooltipLine is one row of a tooltip: an optional icon followed by text spans.
+type TooltipLine struct {
+	Icon         uint32 // Texture ID, 0 for none
+	IconW, IconH float32
+	Spans        []TooltipSpan
+}
+
+// Tooltip is the content shown while hovering a widget. Build it with
+// NewTooltip and the chained helpers, or fill Lines directly. Custom adds
+// arbitrary drawing in a CustomW x CustomH area below the lines.
+type Tooltip struct {
+	Lines []TooltipLine
+
+	Custom           func(r *Renderer, x, y float32)
+	CustomW, CustomH float32
+}
+
+// TooltipProvider builds tooltip content. It is only called once the hover
+// delay has elapsed, so expensive lookups (item descriptions) stay off the
+// common path. Returning nil shows nothing.
+type TooltipProvider func() *Tooltip
+
+// NewTooltip creates an empty tooltip.
+func NewTooltip() *Tooltip {
+	return &Tooltip{}
+}
+
+// Title adds a highlighted heading line.
+func (t *Tooltip) Title(text string) *Tooltip {
+	return t.Text(text, ColorHighlight)
+}
+
+// Text adds a line of text.
+func (t *Tooltip) Text(text string, color Color) *Tooltip {
+	t.Lines = append(t.Lines, TooltipLine{Spans: []TooltipSpan{{text, color}}})
+	return t
+}
+
+// IconText adds a line starting with an icon.
+func (t *Tooltip) IconText(icon uint32, w, h float32, text string, color Color) *Tooltip {
+	t.Lines = append(t.Lines, TooltipLine{
+		Icon:  icon,
+		IconW: w,
+		IconH: h,
+		Spans: []TooltipSpan{{text, color}},
+	})
+	return t
+}
+
+// Append adds a differently colored span to the last line, e.g. a value
+// after its label.
+func (t *Tooltip) Append(text string, color Color) *Tooltip {
+	if len(t.Lines) == 0 {
+		return t.Text(text, color)
+	}
+	last := &t.Lines[len(t.Lines)-1]
+	last.Spans = append(last.Spans, TooltipSpan{text, color})
+	return t
+}
+
+// Content sets custom drawing below the text lines.
+func (t *Tooltip) Content(w, h float32, draw func(r *Renderer, x, y float32)) *Tooltip {
+	t.Custom = draw
+	t.CustomW, t.CustomH = w, h
+	return t
+}
+
+// measureFunc returns the size of a text run.
+type measureFunc func(text string) (w, h float32)
+
+// lineSize returns the size of one line, including its icon.
+func (l TooltipLine) size(measure measureFunc) (w, h float32) {
+	if l.Icon != 0 {
+		w = l.IconW + tooltipIconGap
+		h = l.IconH
+	}
+	for _, span := range l.Spans {
+		sw, sh := measure(span.Text)
+		w += sw
+		h = max(h, sh)
+	}
+	return w, h
+}
+
+// size returns the tooltip's outer size, padding included.
+func (t *Tooltip) size(measure measureFunc) (w, h float32) {
+	for i, line := range t.Lines {
+		lw, lh := line.size(measure)
+		w = max(w, lw)
+		h += lh
+		if i > 0 {
+			h += tooltipLineGap
+		}
+	}
+	if t.Custom != nil {
+		w = max(w, t.CustomW)
+		if len(t.Lines) > 0 {
+			h += tooltipLineGap
+		}
+		h += t.CustomH
+	}
+	return w + tooltipPadding*2, h + tooltipPadding*2
+}
+
+// draw renders the tooltip with its top-left corner at x, y.
+func (t *Tooltip) draw(r *Renderer, x, y, w, h float32) {
+	measure := func(text string) (float32, float32) { return r.MeasureText(text, 1) }
+
+	r.DrawPanel(x, y, w, h, ColorTooltipBg, ColorTooltipBorder)
+
+	cy := y + tooltipPadding
+	for i, line := range t.Lines {
+		if i > 0 {
+			cy += tooltipLineGap
+		}
+		_, lh := line.size(measure)
+		cx := x + tooltipPadding
+		if line.Icon != 0 {
+			r.DrawImage(line.Icon, cx, cy+(lh-line.IconH)/2, line.IconW, line.IconH, ColorWhite)
+			cx += line.IconW + tooltipIconGap
+		}
+		for _, span := range line.Spans {
+			sw, sh := measure(span.Text)
+			r.DrawText(cx, cy+(lh-sh)/2, span.Text, 1, span.Color)
+			cx += sw
+		}
+		cy += lh
+	}
+
+	if t.Custom != nil {
+		if len(t.Lines) > 0 {
+			cy += tooltipLineGap
+		}
+		t.Custom(r, x+tooltipPadding, cy)
+	}
+}
+
+// PlaceTooltip positions a w x h tooltip for a cursor at mouseX, mouseY:
+// below-right of the cursor by default, flipped to the other side on an axis
+// where it would leave the screen, and clamped when it fits neither way.
+func PlaceTooltip(mouseX, mouseY, w, h, screenW, screenH float32) (x, y float32) {
+	x = mouseX + tooltipOffsetX
+	if x+w > screenW-tooltipEdgeMargin {
+		x = mouseX - tooltipOffsetX/2 - w
+	}
+	y = mouseY + tooltipOffsetY
+	if y+h > screenH-tooltipEdgeMargin {
+		y = mouseY - tooltipOffsetY/2 - h
+	}
+
+	x = max(tooltipEdgeMargin, min(x, screenW-tooltipEdgeMargin-w))
+	y = max(tooltipEdgeMargin, min(y, screenH-tooltipEdgeMargin-h))
+	return x, y
+}
+
+// tooltipState tracks which widget is hovered and for how long.
+type tooltipState struct {
+	delay time.Duration
+
+	hoverID    string
+	hoverStart time.Time
+	suppressed bool // Clicked while hovering; hidden until the mouse leaves
+
+	// Registered during the current frame; the last one under the mouse wins
+	// since later widgets are drawn on top.
+	candidateID string
+	candidate   TooltipProvider
+}
+
+// offer registers a hovered widget's tooltip for this frame.
+func (s *tooltipState) offer(id string, provider TooltipProvider) {
+	s.candidateID = id
+	s.candidate = provider
+}
+
+// resolve ends the frame's hover tracking and returns the provider to show,
+// if the hovered widget has been stable for the delay.
+func (s *tooltipState) resolve(now time.Time, pressed bool) TooltipProvider {
+	id, provider := s.candidateID, s.candidate
+	s.candidateID, s.candidate = "", nil
+
+	if id != s.hoverID {
+		s.hoverID = id
+		s.hoverStart = now
+		s.suppressed = false
+	}
+	if id == "" || provider == nil {
+		return nil
+	}
+	if pressed {
+		s.suppressed = true
+	}
+	if s.suppressed || now.Sub(s.hoverStart) < s.delay {
+		return nil
+	}
+	return provider
+}
+
+// SetTooltipDelay sets the hover delay before tooltips appear.
+func (c *Context) SetTooltipDelay(d time.Duration) {
+	c.tooltips.delay = d
+}
+
+// TooltipRegion attaches a tooltip to an arbitrary area in UI units, for
+// elements drawn directly with the renderer (minimap markers, HUD icons).
+func (c *Context) TooltipRegion(id string, x, y, w, h float32, provider TooltipProvider) {
+	if (Rect{x, y, w, h}).Contains(c.input.MouseX, c.input.MouseY) {
+		c.tooltips.offer(id, provider)
+	}
+}
+
+// ItemTooltip attaches a tooltip to the most recently drawn widget.
+func (c *Context) ItemTooltip(provider TooltipProvider) {
+	if c.lastItemID == "" {
+		return
+	}
+	c.TooltipRegion(c.lastItemID, c.lastItem.X, c.lastItem.Y, c.lastItem.W, c.lastItem.H, provider)
+}
+
+// setLastItem records a widget's bounds for ItemTooltip. Widgets without an
+// ID are keyed by position.
+func (c *Context) setLastItem(id string, rect Rect) {
+	if id == "" {
+		id = fmt.Sprintf("%s@%.0f,%.0f", c.currentWindow.ID, rect.X, rect.Y)
+	}
+	c.lastItemID = id
+	c.lastItem = rect
+}
+
+// renderTooltip draws the active tooltip on top of everything else drawn
+// this frame.
+func (c *Context) renderTooltip() {
+	provider := c.tooltips.resolve(c.now(), c.input.MouseLeftDown || c.input.MouseRightDown)
+	if provider == nil {
+		return
+	}
+	t := provider()
+	if t == nil {
+		return
+	}
+
+	w, h := t.size(func(text string) (float32, float32) { return c.renderer.MeasureText(text, 1) })
+	screenW, screenH := c.GetScreenSize()
+	x, y := PlaceTooltip(c.input.MouseX, c.input.MouseY, w, h, screenW, screenH)
+
+	// Draw what's queued so far, so the tooltip's background covers text
+	c.renderer.Flush()
+	t.draw(c.renderer, x, y, w, h)
+}
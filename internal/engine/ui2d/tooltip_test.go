@@ -0,0 +1,98 @@
+package ui2d
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPlaceTooltip(t *testing.T) {
+	const screenW, screenH = 800, 600
+
+	tests := []struct {
+		name         string
+		mouseX       float32
+		mouseY       float32
+		w, h         float32
+		wantX, wantY float32
+	}{
+		{"below right", 100, 100, 200, 50, 116, 120},
+		{"flips left at right edge", 700, 100, 200, 50, 492, 120},
+		{"flips above at bottom edge", 100, 580, 200, 50, 116, 520},
+		{"clamped when wider than either side", 400, 100, 780, 50, tooltipEdgeMargin, 120},
+		{"clamped to top-left", 5, 5, 900, 700, tooltipEdgeMargin, tooltipEdgeMargin},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, y := PlaceTooltip(tt.mouseX, tt.mouseY, tt.w, tt.h, screenW, screenH)
+			if x != tt.wantX || y != tt.wantY {
+				t.Errorf("PlaceTooltip = (%v, %v), want (%v, %v)", x, y, tt.wantX, tt.wantY)
+			}
+		})
+	}
+}
+
+func TestTooltipSize(t *testing.T) {
+	// 8 units per character, 16 high
+	measure := func(text string) (float32, float32) { return float32(len(text)) * 8, 16 }
+
+	tip := NewTooltip().
+		Title("Red Potion").
+		IconText(1, 24, 24, "Heals", ColorTextOnDark).
+		Append(" 45 HP", ColorGreen).
+		Content(200, 30, func(*Renderer, float32, float32) {})
+
+	w, h := tip.size(measure)
+
+	// Widest part is the custom content; lines are 16 + 24 tall plus gaps
+	wantW := float32(200 + tooltipPadding*2)
+	wantH := float32(16 + tooltipLineGap + 24 + tooltipLineGap + 30 + tooltipPadding*2)
+	if w != wantW || h != wantH {
+		t.Errorf("size = (%v, %v), want (%v, %v)", w, h, wantW, wantH)
+	}
+
+	if got := len(tip.Lines[1].Spans); got != 2 {
+		t.Errorf("Append produced %d spans, want 2", got)
+	}
+}
+
+func TestTooltipHoverDelay(t *testing.T) {
+	s := tooltipState{delay: 500 * time.Millisecond}
+	provider := func() *Tooltip { return NewTooltip().Title("x") }
+	start := time.Unix(1000, 0)
+
+	frame := func(id string, at time.Duration, pressed bool) bool {
+		if id != "" {
+			s.offer(id, provider)
+		}
+		return s.resolve(start.Add(at), pressed) != nil
+	}
+
+	if frame("a", 0, false) {
+		t.Error("shown immediately, want delay")
+	}
+	if frame("a", 400*time.Millisecond, false) {
+		t.Error("shown before delay elapsed")
+	}
+	if !frame("a", 500*time.Millisecond, false) {
+		t.Error("not shown after delay")
+	}
+
+	// Moving to another widget restarts the delay
+	if frame("b", 600*time.Millisecond, false) {
+		t.Error("shown immediately after switching widgets")
+	}
+
+	// Clicking hides it until the mouse leaves
+	if frame("b", 1200*time.Millisecond, true) {
+		t.Error("shown while clicking")
+	}
+	if frame("b", 1300*time.Millisecond, false) {
+		t.Error("shown again after click without leaving")
+	}
+	frame("", 1400*time.Millisecond, false)
+	frame("b", 1500*time.Millisecond, false)
+	if !frame("b", 2000*time.Millisecond, false) {
+		t.Error("not shown after leaving and hovering again")
+	}
+}
@@ -0,0 +1,156 @@
+package ui2d
+
+import (
+	"math"
+	"time"
+)
+
+// Ease maps linear progress (0-1) to eased progress. Eases start at 0 and
+// end at 1, but may overshoot in between.
+type Ease func(t float32) float32
+
+// Ease functions.
+var (
+	EaseLinear    Ease = func(t float32) float32 { return t }
+	EaseInQuad    Ease = func(t float32) float32 { return t * t }
+	EaseOutQuad   Ease = func(t float32) float32 { return t * (2 - t) }
+	EaseInOutQuad Ease = func(t float32) float32 {
+		if t < 0.5 {
+			return 2 * t * t
+		}
+		return -1 + (4-2*t)*t
+	}
+	EaseOutCubic Ease = func(t float32) float32 {
+		t--
+		return t*t*t + 1
+	}
+	// EaseOutBack overshoots the end a little and settles back, for a
+	// springy pop.
+	EaseOutBack Ease = func(t float32) float32 {
+		const c1 = 1.70158
+		const c3 = c1 + 1
+		t--
+		return 1 + c3*t*t*t + c1*t*t
+	}
+)
+
+// Tween animates a value from From to To over Duration, shaped by Ease
+// (linear if nil). Advance it with Update each frame.
+type Tween struct {
+	From, To float32
+	Duration time.Duration
+	Ease     Ease
+	elapsed  time.Duration
+}
+
+// NewTween creates a tween from from to to.
+func NewTween(from, to float32, d time.Duration, ease Ease) Tween {
+	return Tween{From: from, To: to, Duration: d, Ease: ease}
+}
+
+// Update advances the tween by dt.
+func (t *Tween) Update(dt time.Duration) {
+	t.advance(dt)
+}
+
+// advance advances the tween by dt and returns the time left over past
+// its end.
+func (t *Tween) advance(dt time.Duration) time.Duration {
+	t.elapsed += dt
+	if t.elapsed <= t.Duration {
+		return 0
+	}
+	over := t.elapsed - t.Duration
+	t.elapsed = t.Duration
+	return over
+}
+
+// Progress returns the eased progress: 0 at the start, 1 at the end.
+func (t *Tween) Progress() float32 {
+	p := float32(1)
+	if t.Duration > 0 {
+		p = float32(t.elapsed) / float32(t.Duration)
+	}
+	if t.Ease == nil {
+		return p
+	}
+	return t.Ease(p)
+}
+
+// Value returns the tween's current value.
+func (t *Tween) Value() float32 {
+	return t.From + (t.To-t.From)*t.Progress()
+}
+
+// Done reports whether the tween has reached its end.
+func (t *Tween) Done() bool {
+	return t.elapsed >= t.Duration
+}
+
+// Reset rewinds the tween to its start.
+func (t *Tween) Reset() {
+	t.elapsed = 0
+}
+
+// Sequence chains tweens, each starting where the previous one ends, such
+// as a toast sliding in, holding and fading out.
+type Sequence struct {
+	Steps []Tween
+	step  int
+}
+
+// Chain creates a sequence of steps.
+func Chain(steps ...Tween) Sequence {
+	return Sequence{Steps: steps}
+}
+
+// Update advances the sequence by dt, carrying time left over at the end
+// of one step into the next.
+func (s *Sequence) Update(dt time.Duration) {
+	for s.step < len(s.Steps) {
+		dt = s.Steps[s.step].advance(dt)
+		if !s.Steps[s.step].Done() {
+			return
+		}
+		if s.step == len(s.Steps)-1 {
+			return
+		}
+		s.step++
+		if dt == 0 {
+			return
+		}
+	}
+}
+
+// Step returns the index of the running step.
+func (s *Sequence) Step() int {
+	return s.step
+}
+
+// Value returns the running step's value, or 0 for an empty sequence.
+func (s *Sequence) Value() float32 {
+	if len(s.Steps) == 0 {
+		return 0
+	}
+	return s.Steps[s.step].Value()
+}
+
+// Done reports whether every step has finished.
+func (s *Sequence) Done() bool {
+	return len(s.Steps) == 0 || (s.step == len(s.Steps)-1 && s.Steps[s.step].Done())
+}
+
+// Hold returns a step that keeps value for d, for pauses in a sequence.
+func Hold(value float32, d time.Duration) Tween {
+	return Tween{From: value, To: value, Duration: d}
+}
+
+// lerp interpolates between a and b.
+func lerp(a, b, t float32) float32 {
+	return a + (b-a)*t
+}
+
+// clamp01 limits v to [0, 1].
+func clamp01(v float32) float32 {
+	return float32(math.Max(0, math.Min(1, float64(v))))
+}
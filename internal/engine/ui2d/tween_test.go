@@ -0,0 +1,225 @@
+package ui2d
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func approx(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-4
+}
+
+func TestEaseEndpoints(t *testing.T) {
+	eases := map[string]Ease{
+		"linear":    EaseLinear,
+		"inQuad":    EaseInQuad,
+		"outQuad":   EaseOutQuad,
+		"inOutQuad": EaseInOutQuad,
+		"outCubic":  EaseOutCubic,
+		"outBack":   EaseOutBack,
+	}
+	for name, ease := range eases {
+		if got := ease(0); !approx(got, 0) {
+			t.Errorf("%s(0) = %v, want 0", name, got)
+		}
+		if got := ease(1); !approx(got, 1) {
+			t.Errorf("%s(1) = %v, want 1", name, got)
+		}
+	}
+	if EaseOutBack(0.8) <= 1 {
+		t.Error("EaseOutBack should overshoot before settling")
+	}
+	if EaseOutCubic(0.5) <= 0.5 || EaseInQuad(0.5) >= 0.5 {
+		t.Error("ease out should lead linear, ease in trail it")
+	}
+}
+
+func TestTween(t *testing.T) {
+	tw := NewTween(10, 20, 100*time.Millisecond, nil)
+	if tw.Value() != 10 || tw.Done() {
+		t.Fatalf("new tween: value %v done %v", tw.Value(), tw.Done())
+	}
+	tw.Update(50 * time.Millisecond)
+	if !approx(tw.Value(), 15) {
+		t.Errorf("halfway value = %v, want 15", tw.Value())
+	}
+	tw.Update(time.Second)
+	if tw.Value() != 20 || !tw.Done() {
+		t.Errorf("finished tween: value %v done %v", tw.Value(), tw.Done())
+	}
+	tw.Reset()
+	if tw.Value() != 10 {
+		t.Errorf("reset value = %v, want 10", tw.Value())
+	}
+
+	instant := NewTween(0, 1, 0, EaseOutCubic)
+	if instant.Value() != 1 || !instant.Done() {
+		t.Error("zero-length tween should be at its end")
+	}
+}
+
+func TestSequence(t *testing.T) {
+	s := Chain(
+		NewTween(0, 1, 100*time.Millisecond, nil),
+		Hold(1, 200*time.Millisecond),
+		NewTween(1, 0, 100*time.Millisecond, nil),
+	)
+
+	tests := []struct {
+		dt    time.Duration
+		step  int
+		value float32
+		done  bool
+	}{
+		{50 * time.Millisecond, 0, 0.5, false},
+		{100 * time.Millisecond, 1, 1, false}, // 50ms carried into the hold
+		{200 * time.Millisecond, 2, 0.5, false},
+		{100 * time.Millisecond, 2, 0, true},
+		{time.Second, 2, 0, true},
+	}
+	for i, tt := range tests {
+		s.Update(tt.dt)
+		if s.Step() != tt.step || !approx(s.Value(), tt.value) || s.Done() != tt.done {
+			t.Errorf("update %d: step %d value %v done %v, want %d %v %v",
+				i, s.Step(), s.Value(), s.Done(), tt.step, tt.value, tt.done)
+		}
+	}
+
+	var empty Sequence
+	empty.Update(time.Second)
+	if !empty.Done() || empty.Value() != 0 {
+		t.Error("empty sequence should be done")
+	}
+}
+
+func TestRendererEffects(t *testing.T) {
+	r := &Renderer{}
+	r.Begin()
+
+	r.PushEffect(Effect{OriginX: 100, OriginY: 100, Scale: 0.5, Alpha: 0.5})
+	r.PushEffect(Effect{Scale: 1, OffsetX: 10, Alpha: 0.5})
+	r.DrawRect(100, 100, 20, 20, ColorWhite)
+	r.PopEffect()
+	r.PopEffect()
+	r.PopEffect() // Unbalanced pops are ignored
+	r.DrawRect(0, 0, 10, 10, ColorWhite)
+
+	// First vertex of each quad: x, y, z, r, g, b, a
+	first := r.solidVertices[:7]
+	if !approx(first[0], 105) || !approx(first[1], 100) || !approx(first[6], 0.25) {
+		t.Errorf("effect vertex = %v, want (105, 100) alpha 0.25", first)
+	}
+	// Second corner: the 20-unit quad halved
+	if !approx(r.solidVertices[7], 115) {
+		t.Errorf("effect quad right edge = %v, want 115", r.solidVertices[7])
+	}
+	plain := r.solidVertices[42:49]
+	if plain[0] != 0 || plain[1] != 0 || plain[6] != 1 {
+		t.Errorf("vertex after popping = %v, want untransformed", plain)
+	}
+}
+
+// fakeClock steps a context's clock by a fixed frame time.
+type fakeClock struct {
+	t time.Time
+}
+
+func (f *fakeClock) now() time.Time {
+	return f.t
+}
+
+func newAnimContext() (*Context, *fakeClock) {
+	clock := &fakeClock{t: time.Unix(1000, 0)}
+	return &Context{
+		renderer: &Renderer{},
+		input:    &InputState{},
+		windows:  make(map[string]*WindowState),
+		now:      clock.now,
+		scale:    1,
+	}, clock
+}
+
+// frame runs one UI frame, drawing the window if draw is set.
+func frame(c *Context, clock *fakeClock, draw bool) {
+	clock.t = clock.t.Add(50 * time.Millisecond)
+	c.Begin()
+	if draw && c.BeginWindow("inv", 10, 10, 100, 100, "Inventory") {
+		c.EndWindow()
+	}
+	c.renderClosingWindows()
+	c.input.EndFrame()
+}
+
+func TestWindowAnimation(t *testing.T) {
+	c, clock := newAnimContext()
+
+	frame(c, clock, true)
+	ws := c.windows["inv"]
+	if ws.anim.Done() || ws.anim.Value() != 0 {
+		t.Fatalf("new window should start opening, value %v", ws.anim.Value())
+	}
+	for range 5 {
+		frame(c, clock, true)
+	}
+	if !ws.anim.Done() || ws.anim.Value() != 1 {
+		t.Fatalf("window should be open, value %v", ws.anim.Value())
+	}
+
+	// One skipped frame neither closes nor reopens the window
+	frame(c, clock, false)
+	if ws.closing {
+		t.Fatal("window closing after a single skipped frame")
+	}
+	frame(c, clock, true)
+	if !ws.anim.Done() {
+		t.Fatal("window reopening after a single skipped frame")
+	}
+
+	// Closing: the frame fades out after the window is gone
+	frame(c, clock, false)
+	frame(c, clock, false)
+	if !ws.closing {
+		t.Fatal("window should be closing")
+	}
+	frame(c, clock, false)
+	mid := ws.anim.Value()
+	if mid <= 0 || mid >= 1 {
+		t.Errorf("closing value = %v, want between 0 and 1", mid)
+	}
+	for range 5 {
+		frame(c, clock, false)
+	}
+	if ws.closing {
+		t.Error("window should have finished closing")
+	}
+
+	// Reopening starts over
+	frame(c, clock, true)
+	if ws.anim.Value() != 0 {
+		t.Errorf("reopened window value = %v, want 0", ws.anim.Value())
+	}
+}
+
+func TestToasts(t *testing.T) {
+	c, _ := newAnimContext()
+	for i := range maxToasts + 1 {
+		c.Toast(string(rune('a'+i)), ColorWhite)
+	}
+	if len(c.toasts) != maxToasts || c.toasts[0].text != "b" {
+		t.Fatalf("toasts = %d, oldest %q; want %d, oldest dropped", len(c.toasts), c.toasts[0].text, maxToasts)
+	}
+
+	c.updateToasts(ToastSlideIn)
+	if v := c.toasts[0].anim.Value(); v != 1 {
+		t.Errorf("toast after sliding in = %v, want 1", v)
+	}
+	c.updateToasts(ToastHold + ToastFadeOut/2)
+	if v := c.toasts[0].anim.Value(); v <= 0 || v >= 1 {
+		t.Errorf("fading toast = %v, want between 0 and 1", v)
+	}
+	c.updateToasts(ToastFadeOut)
+	if len(c.toasts) != 0 {
+		t.Errorf("%d toasts left after fading out", len(c.toasts))
+	}
+}
@@ -0,0 +1,85 @@
+package game
+
+import (
+	"net"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"github.com/Faultbox/midgard-ro/internal/config"
+	"github.com/Faultbox/midgard-ro/internal/game/chat"
+	"github.com/Faultbox/midgard-ro/internal/game/settings"
+	"github.com/Faultbox/midgard-ro/internal/game/states"
+	"github.com/Faultbox/midgard-ro/internal/logger"
+)
+
+// accountSettings holds the settings store of the character in game.
+type accountSettings struct {
+	store *settings.Store // nil outside the game
+	char  string
+
+	// The account's nameplate rules, starting from the config file's
+	nameplates config.NameplatesConfig
+}
+
+// syncSettings loads the account's settings when a character enters the
+// game, restoring their window layout, chat tabs and the account's
+// nameplate rules, and saves them when the character leaves.
+func (g *Game) syncSettings() {
+	char := ""
+	inGame, ok := g.stateManager.Current().(*states.InGameState)
+	if ok {
+		char = g.charName
+	}
+	if char == g.settings.char {
+		return
+	}
+	g.saveSettings()
+	g.settings = accountSettings{}
+	if char == "" {
+		g.uiBackend.SetWindowLayout(nil)
+		return
+	}
+
+	login := g.stateManager.LoginConfig
+	server := net.JoinHostPort(login.ServerHost, strconv.Itoa(login.ServerPort))
+	store, err := settings.Open(settings.Path(config.ConfigDir(), server, login.Username))
+	if err != nil {
+		logger.Warn("failed to load account settings", zap.Error(err))
+	}
+	if store == nil {
+		g.settings.char = char // Don't retry every frame
+		return
+	}
+	if store.ReadOnly() {
+		logger.Warn("account settings are from a newer client, changes won't be saved")
+	}
+	store.SelectCharacter(char)
+
+	g.settings = accountSettings{store: store, char: char, nameplates: g.config.Nameplates}
+	store.GetAccount(settings.KeyNameplates, &g.settings.nameplates)
+	var layout settings.Windows
+	store.Get(settings.KeyWindows, &layout)
+	g.uiBackend.SetWindowLayout(layout)
+	var tabs []chat.Tab
+	if store.Get(settings.KeyChatTabs, &tabs) {
+		inGame.ChatLog().SetTabs(tabs)
+	}
+}
+
+// saveSettings writes the window layout and any other changes of the
+// character in game to their account's settings file.
+func (g *Game) saveSettings() {
+	store := g.settings.store
+	if store == nil {
+		return
+	}
+	if layout := g.uiBackend.WindowLayout(); layout != nil {
+		if err := store.Set(settings.KeyWindows, layout); err != nil {
+			logger.Warn("failed to store window layout", zap.Error(err))
+		}
+	}
+	if err := store.Save(); err != nil {
+		logger.Warn("failed to save account settings", zap.Error(err))
+	}
+}
@@ -0,0 +1,119 @@
+package game
+
+import (
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/Faultbox/midgard-ro/internal/game/entity"
+	"github.com/Faultbox/midgard-ro/internal/game/states"
+	"github.com/Faultbox/midgard-ro/internal/game/ui"
+	"github.com/Faultbox/midgard-ro/internal/game/world"
+	"github.com/Faultbox/midgard-ro/internal/logger"
+)
+
+// areaMapView is the state of the area map window kept across frames.
+type areaMapView struct {
+	open     bool
+	worldMap bool   // Showing the world map
+	target   string // Town picked on the world map
+}
+
+// loadWarpTable loads the warp scripts the area map shows exits from. The
+// map works without them, showing only the warps in view.
+func loadWarpTable(paths []string) *world.WarpTable {
+	if len(paths) == 0 {
+		return nil
+	}
+	table, err := world.LoadWarpTable(paths)
+	if err != nil {
+		logger.Info("warp table unavailable, area map shows warps in view only", zap.Error(err))
+		return nil
+	}
+	logger.Info("loaded warp table", zap.Int("warps", table.Len()))
+	return table
+}
+
+// areaMapState builds the area map window, or nil if it's closed.
+func (g *Game) areaMapState(state *states.InGameState) *ui.AreaMapState {
+	if !g.areaMap.open {
+		return nil
+	}
+
+	mapName := strings.TrimSuffix(state.GetMapName(), ".gat")
+	m := &ui.AreaMapState{
+		MapName:  mapName,
+		WorldMap: g.areaMap.worldMap,
+		Target:   g.areaMap.target,
+		OnToggleWorld: func() {
+			g.areaMap.worldMap = !g.areaMap.worldMap
+		},
+		OnSelectTown: func(town string) {
+			g.areaMap.target = town
+		},
+		OnMove: func(tileX, tileY int) {
+			if err := state.RequestMove(tileX, tileY); err != nil {
+				logger.Warn("area map RequestMove failed", zap.Error(err))
+			}
+		},
+		OnClose: func() {
+			g.areaMap.open = false
+		},
+	}
+	if gat := state.GetGAT(); gat != nil {
+		m.Width, m.Height = int(gat.Width), int(gat.Height)
+	}
+	m.PlayerX, m.PlayerY = state.GetPlayerTilePosition()
+	if d, ok := state.Daylight(); ok {
+		m.Clock = d.Clock() + " Day"
+		if d.Night {
+			m.Clock = d.Clock() + " Night"
+		}
+	}
+
+	// Route to the picked town; its first warp is highlighted on the area map
+	var next string
+	if m.Target != "" && m.Target != mapName {
+		route := g.warps.Route(mapName, m.Target)
+		for _, w := range route {
+			m.Route = append(m.Route, w.DestMap)
+		}
+		if len(route) > 0 {
+			next = route[0].DestMap
+		}
+	}
+
+	for _, w := range g.warps.Exits(mapName) {
+		m.Exits = append(m.Exits, ui.AreaMapExit{X: w.X, Y: w.Y, DestMap: w.DestMap, OnRoute: w.DestMap == next})
+	}
+	fromTable := len(m.Exits) > 0
+	for _, e := range state.GetEntityManager().All() {
+		x, y := entityTile(e)
+		switch {
+		case e.Type == entity.TypeWarp && !fromTable:
+			// Warps in view stand in for a missing warp table
+			m.Exits = append(m.Exits, ui.AreaMapExit{X: x, Y: y})
+		case e.Type == entity.TypeNPC:
+			m.Markers = append(m.Markers, ui.AreaMapMarker{X: x, Y: y, Type: ui.MarkerTypeNPC, Label: e.Name})
+		case e.Type == entity.TypePlayer && e.InParty:
+			m.Markers = append(m.Markers, ui.AreaMapMarker{X: x, Y: y, Type: ui.MarkerTypeParty, Label: e.Name})
+		}
+	}
+
+	for _, town := range world.Towns {
+		m.Towns = append(m.Towns, ui.WorldMapTown{
+			Map:     town.Map,
+			Label:   town.Label,
+			X:       town.X,
+			Y:       town.Y,
+			Current: town.Map == mapName,
+		})
+	}
+	return m
+}
+
+// entityTile returns the tile an entity stands on.
+func entityTile(e *entity.Entity) (int, int) {
+	const tileSize = 5.0
+	return int(e.Position.X / tileSize), int(e.Position.Z / tileSize)
+}
@@ -0,0 +1,172 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	gomath "math"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Faultbox/midgard-ro/internal/config"
+	"github.com/Faultbox/midgard-ro/internal/engine/camera"
+	"github.com/Faultbox/midgard-ro/internal/engine/perf"
+	"github.com/Faultbox/midgard-ro/internal/engine/scene"
+	"github.com/Faultbox/midgard-ro/internal/logger"
+	"github.com/Faultbox/midgard-ro/pkg/formats"
+)
+
+const (
+	// benchWarmup is how many frames run before measuring starts, while
+	// textures upload and the driver compiles shaders.
+	benchWarmup = 60
+
+	// benchPathRadius is the camera path's radius as a fraction of the
+	// map's smaller side.
+	benchPathRadius = 0.3
+
+	// benchCameraDistance matches the in-game camera.
+	benchCameraDistance = 145
+)
+
+// RunBench renders a map along a scripted camera path without connecting
+// to a server, and reports frame times and GPU pass times. The path
+// depends only on the frame number, so runs on the same map are
+// comparable across commits.
+func (g *Game) RunBench(opts config.BenchOptions) (*perf.Report, error) {
+	if opts.Frames <= 0 {
+		return nil, errors.New("benchmark needs at least one frame")
+	}
+	mapName := strings.TrimSuffix(opts.Map, ".gat")
+
+	cfg := scene.DefaultConfig()
+	sc, err := scene.New(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("creating scene: %w", err)
+	}
+	defer sc.Destroy()
+	if err := g.loadBenchMap(sc, mapName); err != nil {
+		return nil, err
+	}
+
+	// Measure rendering, not the display's refresh rate
+	if err := g.imguiBackend.SetSwapInterval(0); err != nil {
+		logger.Warn("failed to turn off vsync, frame times are capped", zap.Error(err))
+	}
+	perf.Start()
+	defer perf.Stop()
+
+	cam := camera.NewThirdPersonCamera()
+	cam.Distance = benchCameraDistance
+
+	logger.Info("benchmark started", zap.String("map", mapName), zap.Int("frames", opts.Frames))
+	var rec perf.Recorder
+	total := benchWarmup + opts.Frames
+	frame := 0
+	last := time.Now()
+	g.imguiBackend.Run(func() {
+		if frame >= total {
+			return
+		}
+		now := time.Now()
+		measured := frame - benchWarmup
+		if measured > 0 {
+			rec.AddFrame(now.Sub(last))
+		}
+		last = now
+
+		x, z, yaw := benchPath(frame, total, sc.MapWidth, sc.MapHeight)
+		cam.Yaw = yaw
+		tex := sc.Render(cam.At(x, sc.GetTerrainHeight(x, z), z), nil)
+
+		g.uiBackend.Begin()
+		w, h := g.uiBackend.GetScreenSize()
+		g.uiBackend.DrawSceneTexture(0, 0, w, h, tex)
+		g.uiBackend.End()
+
+		// GPU times arrive a few frames late; drop the warmup's
+		if gpu, ok := perf.EndFrame(); ok && measured >= perf.Latency-1 {
+			rec.AddGPU(gpu)
+		}
+
+		frame++
+		if frame == total {
+			for _, gpu := range perf.Flush() {
+				rec.AddGPU(gpu)
+			}
+			g.imguiBackend.SetShouldClose(true)
+		}
+	})
+	if frame < total {
+		return nil, fmt.Errorf("window closed after %d of %d frames", frame, total)
+	}
+
+	rep := &perf.Report{
+		Map:      mapName,
+		Width:    int(cfg.Width),
+		Height:   int(cfg.Height),
+		GPU:      g.gpuInfo,
+		Revision: buildRevision(),
+	}
+	rec.Fill(rep)
+	return rep, nil
+}
+
+// loadBenchMap loads a map's terrain and models from the GRF archives.
+func (g *Game) loadBenchMap(sc *scene.Scene, name string) error {
+	gndData, err := g.assetManager.Load("data\\" + name + ".gnd")
+	if err != nil {
+		return fmt.Errorf("loading GND: %w", err)
+	}
+	gnd, err := formats.ParseGND(gndData)
+	if err != nil {
+		return fmt.Errorf("parsing GND: %w", err)
+	}
+	var rsw *formats.RSW
+	if rswData, err := g.assetManager.Load("data\\" + name + ".rsw"); err == nil {
+		if rsw, err = formats.ParseRSW(rswData); err != nil {
+			logger.Warn("failed to parse RSW", zap.Error(err))
+		}
+	}
+	if err := sc.LoadMap(gnd, rsw, g.assetManager.Load); err != nil {
+		return fmt.Errorf("loading map into scene: %w", err)
+	}
+	return nil
+}
+
+// benchPath returns where the camera target is at frame i of n: one loop
+// around the map's centre, with the camera turning twice as fast so it
+// looks both along the path and across the map.
+func benchPath(i, n int, mapWidth, mapHeight float32) (x, z, yaw float32) {
+	t := 2 * gomath.Pi * float64(i) / float64(n)
+	r := float64(min(mapWidth, mapHeight) * benchPathRadius)
+	x = mapWidth/2 + float32(r*gomath.Cos(t))
+	z = mapHeight/2 + float32(r*gomath.Sin(t))
+	return x, z, float32(2 * t)
+}
+
+// buildRevision returns the VCS revision the binary was built from, with
+// "-dirty" for uncommitted changes, or "" if unknown.
+func buildRevision() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	var rev, dirty string
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			rev = s.Value
+		case "vcs.modified":
+			if s.Value == "true" {
+				dirty = "-dirty"
+			}
+		}
+	}
+	if rev == "" {
+		return ""
+	}
+	return rev + dirty
+}
@@ -0,0 +1,117 @@
+package chat
+
+import (
+	"time"
+	"unicode/utf8"
+)
+
+// Chat bubble timing. A bubble shows for BubbleBaseTime plus
+// BubbleRuneTime a character, so longer messages stay up long enough to
+// read, up to BubbleMaxTime, and fades out over the last BubbleFadeTime.
+const (
+	BubbleBaseTime = 4 * time.Second
+	BubbleRuneTime = 80 * time.Millisecond
+	BubbleMaxTime  = 12 * time.Second
+	BubbleFadeTime = 500 * time.Millisecond
+
+	// BubbleMinTime is how long a bubble shows before a message its
+	// speaker said since takes its place.
+	BubbleMinTime = 1500 * time.Millisecond
+
+	// bubbleMaxQueued is how many messages of a speaker wait their turn; a
+	// flood drops the oldest waiting, the chat log still has them.
+	bubbleMaxQueued = 3
+)
+
+// BubbleDuration returns how long a message's bubble shows.
+func BubbleDuration(text string) time.Duration {
+	return min(BubbleBaseTime+time.Duration(utf8.RuneCountInString(text))*BubbleRuneTime, BubbleMaxTime)
+}
+
+// Bubbles are the chat bubbles over speakers' heads, by unit ID. A
+// speaker shows one message at a time; what they say while it shows
+// waits its turn.
+type Bubbles struct {
+	speakers map[uint32]*speech
+}
+
+// speech is a speaker's messages, the one showing first.
+type speech struct {
+	queue   []string
+	started time.Time // When the first started showing
+}
+
+// Say queues a message over a speaker's head.
+func (b *Bubbles) Say(id uint32, text string, now time.Time) {
+	if text == "" {
+		return
+	}
+	if b.speakers == nil {
+		b.speakers = make(map[uint32]*speech)
+	}
+	sp := b.speakers[id]
+	if sp == nil {
+		sp = &speech{}
+		b.speakers[id] = sp
+	}
+	sp.advance(now)
+	if len(sp.queue) == 0 {
+		sp.started = now
+	}
+	if len(sp.queue) > bubbleMaxQueued {
+		sp.queue = append(sp.queue[:1], sp.queue[2:]...)
+	}
+	sp.queue = append(sp.queue, text)
+}
+
+// Bubble returns the message showing over a speaker and its opacity, or
+// false if there's none.
+func (b *Bubbles) Bubble(id uint32, now time.Time) (text string, alpha float32, ok bool) {
+	sp := b.speakers[id]
+	if sp == nil {
+		return "", 0, false
+	}
+	sp.advance(now)
+	if len(sp.queue) == 0 {
+		return "", 0, false
+	}
+	text, alpha = sp.queue[0], 1
+	if len(sp.queue) == 1 {
+		if left := BubbleDuration(text) - now.Sub(sp.started); left < BubbleFadeTime {
+			alpha = float32(left) / float32(BubbleFadeTime)
+		}
+	}
+	return text, alpha, true
+}
+
+// Prune forgets the speakers with nothing left to show.
+func (b *Bubbles) Prune(now time.Time) {
+	for id, sp := range b.speakers {
+		if sp.advance(now); len(sp.queue) == 0 {
+			delete(b.speakers, id)
+		}
+	}
+}
+
+// Clear removes every bubble.
+func (b *Bubbles) Clear() {
+	b.speakers = nil
+}
+
+// advance drops the messages that have shown their time. One with others
+// waiting shows for BubbleMinTime at most, without fading; the next
+// starts when it ends.
+func (sp *speech) advance(now time.Time) {
+	for len(sp.queue) > 0 {
+		d := BubbleDuration(sp.queue[0])
+		if len(sp.queue) > 1 {
+			d = min(d, BubbleMinTime)
+		}
+		end := sp.started.Add(d)
+		if now.Before(end) {
+			return
+		}
+		sp.queue = sp.queue[1:]
+		sp.started = end
+	}
+}
@@ -0,0 +1,82 @@
+package chat
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBubbleDuration(t *testing.T) {
+	if got := BubbleDuration("hi"); got != BubbleBaseTime+2*BubbleRuneTime {
+		t.Errorf("BubbleDuration(hi) = %v", got)
+	}
+	// Runes, not bytes
+	if got := BubbleDuration("안녕"); got != BubbleBaseTime+2*BubbleRuneTime {
+		t.Errorf("BubbleDuration(안녕) = %v", got)
+	}
+	if got := BubbleDuration(strings.Repeat("a", 500)); got != BubbleMaxTime {
+		t.Errorf("BubbleDuration(long) = %v, want the cap", got)
+	}
+}
+
+func TestBubbles(t *testing.T) {
+	now := time.Unix(1000, 0)
+	var b Bubbles
+	b.Say(1, "hello", now)
+
+	at := func(d time.Duration) (string, float32, bool) {
+		return b.Bubble(1, now.Add(d))
+	}
+	if text, alpha, ok := at(time.Second); !ok || text != "hello" || alpha != 1 {
+		t.Errorf("at 1s = %q, %v, %v", text, alpha, ok)
+	}
+	end := BubbleDuration("hello")
+	if _, alpha, _ := at(end - BubbleFadeTime/2); alpha < 0.49 || alpha > 0.51 {
+		t.Errorf("alpha halfway through the fade = %v", alpha)
+	}
+	if _, _, ok := at(end); ok {
+		t.Error("bubble still showing after its time")
+	}
+	if _, _, ok := b.Bubble(2, now); ok {
+		t.Error("bubble over a unit that said nothing")
+	}
+
+	// Speaking again quickly queues: the first shows for the minimum
+	// time, unfaded, then the next for its own
+	now = now.Add(time.Minute)
+	b.Say(1, "one", now)
+	b.Say(1, "two", now.Add(100*time.Millisecond))
+	if text, alpha, _ := at(BubbleMinTime - time.Millisecond); text != "one" || alpha != 1 {
+		t.Errorf("before the minimum time = %q, %v", text, alpha)
+	}
+	if text, _, _ := at(BubbleMinTime); text != "two" {
+		t.Errorf("after the minimum time = %q, want two", text)
+	}
+	if _, _, ok := at(BubbleMinTime + BubbleDuration("two")); ok {
+		t.Error("queued bubble shown past its own time")
+	}
+
+	// A flood drops the oldest waiting
+	now = now.Add(time.Minute)
+	for _, text := range []string{"a", "b", "c", "d", "e", "f"} {
+		b.Say(1, text, now)
+	}
+	var shown []string
+	for d := time.Duration(0); ; d += BubbleMinTime {
+		text, _, ok := at(d)
+		if !ok {
+			break
+		}
+		if len(shown) == 0 || shown[len(shown)-1] != text {
+			shown = append(shown, text)
+		}
+	}
+	if got := strings.Join(shown, ""); got != "adef" {
+		t.Errorf("flood showed %q, want adef", got)
+	}
+
+	b.Prune(now.Add(time.Hour))
+	if len(b.speakers) != 0 {
+		t.Errorf("%d speakers left after pruning", len(b.speakers))
+	}
+}
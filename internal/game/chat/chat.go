@@ -0,0 +1,261 @@
+// Package chat keeps the chat log and the tabs that filter it by channel.
+package chat
+
+import (
+	"encoding/json"
+	"slices"
+)
+
+// Channel is where a chat message came from.
+type Channel uint8
+
+// Chat channels.
+const (
+	Public    Channel = iota // Nearby players
+	Party                    // Party members
+	Guild                    // Guild members
+	Whisper                  // Private messages, sent and received
+	System                   // Client and game notices
+	Broadcast                // Server-wide announcements
+
+	numChannels
+)
+
+var channelNames = [numChannels]string{"Public", "Party", "Guild", "Whisper", "System", "Broadcast"}
+
+// String returns the channel's name.
+func (c Channel) String() string {
+	if c >= numChannels {
+		return "Unknown"
+	}
+	return channelNames[c]
+}
+
+// Channels returns every channel, in order.
+func Channels() []Channel {
+	out := make([]Channel, numChannels)
+	for i := range out {
+		out[i] = Channel(i)
+	}
+	return out
+}
+
+// Filter is a set of channels.
+type Filter uint32
+
+// AllChannels is the filter of every channel.
+const AllChannels = Filter(1<<numChannels - 1)
+
+// FilterOf returns the filter of the given channels.
+func FilterOf(channels ...Channel) Filter {
+	var f Filter
+	for _, c := range channels {
+		f |= 1 << c
+	}
+	return f
+}
+
+// Has reports whether the filter includes c.
+func (f Filter) Has(c Channel) bool {
+	return f&(1<<c) != 0
+}
+
+// Toggle returns the filter with c added or removed.
+func (f Filter) Toggle(c Channel) Filter {
+	return f ^ 1<<c
+}
+
+// MarshalJSON stores a filter as channel names, so saved tabs survive
+// channels being added or reordered.
+func (f Filter) MarshalJSON() ([]byte, error) {
+	names := []string{}
+	for _, c := range Channels() {
+		if f.Has(c) {
+			names = append(names, c.String())
+		}
+	}
+	return json.Marshal(names)
+}
+
+// UnmarshalJSON reads channel names, skipping ones it doesn't know.
+func (f *Filter) UnmarshalJSON(data []byte) error {
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return err
+	}
+	*f = 0
+	for _, name := range names {
+		if i := slices.Index(channelNames[:], name); i >= 0 {
+			*f |= 1 << i
+		}
+	}
+	return nil
+}
+
+// Message is one line of the chat log.
+type Message struct {
+	Channel Channel
+	Text    string
+}
+
+// Tab is a named view of the log showing the channels in its filter.
+// Tabs are saved per character under settings.KeyChatTabs.
+type Tab struct {
+	Name     string `json:"name"`
+	Channels Filter `json:"channels"`
+}
+
+// DefaultTabs returns the tabs a character starts with.
+func DefaultTabs() []Tab {
+	return []Tab{
+		{Name: "All", Channels: AllChannels},
+		{Name: "Public", Channels: FilterOf(Public)},
+		{Name: "Party/Guild", Channels: FilterOf(Party, Guild)},
+		{Name: "System", Channels: FilterOf(System, Broadcast)},
+		{Name: "Whisper", Channels: FilterOf(Whisper)},
+	}
+}
+
+// MaxTabs caps how many tabs a player can have.
+const MaxTabs = 8
+
+// Log is the chat history and the tabs over it: which tab is shown and
+// how many messages each of the others has gained since it was.
+type Log struct {
+	messages []Message // Oldest first
+	max      int
+	tabs     []Tab
+	unread   []int // By tab
+	active   int
+}
+
+// NewLog creates a log keeping the last size messages, with the default
+// tabs.
+func NewLog(size int) *Log {
+	l := &Log{max: size}
+	l.SetTabs(nil)
+	return l
+}
+
+// Add appends a message, dropping the oldest past the log's size, and
+// counts it as unread in every other tab showing its channel.
+func (l *Log) Add(m Message) {
+	l.messages = append(l.messages, m)
+	if len(l.messages) > l.max {
+		l.messages = l.messages[len(l.messages)-l.max:]
+	}
+	for i, tab := range l.tabs {
+		if i != l.active && tab.Channels.Has(m.Channel) {
+			l.unread[i]++
+		}
+	}
+}
+
+// Messages returns the messages shown in the active tab, oldest first.
+func (l *Log) Messages() []Message {
+	filter := l.tabs[l.active].Channels
+	if filter == AllChannels {
+		return l.messages
+	}
+	var out []Message
+	for _, m := range l.messages {
+		if filter.Has(m.Channel) {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// Tabs returns the tabs, in display order.
+func (l *Log) Tabs() []Tab {
+	return slices.Clone(l.tabs)
+}
+
+// SetTabs replaces the tabs, such as with a character's saved ones, and
+// shows the first. Empty or oversized lists restore the defaults.
+func (l *Log) SetTabs(tabs []Tab) {
+	if len(tabs) == 0 || len(tabs) > MaxTabs {
+		tabs = DefaultTabs()
+	}
+	l.tabs = slices.Clone(tabs)
+	l.unread = make([]int, len(tabs))
+	l.active = 0
+}
+
+// Active returns the index of the shown tab.
+func (l *Log) Active() int {
+	return l.active
+}
+
+// Unread returns how many messages tab i gained since it was last shown.
+func (l *Log) Unread(i int) int {
+	return l.unread[i]
+}
+
+// Select shows tab i, marking its messages read.
+func (l *Log) Select(i int) {
+	if i < 0 || i >= len(l.tabs) {
+		return
+	}
+	l.active = i
+	l.unread[i] = 0
+}
+
+// AddTab appends a tab and returns its index, or -1 at MaxTabs.
+func (l *Log) AddTab(tab Tab) int {
+	if len(l.tabs) >= MaxTabs {
+		return -1
+	}
+	l.tabs = append(l.tabs, tab)
+	l.unread = append(l.unread, 0)
+	return len(l.tabs) - 1
+}
+
+// RemoveTab deletes tab i. The last tab can't be removed.
+func (l *Log) RemoveTab(i int) {
+	if i < 0 || i >= len(l.tabs) || len(l.tabs) == 1 {
+		return
+	}
+	l.tabs = slices.Delete(l.tabs, i, i+1)
+	l.unread = slices.Delete(l.unread, i, i+1)
+	if l.active > i || l.active == len(l.tabs) {
+		l.active--
+	}
+	l.unread[l.active] = 0
+}
+
+// RenameTab renames tab i; empty names are ignored.
+func (l *Log) RenameTab(i int, name string) {
+	if i < 0 || i >= len(l.tabs) || name == "" {
+		return
+	}
+	l.tabs[i].Name = name
+}
+
+// ToggleChannel adds or removes a channel from tab i's filter.
+func (l *Log) ToggleChannel(i int, c Channel) {
+	if i < 0 || i >= len(l.tabs) || c >= numChannels {
+		return
+	}
+	l.tabs[i].Channels = l.tabs[i].Channels.Toggle(c)
+}
+
+// MoveTab moves tab from to index to, shifting the tabs between; the
+// active tab stays active wherever it ends up.
+func (l *Log) MoveTab(from, to int) {
+	if from < 0 || from >= len(l.tabs) || to < 0 || to >= len(l.tabs) || from == to {
+		return
+	}
+	tab, unread := l.tabs[from], l.unread[from]
+	l.tabs = slices.Insert(slices.Delete(l.tabs, from, from+1), to, tab)
+	l.unread = slices.Insert(slices.Delete(l.unread, from, from+1), to, unread)
+
+	switch {
+	case l.active == from:
+		l.active = to
+	case from < l.active && to >= l.active:
+		l.active--
+	case from > l.active && to <= l.active:
+		l.active++
+	}
+}
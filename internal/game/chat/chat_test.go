@@ -0,0 +1,148 @@
+package chat
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestFilterJSON(t *testing.T) {
+	f := FilterOf(Party, Whisper)
+	data, err := json.Marshal(Tab{Name: "Friends", Channels: f})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"name":"Friends","channels":["Party","Whisper"]}`; string(data) != want {
+		t.Errorf("encoded %s, want %s", data, want)
+	}
+
+	var tab Tab
+	if err := json.Unmarshal([]byte(`{"name":"x","channels":["Guild","Shout","System"]}`), &tab); err != nil {
+		t.Fatal(err)
+	}
+	if tab.Channels != FilterOf(Guild, System) {
+		t.Errorf("decoded %b, want Guild and System with the unknown name skipped", tab.Channels)
+	}
+}
+
+func TestLogTabs(t *testing.T) {
+	l := NewLog(3)
+	l.Add(Message{Channel: Public, Text: "hi"})
+	l.Add(Message{Channel: Party, Text: "pull"})
+	l.Add(Message{Channel: System, Text: "level up"})
+	l.Add(Message{Channel: Guild, Text: "woe"})
+
+	// The oldest message is dropped past the log's size
+	if got := texts(l.Messages()); !reflect.DeepEqual(got, []string{"pull", "level up", "woe"}) {
+		t.Errorf("All tab = %v", got)
+	}
+	if l.Unread(0) != 0 || l.Unread(1) != 1 || l.Unread(2) != 2 || l.Unread(3) != 1 || l.Unread(4) != 0 {
+		t.Errorf("unread = %d %d %d %d %d, want 0 1 2 1 0",
+			l.Unread(0), l.Unread(1), l.Unread(2), l.Unread(3), l.Unread(4))
+	}
+
+	l.Select(2)
+	if got := texts(l.Messages()); !reflect.DeepEqual(got, []string{"pull", "woe"}) {
+		t.Errorf("Party/Guild tab = %v", got)
+	}
+	if l.Unread(2) != 0 {
+		t.Errorf("shown tab has %d unread", l.Unread(2))
+	}
+
+	// Filters are per tab
+	l.ToggleChannel(2, System)
+	if got := texts(l.Messages()); len(got) != 3 {
+		t.Errorf("tab with System added shows %v", got)
+	}
+}
+
+func TestLogMoveTab(t *testing.T) {
+	tests := []struct {
+		name             string
+		active, from, to int
+		wantActive       int
+	}{
+		{"move the active tab", 1, 1, 3, 3},
+		{"move a tab past the active one", 2, 0, 4, 1},
+		{"move a tab before the active one", 2, 4, 0, 3},
+		{"move tabs after the active one", 1, 3, 4, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLog(10)
+			l.Select(tt.active)
+			name := l.Tabs()[tt.active].Name
+			moved := l.Tabs()[tt.from].Name
+			l.MoveTab(tt.from, tt.to)
+			if l.Active() != tt.wantActive || l.Tabs()[l.Active()].Name != name {
+				t.Errorf("active = %d (%s), want %d (%s)", l.Active(), l.Tabs()[l.Active()].Name, tt.wantActive, name)
+			}
+			if l.Tabs()[tt.to].Name != moved {
+				t.Errorf("tab %d = %s, want %s", tt.to, l.Tabs()[tt.to].Name, moved)
+			}
+		})
+	}
+}
+
+func TestLogAddRemoveTab(t *testing.T) {
+	l := NewLog(10)
+	for range MaxTabs {
+		l.AddTab(Tab{Name: "extra"})
+	}
+	if len(l.Tabs()) != MaxTabs {
+		t.Fatalf("%d tabs, want the cap %d", len(l.Tabs()), MaxTabs)
+	}
+
+	l.Select(MaxTabs - 1)
+	l.RemoveTab(MaxTabs - 1)
+	if l.Active() != MaxTabs-2 {
+		t.Errorf("removing the active last tab left %d active, want %d", l.Active(), MaxTabs-2)
+	}
+	for range MaxTabs {
+		l.RemoveTab(0)
+	}
+	if len(l.Tabs()) != 1 || l.Active() != 0 {
+		t.Errorf("%d tabs, active %d; want the last tab kept and active", len(l.Tabs()), l.Active())
+	}
+
+	// Saved tabs replace the defaults; none restore them
+	l.SetTabs([]Tab{{Name: "Only", Channels: AllChannels}})
+	if len(l.Tabs()) != 1 {
+		t.Errorf("restored %d tabs, want 1", len(l.Tabs()))
+	}
+	l.SetTabs(nil)
+	if !reflect.DeepEqual(l.Tabs(), DefaultTabs()) {
+		t.Errorf("tabs = %v, want the defaults", l.Tabs())
+	}
+}
+
+func texts(messages []Message) []string {
+	var out []string
+	for _, m := range messages {
+		out = append(out, m.Text)
+	}
+	return out
+}
+
+func TestNames(t *testing.T) {
+	n := NewNames(3)
+	for _, name := range []string{"Dalia", "Bob", "Alice", "Bob", "Carla"} {
+		n.Seen(name)
+	}
+	nearby := []string{"Albert", "Bob", "Cal"}
+
+	tests := []struct {
+		typed string
+		want  []string
+	}{
+		{"", []string{"Carla", "Bob", "Alice", "Albert", "Cal"}}, // Dalia forgotten
+		{"al", []string{"Alice", "Albert", "Cal"}},
+		{"B", []string{"Bob", "Albert"}},
+		{"z", nil},
+	}
+	for _, tt := range tests {
+		if got := n.Match(tt.typed, nearby); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Match(%q) = %q, want %q", tt.typed, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package chat
+
+import (
+	"slices"
+	"strings"
+)
+
+// Names remembers the players the chat dealt with recently, such as
+// whisper targets, to complete names with.
+type Names struct {
+	names []string // Most recent first
+	max   int
+}
+
+// NewNames creates a list remembering the last size names.
+func NewNames(size int) *Names {
+	return &Names{max: size}
+}
+
+// Seen moves name to the front, forgetting the oldest past the list's
+// size.
+func (n *Names) Seen(name string) {
+	if name == "" {
+		return
+	}
+	if i := slices.Index(n.names, name); i >= 0 {
+		n.names = slices.Delete(n.names, i, i+1)
+	}
+	n.names = slices.Insert(n.names, 0, name)
+	if len(n.names) > n.max {
+		n.names = n.names[:n.max]
+	}
+}
+
+// Match returns the names containing typed, ignoring case: the ones
+// starting with it first, and in each group the recent names before the
+// nearby ones, which are given nearest first.
+func (n *Names) Match(typed string, nearby []string) []string {
+	typed = strings.ToLower(typed)
+	var prefixed, inside []string
+	for _, name := range slices.Concat(n.names, nearby) {
+		lower := strings.ToLower(name)
+		switch {
+		case slices.Contains(prefixed, name) || slices.Contains(inside, name):
+		case strings.HasPrefix(lower, typed):
+			prefixed = append(prefixed, name)
+		case strings.Contains(lower, typed):
+			inside = append(inside, name)
+		}
+	}
+	return append(prefixed, inside...)
+}
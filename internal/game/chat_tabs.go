@@ -0,0 +1,82 @@
+package game
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/ui2d"
+	"github.com/Faultbox/midgard-ro/internal/game/chat"
+	"github.com/Faultbox/midgard-ro/internal/game/settings"
+	"github.com/Faultbox/midgard-ro/internal/game/ui"
+	"github.com/Faultbox/midgard-ro/internal/logger"
+)
+
+// chatColors are the chat log's line colors by channel.
+var chatColors = map[chat.Channel]ui2d.Color{
+	chat.Public:    {R: 1, G: 1, B: 1, A: 1},
+	chat.Party:     {R: 1, G: 0.78, B: 0.78, A: 1},
+	chat.Guild:     {R: 0.7, G: 1, B: 0.7, A: 1},
+	chat.Whisper:   {R: 1, G: 1, B: 0, A: 1},
+	chat.System:    {R: 1, G: 0.85, B: 0.3, A: 1},
+	chat.Broadcast: {R: 0.55, G: 0.8, B: 1, A: 1},
+}
+
+// chatLines returns the messages of the active chat tab, colored by
+// channel.
+func chatLines(log *chat.Log) []ui.ChatLine {
+	messages := log.Messages()
+	lines := make([]ui.ChatLine, len(messages))
+	for i, m := range messages {
+		lines[i] = ui.ChatLine{Text: m.Text, Color: chatColors[m.Channel]}
+	}
+	return lines
+}
+
+// chatTabs returns the chat tabs with their unread counts and channels.
+func chatTabs(log *chat.Log) []ui.ChatTab {
+	tabs := log.Tabs()
+	out := make([]ui.ChatTab, len(tabs))
+	for i, tab := range tabs {
+		out[i] = ui.ChatTab{Name: tab.Name, Unread: log.Unread(i)}
+		for _, c := range chat.Channels() {
+			out[i].Channels = append(out[i].Channels, ui.ChatChannelToggle{Label: c.String(), On: tab.Channels.Has(c)})
+		}
+	}
+	return out
+}
+
+// chatTabActions edits the chat tabs, saving every change to the
+// character's settings.
+func (g *Game) chatTabActions(log *chat.Log) ui.ChatTabActions {
+	edit := func(f func()) {
+		f()
+		g.persistChatTabs(log)
+	}
+	actions := ui.ChatTabActions{
+		Select: log.Select,
+		Move:   func(from, to int) { edit(func() { log.MoveTab(from, to) }) },
+		Remove: func(tab int) { edit(func() { log.RemoveTab(tab) }) },
+		Rename: func(tab int, name string) { edit(func() { log.RenameTab(tab, name) }) },
+		Toggle: func(tab, channel int) { edit(func() { log.ToggleChannel(tab, chat.Channel(channel)) }) },
+	}
+	if len(log.Tabs()) < chat.MaxTabs {
+		actions.Add = func() {
+			edit(func() { log.Select(log.AddTab(chat.Tab{Name: "New", Channels: chat.AllChannels})) })
+		}
+	}
+	return actions
+}
+
+// persistChatTabs saves the chat tabs of the character in game.
+func (g *Game) persistChatTabs(log *chat.Log) {
+	store := g.settings.store
+	if store == nil {
+		return
+	}
+	if err := store.Set(settings.KeyChatTabs, log.Tabs()); err != nil {
+		logger.Warn("failed to store chat tabs", zap.Error(err))
+		return
+	}
+	if err := store.Save(); err != nil {
+		logger.Warn("failed to save account settings", zap.Error(err))
+	}
+}
@@ -0,0 +1,253 @@
+// Package commands implements the chat slash-command dispatcher.
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Prefix marks a chat line as a command.
+const Prefix = "/"
+
+var (
+	// ErrUnknownCommand is returned for lines naming no registered command.
+	ErrUnknownCommand = errors.New("unknown command")
+	// ErrUsage is returned by handlers for invalid arguments; the dispatcher
+	// replaces it with the command's usage line.
+	ErrUsage = errors.New("invalid arguments")
+)
+
+// Handler runs a command with its parsed arguments.
+type Handler func(args []string) error
+
+// Command is a registered slash-command.
+type Command struct {
+	Name    string   // Name without the leading slash
+	Aliases []string // Alternative names
+	Usage   string   // Argument synopsis, e.g. "<emotion id>"
+	Help    string   // One-line description for /help
+	Dev     bool     // Only available when dev commands are enabled
+	Run     Handler
+
+	// Complete suggests values for the first argument from what's typed
+	// of it, best first (nil for none).
+	Complete func(prefix string) []string
+}
+
+// Dispatcher parses chat lines and runs the matching command.
+type Dispatcher struct {
+	commands map[string]*Command // Name and aliases -> command
+	dev      bool
+	print    func(string)
+}
+
+// NewDispatcher creates a dispatcher that writes command output with print.
+// The built-in /help command is always registered.
+func NewDispatcher(print func(string)) *Dispatcher {
+	d := &Dispatcher{
+		commands: make(map[string]*Command),
+		print:    print,
+	}
+	d.Register(Command{
+		Name:    "help",
+		Aliases: []string{"h", "?"},
+		Usage:   "[command]",
+		Help:    "List commands or show a command's usage",
+		Run:     d.help,
+	})
+	return d
+}
+
+// SetDevMode enables or disables dev-only commands.
+func (d *Dispatcher) SetDevMode(enabled bool) {
+	d.dev = enabled
+}
+
+// Register adds a command. Names are case-insensitive and must be unique.
+func (d *Dispatcher) Register(cmd Command) error {
+	if cmd.Name == "" || cmd.Run == nil {
+		return fmt.Errorf("command %q: name and handler are required", cmd.Name)
+	}
+	c := &cmd
+	names := append([]string{cmd.Name}, cmd.Aliases...)
+	for _, name := range names {
+		if _, exists := d.commands[strings.ToLower(name)]; exists {
+			return fmt.Errorf("command %q already registered", name)
+		}
+	}
+	for _, name := range names {
+		d.commands[strings.ToLower(name)] = c
+	}
+	return nil
+}
+
+// IsCommand reports whether a chat line should be dispatched.
+func IsCommand(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), Prefix)
+}
+
+// Execute runs the command on a chat line ("/name args...").
+func (d *Dispatcher) Execute(line string) error {
+	fields := ParseArgs(strings.TrimPrefix(strings.TrimSpace(line), Prefix))
+	if len(fields) == 0 {
+		return ErrUnknownCommand
+	}
+
+	cmd := d.lookup(fields[0])
+	if cmd == nil {
+		return fmt.Errorf("%w: /%s (try /help)", ErrUnknownCommand, fields[0])
+	}
+
+	err := cmd.Run(fields[1:])
+	if errors.Is(err, ErrUsage) {
+		return fmt.Errorf("usage: %s", usageLine(cmd))
+	}
+	return err
+}
+
+// Commands returns the available commands sorted by name.
+func (d *Dispatcher) Commands() []*Command {
+	seen := make(map[*Command]bool)
+	var result []*Command
+	for _, cmd := range d.commands {
+		if seen[cmd] || (cmd.Dev && !d.dev) {
+			continue
+		}
+		seen[cmd] = true
+		result = append(result, cmd)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// Complete returns completions for the end of a chat line being typed:
+// command names and aliases right after the slash, then the command's
+// first argument if it has a Complete. The candidates replace the line
+// from start; ones with spaces come quoted, as ParseArgs reads them.
+func (d *Dispatcher) Complete(line string) (start int, candidates []string) {
+	if !strings.HasPrefix(line, Prefix) {
+		return 0, nil
+	}
+	name, arg, hasArg := strings.Cut(line[len(Prefix):], " ")
+	if !hasArg {
+		var names []string
+		for n, cmd := range d.commands {
+			if !cmd.Dev || d.dev {
+				names = append(names, n)
+			}
+		}
+		sort.Strings(names)
+		return len(Prefix), MatchPrefix(name, names)
+	}
+
+	cmd := d.lookup(name)
+	if cmd == nil || cmd.Complete == nil {
+		return 0, nil
+	}
+	word := arg
+	if strings.HasPrefix(word, `"`) {
+		if strings.Contains(word[1:], `"`) {
+			return 0, nil // Past the first argument
+		}
+		word = word[1:]
+	} else if strings.ContainsFunc(word, unicode.IsSpace) {
+		return 0, nil
+	}
+	for _, c := range cmd.Complete(word) {
+		if strings.ContainsFunc(c, unicode.IsSpace) {
+			c = `"` + c + `"`
+		}
+		candidates = append(candidates, c)
+	}
+	return len(line) - len(arg), candidates
+}
+
+// MatchPrefix returns the words starting with prefix, ignoring case, in
+// their order.
+func MatchPrefix(prefix string, words []string) []string {
+	var out []string
+	prefix = strings.ToLower(prefix)
+	for _, w := range words {
+		if strings.HasPrefix(strings.ToLower(w), prefix) {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// lookup finds an available command by name or alias.
+func (d *Dispatcher) lookup(name string) *Command {
+	cmd := d.commands[strings.ToLower(name)]
+	if cmd == nil || (cmd.Dev && !d.dev) {
+		return nil
+	}
+	return cmd
+}
+
+func (d *Dispatcher) help(args []string) error {
+	if len(args) > 1 {
+		return ErrUsage
+	}
+	if len(args) == 1 {
+		cmd := d.lookup(strings.TrimPrefix(args[0], Prefix))
+		if cmd == nil {
+			return fmt.Errorf("%w: /%s", ErrUnknownCommand, args[0])
+		}
+		d.print(usageLine(cmd) + " - " + cmd.Help)
+		if len(cmd.Aliases) > 0 {
+			d.print("  aliases: " + Prefix + strings.Join(cmd.Aliases, " "+Prefix))
+		}
+		return nil
+	}
+
+	d.print("Commands:")
+	for _, cmd := range d.Commands() {
+		line := "  " + Prefix + cmd.Name + " - " + cmd.Help
+		if cmd.Dev {
+			line += " (dev)"
+		}
+		d.print(line)
+	}
+	return nil
+}
+
+func usageLine(cmd *Command) string {
+	if cmd.Usage == "" {
+		return Prefix + cmd.Name
+	}
+	return Prefix + cmd.Name + " " + cmd.Usage
+}
+
+// ParseArgs splits a command line on whitespace. Double quotes group words
+// into one argument, e.g. `/w "Some Player" hi` has two arguments.
+func ParseArgs(s string) []string {
+	var args []string
+	var current strings.Builder
+	inQuotes, hasArg := false, false
+
+	for _, r := range s {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			hasArg = true
+		case unicode.IsSpace(r) && !inQuotes:
+			if hasArg {
+				args = append(args, current.String())
+				current.Reset()
+				hasArg = false
+			}
+		default:
+			current.WriteRune(r)
+			hasArg = true
+		}
+	}
+	if hasArg {
+		args = append(args, current.String())
+	}
+	return args
+}
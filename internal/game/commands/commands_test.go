@@ -0,0 +1,156 @@
+package commands
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"", nil},
+		{"where", []string{"where"}},
+		{"emote  5 ", []string{"emote", "5"}},
+		{`w "Some Player" hello there`, []string{"w", "Some Player", "hello", "there"}},
+		{`say ""`, []string{"say", ""}},
+	}
+
+	for _, tt := range tests {
+		if got := ParseArgs(tt.input); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseArgs(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestDispatcherExecute(t *testing.T) {
+	var out []string
+	d := NewDispatcher(func(s string) { out = append(out, s) })
+
+	var gotArgs []string
+	err := d.Register(Command{
+		Name:    "emote",
+		Aliases: []string{"e"},
+		Usage:   "<id>",
+		Help:    "Show an emotion",
+		Run: func(args []string) error {
+			if len(args) != 1 {
+				return ErrUsage
+			}
+			gotArgs = args
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Register error: %v", err)
+	}
+
+	if err := d.Execute("/E 5"); err != nil {
+		t.Fatalf("Execute alias error: %v", err)
+	}
+	if !reflect.DeepEqual(gotArgs, []string{"5"}) {
+		t.Errorf("args = %q, want [5]", gotArgs)
+	}
+
+	if err := d.Execute("/emote"); err == nil || err.Error() != "usage: /emote <id>" {
+		t.Errorf("usage error = %v, want usage line", err)
+	}
+
+	if err := d.Execute("/nope"); !errors.Is(err, ErrUnknownCommand) {
+		t.Errorf("unknown command error = %v, want ErrUnknownCommand", err)
+	}
+}
+
+func TestDispatcherRejectsDuplicates(t *testing.T) {
+	d := NewDispatcher(func(string) {})
+	noop := func([]string) error { return nil }
+
+	if err := d.Register(Command{Name: "sit", Run: noop}); err != nil {
+		t.Fatalf("Register error: %v", err)
+	}
+	if err := d.Register(Command{Name: "SIT", Run: noop}); err == nil {
+		t.Error("expected error for duplicate name")
+	}
+	if err := d.Register(Command{Name: "stand", Aliases: []string{"h"}, Run: noop}); err == nil {
+		t.Error("expected error for alias clashing with /help")
+	}
+}
+
+func TestDevCommandsHiddenUntilEnabled(t *testing.T) {
+	var out []string
+	d := NewDispatcher(func(s string) { out = append(out, s) })
+	ran := false
+	d.Register(Command{Name: "cell", Help: "Inspect cell", Dev: true, Run: func([]string) error {
+		ran = true
+		return nil
+	}})
+
+	if err := d.Execute("/cell"); !errors.Is(err, ErrUnknownCommand) {
+		t.Errorf("dev command without dev mode: error = %v, want ErrUnknownCommand", err)
+	}
+	d.Execute("/help")
+	if strings.Contains(strings.Join(out, "\n"), "/cell") {
+		t.Error("/help lists dev command without dev mode")
+	}
+
+	d.SetDevMode(true)
+	out = nil
+	if err := d.Execute("/cell"); err != nil || !ran {
+		t.Errorf("dev command with dev mode: error = %v, ran = %v", err, ran)
+	}
+	d.Execute("/help")
+	if !strings.Contains(strings.Join(out, "\n"), "/cell - Inspect cell (dev)") {
+		t.Errorf("/help output = %q, want dev command listed", out)
+	}
+}
+
+func TestIsCommand(t *testing.T) {
+	if !IsCommand("  /where") {
+		t.Error("IsCommand(/where) = false")
+	}
+	if IsCommand("hello /where") {
+		t.Error("IsCommand(plain chat) = true")
+	}
+}
+
+func TestDispatcherComplete(t *testing.T) {
+	d := NewDispatcher(func(string) {})
+	noop := func([]string) error { return nil }
+	names := func(prefix string) []string {
+		return MatchPrefix(prefix, []string{"Alice", "Some Player", "alfred"})
+	}
+	d.Register(Command{Name: "w", Aliases: []string{"whisper"}, Run: noop, Complete: names})
+	d.Register(Command{Name: "where", Run: noop})
+	d.Register(Command{Name: "warp", Dev: true, Run: noop})
+
+	tests := []struct {
+		line      string
+		wantStart int
+		want      []string
+	}{
+		{"/w", 1, []string{"w", "where", "whisper"}},
+		{"/WH", 1, []string{"where", "whisper"}},
+		{"/w al", 3, []string{"Alice", "alfred"}},
+		{"/whisper ", 9, []string{"Alice", `"Some Player"`, "alfred"}},
+		{"/w s", 3, []string{`"Some Player"`}},
+		{`/w "Some P`, 3, []string{`"Some Player"`}},
+		{`/w "Some Player" hi`, 0, nil},
+		{"/w Alice hi", 0, nil},
+		{"/where x", 0, nil},
+		{"hello", 0, nil},
+	}
+	for _, tt := range tests {
+		start, got := d.Complete(tt.line)
+		if start != tt.wantStart || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Complete(%q) = %d, %q; want %d, %q", tt.line, start, got, tt.wantStart, tt.want)
+		}
+	}
+
+	d.SetDevMode(true)
+	if _, got := d.Complete("/wa"); !reflect.DeepEqual(got, []string{"warp"}) {
+		t.Errorf("Complete(/wa) in dev mode = %q, want [warp]", got)
+	}
+}
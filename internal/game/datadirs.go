@@ -0,0 +1,41 @@
+package game
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Faultbox/midgard-ro/internal/game/states"
+	"github.com/Faultbox/midgard-ro/internal/logger"
+)
+
+// dataWatchInterval is how often the loose data directories are checked
+// for edited files. A check stats their directories; every file is only
+// rescanned now and then (see grf.Dir.Poll).
+const dataWatchInterval = time.Second
+
+// addDataDirs mounts the loose data directories over the GRF archives and
+// watches them, so files edited there show without a restart.
+func (g *Game) addDataDirs(dirs []string) {
+	for _, dir := range dirs {
+		if err := g.assetManager.AddDir(dir); err != nil {
+			logger.Warn("failed to add data directory", zap.String("path", dir), zap.Error(err))
+		} else {
+			logger.Info("added data directory", zap.String("path", dir))
+		}
+	}
+	g.assetManager.Watch(dataWatchInterval)
+}
+
+// reloadEditedAssets has the game state load the files edited in the data
+// directories again.
+func (g *Game) reloadEditedAssets() {
+	changed := g.assetManager.Changes()
+	if len(changed) == 0 {
+		return
+	}
+	logger.Debug("data files changed", zap.Strings("files", changed))
+	if s, ok := g.stateManager.Current().(*states.InGameState); ok {
+		s.ReloadAssets(changed)
+	}
+}
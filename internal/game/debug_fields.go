@@ -3,6 +3,7 @@ package game
 import (
 	"time"
 
+	"github.com/Faultbox/midgard-ro/internal/game/entity"
 	"github.com/Faultbox/midgard-ro/internal/game/states"
 	"github.com/Faultbox/midgard-ro/internal/game/ui"
 	"github.com/Faultbox/midgard-ro/internal/network"
@@ -42,10 +43,32 @@ func populateDebugFields(out *ui.InGameUIState, state *states.InGameState, clien
 		out.CamPitch = cam.Pitch
 	}
 
+	if em := state.GetEntityManager(); em != nil {
+		out.EntityCount = em.Count()
+		out.PlayerCount = em.CountByType(entity.TypePlayer)
+		out.MonsterCount = em.CountByType(entity.TypeMonster)
+		out.NPCCount = em.CountByType(entity.TypeNPC)
+		out.ItemCount = em.CountByType(entity.TypeItem)
+
+		stats := em.UpdateStats()
+		out.EntitiesUpdated = stats.Updated
+		out.EntitiesThrottled = stats.Throttled
+		out.EntitiesCulled = stats.Culled
+	}
+	anim := state.AnimStats()
+	out.AnimFull, out.AnimReduced, out.AnimFrozen = anim.Full, anim.Reduced, anim.Frozen
+	out.AnimComposited = anim.Composited
+
 	// gl.GetError consumed in the UI layer (already imports gl). Sampled
 	// once per frame is enough — overlays that read it from there will see
 	// the most recent error flag.
 
+	input, waiting := state.InputStats()
+	out.InputWaiting = waiting
+	out.InputFired = input.Fired
+	out.InputBuffered = input.Buffered
+	out.InputDropped = input.Dropped
+
 	if client != nil {
 		st := client.Stats()
 		out.PacketsSent = st.PacketsSent
@@ -56,6 +79,19 @@ func populateDebugFields(out *ui.InGameUIState, state *states.InGameState, clien
 		out.LastSentLen = st.LastSentLen
 		out.LastRecvID = st.LastRecvID
 		out.LastRecvLen = st.LastRecvLen
+		out.PacketsQueued = st.Queued
+		out.PacketsCoalesced = st.Coalesced
+		out.PacketsDeferred = st.Deferred
+		out.SendWrites = st.Writes
+		out.InboundQueued = st.InboundQueued
+		out.InboundPeak = st.InboundPeak
+		out.ReadStalls = st.ReadStalls
+		out.WriteStalls = st.WriteStalls
+		out.UnknownPackets = st.UnknownPackets
+		out.LastUnknownID = st.LastUnknownID
+		out.Resyncs = st.Resyncs
+		out.DroppedBytes = st.DroppedBytes
+		out.VersionMismatch = st.VersionMismatch
 		now := time.Now()
 		if !st.LastSentAt.IsZero() {
 			out.LastSentAgoMs = now.Sub(st.LastSentAt).Milliseconds()
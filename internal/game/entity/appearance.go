@@ -0,0 +1,313 @@
+package entity
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// Option flags of a unit (rAthena OPTION_*), sent in spawn entries and
+// ZC_STATE_CHANGE3. Only the ones that change the sprite are listed.
+const (
+	OptionCart1    uint32 = 0x00000008
+	OptionFalcon   uint32 = 0x00000010
+	OptionRiding   uint32 = 0x00000020 // Peco Peco
+	OptionCart2    uint32 = 0x00000080
+	OptionCart3    uint32 = 0x00000100
+	OptionCart4    uint32 = 0x00000200
+	OptionCart5    uint32 = 0x00000400
+	OptionDragon1  uint32 = 0x00080000
+	OptionWugRider uint32 = 0x00200000
+	OptionMadogear uint32 = 0x00400000
+	OptionDragon2  uint32 = 0x00800000
+	OptionDragon3  uint32 = 0x01000000
+	OptionDragon4  uint32 = 0x02000000
+	OptionDragon5  uint32 = 0x04000000
+
+	OptionCart    = OptionCart1 | OptionCart2 | OptionCart3 | OptionCart4 | OptionCart5
+	OptionDragon  = OptionDragon1 | OptionDragon2 | OptionDragon3 | OptionDragon4 | OptionDragon5
+	OptionMounted = OptionRiding | OptionDragon | OptionWugRider | OptionMadogear
+)
+
+// Unit states (rAthena OPT1_* body states and OPT2_* health flags), sent
+// in spawn entries and ZC_STATE_CHANGE3. Only the ones that tint the
+// sprite are listed.
+const (
+	BodyStateFrozen uint16 = 2
+	HealthPoisoned  uint16 = 0x0001
+)
+
+// Sprite folders in the GRF.
+const (
+	bodySpriteDir    = "data/sprite/인간족/몸통/"
+	headSpriteDir    = "data/sprite/인간족/머리통/"
+	monsterSpriteDir = "data/sprite/몬스터/"
+	robeSpriteDir    = "data/sprite/로브/"
+	effectSpriteDir  = "data/sprite/이팩트/"
+)
+
+// jobSprites maps job IDs to their body sprite names.
+var jobSprites = map[int]string{
+	0:    "초보자",
+	1:    "검사",
+	2:    "마법사",
+	3:    "궁수",
+	4:    "성직자",
+	5:    "상인",
+	6:    "도둑",
+	7:    "기사",
+	8:    "프리스트",
+	9:    "위저드",
+	10:   "제철공",
+	11:   "헌터",
+	12:   "어세신",
+	14:   "크루세이더",
+	15:   "몽크",
+	16:   "세이지",
+	17:   "로그",
+	18:   "연금술사",
+	19:   "바드",
+	20:   "무희",
+	23:   "슈퍼노비스",
+	4008: "로드나이트",
+	4015: "팔라딘",
+}
+
+// mountedSprites maps job IDs to the body sprite of the job riding a Peco
+// Peco. Rider and mount are a single sprite. Mounts of jobs not listed
+// here (dragons, wargs, Mado Gear) fall back to the unmounted body.
+var mountedSprites = map[int]string{
+	7:    "페코페코_기사",
+	14:   "신페코크루세이더",
+	4008: "로드페코",
+	4015: "팔라딘페코",
+}
+
+// robeSprites maps garment view IDs to their sprite folder names.
+var robeSprites = map[int]string{
+	1: "천사날개",
+}
+
+// petSprites maps the monster classes most often kept as pets to their
+// monster sprite names.
+var petSprites = map[int]string{
+	1002: "poring",
+	1011: "chonchon",
+	1014: "spore",
+	1019: "pecopeco",
+	1026: "munak",
+	1029: "isis",
+	1031: "poporing",
+	1035: "hunter_fly",
+	1042: "steel_chonchon",
+	1049: "picky",
+	1052: "rocker",
+	1056: "smokie",
+	1057: "yoyo",
+	1063: "lunatic",
+	1077: "poison_spore",
+	1101: "baphomet_",
+	1107: "desert_wolf_b",
+	1109: "deviruchi",
+	1110: "dokebi",
+	1113: "drops",
+	1155: "petit",
+	1167: "savage_babe",
+	1170: "sohee",
+	1188: "bongun",
+	1200: "zherlthsh",
+}
+
+// baseJobs maps jobs to the job they grow from: transcendent and baby jobs
+// to the normal ones, third jobs to transcendent ones, second jobs to
+// first ones and first jobs to the novice.
+var baseJobs = map[int]int{
+	1: 0, 2: 0, 3: 0, 4: 0, 5: 0, 6: 0,
+	7: 1, 13: 1, 14: 1, 21: 1,
+	8: 4, 15: 4,
+	9: 2, 16: 2,
+	10: 5, 18: 5,
+	11: 3, 19: 3, 20: 3,
+	12: 6, 17: 6,
+	23: 0, 24: 0, 25: 0,
+	4046: 0, 4047: 4046, 4049: 4046,
+	4054: 4008, 4055: 4010, 4056: 4012, 4057: 4009, 4058: 4011, 4059: 4013, 4060: 4054,
+	4066: 4015, 4067: 4017, 4068: 4020, 4069: 4021, 4070: 4016, 4071: 4019, 4072: 4018, 4073: 4066,
+}
+
+func init() {
+	// Transcendent (4001-4022) and baby (4023-4044) jobs follow the
+	// normal ones from novice to dancer; 4045 is the baby super novice.
+	for i := range 22 {
+		baseJobs[4001+i] = i
+		baseJobs[4023+i] = i
+	}
+	baseJobs[4045] = 23
+}
+
+// SpriteFallback is a step of the chain tried when a player's body sprite
+// is missing from the archive.
+type SpriteFallback string
+
+// Sprite fallback steps.
+const (
+	FallbackJob     SpriteFallback = "job"      // The plain job body, for a missing costume or mount
+	FallbackBaseJob SpriteFallback = "base_job" // The bodies of the jobs it grows from
+	FallbackNovice  SpriteFallback = "novice"   // The novice body
+)
+
+// DefaultSpriteFallbacks is the fallback chain used unless configured
+// otherwise: costume, then base job, then novice.
+var DefaultSpriteFallbacks = []SpriteFallback{FallbackJob, FallbackBaseJob, FallbackNovice}
+
+// SpriteLayer is one sprite of a unit's layer stack, by its GRF path
+// without the .spr/.act extension.
+type SpriteLayer struct {
+	Path      string
+	Fallbacks []string // Tried in order when Path is missing
+	Attach    bool     // Placed on the previous layer's anchor point, like heads
+	Garment   bool     // Animated by its own ACT, like wings and capes
+	Behind    bool     // Drawn under the layer it's attached to
+}
+
+// SpriteLayers resolves the sprite layer stack of e, bottom first, for an
+// ACT direction (0-7, south first). Players are a body (the mounted body
+// while riding) with the head attached, and a cart behind them, or in
+// front when they face away from the camera. A garment attaches to the
+// body the same way, hanging behind it unless they face away. A player's
+// body falls back through chain when missing. Returns nil if e's sprites
+// aren't known.
+func SpriteLayers(e *Entity, dir int, chain []SpriteFallback) []SpriteLayer {
+	switch e.Type {
+	case TypePlayer:
+		return playerLayers(e, dir, chain)
+	case TypePet:
+		if name, ok := petSprites[e.SpriteID]; ok {
+			return []SpriteLayer{{Path: monsterSpriteDir + name}}
+		}
+	}
+	return nil
+}
+
+func playerLayers(e *Entity, dir int, chain []SpriteFallback) []SpriteLayer {
+	sex := sexFolder(e.Male)
+	body := bodyLayer(e, sex, chain)
+	if body.Path == "" {
+		return nil
+	}
+	layers := []SpriteLayer{body}
+	if robe := garmentLayer(e, sex); robe.Path != "" {
+		robe.Behind = dir < DirNW || dir > DirNE
+		layers = append(layers, robe)
+	}
+	layers = append(layers, SpriteLayer{Path: fmt.Sprintf("%s%s/%d_%s", headSpriteDir, sex, max(e.HairStyle, 1), sex), Attach: true})
+
+	level := CartLevel(e.Option)
+	if level == 0 {
+		return layers
+	}
+	cart := SpriteLayer{Path: fmt.Sprintf("%s손수레%d", effectSpriteDir, level)}
+	if dir >= DirNW && dir <= DirNE {
+		return append(layers, cart)
+	}
+	return append([]SpriteLayer{cart}, layers...)
+}
+
+// garmentLayer returns a player's garment, which has a sprite for each job:
+// nothing if they wear none or their job's sprite isn't known. Garments
+// without a known name look for a folder named by their ID.
+func garmentLayer(e *Entity, sex string) SpriteLayer {
+	job, ok := jobSprites[e.Job]
+	if e.Robe <= 0 || !ok {
+		return SpriteLayer{}
+	}
+	name, ok := robeSprites[e.Robe]
+	if !ok {
+		name = strconv.Itoa(e.Robe)
+	}
+	return SpriteLayer{Path: robeSpriteDir + name + "/" + sex + "/" + job + "_" + sex, Attach: true, Garment: true}
+}
+
+// bodyLayer returns a player's body: the mounted body while riding, else
+// the costume body if one is worn, else the job's. Jobs without a known
+// sprite look for one named by their ID, which is how custom job sprites
+// are usually packed, and fall back along chain.
+func bodyLayer(e *Entity, sex string, chain []SpriteFallback) SpriteLayer {
+	plain := bodySprite(e.Job, sex)
+	name, known := jobSprites[e.Job]
+	var path string
+	switch {
+	case !known:
+		if len(chain) == 0 {
+			return SpriteLayer{}
+		}
+		path = fmt.Sprintf("%s%s/%d_%s", bodySpriteDir, sex, e.Job, sex)
+		plain = ""
+	case e.Option&OptionRiding != 0 && mountedSprites[e.Job] != "":
+		path = bodySpriteDir + sex + "/" + mountedSprites[e.Job] + "_" + sex
+	case e.BodyStyle > 0:
+		path = fmt.Sprintf("%s%s/costume_%d/%s_%s_%d", bodySpriteDir, sex, e.BodyStyle, name, sex, e.BodyStyle)
+	default:
+		path = plain
+	}
+
+	layer := SpriteLayer{Path: path}
+	add := func(p string) {
+		if p != "" && p != path && !slices.Contains(layer.Fallbacks, p) {
+			layer.Fallbacks = append(layer.Fallbacks, p)
+		}
+	}
+	for _, step := range chain {
+		switch step {
+		case FallbackJob:
+			add(plain)
+		case FallbackBaseJob:
+			for job, ok := baseJobs[e.Job]; ok; job, ok = baseJobs[job] {
+				add(bodySprite(job, sex))
+			}
+		case FallbackNovice:
+			add(bodySprite(0, sex))
+		}
+	}
+	return layer
+}
+
+// bodySprite returns the path of a job's body sprite, or "" if the job's
+// sprite isn't known.
+func bodySprite(job int, sex string) string {
+	name, ok := jobSprites[job]
+	if !ok {
+		return ""
+	}
+	return bodySpriteDir + sex + "/" + name + "_" + sex
+}
+
+// sexFolder returns the folder and file suffix of a sex's sprites.
+func sexFolder(male bool) string {
+	if male {
+		return "남"
+	}
+	return "여"
+}
+
+// IsMounted reports whether option flags a unit riding any mount.
+func IsMounted(option uint32) bool {
+	return option&OptionMounted != 0
+}
+
+// CartLevel returns the cart (1-5) a unit with option pushes, or 0.
+func CartLevel(option uint32) int {
+	switch {
+	case option&OptionCart5 != 0:
+		return 5
+	case option&OptionCart4 != 0:
+		return 4
+	case option&OptionCart3 != 0:
+		return 3
+	case option&OptionCart2 != 0:
+		return 2
+	case option&OptionCart1 != 0:
+		return 1
+	}
+	return 0
+}
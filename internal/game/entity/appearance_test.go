@@ -0,0 +1,135 @@
+package entity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSpriteLayers(t *testing.T) {
+	const (
+		knight   = "data/sprite/인간족/몸통/남/기사_남"
+		peco     = "data/sprite/인간족/몸통/남/페코페코_기사_남"
+		head     = "data/sprite/인간족/머리통/남/12_남"
+		cart     = "data/sprite/이팩트/손수레3"
+		merchant = "data/sprite/인간족/몸통/여/상인_여"
+		herHead  = "data/sprite/인간족/머리통/여/1_여"
+		wings    = "data/sprite/로브/천사날개/여/상인_여"
+	)
+	player := func(job int, male bool, hair int, option uint32) *Entity {
+		e := NewEntity(1, TypePlayer)
+		e.Job, e.Male, e.HairStyle, e.Option = job, male, hair, option
+		return e
+	}
+	robed := func(robe int) *Entity {
+		e := player(5, false, 1, 0)
+		e.Robe = robe
+		return e
+	}
+	pet := NewEntity(2, TypePet)
+	pet.SpriteID = 1002
+
+	tests := []struct {
+		name string
+		e    *Entity
+		dir  int
+		want []SpriteLayer
+	}{
+		{"on foot", player(7, true, 12, 0), DirS, []SpriteLayer{{Path: knight}, {Path: head, Attach: true}}},
+		{"riding", player(7, true, 12, OptionRiding), DirS, []SpriteLayer{{Path: peco}, {Path: head, Attach: true}}},
+		{"unsupported mount", player(7, true, 12, OptionDragon1), DirS, []SpriteLayer{{Path: knight}, {Path: head, Attach: true}}},
+		{"riding flag on a job without mount", player(5, false, 0, OptionRiding), DirS, []SpriteLayer{{Path: merchant}, {Path: herHead, Attach: true}}},
+		{"cart behind", player(5, false, 1, OptionCart3), DirSW, []SpriteLayer{{Path: cart}, {Path: merchant}, {Path: herHead, Attach: true}}},
+		{"cart in front facing away", player(5, false, 1, OptionCart3), DirN, []SpriteLayer{{Path: merchant}, {Path: herHead, Attach: true}, {Path: cart}}},
+		{"pet", pet, DirS, []SpriteLayer{{Path: "data/sprite/몬스터/poring"}}},
+		{"unknown job", player(4054, true, 1, 0), DirS, nil},
+		{"monster", NewEntity(3, TypeMonster), DirS, nil},
+		{"garment behind", robed(1), DirS, []SpriteLayer{{Path: merchant}, {Path: wings, Attach: true, Garment: true, Behind: true}, {Path: herHead, Attach: true}}},
+		{"garment in front facing away", robed(1), DirN, []SpriteLayer{{Path: merchant}, {Path: wings, Attach: true, Garment: true}, {Path: herHead, Attach: true}}},
+		{"unnamed garment", robed(99), DirE, []SpriteLayer{{Path: merchant}, {Path: "data/sprite/로브/99/여/상인_여", Attach: true, Garment: true, Behind: true}, {Path: herHead, Attach: true}}},
+	}
+
+	for _, tt := range tests {
+		if got := SpriteLayers(tt.e, tt.dir, nil); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: SpriteLayers = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSpriteFallbacks(t *testing.T) {
+	const (
+		body   = "data/sprite/인간족/몸통/남/"
+		knight = body + "기사_남"
+		novice = body + "초보자_남"
+	)
+	player := func(job, style int, option uint32) *Entity {
+		e := NewEntity(1, TypePlayer)
+		e.Job, e.Male, e.BodyStyle, e.Option = job, true, style, option
+		return e
+	}
+
+	tests := []struct {
+		name  string
+		e     *Entity
+		chain []SpriteFallback
+		path  string
+		want  []string
+	}{
+		{"job sprite", player(7, 0, 0), DefaultSpriteFallbacks, knight, []string{body + "검사_남", novice}},
+		{"novice", player(0, 0, 0), DefaultSpriteFallbacks, novice, nil},
+		{"costume", player(7, 1, 0), DefaultSpriteFallbacks, body + "costume_1/기사_남_1", []string{knight, body + "검사_남", novice}},
+		{"mount", player(7, 1, OptionRiding), []SpriteFallback{FallbackJob}, body + "페코페코_기사_남", []string{knight}},
+		{"third job", player(4054, 0, 0), DefaultSpriteFallbacks, body + "4054_남", []string{body + "로드나이트_남", knight, body + "검사_남", novice}},
+		{"baby job", player(4030, 0, 0), []SpriteFallback{FallbackBaseJob}, body + "4030_남", []string{knight, body + "검사_남", novice}},
+		{"novice only", player(4054, 0, 0), []SpriteFallback{FallbackNovice}, body + "4054_남", []string{novice}},
+	}
+
+	for _, tt := range tests {
+		layers := SpriteLayers(tt.e, DirS, tt.chain)
+		if len(layers) == 0 {
+			t.Errorf("%s: no layers", tt.name)
+			continue
+		}
+		if got := layers[0]; got.Path != tt.path || !reflect.DeepEqual(got.Fallbacks, tt.want) {
+			t.Errorf("%s: body = %s %v, want %s %v", tt.name, got.Path, got.Fallbacks, tt.path, tt.want)
+		}
+	}
+}
+
+func TestJobName(t *testing.T) {
+	if got := JobName(7); got != "Knight" {
+		t.Errorf("JobName(7) = %q, want Knight", got)
+	}
+	if got := JobName(9999); got != "Job 9999" {
+		t.Errorf("JobName(9999) = %q, want Job 9999", got)
+	}
+}
+
+func TestCartLevel(t *testing.T) {
+	tests := []struct {
+		option uint32
+		want   int
+	}{
+		{0, 0},
+		{OptionRiding, 0},
+		{OptionCart1, 1},
+		{OptionCart2 | OptionFalcon, 2},
+		{OptionCart5, 5},
+	}
+
+	for _, tt := range tests {
+		if got := CartLevel(tt.option); got != tt.want {
+			t.Errorf("CartLevel(%#x) = %d, want %d", tt.option, got, tt.want)
+		}
+	}
+}
+
+func TestIsMounted(t *testing.T) {
+	for _, option := range []uint32{OptionRiding, OptionDragon3, OptionWugRider, OptionMadogear} {
+		if !IsMounted(option | OptionCart1) {
+			t.Errorf("IsMounted(%#x) = false", option)
+		}
+	}
+	if IsMounted(OptionCart1 | OptionFalcon) {
+		t.Error("IsMounted(cart, falcon) = true")
+	}
+}
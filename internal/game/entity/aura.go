@@ -0,0 +1,41 @@
+package entity
+
+// AuraLevel is the base level from which characters wear the aura, the
+// classic 99 cap.
+const AuraLevel = 99
+
+// Aura is the set of idle effects a character wears for their level and
+// job, drawn around them by the scene.
+type Aura uint8
+
+const (
+	AuraMaxLevel      Aura = 1 << iota // Level aura, from AuraLevel
+	AuraRebirth                        // With AuraMaxLevel: a rebirth class, whose aura is gold
+	AuraSoulLink                       // Soul Linker: spirits circling the body
+	AuraStarGladiator                  // Star Gladiator: motes of starlight
+)
+
+// jobAuras maps job IDs to their job-specific idle effects.
+var jobAuras = map[int]Aura{
+	4047: AuraStarGladiator,
+	4048: AuraStarGladiator, // Union
+	4049: AuraSoulLink,
+}
+
+// AuraOf returns the effects a character of a job and base level wears.
+func AuraOf(job, level int) Aura {
+	a := jobAuras[job]
+	if level >= AuraLevel {
+		a |= AuraMaxLevel
+		if isRebirthJob(job) {
+			a |= AuraRebirth
+		}
+	}
+	return a
+}
+
+// isRebirthJob reports whether job is a transcendent class, High Novice
+// through the mounted Paladin.
+func isRebirthJob(job int) bool {
+	return job >= 4001 && job <= 4022
+}
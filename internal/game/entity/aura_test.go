@@ -0,0 +1,28 @@
+package entity
+
+import "testing"
+
+func TestAuraOf(t *testing.T) {
+	tests := []struct {
+		name  string
+		job   int
+		level int
+		want  Aura
+	}{
+		{"low level", 7, 98, 0},
+		{"max level", 7, AuraLevel, AuraMaxLevel},
+		{"over max level", 23, 150, AuraMaxLevel},
+		{"rebirth", 4008, 99, AuraMaxLevel | AuraRebirth},
+		{"rebirth below max", 4008, 80, 0},
+		{"job effect", 4049, 50, AuraSoulLink},
+		{"job effect at max", 4047, 99, AuraStarGladiator | AuraMaxLevel},
+		{"baby class", 4023, 99, AuraMaxLevel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AuraOf(tt.job, tt.level); got != tt.want {
+				t.Errorf("AuraOf(%d, %d) = %b, want %b", tt.job, tt.level, got, tt.want)
+			}
+		})
+	}
+}
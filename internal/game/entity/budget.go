@@ -0,0 +1,116 @@
+package entity
+
+import (
+	"cmp"
+	"slices"
+)
+
+// UpdateBudget limits per-frame entity work on crowded maps. Entities near
+// the player update every frame, farther ones at lower rates, and past
+// MaxActive the lowest-priority entities are culled: not updated or drawn.
+// Priority is the player, then party members, then nearby, then far
+// entities, closest first.
+//
+// The zero value updates every entity every frame.
+type UpdateBudget struct {
+	NearRadius  float32 // World units; entities within update every frame
+	FarRadius   float32 // World units; entities beyond update at FarInterval
+	MidInterval float64 // Seconds between updates from NearRadius to FarRadius
+	FarInterval float64 // Seconds between updates beyond FarRadius
+	MaxActive   int     // Hard cap on updated and drawn entities, 0 for none
+}
+
+// DefaultUpdateBudget returns the budget entity managers start with: full
+// rate within 7 cells, 15 Hz out to the 14-cell view range, 5 Hz beyond,
+// and at most 150 active entities.
+func DefaultUpdateBudget() UpdateBudget {
+	const tileSize = 5.0
+	return UpdateBudget{
+		NearRadius:  7 * tileSize,
+		FarRadius:   14 * tileSize,
+		MidInterval: 1.0 / 15,
+		FarInterval: 1.0 / 5,
+		MaxActive:   150,
+	}
+}
+
+// interval returns the update interval for an entity distSq (squared world
+// units) from the player.
+func (b UpdateBudget) interval(distSq float32) float64 {
+	switch {
+	case distSq <= b.NearRadius*b.NearRadius:
+		return 0
+	case distSq <= b.FarRadius*b.FarRadius:
+		return b.MidInterval
+	default:
+		return b.FarInterval
+	}
+}
+
+// UpdateStats counts how the last Manager.Update spent its budget.
+type UpdateStats struct {
+	Updated   int // Entities updated this frame
+	Throttled int // Entities waiting for their next (lower-rate) update
+	Culled    int // Entities over MaxActive
+}
+
+// Update priorities, lowest first.
+const (
+	priorityPlayer = iota
+	priorityParty
+	priorityNear
+	priorityFar
+)
+
+// rankedEntity is an entity with its update priority for one frame.
+type rankedEntity struct {
+	e        *Entity
+	priority int
+	distSq   float32
+}
+
+// SetUpdateBudget replaces the manager's update budget.
+func (m *Manager) SetUpdateBudget(b UpdateBudget) {
+	m.budget = b
+}
+
+// UpdateStats returns how the last Update spent the budget.
+func (m *Manager) UpdateStats() UpdateStats {
+	return m.stats
+}
+
+// rank orders the entities by update priority into m.ranked.
+func (m *Manager) rank() {
+	var px, pz float32
+	if m.player != nil {
+		px, pz = m.player.Position.X, m.player.Position.Z
+	}
+	nearSq := m.budget.NearRadius * m.budget.NearRadius
+
+	m.ranked = m.ranked[:0]
+	for _, e := range m.entities {
+		dx, dz := e.Position.X-px, e.Position.Z-pz
+		r := rankedEntity{e: e, distSq: dx*dx + dz*dz}
+		switch {
+		case e == m.player:
+			r.priority = priorityPlayer
+		case e.InParty:
+			r.priority = priorityParty
+		case r.distSq <= nearSq:
+			r.priority = priorityNear
+		default:
+			r.priority = priorityFar
+		}
+		m.ranked = append(m.ranked, r)
+	}
+
+	slices.SortFunc(m.ranked, func(a, b rankedEntity) int {
+		if c := cmp.Compare(a.priority, b.priority); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.distSq, b.distSq); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.e.ID, b.e.ID) // Stable across frames
+	})
+}
@@ -0,0 +1,77 @@
+package entity
+
+import "testing"
+
+func TestManagerUpdateBudget(t *testing.T) {
+	m := NewManager()
+	m.SetUpdateBudget(UpdateBudget{
+		NearRadius:  10,
+		FarRadius:   50,
+		MidInterval: 0.1,
+		FarInterval: 0.5,
+		MaxActive:   4,
+	})
+
+	player := NewEntity(1, TypePlayer)
+	m.SetPlayer(player)
+
+	spawn := func(id uint32, x float32) *Entity {
+		e := m.Spawn(id, TypePlayer)
+		e.SetPosition(x, 0, 0)
+		return e
+	}
+	near := spawn(2, 5)
+	mid := spawn(3, 30)
+	far := spawn(4, 80)
+	culled := spawn(5, 90)
+	party := spawn(6, 200)
+	party.InParty = true
+
+	m.Update(0.05)
+
+	if !far.Culled || !culled.Culled {
+		t.Errorf("far entities not culled: far=%v culled=%v", far.Culled, culled.Culled)
+	}
+	if party.Culled || near.Culled || player.Culled {
+		t.Error("player, party member or nearby entity culled")
+	}
+	if near.AnimTime != 0.05 {
+		t.Errorf("near AnimTime = %v, want 0.05 (every frame)", near.AnimTime)
+	}
+	if mid.AnimTime != 0 {
+		t.Errorf("mid AnimTime = %v, want 0 (throttled)", mid.AnimTime)
+	}
+
+	m.Update(0.05)
+	if mid.AnimTime != 0.1 {
+		t.Errorf("mid AnimTime = %v, want 0.1 (accumulated)", mid.AnimTime)
+	}
+	if far.AnimTime != 0 {
+		t.Errorf("culled entity updated: AnimTime = %v", far.AnimTime)
+	}
+
+	want := UpdateStats{Updated: 3, Throttled: 1, Culled: 2}
+	if got := m.UpdateStats(); got != want {
+		t.Errorf("UpdateStats() = %+v, want %+v", got, want)
+	}
+
+	vis := m.AllVisible()
+	for _, e := range vis {
+		if e.Culled {
+			t.Errorf("AllVisible returned culled entity %d", e.ID)
+		}
+	}
+}
+
+func TestZeroBudgetUpdatesEverything(t *testing.T) {
+	m := NewManager()
+	m.SetUpdateBudget(UpdateBudget{})
+	for id := uint32(1); id <= 3; id++ {
+		m.Spawn(id, TypeMonster).SetPosition(float32(id)*1000, 0, 0)
+	}
+
+	m.Update(0.016)
+	if got := m.UpdateStats(); got.Updated != 3 || got.Throttled != 0 || got.Culled != 0 {
+		t.Errorf("UpdateStats() = %+v, want all 3 updated", got)
+	}
+}
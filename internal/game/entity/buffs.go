@@ -0,0 +1,159 @@
+package entity
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// BuffWarnTime is how long before a buff wears off its icon flashes and
+// the player is warned.
+const BuffWarnTime = 10 * time.Second
+
+// statusNames are the names of the statuses (rAthena EFST_*) most often
+// on a player: skill buffs, speed potions and stat foods. Others show by
+// number.
+var statusNames = map[uint16]string{
+	0:   "Provoke",
+	1:   "Endure",
+	2:   "Two-Hand Quicken",
+	3:   "Improve Concentration",
+	4:   "Hiding",
+	5:   "Cloaking",
+	6:   "Enchant Poison",
+	7:   "Poison React",
+	8:   "Quagmire",
+	9:   "Angelus",
+	10:  "Blessing",
+	11:  "Signum Crucis",
+	12:  "Increase AGI",
+	13:  "Decrease AGI",
+	14:  "Slow Poison",
+	15:  "Impositio Manus",
+	16:  "Suffragium",
+	17:  "Aspersio",
+	18:  "Benedictio",
+	19:  "Kyrie Eleison",
+	20:  "Magnificat",
+	21:  "Gloria",
+	22:  "Lex Aeterna",
+	23:  "Adrenaline Rush",
+	24:  "Weapon Perfection",
+	25:  "Power-Thrust",
+	26:  "Maximize Power",
+	30:  "Loud Exclamation",
+	31:  "Energy Coat",
+	35:  "Overweight 50%",
+	36:  "Overweight 90%",
+	37:  "Concentration Potion",
+	38:  "Awakening Potion",
+	39:  "Berserk Potion",
+	241: "STR Food",
+	242: "AGI Food",
+	243: "VIT Food",
+	244: "DEX Food",
+	245: "INT Food",
+	246: "LUK Food",
+}
+
+// StatusName returns the name of a status.
+func StatusName(status uint16) string {
+	if name, ok := statusNames[status]; ok {
+		return name
+	}
+	return fmt.Sprintf("Status %d", status)
+}
+
+// Buff is a status on the player, from a skill, potion or food.
+type Buff struct {
+	Status  uint16
+	Total   time.Duration // Zero without a timer
+	Expires time.Time     // Zero without a timer
+	warned  bool
+}
+
+// Timed reports whether the buff wears off by itself.
+func (b Buff) Timed() bool {
+	return !b.Expires.IsZero()
+}
+
+// Remaining returns the time left until the buff wears off.
+func (b Buff) Remaining(now time.Time) time.Duration {
+	if !b.Timed() {
+		return 0
+	}
+	return max(0, b.Expires.Sub(now))
+}
+
+// Fraction returns how much of the buff's time is left, 1 when it was
+// just put on or has no timer.
+func (b Buff) Fraction(now time.Time) float32 {
+	if !b.Timed() || b.Total <= 0 {
+		return 1
+	}
+	return min(1, float32(b.Remaining(now))/float32(b.Total))
+}
+
+// Expiring reports whether the buff wears off within BuffWarnTime.
+func (b Buff) Expiring(now time.Time) bool {
+	return b.Timed() && b.Remaining(now) <= BuffWarnTime
+}
+
+// Buffs tracks the timers of the player's buffs from the status changes
+// the server sends, so their countdowns run locally between packets. The
+// times passed in must come from the clock game logic runs on.
+type Buffs struct {
+	list []Buff // In the order they were put on
+}
+
+// Set puts a status on, with total and left its duration and the time
+// it has left (zero without a timer), or takes it off. Putting on a
+// status already on renews it.
+func (b *Buffs) Set(status uint16, on bool, total, left time.Duration, now time.Time) {
+	i := slices.IndexFunc(b.list, func(buff Buff) bool { return buff.Status == status })
+	if !on {
+		if i >= 0 {
+			b.list = slices.Delete(b.list, i, i+1)
+		}
+		return
+	}
+	buff := Buff{Status: status}
+	if left > 0 {
+		buff.Total = max(total, left)
+		buff.Expires = now.Add(left)
+	}
+	if i >= 0 {
+		b.list[i] = buff
+	} else {
+		b.list = append(b.list, buff)
+	}
+}
+
+// Clear takes every buff off, as when the player dies or leaves the map.
+func (b *Buffs) Clear() {
+	b.list = nil
+}
+
+// Active returns the buffs on, dropping those whose time is up.
+func (b *Buffs) Active(now time.Time) []Buff {
+	b.list = slices.DeleteFunc(b.list, func(buff Buff) bool {
+		return buff.Timed() && !now.Before(buff.Expires)
+	})
+	return slices.Clone(b.list)
+}
+
+// Expiring returns the buffs that have come within BuffWarnTime of
+// wearing off since the last call, each once per time it's put on.
+// Buffs lasting no longer than that aren't warned about.
+func (b *Buffs) Expiring(now time.Time) []Buff {
+	var expiring []Buff
+	for i := range b.list {
+		buff := &b.list[i]
+		if buff.warned || buff.Total <= BuffWarnTime || !buff.Expiring(now) || !now.Before(buff.Expires) {
+			continue
+		}
+		buff.warned = true
+		expiring = append(expiring, *buff)
+	}
+	return expiring
+}
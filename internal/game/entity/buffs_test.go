@@ -0,0 +1,71 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuffs(t *testing.T) {
+	const (
+		blessing = 10
+		agi      = 12
+		riding   = 27
+	)
+	start := time.Unix(1000, 0)
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+
+	var b Buffs
+	b.Set(blessing, true, 240*time.Second, 60*time.Second, start)
+	b.Set(agi, true, 5*time.Second, 5*time.Second, start)
+	b.Set(riding, true, 0, 0, start)
+
+	active := b.Active(at(3 * time.Second))
+	if len(active) != 3 || active[1].Status != agi || active[1].Remaining(at(3*time.Second)) != 2*time.Second {
+		t.Fatalf("Active() = %+v, want blessing, agi with 2s left, riding", active)
+	}
+
+	// Increase AGI has run out
+	active = b.Active(at(30 * time.Second))
+	if len(active) != 2 || active[0].Status != blessing || active[1].Status != riding {
+		t.Fatalf("Active() = %+v, want blessing, riding", active)
+	}
+	if got := active[0].Fraction(at(30 * time.Second)); got != 0.125 {
+		t.Errorf("blessing Fraction() = %v, want 0.125 of its 240s", got)
+	}
+	if active[1].Timed() || active[1].Fraction(start) != 1 || active[1].Expiring(start) {
+		t.Errorf("riding = %+v, want untimed", active[1])
+	}
+
+	if got := b.Expiring(at(45 * time.Second)); len(got) != 0 {
+		t.Errorf("Expiring() at 45s = %+v, want none", got)
+	}
+	got := b.Expiring(at(51 * time.Second))
+	if len(got) != 1 || got[0].Status != blessing || !got[0].Expiring(at(51*time.Second)) {
+		t.Errorf("Expiring() at 51s = %+v, want blessing", got)
+	}
+	if got := b.Expiring(at(52 * time.Second)); len(got) != 0 {
+		t.Errorf("Expiring() warned twice: %+v", got)
+	}
+
+	// Renewed: its timer starts over and it's warned about again
+	b.Set(blessing, true, 240*time.Second, 240*time.Second, at(55*time.Second))
+	if got := b.Active(at(55 * time.Second))[0].Remaining(at(55 * time.Second)); got != 240*time.Second {
+		t.Errorf("renewed Remaining() = %v, want 240s", got)
+	}
+	if got := b.Expiring(at(290 * time.Second)); len(got) != 1 {
+		t.Errorf("renewed Expiring() = %+v, want blessing", got)
+	}
+
+	active = b.Active(at(60 * time.Second))
+	if len(active) != 2 || active[1].Status != riding {
+		t.Errorf("Active() at 60s = %+v, want blessing and riding", active)
+	}
+	b.Set(riding, false, 0, 0, at(60*time.Second))
+	if active = b.Active(at(300 * time.Second)); len(active) != 0 {
+		t.Errorf("Active() after all wore off = %+v", active)
+	}
+
+	if StatusName(blessing) != "Blessing" || StatusName(9999) != "Status 9999" {
+		t.Errorf("StatusName() = %q, %q", StatusName(blessing), StatusName(9999))
+	}
+}
@@ -0,0 +1,138 @@
+package entity
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Faultbox/midgard-ro/pkg/formats"
+	"github.com/Faultbox/midgard-ro/pkg/math"
+)
+
+// ACT frame timing: intervals count ticks of actTick, and actions without
+// one play at defaultActInterval.
+const (
+	actTick            = 24 * time.Millisecond
+	defaultActInterval = 4
+)
+
+// HitDelay returns how long after an attack animation starts its hit
+// lands: at the action's attack frame, with the animation stretched over
+// motion (the attack motion the server sent) or, if that's 0, played at
+// the ACT's own frame rate. ok is false if the action has no attack frame.
+func HitDelay(act *formats.ACT, action int, motion time.Duration) (delay time.Duration, ok bool) {
+	if act == nil {
+		return 0, false
+	}
+	frame := act.AttackFrame(action)
+	if frame < 0 {
+		return 0, false
+	}
+	if motion > 0 {
+		return motion * time.Duration(frame) / time.Duration(len(act.Actions[action].Frames)), true
+	}
+	interval := float32(defaultActInterval)
+	if action < len(act.Intervals) && act.Intervals[action] > 0 {
+		interval = act.Intervals[action]
+	}
+	return time.Duration(float32(frame) * interval * float32(actTick)), true
+}
+
+// Hit is damage waiting for the attack animation to strike.
+type Hit struct {
+	SourceID uint32
+	TargetID uint32
+	Damage   int // 0 for a miss
+	Critical bool
+	Sound    string    // Played when the hit lands, "" for the default
+	At       time.Time // When the hit lands
+}
+
+// CombatQueue holds hits until their attack animations strike.
+type CombatQueue struct {
+	hits []Hit // By landing time
+}
+
+// Schedule queues a hit.
+func (q *CombatQueue) Schedule(h Hit) {
+	i := sort.Search(len(q.hits), func(i int) bool { return q.hits[i].At.After(h.At) })
+	q.hits = append(q.hits, Hit{})
+	copy(q.hits[i+1:], q.hits[i:])
+	q.hits[i] = h
+}
+
+// Due removes and returns the hits that have landed by now, in the order
+// they landed.
+func (q *CombatQueue) Due(now time.Time) []Hit {
+	n := sort.Search(len(q.hits), func(i int) bool { return q.hits[i].At.After(now) })
+	if n == 0 {
+		return nil
+	}
+	due := make([]Hit, n)
+	copy(due, q.hits[:n])
+	q.hits = append(q.hits[:0], q.hits[n:]...)
+	return due
+}
+
+// Len returns the number of hits waiting.
+func (q *CombatQueue) Len() int {
+	return len(q.hits)
+}
+
+// Clear drops the waiting hits, e.g. on a map change.
+func (q *CombatQueue) Clear() {
+	q.hits = q.hits[:0]
+}
+
+// DamageKind tells whose damage a number shows, which picks its color.
+type DamageKind uint8
+
+const (
+	DamageOther DamageKind = iota // Between other units
+	DamageDealt                   // Dealt by the player
+	DamageTaken                   // Taken by the player
+)
+
+// Damage number motion: numbers rise DamageNumberRise world units over
+// DamageNumberLife seconds, fading out over the last third.
+const (
+	DamageNumberLife = 1.5
+	DamageNumberRise = 15.0
+)
+
+// DamageNumber is a damage amount floating up from a unit that was hit.
+type DamageNumber struct {
+	Position math.Vec3 // Where the hit landed, at the target's feet
+	Amount   int       // 0 for a miss
+	Kind     DamageKind
+	Critical bool
+	Age      float64 // Seconds
+}
+
+// Offset returns how far the number has risen.
+func (d *DamageNumber) Offset() float32 {
+	t := min(d.Age/DamageNumberLife, 1)
+	// Ease out: fast at first, settling at the top
+	return float32(DamageNumberRise * (1 - (1-t)*(1-t)))
+}
+
+// Alpha returns the number's opacity.
+func (d *DamageNumber) Alpha() float32 {
+	const fadeStart = DamageNumberLife * 2 / 3
+	if d.Age <= fadeStart {
+		return 1
+	}
+	return float32(max(0, 1-(d.Age-fadeStart)/(DamageNumberLife-fadeStart)))
+}
+
+// AgeDamageNumbers advances damage numbers by dt seconds, dropping the
+// ones that have faded out.
+func AgeDamageNumbers(nums []DamageNumber, dt float64) []DamageNumber {
+	kept := nums[:0]
+	for _, d := range nums {
+		d.Age += dt
+		if d.Age < DamageNumberLife {
+			kept = append(kept, d)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,101 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Faultbox/midgard-ro/pkg/formats"
+)
+
+// attackACT returns an ACT with a 5-frame attack action striking on frame
+// 3, and an action without an attack frame.
+func attackACT() *formats.ACT {
+	act := &formats.ACT{
+		Events:    []string{formats.EventAttack},
+		Actions:   []formats.Action{{Frames: make([]formats.Frame, 5)}, {Frames: make([]formats.Frame, 2)}},
+		Intervals: []float32{5, 4},
+	}
+	for _, a := range act.Actions {
+		for i := range a.Frames {
+			a.Frames[i].EventID = -1
+		}
+	}
+	act.Actions[0].Frames[3].Trigger = formats.TriggerAttack
+	return act
+}
+
+func TestHitDelay(t *testing.T) {
+	act := attackACT()
+	tests := []struct {
+		name   string
+		act    *formats.ACT
+		action int
+		motion time.Duration
+		want   time.Duration
+		ok     bool
+	}{
+		{"stretched over the attack motion", act, 0, 500 * time.Millisecond, 300 * time.Millisecond, true},
+		{"ACT frame rate", act, 0, 0, 3 * 5 * actTick, true},
+		{"no attack frame", act, 1, 500 * time.Millisecond, 0, false},
+		{"no ACT", nil, 0, 500 * time.Millisecond, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := HitDelay(tt.act, tt.action, tt.motion)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("HitDelay = %v, %v, want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+
+	act.Intervals = nil
+	if got, _ := HitDelay(act, 0, 0); got != 3*defaultActInterval*actTick {
+		t.Errorf("HitDelay without intervals = %v, want %v", got, 3*defaultActInterval*actTick)
+	}
+}
+
+func TestCombatQueue(t *testing.T) {
+	start := time.Unix(1000, 0)
+	var q CombatQueue
+	q.Schedule(Hit{TargetID: 2, At: start.Add(300 * time.Millisecond)})
+	q.Schedule(Hit{TargetID: 1, At: start.Add(100 * time.Millisecond)})
+	q.Schedule(Hit{TargetID: 3, At: start.Add(300 * time.Millisecond)})
+
+	if due := q.Due(start); len(due) != 0 {
+		t.Fatalf("Due before any hit landed = %+v", due)
+	}
+	due := q.Due(start.Add(300 * time.Millisecond))
+	if len(due) != 3 || due[0].TargetID != 1 || due[1].TargetID != 2 || due[2].TargetID != 3 {
+		t.Fatalf("Due = %+v, want targets 1, 2, 3", due)
+	}
+	if q.Len() != 0 {
+		t.Errorf("Len after Due = %d, want 0", q.Len())
+	}
+
+	q.Schedule(Hit{At: start})
+	q.Clear()
+	if q.Len() != 0 {
+		t.Errorf("Len after Clear = %d, want 0", q.Len())
+	}
+}
+
+func TestDamageNumberMotion(t *testing.T) {
+	d := DamageNumber{}
+	if d.Offset() != 0 || d.Alpha() != 1 {
+		t.Errorf("new number: offset %v alpha %v", d.Offset(), d.Alpha())
+	}
+	d.Age = DamageNumberLife / 2
+	if d.Offset() <= DamageNumberRise/2 || d.Alpha() != 1 {
+		t.Errorf("halfway: offset %v alpha %v, want past half height and opaque", d.Offset(), d.Alpha())
+	}
+	d.Age = DamageNumberLife
+	if d.Offset() != DamageNumberRise || d.Alpha() != 0 {
+		t.Errorf("end: offset %v alpha %v", d.Offset(), d.Alpha())
+	}
+
+	nums := AgeDamageNumbers([]DamageNumber{{Amount: 1}, {Amount: 2, Age: 1.4}}, 0.2)
+	if len(nums) != 1 || nums[0].Amount != 1 || !near(float32(nums[0].Age), 0.2) {
+		t.Errorf("AgeDamageNumbers = %+v, want the new number aged", nums)
+	}
+}
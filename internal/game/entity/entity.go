@@ -16,6 +16,7 @@ const (
 	TypeSkillEffect
 	TypeWarp
 	TypePortal
+	TypePet
 )
 
 // State represents the current state of an entity.
@@ -52,11 +53,25 @@ type Entity struct {
 	HairColor    int // Hair color
 	ClothesColor int // Clothes color
 	BodyPalette  int // Body palette
+	BodyStyle    int // Costume body style, 0 for the job's own
+	Robe         int // Garment view ID, 0 for none
+	Male         bool
+	Option       uint32 // Option* flags: cart, riding (see appearance.go)
+	BodyState    uint16 // Body state, e.g. BodyStateFrozen
+	HealthState  uint16 // Health flags, e.g. HealthPoisoned
+
+	// OwnerID is the owner of a pet, which follows them (0 if unknown).
+	OwnerID uint32
+
+	// Texture is the entity's sprite texture, taken from the scene's
+	// entity texture pool and returned by Manager.OnRelease (0 if none).
+	Texture uint32
 
 	// Display properties
 	ShowHP      bool       // Whether to show HP bar
 	ShowName    bool       // Whether to show name
 	NameColor   [4]float32 // Name display color (RGBA)
+	GuildID     uint32     // Guild ID (for players, 0 if none)
 	GuildName   string     // Guild name (for players)
 	GuildEmblem int        // Guild emblem ID
 	Title       string     // Title/party name
@@ -90,19 +105,36 @@ type Entity struct {
 	IsVisible    bool
 	IsTargetable bool
 	IsDead       bool
+	InParty      bool // Party member of the local player; never culled first
+	Culled       bool // Over the manager's budget: not updated or drawn
+	Leaving      bool // Fading out; the manager removes it once faded
+
+	// Fade is the opacity animation of units coming into view or leaving.
+	Fade Fade
+
+	// Flash is the tint of a unit's sprite when it's hit.
+	Flash Flash
+
+	pooled      bool    // Created by Manager.Spawn, recycled on removal
+	updateAccum float64 // Time since the last (throttled) update
 }
 
 // NewEntity creates a new entity.
 func NewEntity(id uint32, entityType Type) *Entity {
-	e := &Entity{
-		ID:           id,
-		Type:         entityType,
-		MoveSpeed:    1.0,
-		AnimSpeed:    1.0,
-		IsVisible:    true,
-		IsTargetable: true,
-		NameColor:    [4]float32{1, 1, 1, 1}, // White by default
-	}
+	e := &Entity{}
+	e.init(id, entityType)
+	return e
+}
+
+// init sets the defaults for a fresh entity of entityType.
+func (e *Entity) init(id uint32, entityType Type) {
+	e.ID = id
+	e.Type = entityType
+	e.MoveSpeed = 1.0
+	e.AnimSpeed = 1.0
+	e.IsVisible = true
+	e.IsTargetable = true
+	e.NameColor = [4]float32{1, 1, 1, 1} // White by default
 
 	// Set default display properties based on type
 	switch entityType {
@@ -123,9 +155,11 @@ func NewEntity(id uint32, entityType Type) *Entity {
 		e.ShowName = true
 		e.NameColor = [4]float32{0.7, 0.7, 1, 1} // Light blue for items
 		e.IsTargetable = false
+	case TypePet:
+		e.ShowHP = false
+		e.ShowName = true
+		e.IsTargetable = false
 	}
-
-	return e
 }
 
 // SetPosition sets the entity position.
@@ -187,10 +221,24 @@ func (e *Entity) Heal(amount int) {
 	}
 }
 
+// Opacity returns how opaque the entity is drawn, 0 to 1.
+func (e *Entity) Opacity() float32 {
+	return e.Fade.Alpha()
+}
+
+// Tint returns the sprite tint the entity is drawn with: its hit flash,
+// and its opacity as alpha.
+func (e *Entity) Tint() [4]float32 {
+	c := e.Flash.Tint()
+	return [4]float32{c[0], c[1], c[2], e.Opacity()}
+}
+
 // Update updates the entity state and animation.
 func (e *Entity) Update(dt float64) {
 	// Update animation time
 	e.AnimTime += dt * e.AnimSpeed
+	e.Fade.Advance(dt)
+	e.Flash.Advance(dt)
 
 	// Update state based on conditions
 	if e.IsDead && e.State != StateDead {
@@ -206,12 +254,23 @@ type Manager struct {
 	entities map[uint32]*Entity
 	player   *Entity // Reference to local player
 	playerID uint32  // Player entity ID
+
+	pool   *Pool
+	budget UpdateBudget
+	ranked []rankedEntity // Update order, reused every frame
+	stats  UpdateStats
+
+	// OnRelease, if set, is called when a spawned entity is removed, before
+	// it returns to the pool, so its GPU resources can be released too.
+	OnRelease func(e *Entity)
 }
 
 // NewManager creates a new entity manager.
 func NewManager() *Manager {
 	return &Manager{
 		entities: make(map[uint32]*Entity),
+		pool:     NewPool(DefaultPoolSize),
+		budget:   DefaultUpdateBudget(),
 	}
 }
 
@@ -220,9 +279,48 @@ func (m *Manager) Add(e *Entity) {
 	m.entities[e.ID] = e
 }
 
-// Remove removes an entity.
+// Spawn adds an entity taken from the manager's pool and returns it,
+// fading in. An existing entity with the same ID is replaced.
+func (m *Manager) Spawn(id uint32, entityType Type) *Entity {
+	m.Remove(id)
+	e := m.pool.Get(id, entityType)
+	e.Fade = FadeIn(SpawnFade)
+	m.entities[id] = e
+	return e
+}
+
+// Vanish fades an entity out over duration seconds after lingering for
+// linger seconds, e.g. a corpse, then removes it. It can't be targeted
+// meanwhile. Unknown IDs are ignored.
+func (m *Manager) Vanish(id uint32, linger, duration float64) {
+	e, ok := m.entities[id]
+	if !ok || e.Leaving {
+		return
+	}
+	e.Leaving = true
+	e.IsTargetable = false
+	e.Fade = FadeOut(e.Opacity(), linger, duration)
+}
+
+// Remove removes an entity. Spawned entities go back to the pool.
 func (m *Manager) Remove(id uint32) {
+	e, ok := m.entities[id]
+	if !ok {
+		return
+	}
 	delete(m.entities, id)
+	m.release(e)
+}
+
+// release hands a spawned entity back to the pool.
+func (m *Manager) release(e *Entity) {
+	if !e.pooled {
+		return
+	}
+	if m.OnRelease != nil {
+		m.OnRelease(e)
+	}
+	m.pool.Put(e)
 }
 
 // Get returns an entity by ID.
@@ -247,10 +345,38 @@ func (m *Manager) PlayerID() uint32 {
 	return m.playerID
 }
 
-// Update updates all entities.
+// Update updates the entities within the update budget. Throttled
+// entities receive the time accumulated since their last update.
 func (m *Manager) Update(dt float64) {
-	for _, e := range m.entities {
-		e.Update(dt)
+	m.stats = UpdateStats{}
+	m.rank()
+	for i, r := range m.ranked {
+		e := r.e
+		if m.budget.MaxActive > 0 && i >= m.budget.MaxActive {
+			e.Culled = true
+			m.stats.Culled++
+			continue
+		}
+		e.Culled = false
+
+		e.updateAccum += dt
+		if e.updateAccum < m.budget.interval(r.distSq) {
+			m.stats.Throttled++
+			continue
+		}
+		e.Update(e.updateAccum)
+		e.updateAccum = 0
+		m.stats.Updated++
+	}
+	clear(m.ranked) // Don't keep removed entities reachable
+
+	// Remove the entities that finished fading out, or that aren't drawn
+	// so have nothing to fade
+	for id, e := range m.entities {
+		if e.Leaving && (e.Fade.Done() || !e.IsVisible || e.Culled) {
+			delete(m.entities, id)
+			m.release(e)
+		}
 	}
 }
 
@@ -263,11 +389,11 @@ func (m *Manager) All() []*Entity {
 	return result
 }
 
-// AllVisible returns all visible entities.
+// AllVisible returns all visible entities that aren't culled.
 func (m *Manager) AllVisible() []*Entity {
 	result := make([]*Entity, 0, len(m.entities))
 	for _, e := range m.entities {
-		if e.IsVisible {
+		if e.IsVisible && !e.Culled {
 			result = append(result, e)
 		}
 	}
@@ -285,6 +411,24 @@ func (m *Manager) GetByType(entityType Type) []*Entity {
 	return result
 }
 
+// NearestOther returns the visible entity of the given type closest to the
+// world position x, z within radius, excluding the local player. Returns nil
+// if none is in range.
+func (m *Manager) NearestOther(x, z, radius float32, entityType Type) *Entity {
+	var best *Entity
+	bestDist := radius * radius
+	for _, e := range m.entities {
+		if e.Type != entityType || !e.IsVisible || e.Culled || e.Leaving || e == m.player {
+			continue
+		}
+		dx, dz := e.Position.X-x, e.Position.Z-z
+		if d := dx*dx + dz*dz; d <= bestDist {
+			best, bestDist = e, d
+		}
+	}
+	return best
+}
+
 // Count returns the total number of entities.
 func (m *Manager) Count() int {
 	return len(m.entities)
@@ -303,15 +447,19 @@ func (m *Manager) CountByType(entityType Type) int {
 
 // Clear removes all entities except the player.
 func (m *Manager) Clear() {
-	for id := range m.entities {
+	for id, e := range m.entities {
 		if id != m.playerID {
 			delete(m.entities, id)
+			m.release(e)
 		}
 	}
 }
 
 // ClearAll removes all entities including the player.
 func (m *Manager) ClearAll() {
+	for _, e := range m.entities {
+		m.release(e)
+	}
 	m.entities = make(map[uint32]*Entity)
 	m.player = nil
 	m.playerID = 0
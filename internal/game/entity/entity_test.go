@@ -0,0 +1,45 @@
+package entity
+
+import "testing"
+
+func TestManagerNearestOther(t *testing.T) {
+	m := NewManager()
+
+	self := NewEntity(1, TypePlayer)
+	self.SetPosition(100, 0, 100)
+	m.SetPlayer(self)
+
+	near := NewEntity(2, TypePlayer)
+	near.SetPosition(103, 0, 100)
+	m.Add(near)
+
+	far := NewEntity(3, TypePlayer)
+	far.SetPosition(120, 0, 100)
+	m.Add(far)
+
+	monster := NewEntity(4, TypeMonster)
+	monster.SetPosition(101, 0, 100)
+	m.Add(monster)
+
+	hidden := NewEntity(5, TypePlayer)
+	hidden.SetPosition(101, 0, 101)
+	hidden.IsVisible = false
+	m.Add(hidden)
+
+	tests := []struct {
+		name   string
+		x, z   float32
+		radius float32
+		want   *Entity
+	}{
+		{"skips self, hidden and other types", 100, 100, 5, near},
+		{"nothing in range", 100, 100, 2, nil},
+		{"picks closest", 119, 100, 30, far},
+	}
+
+	for _, tt := range tests {
+		if got := m.NearestOther(tt.x, tt.z, tt.radius, TypePlayer); got != tt.want {
+			t.Errorf("%s: NearestOther = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
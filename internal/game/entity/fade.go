@@ -0,0 +1,60 @@
+package entity
+
+// Fade durations in seconds.
+const (
+	SpawnFade  = 0.35 // Units coming into view
+	VanishFade = 0.35 // Units leaving view
+	CorpseFade = 1.0  // Corpses, once they've lingered
+	WarpFade   = 0.2  // Units teleporting away, hidden by the effect
+
+	// CorpseLinger is how long a dead unit lies before it fades.
+	CorpseLinger = 3.0
+)
+
+// Fade animates a unit's opacity: in when it appears, out when it leaves.
+// Every unit type fades the same way. The zero Fade is opaque.
+type Fade struct {
+	from, to float32
+	delay    float64 // Seconds before the fade starts
+	duration float64 // Seconds
+	elapsed  float64
+	active   bool
+}
+
+// FadeIn returns a fade from invisible to opaque over duration seconds.
+func FadeIn(duration float64) Fade {
+	return Fade{from: 0, to: 1, duration: duration, active: true}
+}
+
+// FadeOut returns a fade from opacity from to invisible over duration
+// seconds, starting after delay seconds.
+func FadeOut(from float32, delay, duration float64) Fade {
+	return Fade{from: from, to: 0, delay: delay, duration: duration, active: true}
+}
+
+// Advance moves the fade on by dt seconds.
+func (f *Fade) Advance(dt float64) {
+	if f.active {
+		f.elapsed += dt
+	}
+}
+
+// Alpha returns the opacity, 0 to 1.
+func (f *Fade) Alpha() float32 {
+	if !f.active {
+		return 1
+	}
+	t := f.elapsed - f.delay
+	switch {
+	case t <= 0:
+		return f.from
+	case t >= f.duration:
+		return f.to
+	}
+	return f.from + (f.to-f.from)*float32(t/f.duration)
+}
+
+// Done reports whether the fade has finished.
+func (f *Fade) Done() bool {
+	return f.active && f.elapsed >= f.delay+f.duration
+}
@@ -0,0 +1,65 @@
+package entity
+
+import "testing"
+
+func TestFade(t *testing.T) {
+	var opaque Fade
+	if opaque.Alpha() != 1 || opaque.Done() {
+		t.Errorf("zero Fade: Alpha() = %v, Done() = %v", opaque.Alpha(), opaque.Done())
+	}
+
+	tests := []struct {
+		name  string
+		fade  Fade
+		after float64
+		alpha float32
+		done  bool
+	}{
+		{"fade in starts invisible", FadeIn(0.5), 0, 0, false},
+		{"fade in halfway", FadeIn(0.5), 0.25, 0.5, false},
+		{"fade in done", FadeIn(0.5), 0.6, 1, true},
+		{"fade out waits", FadeOut(1, 2, 1), 1.5, 1, false},
+		{"fade out halfway", FadeOut(1, 2, 1), 2.5, 0.5, false},
+		{"fade out from half", FadeOut(0.5, 0, 1), 0.5, 0.25, false},
+		{"fade out done", FadeOut(1, 2, 1), 3, 0, true},
+	}
+	for _, tt := range tests {
+		f := tt.fade
+		f.Advance(tt.after)
+		if got := f.Alpha(); got != tt.alpha || f.Done() != tt.done {
+			t.Errorf("%s: Alpha() = %v, Done() = %v, want %v, %v", tt.name, got, f.Done(), tt.alpha, tt.done)
+		}
+	}
+}
+
+func TestManagerVanish(t *testing.T) {
+	m := NewManager()
+	corpse := m.Spawn(1, TypeMonster)
+	leaving := m.Spawn(2, TypePlayer)
+	culled := m.Spawn(3, TypePlayer)
+	m.Update(SpawnFade)
+	if corpse.Opacity() != 1 {
+		t.Fatalf("spawned unit Opacity() = %v after fading in", corpse.Opacity())
+	}
+
+	m.Vanish(1, CorpseLinger, CorpseFade)
+	m.Vanish(2, 0, VanishFade)
+	m.Vanish(3, 0, VanishFade)
+	m.Vanish(99, 0, VanishFade) // Unknown IDs are ignored
+	if leaving.IsTargetable || m.Get(2) == nil {
+		t.Error("a vanishing unit should stay, untargetable, while it fades")
+	}
+
+	culled.IsVisible = false // Not drawn, so nothing to fade
+	m.Update(VanishFade)
+	if m.Get(2) != nil || m.Get(3) != nil {
+		t.Error("units that finished fading or aren't drawn weren't removed")
+	}
+	if m.Get(1) == nil || corpse.Opacity() != 1 {
+		t.Errorf("corpse gone or fading before it lingered: %v", corpse.Opacity())
+	}
+	m.Update(CorpseLinger + CorpseFade)
+	if m.Get(1) != nil {
+		t.Error("corpse not removed after fading")
+	}
+}
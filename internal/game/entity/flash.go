@@ -0,0 +1,73 @@
+package entity
+
+// FlashDuration is how long, in seconds, a unit's sprite flashes when hit.
+const FlashDuration = 0.2
+
+// FlashKind is the context of a hit, which sets the color its target
+// flashes.
+type FlashKind int
+
+// Flash kinds.
+const (
+	FlashHit      FlashKind = iota // White, a normal hit
+	FlashCritical                  // Red, a critical hit
+	FlashFrozen                    // Blue, a hit on a frozen unit
+	FlashPoison                    // Green, a hit on a poisoned unit
+)
+
+// flashTints are the sprite tints of each flash kind at its peak. The
+// sprite tint multiplies the texture, so channels above 1 brighten it.
+var flashTints = [...][3]float32{
+	FlashHit:      {2.2, 2.2, 2.2},
+	FlashCritical: {2.4, 0.6, 0.6},
+	FlashFrozen:   {0.7, 1.3, 2.6},
+	FlashPoison:   {0.7, 2.2, 0.7},
+}
+
+// FlashFor returns the flash of a hit on e: red for a critical, else blue
+// or green for a frozen or poisoned target, else white.
+func FlashFor(e *Entity, critical bool) FlashKind {
+	switch {
+	case critical:
+		return FlashCritical
+	case e.BodyState == BodyStateFrozen:
+		return FlashFrozen
+	case e.HealthState&HealthPoisoned != 0:
+		return FlashPoison
+	}
+	return FlashHit
+}
+
+// Flash animates a unit's sprite tint when it's hit, from the flash color
+// back to untinted. The zero Flash is untinted.
+type Flash struct {
+	kind    FlashKind
+	elapsed float64
+	active  bool
+}
+
+// Start restarts the flash with kind's color.
+func (f *Flash) Start(kind FlashKind) {
+	*f = Flash{kind: kind, active: true}
+}
+
+// Advance moves the flash on by dt seconds.
+func (f *Flash) Advance(dt float64) {
+	if !f.active {
+		return
+	}
+	f.elapsed += dt
+	if f.elapsed >= FlashDuration {
+		*f = Flash{}
+	}
+}
+
+// Tint returns the sprite tint the flash adds, {1, 1, 1} when none.
+func (f *Flash) Tint() [3]float32 {
+	if !f.active {
+		return [3]float32{1, 1, 1}
+	}
+	w := 1 - float32(f.elapsed/FlashDuration)
+	c := flashTints[f.kind]
+	return [3]float32{1 + (c[0]-1)*w, 1 + (c[1]-1)*w, 1 + (c[2]-1)*w}
+}
@@ -0,0 +1,50 @@
+package entity
+
+import "testing"
+
+func TestFlashFor(t *testing.T) {
+	unit := func(body, health uint16) *Entity {
+		e := NewEntity(1, TypeMonster)
+		e.BodyState, e.HealthState = body, health
+		return e
+	}
+
+	tests := []struct {
+		name     string
+		e        *Entity
+		critical bool
+		want     FlashKind
+	}{
+		{"hit", unit(0, 0), false, FlashHit},
+		{"critical", unit(BodyStateFrozen, HealthPoisoned), true, FlashCritical},
+		{"frozen", unit(BodyStateFrozen, 0), false, FlashFrozen},
+		{"poisoned", unit(0, HealthPoisoned|0x4), false, FlashPoison},
+		{"stone", unit(1, 0), false, FlashHit},
+	}
+
+	for _, tt := range tests {
+		if got := FlashFor(tt.e, tt.critical); got != tt.want {
+			t.Errorf("%s: FlashFor = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFlash(t *testing.T) {
+	var f Flash
+	if got := f.Tint(); got != [3]float32{1, 1, 1} {
+		t.Errorf("zero Flash tint = %v", got)
+	}
+
+	f.Start(FlashCritical)
+	if got := f.Tint(); got != flashTints[FlashCritical] {
+		t.Errorf("tint at start = %v, want %v", got, flashTints[FlashCritical])
+	}
+	f.Advance(FlashDuration / 2)
+	if got := f.Tint(); got[0] != 1.7 || got[1] != 0.8 {
+		t.Errorf("tint halfway = %v, want {1.7, 0.8, 0.8}", got)
+	}
+	f.Advance(FlashDuration)
+	if got := f.Tint(); got != [3]float32{1, 1, 1} {
+		t.Errorf("tint after the flash = %v", got)
+	}
+}
@@ -0,0 +1,48 @@
+package entity
+
+import "time"
+
+// Idle tracks how long the player has been away from the controls, to
+// mark them away after a while.
+type Idle struct {
+	awayAfter time.Duration
+	last      time.Time
+}
+
+// NewIdle returns a tracker marking the player away after awayAfter
+// without activity, counting from now. Zero never marks them away.
+func NewIdle(awayAfter time.Duration, now time.Time) *Idle {
+	return &Idle{awayAfter: awayAfter, last: now}
+}
+
+// Activity records player activity at t: input, or a request sent.
+// Activity older than the last recorded is ignored.
+func (i *Idle) Activity(t time.Time) {
+	if t.After(i.last) {
+		i.last = t
+	}
+}
+
+// IdleFor returns how long the player has been inactive at now.
+func (i *Idle) IdleFor(now time.Time) time.Duration {
+	return now.Sub(i.last)
+}
+
+// Away reports whether the player has been inactive long enough to count
+// as away.
+func (i *Idle) Away(now time.Time) bool {
+	return i.awayAfter > 0 && i.IdleFor(now) >= i.awayAfter
+}
+
+// FidgetTurn returns which turn of fidgets a unit standing idle for
+// idleFor is playing, counting from 0: one starts every interval and
+// lasts length. ok is false between fidgets, or if interval is 0.
+func FidgetTurn(idleFor, interval, length time.Duration) (turn int, ok bool) {
+	if interval <= 0 || idleFor < interval {
+		return 0, false
+	}
+	if idleFor%interval >= length {
+		return 0, false
+	}
+	return int(idleFor/interval) - 1, true
+}
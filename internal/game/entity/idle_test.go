@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIdleAway(t *testing.T) {
+	start := time.Unix(1000, 0)
+	i := NewIdle(time.Minute, start)
+
+	if i.Away(start.Add(59 * time.Second)) {
+		t.Error("away before the idle time")
+	}
+	if !i.Away(start.Add(time.Minute)) {
+		t.Error("not away after the idle time")
+	}
+
+	i.Activity(start.Add(50 * time.Second))
+	if i.Away(start.Add(time.Minute)) {
+		t.Error("still away after activity")
+	}
+	i.Activity(start) // Older activity is ignored
+	if got := i.IdleFor(start.Add(time.Minute)); got != 10*time.Second {
+		t.Errorf("IdleFor = %v, want 10s", got)
+	}
+
+	if NewIdle(0, start).Away(start.Add(time.Hour)) {
+		t.Error("away with the idle time disabled")
+	}
+}
+
+func TestFidgetTurn(t *testing.T) {
+	const interval, length = 10 * time.Second, 2 * time.Second
+	tests := []struct {
+		idle     time.Duration
+		wantTurn int
+		wantOK   bool
+	}{
+		{5 * time.Second, 0, false},
+		{10 * time.Second, 0, true},
+		{11 * time.Second, 0, true},
+		{12 * time.Second, 0, false},
+		{21 * time.Second, 1, true},
+		{35 * time.Second, 0, false},
+	}
+	for _, tt := range tests {
+		turn, ok := FidgetTurn(tt.idle, interval, length)
+		if turn != tt.wantTurn || ok != tt.wantOK {
+			t.Errorf("FidgetTurn(%v) = %d, %v, want %d, %v", tt.idle, turn, ok, tt.wantTurn, tt.wantOK)
+		}
+	}
+	if _, ok := FidgetTurn(time.Hour, 0, length); ok {
+		t.Error("fidget with the interval disabled")
+	}
+}
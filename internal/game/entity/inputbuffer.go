@@ -0,0 +1,150 @@
+package entity
+
+import "time"
+
+// How many key presses the input buffer holds while an action plays.
+const (
+	MinInputBufferDepth = 1
+	MaxInputBufferDepth = 2
+)
+
+// inputMaxAge is how long a buffered press waits before it's dropped, so a
+// press the player has long forgotten doesn't fire.
+const inputMaxAge = time.Second
+
+// InputKind is what a buffered key press asks for.
+type InputKind uint8
+
+const (
+	InputAttack InputKind = iota
+	InputSkill
+)
+
+// Input is a key press asking for an attack or a skill.
+type Input struct {
+	Kind     InputKind
+	TargetID uint32
+	SkillID  uint16 // InputSkill only
+	Level    uint16
+	At       time.Time // When it was pressed
+}
+
+// InputStats counts the presses an InputBuffer has handled.
+type InputStats struct {
+	Fired    uint64 // Sent, at once or after waiting
+	Buffered uint64 // Made to wait for an action to end
+	Dropped  uint64 // Pushed out by a newer press, or waited too long
+}
+
+// InputBuffer holds the skill and attack presses made while the player's
+// current action plays and hands them out once it ends, as the original
+// client does, so presses at high ASPD aren't lost. Presses fire no faster
+// than minInterval, the send queue's rate cap for their packets, so one
+// that leaves the buffer isn't held back again there.
+type InputBuffer struct {
+	depth       int
+	minInterval time.Duration
+	queue       []Input
+	busyUntil   time.Time
+	lastFired   time.Time
+	stats       InputStats
+}
+
+// NewInputBuffer creates an input buffer holding depth presses, clamped to
+// MinInputBufferDepth..MaxInputBufferDepth.
+func NewInputBuffer(depth int, minInterval time.Duration) *InputBuffer {
+	return &InputBuffer{depth: ClampInputBufferDepth(depth), minInterval: minInterval}
+}
+
+// ClampInputBufferDepth clamps a configured depth to the supported range.
+func ClampInputBufferDepth(depth int) int {
+	return min(max(depth, MinInputBufferDepth), MaxInputBufferDepth)
+}
+
+// SetDepth changes how many presses the buffer holds, dropping the oldest
+// ones that no longer fit.
+func (b *InputBuffer) SetDepth(depth int) {
+	b.depth = ClampInputBufferDepth(depth)
+	if n := len(b.queue) - b.depth; n > 0 {
+		b.stats.Dropped += uint64(n)
+		b.queue = append(b.queue[:0], b.queue[n:]...)
+	}
+}
+
+// Depth returns how many presses the buffer holds.
+func (b *InputBuffer) Depth() int {
+	return b.depth
+}
+
+// Begin marks the player busy with an action that ends at until.
+func (b *InputBuffer) Begin(until time.Time) {
+	if until.After(b.busyUntil) {
+		b.busyUntil = until
+	}
+}
+
+// Ready reports whether a press may fire at now: no action is playing and
+// the last press fired at least minInterval ago.
+func (b *InputBuffer) Ready(now time.Time) bool {
+	if now.Before(b.busyUntil) {
+		return false
+	}
+	return b.lastFired.IsZero() || now.Sub(b.lastFired) >= b.minInterval
+}
+
+// Press takes a key press made at now. It returns true if the press is to
+// fire at once; otherwise it waits, pushing the oldest waiting press out
+// of a full buffer.
+func (b *InputBuffer) Press(in Input, now time.Time) bool {
+	in.At = now
+	if len(b.queue) == 0 && b.Ready(now) {
+		b.fired(now)
+		return true
+	}
+	if len(b.queue) >= b.depth {
+		b.queue = append(b.queue[:0], b.queue[1:]...)
+		b.stats.Dropped++
+	}
+	b.queue = append(b.queue, in)
+	b.stats.Buffered++
+	return false
+}
+
+// Next removes and returns the waiting press to fire at now, if any.
+// Presses that waited longer than inputMaxAge are dropped.
+func (b *InputBuffer) Next(now time.Time) (Input, bool) {
+	for len(b.queue) > 0 && now.Sub(b.queue[0].At) > inputMaxAge {
+		b.queue = append(b.queue[:0], b.queue[1:]...)
+		b.stats.Dropped++
+	}
+	if len(b.queue) == 0 || !b.Ready(now) {
+		return Input{}, false
+	}
+	in := b.queue[0]
+	b.queue = append(b.queue[:0], b.queue[1:]...)
+	b.fired(now)
+	return in, true
+}
+
+func (b *InputBuffer) fired(now time.Time) {
+	b.lastFired = now
+	b.stats.Fired++
+}
+
+// Len returns the number of presses waiting.
+func (b *InputBuffer) Len() int {
+	return len(b.queue)
+}
+
+// Stats returns the presses counted so far.
+func (b *InputBuffer) Stats() InputStats {
+	return b.stats
+}
+
+// Clear drops the waiting presses and ends the current action, e.g. when
+// the player dies.
+func (b *InputBuffer) Clear() {
+	b.stats.Dropped += uint64(len(b.queue))
+	b.queue = b.queue[:0]
+	b.busyUntil = time.Time{}
+}
@@ -0,0 +1,107 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClampInputBufferDepth(t *testing.T) {
+	for _, tt := range []struct{ in, want int }{{0, 1}, {1, 1}, {2, 2}, {5, 2}} {
+		if got := ClampInputBufferDepth(tt.in); got != tt.want {
+			t.Errorf("ClampInputBufferDepth(%d) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestInputBufferFiresWhenIdle(t *testing.T) {
+	now := time.Unix(1000, 0)
+	b := NewInputBuffer(1, 100*time.Millisecond)
+
+	if !b.Press(Input{Kind: InputAttack, TargetID: 7}, now) {
+		t.Fatal("press while idle didn't fire at once")
+	}
+	// Too soon after the last press for the send queue's rate cap
+	if b.Press(Input{Kind: InputAttack, TargetID: 7}, now.Add(50*time.Millisecond)) {
+		t.Error("press within the rate cap fired at once")
+	}
+	if _, ok := b.Next(now.Add(99 * time.Millisecond)); ok {
+		t.Error("Next fired within the rate cap")
+	}
+	if in, ok := b.Next(now.Add(100 * time.Millisecond)); !ok || in.TargetID != 7 {
+		t.Errorf("Next = %+v, %v, want the buffered attack", in, ok)
+	}
+	if st := b.Stats(); st.Fired != 2 || st.Buffered != 1 || st.Dropped != 0 {
+		t.Errorf("Stats = %+v", st)
+	}
+}
+
+func TestInputBufferWaitsForAction(t *testing.T) {
+	now := time.Unix(1000, 0)
+	b := NewInputBuffer(2, 100*time.Millisecond)
+	b.Begin(now.Add(500 * time.Millisecond))
+
+	for i := range 3 {
+		if b.Press(Input{Kind: InputSkill, SkillID: uint16(i + 1), Level: 1}, now.Add(time.Duration(i)*10*time.Millisecond)) {
+			t.Fatalf("press %d fired during the action", i)
+		}
+	}
+	if b.Len() != 2 {
+		t.Fatalf("Len = %d, want 2", b.Len())
+	}
+	if _, ok := b.Next(now.Add(400 * time.Millisecond)); ok {
+		t.Error("Next fired during the action")
+	}
+
+	// The oldest press was pushed out; the others fire in order, spaced
+	// by the rate cap
+	end := now.Add(500 * time.Millisecond)
+	if in, ok := b.Next(end); !ok || in.SkillID != 2 {
+		t.Errorf("first Next = %+v, %v, want skill 2", in, ok)
+	}
+	if _, ok := b.Next(end.Add(50 * time.Millisecond)); ok {
+		t.Error("second Next fired within the rate cap")
+	}
+	if in, ok := b.Next(end.Add(100 * time.Millisecond)); !ok || in.SkillID != 3 {
+		t.Errorf("second Next = %+v, %v, want skill 3", in, ok)
+	}
+	if st := b.Stats(); st.Fired != 2 || st.Buffered != 3 || st.Dropped != 1 {
+		t.Errorf("Stats = %+v", st)
+	}
+}
+
+func TestInputBufferDropsStalePresses(t *testing.T) {
+	now := time.Unix(1000, 0)
+	b := NewInputBuffer(1, 0)
+	b.Begin(now.Add(2 * time.Second))
+	b.Press(Input{Kind: InputAttack, TargetID: 1}, now)
+
+	if _, ok := b.Next(now.Add(2 * time.Second)); ok {
+		t.Error("a press older than inputMaxAge fired")
+	}
+	if b.Len() != 0 || b.Stats().Dropped != 1 {
+		t.Errorf("Len = %d, Dropped = %d, want 0, 1", b.Len(), b.Stats().Dropped)
+	}
+}
+
+func TestInputBufferSetDepthAndClear(t *testing.T) {
+	now := time.Unix(1000, 0)
+	b := NewInputBuffer(2, 0)
+	b.Begin(now.Add(time.Second))
+	b.Press(Input{TargetID: 1}, now)
+	b.Press(Input{TargetID: 2}, now)
+
+	b.SetDepth(1)
+	if in, ok := b.Next(now.Add(time.Second)); !ok || in.TargetID != 2 {
+		t.Errorf("Next after SetDepth(1) = %+v, %v, want target 2", in, ok)
+	}
+
+	b.Begin(now.Add(2 * time.Second))
+	b.Press(Input{TargetID: 3}, now.Add(time.Second))
+	b.Clear()
+	if b.Len() != 0 || !b.Ready(now.Add(time.Second)) {
+		t.Error("Clear left a press waiting or the action playing")
+	}
+	if st := b.Stats(); st.Dropped != 2 {
+		t.Errorf("Dropped = %d, want 2", st.Dropped)
+	}
+}
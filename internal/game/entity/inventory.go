@@ -0,0 +1,380 @@
+package entity
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Faultbox/midgard-ro/internal/game/locale"
+)
+
+// ItemType is an item's category (rAthena item_types).
+type ItemType uint8
+
+const (
+	ItemHealing      ItemType = 0
+	ItemUsable       ItemType = 2
+	ItemEtc          ItemType = 3
+	ItemArmor        ItemType = 4
+	ItemWeapon       ItemType = 5
+	ItemCard         ItemType = 6
+	ItemPetEgg       ItemType = 7
+	ItemPetArmor     ItemType = 8
+	ItemAmmo         ItemType = 10
+	ItemDelayConsume ItemType = 11
+	ItemShadowGear   ItemType = 12
+	ItemCash         ItemType = 18
+)
+
+// Stackable reports whether items of this type stack in one inventory slot.
+// Equipment and pet items are unique per slot.
+func (t ItemType) Stackable() bool {
+	switch t {
+	case ItemArmor, ItemWeapon, ItemPetEgg, ItemPetArmor, ItemShadowGear:
+		return false
+	}
+	return true
+}
+
+// EquipLocation is a set of equipment slots (rAthena EQP_* bits).
+type EquipLocation uint32
+
+const (
+	EquipHeadLow  EquipLocation = 0x0001
+	EquipWeapon   EquipLocation = 0x0002 // Right hand
+	EquipGarment  EquipLocation = 0x0004
+	EquipAccLeft  EquipLocation = 0x0008
+	EquipArmor    EquipLocation = 0x0010
+	EquipShield   EquipLocation = 0x0020 // Left hand
+	EquipShoes    EquipLocation = 0x0040
+	EquipAccRight EquipLocation = 0x0080
+	EquipHeadTop  EquipLocation = 0x0100
+	EquipHeadMid  EquipLocation = 0x0200
+	EquipAmmo     EquipLocation = 0x8000
+)
+
+// equipNames names the slots in the order Names lists them.
+var equipNames = []struct {
+	loc  EquipLocation
+	name string
+}{
+	{EquipHeadTop, "Upper Headgear"},
+	{EquipHeadMid, "Middle Headgear"},
+	{EquipHeadLow, "Lower Headgear"},
+	{EquipArmor, "Armor"},
+	{EquipWeapon, "Weapon"},
+	{EquipShield, "Shield"},
+	{EquipGarment, "Garment"},
+	{EquipShoes, "Shoes"},
+	{EquipAccRight, "Accessory"},
+	{EquipAccLeft, "Accessory"},
+	{EquipAmmo, "Ammunition"},
+}
+
+// String names the slots, such as "Weapon, Shield" for a two-handed
+// weapon. An item for either accessory slot is just "Accessory".
+func (l EquipLocation) String() string {
+	var names []string
+	for _, n := range equipNames {
+		if l&n.loc != 0 && (len(names) == 0 || names[len(names)-1] != n.name) {
+			names = append(names, n.name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// InventoryItem is one inventory slot.
+type InventoryItem struct {
+	Index      int // Server inventory index, echoed back in item requests
+	ItemID     uint32
+	Type       ItemType
+	Amount     int
+	Identified bool
+	Damaged    bool // Broken in battle, until repaired
+
+	Location EquipLocation // Slots the item fits, 0 if it isn't equipment
+	Worn     EquipLocation // Slots the item is worn in, 0 when not worn
+	Refine   int
+	Cards    [4]uint32 // Slotted cards; crafted items name the maker here instead
+}
+
+// Equipped reports whether the item is worn.
+func (it InventoryItem) Equipped() bool {
+	return it.Worn != 0
+}
+
+// CardCount returns the number of cards slotted in the item.
+func (it InventoryItem) CardCount() int {
+	if it.crafted() {
+		return 0
+	}
+	n := 0
+	for _, c := range it.Cards {
+		if c != 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// crafted reports whether the item's cards name its maker instead: forged,
+// brewed and named pet items mark the first card.
+func (it InventoryItem) crafted() bool {
+	switch it.Cards[0] {
+	case cardForged, cardBrewed, cardPetName:
+		return true
+	}
+	return false
+}
+
+// Name returns a display name from the client item tables; items they
+// don't name are shown by ID.
+func (it InventoryItem) Name() string {
+	identified := it.Identified || it.Type.Stackable()
+	if name, ok := locale.ItemName(int(it.ItemID), identified); ok {
+		return name
+	}
+	if !identified {
+		return fmt.Sprintf("Unidentified item #%d", it.ItemID)
+	}
+	return fmt.Sprintf("Item #%d", it.ItemID)
+}
+
+// The first card of items that hold no cards.
+const (
+	cardForged  = 0x00FF // Forged weapon: element and star crumbs follow
+	cardBrewed  = 0x00FE // Brewed potion
+	cardPetName = 0xFF00 // Egg of a renamed pet
+)
+
+// forgeElements name a forged weapon's element, by its low card byte.
+var forgeElements = [...]string{1: "Ice", 2: "Earth", 3: "Fire", 4: "Wind"}
+
+// forgeStrengths name a forged weapon's star crumbs, one to three.
+var forgeStrengths = [...]string{1: "Strong", 2: "Very Strong", 3: "Very Very Strong"}
+
+// cardCounts name identical cards slotted together.
+var cardCounts = [...]string{2: "Double", 3: "Triple", 4: "Quadruple"}
+
+// ItemGrade is how an item stands out in lists, which color it by grade.
+type ItemGrade uint8
+
+const (
+	GradeNormal       ItemGrade = iota
+	GradeCarded                 // Holds cards
+	GradeRefined                // Refined to HighRefine or more
+	GradeDamaged                // Broken, unusable until repaired
+	GradeUnidentified           // Equipment still to be appraised
+)
+
+// HighRefine is the refine from which an item is graded refined.
+const HighRefine = 7
+
+// Grade returns the item's grade. An unidentified item shows nothing else,
+// and a broken one is graded so over its refine and cards.
+func (it InventoryItem) Grade() ItemGrade {
+	switch {
+	case !it.Identified && !it.Type.Stackable():
+		return GradeUnidentified
+	case it.Damaged:
+		return GradeDamaged
+	case it.Refine >= HighRefine:
+		return GradeRefined
+	case it.CardCount() > 0:
+		return GradeCarded
+	}
+	return GradeNormal
+}
+
+// Slots returns the item's card slots from the client tables, or the cards
+// it holds when the tables don't know it. Crafted items have none.
+func (it InventoryItem) Slots() int {
+	if it.crafted() {
+		return 0
+	}
+	if n := locale.SlotCount(int(it.ItemID)); n > 0 {
+		return min(n, len(it.Cards))
+	}
+	return it.CardCount()
+}
+
+// SlotCards returns the card in each slot of the item, 0 for an empty one.
+func (it InventoryItem) SlotCards() []uint32 {
+	if it.crafted() {
+		return nil
+	}
+	cards := append([]uint32(nil), it.Cards[:it.Slots()]...)
+	// Cards the tables don't give slots for are still shown
+	for _, c := range it.Cards[len(cards):] {
+		if c != 0 {
+			cards = append(cards, c)
+		}
+	}
+	return cards
+}
+
+// FullName returns the name the client shows for the item: its refine
+// before its AffixedName, such as "+7 Double Lucky Knife of Hydra".
+// Unidentified items show their bare name.
+func (it InventoryItem) FullName() string {
+	name := it.AffixedName()
+	if it.Refine > 0 && it.Grade() != GradeUnidentified {
+		name = fmt.Sprintf("+%d %s", it.Refine, name)
+	}
+	return name
+}
+
+// AffixedName returns the item's name with the forge's strength and
+// element, or the names its cards give it, such as "Double Lucky Knife of
+// Hydra". Identical cards combine, and cards the tables don't name add
+// nothing.
+func (it InventoryItem) AffixedName() string {
+	name := it.Name()
+	if it.Grade() == GradeUnidentified {
+		return name
+	}
+
+	var prefixes, postfixes []string
+	if it.Cards[0] == cardForged {
+		element, stars := it.Cards[1]&0xFF, (it.Cards[1]>>8)/5
+		if int(element) < len(forgeElements) && forgeElements[element] != "" {
+			prefixes = append(prefixes, forgeElements[element])
+		}
+		if int(stars) < len(forgeStrengths) && forgeStrengths[stars] != "" {
+			prefixes = append([]string{forgeStrengths[stars]}, prefixes...)
+		}
+	} else if it.CardCount() > 0 {
+		prefixes, postfixes = cardAffixes(it.Cards)
+	}
+
+	parts := append(prefixes, name)
+	return strings.Join(append(parts, postfixes...), " ")
+}
+
+// cardAffixes returns the names slotted cards give an item, before and
+// after its own, in slot order with identical names combined.
+func cardAffixes(cards [4]uint32) (prefixes, postfixes []string) {
+	type affix struct {
+		text  string
+		count int
+		post  bool
+	}
+	var affixes []*affix
+	seen := make(map[string]*affix)
+	for _, c := range cards {
+		if c == 0 {
+			continue
+		}
+		text, post, ok := locale.CardAffix(int(c))
+		if !ok {
+			continue
+		}
+		if a, ok := seen[text]; ok {
+			a.count++
+			continue
+		}
+		a := &affix{text: text, count: 1, post: post}
+		seen[text] = a
+		affixes = append(affixes, a)
+	}
+	for _, a := range affixes {
+		text := a.text
+		if a.count < len(cardCounts) && cardCounts[a.count] != "" {
+			text = cardCounts[a.count] + " " + text
+		}
+		if a.post {
+			postfixes = append(postfixes, text)
+		} else {
+			prefixes = append(prefixes, text)
+		}
+	}
+	return prefixes, postfixes
+}
+
+// Inventory tracks the player's items by server index.
+type Inventory struct {
+	items map[int]*InventoryItem
+}
+
+// NewInventory creates an empty inventory.
+func NewInventory() *Inventory {
+	return &Inventory{items: make(map[int]*InventoryItem)}
+}
+
+// Set adds or replaces an item slot.
+func (inv *Inventory) Set(item InventoryItem) {
+	inv.items[item.Index] = &item
+}
+
+// Get returns the item at index.
+func (inv *Inventory) Get(index int) (InventoryItem, bool) {
+	item, ok := inv.items[index]
+	if !ok {
+		return InventoryItem{}, false
+	}
+	return *item, true
+}
+
+// Remove takes amount items from the slot at index, deleting the slot when
+// none are left. Returns false if the slot doesn't exist.
+func (inv *Inventory) Remove(index, amount int) bool {
+	item, ok := inv.items[index]
+	if !ok {
+		return false
+	}
+	item.Amount -= amount
+	if item.Amount <= 0 {
+		delete(inv.items, index)
+	}
+	return true
+}
+
+// SetWorn records the slots the item at index is worn in, 0 when taken
+// off. Items worn in those slots before are taken off: the server swaps
+// them out. Returns false if the slot doesn't exist.
+func (inv *Inventory) SetWorn(index int, worn EquipLocation) bool {
+	item, ok := inv.items[index]
+	if !ok {
+		return false
+	}
+	if worn != 0 {
+		for _, other := range inv.items {
+			if other != item && other.Worn&worn != 0 {
+				other.Worn &^= worn
+			}
+		}
+	}
+	item.Worn = worn
+	return true
+}
+
+// WornIn returns the items worn in any of the slots, ordered by index.
+func (inv *Inventory) WornIn(loc EquipLocation) []InventoryItem {
+	var items []InventoryItem
+	for _, item := range inv.Items() {
+		if item.Worn&loc != 0 {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Items returns the items ordered by index.
+func (inv *Inventory) Items() []InventoryItem {
+	items := make([]InventoryItem, 0, len(inv.items))
+	for _, item := range inv.items {
+		items = append(items, *item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Index < items[j].Index })
+	return items
+}
+
+// Len returns the number of occupied slots.
+func (inv *Inventory) Len() int {
+	return len(inv.items)
+}
+
+// Clear removes all items.
+func (inv *Inventory) Clear() {
+	inv.items = make(map[int]*InventoryItem)
+}
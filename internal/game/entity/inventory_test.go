@@ -0,0 +1,198 @@
+package entity
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Faultbox/midgard-ro/internal/game/locale"
+)
+
+func TestInventoryRemove(t *testing.T) {
+	inv := NewInventory()
+	inv.Set(InventoryItem{Index: 4, ItemID: 909, Type: ItemEtc, Amount: 10})
+	inv.Set(InventoryItem{Index: 2, ItemID: 1201, Type: ItemWeapon, Amount: 1})
+
+	if !inv.Remove(4, 3) {
+		t.Fatal("Remove(4, 3) = false")
+	}
+	if item, _ := inv.Get(4); item.Amount != 7 {
+		t.Errorf("amount after partial drop = %d, want 7", item.Amount)
+	}
+
+	inv.Remove(4, 7)
+	if _, ok := inv.Get(4); ok {
+		t.Error("slot still present after dropping all items")
+	}
+	if inv.Remove(99, 1) {
+		t.Error("Remove of unknown index = true")
+	}
+
+	items := inv.Items()
+	if len(items) != 1 || items[0].Index != 2 {
+		t.Errorf("Items() = %+v, want only index 2", items)
+	}
+}
+
+func TestItemTypeStackable(t *testing.T) {
+	tests := []struct {
+		itemType ItemType
+		want     bool
+	}{
+		{ItemHealing, true},
+		{ItemEtc, true},
+		{ItemAmmo, true},
+		{ItemWeapon, false},
+		{ItemArmor, false},
+		{ItemPetEgg, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.itemType.Stackable(); got != tt.want {
+			t.Errorf("ItemType(%d).Stackable() = %v, want %v", tt.itemType, got, tt.want)
+		}
+	}
+}
+
+func TestInventoryEquip(t *testing.T) {
+	inv := NewInventory()
+	inv.Set(InventoryItem{Index: 2, ItemID: 1201, Type: ItemWeapon, Amount: 1, Location: EquipWeapon, Worn: EquipWeapon})
+	inv.Set(InventoryItem{Index: 3, ItemID: 2101, Type: ItemArmor, Amount: 1, Location: EquipShield, Worn: EquipShield})
+	inv.Set(InventoryItem{Index: 4, ItemID: 1116, Type: ItemWeapon, Amount: 1, Location: EquipWeapon | EquipShield})
+
+	// A two-handed weapon replaces both the weapon and the shield
+	if !inv.SetWorn(4, EquipWeapon|EquipShield) {
+		t.Fatal("SetWorn(4) = false")
+	}
+	if worn := inv.WornIn(EquipWeapon | EquipShield); len(worn) != 1 || worn[0].Index != 4 {
+		t.Errorf("WornIn after swap = %+v, want only index 4", worn)
+	}
+	if item, _ := inv.Get(3); item.Equipped() {
+		t.Error("shield still worn after the two-handed weapon")
+	}
+
+	inv.SetWorn(4, 0)
+	if len(inv.WornIn(EquipWeapon)) != 0 {
+		t.Error("weapon still worn after taking it off")
+	}
+	if inv.SetWorn(99, EquipArmor) {
+		t.Error("SetWorn of unknown index = true")
+	}
+}
+
+func TestEquipLocationString(t *testing.T) {
+	tests := []struct {
+		loc  EquipLocation
+		want string
+	}{
+		{EquipArmor, "Armor"},
+		{EquipWeapon | EquipShield, "Weapon, Shield"},
+		{EquipAccLeft | EquipAccRight, "Accessory"},
+		{EquipHeadTop | EquipHeadMid | EquipHeadLow, "Upper Headgear, Middle Headgear, Lower Headgear"},
+		{0, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.loc.String(); got != tt.want {
+			t.Errorf("EquipLocation(%#x).String() = %q, want %q", uint32(tt.loc), got, tt.want)
+		}
+	}
+
+	crafted := InventoryItem{Cards: [4]uint32{0x00FF, 0, 150000, 0}}
+	if n := crafted.CardCount(); n != 0 {
+		t.Errorf("crafted CardCount() = %d, want 0", n)
+	}
+	if n := (InventoryItem{Cards: [4]uint32{4001, 4002}}).CardCount(); n != 2 {
+		t.Errorf("CardCount() = %d, want 2", n)
+	}
+}
+
+func loadCardTables(t *testing.T) {
+	t.Helper()
+	files := map[string]string{
+		"data/idnum2itemdisplaynametable.txt": "1201#Knife#\n2301#Cotton_Shirt#\n",
+		"data/num2itemdisplaynametable.txt":   "1201#Dagger#\n",
+		"data/cardprefixnametable.txt":        "4001#Lucky#\n4035#of_Hydra#\n",
+		"data/cardpostfixnametable.txt":       "4035#\n",
+		"data/itemslotcounttable.txt":         "1201#\n3#\n",
+	}
+	read := func(path string) ([]byte, error) {
+		if s, ok := files[path]; ok {
+			return []byte(s), nil
+		}
+		return nil, errors.New("not found")
+	}
+	if err := locale.Load("", read, ""); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { locale.Load("", nil, "") })
+}
+
+func TestItemFullName(t *testing.T) {
+	loadCardTables(t)
+	knife := InventoryItem{ItemID: 1201, Type: ItemWeapon, Identified: true}
+	with := func(refine int, cards ...uint32) InventoryItem {
+		it := knife
+		it.Refine = refine
+		copy(it.Cards[:], cards)
+		return it
+	}
+
+	tests := []struct {
+		name string
+		item InventoryItem
+		want string
+	}{
+		{"plain", knife, "Knife"},
+		{"refined", with(7), "+7 Knife"},
+		{"prefix", with(0, 4001), "Lucky Knife"},
+		{"combined", with(4, 4001, 4001, 4035), "+4 Double Lucky Knife of Hydra"},
+		{"unnamed card", with(0, 4999), "Knife"},
+		{"forged", with(0, 0x00FF, 10<<8|3), "Very Strong Fire Knife"},
+		{"unidentified", InventoryItem{ItemID: 1201, Type: ItemWeapon, Refine: 4, Cards: [4]uint32{4001}}, "Dagger"},
+	}
+	for _, tt := range tests {
+		if got := tt.item.FullName(); got != tt.want {
+			t.Errorf("%s: FullName() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestItemSlotCards(t *testing.T) {
+	loadCardTables(t)
+	tests := []struct {
+		name string
+		item InventoryItem
+		want []uint32
+	}{
+		{"slotted", InventoryItem{ItemID: 1201, Cards: [4]uint32{4001}}, []uint32{4001, 0, 0}},
+		{"unknown slots", InventoryItem{ItemID: 2301, Cards: [4]uint32{4001, 4035}}, []uint32{4001, 4035}},
+		{"no slots", InventoryItem{ItemID: 2301}, nil},
+		{"forged", InventoryItem{ItemID: 1201, Cards: [4]uint32{0x00FF, 5 << 8}}, nil},
+	}
+	for _, tt := range tests {
+		if got := tt.item.SlotCards(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: SlotCards() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestItemGrade(t *testing.T) {
+	tests := []struct {
+		name string
+		item InventoryItem
+		want ItemGrade
+	}{
+		{"normal", InventoryItem{Type: ItemWeapon, Identified: true, Refine: 6}, GradeNormal},
+		{"carded", InventoryItem{Type: ItemWeapon, Identified: true, Cards: [4]uint32{4001}}, GradeCarded},
+		{"refined", InventoryItem{Type: ItemWeapon, Identified: true, Refine: 7, Cards: [4]uint32{4001}}, GradeRefined},
+		{"damaged", InventoryItem{Type: ItemWeapon, Identified: true, Damaged: true, Refine: 9}, GradeDamaged},
+		{"unidentified", InventoryItem{Type: ItemArmor, Damaged: true}, GradeUnidentified},
+		{"stackable", InventoryItem{Type: ItemHealing}, GradeNormal},
+		{"forged", InventoryItem{Type: ItemWeapon, Identified: true, Cards: [4]uint32{0x00FF}}, GradeNormal},
+	}
+	for _, tt := range tests {
+		if got := tt.item.Grade(); got != tt.want {
+			t.Errorf("%s: Grade() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package entity
+
+import (
+	gomath "math"
+
+	"github.com/Faultbox/midgard-ro/pkg/math"
+)
+
+// Pet follow AI distances and speed, in world units (5 per tile). Like
+// the server's pet AI, a pet idles while its owner is close and walks to
+// a spot behind them once they've moved away.
+const (
+	PetFollowDistance = 3 * 5.0 // Start following beyond this
+	PetStopDistance   = 2 * 5.0 // Stop this far behind the owner
+	PetWalkSpeed      = 5.0 / 0.15
+)
+
+// DirectionVector returns the unit vector on the X/Z plane of an RO
+// direction index, the inverse of CalculateDirection.
+func DirectionVector(dir int) (dx, dz float32) {
+	sectors := [8]int{DirS: 0, DirSE: 1, DirE: 2, DirNE: 3, DirN: 4, DirNW: 5, DirW: 6, DirSW: 7}
+	angle := float64(sectors[dir&7]) * gomath.Pi / 4
+	return float32(gomath.Sin(angle)), float32(gomath.Cos(angle))
+}
+
+// PetFollowTarget returns where a pet stands when its owner at owner
+// faces ownerDir: PetStopDistance behind them.
+func PetFollowTarget(owner math.Vec3, ownerDir int) math.Vec3 {
+	dx, dz := DirectionVector(ownerDir)
+	return math.Vec3{
+		X: owner.X - dx*PetStopDistance,
+		Y: owner.Y,
+		Z: owner.Z - dz*PetStopDistance,
+	}
+}
+
+// FollowOwners walks pets toward their owners for dt seconds. Pets whose
+// owner isn't in view stay put. height returns the terrain height at a
+// position, or nil to keep the pets' height.
+func (m *Manager) FollowOwners(dt float64, height func(x, z float32) float32) {
+	for _, pet := range m.entities {
+		if pet.Type != TypePet || pet.OwnerID == 0 {
+			continue
+		}
+		owner := m.entities[pet.OwnerID]
+		if owner == nil {
+			continue
+		}
+		if pet.State == StateWalking {
+			followStep(pet, owner, float32(dt))
+		} else if distXZ(pet.Position, owner.Position) > PetFollowDistance {
+			pet.State = StateWalking
+		}
+		if height != nil {
+			pet.Position.Y = height(pet.Position.X, pet.Position.Z)
+		}
+	}
+}
+
+// followStep moves a following pet toward the spot behind its owner, and
+// stops it there.
+func followStep(pet, owner *Entity, dt float32) {
+	target := PetFollowTarget(owner.Position, int(owner.Direction))
+	dx := target.X - pet.Position.X
+	dz := target.Z - pet.Position.Z
+	dist := float32(gomath.Hypot(float64(dx), float64(dz)))
+	step := PetWalkSpeed * dt
+	if dist <= step {
+		pet.Position.X, pet.Position.Z = target.X, target.Z
+		pet.Direction = owner.Direction
+		pet.State = StateIdle
+		return
+	}
+	pet.Position.X += dx / dist * step
+	pet.Position.Z += dz / dist * step
+	pet.Direction = uint8(CalculateDirection(dx, dz))
+}
+
+// distXZ returns the distance between a and b on the ground plane.
+func distXZ(a, b math.Vec3) float32 {
+	return float32(gomath.Hypot(float64(a.X-b.X), float64(a.Z-b.Z)))
+}
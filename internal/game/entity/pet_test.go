@@ -0,0 +1,78 @@
+package entity
+
+import (
+	gomath "math"
+	"testing"
+
+	"github.com/Faultbox/midgard-ro/pkg/math"
+)
+
+func TestDirectionVectorInvertsCalculateDirection(t *testing.T) {
+	for dir := range 8 {
+		dx, dz := DirectionVector(dir)
+		if got := CalculateDirection(dx, dz); got != dir {
+			t.Errorf("CalculateDirection(DirectionVector(%d)) = %d", dir, got)
+		}
+	}
+}
+
+func TestPetFollowTarget(t *testing.T) {
+	owner := math.Vec3{X: 100, Y: 3, Z: 100}
+	tests := []struct {
+		dir  int
+		want math.Vec3
+	}{
+		{DirS, math.Vec3{X: 100, Y: 3, Z: 100 - PetStopDistance}},
+		{DirE, math.Vec3{X: 100 - PetStopDistance, Y: 3, Z: 100}},
+		{DirN, math.Vec3{X: 100, Y: 3, Z: 100 + PetStopDistance}},
+	}
+
+	for _, tt := range tests {
+		got := PetFollowTarget(owner, tt.dir)
+		if !near(got.X, tt.want.X) || got.Y != tt.want.Y || !near(got.Z, tt.want.Z) {
+			t.Errorf("PetFollowTarget(dir %d) = %v, want %v", tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestManagerFollowOwners(t *testing.T) {
+	m := NewManager()
+	owner := NewEntity(1, TypePlayer)
+	owner.SetPosition(100, 0, 100)
+	owner.Direction = DirS
+	m.SetPlayer(owner)
+
+	pet := m.Spawn(2, TypePet)
+	pet.OwnerID = 1
+	pet.SetPosition(100, 0, 95)
+	stray := m.Spawn(3, TypePet) // Owner out of view
+	stray.OwnerID = 9
+	stray.SetPosition(0, 0, 0)
+
+	// Close to the owner: idles
+	m.FollowOwners(0.1, nil)
+	if pet.State != StateIdle || pet.Position.Z != 95 {
+		t.Fatalf("pet near owner moved: state %d at %v", pet.State, pet.Position)
+	}
+
+	// Owner walks away: the pet follows and stops behind them
+	owner.SetPosition(100, 0, 140)
+	flat := func(x, z float32) float32 { return 2 }
+	for range 20 {
+		m.FollowOwners(0.1, flat)
+	}
+	want := PetFollowTarget(owner.Position, DirS)
+	if pet.State != StateIdle || !near(pet.Position.X, want.X) || !near(pet.Position.Z, want.Z) {
+		t.Errorf("pet at %v (state %d), want idle at %v", pet.Position, pet.State, want)
+	}
+	if pet.Position.Y != 2 {
+		t.Errorf("pet height = %v, want terrain height 2", pet.Position.Y)
+	}
+	if stray.Position != (math.Vec3{}) {
+		t.Errorf("pet without owner moved to %v", stray.Position)
+	}
+}
+
+func near(a, b float32) bool {
+	return gomath.Abs(float64(a-b)) < 1e-3
+}
@@ -0,0 +1,52 @@
+package entity
+
+// DefaultPoolSize is how many released entities a pool keeps for reuse.
+// Busy towns show a few hundred actors coming and going.
+const DefaultPoolSize = 256
+
+// Pool recycles entities so actors constantly entering and leaving view
+// don't allocate a new Entity each time.
+type Pool struct {
+	free    []*Entity
+	maxFree int
+}
+
+// NewPool creates a pool keeping up to maxFree released entities.
+func NewPool(maxFree int) *Pool {
+	return &Pool{maxFree: maxFree}
+}
+
+// Get returns a reset entity, reusing a released one when available.
+func (p *Pool) Get(id uint32, entityType Type) *Entity {
+	n := len(p.free)
+	if n == 0 {
+		e := NewEntity(id, entityType)
+		e.pooled = true
+		return e
+	}
+
+	e := p.free[n-1]
+	p.free[n-1] = nil
+	p.free = p.free[:n-1]
+
+	path := e.MovePath[:0] // Keep the path's backing array
+	*e = Entity{}
+	e.init(id, entityType)
+	e.MovePath = path
+	e.pooled = true
+	return e
+}
+
+// Put releases an entity for reuse. The caller must not keep references to
+// it. Entities beyond the pool's capacity are left to the garbage collector.
+func (p *Pool) Put(e *Entity) {
+	if e == nil || len(p.free) >= p.maxFree {
+		return
+	}
+	p.free = append(p.free, e)
+}
+
+// Free returns the number of entities waiting for reuse.
+func (p *Pool) Free() int {
+	return len(p.free)
+}
@@ -0,0 +1,46 @@
+package entity
+
+import "testing"
+
+func TestPoolReusesEntities(t *testing.T) {
+	p := NewPool(1)
+
+	e := p.Get(1, TypeMonster)
+	e.Name = "Poring"
+	e.HP = 50
+	p.Put(e)
+	p.Put(NewEntity(2, TypeNPC)) // Over capacity, dropped
+
+	if p.Free() != 1 {
+		t.Fatalf("Free() = %d, want 1", p.Free())
+	}
+
+	got := p.Get(3, TypeItem)
+	if got != e {
+		t.Fatal("Get did not reuse the released entity")
+	}
+	if got.ID != 3 || got.Type != TypeItem || got.Name != "" || got.HP != 0 {
+		t.Errorf("reused entity not reset: %+v", got)
+	}
+	if got.IsTargetable || !got.IsVisible {
+		t.Error("reused entity missing TypeItem defaults")
+	}
+}
+
+func TestManagerSpawnRemoveReleases(t *testing.T) {
+	m := NewManager()
+	var released []uint32
+	m.OnRelease = func(e *Entity) { released = append(released, e.ID) }
+
+	e := m.Spawn(7, TypeMonster)
+	m.Add(NewEntity(8, TypeNPC)) // Not pooled
+
+	m.Remove(7)
+	m.Remove(8)
+	if len(released) != 1 || released[0] != 7 {
+		t.Errorf("released = %v, want [7]", released)
+	}
+	if m.Spawn(9, TypePlayer) != e {
+		t.Error("Spawn did not reuse the removed entity")
+	}
+}
@@ -0,0 +1,68 @@
+package entity
+
+// LevelKind identifies which level a LevelUp refers to.
+type LevelKind uint8
+
+const (
+	LevelBase LevelKind = iota
+	LevelJob
+)
+
+// LevelUp describes a level increase detected from a status update.
+type LevelUp struct {
+	Kind  LevelKind
+	Level int
+}
+
+// Progress tracks the player's base/job levels and experience, as sent by
+// the server through status parameter updates.
+type Progress struct {
+	BaseLevel   int
+	JobLevel    int
+	BaseExp     int64
+	NextBaseExp int64 // Experience required for the next base level (0 = max level)
+	JobExp      int64
+	NextJobExp  int64 // Experience required for the next job level (0 = max level)
+}
+
+// SetBaseLevel updates the base level. It reports a level-up only when a
+// previously known level increases, so the initial sync after entering the
+// map is not announced.
+func (p *Progress) SetBaseLevel(level int) (LevelUp, bool) {
+	prev := p.BaseLevel
+	p.BaseLevel = level
+	if prev == 0 || level <= prev {
+		return LevelUp{}, false
+	}
+	return LevelUp{Kind: LevelBase, Level: level}, true
+}
+
+// SetJobLevel updates the job level. See SetBaseLevel.
+func (p *Progress) SetJobLevel(level int) (LevelUp, bool) {
+	prev := p.JobLevel
+	p.JobLevel = level
+	if prev == 0 || level <= prev {
+		return LevelUp{}, false
+	}
+	return LevelUp{Kind: LevelJob, Level: level}, true
+}
+
+// BaseExpRatio returns base experience progress in the range [0, 1].
+func (p *Progress) BaseExpRatio() float32 {
+	return expRatio(p.BaseExp, p.NextBaseExp)
+}
+
+// JobExpRatio returns job experience progress in the range [0, 1].
+func (p *Progress) JobExpRatio() float32 {
+	return expRatio(p.JobExp, p.NextJobExp)
+}
+
+func expRatio(exp, next int64) float32 {
+	if next <= 0 || exp <= 0 {
+		return 0
+	}
+	if exp >= next {
+		return 1
+	}
+	return float32(float64(exp) / float64(next))
+}
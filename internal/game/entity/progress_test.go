@@ -0,0 +1,56 @@
+package entity
+
+import "testing"
+
+func TestProgressLevelUp(t *testing.T) {
+	var p Progress
+
+	// Initial sync after map enter is not a level-up
+	if _, ok := p.SetBaseLevel(10); ok {
+		t.Error("initial base level reported as level-up")
+	}
+	if _, ok := p.SetJobLevel(5); ok {
+		t.Error("initial job level reported as level-up")
+	}
+
+	up, ok := p.SetBaseLevel(11)
+	if !ok || up.Kind != LevelBase || up.Level != 11 {
+		t.Errorf("SetBaseLevel(11) = %+v, %v", up, ok)
+	}
+	up, ok = p.SetJobLevel(6)
+	if !ok || up.Kind != LevelJob || up.Level != 6 {
+		t.Errorf("SetJobLevel(6) = %+v, %v", up, ok)
+	}
+
+	// Resends and decreases (rebirth, reset) are not level-ups
+	if _, ok := p.SetBaseLevel(11); ok {
+		t.Error("unchanged base level reported as level-up")
+	}
+	if _, ok := p.SetJobLevel(1); ok {
+		t.Error("job level decrease reported as level-up")
+	}
+}
+
+func TestProgressExpRatio(t *testing.T) {
+	tests := []struct {
+		exp, next int64
+		want      float32
+	}{
+		{0, 100, 0},
+		{25, 100, 0.25},
+		{100, 100, 1},
+		{150, 100, 1},
+		{50, 0, 0}, // Max level
+		{-5, 100, 0},
+	}
+
+	for _, tt := range tests {
+		p := Progress{BaseExp: tt.exp, NextBaseExp: tt.next, JobExp: tt.exp, NextJobExp: tt.next}
+		if got := p.BaseExpRatio(); got != tt.want {
+			t.Errorf("BaseExpRatio(%d/%d) = %v, want %v", tt.exp, tt.next, got, tt.want)
+		}
+		if got := p.JobExpRatio(); got != tt.want {
+			t.Errorf("JobExpRatio(%d/%d) = %v, want %v", tt.exp, tt.next, got, tt.want)
+		}
+	}
+}
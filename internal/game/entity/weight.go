@@ -0,0 +1,70 @@
+package entity
+
+import "fmt"
+
+// Statuses (rAthena EFST_*) the server puts on an overweight player.
+const (
+	StatusWeightOver50 uint16 = 35
+	StatusWeightOver90 uint16 = 36
+)
+
+// WeightLevel is how heavily the player is loaded.
+type WeightLevel uint8
+
+const (
+	WeightNormal WeightLevel = iota
+	WeightOver50             // HP and SP no longer regenerate
+	WeightOver90             // Attacks and skills are refused as well
+)
+
+// Weight is what the player carries and the most they can, in tenths as
+// the server sends them through status parameter updates.
+type Weight struct {
+	Current int
+	Max     int
+}
+
+// Level returns how heavily the player is loaded. It's normal until the
+// limit is known.
+func (w Weight) Level() WeightLevel {
+	switch {
+	case w.Max <= 0:
+		return WeightNormal
+	case w.Current*10 >= w.Max*9:
+		return WeightOver90
+	case w.Current*2 >= w.Max:
+		return WeightOver50
+	}
+	return WeightNormal
+}
+
+// Status returns the overweight status the level stands for, or false at
+// a normal weight.
+func (w Weight) Status() (uint16, bool) {
+	switch w.Level() {
+	case WeightOver50:
+		return StatusWeightOver50, true
+	case WeightOver90:
+		return StatusWeightOver90, true
+	}
+	return 0, false
+}
+
+// Left returns the weight, in tenths, that can still be carried.
+func (w Weight) Left() int {
+	return max(0, w.Max-w.Current)
+}
+
+// Ratio returns the weight carried in the range [0, 1].
+func (w Weight) Ratio() float32 {
+	if w.Max <= 0 {
+		return 0
+	}
+	return min(1, max(0, float32(w.Current)/float32(w.Max)))
+}
+
+// String formats the weight in whole units as the basic info window shows
+// it: "Weight: 123 / 2800".
+func (w Weight) String() string {
+	return fmt.Sprintf("Weight: %d / %d", w.Current/10, w.Max/10)
+}
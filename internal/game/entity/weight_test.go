@@ -0,0 +1,49 @@
+package entity
+
+import "testing"
+
+func TestWeightLevel(t *testing.T) {
+	tests := []struct {
+		name       string
+		w          Weight
+		want       WeightLevel
+		wantStatus uint16
+	}{
+		{"unknown limit", Weight{Current: 500}, WeightNormal, 0},
+		{"light", Weight{Current: 13990, Max: 28000}, WeightNormal, 0},
+		{"half", Weight{Current: 14000, Max: 28000}, WeightOver50, StatusWeightOver50},
+		{"just under 90%", Weight{Current: 25190, Max: 28000}, WeightOver50, StatusWeightOver50},
+		{"90%", Weight{Current: 25200, Max: 28000}, WeightOver90, StatusWeightOver90},
+		{"over the limit", Weight{Current: 30000, Max: 28000}, WeightOver90, StatusWeightOver90},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.w.Level(); got != tt.want {
+				t.Errorf("Level = %d, want %d", got, tt.want)
+			}
+			status, ok := tt.w.Status()
+			if ok != (tt.wantStatus != 0) || status != tt.wantStatus {
+				t.Errorf("Status = %d, %v, want %d", status, ok, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestWeightReadout(t *testing.T) {
+	w := Weight{Current: 1235, Max: 28000}
+	if got := w.String(); got != "Weight: 123 / 2800" {
+		t.Errorf("String = %q", got)
+	}
+	if got := w.Left(); got != 26765 {
+		t.Errorf("Left = %d, want 26765", got)
+	}
+	if got := (Weight{Current: 30000, Max: 28000}).Left(); got != 0 {
+		t.Errorf("Left over the limit = %d, want 0", got)
+	}
+	if got := (Weight{Current: 7000, Max: 28000}).Ratio(); got != 0.25 {
+		t.Errorf("Ratio = %v, want 0.25", got)
+	}
+	if got := (Weight{Current: 7000}).Ratio(); got != 0 {
+		t.Errorf("Ratio of an unknown limit = %v, want 0", got)
+	}
+}
@@ -3,11 +3,12 @@ package game
 
 import (
 	"fmt"
-	"image"
-	"image/png"
+	"net"
 	"os"
-	"path/filepath"
 	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/AllenDang/cimgui-go/backend"
@@ -18,8 +19,20 @@ import (
 
 	"github.com/Faultbox/midgard-ro/internal/assets"
 	"github.com/Faultbox/midgard-ro/internal/config"
+	"github.com/Faultbox/midgard-ro/internal/crash"
+	"github.com/Faultbox/midgard-ro/internal/engine/audio"
+	"github.com/Faultbox/midgard-ro/internal/engine/camera"
+	"github.com/Faultbox/midgard-ro/internal/engine/clock"
+	"github.com/Faultbox/midgard-ro/internal/engine/gldebug"
+	"github.com/Faultbox/midgard-ro/internal/engine/pacing"
+	"github.com/Faultbox/midgard-ro/internal/engine/texfilter"
+	"github.com/Faultbox/midgard-ro/internal/engine/texture"
+	"github.com/Faultbox/midgard-ro/internal/engine/ui2d"
+	"github.com/Faultbox/midgard-ro/internal/game/entity"
+	"github.com/Faultbox/midgard-ro/internal/game/locale"
 	"github.com/Faultbox/midgard-ro/internal/game/states"
 	"github.com/Faultbox/midgard-ro/internal/game/ui"
+	"github.com/Faultbox/midgard-ro/internal/game/world"
 	"github.com/Faultbox/midgard-ro/internal/logger"
 	"github.com/Faultbox/midgard-ro/internal/network"
 )
@@ -51,6 +64,9 @@ type Game struct {
 	// Assets
 	assetManager *assets.Manager
 
+	// Sound effects and music (nil if audio output is unavailable)
+	audio *audio.Manager
+
 	// Timing
 	lastTime   time.Time
 	frameCount int
@@ -58,11 +74,12 @@ type Game struct {
 	fpsTimer   time.Time
 	dt         float64 // Delta time in seconds
 
-	// Screenshot support
-	screenshotDir       string
-	screenshotRequested bool
-	screenshotMsg       string
-	screenshotMsgTime   time.Time
+	// Frame rate cap and background throttling
+	pacer pacing.Pacer
+
+	// Screenshot capture, notification and gallery
+	screenshots *screenshotManager
+	charName    string // Selected character, used in screenshot filenames
 
 	// Input tracking
 	lastMouseX float32
@@ -74,6 +91,41 @@ type Game struct {
 	// Debug overlay toggle (F3). Default off so the HUD isn't cluttered;
 	// turn on to inspect player/camera/scene/network telemetry live.
 	showDebug bool
+
+	// Settings window toggle (F10)
+	showSettings bool
+
+	// Escape menu toggle, in game
+	showSystemMenu bool
+
+	// Per-account and per-character settings of the character in game
+	settings accountSettings
+
+	// Inventory window toggle (Alt+E)
+	showInventory bool
+
+	// Toasts in the corner for pickups, logins and screenshots
+	notifications *ui.NotificationQueue
+
+	// Equipment window toggle (Alt+Q)
+	showEquipment bool
+
+	// NPC dialog history window toggle (Alt+H)
+	showDialogHistory bool
+
+	// Area map window (Alt+V): open, showing the world map, the town picked
+	// there, and the warps between maps
+	areaMap areaMapView
+	warps   *world.WarpTable
+
+	// GPU vendor/renderer/driver, captured at GL init for crash reports
+	gpuInfo string
+
+	// Panic recovered inside the frame callback, returned from Run
+	crashErr *crash.PanicError
+
+	// Recording or replay of the session (--record, --replay), nil if none
+	replay *replaySession
 }
 
 // New creates a new game instance with ImGui windowing (backward compatible).
@@ -88,13 +140,16 @@ func New(cfg *config.Config) (*Game, error) {
 	)
 
 	g := &Game{
-		config:        cfg,
-		running:       false,
-		stateManager:  states.NewManager(),
-		client:        network.New(),
-		assetManager:  assets.NewManager(),
-		screenshotDir: "data/Screenshots",
+		config:       cfg,
+		running:      false,
+		stateManager: states.NewManager(),
+		client:       network.New(),
+		assetManager: assets.NewManager(),
+		screenshots:  newScreenshotManager(cfg),
+
+		notifications: ui.NewNotificationQueue(cfg.Game.NotifyDuration, cfg.Game.MaxNotifications),
 	}
+	g.client.SetDialer(newDialer(cfg))
 
 	// Load GRF archives
 	for _, grfPath := range cfg.Data.GRFPaths {
@@ -104,6 +159,7 @@ func New(cfg *config.Config) (*Game, error) {
 			logger.Info("loaded GRF archive", zap.String("path", grfPath))
 		}
 	}
+	g.addDataDirs(cfg.Data.DataDirs)
 
 	// Create ImGui backend (for windowing)
 	var err error
@@ -125,6 +181,7 @@ func New(cfg *config.Config) (*Game, error) {
 
 	g.imguiBackend.SetBgColor(imgui.NewVec4(0.05, 0.05, 0.08, 1.0))
 	g.imguiBackend.CreateWindow("Midgard RO", cfg.Graphics.Width, cfg.Graphics.Height)
+	g.applyVSync()
 
 	// Initialize OpenGL
 	if err := gl.Init(); err != nil {
@@ -133,10 +190,17 @@ func New(cfg *config.Config) (*Game, error) {
 
 	version := gl.GoStr(gl.GetString(gl.VERSION))
 	renderer := gl.GoStr(gl.GetString(gl.RENDERER))
+	vendor := gl.GoStr(gl.GetString(gl.VENDOR))
 	logger.Info("OpenGL initialized",
 		zap.String("version", version),
 		zap.String("renderer", renderer),
 	)
+	g.gpuInfo = fmt.Sprintf("%s / %s / OpenGL %s", vendor, renderer, version)
+
+	gldebug.SetEnabled(cfg.Graphics.GLDebug)
+	if mode := gldebug.Init(); mode != gldebug.ModeOff {
+		logger.Info("OpenGL error checking enabled", zap.Stringer("mode", mode))
+	}
 
 	// Initialize game state
 	if err := g.initGameState(cfg); err != nil {
@@ -150,6 +214,7 @@ func New(cfg *config.Config) (*Game, error) {
 		return nil, fmt.Errorf("create ui2d backend: %w", err)
 	}
 	ui2dBackend.SetAssetLoader(g.assetManager.Load)
+	ui2dBackend.SetUIScale(cfg.Graphics.UIScale)
 	g.uiBackend = ui2dBackend
 
 	logger.Info("game initialized successfully")
@@ -169,13 +234,16 @@ func NewHeadless(cfg *config.Config) (*Game, error) {
 	)
 
 	g := &Game{
-		config:        cfg,
-		running:       false,
-		stateManager:  states.NewManager(),
-		client:        network.New(),
-		assetManager:  assets.NewManager(),
-		screenshotDir: "data/Screenshots",
+		config:       cfg,
+		running:      false,
+		stateManager: states.NewManager(),
+		client:       network.New(),
+		assetManager: assets.NewManager(),
+		screenshots:  newScreenshotManager(cfg),
+
+		notifications: ui.NewNotificationQueue(cfg.Game.NotifyDuration, cfg.Game.MaxNotifications),
 	}
+	g.client.SetDialer(newDialer(cfg))
 
 	// Load GRF archives
 	for _, grfPath := range cfg.Data.GRFPaths {
@@ -185,6 +253,7 @@ func NewHeadless(cfg *config.Config) (*Game, error) {
 			logger.Info("loaded GRF archive", zap.String("path", grfPath))
 		}
 	}
+	g.addDataDirs(cfg.Data.DataDirs)
 
 	// Initialize game state
 	if err := g.initGameState(cfg); err != nil {
@@ -212,8 +281,35 @@ func (g *Game) initGameState(cfg *config.Config) error {
 		loginCfg.ServerPort = port
 	}
 
-	// Set texture loader for states
+	// Filtering applies as textures are uploaded, so set it before any load
+	texfilter.Set(textureFilter(cfg.Graphics))
+	if err := texture.Configure(cfg.Graphics.TextureKeys); err != nil {
+		logger.Warn("invalid texture keys, using the defaults", zap.Error(err))
+		texture.ResetPolicy()
+	}
+	g.loadLocale()
+	g.pacer = pacing.Pacer{Limit: max(cfg.Graphics.FPSLimit, 0), Throttle: cfg.Graphics.BackgroundThrottle}
+
+	// Set texture loader and sound player for states
 	g.stateManager.SetTexLoader(g.assetManager.Load)
+	g.initAudio(cfg)
+	g.stateManager.SetSoundPlayer(g.playSound, g.playSoundAt)
+	g.stateManager.SetAmbientPlayer(g.setAmbience)
+	g.stateManager.SetDevCommands(cfg.Game.DevCommands)
+	g.stateManager.SetReportDir(cfg.Logging.CrashDir)
+	g.stateManager.SetAuras(cfg.Graphics.Auras)
+	g.stateManager.SetBuffWarnings(cfg.Game.BuffWarnings)
+	g.stateManager.SetDoNotDisturb(cfg.Game.DoNotDisturb)
+	g.stateManager.SetInputBufferDepth(cfg.Game.InputBuffer)
+	g.stateManager.SetIdle(cfg.Game.AwayAfter, cfg.Game.IdleFidgets)
+	g.stateManager.SetInstantDialogText(cfg.Game.InstantDialogText)
+	g.stateManager.SetCameraEffects(cameraEffectScale(cfg.Accessibility))
+	g.stateManager.SetSpriteFallbacks(spriteFallbacks(cfg.Game.SpriteFallbacks))
+	g.stateManager.SetPostFX(postFXSettings(cfg.Graphics))
+	g.stateManager.SetColorLUT(loadColorLUT(cfg.Graphics.ColorLUT))
+	g.stateManager.SetDayCycle(cfg.Game.DayDuration, cfg.Game.NightDuration)
+	g.stateManager.SetLoginConfig(loginCfg)
+	g.warps = loadWarpTable(cfg.Data.WarpTables)
 
 	loginState := states.NewLoginState(loginCfg, g.client, g.stateManager)
 	g.stateManager.Change(loginState)
@@ -221,6 +317,112 @@ func (g *Game) initGameState(cfg *config.Config) error {
 	return nil
 }
 
+// initAudio starts audio output with the configured volumes. The game runs
+// silently if no audio device is available.
+func (g *Game) initAudio(cfg *config.Config) {
+	m := audio.New()
+	if err := m.Init(); err != nil {
+		logger.Warn("audio unavailable", zap.Error(err))
+		return
+	}
+	master := float64(cfg.Audio.MasterVolume)
+	if cfg.Audio.Muted {
+		master = 0
+	}
+	m.SetMasterVolume(master)
+	m.SetBGMVolume(float64(cfg.Audio.MusicVolume))
+	m.SetSFXVolume(float64(cfg.Audio.SFXVolume))
+	for name, r := range cfg.Audio.Rolloff {
+		category, ok := audio.ParseCategory(name)
+		curve, curveOK := audio.ParseRolloffCurve(r.Curve)
+		if !ok || !curveOK {
+			logger.Warn("ignoring audio rolloff", zap.String("category", name), zap.String("curve", r.Curve))
+			continue
+		}
+		m.SetRolloff(category, audio.Rolloff{
+			Curve:       curve,
+			MinDistance: float64(r.MinDistance * worldUnitsPerCell),
+			MaxDistance: float64(r.MaxDistance * worldUnitsPerCell),
+		})
+	}
+	g.audio = m
+}
+
+// worldUnitsPerCell is the size of a map cell in world units.
+const worldUnitsPerCell = 5
+
+// playSound plays a WAV interface sound effect from the GRF archives.
+func (g *Game) playSound(path string) {
+	g.playSoundFrom(path, audio.SoundSource{Name: path, Category: audio.CategoryUI})
+}
+
+// playSoundAt plays a WAV sound effect from the GRF archives at a world
+// position, fading it with distance from the player.
+func (g *Game) playSoundAt(path string, x, y, z float32) {
+	g.playSoundFrom(path, audio.SoundSource{
+		Name: path, Category: audio.CategoryEffect, Positional: true,
+		X: float64(x), Y: float64(y), Z: float64(z),
+	})
+}
+
+func (g *Game) playSoundFrom(path string, src audio.SoundSource) {
+	if g.audio == nil {
+		return
+	}
+	data, err := g.assetManager.Load(path)
+	if err != nil {
+		logger.Debug("sound not found", zap.String("path", path), zap.Error(err))
+		return
+	}
+	if err := g.audio.PlaySound(data, src); err != nil {
+		logger.Debug("failed to play sound", zap.String("path", path), zap.Error(err))
+	}
+}
+
+// setAmbience plays a map's looping ambient sounds from the GRF archives.
+func (g *Game) setAmbience(sounds []states.AmbientSound) {
+	if g.audio == nil {
+		return
+	}
+	emitters := make([]audio.Emitter, len(sounds))
+	for i, s := range sounds {
+		emitters[i] = audio.Emitter{
+			File:   s.Path,
+			X:      float64(s.X),
+			Y:      float64(s.Y),
+			Z:      float64(s.Z),
+			Volume: float64(s.Volume),
+			Range:  float64(s.Range),
+			Cycle:  float64(s.Cycle),
+		}
+	}
+	if err := g.audio.SetAmbient(emitters, g.assetManager.Load); err != nil {
+		logger.Debug("ambient sounds missing", zap.Error(err))
+	}
+}
+
+// updateAudio hears the world from the player and applies the map's
+// acoustics.
+func (g *Game) updateAudio(mapName string, x, y, z float32) {
+	if g.audio == nil {
+		return
+	}
+	g.audio.SetListener(float64(x), float64(y), float64(z))
+	g.audio.SetEnvironment(audio.EnvironmentForMap(mapName))
+}
+
+// audioDebug describes the audio environment and the voices playing for
+// the debug overlay.
+func (g *Game) audioDebug() (env string, voices []string) {
+	if g.audio == nil {
+		return "off", nil
+	}
+	for _, v := range g.audio.Voices() {
+		voices = append(voices, fmt.Sprintf("%-7s %3.0f%% %5.1f  %s", v.Category, v.Gain*100, v.Distance/worldUnitsPerCell, v.Name))
+	}
+	return g.audio.GetEnvironment().Name, voices
+}
+
 // loadKoreanFont loads a font with Korean glyph support.
 func (g *Game) loadKoreanFont() {
 	io := imgui.CurrentIO()
@@ -265,16 +467,79 @@ func (g *Game) Run() error {
 
 	logger.Info("starting game loop")
 
-	// Run with ImGui backend
+	// Run with ImGui backend. Panics must not unwind through the C frames of
+	// the backend loop, so they are recovered per frame and returned instead.
 	g.imguiBackend.Run(func() {
+		if g.crashErr != nil || !g.PaceFrame(g.windowState()) {
+			return
+		}
+		defer func() {
+			if r := recover(); r != nil {
+				g.crashErr = &crash.PanicError{Value: r, Stack: debug.Stack()}
+				g.running = false
+				g.imguiBackend.SetShouldClose(true)
+			}
+		}()
 		g.frame()
 	})
 
+	if g.crashErr != nil {
+		return g.crashErr
+	}
 	return nil
 }
 
+// PaceFrame sleeps until the next frame is due for a window in state w and
+// reports whether to run it. Minimized, it sleeps pacing.PollInterval at
+// most and returns false while the frame isn't due yet, so the loop goes
+// back to polling events and notices the window being restored.
+func (g *Game) PaceFrame(w pacing.Window) bool {
+	if wait := g.pacer.Until(time.Now(), w); wait > 0 {
+		if w.Minimized && wait > pacing.PollInterval {
+			time.Sleep(pacing.PollInterval)
+			return false
+		}
+		time.Sleep(wait)
+	}
+	g.pacer.Begin(time.Now())
+	return true
+}
+
+// windowState returns the focus and visibility of the ImGui-hosted window.
+// The SDL backend reports a minimized window as having no size.
+func (g *Game) windowState() pacing.Window {
+	io := imgui.CurrentIO()
+	size := io.DisplaySize()
+	return pacing.Window{Focused: !io.AppFocusLost(), Minimized: size.X <= 0 || size.Y <= 0}
+}
+
+// applyVSync sets the swap interval of the ImGui-hosted window from the
+// config. Headless, the caller owns the GL context and its swap interval.
+func (g *Game) applyVSync() {
+	if g.imguiBackend == nil {
+		return
+	}
+	interval := sdlbackend.SDLSwapIntervalImmediate
+	if g.config.Graphics.VSync {
+		interval = sdlbackend.SDLSwapIntervalVsync
+	}
+	if err := g.imguiBackend.SetSwapInterval(interval); err != nil {
+		logger.Warn("failed to set vsync", zap.Bool("vsync", g.config.Graphics.VSync), zap.Error(err))
+	}
+}
+
+// GPUInfo returns the OpenGL vendor, renderer and version, if known.
+func (g *Game) GPUInfo() string {
+	return g.gpuInfo
+}
+
 // frame processes a single frame.
 func (g *Game) frame() {
+	if !g.beginReplayFrame() {
+		return
+	}
+	defer g.endReplayFrame()
+
 	// Run any pending UI action from the previous frame (login, char-select, etc).
 	// Deferred one frame so the click visibly highlights before the action fires.
 	if g.pendingAction != nil {
@@ -287,6 +552,9 @@ func (g *Game) frame() {
 	now := time.Now()
 	g.dt = now.Sub(g.lastTime).Seconds()
 	g.lastTime = now
+	if step, fixed := clock.Step(); fixed {
+		g.dt = step.Seconds()
+	}
 
 	// Update FPS counter
 	g.frameCount++
@@ -300,15 +568,30 @@ func (g *Game) frame() {
 		}
 	}
 
-	// Handle ESC to quit
-	if imgui.IsKeyPressedBoolV(imgui.KeyEscape, false) {
-		g.running = false
-		g.imguiBackend.SetShouldClose(true)
+	// Handle ESC to open the menu in game and quit elsewhere (unless it is
+	// leaving a text field such as chat, or closing the player menu or drop
+	// dialog)
+	if imgui.IsKeyPressedBoolV(imgui.KeyEscape, false) && !imgui.CurrentIO().WantTextInput() && !g.inGamePopupOpen() {
+		if _, inGame := g.stateManager.Current().(*states.InGameState); inGame {
+			g.showSystemMenu = !g.showSystemMenu
+		} else {
+			g.quit()
+		}
 	}
 
-	// Handle F12 for screenshot (will capture at start of NEXT frame)
+	// Handle F12 for screenshot (captured after this frame's render)
 	if imgui.IsKeyChordPressed(imgui.KeyChord(imgui.KeyF12)) {
-		g.screenshotRequested = true
+		g.screenshots.requested = true
+	}
+
+	// F11 toggles the screenshot gallery
+	if imgui.IsKeyPressedBoolV(imgui.KeyF11, false) {
+		g.ToggleScreenshotGallery()
+	}
+
+	// F10 toggles the settings window
+	if imgui.IsKeyPressedBoolV(imgui.KeyF10, false) {
+		g.ToggleSettings()
 	}
 
 	// F3 toggles the in-game debug overlay (player/camera/scene/network).
@@ -318,32 +601,62 @@ func (g *Game) frame() {
 
 	// Handle camera controls when in InGameState
 	if inGameState, ok := g.stateManager.Current().(*states.InGameState); ok {
+		// F4 (debug overlay open) cycles the picture-in-picture camera
+		if g.showDebug && imgui.IsKeyPressedBoolV(imgui.KeyF4, false) {
+			inGameState.CyclePiPMode()
+		}
+		// Alt+E toggles the inventory window
+		if imgui.IsKeyChordPressed(imgui.KeyChord(imgui.ModAlt|imgui.KeyE)) && !imgui.CurrentIO().WantTextInput() {
+			g.showInventory = !g.showInventory
+		}
+		// Alt+Q toggles the equipment window
+		if imgui.IsKeyChordPressed(imgui.KeyChord(imgui.ModAlt|imgui.KeyQ)) && !imgui.CurrentIO().WantTextInput() {
+			g.showEquipment = !g.showEquipment
+		}
+		// Alt+V toggles the area map
+		if imgui.IsKeyChordPressed(imgui.KeyChord(imgui.ModAlt|imgui.KeyV)) && !imgui.CurrentIO().WantTextInput() {
+			g.areaMap.open = !g.areaMap.open
+		}
+		// Alt+H toggles the NPC dialog history
+		if imgui.IsKeyChordPressed(imgui.KeyChord(imgui.ModAlt|imgui.KeyH)) && !imgui.CurrentIO().WantTextInput() {
+			g.showDialogHistory = !g.showDialogHistory
+		}
+		// Space completes the NPC dialog page or presses its button; held,
+		// it moves past pages with Next as they come
+		space := imgui.IsKeyDown(imgui.KeySpace) && !imgui.CurrentIO().WantTextInput()
+		if space && imgui.IsKeyPressedBoolV(imgui.KeySpace, false) {
+			inGameState.AdvanceDialog()
+		}
+		inGameState.SetDialogAutoAdvance(space)
 		g.handleInGameInput(inGameState)
 	}
 
 	// Update state machine
+	g.reloadEditedAssets()
 	if err := g.stateManager.Update(g.dt); err != nil {
 		logger.Error("state update error", zap.Error(err))
 	}
 
 	// Render 3D scene (if applicable)
+	endSection := gldebug.Section("state")
 	if err := g.stateManager.Render(); err != nil {
 		logger.Error("state render error", zap.Error(err))
 	}
+	endSection()
 
 	// Render UI based on current state
+	endSection = gldebug.Section("ui")
 	g.renderUI()
+	endSection()
 
 	// Capture screenshot AFTER rendering (from back buffer before swap)
-	if g.screenshotRequested {
-		g.screenshotRequested = false
-		g.captureScreenshot()
-	}
+	g.ProcessScreenshot()
 }
 
 // renderUI renders the appropriate UI for the current state.
 func (g *Game) renderUI() {
 	viewportWidth, viewportHeight := g.uiBackend.GetScreenSize()
+	g.syncSettings()
 
 	// Begin UI frame
 	g.uiBackend.Begin()
@@ -357,6 +670,7 @@ func (g *Game) renderUI() {
 			ErrorMessage: state.GetErrorMessage(),
 			IsLoading:    state.IsLoadingState(),
 			ServerName:   g.config.Network.LoginServer,
+			WaitMessage:  state.WaitStatus(),
 			OnUsernameChange: func(s string) {
 				state.SetUsername(s)
 			},
@@ -368,6 +682,7 @@ func (g *Game) renderUI() {
 					_ = state.AttemptLogin()
 				}
 			},
+			OnCancel: loginCancel(state),
 		}, viewportWidth, viewportHeight)
 
 	case *states.ConnectingState:
@@ -377,18 +692,40 @@ func (g *Game) renderUI() {
 		}, viewportWidth, viewportHeight)
 
 	case *states.CharSelectState:
-		g.uiBackend.RenderCharSelectUI(ui.CharSelectUIState{
+		charSelect := ui.CharSelectUIState{
 			Characters:    state.GetCharacters(),
 			SelectedIndex: -1, // Managed by the backend
 			StatusMessage: state.GetStatusMessage(),
 			ErrorMessage:  state.GetErrorMessage(),
-			IsLoading:     state.IsLoadingState(),
+			IsLoading:     state.IsLoadingState() || !state.CharactersUnlocked(),
 			IsReady:       state.IsCharListReady(),
 			OnSelect: func(index int) {
+				if chars := state.GetCharacters(); index >= 0 && index < len(chars) {
+					g.charName = ui.GetCharName(chars[index])
+				}
 				g.pendingAction = func() {
 					_ = state.SelectCharacter(index)
 				}
 			},
+			Pincode:    g.pincodeUI(state),
+			PincodeSet: state.PincodeSet(),
+		}
+		if state.PincodeEnabled() {
+			charSelect.OnChangePincode = func() {
+				g.pendingAction = func() {
+					_ = state.ChangePincode()
+				}
+			}
+		}
+		g.uiBackend.RenderCharSelectUI(charSelect, viewportWidth, viewportHeight)
+
+	case *states.DisconnectedState:
+		g.uiBackend.RenderDisconnectedUI(ui.DisconnectedUIState{
+			Title:   state.GetTitle(),
+			Message: state.GetMessage(),
+			OnReturn: func() {
+				g.pendingAction = state.ReturnToLogin
+			},
 		}, viewportWidth, viewportHeight)
 
 	case *states.LoadingState:
@@ -410,6 +747,7 @@ func (g *Game) renderUI() {
 			playerDirection = uint8(player.Direction)
 		}
 		playerTileX, playerTileY = state.GetPlayerTilePosition()
+		g.updateAudio(state.GetMapName(), playerX, playerY, playerZ)
 
 		uiState := ui.InGameUIState{
 			MapName:         state.GetMapName(),
@@ -427,6 +765,81 @@ func (g *Game) renderUI() {
 			FPS:             g.fps,
 		}
 		populateDebugFields(&uiState, state, g.client)
+		uiState.PingMs = int(state.Latency().Milliseconds())
+		uiState.ShowPing = g.config.Game.ShowPing
+		uiState.ServerLagging = state.ServerStalled()
+		pvp := state.PVPStatus()
+		uiState.PVPMode, uiState.PVPRank, uiState.PVPPlayers = pvp.Mode, pvp.Rank, pvp.Total
+		uiState.AttackCursor = state.HoverAttackable()
+
+		progress := state.GetProgress()
+		uiState.PlayerLevel = progress.BaseLevel
+		uiState.PlayerJobLevel = progress.JobLevel
+		uiState.BaseExpRatio = progress.BaseExpRatio()
+		uiState.JobExpRatio = progress.JobExpRatio()
+		chatLog := state.ChatLog()
+		uiState.ChatMessages = chatLines(chatLog)
+		uiState.ChatTabs = chatTabs(chatLog)
+		uiState.ActiveChatTab = chatLog.Active()
+		uiState.ChatTabActions = g.chatTabActions(chatLog)
+		uiState.OnChatSubmit = state.SubmitChat
+		uiState.OnChatComplete = state.CompleteChat
+		uiState.ChatDraft = state.TakeChatDraft()
+		uiState.ContextMenu = playerContextMenu(state)
+		uiState.ShowInventory = g.showInventory
+		uiState.Inventory = inventoryRows(state.GetInventory())
+		uiState.OnItemDrop = state.RequestDrop
+		uiState.ShowEquipment = g.showEquipment
+		uiState.OnEquip = func(index int) {
+			if err := state.EquipItem(index); err != nil {
+				logger.Warn("equip failed", zap.Int("index", index), zap.Error(err))
+			}
+		}
+		uiState.OnUnequip = func(index int) {
+			if err := state.UnequipItem(index); err != nil {
+				logger.Warn("unequip failed", zap.Int("index", index), zap.Error(err))
+			}
+		}
+		uiState.DropPrompt = dropPrompt(state)
+		uiState.VendingShop = vendingShop(state)
+		uiState.RequestDialog = requestDialog(state)
+		uiState.NPCDialog = g.npcDialog(state)
+		uiState.DialogHistory = g.dialogHistory(state)
+		uiState.AreaMap = g.areaMapState(state)
+		if b := state.Banner(); b != nil {
+			uiState.Banner = &ui.BannerState{Text: b.Text, Color: b.Color, FontSize: b.FontSize, Progress: b.Progress}
+		}
+		for _, b := range state.Buffs() {
+			uiState.Buffs = append(uiState.Buffs, ui.BuffIcon{
+				Name: b.Name, Timed: b.Timed, Remaining: b.Remaining, Fraction: b.Fraction, Expiring: b.Expiring,
+			})
+		}
+		if c := state.Cutin(); c != nil {
+			uiState.Cutin = &ui.CutinState{Image: c.Image, Position: c.Position, Alpha: c.Alpha}
+		}
+		uiState.DamageNumbers = g.damageNumbers(state, viewportWidth, viewportHeight)
+		uiState.Nameplates = g.nameplates(state, viewportWidth, viewportHeight)
+		uiState.ChatBubbles = g.chatBubbles(state, viewportWidth, viewportHeight)
+		uiState.PiPMode = state.GetPiPMode().String()
+		if g.showDebug {
+			uiState.PiPTexture = state.GetPiPTexture()
+			uiState.AudioEnvironment, uiState.AudioVoices = g.audioDebug()
+			uiState.MissingSprites = state.MissingSprites()
+		}
+		if pe := state.GetPlayerEntity(); pe != nil {
+			uiState.PlayerHP, uiState.PlayerMaxHP = pe.HP, pe.MaxHP
+			uiState.PlayerSP, uiState.PlayerMaxSP = pe.SP, pe.MaxSP
+		}
+		uiState.Weight, uiState.Zeny = state.GetWeight(), state.GetZeny()
+
+		// UI-less capture: draw only the scene for the frame being captured
+		if g.screenshots.hidingUI() {
+			if uiState.SceneReady && uiState.SceneTexture != 0 {
+				g.uiBackend.DrawSceneTexture(0, 0, viewportWidth, viewportHeight, uiState.SceneTexture)
+			}
+			g.uiBackend.End()
+			return
+		}
 		g.uiBackend.RenderInGameUI(uiState, g.dt, viewportWidth, viewportHeight)
 
 	default:
@@ -443,11 +856,82 @@ func (g *Game) renderUI() {
 		g.uiBackend.RenderFPSOverlay(g.fps, viewportWidth, viewportHeight)
 	}
 
-	// Screenshot notification (show for 3 seconds)
-	if g.screenshotMsg != "" && time.Since(g.screenshotMsgTime) < 3*time.Second {
-		g.uiBackend.RenderScreenshotMessage(g.screenshotMsg, viewportWidth, viewportHeight)
+	// Escape menu
+	if state, ok := g.stateManager.Current().(*states.InGameState); ok && g.showSystemMenu {
+		g.uiBackend.RenderSystemMenu(ui.SystemMenuState{
+			CharSelectPending: state.CharSelectPending(),
+			OnCharSelect: func() {
+				g.showSystemMenu = false
+				g.pendingAction = state.RequestCharSelect
+			},
+			OnSettings: func() {
+				g.showSystemMenu = false
+				g.showSettings = true
+			},
+			OnExit: g.quit,
+			OnClose: func() {
+				g.showSystemMenu = false
+			},
+		}, viewportWidth, viewportHeight)
+	} else {
+		g.showSystemMenu = false
 	}
 
+	// Settings window
+	if g.showSettings {
+		fx := postFXSettings(g.config.Graphics)
+		g.uiBackend.RenderSettingsUI(ui.SettingsUIState{
+			UIScale:                   g.config.Graphics.UIScale,
+			Auras:                     g.config.Graphics.Auras,
+			InstantDialogText:         g.config.Game.InstantDialogText,
+			Language:                  locale.LanguageName(g.config.Game.Language),
+			VSync:                     g.config.Graphics.VSync,
+			FPSLimit:                  g.pacer.Limit,
+			BackgroundThrottle:        g.pacer.Throttle,
+			Gamma:                     fx.Gamma,
+			Brightness:                fx.Brightness,
+			FXAA:                      g.config.Graphics.FXAA,
+			ColorGrading:              g.config.Graphics.ColorGrading,
+			HasColorLUT:               g.stateManager.ColorLUT != nil,
+			Palette:                   g.config.Accessibility.Palette,
+			DamageTextScale:           g.config.Accessibility.DamageTextScale,
+			ReduceFlashes:             g.config.Accessibility.ReduceFlashes,
+			CameraEffects:             g.config.Accessibility.CameraEffects,
+			CameraEffectScale:         camera.ClampEffectScale(g.config.Accessibility.CameraEffectScale),
+			Nameplates:                g.nameplateSettings(),
+			NameplateGuild:            g.nameplateConfig().Guild,
+			NameplatePartyHP:          g.nameplateConfig().PartyHP,
+			OnUIScaleChange:           g.SetUIScale,
+			OnAurasChange:             g.SetAuras,
+			OnInstantDialogTextChange: g.SetInstantDialogText,
+			OnLanguageNext: func() {
+				g.SetLanguage(locale.NextLanguage(g.config.Game.Language))
+			},
+			OnVSyncChange:             g.SetVSync,
+			OnFPSLimitChange:          g.SetFPSLimit,
+			OnThrottleChange:          g.SetBackgroundThrottle,
+			OnGammaChange:             g.SetGamma,
+			OnBrightnessChange:        g.SetBrightness,
+			OnFXAAChange:              g.SetFXAA,
+			OnColorGradingChange:      g.SetColorGrading,
+			OnPaletteChange:           g.SetPalette,
+			OnDamageTextScaleChange:   g.SetDamageTextScale,
+			OnReduceFlashesChange:     g.SetReduceFlashes,
+			OnCameraEffectsChange:     g.SetCameraEffects,
+			OnCameraEffectScaleChange: g.SetCameraEffectScale,
+			OnNameplateModeCycle:      g.CycleNameplateMode,
+			OnNameplateGuildChange:    g.SetNameplateGuild,
+			OnNameplatePartyHPChange:  g.SetNameplatePartyHP,
+			OnClose: func() {
+				g.showSettings = false
+			},
+		}, viewportWidth, viewportHeight)
+	}
+
+	// Capture flash and gallery, then the notifications over all
+	g.renderScreenshotOverlays(viewportWidth, viewportHeight)
+	g.renderNotifications(viewportWidth, viewportHeight)
+
 	// End UI frame
 	g.uiBackend.End()
 }
@@ -455,8 +939,14 @@ func (g *Game) renderUI() {
 // Close cleans up game resources.
 func (g *Game) Close() {
 	logger.Info("closing game")
+	g.stopReplay()
+
+	if g.screenshots != nil {
+		g.screenshots.releaseGallery()
+	}
 
 	if g.uiBackend != nil {
+		g.saveSettings()
 		g.uiBackend.Close()
 	}
 
@@ -464,84 +954,29 @@ func (g *Game) Close() {
 		g.client.Disconnect()
 	}
 
+	if g.audio != nil {
+		g.audio.Close()
+	}
+
 	if g.assetManager != nil {
 		g.assetManager.Close()
 	}
 }
 
-// captureScreenshot captures the current frame to a PNG file.
-func (g *Game) captureScreenshot() {
-	var pixels []byte
-	var width, height int
-
-	// Get actual viewport size from OpenGL (handles HiDPI correctly)
-	var viewport [4]int32
-	gl.GetIntegerv(gl.VIEWPORT, &viewport[0])
-	width = int(viewport[2])
-	height = int(viewport[3])
-
-	if width <= 0 || height <= 0 {
-		logger.Warn("screenshot failed: invalid viewport")
-		return
-	}
-
-	pixels = make([]byte, width*height*4)
-	gl.ReadPixels(0, 0, int32(width), int32(height), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(pixels))
-
-	// Flip vertically for default framebuffer
-	rowSize := width * 4
-	flipped := make([]byte, len(pixels))
-	for y := 0; y < height; y++ {
-		srcRow := (height - 1 - y) * rowSize
-		dstRow := y * rowSize
-		copy(flipped[dstRow:dstRow+rowSize], pixels[srcRow:srcRow+rowSize])
-	}
-	pixels = flipped
-
-	// Create screenshot directory if needed
-	if err := os.MkdirAll(g.screenshotDir, 0755); err != nil {
-		logger.Warn("failed to create screenshot dir", zap.Error(err))
-		return
-	}
-
-	// Create image (pixels are already in correct orientation from CaptureScene or flipped above)
-	img := image.NewRGBA(image.Rect(0, 0, width, height))
-	copy(img.Pix, pixels)
-
-	// Generate filename with timestamp
-	timestamp := time.Now().Format("20060102-150405")
-	filename := fmt.Sprintf("screenshot-%s.png", timestamp)
-	savePath := filepath.Join(g.screenshotDir, filename)
-
-	// Save to file
-	file, err := os.Create(savePath)
-	if err != nil {
-		logger.Warn("failed to create screenshot file", zap.Error(err))
-		return
+// handleInGameInput handles camera and movement input when in game.
+func (g *Game) handleInGameInput(state *states.InGameState) {
+	// Any input keeps the player from being marked away
+	if inputActive() {
+		state.NoteActivity()
 	}
-	defer file.Close()
 
-	if err := png.Encode(file, img); err != nil {
-		logger.Warn("failed to encode screenshot", zap.Error(err))
+	camera := state.GetCamera()
+	if camera == nil {
 		return
 	}
 
-	// Also save as "latest.png" for easy access
-	latestPath := filepath.Join(g.screenshotDir, "latest.png")
-	if latestFile, err := os.Create(latestPath); err == nil {
-		_ = png.Encode(latestFile, img)
-		latestFile.Close()
-	}
-
-	g.screenshotMsg = fmt.Sprintf("Saved: %s", filename)
-	g.screenshotMsgTime = time.Now()
-	logger.Info("screenshot saved", zap.String("path", savePath))
-}
-
-// handleInGameInput handles camera and movement input when in game.
-func (g *Game) handleInGameInput(state *states.InGameState) {
-	camera := state.GetCamera()
-	if camera == nil {
+	// The area map covers the world; clicks on it are handled by the UI
+	if g.areaMap.open {
 		return
 	}
 
@@ -569,11 +1004,39 @@ func (g *Game) handleInGameInput(state *states.InGameState) {
 	g.lastMouseX = mouseX
 	g.lastMouseY = mouseY
 
+	// The unit under the mouse shows its name in nameplate hover mode
+	if io.WantCaptureMouse() {
+		state.ClearHover()
+	} else {
+		viewportW, viewportH := g.uiBackend.GetScreenSize()
+		state.SetHover(mouseX, mouseY, viewportW, viewportH)
+	}
+
+	// Right click without dragging the camera opens the player menu
+	if imgui.IsMouseReleased(imgui.MouseButtonRight) && !io.WantCaptureMouse() {
+		drag := imgui.MouseDragDeltaV(imgui.MouseButtonRight, 0)
+		if drag.X*drag.X+drag.Y*drag.Y < playerMenuMaxDrag*playerMenuMaxDrag {
+			viewportW, viewportH := g.uiBackend.GetScreenSize()
+			state.OpenPlayerMenu(mouseX, mouseY, viewportW, viewportH)
+		}
+	}
+
 	// Left click for click-to-move. Skip if any imgui window (HUD, minimap,
-	// chat, etc) is consuming the click; otherwise ray-cast to ground plane
-	// and dispatch a server move request.
+	// chat, etc) is consuming the click; a click on a chat room board shows
+	// the room, one on a shop board or vendor opens the shop and one on an
+	// attackable unit attacks it; otherwise ray-cast to ground plane and
+	// dispatch a server move request.
 	if imgui.IsMouseClickedBool(imgui.MouseButtonLeft) && !io.WantCaptureMouse() {
 		viewportW, viewportH := g.uiBackend.GetScreenSize()
+		if state.ChatRoomAt(mouseX, mouseY, viewportW, viewportH) {
+			return
+		}
+		if state.OpenVendingAt(mouseX, mouseY, viewportW, viewportH) {
+			return
+		}
+		if state.AttackHovered() {
+			return
+		}
 		if tileX, tileY, ok := state.ScreenToTile(mouseX, mouseY, viewportW, viewportH); ok {
 			if err := state.RequestMove(tileX, tileY); err != nil {
 				logger.Warn("click-to-move RequestMove failed", zap.Error(err))
@@ -582,6 +1045,287 @@ func (g *Game) handleInGameInput(state *states.InGameState) {
 	}
 }
 
+// inputActive reports whether the mouse moved or scrolled, or a mouse
+// button or key is down, this frame.
+func inputActive() bool {
+	io := imgui.CurrentIO()
+	if d := io.MouseDelta(); d.X != 0 || d.Y != 0 || io.MouseWheel() != 0 || imgui.IsAnyMouseDown() {
+		return true
+	}
+	for k := imgui.KeyNamedKeyBEGIN; k < imgui.KeyNamedKeyEND; k++ {
+		if imgui.IsKeyDown(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// NoteActivity records player input in game, keeping the player from
+// being marked away. For event loops outside the ImGui backend's.
+func (g *Game) NoteActivity() {
+	if state, ok := g.stateManager.Current().(*states.InGameState); ok {
+		state.NoteActivity()
+	}
+}
+
+// playerMenuMaxDrag is how far (pixels) the mouse may move between right
+// press and release for it to count as a click rather than a camera drag.
+const playerMenuMaxDrag = 4
+
+// inGamePopupOpen reports whether the area map, the dialog history, the
+// in-game player menu, drop dialog, a shop or a request dialog is open.
+func (g *Game) inGamePopupOpen() bool {
+	state, ok := g.stateManager.Current().(*states.InGameState)
+	return ok && (g.areaMap.open || g.showDialogHistory || state.GetPlayerMenu() != nil || state.GetDropPrompt() != nil ||
+		state.GetVendingShop() != nil || state.GetRequestDialog() != nil)
+}
+
+// loginCancel returns the login screen's cancel action while it waits on a
+// busy server, or nil.
+func loginCancel(state *states.LoginState) func() {
+	if !state.Waiting() {
+		return nil
+	}
+	return state.CancelLogin
+}
+
+// pincodeUI builds the PIN keypad for the character server's prompt, or nil.
+func (g *Game) pincodeUI(state *states.CharSelectState) *ui.PincodeUIState {
+	prompt := state.Pincode()
+	if prompt == nil {
+		return nil
+	}
+	p := &ui.PincodeUIState{
+		AskCurrent: prompt.AskCurrent,
+		AskNew:     prompt.AskNew,
+		Keypad:     prompt.Keypad,
+		Message:    prompt.Message,
+		OnSubmit: func(current, newPin string) {
+			g.pendingAction = func() {
+				_ = state.SubmitPincode(current, newPin)
+			}
+		},
+	}
+	if prompt.Optional {
+		p.OnCancel = func() {
+			g.pendingAction = state.CancelPincode
+		}
+	}
+	return p
+}
+
+// playerContextMenu builds the UI for the state's open player menu, or nil.
+func playerContextMenu(state *states.InGameState) *ui.ContextMenuState {
+	menu := state.GetPlayerMenu()
+	if menu == nil {
+		return nil
+	}
+
+	title := menu.Name
+	if title == "" {
+		title = "Player"
+	}
+	cm := &ui.ContextMenuState{
+		Title:   title,
+		X:       menu.ScreenX,
+		Y:       menu.ScreenY,
+		OnClose: state.ClosePlayerMenu,
+	}
+	for _, entry := range menu.Entries {
+		action := entry.Action
+		cm.Items = append(cm.Items, ui.ContextMenuItem{
+			Label:    action.String(),
+			Enabled:  entry.Enabled,
+			OnSelect: func() { state.RunPlayerAction(action) },
+		})
+	}
+	return cm
+}
+
+// inventoryRows converts the inventory to inventory window rows.
+func inventoryRows(inv *entity.Inventory) []ui.InventoryItem {
+	items := inv.Items()
+	rows := make([]ui.InventoryItem, len(items))
+	for i, item := range items {
+		rows[i] = ui.InventoryItem{
+			Index:     item.Index,
+			Name:      item.AffixedName(),
+			Amount:    item.Amount,
+			Droppable: !item.Equipped(),
+			Location:  item.Location,
+			Worn:      item.Worn,
+			Refine:    item.Refine,
+			Cards:     item.CardCount(),
+			Slots:     item.Slots(),
+			Grade:     item.Grade(),
+		}
+		for _, card := range item.SlotCards() {
+			name := ""
+			if card != 0 {
+				name = entity.InventoryItem{ItemID: card, Type: entity.ItemCard}.Name()
+			}
+			rows[i].SlotCards = append(rows[i].SlotCards, name)
+		}
+	}
+	return rows
+}
+
+// dropPrompt builds the quantity dialog for the state's pending drop, or nil.
+func dropPrompt(state *states.InGameState) *ui.QuantityPrompt {
+	prompt := state.GetDropPrompt()
+	if prompt == nil {
+		return nil
+	}
+	return &ui.QuantityPrompt{
+		Title:     "Drop " + prompt.Name,
+		Min:       1,
+		Max:       prompt.Max,
+		OnConfirm: state.ConfirmDrop,
+		OnCancel:  state.CancelDrop,
+	}
+}
+
+// requestDialog builds the dialog for the state's oldest request, or nil.
+func requestDialog(state *states.InGameState) *ui.RequestDialogState {
+	req := state.GetRequestDialog()
+	if req == nil {
+		return nil
+	}
+	d := &ui.RequestDialogState{
+		Message:      req.Message,
+		AcceptLabel:  "Accept",
+		DeclineLabel: "Decline",
+		Remaining:    req.Remaining(),
+		OnAnswer:     state.AnswerRequest,
+	}
+	switch req.Kind {
+	case states.RequestPartyInvite:
+		d.Title = "Party Invitation"
+	case states.RequestGuildInvite:
+		d.Title = "Guild Invitation"
+	case states.RequestTrade:
+		d.Title = "Trade Request"
+	case states.RequestRestart:
+		d.Title = "You Have Died"
+		d.AcceptLabel = "Save Point"
+		d.DeclineLabel = "Wait"
+	}
+	return d
+}
+
+// npcDialog builds the dialog window of the NPC talked to, or nil.
+func (g *Game) npcDialog(state *states.InGameState) *ui.NPCDialogState {
+	d := state.GetNPCDialog()
+	if d == nil {
+		return nil
+	}
+	dialog := &ui.NPCDialogState{
+		Name:      d.Name,
+		Text:      d.Text,
+		Typing:    d.Typing,
+		OnAdvance: state.AdvanceDialog,
+		OnHistory: func() {
+			g.showDialogHistory = true
+		},
+	}
+	switch d.Button {
+	case states.DialogNext:
+		dialog.Button = "Next"
+	case states.DialogClose:
+		dialog.Button = "Close"
+	}
+	return dialog
+}
+
+// dialogHistory builds the NPC dialog history window, or nil when closed.
+func (g *Game) dialogHistory(state *states.InGameState) *ui.DialogHistoryState {
+	if !g.showDialogHistory {
+		return nil
+	}
+	lines := state.DialogHistory()
+	hist := &ui.DialogHistoryState{
+		Lines: make([]ui.DialogHistoryLine, len(lines)),
+		OnClose: func() {
+			g.showDialogHistory = false
+		},
+	}
+	for i, line := range lines {
+		hist.Lines[i] = ui.DialogHistoryLine{Name: line.Name, Text: line.Text}
+	}
+	return hist
+}
+
+// vendingShop builds the window for the shop being browsed, or nil.
+func vendingShop(state *states.InGameState) *ui.VendingShopState {
+	shop := state.GetVendingShop()
+	if shop == nil {
+		return nil
+	}
+	view := &ui.VendingShopState{
+		VendorID: shop.VendorID,
+		Title:    shop.Title,
+		Zeny:     state.GetZeny(),
+		Items:    make([]ui.VendingShopItem, len(shop.Items)),
+		OnBuy:    state.ConfirmVendingPurchase,
+		OnClose:  state.CloseVendingShop,
+	}
+	for i, item := range shop.Items {
+		view.Items[i] = ui.VendingShopItem{
+			Index:  item.Index,
+			Name:   item.Name,
+			Price:  item.Price,
+			Amount: item.Amount,
+			Grade:  item.Grade,
+		}
+	}
+	return view
+}
+
+// chatBubbles builds the chat bubbles in view.
+func (g *Game) chatBubbles(state *states.InGameState, width, height float32) []ui.ChatBubble {
+	bubbles := state.GetChatBubbles(width, height)
+	if len(bubbles) == 0 {
+		return nil
+	}
+	out := make([]ui.ChatBubble, len(bubbles))
+	for i, b := range bubbles {
+		out[i] = ui.ChatBubble{X: b.ScreenX, Y: b.ScreenY, Text: b.Text, Alpha: b.Alpha}
+	}
+	return out
+}
+
+// criticalDamageScale enlarges critical hits over normal ones.
+const criticalDamageScale = 1.5
+
+// damageNumbers builds the damage numbers in view, colored by the
+// accessibility palette and sized by the damage text scale.
+func (g *Game) damageNumbers(state *states.InGameState, width, height float32) []ui.DamageNumber {
+	nums := state.GetDamageNumbers(width, height)
+	if len(nums) == 0 {
+		return nil
+	}
+	palette := ui2d.PaletteByName(g.config.Accessibility.Palette)
+	scale := ui2d.ClampDamageTextScale(g.config.Accessibility.DamageTextScale)
+	out := make([]ui.DamageNumber, len(nums))
+	for i, d := range nums {
+		color := palette.DamageDealt
+		if d.Kind == entity.DamageTaken {
+			color = palette.DamageTaken
+		}
+		color.A *= d.Alpha
+		text := strconv.Itoa(d.Amount)
+		if d.Amount <= 0 {
+			text = "Miss"
+		}
+		size := scale
+		if d.Critical {
+			size *= criticalDamageScale
+		}
+		out[i] = ui.DamageNumber{X: d.ScreenX, Y: d.ScreenY, Text: text, Color: color, Scale: size}
+	}
+	return out
+}
+
 // LoadAsset loads an asset from GRF archives.
 func (g *Game) LoadAsset(path string) ([]byte, error) {
 	return g.assetManager.Load(path)
@@ -594,6 +1338,7 @@ func (g *Game) SetUIBackend(backend ui.UIBackend) {
 		g.uiBackend.Close()
 	}
 	g.uiBackend = backend
+	g.uiBackend.SetUIScale(g.config.Graphics.UIScale)
 }
 
 // StateManager returns the state manager.
@@ -670,15 +1415,198 @@ func (g *Game) RenderUI() {
 	g.renderUI()
 }
 
+// quit ends the game loop after this frame.
+func (g *Game) quit() {
+	g.running = false
+	g.imguiBackend.SetShouldClose(true)
+}
+
+// ToggleSettings opens or closes the settings window.
+func (g *Game) ToggleSettings() {
+	g.showSettings = !g.showSettings
+}
+
+// SetUIScale changes the UI scale at runtime and persists it to the config file.
+func (g *Game) SetUIScale(scale float32) {
+	scale = ui2d.ClampScale(scale)
+	if scale == g.config.Graphics.UIScale {
+		return
+	}
+
+	g.config.Graphics.UIScale = scale
+	g.uiBackend.SetUIScale(scale)
+	g.persistConfig()
+}
+
+// SetPalette selects the color palette by name and persists it to the
+// config file. Unknown names select the default palette.
+func (g *Game) SetPalette(name string) {
+	name = ui2d.PaletteByName(name).Name
+	if name == g.config.Accessibility.Palette {
+		return
+	}
+	g.config.Accessibility.Palette = name
+	g.persistConfig()
+}
+
+// SetDamageTextScale changes the damage number size and persists it to the
+// config file.
+func (g *Game) SetDamageTextScale(scale float32) {
+	scale = ui2d.ClampDamageTextScale(scale)
+	if scale == g.config.Accessibility.DamageTextScale {
+		return
+	}
+	g.config.Accessibility.DamageTextScale = scale
+	g.persistConfig()
+}
+
+// spriteFallbacks converts the configured sprite fallback chain, dropping
+// unknown steps.
+func spriteFallbacks(names []string) []entity.SpriteFallback {
+	chain := make([]entity.SpriteFallback, 0, len(names))
+	for _, name := range names {
+		switch f := entity.SpriteFallback(name); f {
+		case entity.FallbackJob, entity.FallbackBaseJob, entity.FallbackNovice:
+			chain = append(chain, f)
+		default:
+			logger.Warn("unknown sprite fallback", zap.String("name", name))
+		}
+	}
+	return chain
+}
+
+// SetAuras turns level and job auras around characters on or off and
+// persists the choice to the config file.
+func (g *Game) SetAuras(enabled bool) {
+	if enabled == g.config.Graphics.Auras {
+		return
+	}
+	g.config.Graphics.Auras = enabled
+	g.stateManager.SetAuras(enabled)
+	g.persistConfig()
+}
+
+// loadLocale loads the client's text tables in the configured language.
+func (g *Game) loadLocale() {
+	if err := locale.Load(g.config.Game.Language, g.assetManager.Load, g.config.Game.StringOverrides); err != nil {
+		logger.Warn("failed to load string overrides", zap.String("path", g.config.Game.StringOverrides), zap.Error(err))
+	}
+}
+
+// SetLanguage switches the client's text to a language and persists the
+// choice to the config file.
+func (g *Game) SetLanguage(code string) {
+	if code == g.config.Game.Language {
+		return
+	}
+	g.config.Game.Language = code
+	g.loadLocale()
+	g.persistConfig()
+}
+
+// SetVSync turns vsync on or off and persists the choice to the config
+// file.
+func (g *Game) SetVSync(enabled bool) {
+	if enabled == g.config.Graphics.VSync {
+		return
+	}
+	g.config.Graphics.VSync = enabled
+	g.applyVSync()
+	g.persistConfig()
+}
+
+// SetFPSLimit caps the frame rate, 0 for no cap, and persists the choice
+// to the config file.
+func (g *Game) SetFPSLimit(limit int) {
+	limit = max(limit, 0)
+	if limit == g.config.Graphics.FPSLimit {
+		return
+	}
+	g.config.Graphics.FPSLimit = limit
+	g.pacer.Limit = limit
+	g.persistConfig()
+}
+
+// SetBackgroundThrottle turns slowing down in the background on or off and
+// persists the choice to the config file.
+func (g *Game) SetBackgroundThrottle(enabled bool) {
+	if enabled == g.config.Graphics.BackgroundThrottle {
+		return
+	}
+	g.config.Graphics.BackgroundThrottle = enabled
+	g.pacer.Throttle = enabled
+	g.persistConfig()
+}
+
+// SetInstantDialogText turns typing out NPC dialog text off or on and
+// persists the choice to the config file.
+func (g *Game) SetInstantDialogText(instant bool) {
+	if instant == g.config.Game.InstantDialogText {
+		return
+	}
+	g.config.Game.InstantDialogText = instant
+	g.stateManager.SetInstantDialogText(instant)
+	g.persistConfig()
+}
+
+// SetReduceFlashes turns full-screen flash effects off or on and persists
+// the choice to the config file.
+func (g *Game) SetReduceFlashes(reduce bool) {
+	if reduce == g.config.Accessibility.ReduceFlashes {
+		return
+	}
+	g.config.Accessibility.ReduceFlashes = reduce
+	g.persistConfig()
+}
+
+// SetCameraEffects turns camera shakes and zooms on or off and persists
+// the choice to the config file.
+func (g *Game) SetCameraEffects(enabled bool) {
+	if enabled == g.config.Accessibility.CameraEffects {
+		return
+	}
+	g.config.Accessibility.CameraEffects = enabled
+	g.stateManager.SetCameraEffects(cameraEffectScale(g.config.Accessibility))
+	g.persistConfig()
+}
+
+// SetCameraEffectScale changes the strength of camera shakes and zooms and
+// persists it to the config file.
+func (g *Game) SetCameraEffectScale(scale float32) {
+	scale = camera.ClampEffectScale(scale)
+	if scale == g.config.Accessibility.CameraEffectScale {
+		return
+	}
+	g.config.Accessibility.CameraEffectScale = scale
+	g.stateManager.SetCameraEffects(cameraEffectScale(g.config.Accessibility))
+	g.persistConfig()
+}
+
+// cameraEffectScale returns the strength of camera effects the
+// accessibility settings ask for, 0 if they're off.
+func cameraEffectScale(cfg config.AccessibilityConfig) float32 {
+	if !cfg.CameraEffects {
+		return 0
+	}
+	return camera.ClampEffectScale(cfg.CameraEffectScale)
+}
+
+func (g *Game) persistConfig() {
+	if err := g.config.Persist(); err != nil {
+		logger.Warn("failed to save config", zap.Error(err))
+	}
+}
+
 // HandleScreenshot requests a screenshot capture.
+// The capture happens in ProcessScreenshot after the next UI render.
 func (g *Game) HandleScreenshot() {
-	g.screenshotRequested = true
+	g.screenshots.requested = true
 }
 
 // ProcessScreenshot processes any pending screenshot request.
 func (g *Game) ProcessScreenshot() {
-	if g.screenshotRequested {
-		g.screenshotRequested = false
+	if g.screenshots.requested {
+		g.screenshots.requested = false
 		g.captureScreenshot()
 	}
 }
@@ -714,21 +1642,15 @@ func (g *Game) InitTiming() {
 
 // parseHostPort extracts host and port from "host:port" string.
 func parseHostPort(addr string) (string, int) {
-	var host string
-	var port int
-
-	n, err := fmt.Sscanf(addr, "%s:%d", &host, &port)
-	if err != nil || n != 2 {
-		// Try with colons allowed in format
-		for i := len(addr) - 1; i >= 0; i-- {
-			if addr[i] == ':' {
-				host = addr[:i]
-				_, _ = fmt.Sscanf(addr[i+1:], "%d", &port)
-				break
-			}
-		}
+	// net.SplitHostPort handles bracketed IPv6 literals ([::1]:6900)
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		// No port given: accept a bare hostname or IPv6 literal
+		host = strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]")
+		portStr = ""
 	}
 
+	port, _ := strconv.Atoi(portStr)
 	if port == 0 {
 		port = 6900 // Default
 	}
@@ -736,6 +1658,19 @@ func parseHostPort(addr string) (string, int) {
 	return host, port
 }
 
+// newDialer builds the network dialer from the proxy and timeout settings.
+func newDialer(cfg *config.Config) *network.Dialer {
+	return &network.Dialer{
+		Proxy: network.ProxyConfig{
+			Type:     cfg.Network.Proxy.Type,
+			Address:  cfg.Network.Proxy.Address,
+			Username: cfg.Network.Proxy.Username,
+			Password: cfg.Network.Proxy.Password,
+		},
+		Timeout: cfg.Network.ConnectTimeout,
+	}
+}
+
 // fileExists checks if a file exists.
 func fileExists(path string) bool {
 	_, err := os.Stat(path)
@@ -0,0 +1,245 @@
+// Package locale holds the client's text in the language picked in the
+// settings: the UI messages of msgstringtable.txt, which servers and the
+// game refer to by index, and the item names, descriptions and card
+// affixes.
+//
+// The GRF's own tables come first. A language's translation, the same
+// files under data/lang/<code>/, replaces their text where it has any,
+// and a translation override file on disk replaces messages of either,
+// so a player can fix a string without repacking a GRF.
+//
+// Load swaps the tables in place without a lock, so loading and lookups
+// must happen on the same goroutine; a language switch shows from the
+// next lookup on.
+package locale
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Faultbox/midgard-ro/pkg/formats"
+)
+
+// Language is a language the settings offer.
+type Language struct {
+	Code string // Folder of its tables under data/lang/
+	Name string
+}
+
+// Languages are the languages the settings step through.
+var Languages = []Language{
+	{"en", "English"},
+	{"ko", "Korean"},
+	{"ja", "Japanese"},
+	{"zh", "Chinese"},
+	{"pt", "Portuguese"},
+	{"es", "Spanish"},
+	{"de", "German"},
+	{"fr", "French"},
+	{"ru", "Russian"},
+	{"th", "Thai"},
+}
+
+// Reader reads a file of the GRFs and data folders.
+type Reader func(path string) ([]byte, error)
+
+// The item tables, indexes of items.
+const (
+	itemNames = iota
+	itemDescs
+	unidentifiedNames
+	unidentifiedDescs
+	cardPrefixes
+	slotCounts
+	itemTableCount
+)
+
+// itemTablePaths are the GRF paths of the item tables.
+var itemTablePaths = [itemTableCount]string{
+	itemNames:         formats.ItemNameTablePath,
+	itemDescs:         formats.ItemDescTablePath,
+	unidentifiedNames: formats.UnidentifiedItemNameTablePath,
+	unidentifiedDescs: formats.UnidentifiedItemDescTablePath,
+	cardPrefixes:      formats.CardPrefixTablePath,
+	slotCounts:        formats.ItemSlotCountTablePath,
+}
+
+var (
+	language string
+	messages formats.MsgStringTable
+	items    [itemTableCount]formats.IDTable
+	postfix  map[int]bool // Cards named after the item
+)
+
+// Load loads the tables of a language through read, then applies the
+// override file at overridePath ("" for none): messages by index, written
+// as the item tables are ("1#Server closed#"). Missing tables are left
+// empty; only a missing or unreadable override file is an error, and the
+// tables are loaded regardless.
+func Load(code string, read Reader, overridePath string) error {
+	language = code
+	messages = loadMessages(read, formats.MsgStringTablePath)
+	for i, path := range itemTablePaths {
+		items[i] = loadIDTable(read, path)
+	}
+	postfix = loadIDList(read, formats.CardPostfixListPath)
+	if code != "" {
+		for i, m := range loadMessages(read, langPath(code, formats.MsgStringTablePath)) {
+			setMessage(i, m)
+		}
+		for i, path := range itemTablePaths {
+			for id, text := range loadIDTable(read, langPath(code, path)) {
+				items[i][id] = text
+			}
+		}
+	}
+
+	if overridePath == "" {
+		return nil
+	}
+	data, err := os.ReadFile(overridePath)
+	if err != nil {
+		return fmt.Errorf("read string overrides: %w", err)
+	}
+	for id, text := range formats.ParseIDTable(data) {
+		setMessage(id, text)
+	}
+	return nil
+}
+
+// langPath returns where a language keeps its translation of a table.
+func langPath(code, path string) string {
+	return "data/lang/" + code + "/" + strings.TrimPrefix(path, "data/")
+}
+
+// loadMessages reads a message table, empty if it's missing.
+func loadMessages(read Reader, path string) formats.MsgStringTable {
+	if read == nil {
+		return nil
+	}
+	data, err := read(path)
+	if err != nil {
+		return nil
+	}
+	return formats.ParseMsgStringTable(data)
+}
+
+// loadIDTable reads an ID table, empty if it's missing.
+func loadIDTable(read Reader, path string) formats.IDTable {
+	table := formats.IDTable{}
+	if read == nil {
+		return table
+	}
+	if data, err := read(path); err == nil {
+		table = formats.ParseIDTable(data)
+	}
+	return table
+}
+
+// loadIDList reads an ID list, empty if it's missing.
+func loadIDList(read Reader, path string) map[int]bool {
+	if read == nil {
+		return map[int]bool{}
+	}
+	data, err := read(path)
+	if err != nil {
+		return map[int]bool{}
+	}
+	return formats.ParseIDList(data)
+}
+
+// setMessage replaces message index, unless text is empty.
+func setMessage(index int, text string) {
+	if index < 0 || text == "" {
+		return
+	}
+	if index >= len(messages) {
+		messages = append(messages, make(formats.MsgStringTable, index+1-len(messages))...)
+	}
+	messages[index] = text
+}
+
+// Current returns the code of the loaded language.
+func Current() string {
+	return language
+}
+
+// Get returns message index of msgstringtable.txt, or "" if there's none.
+func Get(index int) string {
+	s, _ := messages.Get(index)
+	return s
+}
+
+// Or returns message index, or fallback if there's none.
+func Or(index int, fallback string) string {
+	if s, ok := messages.Get(index); ok {
+		return s
+	}
+	return fallback
+}
+
+// ItemName returns an item's display name as identified or not, or false
+// if the tables don't have it. The tables write spaces as underscores.
+func ItemName(id int, identified bool) (string, bool) {
+	table := items[itemNames]
+	if !identified {
+		table = items[unidentifiedNames]
+	}
+	name, ok := table.Get(id)
+	return strings.ReplaceAll(name, "_", " "), ok
+}
+
+// ItemDescription returns an item's description as identified or not, or
+// false if the tables don't have it.
+func ItemDescription(id int, identified bool) (string, bool) {
+	if !identified {
+		return items[unidentifiedDescs].Get(id)
+	}
+	return items[itemDescs].Get(id)
+}
+
+// CardAffix returns the text a card gives the name of an item it's slotted
+// in, and whether it goes after the name rather than before, or false if
+// the tables don't have the card.
+func CardAffix(id int) (text string, postfixed, ok bool) {
+	text, ok = items[cardPrefixes].Get(id)
+	if !ok || text == "" {
+		return "", false, false
+	}
+	return strings.ReplaceAll(text, "_", " "), postfix[id], true
+}
+
+// SlotCount returns the card slots of an item, or 0 if the table doesn't
+// have it.
+func SlotCount(id int) int {
+	text, _ := items[slotCounts].Get(id)
+	n, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// LanguageName returns the name of a language, or its code if it isn't
+// one the settings offer.
+func LanguageName(code string) string {
+	for _, l := range Languages {
+		if l.Code == code {
+			return l.Name
+		}
+	}
+	return code
+}
+
+// NextLanguage returns the language after code in Languages, wrapping
+// around. A code not in the list steps to the first.
+func NextLanguage(code string) string {
+	for i, l := range Languages {
+		if l.Code == code {
+			return Languages[(i+1)%len(Languages)].Code
+		}
+	}
+	return Languages[0].Code
+}
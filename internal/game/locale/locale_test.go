@@ -0,0 +1,116 @@
+package locale
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	files := map[string]string{
+		"data/msgstringtable.txt":                     "서버 종료#\n중복 접속#\n아이템#\n",
+		"data/lang/en/msgstringtable.txt":             "Server closed#\n#\n",
+		"data/idnum2itemdisplaynametable.txt":         "501#빨간포션#\n502#주황포션#\n",
+		"data/lang/en/idnum2itemdisplaynametable.txt": "501#Red_Potion#\n",
+		"data/num2itemdisplaynametable.txt":           "501#Potion#\n",
+		"data/idnum2itemdesctable.txt":                "501#\nHeals a little.\n#\n",
+		"data/cardprefixnametable.txt":                "4001#Lucky#\n4035#of_Hydra#\n",
+		"data/cardpostfixnametable.txt":               "4035#\n",
+		"data/itemslotcounttable.txt":                 "1116#\n3#\n",
+	}
+	read := func(path string) ([]byte, error) {
+		if s, ok := files[path]; ok {
+			return []byte(s), nil
+		}
+		return nil, errors.New("not found")
+	}
+	overrides := filepath.Join(t.TempDir(), "overrides.txt")
+	if err := os.WriteFile(overrides, []byte("// Fixes\n2#Item#\n5#Added#\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Load("en", read, overrides); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if Current() != "en" {
+		t.Errorf("Current = %q", Current())
+	}
+	// Translated, untranslated (an empty line keeps the GRF's), overridden
+	// and added by the override file
+	for i, want := range []string{"Server closed", "중복 접속", "Item", "", "", "Added"} {
+		if got := Get(i); got != want {
+			t.Errorf("Get(%d) = %q, want %q", i, got, want)
+		}
+	}
+	if got := Or(4, "fallback"); got != "fallback" {
+		t.Errorf("Or(4) = %q", got)
+	}
+
+	items := []struct {
+		id         int
+		identified bool
+		want       string
+		ok         bool
+	}{
+		{501, true, "Red Potion", true},
+		{502, true, "주황포션", true},
+		{501, false, "Potion", true},
+		{503, true, "", false},
+	}
+	for _, it := range items {
+		if got, ok := ItemName(it.id, it.identified); got != it.want || ok != it.ok {
+			t.Errorf("ItemName(%d, %v) = %q, %v", it.id, it.identified, got, ok)
+		}
+	}
+	if desc, ok := ItemDescription(501, true); !ok || desc != "Heals a little." {
+		t.Errorf("ItemDescription(501) = %q, %v", desc, ok)
+	}
+	if _, ok := ItemDescription(501, false); ok {
+		t.Error("unidentified description from the identified table")
+	}
+
+	affixes := []struct {
+		id        int
+		want      string
+		postfixed bool
+		ok        bool
+	}{
+		{4001, "Lucky", false, true},
+		{4035, "of Hydra", true, true},
+		{4002, "", false, false},
+	}
+	for _, a := range affixes {
+		if got, postfixed, ok := CardAffix(a.id); got != a.want || postfixed != a.postfixed || ok != a.ok {
+			t.Errorf("CardAffix(%d) = %q, %v, %v", a.id, got, postfixed, ok)
+		}
+	}
+	if got := SlotCount(1116); got != 3 {
+		t.Errorf("SlotCount(1116) = %d, want 3", got)
+	}
+	if got := SlotCount(501); got != 0 {
+		t.Errorf("SlotCount(501) = %d, want 0", got)
+	}
+
+	// A missing override file is reported; the tables still load
+	if err := Load("", read, filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("missing override file not reported")
+	}
+	if got := Get(0); got != "서버 종료" {
+		t.Errorf("untranslated Get(0) = %q", got)
+	}
+}
+
+func TestNextLanguage(t *testing.T) {
+	for i, l := range Languages {
+		if got, want := NextLanguage(l.Code), Languages[(i+1)%len(Languages)].Code; got != want {
+			t.Errorf("NextLanguage(%s) = %s, want %s", l.Code, got, want)
+		}
+	}
+	if got := NextLanguage("xx"); got != Languages[0].Code {
+		t.Errorf("NextLanguage(xx) = %s", got)
+	}
+	if got := LanguageName("xx"); got != "xx" {
+		t.Errorf("LanguageName(xx) = %s", got)
+	}
+}
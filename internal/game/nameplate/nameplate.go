@@ -0,0 +1,170 @@
+// Package nameplate decides which unit names show over the scene and keeps
+// them from piling up on each other.
+package nameplate
+
+import "sort"
+
+// Mode is when a unit type's names show.
+type Mode uint8
+
+const (
+	ModeAlways Mode = iota // Always shown
+	ModeHover              // Shown while the mouse is over the unit
+	ModeNever              // Never shown
+)
+
+// modeNames are the config spellings of the modes.
+var modeNames = [...]string{
+	ModeAlways: "always",
+	ModeHover:  "hover",
+	ModeNever:  "never",
+}
+
+// ParseMode returns the mode named s ("always", "hover" or "never"), or
+// ModeAlways for an unknown name.
+func ParseMode(s string) Mode {
+	for m, name := range modeNames {
+		if name == s {
+			return Mode(m)
+		}
+	}
+	return ModeAlways
+}
+
+// String returns the config name of the mode.
+func (m Mode) String() string {
+	if int(m) < len(modeNames) {
+		return modeNames[m]
+	}
+	return modeNames[ModeAlways]
+}
+
+// Label returns the mode's settings label.
+func (m Mode) Label() string {
+	switch m {
+	case ModeHover:
+		return "Hover"
+	case ModeNever:
+		return "Never"
+	default:
+		return "Always"
+	}
+}
+
+// Next returns the mode after m, cycling always, hover, never.
+func (m Mode) Next() Mode {
+	return (m + 1) % Mode(len(modeNames))
+}
+
+// Kind is the type of unit a nameplate belongs to; each has its own rule.
+type Kind uint8
+
+const (
+	KindSelf    Kind = iota // The local player
+	KindPlayer              // Other players
+	KindParty               // Party members of the local player
+	KindNPC                 // NPCs
+	KindMonster             // Monsters
+)
+
+// Rules are the nameplate display settings.
+type Rules struct {
+	Self     Mode
+	Players  Mode
+	Party    Mode
+	NPCs     Mode
+	Monsters Mode
+
+	Guild   bool // Show guild name and position lines under player names
+	PartyHP bool // Show party members' HP bars
+
+	// MaxDistance hides names of units farther from the player than this
+	// (world units, 0 = no limit).
+	MaxDistance float32
+}
+
+// Mode returns the mode for a kind of unit.
+func (r *Rules) Mode(k Kind) Mode {
+	switch k {
+	case KindSelf:
+		return r.Self
+	case KindPlayer:
+		return r.Players
+	case KindParty:
+		return r.Party
+	case KindNPC:
+		return r.NPCs
+	case KindMonster:
+		return r.Monsters
+	default:
+		return ModeNever
+	}
+}
+
+// Shows reports whether a unit of kind k shows its name, given whether the
+// mouse is over it.
+func (r *Rules) Shows(k Kind, hovered bool) bool {
+	switch r.Mode(k) {
+	case ModeAlways:
+		return true
+	case ModeHover:
+		return hovered
+	default:
+		return false
+	}
+}
+
+// Label is a nameplate's box on screen.
+type Label struct {
+	X, Y, W, H float32 // Top-left corner and size
+	Distance   float32 // From the player, world units
+	Pinned     bool    // The player's own or the hovered unit's: never hidden
+}
+
+func (l *Label) overlaps(o *Label) bool {
+	return l.X < o.X+o.W && o.X < l.X+l.W && l.Y < o.Y+o.H && o.Y < l.Y+l.H
+}
+
+// Layout decides which labels to draw and returns whether each is shown.
+// Labels farther than maxDistance (0 = no limit) are hidden. The rest are
+// placed pinned first, then nearest first, hiding any that would overlap
+// one already placed, so a crowd shows the names closest to the player.
+func Layout(labels []Label, maxDistance float32) []bool {
+	order := make([]int, len(labels))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		la, lb := &labels[order[a]], &labels[order[b]]
+		if la.Pinned != lb.Pinned {
+			return la.Pinned
+		}
+		return la.Distance < lb.Distance
+	})
+
+	shown := make([]bool, len(labels))
+	placed := make([]int, 0, len(labels))
+	for _, i := range order {
+		l := &labels[i]
+		if !l.Pinned {
+			if maxDistance > 0 && l.Distance > maxDistance {
+				continue
+			}
+			if overlapsAny(l, labels, placed) {
+				continue
+			}
+		}
+		shown[i] = true
+		placed = append(placed, i)
+	}
+	return shown
+}
+
+func overlapsAny(l *Label, labels []Label, placed []int) bool {
+	for _, j := range placed {
+		if l.overlaps(&labels[j]) {
+			return true
+		}
+	}
+	return false
+}
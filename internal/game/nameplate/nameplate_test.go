@@ -0,0 +1,120 @@
+package nameplate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMode(t *testing.T) {
+	tests := []struct {
+		name string
+		want Mode
+	}{
+		{"always", ModeAlways},
+		{"hover", ModeHover},
+		{"never", ModeNever},
+		{"", ModeAlways},
+		{"sometimes", ModeAlways},
+	}
+	for _, tt := range tests {
+		if got := ParseMode(tt.name); got != tt.want {
+			t.Errorf("ParseMode(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	for m := ModeAlways; m <= ModeNever; m++ {
+		if got := ParseMode(m.String()); got != m {
+			t.Errorf("ParseMode(%q) = %v, want %v", m.String(), got, m)
+		}
+	}
+}
+
+func TestModeNext(t *testing.T) {
+	m := ModeAlways
+	want := []Mode{ModeHover, ModeNever, ModeAlways}
+	for _, w := range want {
+		m = m.Next()
+		if m != w {
+			t.Fatalf("Next = %v, want %v", m, w)
+		}
+	}
+}
+
+func TestRulesShows(t *testing.T) {
+	r := Rules{Self: ModeAlways, Players: ModeHover, Party: ModeAlways, NPCs: ModeNever, Monsters: ModeHover}
+	tests := []struct {
+		kind    Kind
+		hovered bool
+		want    bool
+	}{
+		{KindSelf, false, true},
+		{KindPlayer, false, false},
+		{KindPlayer, true, true},
+		{KindParty, false, true},
+		{KindNPC, true, false},
+		{KindMonster, false, false},
+		{KindMonster, true, true},
+	}
+	for _, tt := range tests {
+		if got := r.Shows(tt.kind, tt.hovered); got != tt.want {
+			t.Errorf("Shows(%d, %v) = %v, want %v", tt.kind, tt.hovered, got, tt.want)
+		}
+	}
+}
+
+func TestLayout(t *testing.T) {
+	tests := []struct {
+		name        string
+		labels      []Label
+		maxDistance float32
+		want        []bool
+	}{
+		{
+			"apart",
+			[]Label{{X: 0, Y: 0, W: 50, H: 10, Distance: 10}, {X: 60, Y: 0, W: 50, H: 10, Distance: 20}},
+			0,
+			[]bool{true, true},
+		},
+		{
+			"nearest wins an overlap",
+			[]Label{{X: 0, Y: 0, W: 50, H: 10, Distance: 30}, {X: 20, Y: 5, W: 50, H: 10, Distance: 10}},
+			0,
+			[]bool{false, true},
+		},
+		{
+			"touching edges don't overlap",
+			[]Label{{X: 0, Y: 0, W: 50, H: 10, Distance: 10}, {X: 0, Y: 10, W: 50, H: 10, Distance: 20}},
+			0,
+			[]bool{true, true},
+		},
+		{
+			"too far",
+			[]Label{{X: 0, Y: 0, W: 50, H: 10, Distance: 10}, {X: 100, Y: 0, W: 50, H: 10, Distance: 80}},
+			75,
+			[]bool{true, false},
+		},
+		{
+			"pinned beats nearer and ignores distance",
+			[]Label{{X: 0, Y: 0, W: 50, H: 10, Distance: 5}, {X: 10, Y: 0, W: 50, H: 10, Distance: 100, Pinned: true}},
+			75,
+			[]bool{false, true},
+		},
+		{
+			"hidden labels don't block",
+			[]Label{
+				{X: 0, Y: 0, W: 50, H: 10, Distance: 10},
+				{X: 40, Y: 0, W: 50, H: 10, Distance: 20},
+				{X: 80, Y: 0, W: 50, H: 10, Distance: 30},
+			},
+			0,
+			[]bool{true, false, true},
+		},
+		{"none", nil, 0, []bool{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Layout(tt.labels, tt.maxDistance); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Layout = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package game
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/Faultbox/midgard-ro/internal/config"
+	"github.com/Faultbox/midgard-ro/internal/engine/ui2d"
+	"github.com/Faultbox/midgard-ro/internal/game/nameplate"
+	"github.com/Faultbox/midgard-ro/internal/game/settings"
+	"github.com/Faultbox/midgard-ro/internal/game/states"
+	"github.com/Faultbox/midgard-ro/internal/game/ui"
+	"github.com/Faultbox/midgard-ro/internal/logger"
+)
+
+// nameplateCellSize is the size of a map cell in world units, for the
+// nameplate distance limit given in cells.
+const nameplateCellSize = 5.0
+
+// Nameplate line colors under the name.
+var (
+	nameplateGuildColor    = ui2d.Color{R: 0.6, G: 0.85, B: 1, A: 1}
+	nameplatePositionColor = ui2d.Color{R: 0.75, G: 0.75, B: 0.8, A: 1}
+)
+
+// nameplateLabels name the unit types in the settings window, in the order
+// of nameplateModes.
+var nameplateLabels = [...]string{"Own name", "Players", "Party", "NPCs", "Monsters"}
+
+// nameplateConfig returns the nameplate rules in effect: the account's
+// while a character is in game, the config file's otherwise.
+func (g *Game) nameplateConfig() *config.NameplatesConfig {
+	if g.settings.store != nil {
+		return &g.settings.nameplates
+	}
+	return &g.config.Nameplates
+}
+
+// persistNameplates saves changed nameplate rules where nameplateConfig
+// took them from.
+func (g *Game) persistNameplates() {
+	store := g.settings.store
+	if store == nil {
+		g.persistConfig()
+		return
+	}
+	if err := store.SetAccount(settings.KeyNameplates, &g.settings.nameplates); err != nil {
+		logger.Warn("failed to store nameplate rules", zap.Error(err))
+		return
+	}
+	if err := store.Save(); err != nil {
+		logger.Warn("failed to save account settings", zap.Error(err))
+	}
+}
+
+// nameplateModes returns the config fields holding each unit type's mode,
+// in settings order.
+func (g *Game) nameplateModes() []*string {
+	n := g.nameplateConfig()
+	return []*string{&n.Self, &n.Players, &n.Party, &n.NPCs, &n.Monsters}
+}
+
+// nameplateRules returns the configured nameplate rules.
+func (g *Game) nameplateRules() nameplate.Rules {
+	n := g.nameplateConfig()
+	return nameplate.Rules{
+		Self:        nameplate.ParseMode(n.Self),
+		Players:     nameplate.ParseMode(n.Players),
+		Party:       nameplate.ParseMode(n.Party),
+		NPCs:        nameplate.ParseMode(n.NPCs),
+		Monsters:    nameplate.ParseMode(n.Monsters),
+		Guild:       n.Guild,
+		PartyHP:     n.PartyHP,
+		MaxDistance: float32(n.MaxDistance) * nameplateCellSize,
+	}
+}
+
+// nameplateSettings lists each unit type's mode for the settings window.
+func (g *Game) nameplateSettings() []ui.NameplateSetting {
+	modes := g.nameplateModes()
+	settings := make([]ui.NameplateSetting, len(modes))
+	for i, m := range modes {
+		settings[i] = ui.NameplateSetting{Label: nameplateLabels[i], Mode: nameplate.ParseMode(*m).Label()}
+	}
+	return settings
+}
+
+// CycleNameplateMode steps a unit type's nameplate mode (by settings index)
+// through always, hover and never, and persists it to the config file.
+func (g *Game) CycleNameplateMode(index int) {
+	modes := g.nameplateModes()
+	if index < 0 || index >= len(modes) {
+		return
+	}
+	*modes[index] = nameplate.ParseMode(*modes[index]).Next().String()
+	g.persistNameplates()
+}
+
+// SetNameplateGuild shows or hides guild lines under player names and
+// persists the choice to the config file.
+func (g *Game) SetNameplateGuild(show bool) {
+	n := g.nameplateConfig()
+	if show == n.Guild {
+		return
+	}
+	n.Guild = show
+	g.persistNameplates()
+}
+
+// SetNameplatePartyHP shows or hides party members' HP bars and persists
+// the choice to the config file.
+func (g *Game) SetNameplatePartyHP(show bool) {
+	n := g.nameplateConfig()
+	if show == n.PartyHP {
+		return
+	}
+	n.PartyHP = show
+	g.persistNameplates()
+}
+
+// nameplates builds the nameplates in view, dropping far ones and the ones
+// that would overlap a nearer unit's.
+func (g *Game) nameplates(state *states.InGameState, width, height float32) []ui.Nameplate {
+	rules := g.nameplateRules()
+	found := state.GetNameplates(width, height, rules)
+	if len(found) == 0 {
+		return nil
+	}
+	palette := ui2d.PaletteByName(g.config.Accessibility.Palette)
+	plates := make([]ui.Nameplate, 0, len(found))
+	labels := make([]nameplate.Label, 0, len(found))
+	for i := range found {
+		f := &found[i]
+		name := f.Name
+		if name == "" && f.Kind == nameplate.KindSelf {
+			name = g.charName
+		}
+		p := ui.Nameplate{X: f.ScreenX, Y: f.ScreenY + ui.NameplateGap, HP: f.HP}
+		if name != "" {
+			c := f.NameColor
+			p.Lines = append(p.Lines, ui.NameplateLine{Text: name, Color: ui2d.Color{R: c[0], G: c[1], B: c[2], A: c[3]}})
+		}
+		if f.Guild != "" {
+			p.Lines = append(p.Lines, ui.NameplateLine{Text: f.Guild, Color: nameplateGuildColor})
+		}
+		if f.Position != "" {
+			p.Lines = append(p.Lines, ui.NameplateLine{Text: f.Position, Color: nameplatePositionColor})
+		}
+		if p.HP >= 0 {
+			p.HP = min(p.HP, 1)
+			p.HPColor = palette.HPColor(p.HP)
+		}
+		if len(p.Lines) == 0 && p.HP < 0 {
+			continue
+		}
+		w, h := p.Size()
+		plates = append(plates, p)
+		labels = append(labels, nameplate.Label{
+			X:        p.X - w/2,
+			Y:        p.Y,
+			W:        w,
+			H:        h,
+			Distance: f.Distance,
+			Pinned:   f.Kind == nameplate.KindSelf || f.Hovered,
+		})
+	}
+
+	shown := nameplate.Layout(labels, rules.MaxDistance)
+	kept := plates[:0]
+	for i := range plates {
+		if shown[i] {
+			kept = append(kept, plates[i])
+		}
+	}
+	return kept
+}
@@ -0,0 +1,50 @@
+package game
+
+import (
+	"github.com/Faultbox/midgard-ro/internal/engine/clock"
+	"github.com/Faultbox/midgard-ro/internal/game/states"
+	"github.com/Faultbox/midgard-ro/internal/game/ui"
+)
+
+// noticeKinds maps the in-game state's notice kinds to the toasts'.
+var noticeKinds = map[states.NoticeKind]ui.NotifyKind{
+	states.NoticeItem:   ui.NotifyItem,
+	states.NoticeFriend: ui.NotifyFriend,
+	states.NoticeGuild:  ui.NotifyGuild,
+	states.NoticeQuest:  ui.NotifyQuest,
+}
+
+// renderNotifications queues the in-game state's new notices and draws the
+// toasts. Clicking one opens the window it relates to and dismisses it.
+func (g *Game) renderNotifications(viewportWidth, viewportHeight float32) {
+	now := clock.Now()
+	g.notifications.SetDoNotDisturb(g.stateManager.DoNotDisturb)
+	if state, ok := g.stateManager.Current().(*states.InGameState); ok {
+		for _, n := range state.TakeNotices() {
+			g.notifications.Push(ui.Notification{
+				Kind:  noticeKinds[n.Kind],
+				Text:  n.Text,
+				Key:   n.Key,
+				Count: n.Count,
+			}, now)
+		}
+	}
+
+	shown := g.notifications.Visible(now)
+	if len(shown) == 0 {
+		return
+	}
+	g.uiBackend.RenderNotifications(ui.NotificationsState{
+		Items: shown,
+		OnClick: func(n ui.Notification) {
+			switch n.Kind {
+			case ui.NotifyItem:
+				g.showInventory = true
+			case ui.NotifyScreenshot:
+				g.screenshots.galleryOpen = true
+				g.screenshots.galleryDirty = true
+			}
+			g.notifications.Dismiss(n.ID)
+		},
+	}, viewportWidth, viewportHeight)
+}
@@ -0,0 +1,101 @@
+package game
+
+import (
+	"fmt"
+	"image"
+	_ "image/png" // Color grading tables are PNG strips
+	"os"
+
+	"go.uber.org/zap"
+
+	"github.com/Faultbox/midgard-ro/internal/config"
+	"github.com/Faultbox/midgard-ro/internal/engine/postfx"
+	"github.com/Faultbox/midgard-ro/internal/engine/texfilter"
+	"github.com/Faultbox/midgard-ro/internal/logger"
+)
+
+// postFXSettings returns the configured post-processing of the 3D view.
+func postFXSettings(cfg config.GraphicsConfig) postfx.Settings {
+	return postfx.Settings{
+		Gamma:      cfg.Gamma,
+		Brightness: cfg.Brightness,
+		Grading:    cfg.ColorGrading,
+		FXAA:       cfg.FXAA,
+	}.Clamped()
+}
+
+// loadColorLUT reads the configured color grading table, or returns nil
+// when there's none. A table that can't be read turns grading off.
+func loadColorLUT(path string) image.Image {
+	if path == "" {
+		return nil
+	}
+	img, err := decodeImageFile(path)
+	if err != nil {
+		logger.Warn("color grading table unavailable", zap.String("path", path), zap.Error(err))
+		return nil
+	}
+	return img
+}
+
+// textureFilter returns the configured texture filtering, or the default
+// when the config names a mode that doesn't exist.
+func textureFilter(cfg config.GraphicsConfig) texfilter.Filter {
+	f, err := texfilter.Parse(cfg.TextureFilter, cfg.Anisotropy)
+	if err != nil {
+		logger.Warn("invalid texture filtering, using the default", zap.Error(err))
+		return texfilter.Default
+	}
+	return f
+}
+
+func decodeImageFile(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", path, err)
+	}
+	return img, nil
+}
+
+// setPostFX applies a change to the post-processing and persists it to
+// the config file.
+func (g *Game) setPostFX(change func(*config.GraphicsConfig)) {
+	before := postFXSettings(g.config.Graphics)
+	change(&g.config.Graphics)
+	after := postFXSettings(g.config.Graphics)
+	if after == before {
+		return
+	}
+	g.stateManager.SetPostFX(after)
+	g.persistConfig()
+}
+
+// SetGamma changes the gamma correction of the 3D view.
+func (g *Game) SetGamma(gamma float32) {
+	g.setPostFX(func(c *config.GraphicsConfig) {
+		c.Gamma = max(postfx.MinGamma, min(gamma, postfx.MaxGamma))
+	})
+}
+
+// SetBrightness changes the brightness of the 3D view.
+func (g *Game) SetBrightness(brightness float32) {
+	g.setPostFX(func(c *config.GraphicsConfig) {
+		c.Brightness = max(postfx.MinBrightness, min(brightness, postfx.MaxBrightness))
+	})
+}
+
+// SetFXAA turns anti-aliasing of the 3D view on or off.
+func (g *Game) SetFXAA(enabled bool) {
+	g.setPostFX(func(c *config.GraphicsConfig) { c.FXAA = enabled })
+}
+
+// SetColorGrading turns color grading through the configured table on or
+// off.
+func (g *Game) SetColorGrading(enabled bool) {
+	g.setPostFX(func(c *config.GraphicsConfig) { c.ColorGrading = enabled })
+}
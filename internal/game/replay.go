@@ -0,0 +1,244 @@
+package game
+
+import (
+	"net"
+	"strings"
+	"time"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+	"go.uber.org/zap"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/clock"
+	"github.com/Faultbox/midgard-ro/internal/engine/replay"
+	"github.com/Faultbox/midgard-ro/internal/engine/rng"
+	"github.com/Faultbox/midgard-ro/internal/game/states"
+	"github.com/Faultbox/midgard-ro/internal/logger"
+)
+
+// replayStep is the fixed time step of recordings.
+const replayStep = time.Second / 60
+
+// replaySession is the recording or the replay of the session, if one is
+// running. Both run the game on a fixed time step; a recording holds the
+// frame rate to it, so the game runs at its normal speed on machines
+// fast enough.
+type replaySession struct {
+	recorder  *replay.Recorder
+	player    *replay.Player
+	lastFrame time.Time // Recorder: wall time the last frame started
+
+	primed bool               // Player: the first frame's input is queued
+	keys   map[imgui.Key]bool // Player: keys held down by the replay
+	input  replay.Input       // Player: input last queued
+}
+
+// StartRecording records the session into path, for playback with
+// --replay. Call it before Run.
+func (g *Game) StartRecording(path string) error {
+	header := replay.Header{
+		Start:  time.Now(),
+		Step:   replayStep,
+		Seed:   uint64(time.Now().UnixNano()),
+		Width:  g.config.Graphics.Width,
+		Height: g.config.Graphics.Height,
+	}
+	rec, err := replay.Create(path, header)
+	if err != nil {
+		return err
+	}
+	g.replay = &replaySession{recorder: rec}
+	g.startFixedStep(header)
+	g.client.SetConnHook(func(_ string, dial func() (net.Conn, error)) (net.Conn, error) {
+		conn, err := dial()
+		if err != nil {
+			return nil, err
+		}
+		return rec.Conn(conn), nil
+	})
+	logger.Info("recording session", zap.String("file", path))
+	return nil
+}
+
+// StartReplay plays a recorded session back instead of connecting to the
+// servers. Open the player before New, to create the window at the
+// recorded size, and call this before Run.
+func (g *Game) StartReplay(p *replay.Player) {
+	g.replay = &replaySession{player: p, keys: make(map[imgui.Key]bool)}
+	g.startFixedStep(p.Header())
+	g.client.SetConnHook(func(string, func() (net.Conn, error)) (net.Conn, error) {
+		return p.Conn(), nil
+	})
+	logger.Info("replaying session; input to the window would disturb it",
+		zap.Time("recorded", p.Header().Start))
+}
+
+// startFixedStep makes the clock and random source those of a recording.
+func (g *Game) startFixedStep(h replay.Header) {
+	clock.SetFixedStep(h.Start, h.Step)
+	rng.Seed(h.Seed)
+}
+
+// beginReplayFrame advances the recording or replay at the start of a
+// frame. It returns false for a frame the game must skip: while the
+// replay queues its first input, and once it has ended.
+func (g *Game) beginReplayFrame() bool {
+	r := g.replay
+	if r == nil {
+		return true
+	}
+	clock.Advance()
+	if r.recorder != nil {
+		if wait := replayStep - time.Since(r.lastFrame); wait > 0 {
+			time.Sleep(wait)
+		}
+		r.lastFrame = time.Now()
+		r.recorder.SetInput(g.captureInput())
+		return true
+	}
+
+	// The input of a frame must be queued before it starts, in the frame
+	// before: the first frame only queues
+	if !r.primed {
+		r.primed = true
+		g.queueReplayInput()
+		return false
+	}
+	if !r.player.Advance() {
+		logger.Info("replay finished", zap.Int("frames", r.player.Frame()))
+		g.replay = nil
+		clock.SetRealTime()
+		g.quit()
+		return false
+	}
+	g.queueReplayInput()
+	return true
+}
+
+// endReplayFrame writes the frame to the recording.
+func (g *Game) endReplayFrame() {
+	if g.replay == nil || g.replay.recorder == nil {
+		return
+	}
+	if err := g.replay.recorder.EndFrame(); err != nil {
+		logger.Error("recording stopped", zap.Error(err))
+		g.stopReplay()
+	}
+}
+
+// stopReplay ends the recording or replay.
+func (g *Game) stopReplay() {
+	r := g.replay
+	if r == nil {
+		return
+	}
+	g.replay = nil
+	g.client.SetConnHook(nil)
+	clock.SetRealTime()
+	if r.recorder != nil {
+		if err := r.recorder.Close(); err != nil {
+			logger.Warn("failed to close recording", zap.Error(err))
+		}
+	}
+	if r.player != nil {
+		_ = r.player.Close()
+	}
+}
+
+// captureInput returns this frame's input, for the recording.
+func (g *Game) captureInput() replay.Input {
+	io := imgui.CurrentIO()
+	origin := imgui.MainViewport().Pos()
+	mouse := io.MousePos()
+	in := replay.Input{
+		MouseX: mouse.X - origin.X,
+		MouseY: mouse.Y - origin.Y,
+		WheelX: io.MouseWheelH(),
+		WheelY: io.MouseWheel(),
+	}
+	for i, down := range io.MouseDown() {
+		if down {
+			in.Buttons |= 1 << i
+		}
+	}
+	for key := imgui.KeyNamedKeyBEGIN; key < imgui.KeyReservedForModCtrl; key++ {
+		if imgui.IsKeyDown(key) {
+			in.Keys = append(in.Keys, int32(key))
+		}
+	}
+	for _, m := range replayMods {
+		if m.down(io) {
+			in.Mods |= m.bit
+		}
+	}
+
+	var text strings.Builder
+	for _, ch := range io.InputQueueCharacters().Slice() {
+		text.WriteRune(rune(ch))
+	}
+	in.Text = text.String()
+	// Keep account names and passwords out of recordings: their length
+	// is enough to replay the login screen
+	if _, login := g.stateManager.Current().(*states.LoginState); login {
+		in.Text = strings.Repeat("*", len([]rune(in.Text)))
+	}
+	return in
+}
+
+// replayMods maps the modifier keys to their bits in replay.Input.
+var replayMods = []struct {
+	bit  uint8
+	key  imgui.Key
+	down func(*imgui.IO) bool
+}{
+	{replay.ModCtrl, imgui.ModCtrl, (*imgui.IO).KeyCtrl},
+	{replay.ModShift, imgui.ModShift, (*imgui.IO).KeyShift},
+	{replay.ModAlt, imgui.ModAlt, (*imgui.IO).KeyAlt},
+	{replay.ModSuper, imgui.ModSuper, (*imgui.IO).KeySuper},
+}
+
+// queueReplayInput queues the input of the next replayed frame as events,
+// the changes from the input queued before it.
+func (g *Game) queueReplayInput() {
+	r := g.replay
+	in, ok := r.player.Input()
+	if !ok {
+		return
+	}
+	io := imgui.CurrentIO()
+	origin := imgui.MainViewport().Pos()
+	io.AddMousePosEvent(origin.X+in.MouseX, origin.Y+in.MouseY)
+	for i := range 5 {
+		if down := in.Buttons&(1<<i) != 0; down != (r.input.Buttons&(1<<i) != 0) {
+			io.AddMouseButtonEvent(int32(i), down)
+		}
+	}
+	if in.WheelX != 0 || in.WheelY != 0 {
+		io.AddMouseWheelEvent(in.WheelX, in.WheelY)
+	}
+	for _, m := range replayMods {
+		if down := in.Mods&m.bit != 0; down != (r.input.Mods&m.bit != 0) {
+			io.AddKeyEvent(m.key, down)
+		}
+	}
+
+	held := make(map[imgui.Key]bool, len(in.Keys))
+	for _, k := range in.Keys {
+		held[imgui.Key(k)] = true
+	}
+	for key := range r.keys {
+		if !held[key] {
+			io.AddKeyEvent(key, false)
+		}
+	}
+	for key := range held {
+		if !r.keys[key] {
+			io.AddKeyEvent(key, true)
+		}
+	}
+	r.keys = held
+
+	if in.Text != "" {
+		io.AddInputCharactersUTF8(in.Text)
+	}
+	r.input = in
+}
@@ -0,0 +1,238 @@
+package game
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"go.uber.org/zap"
+
+	"github.com/Faultbox/midgard-ro/internal/config"
+	"github.com/Faultbox/midgard-ro/internal/engine/clock"
+	"github.com/Faultbox/midgard-ro/internal/engine/debug"
+	"github.com/Faultbox/midgard-ro/internal/game/states"
+	"github.com/Faultbox/midgard-ro/internal/game/ui"
+	"github.com/Faultbox/midgard-ro/internal/logger"
+)
+
+const (
+	defaultScreenshotDir = "data/Screenshots"
+	screenshotFlashTTL   = 250 * time.Millisecond
+	galleryMaxEntries    = 12
+	galleryThumbW        = 160
+	galleryThumbH        = 120
+)
+
+// screenshotManager holds capture settings, notification state and the gallery.
+type screenshotManager struct {
+	dir    string
+	hideUI bool // Capture the scene without the HUD
+
+	requested bool
+	flashTime time.Time
+
+	// Gallery window (F11). Thumbnails are GL textures owned by the manager.
+	galleryOpen  bool
+	galleryDirty bool
+	gallery      []ui.ScreenshotThumb
+}
+
+// newScreenshotManager creates a screenshot manager from the game config.
+func newScreenshotManager(cfg *config.Config) *screenshotManager {
+	dir := cfg.Game.ScreenshotDir
+	if dir == "" {
+		dir = defaultScreenshotDir
+	}
+	return &screenshotManager{
+		dir:          dir,
+		hideUI:       cfg.Game.ScreenshotHideUI,
+		galleryDirty: true,
+	}
+}
+
+// hidingUI returns true if the current frame should be rendered without the HUD
+// because a UI-less capture is pending.
+func (sm *screenshotManager) hidingUI() bool {
+	return sm.requested && sm.hideUI
+}
+
+// flashAlpha returns the opacity of the post-capture flash, fading to zero.
+func (sm *screenshotManager) flashAlpha() float32 {
+	elapsed := time.Since(sm.flashTime)
+	if sm.flashTime.IsZero() || elapsed >= screenshotFlashTTL {
+		return 0
+	}
+	return 0.6 * (1 - float32(elapsed)/float32(screenshotFlashTTL))
+}
+
+// releaseGallery deletes all thumbnail textures.
+func (sm *screenshotManager) releaseGallery() {
+	for _, thumb := range sm.gallery {
+		if thumb.TextureID != 0 {
+			gl.DeleteTextures(1, &thumb.TextureID)
+		}
+	}
+	sm.gallery = nil
+}
+
+// refreshGallery reloads the most recent screenshots and uploads their thumbnails.
+func (sm *screenshotManager) refreshGallery() {
+	sm.releaseGallery()
+	sm.galleryDirty = false
+
+	entries, err := debug.ListScreenshots(sm.dir, galleryMaxEntries)
+	if err != nil {
+		logger.Warn("failed to list screenshots", zap.Error(err))
+		return
+	}
+
+	for _, entry := range entries {
+		thumb := ui.ScreenshotThumb{Name: entry.Name}
+		if img, err := loadPNG(entry.Path); err != nil {
+			logger.Warn("failed to load screenshot thumbnail", zap.String("path", entry.Path), zap.Error(err))
+		} else {
+			small := debug.Thumbnail(img, galleryThumbW, galleryThumbH)
+			thumb.Width = small.Bounds().Dx()
+			thumb.Height = small.Bounds().Dy()
+			thumb.TextureID = uploadThumbnail(small)
+		}
+		sm.gallery = append(sm.gallery, thumb)
+	}
+}
+
+// loadPNG decodes a PNG file from disk.
+func loadPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+// uploadThumbnail creates a GL texture from an RGBA image.
+func uploadThumbnail(img *image.RGBA) uint32 {
+	b := img.Bounds()
+	if b.Dx() == 0 || b.Dy() == 0 {
+		return 0
+	}
+
+	var texID uint32
+	gl.GenTextures(1, &texID)
+	gl.BindTexture(gl.TEXTURE_2D, texID)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA, int32(b.Dx()), int32(b.Dy()), 0,
+		gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(img.Pix))
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+	return texID
+}
+
+// renderScreenshotOverlays draws the capture flash and gallery.
+func (g *Game) renderScreenshotOverlays(viewportWidth, viewportHeight float32) {
+	sm := g.screenshots
+
+	if sm.galleryOpen {
+		if sm.galleryDirty {
+			sm.refreshGallery()
+		}
+		g.uiBackend.RenderScreenshotGallery(ui.ScreenshotGalleryState{
+			Dir:     sm.dir,
+			Entries: sm.gallery,
+			OnClose: func() {
+				sm.galleryOpen = false
+			},
+		}, viewportWidth, viewportHeight)
+	}
+
+	if alpha := sm.flashAlpha(); alpha > 0 && !g.config.Accessibility.ReduceFlashes {
+		g.uiBackend.RenderScreenFlash(alpha, viewportWidth, viewportHeight)
+	}
+}
+
+// ToggleScreenshotGallery opens or closes the screenshot gallery window.
+func (g *Game) ToggleScreenshotGallery() {
+	g.screenshots.galleryOpen = !g.screenshots.galleryOpen
+	if g.screenshots.galleryOpen {
+		g.screenshots.galleryDirty = true
+	}
+}
+
+// screenshotMapName returns the current map name for screenshot filenames.
+func (g *Game) screenshotMapName() string {
+	switch state := g.stateManager.Current().(type) {
+	case *states.InGameState:
+		return state.GetMapName()
+	case *states.LoadingState:
+		return state.GetMapName()
+	}
+	return ""
+}
+
+// captureScreenshot captures the current frame to a PNG file.
+func (g *Game) captureScreenshot() {
+	sm := g.screenshots
+
+	// Get actual viewport size from OpenGL (handles HiDPI correctly)
+	var viewport [4]int32
+	gl.GetIntegerv(gl.VIEWPORT, &viewport[0])
+	width := int(viewport[2])
+	height := int(viewport[3])
+
+	if width <= 0 || height <= 0 {
+		logger.Warn("screenshot failed: invalid viewport")
+		return
+	}
+
+	pixels := make([]byte, width*height*4)
+	gl.ReadPixels(0, 0, int32(width), int32(height), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(pixels))
+
+	// Flip vertically for default framebuffer
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	rowSize := width * 4
+	for y := 0; y < height; y++ {
+		srcRow := (height - 1 - y) * rowSize
+		copy(img.Pix[y*img.Stride:y*img.Stride+rowSize], pixels[srcRow:srcRow+rowSize])
+	}
+
+	// Create screenshot directory if needed
+	if err := os.MkdirAll(sm.dir, 0755); err != nil {
+		logger.Warn("failed to create screenshot dir", zap.Error(err))
+		return
+	}
+
+	filename := debug.ScreenshotName(g.screenshotMapName(), g.charName, time.Now())
+	savePath := filepath.Join(sm.dir, filename)
+	if err := savePNG(savePath, img); err != nil {
+		logger.Warn("failed to save screenshot", zap.Error(err))
+		return
+	}
+
+	// Also save as "latest.png" for easy access
+	_ = savePNG(filepath.Join(sm.dir, "latest.png"), img)
+
+	sm.flashTime = time.Now()
+	g.notifications.Push(ui.Notification{Kind: ui.NotifyScreenshot, Text: "Saved: " + filename}, clock.Now())
+	sm.galleryDirty = true
+	logger.Info("screenshot saved", zap.String("path", savePath))
+}
+
+// savePNG encodes img to path.
+func savePNG(path string, img image.Image) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating file: %w", err)
+	}
+	defer file.Close()
+
+	if err := png.Encode(file, img); err != nil {
+		return fmt.Errorf("encoding PNG: %w", err)
+	}
+	return nil
+}
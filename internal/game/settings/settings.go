@@ -0,0 +1,230 @@
+// Package settings stores client settings that belong to an account or one
+// of its characters rather than to the installation: window layouts,
+// hotkeys, chat tabs and nameplate rules. Each account's settings live in
+// one versioned JSON file under the user config directory.
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Version is the current file format. Older files are upgraded through
+// migrations when opened; newer ones are read but never overwritten.
+const Version = 1
+
+// Section keys. UI subsystems each own one and the type stored under it.
+const (
+	KeyWindows    = "windows"    // Windows, per character
+	KeyHotkeys    = "hotkeys"    // Per character
+	KeyChatTabs   = "chat_tabs"  // Per character
+	KeyNameplates = "nameplates" // config.NameplatesConfig, per account
+)
+
+// WindowLayout is where the player left a window.
+type WindowLayout struct {
+	X float32 `json:"x"`
+	Y float32 `json:"y"`
+}
+
+// Windows maps window IDs to their layout.
+type Windows map[string]WindowLayout
+
+// Section holds one scope's settings by key, left encoded until a
+// subsystem asks for them so unknown keys survive a round trip.
+type Section map[string]json.RawMessage
+
+// file is the on-disk format.
+type file struct {
+	Version    int                `json:"version"`
+	Account    Section            `json:"account,omitempty"`
+	Characters map[string]Section `json:"characters,omitempty"`
+}
+
+// migrations upgrade a file one version at a time: migrations[i] takes a
+// version i+1 document to version i+2. Changing the format means bumping
+// Version and appending a migration, so saved layouts carry over.
+var migrations []func(doc map[string]any) error
+
+// Store is an account's settings file.
+type Store struct {
+	path      string
+	data      file
+	character string // Selected character, "" for account scope only
+	readOnly  bool   // Written by a newer client
+	dirty     bool
+}
+
+// Path returns where an account's settings are stored under dir: one
+// directory per login server, one file per account.
+func Path(dir, server, account string) string {
+	return filepath.Join(dir, "settings", safeName(server), safeName(strings.ToLower(account))+".json")
+}
+
+// safeName replaces characters that aren't safe in a file name.
+func safeName(s string) string {
+	if s == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '.':
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// Open loads the settings file at path; a missing file is an empty store.
+// A file that can't be parsed is moved aside to path+".bak" and an empty
+// store is returned along with the error, so a bad file costs the player
+// their layout once rather than every session.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, data: file{Version: Version}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := decode(data)
+	if err != nil {
+		err = fmt.Errorf("parsing %s: %w", path, err)
+		if renameErr := os.Rename(path, path+".bak"); renameErr != nil {
+			err = errors.Join(err, renameErr)
+		}
+		return s, err
+	}
+	s.data = *f
+	s.readOnly = f.Version > Version
+	return s, nil
+}
+
+// decode parses a settings file, upgrading older versions.
+func decode(data []byte) (*file, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	version := 1 // Hand-written files without a version are the first format
+	if v, ok := doc["version"].(float64); ok && v >= 1 {
+		version = int(v)
+	}
+	for ; version <= len(migrations); version++ {
+		if err := migrations[version-1](doc); err != nil {
+			return nil, fmt.Errorf("migrating from version %d: %w", version, err)
+		}
+	}
+	doc["version"] = version
+
+	upgraded, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var f file
+	if err := json.Unmarshal(upgraded, &f); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// SelectCharacter picks the character whose settings Get and Set use
+// before falling back to the account's. "" selects the account only.
+func (s *Store) SelectCharacter(name string) {
+	s.character = name
+}
+
+// ReadOnly reports whether the file came from a newer client, in which
+// case changes are kept for the session but not saved.
+func (s *Store) ReadOnly() bool {
+	return s.readOnly
+}
+
+// Get decodes the setting under key into v, preferring the selected
+// character's copy over the account's. It reports whether one was found.
+func (s *Store) Get(key string, v any) bool {
+	if s.character != "" && decodeInto(s.data.Characters[s.character], key, v) {
+		return true
+	}
+	return s.GetAccount(key, v)
+}
+
+// GetAccount decodes the account-wide setting under key into v and
+// reports whether it was found.
+func (s *Store) GetAccount(key string, v any) bool {
+	return decodeInto(s.data.Account, key, v)
+}
+
+func decodeInto(sec Section, key string, v any) bool {
+	raw, ok := sec[key]
+	return ok && json.Unmarshal(raw, v) == nil
+}
+
+// Set stores v under key for the selected character, or for the account
+// if none is selected.
+func (s *Store) Set(key string, v any) error {
+	if s.character == "" {
+		return s.SetAccount(key, v)
+	}
+	if s.data.Characters == nil {
+		s.data.Characters = make(map[string]Section)
+	}
+	sec := s.data.Characters[s.character]
+	if sec == nil {
+		sec = make(Section)
+		s.data.Characters[s.character] = sec
+	}
+	return s.set(sec, key, v)
+}
+
+// SetAccount stores v under key for the whole account.
+func (s *Store) SetAccount(key string, v any) error {
+	if s.data.Account == nil {
+		s.data.Account = make(Section)
+	}
+	return s.set(s.data.Account, key, v)
+}
+
+func (s *Store) set(sec Section, key string, v any) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encoding %s: %w", key, err)
+	}
+	if old, ok := sec[key]; ok && string(old) == string(raw) {
+		return nil
+	}
+	sec[key] = raw
+	s.dirty = true
+	return nil
+}
+
+// Save writes the file if anything changed. It writes a temporary file
+// and renames it over the old one, so a crash mid-write can't truncate
+// the player's settings.
+func (s *Store) Save() error {
+	if !s.dirty || s.readOnly {
+		return nil
+	}
+	data, err := json.MarshalIndent(&s.data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding settings: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("creating settings dir: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("writing settings: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("replacing settings: %w", err)
+	}
+	s.dirty = false
+	return nil
+}
@@ -0,0 +1,152 @@
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPath(t *testing.T) {
+	got := Path("/cfg", "127.0.0.1:6900", "Player/One")
+	want := filepath.Join("/cfg", "settings", "127.0.0.1_6900", "player_one.json")
+	if got != want {
+		t.Errorf("Path() = %q, want %q", got, want)
+	}
+}
+
+func TestStoreScopes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "acct.json")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Set(KeyWindows, Windows{"inventory": {X: 10, Y: 20}}); err != nil {
+		t.Fatal(err)
+	}
+	s.SelectCharacter("Alice")
+	if err := s.Set(KeyWindows, Windows{"inventory": {X: 300, Y: 40}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		character string
+		want      float32
+	}{
+		{"Alice", 300},
+		{"Bob", 10}, // Falls back to the account
+		{"", 10},
+	}
+	for _, tt := range tests {
+		s.SelectCharacter(tt.character)
+		var w Windows
+		if !s.Get(KeyWindows, &w) || w["inventory"].X != tt.want {
+			t.Errorf("%q: windows = %v, want inventory at x=%v", tt.character, w, tt.want)
+		}
+	}
+
+	var missing Windows
+	if s.Get(KeyHotkeys, &missing) {
+		t.Errorf("Get() found an unset key")
+	}
+}
+
+func TestStoreSaveOnlyWhenChanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "acct.json")
+	s, _ := Open(path)
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("saving an unchanged store wrote a file")
+	}
+
+	_ = s.SetAccount(KeyNameplates, map[string]string{"monsters": "hover"})
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	_ = s.SetAccount(KeyNameplates, map[string]string{"monsters": "hover"})
+	if s.dirty {
+		t.Errorf("setting the same value marked the store changed")
+	}
+}
+
+func TestOpenCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "acct.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Open(path)
+	if err == nil || s == nil {
+		t.Fatalf("Open() = %v, %v, want an empty store and an error", s, err)
+	}
+	if _, err := os.Stat(path + ".bak"); err != nil {
+		t.Errorf("corrupt file wasn't set aside: %v", err)
+	}
+}
+
+func TestOpenNewerVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "acct.json")
+	data := []byte(`{"version": 99, "account": {"windows": {"chat": {"x": 1, "y": 2}}}, "future": true}`)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.ReadOnly() {
+		t.Errorf("newer file isn't read-only")
+	}
+	var w Windows
+	if !s.Get(KeyWindows, &w) || w["chat"].Y != 2 {
+		t.Errorf("windows = %v, want chat at y=2", w)
+	}
+	_ = s.SetAccount(KeyWindows, Windows{})
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != string(data) {
+		t.Errorf("newer file was overwritten: %s", got)
+	}
+}
+
+func TestMigrations(t *testing.T) {
+	if Version != len(migrations)+1 {
+		t.Fatalf("Version = %d with %d migrations", Version, len(migrations))
+	}
+
+	// A hypothetical version 2 renamed the windows key
+	saved := migrations
+	defer func() { migrations = saved }()
+	migrations = []func(map[string]any) error{
+		func(doc map[string]any) error {
+			if acct, ok := doc["account"].(map[string]any); ok {
+				acct["window_layout"] = acct["windows"]
+				delete(acct, "windows")
+			}
+			return nil
+		},
+	}
+
+	f, err := decode([]byte(`{"version": 1, "account": {"windows": {"chat": {"x": 5, "y": 6}}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Version != 2 {
+		t.Errorf("version = %d, want 2", f.Version)
+	}
+	var w Windows
+	if err := json.Unmarshal(f.Account["window_layout"], &w); err != nil || w["chat"].X != 5 {
+		t.Errorf("migrated windows = %v, %v", w, err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"go.uber.org/zap"
 
+	"github.com/Faultbox/midgard-ro/internal/engine/clock"
 	"github.com/Faultbox/midgard-ro/internal/logger"
 	"github.com/Faultbox/midgard-ro/internal/network"
 	"github.com/Faultbox/midgard-ro/internal/network/packets"
@@ -35,6 +36,7 @@ type CharSelectState struct {
 	ErrorMsg      string
 	StatusMsg     string
 	CharListReady bool
+	pincode       pincodeState
 
 	// Map server info (after selection)
 	MapServerIP   string
@@ -59,17 +61,21 @@ func NewCharSelectState(cfg CharSelectStateConfig, client *network.Client, manag
 
 // Enter is called when entering this state.
 func (s *CharSelectState) Enter() error {
-	s.enterTime = time.Now()
+	s.enterTime = clock.Now()
 	s.ErrorMsg = ""
 	s.IsLoading = true
 	s.CharListReady = false
 	s.Characters = nil
+	s.pincode = pincodeState{}
+	s.manager.charServerHost, s.manager.charServerPort = s.config.CharServerHost, s.config.CharServerPort
 
 	// Register packet handlers
 	s.client.RegisterHandler(packets.HC_ACCEPT_ENTER, s.handleCharListAccept)
 	s.client.RegisterHandler(packets.HC_REFUSE_ENTER, s.handleCharListRefuse)
 	s.client.RegisterHandler(packets.HC_NOTIFY_ZONESVR, s.handleMapServerInfo)
 	s.client.RegisterHandler(packets.HC_NOTIFY_ZONESVR2, s.handleMapServerInfo) // Modern rAthena
+	s.client.RegisterHandler(packets.HC_SECOND_PASSWD_LOGIN, s.handlePincodeLogin)
+	s.client.RegisterHandler(packets.SC_NOTIFY_BAN, s.manager.kickHandler(s.client))
 
 	// Send character server enter request
 	return s.sendCharEnter()
@@ -83,7 +89,7 @@ func (s *CharSelectState) Exit() error {
 // Update is called every frame.
 func (s *CharSelectState) Update(dt float64) error {
 	// Check for timeout
-	if s.IsLoading && time.Since(s.enterTime) > 30*time.Second {
+	if s.IsLoading && clock.Since(s.enterTime) > 30*time.Second {
 		s.ErrorMsg = "Timeout waiting for character list"
 		s.IsLoading = false
 		return nil
@@ -91,6 +97,9 @@ func (s *CharSelectState) Update(dt float64) error {
 
 	// Process network
 	if err := s.client.Process(); err != nil {
+		if s.manager.connectionLost(s.client, err) {
+			return nil
+		}
 		s.ErrorMsg = fmt.Sprintf("Network error: %v", err)
 		s.IsLoading = false
 	}
@@ -196,6 +205,7 @@ func (s *CharSelectState) handleMapServerInfo(data []byte) error {
 	s.MapServerPort = info.Port
 	s.MapName = info.GetMapName()
 	s.CharID = info.CharID
+	s.manager.Character = s.GetSelectedCharacter()
 
 	// Store character ID in client
 	s.client.SetCharID(info.CharID)
@@ -221,6 +231,9 @@ func (s *CharSelectState) SelectCharacter(slotIndex int) error {
 	if slotIndex < 0 || slotIndex >= len(s.Characters) {
 		return fmt.Errorf("invalid slot index: %d", slotIndex)
 	}
+	if !s.CharactersUnlocked() {
+		return fmt.Errorf("PIN code required")
+	}
 
 	s.SelectedSlot = slotIndex
 	s.IsLoading = true
@@ -0,0 +1,196 @@
+package states
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/Faultbox/midgard-ro/internal/logger"
+	"github.com/Faultbox/midgard-ro/internal/network/packets"
+)
+
+// pincodeMode is what the PIN keypad is asking for.
+type pincodeMode int
+
+const (
+	pincodeNone   pincodeMode = iota // Keypad closed
+	pincodeEnter                     // The PIN, to unlock the characters
+	pincodeCreate                    // A first PIN
+	pincodeChange                    // The current PIN and a new one
+)
+
+// pincodeState tracks the character server's PIN code system. Servers
+// without one never send HC_SECOND_PASSWD_LOGIN, leaving it disabled.
+type pincodeState struct {
+	enabled  bool        // The server uses PIN codes
+	unlocked bool        // Characters may be selected
+	hasPin   bool        // The account is known to have a PIN
+	mode     pincodeMode // Keypad shown, pincodeNone when closed
+	asked    pincodeMode // Last PIN sent, to ask again if refused
+	optional bool        // The player opened the keypad and may close it
+	pending  bool        // A PIN was sent and the reply hasn't come
+	seed     uint32
+	message  string
+}
+
+// PincodePrompt is the PIN keypad the character select screen shows.
+type PincodePrompt struct {
+	AskCurrent bool     // Type the current PIN
+	AskNew     bool     // Type a new PIN, twice
+	Keypad     [10]byte // Digit on each button, shuffled by the server
+	Message    string   // Why the PIN is asked again, if it is
+	Optional   bool     // The keypad may be closed without a PIN
+}
+
+// handlePincodeLogin handles HC_SECOND_PASSWD_LOGIN, sent after the
+// character list and in reply to every PIN sent.
+func (s *CharSelectState) handlePincodeLogin(data []byte) error {
+	p, ok := packets.DecodePincodeLogin(data)
+	if !ok {
+		return fmt.Errorf("invalid pincode packet")
+	}
+	logger.Debug("pincode state", zap.Uint16("state", p.State))
+
+	pin := &s.pincode
+	wasPending := pin.pending
+	pin.seed, pin.pending, pin.message = p.Seed, false, ""
+	pin.enabled = p.State != packets.PincodeOK
+	if wasPending {
+		s.StatusMsg = ""
+	}
+	switch p.State {
+	case packets.PincodeOK:
+		pin.unlocked, pin.mode = true, pincodeNone
+	case packets.PincodePassed:
+		pin.unlocked, pin.mode = true, pincodeNone
+		if wasPending {
+			s.StatusMsg = "PIN code accepted."
+			if pin.asked != pincodeEnter {
+				s.StatusMsg = "PIN code saved."
+			}
+			pin.hasPin = true
+		}
+	case packets.PincodeAsk:
+		pin.hasPin = true
+		pin.mode, pin.optional = pincodeEnter, false
+	case packets.PincodeNotSet, packets.PincodeNew:
+		pin.mode, pin.optional = pincodeCreate, pin.unlocked
+	case packets.PincodeExpired:
+		pin.hasPin = true
+		pin.mode, pin.optional = pincodeChange, false
+		pin.message = "Your PIN code has expired. Choose a new one."
+	case packets.PincodeWrong:
+		pin.mode = pin.asked
+		pin.message = "Wrong PIN code."
+	case packets.PincodeIllegal:
+		pin.mode = pin.asked
+		pin.message = "That PIN code is too easy to guess. Choose another."
+	default:
+		logger.Warn("unknown pincode state", zap.Uint16("state", p.State))
+		return nil
+	}
+	if pin.mode == pincodeNone {
+		pin.optional = false
+	}
+	return nil
+}
+
+// Pincode returns the keypad to show, or nil when it's closed.
+func (s *CharSelectState) Pincode() *PincodePrompt {
+	pin := &s.pincode
+	if pin.mode == pincodeNone || pin.pending {
+		return nil
+	}
+	return &PincodePrompt{
+		AskCurrent: pin.mode != pincodeCreate,
+		AskNew:     pin.mode != pincodeEnter,
+		Keypad:     packets.PincodeKeypad(pin.seed),
+		Message:    pin.message,
+		Optional:   pin.optional,
+	}
+}
+
+// CharactersUnlocked reports whether characters may be selected: the
+// server has no PIN codes or the right one was entered.
+func (s *CharSelectState) CharactersUnlocked() bool {
+	return !s.pincode.enabled || s.pincode.unlocked
+}
+
+// PincodeEnabled reports whether the server uses PIN codes, so the player
+// can set up or change theirs.
+func (s *CharSelectState) PincodeEnabled() bool {
+	return s.pincode.enabled && s.pincode.unlocked
+}
+
+// PincodeSet reports whether the account is known to have a PIN code.
+func (s *CharSelectState) PincodeSet() bool {
+	return s.pincode.hasPin
+}
+
+// SubmitPincode sends the PINs typed on the keypad: the current one to
+// unlock or change, the new one to create or change.
+func (s *CharSelectState) SubmitPincode(current, newPin string) error {
+	pin := &s.pincode
+	accountID, _, _, _ := s.client.Session()
+
+	var pkt interface{ Encode() []byte }
+	switch pin.mode {
+	case pincodeEnter:
+		pkt = &packets.PincodeSubmit{
+			PacketID:  packets.CH_SECOND_PASSWD_ACK,
+			AccountID: accountID,
+			Pin:       packets.EncodePincode(pin.seed, current),
+		}
+	case pincodeCreate:
+		pkt = &packets.PincodeSubmit{
+			PacketID:  packets.CH_MAKE_SECOND_PASSWD,
+			AccountID: accountID,
+			Pin:       packets.EncodePincode(pin.seed, newPin),
+		}
+	case pincodeChange:
+		pkt = &packets.PincodeChange{
+			PacketID:  packets.CH_EDIT_SECOND_PASSWD,
+			AccountID: accountID,
+			OldPin:    packets.EncodePincode(pin.seed, current),
+			NewPin:    packets.EncodePincode(pin.seed, newPin),
+		}
+	default:
+		return fmt.Errorf("no PIN code requested")
+	}
+
+	if err := s.client.Send(pkt.Encode()); err != nil {
+		s.ErrorMsg = fmt.Sprintf("Failed to send PIN code: %v", err)
+		return err
+	}
+	pin.asked, pin.pending = pin.mode, true
+	s.StatusMsg = "Checking PIN code..."
+	return nil
+}
+
+// ChangePincode opens the keypad to change the account's PIN code, or
+// asks the server to set one up if the account has none.
+func (s *CharSelectState) ChangePincode() error {
+	pin := &s.pincode
+	if !s.PincodeEnabled() || pin.mode != pincodeNone || pin.pending {
+		return nil
+	}
+	if pin.hasPin {
+		pin.mode, pin.optional, pin.message = pincodeChange, true, ""
+		return nil
+	}
+	// The server answers with PincodeNew if the account has no PIN
+	accountID, _, _, _ := s.client.Session()
+	pkt := &packets.AccountRequest{PacketID: packets.CH_AVAILABLE_SECOND_PASSWD, AccountID: accountID}
+	if err := s.client.Send(pkt.Encode()); err != nil {
+		s.ErrorMsg = fmt.Sprintf("Failed to request PIN code setup: %v", err)
+		return err
+	}
+	return nil
+}
+
+// CancelPincode closes a keypad the player opened.
+func (s *CharSelectState) CancelPincode() {
+	if s.pincode.optional {
+		s.pincode.mode, s.pincode.optional = pincodeNone, false
+	}
+}
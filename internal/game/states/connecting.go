@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/Faultbox/midgard-ro/internal/engine/clock"
 	"github.com/Faultbox/midgard-ro/internal/network"
 )
 
@@ -47,7 +48,7 @@ func NewConnectingState(cfg ConnectingStateConfig, client *network.Client, manag
 
 // Enter is called when entering this state.
 func (s *ConnectingState) Enter() error {
-	s.startTime = time.Now()
+	s.startTime = clock.Now()
 	s.connected = false
 	s.ErrorMsg = ""
 
@@ -71,8 +72,12 @@ func (s *ConnectingState) Exit() error {
 // Update is called every frame.
 func (s *ConnectingState) Update(dt float64) error {
 	// Check timeout
-	if time.Since(s.startTime) > s.config.Timeout {
-		s.ErrorMsg = "Connection timed out"
+	if clock.Since(s.startTime) > s.config.Timeout {
+		msg := s.ErrorMsg
+		if msg == "" {
+			msg = "Connection timed out."
+		}
+		s.manager.disconnect(s.client, "Connection failed", msg)
 		return nil
 	}
 
@@ -0,0 +1,147 @@
+package states
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/Faultbox/midgard-ro/internal/logger"
+	"github.com/Faultbox/midgard-ro/internal/network"
+	"github.com/Faultbox/midgard-ro/internal/network/packets"
+)
+
+// msgDisconnected is the msgstringtable.txt index of the generic
+// "disconnected from server" message.
+const msgDisconnected = 3
+
+// kickMessage is the text for an SC_NOTIFY_BAN reason: a msgstringtable.txt
+// index (-1 for none) and the English text used without one.
+type kickMessage struct {
+	index    int
+	fallback string
+}
+
+// kickMessages maps SC_NOTIFY_BAN reasons to text, with the table indexes
+// the original client uses.
+var kickMessages = map[uint8]kickMessage{
+	packets.BanServerClosed:    {4, "The server has closed."},
+	packets.BanDuplicateLogin:  {5, "Someone has logged in with this account."},
+	packets.BanTimeout:         {241, "The connection timed out or fell out of sync."},
+	packets.BanServerFull:      {264, "The server is full. Please try again later."},
+	packets.BanUnderaged:       {305, "This account is too young for this server."},
+	packets.BanStillOnline:     {441, "The server still has your last session. Please try again in a moment."},
+	packets.BanIPLimit:         {529, "Too many connections from your IP address."},
+	packets.BanPaidTimeExpired: {530, "Your paid play time has run out."},
+	packets.BanKicked:          {-1, "You were disconnected by a Game Master or a server shutdown."},
+}
+
+// KickText returns the message for an SC_NOTIFY_BAN reason.
+func (m *Manager) KickText(code uint8) string {
+	msg, ok := kickMessages[code]
+	if !ok {
+		return m.MsgString(msgDisconnected, "Disconnected from the server.")
+	}
+	return m.MsgString(msg.index, msg.fallback)
+}
+
+// DisconnectedState is the error screen shown when the session ends: a
+// kick, a ban, the server shutting down or the connection dropping. It
+// explains why and offers a way back to the login screen.
+type DisconnectedState struct {
+	client  *network.Client
+	manager *Manager
+
+	Title   string
+	Message string
+}
+
+// NewDisconnectedState creates the error screen for a session that ended.
+func NewDisconnectedState(title, message string, client *network.Client, manager *Manager) *DisconnectedState {
+	return &DisconnectedState{
+		client:  client,
+		manager: manager,
+		Title:   title,
+		Message: message,
+	}
+}
+
+// Enter closes the connection if the server hasn't already.
+func (s *DisconnectedState) Enter() error {
+	s.client.Disconnect()
+	return nil
+}
+
+// Exit is called when leaving this state.
+func (s *DisconnectedState) Exit() error {
+	return nil
+}
+
+// Update is called every frame.
+func (s *DisconnectedState) Update(dt float64) error {
+	return nil
+}
+
+// Render is called every frame to draw the state.
+func (s *DisconnectedState) Render() error {
+	return nil
+}
+
+// HandleInput processes input events.
+func (s *DisconnectedState) HandleInput(event interface{}) error {
+	return nil
+}
+
+// GetTitle returns the error screen's title.
+func (s *DisconnectedState) GetTitle() string {
+	return s.Title
+}
+
+// GetMessage returns why the session ended.
+func (s *DisconnectedState) GetMessage() string {
+	return s.Message
+}
+
+// ReturnToLogin goes back to the login screen.
+func (s *DisconnectedState) ReturnToLogin() {
+	s.manager.Change(NewLoginState(s.manager.LoginConfig, s.client, s.manager))
+}
+
+// disconnect switches to the error screen. The first reason wins: a kick
+// is followed by the server closing the connection, which isn't news.
+func (m *Manager) disconnect(client *network.Client, title, message string) {
+	if _, ok := m.next.(*DisconnectedState); ok {
+		return
+	}
+	logger.Info("session ended", zap.String("title", title), zap.String("message", message))
+	m.Change(NewDisconnectedState(title, message, client, m))
+}
+
+// kickHandler returns the SC_NOTIFY_BAN handler for states connected to
+// the char or map server.
+func (m *Manager) kickHandler(client *network.Client) network.PacketHandler {
+	return func(data []byte) error {
+		code, _ := packets.DecodeNotifyBan(data)
+		m.disconnect(client, "Disconnected", m.KickText(code))
+		return nil
+	}
+}
+
+// connectionLost shows the error screen if a Process error closed the
+// connection, and reports whether it did. Errors that leave the
+// connection open, such as a failed packet handler, are left to the
+// caller.
+func (m *Manager) connectionLost(client *network.Client, err error) bool {
+	return m.connectionLostBecause(client, err, "")
+}
+
+// connectionLostBecause is connectionLost with a message explaining why
+// the connection dropped, or the generic one if message is empty.
+func (m *Manager) connectionLostBecause(client *network.Client, err error, message string) bool {
+	if client.IsConnected() {
+		return false
+	}
+	logger.Warn("connection lost", zap.Error(err))
+	if message == "" {
+		message = m.MsgString(msgDisconnected, "Disconnected from the server.")
+	}
+	m.disconnect(client, "Disconnected", message)
+	return true
+}
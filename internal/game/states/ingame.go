@@ -9,10 +9,15 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/Faultbox/midgard-ro/internal/engine/camera"
+	"github.com/Faultbox/midgard-ro/internal/engine/clock"
 	"github.com/Faultbox/midgard-ro/internal/engine/picking"
 	"github.com/Faultbox/midgard-ro/internal/engine/playerrender"
 	"github.com/Faultbox/midgard-ro/internal/engine/scene"
+	"github.com/Faultbox/midgard-ro/internal/engine/sprite"
+	"github.com/Faultbox/midgard-ro/internal/game/chat"
+	"github.com/Faultbox/midgard-ro/internal/game/commands"
 	"github.com/Faultbox/midgard-ro/internal/game/entity"
+	"github.com/Faultbox/midgard-ro/internal/game/world"
 	"github.com/Faultbox/midgard-ro/internal/logger"
 	"github.com/Faultbox/midgard-ro/internal/network"
 	"github.com/Faultbox/midgard-ro/internal/network/packets"
@@ -20,6 +25,18 @@ import (
 	"github.com/Faultbox/midgard-ro/pkg/math"
 )
 
+const (
+	// Level-up effect sprites under data/sprite/이팩트/ and their sound
+	baseLevelUpEffect = "levelup"
+	jobLevelUpEffect  = "joblvup"
+	levelUpSound      = "data/wav/effect/levelup.wav"
+
+	// maxChatMessages bounds the chat log kept for the HUD.
+	maxChatMessages = 100
+	// maxRecentNames bounds the names remembered for chat completion.
+	maxRecentNames = 30
+)
+
 // InGameStateConfig contains configuration for the in-game state.
 type InGameStateConfig struct {
 	MapName   string
@@ -28,6 +45,11 @@ type InGameStateConfig struct {
 	SpawnDir  uint8
 	CharID    uint32
 	TexLoader func(string) ([]byte, error)
+
+	// After a map change, the scene kept from the map left with the map
+	// preloaded from mapFiles, nil if preloading failed
+	Scene    *scene.Scene
+	mapFiles *mapFiles
 }
 
 // InGameState handles the main gameplay state.
@@ -38,13 +60,112 @@ type InGameState struct {
 
 	// Rendering
 	scene        *scene.Scene
+	sceneKept    bool // Handed to the loading state of a map change
 	camera       *camera.ThirdPersonCamera
-	gat          *formats.GAT // Walkability + minimap shape
+	gat          *formats.GAT       // Walkability + minimap shape
+	walk         *world.Walkability // GAT plus server-driven cell overrides
+	ground       *world.Ground      // Ground levels, two where walkable areas overlap
 	playerRender *playerrender.Renderer
 
 	// Entities
 	entityManager *entity.Manager
 	player        *entity.Character
+	progress      entity.Progress // Levels and experience from status updates
+
+	// Chat log and its tabs
+	chatLog     *chat.Log
+	commands    *commands.Dispatcher
+	chatDraft   string      // Prefilled into the chat input by TakeChatDraft
+	recentNames *chat.Names // Whisper and invite targets, for completion
+
+	// Player context menu
+	playerMenu      *PlayerMenu
+	blockedWhispers map[string]bool
+
+	// Inventory
+	inventory  *entity.Inventory
+	dropPrompt *DropPrompt // Open quantity prompt for a stackable drop
+	zeny       int64
+	weight     entity.Weight
+	itemRings  map[uint32]scene.DecalID // Loot rings under ground items
+
+	// Other players' shops: title boards by vendor account ID, and the
+	// shop being browsed
+	vendingBoards map[uint32]string
+	vendingShop   *VendingShop
+
+	// Chat rooms and NPC waiting rooms in view, by owner ID
+	chatRooms map[uint32]packets.ChatRoom
+
+	// Units in view: loaded sprites by GRF path (nil if missing), the
+	// composited sprite of each unit, and the player's own pet
+	spriteAssets map[string]*spriteAsset
+	// missingSprites maps sprites found missing to the fallback shown
+	// instead ("" for none)
+	missingSprites map[string]string
+	unitSprites    map[uint32]*unitSprite
+	petID          uint32
+
+	// How closely each unit's animation is kept up this frame
+	animLOD   sprite.AnimLOD
+	unitLOD   map[uint32]sprite.AnimLevel
+	animStats sprite.AnimStats
+
+	// Combat: hits waiting for their attack frame, when attack animations
+	// end by unit, and the damage numbers shown
+	combat        entity.CombatQueue
+	attackEnds    map[uint32]time.Time
+	damageNumbers []entity.DamageNumber
+
+	// Attack and skill presses waiting for the player's current action
+	input *entity.InputBuffer
+
+	// Time since the player last touched the controls, and whether it has
+	// marked them away
+	idle *entity.Idle
+	away bool
+
+	// Unit under the mouse, whose name shows in nameplate hover mode
+	hoverID uint32
+
+	// Cell marker under the mouse showing where a click walks to
+	walkIndicator walkIndicator
+
+	// The current map's rules and the player's PVP rank on it
+	mapProperty packets.MapProperty
+	pvpRank     packets.PVPRank
+
+	// Yes/no requests from the server, oldest (shown) first
+	requests []*RequestDialog
+
+	// NPC illustration shown during a dialog
+	cutin cutinState
+	// dialogZoom is the camera zoom toward the NPC talked to, 0 if none
+	dialogZoom camera.EffectID
+	// The NPC dialog open, nil if none, and the lines moved past
+	dialog            *npcDialog
+	dialogHistory     []DialogLine
+	dialogAutoAdvance bool // The advance key is held
+
+	// Server announcements scrolling across the top of the screen
+	banners bannerQueue
+
+	// Events for the notification toasts, taken by TakeNotices
+	notices []Notice
+
+	// Chat bubbles over the heads of units that spoke
+	bubbles chat.Bubbles
+
+	// The player's buffs, counting down between status changes
+	buffs entity.Buffs
+
+	// Position sync: the last walk the server confirmed, and drift
+	// detection between it and the predicted position
+	serverWalk       world.ServerWalk
+	desync           *world.DesyncDetector
+	lastDesyncSample time.Time
+	lastDesync       *world.DesyncEvent
+	desyncCount      int
 
 	// Map info
 	MapName string
@@ -56,11 +177,16 @@ type InGameState struct {
 	moveInputZ float32 // -1 to 1
 
 	// Network timing
-	lastMoveTick      uint32
-	moveTickRate      time.Duration
-	lastKeepAlive     time.Time
-	keepAliveInterval time.Duration
-	enterTime         time.Time // Used as the local epoch for ClientTick
+	lastMoveTick  uint32
+	moveTickRate  time.Duration
+	heartbeat     *network.Heartbeat // Paces CZ_REQUEST_TIME and times the replies
+	serverStalled bool               // Keep-alive replies stopped; warned in chat
+	versionWarned bool               // Server packets kept failing to parse; warned in chat
+	enterTime     time.Time          // Used as the local epoch for ClientTick
+	clock         world.Clock        // Server tick, synced from ZC_NOTIFY_TIME
+	dayCycle      world.DayCycle     // Night and day, from EFST_SKE and the configured cycle
+	pendingNight  *bool              // EFST_SKE received before the clock synced
+	charSelectAt  time.Time          // When a return to character select was asked; zero when none is pending
 
 	// State
 	ErrorMsg   string
@@ -72,15 +198,32 @@ type InGameState struct {
 // NewInGameState creates a new in-game state.
 func NewInGameState(cfg InGameStateConfig, client *network.Client, manager *Manager) *InGameState {
 	return &InGameState{
-		config:            cfg,
-		client:            client,
-		manager:           manager,
-		entityManager:     entity.NewManager(),
-		MapName:           cfg.MapName,
-		TileX:             cfg.SpawnX,
-		TileY:             cfg.SpawnY,
-		moveTickRate:      100 * time.Millisecond, // Send move requests every 100ms max
-		keepAliveInterval: 10 * time.Second,       // rAthena map server times out around 30s of silence
+		config:          cfg,
+		client:          client,
+		manager:         manager,
+		entityManager:   entity.NewManager(),
+		chatLog:         chat.NewLog(maxChatMessages),
+		recentNames:     chat.NewNames(maxRecentNames),
+		blockedWhispers: make(map[string]bool),
+		inventory:       entity.NewInventory(),
+		vendingBoards:   make(map[uint32]string),
+		chatRooms:       make(map[uint32]packets.ChatRoom),
+		itemRings:       make(map[uint32]scene.DecalID),
+		spriteAssets:    make(map[string]*spriteAsset),
+		missingSprites:  make(map[string]string),
+		unitSprites:     make(map[uint32]*unitSprite),
+		animLOD:         sprite.DefaultAnimLOD(),
+		unitLOD:         make(map[uint32]sprite.AnimLevel),
+		attackEnds:      make(map[uint32]time.Time),
+		input:           entity.NewInputBuffer(manager.InputBufferDepth, inputInterval),
+		idle:            entity.NewIdle(manager.AwayAfter, clock.Now()),
+		desync:          world.NewDesyncDetector(),
+		MapName:         cfg.MapName,
+		TileX:           cfg.SpawnX,
+		TileY:           cfg.SpawnY,
+		moveTickRate:    100 * time.Millisecond, // Send move requests every 100ms max
+		heartbeat:       network.NewHeartbeat(keepAliveInterval, keepAliveTimeout),
+		dayCycle:        world.DayCycle{Day: manager.DayLength, Night: manager.NightLength},
 	}
 }
 
@@ -94,13 +237,31 @@ func (s *InGameState) Enter() error {
 	s.ErrorMsg = ""
 	s.StatusMsg = fmt.Sprintf("Loading %s...", s.MapName)
 
-	// Create scene
-	var err error
-	s.scene, err = scene.New(scene.DefaultConfig())
-	if err != nil {
-		logger.Error("failed to create scene", zap.Error(err))
-		s.ErrorMsg = fmt.Sprintf("Failed to create scene: %v", err)
-		return err
+	// Create scene, unless one comes from the map left
+	if s.config.Scene != nil {
+		s.scene = s.config.Scene
+	} else {
+		var err error
+		s.scene, err = scene.New(scene.DefaultConfig())
+		if err != nil {
+			logger.Error("failed to create scene", zap.Error(err))
+			s.ErrorMsg = fmt.Sprintf("Failed to create scene: %v", err)
+			return err
+		}
+		if s.manager.ColorLUT != nil {
+			if err := s.scene.SetColorLUT(s.manager.ColorLUT); err != nil {
+				logger.Warn("color grading unavailable", zap.Error(err))
+			}
+		}
+	}
+
+	// Entity sprite textures go back to the scene's pool with the entity
+	s.entityManager.OnRelease = func(e *entity.Entity) {
+		if e.Texture != 0 && s.scene != nil {
+			s.scene.EntityTextures().Release(e.Texture)
+		}
+		delete(s.unitSprites, e.ID)
+		delete(s.attackEnds, e.ID)
 	}
 
 	// Load map data from GRF
@@ -120,13 +281,12 @@ func (s *InGameState) Enter() error {
 	worldZ := float32(s.config.SpawnY) * tileSize
 
 	// Get terrain height at spawn position
-	var worldY float32
-	if s.scene != nil && s.MapLoaded {
-		worldY = s.scene.GetTerrainHeight(worldX, worldZ)
-	}
+	worldY := s.spawnHeight(worldX, worldZ)
 
 	s.player = entity.NewCharacter(worldX, worldY, worldZ)
 	s.player.Direction = int(s.config.SpawnDir)
+	s.player.MoveSpeed = playerWalkSpeed
+	s.resetServerWalk(s.config.SpawnX, s.config.SpawnY)
 
 	logger.Debug("created player character",
 		zap.Float32("worldX", worldX),
@@ -138,12 +298,19 @@ func (s *InGameState) Enter() error {
 	playerEntity.Position.X = worldX
 	playerEntity.Position.Y = worldY
 	playerEntity.Position.Z = worldZ
+	if ch := s.manager.Character; ch != nil && ch.CharID == s.config.CharID {
+		playerEntity.Job = int(ch.Class)
+		playerEntity.Level = int(ch.BaseLevel)
+	}
 	s.entityManager.SetPlayer(playerEntity)
 
 	// Create third-person camera following player (RO-style)
 	s.camera = camera.NewThirdPersonCamera()
 	s.camera.Distance = 145 // RO-style close distance (like grfbrowser PlayMode)
 	s.camera.Yaw = 0
+	if s.SceneReady {
+		s.camera.Collide = s.scene.CameraObstruction
+	}
 
 	// Build the player billboard renderer (procedural texture for now —
 	// real Novice SPR/ACT composites land in a follow-up PR).
@@ -157,64 +324,41 @@ func (s *InGameState) Enter() error {
 
 	// Mark entry time — used as the local epoch for ClientTick and as the
 	// gate for the keep-alive ticker (only run after we're actually in-game).
-	s.enterTime = time.Now()
-	s.lastKeepAlive = s.enterTime
+	s.enterTime = clock.Now()
 
-	// Register packet handlers
+	// Register packet handlers and chat commands
 	s.registerPacketHandlers()
+	s.registerCommands()
 
 	return nil
 }
 
-// loadMap loads the map data from GRF archives.
+// loadMap loads the map data from GRF archives, or shows the map the
+// loading state preloaded.
 func (s *InGameState) loadMap() error {
-	if s.manager.TexLoader == nil {
-		return fmt.Errorf("no texture loader available")
-	}
-
 	// Get base map name (remove .gat extension)
 	baseName := strings.TrimSuffix(s.MapName, ".gat")
 
-	// Load GAT (walkability + minimap shape).  Non-fatal — log and continue.
-	gatPath := "data\\" + baseName + ".gat"
-	if gatData, gatErr := s.manager.TexLoader(gatPath); gatErr == nil {
-		if gat, parseErr := formats.ParseGAT(gatData); parseErr == nil {
-			s.gat = gat
-		} else {
-			logger.Warn("failed to parse GAT", zap.Error(parseErr))
-		}
-	} else {
-		logger.Warn("failed to load GAT", zap.Error(gatErr))
-	}
-
-	// Load GND (terrain)
-	gndPath := "data\\" + baseName + ".gnd"
-	gndData, err := s.manager.TexLoader(gndPath)
-	if err != nil {
-		return fmt.Errorf("loading GND: %w", err)
-	}
-	gnd, err := formats.ParseGND(gndData)
-	if err != nil {
-		return fmt.Errorf("parsing GND: %w", err)
-	}
-
-	// Load RSW (map resources)
-	rswPath := "data\\" + baseName + ".rsw"
-	rswData, err := s.manager.TexLoader(rswPath)
-	var rsw *formats.RSW
-	if err == nil {
-		rsw, err = formats.ParseRSW(rswData)
+	files := s.config.mapFiles
+	preloaded := files != nil && s.scene.ShowPreloaded(baseName)
+	if !preloaded {
+		// A map preloaded is another one's, from a map change overtaken
+		s.scene.DropPreloaded()
+		var err error
+		files, err = readMapFiles(s.manager.TexLoader, baseName)
+		s.setGAT(files.gat)
 		if err != nil {
-			logger.Warn("failed to parse RSW", zap.Error(err))
+			return err
+		}
+		if err := s.scene.LoadMap(files.gnd, files.rsw, s.manager.TexLoader); err != nil {
+			return fmt.Errorf("loading map into scene: %w", err)
 		}
 	} else {
-		logger.Warn("failed to load RSW", zap.Error(err))
+		s.setGAT(files.gat)
 	}
+	s.ground = world.NewGround(s.gat, s.scene.GetTerrainHeight)
 
-	// Load map into scene
-	if err := s.scene.LoadMap(gnd, rsw, s.manager.TexLoader); err != nil {
-		return fmt.Errorf("loading map into scene: %w", err)
-	}
+	s.startAmbience(files.rsw)
 
 	logger.Info("map loaded successfully",
 		zap.String("map", baseName),
@@ -224,14 +368,71 @@ func (s *InGameState) loadMap() error {
 	return nil
 }
 
+// setGAT sets the map's walkability, nil if its GAT didn't load.
+func (s *InGameState) setGAT(gat *formats.GAT) {
+	s.gat = gat
+	if gat != nil {
+		s.walk = world.NewWalkability(gat)
+	}
+}
+
+// startAmbience plays the map's RSW sound objects as looping ambient
+// sounds, placed the way the scene places models.
+func (s *InGameState) startAmbience(rsw *formats.RSW) {
+	if s.manager.SetAmbience == nil {
+		return
+	}
+	var sounds []AmbientSound
+	if rsw != nil {
+		for _, src := range rsw.GetSounds() {
+			sounds = append(sounds, AmbientSound{
+				Path:   "data/wav/" + src.File,
+				X:      src.Position[0] + s.scene.MapWidth/2,
+				Y:      -src.Position[1],
+				Z:      src.Position[2] + s.scene.MapHeight/2,
+				Volume: src.Volume,
+				Range:  src.Range,
+				Cycle:  src.Cycle,
+			})
+		}
+	}
+	s.manager.SetAmbience(sounds)
+}
+
 // Exit is called when leaving this state.
 func (s *InGameState) Exit() error {
+	s.playerMenu = nil
+	s.dropPrompt = nil
+	s.vendingShop = nil
+	s.requests = nil
+	s.cutin = cutinState{}
+	s.dialogZoom = 0
+	s.dialog = nil
+	s.dialogHistory = nil
+	s.dialogAutoAdvance = false
+	s.banners = bannerQueue{}
+	s.bubbles.Clear()
+	s.buffs.Clear()
+	s.combat.Clear()
+	s.damageNumbers = nil
+	s.hoverID = 0
+	s.walkIndicator = walkIndicator{}
+	s.mapProperty = packets.MapProperty{}
+	s.pvpRank = packets.PVPRank{}
+	clear(s.itemRings)
+	if s.manager.SetAmbience != nil {
+		s.manager.SetAmbience(nil)
+	}
 	if s.playerRender != nil {
 		s.playerRender.Destroy()
 		s.playerRender = nil
 	}
+	// Units go back to the pool, and their textures to the scene's
+	s.entityManager.ClearAll()
 	if s.scene != nil {
-		s.scene.Destroy()
+		if !s.sceneKept {
+			s.scene.Destroy()
+		}
 		s.scene = nil
 	}
 	return nil
@@ -243,15 +444,19 @@ func (s *InGameState) Update(dt float64) error {
 
 	// Process network
 	if err := s.client.Process(); err != nil {
+		if s.manager.connectionLostBecause(s.client, err, s.dropReason(clock.Now())) {
+			return nil
+		}
 		s.ErrorMsg = fmt.Sprintf("Network error: %v", err)
 	}
 
 	// Keep-alive: rAthena's map server drops the session after a few seconds
-	// of silence. Send CZ_REQUEST_TIME at keepAliveInterval cadence.
-	if !s.enterTime.IsZero() && time.Since(s.lastKeepAlive) >= s.keepAliveInterval {
-		s.sendKeepAlive()
-		s.lastKeepAlive = time.Now()
+	// of silence, so CZ_REQUEST_TIME goes out on the heartbeat's cadence.
+	if !s.enterTime.IsZero() {
+		s.updateHeartbeat(clock.Now())
 	}
+	s.warnVersionMismatch()
+	s.checkCharSelectTimeout(clock.Now())
 
 	// Update player movement
 	if s.player != nil {
@@ -263,17 +468,40 @@ func (s *InGameState) Update(dt float64) error {
 			s.player.Update(deltaMs)
 		}
 
+		// Stay on the level walked on where floors overlap
+		s.player.WorldY = s.groundHeight(s.player.WorldX, s.player.WorldZ, s.player.WorldY)
+
 		// Update render interpolation
 		s.player.UpdateRenderPosition(deltaMs)
+		if s.camera != nil {
+			x, y, z := s.player.RenderPosition()
+			s.camera.Effects.Scale = s.manager.CameraEffects
+			s.camera.Update(x, y, z, float32(dt))
+		}
 
 		// Update tile position
 		tileSize := float32(5.0)
 		s.TileX = int(s.player.WorldX / tileSize)
 		s.TileY = int(s.player.WorldZ / tileSize)
+		s.checkDesync(clock.Now())
 	}
 
-	// Update all entities
+	// Update entities within the budget, ranked by distance to the player
+	if pe := s.entityManager.Player(); pe != nil && s.player != nil {
+		pe.SetPosition(s.player.Position())
+		pe.Direction = uint8(s.player.Direction)
+	}
 	s.entityManager.Update(dt)
+	s.updateUnits(dt)
+	s.updateCombat(dt)
+	s.updateInput()
+	s.updateIdle(clock.Now())
+	s.syncVendingBoards()
+	s.syncChatRoomBoards()
+	s.updateRequests(dt)
+	s.updateDialog(dt)
+	s.bubbles.Prune(clock.Now())
+	s.warnExpiringBuffs(clock.Now())
 
 	return nil
 }
@@ -289,11 +517,26 @@ func (s *InGameState) Render() error {
 
 	// Use the extras hook so the player billboard composites into the
 	// scene framebuffer (after world rendering, before unbind).
-	s.scene.RenderWithThirdPersonExtras(s.camera, x, y, z, func(viewProj math.Mat4) {
+	view := s.camera.ViewMatrix(x, y, z)
+	s.updateAuras()
+	s.updateDaylight()
+	s.updateUnitLOD()
+	s.scene.PostFX = s.manager.PostFX
+	drawPlayer := func(viewProj math.Mat4) {
+		s.renderGroundItems(viewProj, view)
+		s.renderUnits(viewProj, view)
 		if s.playerRender != nil {
+			s.playerRender.Sink = s.spriteSink(x, z)
+			if pe := s.entityManager.Player(); pe != nil {
+				s.playerRender.Tint = pe.Tint()
+			}
 			s.playerRender.Render(viewProj, s.player, s.camera.PosX, s.camera.PosZ)
 		}
-	})
+	}
+	s.scene.Render(s.camera.At(x, y, z), drawPlayer)
+
+	// Secondary debug camera, after the main pass so it sees this frame's shadow map
+	s.scene.RenderPiP(x, y, z, drawPlayer)
 	return nil
 }
 
@@ -345,17 +588,25 @@ func (s *InGameState) registerPacketHandlers() {
 	s.client.RegisterHandler(packets.ZC_NOTIFY_MOVEENTRY, s.handleEntityMove)
 	s.client.RegisterHandler(packets.ZC_NPCACK_MAPMOVE, s.handleMapChange)
 	s.client.RegisterHandler(packets.ZC_NOTIFY_PLAYERMOVE, s.handlePlayerMove)
-}
-
-// sendKeepAlive sends CZ_REQUEST_TIME so the map server doesn't time us out.
-func (s *InGameState) sendKeepAlive() {
-	pkt := &packets.TickSend{
-		PacketID:   packets.CZ_REQUEST_TIME,
-		ClientTick: uint32(time.Since(s.enterTime).Milliseconds()),
-	}
-	if err := s.client.Send(pkt.Encode()); err != nil {
-		logger.Warn("keep-alive send failed", zap.Error(err))
-	}
+	s.client.RegisterHandler(packets.ZC_CHANGE_CELLTYPE, s.handleChangeCellType)
+	s.client.RegisterHandler(packets.ZC_PAR_CHANGE, s.handleParChange)
+	s.client.RegisterHandler(packets.ZC_LONGPAR_CHANGE, s.handleLongParChange)
+	s.client.RegisterHandler(packets.ZC_LONGPAR_CHANGE2, s.handleLongParChange2)
+	s.client.RegisterHandler(packets.ZC_NOTIFY_TIME, s.handleNotifyTime)
+	s.client.RegisterHandler(packets.ZC_USER_COUNT, s.handleUserCount)
+	s.registerUnitHandlers()
+	s.registerCombatHandlers()
+	s.registerInventoryHandlers()
+	s.registerVendingHandlers()
+	s.registerChatRoomHandlers()
+	s.registerNoticeHandlers()
+	s.registerRequestHandlers()
+	s.registerNameplateHandlers()
+	s.registerSessionHandlers()
+	s.registerPVPHandlers()
+	s.registerDaylightHandlers()
+	s.registerNPCHandlers()
+	s.registerChatHandlers()
 }
 
 // handlePlayerMove processes ZC_NOTIFY_PLAYERMOVE — server confirms our
@@ -375,6 +626,13 @@ func (s *InGameState) handlePlayerMove(data []byte) error {
 		zap.Int("endX", mv.EndX),
 		zap.Int("endY", mv.EndY))
 
+	s.serverWalk = world.ServerWalk{
+		StartX: mv.StartX, StartY: mv.StartY,
+		EndX: mv.EndX, EndY: mv.EndY,
+		Start:        clock.Now(),
+		CellDuration: world.DefaultCellDuration,
+	}
+
 	if s.player == nil {
 		return nil
 	}
@@ -383,6 +641,159 @@ func (s *InGameState) handlePlayerMove(data []byte) error {
 	return nil
 }
 
+// handleChangeCellType processes ZC_CHANGE_CELLTYPE — a cell's walkability
+// changed at runtime (Ice Wall, doors). The override is applied on top of
+// the GAT so pathfinding sees it immediately.
+func (s *InGameState) handleChangeCellType(data []byte) error {
+	pkt := packets.DecodeChangeCellType(data)
+	if pkt == nil {
+		return fmt.Errorf("invalid ZC_CHANGE_CELLTYPE: %d bytes", len(data))
+	}
+	if s.walk == nil {
+		return nil
+	}
+
+	mapName := strings.TrimSuffix(pkt.GetMapName(), ".gat")
+	if mapName != "" && mapName != strings.TrimSuffix(s.MapName, ".gat") {
+		logger.Debug("ignoring cell change for other map", zap.String("map", mapName))
+		return nil
+	}
+
+	if !s.walk.SetCellType(pkt.X, pkt.Y, formats.GATCellType(pkt.Type)) {
+		logger.Warn("cell change out of bounds", zap.Int("x", pkt.X), zap.Int("y", pkt.Y))
+		return nil
+	}
+	logger.Debug("cell type changed",
+		zap.Int("x", pkt.X),
+		zap.Int("y", pkt.Y),
+		zap.Uint16("type", pkt.Type))
+	return nil
+}
+
+func (s *InGameState) handleParChange(data []byte) error {
+	pkt := packets.DecodeParChange(data)
+	if pkt == nil {
+		return fmt.Errorf("invalid ZC_PAR_CHANGE: %d bytes", len(data))
+	}
+	s.applyParChange(pkt)
+	return nil
+}
+
+func (s *InGameState) handleLongParChange(data []byte) error {
+	pkt := packets.DecodeLongParChange(data)
+	if pkt == nil {
+		return fmt.Errorf("invalid ZC_LONGPAR_CHANGE: %d bytes", len(data))
+	}
+	s.applyParChange(pkt)
+	return nil
+}
+
+func (s *InGameState) handleLongParChange2(data []byte) error {
+	pkt := packets.DecodeLongParChange2(data)
+	if pkt == nil {
+		return fmt.Errorf("invalid ZC_LONGPAR_CHANGE2: %d bytes", len(data))
+	}
+	s.applyParChange(pkt)
+	return nil
+}
+
+// applyParChange updates the player's stats, levels and experience from a
+// status parameter update. Parameters the HUD doesn't show are ignored.
+func (s *InGameState) applyParChange(pkt *packets.ParChange) {
+	p := &s.progress
+	var up entity.LevelUp
+	var leveled bool
+
+	switch pkt.VarID {
+	case packets.VarBaseExp:
+		p.BaseExp = pkt.Value
+	case packets.VarNextBaseExp:
+		p.NextBaseExp = pkt.Value
+	case packets.VarJobExp:
+		p.JobExp = pkt.Value
+	case packets.VarNextJobExp:
+		p.NextJobExp = pkt.Value
+	case packets.VarBaseLevel:
+		up, leveled = p.SetBaseLevel(int(pkt.Value))
+		if pe := s.entityManager.Player(); pe != nil {
+			pe.Level = int(pkt.Value)
+		}
+	case packets.VarJobLevel:
+		up, leveled = p.SetJobLevel(int(pkt.Value))
+	case packets.VarHP, packets.VarMaxHP, packets.VarSP, packets.VarMaxSP:
+		s.applyVitals(pkt.VarID, int(pkt.Value))
+	case packets.VarZeny:
+		s.zeny = pkt.Value
+	case packets.VarWeight:
+		s.setWeight(entity.Weight{Current: int(pkt.Value), Max: s.weight.Max})
+	case packets.VarMaxWeight:
+		s.setWeight(entity.Weight{Current: s.weight.Current, Max: int(pkt.Value)})
+	default:
+		return
+	}
+
+	if leveled {
+		s.onLevelUp(up)
+	}
+}
+
+func (s *InGameState) applyVitals(varID uint16, value int) {
+	pe := s.entityManager.Player()
+	if pe == nil {
+		return
+	}
+	switch varID {
+	case packets.VarHP:
+		switch {
+		case value == 0 && pe.HP > 0:
+			s.onPlayerDeath()
+		case value > 0:
+			s.dropRequests(RequestRestart) // Resurrected
+		}
+		pe.HP = value
+	case packets.VarMaxHP:
+		pe.MaxHP = value
+	case packets.VarSP:
+		pe.SP = value
+	case packets.VarMaxSP:
+		pe.MaxSP = value
+	}
+}
+
+// onLevelUp plays the level-up effect and sound on the player and
+// announces the new level in chat.
+func (s *InGameState) onLevelUp(up entity.LevelUp) {
+	effect := baseLevelUpEffect
+	msg := fmt.Sprintf("Base level up! Now level %d.", up.Level)
+	if up.Kind == entity.LevelJob {
+		effect = jobLevelUpEffect
+		msg = fmt.Sprintf("Job level up! Now job level %d.", up.Level)
+	}
+	logger.Info("level up", zap.Uint8("kind", uint8(up.Kind)), zap.Int("level", up.Level))
+	s.addChatMessage(msg)
+
+	if s.player != nil && s.scene != nil && s.manager.TexLoader != nil {
+		x, y, z := s.player.RenderPosition()
+		if !s.scene.SpawnEffect(effect, x, y, z, s.manager.TexLoader) {
+			logger.Debug("level-up effect unavailable", zap.String("effect", effect))
+		}
+	}
+	if s.manager.PlaySound != nil {
+		s.manager.PlaySound(levelUpSound)
+	}
+}
+
+// addChatMessage adds a system notice to the chat log.
+func (s *InGameState) addChatMessage(msg string) {
+	s.addChat(chat.System, msg)
+}
+
+// addChat adds a line on a channel to the chat log, dropping the oldest
+// lines past maxChatMessages.
+func (s *InGameState) addChat(channel chat.Channel, msg string) {
+	s.chatLog.Add(chat.Message{Channel: channel, Text: msg})
+}
+
 func (s *InGameState) handleEntitySpawn(data []byte) error {
 	// Parse entity spawn packet (simplified)
 	// Full implementation would extract entity ID, type, position, etc.
@@ -394,40 +805,78 @@ func (s *InGameState) handleEntityMove(data []byte) error {
 	return nil
 }
 
+// handleMapChange processes ZC_NPCACK_MAPMOVE, sent as the player warps
+// within the map server: through a portal, by teleporting or by an NPC.
+// The scene goes to the loading state, which preloads the next map into it.
 func (s *InGameState) handleMapChange(data []byte) error {
-	// Handle map change request from server
-	// This would trigger a transition to loading state for the new map
+	mv := packets.DecodeMapMove(data)
+	if mv == nil {
+		return fmt.Errorf("invalid ZC_NPCACK_MAPMOVE: %d bytes", len(data))
+	}
+	logger.Info("map change", zap.String("map", mv.MapName), zap.Int("x", mv.X), zap.Int("y", mv.Y))
+
+	// The map left stays shown in the scene while the next one preloads
+	s.manager.Change(NewLoadingState(LoadingStateConfig{
+		MapName:   mv.MapName,
+		SpawnX:    mv.X,
+		SpawnY:    mv.Y,
+		CharID:    s.config.CharID,
+		TexLoader: s.config.TexLoader,
+		Scene:     s.scene,
+	}, s.client, s.manager))
+	s.sceneKept = s.scene != nil
 	return nil
 }
 
 // SetMoveInput sets the movement input from keyboard.
 func (s *InGameState) SetMoveInput(x, z float32) {
+	if x != 0 || z != 0 {
+		s.NoteActivity()
+	}
 	s.moveInputX = x
 	s.moveInputZ = z
 }
 
 // ScreenToTile maps a screen-space click (in viewport pixels) to a tile
-// coordinate by ray-casting against the y=0 ground plane using the most
-// recent view-projection matrix the scene rendered with.
+// coordinate by ray-casting against the ground levels, or the y=0 plane
+// without a GAT, using the most recent view-projection matrix the scene
+// rendered with. Where floors overlap, the upper one the player sees is
+// picked.
 //
 // Returns ok=false if the scene hasn't rendered yet, or if the ray points
 // away from the ground (e.g. clicking the sky).
 func (s *InGameState) ScreenToTile(screenX, screenY, viewportW, viewportH float32) (tileX, tileY int, ok bool) {
+	worldX, worldZ, ok := s.screenToGround(screenX, screenY, viewportW, viewportH)
+	if !ok {
+		return 0, 0, false
+	}
+	const tileSize = float32(5.0)
+	return int(worldX / tileSize), int(worldZ / tileSize), true
+}
+
+// screenToGround converts screen coordinates to a world position on the
+// ground.
+func (s *InGameState) screenToGround(screenX, screenY, viewportW, viewportH float32) (worldX, worldZ float32, ok bool) {
 	if s.scene == nil || viewportW <= 0 || viewportH <= 0 {
 		return 0, 0, false
 	}
 	invViewProj := s.scene.LastViewProj().Inverse()
 	ray := picking.ScreenToRay(screenX, screenY, viewportW, viewportH, invViewProj)
-	worldX, worldZ, hit := ray.IntersectPlaneY(0)
-	if !hit {
-		return 0, 0, false
+	if s.ground != nil {
+		if x, z, ok := s.ground.RayHit(ray.Origin, ray.Direction); ok {
+			return x, z, true
+		}
 	}
-	const tileSize = float32(5.0)
-	return int(worldX / tileSize), int(worldZ / tileSize), true
+	return ray.IntersectPlaneY(0)
 }
 
-// RequestMove sends a movement request to the server.
+// RequestMove sends a movement request to the server. Cliffs are never
+// walkable, so moves onto them aren't sent.
 func (s *InGameState) RequestMove(tileX, tileY int) error {
+	if s.walk.IsCliff(tileX, tileY) {
+		logger.Debug("move onto a cliff ignored", zap.Int("x", tileX), zap.Int("y", tileY))
+		return nil
+	}
 	pkt := &packets.MoveRequest{
 		PacketID: packets.CZ_REQUEST_MOVE,
 	}
@@ -443,7 +892,7 @@ func (s *InGameState) RequestMove(tileX, tileY int) error {
 		s.player.SetDestination(float32(tileX)*tileSize, float32(tileY)*tileSize)
 	}
 
-	s.lastMoveTick = uint32(time.Now().UnixMilli() & 0xFFFFFFFF)
+	s.lastMoveTick = uint32(clock.Now().UnixMilli() & 0xFFFFFFFF)
 	return nil
 }
 
@@ -490,6 +939,52 @@ func (s *InGameState) GetGAT() *formats.GAT {
 	return s.gat
 }
 
+// GetWalkability returns the mutable walkability layer (GAT plus runtime
+// overrides), or nil if the GAT is unavailable.
+func (s *InGameState) GetWalkability() *world.Walkability {
+	return s.walk
+}
+
+// CyclePiPMode switches the picture-in-picture debug camera to the next
+// mode (off, top-down, sun).
+func (s *InGameState) CyclePiPMode() {
+	if s.scene == nil {
+		return
+	}
+	next := s.scene.PiPMode().Next()
+	if err := s.scene.SetPiPMode(next); err != nil {
+		logger.Warn("failed to enable picture-in-picture", zap.Error(err))
+		return
+	}
+	logger.Debug("picture-in-picture mode", zap.String("mode", next.String()))
+}
+
+// GetPiPMode returns the current picture-in-picture camera mode.
+func (s *InGameState) GetPiPMode() scene.PiPMode {
+	if s.scene == nil {
+		return scene.PiPOff
+	}
+	return s.scene.PiPMode()
+}
+
+// GetPiPTexture returns the picture-in-picture texture, or 0 when disabled.
+func (s *InGameState) GetPiPTexture() uint32 {
+	if s.scene == nil {
+		return 0
+	}
+	return s.scene.PiPTexture()
+}
+
+// GetProgress returns the player's levels and experience.
+func (s *InGameState) GetProgress() entity.Progress {
+	return s.progress
+}
+
+// ChatLog returns the chat log and its tabs.
+func (s *InGameState) ChatLog() *chat.Log {
+	return s.chatLog
+}
+
 // GetPlayerEntity returns the player as an Entity (for UI).
 func (s *InGameState) GetPlayerEntity() *entity.Entity {
 	return s.entityManager.Player()
@@ -0,0 +1,51 @@
+package states
+
+import (
+	"github.com/Faultbox/midgard-ro/internal/engine/scene"
+	"github.com/Faultbox/midgard-ro/internal/game/entity"
+)
+
+// auraStyles returns the scene looks of a character's aura effects. The
+// level aura is gold for rebirth classes; job effects stack on top.
+func auraStyles(a entity.Aura) scene.AuraStyles {
+	var styles scene.AuraStyles
+	switch {
+	case a&entity.AuraMaxLevel != 0 && a&entity.AuraRebirth != 0:
+		styles |= scene.AuraGold
+	case a&entity.AuraMaxLevel != 0:
+		styles |= scene.AuraBlue
+	}
+	if a&entity.AuraSoulLink != 0 {
+		styles |= scene.AuraSpirit
+	}
+	if a&entity.AuraStarGladiator != 0 {
+		styles |= scene.AuraStarlight
+	}
+	return styles
+}
+
+// updateAuras sets the auras of the player and the players in view from
+// their job and base level, at the positions they're drawn at this frame.
+// Auras of players who left view or died are swept away.
+func (s *InGameState) updateAuras() {
+	if !s.manager.Auras {
+		s.scene.ClearAuras()
+		return
+	}
+	player := s.entityManager.Player()
+	for _, e := range s.entityManager.AllVisible() {
+		if e.Type != entity.TypePlayer || e.IsDead {
+			continue
+		}
+		styles := auraStyles(entity.AuraOf(e.Job, e.Level))
+		if styles == 0 {
+			continue
+		}
+		pos := [3]float32{e.Position.X, e.Position.Y, e.Position.Z}
+		if e == player {
+			pos[0], pos[1], pos[2] = s.player.RenderPosition()
+		}
+		s.scene.SetAura(e.ID, scene.Aura{Styles: styles, Position: pos})
+	}
+	s.scene.SweepAuras()
+}
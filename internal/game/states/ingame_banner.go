@@ -0,0 +1,78 @@
+package states
+
+import (
+	"time"
+	"unicode/utf8"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/clock"
+	"github.com/Faultbox/midgard-ro/internal/network/packets"
+)
+
+const (
+	// bannerBaseTime and bannerRuneTime make how long an announcement
+	// takes to scroll across the screen: longer text scrolls for longer,
+	// at about the same speed.
+	bannerBaseTime = 6 * time.Second
+	bannerRuneTime = 100 * time.Millisecond
+
+	// bannerMaxQueued is how many announcements wait their turn; a flood
+	// drops the oldest waiting, the chat log still has them.
+	bannerMaxQueued = 5
+)
+
+// Banner is a server announcement scrolling across the top of the screen.
+type Banner struct {
+	Text     string
+	Color    uint32  // 0xRRGGBB
+	FontSize int     // In points; 12 is the chat font's
+	Progress float32 // How far it has scrolled, 0 entering to 1 gone
+}
+
+// bannerQueue shows announcements one at a time, so they never overlap.
+type bannerQueue struct {
+	queue   []packets.Broadcast // The one showing first
+	started time.Time           // When the first started scrolling
+}
+
+// push queues an announcement. One already showing or waiting isn't
+// queued again.
+func (q *bannerQueue) push(b packets.Broadcast, now time.Time) {
+	for _, queued := range q.queue {
+		if queued == b {
+			return
+		}
+	}
+	if len(q.queue) == 0 {
+		q.started = now
+	}
+	if len(q.queue) > bannerMaxQueued {
+		q.queue = append(q.queue[:1], q.queue[2:]...)
+	}
+	q.queue = append(q.queue, b)
+}
+
+// current returns the announcement showing, moving on to the next once
+// it has scrolled by.
+func (q *bannerQueue) current(now time.Time) *Banner {
+	for len(q.queue) > 0 {
+		b := q.queue[0]
+		d := bannerBaseTime + time.Duration(utf8.RuneCountInString(b.Message))*bannerRuneTime
+		elapsed := now.Sub(q.started)
+		if elapsed < d {
+			return &Banner{
+				Text:     b.Message,
+				Color:    b.Color,
+				FontSize: int(b.FontSize),
+				Progress: float32(elapsed) / float32(d),
+			}
+		}
+		q.queue = q.queue[1:]
+		q.started = now
+	}
+	return nil
+}
+
+// Banner returns the server announcement to draw, or nil if there's none.
+func (s *InGameState) Banner() *Banner {
+	return s.banners.current(clock.Now())
+}
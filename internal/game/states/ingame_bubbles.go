@@ -0,0 +1,83 @@
+package states
+
+import (
+	"fmt"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/clock"
+	"github.com/Faultbox/midgard-ro/internal/engine/picking"
+	"github.com/Faultbox/midgard-ro/internal/game/chat"
+	"github.com/Faultbox/midgard-ro/internal/network/packets"
+)
+
+// chatBubbleLift is how far above a unit's feet (world units) its chat
+// bubble sits, clear of a standing character's head.
+const chatBubbleLift = 32
+
+// ChatBubble is a chat bubble in view, projected to the screen above its
+// speaker's head.
+type ChatBubble struct {
+	ID               uint32
+	ScreenX, ScreenY float32 // Bottom center
+	Text             string
+	Alpha            float32
+}
+
+func (s *InGameState) registerChatHandlers() {
+	s.client.RegisterHandler(packets.ZC_NOTIFY_CHAT, s.handleUnitChat)
+	s.client.RegisterHandler(packets.ZC_NOTIFY_PLAYERCHAT, s.handlePlayerChat)
+}
+
+// handleUnitChat processes ZC_NOTIFY_CHAT — a unit in view speaking.
+func (s *InGameState) handleUnitChat(data []byte) error {
+	c, ok := packets.DecodeUnitChat(data)
+	if !ok {
+		return fmt.Errorf("invalid ZC_NOTIFY_CHAT: %d bytes", len(data))
+	}
+	s.say(c.ID, c.Message)
+	return nil
+}
+
+// handlePlayerChat processes ZC_NOTIFY_PLAYERCHAT — the player's own chat
+// as the server passed it on.
+func (s *InGameState) handlePlayerChat(data []byte) error {
+	msg, ok := packets.DecodePlayerChat(data)
+	if !ok {
+		return fmt.Errorf("invalid ZC_NOTIFY_PLAYERCHAT: %d bytes", len(data))
+	}
+	s.say(s.entityManager.PlayerID(), msg)
+	return nil
+}
+
+// say logs a line of public chat and shows it over its speaker's head.
+func (s *InGameState) say(id uint32, msg string) {
+	s.addChat(chat.Public, msg)
+	s.bubbles.Say(id, msg, clock.Now())
+}
+
+// GetChatBubbles returns the chat bubbles in view, projected to a
+// viewportW x viewportH screen. While away the player has the AFK marker
+// over their head, unless they're saying something.
+func (s *InGameState) GetChatBubbles(viewportW, viewportH float32) []ChatBubble {
+	if s.scene == nil {
+		return nil
+	}
+	now := clock.Now()
+	viewProj := s.scene.LastViewProj()
+	var bubbles []ChatBubble
+	for _, e := range s.entityManager.AllVisible() {
+		text, alpha, ok := s.bubbles.Bubble(e.ID, now)
+		if !ok && s.away && e.ID == s.entityManager.PlayerID() {
+			text, alpha, ok = awayMarker, 1, true
+		}
+		if !ok {
+			continue
+		}
+		p := [3]float32{e.Position.X, e.Position.Y + chatBubbleLift, e.Position.Z}
+		x, y, ok := picking.WorldToScreen(p, viewportW, viewportH, viewProj)
+		if !ok {
+			continue
+		}
+		bubbles = append(bubbles, ChatBubble{ID: e.ID, ScreenX: x, ScreenY: y, Text: text, Alpha: alpha})
+	}
+	return bubbles
+}
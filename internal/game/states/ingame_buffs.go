@@ -0,0 +1,53 @@
+package states
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/clock"
+	"github.com/Faultbox/midgard-ro/internal/game/chat"
+	"github.com/Faultbox/midgard-ro/internal/game/entity"
+)
+
+// BuffIcon is one of the player's buffs as its status icon shows it.
+type BuffIcon struct {
+	Name      string
+	Timed     bool          // Wears off by itself
+	Remaining time.Duration // Until it wears off
+	Fraction  float32       // Of its time left, 1 for untimed buffs
+	Expiring  bool          // Wears off within entity.BuffWarnTime
+}
+
+// Buffs returns the player's buffs, in the order they were put on, after
+// the overweight state if there's one.
+func (s *InGameState) Buffs() []BuffIcon {
+	now := clock.Now()
+	buffs := s.buffs.Active(now)
+	icons := make([]BuffIcon, 0, len(buffs)+1)
+	if icon, ok := s.overweightBuff(); ok {
+		icons = append(icons, icon)
+	}
+	for _, b := range buffs {
+		if b.Status == entity.StatusWeightOver50 || b.Status == entity.StatusWeightOver90 {
+			continue // Shown from the weight
+		}
+		icons = append(icons, BuffIcon{
+			Name:      entity.StatusName(b.Status),
+			Timed:     b.Timed(),
+			Remaining: b.Remaining(now),
+			Fraction:  b.Fraction(now),
+			Expiring:  b.Expiring(now),
+		})
+	}
+	return icons
+}
+
+// warnExpiringBuffs warns in chat of buffs about to wear off, if enabled.
+func (s *InGameState) warnExpiringBuffs(now time.Time) {
+	for _, b := range s.buffs.Expiring(now) {
+		if s.manager.BuffWarnings {
+			secs := int(b.Remaining(now).Round(time.Second) / time.Second)
+			s.addChat(chat.System, fmt.Sprintf("%s wears off in %d seconds.", entity.StatusName(b.Status), secs))
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package states
+
+import (
+	"github.com/Faultbox/midgard-ro/internal/engine/camera"
+	"github.com/Faultbox/midgard-ro/internal/game/entity"
+	"github.com/Faultbox/midgard-ro/pkg/math"
+)
+
+// Camera feedback on combat and NPC dialogs.
+const (
+	// heavyHitFraction is the share of the player's max HP a hit must take
+	// to shake the camera.
+	heavyHitFraction = 0.1
+	heavyHitShake    = 3    // World units
+	heavyHitDuration = 0.35 // Seconds
+
+	criticalPunch         = 0.08 // Fraction of the camera distance
+	criticalPunchDuration = 0.25
+
+	// The camera comes closer and looks halfway to an NPC the player
+	// talks with.
+	dialogZoom     = 0.25
+	dialogZoomEase = 0.6
+)
+
+// cameraHitFeedback shakes the camera when the player takes a heavy hit and
+// punches it in when the player lands a critical one.
+func (s *InGameState) cameraHitFeedback(hit entity.Hit, kind entity.DamageKind, target *entity.Entity) {
+	if s.camera == nil || hit.Damage <= 0 {
+		return
+	}
+	switch {
+	case kind == entity.DamageTaken && target.MaxHP > 0 && float32(hit.Damage) >= heavyHitFraction*float32(target.MaxHP):
+		s.camera.Effects.Add(camera.Shake(heavyHitShake, heavyHitDuration))
+	case kind == entity.DamageDealt && hit.Critical:
+		s.camera.Effects.Add(camera.ZoomPunch(criticalPunch, criticalPunchDuration))
+	}
+}
+
+// startDialogZoom eases the camera toward an NPC when a dialog with it
+// starts. Lines after the first keep the zoom going.
+func (s *InGameState) startDialogZoom(npcID uint32) {
+	if s.camera == nil || s.dialogZoom != 0 || s.player == nil {
+		return
+	}
+	npc := s.entityManager.Get(npcID)
+	if npc == nil {
+		return
+	}
+	x, y, z := s.player.RenderPosition()
+	focus := npc.Position.Sub(math.Vec3{X: x, Y: y, Z: z}).Scale(0.5)
+	s.dialogZoom = s.camera.Effects.Add(camera.ZoomTo(focus, dialogZoom, dialogZoomEase))
+}
+
+// endDialogZoom eases the camera back once the dialog ends.
+func (s *InGameState) endDialogZoom() {
+	if s.camera != nil && s.dialogZoom != 0 {
+		s.camera.Effects.Release(s.dialogZoom)
+	}
+	s.dialogZoom = 0
+}
@@ -0,0 +1,104 @@
+package states
+
+import (
+	"fmt"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/picking"
+	"github.com/Faultbox/midgard-ro/internal/engine/scene"
+	"github.com/Faultbox/midgard-ro/internal/network/packets"
+)
+
+func (s *InGameState) registerChatRoomHandlers() {
+	s.client.RegisterHandler(packets.ZC_ROOM_NEWENTRY, s.handleChatRoom)
+	s.client.RegisterHandler(packets.ZC_CHANGE_CHATROOM, s.handleChatRoom)
+	s.client.RegisterHandler(packets.ZC_DESTROY_ROOM, s.handleDestroyRoom)
+}
+
+// chatRoomLabel returns the text of a chat room's board: its title and
+// how full it is.
+func chatRoomLabel(room packets.ChatRoom) string {
+	return fmt.Sprintf("%s (%d/%d)", room.Title, room.Users, room.Limit)
+}
+
+// syncChatRoomBoards places the board of each chat room in view over its
+// owner's head: an event sign for an NPC's waiting room, a chat room board
+// for a player's. Boards of owners that haven't spawned or are culled are
+// hidden.
+func (s *InGameState) syncChatRoomBoards() {
+	if s.scene == nil {
+		return
+	}
+	for id, room := range s.chatRooms {
+		e := s.entityManager.Get(id)
+		if e == nil || !e.IsVisible || e.Culled {
+			s.scene.RemoveBoard(id)
+			continue
+		}
+		kind := scene.BoardChatroom
+		if room.Type == packets.ChatRoomArena {
+			kind = scene.BoardEvent
+		}
+		s.scene.SetBoard(id, scene.Board{
+			Kind:     kind,
+			Text:     chatRoomLabel(room),
+			Position: [3]float32{e.Position.X, e.Position.Y + vendingBoardLift, e.Position.Z},
+		})
+	}
+}
+
+// ChatRoomAt shows the chat room whose board is under a left-click in the
+// chat log. Returns false if there is none, so the click can go on to
+// shops and the ground. Joining rooms isn't supported yet.
+func (s *InGameState) ChatRoomAt(screenX, screenY, viewportW, viewportH float32) bool {
+	if len(s.chatRooms) == 0 || s.scene == nil || viewportW <= 0 || viewportH <= 0 {
+		return false
+	}
+	ray := picking.ScreenToRay(screenX, screenY, viewportW, viewportH, s.scene.LastViewProj().Inverse())
+	id, ok := s.scene.PickBoard(ray)
+	if !ok {
+		return false
+	}
+	room, ok := s.chatRooms[id]
+	if !ok {
+		return false
+	}
+	kind := "Chat room"
+	switch room.Type {
+	case packets.ChatRoomPrivate:
+		kind = "Private chat room"
+	case packets.ChatRoomArena:
+		kind = "Waiting room"
+	}
+	s.addChatMessage(fmt.Sprintf("%s: %s. Joining isn't supported yet.", kind, chatRoomLabel(room)))
+	return true
+}
+
+// handleChatRoom processes ZC_ROOM_NEWENTRY and ZC_CHANGE_CHATROOM — a
+// chat room was opened or changed, or came into view.
+func (s *InGameState) handleChatRoom(data []byte) error {
+	room := packets.DecodeChatRoom(data)
+	if room == nil {
+		return fmt.Errorf("invalid chat room packet: %d bytes", len(data))
+	}
+	s.chatRooms[room.OwnerID] = *room
+	s.syncChatRoomBoards()
+	return nil
+}
+
+// handleDestroyRoom processes ZC_DESTROY_ROOM — a chat room closed.
+func (s *InGameState) handleDestroyRoom(data []byte) error {
+	roomID, ok := packets.DecodeDestroyRoom(data)
+	if !ok {
+		return fmt.Errorf("invalid ZC_DESTROY_ROOM: %d bytes", len(data))
+	}
+	for owner, room := range s.chatRooms {
+		if room.RoomID != roomID {
+			continue
+		}
+		delete(s.chatRooms, owner)
+		if s.scene != nil {
+			s.scene.RemoveBoard(owner)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package states
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/clock"
+	"github.com/Faultbox/midgard-ro/internal/engine/picking"
+	"github.com/Faultbox/midgard-ro/internal/game/entity"
+	"github.com/Faultbox/midgard-ro/internal/network/packets"
+	"github.com/Faultbox/midgard-ro/pkg/formats"
+)
+
+const (
+	// hitSound plays when a hit lands if the attack animation names no
+	// sound of its own.
+	hitSound = "data/wav/_hit_fist1.wav"
+
+	// soundDir is where ACT sound events are found.
+	soundDir = "data/wav/"
+
+	// damageNumberLift is how far above a unit's feet (world units) its
+	// damage numbers start.
+	damageNumberLift = 10
+)
+
+// DamageNumber is a damage number in view, projected to the screen.
+type DamageNumber struct {
+	ScreenX, ScreenY float32
+	Amount           int // 0 for a miss
+	Kind             entity.DamageKind
+	Critical         bool
+	Alpha            float32
+}
+
+func (s *InGameState) registerCombatHandlers() {
+	s.client.RegisterHandler(packets.ZC_NOTIFY_ACT, s.handleNotifyAct)
+	s.client.RegisterHandler(packets.ZC_NOTIFY_ACT2, s.handleNotifyAct)
+	s.client.RegisterHandler(packets.ZC_NOTIFY_SKILL2, s.handleNotifySkill)
+}
+
+// handleNotifyAct processes ZC_NOTIFY_ACT and ZC_NOTIFY_ACT2 — a unit
+// attacked, sat down or stood up. An attack plays the attacker's attack
+// animation, and its damage shows when the animation's attack frame
+// strikes rather than when the packet arrives.
+func (s *InGameState) handleNotifyAct(data []byte) error {
+	act := packets.DecodeNotifyAct(data)
+	if act == nil {
+		return fmt.Errorf("invalid ZC_NOTIFY_ACT: %d bytes", len(data))
+	}
+	switch act.Type {
+	case packets.ActTypeSit, packets.ActTypeStand:
+		if e := s.entityManager.Get(act.SourceID); e != nil {
+			e.State = entity.StateIdle
+			if act.Type == packets.ActTypeSit {
+				e.State = entity.StateSitting
+			}
+		}
+		return nil
+	}
+	if !act.IsAttack() {
+		return nil
+	}
+
+	now := clock.Now()
+	hit := entity.Hit{
+		SourceID: act.SourceID,
+		TargetID: act.TargetID,
+		Damage:   int(act.Damage) + int(act.Damage2),
+		Critical: act.Type == packets.ActTypeCritical || act.Type == packets.ActTypeMultiCritical,
+		At:       now,
+	}
+	if src := s.entityManager.Get(act.SourceID); src != nil && !src.IsDead {
+		motion := time.Duration(act.AttackMotion) * time.Millisecond
+		src.State = entity.StateAttacking
+		s.attackEnds[src.ID] = now.Add(motion)
+		s.beginAction(src.ID, now.Add(motion))
+		if target := s.entityManager.Get(act.TargetID); target != nil {
+			src.Direction = uint8(entity.CalculateDirection(target.Position.X-src.Position.X, target.Position.Z-src.Position.Z))
+		}
+		if a, action := s.attackAnimation(src); a != nil {
+			if delay, ok := entity.HitDelay(a, action, motion); ok {
+				hit.At = now.Add(delay)
+			}
+			hit.Sound = attackSound(a, action)
+		}
+	}
+	s.combat.Schedule(hit)
+	return nil
+}
+
+// handleNotifySkill processes ZC_NOTIFY_SKILL2 — a skill hit a unit. Its
+// damage shows once the target's flinch starts, after the source's attack
+// motion.
+func (s *InGameState) handleNotifySkill(data []byte) error {
+	sk := packets.DecodeNotifySkill(data)
+	if sk == nil {
+		return fmt.Errorf("invalid ZC_NOTIFY_SKILL2: %d bytes", len(data))
+	}
+	if sk.Damage < 0 {
+		return nil
+	}
+	motion := time.Duration(sk.AttackMotion) * time.Millisecond
+	now := clock.Now()
+	s.beginAction(sk.SourceID, now.Add(motion))
+	s.combat.Schedule(entity.Hit{
+		SourceID: sk.SourceID,
+		TargetID: sk.TargetID,
+		Damage:   int(sk.Damage),
+		At:       now.Add(motion),
+	})
+	return nil
+}
+
+// attackAnimation returns the ACT of a unit's attack and the index of its
+// action facing the unit's direction, or nil if the unit's sprite isn't
+// loaded.
+func (s *InGameState) attackAnimation(e *entity.Entity) (*formats.ACT, int) {
+	layers := entity.SpriteLayers(e, int(e.Direction), s.manager.SpriteFallbacks)
+	if len(layers) == 0 {
+		return nil, 0
+	}
+	a := s.layerAsset(layers[0])
+	if a == nil {
+		return nil, 0
+	}
+	return a.act, attackAction(e)*8 + int(e.Direction)
+}
+
+// attackSound returns the path of the first sound an attack action plays,
+// or "" if it plays none.
+func attackSound(act *formats.ACT, action int) string {
+	if action >= len(act.Actions) {
+		return ""
+	}
+	for i := range act.Actions[action].Frames {
+		f := &act.Actions[action].Frames[i]
+		if f.Trigger.Has(formats.TriggerSound) {
+			return soundDir + act.EventName(f)
+		}
+	}
+	return ""
+}
+
+// updateCombat lands the hits whose attack frames have struck, ends
+// finished attack animations and ages damage numbers.
+func (s *InGameState) updateCombat(dt float64) {
+	now := clock.Now()
+	for _, hit := range s.combat.Due(now) {
+		s.landHit(hit)
+	}
+	for id, end := range s.attackEnds {
+		if now.Before(end) {
+			continue
+		}
+		if e := s.entityManager.Get(id); e != nil && e.State == entity.StateAttacking {
+			e.State = entity.StateIdle
+		}
+		delete(s.attackEnds, id)
+	}
+	s.damageNumbers = entity.AgeDamageNumbers(s.damageNumbers, dt)
+}
+
+// landHit shows a hit's damage over its target, flashes the target's
+// sprite and plays the hit's sound.
+func (s *InGameState) landHit(hit entity.Hit) {
+	target := s.entityManager.Get(hit.TargetID)
+	if target == nil {
+		return
+	}
+	kind := entity.DamageOther
+	switch s.entityManager.PlayerID() {
+	case hit.SourceID:
+		kind = entity.DamageDealt
+	case hit.TargetID:
+		kind = entity.DamageTaken
+	}
+	pos := target.Position
+	pos.Y += damageNumberLift
+	s.damageNumbers = append(s.damageNumbers, entity.DamageNumber{
+		Position: pos,
+		Amount:   hit.Damage,
+		Kind:     kind,
+		Critical: hit.Critical,
+	})
+	s.cameraHitFeedback(hit, kind, target)
+	if hit.Damage > 0 {
+		target.Flash.Start(entity.FlashFor(target, hit.Critical))
+	}
+
+	if hit.Damage <= 0 || s.manager.PlaySoundAt == nil {
+		return
+	}
+	sound := hit.Sound
+	if sound == "" {
+		sound = hitSound
+	}
+	s.manager.PlaySoundAt(sound, target.Position.X, target.Position.Y, target.Position.Z)
+}
+
+// GetDamageNumbers returns the damage numbers in view, projected to a
+// viewportW x viewportH screen.
+func (s *InGameState) GetDamageNumbers(viewportW, viewportH float32) []DamageNumber {
+	if s.scene == nil || len(s.damageNumbers) == 0 {
+		return nil
+	}
+	viewProj := s.scene.LastViewProj()
+	nums := make([]DamageNumber, 0, len(s.damageNumbers))
+	for i := range s.damageNumbers {
+		d := &s.damageNumbers[i]
+		p := [3]float32{d.Position.X, d.Position.Y + d.Offset(), d.Position.Z}
+		x, y, ok := picking.WorldToScreen(p, viewportW, viewportH, viewProj)
+		if !ok {
+			continue
+		}
+		nums = append(nums, DamageNumber{
+			ScreenX:  x,
+			ScreenY:  y,
+			Amount:   d.Amount,
+			Kind:     d.Kind,
+			Critical: d.Critical,
+			Alpha:    d.Alpha(),
+		})
+	}
+	return nums
+}
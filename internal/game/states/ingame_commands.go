@@ -0,0 +1,349 @@
+package states
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/clock"
+	"github.com/Faultbox/midgard-ro/internal/game/chat"
+	"github.com/Faultbox/midgard-ro/internal/game/commands"
+	"github.com/Faultbox/midgard-ro/internal/game/entity"
+	"github.com/Faultbox/midgard-ro/internal/logger"
+	"github.com/Faultbox/midgard-ro/internal/network/packets"
+)
+
+// maxEmotion is the highest emotion index accepted by /emote (rAthena ET_MAX - 1).
+const maxEmotion = 88
+
+// emotionNames names the common emotions by index, after rAthena's
+// emotion_type, so /emote takes a name as well as a number.
+var emotionNames = []string{
+	"surprise", "question", "delight", "throb", "sweat", "aha", "fret", "anger",
+	"money", "think", "scissors", "rock", "paper", "flag", "bigthrob", "thanks",
+	"kek", "sorry", "smile", "sweat2", "scratch", "best", "stare", "huk",
+	"o", "x", "help", "go", "cry", "kik", "chup", "chupchup", "hng", "ok",
+}
+
+// registerCommands sets up the chat command dispatcher with the player
+// commands, plus dev commands when enabled in the config.
+func (s *InGameState) registerCommands() {
+	s.commands = commands.NewDispatcher(s.addChatMessage)
+	s.commands.SetDevMode(s.manager.DevCommands)
+
+	for _, cmd := range []commands.Command{
+		{Name: "where", Help: "Show the current map and coordinates", Run: s.cmdWhere},
+		{Name: "time", Help: "Show local and server time", Run: s.cmdTime},
+		{Name: "who", Help: "Show how many players are online", Run: s.cmdWho},
+		{Name: "sit", Help: "Sit down", Run: s.cmdSit},
+		{Name: "stand", Help: "Stand up", Run: s.cmdStand},
+		{Name: "w", Aliases: []string{"whisper"}, Usage: "<name> <message>", Help: "Send a private message", Run: s.cmdWhisper, Complete: s.completeName},
+		{Name: "invite", Usage: "<name>", Help: "Invite a player to your party", Run: s.cmdInvite, Complete: s.completeName},
+		{Name: "emote", Aliases: []string{"e"}, Usage: "<0-88 or name>", Help: "Show an emotion bubble", Run: s.cmdEmote, Complete: completeEmotion},
+		{Name: "quit", Aliases: []string{"logout"}, Help: "Log out", Run: s.cmdQuit},
+		{Name: "charselect", Help: "Return to character select", Run: s.cmdCharSelect},
+		{Name: "dnd", Help: "Toggle do-not-disturb, which hides notifications", Run: s.cmdDoNotDisturb},
+
+		// Dev-only (game.dev_commands)
+		{Name: "cell", Usage: "[x y]", Help: "Show the walkability of a cell", Dev: true, Run: s.cmdCell},
+		{Name: "pip", Help: "Cycle the picture-in-picture debug camera", Dev: true, Run: s.cmdPiP},
+		{Name: "desync", Usage: "[save]", Help: "Show position desyncs, or save the last for a bug report", Dev: true, Run: s.cmdDesync},
+		{Name: "skill", Usage: "<id> [level]", Help: "Use a skill on the unit under the mouse, or yourself", Dev: true, Run: s.cmdSkill},
+	} {
+		if err := s.commands.Register(cmd); err != nil {
+			logger.Warn("failed to register chat command", zap.Error(err))
+		}
+	}
+}
+
+// SubmitChat handles a line entered in the chat input. Slash-commands are
+// dispatched locally; anything else is echoed, over the player's head too,
+// since sending chat to the server isn't supported yet.
+func (s *InGameState) SubmitChat(line string) {
+	if line == "" {
+		return
+	}
+	if !commands.IsCommand(line) {
+		s.say(s.entityManager.PlayerID(), line)
+		return
+	}
+	if s.commands == nil {
+		return
+	}
+	if err := s.commands.Execute(line); err != nil {
+		s.addChatMessage(err.Error())
+	}
+}
+
+// CompleteChat returns completions for the end of the chat line being
+// typed: command names, player names for /w and /invite, and emotion
+// names for /emote. The candidates replace the line from start.
+func (s *InGameState) CompleteChat(line string) (start int, candidates []string) {
+	if s.commands == nil {
+		return 0, nil
+	}
+	return s.commands.Complete(line)
+}
+
+// completeName ranks the player names starting with or containing typed:
+// the ones whispered or invited recently, then the players around, nearest
+// first.
+func (s *InGameState) completeName(typed string) []string {
+	self := s.entityManager.Player()
+	var players []*entity.Entity
+	for _, e := range s.entityManager.GetByType(entity.TypePlayer) {
+		if e != self && e.Name != "" && !e.Leaving {
+			players = append(players, e)
+		}
+	}
+	if self != nil {
+		distance := func(e *entity.Entity) float32 {
+			dx, dz := e.Position.X-self.Position.X, e.Position.Z-self.Position.Z
+			return dx*dx + dz*dz
+		}
+		slices.SortFunc(players, func(a, b *entity.Entity) int {
+			return cmp.Compare(distance(a), distance(b))
+		})
+	}
+	nearby := make([]string, len(players))
+	for i, e := range players {
+		nearby[i] = e.Name
+	}
+	return s.recentNames.Match(typed, nearby)
+}
+
+// completeEmotion returns the emotion names starting with typed.
+func completeEmotion(typed string) []string {
+	return commands.MatchPrefix(typed, emotionNames)
+}
+
+// RegisterCommand adds a chat command, e.g. dev tools owned by other
+// subsystems. Must be called after Enter.
+func (s *InGameState) RegisterCommand(cmd commands.Command) error {
+	if s.commands == nil {
+		return fmt.Errorf("command %q: state not entered", cmd.Name)
+	}
+	return s.commands.Register(cmd)
+}
+
+func (s *InGameState) cmdWhere(args []string) error {
+	if len(args) != 0 {
+		return commands.ErrUsage
+	}
+	s.addChatMessage(fmt.Sprintf("%s %d %d", s.MapName, s.TileX, s.TileY))
+	return nil
+}
+
+func (s *InGameState) cmdTime(args []string) error {
+	if len(args) != 0 {
+		return commands.ErrUsage
+	}
+	s.addChatMessage("Local time: " + clock.Now().Format("2006-01-02 15:04:05"))
+	if !s.clock.Synced() {
+		s.addChatMessage("Server time: unknown (no reply to keep-alive yet)")
+		return nil
+	}
+	// The server only reports its uptime; the clock extrapolates from the last reply
+	s.addChatMessage("Server uptime: " + s.clock.Uptime(clock.Now()).Truncate(time.Second).String())
+	d, _ := s.Daylight()
+	phase := "day"
+	if d.Night {
+		phase = "night"
+	}
+	s.addChatMessage(fmt.Sprintf("Game time: %s (%s)", d.Clock(), phase))
+	return nil
+}
+
+// cmdWho asks the server for the players online; the count comes back in
+// ZC_USER_COUNT.
+func (s *InGameState) cmdWho(args []string) error {
+	if len(args) != 0 {
+		return commands.ErrUsage
+	}
+	pkt := &packets.UserCountRequest{PacketID: packets.CZ_REQ_USER_COUNT}
+	if err := s.client.Send(pkt.Encode()); err != nil {
+		return fmt.Errorf("send user count request: %w", err)
+	}
+	return nil
+}
+
+// handleUserCount shows the answer to /who.
+func (s *InGameState) handleUserCount(data []byte) error {
+	n, ok := packets.DecodeUserCount(data)
+	if !ok {
+		return fmt.Errorf("invalid ZC_USER_COUNT: %d bytes", len(data))
+	}
+	if n == 1 {
+		s.addChatMessage("1 player online.")
+	} else {
+		s.addChatMessage(fmt.Sprintf("%d players online.", n))
+	}
+	return nil
+}
+
+func (s *InGameState) cmdSit(args []string) error {
+	if len(args) != 0 {
+		return commands.ErrUsage
+	}
+	return s.sendAction(0, packets.ActionSit)
+}
+
+func (s *InGameState) cmdStand(args []string) error {
+	if len(args) != 0 {
+		return commands.ErrUsage
+	}
+	return s.sendAction(0, packets.ActionStand)
+}
+
+func (s *InGameState) cmdEmote(args []string) error {
+	if len(args) != 1 {
+		return commands.ErrUsage
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil {
+		n = slices.Index(emotionNames, strings.ToLower(args[0]))
+	}
+	if n < 0 || n > maxEmotion {
+		return commands.ErrUsage
+	}
+
+	pkt := &packets.EmotionRequest{PacketID: packets.CZ_REQ_EMOTION, Type: uint8(n)}
+	if err := s.client.Send(pkt.Encode()); err != nil {
+		return fmt.Errorf("send emotion: %w", err)
+	}
+	return nil
+}
+
+func (s *InGameState) cmdWhisper(args []string) error {
+	if len(args) < 2 || args[0] == "" {
+		return commands.ErrUsage
+	}
+	message := strings.Join(args[1:], " ")
+
+	pkt := &packets.Whisper{PacketID: packets.CZ_WHISPER, Target: args[0], Message: message}
+	if err := s.client.Send(pkt.Encode()); err != nil {
+		return fmt.Errorf("send whisper: %w", err)
+	}
+	s.addChat(chat.Whisper, fmt.Sprintf("(To %s) %s", args[0], message))
+	s.recentNames.Seen(args[0])
+	return nil
+}
+
+func (s *InGameState) cmdInvite(args []string) error {
+	if len(args) != 1 || args[0] == "" {
+		return commands.ErrUsage
+	}
+	pkt := &packets.PartyInvite{PacketID: packets.CZ_PARTY_JOIN_REQ, Name: args[0]}
+	if err := s.client.Send(pkt.Encode()); err != nil {
+		return fmt.Errorf("send party invite: %w", err)
+	}
+	s.addChatMessage(fmt.Sprintf("Party invitation sent to %s.", args[0]))
+	s.recentNames.Seen(args[0])
+	return nil
+}
+
+func (s *InGameState) cmdCell(args []string) error {
+	x, y := s.TileX, s.TileY
+	switch len(args) {
+	case 0:
+	case 2:
+		var errX, errY error
+		x, errX = strconv.Atoi(args[0])
+		y, errY = strconv.Atoi(args[1])
+		if errX != nil || errY != nil {
+			return commands.ErrUsage
+		}
+	default:
+		return commands.ErrUsage
+	}
+
+	if s.walk == nil {
+		return fmt.Errorf("no walkability data for %s", s.MapName)
+	}
+	if !s.walk.InBounds(x, y) {
+		return fmt.Errorf("cell (%d, %d) is outside the map", x, y)
+	}
+	msg := fmt.Sprintf("Cell (%d, %d): %s", x, y, s.walk.CellType(x, y))
+	if s.ground.MultiLevel(x, y) {
+		levels := s.ground.Levels((float32(x)+0.5)*5, (float32(y)+0.5)*5)
+		msg += fmt.Sprintf(", levels at %.1f and %.1f", levels[0], levels[1])
+	}
+	s.addChatMessage(msg)
+	return nil
+}
+
+func (s *InGameState) cmdSkill(args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return commands.ErrUsage
+	}
+	id, err := strconv.ParseUint(args[0], 10, 16)
+	if err != nil {
+		return commands.ErrUsage
+	}
+	level := uint64(1)
+	if len(args) == 2 {
+		if level, err = strconv.ParseUint(args[1], 10, 16); err != nil {
+			return commands.ErrUsage
+		}
+	}
+	target := s.hoverID
+	if target == 0 {
+		target = s.entityManager.PlayerID()
+	}
+	return s.UseSkill(uint16(id), uint16(level), target)
+}
+
+func (s *InGameState) cmdDoNotDisturb(args []string) error {
+	if len(args) != 0 {
+		return commands.ErrUsage
+	}
+	s.manager.DoNotDisturb = !s.manager.DoNotDisturb
+	if s.manager.DoNotDisturb {
+		s.notices = nil
+		s.addChatMessage("Do-not-disturb on: notifications are hidden.")
+	} else {
+		s.addChatMessage("Do-not-disturb off.")
+	}
+	return nil
+}
+
+func (s *InGameState) cmdPiP(args []string) error {
+	if len(args) != 0 {
+		return commands.ErrUsage
+	}
+	s.CyclePiPMode()
+	s.addChatMessage("PiP camera: " + s.GetPiPMode().String())
+	return nil
+}
+
+// sendAction sends a CZ_REQUEST_ACT2 for the player. Attacks the server
+// would refuse for the weight carried aren't sent.
+func (s *InGameState) sendAction(targetID uint32, action uint8) error {
+	if (action == packets.ActionAttack || action == packets.ActionAttackRepeat) && s.weight.Level() == entity.WeightOver90 {
+		return errors.New("you can't attack while carrying over 90% of your weight limit")
+	}
+	pkt := &packets.ActionRequest{PacketID: packets.CZ_REQUEST_ACT2, TargetID: targetID, Action: action}
+	if err := s.client.Send(pkt.Encode()); err != nil {
+		return fmt.Errorf("send action: %w", err)
+	}
+	return nil
+}
+
+// handleNotifyTime times the keep-alive ZC_NOTIFY_TIME answers and syncs
+// the server clock to its tick.
+func (s *InGameState) handleNotifyTime(data []byte) error {
+	pkt := packets.DecodeNotifyTime(data)
+	if pkt == nil {
+		return nil
+	}
+	now := clock.Now()
+	s.keepAliveAnswered(now)
+	s.syncClock(pkt.ServerTick, now)
+	return nil
+}
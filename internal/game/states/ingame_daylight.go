@@ -0,0 +1,70 @@
+package states
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/clock"
+	"github.com/Faultbox/midgard-ro/internal/game/world"
+	"github.com/Faultbox/midgard-ro/internal/network/packets"
+)
+
+func (s *InGameState) registerDaylightHandlers() {
+	s.client.RegisterHandler(packets.ZC_MSG_STATE_CHANGE, s.handleStatusChange)
+	s.client.RegisterHandler(packets.ZC_MSG_STATE_CHANGE3, s.handleStatusChange)
+}
+
+// handleStatusChange processes ZC_MSG_STATE_CHANGE and its timed variant.
+// Only the player's statuses are used: rAthena puts EFST_SKE on players
+// on maps with night enabled while its day/night cycle is at night, and
+// the others are the player's buffs.
+func (s *InGameState) handleStatusChange(data []byte) error {
+	sc := packets.DecodeStatusChange(data)
+	if sc == nil {
+		return fmt.Errorf("invalid ZC_MSG_STATE_CHANGE: %d bytes", len(data))
+	}
+	if sc.ID != s.entityManager.PlayerID() {
+		return nil
+	}
+	if sc.Status == packets.EFST_SKE {
+		s.observeNight(sc.On, clock.Now())
+		return nil
+	}
+	total := time.Duration(sc.Total) * time.Millisecond
+	left := time.Duration(sc.Left) * time.Millisecond
+	s.buffs.Set(sc.Status, sc.On, total, left, clock.Now())
+	return nil
+}
+
+// observeNight records the server announcing night or day. Until the
+// server clock is synced it's kept for the first ZC_NOTIFY_TIME, since the
+// cycle is anchored in server time.
+func (s *InGameState) observeNight(night bool, now time.Time) {
+	if !s.clock.Synced() {
+		s.pendingNight = &night
+		return
+	}
+	s.dayCycle.Observe(night, s.clock.Uptime(now))
+}
+
+// syncClock records a server tick from ZC_NOTIFY_TIME.
+func (s *InGameState) syncClock(tick uint32, now time.Time) {
+	s.clock.Sync(tick, s.heartbeat.Latency(), now)
+	if s.pendingNight != nil {
+		s.dayCycle.Observe(*s.pendingNight, s.clock.Uptime(now))
+		s.pendingNight = nil
+	}
+}
+
+// Daylight returns the time of day, and whether the server clock is
+// synced; before then it's always day.
+func (s *InGameState) Daylight() (world.Daylight, bool) {
+	return s.dayCycle.At(s.clock.Uptime(clock.Now())), s.clock.Synced()
+}
+
+// updateDaylight darkens the scene for the time of day.
+func (s *InGameState) updateDaylight() {
+	d, _ := s.Daylight()
+	s.scene.Darkness = float32(d.Darkness)
+	s.scene.NightLamps = world.HasNightLamps(s.MapName)
+}
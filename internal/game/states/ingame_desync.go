@@ -0,0 +1,115 @@
+package states
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/clock"
+	"github.com/Faultbox/midgard-ro/internal/game/commands"
+	"github.com/Faultbox/midgard-ro/internal/game/world"
+	"github.com/Faultbox/midgard-ro/internal/logger"
+)
+
+// desyncSampleInterval is how often the player's position is compared with
+// the server's. With the default strike count a desync is reported after
+// about a second of drift.
+const desyncSampleInterval = 250 * time.Millisecond
+
+// playerWalkSpeed is the player's local walk speed in world units per
+// second, matching the server's default of one cell per
+// world.DefaultCellDuration so the predicted position keeps pace.
+const playerWalkSpeed = 5.0 / float32(world.DefaultCellDuration) * float32(time.Second)
+
+// resetServerWalk records the player as standing on a tile, e.g. at spawn.
+func (s *InGameState) resetServerWalk(x, y int) {
+	s.serverWalk = world.ServerWalk{
+		StartX: x, StartY: y,
+		EndX: x, EndY: y,
+		Start:        clock.Now(),
+		CellDuration: world.DefaultCellDuration,
+	}
+	s.desync.Reset()
+	s.lastDesyncSample = time.Time{}
+}
+
+// checkDesync compares the player's predicted position with the server's
+// estimated one, and resyncs when they have drifted apart.
+func (s *InGameState) checkDesync(now time.Time) {
+	if s.player == nil || now.Sub(s.lastDesyncSample) < desyncSampleInterval {
+		return
+	}
+	s.lastDesyncSample = now
+
+	const tileSize = float32(5.0)
+	serverX, serverY := s.serverWalk.PositionAt(now)
+	ev := s.desync.Record(world.PositionSample{
+		Time:       now,
+		ServerX:    serverX,
+		ServerY:    serverY,
+		PredictedX: s.player.WorldX / tileSize,
+		PredictedY: s.player.WorldZ / tileSize,
+	})
+	if ev == nil {
+		return
+	}
+	ev.Map = s.MapName
+	s.lastDesync = ev
+	s.desyncCount++
+
+	last := ev.Last()
+	logger.Warn("position desync",
+		zap.String("map", ev.Map),
+		zap.Float32("drift", ev.Drift),
+		zap.Float32("serverX", last.ServerX),
+		zap.Float32("serverY", last.ServerY),
+		zap.Float32("predictedX", last.PredictedX),
+		zap.Float32("predictedY", last.PredictedY),
+		zap.Int("samples", len(ev.Samples)))
+	s.resyncPosition(now)
+}
+
+// resyncPosition moves the player to where the server has them. A walk the
+// server is still on is requested again, so the server confirms it from
+// its actual position.
+func (s *InGameState) resyncPosition(now time.Time) {
+	const tileSize = float32(5.0)
+	x, y := s.serverWalk.PositionAt(now)
+	s.player.WorldX = x * tileSize
+	s.player.WorldZ = y * tileSize
+
+	w := s.serverWalk
+	if now.Sub(w.Start) >= w.Duration() {
+		s.player.ClearDestination()
+		return
+	}
+	if err := s.RequestMove(w.EndX, w.EndY); err != nil {
+		logger.Warn("resync move request failed", zap.Error(err))
+	}
+}
+
+func (s *InGameState) cmdDesync(args []string) error {
+	switch {
+	case len(args) == 0:
+		if s.lastDesync == nil {
+			s.addChatMessage("No desyncs detected")
+			return nil
+		}
+		s.addChatMessage(fmt.Sprintf("%d desyncs, last at %s: %.1f tiles",
+			s.desyncCount, s.lastDesync.Time.Format("15:04:05"), s.lastDesync.Drift))
+		return nil
+	case len(args) == 1 && args[0] == "save":
+		if s.lastDesync == nil {
+			return errors.New("no desync to save")
+		}
+		path, err := s.lastDesync.Write(s.manager.ReportDir)
+		if err != nil {
+			return err
+		}
+		s.addChatMessage("Desync saved to " + path)
+		return nil
+	}
+	return commands.ErrUsage
+}
@@ -0,0 +1,35 @@
+package states
+
+// groundHeight returns the height at a world position of the ground level
+// closest to near, so entities on a bridge or platform stay on it rather
+// than dropping to the floor under it. Without the map's GAT it's the
+// terrain's height.
+func (s *InGameState) groundHeight(x, z, near float32) float32 {
+	if s.ground != nil {
+		return s.ground.HeightAt(x, z, near)
+	}
+	if s.scene == nil || !s.MapLoaded {
+		return 0
+	}
+	return s.scene.GetTerrainHeight(x, z)
+}
+
+// unitHeight returns the ground height of another unit at a world
+// position: the level closest to the player's, since the units the server
+// shows are mostly on the player's floor.
+func (s *InGameState) unitHeight(x, z float32) float32 {
+	var near float32
+	if s.player != nil {
+		near = s.player.WorldY
+	}
+	return s.groundHeight(x, z, near)
+}
+
+// spawnHeight returns the height the player enters the map at: the GAT
+// surface the server placed them on.
+func (s *InGameState) spawnHeight(x, z float32) float32 {
+	if s.ground != nil {
+		return s.ground.Surface(x, z)
+	}
+	return s.groundHeight(x, z, 0)
+}
@@ -0,0 +1,90 @@
+package states
+
+import (
+	"time"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/clock"
+	"github.com/Faultbox/midgard-ro/internal/game/entity"
+)
+
+// awayMarker is the bubble shown over the player's head while away.
+const awayMarker = "AFK"
+
+// fidgetLength is how long a unit holds a fidget before standing idle
+// again.
+const fidgetLength = 1500 * time.Millisecond
+
+// How many actions player and monster (pet) sprites have before their
+// extras: any actions past these are played as idle fidgets.
+const (
+	playerActionCount  = 13
+	monsterActionCount = 5
+)
+
+// NoteActivity records the player touching the controls, which keeps them
+// from being marked away and brings them back if they were.
+func (s *InGameState) NoteActivity() {
+	s.idle.Activity(clock.Now())
+}
+
+// Away reports whether the player is marked away (AFK).
+func (s *InGameState) Away() bool {
+	return s.away
+}
+
+// updateIdle marks the player away once they've been inactive for the
+// configured time, and back on their next input. Requests sent count as
+// activity too. The away status stays local: this packet version has no
+// away flag to send, so other players don't see it.
+func (s *InGameState) updateIdle(now time.Time) {
+	s.idle.Activity(s.client.Stats().LastActiveAt)
+	away := s.idle.Away(now)
+	if away == s.away {
+		return
+	}
+	s.away = away
+	if away {
+		s.addChatMessage("You are now away (AFK).")
+	} else {
+		s.addChatMessage("You are back.")
+	}
+}
+
+// idleFidget returns the fidget a unit standing idle for idleFor plays
+// now, if it's time for one and the unit's body sprite has extra actions
+// to play. Fidgets take turns through the extras, staggered by unit ID so
+// a crowd doesn't fidget in step.
+func (s *InGameState) idleFidget(e *entity.Entity, idleFor time.Duration) (int, bool) {
+	interval := s.manager.FidgetInterval
+	if interval <= 0 {
+		return 0, false
+	}
+	idleFor += time.Duration(e.ID) * time.Second % interval
+	turn, ok := entity.FidgetTurn(idleFor, interval, fidgetLength)
+	if !ok {
+		return 0, false
+	}
+
+	var base int
+	switch e.Type {
+	case entity.TypePlayer:
+		base = playerActionCount
+	case entity.TypePet:
+		base = monsterActionCount
+	default:
+		return 0, false
+	}
+	layers := entity.SpriteLayers(e, 0, s.manager.SpriteFallbacks)
+	if len(layers) == 0 {
+		return 0, false
+	}
+	a := s.layerAsset(layers[0])
+	if a == nil {
+		return 0, false
+	}
+	extra := len(a.act.Actions)/8 - base
+	if extra <= 0 {
+		return 0, false
+	}
+	return base + turn%extra, true
+}
@@ -0,0 +1,97 @@
+package states
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/clock"
+	"github.com/Faultbox/midgard-ro/internal/game/entity"
+	"github.com/Faultbox/midgard-ro/internal/network"
+	"github.com/Faultbox/midgard-ro/internal/network/packets"
+)
+
+// inputInterval is the send queue's rate cap on attack requests, which
+// buffered attacks and skills keep to.
+var inputInterval = network.DefaultSendPolicies[packets.CZ_REQUEST_ACT2].MinInterval
+
+// AttackHovered attacks the unit under the mouse, if it can be attacked,
+// and reports whether it could, so the click doesn't also walk. During
+// the player's own attack the request waits in the input buffer.
+func (s *InGameState) AttackHovered() bool {
+	if !s.HoverAttackable() {
+		return false
+	}
+	if err := s.pressInput(entity.Input{Kind: entity.InputAttack, TargetID: s.hoverID}); err != nil {
+		s.addChatMessage(err.Error())
+	}
+	return true
+}
+
+// UseSkill uses a skill at a level on a unit, the player's own ID for self
+// skills. During the player's current action the request waits in the
+// input buffer.
+func (s *InGameState) UseSkill(skillID, level uint16, targetID uint32) error {
+	return s.pressInput(entity.Input{Kind: entity.InputSkill, SkillID: skillID, Level: level, TargetID: targetID})
+}
+
+// InputStats returns the input buffer's counts and the presses waiting in
+// it, for the debug overlay.
+func (s *InGameState) InputStats() (entity.InputStats, int) {
+	return s.input.Stats(), s.input.Len()
+}
+
+// pressInput sends an attack or skill request at once if the player is
+// free to act, or buffers it until the current action ends.
+func (s *InGameState) pressInput(in entity.Input) error {
+	if !s.input.Press(in, clock.Now()) {
+		return nil
+	}
+	return s.fireInput(in)
+}
+
+// updateInput sends the buffered request whose turn has come.
+func (s *InGameState) updateInput() {
+	in, ok := s.input.Next(clock.Now())
+	if !ok {
+		return
+	}
+	if err := s.fireInput(in); err != nil {
+		s.addChatMessage(err.Error())
+	}
+}
+
+// fireInput sends the request of a key press. An attack on a unit that
+// left view while it waited is dropped.
+func (s *InGameState) fireInput(in entity.Input) error {
+	switch in.Kind {
+	case entity.InputAttack:
+		if s.entityManager.Get(in.TargetID) == nil {
+			return nil
+		}
+		return s.sendAction(in.TargetID, packets.ActionAttack)
+	case entity.InputSkill:
+		return s.sendSkill(in.SkillID, in.Level, in.TargetID)
+	}
+	return nil
+}
+
+// beginAction marks the player busy until their attack or skill motion
+// ends, so buffered presses wait for it.
+func (s *InGameState) beginAction(sourceID uint32, end time.Time) {
+	if sourceID == s.entityManager.PlayerID() {
+		s.input.Begin(end)
+	}
+}
+
+// sendSkill sends CZ_USE_SKILL.
+func (s *InGameState) sendSkill(skillID, level uint16, targetID uint32) error {
+	if s.weight.Level() == entity.WeightOver90 {
+		return errors.New("you can't use skills while carrying over 90% of your weight limit")
+	}
+	pkt := &packets.SkillRequest{PacketID: packets.CZ_USE_SKILL, Level: level, SkillID: skillID, TargetID: targetID}
+	if err := s.client.Send(pkt.Encode()); err != nil {
+		return fmt.Errorf("send skill: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,384 @@
+package states
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/scene"
+	"github.com/Faultbox/midgard-ro/internal/game/entity"
+	"github.com/Faultbox/midgard-ro/internal/logger"
+	"github.com/Faultbox/midgard-ro/internal/network/packets"
+	"github.com/Faultbox/midgard-ro/pkg/math"
+)
+
+const (
+	// groundItemSize is the billboard size of a ground item in world units.
+	// Item sprites aren't loaded yet, so items are drawn as a small marker.
+	groundItemSize = 2.5
+
+	// subCellsPerCell is how finely ZC_ITEM_FALL_ENTRY places an item
+	// within its cell.
+	subCellsPerCell = 12
+
+	// itemRingRadius is the size of the loot ring under a ground item.
+	itemRingRadius = 2.5
+	itemRingFadeIn = 300 * time.Millisecond
+)
+
+var (
+	groundItemTint = [4]float32{0.7, 0.7, 1, 1}
+	itemRingColor  = [4]float32{1, 0.9, 0.5, 0.7}
+)
+
+// DropPrompt asks how many of a stackable item to drop.
+type DropPrompt struct {
+	Index int
+	Name  string
+	Max   int
+}
+
+func (s *InGameState) registerInventoryHandlers() {
+	s.client.RegisterHandler(packets.ZC_INVENTORY_ITEMLIST_NORMAL, s.handleInventoryNormal)
+	s.client.RegisterHandler(packets.ZC_INVENTORY_ITEMLIST_EQUIP, s.handleInventoryEquip)
+	s.client.RegisterHandler(packets.ZC_REQ_WEAR_EQUIP_ACK, s.handleWearEquipAck)
+	s.client.RegisterHandler(packets.ZC_REQ_TAKEOFF_EQUIP_ACK, s.handleTakeoffEquipAck)
+	s.client.RegisterHandler(packets.ZC_ITEM_THROW_ACK, s.handleItemThrowAck)
+	s.client.RegisterHandler(packets.ZC_ITEM_FALL_ENTRY, s.handleItemFallEntry)
+	s.client.RegisterHandler(packets.ZC_ITEM_DISAPPEAR, s.handleItemDisappear)
+	s.client.RegisterHandler(packets.ZC_ITEM_PICKUP_ACK, s.handleItemPickupAck)
+}
+
+// GetInventory returns the player's inventory.
+func (s *InGameState) GetInventory() *entity.Inventory {
+	return s.inventory
+}
+
+// RequestDrop starts dropping the item at index: stackables with more than
+// one item open the quantity prompt, anything else is dropped directly.
+func (s *InGameState) RequestDrop(index int) {
+	item, ok := s.inventory.Get(index)
+	if !ok {
+		return
+	}
+	if item.Equipped() {
+		s.addChatMessage("Unequip the item before dropping it.")
+		return
+	}
+	if item.Type.Stackable() && item.Amount > 1 {
+		s.dropPrompt = &DropPrompt{Index: index, Name: item.FullName(), Max: item.Amount}
+		return
+	}
+	if err := s.DropItem(index, 1); err != nil {
+		logger.Warn("drop item failed", zap.Int("index", index), zap.Error(err))
+	}
+}
+
+// GetDropPrompt returns the open quantity prompt, or nil.
+func (s *InGameState) GetDropPrompt() *DropPrompt {
+	return s.dropPrompt
+}
+
+// ConfirmDrop drops amount items of the prompted slot and closes the prompt.
+func (s *InGameState) ConfirmDrop(amount int) {
+	prompt := s.dropPrompt
+	s.dropPrompt = nil
+	if prompt == nil {
+		return
+	}
+	if err := s.DropItem(prompt.Index, amount); err != nil {
+		s.addChatMessage(fmt.Sprintf("Can't drop %s: %v", prompt.Name, err))
+	}
+}
+
+// CancelDrop closes the quantity prompt.
+func (s *InGameState) CancelDrop() {
+	s.dropPrompt = nil
+}
+
+// DropItem sends CZ_ITEM_THROW. The inventory is updated when the server
+// acknowledges the drop with ZC_ITEM_THROW_ACK.
+func (s *InGameState) DropItem(index, amount int) error {
+	item, ok := s.inventory.Get(index)
+	if !ok {
+		return fmt.Errorf("no item at index %d", index)
+	}
+	if item.Equipped() {
+		return errors.New("item is equipped")
+	}
+	if amount < 1 || amount > item.Amount {
+		return fmt.Errorf("amount %d out of range 1-%d", amount, item.Amount)
+	}
+
+	pkt := &packets.ItemThrow{
+		PacketID: packets.CZ_ITEM_THROW,
+		Index:    uint16(index),
+		Count:    uint16(amount),
+	}
+	if err := s.client.Send(pkt.Encode()); err != nil {
+		return fmt.Errorf("send item throw: %w", err)
+	}
+	return nil
+}
+
+// handleInventoryNormal processes ZC_INVENTORY_ITEMLIST_NORMAL, the list of
+// stackable and usable items sent on map entry.
+func (s *InGameState) handleInventoryNormal(data []byte) error {
+	items := packets.DecodeInventoryNormal(data)
+	for _, it := range items {
+		s.inventory.Set(entity.InventoryItem{
+			Index:      int(it.Index),
+			ItemID:     it.ItemID,
+			Type:       entity.ItemType(it.Type),
+			Amount:     int(it.Count),
+			Worn:       entity.EquipLocation(it.WearState),
+			Identified: it.Identified,
+		})
+	}
+	logger.Debug("inventory list", zap.Int("items", len(items)))
+	return nil
+}
+
+// handleInventoryEquip processes ZC_INVENTORY_ITEMLIST_EQUIP, the list of
+// equipment sent on map entry.
+func (s *InGameState) handleInventoryEquip(data []byte) error {
+	items := packets.DecodeInventoryEquip(data)
+	for _, it := range items {
+		s.inventory.Set(entity.InventoryItem{
+			Index:      int(it.Index),
+			ItemID:     it.ItemID,
+			Type:       entity.ItemType(it.Type),
+			Amount:     1,
+			Identified: it.Identified,
+			Damaged:    it.Damaged,
+			Location:   entity.EquipLocation(it.Location),
+			Worn:       entity.EquipLocation(it.WearState),
+			Refine:     int(it.Refine),
+			Cards:      it.Cards,
+		})
+	}
+	logger.Debug("equipment list", zap.Int("items", len(items)))
+	return nil
+}
+
+// EquipItem sends CZ_REQ_WEAR_EQUIP. The server takes off whatever the item
+// replaces, then answers with ZC_REQ_WEAR_EQUIP_ACK.
+func (s *InGameState) EquipItem(index int) error {
+	item, ok := s.inventory.Get(index)
+	if !ok {
+		return fmt.Errorf("no item at index %d", index)
+	}
+	if item.Location == 0 {
+		return errors.New("item can't be equipped")
+	}
+	if item.Equipped() {
+		return nil
+	}
+	pkt := &packets.WearEquip{
+		PacketID: packets.CZ_REQ_WEAR_EQUIP,
+		Index:    uint16(index),
+		Location: uint32(item.Location),
+	}
+	if err := s.client.Send(pkt.Encode()); err != nil {
+		return fmt.Errorf("send wear equip: %w", err)
+	}
+	return nil
+}
+
+// UnequipItem sends CZ_REQ_TAKEOFF_EQUIP for a worn item.
+func (s *InGameState) UnequipItem(index int) error {
+	item, ok := s.inventory.Get(index)
+	if !ok {
+		return fmt.Errorf("no item at index %d", index)
+	}
+	if !item.Equipped() {
+		return nil
+	}
+	pkt := &packets.TakeoffEquip{PacketID: packets.CZ_REQ_TAKEOFF_EQUIP, Index: uint16(index)}
+	if err := s.client.Send(pkt.Encode()); err != nil {
+		return fmt.Errorf("send takeoff equip: %w", err)
+	}
+	return nil
+}
+
+// handleWearEquipAck processes ZC_REQ_WEAR_EQUIP_ACK.
+func (s *InGameState) handleWearEquipAck(data []byte) error {
+	ack, ok := packets.DecodeEquipAck(data)
+	if !ok {
+		return fmt.Errorf("invalid ZC_REQ_WEAR_EQUIP_ACK: %d bytes", len(data))
+	}
+	switch ack.Result {
+	case packets.EquipOK:
+		if !s.inventory.SetWorn(int(ack.Index), entity.EquipLocation(ack.Location)) {
+			logger.Warn("equip ack for unknown inventory index", zap.Uint16("index", ack.Index))
+		}
+	case packets.EquipLevelTooLow:
+		s.addChatMessage("Your level is too low to equip that item.")
+	default:
+		s.addChatMessage("You can't equip that item.")
+	}
+	return nil
+}
+
+// handleTakeoffEquipAck processes ZC_REQ_TAKEOFF_EQUIP_ACK, also sent for
+// items swapped out by an equip.
+func (s *InGameState) handleTakeoffEquipAck(data []byte) error {
+	ack, ok := packets.DecodeEquipAck(data)
+	if !ok {
+		return fmt.Errorf("invalid ZC_REQ_TAKEOFF_EQUIP_ACK: %d bytes", len(data))
+	}
+	if ack.Result != packets.EquipOK {
+		s.addChatMessage("You can't take that item off.")
+		return nil
+	}
+	item, ok := s.inventory.Get(int(ack.Index))
+	if !ok {
+		logger.Warn("takeoff ack for unknown inventory index", zap.Uint16("index", ack.Index))
+		return nil
+	}
+	s.inventory.SetWorn(item.Index, item.Worn&^entity.EquipLocation(ack.Location))
+	return nil
+}
+
+// handleItemThrowAck processes ZC_ITEM_THROW_ACK — the server took the
+// dropped items out of our inventory.
+func (s *InGameState) handleItemThrowAck(data []byte) error {
+	ack := packets.DecodeItemThrowAck(data)
+	if ack == nil {
+		return fmt.Errorf("invalid ZC_ITEM_THROW_ACK: %d bytes", len(data))
+	}
+	if !s.inventory.Remove(int(ack.Index), int(ack.Count)) {
+		logger.Warn("throw ack for unknown inventory index", zap.Uint16("index", ack.Index))
+	}
+	return nil
+}
+
+// handleItemPickupAck processes ZC_ITEM_PICKUP_ACK — items were added to
+// our inventory, or a pickup failed.
+func (s *InGameState) handleItemPickupAck(data []byte) error {
+	ack := packets.DecodeItemPickupAck(data)
+	if ack == nil {
+		return fmt.Errorf("invalid ZC_ITEM_PICKUP_ACK: %d bytes", len(data))
+	}
+	switch ack.Result {
+	case packets.PickupOK:
+	case packets.PickupOverweight:
+		s.addChatMessage("You can't carry any more weight.")
+		return nil
+	case packets.PickupOverAmount, packets.PickupStackLimit:
+		s.addChatMessage("You can't carry any more of that item.")
+		return nil
+	default:
+		s.addChatMessage("You can't pick that item up.")
+		return nil
+	}
+
+	item, ok := s.inventory.Get(int(ack.Index))
+	if ok && item.ItemID == ack.ItemID {
+		item.Amount += int(ack.Amount)
+	} else {
+		item = entity.InventoryItem{
+			Index:      int(ack.Index),
+			ItemID:     ack.ItemID,
+			Type:       entity.ItemType(ack.Type),
+			Amount:     int(ack.Amount),
+			Identified: ack.Identified,
+			Damaged:    ack.Damaged,
+			Location:   entity.EquipLocation(ack.Location),
+			Refine:     int(ack.Refine),
+			Cards:      ack.Cards,
+		}
+	}
+	s.inventory.Set(item)
+	s.notify(Notice{Kind: NoticeItem, Text: item.FullName(), Key: fmt.Sprintf("item:%d", ack.ItemID), Count: int(ack.Amount)})
+	return nil
+}
+
+// handleItemFallEntry processes ZC_ITEM_FALL_ENTRY — an item landed on the
+// ground, either our own drop echoed back or one dropped nearby.
+func (s *InGameState) handleItemFallEntry(data []byte) error {
+	fall := packets.DecodeItemFallEntry(data)
+	if fall == nil {
+		return fmt.Errorf("invalid ZC_ITEM_FALL_ENTRY: %d bytes", len(data))
+	}
+
+	const tileSize = float32(5.0)
+	x := (float32(fall.X) + float32(fall.SubX)/subCellsPerCell) * tileSize
+	z := (float32(fall.Y) + float32(fall.SubY)/subCellsPerCell) * tileSize
+	y := s.surfaceHeight(x, z)
+
+	item := s.entityManager.Spawn(fall.ObjectID, entity.TypeItem)
+	item.SpriteID = int(fall.ItemID)
+	item.Name = entity.InventoryItem{
+		ItemID:     fall.ItemID,
+		Type:       entity.ItemType(fall.Type),
+		Identified: fall.Identified,
+	}.Name()
+	if fall.Count > 1 {
+		item.Name = fmt.Sprintf("%s x%d", item.Name, fall.Count)
+	}
+	item.SetPosition(x, y, z)
+	s.addItemRing(fall.ObjectID, x, z)
+	return nil
+}
+
+// addItemRing marks a ground item with a ring decal so it stands out from
+// the terrain.
+func (s *InGameState) addItemRing(id uint32, x, z float32) {
+	if s.scene == nil {
+		return
+	}
+	s.removeItemRing(id)
+	s.itemRings[id] = s.scene.SpawnDecal(scene.Decal{
+		Shape:  scene.DecalRing,
+		X:      x,
+		Z:      z,
+		Radius: itemRingRadius,
+		Color:  itemRingColor,
+		FadeIn: itemRingFadeIn,
+	})
+}
+
+func (s *InGameState) removeItemRing(id uint32) {
+	if ring, ok := s.itemRings[id]; ok {
+		if s.scene != nil {
+			s.scene.RemoveDecal(ring)
+		}
+		delete(s.itemRings, id)
+	}
+}
+
+// handleItemDisappear processes ZC_ITEM_DISAPPEAR — a ground item was
+// picked up or expired.
+func (s *InGameState) handleItemDisappear(data []byte) error {
+	id, ok := packets.DecodeItemDisappear(data)
+	if !ok {
+		return fmt.Errorf("invalid ZC_ITEM_DISAPPEAR: %d bytes", len(data))
+	}
+	s.entityManager.Vanish(id, 0, entity.VanishFade)
+	s.removeItemRing(id)
+	return nil
+}
+
+// renderGroundItems draws a marker billboard for each item on the ground.
+func (s *InGameState) renderGroundItems(viewProj, view math.Mat4) {
+	items := s.entityManager.GetByType(entity.TypeItem)
+	if len(items) == 0 {
+		return
+	}
+
+	camRight := math.Vec3{X: view[0], Y: view[4], Z: view[8]}
+	camUp := math.Vec3{X: view[1], Y: view[5], Z: view[9]}
+	tex := s.scene.FallbackTexture()
+	for _, item := range items {
+		if !item.IsVisible || item.Culled {
+			continue
+		}
+		pos := [3]float32{item.Position.X, item.Position.Y, item.Position.Z}
+		tint := groundItemTint
+		tint[3] *= item.Opacity()
+		s.scene.RenderSprite(viewProj, camRight, camUp, pos, groundItemSize, groundItemSize, tex, tint)
+	}
+}
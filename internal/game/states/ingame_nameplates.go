@@ -0,0 +1,166 @@
+package states
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/picking"
+	"github.com/Faultbox/midgard-ro/internal/game/entity"
+	"github.com/Faultbox/midgard-ro/internal/game/nameplate"
+	"github.com/Faultbox/midgard-ro/internal/logger"
+	"github.com/Faultbox/midgard-ro/internal/network/packets"
+)
+
+// hoverPickRadius is how far from the mouse (world units) a unit can stand
+// and still count as hovered, matching player picking.
+const hoverPickRadius = playerPickRadius
+
+// Nameplate is a unit's name in view, projected to the screen at its feet,
+// where RO draws names.
+type Nameplate struct {
+	ID               uint32
+	Kind             nameplate.Kind
+	ScreenX, ScreenY float32
+	Distance         float32 // From the player, world units
+	Hovered          bool
+	Name             string
+	Guild            string  // Guild name, "" if none or hidden
+	Position         string  // Guild position, "" if none or hidden
+	HP               float32 // HP fraction, or -1 for no HP bar
+	NameColor        [4]float32
+}
+
+func (s *InGameState) registerNameplateHandlers() {
+	s.client.RegisterHandler(packets.ZC_ACK_REQNAMEALL2, s.handleUnitNames)
+}
+
+// requestUnitNames asks the server for a player's guild and position
+// names, which the unit entry doesn't carry.
+func (s *InGameState) requestUnitNames(id uint32) {
+	pkt := &packets.AccountRequest{PacketID: packets.CZ_REQNAME2, AccountID: id}
+	if err := s.client.Send(pkt.Encode()); err != nil {
+		logger.Warn("name request send failed", zap.Uint32("id", id), zap.Error(err))
+	}
+}
+
+// handleUnitNames processes ZC_ACK_REQNAMEALL2 — a player's party, guild
+// and position names.
+func (s *InGameState) handleUnitNames(data []byte) error {
+	names := packets.DecodeUnitNames(data)
+	if names == nil {
+		return fmt.Errorf("invalid ZC_ACK_REQNAMEALL2: %d bytes", len(data))
+	}
+	e := s.entityManager.Get(names.ID)
+	if e == nil {
+		return nil
+	}
+	if names.Name != "" {
+		e.Name = names.Name
+	}
+	e.GuildName = names.Guild
+	e.Title = names.Position
+	return nil
+}
+
+// SetHover marks the unit under the mouse, whose name shows in hover mode.
+func (s *InGameState) SetHover(screenX, screenY, viewportW, viewportH float32) {
+	s.hoverID = 0
+	x, z, ok := s.screenToGround(screenX, screenY, viewportW, viewportH)
+	if !ok {
+		s.hideWalkIndicator()
+		return
+	}
+	s.showWalkIndicator(x, z)
+	best := float32(hoverPickRadius * hoverPickRadius)
+	for _, e := range s.entityManager.AllVisible() {
+		if _, ok := nameplateKind(e, 0); !ok {
+			continue
+		}
+		dx, dz := e.Position.X-x, e.Position.Z-z
+		if d := dx*dx + dz*dz; d <= best {
+			s.hoverID, best = e.ID, d
+		}
+	}
+}
+
+// ClearHover marks no unit as hovered, e.g. while the mouse is over a
+// window.
+func (s *InGameState) ClearHover() {
+	s.hoverID = 0
+	s.hideWalkIndicator()
+}
+
+// nameplateKind returns which nameplate rule applies to e, or false if
+// units of its type have no nameplate. Units fading out have none either.
+func nameplateKind(e *entity.Entity, playerID uint32) (nameplate.Kind, bool) {
+	if e.Leaving {
+		return 0, false
+	}
+	switch e.Type {
+	case entity.TypePlayer:
+		switch {
+		case e.ID == playerID:
+			return nameplate.KindSelf, true
+		case e.InParty:
+			return nameplate.KindParty, true
+		}
+		return nameplate.KindPlayer, true
+	case entity.TypeNPC:
+		return nameplate.KindNPC, true
+	case entity.TypeMonster:
+		return nameplate.KindMonster, true
+	}
+	return 0, false
+}
+
+// GetNameplates returns the nameplates the rules show, projected to a
+// viewportW x viewportH screen. Distance and overlap are left to
+// nameplate.Layout, which needs the drawn sizes.
+func (s *InGameState) GetNameplates(viewportW, viewportH float32, rules nameplate.Rules) []Nameplate {
+	if s.scene == nil {
+		return nil
+	}
+	player := s.entityManager.Player()
+	if player == nil {
+		return nil
+	}
+	viewProj := s.scene.LastViewProj()
+	var plates []Nameplate
+	for _, e := range s.entityManager.AllVisible() {
+		kind, ok := nameplateKind(e, player.ID)
+		if !ok || e.IsDead && kind != nameplate.KindSelf {
+			continue
+		}
+		hovered := e.ID == s.hoverID
+		if !rules.Shows(kind, hovered) {
+			continue
+		}
+		x, y, ok := picking.WorldToScreen([3]float32{e.Position.X, e.Position.Y, e.Position.Z}, viewportW, viewportH, viewProj)
+		if !ok {
+			continue
+		}
+		p := Nameplate{
+			ID:        e.ID,
+			Kind:      kind,
+			ScreenX:   x,
+			ScreenY:   y,
+			Distance:  e.Position.Distance(player.Position),
+			Hovered:   hovered,
+			Name:      e.Name,
+			HP:        -1,
+			NameColor: e.NameColor,
+		}
+		if e.Type == entity.TypePlayer && s.Attackable(e) {
+			p.NameColor = pvpNameColor
+		}
+		if rules.Guild && e.Type == entity.TypePlayer {
+			p.Guild, p.Position = e.GuildName, e.Title
+		}
+		if rules.PartyHP && kind == nameplate.KindParty && e.MaxHP > 0 {
+			p.HP = e.HPPercent()
+		}
+		plates = append(plates, p)
+	}
+	return plates
+}
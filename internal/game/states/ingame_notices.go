@@ -0,0 +1,101 @@
+package states
+
+import (
+	"fmt"
+
+	"github.com/Faultbox/midgard-ro/internal/network/packets"
+)
+
+// NoticeKind is what a notice is about.
+type NoticeKind uint8
+
+const (
+	NoticeItem   NoticeKind = iota // Item picked up
+	NoticeFriend                   // Friend came online
+	NoticeGuild                    // Guild member came online
+	NoticeQuest                    // Quest objective progress
+)
+
+// Notice is a transient event for the notification toasts.
+type Notice struct {
+	Kind  NoticeKind
+	Text  string
+	Key   string // Notices of a key merge, adding up Count; "" for none
+	Count int    // Items gained; 0 for a notice without a count
+}
+
+// maxNotices is how many notices wait to be taken; a flood while the game
+// isn't drawing drops the oldest.
+const maxNotices = 32
+
+func (s *InGameState) registerNoticeHandlers() {
+	s.client.RegisterHandler(packets.ZC_FRIENDS_STATE, s.handleFriendState)
+	s.client.RegisterHandler(packets.ZC_UPDATE_CHARSTAT2, s.handleGuildMemberState)
+	s.client.RegisterHandler(packets.ZC_UPDATE_MISSION_HUNT, s.handleMissionHunt)
+}
+
+// notify queues a notice, unless do-not-disturb is on.
+func (s *InGameState) notify(n Notice) {
+	if s.manager.DoNotDisturb {
+		return
+	}
+	if len(s.notices) >= maxNotices {
+		s.notices = s.notices[1:]
+	}
+	s.notices = append(s.notices, n)
+}
+
+// TakeNotices returns the notices since the last call, oldest first.
+func (s *InGameState) TakeNotices() []Notice {
+	notices := s.notices
+	s.notices = nil
+	return notices
+}
+
+// handleFriendState processes ZC_FRIENDS_STATE, sent as a friend logs in
+// or out.
+func (s *InGameState) handleFriendState(data []byte) error {
+	st := packets.DecodeFriendState(data)
+	if st == nil {
+		return fmt.Errorf("invalid ZC_FRIENDS_STATE: %d bytes", len(data))
+	}
+	if st.Online {
+		s.notify(Notice{Kind: NoticeFriend, Text: st.Name + " is now online.", Key: fmt.Sprintf("friend:%d", st.CharID)})
+	}
+	return nil
+}
+
+// handleGuildMemberState processes ZC_UPDATE_CHARSTAT2, sent as a guild
+// member logs in or out. The packet carries no name, so it's taken from
+// the member's unit when in view.
+func (s *InGameState) handleGuildMemberState(data []byte) error {
+	st := packets.DecodeGuildMemberState(data)
+	if st == nil {
+		return fmt.Errorf("invalid ZC_UPDATE_CHARSTAT2: %d bytes", len(data))
+	}
+	if !st.Online || st.AccountID == s.entityManager.PlayerID() {
+		return nil
+	}
+	text := "A guild member is now online."
+	if e := s.entityManager.Get(st.AccountID); e != nil && e.Name != "" {
+		text = e.Name + " is now online."
+	}
+	s.notify(Notice{Kind: NoticeGuild, Text: text, Key: fmt.Sprintf("guild:%d", st.CharID)})
+	return nil
+}
+
+// handleMissionHunt processes ZC_UPDATE_MISSION_HUNT, sent as kills count
+// toward quest objectives. Each objective's notice shows its latest count.
+func (s *InGameState) handleMissionHunt(data []byte) error {
+	if len(data) < 6 {
+		return fmt.Errorf("invalid ZC_UPDATE_MISSION_HUNT: %d bytes", len(data))
+	}
+	for _, h := range packets.DecodeMissionHunt(data) {
+		text := fmt.Sprintf("Quest %d: %d/%d", h.QuestID(), h.Count, h.MaxCount)
+		if h.Count >= h.MaxCount {
+			text += " complete"
+		}
+		s.notify(Notice{Kind: NoticeQuest, Text: text, Key: fmt.Sprintf("quest:%d", h.HuntID)})
+	}
+	return nil
+}
@@ -0,0 +1,338 @@
+package states
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/clock"
+	"github.com/Faultbox/midgard-ro/internal/logger"
+	"github.com/Faultbox/midgard-ro/internal/network/packets"
+)
+
+// cutinFade is how long an NPC illustration takes to fade in or out.
+const cutinFade = 300 * time.Millisecond
+
+// Cutin is an NPC illustration on screen.
+type Cutin struct {
+	Image    string  // Image name, without folder and usually extension
+	Position uint8   // packets.Cutin* placement
+	Alpha    float32 // Fade, 0 to 1
+}
+
+// cutinState is the illustration shown, fading in since shown or out
+// since hidden.
+type cutinState struct {
+	image    string
+	position uint8
+	shown    time.Time
+	hidden   time.Time // Zero while shown
+}
+
+// NPC dialog text appears dialogTypeRate characters per second, unless
+// the instant text setting is on. Holding the advance key moves past
+// finished pages after dialogAutoAdvanceDelay seconds.
+const (
+	dialogTypeRate         = 60.0
+	dialogAutoAdvanceDelay = 0.3
+)
+
+// maxDialogHistory caps the lines kept in the dialog history.
+const maxDialogHistory = 200
+
+// DialogButton is what moves an NPC dialog past its page.
+type DialogButton int
+
+const (
+	DialogNoButton DialogButton = iota // The NPC is still talking
+	DialogNext
+	DialogClose
+)
+
+// NPCDialog is the NPC dialog window: the page typed so far.
+type NPCDialog struct {
+	Name   string
+	Text   string // Typed so far
+	Typing bool   // Text isn't complete yet
+	Button DialogButton
+}
+
+// DialogLine is a line of an NPC dialog the player moved past.
+type DialogLine struct {
+	Name string
+	Text string
+}
+
+// npcDialog is the dialog with an NPC: its page's lines and how many of
+// their characters are typed.
+type npcDialog struct {
+	npcID  uint32
+	name   string
+	lines  []string
+	typed  float64 // Characters typed of the page
+	button DialogButton
+	waited float64 // Seconds the finished page has been up
+}
+
+// length returns the number of characters on the page.
+func (d *npcDialog) length() int {
+	n := 0
+	for _, line := range d.lines {
+		n += len([]rune(line)) + 1
+	}
+	return n
+}
+
+// text returns the first n characters of the page.
+func (d *npcDialog) text(n int) string {
+	var b strings.Builder
+	for i, line := range d.lines {
+		if i > 0 {
+			if n == 0 {
+				break
+			}
+			b.WriteByte('\n')
+			n--
+		}
+		runes := []rune(line)
+		if n < len(runes) {
+			b.WriteString(string(runes[:n]))
+			break
+		}
+		b.WriteString(line)
+		n -= len(runes)
+	}
+	return b.String()
+}
+
+func (s *InGameState) registerNPCHandlers() {
+	s.client.RegisterHandler(packets.ZC_SAY_DIALOG, s.handleSayDialog)
+	s.client.RegisterHandler(packets.ZC_WAIT_DIALOG, s.handleWaitDialog)
+	s.client.RegisterHandler(packets.ZC_SHOW_IMAGE2, s.handleShowImage)
+	s.client.RegisterHandler(packets.ZC_CLOSE_DIALOG, s.handleCloseDialog)
+}
+
+// handleSayDialog processes ZC_SAY_DIALOG — a line of an NPC's dialog,
+// added to its page. The camera eases toward the NPC for the length of
+// the dialog.
+func (s *InGameState) handleSayDialog(data []byte) error {
+	id, msg, ok := packets.DecodeSayDialog(data)
+	if !ok {
+		return fmt.Errorf("invalid ZC_SAY_DIALOG: %d bytes", len(data))
+	}
+	if s.dialog == nil || s.dialog.npcID != id {
+		s.dialog = &npcDialog{npcID: id, name: s.npcName(id)}
+	}
+	s.dialog.lines = append(s.dialog.lines, stripColorCodes(msg))
+	s.startDialogZoom(id)
+	return nil
+}
+
+// handleWaitDialog processes ZC_WAIT_DIALOG — the page ends with Next.
+func (s *InGameState) handleWaitDialog(data []byte) error {
+	id, ok := packets.DecodeWaitDialog(data)
+	if !ok {
+		return fmt.Errorf("invalid ZC_WAIT_DIALOG: %d bytes", len(data))
+	}
+	if s.dialog != nil && s.dialog.npcID == id {
+		s.dialog.button = DialogNext
+	}
+	return nil
+}
+
+// handleShowImage processes ZC_SHOW_IMAGE2 — an NPC shows its
+// illustration, or clears it.
+func (s *InGameState) handleShowImage(data []byte) error {
+	image, pos, ok := packets.DecodeShowImage(data)
+	if !ok {
+		return fmt.Errorf("invalid ZC_SHOW_IMAGE2: %d bytes", len(data))
+	}
+	logger.Debug("cutin", zap.String("image", image), zap.Uint8("position", pos))
+	if pos == packets.CutinClear || image == "" {
+		s.hideCutin()
+		return nil
+	}
+	c := &s.cutin
+	if c.image == image && c.position == pos && c.hidden.IsZero() {
+		return nil
+	}
+	*c = cutinState{image: image, position: pos, shown: clock.Now()}
+	return nil
+}
+
+// handleCloseDialog processes ZC_CLOSE_DIALOG — the NPC dialog ends with
+// Close. Closing it takes its illustration and camera zoom with it; with
+// no page to read, it ends at once.
+func (s *InGameState) handleCloseDialog(data []byte) error {
+	id, ok := packets.DecodeCloseDialog(data)
+	if !ok {
+		return fmt.Errorf("invalid ZC_CLOSE_DIALOG: %d bytes", len(data))
+	}
+	if s.dialog != nil && s.dialog.npcID == id && len(s.dialog.lines) > 0 {
+		s.dialog.button = DialogClose
+		return nil
+	}
+	s.sendDialogClose(id)
+	s.endDialog()
+	return nil
+}
+
+// GetNPCDialog returns the NPC dialog window, or nil if no NPC is talking.
+func (s *InGameState) GetNPCDialog() *NPCDialog {
+	d := s.dialog
+	if d == nil || len(d.lines) == 0 {
+		return nil
+	}
+	typed := min(int(d.typed), d.length())
+	dialog := &NPCDialog{Name: d.name, Text: d.text(typed), Typing: typed < d.length()}
+	if !dialog.Typing {
+		dialog.Button = d.button
+	}
+	return dialog
+}
+
+// DialogHistory returns the lines of NPC dialogs the player moved past,
+// oldest first.
+func (s *InGameState) DialogHistory() []DialogLine {
+	return s.dialogHistory
+}
+
+// AdvanceDialog completes the page being typed, or presses its button.
+func (s *InGameState) AdvanceDialog() {
+	d := s.dialog
+	if d == nil || len(d.lines) == 0 {
+		return
+	}
+	if int(d.typed) < d.length() {
+		d.typed = float64(d.length())
+		return
+	}
+	switch d.button {
+	case DialogNext:
+		s.recordDialog(d)
+		*d = npcDialog{npcID: d.npcID, name: d.name}
+		pkt := &packets.AccountRequest{PacketID: packets.CZ_REQ_NEXT_SCRIPT, AccountID: d.npcID}
+		if err := s.client.Send(pkt.Encode()); err != nil {
+			logger.Warn("dialog next send failed", zap.Error(err))
+		}
+	case DialogClose:
+		s.recordDialog(d)
+		s.sendDialogClose(d.npcID)
+		s.endDialog()
+	}
+}
+
+// SetDialogAutoAdvance sets whether the advance key is held, moving past
+// pages with Next as soon as they are typed.
+func (s *InGameState) SetDialogAutoAdvance(held bool) {
+	s.dialogAutoAdvance = held
+}
+
+// updateDialog types the page and, while the advance key is held, moves
+// past it once finished.
+func (s *InGameState) updateDialog(dt float64) {
+	d := s.dialog
+	if d == nil || len(d.lines) == 0 {
+		return
+	}
+	if s.manager.InstantDialogText || s.dialogAutoAdvance {
+		d.typed = float64(d.length())
+	} else {
+		d.typed += dt * dialogTypeRate
+	}
+	if int(d.typed) < d.length() || d.button != DialogNext || !s.dialogAutoAdvance {
+		d.waited = 0
+		return
+	}
+	d.waited += dt
+	if d.waited >= dialogAutoAdvanceDelay {
+		s.AdvanceDialog()
+	}
+}
+
+// recordDialog adds a finished page to the dialog history.
+func (s *InGameState) recordDialog(d *npcDialog) {
+	for _, line := range d.lines {
+		s.dialogHistory = append(s.dialogHistory, DialogLine{Name: d.name, Text: line})
+	}
+	if over := len(s.dialogHistory) - maxDialogHistory; over > 0 {
+		s.dialogHistory = slices.Delete(s.dialogHistory, 0, over)
+	}
+}
+
+// sendDialogClose tells the server the player closed the NPC's dialog.
+func (s *InGameState) sendDialogClose(npcID uint32) {
+	pkt := &packets.AccountRequest{PacketID: packets.CZ_CLOSE_DIALOG, AccountID: npcID}
+	if err := s.client.Send(pkt.Encode()); err != nil {
+		logger.Warn("dialog close send failed", zap.Error(err))
+	}
+}
+
+// endDialog closes the dialog window with its illustration and camera
+// zoom.
+func (s *InGameState) endDialog() {
+	s.dialog = nil
+	s.hideCutin()
+	s.endDialogZoom()
+}
+
+// npcName returns the name an NPC shows in its dialog, without the
+// "#suffix" scripts use to tell NPCs of the same name apart.
+func (s *InGameState) npcName(id uint32) string {
+	e := s.entityManager.Get(id)
+	if e == nil {
+		return ""
+	}
+	name, _, _ := strings.Cut(e.Name, "#")
+	return name
+}
+
+// stripColorCodes removes the ^RRGGBB color codes of NPC text.
+func stripColorCodes(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] == '^' && i+7 <= len(text) && isHex(text[i+1:i+7]) {
+			i += 6
+			continue
+		}
+		b.WriteByte(text[i])
+	}
+	return b.String()
+}
+
+// isHex reports whether s is all hexadecimal digits.
+func isHex(s string) bool {
+	for _, c := range []byte(s) {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// hideCutin starts fading out the illustration shown.
+func (s *InGameState) hideCutin() {
+	if s.cutin.image != "" && s.cutin.hidden.IsZero() {
+		s.cutin.hidden = clock.Now()
+	}
+}
+
+// Cutin returns the NPC illustration to draw, or nil if there's none.
+func (s *InGameState) Cutin() *Cutin {
+	c := &s.cutin
+	if c.image == "" {
+		return nil
+	}
+	alpha := min(float32(clock.Since(c.shown))/float32(cutinFade), 1)
+	if !c.hidden.IsZero() {
+		alpha = min(alpha, 1-float32(clock.Since(c.hidden))/float32(cutinFade))
+		if alpha <= 0 {
+			*c = cutinState{}
+			return nil
+		}
+	}
+	return &Cutin{Image: c.image, Position: c.position, Alpha: alpha}
+}
@@ -0,0 +1,191 @@
+package states
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/Faultbox/midgard-ro/internal/game/entity"
+	"github.com/Faultbox/midgard-ro/internal/logger"
+	"github.com/Faultbox/midgard-ro/internal/network/packets"
+)
+
+const (
+	// playerPickRadius is how far from a click (world units) a player can
+	// stand and still be picked; sprites are drawn above their feet.
+	playerPickRadius = 7.5
+
+	// tradeRange is the maximum trade distance in cells (rAthena checks 2).
+	tradeRange = 2
+)
+
+// PlayerAction is an entry of the right-click menu on another player.
+type PlayerAction int
+
+const (
+	PlayerActionWhisper PlayerAction = iota
+	PlayerActionTrade
+	PlayerActionPartyInvite
+	PlayerActionViewEquip
+	PlayerActionBlock
+	PlayerActionUnblock
+)
+
+// String returns the menu label.
+func (a PlayerAction) String() string {
+	switch a {
+	case PlayerActionWhisper:
+		return "Whisper"
+	case PlayerActionTrade:
+		return "Request Trade"
+	case PlayerActionPartyInvite:
+		return "Invite to Party"
+	case PlayerActionViewEquip:
+		return "View Equipment"
+	case PlayerActionBlock:
+		return "Block"
+	case PlayerActionUnblock:
+		return "Unblock"
+	default:
+		return "Unknown"
+	}
+}
+
+// PlayerMenuEntry is a menu action and whether it can be used right now.
+type PlayerMenuEntry struct {
+	Action  PlayerAction
+	Enabled bool
+}
+
+// PlayerMenu is the open context menu for another player.
+type PlayerMenu struct {
+	TargetID         uint32
+	Name             string
+	ScreenX, ScreenY float32
+	Entries          []PlayerMenuEntry
+}
+
+// OpenPlayerMenu opens the context menu for the player under a right-click.
+// Returns false (closing any open menu) if no other player is there.
+func (s *InGameState) OpenPlayerMenu(screenX, screenY, viewportW, viewportH float32) bool {
+	s.playerMenu = nil
+
+	x, z, ok := s.screenToGround(screenX, screenY, viewportW, viewportH)
+	if !ok {
+		return false
+	}
+	target := s.entityManager.NearestOther(x, z, playerPickRadius, entity.TypePlayer)
+	if target == nil {
+		return false
+	}
+
+	s.playerMenu = &PlayerMenu{
+		TargetID: target.ID,
+		Name:     target.Name,
+		ScreenX:  screenX,
+		ScreenY:  screenY,
+		Entries:  s.playerMenuEntries(target),
+	}
+	return true
+}
+
+// playerMenuEntries lists the actions for target, greying out the ones the
+// server would refuse: name-based requests need the name (it arrives after
+// the spawn packet) and trades need the target within range.
+func (s *InGameState) playerMenuEntries(target *entity.Entity) []PlayerMenuEntry {
+	hasName := target.Name != ""
+
+	inTradeRange := false
+	if s.player != nil {
+		px, _, pz := s.player.Position()
+		const tileSize = float32(5.0)
+		dx := (target.Position.X - px) / tileSize
+		dz := (target.Position.Z - pz) / tileSize
+		inTradeRange = max(dx, -dx) <= tradeRange && max(dz, -dz) <= tradeRange
+	}
+
+	block := PlayerActionBlock
+	if s.blockedWhispers[target.Name] {
+		block = PlayerActionUnblock
+	}
+
+	return []PlayerMenuEntry{
+		{PlayerActionWhisper, hasName},
+		{PlayerActionTrade, inTradeRange && !target.IsDead},
+		{PlayerActionPartyInvite, hasName},
+		{PlayerActionViewEquip, true}, // The server decides whether it's allowed
+		{block, hasName},
+	}
+}
+
+// GetPlayerMenu returns the open player context menu, or nil.
+func (s *InGameState) GetPlayerMenu() *PlayerMenu {
+	return s.playerMenu
+}
+
+// ClosePlayerMenu closes the player context menu.
+func (s *InGameState) ClosePlayerMenu() {
+	s.playerMenu = nil
+}
+
+// RunPlayerAction performs a menu action on the menu's target and closes
+// the menu.
+func (s *InGameState) RunPlayerAction(action PlayerAction) {
+	menu := s.playerMenu
+	s.playerMenu = nil
+	if menu == nil {
+		return
+	}
+
+	var pkt []byte
+	switch action {
+	case PlayerActionWhisper:
+		// Leave the message to the player
+		s.chatDraft = fmt.Sprintf("/w %q ", menu.Name)
+		s.recentNames.Seen(menu.Name)
+		return
+	case PlayerActionTrade:
+		pkt = (&packets.AccountRequest{PacketID: packets.CZ_REQ_EXCHANGE_ITEM, AccountID: menu.TargetID}).Encode()
+		s.addChatMessage(fmt.Sprintf("Trade request sent to %s.", menu.displayName()))
+	case PlayerActionPartyInvite:
+		pkt = (&packets.PartyInvite{PacketID: packets.CZ_PARTY_JOIN_REQ, Name: menu.Name}).Encode()
+		s.addChatMessage(fmt.Sprintf("Party invitation sent to %s.", menu.Name))
+		s.recentNames.Seen(menu.Name)
+	case PlayerActionViewEquip:
+		pkt = (&packets.AccountRequest{PacketID: packets.CZ_EQUIPWIN_MICROSCOPE, AccountID: menu.TargetID}).Encode()
+	case PlayerActionBlock, PlayerActionUnblock:
+		pkt = s.setWhisperBlocked(menu.Name, action == PlayerActionBlock)
+	}
+
+	if err := s.client.Send(pkt); err != nil {
+		logger.Warn("player action send failed", zap.String("action", action.String()), zap.Error(err))
+	}
+}
+
+// setWhisperBlocked records a whisper block and returns the request packet.
+func (s *InGameState) setWhisperBlocked(name string, blocked bool) []byte {
+	setting := packets.WhisperAllow
+	if blocked {
+		setting = packets.WhisperBlock
+		s.blockedWhispers[name] = true
+		s.addChatMessage(fmt.Sprintf("Blocked whispers from %s.", name))
+	} else {
+		delete(s.blockedWhispers, name)
+		s.addChatMessage(fmt.Sprintf("Unblocked whispers from %s.", name))
+	}
+	return (&packets.WhisperSetting{PacketID: packets.CZ_SETTING_WHISPER_PC, Name: name, Type: setting}).Encode()
+}
+
+// TakeChatDraft returns text to prefill the chat input with, once.
+func (s *InGameState) TakeChatDraft() string {
+	draft := s.chatDraft
+	s.chatDraft = ""
+	return draft
+}
+
+func (m *PlayerMenu) displayName() string {
+	if m.Name != "" {
+		return m.Name
+	}
+	return fmt.Sprintf("#%d", m.TargetID)
+}
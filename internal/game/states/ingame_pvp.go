@@ -0,0 +1,112 @@
+package states
+
+import (
+	"fmt"
+
+	"github.com/Faultbox/midgard-ro/internal/game/chat"
+	"github.com/Faultbox/midgard-ro/internal/game/entity"
+	"github.com/Faultbox/midgard-ro/internal/network/packets"
+)
+
+// pvpNameColor is the name color of players who can be attacked.
+var pvpNameColor = [4]float32{1, 0.35, 0.35, 1}
+
+// PVPStatus is the current map's PVP state for the HUD.
+type PVPStatus struct {
+	Mode  string // "PvP", "GvG" or "Battleground"; "" on safe maps
+	Rank  int    // The player's PVP rank, 0 if the map doesn't count kills
+	Total int    // Players ranked
+}
+
+func (s *InGameState) registerPVPHandlers() {
+	s.client.RegisterHandler(packets.ZC_MAPPROPERTY_R2, s.handleMapProperty)
+	s.client.RegisterHandler(packets.ZC_NOTIFY_RANKING, s.handlePVPRank)
+}
+
+// handleMapProperty processes ZC_MAPPROPERTY_R2 — the rules of the map
+// just entered: whether players can attack each other, and how.
+func (s *InGameState) handleMapProperty(data []byte) error {
+	prop, ok := packets.DecodeMapProperty(data)
+	if !ok {
+		return fmt.Errorf("invalid ZC_MAPPROPERTY_R2: %d bytes", len(data))
+	}
+	before := pvpMode(s.mapProperty)
+	s.mapProperty = prop
+	if !prop.Has(packets.MapFlagCountPK) {
+		s.pvpRank = packets.PVPRank{}
+	}
+	if mode := pvpMode(prop); mode != before {
+		if mode != "" {
+			s.addChat(chat.System, fmt.Sprintf("%s is enabled on this map: other players can attack you.", mode))
+		} else {
+			s.addChat(chat.System, "You are in a safe zone.")
+		}
+	}
+	return nil
+}
+
+// handlePVPRank processes ZC_NOTIFY_RANKING — the player's standing on a
+// PVP map, sent as kills change it.
+func (s *InGameState) handlePVPRank(data []byte) error {
+	rank, ok := packets.DecodePVPRank(data)
+	if !ok {
+		return fmt.Errorf("invalid ZC_NOTIFY_RANKING: %d bytes", len(data))
+	}
+	if rank.AccountID == s.entityManager.PlayerID() {
+		s.pvpRank = rank
+	}
+	return nil
+}
+
+// pvpMode names the kind of player fighting a map allows, or "" if none.
+func pvpMode(prop packets.MapProperty) string {
+	switch {
+	case prop.Has(packets.MapFlagBattlefield):
+		return "Battleground"
+	case prop.Has(packets.MapFlagGuild):
+		return "GvG"
+	case prop.Has(packets.MapFlagParty):
+		return "PvP"
+	}
+	return ""
+}
+
+// PVPStatus returns the current map's PVP state.
+func (s *InGameState) PVPStatus() PVPStatus {
+	return PVPStatus{Mode: pvpMode(s.mapProperty), Rank: s.pvpRank.Rank, Total: s.pvpRank.Total}
+}
+
+// Attackable reports whether the player can attack e: monsters anywhere,
+// and on PVP maps the players outside their party, or on GvG maps outside
+// their guild. On safe maps no player is.
+func (s *InGameState) Attackable(e *entity.Entity) bool {
+	if e == nil || e.IsDead {
+		return false
+	}
+	switch e.Type {
+	case entity.TypeMonster:
+		return true
+	case entity.TypePlayer:
+		player := s.entityManager.Player()
+		if player == nil || e.ID == player.ID {
+			return false
+		}
+		prop := s.mapProperty
+		if prop.Has(packets.MapFlagParty) && !e.InParty {
+			return true
+		}
+		if prop.Has(packets.MapFlagGuild) || prop.Has(packets.MapFlagBattlefield) {
+			return player.GuildID == 0 || e.GuildID != player.GuildID
+		}
+	}
+	return false
+}
+
+// HoverAttackable reports whether the unit under the mouse can be
+// attacked, which shows the attack cursor.
+func (s *InGameState) HoverAttackable() bool {
+	if s.hoverID == 0 {
+		return false
+	}
+	return s.Attackable(s.entityManager.Get(s.hoverID))
+}
@@ -0,0 +1,189 @@
+package states
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/Faultbox/midgard-ro/internal/logger"
+	"github.com/Faultbox/midgard-ro/internal/network/packets"
+)
+
+// Seconds a request waits for an answer before it is refused. The server
+// gives up on a trade sooner than on an invitation.
+const (
+	inviteTimeout = 30.0
+	tradeTimeout  = 20.0
+)
+
+// maxPendingRequests caps the request queue; further requests are refused
+// as they arrive, so a spammer can't bury the player in dialogs.
+const maxPendingRequests = 5
+
+// RequestKind is the kind of a yes/no request.
+type RequestKind int
+
+const (
+	RequestPartyInvite RequestKind = iota
+	RequestGuildInvite
+	RequestTrade
+	RequestRestart // Return to the save point after dying
+)
+
+// RequestDialog is a pending yes/no request. Requests are shown one at a
+// time, oldest first.
+type RequestDialog struct {
+	Kind    RequestKind
+	Message string
+	Timeout float64 // Seconds until the request is refused; 0 waits
+	Elapsed float64
+
+	answer func(accept bool) error // Sends the answer to the server
+}
+
+// Remaining returns the seconds left to answer, or 0 if the request waits.
+func (r *RequestDialog) Remaining() float64 {
+	if r.Timeout <= 0 {
+		return 0
+	}
+	return max(0, r.Timeout-r.Elapsed)
+}
+
+func (s *InGameState) registerRequestHandlers() {
+	s.client.RegisterHandler(packets.ZC_PARTY_JOIN_REQ, s.handlePartyInvite)
+	s.client.RegisterHandler(packets.ZC_REQ_JOIN_GUILD, s.handleGuildInvite)
+	s.client.RegisterHandler(packets.ZC_REQ_EXCHANGE_ITEM2, s.handleTradeRequest)
+}
+
+// GetRequestDialog returns the request to answer, or nil.
+func (s *InGameState) GetRequestDialog() *RequestDialog {
+	if len(s.requests) == 0 {
+		return nil
+	}
+	return s.requests[0]
+}
+
+// AnswerRequest answers the shown request and moves on to the next.
+func (s *InGameState) AnswerRequest(accept bool) {
+	if len(s.requests) == 0 {
+		return
+	}
+	req := s.requests[0]
+	s.requests = s.requests[1:]
+	if err := req.answer(accept); err != nil {
+		logger.Warn("request answer send failed", zap.Int("kind", int(req.Kind)), zap.Error(err))
+	}
+}
+
+// pushRequest queues a request, refusing it at once if the queue is full.
+func (s *InGameState) pushRequest(req *RequestDialog) {
+	if len(s.requests) >= maxPendingRequests {
+		if err := req.answer(false); err != nil {
+			logger.Warn("request refuse send failed", zap.Int("kind", int(req.Kind)), zap.Error(err))
+		}
+		return
+	}
+	s.requests = append(s.requests, req)
+}
+
+// updateRequests refuses the shown request when its time runs out. Only
+// the shown request's clock runs.
+func (s *InGameState) updateRequests(dt float64) {
+	req := s.GetRequestDialog()
+	if req == nil || req.Timeout <= 0 {
+		return
+	}
+	req.Elapsed += dt
+	if req.Elapsed >= req.Timeout {
+		s.AnswerRequest(false)
+	}
+}
+
+// dropRequests removes queued requests of a kind without answering them.
+func (s *InGameState) dropRequests(kind RequestKind) {
+	kept := s.requests[:0]
+	for _, req := range s.requests {
+		if req.Kind != kind {
+			kept = append(kept, req)
+		}
+	}
+	clear(s.requests[len(kept):])
+	s.requests = kept
+}
+
+// handlePartyInvite processes ZC_PARTY_JOIN_REQ.
+func (s *InGameState) handlePartyInvite(data []byte) error {
+	inv := packets.DecodeInvitation(data)
+	if inv == nil {
+		return fmt.Errorf("invalid ZC_PARTY_JOIN_REQ: %d bytes", len(data))
+	}
+	s.pushRequest(&RequestDialog{
+		Kind:    RequestPartyInvite,
+		Message: fmt.Sprintf("You have been invited to join the party '%s'.", inv.Name),
+		Timeout: inviteTimeout,
+		answer: func(accept bool) error {
+			pkt := &packets.PartyInviteReply{PacketID: packets.CZ_PARTY_JOIN_REQ_ACK, PartyID: inv.ID, Accept: accept}
+			return s.client.Send(pkt.Encode())
+		},
+	})
+	return nil
+}
+
+// handleGuildInvite processes ZC_REQ_JOIN_GUILD.
+func (s *InGameState) handleGuildInvite(data []byte) error {
+	inv := packets.DecodeInvitation(data)
+	if inv == nil {
+		return fmt.Errorf("invalid ZC_REQ_JOIN_GUILD: %d bytes", len(data))
+	}
+	s.pushRequest(&RequestDialog{
+		Kind:    RequestGuildInvite,
+		Message: fmt.Sprintf("You have been invited to join the guild '%s'.", inv.Name),
+		Timeout: inviteTimeout,
+		answer: func(accept bool) error {
+			pkt := &packets.GuildInviteReply{PacketID: packets.CZ_JOIN_GUILD, GuildID: inv.ID, Accept: accept}
+			return s.client.Send(pkt.Encode())
+		},
+	})
+	return nil
+}
+
+// handleTradeRequest processes ZC_REQ_EXCHANGE_ITEM2.
+func (s *InGameState) handleTradeRequest(data []byte) error {
+	req := packets.DecodeTradeRequest(data)
+	if req == nil {
+		return fmt.Errorf("invalid ZC_REQ_EXCHANGE_ITEM2: %d bytes", len(data))
+	}
+	s.pushRequest(&RequestDialog{
+		Kind:    RequestTrade,
+		Message: fmt.Sprintf("%s (level %d) wants to trade with you.", req.Name, req.Level),
+		Timeout: tradeTimeout,
+		answer: func(accept bool) error {
+			result := packets.TradeRefuse
+			if accept {
+				result = packets.TradeAccept
+			}
+			pkt := &packets.ByteRequest{PacketID: packets.CZ_ACK_EXCHANGE_ITEM, Value: result}
+			return s.client.Send(pkt.Encode())
+		},
+	})
+	return nil
+}
+
+// onPlayerDeath offers to return to the save point. Declining waits for a
+// resurrection; the offer is withdrawn if one comes.
+func (s *InGameState) onPlayerDeath() {
+	s.addChatMessage("You have died.")
+	s.input.Clear()
+	s.dropRequests(RequestRestart)
+	s.pushRequest(&RequestDialog{
+		Kind:    RequestRestart,
+		Message: "Return to your save point? Decline to wait for a resurrection.",
+		answer: func(accept bool) error {
+			if !accept {
+				return nil
+			}
+			pkt := &packets.ByteRequest{PacketID: packets.CZ_RESTART, Value: packets.RestartSavePoint}
+			return s.client.Send(pkt.Encode())
+		},
+	})
+}
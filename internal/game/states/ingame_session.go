@@ -0,0 +1,274 @@
+package states
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/clock"
+	"github.com/Faultbox/midgard-ro/internal/game/chat"
+	"github.com/Faultbox/midgard-ro/internal/game/commands"
+	"github.com/Faultbox/midgard-ro/internal/logger"
+	"github.com/Faultbox/midgard-ro/internal/network"
+	"github.com/Faultbox/midgard-ro/internal/network/packets"
+)
+
+func (s *InGameState) registerSessionHandlers() {
+	s.client.RegisterHandler(packets.SC_NOTIFY_BAN, s.handleKick)
+	s.client.RegisterHandler(packets.ZC_ACK_REQ_DISCONNECT, s.handleDisconnectAck)
+	s.client.RegisterHandler(packets.ZC_RESTART_ACK, s.handleRestartAck)
+	s.client.RegisterHandler(packets.ZC_BROADCAST, s.handleBroadcast)
+	s.client.RegisterHandler(packets.ZC_BROADCAST2, s.handleBroadcast2)
+}
+
+const (
+	// keepAliveInterval is how often CZ_REQUEST_TIME goes out; rAthena's
+	// map server times the session out around 30s of silence.
+	keepAliveInterval = 10 * time.Second
+
+	// keepAliveTimeout is how long a keep-alive may go unanswered before
+	// the server counts as not responding.
+	keepAliveTimeout = 5 * time.Second
+
+	// afkIdleTime is how long without sending anything but keep-alives
+	// makes a dropped session read as an idle kick.
+	afkIdleTime = 5 * time.Minute
+
+	// charSelectTimeout is how long a return to character select waits for
+	// the server before giving up.
+	charSelectTimeout = 10 * time.Second
+)
+
+// updateHeartbeat sends a keep-alive when one is due, and warns in chat
+// when the server stops answering them.
+func (s *InGameState) updateHeartbeat(now time.Time) {
+	if s.heartbeat.Due(now) {
+		pkt := &packets.TickSend{
+			PacketID:   packets.CZ_REQUEST_TIME,
+			ClientTick: uint32(now.Sub(s.enterTime).Milliseconds()),
+		}
+		if err := s.client.Send(pkt.Encode()); err != nil {
+			logger.Warn("keep-alive send failed", zap.Error(err))
+		} else {
+			s.heartbeat.Sent(now)
+		}
+	}
+
+	if !s.serverStalled && s.heartbeat.Stalled(now) {
+		s.serverStalled = true
+		logger.Warn("server not responding", zap.Duration("waiting", s.heartbeat.Unanswered(now)))
+		s.addChatMessage("The server is not responding. Waiting for it to catch up...")
+	}
+}
+
+// keepAliveAnswered times a ZC_NOTIFY_TIME reply, and clears the
+// not-responding warning once the server catches up.
+func (s *InGameState) keepAliveAnswered(now time.Time) {
+	if _, ok := s.heartbeat.Received(now); !ok {
+		return
+	}
+	if s.serverStalled && !s.heartbeat.Stalled(now) {
+		s.serverStalled = false
+		logger.Info("server responding again", zap.Duration("latency", s.heartbeat.Latency()))
+		s.addChatMessage("Connection to the server restored.")
+	}
+}
+
+// warnVersionMismatch tells the player, once, when the server's packets
+// keep failing to parse: the server likely uses another packetver.
+func (s *InGameState) warnVersionMismatch() {
+	if s.versionWarned || !s.client.Stats().VersionMismatch {
+		return
+	}
+	s.versionWarned = true
+	s.addChatMessage(network.MismatchWarning)
+}
+
+// Latency returns the smoothed keep-alive round trip, or 0 before the
+// first reply.
+func (s *InGameState) Latency() time.Duration {
+	return s.heartbeat.Latency()
+}
+
+// ServerStalled reports whether the server stopped answering keep-alives.
+func (s *InGameState) ServerStalled() bool {
+	return s.serverStalled
+}
+
+// idleFor returns how long the player has sent nothing but keep-alives.
+func (s *InGameState) idleFor(now time.Time) time.Duration {
+	last := s.client.Stats().LastActiveAt
+	if last.Before(s.enterTime) {
+		last = s.enterTime
+	}
+	return now.Sub(last)
+}
+
+// dropReason explains a dropped session from what led up to it: a server
+// that had stopped responding, or a player idle long enough for an AFK
+// kick. It returns "" when neither applies.
+func (s *InGameState) dropReason(now time.Time) string {
+	if s.serverStalled {
+		return "The server stopped responding."
+	}
+	if idle := s.idleFor(now); idle >= afkIdleTime {
+		return fmt.Sprintf("You were disconnected after %d minutes of inactivity.", int(idle.Minutes()))
+	}
+	return ""
+}
+
+// handleKick processes SC_NOTIFY_BAN. Servers send the timeout reason
+// for idle kicks too, so it's explained when the player was away.
+func (s *InGameState) handleKick(data []byte) error {
+	code, _ := packets.DecodeNotifyBan(data)
+	msg := s.manager.KickText(code)
+	if code == packets.BanTimeout {
+		if reason := s.dropReason(clock.Now()); reason != "" {
+			msg = reason
+		}
+	}
+	s.manager.disconnect(s.client, "Disconnected", msg)
+	return nil
+}
+
+// cmdQuit asks the server to log out. The server may refuse right after
+// combat; the answer comes back as ZC_ACK_REQ_DISCONNECT.
+func (s *InGameState) cmdQuit(args []string) error {
+	if len(args) != 0 {
+		return commands.ErrUsage
+	}
+	pkt := &packets.DisconnectRequest{PacketID: packets.CZ_REQ_DISCONNECT}
+	if err := s.client.Send(pkt.Encode()); err != nil {
+		return fmt.Errorf("logout request: %w", err)
+	}
+	return nil
+}
+
+// RequestCharSelect asks to return to character select, keeping the
+// session: the map server hands the account back to the char server and
+// answers with ZC_RESTART_ACK, or ZC_ACK_REQ_DISCONNECT when it refuses
+// during combat. Why it can't be asked now is explained in chat.
+func (s *InGameState) RequestCharSelect() {
+	switch {
+	case s.CharSelectPending():
+		s.addChatMessage("Already returning to character select...")
+		return
+	case s.vendingShop != nil:
+		s.addChatMessage("Close the shop before switching characters.")
+		return
+	}
+	pkt := &packets.ByteRequest{PacketID: packets.CZ_RESTART, Value: packets.RestartCharSelect}
+	if err := s.client.Send(pkt.Encode()); err != nil {
+		logger.Warn("character select send failed", zap.Error(err))
+		s.addChatMessage("Couldn't reach the server to switch characters.")
+		return
+	}
+	s.charSelectAt = clock.Now()
+	s.dropPrompt = nil
+	s.playerMenu = nil
+}
+
+// CharSelectPending reports whether a return to character select is
+// waiting for the server.
+func (s *InGameState) CharSelectPending() bool {
+	return !s.charSelectAt.IsZero()
+}
+
+// checkCharSelectTimeout gives up on a return to character select the
+// server never answered.
+func (s *InGameState) checkCharSelectTimeout(now time.Time) {
+	if s.charSelectAt.IsZero() || now.Sub(s.charSelectAt) < charSelectTimeout {
+		return
+	}
+	s.charSelectAt = time.Time{}
+	logger.Warn("character select request timed out")
+	s.addChatMessage("The server didn't answer. Try switching characters again.")
+}
+
+// handleRestartAck processes ZC_RESTART_ACK — the char server taking the
+// account back for character select. The map connection closes and the
+// client reconnects to the char server with the session it logged in with.
+func (s *InGameState) handleRestartAck(data []byte) error {
+	accepted, ok := packets.DecodeRestartAck(data)
+	if !ok {
+		return fmt.Errorf("invalid ZC_RESTART_ACK: %d bytes", len(data))
+	}
+	s.charSelectAt = time.Time{}
+	if !accepted {
+		s.addChatMessage("You can't return to character select right now.")
+		return nil
+	}
+	host, port := s.manager.charServer()
+	if host == "" {
+		s.manager.disconnect(s.client, "Logged out", "The character server address is unknown. Please log in again.")
+		return nil
+	}
+	logger.Info("returning to character select", zap.String("charServer", fmt.Sprintf("%s:%d", host, port)))
+	s.client.Disconnect()
+	s.manager.Change(NewConnectingState(ConnectingStateConfig{
+		NextState:  "charselect",
+		ServerHost: host,
+		ServerPort: port,
+	}, s.client, s.manager))
+	return nil
+}
+
+// cmdCharSelect returns to character select.
+func (s *InGameState) cmdCharSelect(args []string) error {
+	if len(args) != 0 {
+		return commands.ErrUsage
+	}
+	s.RequestCharSelect()
+	return nil
+}
+
+// handleDisconnectAck processes ZC_ACK_REQ_DISCONNECT — the answer to a
+// logout request, or a refused return to character select.
+func (s *InGameState) handleDisconnectAck(data []byte) error {
+	allowed, ok := packets.DecodeDisconnectAck(data)
+	if !ok {
+		return fmt.Errorf("invalid ZC_ACK_REQ_DISCONNECT: %d bytes", len(data))
+	}
+	if !allowed {
+		if s.CharSelectPending() {
+			s.charSelectAt = time.Time{}
+			s.addChatMessage("You can't switch characters during combat. Please wait a few seconds.")
+			return nil
+		}
+		s.addChatMessage("You can't log out during combat. Please wait a few seconds.")
+		return nil
+	}
+	s.manager.disconnect(s.client, "Logged out", "You have logged out.")
+	return nil
+}
+
+// handleBroadcast processes ZC_BROADCAST — a server-wide announcement,
+// such as a shutdown countdown, scrolled across the top of the screen
+// and kept in the chat log.
+func (s *InGameState) handleBroadcast(data []byte) error {
+	msg, ok := packets.DecodeBroadcast(data)
+	if !ok {
+		return fmt.Errorf("invalid ZC_BROADCAST: %d bytes", len(data))
+	}
+	s.announce(packets.PlainBroadcast(msg))
+	return nil
+}
+
+// handleBroadcast2 processes ZC_BROADCAST2 — an announcement in a color
+// and font size of the server's choosing.
+func (s *InGameState) handleBroadcast2(data []byte) error {
+	b, ok := packets.DecodeBroadcast2(data)
+	if !ok {
+		return fmt.Errorf("invalid ZC_BROADCAST2: %d bytes", len(data))
+	}
+	s.announce(b)
+	return nil
+}
+
+// announce logs an announcement to chat and queues its banner.
+func (s *InGameState) announce(b packets.Broadcast) {
+	logger.Info("server broadcast", zap.String("message", b.Message))
+	s.addChat(chat.Broadcast, b.Message)
+	s.banners.push(b, clock.Now())
+}
@@ -0,0 +1,546 @@
+package states
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/character"
+	"github.com/Faultbox/midgard-ro/internal/engine/clock"
+	"github.com/Faultbox/midgard-ro/internal/engine/scene"
+	"github.com/Faultbox/midgard-ro/internal/engine/sprite"
+	"github.com/Faultbox/midgard-ro/internal/game/entity"
+	"github.com/Faultbox/midgard-ro/internal/logger"
+	"github.com/Faultbox/midgard-ro/internal/network/packets"
+	"github.com/Faultbox/midgard-ro/pkg/formats"
+	"github.com/Faultbox/midgard-ro/pkg/grf"
+	"github.com/Faultbox/midgard-ro/pkg/math"
+)
+
+// unitSpriteScale converts unit sprite pixels to world units.
+const unitSpriteScale = 0.25
+
+// Sprite actions of players and of monsters (pets included).
+const (
+	actionIdle          = 0
+	actionWalk          = 1
+	actionPlayerSit     = 2
+	actionPlayerAttack  = 5
+	actionPlayerDead    = 8
+	actionMonsterAttack = 2
+	actionMonsterDead   = 4
+)
+
+// unitTypes maps the object types of unit entries to the entity types
+// shown in game. Other units (skills, homunculi, mercenaries) aren't yet.
+var unitTypes = map[uint8]entity.Type{
+	packets.UnitPC:       entity.TypePlayer,
+	packets.UnitNPC:      entity.TypeNPC,
+	packets.UnitEventNPC: entity.TypeNPC,
+	packets.UnitMonster:  entity.TypeMonster,
+	packets.UnitPet:      entity.TypePet,
+}
+
+// spriteAsset is a loaded SPR/ACT pair, shared by every unit using it.
+type spriteAsset struct {
+	spr *formats.SPR
+	act *formats.ACT
+}
+
+// unitSpriteKey is what a unit's composited sprite depends on; the sprite
+// is composited again when it changes.
+type unitSpriteKey struct {
+	dir, action       int
+	job, sprite, hair int
+	body, robe        int
+	male              bool
+	option            uint32
+	garmentFrame      int
+}
+
+// pose returns the key without the garment's frame, which changes as it
+// animates.
+func (k unitSpriteKey) pose() unitSpriteKey {
+	k.garmentFrame = 0
+	return k
+}
+
+// unitSprite is the composited sprite of a unit, whose texture is the
+// entity's Texture.
+type unitSprite struct {
+	key       unitSpriteKey
+	started   time.Time // When the unit took its pose, which garments animate from
+	idleSince time.Time // When the unit began standing idle, zero if it isn't
+	animAt    time.Time // When its frame last advanced
+	width     float32   // World units
+	height    float32
+	originX   float32 // Sprite origin within the texture, in world units
+	originY   float32
+}
+
+func (s *InGameState) registerUnitHandlers() {
+	s.client.RegisterHandler(packets.ZC_NOTIFY_STANDENTRY11, s.handleUnitEntry)
+	s.client.RegisterHandler(packets.ZC_NOTIFY_NEWENTRY11, s.handleUnitEntry)
+	s.client.RegisterHandler(packets.ZC_NOTIFY_MOVEENTRY11, s.handleUnitEntry)
+	s.client.RegisterHandler(packets.ZC_NOTIFY_VANISH, s.handleVanish)
+	s.client.RegisterHandler(packets.ZC_STATE_CHANGE3, s.handleStateChange)
+	s.client.RegisterHandler(packets.ZC_CHANGESTATE_PET, s.handlePetState)
+}
+
+// handleUnitEntry processes the unit entries — a player, NPC, monster or
+// pet came into view. Walking units are placed at their destination.
+func (s *InGameState) handleUnitEntry(data []byte) error {
+	u := packets.DecodeUnitEntry(data)
+	if u == nil {
+		return fmt.Errorf("invalid unit entry: %d bytes", len(data))
+	}
+	t, ok := unitTypes[u.ObjectType]
+	if !ok || (t == entity.TypePlayer && u.CharID == s.config.CharID) {
+		return nil
+	}
+
+	e := s.entityManager.Spawn(u.ID, t)
+	e.Name = u.Name
+	e.Job = int(u.Job)
+	e.SpriteID = int(u.Job)
+	e.HairStyle = int(u.Head)
+	e.HairColor = int(u.HeadPalette)
+	e.ClothesColor = int(u.BodyPalette)
+	e.BodyStyle = int(u.Body)
+	e.Robe = int(u.Robe)
+	e.Weapon = int(u.Weapon)
+	e.Shield = int(u.Shield)
+	e.HeadTop = int(u.HeadTop)
+	e.HeadMid = int(u.HeadMid)
+	e.HeadBottom = int(u.HeadBottom)
+	e.Male = u.Male
+	e.Option = u.Option
+	e.BodyState = u.BodyState
+	e.HealthState = u.HealthState
+	e.GuildID = u.GuildID
+	e.Level = int(u.Level)
+	e.HP = int(u.HP)
+	e.MaxHP = int(u.MaxHP)
+	e.Direction = u.Dir
+	switch u.State {
+	case packets.UnitStateDead:
+		e.IsDead = true
+		e.State = entity.StateDead
+	case packets.UnitStateSitting:
+		e.State = entity.StateSitting
+	}
+
+	x, y := u.X, u.Y
+	if u.Walking {
+		x, y = u.DestX, u.DestY
+	}
+	s.placeUnit(e, x, y)
+
+	if u.Spawned && t == entity.TypePlayer {
+		s.playWarp(e)
+	}
+	if t == entity.TypePet {
+		s.adoptPet(e)
+	}
+	if t == entity.TypePlayer && u.GuildID != 0 {
+		s.requestUnitNames(e.ID)
+	}
+	return nil
+}
+
+// placeUnit puts a unit on a tile, on the ground.
+func (s *InGameState) placeUnit(e *entity.Entity, x, y int) {
+	const tileSize = float32(5.0)
+	wx, wz := float32(x)*tileSize, float32(y)*tileSize
+	e.SetPosition(wx, s.unitHeight(wx, wz), wz)
+}
+
+// adoptPet sets the owner a newly seen pet follows: the player for their
+// own pet, otherwise the nearest other player, next to whom the server
+// spawns pets.
+func (s *InGameState) adoptPet(pet *entity.Entity) {
+	if pet.ID == s.petID {
+		pet.OwnerID = s.entityManager.PlayerID()
+		return
+	}
+	if owner := s.entityManager.NearestOther(pet.Position.X, pet.Position.Z, entity.PetFollowDistance, entity.TypePlayer); owner != nil {
+		pet.OwnerID = owner.ID
+	}
+}
+
+// handleVanish processes ZC_NOTIFY_VANISH — a unit left view, died,
+// logged out or teleported. Units fade out rather than pop: the dead lie
+// a while first, teleporters leave in a column of light.
+func (s *InGameState) handleVanish(data []byte) error {
+	id, reason, ok := packets.DecodeVanish(data)
+	if !ok {
+		return fmt.Errorf("invalid ZC_NOTIFY_VANISH: %d bytes", len(data))
+	}
+	logger.Debug("unit vanished", zap.Uint32("id", id), zap.Uint8("reason", reason))
+	e := s.entityManager.Get(id)
+	if e == nil {
+		return nil
+	}
+	switch reason {
+	case packets.VanishDied:
+		e.IsDead = true
+		e.State = entity.StateDead
+		s.entityManager.Vanish(id, entity.CorpseLinger, entity.CorpseFade)
+	case packets.VanishTeleported:
+		s.playWarp(e)
+		s.entityManager.Vanish(id, 0, entity.WarpFade)
+	default:
+		s.entityManager.Vanish(id, 0, entity.VanishFade)
+	}
+	return nil
+}
+
+// warpColumnTime is how long the column of light of a warp lasts.
+const warpColumnTime = 1200 * time.Millisecond
+
+// playWarp plays the column of light of a unit warping in or out.
+func (s *InGameState) playWarp(e *entity.Entity) {
+	if s.scene == nil {
+		return
+	}
+	pos := [3]float32{e.Position.X, e.Position.Y, e.Position.Z}
+	s.scene.BurstAura(scene.Aura{Styles: scene.AuraTeleport, Position: pos}, warpColumnTime)
+}
+
+// handleStateChange processes ZC_STATE_CHANGE3 — a unit's states or
+// option flags changed, e.g. it was frozen or mounted a Peco Peco. The
+// new flags take effect on the unit's next sprite.
+func (s *InGameState) handleStateChange(data []byte) error {
+	sc := packets.DecodeStateChange(data)
+	if sc == nil {
+		return fmt.Errorf("invalid ZC_STATE_CHANGE3: %d bytes", len(data))
+	}
+	if e := s.entityManager.Get(sc.ID); e != nil {
+		e.Option = sc.Option
+		e.BodyState, e.HealthState = sc.BodyState, sc.HealthState
+	}
+	return nil
+}
+
+// handlePetState processes ZC_CHANGESTATE_PET. Only ownership matters to
+// the client so far: the player's own pet follows the player.
+func (s *InGameState) handlePetState(data []byte) error {
+	ps := packets.DecodePetState(data)
+	if ps == nil {
+		return fmt.Errorf("invalid ZC_CHANGESTATE_PET: %d bytes", len(data))
+	}
+	if ps.Type != packets.PetStateOwned {
+		return nil
+	}
+	s.petID = ps.ID
+	if pet := s.entityManager.Get(ps.ID); pet != nil {
+		pet.OwnerID = s.entityManager.PlayerID()
+	}
+	return nil
+}
+
+// updateUnits moves pets after their owners.
+func (s *InGameState) updateUnits(dt float64) {
+	s.entityManager.FollowOwners(dt, s.unitHeight)
+}
+
+// unitScreenMargin is how far off screen, in normalized device units, a
+// unit's feet may be and its sprite still show.
+const unitScreenMargin = 0.3
+
+// updateUnitLOD sets the animation level of each unit for the frame, by
+// whether last frame's view showed it and how far it is from the player.
+func (s *InGameState) updateUnitLOD() {
+	clear(s.unitLOD)
+	s.animStats = sprite.AnimStats{}
+	viewProj := s.scene.LastViewProj()
+	player := s.entityManager.Player()
+	for _, e := range s.entityManager.AllVisible() {
+		if e == player || e.Type == entity.TypeItem {
+			continue
+		}
+		onScreen := sprite.OnScreen(viewProj, [3]float32{e.Position.X, e.Position.Y, e.Position.Z}, unitScreenMargin)
+		var dist float32
+		if player != nil {
+			dist = e.Position.Distance(player.Position)
+		}
+		level := s.animLOD.Level(onScreen, dist)
+		s.unitLOD[e.ID] = level
+		s.animStats.Count(level)
+	}
+}
+
+// AnimStats returns how many units animated at each level last frame, and
+// the composites generated, for the debug overlay.
+func (s *InGameState) AnimStats() sprite.AnimStats {
+	return s.animStats
+}
+
+// renderUnits draws the composited sprite of each unit in view. Units off
+// screen keep their last composite.
+func (s *InGameState) renderUnits(viewProj, view math.Mat4) {
+	camRight := math.Vec3{X: view[0], Y: view[4], Z: view[8]}
+	camUp := math.Vec3{X: view[1], Y: view[5], Z: view[9]}
+	player := s.entityManager.Player()
+	for _, e := range s.entityManager.AllVisible() {
+		if e == player || e.Type == entity.TypeItem {
+			continue
+		}
+		var sp *unitSprite
+		if level := s.unitLOD[e.ID]; level == sprite.AnimFrozen {
+			if sp = s.unitSprites[e.ID]; e.Texture == 0 {
+				sp = nil
+			}
+		} else {
+			sp = s.unitSprite(e, level)
+		}
+		if sp == nil {
+			continue
+		}
+
+		// Shift the bottom-center anchored quad so the sprite origin (the
+		// feet) lands on the unit's position, a little under it in water
+		dx := sp.width/2 - sp.originX
+		dy := sp.originY - sp.height - s.spriteSink(e.Position.X, e.Position.Z)
+		pos := [3]float32{
+			e.Position.X + camRight.X*dx + camUp.X*dy,
+			e.Position.Y + camRight.Y*dx + camUp.Y*dy,
+			e.Position.Z + camRight.Z*dx + camUp.Z*dy,
+		}
+		s.scene.RenderSprite(viewProj, camRight, camUp, pos, sp.width, sp.height, e.Texture, e.Tint())
+	}
+}
+
+// unitSprite returns the composited sprite of a unit as seen from the
+// camera, compositing it again if its look changed or its garment moved
+// on a frame. At a reduced animation level the garment's frames advance
+// less often. Returns nil if the unit has no known sprites.
+func (s *InGameState) unitSprite(e *entity.Entity, level sprite.AnimLevel) *unitSprite {
+	angle := character.CameraAngleToPlayer(s.camera.PosX, s.camera.PosZ, e.Position.X, e.Position.Z)
+	dir, _ := character.CalculateVisualDirection(angle, int(e.Direction), -1)
+	key := unitSpriteKey{
+		dir:    dir,
+		action: unitAction(e),
+		job:    e.Job,
+		sprite: e.SpriteID,
+		hair:   e.HairStyle,
+		body:   e.BodyStyle,
+		robe:   e.Robe,
+		male:   e.Male,
+		option: e.Option,
+	}
+	now := clock.Now()
+	started := now
+	prev := s.unitSprites[e.ID]
+
+	// Standing idle, the unit fidgets now and then
+	var idleSince time.Time
+	if key.action == actionIdle {
+		idleSince = now
+		if prev != nil && !prev.idleSince.IsZero() {
+			idleSince = prev.idleSince
+		}
+		if f, ok := s.idleFidget(e, now.Sub(idleSince)); ok {
+			key.action = f
+		}
+	}
+	if prev != nil && prev.key.pose() == key {
+		started = prev.started
+	}
+
+	// A garment plays its own ACT from the start of the body's action
+	advance := prev == nil || prev.key.pose() != key || s.animLOD.Due(level, prev.animAt, now)
+	var stack []sprite.Layer
+	if e.Robe != 0 && advance {
+		stack = s.spriteStack(entity.SpriteLayers(e, dir, s.manager.SpriteFallbacks))
+		for i := range stack {
+			if stack[i].Garment {
+				stack[i].Frame = sprite.FrameAt(stack[i].ACT, key.action, dir, now.Sub(started))
+				key.garmentFrame = stack[i].Frame
+			}
+		}
+	} else if e.Robe != 0 {
+		key.garmentFrame = prev.key.garmentFrame
+	}
+	if prev != nil && prev.key == key {
+		if advance {
+			prev.animAt = now
+		}
+		if e.Texture == 0 {
+			return nil
+		}
+		return prev
+	}
+
+	if e.Texture != 0 {
+		s.scene.EntityTextures().Release(e.Texture)
+		e.Texture = 0
+	}
+	sp := &unitSprite{key: key, started: started, idleSince: idleSince, animAt: now}
+	s.unitSprites[e.ID] = sp
+	s.animStats.Composited++
+
+	if stack == nil {
+		stack = s.spriteStack(entity.SpriteLayers(e, dir, s.manager.SpriteFallbacks))
+	}
+	result := sprite.CompositeLayers(stack, key.action, dir, 0)
+	e.Texture = s.scene.EntityTextures().Upload(result)
+	if e.Texture == 0 {
+		return nil
+	}
+	sp.width = float32(result.Width) * unitSpriteScale
+	sp.height = float32(result.Height) * unitSpriteScale
+	sp.originX = float32(result.OriginX) * unitSpriteScale
+	sp.originY = float32(result.OriginY) * unitSpriteScale
+	return sp
+}
+
+// unitAction returns the sprite action for a unit's state.
+func unitAction(e *entity.Entity) int {
+	switch {
+	case e.Type == entity.TypePlayer && e.State == entity.StateSitting:
+		return actionPlayerSit
+	case e.Type == entity.TypePlayer && e.IsDead:
+		return actionPlayerDead
+	case e.IsDead:
+		return actionMonsterDead
+	case e.State == entity.StateAttacking:
+		return attackAction(e)
+	case e.State == entity.StateWalking:
+		return actionWalk
+	}
+	return actionIdle
+}
+
+// attackAction returns the sprite action of a unit's attack.
+func attackAction(e *entity.Entity) int {
+	if e.Type == entity.TypePlayer {
+		return actionPlayerAttack
+	}
+	return actionMonsterAttack
+}
+
+// spriteStack loads the sprites of a layer stack, trying a layer's
+// fallbacks when its sprite is missing. Missing sprites are left out,
+// along with layers attached to them, so a unit with a missing head still
+// shows its body.
+func (s *InGameState) spriteStack(layers []entity.SpriteLayer) []sprite.Layer {
+	stack := make([]sprite.Layer, 0, len(layers))
+	baseLoaded := false
+	for _, l := range layers {
+		a := s.layerAsset(l)
+		if !l.Attach {
+			baseLoaded = a != nil
+		}
+		if a == nil || (l.Attach && !baseLoaded) {
+			continue
+		}
+		stack = append(stack, sprite.Layer{
+			SPR:        a.spr,
+			ACT:        a.act,
+			Attach:     l.Attach,
+			FirstFrame: l.Attach && !l.Garment,
+			Garment:    l.Garment,
+			Behind:     l.Behind,
+		})
+	}
+	return stack
+}
+
+// layerAsset loads a layer's sprite, or the first of its fallbacks found.
+// A missing sprite with fallbacks is noted for the debug overlay, once per
+// path.
+func (s *InGameState) layerAsset(l entity.SpriteLayer) *spriteAsset {
+	a := s.spriteAsset(l.Path)
+	if a != nil || len(l.Fallbacks) == 0 {
+		return a
+	}
+	used := ""
+	for _, path := range l.Fallbacks {
+		if a = s.spriteAsset(path); a != nil {
+			used = path
+			break
+		}
+	}
+	if _, seen := s.missingSprites[l.Path]; !seen {
+		s.missingSprites[l.Path] = used
+		logger.Warn("missing sprite", zap.String("path", l.Path), zap.String("fallback", used))
+	}
+	return a
+}
+
+// MissingSprites lists the sprites found missing with the fallback shown
+// instead, sorted, for the debug overlay.
+func (s *InGameState) MissingSprites() []string {
+	lines := make([]string, 0, len(s.missingSprites))
+	for path, used := range s.missingSprites {
+		if used == "" {
+			used = "nothing"
+		}
+		lines = append(lines, fmt.Sprintf("%s -> %s", path, used))
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+// ReloadAssets drops what the state made of edited data files so it loads
+// them again: unit sprites and the scene's model textures. names are
+// normalized file names (see grf.NormalizePath).
+func (s *InGameState) ReloadAssets(names []string) {
+	reloaded := 0
+	for path := range s.spriteAssets {
+		name := grf.NormalizePath(path)
+		if slices.Contains(names, name+".spr") || slices.Contains(names, name+".act") {
+			delete(s.spriteAssets, path)
+			delete(s.missingSprites, path)
+			reloaded++
+		}
+	}
+	if reloaded > 0 {
+		clear(s.unitSprites) // Composited again on their next frame
+	}
+	if s.scene != nil {
+		reloaded += s.scene.ReloadTextures(names)
+	}
+	logger.Info("reloaded edited assets", zap.Int("files", len(names)), zap.Int("reloaded", reloaded))
+}
+
+// spriteAsset loads and caches a sprite by its path without extension.
+// Sprites that fail to load are cached as nil and not tried again.
+func (s *InGameState) spriteAsset(path string) *spriteAsset {
+	if a, ok := s.spriteAssets[path]; ok {
+		return a
+	}
+	a, err := loadSpriteAsset(s.manager.TexLoader, path)
+	if err != nil {
+		logger.Debug("unit sprite not loaded", zap.String("path", path), zap.Error(err))
+	}
+	s.spriteAssets[path] = a
+	return a
+}
+
+func loadSpriteAsset(texLoader func(string) ([]byte, error), path string) (*spriteAsset, error) {
+	if texLoader == nil {
+		return nil, fmt.Errorf("no texture loader available")
+	}
+	sprData, err := texLoader(path + ".spr")
+	if err != nil {
+		return nil, fmt.Errorf("loading SPR: %w", err)
+	}
+	actData, err := texLoader(path + ".act")
+	if err != nil {
+		return nil, fmt.Errorf("loading ACT: %w", err)
+	}
+	spr, err := formats.ParseSPR(sprData)
+	if err != nil {
+		return nil, fmt.Errorf("parsing SPR: %w", err)
+	}
+	act, err := formats.ParseACT(actData)
+	if err != nil {
+		return nil, fmt.Errorf("parsing ACT: %w", err)
+	}
+	return &spriteAsset{spr: spr, act: act}, nil
+}
@@ -0,0 +1,294 @@
+package states
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+
+	"go.uber.org/zap"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/picking"
+	"github.com/Faultbox/midgard-ro/internal/engine/scene"
+	"github.com/Faultbox/midgard-ro/internal/game/entity"
+	"github.com/Faultbox/midgard-ro/internal/logger"
+	"github.com/Faultbox/midgard-ro/internal/network/packets"
+)
+
+// vendingBoardLift is how far above a vendor's feet (world units) the shop
+// title board sits, just over the sprite's head.
+const vendingBoardLift = 16
+
+// VendingShopItem is an item for sale in another player's shop.
+type VendingShopItem struct {
+	Index  int // Index in the vendor's cart, used to buy
+	ItemID uint32
+	Name   string
+	Price  int // Zeny per item
+	Amount int // Items for sale
+	Grade  entity.ItemGrade
+}
+
+// VendingShop is the open shop of another player.
+type VendingShop struct {
+	VendorID uint32
+	UniqueID uint32
+	Title    string
+	Items    []VendingShopItem
+}
+
+// Item returns the item at a cart index.
+func (v *VendingShop) Item(index int) (VendingShopItem, bool) {
+	for _, item := range v.Items {
+		if item.Index == index {
+			return item, true
+		}
+	}
+	return VendingShopItem{}, false
+}
+
+func (s *InGameState) registerVendingHandlers() {
+	s.client.RegisterHandler(packets.ZC_STORE_ENTRY, s.handleStoreEntry)
+	s.client.RegisterHandler(packets.ZC_DISAPPEAR_ENTRY, s.handleDisappearEntry)
+	s.client.RegisterHandler(packets.ZC_PC_PURCHASE_ITEMLIST_FROMMC2, s.handleVendingItemList)
+	s.client.RegisterHandler(packets.ZC_PC_PURCHASE_RESULT_FROMMC, s.handleVendingPurchaseResult)
+}
+
+// syncVendingBoards places the title board of each vendor in view over
+// their head. Boards of vendors that haven't spawned or are culled are
+// hidden.
+func (s *InGameState) syncVendingBoards() {
+	if s.scene == nil {
+		return
+	}
+	for id, title := range s.vendingBoards {
+		e := s.entityManager.Get(id)
+		if e == nil || !e.IsVisible || e.Culled {
+			s.scene.RemoveBoard(id)
+			continue
+		}
+		s.scene.SetBoard(id, scene.Board{
+			Kind:     scene.BoardVending,
+			Text:     title,
+			Position: [3]float32{e.Position.X, e.Position.Y + vendingBoardLift, e.Position.Z},
+		})
+	}
+}
+
+// OpenVendingAt opens the shop whose title board or vendor is under a
+// left-click. Returns false if there is none, so the click can move the
+// player instead.
+func (s *InGameState) OpenVendingAt(screenX, screenY, viewportW, viewportH float32) bool {
+	if len(s.vendingBoards) == 0 {
+		return false
+	}
+
+	if s.scene == nil || viewportW <= 0 || viewportH <= 0 {
+		return false
+	}
+	ray := picking.ScreenToRay(screenX, screenY, viewportW, viewportH, s.scene.LastViewProj().Inverse())
+	if id, ok := s.scene.PickBoard(ray); ok {
+		if _, vending := s.vendingBoards[id]; vending {
+			s.OpenVending(id)
+			return true
+		}
+	}
+
+	x, z, ok := s.screenToGround(screenX, screenY, viewportW, viewportH)
+	if !ok {
+		return false
+	}
+	vendor := s.entityManager.NearestOther(x, z, playerPickRadius, entity.TypePlayer)
+	if vendor == nil {
+		return false
+	}
+	if _, vending := s.vendingBoards[vendor.ID]; !vending {
+		return false
+	}
+	s.OpenVending(vendor.ID)
+	return true
+}
+
+// OpenVending asks the server for a vendor's items. The shop opens when
+// they arrive with ZC_PC_PURCHASE_ITEMLIST_FROMMC2.
+func (s *InGameState) OpenVending(vendorID uint32) {
+	pkt := &packets.AccountRequest{PacketID: packets.CZ_REQ_BUY_FROMMC, AccountID: vendorID}
+	if err := s.client.Send(pkt.Encode()); err != nil {
+		logger.Warn("open vending send failed", zap.Uint32("vendor", vendorID), zap.Error(err))
+	}
+}
+
+// GetVendingShop returns the open shop, or nil.
+func (s *InGameState) GetVendingShop() *VendingShop {
+	return s.vendingShop
+}
+
+// CloseVendingShop closes the open shop.
+func (s *InGameState) CloseVendingShop() {
+	s.vendingShop = nil
+}
+
+// GetZeny returns the player's zeny.
+func (s *InGameState) GetZeny() int64 {
+	return s.zeny
+}
+
+// ConfirmVendingPurchase buys the cart (amounts by item index) from the
+// open shop, reporting a refused purchase in chat.
+func (s *InGameState) ConfirmVendingPurchase(cart map[int]int) {
+	if err := s.BuyFromVendor(cart); err != nil {
+		s.addChatMessage(fmt.Sprintf("Can't buy: %v", err))
+	}
+}
+
+// BuyFromVendor sends CZ_PC_PURCHASE_ITEMLIST_FROMMC2 for the cart (amounts
+// by item index) and closes the shop, as the original client does. The
+// server only answers a failed purchase.
+func (s *InGameState) BuyFromVendor(cart map[int]int) error {
+	shop := s.vendingShop
+	if shop == nil {
+		return errors.New("no shop open")
+	}
+
+	var items []packets.VendingPurchaseItem
+	var total int64
+	for index, amount := range cart {
+		if amount == 0 {
+			continue
+		}
+		item, ok := shop.Item(index)
+		if !ok {
+			return fmt.Errorf("no item at index %d", index)
+		}
+		if amount < 0 || amount > item.Amount {
+			return fmt.Errorf("%s: amount %d out of range 1-%d", item.Name, amount, item.Amount)
+		}
+		total += int64(item.Price) * int64(amount)
+		items = append(items, packets.VendingPurchaseItem{Index: uint16(index), Amount: uint16(amount)})
+	}
+	if len(items) == 0 {
+		return errors.New("nothing selected")
+	}
+	if total > s.zeny {
+		return fmt.Errorf("not enough zeny (%d needed, %d carried)", total, s.zeny)
+	}
+	if s.weight.Max > 0 && s.weight.Left() == 0 {
+		return errors.New("you can't carry any more weight")
+	}
+	slices.SortFunc(items, func(a, b packets.VendingPurchaseItem) int { return cmp.Compare(a.Index, b.Index) })
+
+	pkt := &packets.VendingPurchase{
+		PacketID: packets.CZ_PC_PURCHASE_ITEMLIST_FROMMC2,
+		VendorID: shop.VendorID,
+		UniqueID: shop.UniqueID,
+		Items:    items,
+	}
+	if err := s.client.Send(pkt.Encode()); err != nil {
+		return fmt.Errorf("send purchase: %w", err)
+	}
+	s.vendingShop = nil
+	return nil
+}
+
+// handleStoreEntry processes ZC_STORE_ENTRY — a player opened a shop, or
+// came into view with one open.
+func (s *InGameState) handleStoreEntry(data []byte) error {
+	id, title, ok := packets.DecodeStoreEntry(data)
+	if !ok {
+		return fmt.Errorf("invalid ZC_STORE_ENTRY: %d bytes", len(data))
+	}
+	s.vendingBoards[id] = title
+	s.syncVendingBoards()
+	return nil
+}
+
+// handleDisappearEntry processes ZC_DISAPPEAR_ENTRY — a vendor closed their
+// shop. An open view of it closes too.
+func (s *InGameState) handleDisappearEntry(data []byte) error {
+	id, ok := packets.DecodeDisappearEntry(data)
+	if !ok {
+		return fmt.Errorf("invalid ZC_DISAPPEAR_ENTRY: %d bytes", len(data))
+	}
+	delete(s.vendingBoards, id)
+	if s.scene != nil {
+		s.scene.RemoveBoard(id)
+	}
+	if s.vendingShop != nil && s.vendingShop.VendorID == id {
+		s.vendingShop = nil
+		s.addChatMessage("The shop has closed.")
+	}
+	return nil
+}
+
+// handleVendingItemList processes ZC_PC_PURCHASE_ITEMLIST_FROMMC2 — the
+// contents of the shop we asked for.
+func (s *InGameState) handleVendingItemList(data []byte) error {
+	list := packets.DecodeVendingItemList(data)
+	if list == nil {
+		return fmt.Errorf("invalid ZC_PC_PURCHASE_ITEMLIST_FROMMC2: %d bytes", len(data))
+	}
+
+	shop := &VendingShop{
+		VendorID: list.VendorID,
+		UniqueID: list.UniqueID,
+		Title:    s.vendingBoards[list.VendorID],
+		Items:    make([]VendingShopItem, len(list.Items)),
+	}
+	for i, it := range list.Items {
+		item := entity.InventoryItem{
+			ItemID:     it.ItemID,
+			Type:       entity.ItemType(it.Type),
+			Identified: it.Identified,
+			Damaged:    it.Damaged,
+			Refine:     int(it.Refine),
+			Cards:      it.Cards,
+		}
+		name := item.FullName()
+		if slots := item.Slots(); slots > 0 {
+			name = fmt.Sprintf("%s [%d]", name, slots)
+		}
+		shop.Items[i] = VendingShopItem{
+			Index:  int(it.Index),
+			ItemID: it.ItemID,
+			Name:   name,
+			Price:  int(it.Price),
+			Amount: int(it.Amount),
+			Grade:  item.Grade(),
+		}
+	}
+	if shop.Title == "" {
+		shop.Title = "Shop"
+	}
+	s.vendingShop = shop
+	logger.Debug("vending shop", zap.Uint32("vendor", list.VendorID), zap.Int("items", len(shop.Items)))
+	return nil
+}
+
+// handleVendingPurchaseResult processes ZC_PC_PURCHASE_RESULT_FROMMC — the
+// vendor's server refused a purchase.
+func (s *InGameState) handleVendingPurchaseResult(data []byte) error {
+	res := packets.DecodeVendingPurchaseResult(data)
+	if res == nil {
+		return fmt.Errorf("invalid ZC_PC_PURCHASE_RESULT_FROMMC: %d bytes", len(data))
+	}
+
+	var msg string
+	switch res.Result {
+	case packets.PurchaseOK:
+		return nil
+	case packets.PurchaseNoZeny:
+		msg = "You don't have enough zeny."
+	case packets.PurchaseOverweight:
+		msg = "You can't carry that much weight."
+	case packets.PurchaseOutOfStock:
+		msg = "The shop doesn't have that many left."
+	case packets.PurchaseTrading:
+		msg = "You can't buy while trading."
+	case packets.PurchaseStoreChanged, packets.PurchaseNoSalesInfo:
+		msg = "The shop has changed. Open it again to see its items."
+	default:
+		msg = fmt.Sprintf("The purchase failed (%d).", res.Result)
+	}
+	s.addChatMessage(msg)
+	return nil
+}
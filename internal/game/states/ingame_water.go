@@ -0,0 +1,100 @@
+package states
+
+import (
+	"github.com/Faultbox/midgard-ro/internal/engine/scene"
+)
+
+const (
+	// waterSink is how far units' sprites sink into water cells.
+	waterSink = 1.5
+
+	// walkIndicatorRadius is half the size of the walk indicator, just
+	// inside a cell.
+	walkIndicatorRadius = 2.3
+)
+
+var (
+	walkIndicatorColor      = [4]float32{1, 1, 1, 0.35}
+	walkIndicatorWaterColor = [4]float32{0.3, 0.6, 1, 0.55}
+)
+
+// walkIndicator is the marker on the cell under the mouse: white on
+// ground, blue on water, hidden where a click can't walk.
+type walkIndicator struct {
+	decal scene.DecalID // 0 when hidden
+	cellX int
+	cellY int
+	water bool
+}
+
+// cellAt returns the cell containing a world position.
+func cellAt(x, z float32) (int, int) {
+	const tileSize = float32(5.0)
+	return int(x / tileSize), int(z / tileSize)
+}
+
+// onWater reports whether a world position is over a water cell.
+func (s *InGameState) onWater(x, z float32) bool {
+	cx, cy := cellAt(x, z)
+	return s.walk.IsWater(cx, cy)
+}
+
+// surfaceHeight returns the height things rest at: the ground, or the
+// water surface over water cells, where ground items float.
+func (s *InGameState) surfaceHeight(x, z float32) float32 {
+	if s.scene == nil {
+		return 0
+	}
+	y := s.unitHeight(x, z)
+	if water, ok := s.scene.WaterHeight(); ok && s.onWater(x, z) {
+		y = max(y, water)
+	}
+	return y
+}
+
+// spriteSink returns how far a sprite standing at a world position sinks:
+// a little into water, not at all elsewhere.
+func (s *InGameState) spriteSink(x, z float32) float32 {
+	if s.onWater(x, z) {
+		return waterSink
+	}
+	return 0
+}
+
+// showWalkIndicator moves the walk indicator to the cell at a world
+// position, hiding it if the cell can't be walked on.
+func (s *InGameState) showWalkIndicator(x, z float32) {
+	cx, cy := cellAt(x, z)
+	if s.scene == nil || !s.walk.IsWalkable(cx, cy) {
+		s.hideWalkIndicator()
+		return
+	}
+	water := s.walk.IsWater(cx, cy)
+	wi := &s.walkIndicator
+	if wi.decal != 0 && wi.cellX == cx && wi.cellY == cy && wi.water == water {
+		return
+	}
+	s.hideWalkIndicator()
+
+	color := walkIndicatorColor
+	if water {
+		color = walkIndicatorWaterColor
+	}
+	const tileSize = float32(5.0)
+	wi.decal = s.scene.SpawnDecal(scene.Decal{
+		Shape:  scene.DecalSquare,
+		X:      (float32(cx) + 0.5) * tileSize,
+		Z:      (float32(cy) + 0.5) * tileSize,
+		Radius: walkIndicatorRadius,
+		Color:  color,
+	})
+	wi.cellX, wi.cellY, wi.water = cx, cy, water
+}
+
+// hideWalkIndicator removes the walk indicator.
+func (s *InGameState) hideWalkIndicator() {
+	if s.walkIndicator.decal != 0 && s.scene != nil {
+		s.scene.RemoveDecal(s.walkIndicator.decal)
+	}
+	s.walkIndicator.decal = 0
+}
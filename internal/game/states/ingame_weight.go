@@ -0,0 +1,42 @@
+package states
+
+import (
+	"github.com/Faultbox/midgard-ro/internal/game/chat"
+	"github.com/Faultbox/midgard-ro/internal/game/entity"
+)
+
+// overweightMessages announce the player becoming more heavily loaded.
+var overweightMessages = map[entity.WeightLevel]string{
+	entity.WeightOver50: "You are carrying over half your weight limit. HP and SP no longer recover by themselves.",
+	entity.WeightOver90: "You are carrying over 90% of your weight limit. You can't attack or use skills.",
+}
+
+// setWeight updates the weight carried and its limit, announcing in chat
+// when the player becomes more heavily loaded. Nothing is announced until
+// the limit is known, so the load the player entered the map with isn't.
+func (s *InGameState) setWeight(w entity.Weight) {
+	prev := s.weight
+	s.weight = w
+	if prev.Max <= 0 {
+		return
+	}
+	if level := w.Level(); level > prev.Level() {
+		s.addChat(chat.System, overweightMessages[level])
+	}
+}
+
+// GetWeight returns the weight the player carries and the most they can.
+func (s *InGameState) GetWeight() entity.Weight {
+	return s.weight
+}
+
+// overweightBuff returns the icon of the player's overweight state, or
+// false at a normal weight. It stands in for the server's own status, so
+// the icon is right as soon as the weight is.
+func (s *InGameState) overweightBuff() (BuffIcon, bool) {
+	status, ok := s.weight.Status()
+	if !ok {
+		return BuffIcon{}, false
+	}
+	return BuffIcon{Name: entity.StatusName(status), Fraction: 1}, true
+}
@@ -8,9 +8,12 @@ import (
 
 	"go.uber.org/zap"
 
+	"github.com/Faultbox/midgard-ro/internal/engine/clock"
+	"github.com/Faultbox/midgard-ro/internal/engine/scene"
 	"github.com/Faultbox/midgard-ro/internal/logger"
 	"github.com/Faultbox/midgard-ro/internal/network"
 	"github.com/Faultbox/midgard-ro/internal/network/packets"
+	"github.com/Faultbox/midgard-ro/pkg/formats"
 )
 
 // LoadingStateConfig contains configuration for the loading state.
@@ -21,6 +24,11 @@ type LoadingStateConfig struct {
 	SpawnDir  uint8
 	CharID    uint32
 	TexLoader func(string) ([]byte, error) // Function to load textures from GRF
+
+	// On a map change, the scene of the map left. It keeps that map shown
+	// while the next one is preloaded into it, and goes on to the game
+	// state. Nil when entering the map server.
+	Scene *scene.Scene
 }
 
 // LoadingState handles map loading before entering the game.
@@ -38,6 +46,11 @@ type LoadingState struct {
 
 	// Loaded data (passed to InGame state)
 	MapLoaded bool
+	mapFiles  *mapFiles // Files of the map preloaded, nil if it wasn't
+
+	// Frames updated; a map change preloads once the loading screen has
+	// been drawn
+	frames int
 
 	// Timing
 	startTime time.Time
@@ -56,39 +69,61 @@ func NewLoadingState(cfg LoadingStateConfig, client *network.Client, manager *Ma
 
 // Enter is called when entering this state.
 func (s *LoadingState) Enter() error {
-	s.startTime = time.Now()
+	s.startTime = clock.Now()
 	s.ErrorMsg = ""
 	s.Progress = 0
 	s.IsComplete = false
 
 	logger.Info("entering LoadingState", zap.String("map", s.config.MapName))
 
+	// A map change stays on the map server: there's nothing to enter, the
+	// next map is preloaded in Update
+	if s.config.Scene != nil {
+		s.StatusMsg = fmt.Sprintf("Warping to %s", s.getDisplayMapName())
+		s.LoadingPhase = "loading"
+		return nil
+	}
+
 	// Register map server packet handlers
 	s.client.RegisterHandler(packets.ZC_ACCEPT_ENTER, s.handleMapAccept)
 	s.client.RegisterHandler(packets.ZC_ACCEPT_ENTER2, s.handleMapAccept) // Modern rAthena
+	s.client.RegisterHandler(packets.SC_NOTIFY_BAN, s.manager.kickHandler(s.client))
 
 	// Send map enter packet
 	return s.sendMapEnter()
 }
 
-// Exit is called when leaving this state.
+// Exit is called when leaving this state. A scene not handed on to the
+// game state, as on a disconnect, is released.
 func (s *LoadingState) Exit() error {
+	if s.config.Scene != nil {
+		s.config.Scene.Destroy()
+		s.config.Scene = nil
+	}
 	return nil
 }
 
 // Update is called every frame.
 func (s *LoadingState) Update(dt float64) error {
 	// Check for timeout
-	if time.Since(s.startTime) > 60*time.Second {
-		s.ErrorMsg = "Map loading timed out"
+	if clock.Since(s.startTime) > 60*time.Second {
+		s.manager.disconnect(s.client, "Connection failed", "The map server didn't answer in time.")
 		return nil
 	}
 
 	// Process network
 	if err := s.client.Process(); err != nil {
+		if s.manager.connectionLost(s.client, err) {
+			return nil
+		}
 		s.ErrorMsg = fmt.Sprintf("Network error: %v", err)
 	}
 
+	if s.config.Scene != nil && !s.IsComplete && s.frames > 0 {
+		s.preloadMap()
+	}
+	s.frames++
+
 	// Simulate loading progress for visual feedback
 	if !s.IsComplete && s.Progress < 0.95 {
 		s.Progress += float32(dt) * 0.5 // Progress over ~2 seconds
@@ -133,7 +168,7 @@ func (s *LoadingState) sendMapEnter() error {
 		AccountID:  accountID,
 		CharID:     charID,
 		LoginID1:   loginID1,
-		ClientTick: uint32(time.Now().UnixMilli() & 0xFFFFFFFF),
+		ClientTick: uint32(clock.Now().UnixMilli() & 0xFFFFFFFF),
 		Sex:        sex,
 		// Unknown bytes are zero-initialized
 	}
@@ -182,6 +217,27 @@ func (s *LoadingState) handleMapAccept(data []byte) error {
 	return nil
 }
 
+// preloadMap loads the next map of a map change into the scene alongside
+// the one left, then tells the server the client is ready. A map that
+// fails to load is left for the game state to load again and report.
+func (s *LoadingState) preloadMap() {
+	s.LoadingPhase = "spawning"
+	baseName := strings.TrimSuffix(s.config.MapName, ".gat")
+	files, err := readMapFiles(s.manager.TexLoader, baseName)
+	if err == nil {
+		err = s.config.Scene.PreloadMap(baseName, files.gnd, files.rsw, s.manager.TexLoader)
+	}
+	if err != nil {
+		logger.Warn("failed to preload map", zap.String("map", baseName), zap.Error(err))
+	} else {
+		s.mapFiles = files
+		s.MapLoaded = true
+	}
+
+	s.sendLoadingComplete()
+	s.IsComplete = true
+}
+
 func (s *LoadingState) sendLoadingComplete() {
 	pkt := &packets.LoadingComplete{
 		PacketID: packets.CZ_NOTIFY_ACTORINIT,
@@ -197,7 +253,10 @@ func (s *LoadingState) transitionToInGame() {
 		SpawnDir:  s.config.SpawnDir,
 		CharID:    s.config.CharID,
 		TexLoader: s.config.TexLoader,
+		Scene:     s.config.Scene,
+		mapFiles:  s.mapFiles,
 	}, s.client, s.manager))
+	s.config.Scene = nil // The game state's now
 }
 
 func (s *LoadingState) getDisplayMapName() string {
@@ -205,6 +264,68 @@ func (s *LoadingState) getDisplayMapName() string {
 	return strings.TrimSuffix(s.config.MapName, ".gat")
 }
 
+// mapFiles are the parsed files of a map. The GAT and RSW are nil when
+// missing or damaged.
+type mapFiles struct {
+	gat *formats.GAT
+	gnd *formats.GND
+	rsw *formats.RSW
+}
+
+// readMapFiles reads and parses the files of a map through load. A map
+// can't load without its GND, so failing to read it is an error; the
+// files returned then still hold the GAT.
+func readMapFiles(load func(string) ([]byte, error), baseName string) (*mapFiles, error) {
+	if load == nil {
+		return &mapFiles{}, fmt.Errorf("no texture loader available")
+	}
+	files := &mapFiles{}
+
+	// Load GAT (walkability + minimap shape).  Non-fatal — log and continue.
+	gatPath := "data\\" + baseName + ".gat"
+	if gatData, gatErr := load(gatPath); gatErr == nil {
+		if gat, parseErr := formats.ParseGAT(gatData); parseErr == nil {
+			files.gat = gat
+		} else {
+			logger.Warn("failed to parse GAT", zap.Error(parseErr))
+		}
+	} else {
+		logger.Warn("failed to load GAT", zap.Error(gatErr))
+	}
+
+	// Load GND (terrain)
+	gndPath := "data\\" + baseName + ".gnd"
+	gndData, err := load(gndPath)
+	if err != nil {
+		return files, fmt.Errorf("loading GND: %w", err)
+	}
+	gnd, err := formats.ParseGND(gndData)
+	if err != nil {
+		return files, fmt.Errorf("parsing GND: %w", err)
+	}
+	for _, w := range gnd.Warnings {
+		logger.Warn("damaged GND", zap.String("file", gndPath), zap.Stringer("skipped", w))
+	}
+	files.gnd = gnd
+
+	// Load RSW (map resources)
+	rswPath := "data\\" + baseName + ".rsw"
+	rswData, err := load(rswPath)
+	if err == nil {
+		files.rsw, err = formats.ParseRSW(rswData)
+		if err != nil {
+			logger.Warn("failed to parse RSW", zap.Error(err))
+		} else {
+			for _, w := range files.rsw.Warnings {
+				logger.Warn("damaged RSW", zap.String("file", rswPath), zap.Stringer("skipped", w))
+			}
+		}
+	} else {
+		logger.Warn("failed to load RSW", zap.Error(err))
+	}
+	return files, nil
+}
+
 // GetStatusMessage returns the current status message.
 func (s *LoadingState) GetStatusMessage() string {
 	return s.StatusMsg
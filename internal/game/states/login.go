@@ -3,7 +3,10 @@ package states
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/Faultbox/midgard-ro/internal/engine/clock"
 	"github.com/Faultbox/midgard-ro/internal/network"
 	"github.com/Faultbox/midgard-ro/internal/network/packets"
 )
@@ -30,10 +33,27 @@ type LoginState struct {
 	IsLoading bool
 
 	// Connection state
-	connected bool
-	loginSent bool
+	connected     bool
+	loginSent     bool
+	versionWarned bool // MismatchWarning shown for this login attempt
+
+	// Waiting on a busy server: the place in its login queue (0 if not
+	// queued), and when and why the login is retried (zero if it isn't)
+	queuePosition int
+	retryAt       time.Time
+	retryReason   string
+	retries       int // Busy refusals since the player pressed Login
 }
 
+// Automatic login retries while the server is busy: the delay doubles per
+// refusal, up to the maximum, until the player gives up or it's tried
+// busyMaxRetries times.
+const (
+	busyRetryDelay    = 10 * time.Second
+	busyRetryMaxDelay = 60 * time.Second
+	busyMaxRetries    = 8
+)
+
 // NewLoginState creates a new login state.
 func NewLoginState(cfg LoginStateConfig, client *network.Client, manager *Manager) *LoginState {
 	return &LoginState{
@@ -51,44 +71,113 @@ func (s *LoginState) Enter() error {
 	s.IsLoading = false
 	s.connected = false
 	s.loginSent = false
+	s.resetBusy()
 
 	// Register packet handlers (both old and modern versions)
 	s.client.RegisterHandler(packets.AC_ACCEPT_LOGIN, s.handleLoginAccept)
 	s.client.RegisterHandler(packets.AC_ACCEPT_LOGIN2, s.handleLoginAccept2)
 	s.client.RegisterHandler(packets.AC_REFUSE_LOGIN, s.handleLoginRefuse)
-	s.client.RegisterHandler(packets.AC_REFUSE_LOGIN2, s.handleLoginRefuse2)
+	s.client.RegisterHandler(packets.AC_REFUSE_LOGIN2, s.handleLoginRefuse) // Modern rAthena
 	s.client.RegisterHandler(packets.AC_NOTIFY_ERROR, s.handleNotifyError)
+	s.client.RegisterHandler(packets.AC_LOGIN_QUEUE, s.handleLoginQueue)
 
 	return nil
 }
 
+// handleNotifyError processes AC_NOTIFY_ERROR, the login server's
+// SC_NOTIFY_BAN. A full server, or one still holding the last session,
+// is retried; anything else ends the attempt.
 func (s *LoginState) handleNotifyError(data []byte) error {
+	code, _ := packets.DecodeNotifyBan(data)
+	if code == packets.BanServerFull || code == packets.BanStillOnline {
+		s.busyRefused(s.manager.KickText(code))
+		return nil
+	}
 	s.IsLoading = false
+	s.resetBusy()
+	s.ErrorMsg = s.manager.KickText(code)
+	return nil
+}
 
-	errorCode := byte(0)
-	if len(data) >= 3 {
-		errorCode = data[2]
+// handleLoginQueue processes AC_LOGIN_QUEUE — the player's place in line
+// on a busy server, which either keeps the connection open until the
+// login goes through or asks to try again later.
+func (s *LoginState) handleLoginQueue(data []byte) error {
+	q, ok := packets.DecodeLoginQueue(data)
+	if !ok {
+		return fmt.Errorf("invalid AC_LOGIN_QUEUE: %d bytes", len(data))
 	}
-
-	switch errorCode {
-	case 1:
-		s.ErrorMsg = "Server closed"
-	case 2:
-		s.ErrorMsg = "Someone already logged in with this ID"
-	case 3:
-		s.ErrorMsg = "Timeout"
-	case 4:
-		s.ErrorMsg = "Server full"
-	case 5:
-		s.ErrorMsg = "IP blocked"
-	case 8:
-		s.ErrorMsg = "Too many connections. Please wait."
-	default:
-		s.ErrorMsg = fmt.Sprintf("Server error: %d", errorCode)
+	if q.RetryAfter > 0 {
+		s.scheduleRetry(time.Duration(q.RetryAfter)*time.Second, "The server is busy.")
 	}
+	s.queuePosition = max(q.Position, 1)
 	return nil
 }
 
+// busyRefused retries a login the busy server refused after the backoff
+// delay. Once retries run out the reason is shown as an error instead.
+func (s *LoginState) busyRefused(reason string) {
+	if s.retries >= busyMaxRetries {
+		s.client.Disconnect()
+		s.IsLoading = false
+		s.resetBusy()
+		s.ErrorMsg = reason + " Please try again later."
+		return
+	}
+	delay := min(busyRetryDelay<<s.retries, busyRetryMaxDelay)
+	s.retries++
+	s.scheduleRetry(delay, reason)
+}
+
+// scheduleRetry drops the connection and logs in again after delay.
+func (s *LoginState) scheduleRetry(delay time.Duration, reason string) {
+	s.client.Disconnect()
+	s.connected = false
+	s.loginSent = false
+	s.queuePosition = 0
+	s.retryAt = clock.Now().Add(delay)
+	s.retryReason = reason
+	s.IsLoading = true
+}
+
+// resetBusy forgets any queue place and pending retry.
+func (s *LoginState) resetBusy() {
+	s.queuePosition = 0
+	s.retryAt = time.Time{}
+	s.retryReason = ""
+	s.retries = 0
+}
+
+// CancelLogin gives up waiting on a busy server.
+func (s *LoginState) CancelLogin() {
+	s.client.Disconnect()
+	s.connected = false
+	s.loginSent = false
+	s.IsLoading = false
+	s.resetBusy()
+}
+
+// Waiting reports whether the login is queued or waiting to retry, which
+// the player can cancel.
+func (s *LoginState) Waiting() bool {
+	return s.queuePosition > 0 || !s.retryAt.IsZero()
+}
+
+// WaitStatus describes the wait on a busy server, or "" if there's none.
+func (s *LoginState) WaitStatus() string {
+	switch {
+	case !s.retryAt.IsZero():
+		secs := max(int(time.Until(s.retryAt).Round(time.Second).Seconds()), 0)
+		if s.retries == 0 {
+			return fmt.Sprintf("%s Retrying in %ds", s.retryReason, secs)
+		}
+		return fmt.Sprintf("%s Retrying in %ds (attempt %d of %d)", s.retryReason, secs, s.retries, busyMaxRetries)
+	case s.queuePosition > 0:
+		return fmt.Sprintf("The server is busy. Position in queue: %d", s.queuePosition)
+	}
+	return ""
+}
+
 // Exit is called when leaving this state.
 func (s *LoginState) Exit() error {
 	return nil
@@ -96,10 +185,25 @@ func (s *LoginState) Exit() error {
 
 // Update is called every frame.
 func (s *LoginState) Update(dt float64) error {
+	if !s.retryAt.IsZero() {
+		if clock.Now().Before(s.retryAt) {
+			return nil
+		}
+		s.retryAt = time.Time{}
+		s.IsLoading = false
+		_ = s.attemptLogin()
+		return nil
+	}
+
 	// Process network packets
 	if err := s.client.Process(); err != nil {
 		s.ErrorMsg = fmt.Sprintf("Network error: %v", err)
 		s.IsLoading = false
+		s.resetBusy()
+	}
+	if !s.versionWarned && s.ErrorMsg == "" && s.client.Stats().VersionMismatch {
+		s.versionWarned = true
+		s.ErrorMsg = network.MismatchWarning
 	}
 
 	return nil
@@ -121,9 +225,22 @@ func (s *LoginState) AttemptLogin() error {
 	if s.IsLoading {
 		return nil
 	}
+	s.resetBusy()
+	return s.attemptLogin()
+}
+
+// attemptLogin connects and logs in, keeping the count of busy retries.
+func (s *LoginState) attemptLogin() error {
+	if s.IsLoading {
+		return nil
+	}
 
 	s.ErrorMsg = ""
 	s.IsLoading = true
+	s.versionWarned = false
+
+	// Returning to the login screen after a disconnect keeps the name
+	s.manager.LoginConfig.Username = s.Username
 
 	// Connect if not already connected
 	if !s.client.IsConnected() {
@@ -131,6 +248,7 @@ func (s *LoginState) AttemptLogin() error {
 		if err != nil {
 			s.ErrorMsg = fmt.Sprintf("Connection failed: %v", err)
 			s.IsLoading = false
+			s.resetBusy()
 			return err
 		}
 		s.connected = true
@@ -219,34 +337,27 @@ func (s *LoginState) handleLoginAccept(data []byte) error {
 }
 
 func (s *LoginState) handleLoginRefuse(data []byte) error {
-	s.IsLoading = false
-
-	// Parse error code
-	if len(data) < 3 {
-		s.ErrorMsg = "Login refused"
+	refusal := packets.DecodeRefuseLogin(data)
+	if refusal != nil && refusal.Code == packets.RefuseServerBusy {
+		s.busyRefused("The server is busy.")
 		return nil
 	}
 
-	errorCode := data[2]
-	s.setLoginError(errorCode)
-	return nil
-}
-
-func (s *LoginState) handleLoginRefuse2(data []byte) error {
 	s.IsLoading = false
-
-	// Modern packet: 0x083E - error code at offset 2
-	if len(data) < 3 {
+	s.resetBusy()
+	if refusal == nil {
 		s.ErrorMsg = "Login refused"
 		return nil
 	}
-
-	errorCode := data[2]
-	s.setLoginError(errorCode)
+	s.setLoginError(refusal.Code, refusal.BlockDate)
 	return nil
 }
 
-func (s *LoginState) setLoginError(errorCode byte) {
+// msgBannedUntil is the msgstringtable.txt index of the temporary ban
+// message, with a %s for the date.
+const msgBannedUntil = 449
+
+func (s *LoginState) setLoginError(errorCode byte, blockDate string) {
 	switch errorCode {
 	case 0:
 		s.ErrorMsg = "Unregistered ID"
@@ -257,11 +368,11 @@ func (s *LoginState) setLoginError(errorCode byte) {
 	case 3:
 		s.ErrorMsg = "Server rejected connection"
 	case 4:
-		s.ErrorMsg = "Server is full"
+		s.ErrorMsg = "This account has been blocked by the GM team"
 	case 5:
-		s.ErrorMsg = "Banned"
+		s.ErrorMsg = "Client version is out of date"
 	case 6:
-		s.ErrorMsg = "Server under maintenance"
+		s.ErrorMsg = bannedUntil(s.manager.MsgString(msgBannedUntil, "This account is banned until %s"), blockDate)
 	case 7:
 		s.ErrorMsg = "Server overloaded"
 	case 8:
@@ -270,11 +381,24 @@ func (s *LoginState) setLoginError(errorCode byte) {
 		s.ErrorMsg = "IP banned"
 	case 10:
 		s.ErrorMsg = "Locked for security"
+	case 99:
+		s.ErrorMsg = "This account has been deleted"
 	default:
 		s.ErrorMsg = fmt.Sprintf("Login error: %d", errorCode)
 	}
 }
 
+// bannedUntil fills the ban end date into the temporary ban message.
+func bannedUntil(format, date string) string {
+	if date == "" {
+		date = "further notice"
+	}
+	if !strings.Contains(format, "%s") {
+		return format + " " + date
+	}
+	return strings.Replace(format, "%s", date, 1)
+}
+
 // handleLoginAccept2 handles AC_ACCEPT_LOGIN2 (0x0AC4) - modern rAthena format
 func (s *LoginState) handleLoginAccept2(data []byte) error {
 	s.IsLoading = false
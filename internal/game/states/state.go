@@ -1,6 +1,16 @@
 // Package states implements game state management.
 package states
 
+import (
+	"image"
+	"time"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/postfx"
+	"github.com/Faultbox/midgard-ro/internal/game/entity"
+	"github.com/Faultbox/midgard-ro/internal/game/locale"
+	"github.com/Faultbox/midgard-ro/internal/network/packets"
+)
+
 // State represents a game state (login, character select, in-game, etc.)
 type State interface {
 	// Enter is called when entering this state.
@@ -22,11 +32,83 @@ type State interface {
 // TexLoaderFunc is a function that loads asset data from GRF.
 type TexLoaderFunc func(path string) ([]byte, error)
 
+// SoundFunc plays a sound effect by asset path.
+type SoundFunc func(path string)
+
+// SoundAtFunc plays a sound effect by asset path at a world position, so
+// it fades with distance from the player.
+type SoundAtFunc func(path string, x, y, z float32)
+
+// AmbientSound is a looping sound placed on the map, at a world position.
+type AmbientSound struct {
+	Path    string
+	X, Y, Z float32
+	Volume  float32 // 0-1
+	Range   float32 // Silent beyond, in world units
+	Cycle   float32 // Seconds from one start to the next
+}
+
+// AmbientFunc replaces the map's ambient sounds; nil silences them.
+type AmbientFunc func(sounds []AmbientSound)
+
 // Manager manages game state transitions.
 type Manager struct {
-	current   State
-	next      State
-	TexLoader TexLoaderFunc
+	current     State
+	next        State
+	TexLoader   TexLoaderFunc
+	PlaySound   SoundFunc
+	PlaySoundAt SoundAtFunc
+	SetAmbience AmbientFunc
+
+	DevCommands  bool   // Enables dev-only chat commands
+	ReportDir    string // Where bug report files (e.g. desync events) are written
+	Auras        bool   // Draws level and job auras around characters
+	BuffWarnings bool   // Warns in chat before a buff wears off
+	DoNotDisturb bool   // Drops notification toasts; toggled by /dnd
+
+	// InputBufferDepth is how many attack and skill presses wait for the
+	// player's current action (1-2).
+	InputBufferDepth int
+
+	// AwayAfter is how long without input marks the player away, 0 never.
+	AwayAfter time.Duration
+
+	// FidgetInterval is how often units standing idle play a fidget, 0
+	// never.
+	FidgetInterval time.Duration
+
+	// InstantDialogText shows NPC dialog pages at once instead of typing
+	// them out.
+	InstantDialogText bool
+
+	// CameraEffects is the strength of camera shakes and zooms, 0 for
+	// none.
+	CameraEffects float32
+
+	// SpriteFallbacks is the chain tried when a player's body sprite is
+	// missing from the archive.
+	SpriteFallbacks []entity.SpriteFallback
+
+	// PostFX is the post-processing of the 3D view, and ColorLUT the
+	// grading table it uses; nil when none is configured.
+	PostFX   postfx.Settings
+	ColorLUT image.Image
+
+	// DayLength and NightLength mirror the server's day/night cycle;
+	// zero when it has none.
+	DayLength, NightLength time.Duration
+
+	// LoginConfig starts the login screen again after a disconnect.
+	LoginConfig LoginStateConfig
+
+	// Char server of the session, which the game returns to for
+	// character select
+	charServerHost string
+	charServerPort int
+
+	// Character is the character picked at character select, whose job
+	// and level the in-game state starts the player with.
+	Character *packets.CharInfo
 }
 
 // NewManager creates a new state manager.
@@ -39,6 +121,107 @@ func (m *Manager) SetTexLoader(loader TexLoaderFunc) {
 	m.TexLoader = loader
 }
 
+// SetSoundPlayer sets the sound effect players, for interface sounds and
+// sounds in the world.
+func (m *Manager) SetSoundPlayer(play SoundFunc, playAt SoundAtFunc) {
+	m.PlaySound = play
+	m.PlaySoundAt = playAt
+}
+
+// SetAmbientPlayer sets the player of the map's looping ambient sounds.
+func (m *Manager) SetAmbientPlayer(play AmbientFunc) {
+	m.SetAmbience = play
+}
+
+// SetDevCommands enables or disables dev-only chat commands for states
+// entered afterwards.
+func (m *Manager) SetDevCommands(enabled bool) {
+	m.DevCommands = enabled
+}
+
+// SetDayCycle sets the lengths of the server's day and night, so the
+// in-game state can predict when night falls.
+func (m *Manager) SetDayCycle(day, night time.Duration) {
+	m.DayLength, m.NightLength = day, night
+}
+
+// SetBuffWarnings turns chat warnings before buffs wear off on or off.
+func (m *Manager) SetBuffWarnings(enabled bool) {
+	m.BuffWarnings = enabled
+}
+
+// SetDoNotDisturb sets whether notification toasts are dropped.
+func (m *Manager) SetDoNotDisturb(on bool) {
+	m.DoNotDisturb = on
+}
+
+// SetInputBufferDepth sets how many attack and skill presses wait for the
+// player's current action, for states entered afterwards.
+func (m *Manager) SetInputBufferDepth(depth int) {
+	m.InputBufferDepth = entity.ClampInputBufferDepth(depth)
+}
+
+// SetIdle sets how long without input marks the player away and how often
+// idle units fidget, 0 to turn either off.
+func (m *Manager) SetIdle(awayAfter, fidgetInterval time.Duration) {
+	m.AwayAfter, m.FidgetInterval = max(awayAfter, 0), max(fidgetInterval, 0)
+}
+
+// SetInstantDialogText sets whether NPC dialog pages show at once.
+func (m *Manager) SetInstantDialogText(instant bool) {
+	m.InstantDialogText = instant
+}
+
+// SetCameraEffects sets the strength of camera shakes and zooms, 0 to turn
+// them off.
+func (m *Manager) SetCameraEffects(scale float32) {
+	m.CameraEffects = scale
+}
+
+// SetSpriteFallbacks sets the chain tried when a player's body sprite is
+// missing.
+func (m *Manager) SetSpriteFallbacks(chain []entity.SpriteFallback) {
+	m.SpriteFallbacks = chain
+}
+
+// SetAuras turns level and job auras around characters on or off.
+func (m *Manager) SetAuras(enabled bool) {
+	m.Auras = enabled
+}
+
+// SetPostFX sets the post-processing of the 3D view.
+func (m *Manager) SetPostFX(settings postfx.Settings) {
+	m.PostFX = settings
+}
+
+// SetColorLUT sets the color grading table scenes load, nil for none.
+func (m *Manager) SetColorLUT(img image.Image) {
+	m.ColorLUT = img
+}
+
+// charServer returns the address of the session's char server, empty
+// before one was reached.
+func (m *Manager) charServer() (host string, port int) {
+	return m.charServerHost, m.charServerPort
+}
+
+// SetReportDir sets the folder bug report files are written to.
+func (m *Manager) SetReportDir(dir string) {
+	m.ReportDir = dir
+}
+
+// SetLoginConfig sets how the login screen is set up when returning to it
+// after a disconnect.
+func (m *Manager) SetLoginConfig(cfg LoginStateConfig) {
+	m.LoginConfig = cfg
+}
+
+// MsgString returns message index of the client's msgstringtable.txt, in
+// the selected language, or fallback if the message is missing.
+func (m *Manager) MsgString(index int, fallback string) string {
+	return locale.Or(index, fallback)
+}
+
 // Current returns the current state.
 func (m *Manager) Current() State {
 	return m.current
@@ -0,0 +1,107 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/ui2d"
+)
+
+// areaMapTexDir is the GRF folder of the area map images, one BMP per map.
+const areaMapTexDir = `data\texture\유저인터페이스\map\`
+
+// worldMapTexture is the GRF path of the world map image.
+const worldMapTexture = `data\texture\유저인터페이스\worldmap.jpg`
+
+// areaMapHoverRadius is how close, in UI units, the mouse must be to a
+// marker or town to pick it.
+const areaMapHoverRadius = 8
+
+// mapLayout places a map of tiles in a box on screen, centered and scaled
+// to fit. Tile Y grows north, screen Y south.
+type mapLayout struct {
+	x, y   float32 // Top-left of the map on screen
+	w, h   float32
+	scale  float32 // Screen units per tile
+	tilesW int
+	tilesH int
+}
+
+// fitMap fits a tilesW x tilesH map into the box at x, y.
+func fitMap(x, y, w, h float32, tilesW, tilesH int) mapLayout {
+	if tilesW <= 0 || tilesH <= 0 {
+		return mapLayout{}
+	}
+	scale := min(w/float32(tilesW), h/float32(tilesH))
+	l := mapLayout{scale: scale, tilesW: tilesW, tilesH: tilesH}
+	l.w, l.h = float32(tilesW)*scale, float32(tilesH)*scale
+	l.x, l.y = x+(w-l.w)/2, y+(h-l.h)/2
+	return l
+}
+
+// toScreen returns the screen position of the center of a tile.
+func (l mapLayout) toScreen(tileX, tileY int) (float32, float32) {
+	return l.x + (float32(tileX)+0.5)*l.scale, l.y + (float32(l.tilesH-1-tileY)+0.5)*l.scale
+}
+
+// toTile returns the tile at a screen position, and whether it's on the
+// map.
+func (l mapLayout) toTile(x, y float32) (int, int, bool) {
+	if l.scale == 0 || x < l.x || y < l.y || x >= l.x+l.w || y >= l.y+l.h {
+		return 0, 0, false
+	}
+	tx := int((x - l.x) / l.scale)
+	ty := l.tilesH - 1 - int((y-l.y)/l.scale)
+	return tx, ty, true
+}
+
+// markerColor returns the color of a unit marker on the area map.
+func markerColor(t MarkerType) ui2d.Color {
+	switch t {
+	case MarkerTypePlayer:
+		return ui2d.Color{R: 0.2, G: 1.0, B: 0.2, A: 1}
+	case MarkerTypeParty:
+		return ui2d.Color{R: 1.0, G: 0.6, B: 0.9, A: 1}
+	case MarkerTypeGuild:
+		return ui2d.Color{R: 0.6, G: 0.8, B: 1.0, A: 1}
+	case MarkerTypeNPC:
+		return ui2d.Color{R: 1.0, G: 0.85, B: 0.2, A: 1}
+	case MarkerTypeWarp:
+		return ui2d.Color{R: 0.9, G: 0.3, B: 0.3, A: 1}
+	}
+	return ui2d.ColorWhite
+}
+
+// exitLabel returns the label of an exit on the area map.
+func exitLabel(exit AreaMapExit) string {
+	if exit.DestMap == "" {
+		return "Warp"
+	}
+	return exit.DestMap
+}
+
+// areaMapTitle names the area map window after the map, with the game
+// time when it's known.
+func areaMapTitle(m *AreaMapState) string {
+	title := "Area Map - " + m.MapName
+	if m.Clock != "" {
+		title += "  " + m.Clock
+	}
+	return title
+}
+
+// routeText describes the route to the selected town.
+func routeText(m *AreaMapState) string {
+	switch {
+	case m.Target == "":
+		return "Pick a town to see the way there."
+	case len(m.Route) == 0:
+		return "No known route to " + m.Target + "."
+	}
+	return "Route: " + strings.Join(append([]string{m.MapName}, m.Route...), " > ")
+}
+
+// near reports whether x, y is within areaMapHoverRadius of px, py.
+func near(x, y, px, py float32) bool {
+	dx, dy := x-px, y-py
+	return dx*dx+dy*dy <= areaMapHoverRadius*areaMapHoverRadius
+}
@@ -2,7 +2,12 @@
 package ui
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/Faultbox/midgard-ro/internal/engine/ui2d"
+	"github.com/Faultbox/midgard-ro/internal/game/entity"
+	"github.com/Faultbox/midgard-ro/internal/game/settings"
 	"github.com/Faultbox/midgard-ro/internal/network/packets"
 )
 
@@ -42,16 +47,100 @@ type UIBackend interface {
 	// RenderLoadingUI renders the map loading screen.
 	RenderLoadingUI(state LoadingUIState, width, height float32)
 
+	// RenderDisconnectedUI renders the error screen shown when the session ends.
+	RenderDisconnectedUI(state DisconnectedUIState, width, height float32)
+
 	// RenderInGameUI renders the in-game HUD.
 	RenderInGameUI(state InGameUIState, dt float64, width, height float32)
 
 	// RenderFPSOverlay renders an FPS counter (if enabled).
 	RenderFPSOverlay(fps float64, width, height float32)
 
-	// RenderScreenshotMessage renders a screenshot notification.
-	RenderScreenshotMessage(msg string, width, height float32)
+	// RenderNotifications renders the notification toasts in the corner.
+	RenderNotifications(state NotificationsState, width, height float32)
+
+	// RenderScreenFlash renders a full-screen white flash (alpha 0..1) after a capture.
+	RenderScreenFlash(alpha float32, width, height float32)
+
+	// RenderScreenshotGallery renders the recent screenshots window.
+	RenderScreenshotGallery(state ScreenshotGalleryState, width, height float32)
+
+	// SetUIScale sets the UI scale factor applied to layout, text and hit-testing.
+	// GetScreenSize reports dimensions in scaled UI units afterwards.
+	SetUIScale(scale float32)
+
+	// RenderSettingsUI renders the settings window.
+	RenderSettingsUI(state SettingsUIState, width, height float32)
+
+	// RenderSystemMenu renders the Escape menu.
+	RenderSystemMenu(state SystemMenuState, width, height float32)
+
+	// WindowLayout returns where the player has moved windows to.
+	WindowLayout() settings.Windows
+
+	// SetWindowLayout moves windows to a saved layout. Windows it doesn't
+	// list go back to their default positions.
+	SetWindowLayout(layout settings.Windows)
 }
 
+// ChatBubble is what a unit said, shown over its head.
+type ChatBubble struct {
+	X, Y  float32 // Bottom center, in screen units
+	Text  string
+	Alpha float32 // Fading out at the end of its time
+}
+
+// DamageNumber is a damage amount floating over a unit.
+type DamageNumber struct {
+	X, Y  float32 // Center, in screen units
+	Text  string
+	Color ui2d.Color // Palette color, faded by the number's age
+	Scale float32
+}
+
+// Nameplate layout, in screen units. Text width is estimated rather than
+// measured so the layout can be worked out before drawing.
+const (
+	NameplateLineHeight = 15
+	NameplateCharWidth  = 7
+	NameplateHPHeight   = 5
+	NameplateGap        = 4 // Between a unit's feet and its nameplate
+)
+
+// NameplateLine is a line of a nameplate: the name, guild or position.
+type NameplateLine struct {
+	Text  string
+	Color ui2d.Color
+}
+
+// Nameplate is a unit's name, with its guild lines and HP bar, centered
+// below its feet as in RO.
+type Nameplate struct {
+	X, Y    float32 // Top center, in screen units
+	Lines   []NameplateLine
+	HP      float32    // HP fraction, or -1 for no HP bar
+	HPColor ui2d.Color // Palette color for the HP fraction
+}
+
+// Size returns the nameplate's estimated size.
+func (p *Nameplate) Size() (w, h float32) {
+	for _, l := range p.Lines {
+		w = max(w, float32(len([]rune(l.Text)))*NameplateCharWidth)
+	}
+	h = float32(len(p.Lines)) * NameplateLineHeight
+	if p.HP >= 0 {
+		w = max(w, nameplateHPWidth)
+		h += NameplateHPHeight + 2
+	}
+	return w, h
+}
+
+// nameplateHPWidth is the width of nameplate HP bars.
+const nameplateHPWidth = 60
+
+// debugMissingSprites is how many missing sprites the debug overlay lists.
+const debugMissingSprites = 5
+
 // LoginUIState contains the data needed to render the login UI.
 type LoginUIState struct {
 	Username     string
@@ -60,10 +149,15 @@ type LoginUIState struct {
 	IsLoading    bool
 	ServerName   string
 
+	// WaitMessage describes the wait on a busy server: the queue position
+	// or the countdown to the next retry. "" when not waiting.
+	WaitMessage string
+
 	// Callbacks
 	OnUsernameChange func(string)
 	OnPasswordChange func(string)
 	OnLogin          func()
+	OnCancel         func() // Stops waiting on a busy server (nil hides it)
 }
 
 // ConnectingUIState contains the data needed to render the connecting UI.
@@ -72,6 +166,14 @@ type ConnectingUIState struct {
 	ErrorMessage  string
 }
 
+// DisconnectedUIState contains the data needed to render the error screen
+// shown after a kick, ban or lost connection.
+type DisconnectedUIState struct {
+	Title    string
+	Message  string
+	OnReturn func() // Back to the login screen
+}
+
 // CharSelectUIState contains the data needed to render the character select UI.
 type CharSelectUIState struct {
 	Characters    []*packets.CharInfo
@@ -81,9 +183,25 @@ type CharSelectUIState struct {
 	IsLoading     bool
 	IsReady       bool
 
+	Pincode    *PincodeUIState // PIN keypad over the list, nil when closed
+	PincodeSet bool            // The account has a PIN code to change
+
 	// Callbacks
-	OnSelect      func(index int)
-	OnSelectIndex func(index int)
+	OnSelect        func(index int)
+	OnSelectIndex   func(index int)
+	OnChangePincode func() // Nil when the server has no PIN codes
+}
+
+// PincodeUIState is the character server's PIN keypad. The buttons are
+// shuffled by the server on every prompt.
+type PincodeUIState struct {
+	AskCurrent bool     // Type the current PIN
+	AskNew     bool     // Type a new PIN, then again to confirm
+	Keypad     [10]byte // Digit on each button
+	Message    string   // From the server, such as a wrong PIN
+
+	OnSubmit func(current, newPin string)
+	OnCancel func() // Nil when a PIN is required
 }
 
 // LoadingUIState contains the data needed to render the loading UI.
@@ -113,6 +231,14 @@ type InGameUIState struct {
 	CamDistance      float32
 	CamYaw, CamPitch float32
 
+	// Audio environment and sound effects playing (debug)
+	AudioEnvironment string
+	AudioVoices      []string
+
+	// Sprites missing from the archive and the fallbacks shown (debug),
+	// of which the overlay lists the first debugMissingSprites
+	MissingSprites []string
+
 	// Scene framebuffer + GL diagnostics (debug)
 	SceneFBWidth  int32
 	SceneFBHeight int32
@@ -126,6 +252,90 @@ type InGameUIState struct {
 	PlayerSP, PlayerMaxSP int
 	PlayerLevel           int
 	PlayerJobLevel        int
+	BaseExpRatio          float32 // Base experience progress (0-1)
+	JobExpRatio           float32 // Job experience progress (0-1)
+
+	// Basic info window: the weight carried and zeny
+	Weight entity.Weight
+	Zeny   int64
+
+	// Chat log of the shown tab, oldest first
+	ChatMessages []ChatLine
+
+	// ChatTabs filter the chat log by channel, in display order
+	ChatTabs       []ChatTab
+	ActiveChatTab  int
+	ChatTabActions ChatTabActions
+
+	// OnChatSubmit receives lines entered in the chat input (nil hides it)
+	OnChatSubmit func(line string)
+
+	// OnChatComplete returns completions for the chat line being typed,
+	// replacing it from start; Tab cycles them (nil for none)
+	OnChatComplete func(line string) (start int, candidates []string)
+
+	// ChatDraft, if set, replaces the chat input text and focuses it
+	ChatDraft string
+
+	// ContextMenu is the open right-click menu, nil when closed
+	ContextMenu *ContextMenuState
+
+	// Inventory window (Alt+E)
+	ShowInventory bool
+	Inventory     []InventoryItem
+
+	// OnItemDrop is called when an inventory item is dragged out of the
+	// windows onto the game viewport
+	OnItemDrop func(index int)
+
+	// Equipment window (Alt+Q): the doll shows the worn items of Inventory
+	ShowEquipment bool
+
+	// OnEquip and OnUnequip are called on a double-click on an inventory
+	// item or a doll slot
+	OnEquip   func(index int)
+	OnUnequip func(index int)
+
+	// DropPrompt is the open quantity dialog for a drop or another flow
+	// that asks how many, nil when closed
+	DropPrompt *QuantityPrompt
+
+	// VendingShop is the open shop of another player, nil when closed
+	VendingShop *VendingShopState
+
+	// RequestDialog is the yes/no request to answer, such as a party
+	// invitation, nil when there is none
+	RequestDialog *RequestDialogState
+
+	// NPCDialog is the dialog of the NPC talked to, nil when none
+	NPCDialog *NPCDialogState
+
+	// DialogHistory is the window of NPC dialog lines the player moved
+	// past (Alt+H), nil when closed
+	DialogHistory *DialogHistoryState
+
+	// AreaMap is the full-screen area/world map (Alt+V), nil when closed
+	AreaMap *AreaMapState
+
+	// Cutin is the illustration of the NPC talking, nil when there's none
+	Cutin *CutinState
+
+	// Banner is the server announcement scrolling across the top of the
+	// screen, nil when there's none
+	Banner *BannerState
+
+	// Buffs are the player's buffs, shown as icons down the right edge
+	Buffs []BuffIcon
+
+	// DamageNumbers float over the units that were hit, under the HUD
+	DamageNumbers []DamageNumber
+
+	// Nameplates are the unit names shown, already thinned out so they
+	// don't overlap
+	Nameplates []Nameplate
+
+	// ChatBubbles are what units in view said, over their heads
+	ChatBubbles []ChatBubble
 
 	// Entity counts
 	EntityCount  int
@@ -134,6 +344,11 @@ type InGameUIState struct {
 	NPCCount     int
 	ItemCount    int
 
+	// Entity update budget, last frame (debug)
+	EntitiesUpdated   int
+	EntitiesThrottled int
+	EntitiesCulled    int
+
 	// Network telemetry (debug)
 	PacketsSent     uint64
 	PacketsReceived uint64
@@ -146,6 +361,46 @@ type InGameUIState struct {
 	LastRecvLen     int
 	LastRecvAgoMs   int64
 
+	// Send queue (debug)
+	PacketsQueued    int
+	PacketsCoalesced uint64
+	PacketsDeferred  uint64
+	SendWrites       uint64
+
+	// Unit animation LOD (debug), last frame: units animating at full and
+	// reduced rates, frozen off screen, and sprites composited
+	AnimFull       int
+	AnimReduced    int
+	AnimFrozen     int
+	AnimComposited int
+
+	// Input buffer (debug): attack and skill presses waiting for the
+	// player's action, and how many fired, waited or were dropped
+	InputWaiting  int
+	InputFired    uint64
+	InputBuffered uint64
+	InputDropped  uint64
+
+	// Socket goroutines (debug): reads waiting for the frame and the most
+	// that waited, and how often reads or flushes had to wait
+	InboundQueued int
+	InboundPeak   int
+	ReadStalls    uint64
+	WriteStalls   uint64
+
+	// Packet framing (debug): packets of unknown IDs skipped, and resyncs
+	// of the stream with the bytes they dropped. VersionMismatch flags a
+	// server likely on another packetver
+	UnknownPackets  uint64
+	LastUnknownID   uint16
+	Resyncs         uint64
+	DroppedBytes    uint64
+	VersionMismatch bool
+
+	// Picture-in-picture debug camera (0 texture = disabled)
+	PiPTexture uint32
+	PiPMode    string
+
 	// Scene info
 	SceneReady    bool
 	SceneTexture  uint32
@@ -161,6 +416,349 @@ type InGameUIState struct {
 
 	// FPS
 	FPS float64
+
+	// Connection quality, from keep-alive round trips
+	PingMs        int  // Smoothed latency, 0 before the first reply
+	ShowPing      bool // Show the latency in the status bar
+	ServerLagging bool // The server stopped answering keep-alives
+
+	// Map PVP rules, from the server's map properties
+	PVPMode    string // "PvP", "GvG" or "Battleground"; "" on safe maps
+	PVPRank    int    // The player's rank on a ranked PVP map, 0 if none
+	PVPPlayers int    // Players ranked
+
+	// AttackCursor marks the mouse when it's over a unit the player can attack
+	AttackCursor bool
+}
+
+// pvpColor is the color of the PVP mode badge and attack cursor.
+var pvpColor = ui2d.Color{R: 1, G: 0.3, B: 0.3, A: 1}
+
+// PVPText returns the status bar's PVP badge, with the player's rank on
+// ranked maps. It's empty on safe maps.
+func (s *InGameUIState) PVPText() string {
+	if s.PVPMode == "" {
+		return ""
+	}
+	if s.PVPRank > 0 {
+		return fmt.Sprintf("%s  Rank %d/%d", s.PVPMode, s.PVPRank, s.PVPPlayers)
+	}
+	return s.PVPMode
+}
+
+// ConnectionText returns the status bar's connection note: a warning
+// while the server isn't responding, otherwise the ping if shown. It's
+// empty when there's nothing to show.
+func (s *InGameUIState) ConnectionText() string {
+	switch {
+	case s.ServerLagging:
+		return "Server not responding"
+	case s.ShowPing && s.PingMs > 0:
+		return fmt.Sprintf("Ping: %d ms", s.PingMs)
+	}
+	return ""
+}
+
+// ChatLine is a chat log line, colored by its channel.
+type ChatLine struct {
+	Text  string
+	Color ui2d.Color
+}
+
+// ChatTab is a chat tab as shown in the tab bar.
+type ChatTab struct {
+	Name     string
+	Unread   int                 // Messages since the tab was last shown
+	Channels []ChatChannelToggle // Every channel, for the tab's settings
+}
+
+// ChatChannelToggle is a channel in a chat tab's settings and whether
+// the tab shows it.
+type ChatChannelToggle struct {
+	Label string
+	On    bool
+}
+
+// ChatTabActions edit the chat tabs by index. A nil action isn't offered.
+type ChatTabActions struct {
+	Select func(tab int)
+	Move   func(from, to int)
+	Add    func()
+	Remove func(tab int)
+	Rename func(tab int, name string)
+	Toggle func(tab, channel int)
+}
+
+// ContextMenuItem is one entry of a context menu.
+type ContextMenuItem struct {
+	Label    string
+	Enabled  bool // Disabled items are shown greyed out
+	OnSelect func()
+}
+
+// ContextMenuState contains the data needed to render a context menu
+// opened at X, Y (screen pixels).
+type ContextMenuState struct {
+	Title string
+	X, Y  float32
+	Items []ContextMenuItem
+
+	// OnClose is called when the menu is dismissed without a selection
+	OnClose func()
+}
+
+// InventoryItem is one row of the inventory window.
+type InventoryItem struct {
+	Index     int // Server inventory index, passed back to OnItemDrop
+	Name      string
+	Amount    int
+	Droppable bool // Equipped items can't be dropped
+
+	Location entity.EquipLocation // Slots it fits, 0 if it isn't equipment
+	Worn     entity.EquipLocation // Slots it's worn in
+	Refine   int
+	Cards    int // Cards slotted
+	Slots    int // Card slots, shown over Cards when known
+
+	// SlotCards names the card in each slot, "" for an empty one
+	SlotCards []string
+	Grade     entity.ItemGrade // Colors the row
+}
+
+// QuantityPrompt contains the data needed to render a "how many?" dialog,
+// shared by drops, purchases and moving items between windows. The amount
+// is limited by what's available and, when set, by the weight left to
+// carry and the zeny to pay with.
+type QuantityPrompt struct {
+	Title   string
+	Min     int // Least amount accepted, usually 1
+	Max     int // Amount available
+	Initial int // Amount the dialog opens with; 0 opens at the limit
+
+	UnitWeight int // Weight of one item; 0 when weight doesn't matter
+	WeightLeft int // Weight that can still be carried
+
+	UnitPrice int64 // Zeny per item; 0 when nothing is paid
+	Zeny      int64 // Zeny available to pay with
+
+	OnConfirm func(amount int)
+	OnCancel  func()
+}
+
+// CutinState is an NPC illustration, fading in or out.
+type CutinState struct {
+	Image    string  // Image name as the server sent it
+	Position uint8   // packets.Cutin* placement
+	Alpha    float32 // 0 to 1
+}
+
+// BannerState is a server announcement scrolling across the screen.
+type BannerState struct {
+	Text     string
+	Color    uint32  // 0xRRGGBB
+	FontSize int     // In points; 12 is the chat font's
+	Progress float32 // 0 entering at the right edge to 1 gone past the left
+}
+
+// BuffIcon is a buff on the player, with a countdown ring for its time
+// left.
+type BuffIcon struct {
+	Name      string
+	Timed     bool          // Wears off by itself; untimed buffs have no ring
+	Remaining time.Duration // Until it wears off
+	Fraction  float32       // Of its time left
+	Expiring  bool          // About to wear off: the icon flashes
+}
+
+// RequestDialogState is a modal yes/no request. Enter accepts and Escape
+// declines.
+type RequestDialogState struct {
+	Title        string
+	Message      string
+	AcceptLabel  string
+	DeclineLabel string
+	Remaining    float64 // Seconds until the request is declined; 0 waits
+	OnAnswer     func(accept bool)
+}
+
+// NPCDialogState is an NPC's dialog window. Its text types out; a click
+// on it, or Space, completes the page and then presses the button.
+type NPCDialogState struct {
+	Name   string
+	Text   string // Typed so far, lines separated by "\n"
+	Typing bool
+	Button string // "Next" or "Close", "" while the NPC is still talking
+
+	OnAdvance func() // Completes the text, or presses the button
+	OnHistory func() // Opens the dialog history
+}
+
+// DialogHistoryState is the scrollable window of past NPC dialog lines,
+// oldest first.
+type DialogHistoryState struct {
+	Lines   []DialogHistoryLine
+	OnClose func()
+}
+
+// DialogHistoryLine is a line of the dialog history and the NPC who said
+// it.
+type DialogHistoryLine struct {
+	Name string
+	Text string
+}
+
+// VendingShopItem is one row of a shop window.
+type VendingShopItem struct {
+	Index  int // Vendor's cart index, the key of OnBuy's cart
+	Name   string
+	Price  int // Zeny per item
+	Amount int // Items for sale
+	Grade  entity.ItemGrade
+}
+
+// VendingShopState contains the data needed to render another player's shop.
+type VendingShopState struct {
+	VendorID uint32 // Tells shops apart, so a new shop starts with an empty cart
+	Title    string
+	Zeny     int64 // Carried by the player
+	Items    []VendingShopItem
+
+	// Callbacks
+	OnBuy   func(cart map[int]int) // Amounts by item index
+	OnClose func()
+}
+
+// AreaMapMarker is a unit shown on the area map.
+type AreaMapMarker struct {
+	X, Y  int // Tile position
+	Type  MarkerType
+	Label string // Shown on hover
+}
+
+// AreaMapExit is a warp leaving the map.
+type AreaMapExit struct {
+	X, Y    int    // Tile position
+	DestMap string // "" for a warp seen in the world but not in the warp table
+	OnRoute bool   // First warp of the route to the selected town
+}
+
+// WorldMapTown is a town on the world map.
+type WorldMapTown struct {
+	Map     string
+	Label   string
+	X, Y    float32 // Position on the world map image, 0-1 from top-left
+	Current bool    // The player is in this town
+}
+
+// AreaMapState contains the data needed to render the full-screen map
+// window: the current map with the player, party members, NPCs and exits,
+// or the world map to pick a town to travel to.
+type AreaMapState struct {
+	MapName          string
+	Clock            string // Game time, e.g. "21:40 Night"; "" before the server clock syncs
+	Width, Height    int    // Map size in tiles, 0 if unknown
+	PlayerX, PlayerY int
+	Markers          []AreaMapMarker
+	Exits            []AreaMapExit
+
+	WorldMap bool // Show the world map instead of the area map
+	Towns    []WorldMapTown
+	Target   string   // Town picked on the world map, "" for none
+	Route    []string // Maps to cross to reach Target, in order; nil if unknown
+
+	// Callbacks
+	OnToggleWorld func()
+	OnSelectTown  func(mapName string)
+	OnMove        func(tileX, tileY int) // Click on the area map
+	OnClose       func()
+}
+
+// ScreenshotThumb is a single gallery entry with its uploaded thumbnail.
+type ScreenshotThumb struct {
+	Name      string
+	TextureID uint32 // 0 if the thumbnail could not be loaded
+	Width     int
+	Height    int
+}
+
+// ScreenshotGalleryState contains the data needed to render the screenshot gallery.
+type ScreenshotGalleryState struct {
+	Dir     string
+	Entries []ScreenshotThumb
+
+	// Callbacks
+	OnClose func()
+}
+
+// SettingsUIState contains the data needed to render the settings window.
+type SettingsUIState struct {
+	UIScale           float32
+	Auras             bool   // Level and job auras around characters
+	InstantDialogText bool   // NPC dialog pages show at once
+	Language          string // Name of the language of the client's text
+
+	// Frame pacing
+	VSync              bool
+	FPSLimit           int  // 0 for none
+	BackgroundThrottle bool // Slow down while unfocused or minimized
+
+	// Post-processing of the 3D view
+	Gamma        float32
+	Brightness   float32
+	FXAA         bool
+	ColorGrading bool
+	HasColorLUT  bool // Grading does nothing without a table configured
+
+	// Accessibility
+	Palette           string // Name of the selected ui2d.Palette
+	DamageTextScale   float32
+	ReduceFlashes     bool
+	CameraEffects     bool
+	CameraEffectScale float32
+
+	// Nameplates: when each unit type's names show, and the extra lines
+	Nameplates       []NameplateSetting
+	NameplateGuild   bool
+	NameplatePartyHP bool
+
+	// Callbacks
+	OnUIScaleChange           func(scale float32)
+	OnAurasChange             func(enabled bool)
+	OnInstantDialogTextChange func(instant bool)
+	OnLanguageNext            func() // Steps to the next language
+	OnVSyncChange             func(enabled bool)
+	OnFPSLimitChange          func(limit int)
+	OnThrottleChange          func(enabled bool)
+	OnGammaChange             func(gamma float32)
+	OnBrightnessChange        func(brightness float32)
+	OnFXAAChange              func(enabled bool)
+	OnColorGradingChange      func(enabled bool)
+	OnPaletteChange           func(name string)
+	OnDamageTextScaleChange   func(scale float32)
+	OnReduceFlashesChange     func(reduce bool)
+	OnCameraEffectsChange     func(enabled bool)
+	OnCameraEffectScaleChange func(scale float32)
+	OnNameplateModeCycle      func(index int) // Steps Nameplates[index] to its next mode
+	OnNameplateGuildChange    func(show bool)
+	OnNameplatePartyHPChange  func(show bool)
+	OnClose                   func()
+}
+
+// SystemMenuState contains the data needed to render the Escape menu.
+type SystemMenuState struct {
+	CharSelectPending bool // Waiting for the server to allow a character switch
+
+	// Callbacks
+	OnCharSelect func()
+	OnSettings   func()
+	OnExit       func()
+	OnClose      func()
+}
+
+// NameplateSetting is a unit type's nameplate mode in the settings window.
+type NameplateSetting struct {
+	Label string // Unit type, e.g. "Monsters"
+	Mode  string // Mode label, e.g. "Hover"
 }
 
 // GetCharName safely gets a character name from CharInfo.
@@ -0,0 +1,33 @@
+package ui
+
+import "github.com/Faultbox/midgard-ro/internal/engine/ui2d"
+
+const (
+	bannerTop = 36 // Below the top edge, clear of the menu buttons
+	bannerPad = 4  // Around the text, in the strip behind it
+)
+
+// bannerX returns where a banner's text starts as it scrolls in from the
+// right edge of a screen screenW wide until it has left by the left.
+func bannerX(progress, textW, screenW float32) float32 {
+	return screenW - progress*(screenW+textW)
+}
+
+// bannerScale returns the text scale of a banner's font size, the chat
+// font being 12 points.
+func bannerScale(fontSize int) float32 {
+	if fontSize <= 0 {
+		return 1
+	}
+	return max(0.75, min(float32(fontSize)/12, 2))
+}
+
+// bannerColor converts an announcement's 0xRRGGBB color.
+func bannerColor(rgb uint32) ui2d.Color {
+	return ui2d.Color{
+		R: float32(rgb>>16&0xFF) / 255,
+		G: float32(rgb>>8&0xFF) / 255,
+		B: float32(rgb&0xFF) / 255,
+		A: 1,
+	}
+}
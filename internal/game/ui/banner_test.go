@@ -0,0 +1,36 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/ui2d"
+)
+
+func TestBanner(t *testing.T) {
+	for _, tt := range []struct {
+		progress, want float32
+	}{
+		{0, 800},
+		{0.5, 300},
+		{1, -200},
+	} {
+		if got := bannerX(tt.progress, 200, 800); got != tt.want {
+			t.Errorf("bannerX(%v) = %v, want %v", tt.progress, got, tt.want)
+		}
+	}
+
+	for _, tt := range []struct {
+		size int
+		want float32
+	}{
+		{0, 1}, {12, 1}, {18, 1.5}, {6, 0.75}, {40, 2},
+	} {
+		if got := bannerScale(tt.size); got != tt.want {
+			t.Errorf("bannerScale(%d) = %v, want %v", tt.size, got, tt.want)
+		}
+	}
+
+	if got := bannerColor(0x00FF00); got != (ui2d.Color{G: 1, A: 1}) {
+		t.Errorf("bannerColor(0x00FF00) = %+v", got)
+	}
+}
@@ -0,0 +1,30 @@
+package ui
+
+import (
+	"github.com/Faultbox/midgard-ro/internal/engine/ui2d"
+	"github.com/Faultbox/midgard-ro/internal/game/entity"
+)
+
+// The basic info window sits in the top-left corner, the debug overlay
+// under it.
+const (
+	basicInfoWidth  = 200
+	basicInfoHeight = 84
+)
+
+// weightOverColor is the color of the weight readout of an overweight
+// player, whose HP and SP no longer recover.
+var weightOverColor = ui2d.Color{R: 1, G: 0.3, B: 0.3, A: 1}
+
+// weightColor returns the color of the weight readout.
+func weightColor(w entity.Weight) ui2d.Color {
+	if w.Level() >= entity.WeightOver50 {
+		return weightOverColor
+	}
+	return ui2d.ColorTextOnDark
+}
+
+// zenyText formats the zeny readout: "Zeny: 1,500,000".
+func zenyText(zeny int64) string {
+	return "Zeny: " + formatZeny(zeny)
+}
@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	buffIconSize  = 32
+	buffIconGap   = 4
+	buffTop       = 66              // Below the banner strip
+	buffRing      = 3               // Thickness of the countdown ring
+	buffFlashRate = time.Second / 4 // Half a flash of an icon about to wear off
+)
+
+// buffColumn returns where the i-th buff icon goes, in a column down the
+// right edge of a screen width wide.
+func buffColumn(i int, width float32) (x, y float32) {
+	return width - buffIconSize - 10, buffTop + float32(i)*(buffIconSize+buffIconGap)
+}
+
+// buffLabel returns the letters standing for a buff on its icon: the
+// initials of a name of several words, or the start of a single word.
+func buffLabel(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool { return r == ' ' || r == '-' })
+	if len(words) == 1 {
+		r := []rune(words[0])
+		return string(r[:min(3, len(r))])
+	}
+	var label []rune
+	for _, w := range words[:min(3, len(words))] {
+		label = append(label, unicode.ToUpper([]rune(w)[0]))
+	}
+	return string(label)
+}
+
+// buffTime formats the time left on a buff, in its largest whole unit.
+func buffTime(d time.Duration) string {
+	switch {
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	}
+	return fmt.Sprintf("%ds", int((d+time.Second-1)/time.Second))
+}
+
+// buffVisible reports whether a buff's icon shows, flashing as it's about
+// to wear off. It follows the time left, so the flashing keeps pace with
+// the game clock.
+func buffVisible(b BuffIcon) bool {
+	return !b.Expiring || int(b.Remaining/buffFlashRate)%2 == 0
+}
+
+// ringSegments returns the rectangles, as x, y, w, h, of a countdown ring
+// drawn along the edges of a square of side size at x, y: a frame of
+// thickness t that runs clockwise from the top middle and is cut short as
+// fraction falls from 1 to 0.
+func ringSegments(x, y, size, t, fraction float32) [][4]float32 {
+	half := size / 2
+	// The ring's path, as runs along the edges: start, direction, length
+	type run struct{ x, y, dx, dy, length float32 }
+	runs := []run{
+		{x + half, y, 1, 0, half},
+		{x + size - t, y, 0, 1, size},
+		{x + size, y + size - t, -1, 0, size},
+		{x, y + size, 0, -1, size},
+		{x, y, 1, 0, half},
+	}
+	left := max(0, min(fraction, 1)) * 4 * size
+	var rects [][4]float32
+	for _, r := range runs {
+		if left <= 0 {
+			break
+		}
+		l := min(left, r.length)
+		left -= l
+		switch {
+		case r.dx > 0:
+			rects = append(rects, [4]float32{r.x, r.y, l, t})
+		case r.dy > 0:
+			rects = append(rects, [4]float32{r.x, r.y, t, l})
+		case r.dx < 0:
+			rects = append(rects, [4]float32{r.x - l, r.y, l, t})
+		default:
+			rects = append(rects, [4]float32{r.x, r.y - l, t, l})
+		}
+	}
+	return rects
+}
@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBuffLabel(t *testing.T) {
+	tests := []struct{ name, want string }{
+		{"Blessing", "Ble"},
+		{"Increase AGI", "IA"},
+		{"Two-Hand Quicken", "THQ"},
+		{"Status 300", "S3"},
+		{"Kyrie Eleison", "KE"},
+	}
+	for _, tt := range tests {
+		if got := buffLabel(tt.name); got != tt.want {
+			t.Errorf("buffLabel(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBuffTime(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{90 * time.Minute, "1h"},
+		{150 * time.Second, "2m"},
+		{59*time.Second + time.Millisecond, "60s"},
+		{300 * time.Millisecond, "1s"},
+		{0, "0s"},
+	}
+	for _, tt := range tests {
+		if got := buffTime(tt.d); got != tt.want {
+			t.Errorf("buffTime(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestBuffVisible(t *testing.T) {
+	if !buffVisible(BuffIcon{Remaining: 100*time.Millisecond + buffFlashRate}) {
+		t.Error("buff not about to wear off flashed")
+	}
+	on := buffVisible(BuffIcon{Expiring: true, Remaining: 5 * time.Second})
+	off := buffVisible(BuffIcon{Expiring: true, Remaining: 5*time.Second - buffFlashRate})
+	if on == off {
+		t.Error("buff about to wear off didn't flash")
+	}
+}
+
+func TestRingSegments(t *testing.T) {
+	tests := []struct {
+		fraction float32
+		want     [][4]float32
+	}{
+		{0, nil},
+		{0.125, [][4]float32{{5, 0, 5, 1}}},
+		{0.25, [][4]float32{{5, 0, 5, 1}, {9, 0, 1, 5}}},
+		{1, [][4]float32{{5, 0, 5, 1}, {9, 0, 1, 10}, {0, 9, 10, 1}, {0, 0, 1, 10}, {0, 0, 5, 1}}},
+	}
+	for _, tt := range tests {
+		if got := ringSegments(0, 0, 10, 1, tt.fraction); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ringSegments(%v) = %v, want %v", tt.fraction, got, tt.want)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/AllenDang/cimgui-go/imgui"
 
+	"github.com/Faultbox/midgard-ro/internal/game/entity"
 	"github.com/Faultbox/midgard-ro/internal/game/states"
 	"github.com/Faultbox/midgard-ro/internal/network/packets"
 )
@@ -108,7 +109,7 @@ func (ui *CharSelectUI) renderCharacterList(characters []*packets.CharInfo) {
 	// List of characters
 	if imgui.BeginListBoxV("##charlist", imgui.NewVec2(-1, 300)) {
 		for i, char := range characters {
-			label := fmt.Sprintf("%s (Lv %d %s)", char.GetName(), char.BaseLevel, getJobName(char.Class))
+			label := fmt.Sprintf("%s (Lv %d %s)", char.GetName(), char.BaseLevel, entity.JobName(int(char.Class)))
 			isSelected := ui.selectedIndex == i
 			if imgui.SelectableBoolV(label, isSelected, 0, imgui.NewVec2(0, 0)) {
 				ui.selectedIndex = i
@@ -135,7 +136,7 @@ func (ui *CharSelectUI) renderCharacterDetails() {
 		imgui.TableSetupColumnV("Value", imgui.TableColumnFlagsWidthStretch, 0, 0)
 
 		addInfoRow("Name:", char.GetName())
-		addInfoRow("Job:", getJobName(char.Class))
+		addInfoRow("Job:", entity.JobName(int(char.Class)))
 		addInfoRow("Base Level:", fmt.Sprintf("%d", char.BaseLevel))
 		addInfoRow("Job Level:", fmt.Sprintf("%d", char.JobLevel))
 		addInfoRow("HP:", fmt.Sprintf("%d / %d", char.HP, char.MaxHP))
@@ -205,93 +206,3 @@ func addStatRow(name string, value int) {
 	imgui.TableNextColumn()
 	imgui.Text(fmt.Sprintf("%d", value))
 }
-
-// getJobName returns the job class name from the job ID.
-func getJobName(jobID uint16) string {
-	jobs := map[uint16]string{
-		0:    "Novice",
-		1:    "Swordman",
-		2:    "Mage",
-		3:    "Archer",
-		4:    "Acolyte",
-		5:    "Merchant",
-		6:    "Thief",
-		7:    "Knight",
-		8:    "Priest",
-		9:    "Wizard",
-		10:   "Blacksmith",
-		11:   "Hunter",
-		12:   "Assassin",
-		13:   "Knight (Peco)",
-		14:   "Crusader",
-		15:   "Monk",
-		16:   "Sage",
-		17:   "Rogue",
-		18:   "Alchemist",
-		19:   "Bard",
-		20:   "Dancer",
-		21:   "Crusader (Peco)",
-		23:   "Super Novice",
-		24:   "Gunslinger",
-		25:   "Ninja",
-		4001: "High Novice",
-		4002: "High Swordman",
-		4003: "High Mage",
-		4004: "High Archer",
-		4005: "High Acolyte",
-		4006: "High Merchant",
-		4007: "High Thief",
-		4008: "Lord Knight",
-		4009: "High Priest",
-		4010: "High Wizard",
-		4011: "Whitesmith",
-		4012: "Sniper",
-		4013: "Assassin Cross",
-		4014: "Lord Knight (Peco)",
-		4015: "Paladin",
-		4016: "Champion",
-		4017: "Professor",
-		4018: "Stalker",
-		4019: "Creator",
-		4020: "Clown",
-		4021: "Gypsy",
-		4022: "Paladin (Peco)",
-		4023: "Baby",
-		4024: "Baby Swordman",
-		4025: "Baby Mage",
-		4026: "Baby Archer",
-		4027: "Baby Acolyte",
-		4028: "Baby Merchant",
-		4029: "Baby Thief",
-		4030: "Baby Knight",
-		4031: "Baby Priest",
-		4032: "Baby Wizard",
-		4033: "Baby Blacksmith",
-		4034: "Baby Hunter",
-		4035: "Baby Assassin",
-		4045: "Super Baby",
-		4046: "Taekwon",
-		4047: "Star Gladiator",
-		4049: "Soul Linker",
-		4054: "Rune Knight",
-		4055: "Warlock",
-		4056: "Ranger",
-		4057: "Arch Bishop",
-		4058: "Mechanic",
-		4059: "Guillotine Cross",
-		4060: "Rune Knight (Dragon)",
-		4066: "Royal Guard",
-		4067: "Sorcerer",
-		4068: "Minstrel",
-		4069: "Wanderer",
-		4070: "Sura",
-		4071: "Genetic",
-		4072: "Shadow Chaser",
-		4073: "Royal Guard (Gryphon)", //nolint:misspell // "Gryphon" is the RO Royal Guard mount name
-	}
-
-	if name, ok := jobs[jobID]; ok {
-		return name
-	}
-	return fmt.Sprintf("Unknown (%d)", jobID)
-}
@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/ui2d"
+)
+
+// Chat bubble layout, in screen units.
+const (
+	chatBubbleMaxWidth = 180 // Of the text, before it wraps
+	chatBubblePad      = 4
+	chatBubbleGap      = 6 // Between the bubble and the speaker's head
+)
+
+// Characters CJK text doesn't start a line with, closing punctuation and
+// the like, and doesn't end one with.
+const (
+	noLineStart = "、。，．,.!?！？:;：；)]}）」』】〕〉》・ー…ぁぃぅぇぉっゃゅょァィゥェォッャュョ"
+	noLineEnd   = "([{（「『【〔〈《"
+)
+
+// isCJK reports whether r is written without spaces between words, so
+// lines may break on either side of it.
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) ||
+		r >= 0x3000 && r <= 0x303F || r >= 0xFF00 && r <= 0xFFEF // CJK and full-width punctuation
+}
+
+// canBreak reports whether a line may break between a and b.
+func canBreak(a, b rune) bool {
+	if b == ' ' || a == ' ' {
+		return true
+	}
+	if !isCJK(a) && !isCJK(b) {
+		return false
+	}
+	return !strings.ContainsRune(noLineStart, b) && !strings.ContainsRune(noLineEnd, a)
+}
+
+// wrapBubble breaks chat text into lines no wider than maxW as measured.
+// Latin text breaks at spaces; CJK text, which has none, breaks between
+// characters, keeping closing punctuation off the start of a line. A word
+// wider than maxW is broken where it overflows.
+func wrapBubble(text string, maxW float32, measure func(string) float32) []string {
+	var lines []string
+	for _, para := range strings.Split(text, "\n") {
+		runes := []rune(strings.TrimSpace(para))
+		for len(runes) > 0 {
+			// The most runes that fit, at least one
+			n := sort.Search(len(runes), func(i int) bool {
+				return measure(string(runes[:i+1])) > maxW
+			})
+			n = max(n, 1)
+			if n < len(runes) {
+				for k := n; k > 0; k-- {
+					if canBreak(runes[k-1], runes[k]) {
+						n = k
+						break
+					}
+				}
+			}
+			lines = append(lines, strings.TrimRight(string(runes[:n]), " "))
+			runes = []rune(strings.TrimLeft(string(runes[n:]), " "))
+		}
+	}
+	return lines
+}
+
+// bubbleBox lays out a chat bubble: its wrapped lines, and the box behind
+// them, sitting on the bubble's anchor.
+func bubbleBox(b *ChatBubble, lineH float32, measure func(string) float32) (lines []string, box ui2d.Rect) {
+	lines = wrapBubble(b.Text, chatBubbleMaxWidth, measure)
+	var w float32
+	for _, l := range lines {
+		w = max(w, measure(l))
+	}
+	box.W = w + 2*chatBubblePad
+	box.H = float32(len(lines))*lineH + 2*chatBubblePad
+	box.X = b.X - box.W/2
+	box.Y = b.Y - chatBubbleGap - box.H
+	return lines, box
+}
@@ -0,0 +1,32 @@
+package ui
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWrapBubble(t *testing.T) {
+	measure := func(s string) float32 { return float32(len([]rune(s))) * 10 }
+
+	tests := []struct {
+		text string
+		maxW float32
+		want []string
+	}{
+		{"Poring : hi", 180, []string{"Poring : hi"}},
+		{"hello world foo", 110, []string{"hello world", "foo"}},
+		{"abcdefghij", 40, []string{"abcd", "efgh", "ij"}},
+		{"こんにちは世界", 50, []string{"こんにちは", "世界"}},
+		// Closing punctuation stays with the character before it
+		{"はい。です", 30, []string{"はい。", "です"}},
+		{"はい。です", 20, []string{"は", "い。", "です"}},
+		{"hi 안녕하세요", 50, []string{"hi 안녕", "하세요"}},
+		{"two\nlines", 180, []string{"two", "lines"}},
+		{"", 100, nil},
+	}
+	for _, tt := range tests {
+		if got := wrapBubble(tt.text, tt.maxW, measure); !slices.Equal(got, tt.want) {
+			t.Errorf("wrapBubble(%q, %v) = %q, want %q", tt.text, tt.maxW, got, tt.want)
+		}
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/AllenDang/cimgui-go/imgui"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/clock"
 )
 
 // ChatMessage represents a single chat message.
@@ -74,7 +76,7 @@ func NewChatBox() *ChatBox {
 // AddMessage adds a new message to the chat.
 func (cb *ChatBox) AddMessage(channel ChatChannel, sender, message string) {
 	msg := ChatMessage{
-		Timestamp: time.Now(),
+		Timestamp: clock.Now(),
 		Channel:   channel,
 		Sender:    sender,
 		Message:   message,
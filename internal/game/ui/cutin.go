@@ -0,0 +1,35 @@
+package ui
+
+import (
+	"path"
+
+	"github.com/Faultbox/midgard-ro/internal/network/packets"
+)
+
+// cutinTexDir is the GRF folder of the NPC illustrations.
+const cutinTexDir = `data\texture\유저인터페이스\illust\`
+
+// cutinImagePaths returns the GRF paths to try for an illustration.
+// Servers name most without an extension, meaning a BMP; a few are TGA.
+func cutinImagePaths(image string) []string {
+	if path.Ext(image) != "" {
+		return []string{cutinTexDir + image}
+	}
+	return []string{cutinTexDir + image + ".bmp", cutinTexDir + image + ".tga"}
+}
+
+// cutinOrigin places a w x h illustration on a screen of screenW x
+// screenH whose HUD bar takes the bottom bottomBar units, returning its
+// top-left corner. Bottom placements stand on the bar.
+func cutinOrigin(position uint8, w, h, screenW, screenH, bottomBar float32) (x, y float32) {
+	bottom := screenH - bottomBar - h
+	switch position {
+	case packets.CutinBottomLeft:
+		return 0, bottom
+	case packets.CutinBottomCenter:
+		return (screenW - w) / 2, bottom
+	case packets.CutinBottomRight:
+		return screenW - w, bottom
+	}
+	return (screenW - w) / 2, (screenH - h) / 2
+}
@@ -0,0 +1,35 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Faultbox/midgard-ro/internal/network/packets"
+)
+
+func TestCutinImagePaths(t *testing.T) {
+	if got, want := cutinImagePaths("kafra_01"), []string{cutinTexDir + "kafra_01.bmp", cutinTexDir + "kafra_01.tga"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("cutinImagePaths(kafra_01) = %q, want %q", got, want)
+	}
+	if got, want := cutinImagePaths("ein_soldier.tga"), []string{cutinTexDir + "ein_soldier.tga"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("cutinImagePaths(ein_soldier.tga) = %q, want %q", got, want)
+	}
+}
+
+func TestCutinOrigin(t *testing.T) {
+	tests := []struct {
+		position uint8
+		x, y     float32
+	}{
+		{packets.CutinBottomLeft, 0, 375},
+		{packets.CutinBottomCenter, 300, 375},
+		{packets.CutinBottomRight, 600, 375},
+		{packets.CutinWindow, 300, 200},
+		{packets.CutinCenter, 300, 200},
+	}
+	for _, tt := range tests {
+		if x, y := cutinOrigin(tt.position, 200, 200, 800, 600, 25); x != tt.x || y != tt.y {
+			t.Errorf("cutinOrigin(%d) = %v, %v, want %v, %v", tt.position, x, y, tt.x, tt.y)
+		}
+	}
+}
@@ -68,6 +68,25 @@ type DebugOverlay struct {
 	LastRecvAgo     time.Duration
 	LastRecvLen     int
 
+	// Send queue stats
+	PacketsQueued    int
+	PacketsCoalesced uint64
+	PacketsDeferred  uint64
+	SendWrites       uint64
+
+	// Socket goroutine stats
+	InboundQueued int
+	InboundPeak   int
+	ReadStalls    uint64
+	WriteStalls   uint64
+
+	// Packet framing stats
+	UnknownPackets  uint64
+	LastUnknownID   uint16
+	Resyncs         uint64
+	DroppedBytes    uint64
+	VersionMismatch bool
+
 	// Render stats
 	DrawCalls       int
 	Triangles       int
@@ -251,6 +270,13 @@ func (d *DebugOverlay) renderNetworkInfo() {
 	imgui.Text("Network")
 	imgui.Text(fmt.Sprintf("  Sent: %d pkts (%s)", d.PacketsSent, formatBytes(int64(d.BytesSent))))
 	imgui.Text(fmt.Sprintf("  Recv: %d pkts (%s)", d.PacketsReceived, formatBytes(int64(d.BytesReceived))))
+	imgui.Text(fmt.Sprintf("  Queue: %d  Writes: %d", d.PacketsQueued, d.SendWrites))
+	imgui.Text(fmt.Sprintf("  Coalesced: %d  Deferred: %d", d.PacketsCoalesced, d.PacketsDeferred))
+	imgui.Text(fmt.Sprintf("  Inbound: %d (peak %d)  Stalls: %d read, %d write", d.InboundQueued, d.InboundPeak, d.ReadStalls, d.WriteStalls))
+	imgui.Text(fmt.Sprintf("  Unknown: %d (last 0x%04X)  Resyncs: %d (%s dropped)", d.UnknownPackets, d.LastUnknownID, d.Resyncs, formatBytes(int64(d.DroppedBytes))))
+	if d.VersionMismatch {
+		imgui.TextColored(imgui.NewVec4(1, 0.4, 0.4, 1), "  Packetver mismatch suspected")
+	}
 	if d.LastSentID != 0 {
 		imgui.Text(fmt.Sprintf("  -> 0x%04X (%dB) %s ago", d.LastSentID, d.LastSentLen, formatAgo(d.LastSentAgo)))
 	}
@@ -0,0 +1,74 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// wrapText breaks text into lines no wider than maxW as measured, at
+// spaces. A word wider than maxW gets a line of its own.
+func wrapText(text string, maxW float32, measure func(string) float32) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line == "" {
+			line = word
+			continue
+		}
+		if next := line + " " + word; measure(next) <= maxW {
+			line = next
+			continue
+		}
+		lines = append(lines, line)
+		line = word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// wrapLines wraps each "\n"-separated line of text like wrapText, keeping
+// blank lines.
+func wrapLines(text string, maxW float32, measure func(string) float32) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		wrapped := wrapText(line, maxW, measure)
+		if len(wrapped) == 0 {
+			wrapped = []string{""}
+		}
+		lines = append(lines, wrapped...)
+	}
+	return lines
+}
+
+// historyRow is a row of the dialog history window: a line, or the name
+// of the NPC whose lines follow.
+type historyRow struct {
+	Text   string
+	Header bool
+}
+
+// historyRows lays out the dialog history, wrapping its lines and heading
+// each NPC's run of lines with the NPC's name.
+func historyRows(lines []DialogHistoryLine, maxW float32, measure func(string) float32) []historyRow {
+	var rows []historyRow
+	for i, line := range lines {
+		if line.Name != "" && (i == 0 || lines[i-1].Name != line.Name) {
+			rows = append(rows, historyRow{Text: line.Name, Header: true})
+		}
+		for _, text := range wrapLines(line.Text, maxW, measure) {
+			rows = append(rows, historyRow{Text: text})
+		}
+	}
+	return rows
+}
+
+// countdownLabel describes the time left to answer a request, or "" if it
+// waits.
+func countdownLabel(remaining float64) string {
+	if remaining <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("Declining in %ds", int(remaining+0.999))
+}
@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWrapText(t *testing.T) {
+	measure := func(s string) float32 { return float32(len([]rune(s))) * 8 }
+
+	tests := []struct {
+		text string
+		maxW float32
+		want []string
+	}{
+		{"Join the party?", 200, []string{"Join the party?"}},
+		{"You have been invited to join", 100, []string{"You have", "been invited", "to join"}},
+		{"Supercalifragilistic party", 80, []string{"Supercalifragilistic", "party"}},
+		{"  spaced   out  ", 200, []string{"spaced out"}},
+		{"", 100, nil},
+	}
+	for _, tt := range tests {
+		if got := wrapText(tt.text, tt.maxW, measure); !slices.Equal(got, tt.want) {
+			t.Errorf("wrapText(%q, %v) = %q, want %q", tt.text, tt.maxW, got, tt.want)
+		}
+	}
+}
+
+func TestWrapLines(t *testing.T) {
+	measure := func(s string) float32 { return float32(len([]rune(s))) * 8 }
+	got := wrapLines("[Kafra]\nWelcome to the Kafra\n\nBye", 100, measure)
+	want := []string{"[Kafra]", "Welcome to", "the Kafra", "", "Bye"}
+	if !slices.Equal(got, want) {
+		t.Errorf("wrapLines = %q, want %q", got, want)
+	}
+}
+
+func TestHistoryRows(t *testing.T) {
+	measure := func(s string) float32 { return float32(len([]rune(s))) * 8 }
+	lines := []DialogHistoryLine{
+		{Name: "Kafra", Text: "[Kafra]"},
+		{Name: "Kafra", Text: "Welcome to the Kafra"},
+		{Name: "Guard", Text: "Halt!"},
+	}
+	want := []historyRow{
+		{Text: "Kafra", Header: true},
+		{Text: "[Kafra]"},
+		{Text: "Welcome to"},
+		{Text: "the Kafra"},
+		{Text: "Guard", Header: true},
+		{Text: "Halt!"},
+	}
+	if got := historyRows(lines, 100, measure); !slices.Equal(got, want) {
+		t.Errorf("historyRows = %v, want %v", got, want)
+	}
+}
+
+func TestCountdownLabel(t *testing.T) {
+	tests := []struct {
+		remaining float64
+		want      string
+	}{
+		{0, ""},
+		{29.2, "Declining in 30s"},
+		{1, "Declining in 1s"},
+		{0.1, "Declining in 1s"},
+	}
+	for _, tt := range tests {
+		if got := countdownLabel(tt.remaining); got != tt.want {
+			t.Errorf("countdownLabel(%v) = %q, want %q", tt.remaining, got, tt.want)
+		}
+	}
+}
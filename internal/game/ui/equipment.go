@@ -0,0 +1,177 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/ui2d"
+	"github.com/Faultbox/midgard-ro/internal/game/entity"
+)
+
+// DollSlot is a place on the equipment doll.
+type DollSlot struct {
+	Label    string
+	Location entity.EquipLocation
+}
+
+// The equipment doll's columns, top to bottom, either side of the
+// silhouette as in the original client's window, and the ammunition slot
+// under it.
+var (
+	DollLeft = []DollSlot{
+		{"Upper Headgear", entity.EquipHeadTop},
+		{"Armor", entity.EquipArmor},
+		{"Weapon", entity.EquipWeapon},
+		{"Garment", entity.EquipGarment},
+		{"Accessory", entity.EquipAccRight},
+	}
+	DollRight = []DollSlot{
+		{"Middle Headgear", entity.EquipHeadMid},
+		{"Lower Headgear", entity.EquipHeadLow},
+		{"Shield", entity.EquipShield},
+		{"Shoes", entity.EquipShoes},
+		{"Accessory", entity.EquipAccLeft},
+	}
+	DollAmmo = DollSlot{"Ammunition", entity.EquipAmmo}
+)
+
+// WornIn returns the item worn in a doll slot. An item covering several
+// slots, like a two-handed weapon, shows in each.
+func WornIn(items []InventoryItem, loc entity.EquipLocation) (InventoryItem, bool) {
+	for _, item := range items {
+		if item.Worn&loc != 0 {
+			return item, true
+		}
+	}
+	return InventoryItem{}, false
+}
+
+// Label returns the item name with its refine and card slots, such as
+// "+7 Item #1201 [1]". Items whose slots aren't known show their cards.
+func (it InventoryItem) Label() string {
+	label := it.Name
+	if it.Refine > 0 {
+		label = fmt.Sprintf("+%d %s", it.Refine, label)
+	}
+	slots := it.Slots
+	if slots == 0 {
+		slots = it.Cards
+	}
+	if slots > 0 {
+		label = fmt.Sprintf("%s [%d]", label, slots)
+	}
+	return label
+}
+
+// Item grade colors, on the light window background.
+var (
+	ColorItemCarded  = ui2d.Color{R: 0.15, G: 0.35, B: 0.85, A: 1}
+	ColorItemRefined = ui2d.Color{R: 0.55, G: 0.2, B: 0.75, A: 1}
+	ColorItemDamaged = ui2d.Color{R: 0.8, G: 0.15, B: 0.15, A: 1}
+)
+
+// ItemColor returns the color lists show an item of a grade in, or false
+// for normal items, which keep the text color.
+func ItemColor(grade entity.ItemGrade) (ui2d.Color, bool) {
+	switch grade {
+	case entity.GradeCarded:
+		return ColorItemCarded, true
+	case entity.GradeRefined:
+		return ColorItemRefined, true
+	case entity.GradeDamaged:
+		return ColorItemDamaged, true
+	case entity.GradeUnidentified:
+		return ui2d.ColorTextDim, true
+	}
+	return ui2d.ColorText, false
+}
+
+// TooltipSection is a titled block of an item tooltip.
+type TooltipSection struct {
+	Title string
+	Lines []string
+	Grade entity.ItemGrade // Of the item it describes, coloring the title
+}
+
+// EquipTooltip describes an equipment item and compares it with what it
+// would replace: the differences are shown next to its values, and each
+// replaced item follows in its own section. Returns nil for items that
+// aren't equipment.
+func EquipTooltip(item InventoryItem, items []InventoryItem) []TooltipSection {
+	if item.Location == 0 {
+		return nil
+	}
+	var replaced []InventoryItem
+	if item.Worn == 0 {
+		for _, other := range items {
+			if other.Index != item.Index && other.Worn&item.Location != 0 {
+				replaced = append(replaced, other)
+			}
+		}
+	}
+
+	// Deltas only make sense against a single item
+	var against *InventoryItem
+	if len(replaced) == 1 {
+		against = &replaced[0]
+	}
+	sections := []TooltipSection{{Title: item.Label(), Lines: equipLines(item, against), Grade: item.Grade}}
+	if item.Worn != 0 {
+		sections[0].Lines = append(sections[0].Lines, "Equipped")
+	} else if len(replaced) == 0 {
+		sections[0].Lines = append(sections[0].Lines, "Nothing equipped there")
+	}
+	for _, r := range replaced {
+		sections = append(sections, TooltipSection{Title: "Equipped: " + r.Label(), Lines: equipLines(r, nil), Grade: r.Grade})
+	}
+	return sections
+}
+
+// equipLines returns an item's tooltip lines, with the differences from
+// against when it's set.
+func equipLines(item InventoryItem, against *InventoryItem) []string {
+	lines := []string{"Slot: " + item.Location.String()}
+	refine := fmt.Sprintf("Refine: +%d", item.Refine)
+	cards := fmt.Sprintf("Cards: %d", item.Cards)
+	if against != nil {
+		refine += delta(item.Refine - against.Refine)
+		cards += delta(item.Cards - against.Cards)
+	}
+	lines = append(lines, refine, cards)
+	for i, card := range item.SlotCards {
+		if card == "" {
+			card = "empty"
+		}
+		lines = append(lines, fmt.Sprintf("Card slot %d: %s", i+1, card))
+	}
+	return lines
+}
+
+// delta formats a difference for a comparison, empty when there's none.
+func delta(d int) string {
+	if d == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%+d)", d)
+}
+
+// doubleClickTime is the longest gap between the clicks of a double-click.
+const doubleClickTime = 400 * time.Millisecond
+
+// doubleClick detects a second click on the same target, for the 2D
+// backend whose input has no double-click event.
+type doubleClick struct {
+	target int
+	at     time.Time
+}
+
+// click records a click on target and reports whether it completes a
+// double-click.
+func (d *doubleClick) click(target int, now time.Time) bool {
+	if target == d.target && now.Sub(d.at) <= doubleClickTime {
+		d.at = time.Time{}
+		return true
+	}
+	d.target, d.at = target, now
+	return false
+}
@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Faultbox/midgard-ro/internal/game/entity"
+)
+
+func TestEquipTooltip(t *testing.T) {
+	sword := InventoryItem{Index: 2, Name: "Item #1101", Location: entity.EquipWeapon, Worn: entity.EquipWeapon, Refine: 4}
+	shield := InventoryItem{Index: 3, Name: "Item #2101", Location: entity.EquipShield, Worn: entity.EquipShield, Cards: 1}
+	blade := InventoryItem{Index: 4, Name: "Item #1116", Location: entity.EquipWeapon, Refine: 7, Cards: 1}
+	twoHanded := InventoryItem{Index: 5, Name: "Item #1151", Location: entity.EquipWeapon | entity.EquipShield}
+	items := []InventoryItem{sword, shield, blade, twoHanded}
+
+	tests := []struct {
+		name string
+		item InventoryItem
+		want []TooltipSection
+	}{
+		{"compared", blade, []TooltipSection{
+			{Title: "+7 Item #1116 [1]", Lines: []string{"Slot: Weapon", "Refine: +7 (+3)", "Cards: 1 (+1)"}},
+			{Title: "Equipped: +4 Item #1101", Lines: []string{"Slot: Weapon", "Refine: +4", "Cards: 0"}},
+		}},
+		{"replaces two", twoHanded, []TooltipSection{
+			{Title: "Item #1151", Lines: []string{"Slot: Weapon, Shield", "Refine: +0", "Cards: 0"}},
+			{Title: "Equipped: +4 Item #1101", Lines: []string{"Slot: Weapon", "Refine: +4", "Cards: 0"}},
+			{Title: "Equipped: Item #2101 [1]", Lines: []string{"Slot: Shield", "Refine: +0", "Cards: 1"}},
+		}},
+		{"worn", sword, []TooltipSection{
+			{Title: "+4 Item #1101", Lines: []string{"Slot: Weapon", "Refine: +4", "Cards: 0", "Equipped"}},
+		}},
+		{"empty slot", InventoryItem{Name: "Item #2301", Location: entity.EquipArmor}, []TooltipSection{
+			{Title: "Item #2301", Lines: []string{"Slot: Armor", "Refine: +0", "Cards: 0", "Nothing equipped there"}},
+		}},
+		{"slotted", InventoryItem{Name: "Item #2302", Location: entity.EquipArmor, Cards: 1, Slots: 2, SlotCards: []string{"Item #4001", ""}, Grade: entity.GradeCarded}, []TooltipSection{
+			{Title: "Item #2302 [2]", Lines: []string{"Slot: Armor", "Refine: +0", "Cards: 1", "Card slot 1: Item #4001", "Card slot 2: empty", "Nothing equipped there"}, Grade: entity.GradeCarded},
+		}},
+		{"not equipment", InventoryItem{Name: "Item #501", Amount: 5}, nil},
+	}
+	for _, tt := range tests {
+		if got := EquipTooltip(tt.item, items); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: EquipTooltip() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	if got, ok := WornIn(items, entity.EquipShield); !ok || got.Index != 3 {
+		t.Errorf("WornIn(shield) = %+v, %v", got, ok)
+	}
+}
+
+func TestDoubleClick(t *testing.T) {
+	var d doubleClick
+	now := time.Now()
+	if d.click(4, now) {
+		t.Error("first click reported a double-click")
+	}
+	if !d.click(4, now.Add(200*time.Millisecond)) {
+		t.Error("second click within the gap missed")
+	}
+	if d.click(4, now.Add(300*time.Millisecond)) {
+		t.Error("third click started another double-click")
+	}
+	d.click(5, now.Add(time.Second))
+	if d.click(4, now.Add(time.Second+100*time.Millisecond)) {
+		t.Error("clicks on different targets made a double-click")
+	}
+	if d.click(4, now.Add(2*time.Second)) {
+		t.Error("click after the gap made a double-click")
+	}
+}
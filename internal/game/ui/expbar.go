@@ -0,0 +1,29 @@
+package ui
+
+// expFillRate controls how fast the displayed experience bar catches up with
+// the real value (fraction of the remaining distance per second).
+const expFillRate = 4.0
+
+// expFill animates an experience bar towards its target ratio. On a level-up
+// the bar restarts from empty so the gain wraps around instead of draining.
+type expFill struct {
+	shown float32
+	level int
+}
+
+// update advances the animation by dt seconds and returns the ratio to draw.
+func (f *expFill) update(target float32, level int, dt float64) float32 {
+	if f.level != 0 && level > f.level {
+		f.shown = 0
+	}
+	f.level = level
+
+	diff := target - f.shown
+	step := float32(dt * expFillRate)
+	if step >= 1 || diff*diff < 1e-6 {
+		f.shown = target
+	} else {
+		f.shown += diff * step
+	}
+	return f.shown
+}
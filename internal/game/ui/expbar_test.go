@@ -0,0 +1,30 @@
+package ui
+
+import "testing"
+
+func TestExpFillAnimatesTowardsTarget(t *testing.T) {
+	var f expFill
+
+	first := f.update(0.5, 10, 0.1)
+	if first <= 0 || first >= 0.5 {
+		t.Fatalf("first step = %v, want between 0 and 0.5", first)
+	}
+	second := f.update(0.5, 10, 0.1)
+	if second <= first || second > 0.5 {
+		t.Errorf("second step = %v, want between %v and 0.5", second, first)
+	}
+
+	// A long frame snaps to the target
+	if got := f.update(0.5, 10, 1); got != 0.5 {
+		t.Errorf("long frame = %v, want 0.5", got)
+	}
+}
+
+func TestExpFillWrapsOnLevelUp(t *testing.T) {
+	f := expFill{shown: 0.9, level: 10}
+
+	got := f.update(0.2, 11, 0.1)
+	if got <= 0 || got >= 0.2 {
+		t.Errorf("after level-up = %v, want to refill from 0 towards 0.2", got)
+	}
+}
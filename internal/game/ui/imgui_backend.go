@@ -3,11 +3,18 @@ package ui
 
 import (
 	"fmt"
+	"math"
+	"strings"
 
 	"github.com/AllenDang/cimgui-go/imgui"
 	"github.com/go-gl/gl/v4.1-core/gl"
 
+	"github.com/Faultbox/midgard-ro/internal/engine/camera"
+	"github.com/Faultbox/midgard-ro/internal/engine/pacing"
+	"github.com/Faultbox/midgard-ro/internal/engine/postfx"
 	"github.com/Faultbox/midgard-ro/internal/engine/ui2d"
+	"github.com/Faultbox/midgard-ro/internal/game/entity"
+	"github.com/Faultbox/midgard-ro/internal/game/settings"
 	"github.com/Faultbox/midgard-ro/internal/network/packets"
 )
 
@@ -23,6 +30,10 @@ type ImGuiBackend struct {
 	charSelectUI *ImGuiCharSelectUI
 	loadingUI    *ImGuiLoadingUI
 	inGameUI     *ImGuiInGameUI
+
+	// UI scale being dragged in the settings, applied on release
+	scaleDrag    float32
+	scaleEditing bool
 }
 
 // NewImGuiBackend creates a new ImGui UI backend.
@@ -119,6 +130,26 @@ func (b *ImGuiBackend) RenderLoadingUI(state LoadingUIState, width, height float
 	b.loadingUI.Render(state, width, height)
 }
 
+// RenderDisconnectedUI renders the error screen shown when the session ends.
+func (b *ImGuiBackend) RenderDisconnectedUI(state DisconnectedUIState, width, height float32) {
+	windowWidth := float32(360)
+	windowHeight := float32(160)
+	imgui.SetNextWindowPos(imgui.NewVec2((width-windowWidth)/2, (height-windowHeight)/2))
+	imgui.SetNextWindowSize(imgui.NewVec2(windowWidth, windowHeight))
+
+	flags := imgui.WindowFlagsNoResize | imgui.WindowFlagsNoMove | imgui.WindowFlagsNoCollapse
+	if imgui.BeginV(state.Title+"###disconnected", nil, flags) {
+		imgui.Spacing()
+		imgui.TextWrapped(state.Message)
+		imgui.Spacing()
+		imgui.Spacing()
+		if imgui.ButtonV("Return to Login", imgui.NewVec2(-1, 0)) && state.OnReturn != nil {
+			state.OnReturn()
+		}
+	}
+	imgui.End()
+}
+
 // RenderInGameUI renders the in-game HUD.
 func (b *ImGuiBackend) RenderInGameUI(state InGameUIState, dt float64, width, height float32) {
 	if b.inGameUI == nil {
@@ -140,19 +171,269 @@ func (b *ImGuiBackend) RenderFPSOverlay(fps float64, width, height float32) {
 	imgui.End()
 }
 
-// RenderScreenshotMessage renders a screenshot notification.
-func (b *ImGuiBackend) RenderScreenshotMessage(msg string, width, height float32) {
-	msgWidth := float32(300)
-	imgui.SetNextWindowPos(imgui.NewVec2((width-msgWidth)/2, height-60))
-	imgui.SetNextWindowSize(imgui.NewVec2(msgWidth, 0))
-	imgui.SetNextWindowBgAlpha(0.8)
+// RenderNotifications renders the notification toasts up from the
+// bottom right corner, newest at the bottom. Clicking one hands it to
+// OnClick.
+func (b *ImGuiBackend) RenderNotifications(state NotificationsState, width, height float32) {
+	flags := imgui.WindowFlagsNoTitleBar | imgui.WindowFlagsNoResize |
+		imgui.WindowFlagsNoMove | imgui.WindowFlagsNoSavedSettings |
+		imgui.WindowFlagsNoFocusOnAppearing | imgui.WindowFlagsAlwaysAutoResize |
+		imgui.WindowFlagsNoScrollbar
+	y := height - 60
+	for i := len(state.Items) - 1; i >= 0; i-- {
+		n := state.Items[i]
+		imgui.SetNextWindowPosV(imgui.NewVec2(width-10, y), imgui.CondAlways, imgui.NewVec2(1, 1))
+		imgui.SetNextWindowBgAlpha(0.8)
+		imgui.PushStyleVarFloat(imgui.StyleVarAlpha, n.Alpha)
+		if imgui.BeginV(fmt.Sprintf("##Notify%d", n.ID), nil, flags) {
+			imgui.TextColored(paletteVec4(n.Kind.Color()), "["+n.Kind.Icon()+"]")
+			imgui.SameLine()
+			imgui.Text(n.Label())
+			if imgui.IsWindowHovered() && imgui.IsMouseClickedBool(imgui.MouseButtonLeft) && state.OnClick != nil {
+				state.OnClick(n)
+			}
+			y -= imgui.WindowSize().Y + 4
+		}
+		imgui.End()
+		imgui.PopStyleVar()
+	}
+}
+
+// RenderScreenFlash renders a full-screen white flash.
+func (b *ImGuiBackend) RenderScreenFlash(alpha float32, width, height float32) {
+	if alpha <= 0 {
+		return
+	}
+	imgui.SetNextWindowPos(imgui.NewVec2(0, 0))
+	imgui.SetNextWindowSize(imgui.NewVec2(width, height))
+	imgui.SetNextWindowBgAlpha(0)
 	flags := imgui.WindowFlagsNoTitleBar | imgui.WindowFlagsNoResize |
 		imgui.WindowFlagsNoMove | imgui.WindowFlagsNoInputs |
-		imgui.WindowFlagsAlwaysAutoResize
-	if imgui.BeginV("##Screenshot", nil, flags) {
-		imgui.TextColored(imgui.NewVec4(0.2, 1.0, 0.2, 1.0), msg)
+		imgui.WindowFlagsNoScrollbar | imgui.WindowFlagsNoSavedSettings
+	if imgui.BeginV("##ScreenFlash", nil, flags) {
+		drawList := imgui.WindowDrawList()
+		drawList.AddRectFilledV(imgui.NewVec2(0, 0), imgui.NewVec2(width, height),
+			imgui.ColorU32Vec4(imgui.NewVec4(1, 1, 1, alpha)), 0, 0)
+	}
+	imgui.End()
+}
+
+// RenderScreenshotGallery renders the recent screenshots window.
+func (b *ImGuiBackend) RenderScreenshotGallery(state ScreenshotGalleryState, width, height float32) {
+	const thumbW, thumbH = float32(160), float32(120)
+
+	imgui.SetNextWindowPosV(imgui.NewVec2(width/2, height/2), imgui.CondAppearing, imgui.NewVec2(0.5, 0.5))
+	imgui.SetNextWindowSizeV(imgui.NewVec2(560, 420), imgui.CondAppearing)
+
+	open := true
+	if imgui.BeginV("Screenshots", &open, 0) {
+		imgui.TextDisabled(state.Dir)
+		imgui.Separator()
+
+		if len(state.Entries) == 0 {
+			imgui.Text("No screenshots yet. Press F12 to capture.")
+		}
+
+		avail := imgui.ContentRegionAvail().X
+		columns := max(int(avail/(thumbW+8)), 1)
+		for i, entry := range state.Entries {
+			if i%columns != 0 {
+				imgui.SameLine()
+			}
+			imgui.BeginGroup()
+			if entry.TextureID != 0 {
+				w, h := thumbW, thumbH
+				if entry.Width > 0 && entry.Height > 0 {
+					h = thumbW * float32(entry.Height) / float32(entry.Width)
+				}
+				texRef := imgui.NewTextureRefTextureID(imgui.TextureID(entry.TextureID))
+				imgui.ImageV(*texRef, imgui.NewVec2(w, h), imgui.NewVec2(0, 0), imgui.NewVec2(1, 1))
+			} else {
+				imgui.Dummy(imgui.NewVec2(thumbW, thumbH))
+			}
+			imgui.Text(entry.Name)
+			imgui.EndGroup()
+		}
+	}
+	imgui.End()
+
+	if !open && state.OnClose != nil {
+		state.OnClose()
+	}
+}
+
+// SetUIScale is a no-op: ImGui windows keep ImGui's own style sizing.
+// The UI scale applies to the ui2d backend.
+func (b *ImGuiBackend) SetUIScale(_ float32) {}
+
+// WindowLayout returns nil: ImGui windows are laid out each frame and
+// don't remember being moved.
+func (b *ImGuiBackend) WindowLayout() settings.Windows {
+	return nil
+}
+
+// SetWindowLayout is a no-op, see WindowLayout.
+func (b *ImGuiBackend) SetWindowLayout(_ settings.Windows) {}
+
+// RenderSystemMenu renders the Escape menu.
+func (b *ImGuiBackend) RenderSystemMenu(state SystemMenuState, width, height float32) {
+	imgui.SetNextWindowPosV(imgui.NewVec2(width/2, height/2), imgui.CondAlways, imgui.NewVec2(0.5, 0.5))
+	flags := imgui.WindowFlagsNoResize | imgui.WindowFlagsNoCollapse |
+		imgui.WindowFlagsNoSavedSettings | imgui.WindowFlagsAlwaysAutoResize
+
+	var selected func()
+	if imgui.BeginV("Menu##SystemMenu", nil, flags) {
+		size := imgui.NewVec2(200, 0)
+		imgui.BeginDisabledV(state.CharSelectPending)
+		label := "Character Select"
+		if state.CharSelectPending {
+			label = "Switching..."
+		}
+		if imgui.ButtonV(label, size) {
+			selected = state.OnCharSelect
+		}
+		imgui.EndDisabled()
+		if imgui.ButtonV("Settings", size) {
+			selected = state.OnSettings
+		}
+		if imgui.ButtonV("Exit Game", size) {
+			selected = state.OnExit
+		}
+		if imgui.ButtonV("Back to Game", size) {
+			selected = state.OnClose
+		}
+	}
+	imgui.End()
+
+	if selected != nil {
+		selected()
+	}
+}
+
+// RenderSettingsUI renders the settings window.
+func (b *ImGuiBackend) RenderSettingsUI(state SettingsUIState, width, height float32) {
+	imgui.SetNextWindowPosV(imgui.NewVec2(width/2, height/2), imgui.CondAppearing, imgui.NewVec2(0.5, 0.5))
+
+	open := true
+	if imgui.BeginV("Settings", &open, imgui.WindowFlagsAlwaysAutoResize) {
+		scale := state.UIScale
+		if b.scaleEditing {
+			scale = b.scaleDrag
+		}
+		imgui.SetNextItemWidth(200)
+		imgui.SliderFloatV("UI Scale", &scale, ui2d.MinScale, ui2d.MaxScale, "%.2fx", imgui.SliderFlagsNone)
+		b.scaleDrag, b.scaleEditing = scale, imgui.IsItemActive()
+		if imgui.IsItemDeactivatedAfterEdit() && state.OnUIScaleChange != nil {
+			state.OnUIScaleChange(ui2d.ClampScale(scale))
+		}
+
+		auras := state.Auras
+		if imgui.Checkbox("Character auras", &auras) && state.OnAurasChange != nil {
+			state.OnAurasChange(auras)
+		}
+		instant := state.InstantDialogText
+		if imgui.Checkbox("Instant NPC text", &instant) && state.OnInstantDialogTextChange != nil {
+			state.OnInstantDialogTextChange(instant)
+		}
+		if imgui.ButtonV("Language: "+state.Language+"###language", imgui.NewVec2(0, 0)) && state.OnLanguageNext != nil {
+			state.OnLanguageNext()
+		}
+
+		if imgui.ButtonV("FPS cap: "+fpsLimitLabel(state.FPSLimit)+"###fps_limit", imgui.NewVec2(0, 0)) && state.OnFPSLimitChange != nil {
+			state.OnFPSLimitChange(pacing.NextLimit(state.FPSLimit))
+		}
+		vsync := state.VSync
+		if imgui.Checkbox("VSync", &vsync) && state.OnVSyncChange != nil {
+			state.OnVSyncChange(vsync)
+		}
+		throttle := state.BackgroundThrottle
+		if imgui.Checkbox("Slow down in background", &throttle) && state.OnThrottleChange != nil {
+			state.OnThrottleChange(throttle)
+		}
+
+		gamma := state.Gamma
+		imgui.SetNextItemWidth(200)
+		if imgui.SliderFloatV("Gamma", &gamma, postfx.MinGamma, postfx.MaxGamma, "%.1f", imgui.SliderFlagsNone) &&
+			state.OnGammaChange != nil {
+			state.OnGammaChange(gamma)
+		}
+		brightness := state.Brightness
+		imgui.SetNextItemWidth(200)
+		if imgui.SliderFloatV("Brightness", &brightness, postfx.MinBrightness, postfx.MaxBrightness, "%.1fx", imgui.SliderFlagsNone) &&
+			state.OnBrightnessChange != nil {
+			state.OnBrightnessChange(brightness)
+		}
+		fxaa := state.FXAA
+		if imgui.Checkbox("Anti-aliasing (FXAA)", &fxaa) && state.OnFXAAChange != nil {
+			state.OnFXAAChange(fxaa)
+		}
+		if state.HasColorLUT {
+			grading := state.ColorGrading
+			if imgui.Checkbox("Color grading", &grading) && state.OnColorGradingChange != nil {
+				state.OnColorGradingChange(grading)
+			}
+		}
+
+		imgui.SeparatorText("Accessibility")
+		palette := ui2d.PaletteByName(state.Palette)
+		imgui.SetNextItemWidth(200)
+		if imgui.BeginCombo("Colors", palette.Label) {
+			for _, p := range ui2d.Palettes {
+				if imgui.SelectableBoolV(p.Label, p.Name == palette.Name, 0, imgui.NewVec2(0, 0)) &&
+					state.OnPaletteChange != nil {
+					state.OnPaletteChange(p.Name)
+				}
+			}
+			imgui.EndCombo()
+		}
+
+		damageScale := state.DamageTextScale
+		imgui.SetNextItemWidth(200)
+		if imgui.SliderFloatV("Damage Numbers", &damageScale, ui2d.MinDamageTextScale, ui2d.MaxDamageTextScale, "%.2fx", imgui.SliderFlagsNone) &&
+			state.OnDamageTextScaleChange != nil {
+			state.OnDamageTextScaleChange(ui2d.ClampDamageTextScale(damageScale))
+		}
+
+		reduce := state.ReduceFlashes
+		if imgui.Checkbox("Reduce flashes", &reduce) && state.OnReduceFlashesChange != nil {
+			state.OnReduceFlashesChange(reduce)
+		}
+
+		cameraFX := state.CameraEffects
+		if imgui.Checkbox("Camera shake and zoom", &cameraFX) && state.OnCameraEffectsChange != nil {
+			state.OnCameraEffectsChange(cameraFX)
+		}
+		if cameraFX {
+			strength := state.CameraEffectScale
+			imgui.SetNextItemWidth(200)
+			if imgui.SliderFloatV("Camera effect strength", &strength, camera.MinEffectScale, camera.MaxEffectScale, "%.2fx", imgui.SliderFlagsNone) &&
+				state.OnCameraEffectScaleChange != nil {
+				state.OnCameraEffectScaleChange(camera.ClampEffectScale(strength))
+			}
+		}
+
+		imgui.SeparatorText("Nameplates")
+		for i, np := range state.Nameplates {
+			if imgui.ButtonV(np.Mode+"##nameplate"+np.Label, imgui.NewVec2(80, 0)) && state.OnNameplateModeCycle != nil {
+				state.OnNameplateModeCycle(i)
+			}
+			imgui.SameLine()
+			imgui.Text(np.Label)
+		}
+		guild := state.NameplateGuild
+		if imgui.Checkbox("Guild names", &guild) && state.OnNameplateGuildChange != nil {
+			state.OnNameplateGuildChange(guild)
+		}
+		partyHP := state.NameplatePartyHP
+		if imgui.Checkbox("Party HP bars", &partyHP) && state.OnNameplatePartyHPChange != nil {
+			state.OnNameplatePartyHPChange(partyHP)
+		}
 	}
 	imgui.End()
+
+	if !open && state.OnClose != nil {
+		state.OnClose()
+	}
 }
 
 // updateInputFromImGui updates the ui2d InputState from ImGui.
@@ -199,6 +480,9 @@ func (ui *ImGuiLoginUI) Render(state LoginUIState, viewportWidth, viewportHeight
 
 	windowWidth := float32(350)
 	windowHeight := float32(250)
+	if state.OnCancel != nil {
+		windowHeight += 50
+	}
 	windowX := (viewportWidth - windowWidth) / 2
 	windowY := (viewportHeight - windowHeight) / 2
 
@@ -249,10 +533,20 @@ func (ui *ImGuiLoginUI) Render(state LoginUIState, viewportWidth, viewportHeight
 		}
 		imgui.EndDisabled()
 
-		if state.IsLoading {
+		switch {
+		case state.WaitMessage != "":
+			imgui.Spacing()
+			imgui.TextWrapped(state.WaitMessage)
+		case state.IsLoading:
 			imgui.Spacing()
 			imguiCenterText("Connecting...")
 		}
+		if state.OnCancel != nil {
+			imgui.Spacing()
+			if imgui.ButtonV("Cancel", imgui.NewVec2(-1, 0)) {
+				state.OnCancel()
+			}
+		}
 
 		imgui.Spacing()
 		imgui.Separator()
@@ -304,6 +598,7 @@ func (ui *ImGuiConnectingUI) Render(state ConnectingUIState, viewportWidth, view
 // ImGuiCharSelectUI renders the character selection UI using ImGui.
 type ImGuiCharSelectUI struct {
 	selectedIndex int
+	pincode       PincodeEntry
 }
 
 // NewImGuiCharSelectUI creates a new ImGui character selection UI.
@@ -346,6 +641,75 @@ func (ui *ImGuiCharSelectUI) Render(state CharSelectUIState, viewportWidth, view
 		}
 	}
 	imgui.End()
+
+	ui.pincode.Sync(state.Pincode)
+	if state.Pincode != nil {
+		ui.renderPincode(state.Pincode, viewportWidth, viewportHeight)
+	}
+}
+
+// renderPincode draws the PIN keypad over the character list.
+func (ui *ImGuiCharSelectUI) renderPincode(p *PincodeUIState, viewportWidth, viewportHeight float32) {
+	const button = 56
+	windowWidth, windowHeight := float32(220), float32(390)
+	imgui.SetNextWindowPos(imgui.NewVec2((viewportWidth-windowWidth)/2, (viewportHeight-windowHeight)/2))
+	imgui.SetNextWindowSize(imgui.NewVec2(windowWidth, windowHeight))
+	imgui.SetNextWindowFocus()
+
+	flags := imgui.WindowFlagsNoResize | imgui.WindowFlagsNoMove | imgui.WindowFlagsNoCollapse
+	if imgui.BeginV("PIN Code", nil, flags) {
+		imguiCenterText(ui.pincode.Prompt())
+		imgui.Spacing()
+		imguiCenterText(ui.pincode.Masked())
+		imgui.Spacing()
+
+		// Buttons 0-8 in a 3x3 grid, then Clear, button 9 and Back
+		for i := range 9 {
+			if i%3 != 0 {
+				imgui.SameLine()
+			}
+			if imgui.ButtonV(fmt.Sprintf("%d##pin%d", p.Keypad[i], i), imgui.NewVec2(button, button)) {
+				ui.pincode.Press(p.Keypad[i])
+			}
+		}
+		if imgui.ButtonV("Clear", imgui.NewVec2(button, button)) {
+			ui.pincode.Clear()
+		}
+		imgui.SameLine()
+		if imgui.ButtonV(fmt.Sprintf("%d##pin9", p.Keypad[9]), imgui.NewVec2(button, button)) {
+			ui.pincode.Press(p.Keypad[9])
+		}
+		imgui.SameLine()
+		if imgui.ButtonV("Back", imgui.NewVec2(button, button)) {
+			ui.pincode.Back()
+		}
+		imgui.Spacing()
+
+		imgui.BeginDisabledV(!ui.pincode.Ready())
+		if imgui.ButtonV("OK", imgui.NewVec2(button*1.5, 0)) || (ui.pincode.Ready() && imgui.IsKeyPressedBool(imgui.KeyEnter)) {
+			if current, newPin, done := ui.pincode.Confirm(); done && p.OnSubmit != nil {
+				p.OnSubmit(current, newPin)
+			}
+		}
+		imgui.EndDisabled()
+		if p.OnCancel != nil {
+			imgui.SameLine()
+			if imgui.ButtonV("Cancel", imgui.NewVec2(button*1.5, 0)) {
+				p.OnCancel()
+			}
+		}
+
+		if msg := ui.pincode.Error(); msg != "" || p.Message != "" {
+			if msg == "" {
+				msg = p.Message
+			}
+			imgui.Spacing()
+			imgui.PushTextWrapPos()
+			imgui.TextColored(imgui.NewVec4(1, 0.3, 0.3, 1), msg)
+			imgui.PopTextWrapPos()
+		}
+	}
+	imgui.End()
 }
 
 func (ui *ImGuiCharSelectUI) renderCharacterList(characters []*packets.CharInfo) {
@@ -361,7 +725,7 @@ func (ui *ImGuiCharSelectUI) renderCharacterList(characters []*packets.CharInfo)
 
 		if imgui.BeginListBoxV("##charlist", imgui.NewVec2(-1, 300)) {
 			for i, char := range characters {
-				label := fmt.Sprintf("%s (Lv %d %s)", char.GetName(), char.BaseLevel, imguiGetJobName(char.Class))
+				label := fmt.Sprintf("%s (Lv %d %s)", char.GetName(), char.BaseLevel, entity.JobName(int(char.Class)))
 				isSelected := ui.selectedIndex == i
 				if imgui.SelectableBoolV(label, isSelected, 0, imgui.NewVec2(0, 0)) {
 					ui.selectedIndex = i
@@ -393,7 +757,7 @@ func (ui *ImGuiCharSelectUI) renderCharacterDetails(characters []*packets.CharIn
 		imgui.TableSetupColumnV("Value", imgui.TableColumnFlagsWidthStretch, 0, 0)
 
 		imguiAddInfoRow("Name:", char.GetName())
-		imguiAddInfoRow("Job:", imguiGetJobName(char.Class))
+		imguiAddInfoRow("Job:", entity.JobName(int(char.Class)))
 		imguiAddInfoRow("Base Level:", fmt.Sprintf("%d", char.BaseLevel))
 		imguiAddInfoRow("Job Level:", fmt.Sprintf("%d", char.JobLevel))
 		imguiAddInfoRow("HP:", fmt.Sprintf("%d / %d", char.HP, char.MaxHP))
@@ -427,6 +791,19 @@ func (ui *ImGuiCharSelectUI) renderActionButtons(state CharSelectUIState) {
 	imgui.BeginDisabledV(true)
 	imgui.ButtonV("Delete Character", imgui.NewVec2(150, 0))
 	imgui.EndDisabled()
+
+	if state.OnChangePincode != nil {
+		imgui.SameLine()
+		label := "Set PIN Code"
+		if state.PincodeSet {
+			label = "Change PIN Code"
+		}
+		imgui.BeginDisabledV(state.Pincode != nil)
+		if imgui.ButtonV(label, imgui.NewVec2(150, 0)) {
+			state.OnChangePincode()
+		}
+		imgui.EndDisabled()
+	}
 }
 
 // ImGuiLoadingUI renders the loading UI using ImGui.
@@ -473,11 +850,32 @@ func (ui *ImGuiLoadingUI) Render(state LoadingUIState, viewportWidth, viewportHe
 }
 
 // ImGuiInGameUI renders the in-game HUD using ImGui.
-type ImGuiInGameUI struct{}
+type ImGuiInGameUI struct {
+	baseExp expFill
+	jobExp  expFill
+
+	chatInput     string
+	chatTypeahead typeahead
+
+	chatTabDrag int    // Chat tab being dragged, -1 when none
+	chatTabMenu int    // Chat tab whose settings popup is open
+	chatTabName string // Name being edited in the settings popup
+
+	dragIndex int // Inventory index being dragged, -1 when none
+	qtyCount  int32
+	qtyTitle  string // Prompt the quantity was initialised for
+
+	shopVendor uint32          // Vendor the cart is for
+	shopCart   map[int]int     // Amounts to buy by item index
+	shopSel    int             // Item the quantity dialog is for
+	shopPrompt *QuantityPrompt // Quantity dialog for the selected item, nil when closed
+
+	historyLen int // Dialog history lines shown, to scroll to new ones
+}
 
 // NewImGuiInGameUI creates a new ImGui in-game UI.
 func NewImGuiInGameUI() *ImGuiInGameUI {
-	return &ImGuiInGameUI{}
+	return &ImGuiInGameUI{dragIndex: -1, chatTabDrag: -1}
 }
 
 // Render renders the in-game HUD.
@@ -499,19 +897,84 @@ func (ui *ImGuiInGameUI) Render(state InGameUIState, dt float64, viewportWidth,
 				imgui.NewVec2(viewportWidth, viewportHeight),
 				imgui.NewVec2(0, 1),
 				imgui.NewVec2(1, 0))
+			renderNameplates(state.Nameplates)
+			renderChatBubbles(state.ChatBubbles)
+			renderDamageNumbers(state.DamageNumbers)
+			if state.Banner != nil {
+				renderBanner(state.Banner, viewportWidth)
+			}
+			renderBuffs(state.Buffs, viewportWidth)
 		}
 		imgui.End()
 		imgui.PopStyleVar()
 	}
 
-	// Debug overlay (top-left)
+	renderBasicInfo(state)
+
+	// Debug overlay (top-left, under the basic info window) and
+	// picture-in-picture view (top-right)
 	if state.ShowDebugInfo {
 		ui.renderDebugOverlay(state)
+		if state.PiPTexture != 0 {
+			ui.renderPiP(state, viewportWidth)
+		}
+	}
+
+	// Chat tabs, log and input (bottom-left, above the experience bars)
+	if len(state.ChatTabs) > 0 {
+		ui.renderChatTabs(state.ChatTabs, state.ActiveChatTab, state.ChatTabActions, viewportHeight)
+	}
+	if len(state.ChatMessages) > 0 {
+		ui.renderChatLog(state.ChatMessages, viewportHeight)
+	}
+	if state.OnChatSubmit != nil {
+		ui.renderChatInput(state.OnChatSubmit, state.OnChatComplete, state.ChatDraft, viewportHeight)
 	}
 
-	// Bottom status bar
+	// Experience bars and bottom status bar
+	ui.renderExpBars(state, dt, viewportWidth, viewportHeight)
 	ui.renderBottomStatusBar(state, viewportWidth, viewportHeight)
 
+	if state.AreaMap != nil {
+		renderAreaMap(state.AreaMap, viewportWidth, viewportHeight)
+	}
+	if state.ShowInventory {
+		ui.renderInventory(state, viewportWidth)
+	} else {
+		ui.dragIndex = -1
+	}
+	if state.ShowEquipment {
+		renderEquipment(state.Inventory, state.OnUnequip, viewportWidth)
+	}
+	if state.VendingShop != nil {
+		ui.renderVendingShop(state.VendingShop, viewportWidth, viewportHeight)
+	} else {
+		ui.shopCart = nil
+	}
+	if state.DropPrompt != nil {
+		ui.renderQuantityPrompt(state.DropPrompt, viewportWidth, viewportHeight)
+	}
+	if state.NPCDialog != nil {
+		renderNPCDialog(state.NPCDialog, viewportWidth, viewportHeight)
+	}
+	if state.DialogHistory != nil {
+		ui.renderDialogHistory(state.DialogHistory, viewportWidth, viewportHeight)
+	} else {
+		ui.historyLen = 0
+	}
+	if state.RequestDialog != nil {
+		renderRequestDialog(state.RequestDialog, viewportWidth, viewportHeight)
+	}
+
+	// Right-click menu above the HUD
+	if state.ContextMenu != nil {
+		ui.renderContextMenu(state.ContextMenu)
+	}
+
+	if state.AttackCursor {
+		renderAttackCursor()
+	}
+
 	// Error overlay
 	if state.ErrorMessage != "" {
 		ui.renderErrorOverlay(state.ErrorMessage, viewportWidth, viewportHeight)
@@ -525,7 +988,7 @@ func (ui *ImGuiInGameUI) renderDebugOverlay(state InGameUIState) {
 	// diagnostics. It does NOT need to be deferred or buffered.
 	state.LastGLError = gl.GetError()
 
-	imgui.SetNextWindowPos(imgui.NewVec2(10, 10))
+	imgui.SetNextWindowPos(imgui.NewVec2(10, basicInfoHeight+20))
 	imgui.SetNextWindowSize(imgui.NewVec2(320, 0))
 	imgui.SetNextWindowBgAlpha(0.7)
 	flags := imgui.WindowFlagsNoTitleBar | imgui.WindowFlagsNoResize |
@@ -533,7 +996,7 @@ func (ui *ImGuiInGameUI) renderDebugOverlay(state InGameUIState) {
 		imgui.WindowFlagsNoSavedSettings | imgui.WindowFlagsNoFocusOnAppearing |
 		imgui.WindowFlagsNoInputs
 	if imgui.BeginV("##Debug", nil, flags) {
-		imgui.TextDisabled("F3 to toggle")
+		imgui.TextDisabled(fmt.Sprintf("F3 to toggle   F4 PiP: %s", state.PiPMode))
 
 		// FPS
 		fpsColor := imgui.NewVec4(0.2, 1.0, 0.2, 1.0)
@@ -575,11 +1038,35 @@ func (ui *ImGuiInGameUI) renderDebugOverlay(state InGameUIState) {
 			imgui.Text("  GL Err: NONE")
 		}
 
+		// Audio
+		imgui.Separator()
+		imgui.Text(fmt.Sprintf("Audio: %s  Voices: %d", state.AudioEnvironment, len(state.AudioVoices)))
+		for _, v := range state.AudioVoices {
+			imgui.Text("  " + v)
+		}
+		if len(state.MissingSprites) > 0 {
+			imgui.Text(fmt.Sprintf("Missing sprites: %d", len(state.MissingSprites)))
+			for _, m := range state.MissingSprites[:min(len(state.MissingSprites), debugMissingSprites)] {
+				imgui.Text("  " + m)
+			}
+		}
+
 		// Network
 		imgui.Separator()
 		imgui.Text("Network")
 		imgui.Text(fmt.Sprintf("  Sent: %d pkts (%dB)", state.PacketsSent, state.BytesSent))
 		imgui.Text(fmt.Sprintf("  Recv: %d pkts (%dB)", state.PacketsReceived, state.BytesReceived))
+		imgui.Text(fmt.Sprintf("  Queue: %d  Writes: %d  Coalesced: %d  Deferred: %d",
+			state.PacketsQueued, state.SendWrites, state.PacketsCoalesced, state.PacketsDeferred))
+		imgui.Text(fmt.Sprintf("  Input: %d waiting  Fired: %d  Buffered: %d  Dropped: %d",
+			state.InputWaiting, state.InputFired, state.InputBuffered, state.InputDropped))
+		imgui.Text(fmt.Sprintf("  Inbound: %d (peak %d)  Stalls: %d read, %d write",
+			state.InboundQueued, state.InboundPeak, state.ReadStalls, state.WriteStalls))
+		imgui.Text(fmt.Sprintf("  Unknown: %d (last 0x%04X)  Resyncs: %d (%dB dropped)",
+			state.UnknownPackets, state.LastUnknownID, state.Resyncs, state.DroppedBytes))
+		if state.VersionMismatch {
+			imgui.TextColored(imgui.NewVec4(1, 0.4, 0.4, 1), "  Packetver mismatch suspected")
+		}
 		if state.LastSentID != 0 {
 			imgui.Text(fmt.Sprintf("  -> 0x%04X (%dB) %dms ago", state.LastSentID, state.LastSentLen, state.LastSentAgoMs))
 		}
@@ -590,10 +1077,962 @@ func (ui *ImGuiInGameUI) renderDebugOverlay(state InGameUIState) {
 		imgui.Separator()
 		imgui.Text(fmt.Sprintf("Entities: %d (P:%d M:%d N:%d I:%d)",
 			state.EntityCount, state.PlayerCount, state.MonsterCount, state.NPCCount, state.ItemCount))
+		imgui.Text(fmt.Sprintf("  Updated: %d  Throttled: %d  Culled: %d",
+			state.EntitiesUpdated, state.EntitiesThrottled, state.EntitiesCulled))
+		imgui.Text(fmt.Sprintf("  Anim: full %d  reduced %d  frozen %d  Composited: %d",
+			state.AnimFull, state.AnimReduced, state.AnimFrozen, state.AnimComposited))
+	}
+	imgui.End()
+}
+
+// renderPiP draws the picture-in-picture debug camera in the top-right corner.
+func (ui *ImGuiInGameUI) renderPiP(state InGameUIState, viewportWidth float32) {
+	const pipW, pipH = 320, 240
+	imgui.SetNextWindowPos(imgui.NewVec2(viewportWidth-pipW-20, 10))
+	imgui.SetNextWindowBgAlpha(0.8)
+	flags := imgui.WindowFlagsNoResize | imgui.WindowFlagsNoMove |
+		imgui.WindowFlagsNoScrollbar | imgui.WindowFlagsNoSavedSettings |
+		imgui.WindowFlagsNoFocusOnAppearing | imgui.WindowFlagsAlwaysAutoResize |
+		imgui.WindowFlagsNoInputs
+	if imgui.BeginV("PiP: "+state.PiPMode+"###PiP", nil, flags) {
+		texRef := imgui.NewTextureRefTextureID(imgui.TextureID(state.PiPTexture))
+		imgui.ImageV(*texRef,
+			imgui.NewVec2(pipW, pipH),
+			imgui.NewVec2(0, 1),
+			imgui.NewVec2(1, 0))
 	}
 	imgui.End()
 }
 
+// renderExpBars draws the base and job experience bars above the status bar.
+func (ui *ImGuiInGameUI) renderExpBars(state InGameUIState, dt float64, viewportWidth, viewportHeight float32) {
+	baseRatio := ui.baseExp.update(state.BaseExpRatio, state.PlayerLevel, dt)
+	jobRatio := ui.jobExp.update(state.JobExpRatio, state.PlayerJobLevel, dt)
+
+	barHeight := float32(22)
+	imgui.SetNextWindowPos(imgui.NewVec2(0, viewportHeight-25-barHeight))
+	imgui.SetNextWindowSize(imgui.NewVec2(viewportWidth, barHeight))
+	imgui.SetNextWindowBgAlpha(0.7)
+
+	flags := imgui.WindowFlagsNoTitleBar | imgui.WindowFlagsNoResize |
+		imgui.WindowFlagsNoMove | imgui.WindowFlagsNoScrollbar |
+		imgui.WindowFlagsNoSavedSettings | imgui.WindowFlagsNoInputs
+
+	imgui.PushStyleVarVec2(imgui.StyleVarWindowPadding, imgui.NewVec2(10, 3))
+	if imgui.BeginV("##ExpBars", nil, flags) {
+		half := (viewportWidth - 40) / 2
+		ui.renderExpBar(fmt.Sprintf("Base Lv. %d", state.PlayerLevel), baseRatio, state.BaseExpRatio, half,
+			imgui.NewVec4(0.3, 0.6, 1.0, 1.0))
+		imgui.SameLine()
+		ui.renderExpBar(fmt.Sprintf("Job Lv. %d", state.PlayerJobLevel), jobRatio, state.JobExpRatio, half,
+			imgui.NewVec4(0.9, 0.6, 0.2, 1.0))
+	}
+	imgui.End()
+	imgui.PopStyleVar()
+}
+
+// renderBasicInfo draws the basic info window in the top-left corner: the
+// weight carried, red when overweight, and the zeny.
+func renderBasicInfo(state InGameUIState) {
+	imgui.SetNextWindowPos(imgui.NewVec2(10, 10))
+	imgui.SetNextWindowSize(imgui.NewVec2(basicInfoWidth, 0))
+	imgui.SetNextWindowBgAlpha(0.7)
+	flags := imgui.WindowFlagsNoTitleBar | imgui.WindowFlagsNoResize |
+		imgui.WindowFlagsNoMove | imgui.WindowFlagsNoScrollbar |
+		imgui.WindowFlagsNoSavedSettings | imgui.WindowFlagsNoFocusOnAppearing |
+		imgui.WindowFlagsNoInputs
+	if imgui.BeginV("##BasicInfo", nil, flags) {
+		imgui.TextColored(paletteVec4(weightColor(state.Weight)), state.Weight.String())
+		imgui.ProgressBarV(state.Weight.Ratio(), imgui.NewVec2(-1, 8), "")
+		imgui.Text(zenyText(state.Zeny))
+	}
+	imgui.End()
+}
+
+func (ui *ImGuiInGameUI) renderExpBar(label string, shown, actual, width float32, color imgui.Vec4) {
+	imgui.Text(label)
+	imgui.SameLine()
+	labelWidth := imgui.CalcTextSize(label).X + imgui.CurrentStyle().ItemSpacing().X
+	imgui.PushStyleColorVec4(imgui.ColPlotHistogram, color)
+	imgui.ProgressBarV(shown, imgui.NewVec2(width-labelWidth, 14), fmt.Sprintf("%.1f%%", actual*100))
+	imgui.PopStyleColor()
+}
+
+// chatLogLines is how many lines the chat log shows.
+const chatLogLines = 8
+
+// renderChatLog draws the most recent chat messages.
+func (ui *ImGuiInGameUI) renderChatLog(messages []ChatLine, viewportHeight float32) {
+	if len(messages) > chatLogLines {
+		messages = messages[len(messages)-chatLogLines:]
+	}
+
+	imgui.SetNextWindowPos(imgui.NewVec2(10, viewportHeight-55-chatInputHeight-chatLogLines*18))
+	imgui.SetNextWindowSize(imgui.NewVec2(400, chatLogLines*18))
+	imgui.SetNextWindowBgAlpha(0.4)
+
+	flags := imgui.WindowFlagsNoTitleBar | imgui.WindowFlagsNoResize |
+		imgui.WindowFlagsNoMove | imgui.WindowFlagsNoScrollbar |
+		imgui.WindowFlagsNoSavedSettings | imgui.WindowFlagsNoFocusOnAppearing |
+		imgui.WindowFlagsNoInputs
+	if imgui.BeginV("##ChatLog", nil, flags) {
+		for _, msg := range messages {
+			imgui.TextColored(imgui.NewVec4(msg.Color.R, msg.Color.G, msg.Color.B, msg.Color.A), msg.Text)
+		}
+	}
+	imgui.End()
+}
+
+// chatTabsHeight is the height of the chat tab bar above the chat log.
+const chatTabsHeight = 26
+
+// renderChatTabs draws the chat tab bar above the log. Clicking a tab
+// shows it, dragging one onto another moves it there, and right-clicking
+// opens its settings.
+func (ui *ImGuiInGameUI) renderChatTabs(tabs []ChatTab, active int, actions ChatTabActions, viewportHeight float32) {
+	imgui.SetNextWindowPos(imgui.NewVec2(10, viewportHeight-55-chatInputHeight-chatLogLines*18-chatTabsHeight))
+	imgui.SetNextWindowSize(imgui.NewVec2(400, chatTabsHeight))
+	imgui.SetNextWindowBgAlpha(0.4)
+
+	flags := imgui.WindowFlagsNoTitleBar | imgui.WindowFlagsNoResize |
+		imgui.WindowFlagsNoMove | imgui.WindowFlagsNoScrollbar |
+		imgui.WindowFlagsNoSavedSettings | imgui.WindowFlagsNoFocusOnAppearing
+	imgui.PushStyleVarVec2(imgui.StyleVarWindowPadding, imgui.NewVec2(4, 4))
+	if imgui.BeginV("##ChatTabs", nil, flags) {
+		target := -1
+		for i, tab := range tabs {
+			if i > 0 {
+				imgui.SameLine()
+			}
+			colors := 0
+			if i == active {
+				imgui.PushStyleColorVec4(imgui.ColButton, imgui.NewVec4(0.26, 0.59, 0.98, 1))
+				colors++
+			}
+			if tab.Unread > 0 && i != active {
+				imgui.PushStyleColorVec4(imgui.ColText, imgui.NewVec4(1, 0.85, 0.3, 1))
+				colors++
+			}
+			if imgui.SmallButton(fmt.Sprintf("%s###chattab%d", chatTabLabel(tab), i)) && ui.chatTabDrag < 0 && actions.Select != nil {
+				actions.Select(i)
+			}
+			imgui.PopStyleColorV(int32(colors))
+
+			if imgui.IsItemActive() && imgui.IsMouseDraggingV(imgui.MouseButtonLeft, 4) {
+				ui.chatTabDrag = i
+			}
+			if ui.chatTabDrag >= 0 && imgui.IsItemHoveredV(imgui.HoveredFlagsAllowWhenBlockedByActiveItem) {
+				target = i
+			}
+			if imgui.IsItemClickedV(imgui.MouseButtonRight) {
+				ui.chatTabMenu, ui.chatTabName = i, tab.Name
+				imgui.OpenPopupStr("##ChatTabMenu")
+			}
+		}
+		if actions.Add != nil {
+			imgui.SameLine()
+			if imgui.SmallButton("+") {
+				actions.Add()
+			}
+		}
+
+		if ui.chatTabDrag >= 0 && imgui.IsMouseReleased(imgui.MouseButtonLeft) {
+			if target >= 0 && target != ui.chatTabDrag && actions.Move != nil {
+				actions.Move(ui.chatTabDrag, target)
+			}
+			ui.chatTabDrag = -1
+		}
+		ui.renderChatTabMenu(tabs, actions)
+	}
+	imgui.End()
+	imgui.PopStyleVar()
+}
+
+// renderChatTabMenu draws the settings popup of the right-clicked chat
+// tab: its name, the channels it shows, and a button to remove it.
+func (ui *ImGuiInGameUI) renderChatTabMenu(tabs []ChatTab, actions ChatTabActions) {
+	if !imgui.BeginPopup("##ChatTabMenu") {
+		return
+	}
+	i := ui.chatTabMenu
+	if i < 0 || i >= len(tabs) {
+		imgui.CloseCurrentPopup()
+		imgui.EndPopup()
+		return
+	}
+
+	imgui.SetNextItemWidth(160)
+	if imgui.InputTextWithHint("##name", "Tab name", &ui.chatTabName, imgui.InputTextFlagsEnterReturnsTrue, nil) && actions.Rename != nil {
+		if name := strings.TrimSpace(ui.chatTabName); name != "" {
+			actions.Rename(i, name)
+		}
+	}
+	imgui.Separator()
+	for ch, toggle := range tabs[i].Channels {
+		on := toggle.On
+		if imgui.Checkbox(toggle.Label, &on) && actions.Toggle != nil {
+			actions.Toggle(i, ch)
+		}
+	}
+	imgui.Separator()
+	imgui.BeginDisabledV(actions.Remove == nil || len(tabs) < 2)
+	if imgui.Button("Remove tab") {
+		actions.Remove(i)
+		imgui.CloseCurrentPopup()
+	}
+	imgui.EndDisabled()
+	imgui.EndPopup()
+}
+
+// chatInputHeight is the height of the chat entry line below the chat log.
+const chatInputHeight = 30
+
+// renderChatInput draws the chat entry line. Enter focuses it when no other
+// text field is active; focus is kept after submitting so several lines can
+// be typed in a row. A non-empty draft replaces the text and focuses it.
+// Tab cycles the completions from onComplete, listed above the line.
+func (ui *ImGuiInGameUI) renderChatInput(onSubmit func(string), onComplete func(string) (int, []string), draft string, viewportHeight float32) {
+	focus := imgui.IsKeyPressedBoolV(imgui.KeyEnter, false) && !imgui.CurrentIO().WantTextInput()
+	if draft != "" {
+		ui.chatInput = draft
+		focus = true
+	}
+
+	imgui.SetNextWindowPos(imgui.NewVec2(10, viewportHeight-53-chatInputHeight))
+	imgui.SetNextWindowSize(imgui.NewVec2(400, chatInputHeight))
+	imgui.SetNextWindowBgAlpha(0.4)
+
+	flags := imgui.WindowFlagsNoTitleBar | imgui.WindowFlagsNoResize |
+		imgui.WindowFlagsNoMove | imgui.WindowFlagsNoScrollbar |
+		imgui.WindowFlagsNoSavedSettings | imgui.WindowFlagsNoFocusOnAppearing
+	complete := func(data imgui.InputTextCallbackData) int {
+		ui.chatTypeahead.update(data.Buf(), onComplete)
+		if text, ok := ui.chatTypeahead.cycle(imgui.CurrentIO().KeyShift()); ok {
+			data.DeleteChars(0, data.BufTextLen())
+			data.InsertChars(0, text)
+		}
+		return 0
+	}
+	active := false
+	if imgui.BeginV("##ChatInput", nil, flags) {
+		imgui.SetNextItemWidth(-1)
+		if focus {
+			imgui.SetKeyboardFocusHere()
+		}
+		inputFlags := imgui.InputTextFlagsEnterReturnsTrue | imgui.InputTextFlagsCallbackCompletion
+		if imgui.InputTextWithHint("##chat", "Press Enter to chat, /help for commands", &ui.chatInput, inputFlags, complete) {
+			line := strings.TrimSpace(ui.chatInput)
+			ui.chatInput = ""
+			if line != "" {
+				onSubmit(line)
+			}
+			imgui.SetKeyboardFocusHereV(-1)
+		}
+		active = imgui.IsItemActive()
+	}
+	imgui.End()
+
+	ui.chatTypeahead.update(ui.chatInput, onComplete)
+	if active {
+		ui.renderTypeahead(10, viewportHeight-53-chatInputHeight)
+	}
+}
+
+// renderTypeahead lists the chat input's completions in a box whose
+// bottom-left corner is at x, y, the one Tab put in the input highlighted.
+func (ui *ImGuiInGameUI) renderTypeahead(x, y float32) {
+	shown, selected := ui.chatTypeahead.visible()
+	if len(shown) == 0 {
+		return
+	}
+	imgui.SetNextWindowPosV(imgui.NewVec2(x, y), imgui.CondAlways, imgui.NewVec2(0, 1))
+	imgui.SetNextWindowBgAlpha(0.85)
+	flags := imgui.WindowFlagsNoDecoration | imgui.WindowFlagsAlwaysAutoResize |
+		imgui.WindowFlagsNoSavedSettings | imgui.WindowFlagsNoFocusOnAppearing |
+		imgui.WindowFlagsNoNav | imgui.WindowFlagsNoInputs
+	if imgui.BeginV("##ChatTypeahead", nil, flags) {
+		for i, c := range shown {
+			if i == selected {
+				imgui.TextColored(imgui.NewVec4(0.4, 0.75, 1, 1), c)
+			} else {
+				imgui.Text(c)
+			}
+		}
+	}
+	imgui.End()
+}
+
+// renderContextMenu draws a right-click menu at the click position. Clicking
+// elsewhere or pressing Escape dismisses it.
+func (ui *ImGuiInGameUI) renderContextMenu(menu *ContextMenuState) {
+	imgui.SetNextWindowPos(imgui.NewVec2(menu.X, menu.Y))
+	imgui.SetNextWindowBgAlpha(0.9)
+
+	flags := imgui.WindowFlagsNoTitleBar | imgui.WindowFlagsNoResize |
+		imgui.WindowFlagsNoMove | imgui.WindowFlagsNoScrollbar |
+		imgui.WindowFlagsNoSavedSettings | imgui.WindowFlagsAlwaysAutoResize
+	var selected func()
+	dismissed := imgui.IsKeyPressedBool(imgui.KeyEscape)
+	if imgui.BeginV("##ContextMenu", nil, flags) {
+		if menu.Title != "" {
+			imgui.TextDisabled(menu.Title)
+			imgui.Separator()
+		}
+		for _, item := range menu.Items {
+			if imgui.MenuItemBoolV(item.Label, "", false, item.Enabled) {
+				selected = item.OnSelect
+			}
+		}
+		if !imgui.IsWindowHovered() &&
+			(imgui.IsMouseClickedBool(imgui.MouseButtonLeft) || imgui.IsMouseClickedBool(imgui.MouseButtonRight)) {
+			dismissed = true
+		}
+	}
+	imgui.End()
+
+	switch {
+	case selected != nil:
+		selected()
+	case dismissed && menu.OnClose != nil:
+		menu.OnClose()
+	}
+}
+
+// renderInventory draws the inventory window. Dragging a row and releasing
+// it outside every window drops the item on the ground; double-clicking
+// equipment puts it on or takes it off.
+func (ui *ImGuiInGameUI) renderInventory(state InGameUIState, viewportWidth float32) {
+	items := state.Inventory
+	imgui.SetNextWindowPosV(imgui.NewVec2(viewportWidth-270, 60), imgui.CondFirstUseEver, imgui.NewVec2(0, 0))
+	imgui.SetNextWindowSizeV(imgui.NewVec2(260, 300), imgui.CondFirstUseEver)
+
+	overWindow := true
+	if imgui.BeginV("Inventory", nil, imgui.WindowFlagsNoSavedSettings) {
+		if len(items) == 0 {
+			imgui.TextDisabled("No items")
+		}
+		for _, item := range items {
+			label := fmt.Sprintf("%s  x%d##inv%d", item.Label(), item.Amount, item.Index)
+			if !item.Droppable {
+				label = fmt.Sprintf("%s  (equipped)##inv%d", item.Label(), item.Index)
+			} else if item.Location != 0 {
+				label = fmt.Sprintf("%s##inv%d", item.Label(), item.Index)
+			}
+			color, graded := imguiItemColor(item.Grade)
+			if graded {
+				imgui.PushStyleColorVec4(imgui.ColText, color)
+			}
+			imgui.SelectableBoolV(label, ui.dragIndex == item.Index, 0, imgui.NewVec2(0, 0))
+			if graded {
+				imgui.PopStyleColor()
+			}
+			if item.Droppable && imgui.IsItemActive() && imgui.IsMouseDragging(imgui.MouseButtonLeft) {
+				ui.dragIndex = item.Index
+			}
+			if ui.dragIndex == item.Index {
+				imgui.SetTooltip(item.Name)
+			} else if ui.dragIndex < 0 && imgui.IsItemHovered() {
+				imguiEquipTooltip(item, items)
+				if imgui.IsMouseDoubleClicked(imgui.MouseButtonLeft) {
+					toggleEquip(item, state.OnEquip, state.OnUnequip)
+				}
+			}
+		}
+		overWindow = imgui.IsWindowHoveredV(imgui.HoveredFlagsAnyWindow | imgui.HoveredFlagsAllowWhenBlockedByActiveItem)
+	}
+	imgui.End()
+
+	if ui.dragIndex >= 0 && imgui.IsMouseReleased(imgui.MouseButtonLeft) {
+		if !overWindow && state.OnItemDrop != nil {
+			state.OnItemDrop(ui.dragIndex)
+		}
+		ui.dragIndex = -1
+	}
+}
+
+// toggleEquip equips an item, or takes it off if it's worn. Items that
+// aren't equipment are left alone.
+func toggleEquip(item InventoryItem, onEquip, onUnequip func(int)) {
+	switch {
+	case item.Location == 0:
+	case item.Worn != 0 && onUnequip != nil:
+		onUnequip(item.Index)
+	case item.Worn == 0 && onEquip != nil:
+		onEquip(item.Index)
+	}
+}
+
+// imguiItemColor returns the color of an item grade on ImGui's dark
+// windows, or false for normal items.
+func imguiItemColor(grade entity.ItemGrade) (imgui.Vec4, bool) {
+	c, ok := ItemColor(grade)
+	return paletteVec4(c.Lighten(0.3)), ok
+}
+
+// imguiEquipTooltip shows the comparison tooltip for a hovered equipment
+// item.
+func imguiEquipTooltip(item InventoryItem, items []InventoryItem) {
+	sections := EquipTooltip(item, items)
+	if sections == nil || !imgui.BeginTooltip() {
+		return
+	}
+	for i, sec := range sections {
+		if i > 0 {
+			imgui.Separator()
+		}
+		title := imgui.NewVec4(1, 0.85, 0.4, 1)
+		if color, ok := imguiItemColor(sec.Grade); ok {
+			title = color
+		}
+		imgui.TextColored(title, sec.Title)
+		for _, line := range sec.Lines {
+			imgui.Text(line)
+		}
+	}
+	imgui.EndTooltip()
+}
+
+// renderEquipment draws the equipment window: the worn items either side
+// of a character silhouette. Double-clicking a slot takes its item off.
+func renderEquipment(items []InventoryItem, onUnequip func(int), viewportWidth float32) {
+	imgui.SetNextWindowPosV(imgui.NewVec2(viewportWidth-620, 60), imgui.CondFirstUseEver, imgui.NewVec2(0, 0))
+	imgui.SetNextWindowSizeV(imgui.NewVec2(340, 0), imgui.CondFirstUseEver)
+
+	if imgui.BeginV("Equipment", nil, imgui.WindowFlagsNoSavedSettings) {
+		if imgui.BeginTable("##doll", 3) {
+			imgui.TableSetupColumnV("Left", imgui.TableColumnFlagsWidthStretch, 0, 0)
+			imgui.TableSetupColumnV("Doll", imgui.TableColumnFlagsWidthFixed, 60, 0)
+			imgui.TableSetupColumnV("Right", imgui.TableColumnFlagsWidthStretch, 0, 0)
+			imgui.TableNextRow()
+
+			imgui.TableNextColumn()
+			for _, slot := range DollLeft {
+				imguiDollSlot(slot, items, onUnequip)
+			}
+			imgui.TableNextColumn()
+			imguiSilhouette(60, 5*2*imgui.TextLineHeightWithSpacing())
+			imgui.TableNextColumn()
+			for _, slot := range DollRight {
+				imguiDollSlot(slot, items, onUnequip)
+			}
+			imgui.EndTable()
+		}
+		imgui.Separator()
+		imguiDollSlot(DollAmmo, items, onUnequip)
+	}
+	imgui.End()
+}
+
+// imguiDollSlot draws one slot of the equipment doll.
+func imguiDollSlot(slot DollSlot, items []InventoryItem, onUnequip func(int)) {
+	imgui.TextDisabled(slot.Label)
+	item, ok := WornIn(items, slot.Location)
+	if !ok {
+		imgui.Text("-")
+		return
+	}
+	imgui.SelectableBool(fmt.Sprintf("%s##slot%d", item.Label(), slot.Location))
+	if imgui.IsItemHovered() {
+		imguiEquipTooltip(item, items)
+		if imgui.IsMouseDoubleClicked(imgui.MouseButtonLeft) && onUnequip != nil {
+			onUnequip(item.Index)
+		}
+	}
+}
+
+// imguiSilhouette draws a plain figure standing in for the character in
+// a w x h area at the cursor.
+func imguiSilhouette(w, h float32) {
+	pos := imgui.CursorScreenPos()
+	dl := imgui.WindowDrawList()
+	col := imguiColor(ui2d.Color{R: 0.45, G: 0.45, B: 0.5, A: 0.6})
+	cx := pos.X + w/2
+	head := w / 5
+	dl.AddCircleFilledV(imgui.NewVec2(cx, pos.Y+head+4), head, col, 16)
+	dl.AddRectFilledV(imgui.NewVec2(cx-w/4, pos.Y+2*head+8), imgui.NewVec2(cx+w/4, pos.Y+h*0.6), col, 4, 0)
+	dl.AddRectFilledV(imgui.NewVec2(cx-w/4, pos.Y+h*0.6+2), imgui.NewVec2(cx-2, pos.Y+h-4), col, 3, 0)
+	dl.AddRectFilledV(imgui.NewVec2(cx+2, pos.Y+h*0.6+2), imgui.NewVec2(cx+w/4, pos.Y+h-4), col, 3, 0)
+	imgui.Dummy(imgui.NewVec2(w, h))
+}
+
+// renderQuantityPrompt draws the centred "how many?" dialog: a slider and
+// number field over the accepted range, a Max button, and Up/Down (ten at a
+// time with Shift) stepping the amount.
+func (ui *ImGuiInGameUI) renderQuantityPrompt(prompt *QuantityPrompt, viewportWidth, viewportHeight float32) {
+	if ui.qtyTitle != prompt.Title {
+		ui.qtyTitle = prompt.Title
+		ui.qtyCount = int32(prompt.Start())
+	}
+	limit, reason := prompt.Limit()
+	allowed := prompt.Allowed()
+	limit = max(limit, prompt.Min)
+
+	imgui.SetNextWindowPosV(imgui.NewVec2(viewportWidth/2, viewportHeight/2), imgui.CondAlways, imgui.NewVec2(0.5, 0.5))
+	flags := imgui.WindowFlagsNoResize | imgui.WindowFlagsNoCollapse |
+		imgui.WindowFlagsNoSavedSettings | imgui.WindowFlagsAlwaysAutoResize
+
+	var confirm, cancel bool
+	if imgui.BeginV(prompt.Title+"##QuantityPrompt", nil, flags) {
+		imgui.Text(prompt.Hint(int(ui.qtyCount)))
+		if reason != "" {
+			imgui.TextColored(imgui.NewVec4(1, 0.3, 0.3, 1), reason)
+		}
+		imgui.BeginDisabledV(!allowed)
+		imgui.SetNextItemWidth(220)
+		imgui.SliderInt("##slider", &ui.qtyCount, int32(prompt.Min), int32(limit))
+		imgui.SetNextItemWidth(160)
+		imgui.InputInt("##amount", &ui.qtyCount)
+		imgui.SameLine()
+		if imgui.Button("Max") {
+			ui.qtyCount = int32(limit)
+		}
+		if imgui.IsWindowFocused() {
+			shift := imgui.CurrentIO().KeyShift()
+			if imgui.IsKeyPressedBool(imgui.KeyUpArrow) {
+				ui.qtyCount = int32(prompt.Step(int(ui.qtyCount), 1, shift))
+			}
+			if imgui.IsKeyPressedBool(imgui.KeyDownArrow) {
+				ui.qtyCount = int32(prompt.Step(int(ui.qtyCount), -1, shift))
+			}
+		}
+		ui.qtyCount = int32(prompt.Clamp(int(ui.qtyCount)))
+		confirm = imgui.Button("OK") || imgui.IsKeyPressedBoolV(imgui.KeyEnter, false)
+		confirm = confirm && allowed
+		imgui.EndDisabled()
+		imgui.SameLine()
+		cancel = imgui.Button("Cancel") || imgui.IsKeyPressedBool(imgui.KeyEscape)
+	}
+	imgui.End()
+
+	switch {
+	case confirm:
+		ui.qtyTitle = ""
+		if prompt.OnConfirm != nil {
+			prompt.OnConfirm(int(ui.qtyCount))
+		}
+	case cancel:
+		ui.qtyTitle = ""
+		if prompt.OnCancel != nil {
+			prompt.OnCancel()
+		}
+	}
+}
+
+// renderRequestDialog draws the centred yes/no dialog for a request. Enter
+// accepts and Escape declines unless a text field has focus.
+func renderRequestDialog(req *RequestDialogState, viewportWidth, viewportHeight float32) {
+	imgui.SetNextWindowPosV(imgui.NewVec2(viewportWidth/2, viewportHeight/2), imgui.CondAlways, imgui.NewVec2(0.5, 0.5))
+	imgui.SetNextWindowSizeV(imgui.NewVec2(320, 0), imgui.CondAlways)
+	flags := imgui.WindowFlagsNoResize | imgui.WindowFlagsNoCollapse |
+		imgui.WindowFlagsNoSavedSettings | imgui.WindowFlagsNoMove
+
+	var accept, decline bool
+	if imgui.BeginV(req.Title+"##RequestDialog", nil, flags) {
+		imgui.TextWrapped(req.Message)
+		if countdown := countdownLabel(req.Remaining); countdown != "" {
+			imgui.TextDisabled(countdown)
+		}
+		imgui.Spacing()
+		accept = imgui.Button(req.AcceptLabel)
+		imgui.SameLine()
+		decline = imgui.Button(req.DeclineLabel)
+	}
+	imgui.End()
+
+	if !imgui.CurrentIO().WantTextInput() {
+		accept = accept || imgui.IsKeyPressedBoolV(imgui.KeyEnter, false)
+		decline = decline || imgui.IsKeyPressedBoolV(imgui.KeyEscape, false)
+	}
+	switch {
+	case accept && req.OnAnswer != nil:
+		req.OnAnswer(true)
+	case decline && req.OnAnswer != nil:
+		req.OnAnswer(false)
+	}
+}
+
+// renderNPCDialog draws an NPC's dialog in the upper middle of the
+// screen. A click on the text completes the page being typed.
+func renderNPCDialog(d *NPCDialogState, viewportWidth, viewportHeight float32) {
+	imgui.SetNextWindowPosV(imgui.NewVec2(viewportWidth/2, viewportHeight/5), imgui.CondAlways, imgui.NewVec2(0.5, 0))
+	imgui.SetNextWindowSizeV(imgui.NewVec2(360, 0), imgui.CondAlways)
+	flags := imgui.WindowFlagsNoResize | imgui.WindowFlagsNoCollapse |
+		imgui.WindowFlagsNoSavedSettings | imgui.WindowFlagsNoMove
+	title := d.Name
+	if title == "" {
+		title = "NPC"
+	}
+
+	var advance, history bool
+	if imgui.BeginV(title+"##NPCDialog", nil, flags) {
+		imgui.BeginGroup()
+		imgui.TextWrapped(d.Text)
+		imgui.Dummy(imgui.NewVec2(0, 0))
+		imgui.EndGroup()
+		advance = d.Typing && imgui.IsItemHovered() && imgui.IsMouseClickedBool(imgui.MouseButtonLeft)
+		imgui.Spacing()
+		history = imgui.Button("History")
+		if d.Button != "" {
+			imgui.SameLine()
+			advance = advance || imgui.Button(d.Button)
+		}
+	}
+	imgui.End()
+
+	switch {
+	case advance && d.OnAdvance != nil:
+		d.OnAdvance()
+	case history && d.OnHistory != nil:
+		d.OnHistory()
+	}
+}
+
+// renderDialogHistory draws the past NPC dialog lines in a scrolling
+// region that follows new lines.
+func (ui *ImGuiInGameUI) renderDialogHistory(hist *DialogHistoryState, viewportWidth, viewportHeight float32) {
+	imgui.SetNextWindowPosV(imgui.NewVec2(viewportWidth/2, viewportHeight/2), imgui.CondAppearing, imgui.NewVec2(0.5, 0.5))
+	imgui.SetNextWindowSizeV(imgui.NewVec2(420, 360), imgui.CondAppearing)
+
+	open := true
+	if imgui.BeginV("Dialog History (Alt+H)", &open, imgui.WindowFlagsNoCollapse) {
+		if len(hist.Lines) == 0 {
+			imgui.TextDisabled("No dialog yet.")
+		}
+		imgui.BeginChildStrV("DialogHistoryLines", imgui.NewVec2(0, 0), imgui.ChildFlagsNone, 0)
+		for i, line := range hist.Lines {
+			if line.Name != "" && (i == 0 || hist.Lines[i-1].Name != line.Name) {
+				imgui.TextDisabled(line.Name)
+			}
+			imgui.TextWrapped(line.Text)
+		}
+		if len(hist.Lines) != ui.historyLen {
+			imgui.SetScrollHereYV(1.0)
+			ui.historyLen = len(hist.Lines)
+		}
+		imgui.EndChild()
+		if imgui.IsWindowFocused() && imgui.IsKeyPressedBool(imgui.KeyEscape) {
+			open = false
+		}
+	}
+	imgui.End()
+
+	if !open && hist.OnClose != nil {
+		hist.OnClose()
+	}
+}
+
+// renderAreaMap draws the full-screen area map, or the world map, with
+// the draw list. Map images aren't loaded in this backend; the map is a
+// plain panel.
+func renderAreaMap(m *AreaMapState, viewportWidth, viewportHeight float32) {
+	const margin = 30
+	imgui.SetNextWindowPos(imgui.NewVec2(margin, margin))
+	imgui.SetNextWindowSize(imgui.NewVec2(viewportWidth-2*margin, viewportHeight-2*margin))
+	imgui.SetNextWindowBgAlpha(0.75)
+
+	title, toggle := areaMapTitle(m), "World Map"
+	if m.WorldMap {
+		title, toggle = "World Map", "Area Map"
+	}
+
+	open := true
+	var toggled bool
+	flags := imgui.WindowFlagsNoResize | imgui.WindowFlagsNoMove | imgui.WindowFlagsNoCollapse |
+		imgui.WindowFlagsNoSavedSettings | imgui.WindowFlagsNoScrollbar
+	if imgui.BeginV(title+"###AreaMap", &open, flags) {
+		toggled = imgui.Button(toggle)
+
+		origin := imgui.CursorScreenPos()
+		avail := imgui.ContentRegionAvail()
+		box := ui2d.Rect{X: origin.X, Y: origin.Y, W: avail.X, H: avail.Y - imgui.TextLineHeightWithSpacing()}
+		if m.WorldMap {
+			drawWorldMapImGui(m, box)
+		} else {
+			drawAreaMapImGui(m, box)
+		}
+		imgui.Dummy(imgui.NewVec2(box.W, box.H))
+		imgui.Text(routeText(m))
+
+		if imgui.IsWindowFocused() && imgui.IsKeyPressedBool(imgui.KeyEscape) {
+			open = false
+		}
+	}
+	imgui.End()
+
+	switch {
+	case toggled && m.OnToggleWorld != nil:
+		m.OnToggleWorld()
+	case !open && m.OnClose != nil:
+		m.OnClose()
+	}
+}
+
+func drawAreaMapImGui(m *AreaMapState, box ui2d.Rect) {
+	l := fitMap(box.X, box.Y, box.W, box.H, m.Width, m.Height)
+	if l.scale == 0 {
+		imgui.Text("No map loaded")
+		return
+	}
+	dl := imgui.WindowDrawList()
+	dl.AddRectFilledV(imgui.NewVec2(l.x, l.y), imgui.NewVec2(l.x+l.w, l.y+l.h), imguiColor(ui2d.Color{R: 0.1, G: 0.15, B: 0.2, A: 1}), 0, 0)
+
+	mouse := imgui.MousePos()
+	for _, exit := range m.Exits {
+		x, y := l.toScreen(exit.X, exit.Y)
+		color := markerColor(MarkerTypeWarp)
+		if exit.OnRoute {
+			color = ui2d.ColorHighlight
+		}
+		dl.AddTriangleFilled(imgui.NewVec2(x, y-5), imgui.NewVec2(x+5, y+5), imgui.NewVec2(x-5, y+5), imguiColor(color))
+		dl.AddTextVec2(imgui.NewVec2(x+7, y-7), imguiColor(ui2d.ColorTextOnDark), exitLabel(exit))
+	}
+	for _, mk := range m.Markers {
+		x, y := l.toScreen(mk.X, mk.Y)
+		dl.AddCircleFilledV(imgui.NewVec2(x, y), 3, imguiColor(markerColor(mk.Type)), 8)
+		if near(mouse.X, mouse.Y, x, y) && mk.Label != "" {
+			imgui.SetTooltip(mk.Label)
+		}
+	}
+	px, py := l.toScreen(m.PlayerX, m.PlayerY)
+	dl.AddCircleFilledV(imgui.NewVec2(px, py), 5, imguiColor(markerColor(MarkerTypePlayer)), 12)
+	dl.AddCircleV(imgui.NewVec2(px, py), 5, imguiColor(ui2d.ColorWhite), 12, 1)
+
+	if imgui.IsWindowHovered() && imgui.IsMouseClickedBool(imgui.MouseButtonLeft) && m.OnMove != nil {
+		if tx, ty, ok := l.toTile(mouse.X, mouse.Y); ok {
+			m.OnMove(tx, ty)
+		}
+	}
+}
+
+func drawWorldMapImGui(m *AreaMapState, box ui2d.Rect) {
+	l := fitMap(box.X, box.Y, box.W, box.H, 4, 3)
+	dl := imgui.WindowDrawList()
+	dl.AddRectFilledV(imgui.NewVec2(l.x, l.y), imgui.NewVec2(l.x+l.w, l.y+l.h), imguiColor(ui2d.Color{R: 0.1, G: 0.2, B: 0.3, A: 1}), 0, 0)
+
+	mouse := imgui.MousePos()
+	for _, town := range m.Towns {
+		x, y := l.x+town.X*l.w, l.y+town.Y*l.h
+		color := markerColor(MarkerTypeNPC)
+		switch {
+		case town.Current:
+			color = markerColor(MarkerTypePlayer)
+		case town.Map == m.Target:
+			color = ui2d.ColorHighlight
+		}
+		dl.AddCircleFilledV(imgui.NewVec2(x, y), 5, imguiColor(color), 12)
+		dl.AddTextVec2(imgui.NewVec2(x+8, y-7), imguiColor(ui2d.ColorTextOnDark), town.Label)
+
+		if near(mouse.X, mouse.Y, x, y) && imgui.IsWindowHovered() &&
+			imgui.IsMouseClickedBool(imgui.MouseButtonLeft) && m.OnSelectTown != nil {
+			m.OnSelectTown(town.Map)
+		}
+	}
+}
+
+// renderDamageNumbers draws damage numbers over the scene window.
+func renderDamageNumbers(nums []DamageNumber) {
+	dl := imgui.WindowDrawList()
+	font := imgui.CurrentFont()
+	for _, d := range nums {
+		size := imgui.FontSize() * d.Scale
+		textSize := imgui.CalcTextSize(d.Text).Mul(d.Scale)
+		pos := imgui.NewVec2(d.X-textSize.X/2, d.Y-textSize.Y/2)
+		dl.AddTextFontPtr(font, size, pos.Add(imgui.NewVec2(1, 1)), imguiColor(ui2d.Color{A: d.Color.A}), d.Text)
+		dl.AddTextFontPtr(font, size, pos, imguiColor(d.Color), d.Text)
+	}
+}
+
+// renderBanner draws a server announcement on a strip across the top of
+// the scene window.
+func renderBanner(b *BannerState, viewportWidth float32) {
+	dl := imgui.WindowDrawList()
+	scale := bannerScale(b.FontSize)
+	textSize := imgui.CalcTextSize(b.Text).Mul(scale)
+	x := bannerX(b.Progress, textSize.X, viewportWidth)
+	dl.AddRectFilled(imgui.NewVec2(0, bannerTop-bannerPad), imgui.NewVec2(viewportWidth, bannerTop+textSize.Y+bannerPad),
+		imguiColor(ui2d.ColorBlack.WithAlpha(0.45)))
+	size := imgui.FontSize() * scale
+	dl.AddTextFontPtr(imgui.CurrentFont(), size, imgui.NewVec2(x+1, bannerTop+1), imguiColor(ui2d.ColorBlack), b.Text)
+	dl.AddTextFontPtr(imgui.CurrentFont(), size, imgui.NewVec2(x, bannerTop), imguiColor(bannerColor(b.Color)), b.Text)
+}
+
+// renderBuffs draws the player's buffs in a column down the right edge of
+// the scene window, each lettered, with a ring counting down its time left.
+func renderBuffs(buffs []BuffIcon, viewportWidth float32) {
+	dl := imgui.WindowDrawList()
+	for i, buff := range buffs {
+		x, y := buffColumn(i, viewportWidth)
+		pMin, pMax := imgui.NewVec2(x, y), imgui.NewVec2(x+buffIconSize, y+buffIconSize)
+		if imgui.IsMouseHoveringRect(pMin, pMax) {
+			tip := buff.Name
+			if buff.Timed {
+				tip += "\n" + buffTime(buff.Remaining) + " left"
+			}
+			imgui.SetTooltip(tip)
+		}
+		if !buffVisible(buff) {
+			continue
+		}
+		dl.AddRectFilledV(pMin, pMax, imguiColor(ui2d.ColorPanelBg), 4, 0)
+		label := buffLabel(buff.Name)
+		labelW := imgui.CalcTextSize(label).X
+		dl.AddTextVec2(imgui.NewVec2(x+(buffIconSize-labelW)/2, y+4), imguiColor(ui2d.ColorTextOnDark), label)
+		if !buff.Timed {
+			continue
+		}
+		left := buffTime(buff.Remaining)
+		leftW := imgui.CalcTextSize(left).X
+		dl.AddTextVec2(imgui.NewVec2(x+(buffIconSize-leftW)/2, y+buffIconSize-16), imguiColor(ui2d.ColorTextDim), left)
+		ring := ui2d.ColorHighlight
+		if buff.Expiring {
+			ring = ui2d.Color{R: 1, G: 0.4, B: 0.2, A: 1}
+		}
+		// Clockwise from the top, shrinking as the time runs out
+		start := float32(-math.Pi / 2)
+		dl.PathArcToV(imgui.NewVec2(x+buffIconSize/2, y+buffIconSize/2), buffIconSize/2-buffRing/2,
+			start, start+2*math.Pi*buff.Fraction, 0)
+		dl.PathStrokeV(imguiColor(ring), 0, buffRing)
+	}
+}
+
+// renderChatBubbles draws what units said in boxes over their heads, over
+// the scene window.
+func renderChatBubbles(bubbles []ChatBubble) {
+	dl := imgui.WindowDrawList()
+	measure := func(s string) float32 { return imgui.CalcTextSize(s).X }
+	lineH := imgui.TextLineHeight()
+	for i := range bubbles {
+		b := &bubbles[i]
+		lines, box := bubbleBox(b, lineH, measure)
+		pMin, pMax := imgui.NewVec2(box.X, box.Y), imgui.NewVec2(box.X+box.W, box.Y+box.H)
+		dl.AddRectFilledV(pMin, pMax, imguiColor(ui2d.ColorPanelBg.WithAlpha(ui2d.ColorPanelBg.A*b.Alpha)), 4, 0)
+		dl.AddRectV(pMin, pMax, imguiColor(ui2d.ColorPanelBorder.WithAlpha(b.Alpha)), 4, 0, 1)
+		y := box.Y + chatBubblePad
+		for _, l := range lines {
+			w := measure(l)
+			dl.AddTextVec2(imgui.NewVec2(b.X-w/2, y), imguiColor(ui2d.ColorTextOnDark.WithAlpha(b.Alpha)), l)
+			y += lineH
+		}
+	}
+}
+
+// renderNameplates draws unit names over the scene window, and the HP bars
+// of party members under them.
+func renderNameplates(plates []Nameplate) {
+	dl := imgui.WindowDrawList()
+	for i := range plates {
+		p := &plates[i]
+		y := p.Y
+		for _, l := range p.Lines {
+			w := imgui.CalcTextSize(l.Text).X
+			dl.AddTextVec2(imgui.NewVec2(p.X-w/2+1, y+1), imguiColor(ui2d.Color{A: l.Color.A}), l.Text)
+			dl.AddTextVec2(imgui.NewVec2(p.X-w/2, y), imguiColor(l.Color), l.Text)
+			y += NameplateLineHeight
+		}
+		if p.HP >= 0 {
+			x := p.X - nameplateHPWidth/2
+			dl.AddRectFilled(imgui.NewVec2(x-1, y+1), imgui.NewVec2(x+nameplateHPWidth+1, y+NameplateHPHeight+3),
+				imguiColor(ui2d.ColorBlack.WithAlpha(0.7)))
+			dl.AddRectFilled(imgui.NewVec2(x, y+2), imgui.NewVec2(x+nameplateHPWidth*p.HP, y+NameplateHPHeight+2),
+				imguiColor(p.HPColor))
+		}
+	}
+}
+
+// imguiColor packs a ui2d color for the imgui draw list.
+func imguiColor(c ui2d.Color) uint32 {
+	return imgui.ColorU32Vec4(imgui.NewVec4(c.R, c.G, c.B, c.A))
+}
+
+// renderVendingShop draws another player's shop. Selecting an item asks
+// how many of it to put in the cart, limited by the zeny the rest of the
+// cart leaves.
+func (ui *ImGuiInGameUI) renderVendingShop(shop *VendingShopState, viewportWidth, viewportHeight float32) {
+	if ui.shopCart == nil || ui.shopVendor != shop.VendorID {
+		ui.shopVendor = shop.VendorID
+		ui.shopCart = make(map[int]int)
+		ui.shopPrompt = nil
+	}
+
+	imgui.SetNextWindowPosV(imgui.NewVec2(viewportWidth/2, viewportHeight/2), imgui.CondFirstUseEver, imgui.NewVec2(0.5, 0.5))
+	imgui.SetNextWindowSizeV(imgui.NewVec2(380, 320), imgui.CondFirstUseEver)
+
+	open := true
+	var buy bool
+	if imgui.BeginV(shop.Title+"##VendingShop", &open, imgui.WindowFlagsNoSavedSettings|imgui.WindowFlagsNoCollapse) {
+		for _, item := range shop.Items {
+			imgui.PushIDInt(int32(item.Index))
+			label := fmt.Sprintf("%s  x%d  %s z", item.Name, item.Amount, formatZeny(int64(item.Price)))
+			if n := ui.shopCart[item.Index]; n > 0 {
+				label += fmt.Sprintf("  [buy %d]", n)
+			}
+			selected := ui.shopPrompt != nil && ui.shopSel == item.Index
+			color, graded := imguiItemColor(item.Grade)
+			if graded {
+				imgui.PushStyleColorVec4(imgui.ColText, color)
+			}
+			if imgui.SelectableBoolV(label, selected, 0, imgui.NewVec2(0, 0)) {
+				ui.shopSel = item.Index
+				ui.shopPrompt = shop.CartPrompt(item, ui.shopCart, func() { ui.shopPrompt = nil })
+				ui.qtyTitle = "" // Open at this item's amount even if another has its name
+			}
+			if graded {
+				imgui.PopStyleColor()
+			}
+			imgui.PopID()
+		}
+		imgui.Separator()
+
+		total := shop.CartTotal(ui.shopCart)
+		totalText := fmt.Sprintf("Total: %s z   (carrying %s z)", formatZeny(total), formatZeny(shop.Zeny))
+		if total > shop.Zeny {
+			imgui.TextColored(imgui.NewVec4(1, 0.3, 0.3, 1), totalText)
+		} else {
+			imgui.Text(totalText)
+		}
+		buy = imgui.Button("Buy") && total > 0
+		imgui.SameLine()
+		if imgui.Button("Clear") {
+			clear(ui.shopCart)
+		}
+		// Escape closes the quantity dialog first
+		if ui.shopPrompt == nil && imgui.IsWindowFocused() && imgui.IsKeyPressedBool(imgui.KeyEscape) {
+			open = false
+		}
+	}
+	imgui.End()
+
+	if ui.shopPrompt != nil {
+		ui.renderQuantityPrompt(ui.shopPrompt, viewportWidth, viewportHeight)
+	}
+
+	switch {
+	case buy:
+		if shop.OnBuy != nil {
+			shop.OnBuy(ui.shopCart) // The cart is kept if the purchase is refused
+		}
+	case !open:
+		ui.shopCart = nil
+		ui.shopPrompt = nil
+		ui.qtyTitle = ""
+		if shop.OnClose != nil {
+			shop.OnClose()
+		}
+	}
+}
+
+// renderAttackCursor draws a crosshair at the mouse over an attackable unit.
+func renderAttackCursor() {
+	dl := imgui.ForegroundDrawListViewportPtr()
+	m := imgui.MousePos()
+	col := imguiColor(pvpColor)
+	const arm, gap = 8, 3
+	dl.AddLineV(imgui.NewVec2(m.X-gap-arm, m.Y), imgui.NewVec2(m.X-gap, m.Y), col, 2)
+	dl.AddLineV(imgui.NewVec2(m.X+gap, m.Y), imgui.NewVec2(m.X+gap+arm, m.Y), col, 2)
+	dl.AddLineV(imgui.NewVec2(m.X, m.Y-gap-arm), imgui.NewVec2(m.X, m.Y-gap), col, 2)
+	dl.AddLineV(imgui.NewVec2(m.X, m.Y+gap), imgui.NewVec2(m.X, m.Y+gap+arm), col, 2)
+}
+
 func (ui *ImGuiInGameUI) renderBottomStatusBar(state InGameUIState, viewportWidth, viewportHeight float32) {
 	barHeight := float32(25)
 	imgui.SetNextWindowPos(imgui.NewVec2(0, viewportHeight-barHeight))
@@ -608,11 +2047,25 @@ func (ui *ImGuiInGameUI) renderBottomStatusBar(state InGameUIState, viewportWidt
 			imgui.Text(state.StatusMessage)
 		} else {
 			imgui.Text(fmt.Sprintf("Map: %s", state.MapName))
+			if pvpText := state.PVPText(); pvpText != "" {
+				imgui.SameLine()
+				imgui.TextColored(imgui.NewVec4(pvpColor.R, pvpColor.G, pvpColor.B, pvpColor.A), pvpText)
+			}
 		}
 
-		imgui.SameLine()
 		posText := fmt.Sprintf("(%d, %d)", state.PlayerTileX, state.PlayerTileY)
 		textWidth := imgui.CalcTextSize(posText).X
+		if connText := state.ConnectionText(); connText != "" {
+			imgui.SameLine()
+			imgui.SetCursorPosX(viewportWidth - textWidth - imgui.CalcTextSize(connText).X - 40)
+			if state.ServerLagging {
+				imgui.TextColored(imgui.NewVec4(1, 0.3, 0.3, 1), connText)
+			} else {
+				imgui.Text(connText)
+			}
+		}
+
+		imgui.SameLine()
 		imgui.SetCursorPosX(viewportWidth - textWidth - 20)
 		imgui.Text(posText)
 	}
@@ -665,32 +2118,3 @@ func imguiAddInfoRow(label, value string) {
 	imgui.TableNextColumn()
 	imgui.Text(value)
 }
-
-// imguiGetJobName returns the job class name from the job ID.
-func imguiGetJobName(jobID uint16) string {
-	jobs := map[uint16]string{
-		0:    "Novice",
-		1:    "Swordman",
-		2:    "Mage",
-		3:    "Archer",
-		4:    "Acolyte",
-		5:    "Merchant",
-		6:    "Thief",
-		7:    "Knight",
-		8:    "Priest",
-		9:    "Wizard",
-		10:   "Blacksmith",
-		11:   "Hunter",
-		12:   "Assassin",
-		4008: "Lord Knight",
-		4009: "High Priest",
-		4010: "High Wizard",
-		4054: "Rune Knight",
-		4055: "Warlock",
-		4057: "Arch Bishop",
-	}
-	if name, ok := jobs[jobID]; ok {
-		return name
-	}
-	return fmt.Sprintf("Job %d", jobID)
-}
@@ -138,6 +138,19 @@ func (ui *InGameUI) Update(deltaMs float64) {
 		ui.debugOverlay.LastSentLen = st.LastSentLen
 		ui.debugOverlay.LastRecvID = st.LastRecvID
 		ui.debugOverlay.LastRecvLen = st.LastRecvLen
+		ui.debugOverlay.PacketsQueued = st.Queued
+		ui.debugOverlay.PacketsCoalesced = st.Coalesced
+		ui.debugOverlay.PacketsDeferred = st.Deferred
+		ui.debugOverlay.SendWrites = st.Writes
+		ui.debugOverlay.InboundQueued = st.InboundQueued
+		ui.debugOverlay.InboundPeak = st.InboundPeak
+		ui.debugOverlay.ReadStalls = st.ReadStalls
+		ui.debugOverlay.WriteStalls = st.WriteStalls
+		ui.debugOverlay.UnknownPackets = st.UnknownPackets
+		ui.debugOverlay.LastUnknownID = st.LastUnknownID
+		ui.debugOverlay.Resyncs = st.Resyncs
+		ui.debugOverlay.DroppedBytes = st.DroppedBytes
+		ui.debugOverlay.VersionMismatch = st.VersionMismatch
 		now := time.Now()
 		if !st.LastSentAt.IsZero() {
 			ui.debugOverlay.LastSentAgo = now.Sub(st.LastSentAt)
@@ -0,0 +1,195 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/ui2d"
+)
+
+// NotifyKind is what a notification is about, which sets its icon and
+// color and the window a click on it opens.
+type NotifyKind uint8
+
+const (
+	NotifyItem       NotifyKind = iota // Item gained; opens the inventory
+	NotifyFriend                       // Friend came online
+	NotifyGuild                        // Guild member came online
+	NotifyQuest                        // Quest objective progress
+	NotifyScreenshot                   // Screenshot saved; opens the gallery
+)
+
+// notifyStyles are the icon letters and colors of the kinds.
+var notifyStyles = [...]struct {
+	icon  string
+	color ui2d.Color
+}{
+	NotifyItem:       {"+", ui2d.Color{R: 0.95, G: 0.8, B: 0.3, A: 1}},
+	NotifyFriend:     {"F", ui2d.Color{R: 0.4, G: 0.85, B: 0.45, A: 1}},
+	NotifyGuild:      {"G", ui2d.Color{R: 0.45, G: 0.7, B: 1, A: 1}},
+	NotifyQuest:      {"!", ui2d.Color{R: 1, G: 0.6, B: 0.25, A: 1}},
+	NotifyScreenshot: {"S", ui2d.Color{R: 0.2, G: 1, B: 0.2, A: 1}},
+}
+
+// Icon returns the letter a notification of the kind is marked with.
+func (k NotifyKind) Icon() string {
+	if int(k) < len(notifyStyles) {
+		return notifyStyles[k].icon
+	}
+	return "?"
+}
+
+// Color returns the color of a notification of the kind.
+func (k NotifyKind) Color() ui2d.Color {
+	if int(k) < len(notifyStyles) {
+		return notifyStyles[k].color
+	}
+	return ui2d.ColorTextOnDark
+}
+
+// Notification is a toast in the corner of the screen.
+type Notification struct {
+	ID    uint64 // Set by the queue, passed back to Dismiss
+	Kind  NotifyKind
+	Text  string
+	Key   string // Notifications of a key merge, adding up Count; "" for none
+	Count int    // Items gained, shown past 1; 0 for none
+
+	// Alpha is the notification's opacity, fading out at the end
+	Alpha float32
+
+	shownAt time.Time // Zero while it waits for room
+}
+
+// Label returns the text shown, with the count, such as "Red Potion x3".
+func (n Notification) Label() string {
+	if n.Count > 1 {
+		return fmt.Sprintf("%s x%d", n.Text, n.Count)
+	}
+	return n.Text
+}
+
+// Notification defaults: how long each shows, and how many show at once.
+const (
+	DefaultNotifyDuration = 4 * time.Second
+	DefaultNotifyVisible  = 4
+	notifyFadeOut         = 400 * time.Millisecond
+)
+
+// NotificationQueue holds the notifications on screen and those waiting
+// for room. Up to MaxVisible show at once, oldest first, each for Duration
+// from when it gets its place. A notification whose key is queued or
+// shown merges into that one, which shows for its full time again.
+type NotificationQueue struct {
+	Duration   time.Duration
+	MaxVisible int
+
+	doNotDisturb bool
+	items        []Notification
+	nextID       uint64
+}
+
+// NewNotificationQueue creates a queue; a zero duration or count takes
+// the default.
+func NewNotificationQueue(duration time.Duration, maxVisible int) *NotificationQueue {
+	if duration <= 0 {
+		duration = DefaultNotifyDuration
+	}
+	if maxVisible <= 0 {
+		maxVisible = DefaultNotifyVisible
+	}
+	return &NotificationQueue{Duration: duration, MaxVisible: maxVisible}
+}
+
+// SetDoNotDisturb turns do-not-disturb on or off. While it's on
+// notifications are dropped, and turning it on clears the queue.
+func (q *NotificationQueue) SetDoNotDisturb(on bool) {
+	if on && !q.doNotDisturb {
+		q.items = nil
+	}
+	q.doNotDisturb = on
+}
+
+// DoNotDisturb reports whether notifications are dropped.
+func (q *NotificationQueue) DoNotDisturb() bool {
+	return q.doNotDisturb
+}
+
+// Push adds a notification at now.
+func (q *NotificationQueue) Push(n Notification, now time.Time) {
+	if q.doNotDisturb {
+		return
+	}
+	q.expire(now)
+	if n.Key != "" {
+		for i := range q.items {
+			it := &q.items[i]
+			if it.Key != n.Key {
+				continue
+			}
+			it.Count += n.Count
+			it.Text = n.Text
+			if !it.shownAt.IsZero() {
+				it.shownAt = now
+			}
+			return
+		}
+	}
+	q.nextID++
+	n.ID = q.nextID
+	n.shownAt = time.Time{}
+	q.items = append(q.items, n)
+}
+
+// Visible returns the notifications on screen at now, oldest first, with
+// their opacity. Waiting notifications take the places of expired ones.
+func (q *NotificationQueue) Visible(now time.Time) []Notification {
+	q.expire(now)
+	shown := make([]Notification, min(len(q.items), q.MaxVisible))
+	for i := range shown {
+		it := &q.items[i]
+		if it.shownAt.IsZero() {
+			it.shownAt = now
+		}
+		shown[i] = *it
+		shown[i].Alpha = 1
+		if left := q.Duration - now.Sub(it.shownAt); left < notifyFadeOut {
+			shown[i].Alpha = float32(left) / float32(notifyFadeOut)
+		}
+	}
+	return shown
+}
+
+// Dismiss removes a notification, clicked away.
+func (q *NotificationQueue) Dismiss(id uint64) {
+	for i, it := range q.items {
+		if it.ID == id {
+			q.items = append(q.items[:i], q.items[i+1:]...)
+			return
+		}
+	}
+}
+
+// Len returns the number of notifications shown and waiting.
+func (q *NotificationQueue) Len() int {
+	return len(q.items)
+}
+
+// expire drops the notifications whose time is up.
+func (q *NotificationQueue) expire(now time.Time) {
+	kept := q.items[:0]
+	for _, it := range q.items {
+		if it.shownAt.IsZero() || now.Sub(it.shownAt) < q.Duration {
+			kept = append(kept, it)
+		}
+	}
+	q.items = kept
+}
+
+// NotificationsState contains the data needed to render the notifications.
+type NotificationsState struct {
+	Items []Notification
+
+	// OnClick is called when a notification is clicked
+	OnClick func(n Notification)
+}
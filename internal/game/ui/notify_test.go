@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNotificationQueue(t *testing.T) {
+	q := NewNotificationQueue(4*time.Second, 2)
+	now := time.Unix(1000, 0)
+
+	q.Push(Notification{Kind: NotifyItem, Text: "Red Potion", Key: "item:501", Count: 2}, now)
+	q.Push(Notification{Kind: NotifyFriend, Text: "Buddy is now online."}, now)
+	q.Push(Notification{Kind: NotifyScreenshot, Text: "Saved: shot.png"}, now)
+	q.Push(Notification{Kind: NotifyItem, Text: "Red Potion", Key: "item:501", Count: 3}, now)
+
+	shown := q.Visible(now)
+	if len(shown) != 2 || q.Len() != 3 {
+		t.Fatalf("Visible = %d of %d, want 2 of 3", len(shown), q.Len())
+	}
+	if got := shown[0].Label(); got != "Red Potion x5" {
+		t.Errorf("merged label = %q, want %q", got, "Red Potion x5")
+	}
+	if shown[1].Label() != "Buddy is now online." {
+		t.Errorf("second = %q", shown[1].Label())
+	}
+
+	// Fading out, then the waiting screenshot takes a place
+	if shown := q.Visible(now.Add(3800 * time.Millisecond)); shown[0].Alpha <= 0 || shown[0].Alpha >= 1 {
+		t.Errorf("alpha near the end = %v", shown[0].Alpha)
+	}
+	later := now.Add(4 * time.Second)
+	shown = q.Visible(later)
+	if len(shown) != 1 || shown[0].Kind != NotifyScreenshot || shown[0].Alpha != 1 {
+		t.Fatalf("after expiry Visible = %+v", shown)
+	}
+
+	// A merge into a shown notification shows it for its full time again
+	q.Push(Notification{Kind: NotifyItem, Text: "Jellopy", Key: "item:909", Count: 1}, later)
+	q.Visible(later)
+	q.Push(Notification{Kind: NotifyItem, Text: "Jellopy", Key: "item:909", Count: 1}, later.Add(3*time.Second))
+	shown = q.Visible(later.Add(5 * time.Second))
+	if len(shown) != 1 || shown[0].Label() != "Jellopy x2" {
+		t.Fatalf("refreshed Visible = %+v", shown)
+	}
+
+	// A notification without a count takes the merged one's text
+	q.Push(Notification{Kind: NotifyQuest, Text: "Quest 7128: 3/10", Key: "quest:7128001"}, later)
+	q.Push(Notification{Kind: NotifyQuest, Text: "Quest 7128: 4/10", Key: "quest:7128001"}, later)
+	if shown := q.Visible(later); len(shown) != 2 || shown[1].Label() != "Quest 7128: 4/10" {
+		t.Fatalf("quest Visible = %+v", shown)
+	}
+
+	q.Dismiss(shown[0].ID)
+	if q.Len() != 1 {
+		t.Errorf("Len after Dismiss = %d, want 1", q.Len())
+	}
+}
+
+func TestNotificationQueueDoNotDisturb(t *testing.T) {
+	q := NewNotificationQueue(0, 0)
+	if q.Duration != DefaultNotifyDuration || q.MaxVisible != DefaultNotifyVisible {
+		t.Errorf("defaults = %v, %d", q.Duration, q.MaxVisible)
+	}
+	now := time.Unix(1000, 0)
+	q.Push(Notification{Text: "a"}, now)
+	q.SetDoNotDisturb(true)
+	if q.Len() != 0 {
+		t.Error("do-not-disturb kept the queue")
+	}
+	q.Push(Notification{Text: "b"}, now)
+	if q.Len() != 0 {
+		t.Error("notification pushed during do-not-disturb")
+	}
+	q.SetDoNotDisturb(false)
+	q.Push(Notification{Text: "c"}, now)
+	if q.Len() != 1 {
+		t.Errorf("Len after do-not-disturb = %d, want 1", q.Len())
+	}
+}
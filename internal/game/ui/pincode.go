@@ -0,0 +1,141 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/Faultbox/midgard-ro/internal/network/packets"
+)
+
+// pincodeStep is one PIN the keypad asks for.
+type pincodeStep int
+
+const (
+	stepCurrent pincodeStep = iota
+	stepNew
+	stepConfirm
+)
+
+// pincodeKey identifies a prompt: a new one, even with the same question,
+// comes with a reshuffled keypad or a message.
+type pincodeKey struct {
+	askCurrent, askNew bool
+	keypad             [10]byte
+	message            string
+}
+
+// PincodeEntry is the typing on the PIN keypad, shared by the backends.
+// It walks through the PINs the prompt asks for: the current one, then a
+// new one typed twice.
+type PincodeEntry struct {
+	steps   []pincodeStep
+	step    int
+	typed   string
+	current string
+	newPin  string
+	err     string
+
+	open bool       // A prompt is shown
+	last pincodeKey // The prompt shown
+}
+
+// Sync starts over when the prompt changes: opened, or asked again after
+// the server refused a PIN. A nil prompt closes the keypad.
+func (e *PincodeEntry) Sync(p *PincodeUIState) {
+	if p == nil {
+		e.open = false
+		return
+	}
+	key := pincodeKey{p.AskCurrent, p.AskNew, p.Keypad, p.Message}
+	if e.open && key == e.last {
+		return
+	}
+	*e = PincodeEntry{open: true, last: key}
+	if p.AskCurrent {
+		e.steps = append(e.steps, stepCurrent)
+	}
+	if p.AskNew {
+		e.steps = append(e.steps, stepNew, stepConfirm)
+	}
+}
+
+// Prompt returns the line above the digits.
+func (e *PincodeEntry) Prompt() string {
+	if e.step >= len(e.steps) {
+		return ""
+	}
+	switch e.steps[e.step] {
+	case stepCurrent:
+		if len(e.steps) > 1 {
+			return "Enter your current PIN code"
+		}
+		return "Enter your PIN code"
+	case stepNew:
+		return "Enter a new PIN code"
+	default:
+		return "Enter the new PIN code again"
+	}
+}
+
+// Masked returns the digits typed so far, hidden.
+func (e *PincodeEntry) Masked() string {
+	return strings.TrimSpace(strings.Repeat("* ", len(e.typed)) +
+		strings.Repeat("_ ", packets.PincodeLength-len(e.typed)))
+}
+
+// Error returns why the last confirmation was refused, if it was.
+func (e *PincodeEntry) Error() string {
+	return e.err
+}
+
+// Press types the digit on a keypad button.
+func (e *PincodeEntry) Press(digit byte) {
+	if len(e.typed) < packets.PincodeLength && digit <= 9 {
+		e.typed += string('0' + digit)
+	}
+}
+
+// Back erases the last digit.
+func (e *PincodeEntry) Back() {
+	if e.typed != "" {
+		e.typed = e.typed[:len(e.typed)-1]
+	}
+}
+
+// Clear erases the digits typed.
+func (e *PincodeEntry) Clear() {
+	e.typed = ""
+}
+
+// Ready reports whether a whole PIN is typed.
+func (e *PincodeEntry) Ready() bool {
+	return len(e.typed) == packets.PincodeLength
+}
+
+// Confirm accepts the PIN typed and moves on. After the last step it
+// returns the PINs to submit and true; a confirmation that doesn't match
+// asks for the new PIN again.
+func (e *PincodeEntry) Confirm() (current, newPin string, done bool) {
+	if !e.Ready() || e.step >= len(e.steps) {
+		return "", "", false
+	}
+	e.err = ""
+	switch e.steps[e.step] {
+	case stepCurrent:
+		e.current = e.typed
+	case stepNew:
+		e.newPin = e.typed
+	case stepConfirm:
+		if e.typed != e.newPin {
+			e.err = "The PIN codes don't match."
+			e.typed, e.newPin = "", ""
+			e.step--
+			return "", "", false
+		}
+	}
+	e.typed = ""
+	e.step++
+	if e.step < len(e.steps) {
+		return "", "", false
+	}
+	return e.current, e.newPin, true
+}
@@ -0,0 +1,75 @@
+package ui
+
+import "testing"
+
+// typePin presses the buttons showing the digits of pin.
+func typePin(e *PincodeEntry, pin string) {
+	for _, c := range pin {
+		e.Press(byte(c - '0'))
+	}
+}
+
+func TestPincodeEntryEnter(t *testing.T) {
+	var e PincodeEntry
+	e.Sync(&PincodeUIState{AskCurrent: true})
+	if got := e.Prompt(); got != "Enter your PIN code" {
+		t.Errorf("Prompt() = %q", got)
+	}
+	typePin(&e, "12")
+	if got := e.Masked(); got != "* * _ _" {
+		t.Errorf("Masked() = %q", got)
+	}
+	if _, _, done := e.Confirm(); done {
+		t.Fatal("Confirm accepted a partial PIN")
+	}
+	typePin(&e, "3456")
+	e.Back()
+	typePin(&e, "9")
+	current, newPin, done := e.Confirm()
+	if !done || current != "1239" || newPin != "" {
+		t.Errorf("Confirm() = %q, %q, %v, want 1239", current, newPin, done)
+	}
+}
+
+func TestPincodeEntryChange(t *testing.T) {
+	var e PincodeEntry
+	prompt := &PincodeUIState{AskCurrent: true, AskNew: true}
+	e.Sync(prompt)
+	steps := []struct {
+		pin, prompt string
+	}{
+		{"1111", "Enter your current PIN code"},
+		{"2580", "Enter a new PIN code"},
+		{"2581", "Enter the new PIN code again"},
+	}
+	for _, s := range steps {
+		if got := e.Prompt(); got != s.prompt {
+			t.Errorf("Prompt() = %q, want %q", got, s.prompt)
+		}
+		typePin(&e, s.pin)
+		if _, _, done := e.Confirm(); done {
+			t.Fatalf("Confirm finished at %q", s.prompt)
+		}
+	}
+	// The confirmation didn't match: the new PIN is asked again
+	if e.Error() == "" || e.Prompt() != "Enter a new PIN code" {
+		t.Fatalf("mismatch: Error() = %q, Prompt() = %q", e.Error(), e.Prompt())
+	}
+	typePin(&e, "2580")
+	e.Confirm()
+	typePin(&e, "2580")
+	if current, newPin, done := e.Confirm(); !done || current != "1111" || newPin != "2580" {
+		t.Errorf("Confirm() = %q, %q, %v", current, newPin, done)
+	}
+
+	// Asked again with a reshuffled keypad, typing starts over
+	typePin(&e, "12")
+	e.Sync(prompt)
+	if e.Masked() != "* * _ _" {
+		t.Error("Sync restarted an unchanged prompt")
+	}
+	e.Sync(&PincodeUIState{AskCurrent: true, AskNew: true, Keypad: [10]byte{9}, Message: "Wrong PIN code."})
+	if e.Masked() != "_ _ _ _" || e.Prompt() != "Enter your current PIN code" {
+		t.Errorf("Sync kept typing across prompts: %q, %q", e.Masked(), e.Prompt())
+	}
+}
@@ -0,0 +1,66 @@
+package ui
+
+import "fmt"
+
+// Limit returns the most the prompt accepts and, when weight or zeny caps
+// it below the amount available, why. The limit is below Min when not even
+// that many can be carried or paid for.
+func (p *QuantityPrompt) Limit() (int, string) {
+	limit, reason := p.Max, ""
+	if p.UnitWeight > 0 {
+		if n := max(0, p.WeightLeft/p.UnitWeight); n < limit {
+			limit, reason = n, "Too heavy to carry more"
+		}
+	}
+	if p.UnitPrice > 0 {
+		if n := max(0, p.Zeny/p.UnitPrice); n < int64(limit) {
+			limit, reason = int(n), "Not enough zeny"
+		}
+	}
+	return limit, reason
+}
+
+// Allowed reports whether any amount can be confirmed, false when weight
+// or zeny caps the limit below Min.
+func (p *QuantityPrompt) Allowed() bool {
+	limit, _ := p.Limit()
+	return limit >= p.Min
+}
+
+// Clamp limits an amount to the range the prompt accepts.
+func (p *QuantityPrompt) Clamp(amount int) int {
+	limit, _ := p.Limit()
+	return max(p.Min, min(amount, limit))
+}
+
+// Start returns the amount the dialog opens with.
+func (p *QuantityPrompt) Start() int {
+	if p.Initial == 0 {
+		limit, _ := p.Limit()
+		return max(limit, p.Min)
+	}
+	return p.Clamp(p.Initial)
+}
+
+// Step returns amount moved by one, or by ten with shift held, in the
+// direction of dir, for the arrow keys.
+func (p *QuantityPrompt) Step(amount, dir int, shift bool) int {
+	if shift {
+		dir *= 10
+	}
+	return p.Clamp(amount + dir)
+}
+
+// Hint returns the line under the title: the range accepted, and the cost
+// of amount when paying.
+func (p *QuantityPrompt) Hint(amount int) string {
+	limit, _ := p.Limit()
+	if limit < p.Min {
+		return "How many? (none)"
+	}
+	hint := fmt.Sprintf("How many? (%d-%d)", p.Min, limit)
+	if p.UnitPrice > 0 {
+		hint += fmt.Sprintf("  %s z", formatZeny(p.UnitPrice*int64(amount)))
+	}
+	return hint
+}
@@ -0,0 +1,63 @@
+package ui
+
+import "testing"
+
+func TestQuantityPromptLimit(t *testing.T) {
+	tests := []struct {
+		name       string
+		prompt     QuantityPrompt
+		wantLimit  int
+		wantReason string
+	}{
+		{"available", QuantityPrompt{Min: 1, Max: 30}, 30, ""},
+		{"weight", QuantityPrompt{Min: 1, Max: 30, UnitWeight: 10, WeightLeft: 95}, 9, "Too heavy to carry more"},
+		{"zeny", QuantityPrompt{Max: 30, UnitPrice: 1000, Zeny: 12500}, 12, "Not enough zeny"},
+		{"zeny under weight", QuantityPrompt{Max: 30, UnitWeight: 10, WeightLeft: 95, UnitPrice: 1000, Zeny: 4000}, 4, "Not enough zeny"},
+		{"plenty", QuantityPrompt{Max: 30, UnitWeight: 1, WeightLeft: 500, UnitPrice: 10, Zeny: 1e6}, 30, ""},
+		{"below min", QuantityPrompt{Min: 1, Max: 30, UnitWeight: 10, WeightLeft: -5}, 0, "Too heavy to carry more"},
+		{"none affordable", QuantityPrompt{Min: 1, Max: 30, UnitPrice: 1000, Zeny: 999}, 0, "Not enough zeny"},
+	}
+	for _, tt := range tests {
+		limit, reason := tt.prompt.Limit()
+		if limit != tt.wantLimit || reason != tt.wantReason {
+			t.Errorf("%s: Limit() = %d, %q, want %d, %q", tt.name, limit, reason, tt.wantLimit, tt.wantReason)
+		}
+		if allowed := tt.prompt.Allowed(); allowed != (tt.wantLimit >= tt.prompt.Min) {
+			t.Errorf("%s: Allowed() = %v", tt.name, allowed)
+		}
+	}
+}
+
+func TestQuantityPromptAmounts(t *testing.T) {
+	p := &QuantityPrompt{Min: 1, Max: 30, UnitPrice: 100, Zeny: 2000}
+	if got := p.Start(); got != 20 {
+		t.Errorf("Start() = %d, want the limit 20", got)
+	}
+	p.Initial = 5
+	if got := p.Start(); got != 5 {
+		t.Errorf("Start() = %d, want 5", got)
+	}
+	if got := p.Clamp(0); got != 1 {
+		t.Errorf("Clamp(0) = %d, want 1", got)
+	}
+	if got := p.Step(5, 1, false); got != 6 {
+		t.Errorf("Step up = %d, want 6", got)
+	}
+	if got := p.Step(15, 1, true); got != 20 {
+		t.Errorf("Step up by ten = %d, want the limit 20", got)
+	}
+	if got := p.Step(5, -1, true); got != 1 {
+		t.Errorf("Step down by ten = %d, want 1", got)
+	}
+	if got, want := p.Hint(12), "How many? (1-20)  1,200 z"; got != want {
+		t.Errorf("Hint(12) = %q, want %q", got, want)
+	}
+
+	p = &QuantityPrompt{Min: 1, Max: 30, UnitPrice: 100, Zeny: 50}
+	if got := p.Start(); got != 1 {
+		t.Errorf("unaffordable Start() = %d, want 1", got)
+	}
+	if got, want := p.Hint(1), "How many? (none)"; got != want {
+		t.Errorf("unaffordable Hint = %q, want %q", got, want)
+	}
+}
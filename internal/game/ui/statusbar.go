@@ -6,6 +6,7 @@ import (
 
 	"github.com/AllenDang/cimgui-go/imgui"
 
+	"github.com/Faultbox/midgard-ro/internal/engine/ui2d"
 	"github.com/Faultbox/midgard-ro/internal/game/entity"
 )
 
@@ -15,8 +16,9 @@ type StatusBar struct {
 	entity *entity.Entity
 
 	// Display settings
-	ShowNumeric bool // Show HP/SP as numbers
-	Compact     bool // Compact mode (smaller bars)
+	ShowNumeric bool         // Show HP/SP as numbers
+	Compact     bool         // Compact mode (smaller bars)
+	Palette     ui2d.Palette // HP bar colors
 }
 
 // NewStatusBar creates a new status bar.
@@ -24,6 +26,7 @@ func NewStatusBar() *StatusBar {
 	return &StatusBar{
 		ShowNumeric: true,
 		Compact:     false,
+		Palette:     ui2d.PaletteDefault,
 	}
 }
 
@@ -108,14 +111,12 @@ func (sb *StatusBar) renderBar(label string, current, max int, percent float32,
 }
 
 func (sb *StatusBar) hpColor(percent float32) imgui.Vec4 {
-	if percent > 0.5 {
-		// Green to Yellow gradient
-		t := (percent - 0.5) * 2
-		return imgui.NewVec4(1.0-t*0.5, 0.8+t*0.2, 0.2, 1.0)
-	}
-	// Yellow to Red gradient
-	t := percent * 2
-	return imgui.NewVec4(1.0, t*0.8, 0.2*t, 1.0)
+	return paletteVec4(sb.Palette.HPColor(percent))
+}
+
+// paletteVec4 converts a palette color for ImGui.
+func paletteVec4(c ui2d.Color) imgui.Vec4 {
+	return imgui.NewVec4(c.R, c.G, c.B, c.A)
 }
 
 // EntityHPBar renders a floating HP bar above an entity.
@@ -124,6 +125,7 @@ type EntityHPBar struct {
 	BarWidth  float32
 	BarHeight float32
 	ShowName  bool
+	Palette   ui2d.Palette // HP bar colors
 }
 
 // NewEntityHPBar creates a new entity HP bar renderer.
@@ -132,6 +134,7 @@ func NewEntityHPBar() *EntityHPBar {
 		BarWidth:  60,
 		BarHeight: 6,
 		ShowName:  true,
+		Palette:   ui2d.PaletteDefault,
 	}
 }
 
@@ -189,10 +192,5 @@ func (hb *EntityHPBar) RenderForEntity(e *entity.Entity, screenX, screenY float3
 }
 
 func (hb *EntityHPBar) hpColor(percent float32) imgui.Vec4 {
-	if percent > 0.5 {
-		return imgui.NewVec4(0.2, 0.9, 0.2, 1.0) // Green
-	} else if percent > 0.25 {
-		return imgui.NewVec4(1.0, 0.8, 0.2, 1.0) // Yellow
-	}
-	return imgui.NewVec4(1.0, 0.2, 0.2, 1.0) // Red
+	return paletteVec4(hb.Palette.HPColor(percent))
 }
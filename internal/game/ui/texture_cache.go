@@ -1,9 +1,7 @@
 package ui
 
 import (
-	"bytes"
 	"fmt"
-	"image"
 	"strings"
 
 	"github.com/Faultbox/midgard-ro/internal/engine/texture"
@@ -39,7 +37,8 @@ func normalizePath(path string) string {
 }
 
 // Load loads (or returns cached) a texture from the given GRF path.
-// Applies magenta key transparency for BMP/TGA images.
+// Decodes BMP, JPEG, PNG and TGA, keying out transparent pixels as the
+// texture policy says for interface images.
 func (tc *TextureCache) Load(grfPath string) (*TextureInfo, error) {
 	key := normalizePath(grfPath)
 
@@ -52,13 +51,10 @@ func (tc *TextureCache) Load(grfPath string) (*TextureInfo, error) {
 		return nil, fmt.Errorf("loading texture %s: %w", grfPath, err)
 	}
 
-	img, _, err := image.Decode(bytes.NewReader(data))
+	rgba, err := texture.Load(data, grfPath, texture.CategoryUI)
 	if err != nil {
 		return nil, fmt.Errorf("decoding texture %s: %w", grfPath, err)
 	}
-
-	// Convert to RGBA with magenta key transparency
-	rgba := texture.ImageToRGBA(img, true)
 	bounds := rgba.Bounds()
 
 	texID := tc.renderer.CreateTexture(bounds.Dx(), bounds.Dy(), rgba.Pix)
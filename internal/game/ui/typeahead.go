@@ -0,0 +1,59 @@
+package ui
+
+// maxTypeaheadShown is how many completions the popup lists; Tab still
+// cycles through the rest.
+const maxTypeaheadShown = 8
+
+// typeahead offers completions for the chat line being typed and cycles
+// the input through them with Tab.
+type typeahead struct {
+	line       string // Text the candidates were made for
+	start      int    // Byte offset in line the candidates replace from
+	candidates []string
+	index      int    // Candidate shown in the input, -1 for none yet
+	shown      string // Input text with that candidate
+}
+
+// update refreshes the candidates when the text changed other than by
+// cycling.
+func (t *typeahead) update(text string, complete func(string) (int, []string)) {
+	if text == t.line || (t.index >= 0 && text == t.shown) {
+		return
+	}
+	*t = typeahead{line: text, shown: text, index: -1}
+	if complete != nil && text != "" {
+		t.start, t.candidates = complete(text)
+	}
+}
+
+// cycle puts the next candidate in the input, or the previous with back,
+// and returns the input's new text.
+func (t *typeahead) cycle(back bool) (string, bool) {
+	n := len(t.candidates)
+	if n == 0 {
+		return t.shown, false
+	}
+	switch {
+	case t.index < 0 && back:
+		t.index = n - 1
+	case back:
+		t.index = (t.index + n - 1) % n
+	default:
+		t.index = (t.index + 1) % n
+	}
+	t.shown = t.line[:t.start] + t.candidates[t.index] + " "
+	return t.shown, true
+}
+
+// visible returns the candidates the popup lists, scrolled to keep the
+// one in the input in view, and that one's position among them (-1 for
+// none).
+func (t *typeahead) visible() ([]string, int) {
+	first := max(0, t.index-maxTypeaheadShown+1)
+	last := min(len(t.candidates), first+maxTypeaheadShown)
+	selected := -1
+	if t.index >= 0 {
+		selected = t.index - first
+	}
+	return t.candidates[first:last], selected
+}
@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTypeahead(t *testing.T) {
+	calls := 0
+	complete := func(line string) (int, []string) {
+		calls++
+		if !strings.HasPrefix(line, "/w ") || strings.Contains(line[3:], " ") {
+			return 0, nil
+		}
+		return 3, []string{"Alice", "Albert", "Alfred"}
+	}
+
+	var ta typeahead
+	ta.update("/w al", complete)
+	if got, ok := ta.cycle(false); !ok || got != "/w Alice " {
+		t.Errorf("first Tab = %q, %v", got, ok)
+	}
+	ta.update("/w Alice ", complete) // The input echoing the completion
+	if got, _ := ta.cycle(false); got != "/w Albert " {
+		t.Errorf("second Tab = %q", got)
+	}
+	if got, _ := ta.cycle(true); got != "/w Alice " {
+		t.Errorf("Shift+Tab = %q", got)
+	}
+	if calls != 1 {
+		t.Errorf("completed %d times while cycling, want 1", calls)
+	}
+
+	// Typing starts over
+	ta.update("/w Alice h", complete)
+	if _, ok := ta.cycle(false); ok || len(ta.candidates) != 0 {
+		t.Errorf("Tab after typing on cycled to %q", ta.candidates)
+	}
+
+	ta.update("/w al", complete)
+	if got, _ := ta.cycle(true); got != "/w Alfred " {
+		t.Errorf("Shift+Tab first = %q, want the last candidate", got)
+	}
+}
+
+func TestTypeaheadVisible(t *testing.T) {
+	ta := typeahead{index: -1}
+	for i := range maxTypeaheadShown + 3 {
+		ta.candidates = append(ta.candidates, string(rune('a'+i)))
+	}
+	if shown, sel := ta.visible(); len(shown) != maxTypeaheadShown || shown[0] != "a" || sel != -1 {
+		t.Errorf("visible() = %q, %d before cycling", shown, sel)
+	}
+	ta.index = maxTypeaheadShown + 1
+	shown, sel := ta.visible()
+	if want := ta.candidates[2 : maxTypeaheadShown+2]; !reflect.DeepEqual(shown, want) || sel != maxTypeaheadShown-1 {
+		t.Errorf("visible() = %q, %d; want %q, %d", shown, sel, want, maxTypeaheadShown-1)
+	}
+}
@@ -3,12 +3,21 @@ package ui
 
 import (
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/AllenDang/cimgui-go/imgui"
 	"github.com/go-gl/gl/v4.1-core/gl"
 
+	"github.com/Faultbox/midgard-ro/internal/engine/camera"
+	"github.com/Faultbox/midgard-ro/internal/engine/clock"
+	"github.com/Faultbox/midgard-ro/internal/engine/pacing"
+	"github.com/Faultbox/midgard-ro/internal/engine/postfx"
 	"github.com/Faultbox/midgard-ro/internal/engine/ui2d"
+	"github.com/Faultbox/midgard-ro/internal/game/settings"
+	"github.com/Faultbox/midgard-ro/internal/network/packets"
 )
 
 // UI2DBackend implements UIBackend using the custom ui2d rendering system.
@@ -27,6 +36,51 @@ type UI2DBackend struct {
 	loginUsername string
 	loginPassword string
 	charSelectIdx int
+	pincode       PincodeEntry
+
+	// Experience bar animation
+	baseExp expFill
+	jobExp  expFill
+
+	chatInput     string
+	chatTypeahead typeahead
+
+	// Chat log: the newest line seen and its slide-in animation
+	chatCount int
+	chatLast  string
+	chatSlide ui2d.Tween
+
+	// Chat tabs: the tab pressed, where, and whether it became a drag; the
+	// tab whose settings are open (-1 for none) and its name being edited
+	chatTabPress    int
+	chatTabPressX   float32
+	chatTabDragging bool
+	chatTabMenu     int
+	chatTabName     string
+
+	// Inventory drag: the row pressed, where, and whether it became a drag
+	invPressIndex        int
+	invPressX, invPressY float32
+	invDragging          bool
+	invClick             doubleClick // Double-click to equip or take off
+
+	// Quantity dialog text and the prompt it was initialised for
+	qtyAmount string
+	qtyTitle  string
+
+	// Vending shop: the vendor the cart is for, amounts by item index, and
+	// the selected row and its quantity dialog
+	shopVendor uint32
+	shopCart   map[int]int
+	shopSel    int
+	shopPrompt *QuantityPrompt
+
+	// Dialog history rows scrolled up from the newest
+	historyScroll int
+
+	// Area and world map images and NPC illustrations by GRF path, nil if
+	// missing
+	images map[string]*TextureInfo
 }
 
 // NewUI2DBackend creates a new ui2d UI backend.
@@ -36,9 +90,13 @@ func NewUI2DBackend(width, height int) (*UI2DBackend, error) {
 		return nil, fmt.Errorf("create ui2d context: %w", err)
 	}
 
+	ctx.SetClipboard(imguiClipboard{})
 	return &UI2DBackend{
 		ctx:           ctx,
 		charSelectIdx: -1,
+		invPressIndex: -1,
+		chatTabPress:  -1,
+		chatTabMenu:   -1,
 	}, nil
 }
 
@@ -101,10 +159,29 @@ func (b *UI2DBackend) syncInputFromImGui() {
 	in.ScrollY = io.MouseWheel()
 
 	in.KeyBackspace = imgui.IsKeyDown(imgui.KeyBackspace)
+	in.KeyDelete = imgui.IsKeyDown(imgui.KeyDelete)
 	in.KeyEnter = imgui.IsKeyDown(imgui.KeyEnter)
 	in.KeyEscape = imgui.IsKeyDown(imgui.KeyEscape)
+	in.KeyUp = imgui.IsKeyDown(imgui.KeyUpArrow)
+	in.KeyDown = imgui.IsKeyDown(imgui.KeyDownArrow)
+	in.KeyLeft = imgui.IsKeyDown(imgui.KeyLeftArrow)
+	in.KeyRight = imgui.IsKeyDown(imgui.KeyRightArrow)
+	in.KeyHome = imgui.IsKeyDown(imgui.KeyHome)
+	in.KeyEnd = imgui.IsKeyDown(imgui.KeyEnd)
+	in.KeyShift = io.KeyShift()
+	in.KeyCtrl = io.KeyCtrl()
+	in.KeyAlt = io.KeyAlt()
 	in.KeyTab = imgui.IsKeyDown(imgui.KeyTab)
 
+	// Editing shortcuts. ImGui already maps Cmd to Ctrl on macOS.
+	if in.KeyCtrl {
+		in.KeySelectAll = imgui.IsKeyPressedBool(imgui.KeyA)
+		in.KeyCopy = imgui.IsKeyPressedBool(imgui.KeyC)
+		in.KeyPaste = imgui.IsKeyPressedBool(imgui.KeyV)
+		in.KeyCut = imgui.IsKeyPressedBool(imgui.KeyX)
+		in.KeyUndo = imgui.IsKeyPressedBool(imgui.KeyZ)
+	}
+
 	// Bridge ImGui's per-frame character input queue into ui2d's TextInput
 	// so users can type into our text fields. ImGui already translates
 	// SDL2 SDL_TEXTINPUT events into Wchars on its IO; we just consume the
@@ -120,12 +197,19 @@ func (b *UI2DBackend) syncInputFromImGui() {
 	}
 }
 
+// imguiClipboard is the system clipboard, reached through ImGui's SDL
+// platform backend.
+type imguiClipboard struct{}
+
+func (imguiClipboard) Text() string        { return imgui.ClipboardText() }
+func (imguiClipboard) SetText(text string) { imgui.SetClipboardText(text) }
+
 // syncViewportSize keeps the ui2d renderer matched to ImGui's viewport size,
 // so the UI scales correctly when the SDL window is resized.
 func (b *UI2DBackend) syncViewportSize() {
 	size := imgui.MainViewport().Size()
-	curW, curH := b.ctx.GetScreenSize()
-	if int(size.X) != int(curW) || int(size.Y) != int(curH) {
+	curW, curH := b.ctx.ViewportSize()
+	if int(size.X) != curW || int(size.Y) != curH {
 		b.ctx.Resize(int(size.X), int(size.Y))
 	}
 }
@@ -162,7 +246,38 @@ func (b *UI2DBackend) Resize(width, height int) {
 	b.ctx.Resize(width, height)
 }
 
-// GetScreenSize returns the current screen dimensions.
+// SetUIScale sets the UI scale factor.
+func (b *UI2DBackend) SetUIScale(scale float32) {
+	b.ctx.SetScale(scale)
+}
+
+// windowGrip is how much of a restored window's top-left corner is kept
+// on screen, so a layout saved at a higher resolution can still be
+// dragged back.
+const windowGrip = 40
+
+// WindowLayout returns where the player has dragged windows to.
+func (b *UI2DBackend) WindowLayout() settings.Windows {
+	layout := make(settings.Windows)
+	for id, pos := range b.ctx.MovedWindows() {
+		layout[id] = settings.WindowLayout{X: pos[0], Y: pos[1]}
+	}
+	return layout
+}
+
+// SetWindowLayout moves windows to a saved layout, keeping each title bar
+// on screen.
+func (b *UI2DBackend) SetWindowLayout(layout settings.Windows) {
+	b.ctx.ResetWindowPositions()
+	width, height := b.ctx.GetScreenSize()
+	for id, l := range layout {
+		x := min(max(l.X, 0), width-windowGrip)
+		y := min(max(l.Y, 0), height-windowGrip)
+		b.ctx.PlaceWindow(id, x, y)
+	}
+}
+
+// GetScreenSize returns the current screen dimensions in UI units.
 func (b *UI2DBackend) GetScreenSize() (width, height float32) {
 	return b.ctx.GetScreenSize()
 }
@@ -172,6 +287,12 @@ func (b *UI2DBackend) Input() *ui2d.InputState {
 	return b.ctx.Input()
 }
 
+// SetClipboard replaces the clipboard reached through ImGui, for hosts
+// that drive SDL themselves.
+func (b *UI2DBackend) SetClipboard(cb ui2d.Clipboard) {
+	b.ctx.SetClipboard(cb)
+}
+
 // DrawSceneTexture draws a 3D scene texture.
 func (b *UI2DBackend) DrawSceneTexture(x, y, w, h float32, textureID uint32) {
 	b.ctx.Renderer().DrawSceneTexture(x, y, w, h, textureID)
@@ -219,6 +340,9 @@ func (b *UI2DBackend) RenderLoginUI(state LoginUIState, width, height float32) {
 	// Center the login window
 	windowWidth := float32(400)
 	windowHeight := float32(340)
+	if state.OnCancel != nil {
+		windowHeight += 60
+	}
 	windowX := (width - windowWidth) / 2
 	windowY := (height - windowHeight) / 2
 
@@ -286,10 +410,21 @@ func (b *UI2DBackend) RenderLoginUI(state LoginUIState, width, height float32) {
 			}
 		}
 
-		if state.IsLoading {
+		switch {
+		case state.WaitMessage != "":
+			b.ctx.Spacer(8)
+			b.ctx.LabelCentered(state.WaitMessage)
+		case state.IsLoading:
 			b.ctx.Spacer(8)
 			b.ctx.LabelCentered("Connecting...")
 		}
+		if state.OnCancel != nil {
+			b.ctx.Spacer(4)
+			b.ctx.Row(28)
+			if b.ctx.Button("login-cancel", 0, "Cancel") {
+				state.OnCancel()
+			}
+		}
 
 		b.ctx.Spacer(12)
 		b.ctx.Separator()
@@ -326,6 +461,36 @@ func (b *UI2DBackend) RenderConnectingUI(state ConnectingUIState, width, height
 	}
 }
 
+// disconnectedWidth is the width of the disconnected error window.
+const disconnectedWidth = 360
+
+// RenderDisconnectedUI renders the error screen shown when the session ends.
+// Enter also returns to the login screen.
+func (b *UI2DBackend) RenderDisconnectedUI(state DisconnectedUIState, width, height float32) {
+	r := b.ctx.Renderer()
+	lines := wrapText(state.Message, disconnectedWidth-24, func(s string) float32 {
+		w, _ := r.MeasureText(s, 1)
+		return w
+	})
+
+	w, h := float32(disconnectedWidth), float32(25+16+len(lines)*18+16+28+8)
+	back := false
+	if b.ctx.BeginWindow("disconnected", (width-w)/2, (height-h)/2, w, h, state.Title) {
+		b.ctx.Spacer(16)
+		for _, line := range lines {
+			b.ctx.Row(18)
+			b.ctx.LabelCentered(line)
+		}
+		b.ctx.Spacer(16)
+		b.ctx.Row(28)
+		back = b.ctx.Button("return", 0, "Return to Login")
+		b.ctx.EndWindow()
+	}
+	if (back || b.ctx.Input().KeyEnterPressed) && state.OnReturn != nil {
+		state.OnReturn()
+	}
+}
+
 // RenderCharSelectUI renders the character selection screen.
 func (b *UI2DBackend) RenderCharSelectUI(state CharSelectUIState, width, height float32) {
 	windowWidth := float32(500)
@@ -406,10 +571,93 @@ func (b *UI2DBackend) RenderCharSelectUI(state CharSelectUIState, width, height
 					}
 				}
 			}
+
+			if state.OnChangePincode != nil {
+				label := "Set PIN Code"
+				if state.PincodeSet {
+					label = "Change PIN Code"
+				}
+				b.ctx.Row(28)
+				if state.Pincode != nil {
+					b.ctx.ButtonDisabled("pincode", 0, label)
+				} else if b.ctx.Button("pincode", 0, label) {
+					state.OnChangePincode()
+				}
+			}
 		}
 
 		b.ctx.EndWindow()
 	}
+
+	b.pincode.Sync(state.Pincode)
+	if state.Pincode != nil {
+		b.renderPincode(state.Pincode, width, height)
+	}
+}
+
+// renderPincode draws the PIN keypad over the character list.
+func (b *UI2DBackend) renderPincode(p *PincodeUIState, width, height float32) {
+	const button = 56
+	w, h := float32(3*button+28), float32(400)
+	if !b.ctx.BeginWindow("pincode", (width-w)/2, (height-h)/2, w, h, "PIN Code") {
+		return
+	}
+	b.ctx.Row(20)
+	b.ctx.LabelCentered(b.pincode.Prompt())
+	b.ctx.Row(20)
+	b.ctx.LabelCentered(b.pincode.Masked())
+	b.ctx.Spacer(4)
+
+	// Buttons 0-8 in a 3x3 grid, then Clear, button 9 and Back
+	for i := range 9 {
+		if i%3 == 0 {
+			b.ctx.Row(button)
+		} else {
+			b.ctx.SameLine()
+		}
+		if b.ctx.Button(fmt.Sprintf("pin_%d", i), button, fmt.Sprintf("%d", p.Keypad[i])) {
+			b.pincode.Press(p.Keypad[i])
+		}
+	}
+	b.ctx.Row(button)
+	if b.ctx.Button("pin_clear", button, "Clear") {
+		b.pincode.Clear()
+	}
+	b.ctx.SameLine()
+	if b.ctx.Button("pin_9", button, fmt.Sprintf("%d", p.Keypad[9])) {
+		b.pincode.Press(p.Keypad[9])
+	}
+	b.ctx.SameLine()
+	if b.ctx.Button("pin_back", button, "Back") {
+		b.pincode.Back()
+	}
+
+	b.ctx.Spacer(4)
+	b.ctx.Row(28)
+	half := float32(button*3+8) / 2
+	if !b.pincode.Ready() {
+		b.ctx.ButtonDisabled("pin_ok", half, "OK")
+	} else if b.ctx.Button("pin_ok", half, "OK") || b.ctx.Input().KeyEnterPressed {
+		if current, newPin, done := b.pincode.Confirm(); done && p.OnSubmit != nil {
+			p.OnSubmit(current, newPin)
+		}
+	}
+	if p.OnCancel != nil {
+		b.ctx.SameLine()
+		if b.ctx.Button("pin_cancel", half, "Cancel") {
+			p.OnCancel()
+		}
+	}
+
+	msg := b.pincode.Error()
+	if msg == "" {
+		msg = p.Message
+	}
+	if msg != "" {
+		b.ctx.Row(20)
+		b.ctx.LabelColored(msg, ui2d.Color{R: 1, G: 0.3, B: 0.3, A: 1})
+	}
+	b.ctx.EndWindow()
 }
 
 // RenderLoadingUI renders the loading screen.
@@ -446,14 +694,33 @@ func (b *UI2DBackend) RenderLoadingUI(state LoadingUIState, width, height float3
 
 // RenderInGameUI renders the in-game HUD.
 func (b *UI2DBackend) RenderInGameUI(state InGameUIState, dt float64, width, height float32) {
+	// Keys typed into a text field, e.g. Enter sending chat, aren't shortcuts
+	shortcuts := !b.ctx.TextInputFocused()
+
 	// Draw scene texture as background
 	if state.SceneReady && state.SceneTexture != 0 {
 		b.ctx.Renderer().DrawSceneTexture(0, 0, width, height, state.SceneTexture)
 	}
+	b.renderNameplates(state.Nameplates)
+	b.renderChatBubbles(state.ChatBubbles)
+	b.renderDamageNumbers(state.DamageNumbers)
+	if state.Cutin != nil {
+		b.renderCutin(state.Cutin, width, height)
+	}
+	if state.Banner != nil {
+		b.renderBanner(state.Banner, width)
+	}
+	b.renderBuffs(state.Buffs, width)
+	b.renderBasicInfo(state)
 
-	// Debug overlay (top-left)
+	// Debug overlay (top-left, under the basic info window)
 	if state.ShowDebugInfo {
-		if b.ctx.BeginWindow("debug", 10, 10, 320, 105, "Debug") {
+		missing := state.MissingSprites[:min(len(state.MissingSprites), debugMissingSprites)]
+		debugH := float32(233 + 16*len(state.AudioVoices) + 16*len(missing))
+		if len(missing) > 0 {
+			debugH += 16
+		}
+		if b.ctx.BeginWindow("debug", 10, basicInfoHeight+20, 340, debugH, "Debug") {
 			b.ctx.Row(16)
 			b.ctx.Label(fmt.Sprintf("Map: %s", state.MapName))
 			b.ctx.Row(16)
@@ -463,8 +730,51 @@ func (b *UI2DBackend) RenderInGameUI(state InGameUIState, dt float64, width, hei
 			b.ctx.Separator()
 			b.ctx.Row(16)
 			b.ctx.Label(fmt.Sprintf("Dir: %d  Entities: %d", state.PlayerDirection, state.EntityCount))
+			b.ctx.Row(16)
+			b.ctx.Label(fmt.Sprintf("F4 PiP: %s", state.PiPMode))
+			b.ctx.Row(16)
+			b.ctx.Label(fmt.Sprintf("Anim: %d full, %d reduced, %d frozen, %d composited",
+				state.AnimFull, state.AnimReduced, state.AnimFrozen, state.AnimComposited))
+			b.ctx.Row(16)
+			b.ctx.Label(fmt.Sprintf("Net: %d writes, %d coalesced, %d deferred",
+				state.SendWrites, state.PacketsCoalesced, state.PacketsDeferred))
+			b.ctx.Row(16)
+			b.ctx.Label(fmt.Sprintf("Input: %d waiting, %d fired, %d buffered, %d dropped",
+				state.InputWaiting, state.InputFired, state.InputBuffered, state.InputDropped))
+			b.ctx.Row(16)
+			b.ctx.Label(fmt.Sprintf("Inbound: %d (peak %d), stalls %d read / %d write",
+				state.InboundQueued, state.InboundPeak, state.ReadStalls, state.WriteStalls))
+			b.ctx.Row(16)
+			framing := fmt.Sprintf("Unknown: %d (last 0x%04X), resyncs %d (%dB dropped)",
+				state.UnknownPackets, state.LastUnknownID, state.Resyncs, state.DroppedBytes)
+			if state.VersionMismatch {
+				b.ctx.LabelColored(framing+" - packetver mismatch?", ui2d.Color{R: 0.8, G: 0.15, B: 0.15, A: 1})
+			} else {
+				b.ctx.Label(framing)
+			}
+			b.ctx.Row(16)
+			b.ctx.Label(fmt.Sprintf("Audio: %s  Voices: %d", state.AudioEnvironment, len(state.AudioVoices)))
+			for _, v := range state.AudioVoices {
+				b.ctx.Row(16)
+				b.ctx.Label("  " + v)
+			}
+			if len(missing) > 0 {
+				b.ctx.Row(16)
+				b.ctx.Label(fmt.Sprintf("Missing sprites: %d", len(state.MissingSprites)))
+				for _, m := range missing {
+					b.ctx.Row(16)
+					b.ctx.Label("  " + m)
+				}
+			}
 			b.ctx.EndWindow()
 		}
+
+		// Picture-in-picture debug camera (top-right)
+		if state.PiPTexture != 0 {
+			pipW, pipH := float32(320), float32(240)
+			b.ctx.Renderer().DrawRect(width-pipW-22, 8, pipW+4, pipH+4, ui2d.ColorPanelBorder)
+			b.ctx.Renderer().DrawSceneTexture(width-pipW-20, 10, pipW, pipH, state.PiPTexture)
+		}
 	}
 
 	// Error overlay
@@ -481,6 +791,15 @@ func (b *UI2DBackend) RenderInGameUI(state InGameUIState, dt float64, width, hei
 		}
 	}
 
+	// Chat input sits above the experience bars, the log above it
+	chatBottom := height - 50
+	if state.OnChatSubmit != nil {
+		chatBottom -= ui2dChatInputHeight
+		b.renderChatInput(state, chatBottom+4)
+	}
+	b.renderChatLog(state.ChatMessages, dt, chatBottom)
+	b.renderExpBars(state, dt, width, height)
+
 	// Bottom status bar (drawn as simple text, not a window)
 	statusText := state.MapName
 	if state.StatusMessage != "" {
@@ -490,36 +809,1618 @@ func (b *UI2DBackend) RenderInGameUI(state InGameUIState, dt float64, width, hei
 	barY := height - 25
 	b.ctx.Renderer().DrawRect(0, barY, width, 25, ui2d.ColorPanelBg)
 	b.ctx.Renderer().DrawText(10, barY+4, statusText, scale, ui2d.ColorTextOnDark)
+	if pvpText := state.PVPText(); pvpText != "" && state.StatusMessage == "" {
+		statusW, _ := b.ctx.Renderer().MeasureText(statusText, scale)
+		b.ctx.Renderer().DrawText(statusW+30, barY+4, pvpText, scale, pvpColor)
+	}
 
 	posText := fmt.Sprintf("(%d, %d)", state.PlayerTileX, state.PlayerTileY)
 	posW, _ := b.ctx.Renderer().MeasureText(posText, scale)
 	b.ctx.Renderer().DrawText(width-posW-10, barY+4, posText, scale, ui2d.ColorTextOnDark)
+
+	if connText := state.ConnectionText(); connText != "" {
+		connColor := ui2d.ColorTextOnDark
+		if state.ServerLagging {
+			connColor = ui2d.Color{R: 1, G: 0.3, B: 0.3, A: 1}
+		}
+		connW, _ := b.ctx.Renderer().MeasureText(connText, scale)
+		b.ctx.Renderer().DrawText(width-posW-connW-30, barY+4, connText, scale, connColor)
+	}
+
+	// The area map covers the HUD, but not dialogs that need an answer
+	if state.AreaMap != nil {
+		b.renderAreaMap(state.AreaMap, width, height)
+	}
+
+	if state.ShowInventory {
+		b.renderInventory(state, width)
+	}
+	if state.ShowEquipment {
+		b.renderEquipment(state.Inventory, state.OnUnequip, width)
+	}
+	if state.VendingShop != nil {
+		b.renderVendingShop(state.VendingShop, width, height)
+	} else {
+		b.shopCart = nil
+	}
+	if state.DropPrompt != nil {
+		b.renderQuantityPrompt(state.DropPrompt, width, height)
+	}
+	if state.NPCDialog != nil {
+		b.renderNPCDialog(state.NPCDialog, width, height)
+	}
+	if state.DialogHistory != nil {
+		b.renderDialogHistory(state.DialogHistory, width, height)
+	} else {
+		b.historyScroll = 0
+	}
+	if state.RequestDialog != nil {
+		b.renderRequestDialog(state.RequestDialog, width, height, shortcuts)
+	}
+
+	// Right-click menu above the HUD
+	if state.ContextMenu != nil {
+		b.renderContextMenu(state.ContextMenu, width, height)
+	}
+
+	// Resolve an inventory drag once every window has been drawn, so a
+	// release over any of them doesn't count as a drop on the ground
+	b.finishInventoryDrag(state.ShowInventory, state.OnItemDrop)
+
+	if state.AttackCursor {
+		b.renderAttackCursor()
+	}
 }
 
-// RenderFPSOverlay renders an FPS counter.
-func (b *UI2DBackend) RenderFPSOverlay(fps float64, width, height float32) {
-	scale := float32(1.0)
-	text := fmt.Sprintf("FPS: %.0f", fps)
-	textW, _ := b.ctx.Renderer().MeasureText(text, scale)
+// renderAttackCursor draws a crosshair at the mouse over an attackable unit.
+func (b *UI2DBackend) renderAttackCursor() {
+	in := b.ctx.Input()
+	x, y := in.MouseX, in.MouseY
+	r := b.ctx.Renderer()
+	const arm, gap, thick = 8, 3, 2
+	r.DrawRect(x-gap-arm, y-thick/2, arm, thick, pvpColor)
+	r.DrawRect(x+gap, y-thick/2, arm, thick, pvpColor)
+	r.DrawRect(x-thick/2, y-gap-arm, thick, arm, pvpColor)
+	r.DrawRect(x-thick/2, y+gap, thick, arm, pvpColor)
+}
 
-	x := width - textW - 10
-	y := float32(5)
+// renderExpBars draws the base and job experience bars above the status bar.
+func (b *UI2DBackend) renderExpBars(state InGameUIState, dt float64, width, height float32) {
+	baseRatio := b.baseExp.update(state.BaseExpRatio, state.PlayerLevel, dt)
+	jobRatio := b.jobExp.update(state.JobExpRatio, state.PlayerJobLevel, dt)
 
-	// Semi-transparent background
-	b.ctx.Renderer().DrawRect(x-5, y-2, textW+10, 20, ui2d.ColorPanelBg.WithAlpha(0.5))
-	b.ctx.Renderer().DrawText(x, y, text, scale, ui2d.ColorTextOnDark)
+	r := b.ctx.Renderer()
+	barY := height - 25 - 20
+	r.DrawRect(0, barY, width, 20, ui2d.ColorPanelBg)
+
+	half := (width - 30) / 2
+	b.drawExpBar(10, barY+3, half, fmt.Sprintf("Base Lv. %d", state.PlayerLevel), baseRatio, state.BaseExpRatio,
+		ui2d.Color{R: 0.3, G: 0.6, B: 1.0, A: 1})
+	b.drawExpBar(20+half, barY+3, half, fmt.Sprintf("Job Lv. %d", state.PlayerJobLevel), jobRatio, state.JobExpRatio,
+		ui2d.Color{R: 0.9, G: 0.6, B: 0.2, A: 1})
 }
 
-// RenderScreenshotMessage renders a screenshot notification.
-func (b *UI2DBackend) RenderScreenshotMessage(msg string, width, height float32) {
-	scale := float32(1.0)
-	textW, textH := b.ctx.Renderer().MeasureText(msg, scale)
+// renderBasicInfo draws the basic info window: the weight carried, red
+// when overweight, and the zeny.
+func (b *UI2DBackend) renderBasicInfo(state InGameUIState) {
+	if !b.ctx.BeginWindow("basic_info", 10, 10, basicInfoWidth, basicInfoHeight, "Basic Info") {
+		return
+	}
+	b.ctx.Row(16)
+	b.ctx.LabelColored(state.Weight.String(), weightColor(state.Weight))
+	b.ctx.Row(8)
+	b.ctx.ProgressBar(state.Weight.Ratio(), 0, 8, "")
+	b.ctx.Row(16)
+	b.ctx.Label(zenyText(state.Zeny))
+	b.ctx.EndWindow()
+}
+
+func (b *UI2DBackend) drawExpBar(x, y, w float32, label string, shown, actual float32, color ui2d.Color) {
+	r := b.ctx.Renderer()
+	labelW, _ := r.MeasureText(label, 1)
+	r.DrawText(x, y, label, 1, ui2d.ColorTextOnDark)
+
+	barX := x + labelW + 6
+	barW := w - labelW - 6
+	r.DrawRect(barX, y, barW, 14, ui2d.Color{R: 0.15, G: 0.15, B: 0.2, A: 1})
+	r.DrawRect(barX, y, barW*shown, 14, color)
+	r.DrawRectOutline(barX, y, barW, 14, 1, ui2d.ColorPanelBorder)
+
+	pct := fmt.Sprintf("%.1f%%", actual*100)
+	pctW, _ := r.MeasureText(pct, 1)
+	r.DrawText(barX+(barW-pctW)/2, y, pct, 1, ui2d.ColorTextOnDark)
+
+	b.ctx.TooltipRegion("exp_"+label, barX, y, barW, 14, func() *ui2d.Tooltip {
+		return ui2d.NewTooltip().
+			Title(label).
+			Text("Experience: ", ui2d.ColorTextOnDark).
+			Append(pct, color).
+			Text(fmt.Sprintf("%.1f%% to next level", (1-actual)*100), ui2d.ColorTextOnDark)
+	})
+}
 
-	msgWidth := textW + 20
-	x := (width - msgWidth) / 2
-	y := height - 60
+// renderChatLog draws the most recent chat messages, ending at bottom.
+// Right-clicking a line copies it.
+func (b *UI2DBackend) renderChatLog(messages []ChatLine, dt float64, bottom float32) {
+	const visibleLines = 8
+	const slideDistance = 24
+	if len(messages) == 0 {
+		return
+	}
 
-	// Semi-transparent background
-	b.ctx.Renderer().DrawRect(x, y, msgWidth, textH+10, ui2d.ColorPanelBg.WithAlpha(0.8))
-	b.ctx.Renderer().DrawText(x+10, y+5, msg, scale, ui2d.Color{R: 0.2, G: 1.0, B: 0.2, A: 1.0})
+	// A new line slides in from the left
+	last := messages[len(messages)-1].Text
+	if len(messages) != b.chatCount || last != b.chatLast {
+		b.chatCount, b.chatLast = len(messages), last
+		b.chatSlide = ui2d.NewTween(0, 1, chatSlideDuration, ui2d.EaseOutCubic)
+	} else {
+		b.chatSlide.Update(time.Duration(dt * float64(time.Second)))
+	}
+
+	if len(messages) > visibleLines {
+		messages = messages[len(messages)-visibleLines:]
+	}
+
+	r := b.ctx.Renderer()
+	input := b.ctx.Input()
+	lineH := float32(18)
+	y := bottom - float32(len(messages))*lineH
+	r.DrawRect(10, y-2, 400, float32(len(messages))*lineH+4, ui2d.Color{R: 0, G: 0, B: 0, A: 0.4})
+	for i, msg := range messages {
+		line := ui2d.Rect{X: 10, Y: y, W: 400, H: lineH}
+		if input.MouseRightPressed && line.Contains(input.MouseX, input.MouseY) {
+			b.ctx.CopyText(msg.Text)
+			b.ctx.Toast("Chat line copied", ui2d.ColorTextOnDark)
+		}
+		if i == len(messages)-1 && !b.chatSlide.Done() {
+			v := b.chatSlide.Value()
+			r.PushEffect(ui2d.Effect{Scale: 1, OffsetX: (v - 1) * slideDistance, Alpha: v})
+			r.DrawText(14, y, msg.Text, 1, msg.Color)
+			r.PopEffect()
+			break
+		}
+		r.DrawText(14, y, msg.Text, 1, msg.Color)
+		y += lineH
+	}
+}
+
+// chatSlideDuration is how long a new chat line takes to slide in.
+const chatSlideDuration = 250 * time.Millisecond
+
+// ui2dChatInputHeight is the height of the chat input window, with the
+// tab bar along its bottom.
+const ui2dChatInputHeight = 70 + chatTabHeight + 4
+
+// renderChatInput draws the chat entry window at y, with the chat tabs
+// under the input. A non-empty draft replaces the text and focuses the
+// input.
+func (b *UI2DBackend) renderChatInput(state InGameUIState, y float32) {
+	if !b.ctx.BeginWindow("chat", 10, y, 400, ui2dChatInputHeight-4, "Chat") {
+		b.chatTabPress, b.chatTabMenu = -1, -1
+		return
+	}
+	b.ctx.Row(28)
+	if state.ChatDraft != "" {
+		b.chatInput = state.ChatDraft
+		b.ctx.SetKeyboardFocus("input")
+	}
+	value, changed, submitted := b.ctx.TextInput("input", 0, b.chatInput)
+	if changed {
+		b.chatInput = value
+	}
+	focused := b.ctx.HasKeyboardFocus("input")
+	b.chatTypeahead.update(b.chatInput, state.OnChatComplete)
+	if in := b.ctx.Input(); focused && in.KeyTabPressed {
+		if text, ok := b.chatTypeahead.cycle(in.KeyShift); ok {
+			b.chatInput = text
+		}
+	}
+	if submitted {
+		line := strings.TrimSpace(b.chatInput)
+		b.chatInput = ""
+		if line != "" {
+			state.OnChatSubmit(line)
+		}
+	}
+	rect := b.ctx.WindowRect()
+	b.renderChatTabs(state.ChatTabs, state.ActiveChatTab, state.ChatTabActions, rect.X+8, rect.Y+rect.H-chatTabHeight-6)
+	b.ctx.EndWindow()
+
+	if focused && !submitted {
+		b.renderTypeahead(rect.X+8, rect.Y)
+	}
+
+	if b.chatTabMenu >= 0 {
+		b.renderChatTabMenu(state.ChatTabs, state.ChatTabActions, rect.X+rect.W+6, rect.Y)
+	}
+}
+
+// Typeahead popup layout, in UI units.
+const (
+	typeaheadLineHeight = 18
+	typeaheadPadding    = 4
+)
+
+// renderTypeahead lists the chat input's completions in a box whose
+// bottom-left corner is at x, y, the one Tab put in the input highlighted.
+func (b *UI2DBackend) renderTypeahead(x, y float32) {
+	shown, selected := b.chatTypeahead.visible()
+	if len(shown) == 0 {
+		return
+	}
+	r := b.ctx.Renderer()
+	w := float32(0)
+	for _, c := range shown {
+		cw, _ := r.MeasureText(c, 1)
+		w = max(w, cw)
+	}
+	w += 2 * typeaheadPadding
+	h := float32(len(shown))*typeaheadLineHeight + 2*typeaheadPadding
+	y -= h
+	r.DrawPanel(x, y, w, h, ui2d.ColorTooltipBg, ui2d.ColorTooltipBorder)
+	for i, c := range shown {
+		lineY := y + typeaheadPadding + float32(i)*typeaheadLineHeight
+		if i == selected {
+			r.DrawRect(x+1, lineY, w-2, typeaheadLineHeight, ui2d.ColorHighlight.WithAlpha(0.6))
+		}
+		r.DrawText(x+typeaheadPadding, lineY+2, c, 1, ui2d.ColorTextOnDark)
+	}
+}
+
+// Chat tab bar layout, in UI units.
+const (
+	chatTabHeight     = 20
+	chatTabPadding    = 6 // Either side of a tab's label
+	chatTabDragPixels = 6 // Movement before a press on a tab becomes a drag
+)
+
+// chatTabLabel returns a tab's label, with its unread count.
+func chatTabLabel(tab ChatTab) string {
+	if tab.Unread > 0 {
+		return fmt.Sprintf("%s (%d)", tab.Name, tab.Unread)
+	}
+	return tab.Name
+}
+
+// renderChatTabs draws the chat tab bar from x, y. Clicking a tab shows
+// it, dragging one along the bar moves it, and right-clicking opens its
+// settings.
+func (b *UI2DBackend) renderChatTabs(tabs []ChatTab, active int, actions ChatTabActions, x, y float32) {
+	if len(tabs) == 0 {
+		return
+	}
+	r := b.ctx.Renderer()
+	input := b.ctx.Input()
+
+	rects := make([]ui2d.Rect, len(tabs))
+	hover := -1
+	for i, tab := range tabs {
+		w, _ := r.MeasureText(chatTabLabel(tab), 1)
+		rects[i] = ui2d.Rect{X: x, Y: y, W: w + 2*chatTabPadding, H: chatTabHeight}
+		if rects[i].Contains(input.MouseX, input.MouseY) {
+			hover = i
+		}
+		x += rects[i].W + 2
+	}
+	add := ui2d.Rect{X: x, Y: y, W: chatTabHeight, H: chatTabHeight}
+
+	switch {
+	case input.MouseLeftPressed && hover >= 0:
+		b.chatTabPress, b.chatTabPressX, b.chatTabDragging = hover, input.MouseX, false
+	case input.MouseLeftPressed && actions.Add != nil && add.Contains(input.MouseX, input.MouseY):
+		actions.Add()
+	case input.MouseRightPressed && hover >= 0:
+		b.chatTabMenu, b.chatTabName = hover, tabs[hover].Name
+	}
+
+	// Where a dragged tab would land: before the first tab whose middle
+	// is right of the mouse
+	target := -1
+	if b.chatTabPress >= len(tabs) {
+		b.chatTabPress = -1
+	}
+	if b.chatTabPress >= 0 {
+		if dx := input.MouseX - b.chatTabPressX; dx*dx >= chatTabDragPixels*chatTabDragPixels {
+			b.chatTabDragging = true
+		}
+		if b.chatTabDragging {
+			target = len(tabs) - 1
+			for i, rect := range rects {
+				if input.MouseX < rect.X+rect.W/2 {
+					target = i
+					if i > b.chatTabPress {
+						target--
+					}
+					break
+				}
+			}
+		}
+		if input.MouseLeftReleased {
+			switch {
+			case b.chatTabDragging && target != b.chatTabPress && actions.Move != nil:
+				actions.Move(b.chatTabPress, target)
+			case !b.chatTabDragging && hover == b.chatTabPress && actions.Select != nil:
+				actions.Select(hover)
+			}
+			b.chatTabPress, b.chatTabDragging, target = -1, false, -1
+		}
+	}
+
+	for i, tab := range tabs {
+		rect := rects[i]
+		bg := ui2d.ColorButtonNormal
+		switch {
+		case i == active:
+			bg = ui2d.ColorButtonActive
+		case i == hover:
+			bg = ui2d.ColorButtonHover
+		}
+		if b.chatTabDragging && i == b.chatTabPress {
+			bg.A = 0.5
+		}
+		r.DrawRect(rect.X, rect.Y, rect.W, rect.H, bg)
+		color := ui2d.ColorText
+		if tab.Unread > 0 && i != active {
+			color = ui2d.ColorHighlight
+		}
+		_, textH := r.MeasureText(tab.Name, 1)
+		r.DrawText(rect.X+chatTabPadding, rect.Y+(rect.H-textH)/2, chatTabLabel(tab), 1, color)
+	}
+	if actions.Add != nil {
+		bg := ui2d.ColorButtonNormal
+		if add.Contains(input.MouseX, input.MouseY) {
+			bg = ui2d.ColorButtonHover
+		}
+		r.DrawRect(add.X, add.Y, add.W, add.H, bg)
+		w, h := r.MeasureText("+", 1)
+		r.DrawText(add.X+(add.W-w)/2, add.Y+(add.H-h)/2, "+", 1, ui2d.ColorText)
+	}
+
+	// The insertion mark shows where a dragged tab lands
+	if target >= 0 && target != b.chatTabPress {
+		markX := rects[target].X - 2
+		if target > b.chatTabPress {
+			markX = rects[target].X + rects[target].W
+		}
+		r.DrawRect(markX, y-2, 2, chatTabHeight+4, ui2d.ColorHighlight)
+	}
+}
+
+// renderChatTabMenu draws the open chat tab's settings at x, y: its name,
+// the channels it shows, and a button to remove it.
+func (b *UI2DBackend) renderChatTabMenu(tabs []ChatTab, actions ChatTabActions, x, y float32) {
+	i := b.chatTabMenu
+	if i >= len(tabs) {
+		b.chatTabMenu = -1
+		return
+	}
+	tab := tabs[i]
+	h := float32(25 + 8 + 32 + len(tab.Channels)*24 + 32 + 8)
+	if !b.ctx.BeginWindow("chat_tab", x, y, 200, h, "Tab settings") {
+		return
+	}
+
+	b.ctx.Row(28)
+	value, changed, submitted := b.ctx.TextInput("name", 0, b.chatTabName)
+	if changed {
+		b.chatTabName = value
+	}
+	if submitted && actions.Rename != nil {
+		if name := strings.TrimSpace(b.chatTabName); name != "" {
+			actions.Rename(i, name)
+		}
+	}
+	for ch, toggle := range tab.Channels {
+		b.ctx.Row(20)
+		if on := b.ctx.Checkbox(fmt.Sprintf("channel_%d", ch), toggle.Label, toggle.On); on != toggle.On && actions.Toggle != nil {
+			actions.Toggle(i, ch)
+		}
+	}
+
+	b.ctx.Row(28)
+	closed := b.ctx.Button("close", 88, "Close") || b.ctx.Input().KeyEscapePressed
+	b.ctx.SameLine()
+	if actions.Remove != nil && len(tabs) > 1 {
+		if b.ctx.Button("remove", 88, "Remove") {
+			actions.Remove(i)
+			closed = true
+		}
+	} else {
+		b.ctx.ButtonDisabled("remove", 88, "Remove")
+	}
+	b.ctx.EndWindow()
+
+	if closed {
+		// Keep a typed name that wasn't confirmed with Enter
+		if name := strings.TrimSpace(b.chatTabName); name != "" && name != tab.Name && actions.Rename != nil {
+			actions.Rename(i, name)
+		}
+		b.chatTabMenu = -1
+	}
+}
+
+// Inventory window layout, in UI units.
+const (
+	inventoryWidth       = 260
+	inventoryRowH        = 24
+	inventoryVisibleRows = 10
+	inventoryDragPixels  = 4 // Movement before a press on a row becomes a drag
+)
+
+// renderInventory draws the inventory window. Pressing a row and dragging
+// it off every window drops the item (see finishInventoryDrag), and
+// double-clicking equipment puts it on or takes it off.
+func (b *UI2DBackend) renderInventory(state InGameUIState, width float32) {
+	items := state.Inventory
+	rows := max(1, min(len(items), inventoryVisibleRows))
+	listH := float32(rows*inventoryRowH + 8)
+	h := 25 + 8 + listH + 8
+	if len(items) > inventoryVisibleRows {
+		h += 20
+	}
+	if !b.ctx.BeginWindow("inventory", width-inventoryWidth-10, 60, inventoryWidth, h, "Inventory (Alt+E)") {
+		return
+	}
+
+	if len(items) == 0 {
+		b.ctx.Row(inventoryRowH)
+		b.ctx.LabelColored("No items", ui2d.ColorTextDim)
+		b.ctx.EndWindow()
+		return
+	}
+
+	input := b.ctx.Input()
+	b.ctx.BeginListBox("items", 0, listH)
+	for i, item := range items {
+		if i == inventoryVisibleRows {
+			break
+		}
+		label := fmt.Sprintf("%s  x%d", item.Label(), item.Amount)
+		if !item.Droppable {
+			label = item.Label() + "  (equipped)"
+		} else if item.Location != 0 {
+			label = item.Label()
+		}
+		dragged := b.invDragging && b.invPressIndex == item.Index
+		color, _ := ItemColor(item.Grade)
+		clicked := b.ctx.SelectableColored(fmt.Sprintf("item_%d", item.Index), label, dragged, color)
+		b.ctx.ItemTooltip(func() *ui2d.Tooltip { return equipTooltip(item, items) })
+		if !clicked {
+			continue
+		}
+		if b.invClick.click(item.Index, clock.Now()) {
+			toggleEquip(item, state.OnEquip, state.OnUnequip)
+			b.invPressIndex = -1
+		} else if item.Droppable {
+			b.invPressIndex = item.Index
+			b.invPressX, b.invPressY = input.MouseX, input.MouseY
+			b.invDragging = false
+		}
+	}
+	b.ctx.EndListBox()
+	if len(items) > inventoryVisibleRows {
+		b.ctx.Row(16)
+		b.ctx.LabelColored(fmt.Sprintf("+%d more", len(items)-inventoryVisibleRows), ui2d.ColorTextDim)
+	}
+	b.ctx.EndWindow()
+
+	if b.invPressIndex < 0 || !input.MouseLeftDown {
+		return
+	}
+	dx, dy := input.MouseX-b.invPressX, input.MouseY-b.invPressY
+	if dx*dx+dy*dy >= inventoryDragPixels*inventoryDragPixels {
+		b.invDragging = true
+	}
+	if b.invDragging {
+		for _, item := range items {
+			if item.Index == b.invPressIndex {
+				b.ctx.Renderer().DrawText(input.MouseX+12, input.MouseY+12, item.Name, 1, ui2d.ColorTextOnDark)
+				break
+			}
+		}
+	}
+}
+
+// equipTooltip builds the comparison tooltip for an equipment item, nil
+// for other items.
+func equipTooltip(item InventoryItem, items []InventoryItem) *ui2d.Tooltip {
+	sections := EquipTooltip(item, items)
+	if sections == nil {
+		return nil
+	}
+	t := ui2d.NewTooltip()
+	for i, sec := range sections {
+		if i > 0 {
+			t.Text("", ui2d.ColorTextOnDark)
+		}
+		if color, ok := ItemColor(sec.Grade); ok {
+			t.Text(sec.Title, color.Lighten(0.4))
+		} else {
+			t.Title(sec.Title)
+		}
+		for _, line := range sec.Lines {
+			t.Text(line, ui2d.ColorTextOnDark)
+		}
+	}
+	return t
+}
+
+// Equipment window layout, in UI units.
+const (
+	equipWidth   = 420
+	equipSlotW   = 150
+	equipSlotH   = 36
+	equipSlotGap = 6
+	equipTitleH  = 25
+)
+
+// renderEquipment draws the equipment window: the worn items either side
+// of a character silhouette, with ammunition below. Double-clicking a slot
+// takes its item off.
+func (b *UI2DBackend) renderEquipment(items []InventoryItem, onUnequip func(int), width float32) {
+	rows := len(DollLeft)
+	h := float32(equipTitleH + 8 + (rows+1)*(equipSlotH+equipSlotGap) + 8)
+	if !b.ctx.BeginWindow("equipment", width-inventoryWidth-equipWidth-20, 60, equipWidth, h, "Equipment (Alt+Q)") {
+		return
+	}
+	win := b.ctx.WindowRect()
+	top := win.Y + equipTitleH + 8
+	left, right := win.X+8, win.X+win.W-8-equipSlotW
+	for i := range rows {
+		y := top + float32(i*(equipSlotH+equipSlotGap))
+		b.drawDollSlot(DollLeft[i], items, onUnequip, left, y)
+		b.drawDollSlot(DollRight[i], items, onUnequip, right, y)
+	}
+	b.drawDollSlot(DollAmmo, items, onUnequip, left, top+float32(rows*(equipSlotH+equipSlotGap)))
+
+	// The silhouette between the columns
+	r := b.ctx.Renderer()
+	col := ui2d.Color{R: 0.45, G: 0.45, B: 0.5, A: 0.5}
+	cx := win.X + win.W/2
+	bodyH := float32(rows*(equipSlotH+equipSlotGap)) - equipSlotGap
+	r.DrawRect(cx-12, top+4, 24, 24, col)
+	r.DrawRect(cx-22, top+32, 44, bodyH*0.5, col)
+	r.DrawRect(cx-20, top+36+bodyH*0.5, 18, bodyH*0.5-40, col)
+	r.DrawRect(cx+2, top+36+bodyH*0.5, 18, bodyH*0.5-40, col)
+	b.ctx.EndWindow()
+}
+
+// drawDollSlot draws one equipment slot at x, y: its name and the item
+// worn there.
+func (b *UI2DBackend) drawDollSlot(slot DollSlot, items []InventoryItem, onUnequip func(int), x, y float32) {
+	r := b.ctx.Renderer()
+	input := b.ctx.Input()
+	rect := ui2d.Rect{X: x, Y: y, W: equipSlotW, H: equipSlotH}
+	item, worn := WornIn(items, slot.Location)
+	if worn && rect.Contains(input.MouseX, input.MouseY) {
+		r.DrawRect(x, y, equipSlotW, equipSlotH, ui2d.ColorButtonHover)
+	}
+	r.DrawRectOutline(x, y, equipSlotW, equipSlotH, 1, ui2d.ColorPanelBorder)
+	r.DrawText(x+4, y+2, slot.Label, 1, ui2d.ColorTextDim)
+	if !worn {
+		r.DrawText(x+4, y+18, "-", 1, ui2d.ColorTextDim)
+		return
+	}
+	r.DrawText(x+4, y+18, item.Label(), 1, ui2d.ColorText)
+
+	id := fmt.Sprintf("equip_%d", slot.Location)
+	b.ctx.TooltipRegion(id, x, y, equipSlotW, equipSlotH, func() *ui2d.Tooltip { return equipTooltip(item, items) })
+	if input.MouseLeftPressed && rect.Contains(input.MouseX, input.MouseY) &&
+		b.invClick.click(-1-int(slot.Location), clock.Now()) && onUnequip != nil {
+		onUnequip(item.Index)
+	}
+}
+
+// finishInventoryDrag drops the dragged item when the mouse is released
+// outside every window.
+func (b *UI2DBackend) finishInventoryDrag(open bool, onDrop func(int)) {
+	if !open {
+		b.invPressIndex = -1
+		b.invDragging = false
+		return
+	}
+	if b.invPressIndex < 0 || !b.ctx.Input().MouseLeftReleased {
+		return
+	}
+	if b.invDragging && !b.ctx.MouseOverWindow() && onDrop != nil {
+		onDrop(b.invPressIndex)
+	}
+	b.invPressIndex = -1
+	b.invDragging = false
+}
+
+// renderQuantityPrompt draws the centred "how many?" dialog: a slider and
+// text field over the accepted range, a Max button, and Up/Down (ten at a
+// time with Shift) stepping the amount.
+func (b *UI2DBackend) renderQuantityPrompt(prompt *QuantityPrompt, width, height float32) {
+	if b.qtyTitle != prompt.Title {
+		b.qtyTitle = prompt.Title
+		b.qtyAmount = strconv.Itoa(prompt.Start())
+		b.ctx.SetKeyboardFocus("amount")
+	}
+	limit, reason := prompt.Limit()
+	allowed := prompt.Allowed()
+	limit = max(limit, prompt.Min)
+	amount, err := strconv.Atoi(strings.TrimSpace(b.qtyAmount))
+	valid := err == nil
+	if !valid {
+		amount = prompt.Min
+	}
+
+	w, h := float32(260), float32(25+8+20+4+20+4+28+4+28+8)
+	if reason != "" {
+		h += 20 + 4
+	}
+	if !b.ctx.BeginWindow("quantity_prompt", (width-w)/2, (height-h)/2, w, h, prompt.Title) {
+		return
+	}
+	b.ctx.Row(20)
+	b.ctx.Label(prompt.Hint(prompt.Clamp(amount)))
+	if reason != "" {
+		b.ctx.Row(20)
+		b.ctx.LabelColored(reason, ui2d.Color{R: 1, G: 0.3, B: 0.3, A: 1})
+	}
+	b.ctx.Row(20)
+	if v, changed := b.ctx.Slider("slider", 0, prompt.Clamp(amount), prompt.Min, limit); changed {
+		b.qtyAmount = strconv.Itoa(v)
+	}
+	b.ctx.Row(28)
+	value, changed, submitted := b.ctx.TextInput("amount", w-16-64, b.qtyAmount)
+	if changed {
+		b.qtyAmount = value
+	}
+	b.ctx.SameLine()
+	if b.ctx.Button("max", 60, "Max") {
+		b.qtyAmount = strconv.Itoa(limit)
+	}
+	b.ctx.Row(28)
+	var confirm bool
+	if allowed {
+		confirm = b.ctx.Button("ok", 100, "OK") || submitted
+	} else {
+		b.ctx.ButtonDisabled("ok", 100, "OK")
+	}
+	b.ctx.SameLine()
+	cancel := b.ctx.Button("cancel", 100, "Cancel") || b.ctx.Input().KeyEscapePressed
+	b.ctx.EndWindow()
+
+	input := b.ctx.Input()
+	switch {
+	case input.KeyUpPressed:
+		b.qtyAmount = strconv.Itoa(prompt.Step(amount, 1, input.KeyShift))
+	case input.KeyDownPressed:
+		b.qtyAmount = strconv.Itoa(prompt.Step(amount, -1, input.KeyShift))
+	}
+
+	switch {
+	case confirm:
+		if !valid {
+			return // Keep the dialog open until the amount is a number
+		}
+		b.qtyTitle = ""
+		if prompt.OnConfirm != nil {
+			prompt.OnConfirm(prompt.Clamp(amount))
+		}
+	case cancel:
+		b.qtyTitle = ""
+		if prompt.OnCancel != nil {
+			prompt.OnCancel()
+		}
+	}
+}
+
+// requestDialogWidth is the width of a request dialog in UI units.
+const requestDialogWidth = 300
+
+// renderRequestDialog draws the centred yes/no dialog for a request. With
+// shortcuts on, Enter accepts and Escape declines.
+func (b *UI2DBackend) renderRequestDialog(req *RequestDialogState, width, height float32, shortcuts bool) {
+	r := b.ctx.Renderer()
+	lines := wrapText(req.Message, requestDialogWidth-24, func(s string) float32 {
+		w, _ := r.MeasureText(s, 1)
+		return w
+	})
+	countdown := countdownLabel(req.Remaining)
+
+	rows := len(lines)
+	if countdown != "" {
+		rows++
+	}
+	w, h := float32(requestDialogWidth), float32(25+8+rows*18+4+28+8)
+	var accept, decline bool
+	if b.ctx.BeginWindow("request_dialog", (width-w)/2, (height-h)/2, w, h, req.Title) {
+		for _, line := range lines {
+			b.ctx.Row(18)
+			b.ctx.Label(line)
+		}
+		if countdown != "" {
+			b.ctx.Row(18)
+			b.ctx.LabelColored(countdown, ui2d.ColorTextDim)
+		}
+		b.ctx.Row(28)
+		accept = b.ctx.Button("accept", 120, req.AcceptLabel)
+		b.ctx.SameLine()
+		decline = b.ctx.Button("decline", 120, req.DeclineLabel)
+		b.ctx.EndWindow()
+	}
+
+	if shortcuts {
+		accept = accept || b.ctx.Input().KeyEnterPressed
+		decline = decline || b.ctx.Input().KeyEscapePressed
+	}
+	switch {
+	case accept && req.OnAnswer != nil:
+		req.OnAnswer(true)
+	case decline && req.OnAnswer != nil:
+		req.OnAnswer(false)
+	}
+}
+
+// NPC dialog window layout, in UI units. The window has room for
+// npcDialogRows lines of text and grows for more.
+const (
+	npcDialogWidth = 360
+	npcDialogRows  = 5
+)
+
+// renderNPCDialog draws an NPC's dialog in the upper middle of the screen,
+// clear of the illustration. A click on the text completes the page being
+// typed.
+func (b *UI2DBackend) renderNPCDialog(d *NPCDialogState, width, height float32) {
+	r := b.ctx.Renderer()
+	lines := wrapLines(d.Text, npcDialogWidth-24, func(s string) float32 {
+		w, _ := r.MeasureText(s, 1)
+		return w
+	})
+	rows := max(len(lines), npcDialogRows)
+	title := d.Name
+	if title == "" {
+		title = "NPC"
+	}
+
+	w, h := float32(npcDialogWidth), float32(25+8+rows*22+28+8)
+	var advance, history bool
+	if b.ctx.BeginWindow("npc_dialog", (width-w)/2, height/5, w, h, title) {
+		win := b.ctx.WindowRect()
+		text := ui2d.Rect{X: win.X, Y: win.Y + 25, W: win.W, H: float32(8 + rows*22)}
+		for i := range rows {
+			b.ctx.Row(18)
+			if i < len(lines) {
+				b.ctx.Label(lines[i])
+			}
+		}
+		b.ctx.Row(28)
+		history = b.ctx.Button("history", 80, "History")
+		if d.Button != "" {
+			b.ctx.SameLine()
+			advance = b.ctx.Button("advance", 100, d.Button)
+		}
+		b.ctx.EndWindow()
+
+		input := b.ctx.Input()
+		if d.Typing && input.MouseLeftPressed && text.Contains(input.MouseX, input.MouseY) {
+			advance = true
+		}
+	}
+
+	switch {
+	case advance && d.OnAdvance != nil:
+		d.OnAdvance()
+	case history && d.OnHistory != nil:
+		d.OnHistory()
+	}
+}
+
+// Dialog history window layout, in UI units.
+const (
+	dialogHistoryWidth = 400
+	dialogHistoryRows  = 16
+)
+
+// renderDialogHistory draws the past NPC dialog lines, newest at the
+// bottom. The mouse wheel scrolls back through older ones.
+func (b *UI2DBackend) renderDialogHistory(hist *DialogHistoryState, width, height float32) {
+	r := b.ctx.Renderer()
+	rows := historyRows(hist.Lines, dialogHistoryWidth-24, func(s string) float32 {
+		w, _ := r.MeasureText(s, 1)
+		return w
+	})
+
+	w, h := float32(dialogHistoryWidth), float32(25+8+dialogHistoryRows*22+8+28+8)
+	var closed bool
+	if b.ctx.BeginWindow("dialog_history", (width-w)/2, (height-h)/2, w, h, "Dialog History (Alt+H)") {
+		input := b.ctx.Input()
+		if input.ScrollY != 0 && b.ctx.WindowRect().Contains(input.MouseX, input.MouseY) {
+			b.historyScroll += int(input.ScrollY * 3)
+		}
+		b.historyScroll = min(max(b.historyScroll, 0), max(len(rows)-dialogHistoryRows, 0))
+
+		end := len(rows) - b.historyScroll
+		start := max(end-dialogHistoryRows, 0)
+		if len(rows) == 0 {
+			b.ctx.Row(18)
+			b.ctx.LabelColored("No dialog yet.", ui2d.ColorTextDim)
+		}
+		for _, row := range rows[start:end] {
+			b.ctx.Row(18)
+			if row.Header {
+				b.ctx.LabelColored(row.Text, ui2d.ColorTextDim)
+			} else {
+				b.ctx.Label(row.Text)
+			}
+		}
+		for range dialogHistoryRows - (end - start) {
+			b.ctx.Row(18)
+		}
+		b.ctx.Separator()
+		b.ctx.Row(28)
+		closed = b.ctx.Button("close", 80, "Close")
+		b.ctx.EndWindow()
+	}
+
+	if (closed || b.ctx.Input().KeyEscapePressed) && hist.OnClose != nil {
+		hist.OnClose()
+	}
+}
+
+// Vending shop window layout, in UI units.
+const (
+	shopWidth       = 340
+	shopRowH        = 24
+	shopVisibleRows = 8
+)
+
+// renderVendingShop draws another player's shop. Selecting a row asks how
+// many of it to put in the cart, limited by the zeny the rest of the cart
+// leaves, then the cart is bought together.
+func (b *UI2DBackend) renderVendingShop(shop *VendingShopState, width, height float32) {
+	if b.shopCart == nil || b.shopVendor != shop.VendorID {
+		b.shopVendor = shop.VendorID
+		b.shopCart = make(map[int]int)
+		b.shopPrompt = nil
+	}
+
+	rows := max(1, min(len(shop.Items), shopVisibleRows))
+	listH := float32(rows*shopRowH + 8)
+	h := listH + 125
+	if !b.ctx.BeginWindow("vending_shop", (width-shopWidth)/2, (height-h)/2, shopWidth, h, shop.Title) {
+		return
+	}
+
+	b.ctx.BeginListBox("items", 0, listH)
+	for i, item := range shop.Items {
+		if i == shopVisibleRows {
+			break
+		}
+		label := fmt.Sprintf("%s  x%d  %s z", item.Name, item.Amount, formatZeny(int64(item.Price)))
+		if n := b.shopCart[item.Index]; n > 0 {
+			label += fmt.Sprintf("  [buy %d]", n)
+		}
+		selected := b.shopPrompt != nil && b.shopSel == item.Index
+		color, _ := ItemColor(item.Grade)
+		if b.ctx.SelectableColored(fmt.Sprintf("item_%d", item.Index), label, selected, color) {
+			b.shopSel = item.Index
+			b.shopPrompt = shop.CartPrompt(item, b.shopCart, func() { b.shopPrompt = nil })
+			b.qtyTitle = "" // Open at this item's amount even if another has its name
+		}
+	}
+	b.ctx.EndListBox()
+
+	total := shop.CartTotal(b.shopCart)
+	b.ctx.Row(20)
+	totalColor := ui2d.ColorTextOnDark
+	if total > shop.Zeny {
+		totalColor = ui2d.Color{R: 1, G: 0.3, B: 0.3, A: 1}
+	}
+	b.ctx.LabelColored(fmt.Sprintf("Total: %s z   (carrying %s z)", formatZeny(total), formatZeny(shop.Zeny)), totalColor)
+
+	b.ctx.Row(28)
+	buy := b.ctx.Button("buy", 100, "Buy")
+	b.ctx.SameLine()
+	if b.ctx.Button("clear", 100, "Clear") {
+		clear(b.shopCart)
+	}
+	b.ctx.SameLine()
+	// Escape closes the quantity dialog first
+	closed := b.ctx.Button("close", 100, "Close") || (b.shopPrompt == nil && b.ctx.Input().KeyEscapePressed)
+	b.ctx.EndWindow()
+
+	if b.shopPrompt != nil {
+		b.renderQuantityPrompt(b.shopPrompt, width, height)
+	}
+
+	switch {
+	case buy && total > 0:
+		if shop.OnBuy != nil {
+			shop.OnBuy(b.shopCart) // The cart is kept if the purchase is refused
+		}
+	case closed:
+		b.shopCart = nil
+		b.shopPrompt = nil
+		b.qtyTitle = ""
+		if shop.OnClose != nil {
+			shop.OnClose()
+		}
+	}
+}
+
+// Context menu layout, in UI units.
+const (
+	contextMenuWidth = 160
+	contextMenuRowH  = 24
+)
+
+// renderContextMenu draws a right-click menu at the click position, kept on
+// screen. Clicking elsewhere or pressing Escape dismisses it.
+func (b *UI2DBackend) renderContextMenu(menu *ContextMenuState, width, height float32) {
+	// Title bar, padding, then one button row (plus gap) per item
+	w := float32(contextMenuWidth)
+	h := 25 + 8 + float32(len(menu.Items))*(contextMenuRowH+4) + 8
+	x, y := b.ctx.ToUI(menu.X, menu.Y)
+	x = max(0, min(x, width-w))
+	y = max(0, min(y, height-h))
+
+	input := b.ctx.Input()
+	outside := !(ui2d.Rect{X: x, Y: y, W: w, H: h}).Contains(input.MouseX, input.MouseY)
+	dismissed := input.KeyEscape ||
+		(outside && (input.MouseLeftPressed || input.MouseRightPressed))
+
+	var selected func()
+	if b.ctx.BeginWindow("context_menu", x, y, w, h, menu.Title) {
+		for i, item := range menu.Items {
+			b.ctx.Row(contextMenuRowH)
+			id := fmt.Sprintf("item%d", i)
+			if !item.Enabled {
+				b.ctx.ButtonDisabled(id, 0, item.Label)
+				continue
+			}
+			if b.ctx.Button(id, 0, item.Label) {
+				selected = item.OnSelect
+			}
+		}
+		b.ctx.EndWindow()
+	}
+
+	switch {
+	case selected != nil:
+		selected()
+	case dismissed && menu.OnClose != nil:
+		menu.OnClose()
+	}
+}
+
+// Area map window layout, in UI units.
+const (
+	areaMapMargin  = 30
+	areaMapToolbar = 25 + 8 + 28 + 8 // Title bar and button row
+)
+
+// renderDamageNumbers draws damage numbers centered on their positions.
+func (b *UI2DBackend) renderDamageNumbers(nums []DamageNumber) {
+	r := b.ctx.Renderer()
+	for _, d := range nums {
+		w, h := r.MeasureText(d.Text, d.Scale)
+		shadow := ui2d.Color{A: d.Color.A}
+		r.DrawText(d.X-w/2+1, d.Y-h/2+1, d.Text, d.Scale, shadow)
+		r.DrawText(d.X-w/2, d.Y-h/2, d.Text, d.Scale, d.Color)
+	}
+}
+
+// renderChatBubbles draws what units said in boxes over their heads.
+func (b *UI2DBackend) renderChatBubbles(bubbles []ChatBubble) {
+	r := b.ctx.Renderer()
+	measure := func(s string) float32 {
+		w, _ := r.MeasureText(s, 1)
+		return w
+	}
+	_, lineH := r.MeasureText("Ag", 1)
+	for i := range bubbles {
+		bb := &bubbles[i]
+		lines, box := bubbleBox(bb, lineH, measure)
+		r.DrawPanel(box.X, box.Y, box.W, box.H,
+			ui2d.ColorPanelBg.WithAlpha(ui2d.ColorPanelBg.A*bb.Alpha), ui2d.ColorPanelBorder.WithAlpha(bb.Alpha))
+		y := box.Y + chatBubblePad
+		for _, l := range lines {
+			w := measure(l)
+			r.DrawText(bb.X-w/2, y, l, 1, ui2d.ColorTextOnDark.WithAlpha(bb.Alpha))
+			y += lineH
+		}
+	}
+}
+
+// renderNameplates draws unit names with a drop shadow, and the HP bars
+// of party members under them.
+func (b *UI2DBackend) renderNameplates(plates []Nameplate) {
+	r := b.ctx.Renderer()
+	for i := range plates {
+		p := &plates[i]
+		y := p.Y
+		for _, l := range p.Lines {
+			w, _ := r.MeasureText(l.Text, 1)
+			r.DrawText(p.X-w/2+1, y+1, l.Text, 1, ui2d.Color{A: l.Color.A})
+			r.DrawText(p.X-w/2, y, l.Text, 1, l.Color)
+			y += NameplateLineHeight
+		}
+		if p.HP >= 0 {
+			x := p.X - nameplateHPWidth/2
+			r.DrawRect(x-1, y+1, nameplateHPWidth+2, NameplateHPHeight+2, ui2d.ColorBlack.WithAlpha(0.7))
+			r.DrawRect(x, y+2, nameplateHPWidth*p.HP, NameplateHPHeight, p.HPColor)
+		}
+	}
+}
+
+// renderAreaMap draws the full-screen area map, or the world map, over
+// the HUD.
+func (b *UI2DBackend) renderAreaMap(m *AreaMapState, width, height float32) {
+	r := b.ctx.Renderer()
+	r.DrawRect(0, 0, width, height, ui2d.ColorBlack.WithAlpha(0.35))
+
+	title, toggle := areaMapTitle(m), "World Map"
+	if m.WorldMap {
+		title, toggle = "World Map", "Area Map"
+	}
+
+	var toggled, closed bool
+	if b.ctx.BeginWindow("area_map", areaMapMargin, areaMapMargin, width-2*areaMapMargin, height-2*areaMapMargin, title) {
+		b.ctx.Row(28)
+		toggled = b.ctx.Button("toggle", 110, toggle)
+		b.ctx.SameLine()
+		closed = b.ctx.Button("close", 80, "Close")
+
+		win := b.ctx.WindowRect()
+		box := ui2d.Rect{X: win.X + 8, Y: win.Y + areaMapToolbar, W: win.W - 16, H: win.H - areaMapToolbar - 8}
+		if m.WorldMap {
+			b.drawWorldMap(m, box)
+		} else {
+			b.drawAreaMap(m, box)
+		}
+		b.ctx.EndWindow()
+	}
+
+	switch {
+	case toggled && m.OnToggleWorld != nil:
+		m.OnToggleWorld()
+	case (closed || b.ctx.Input().KeyEscape) && m.OnClose != nil:
+		m.OnClose()
+	}
+}
+
+// drawAreaMap draws the current map's image with its units and exits into
+// box. A click on the map walks there.
+func (b *UI2DBackend) drawAreaMap(m *AreaMapState, box ui2d.Rect) {
+	r := b.ctx.Renderer()
+	input := b.ctx.Input()
+	l := fitMap(box.X, box.Y, box.W, box.H-22, m.Width, m.Height)
+	if l.scale == 0 {
+		r.DrawText(box.X, box.Y, "No map loaded", 1, ui2d.ColorTextOnDark)
+		return
+	}
+
+	if tex := b.imageTexture(areaMapTexDir + m.MapName + ".bmp"); tex != nil {
+		r.DrawImage(tex.ID, l.x, l.y, l.w, l.h, ui2d.ColorWhite.WithAlpha(0.9))
+	} else {
+		r.DrawRect(l.x, l.y, l.w, l.h, ui2d.Color{R: 0.1, G: 0.15, B: 0.2, A: 0.8})
+	}
+	r.DrawRectOutline(l.x, l.y, l.w, l.h, 1, ui2d.ColorPanelBorder)
+
+	hover := ""
+	for _, exit := range m.Exits {
+		x, y := l.toScreen(exit.X, exit.Y)
+		color := markerColor(MarkerTypeWarp)
+		if exit.OnRoute {
+			color = ui2d.ColorHighlight
+			r.DrawRectOutline(x-7, y-7, 14, 14, 2, color)
+		}
+		r.DrawRect(x-4, y-4, 8, 8, color)
+		label := exitLabel(exit)
+		labelW, _ := r.MeasureText(label, 1)
+		r.DrawText(x-labelW/2, y+6, label, 1, ui2d.ColorTextOnDark)
+	}
+	for _, mk := range m.Markers {
+		x, y := l.toScreen(mk.X, mk.Y)
+		r.DrawRect(x-3, y-3, 6, 6, markerColor(mk.Type))
+		if near(input.MouseX, input.MouseY, x, y) {
+			hover = mk.Label
+		}
+	}
+	px, py := l.toScreen(m.PlayerX, m.PlayerY)
+	r.DrawRect(px-5, py-5, 10, 10, ui2d.ColorWhite)
+	r.DrawRect(px-4, py-4, 8, 8, markerColor(MarkerTypePlayer))
+
+	if hover != "" {
+		r.DrawText(input.MouseX+12, input.MouseY, hover, 1, ui2d.ColorTextOnDark)
+	}
+	r.DrawText(box.X, box.Y+box.H-18, routeText(m), 1, ui2d.ColorTextOnDark)
+
+	if input.MouseLeftPressed && m.OnMove != nil {
+		if tx, ty, ok := l.toTile(input.MouseX, input.MouseY); ok {
+			m.OnMove(tx, ty)
+		}
+	}
+}
+
+// drawWorldMap draws the world map image with its towns into box. A click
+// on a town picks it as the destination.
+func (b *UI2DBackend) drawWorldMap(m *AreaMapState, box ui2d.Rect) {
+	r := b.ctx.Renderer()
+	input := b.ctx.Input()
+
+	// Fit the image, or a 4:3 placeholder, above the route line
+	imgW, imgH := 4, 3
+	tex := b.imageTexture(worldMapTexture)
+	if tex != nil {
+		imgW, imgH = tex.Width, tex.Height
+	}
+	l := fitMap(box.X, box.Y, box.W, box.H-22, imgW, imgH)
+	if tex != nil {
+		r.DrawImage(tex.ID, l.x, l.y, l.w, l.h, ui2d.ColorWhite.WithAlpha(0.9))
+	} else {
+		r.DrawRect(l.x, l.y, l.w, l.h, ui2d.Color{R: 0.1, G: 0.2, B: 0.3, A: 0.8})
+	}
+	r.DrawRectOutline(l.x, l.y, l.w, l.h, 1, ui2d.ColorPanelBorder)
+
+	for _, town := range m.Towns {
+		x, y := l.x+town.X*l.w, l.y+town.Y*l.h
+		color := markerColor(MarkerTypeNPC)
+		switch {
+		case town.Current:
+			color = markerColor(MarkerTypePlayer)
+		case town.Map == m.Target:
+			color = ui2d.ColorHighlight
+		}
+		hovered := near(input.MouseX, input.MouseY, x, y)
+		if hovered || town.Map == m.Target {
+			r.DrawRectOutline(x-7, y-7, 14, 14, 2, color)
+		}
+		r.DrawRect(x-4, y-4, 8, 8, color)
+		labelW, _ := r.MeasureText(town.Label, 1)
+		r.DrawText(x-labelW/2, y+6, town.Label, 1, ui2d.ColorTextOnDark)
+
+		if hovered && input.MouseLeftPressed && m.OnSelectTown != nil {
+			m.OnSelectTown(town.Map)
+		}
+	}
+	r.DrawText(box.X, box.Y+box.H-18, routeText(m), 1, ui2d.ColorTextOnDark)
+}
+
+// imageTexture loads a map image or an NPC illustration once, returning
+// nil if it isn't in the GRF.
+func (b *UI2DBackend) imageTexture(path string) *TextureInfo {
+	if b.texCache == nil {
+		return nil
+	}
+	if tex, ok := b.images[path]; ok {
+		return tex
+	}
+	if b.images == nil {
+		b.images = make(map[string]*TextureInfo)
+	}
+	tex, err := b.texCache.Load(path)
+	if err != nil {
+		tex = nil
+	}
+	b.images[path] = tex
+	return tex
+}
+
+// renderCutin draws an NPC illustration where the server placed it, on
+// the status bar or centered. The window placement can be dragged aside.
+func (b *UI2DBackend) renderCutin(c *CutinState, width, height float32) {
+	var tex *TextureInfo
+	for _, path := range cutinImagePaths(c.Image) {
+		if tex = b.imageTexture(path); tex != nil {
+			break
+		}
+	}
+	if tex == nil {
+		return
+	}
+	r := b.ctx.Renderer()
+	w, h := float32(tex.Width), float32(tex.Height)
+	tint := ui2d.ColorWhite.WithAlpha(c.Alpha)
+	if c.Position != packets.CutinWindow {
+		x, y := cutinOrigin(c.Position, w, h, width, height, 25)
+		r.DrawImage(tex.ID, x, y, w, h, tint)
+		return
+	}
+
+	const titleH, pad = 25, 4
+	x, y := cutinOrigin(c.Position, w+2*pad, h+titleH+2*pad, width, height, 25)
+	if b.ctx.BeginWindow("cutin", x, y, w+2*pad, h+titleH+2*pad, "") {
+		win := b.ctx.WindowRect()
+		r.DrawImage(tex.ID, win.X+pad, win.Y+titleH+pad, w, h, tint)
+		b.ctx.EndWindow()
+	}
+}
+
+// renderBanner draws a server announcement on a strip across the top of
+// the screen.
+func (b *UI2DBackend) renderBanner(banner *BannerState, width float32) {
+	r := b.ctx.Renderer()
+	scale := bannerScale(banner.FontSize)
+	textW, textH := r.MeasureText(banner.Text, scale)
+	x := bannerX(banner.Progress, textW, width)
+	r.DrawRect(0, bannerTop-bannerPad, width, textH+2*bannerPad, ui2d.ColorBlack.WithAlpha(0.45))
+	r.DrawText(x+1, bannerTop+1, banner.Text, scale, ui2d.ColorBlack)
+	r.DrawText(x, bannerTop, banner.Text, scale, bannerColor(banner.Color))
+}
+
+// renderBuffs draws the player's buffs in a column down the right edge,
+// each lettered, with a ring counting down its time left.
+func (b *UI2DBackend) renderBuffs(buffs []BuffIcon, width float32) {
+	r := b.ctx.Renderer()
+	for i, buff := range buffs {
+		x, y := buffColumn(i, width)
+		b.ctx.TooltipRegion("buff_"+buff.Name, x, y, buffIconSize, buffIconSize, func() *ui2d.Tooltip {
+			t := ui2d.NewTooltip().Title(buff.Name)
+			if buff.Timed {
+				t.Text(buffTime(buff.Remaining)+" left", ui2d.ColorTextOnDark)
+			}
+			return t
+		})
+		if !buffVisible(buff) {
+			continue
+		}
+		r.DrawPanel(x, y, buffIconSize, buffIconSize, ui2d.ColorPanelBg, ui2d.ColorPanelBorder)
+		label := buffLabel(buff.Name)
+		labelW, _ := r.MeasureText(label, 0.8)
+		r.DrawText(x+(buffIconSize-labelW)/2, y+4, label, 0.8, ui2d.ColorTextOnDark)
+		if !buff.Timed {
+			continue
+		}
+		left := buffTime(buff.Remaining)
+		leftW, _ := r.MeasureText(left, 0.7)
+		r.DrawText(x+(buffIconSize-leftW)/2, y+buffIconSize-14, left, 0.7, ui2d.ColorTextDim)
+		ring := ui2d.ColorHighlight
+		if buff.Expiring {
+			ring = ui2d.Color{R: 1, G: 0.4, B: 0.2, A: 1}
+		}
+		for _, seg := range ringSegments(x, y, buffIconSize, buffRing, buff.Fraction) {
+			r.DrawRect(seg[0], seg[1], seg[2], seg[3], ring)
+		}
+	}
+}
+
+// RenderFPSOverlay renders an FPS counter.
+func (b *UI2DBackend) RenderFPSOverlay(fps float64, width, height float32) {
+	scale := float32(1.0)
+	text := fmt.Sprintf("FPS: %.0f", fps)
+	textW, _ := b.ctx.Renderer().MeasureText(text, scale)
+
+	x := width - textW - 10
+	y := float32(5)
+
+	// Semi-transparent background
+	b.ctx.Renderer().DrawRect(x-5, y-2, textW+10, 20, ui2d.ColorPanelBg.WithAlpha(0.5))
+	b.ctx.Renderer().DrawText(x, y, text, scale, ui2d.ColorTextOnDark)
+}
+
+// Notification layout, in UI units.
+const (
+	notifyMargin = 10 // From the right edge
+	notifyBottom = 60 // From the bottom edge to the newest
+	notifyIcon   = 16
+	notifyPad    = 5
+	notifyGap    = 4
+)
+
+// RenderNotifications renders the notification toasts up from the
+// bottom right corner, newest at the bottom, each marked with its kind's
+// icon and color. Clicking one hands it to OnClick.
+func (b *UI2DBackend) RenderNotifications(state NotificationsState, width, height float32) {
+	r := b.ctx.Renderer()
+	input := b.ctx.Input()
+	y := height - notifyBottom
+	for i := len(state.Items) - 1; i >= 0; i-- {
+		n := state.Items[i]
+		label, icon, color := n.Label(), n.Kind.Icon(), n.Kind.Color()
+		textW, textH := r.MeasureText(label, 1)
+		w, h := notifyIcon+textW+3*notifyPad, max(textH, notifyIcon)+2*notifyPad
+		x := width - notifyMargin - w
+		y -= h
+
+		r.PushEffect(ui2d.Effect{Scale: 1, Alpha: n.Alpha})
+		r.DrawPanel(x, y, w, h, ui2d.ColorPanelBg, color)
+		r.DrawRect(x+notifyPad, y+(h-notifyIcon)/2, notifyIcon, notifyIcon, color)
+		iconW, iconH := r.MeasureText(icon, 1)
+		r.DrawText(x+notifyPad+(notifyIcon-iconW)/2, y+(h-iconH)/2, icon, 1, ui2d.ColorBlack)
+		r.DrawText(x+notifyIcon+2*notifyPad, y+(h-textH)/2, label, 1, ui2d.ColorTextOnDark)
+		r.PopEffect()
+
+		rect := ui2d.Rect{X: x, Y: y, W: w, H: h}
+		if input.MouseLeftPressed && rect.Contains(input.MouseX, input.MouseY) && state.OnClick != nil {
+			state.OnClick(n)
+		}
+		y -= notifyGap
+	}
+}
+
+// RenderScreenFlash renders a full-screen white flash.
+func (b *UI2DBackend) RenderScreenFlash(alpha float32, width, height float32) {
+	if alpha <= 0 {
+		return
+	}
+	b.ctx.Renderer().DrawRect(0, 0, width, height, ui2d.ColorWhite.WithAlpha(alpha))
+}
+
+// RenderScreenshotGallery renders the recent screenshots window.
+func (b *UI2DBackend) RenderScreenshotGallery(state ScreenshotGalleryState, width, height float32) {
+	const thumbW, thumbH = float32(96), float32(72)
+
+	rows := max(len(state.Entries), 1)
+	windowWidth := float32(420)
+	windowHeight := float32(rows)*(thumbH+4) + 110
+	windowX := (width - windowWidth) / 2
+	windowY := (height - windowHeight) / 2
+
+	if b.ctx.BeginWindow("screenshots", windowX, windowY, windowWidth, windowHeight, "Screenshots") {
+		b.ctx.Row(16)
+		b.ctx.LabelColored(state.Dir, ui2d.ColorTextDim)
+		b.ctx.Separator()
+
+		if len(state.Entries) == 0 {
+			b.ctx.Row(16)
+			b.ctx.Label("No screenshots yet. Press F12 to capture.")
+		}
+
+		for _, entry := range state.Entries {
+			// Fit the thumbnail into the fixed cell, preserving aspect ratio
+			w, h := thumbW, thumbH
+			if entry.Width > 0 && entry.Height > 0 {
+				aspect := float32(entry.Width) / float32(entry.Height)
+				if aspect > thumbW/thumbH {
+					h = thumbW / aspect
+				} else {
+					w = thumbH * aspect
+				}
+			}
+
+			b.ctx.Row(thumbH)
+			b.ctx.Image(entry.TextureID, w, h)
+			b.ctx.SameLine()
+			b.ctx.Label(entry.Name)
+		}
+
+		b.ctx.Separator()
+		b.ctx.Row(28)
+		if b.ctx.Button("close", 0, "Close") && state.OnClose != nil {
+			state.OnClose()
+		}
+
+		b.ctx.EndWindow()
+	}
+}
+
+// systemMenuWidth is the width of the Escape menu in UI units.
+const systemMenuWidth = 220
+
+// RenderSystemMenu renders the Escape menu.
+func (b *UI2DBackend) RenderSystemMenu(state SystemMenuState, width, height float32) {
+	w := float32(systemMenuWidth)
+	h := float32(25 + 8 + 4*(28+4) + 8)
+	if !b.ctx.BeginWindow("system_menu", (width-w)/2, (height-h)/2, w, h, "Menu") {
+		return
+	}
+	var selected func()
+	b.ctx.Row(28)
+	if state.CharSelectPending {
+		b.ctx.ButtonDisabled("char_select", 0, "Switching...")
+	} else if b.ctx.Button("char_select", 0, "Character Select") {
+		selected = state.OnCharSelect
+	}
+	b.ctx.Row(28)
+	if b.ctx.Button("settings", 0, "Settings") {
+		selected = state.OnSettings
+	}
+	b.ctx.Row(28)
+	if b.ctx.Button("exit", 0, "Exit Game") {
+		selected = state.OnExit
+	}
+	b.ctx.Row(28)
+	if b.ctx.Button("close", 0, "Back to Game") {
+		selected = state.OnClose
+	}
+	b.ctx.EndWindow()
+
+	if selected != nil {
+		selected()
+	}
+}
+
+// uiScaleStep is the increment used by the settings window's scale buttons.
+const uiScaleStep = 0.25
+
+// damageTextScaleStep is the increment of the damage number size buttons.
+const damageTextScaleStep = 0.25
+
+// cameraEffectScaleStep is the increment of the camera effect strength
+// buttons.
+const cameraEffectScaleStep = 0.25
+
+// tenths converts a setting to the tenths its slider steps in.
+func tenths(v float32) int {
+	return int(math.Round(float64(v) * 10))
+}
+
+// fpsLimitLabel returns how the settings show a frame rate cap.
+func fpsLimitLabel(limit int) string {
+	if limit <= 0 {
+		return "Off"
+	}
+	return strconv.Itoa(limit)
+}
+
+// nextPalette returns the palette after the named one, wrapping around.
+func nextPalette(name string) string {
+	for i, p := range ui2d.Palettes {
+		if p.Name == name {
+			return ui2d.Palettes[(i+1)%len(ui2d.Palettes)].Name
+		}
+	}
+	return ui2d.Palettes[0].Name
+}
+
+// RenderSettingsUI renders the settings window.
+func (b *UI2DBackend) RenderSettingsUI(state SettingsUIState, width, height float32) {
+	windowWidth := float32(260)
+	windowHeight := float32(525 + 150 + 66 + 22 + 100 + 44)
+	if state.HasColorLUT {
+		windowHeight += 26
+	}
+	windowX := (width - windowWidth) / 2
+	windowY := (height - windowHeight) / 2
+
+	if b.ctx.BeginWindow("settings", windowX, windowY, windowWidth, windowHeight, "Settings") {
+		b.ctx.Row(16)
+		b.ctx.Label(fmt.Sprintf("UI Scale: %.0f%%", state.UIScale*100))
+
+		b.ctx.Row(28)
+		if b.ctx.Button("scale_down", 40, "-") && state.OnUIScaleChange != nil {
+			state.OnUIScaleChange(ui2d.ClampScale(state.UIScale - uiScaleStep))
+		}
+		if b.ctx.Button("scale_up", 40, "+") && state.OnUIScaleChange != nil {
+			state.OnUIScaleChange(ui2d.ClampScale(state.UIScale + uiScaleStep))
+		}
+		if b.ctx.Button("scale_reset", 80, "Reset") && state.OnUIScaleChange != nil {
+			state.OnUIScaleChange(1.0)
+		}
+
+		b.ctx.Row(22)
+		if auras := b.ctx.Checkbox("auras", "Character auras", state.Auras); auras != state.Auras &&
+			state.OnAurasChange != nil {
+			state.OnAurasChange(auras)
+		}
+		b.ctx.Row(22)
+		if instant := b.ctx.Checkbox("instant_dialog_text", "Instant NPC text", state.InstantDialogText); instant != state.InstantDialogText &&
+			state.OnInstantDialogTextChange != nil {
+			state.OnInstantDialogTextChange(instant)
+		}
+		b.ctx.Row(16)
+		b.ctx.Label("Language: " + state.Language)
+		b.ctx.Row(28)
+		if b.ctx.Button("language_next", 80, "Change") && state.OnLanguageNext != nil {
+			state.OnLanguageNext()
+		}
+
+		// The cap button steps through the caps in pacing.Limits
+		b.ctx.Separator()
+		b.ctx.Row(16)
+		b.ctx.Label("FPS Cap: " + fpsLimitLabel(state.FPSLimit))
+		b.ctx.Row(28)
+		if b.ctx.Button("fps_limit_next", 80, "Change") && state.OnFPSLimitChange != nil {
+			state.OnFPSLimitChange(pacing.NextLimit(state.FPSLimit))
+		}
+		b.ctx.Row(22)
+		if vsync := b.ctx.Checkbox("vsync", "VSync", state.VSync); vsync != state.VSync &&
+			state.OnVSyncChange != nil {
+			state.OnVSyncChange(vsync)
+		}
+		b.ctx.Row(22)
+		if throttle := b.ctx.Checkbox("background_throttle", "Slow down in background", state.BackgroundThrottle); throttle != state.BackgroundThrottle &&
+			state.OnThrottleChange != nil {
+			state.OnThrottleChange(throttle)
+		}
+
+		// Gamma and brightness slide in tenths
+		b.ctx.Separator()
+		b.ctx.Row(16)
+		b.ctx.Label(fmt.Sprintf("Gamma: %.1f", state.Gamma))
+		b.ctx.Row(20)
+		if v, changed := b.ctx.Slider("gamma", 0, tenths(state.Gamma), tenths(postfx.MinGamma), tenths(postfx.MaxGamma)); changed &&
+			state.OnGammaChange != nil {
+			state.OnGammaChange(float32(v) / 10)
+		}
+		b.ctx.Row(16)
+		b.ctx.Label(fmt.Sprintf("Brightness: %.0f%%", state.Brightness*100))
+		b.ctx.Row(20)
+		if v, changed := b.ctx.Slider("brightness", 0, tenths(state.Brightness), tenths(postfx.MinBrightness), tenths(postfx.MaxBrightness)); changed &&
+			state.OnBrightnessChange != nil {
+			state.OnBrightnessChange(float32(v) / 10)
+		}
+		b.ctx.Row(22)
+		if fxaa := b.ctx.Checkbox("fxaa", "Anti-aliasing (FXAA)", state.FXAA); fxaa != state.FXAA &&
+			state.OnFXAAChange != nil {
+			state.OnFXAAChange(fxaa)
+		}
+		if state.HasColorLUT {
+			b.ctx.Row(22)
+			if grading := b.ctx.Checkbox("color_grading", "Color grading", state.ColorGrading); grading != state.ColorGrading &&
+				state.OnColorGradingChange != nil {
+				state.OnColorGradingChange(grading)
+			}
+		}
+
+		b.ctx.Separator()
+		b.ctx.Row(16)
+		b.ctx.Label("Accessibility")
+
+		// The palette button steps through the palettes in menu order
+		palette := ui2d.PaletteByName(state.Palette)
+		b.ctx.Row(16)
+		b.ctx.Label("Colors: " + palette.Label)
+		b.ctx.Row(28)
+		if b.ctx.Button("palette_next", 80, "Change") && state.OnPaletteChange != nil {
+			state.OnPaletteChange(nextPalette(palette.Name))
+		}
+
+		b.ctx.Row(16)
+		b.ctx.Label(fmt.Sprintf("Damage Numbers: %.0f%%", state.DamageTextScale*100))
+		b.ctx.Row(28)
+		if b.ctx.Button("damage_down", 40, "-") && state.OnDamageTextScaleChange != nil {
+			state.OnDamageTextScaleChange(ui2d.ClampDamageTextScale(state.DamageTextScale - damageTextScaleStep))
+		}
+		if b.ctx.Button("damage_up", 40, "+") && state.OnDamageTextScaleChange != nil {
+			state.OnDamageTextScaleChange(ui2d.ClampDamageTextScale(state.DamageTextScale + damageTextScaleStep))
+		}
+
+		b.ctx.Row(22)
+		if reduce := b.ctx.Checkbox("reduce_flashes", "Reduce flashes", state.ReduceFlashes); reduce != state.ReduceFlashes &&
+			state.OnReduceFlashesChange != nil {
+			state.OnReduceFlashesChange(reduce)
+		}
+
+		b.ctx.Row(22)
+		if fx := b.ctx.Checkbox("camera_effects", "Camera shake and zoom", state.CameraEffects); fx != state.CameraEffects &&
+			state.OnCameraEffectsChange != nil {
+			state.OnCameraEffectsChange(fx)
+		}
+		b.ctx.Row(16)
+		b.ctx.Label(fmt.Sprintf("Camera Effects: %.0f%%", state.CameraEffectScale*100))
+		b.ctx.Row(28)
+		if b.ctx.Button("camera_fx_down", 40, "-") && state.OnCameraEffectScaleChange != nil {
+			state.OnCameraEffectScaleChange(camera.ClampEffectScale(state.CameraEffectScale - cameraEffectScaleStep))
+		}
+		if b.ctx.Button("camera_fx_up", 40, "+") && state.OnCameraEffectScaleChange != nil {
+			state.OnCameraEffectScaleChange(camera.ClampEffectScale(state.CameraEffectScale + cameraEffectScaleStep))
+		}
+
+		// Each nameplate button steps its unit type through the modes
+		b.ctx.Separator()
+		b.ctx.Row(16)
+		b.ctx.Label("Nameplates")
+		for i, np := range state.Nameplates {
+			b.ctx.Row(24)
+			if b.ctx.Button(fmt.Sprintf("nameplate_%d", i), 0, np.Label+": "+np.Mode) && state.OnNameplateModeCycle != nil {
+				state.OnNameplateModeCycle(i)
+			}
+		}
+		b.ctx.Row(22)
+		if show := b.ctx.Checkbox("nameplate_guild", "Guild names", state.NameplateGuild); show != state.NameplateGuild &&
+			state.OnNameplateGuildChange != nil {
+			state.OnNameplateGuildChange(show)
+		}
+		b.ctx.Row(22)
+		if show := b.ctx.Checkbox("nameplate_party_hp", "Party HP bars", state.NameplatePartyHP); show != state.NameplatePartyHP &&
+			state.OnNameplatePartyHPChange != nil {
+			state.OnNameplatePartyHPChange(show)
+		}
+
+		b.ctx.Separator()
+		b.ctx.Row(28)
+		if b.ctx.Button("close", 0, "Close") && state.OnClose != nil {
+			state.OnClose()
+		}
+
+		b.ctx.EndWindow()
+	}
 }
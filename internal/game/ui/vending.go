@@ -0,0 +1,49 @@
+package ui
+
+import "strconv"
+
+// CartTotal returns the zeny a cart of amounts by item index costs.
+func (v *VendingShopState) CartTotal(cart map[int]int) int64 {
+	var total int64
+	for _, item := range v.Items {
+		total += int64(item.Price) * int64(cart[item.Index])
+	}
+	return total
+}
+
+// CartPrompt builds the dialog asking how many of an item to put in a
+// cart, paid for with the zeny the rest of the cart leaves. Zero takes the
+// item out of the cart. done is called when the dialog closes either way.
+func (v *VendingShopState) CartPrompt(item VendingShopItem, cart map[int]int, done func()) *QuantityPrompt {
+	rest := v.CartTotal(cart) - int64(item.Price)*int64(cart[item.Index])
+	return &QuantityPrompt{
+		Title:     "Buy " + item.Name,
+		Max:       item.Amount,
+		Initial:   max(1, cart[item.Index]),
+		UnitPrice: int64(item.Price),
+		Zeny:      v.Zeny - rest,
+		OnConfirm: func(amount int) {
+			cart[item.Index] = amount
+			done()
+		},
+		OnCancel: done,
+	}
+}
+
+// formatZeny formats an amount of zeny with thousands separators, as shop
+// prices are shown: 1500000 becomes "1,500,000".
+func formatZeny(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	sign := ""
+	if n < 0 {
+		sign, s = "-", s[1:]
+	}
+	out := make([]byte, 0, len(s)+len(s)/3)
+	for i := range len(s) {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			out = append(out, ',')
+		}
+		out = append(out, s[i])
+	}
+	return sign + string(out)
+}
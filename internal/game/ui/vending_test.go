@@ -0,0 +1,56 @@
+package ui
+
+import "testing"
+
+func TestCartTotal(t *testing.T) {
+	shop := &VendingShopState{Items: []VendingShopItem{
+		{Index: 2, Price: 50, Amount: 100},
+		{Index: 5, Price: 1500000, Amount: 2},
+	}}
+	if got := shop.CartTotal(map[int]int{2: 10, 5: 2, 9: 4}); got != 3000500 {
+		t.Errorf("CartTotal = %d, want 3000500", got)
+	}
+	if got := shop.CartTotal(nil); got != 0 {
+		t.Errorf("CartTotal(nil) = %d, want 0", got)
+	}
+}
+
+func TestFormatZeny(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0"},
+		{999, "999"},
+		{1000, "1,000"},
+		{1500000, "1,500,000"},
+		{-25000, "-25,000"},
+	}
+	for _, tt := range tests {
+		if got := formatZeny(tt.n); got != tt.want {
+			t.Errorf("formatZeny(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestCartPrompt(t *testing.T) {
+	shop := &VendingShopState{Zeny: 10000, Items: []VendingShopItem{
+		{Index: 2, Name: "Red Potion", Price: 50, Amount: 100},
+		{Index: 5, Name: "Card", Price: 3000, Amount: 5},
+	}}
+	cart := map[int]int{2: 40, 5: 1}
+	closed := false
+	p := shop.CartPrompt(shop.Items[1], cart, func() { closed = true })
+
+	// 2,000 z go to the potions, leaving enough for two cards at most
+	if limit, reason := p.Limit(); limit != 2 || reason != "Not enough zeny" {
+		t.Errorf("Limit() = %d, %q, want 2, not enough zeny", limit, reason)
+	}
+	if p.Start() != 1 {
+		t.Errorf("Start() = %d, want the amount in the cart", p.Start())
+	}
+	p.OnConfirm(0)
+	if cart[5] != 0 || !closed {
+		t.Errorf("confirming 0 left cart %v, closed %v", cart, closed)
+	}
+}
@@ -0,0 +1,164 @@
+package world
+
+import (
+	"strings"
+	"time"
+)
+
+// Clock is the map server's tick, synchronized from ZC_NOTIFY_TIME
+// replies. The server only reports its tick in milliseconds; the clock
+// extrapolates from the last reply, which left the server half a round
+// trip before it arrived.
+type Clock struct {
+	tick uint32
+	at   time.Time // When the server was at tick
+}
+
+// Sync records a tick the server reported, received at now over a link
+// with round trip rtt.
+func (c *Clock) Sync(tick uint32, rtt time.Duration, now time.Time) {
+	c.tick = tick
+	c.at = now.Add(-rtt / 2)
+}
+
+// Synced reports whether the server has reported its tick yet.
+func (c *Clock) Synced() bool {
+	return !c.at.IsZero()
+}
+
+// Uptime returns the server tick at now as a duration, or 0 before the
+// first sync.
+func (c *Clock) Uptime(now time.Time) time.Duration {
+	if !c.Synced() {
+		return 0
+	}
+	return time.Duration(c.tick)*time.Millisecond + now.Sub(c.at)
+}
+
+// maxTwilight is how long the light takes to fade between day and night.
+const maxTwilight = 30 * time.Second
+
+// DayCycle follows the map server's day and night. rAthena runs them on
+// timers (the day_duration and night_duration battle config) and darkens
+// clients with the EFST_SKE status; with the durations known, the cycle
+// predicts the next change from the last one the server announced.
+type DayCycle struct {
+	Day, Night time.Duration // Lengths of day and night; zero if the server has no cycle
+
+	night bool          // Last phase the server announced
+	since time.Duration // Server uptime it began at
+	known bool
+}
+
+// Daylight is the time of day at a moment.
+type Daylight struct {
+	Night    bool
+	Hour     float64 // Game clock, 0-24: days run 06:00-18:00, nights 18:00-06:00
+	Darkness float64 // 0 in full day to 1 in full night, fading across twilight
+}
+
+// Clock returns the game time as "HH:MM".
+func (d Daylight) Clock() string {
+	minutes := int(d.Hour * 60)
+	return time.Date(0, 1, 1, minutes/60, minutes%60, 0, 0, time.UTC).Format("15:04")
+}
+
+// Observe records the server announcing night (or day) at uptime, which
+// anchors the predicted cycle. The first announcement takes effect at
+// once rather than fading in.
+func (c *DayCycle) Observe(night bool, uptime time.Duration) {
+	if c.known && c.night == night {
+		return
+	}
+	if !c.known {
+		uptime -= c.twilight()
+	}
+	c.night, c.since, c.known = night, uptime, true
+}
+
+// At returns the time of day at a server uptime.
+func (c *DayCycle) At(uptime time.Duration) Daylight {
+	night, start, length := c.phase(uptime)
+	elapsed := uptime - start
+
+	d := Daylight{Night: night}
+	if length > 0 {
+		d.Hour = 6 + 12*float64(elapsed)/float64(length)
+		if night {
+			d.Hour += 12
+		}
+	} else {
+		// No cycle: the clock just follows the server's tick
+		d.Hour = float64(uptime%(24*time.Hour)) / float64(time.Hour)
+	}
+	for d.Hour >= 24 {
+		d.Hour -= 24
+	}
+
+	fade := 1.0
+	if tw := c.twilight(); elapsed < tw {
+		fade = float64(elapsed) / float64(tw)
+	}
+	if night {
+		d.Darkness = fade
+	} else {
+		d.Darkness = 1 - fade
+	}
+	return d
+}
+
+// phase returns whether it's night at uptime, the uptime the phase began
+// at and its length, 0 when there's no cycle to predict from.
+func (c *DayCycle) phase(uptime time.Duration) (night bool, start, length time.Duration) {
+	period := c.Day + c.Night
+	if period <= 0 || c.Day <= 0 || c.Night <= 0 {
+		return c.night, c.since, 0
+	}
+	// Uptime at which a day began
+	var anchor time.Duration
+	if c.known {
+		anchor = c.since
+		if c.night {
+			anchor -= c.Day
+		}
+	}
+	p := (uptime - anchor) % period
+	if p < 0 {
+		p += period
+	}
+	if p < c.Day {
+		return false, uptime - p, c.Day
+	}
+	return true, uptime - (p - c.Day), c.Night
+}
+
+// twilight is the fade between day and night, shortened for short cycles.
+func (c *DayCycle) twilight() time.Duration {
+	tw := maxTwilight
+	for _, d := range []time.Duration{c.Day, c.Night} {
+		if d > 0 && d/4 < tw {
+			tw = d / 4
+		}
+	}
+	return tw
+}
+
+// nightLampMaps are the towns whose street lamps (the light sources in
+// their RSW) are lit only at night.
+var nightLampMaps = map[string]bool{
+	"prontera":    true,
+	"geffen":      true,
+	"payon":       true,
+	"alberta":     true,
+	"izlude":      true,
+	"aldebaran":   true,
+	"comodo":      true,
+	"yuno":        true,
+	"lighthalzen": true,
+	"hugel":       true,
+}
+
+// HasNightLamps reports whether a map's lamps follow the day/night cycle.
+func HasNightLamps(mapName string) bool {
+	return nightLampMaps[strings.TrimSuffix(strings.ToLower(mapName), ".gat")]
+}
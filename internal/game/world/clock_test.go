@@ -0,0 +1,71 @@
+package world
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestClockUptime(t *testing.T) {
+	var c Clock
+	now := time.Now()
+	if c.Synced() || c.Uptime(now) != 0 {
+		t.Fatal("unsynced clock should report no uptime")
+	}
+	// The reply took half of a 100ms round trip to arrive
+	c.Sync(5000, 100*time.Millisecond, now)
+	if got, want := c.Uptime(now.Add(time.Second)), 6050*time.Millisecond; got != want {
+		t.Errorf("Uptime = %v, want %v", got, want)
+	}
+}
+
+func TestDayCyclePredicts(t *testing.T) {
+	c := &DayCycle{Day: 2 * time.Hour, Night: 30 * time.Minute}
+	tests := []struct {
+		uptime   time.Duration
+		night    bool
+		hour     float64
+		darkness float64
+	}{
+		{0, false, 6, 1}, // A fresh day is still fading in
+		{time.Hour, false, 12, 0},
+		{2*time.Hour + 15*time.Minute, true, 0, 1},
+		{2*time.Hour + 15*time.Second, true, 18.1, 0.5},
+		{2*time.Hour + 30*time.Minute + time.Hour, false, 12, 0},
+	}
+	for _, tt := range tests {
+		d := c.At(tt.uptime)
+		if d.Night != tt.night || math.Abs(d.Hour-tt.hour) > 1e-9 || math.Abs(d.Darkness-tt.darkness) > 1e-9 {
+			t.Errorf("At(%v) = %+v, want night %v hour %v darkness %v", tt.uptime, d, tt.night, tt.hour, tt.darkness)
+		}
+	}
+}
+
+func TestDayCycleObserve(t *testing.T) {
+	// The server announces night an hour in, off the predicted schedule
+	c := &DayCycle{Day: 2 * time.Hour, Night: 30 * time.Minute}
+	c.Observe(true, time.Hour)
+	if d := c.At(time.Hour); !d.Night || d.Darkness != 1 {
+		t.Errorf("first announcement should darken at once, got %+v", d)
+	}
+	if d := c.At(time.Hour + 35*time.Minute); d.Night {
+		t.Errorf("day should follow the announced night, got %+v", d)
+	}
+
+	// Without a cycle only the announcements count, fading in
+	var none DayCycle
+	none.Observe(false, 0)
+	none.Observe(true, time.Hour)
+	if d := none.At(time.Hour + 15*time.Second); !d.Night || d.Darkness != 0.5 {
+		t.Errorf("announced night = %+v, want halfway into twilight", d)
+	}
+}
+
+func TestDaylightClock(t *testing.T) {
+	if got := (Daylight{Hour: 18.5}).Clock(); got != "18:30" {
+		t.Errorf("Clock() = %q, want 18:30", got)
+	}
+	if !HasNightLamps("prontera.gat") || HasNightLamps("prt_fild08") {
+		t.Error("HasNightLamps should match towns only")
+	}
+}
@@ -0,0 +1,182 @@
+package world
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Desync detection defaults. Samples are taken a few times a second, so
+// the history covers the last ~15 seconds of walking.
+const (
+	DesyncHistorySize      = 64
+	DefaultDesyncThreshold = 3.0 // Tiles
+	DefaultDesyncStrikes   = 4   // Consecutive samples over the threshold
+)
+
+// DefaultCellDuration is how long the server takes to walk one cell
+// straight at the default walk speed (rAthena DEFAULT_WALK_SPEED).
+const DefaultCellDuration = 150 * time.Millisecond
+
+// Step costs of the server's pathfinding (MOVE_COST, MOVE_DIAGONAL_COST):
+// a diagonal step takes 1.4 times as long as a straight one.
+const (
+	straightCost = 10
+	diagonalCost = 14
+)
+
+// ServerWalk is a walk the server confirmed, from which the server's idea
+// of the player's position at any moment can be estimated.
+type ServerWalk struct {
+	StartX, StartY int
+	EndX, EndY     int
+	Start          time.Time     // When the walk was confirmed
+	CellDuration   time.Duration // Time per straight cell
+}
+
+// Duration returns how long the server takes to walk from start to end,
+// assuming a path with as many diagonal steps as possible.
+func (w ServerWalk) Duration() time.Duration {
+	dx := abs(w.EndX - w.StartX)
+	dy := abs(w.EndY - w.StartY)
+	diag := min(dx, dy)
+	straight := max(dx, dy) - diag
+	return w.CellDuration * time.Duration(straight*straightCost+diag*diagonalCost) / straightCost
+}
+
+// PositionAt estimates the server's position of the walker at t, in
+// tiles, along the straight line from start to end.
+func (w ServerWalk) PositionAt(t time.Time) (x, y float32) {
+	frac := float32(1)
+	if d := w.Duration(); d > 0 {
+		frac = min(max(float32(t.Sub(w.Start))/float32(d), 0), 1)
+	}
+	x = float32(w.StartX) + float32(w.EndX-w.StartX)*frac
+	y = float32(w.StartY) + float32(w.EndY-w.StartY)*frac
+	return x, y
+}
+
+// PositionSample compares the server's and the client's position of the
+// player at one moment, in tiles.
+type PositionSample struct {
+	Time       time.Time `json:"time"`
+	ServerX    float32   `json:"server_x"`
+	ServerY    float32   `json:"server_y"`
+	PredictedX float32   `json:"predicted_x"`
+	PredictedY float32   `json:"predicted_y"`
+}
+
+// Drift returns the distance in tiles between the two positions.
+func (s PositionSample) Drift() float32 {
+	dx := float64(s.PredictedX - s.ServerX)
+	dy := float64(s.PredictedY - s.ServerY)
+	return float32(math.Hypot(dx, dy))
+}
+
+// DesyncEvent records a lasting drift between the client and the server,
+// with the samples leading up to it.
+type DesyncEvent struct {
+	Time    time.Time        `json:"time"`
+	Map     string           `json:"map"`
+	Drift   float32          `json:"drift"` // Tiles, at the last sample
+	Samples []PositionSample `json:"samples"`
+}
+
+// Last returns the sample that triggered the event.
+func (e *DesyncEvent) Last() PositionSample {
+	if len(e.Samples) == 0 {
+		return PositionSample{}
+	}
+	return e.Samples[len(e.Samples)-1]
+}
+
+// Write saves the event as JSON in a timestamped file under dir, for bug
+// reports, and returns the file's path.
+func (e *DesyncEvent) Write(dir string) (string, error) {
+	if dir == "" {
+		dir = "."
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("creating desync folder: %w", err)
+	}
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("encoding desync event: %w", err)
+	}
+	path := filepath.Join(dir, "desync-"+e.Time.Format("20060102-150405")+".json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("writing desync event: %w", err)
+	}
+	return path, nil
+}
+
+// DesyncDetector keeps a ring of position samples and reports a desync when
+// the drift stays over a threshold for several samples in a row. A single
+// sample over the threshold, e.g. from a late walk confirmation, is not
+// enough.
+type DesyncDetector struct {
+	Threshold float32 // Tiles
+	Strikes   int     // Consecutive samples over the threshold
+
+	samples []PositionSample
+	next    int
+	full    bool
+	over    int // Consecutive samples over the threshold so far
+}
+
+// NewDesyncDetector creates a detector with the default history size,
+// threshold and strike count.
+func NewDesyncDetector() *DesyncDetector {
+	return &DesyncDetector{
+		Threshold: DefaultDesyncThreshold,
+		Strikes:   DefaultDesyncStrikes,
+		samples:   make([]PositionSample, DesyncHistorySize),
+	}
+}
+
+// Record adds a sample and returns a desync event if the drift has now
+// stayed over the threshold for Strikes samples, or nil. The strike count
+// restarts after an event, so the caller has time to resync.
+func (d *DesyncDetector) Record(s PositionSample) *DesyncEvent {
+	d.samples[d.next] = s
+	d.next = (d.next + 1) % len(d.samples)
+	if d.next == 0 {
+		d.full = true
+	}
+
+	if s.Drift() <= d.Threshold {
+		d.over = 0
+		return nil
+	}
+	d.over++
+	if d.over < d.Strikes {
+		return nil
+	}
+	d.over = 0
+	return &DesyncEvent{
+		Time:    s.Time,
+		Drift:   s.Drift(),
+		Samples: d.History(),
+	}
+}
+
+// History returns the recorded samples, oldest first.
+func (d *DesyncDetector) History() []PositionSample {
+	if !d.full {
+		return append([]PositionSample(nil), d.samples[:d.next]...)
+	}
+	out := make([]PositionSample, 0, len(d.samples))
+	out = append(out, d.samples[d.next:]...)
+	return append(out, d.samples[:d.next]...)
+}
+
+// Reset forgets the history, e.g. after a map change.
+func (d *DesyncDetector) Reset() {
+	clear(d.samples)
+	d.next = 0
+	d.full = false
+	d.over = 0
+}
@@ -0,0 +1,136 @@
+package world
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestServerWalkPositionAt(t *testing.T) {
+	start := time.Unix(1000, 0)
+	w := ServerWalk{StartX: 10, StartY: 10, EndX: 14, EndY: 10, Start: start, CellDuration: 100 * time.Millisecond}
+
+	tests := []struct {
+		name  string
+		after time.Duration
+		wantX float32
+	}{
+		{"before start", -time.Second, 10},
+		{"at start", 0, 10},
+		{"halfway", 200 * time.Millisecond, 12},
+		{"arrived", 400 * time.Millisecond, 14},
+		{"long after", time.Minute, 14},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, y := w.PositionAt(start.Add(tt.after))
+			if x != tt.wantX || y != 10 {
+				t.Errorf("PositionAt(+%v) = (%v, %v), want (%v, 10)", tt.after, x, y, tt.wantX)
+			}
+		})
+	}
+}
+
+func TestServerWalkDuration(t *testing.T) {
+	tests := []struct {
+		name       string
+		dx, dy     int
+		wantMillis int64
+	}{
+		{"standing", 0, 0, 0},
+		{"straight", 5, 0, 500},
+		{"diagonal", 3, 3, 420},
+		{"mixed", -2, 5, 580}, // 2 diagonal + 3 straight
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := ServerWalk{EndX: tt.dx, EndY: tt.dy, CellDuration: 100 * time.Millisecond}
+			if got := w.Duration().Milliseconds(); got != tt.wantMillis {
+				t.Errorf("Duration() = %dms, want %dms", got, tt.wantMillis)
+			}
+		})
+	}
+}
+
+func TestDesyncDetectorStrikes(t *testing.T) {
+	d := NewDesyncDetector()
+	d.Threshold = 2
+	d.Strikes = 3
+
+	base := time.Unix(1000, 0)
+	sample := func(i int, drift float32) PositionSample {
+		return PositionSample{Time: base.Add(time.Duration(i) * time.Second), ServerX: 10, ServerY: 10, PredictedX: 10 + drift, PredictedY: 10}
+	}
+
+	drifts := []float32{0, 3, 3, 0, 3, 3, 3, 3}
+	var events []int
+	for i, drift := range drifts {
+		if ev := d.Record(sample(i, drift)); ev != nil {
+			events = append(events, i)
+			if ev.Drift != 3 || !ev.Time.Equal(sample(i, drift).Time) {
+				t.Errorf("event %d: drift %v at %v", i, ev.Drift, ev.Time)
+			}
+			if len(ev.Samples) != i+1 || ev.Last() != sample(i, drift) {
+				t.Errorf("event %d: %d samples, last %+v", i, len(ev.Samples), ev.Last())
+			}
+		}
+	}
+	// A drift back under the threshold restarts the count, and so does an event
+	if len(events) != 1 || events[0] != 6 {
+		t.Errorf("events at samples %v, want [6]", events)
+	}
+}
+
+func TestDesyncDetectorHistoryRing(t *testing.T) {
+	d := NewDesyncDetector()
+	base := time.Unix(1000, 0)
+	total := DesyncHistorySize + 5
+	for i := range total {
+		d.Record(PositionSample{Time: base.Add(time.Duration(i) * time.Second)})
+	}
+
+	h := d.History()
+	if len(h) != DesyncHistorySize {
+		t.Fatalf("history has %d samples, want %d", len(h), DesyncHistorySize)
+	}
+	if want := base.Add(5 * time.Second); !h[0].Time.Equal(want) {
+		t.Errorf("oldest sample at %v, want %v", h[0].Time, want)
+	}
+	if want := base.Add(time.Duration(total-1) * time.Second); !h[len(h)-1].Time.Equal(want) {
+		t.Errorf("newest sample at %v, want %v", h[len(h)-1].Time, want)
+	}
+
+	d.Reset()
+	if len(d.History()) != 0 {
+		t.Errorf("history not empty after reset")
+	}
+}
+
+func TestDesyncEventWrite(t *testing.T) {
+	ev := &DesyncEvent{
+		Time:    time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC),
+		Map:     "prontera",
+		Drift:   4,
+		Samples: []PositionSample{{ServerX: 1, ServerY: 2, PredictedX: 5, PredictedY: 2}},
+	}
+
+	path, err := ev.Write(t.TempDir())
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading event: %v", err)
+	}
+
+	var got DesyncEvent
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("decoding event: %v", err)
+	}
+	if got.Map != "prontera" || got.Drift != 4 || len(got.Samples) != 1 || got.Samples[0].PredictedX != 5 {
+		t.Errorf("round trip mismatch: %+v", got)
+	}
+}
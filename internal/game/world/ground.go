@@ -0,0 +1,175 @@
+package world
+
+import (
+	"math"
+
+	"github.com/Faultbox/midgard-ro/internal/engine/terrain"
+	"github.com/Faultbox/midgard-ro/pkg/formats"
+)
+
+// groundCellSize is the side of a GAT cell in world units.
+const groundCellSize = float32(5.0)
+
+// levelGap is how far apart, in world units, the GAT surface and the
+// terrain under it have to be for a cell to have two levels. Closer, they
+// are the same ground and the surface alone is used.
+const levelGap = float32(10.0)
+
+// rayStep is how far apart RayHit samples the ray, in world units.
+const rayStep = groundCellSize / 4
+
+// Ground answers height queries on maps whose walkable areas overlap: a
+// bridge over a river, a platform or elevator cabin over the floor below.
+// The GAT holds one surface per cell, the one the server walks on, while
+// the terrain mesh may lie well under or over it. Such cells have two
+// levels, and an entity stands on the one closest to its current height
+// rather than snapping to the other floor.
+type Ground struct {
+	gat    *formats.GAT
+	width  int
+	height int
+
+	// alt is the terrain level of each cell, NaN where it's the GAT
+	// surface itself.
+	alt []float32
+
+	// minY and maxY bound every level, so RayHit only samples where the
+	// ray can meet one.
+	minY, maxY float32
+}
+
+// NewGround builds the levels of a map from its GAT and the height of its
+// terrain mesh at a world position. Returns nil if gat is nil.
+func NewGround(gat *formats.GAT, terrainHeight func(x, z float32) float32) *Ground {
+	if gat == nil {
+		return nil
+	}
+	g := &Ground{
+		gat:    gat,
+		width:  int(gat.Width),
+		height: int(gat.Height),
+		alt:    make([]float32, len(gat.Cells)),
+		minY:   float32(math.Inf(1)),
+		maxY:   float32(math.Inf(-1)),
+	}
+	nan := float32(math.NaN())
+	for y := range g.height {
+		for x := range g.width {
+			cx, cz := (float32(x)+0.5)*groundCellSize, (float32(y)+0.5)*groundCellSize
+			surface := g.Surface(cx, cz)
+			g.alt[y*g.width+x] = nan
+			g.bound(surface)
+			if terrainHeight == nil {
+				continue
+			}
+			if t := terrainHeight(cx, cz); abs32(t-surface) > levelGap {
+				g.alt[y*g.width+x] = t
+				g.bound(t)
+			}
+		}
+	}
+	return g
+}
+
+func (g *Ground) bound(y float32) {
+	g.minY = min(g.minY, y)
+	g.maxY = max(g.maxY, y)
+}
+
+// Surface returns the height of the GAT surface at a world position, the
+// level the server walks entities on.
+func (g *Ground) Surface(x, z float32) float32 {
+	return terrain.GetInterpolatedHeight(g.gat, x, z)
+}
+
+// Levels returns the heights of the levels at a world position: the GAT
+// surface, then the terrain under or over it if the cell has two.
+func (g *Ground) Levels(x, z float32) []float32 {
+	levels := []float32{g.Surface(x, z)}
+	if l, ok := g.altAt(x, z); ok {
+		levels = append(levels, l)
+	}
+	return levels
+}
+
+// HeightAt returns the height at a world position of the level closest to
+// near, an entity's current height.
+func (g *Ground) HeightAt(x, z, near float32) float32 {
+	surface := g.Surface(x, z)
+	if l, ok := g.altAt(x, z); ok && abs32(l-near) < abs32(surface-near) {
+		return l
+	}
+	return surface
+}
+
+// MultiLevel reports whether a cell has two levels.
+func (g *Ground) MultiLevel(cellX, cellY int) bool {
+	if g == nil || cellX < 0 || cellY < 0 || cellX >= g.width || cellY >= g.height {
+		return false
+	}
+	return !isNaN32(g.alt[cellY*g.width+cellX])
+}
+
+// altAt returns the terrain level of the cell at a world position, or
+// false if the cell has only the surface.
+func (g *Ground) altAt(x, z float32) (float32, bool) {
+	cx, cy := int(x/groundCellSize), int(z/groundCellSize)
+	if x < 0 || z < 0 || !g.MultiLevel(cx, cy) {
+		return 0, false
+	}
+	return g.alt[cy*g.width+cx], true
+}
+
+// RayHit returns where a ray from origin along dir (normalized) first
+// meets a level, so a click picks the floor the player sees rather than
+// the one under it. ok is false if the ray misses the map.
+func (g *Ground) RayHit(origin, dir [3]float32) (x, z float32, ok bool) {
+	if dir[1] >= 0 || g.minY > g.maxY {
+		return 0, 0, false
+	}
+	// Sample only between the highest and lowest level, from just above
+	tStart := max(0, (g.maxY+1-origin[1])/dir[1])
+	tEnd := (g.minY - 1 - origin[1]) / dir[1]
+	if tEnd < tStart {
+		return 0, 0, false
+	}
+
+	at := func(t float32) (float32, float32, float32) {
+		return origin[0] + dir[0]*t, origin[1] + dir[1]*t, origin[2] + dir[2]*t
+	}
+	prevT := tStart
+	_, prevY, _ := at(prevT)
+	for t := tStart + rayStep; ; t += rayStep {
+		t = min(t, tEnd)
+		px, py, pz := at(t)
+		if px >= 0 && pz >= 0 && int(px/groundCellSize) < g.width && int(pz/groundCellSize) < g.height {
+			// Of two levels crossed within a step, the upper one is seen
+			level := g.Surface(px, pz)
+			crossed := py <= level && prevY > level
+			if l, ok := g.altAt(px, pz); ok && py <= l && prevY > l && (!crossed || l > level) {
+				level, crossed = l, true
+			}
+			if crossed {
+				// Interpolate to where the ray crossed the level
+				hit := prevT + (prevY-level)/(prevY-py)*(t-prevT)
+				hx, _, hz := at(hit)
+				return hx, hz, true
+			}
+		}
+		if t >= tEnd {
+			return 0, 0, false
+		}
+		prevT, prevY = t, py
+	}
+}
+
+func abs32(v float32) float32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func isNaN32(v float32) bool {
+	return v != v
+}
@@ -0,0 +1,96 @@
+package world
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Faultbox/midgard-ro/pkg/formats"
+)
+
+// bridgeGround returns the ground of a 10x10 map with flat terrain at
+// height 0 and a bridge at height 20 over cells x 3-6.
+func bridgeGround() *Ground {
+	gat := &formats.GAT{Width: 10, Height: 10, Cells: make([]formats.GATCell, 100)}
+	for i := range gat.Cells {
+		gat.Cells[i].Type = formats.GATWalkable
+		if x := i % 10; x >= 3 && x <= 6 {
+			gat.Cells[i].Heights = [4]float32{-20, -20, -20, -20} // GAT heights grow downwards
+		}
+	}
+	return NewGround(gat, func(x, z float32) float32 { return 0 })
+}
+
+func TestGroundLevels(t *testing.T) {
+	g := bridgeGround()
+
+	if !g.MultiLevel(4, 4) {
+		t.Error("bridge cell has one level")
+	}
+	if g.MultiLevel(1, 4) || g.MultiLevel(-1, 0) {
+		t.Error("plain or outside cell has two levels")
+	}
+	if got := g.Levels(22.5, 22.5); len(got) != 2 || got[0] != 20 || got[1] != 0 {
+		t.Errorf("Levels on the bridge = %v, want [20 0]", got)
+	}
+
+	tests := []struct {
+		name       string
+		x, z, near float32
+		want       float32
+	}{
+		{"on the bridge", 22.5, 22.5, 18, 20},
+		{"under the bridge", 22.5, 22.5, 2, 0},
+		{"halfway prefers the surface", 22.5, 22.5, 10, 20},
+		{"plain ground", 7.5, 22.5, 20, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := g.HeightAt(tt.x, tt.z, tt.near); got != tt.want {
+				t.Errorf("HeightAt = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroundRayHit(t *testing.T) {
+	g := bridgeGround()
+	diag := func(dx, dy float32) [3]float32 {
+		n := float32(math.Sqrt(float64(dx*dx + dy*dy)))
+		return [3]float32{dx / n, dy / n, 0}
+	}
+
+	tests := []struct {
+		name   string
+		origin [3]float32
+		dir    [3]float32
+		wantX  float32
+		wantOK bool
+	}{
+		{"onto the bridge, not the river under it", [3]float32{0, 40, 22.5}, diag(1, -1), 20, true},
+		{"straight down on the bridge", [3]float32{22.5, 100, 22.5}, [3]float32{0, -1, 0}, 22.5, true},
+		{"plain ground before the bridge", [3]float32{5, 10, 22.5}, diag(1, -2), 10, true},
+		{"at the sky", [3]float32{5, 10, 22.5}, diag(1, 1), 0, false},
+		{"off the map", [3]float32{-100, 10, 22.5}, diag(-1, -1), 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, z, ok := g.RayHit(tt.origin, tt.dir)
+			if ok != tt.wantOK {
+				t.Fatalf("RayHit ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && (abs32(x-tt.wantX) > 0.01 || abs32(z-22.5) > 0.01) {
+				t.Errorf("RayHit = (%v, %v), want (%v, 22.5)", x, z, tt.wantX)
+			}
+		})
+	}
+}
+
+func TestNewGroundWithoutGAT(t *testing.T) {
+	g := NewGround(nil, nil)
+	if g != nil {
+		t.Error("NewGround(nil) isn't nil")
+	}
+	if g.MultiLevel(0, 0) {
+		t.Error("nil ground has two levels")
+	}
+}
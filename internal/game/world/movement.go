@@ -15,6 +15,11 @@ type MovementController struct {
 	path      [][2]int
 	pathIndex int
 
+	// Destination and walkability version the path was computed against,
+	// used to replan when a runtime obstacle blocks the remaining path
+	goalX, goalY int
+	pathVersion  uint64
+
 	// Movement state
 	IsFollowingPath bool
 }
@@ -59,6 +64,8 @@ func (mc *MovementController) MoveTo(destTileX, destTileY int) [][2]int {
 	}
 	mc.pathIndex = 0
 	mc.IsFollowingPath = true
+	mc.goalX, mc.goalY = destTileX, destTileY
+	mc.pathVersion = mc.pathFinder.Walkability().Version()
 
 	// Set first waypoint
 	mc.setNextWaypoint()
@@ -80,6 +87,11 @@ func (mc *MovementController) Update(deltaMs float32) {
 		return
 	}
 
+	// Walkability changed since the path was computed
+	if mc.IsFollowingPath && mc.pathVersion != mc.pathFinder.Walkability().Version() {
+		mc.revalidatePath()
+	}
+
 	// Check if we've reached current waypoint
 	if mc.IsFollowingPath && !mc.character.HasDestination && mc.pathIndex < len(mc.path) {
 		// Move to next waypoint
@@ -92,6 +104,27 @@ func (mc *MovementController) Update(deltaMs float32) {
 	}
 }
 
+// revalidatePath replans to the same destination if any remaining waypoint
+// (including the one currently being walked to) has become unwalkable.
+// Paths not crossing changed cells are kept as-is.
+func (mc *MovementController) revalidatePath() {
+	mc.pathVersion = mc.pathFinder.Walkability().Version()
+
+	start := mc.pathIndex - 1
+	if start < 0 {
+		start = 0
+	}
+	for _, cell := range mc.path[start:] {
+		if mc.pathFinder.IsWalkable(cell[0], cell[1]) {
+			continue
+		}
+		if mc.MoveTo(mc.goalX, mc.goalY) == nil {
+			mc.ClearPath()
+		}
+		return
+	}
+}
+
 // ClearPath stops the current path following.
 func (mc *MovementController) ClearPath() {
 	mc.path = nil
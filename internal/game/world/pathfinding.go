@@ -46,28 +46,44 @@ func (h *PathHeap) Pop() interface{} {
 }
 
 // PathFinder handles pathfinding on the game map.
+// Walkability is read through the mutable layer so runtime obstacles apply.
 type PathFinder struct {
-	gat    *formats.GAT
+	walk   *Walkability
 	width  int
 	height int
 }
 
-// NewPathFinder creates a new pathfinder.
+// NewPathFinder creates a new pathfinder with its own walkability layer over gat.
 func NewPathFinder(gat *formats.GAT) *PathFinder {
-	if gat == nil {
+	return NewPathFinderWithWalkability(NewWalkability(gat))
+}
+
+// NewPathFinderWithWalkability creates a pathfinder sharing an existing
+// walkability layer, so overrides applied by the game are seen immediately.
+func NewPathFinderWithWalkability(walk *Walkability) *PathFinder {
+	if walk == nil {
 		return nil
 	}
+	width, height := walk.Size()
 	return &PathFinder{
-		gat:    gat,
-		width:  int(gat.Width),
-		height: int(gat.Height),
+		walk:   walk,
+		width:  width,
+		height: height,
+	}
+}
+
+// Walkability returns the walkability layer used by the pathfinder.
+func (pf *PathFinder) Walkability() *Walkability {
+	if pf == nil {
+		return nil
 	}
+	return pf.walk
 }
 
 // FindPath finds a path from start to goal using A* algorithm.
 // Returns nil if no path exists.
 func (pf *PathFinder) FindPath(startX, startY, goalX, goalY int) [][2]int {
-	if pf == nil || pf.gat == nil {
+	if pf == nil || pf.walk == nil {
 		return nil
 	}
 
@@ -77,7 +93,7 @@ func (pf *PathFinder) FindPath(startX, startY, goalX, goalY int) [][2]int {
 	}
 
 	// Check if goal is walkable
-	if !pf.gat.IsWalkable(goalX, goalY) {
+	if !pf.walk.IsWalkable(goalX, goalY) {
 		return nil
 	}
 
@@ -136,7 +152,7 @@ func (pf *PathFinder) FindPath(startX, startY, goalX, goalY int) [][2]int {
 			nx, ny := current.X+dir[0], current.Y+dir[1]
 
 			// Skip if out of bounds or not walkable
-			if !pf.inBounds(nx, ny) || !pf.gat.IsWalkable(nx, ny) {
+			if !pf.inBounds(nx, ny) || !pf.walk.IsWalkable(nx, ny) {
 				continue
 			}
 
@@ -150,8 +166,8 @@ func (pf *PathFinder) FindPath(startX, startY, goalX, goalY int) [][2]int {
 			if i%2 == 1 { // Diagonal directions (SW, NW, NE, SE)
 				moveCost = diagonalCost
 				// For diagonal movement, both adjacent cells must be walkable
-				if !pf.gat.IsWalkable(current.X+dir[0], current.Y) ||
-					!pf.gat.IsWalkable(current.X, current.Y+dir[1]) {
+				if !pf.walk.IsWalkable(current.X+dir[0], current.Y) ||
+					!pf.walk.IsWalkable(current.X, current.Y+dir[1]) {
 					continue
 				}
 			} else {
@@ -189,13 +205,13 @@ func (pf *PathFinder) FindPath(startX, startY, goalX, goalY int) [][2]int {
 
 // IsWalkable checks if a tile is walkable.
 func (pf *PathFinder) IsWalkable(x, y int) bool {
-	if pf == nil || pf.gat == nil {
+	if pf == nil || pf.walk == nil {
 		return false
 	}
 	if !pf.inBounds(x, y) {
 		return false
 	}
-	return pf.gat.IsWalkable(x, y)
+	return pf.walk.IsWalkable(x, y)
 }
 
 // heuristic calculates the estimated distance using octile distance.
@@ -0,0 +1,158 @@
+// Package world provides game world functionality.
+package world
+
+import (
+	"sync"
+
+	"github.com/Faultbox/midgard-ro/pkg/formats"
+)
+
+// Walkability is a mutable walkability layer on top of the static GAT.
+// Server-driven changes (Ice Wall, closed doors, setcell scripts) are stored
+// as per-cell type overrides; clearing an override restores the GAT cell.
+type Walkability struct {
+	gat    *formats.GAT
+	width  int
+	height int
+
+	mu        sync.RWMutex
+	overrides map[int]formats.GATCellType
+	version   uint64 // Incremented on every change, used to invalidate paths
+}
+
+// NewWalkability creates a walkability layer over gat. Returns nil if gat is nil.
+func NewWalkability(gat *formats.GAT) *Walkability {
+	if gat == nil {
+		return nil
+	}
+	return &Walkability{
+		gat:       gat,
+		width:     int(gat.Width),
+		height:    int(gat.Height),
+		overrides: make(map[int]formats.GATCellType),
+	}
+}
+
+// Size returns the layer dimensions in cells.
+func (w *Walkability) Size() (width, height int) {
+	return w.width, w.height
+}
+
+// InBounds reports whether (x, y) is inside the map.
+func (w *Walkability) InBounds(x, y int) bool {
+	return x >= 0 && x < w.width && y >= 0 && y < w.height
+}
+
+// CellType returns the effective cell type, taking overrides into account.
+func (w *Walkability) CellType(x, y int) formats.GATCellType {
+	if !w.InBounds(x, y) {
+		return formats.GATBlocked
+	}
+	w.mu.RLock()
+	t, ok := w.overrides[y*w.width+x]
+	w.mu.RUnlock()
+	if ok {
+		return t
+	}
+	return w.gat.Cells[y*w.width+x].Type
+}
+
+// IsWalkable reports whether the cell is currently walkable.
+func (w *Walkability) IsWalkable(x, y int) bool {
+	if w == nil || !w.InBounds(x, y) {
+		return false
+	}
+	return w.CellType(x, y).IsWalkable()
+}
+
+// IsWater reports whether the cell is water, walkable or not.
+func (w *Walkability) IsWater(x, y int) bool {
+	return w != nil && w.InBounds(x, y) && w.CellType(x, y).IsWater()
+}
+
+// IsCliff reports whether the cell is a cliff or gap, which the server
+// never lets anyone walk onto.
+func (w *Walkability) IsCliff(x, y int) bool {
+	return w != nil && w.InBounds(x, y) && w.CellType(x, y).IsCliff()
+}
+
+// SetCellType overrides the type of a cell. Setting a cell back to its GAT
+// type removes the override. Returns false if the cell is out of bounds.
+func (w *Walkability) SetCellType(x, y int, t formats.GATCellType) bool {
+	if !w.InBounds(x, y) {
+		return false
+	}
+	idx := y*w.width + x
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	prev, had := w.overrides[idx]
+	if t == w.gat.Cells[idx].Type {
+		if !had {
+			return true
+		}
+		delete(w.overrides, idx)
+	} else {
+		if had && prev == t {
+			return true
+		}
+		w.overrides[idx] = t
+	}
+	w.version++
+	return true
+}
+
+// SetBlocked blocks or unblocks a cell, e.g. for an Ice Wall segment.
+// Unblocking restores the original GAT cell.
+func (w *Walkability) SetBlocked(x, y int, blocked bool) bool {
+	if !w.InBounds(x, y) {
+		return false
+	}
+	if blocked {
+		return w.SetCellType(x, y, formats.GATBlocked)
+	}
+	return w.ClearOverride(x, y)
+}
+
+// ClearOverride restores a cell to its GAT type.
+func (w *Walkability) ClearOverride(x, y int) bool {
+	if !w.InBounds(x, y) {
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	idx := y*w.width + x
+	if _, ok := w.overrides[idx]; ok {
+		delete(w.overrides, idx)
+		w.version++
+	}
+	return true
+}
+
+// Reset removes all overrides, e.g. when leaving the map.
+func (w *Walkability) Reset() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.overrides) > 0 {
+		w.overrides = make(map[int]formats.GATCellType)
+		w.version++
+	}
+}
+
+// OverrideCount returns the number of overridden cells.
+func (w *Walkability) OverrideCount() int {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return len(w.overrides)
+}
+
+// Version returns a counter that changes whenever walkability changes.
+// Callers holding paths compare it to detect stale paths.
+func (w *Walkability) Version() uint64 {
+	if w == nil {
+		return 0
+	}
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.version
+}
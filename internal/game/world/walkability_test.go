@@ -0,0 +1,163 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/Faultbox/midgard-ro/internal/game/entity"
+	"github.com/Faultbox/midgard-ro/pkg/formats"
+)
+
+func TestWalkability_Overrides(t *testing.T) {
+	w := NewWalkability(mockGAT([][2]int{{3, 3}}))
+
+	tests := []struct {
+		name     string
+		apply    func()
+		x, y     int
+		walkable bool
+		changed  bool
+	}{
+		{"gat walkable", func() {}, 1, 1, true, false},
+		{"gat blocked", func() {}, 3, 3, false, false},
+		{"block walkable cell", func() { w.SetBlocked(1, 1, true) }, 1, 1, false, true},
+		{"block again is no-op", func() { w.SetBlocked(1, 1, true) }, 1, 1, false, false},
+		{"unblock restores gat", func() { w.SetBlocked(1, 1, false) }, 1, 1, true, true},
+		{"open gat-blocked cell", func() { w.SetCellType(3, 3, formats.GATWalkable) }, 3, 3, true, true},
+		{"set to gat type drops override", func() { w.SetCellType(3, 3, formats.GATBlocked) }, 3, 3, false, true},
+		{"out of bounds", func() { w.SetBlocked(-1, 0, true) }, -1, 0, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := w.Version()
+			tt.apply()
+			if got := w.IsWalkable(tt.x, tt.y); got != tt.walkable {
+				t.Errorf("IsWalkable(%d,%d) = %v, want %v", tt.x, tt.y, got, tt.walkable)
+			}
+			if changed := w.Version() != before; changed != tt.changed {
+				t.Errorf("version changed = %v, want %v", changed, tt.changed)
+			}
+		})
+	}
+
+	if w.OverrideCount() != 0 {
+		t.Errorf("expected no overrides left, got %d", w.OverrideCount())
+	}
+
+	w.SetBlocked(0, 0, true)
+	w.SetBlocked(0, 1, true)
+	w.Reset()
+	if w.OverrideCount() != 0 || !w.IsWalkable(0, 0) {
+		t.Error("Reset did not restore GAT walkability")
+	}
+}
+
+func TestWalkability_Surfaces(t *testing.T) {
+	w := NewWalkability(mockGAT(nil))
+	w.SetCellType(1, 1, formats.GATWalkableWater)
+	w.SetCellType(2, 1, formats.GATWater)
+	w.SetCellType(3, 1, formats.GATSnipeable)
+	w.SetCellType(4, 1, formats.GATBlockedSnipe)
+
+	tests := []struct {
+		x, y            int
+		walkable, water bool
+		cliff           bool
+	}{
+		{0, 1, true, false, false},
+		{1, 1, true, true, false},
+		{2, 1, false, true, false},
+		{3, 1, false, false, true},
+		{4, 1, false, false, true},
+		{-1, 1, false, false, false},
+	}
+	for _, tt := range tests {
+		if got := w.IsWalkable(tt.x, tt.y); got != tt.walkable {
+			t.Errorf("IsWalkable(%d,%d) = %v, want %v", tt.x, tt.y, got, tt.walkable)
+		}
+		if got := w.IsWater(tt.x, tt.y); got != tt.water {
+			t.Errorf("IsWater(%d,%d) = %v, want %v", tt.x, tt.y, got, tt.water)
+		}
+		if got := w.IsCliff(tt.x, tt.y); got != tt.cliff {
+			t.Errorf("IsCliff(%d,%d) = %v, want %v", tt.x, tt.y, got, tt.cliff)
+		}
+	}
+
+	var none *Walkability
+	if none.IsWater(1, 1) || none.IsCliff(3, 1) {
+		t.Error("nil layer reported water or a cliff")
+	}
+}
+
+func TestPathFinder_RespectsOverrides(t *testing.T) {
+	// Wall on column 2 with a gap at (2,4)
+	gat := mockGAT([][2]int{{2, 0}, {2, 1}, {2, 2}, {2, 3}})
+	pf := NewPathFinder(gat)
+
+	if pf.FindPath(0, 0, 4, 0) == nil {
+		t.Fatal("expected path through the gap")
+	}
+
+	// Ice Wall closes the gap
+	pf.Walkability().SetBlocked(2, 4, true)
+	if path := pf.FindPath(0, 0, 4, 0); path != nil {
+		t.Errorf("expected no path with gap blocked, got %v", path)
+	}
+
+	// Door opens in the GAT wall
+	pf.Walkability().SetCellType(2, 0, formats.GATWalkable)
+	path := pf.FindPath(0, 0, 4, 0)
+	if path == nil {
+		t.Fatal("expected path through opened door")
+	}
+	if len(path) != 5 {
+		t.Errorf("expected straight 5-cell path, got %v", path)
+	}
+}
+
+func TestMovementController_ReplansAroundNewObstacle(t *testing.T) {
+	const tileSize = float32(1.0)
+	pf := NewPathFinder(mockGAT(nil))
+	char := entity.NewCharacter(0.5, 0, 2.5)
+	mc := NewMovementController(pf, char, tileSize)
+
+	if mc.MoveTo(4, 2) == nil {
+		t.Fatal("expected initial path")
+	}
+	pathHas := func(x, y int) bool {
+		for _, c := range mc.GetPath() {
+			if c[0] == x && c[1] == y {
+				return true
+			}
+		}
+		return false
+	}
+	if !pathHas(2, 2) {
+		t.Fatalf("expected straight path through (2,2), got %v", mc.GetPath())
+	}
+
+	// Obstacle off the path leaves it untouched
+	before := mc.GetPath()
+	pf.Walkability().SetBlocked(0, 4, true)
+	mc.Update(16)
+	if &mc.GetPath()[0] != &before[0] {
+		t.Error("path was replanned although no waypoint was affected")
+	}
+
+	// Obstacle on the path triggers a replan around it
+	pf.Walkability().SetBlocked(2, 2, true)
+	mc.Update(16)
+	if !mc.IsFollowingPath {
+		t.Fatal("expected controller to keep following a replanned path")
+	}
+	if pathHas(2, 2) {
+		t.Errorf("replanned path still crosses blocked cell: %v", mc.GetPath())
+	}
+
+	// Blocking the destination stops movement
+	pf.Walkability().SetBlocked(4, 2, true)
+	mc.Update(16)
+	if mc.IsFollowingPath {
+		t.Error("expected movement to stop when the destination is blocked")
+	}
+}
@@ -0,0 +1,223 @@
+package world
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Warp is a portal from a cell of one map to a cell of another.
+type Warp struct {
+	Map          string
+	X, Y         int
+	DestMap      string
+	DestX, DestY int
+}
+
+// WarpTable holds the warps between maps, by source map. The client has
+// no warp data of its own, so it's read from server scripts.
+type WarpTable struct {
+	byMap map[string][]Warp
+	count int
+}
+
+// NewWarpTable indexes warps by their source map.
+func NewWarpTable(warps []Warp) *WarpTable {
+	t := &WarpTable{byMap: make(map[string][]Warp)}
+	for _, w := range warps {
+		t.byMap[w.Map] = append(t.byMap[w.Map], w)
+	}
+	t.count = len(warps)
+	return t
+}
+
+// Len returns the number of warps in the table.
+func (t *WarpTable) Len() int {
+	if t == nil {
+		return 0
+	}
+	return t.count
+}
+
+// Exits returns the warps leaving mapName.
+func (t *WarpTable) Exits(mapName string) []Warp {
+	if t == nil {
+		return nil
+	}
+	return t.byMap[mapName]
+}
+
+// Route returns the warps to take from map from to map to, crossing as
+// few maps as possible, or nil if to can't be reached or is from itself.
+func (t *WarpTable) Route(from, to string) []Warp {
+	if t == nil || from == to {
+		return nil
+	}
+	via := map[string]Warp{from: {}} // Warp taken into each map reached
+	queue := []string{from}
+	for len(queue) > 0 {
+		m := queue[0]
+		queue = queue[1:]
+		for _, w := range t.byMap[m] {
+			if _, seen := via[w.DestMap]; seen {
+				continue
+			}
+			via[w.DestMap] = w
+			if w.DestMap == to {
+				return routeTo(via, from, to)
+			}
+			queue = append(queue, w.DestMap)
+		}
+	}
+	return nil
+}
+
+// routeTo walks the warps taken back from to.
+func routeTo(via map[string]Warp, from, to string) []Warp {
+	var route []Warp
+	for m := to; m != from; m = via[m].Map {
+		route = append(route, via[m])
+	}
+	for i, j := 0, len(route)-1; i < j; i, j = i+1, j-1 {
+		route[i], route[j] = route[j], route[i]
+	}
+	return route
+}
+
+// LoadWarpTable reads the warps of rAthena scripts: files, or directories
+// searched for .txt scripts.
+func LoadWarpTable(paths []string) (*WarpTable, error) {
+	var warps []Warp
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || (p != path && filepath.Ext(p) != ".txt") {
+				return nil
+			}
+			f, err := os.Open(p)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			ws, err := ParseWarps(f)
+			if err != nil {
+				return fmt.Errorf("%s: %w", p, err)
+			}
+			warps = append(warps, ws...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return NewWarpTable(warps), nil
+}
+
+// ParseWarps reads the warp NPCs of an rAthena script:
+//
+//	prontera,156,22,0	warp	prt001	1,1,prt_fild08,170,375
+//
+// Other script lines and comments are skipped, as are floating warps
+// ("-" source) that only exist to be duplicated.
+func ParseWarps(r io.Reader) ([]Warp, error) {
+	var warps []Warp
+	scanner := bufio.NewScanner(r)
+	inComment := false
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if inComment {
+			if _, rest, ok := strings.Cut(line, "*/"); ok {
+				inComment = false
+				line = strings.TrimSpace(rest)
+			} else {
+				continue
+			}
+		}
+		if strings.HasPrefix(line, "/*") {
+			inComment = !strings.Contains(line, "*/")
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) < 4 || (fields[1] != "warp" && fields[1] != "warp2") {
+			continue
+		}
+		if strings.HasPrefix(fields[0], "-") {
+			continue
+		}
+		w, err := parseWarp(fields[0], fields[3])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		warps = append(warps, w)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return warps, nil
+}
+
+// parseWarp parses a warp's "map,x,y,dir" source and "spanx,spany,map,x,y"
+// destination fields.
+func parseWarp(src, dest string) (Warp, error) {
+	s := strings.Split(src, ",")
+	d := strings.Split(dest, ",")
+	if len(s) < 3 || len(d) < 5 {
+		return Warp{}, fmt.Errorf("malformed warp %q -> %q", src, dest)
+	}
+	w := Warp{Map: s[0], DestMap: strings.TrimSpace(d[2])}
+	for _, v := range []struct {
+		dst *int
+		s   string
+	}{{&w.X, s[1]}, {&w.Y, s[2]}, {&w.DestX, d[3]}, {&w.DestY, d[4]}} {
+		n, err := strconv.Atoi(strings.TrimSpace(v.s))
+		if err != nil {
+			return Warp{}, fmt.Errorf("malformed warp %q -> %q: %w", src, dest, err)
+		}
+		*v.dst = n
+	}
+	return w, nil
+}
+
+// Town is a town on the world map.
+type Town struct {
+	Map   string
+	Label string
+	X, Y  float32 // Position on the client's world map image, 0-1 from top-left
+}
+
+// Towns lists the towns of the Rune-Midgarts and Schwarzwald world map,
+// sorted by label.
+var Towns = sortedTowns([]Town{
+	{"prontera", "Prontera", 0.51, 0.42},
+	{"izlude", "Izlude", 0.60, 0.44},
+	{"geffen", "Geffen", 0.33, 0.43},
+	{"payon", "Payon", 0.73, 0.60},
+	{"morocc", "Morroc", 0.42, 0.74},
+	{"alberta", "Alberta", 0.80, 0.73},
+	{"aldebaran", "Al De Baran", 0.50, 0.19},
+	{"comodo", "Comodo", 0.27, 0.88},
+	{"umbala", "Umbala", 0.13, 0.81},
+	{"yuno", "Juno", 0.51, 0.07},
+	{"xmas", "Lutie", 0.66, 0.10},
+	{"amatsu", "Amatsu", 0.93, 0.86},
+	{"gonryun", "Kunlun", 0.93, 0.32},
+	{"niflheim", "Niflheim", 0.07, 0.63},
+	{"einbroch", "Einbroch", 0.32, 0.07},
+})
+
+func sortedTowns(towns []Town) []Town {
+	sort.Slice(towns, func(i, j int) bool { return towns[i].Label < towns[j].Label })
+	return towns
+}
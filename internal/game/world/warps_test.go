@@ -0,0 +1,138 @@
+package world
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testWarpScript = `//===== rAthena Script =======================================
+//= Prontera Warp Script
+//============================================================
+/*
+prontera,1,1,0	warp	commented	1,1,geffen,1,1
+*/
+prontera,156,22,0	warp	prt001	1,1,prt_fild08,170,375
+prt_fild08,170,378,0	warp	prt002	1,1,prontera,156,26
+prt_fild08,350,212,0	warp2	prt003	2,2,izlude,15,89
+-	warp	floating	1,1,geffen,119,63
+prontera,150,150,4	script	Guide	105,{ end; }
+izlude,12,89,0	warp	izl001	1,1,prt_fild08,347,212
+`
+
+func TestParseWarps(t *testing.T) {
+	warps, err := ParseWarps(strings.NewReader(testWarpScript))
+	if err != nil {
+		t.Fatalf("ParseWarps: %v", err)
+	}
+	want := []Warp{
+		{Map: "prontera", X: 156, Y: 22, DestMap: "prt_fild08", DestX: 170, DestY: 375},
+		{Map: "prt_fild08", X: 170, Y: 378, DestMap: "prontera", DestX: 156, DestY: 26},
+		{Map: "prt_fild08", X: 350, Y: 212, DestMap: "izlude", DestX: 15, DestY: 89},
+		{Map: "izlude", X: 12, Y: 89, DestMap: "prt_fild08", DestX: 347, DestY: 212},
+	}
+	if !reflect.DeepEqual(warps, want) {
+		t.Errorf("ParseWarps =\n%+v\nwant\n%+v", warps, want)
+	}
+}
+
+func TestParseWarpsMalformed(t *testing.T) {
+	tests := []string{
+		"prontera,156\twarp\tprt001\t1,1,prt_fild08,170,375",
+		"prontera,156,22,0\twarp\tprt001\t1,1,prt_fild08",
+		"prontera,x,22,0\twarp\tprt001\t1,1,prt_fild08,170,375",
+	}
+	for _, line := range tests {
+		if _, err := ParseWarps(strings.NewReader(line)); err == nil {
+			t.Errorf("ParseWarps(%q) succeeded, want error", line)
+		}
+	}
+}
+
+func TestWarpTableRoute(t *testing.T) {
+	warps, err := ParseWarps(strings.NewReader(testWarpScript))
+	if err != nil {
+		t.Fatalf("ParseWarps: %v", err)
+	}
+	table := NewWarpTable(warps)
+
+	tests := []struct {
+		name     string
+		from, to string
+		want     []string // Maps entered
+	}{
+		{"neighbor", "prontera", "prt_fild08", []string{"prt_fild08"}},
+		{"two hops", "prontera", "izlude", []string{"prt_fild08", "izlude"}},
+		{"back", "izlude", "prontera", []string{"prt_fild08", "prontera"}},
+		{"same map", "prontera", "prontera", nil},
+		{"unreachable", "prontera", "geffen", nil},
+		{"unknown map", "payon", "prontera", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, w := range table.Route(tt.from, tt.to) {
+				got = append(got, w.DestMap)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Route(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWarpTableNil(t *testing.T) {
+	var table *WarpTable
+	if table.Len() != 0 || table.Exits("prontera") != nil || table.Route("prontera", "izlude") != nil {
+		t.Error("nil table should be empty")
+	}
+}
+
+func TestLoadWarpTable(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "fields")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		filepath.Join(dir, "cities.txt"): "prontera,156,22,0\twarp\tprt001\t1,1,prt_fild08,170,375\n",
+		filepath.Join(sub, "fields.txt"): "prt_fild08,170,378,0\twarp\tprt002\t1,1,prontera,156,26\n",
+		filepath.Join(dir, "README.md"):  "prontera,1,1,0\twarp\tdoc\t1,1,geffen,1,1\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	table, err := LoadWarpTable([]string{dir})
+	if err != nil {
+		t.Fatalf("LoadWarpTable: %v", err)
+	}
+	if table.Len() != 2 {
+		t.Errorf("Len = %d, want 2", table.Len())
+	}
+	if exits := table.Exits("prt_fild08"); len(exits) != 1 || exits[0].DestMap != "prontera" {
+		t.Errorf("Exits(prt_fild08) = %+v", exits)
+	}
+
+	if _, err := LoadWarpTable([]string{filepath.Join(dir, "missing")}); err == nil {
+		t.Error("LoadWarpTable of a missing path succeeded")
+	}
+}
+
+func TestTownsSorted(t *testing.T) {
+	for i := 1; i < len(Towns); i++ {
+		if Towns[i-1].Label >= Towns[i].Label {
+			t.Errorf("towns not sorted: %s before %s", Towns[i-1].Label, Towns[i].Label)
+		}
+	}
+	for _, town := range Towns {
+		if town.X < 0 || town.X > 1 || town.Y < 0 || town.Y > 1 {
+			t.Errorf("%s is off the world map: (%v, %v)", town.Map, town.X, town.Y)
+		}
+	}
+}
@@ -14,8 +14,9 @@ type Map struct {
 	Width  int
 	Height int
 
-	// Collision/walkability data
-	GAT *formats.GAT
+	// Collision/walkability data. Walk layers runtime overrides on the GAT.
+	GAT  *formats.GAT
+	Walk *Walkability
 
 	// Ground mesh data
 	GND *formats.GND
@@ -42,6 +43,9 @@ func (m *Map) Load() error {
 
 // IsWalkable checks if a position is walkable.
 func (m *Map) IsWalkable(x, y int) bool {
+	if m.Walk != nil {
+		return m.Walk.IsWalkable(x, y)
+	}
 	if m.GAT == nil {
 		return false
 	}
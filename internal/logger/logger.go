@@ -101,6 +101,19 @@ func InitWithFileConfig(level string, fileCfg FileConfig, consoleOutput bool) er
 		cores = append(cores, fileCore)
 	}
 
+	// In-memory ring of recent entries for crash reports
+	recentEncoder := zapcore.NewConsoleEncoder(zapcore.EncoderConfig{
+		TimeKey:          "time",
+		LevelKey:         "level",
+		MessageKey:       "msg",
+		CallerKey:        "caller",
+		EncodeTime:       zapcore.ISO8601TimeEncoder,
+		EncodeLevel:      zapcore.CapitalLevelEncoder,
+		EncodeCaller:     zapcore.ShortCallerEncoder,
+		ConsoleSeparator: " ",
+	})
+	cores = append(cores, zapcore.NewCore(recentEncoder, recent, lvl))
+
 	Log = zap.New(zapcore.NewTee(cores...), zap.AddCaller())
 	Sugar = Log.Sugar()
 
@@ -190,3 +190,34 @@ func TestDefaultFileConfig(t *testing.T) {
 		t.Error("expected Compress to be true")
 	}
 }
+
+func TestRecentLinesRing(t *testing.T) {
+	r := newRecentLines(3)
+	for _, line := range []string{"a\n", "b\n", "c\n", "d\n"} {
+		r.Write([]byte(line))
+	}
+
+	got := r.Snapshot()
+	want := []string{"b", "c", "d"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Snapshot() = %v, want %v", got, want)
+	}
+
+	r = newRecentLines(3)
+	r.Write([]byte("only\n"))
+	if got := r.Snapshot(); len(got) != 1 || got[0] != "only" {
+		t.Errorf("partial Snapshot() = %v", got)
+	}
+}
+
+func TestRecentLinesCapturesLogs(t *testing.T) {
+	if err := InitWithFileConfig("info", FileConfig{}, false); err != nil {
+		t.Fatalf("failed to init logger: %v", err)
+	}
+
+	Info("crash context marker")
+	lines := RecentLines()
+	if len(lines) == 0 || !strings.Contains(lines[len(lines)-1], "crash context marker") {
+		t.Errorf("expected last recent line to contain marker, got %v", lines)
+	}
+}
@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"strings"
+	"sync"
+)
+
+// RecentLineCount is how many log entries are kept in memory for crash reports.
+const RecentLineCount = 200
+
+// recentLines is a fixed-size ring of the most recent log entries.
+// It is installed as an extra zap core so crash reports can include
+// log context even when file logging is disabled.
+type recentLines struct {
+	mu    sync.Mutex
+	lines []string
+	next  int
+	full  bool
+}
+
+func newRecentLines(size int) *recentLines {
+	return &recentLines{lines: make([]string, size)}
+}
+
+// Write stores one encoded log entry. Zap writes each entry in a single call.
+func (r *recentLines) Write(p []byte) (int, error) {
+	line := strings.TrimRight(string(p), "\n")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lines[r.next] = line
+	r.next = (r.next + 1) % len(r.lines)
+	if r.next == 0 {
+		r.full = true
+	}
+	return len(p), nil
+}
+
+// Sync implements zapcore.WriteSyncer.
+func (r *recentLines) Sync() error {
+	return nil
+}
+
+// Snapshot returns the stored entries, oldest first.
+func (r *recentLines) Snapshot() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.full {
+		return append([]string(nil), r.lines[:r.next]...)
+	}
+	out := make([]string, 0, len(r.lines))
+	out = append(out, r.lines[r.next:]...)
+	return append(out, r.lines[:r.next]...)
+}
+
+var recent = newRecentLines(RecentLineCount)
+
+// RecentLines returns the last RecentLineCount log entries, oldest first.
+func RecentLines() []string {
+	return recent.Snapshot()
+}
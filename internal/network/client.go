@@ -13,6 +13,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/Faultbox/midgard-ro/internal/logger"
+	"github.com/Faultbox/midgard-ro/internal/network/packets"
 )
 
 // ServerType represents the type of server.
@@ -24,23 +25,31 @@ const (
 	ServerMap
 )
 
-// readBufferSize is the size of the read buffer.
+// readBufferSize is the initial size of the read buffer.
 const readBufferSize = 65536
 
-// Client handles network communication.
+// Client handles network communication. The connection's socket is read
+// and written on goroutines of its own (see connIO); Process hands what
+// was read to the handlers on the game loop.
 type Client struct {
 	conn     net.Conn
+	io       *connIO
 	mu       sync.Mutex
 	handlers map[uint16]PacketHandler
+	dialer   *Dialer
+	connHook ConnHook
 
 	// Connection state
 	connected  bool
 	serverType ServerType
 
-	// Read buffer for packet assembly
+	// Read buffer for packet assembly, grown when a read doesn't fit
 	readBuf    []byte
 	readOffset int
 
+	// Outgoing packets waiting for the next Flush
+	sendQueue *sendQueue
+
 	// Session info
 	accountID uint32
 	loginID1  uint32
@@ -63,10 +72,19 @@ type Client struct {
 	lastRecvID   uint16
 	lastRecvAt   time.Time
 	lastRecvLen  int
+	lastActiveAt time.Time
 	packetsSent  uint64
 	packetsRecvd uint64
 	bytesSent    uint64
 	bytesRecvd   uint64
+	sendWrites   uint64
+
+	// Framing: packets of unknown IDs and where their framing was lost
+	unknownPackets uint64
+	lastUnknownID  uint16
+	resyncs        uint64
+	droppedBytes   uint64
+	connResyncs    int // On this connection, for the packetver warning
 }
 
 // Stats is a point-in-time snapshot of network telemetry.
@@ -81,13 +99,42 @@ type Stats struct {
 	PacketsRecvd uint64
 	BytesSent    uint64
 	BytesRecvd   uint64
+
+	// LastActiveAt is when the last packet other than a keep-alive was
+	// sent: the player's last action, for telling an idle session apart.
+	LastActiveAt time.Time
+
+	// Send queue
+	Queued    int    // Packets waiting to be sent
+	Coalesced uint64 // Packets replaced by a newer one of the same type
+	Deferred  uint64 // Packets held back by a rate limit
+	Writes    uint64 // Socket writes; one flush sends all due packets
+
+	// Socket goroutines: reads waiting for the game loop, the most that
+	// waited at once, and how often either side had to wait for the other
+	InboundQueued int
+	InboundPeak   int
+	ReadStalls    uint64 // Reads held up by a game loop falling behind
+	WriteStalls   uint64 // Flushes refused by a socket falling behind
+
+	// Framing: packets of unknown IDs, skipped by their header length, and
+	// the times the stream had to be resynced, dropping bytes
+	UnknownPackets uint64
+	LastUnknownID  uint16
+	Resyncs        uint64
+	DroppedBytes   uint64
+
+	// VersionMismatch is set once framing is lost often enough on the
+	// connection that the server likely uses another packetver; see
+	// MismatchWarning
+	VersionMismatch bool
 }
 
 // Stats returns a snapshot of network telemetry counters.
 func (c *Client) Stats() Stats {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	return Stats{
+	st := Stats{
 		LastSentID:   c.lastSentID,
 		LastSentAt:   c.lastSentAt,
 		LastSentLen:  c.lastSentLen,
@@ -98,7 +145,25 @@ func (c *Client) Stats() Stats {
 		PacketsRecvd: c.packetsRecvd,
 		BytesSent:    c.bytesSent,
 		BytesRecvd:   c.bytesRecvd,
+		LastActiveAt: c.lastActiveAt,
+		Queued:       len(c.sendQueue.pending),
+		Coalesced:    c.sendQueue.coalesced,
+		Deferred:     c.sendQueue.deferred,
+		Writes:       c.sendWrites,
+
+		UnknownPackets:  c.unknownPackets,
+		LastUnknownID:   c.lastUnknownID,
+		Resyncs:         c.resyncs,
+		DroppedBytes:    c.droppedBytes,
+		VersionMismatch: c.connResyncs >= mismatchResyncs,
+	}
+	if c.io != nil {
+		st.InboundQueued = c.io.queued()
+		st.InboundPeak = int(c.io.stats.inboundPeak.Load())
+		st.ReadStalls = c.io.stats.readStalls.Load()
+		st.WriteStalls = c.io.stats.writeStalls.Load()
 	}
+	return st
 }
 
 // PacketHandler handles incoming packets.
@@ -107,11 +172,36 @@ type PacketHandler func(data []byte) error
 // New creates a new network client.
 func New() *Client {
 	return &Client{
-		handlers: make(map[uint16]PacketHandler),
-		readBuf:  make([]byte, readBufferSize),
+		handlers:  make(map[uint16]PacketHandler),
+		readBuf:   make([]byte, readBufferSize),
+		sendQueue: newSendQueue(DefaultSendPolicies),
+		dialer:    &Dialer{},
 	}
 }
 
+// SetDialer sets the dialer used for login/char/map connections.
+// Use it to route traffic through a proxy or change the connect timeout.
+func (c *Client) SetDialer(d *Dialer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if d == nil {
+		d = &Dialer{}
+	}
+	c.dialer = d
+}
+
+// ConnHook stands between the client and its server connections, to
+// record or replay a session: given the address and the dial the client
+// would make, it returns the connection to use.
+type ConnHook func(addr string, dial func() (net.Conn, error)) (net.Conn, error)
+
+// SetConnHook sets the hook connections are opened through, nil for none.
+func (c *Client) SetConnHook(hook ConnHook) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.connHook = hook
+}
+
 // Connect connects to a server.
 func (c *Client) Connect(host string, port int, serverType ServerType) error {
 	c.mu.Lock()
@@ -121,34 +211,87 @@ func (c *Client) Connect(host string, port int, serverType ServerType) error {
 		return fmt.Errorf("already connected")
 	}
 
-	addr := fmt.Sprintf("%s:%d", host, port)
-	logger.Info("connecting to server", zap.String("addr", addr), zap.Int("type", int(serverType)))
-
-	conn, err := net.DialTimeout("tcp", addr, 10*time.Second)
+	addr := JoinHostPort(host, port)
+	logger.Info("connecting to server",
+		zap.String("addr", addr),
+		zap.Int("type", int(serverType)),
+		zap.String("proxy", c.dialer.Proxy.Address))
+
+	dial := func() (net.Conn, error) { return c.dialer.Dial(addr) }
+	var conn net.Conn
+	var err error
+	if c.connHook != nil {
+		conn, err = c.connHook(addr, dial)
+	} else {
+		conn, err = dial()
+	}
 	if err != nil {
-		logger.Error("connection failed", zap.String("addr", addr), zap.Error(err))
+		c.logDialFailure(host, addr, err)
 		return fmt.Errorf("connecting to %s: %w", addr, err)
 	}
 
+	// Recorded and replayed connections are read on the game loop, so
+	// each read lands in the frame it belongs to
+	if c.io != nil {
+		c.io.close() // Lost without a Disconnect
+	}
 	c.conn = conn
+	c.io = newConnIO(conn, c.connHook != nil)
 	c.connected = true
 	c.serverType = serverType
 	c.readOffset = 0                      // Reset read buffer
 	c.charServerAccountIDReceived = false // Reset for new connection
+	c.connResyncs = 0
+	c.sendQueue.reset()
 
 	logger.Info("connected to server", zap.String("addr", addr))
 	return nil
 }
 
-// Disconnect closes the connection.
+// logDialFailure logs connection diagnostics to help debug proxy/IPv6 setups.
+func (c *Client) logDialFailure(host, addr string, err error) {
+	fields := []zap.Field{
+		zap.String("addr", addr),
+		zap.String("reason", DiagnoseDialError(err)),
+		zap.Error(err),
+	}
+
+	if c.dialer.Proxy.Enabled() {
+		fields = append(fields,
+			zap.String("proxyType", c.dialer.Proxy.Type),
+			zap.String("proxyAddr", c.dialer.Proxy.Address))
+	} else if net.ParseIP(host) == nil {
+		// Show what the hostname resolved to (IPv4 and/or IPv6)
+		ips, lookupErr := net.LookupIP(host)
+		if lookupErr != nil {
+			fields = append(fields, zap.NamedError("lookupError", lookupErr))
+		}
+		resolved := make([]string, 0, len(ips))
+		for _, ip := range ips {
+			resolved = append(resolved, ip.String())
+		}
+		fields = append(fields, zap.Strings("resolved", resolved))
+	}
+
+	logger.Error("connection failed", fields...)
+}
+
+// Disconnect sends the packets that are due and closes the connection
+// once they are written, stopping its socket goroutines. Packets still
+// held back by a rate limit are dropped.
 func (c *Client) Disconnect() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if c.conn != nil {
-		c.conn.Close()
-		c.conn = nil
+	if c.connected {
+		_ = c.flushLocked()
 	}
+	c.sendQueue.reset()
+	if c.io != nil {
+		c.io.close()
+		c.io = nil
+	}
+	c.conn = nil
 	c.connected = false
 }
 
@@ -164,7 +307,9 @@ func (c *Client) RegisterHandler(packetID uint16, handler PacketHandler) {
 	c.handlers[packetID] = handler
 }
 
-// Send sends a packet to the server.
+// Send queues a packet for the server. It goes out with the next Flush,
+// which Process does every frame. Redundant requests are coalesced and
+// rate-limited as DefaultSendPolicies describes.
 func (c *Client) Send(data []byte) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -172,26 +317,58 @@ func (c *Client) Send(data []byte) error {
 	if !c.connected {
 		return fmt.Errorf("not connected")
 	}
+	c.sendQueue.push(data)
+	return nil
+}
+
+// Flush hands the queued packets that are due to the writer, to go out in
+// a single write.
+func (c *Client) Flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	if len(data) >= 2 {
-		packetID := binary.LittleEndian.Uint16(data[0:2])
-		logger.Debug("sending packet", zap.String("id", fmt.Sprintf("0x%04X", packetID)), zap.Int("len", len(data)))
-		c.lastSentID = packetID
-		c.lastSentAt = time.Now()
-		c.lastSentLen = len(data)
+	if !c.connected {
+		return nil
+	}
+	return c.flushLocked()
+}
+
+func (c *Client) flushLocked() error {
+	now := time.Now()
+	due := c.sendQueue.take(now)
+	if len(due) == 0 {
+		return nil
 	}
 
-	n, err := c.conn.Write(data)
+	bufs := make(net.Buffers, len(due))
+	size := 0
+	for i, p := range due {
+		logger.Debug("sending packet", zap.String("id", fmt.Sprintf("0x%04X", p.id)), zap.Int("len", len(p.data)))
+		history.add(DirSend, p.id, len(p.data))
+		bufs[i] = p.data
+		size += len(p.data)
+		if !c.sendQueue.policies[p.id].KeepAlive {
+			c.lastActiveAt = now
+		}
+	}
+	last := due[len(due)-1]
+	c.lastSentID = last.id
+	c.lastSentAt = now
+	c.lastSentLen = len(last.data)
+
+	err := c.io.write(bufs)
+	c.sendWrites++
+	c.packetsSent += uint64(len(due))
+	c.bytesSent += uint64(size)
 	if err != nil {
 		logger.Error("send failed", zap.Error(err))
+		return fmt.Errorf("send: %w", err)
 	}
-	c.packetsSent++
-	c.bytesSent += uint64(n)
-	return err
+	return nil
 }
 
-// Process reads and processes incoming packets.
-// Should be called regularly in the game loop.
+// Process flushes queued packets, then processes the incoming ones read
+// since the last call. Should be called regularly in the game loop.
 func (c *Client) Process() (err error) {
 	// Recover from any panics in packet processing to prevent crashes
 	defer func() {
@@ -206,36 +383,32 @@ func (c *Client) Process() (err error) {
 	}()
 
 	c.mu.Lock()
-	if !c.connected || c.conn == nil {
+	if !c.connected || c.io == nil {
 		c.mu.Unlock()
 		return nil
 	}
-	conn := c.conn
+	cio := c.io
+	flushErr := c.flushLocked()
 	c.mu.Unlock()
+	if flushErr != nil {
+		return flushErr
+	}
 
-	// Set short read deadline for non-blocking behavior
-	_ = conn.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
-
-	// Read available data
-	n, err := conn.Read(c.readBuf[c.readOffset:])
-	if err != nil {
-		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
-			// No data available, that's fine
-			return nil
-		}
-		if err == io.EOF {
-			c.mu.Lock()
+	// Take what was read; the packets read before a failure are still
+	// handled, then the failure is reported
+	buf, readErr := cio.drain(c.readBuf[:c.readOffset])
+	if n := len(buf) - c.readOffset; n > 0 {
+		logger.Debug("received raw data", zap.Int("bytes", n), zap.String("hex", fmt.Sprintf("%X", buf[c.readOffset:c.readOffset+min(n, 32)])))
+	}
+	c.readBuf, c.readOffset = buf, len(buf)
+	if readErr != nil {
+		// The connection is unusable, unless a handler already replaced it
+		c.mu.Lock()
+		if c.io == cio {
 			c.connected = false
-			c.mu.Unlock()
-			return fmt.Errorf("connection closed by server")
 		}
-		return fmt.Errorf("read error: %w", err)
-	}
-
-	if n > 0 {
-		logger.Debug("received raw data", zap.Int("bytes", n), zap.String("hex", fmt.Sprintf("%X", c.readBuf[c.readOffset:c.readOffset+min(n, 32)])))
+		c.mu.Unlock()
 	}
-	c.readOffset += n
 
 	// Process complete packets
 	for c.readOffset >= 2 {
@@ -258,21 +431,22 @@ func (c *Client) Process() (err error) {
 		packetID := binary.LittleEndian.Uint16(c.readBuf[0:2])
 
 		// Determine packet length
-		packetLen := c.getPacketLength(packetID, c.readBuf[:c.readOffset])
+		packetLen, how := c.frameLength(c.readBuf[:c.readOffset])
 		logger.Debug("parsing packet", zap.String("id", fmt.Sprintf("0x%04X", packetID)), zap.Int("len", packetLen), zap.Int("available", c.readOffset))
-		if packetLen == 0 {
-			// Unknown packet - if we have less than 32 bytes of unknown data,
-			// it's likely garbage from a previous packet, so flush it
-			if c.readOffset < 32 {
-				logger.Debug("flushing unknown packet data", zap.Int("bytes", c.readOffset))
-				c.readOffset = 0
-				break
-			}
-			// Otherwise, skip 2 bytes and try again
-			copy(c.readBuf, c.readBuf[2:c.readOffset])
-			c.readOffset -= 2
+		if how == short {
+			break
+		}
+		if how == lost {
+			c.resyncStream(packetID)
 			continue
 		}
+		if how == guessed && c.readOffset >= packetLen {
+			c.mu.Lock()
+			c.unknownPackets++
+			c.lastUnknownID = packetID
+			c.mu.Unlock()
+			logger.Debug("skipping unknown packet", zap.String("id", fmt.Sprintf("0x%04X", packetID)), zap.Int("len", packetLen))
+		}
 
 		if c.readOffset < packetLen {
 			// Not enough data yet
@@ -296,6 +470,7 @@ func (c *Client) Process() (err error) {
 		c.packetsRecvd++
 		c.bytesRecvd += uint64(packetLen)
 		c.mu.Unlock()
+		history.add(DirRecv, packetID, packetLen)
 		if handler, ok := c.handlers[packetID]; ok {
 			if err := handler(packetData); err != nil {
 				logger.Error("packet handler error", zap.String("id", fmt.Sprintf("0x%04X", packetID)), zap.Error(err))
@@ -304,13 +479,54 @@ func (c *Client) Process() (err error) {
 		} else {
 			logger.Debug("no handler for packet", zap.String("id", fmt.Sprintf("0x%04X", packetID)))
 		}
+		if !c.isCurrent(cio) {
+			// The handler disconnected or moved to another server; the
+			// rest read, and why reading stopped, belong to the old one
+			return nil
+		}
 	}
 
+	switch {
+	case readErr == io.EOF:
+		return fmt.Errorf("connection closed by server")
+	case readErr != nil:
+		return fmt.Errorf("read error: %w", readErr)
+	}
 	return nil
 }
 
-// getPacketLength returns the length of a packet based on its ID.
-// Returns 0 for unknown packets.
+// isCurrent reports whether cio is still the connection in use.
+func (c *Client) isCurrent(cio *connIO) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.io == cio
+}
+
+// resyncStream drops the bytes read up to the next likely packet start,
+// framing having been lost at a packet of id. Losing it repeatedly on a
+// connection logs the packetver warning.
+func (c *Client) resyncStream(id uint16) {
+	n := c.resync(c.readBuf[:c.readOffset])
+	copy(c.readBuf, c.readBuf[n:c.readOffset])
+	c.readOffset -= n
+
+	c.mu.Lock()
+	c.resyncs++
+	c.droppedBytes += uint64(n)
+	c.connResyncs++
+	warn := c.connResyncs == mismatchResyncs
+	c.mu.Unlock()
+
+	logger.Debug("packet framing lost", zap.String("id", fmt.Sprintf("0x%04X", id)), zap.Int("dropped", n))
+	if warn {
+		logger.Warn("packet framing keeps failing, server packetver may not match",
+			zap.Int("packetver", packets.PacketVer),
+			zap.Int("resyncs", mismatchResyncs))
+	}
+}
+
+// getPacketLength returns the length of a handled packet based on its ID.
+// Returns 0 for other packets, or when data is too short to tell.
 func (c *Client) getPacketLength(packetID uint16, data []byte) int {
 	// Variable-length packets have length in bytes 2-4
 	switch packetID {
@@ -331,6 +547,8 @@ func (c *Client) getPacketLength(packetID uint16, data []byte) int {
 		return 3
 	case 0x083E: // AC_REFUSE_LOGIN2 (modern)
 		return 26
+	case 0x0B2C: // AC_LOGIN_QUEUE
+		return 10
 
 	// Character server packets
 	case 0x006B: // HC_ACCEPT_ENTER (variable)
@@ -346,6 +564,8 @@ func (c *Client) getPacketLength(packetID uint16, data []byte) int {
 		return 28
 	case 0x0AC5: // HC_NOTIFY_ZONESVR2 (modern rAthena)
 		return 28
+	case 0x08B9: // HC_SECOND_PASSWD_LOGIN
+		return 12
 
 	// Map server packets
 	case 0x0073: // ZC_ACCEPT_ENTER
@@ -360,28 +580,150 @@ func (c *Client) getPacketLength(packetID uint16, data []byte) int {
 		return 54
 	case 0x007B: // ZC_NOTIFY_MOVEENTRY
 		return 60
+	case 0x09FD, 0x09FE, 0x09FF: // ZC_NOTIFY_MOVEENTRY11, NEWENTRY11, STANDENTRY11 (variable)
+		if len(data) >= 4 {
+			return int(binary.LittleEndian.Uint16(data[2:4]))
+		}
+		return 0
+	case 0x0080: // ZC_NOTIFY_VANISH
+		return 7
+	case 0x0229: // ZC_STATE_CHANGE3
+		return 15
+	case 0x0196: // ZC_MSG_STATE_CHANGE
+		return 9
+	case 0x0983: // ZC_MSG_STATE_CHANGE3
+		return 29
+	case 0x01A4: // ZC_CHANGESTATE_PET
+		return 11
+	case 0x0A30: // ZC_ACK_REQNAMEALL2
+		return 106
 	case 0x0087: // ZC_NOTIFY_PLAYERMOVE (own walk-OK)
 		return 12
 	case 0x008A: // ZC_NOTIFY_ACT
 		return 29
+	case 0x08C8: // ZC_NOTIFY_ACT2
+		return 34
+	case 0x01DE: // ZC_NOTIFY_SKILL2
+		return 33
 	case 0x0091: // ZC_NPCACK_MAPMOVE
 		return 22
+	case 0x0192: // ZC_CHANGE_CELLTYPE
+		return 24
+	case 0x00B0: // ZC_PAR_CHANGE
+		return 8
+	case 0x00B1: // ZC_LONGPAR_CHANGE
+		return 8
+	case 0x0ACB: // ZC_LONGPAR_CHANGE2
+		return 12
+	case 0x018B: // ZC_ACK_REQ_DISCONNECT
+		return 4
+	case 0x00B3: // ZC_RESTART_ACK
+		return 3
+	case 0x099B: // ZC_MAPPROPERTY_R2
+		return 8
+	case 0x019A: // ZC_NOTIFY_RANKING
+		return 14
+	case 0x009A, 0x01C3: // ZC_BROADCAST, ZC_BROADCAST2 (variable)
+		if len(data) >= 4 {
+			return int(binary.LittleEndian.Uint16(data[2:4]))
+		}
+		return 0
+	case 0x008D, 0x008E: // ZC_NOTIFY_CHAT, ZC_NOTIFY_PLAYERCHAT (variable)
+		if len(data) >= 4 {
+			return int(binary.LittleEndian.Uint16(data[2:4]))
+		}
+		return 0
+
+	// Items
+	case 0x0B09: // ZC_INVENTORY_ITEMLIST_NORMAL (variable, often > 1KB)
+		if len(data) >= 4 {
+			return int(binary.LittleEndian.Uint16(data[2:4]))
+		}
+		return 0
+	case 0x0B39: // ZC_INVENTORY_ITEMLIST_EQUIP (variable)
+		if len(data) >= 4 {
+			return int(binary.LittleEndian.Uint16(data[2:4]))
+		}
+		return 0
+	case 0x00AF: // ZC_ITEM_THROW_ACK
+		return 6
+	case 0x0999: // ZC_REQ_WEAR_EQUIP_ACK
+		return 9
+	case 0x099A: // ZC_REQ_TAKEOFF_EQUIP_ACK
+		return 9
+	case 0x0ADD: // ZC_ITEM_FALL_ENTRY
+		return 24
+	case 0x00A1: // ZC_ITEM_DISAPPEAR
+		return 6
+	case 0x0B41: // ZC_ITEM_PICKUP_ACK
+		return 70
+
+	// Player requests
+	case 0x02C6: // ZC_PARTY_JOIN_REQ
+		return 30
+	case 0x016A: // ZC_REQ_JOIN_GUILD
+		return 30
+	case 0x01F4: // ZC_REQ_EXCHANGE_ITEM2
+		return 32
+
+	// Friends and guild
+	case 0x0206: // ZC_FRIENDS_STATE
+		return 35
+	case 0x01F2: // ZC_UPDATE_CHARSTAT2
+		return 20
+
+	// Quests
+	case 0x09FA: // ZC_UPDATE_MISSION_HUNT (variable)
+		if len(data) >= 4 {
+			return int(binary.LittleEndian.Uint16(data[2:4]))
+		}
+		return 0
+
+	// Chat rooms
+	case 0x00D7, 0x00DF: // ZC_ROOM_NEWENTRY, ZC_CHANGE_CHATROOM (variable)
+		if len(data) >= 4 {
+			return int(binary.LittleEndian.Uint16(data[2:4]))
+		}
+		return 0
+	case 0x00D8: // ZC_DESTROY_ROOM
+		return 6
+
+	// Vending
+	case 0x0131: // ZC_STORE_ENTRY
+		return 86
+	case 0x0132: // ZC_DISAPPEAR_ENTRY
+		return 6
+	case 0x0135: // ZC_PC_PURCHASE_RESULT_FROMMC
+		return 7
+	case 0x0800: // ZC_PC_PURCHASE_ITEMLIST_FROMMC2 (variable)
+		if len(data) >= 4 {
+			return int(binary.LittleEndian.Uint16(data[2:4]))
+		}
+		return 0
+
+	// NPCs
+	case 0x00B4: // ZC_SAY_DIALOG (variable)
+		if len(data) >= 4 {
+			return int(binary.LittleEndian.Uint16(data[2:4]))
+		}
+		return 0
+	case 0x00B5: // ZC_WAIT_DIALOG
+		return 6
+	case 0x01B3: // ZC_SHOW_IMAGE2
+		return 67
+	case 0x00B6: // ZC_CLOSE_DIALOG
+		return 6
 
 	// Keep-alive
 	case 0x007F: // ZC_NOTIFY_TIME (server reply to CZ_REQUEST_TIME)
 		return 6
 
+	// Informational commands
+	case 0x00C2: // ZC_USER_COUNT
+		return 6
+
 	default:
-		// For unknown packets, try to read length from packet header
-		// Only do this if length seems reasonable
-		if len(data) >= 4 {
-			possibleLen := int(binary.LittleEndian.Uint16(data[2:4]))
-			// Sanity check: length should be reasonable (4 bytes min, 1KB max for unknown packets)
-			// Known variable packets are explicitly handled above
-			if possibleLen >= 4 && possibleLen <= 1024 {
-				return possibleLen
-			}
-		}
+		// Unhandled packets are framed by frameLength
 		return 0
 	}
 }
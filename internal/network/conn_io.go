@@ -0,0 +1,206 @@
+package network
+
+import (
+	"errors"
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+// Socket goroutine tuning. Reads wait in a queue of inboundQueueSize
+// chunks for the game loop to drain; once it is full the reader stops
+// reading, and TCP flow control holds the server back. Flushes wait in a
+// queue of outboundQueueSize batches for the writer; one past a full queue
+// fails the connection rather than block the game loop.
+const (
+	inboundQueueSize  = 256
+	outboundQueueSize = 64
+	readChunkSize     = 16 * 1024
+
+	// writeTimeout fails a write the server doesn't take in time, so a
+	// dead connection can't hold flushes up for good.
+	writeTimeout = 5 * time.Second
+
+	// inlineReadWait is how long a read on the game loop waits for data.
+	inlineReadWait = 10 * time.Millisecond
+)
+
+// connIO moves a connection's bytes. Reads and writes run on goroutines of
+// their own, so a slow server can't stall a frame and a slow frame can't
+// stall the socket; the game loop drains what was read once per frame.
+//
+// Inline connections read and write on the game loop instead, a read per
+// drain, as the client did before: recorded and replayed sessions depend
+// on the frame each read happened in.
+type connIO struct {
+	conn   net.Conn
+	inline bool
+
+	inbound    chan []byte      // Chunks read, closed when reading fails
+	outbound   chan net.Buffers // Batches to write, closed by close
+	done       chan struct{}    // Closed to stop a reader waiting on a full queue
+	readerDone chan struct{}
+	writerDone chan struct{}
+	closed     bool
+
+	readErr  error                 // Why reading stopped, set before inbound closes
+	writeErr atomic.Pointer[error] // First write failure
+	readBuf  []byte                // Read buffer of inline connections
+	stats    connIOStats
+}
+
+// connIOStats counts the socket goroutines' backpressure.
+type connIOStats struct {
+	inboundPeak atomic.Int64  // Most chunks waiting at once
+	readStalls  atomic.Uint64 // Reads held up by a full inbound queue
+	writeStalls atomic.Uint64 // Flushes refused by a full outbound queue
+}
+
+// newConnIO starts moving a connection's bytes.
+func newConnIO(conn net.Conn, inline bool) *connIO {
+	c := &connIO{conn: conn, inline: inline}
+	if inline {
+		c.readBuf = make([]byte, readBufferSize)
+		return c
+	}
+	c.inbound = make(chan []byte, inboundQueueSize)
+	c.outbound = make(chan net.Buffers, outboundQueueSize)
+	c.done = make(chan struct{})
+	c.readerDone = make(chan struct{})
+	c.writerDone = make(chan struct{})
+	go c.readLoop()
+	go c.writeLoop()
+	return c
+}
+
+// readLoop reads until the connection fails or is closed, queueing what
+// it reads.
+func (c *connIO) readLoop() {
+	defer close(c.readerDone)
+	defer close(c.inbound)
+	for {
+		buf := make([]byte, readChunkSize)
+		n, err := c.conn.Read(buf)
+		if n > 0 {
+			select {
+			case c.inbound <- buf[:n]:
+			default:
+				c.stats.readStalls.Add(1)
+				select {
+				case c.inbound <- buf[:n]:
+				case <-c.done:
+					return
+				}
+			}
+			if queued := int64(len(c.inbound)); queued > c.stats.inboundPeak.Load() {
+				c.stats.inboundPeak.Store(queued)
+			}
+		}
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+			c.readErr = err
+			return
+		}
+	}
+}
+
+// writeLoop writes the batches handed to it in order. After a failure the
+// rest are dropped, so flushes never wait on a dead connection.
+func (c *connIO) writeLoop() {
+	defer close(c.writerDone)
+	for bufs := range c.outbound {
+		if c.writeErr.Load() != nil {
+			continue
+		}
+		_ = c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		if _, err := bufs.WriteTo(c.conn); err != nil {
+			c.writeErr.Store(&err)
+		}
+	}
+}
+
+// errWriteBacklog fails a connection whose server stopped taking data
+// for as long as the outbound queue took to fill.
+var errWriteBacklog = errors.New("server not taking data: outbound queue full")
+
+// write hands a batch of packets to the writer, or writes it at once on
+// inline connections. The error is that of an earlier write, since a
+// handed-over batch is written later, or errWriteBacklog when the queue
+// is full; the batch is dropped then, and so are all after it.
+func (c *connIO) write(bufs net.Buffers) error {
+	if c.inline {
+		_, err := bufs.WriteTo(c.conn)
+		return err
+	}
+	if err := c.writeErr.Load(); err != nil {
+		return *err
+	}
+	select {
+	case c.outbound <- bufs:
+		return nil
+	default:
+		c.stats.writeStalls.Add(1)
+		err := errWriteBacklog
+		c.writeErr.CompareAndSwap(nil, &err)
+		return err
+	}
+}
+
+// drain appends what was read since the last drain to buf. The error is
+// why reading stopped, once everything read before has been drained.
+func (c *connIO) drain(buf []byte) ([]byte, error) {
+	if c.inline {
+		_ = c.conn.SetReadDeadline(time.Now().Add(inlineReadWait))
+		n, err := c.conn.Read(c.readBuf)
+		buf = append(buf, c.readBuf[:n]...)
+		var netErr net.Error
+		if err != nil && errors.As(err, &netErr) && netErr.Timeout() {
+			err = nil
+		}
+		return buf, err
+	}
+	for {
+		select {
+		case chunk, ok := <-c.inbound:
+			if !ok {
+				return buf, c.readErr
+			}
+			buf = append(buf, chunk...)
+		default:
+			return buf, nil
+		}
+	}
+}
+
+// close writes the batches handed over, giving up after writeTimeout,
+// then closes the connection and waits for the goroutines to stop.
+func (c *connIO) close() {
+	if c.closed {
+		return
+	}
+	c.closed = true
+	if c.inline {
+		c.conn.Close()
+		return
+	}
+	close(c.outbound)
+	select {
+	case <-c.writerDone:
+	case <-time.After(writeTimeout):
+	}
+	close(c.done)
+	c.conn.Close()
+	<-c.writerDone
+	<-c.readerDone
+}
+
+// queued returns the number of chunks read and not yet drained.
+func (c *connIO) queued() int {
+	if c.inline {
+		return 0
+	}
+	return len(c.inbound)
+}
@@ -0,0 +1,233 @@
+package network
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Faultbox/midgard-ro/internal/logger"
+)
+
+// drainUntil drains c until it has read want bytes or reading failed.
+func drainUntil(t *testing.T, c *connIO, want int) ([]byte, error) {
+	t.Helper()
+	var buf []byte
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		var err error
+		if buf, err = c.drain(buf); err != nil || len(buf) >= want {
+			return buf, err
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("drained %d bytes, want %d", len(buf), want)
+	return nil, nil
+}
+
+func TestConnIOReadsAndWrites(t *testing.T) {
+	client, server := net.Pipe()
+	c := newConnIO(client, false)
+
+	go func() {
+		server.Write([]byte{0xB6, 0x00, 1, 2})
+		server.Write([]byte{3, 4})
+	}()
+	buf, err := drainUntil(t, c, 6)
+	if err != nil || !bytes.Equal(buf, []byte{0xB6, 0x00, 1, 2, 3, 4}) {
+		t.Fatalf("drain = %X, %v", buf, err)
+	}
+
+	if err := c.write(net.Buffers{[]byte{0x60, 0x03}, []byte{9}}); err != nil {
+		t.Fatal(err)
+	}
+	got := make([]byte, 3)
+	if _, err := io.ReadFull(server, got); err != nil || !bytes.Equal(got, []byte{0x60, 0x03, 9}) {
+		t.Fatalf("server read %X, %v", got, err)
+	}
+
+	// What was read before the server hung up is drained first
+	go func() {
+		server.Write([]byte{7})
+		server.Close()
+	}()
+	buf, err = drainUntil(t, c, 1)
+	for err == nil {
+		buf, err = drainUntil(t, c, len(buf)+1)
+	}
+	if err != io.EOF || !bytes.Equal(buf, []byte{7}) {
+		t.Errorf("drain after hang-up = %X, %v; want 07, EOF", buf, err)
+	}
+	c.close()
+}
+
+func TestConnIOBackpressure(t *testing.T) {
+	client, server := net.Pipe()
+	c := newConnIO(client, false)
+	defer server.Close()
+
+	// The game loop doesn't drain: the reader stalls once the queue is
+	// full, and the server's writes block behind it
+	written := make(chan int)
+	go func() {
+		n := 0
+		for ; n < inboundQueueSize+10; n++ {
+			server.SetWriteDeadline(time.Now().Add(200 * time.Millisecond))
+			if _, err := server.Write([]byte(strconv.Itoa(n % 10))); err != nil {
+				break
+			}
+		}
+		written <- n
+	}()
+	if n := <-written; n > inboundQueueSize+1 {
+		t.Errorf("server wrote %d chunks past a full queue of %d", n, inboundQueueSize)
+	}
+	if c.stats.readStalls.Load() == 0 || c.queued() != inboundQueueSize || c.stats.inboundPeak.Load() != inboundQueueSize {
+		t.Errorf("stalls %d, queued %d, peak %d", c.stats.readStalls.Load(), c.queued(), c.stats.inboundPeak.Load())
+	}
+
+	// Closing stops the stalled reader
+	closed := make(chan struct{})
+	go func() {
+		c.close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("close hung on a stalled reader")
+	}
+}
+
+func TestConnIOWriteBacklog(t *testing.T) {
+	client, server := net.Pipe()
+	c := newConnIO(client, false)
+
+	// The server doesn't read: the writer blocks on the first batch, the
+	// queue fills behind it, and the next write fails instead of blocking
+	var err error
+	writes := 0
+	for ; err == nil && writes < outboundQueueSize+2; writes++ {
+		err = c.write(net.Buffers{[]byte{0x60, 0x03}})
+	}
+	if err != errWriteBacklog || c.stats.writeStalls.Load() != 1 {
+		t.Fatalf("write %d = %v, stalls %d; want the backlog error", writes, err, c.stats.writeStalls.Load())
+	}
+	if err := c.write(net.Buffers{[]byte{9}}); err != errWriteBacklog {
+		t.Errorf("write after the backlog = %v", err)
+	}
+	server.Close()
+	c.close()
+}
+
+func TestConnIOCloseWritesPending(t *testing.T) {
+	client, server := net.Pipe()
+	c := newConnIO(client, false)
+
+	got := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(server)
+		got <- data
+	}()
+	c.write(net.Buffers{[]byte("bye")})
+	c.close()
+	if data := <-got; string(data) != "bye" {
+		t.Errorf("server read %q before the close, want %q", data, "bye")
+	}
+}
+
+func TestClientProcess(t *testing.T) {
+	if err := logger.InitWithFileConfig("error", logger.FileConfig{}, false); err != nil {
+		t.Fatal(err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("no loopback:", err)
+	}
+	defer ln.Close()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, _ := ln.Accept()
+		accepted <- conn
+	}()
+
+	c := New()
+	addr := ln.Addr().(*net.TCPAddr)
+	if err := c.Connect("127.0.0.1", addr.Port, ServerMap); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Disconnect()
+	server := <-accepted
+
+	var handled []byte
+	c.RegisterHandler(0x00B6, func(data []byte) error {
+		handled = data
+		return nil
+	})
+	server.Write([]byte{0xB6, 0x00, 0x5C, 0x00})
+	server.Write([]byte{0x01, 0x00})
+	for deadline := time.Now().Add(2 * time.Second); handled == nil && time.Now().Before(deadline); {
+		if err := c.Process(); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if !bytes.Equal(handled, []byte{0xB6, 0x00, 0x5C, 0x00, 0x01, 0x00}) {
+		t.Fatalf("handled %X", handled)
+	}
+
+	server.Close()
+	for deadline := time.Now().Add(2 * time.Second); c.IsConnected() && time.Now().Before(deadline); {
+		if err = c.Process(); err != nil {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err == nil || c.IsConnected() {
+		t.Errorf("Process after the server hung up = %v, connected %v", err, c.IsConnected())
+	}
+}
+
+func TestClientProcessHandlerDisconnects(t *testing.T) {
+	if err := logger.InitWithFileConfig("error", logger.FileConfig{}, false); err != nil {
+		t.Fatal(err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("no loopback:", err)
+	}
+	defer ln.Close()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, _ := ln.Accept()
+		accepted <- conn
+	}()
+
+	c := New()
+	if err := c.Connect("127.0.0.1", ln.Addr().(*net.TCPAddr).Port, ServerMap); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Disconnect()
+	server := <-accepted
+
+	// The server sends a packet and hangs up, which its handler reacts to
+	// by disconnecting: the hang-up is no longer the caller's concern
+	handled := 0
+	c.RegisterHandler(0x00B6, func([]byte) error {
+		handled++
+		c.Disconnect()
+		return nil
+	})
+	server.Write([]byte{0xB6, 0x00, 0x5C, 0x00, 0x01, 0x00, 0xB6, 0x00, 0x5C, 0x00, 0x01, 0x00})
+	server.Close()
+	c.mu.Lock()
+	cio := c.io
+	c.mu.Unlock()
+	<-cio.readerDone
+
+	if err := c.Process(); err != nil || handled != 1 {
+		t.Errorf("Process = %v, handled %d; want nil, 1", err, handled)
+	}
+}
@@ -0,0 +1,268 @@
+package network
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Proxy types supported by Dialer.
+const (
+	ProxyNone   = ""
+	ProxySOCKS5 = "socks5"
+	ProxyHTTP   = "http"
+)
+
+// defaultDialTimeout is used when Dialer.Timeout is zero.
+const defaultDialTimeout = 10 * time.Second
+
+// maxProxyHeaderLen bounds the HTTP CONNECT response header size.
+const maxProxyHeaderLen = 8192
+
+// Proxy errors.
+var (
+	ErrUnsupportedProxy = errors.New("unsupported proxy type")
+	ErrProxyHandshake   = errors.New("proxy handshake failed")
+)
+
+// ProxyConfig describes an optional proxy used for server connections.
+type ProxyConfig struct {
+	Type     string // "", "socks5" or "http"
+	Address  string // host:port of the proxy
+	Username string // Optional credentials
+	Password string
+}
+
+// Enabled returns true if a proxy should be used.
+func (p ProxyConfig) Enabled() bool {
+	return p.Type != ProxyNone && p.Address != ""
+}
+
+// Dialer opens TCP connections to login/char/map servers,
+// optionally through a SOCKS5 or HTTP CONNECT proxy.
+type Dialer struct {
+	Proxy   ProxyConfig
+	Timeout time.Duration
+}
+
+// JoinHostPort formats a server address, bracketing IPv6 literals.
+func JoinHostPort(host string, port int) string {
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// Dial connects to addr (host:port), through the proxy if configured.
+func (d *Dialer) Dial(addr string) (net.Conn, error) {
+	timeout := d.Timeout
+	if timeout <= 0 {
+		timeout = defaultDialTimeout
+	}
+
+	if !d.Proxy.Enabled() {
+		return net.DialTimeout("tcp", addr, timeout)
+	}
+
+	switch d.Proxy.Type {
+	case ProxySOCKS5, ProxyHTTP:
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedProxy, d.Proxy.Type)
+	}
+
+	conn, err := net.DialTimeout("tcp", d.Proxy.Address, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to proxy %s: %w", d.Proxy.Address, err)
+	}
+
+	// Bound the handshake by the same timeout as the dial
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+	if d.Proxy.Type == ProxySOCKS5 {
+		err = socks5Connect(conn, addr, d.Proxy.Username, d.Proxy.Password)
+	} else {
+		err = httpConnect(conn, addr, d.Proxy.Username, d.Proxy.Password)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("%s proxy %s: %w", d.Proxy.Type, d.Proxy.Address, err)
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	return conn, nil
+}
+
+// socks5Connect performs the RFC 1928 handshake and CONNECT request.
+// Hostnames are passed to the proxy unresolved so it can pick IPv4 or IPv6.
+func socks5Connect(conn net.Conn, addr, username, password string) error {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return fmt.Errorf("invalid port %q: %w", portStr, err)
+	}
+
+	// Greeting: offer no-auth, plus username/password when credentials are set
+	methods := []byte{0x00}
+	if username != "" {
+		methods = []byte{0x00, 0x02}
+	}
+	greeting := append([]byte{0x05, byte(len(methods))}, methods...)
+	if _, err := conn.Write(greeting); err != nil {
+		return err
+	}
+
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("%w: reading method: %v", ErrProxyHandshake, err)
+	}
+	if reply[0] != 0x05 {
+		return fmt.Errorf("%w: unexpected version %d", ErrProxyHandshake, reply[0])
+	}
+
+	switch reply[1] {
+	case 0x00:
+		// No authentication required
+	case 0x02:
+		if err := socks5Auth(conn, username, password); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%w: no acceptable auth method", ErrProxyHandshake)
+	}
+
+	// CONNECT request
+	req := []byte{0x05, 0x01, 0x00}
+	if ip := net.ParseIP(host); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			req = append(req, 0x01)
+			req = append(req, ip4...)
+		} else {
+			req = append(req, 0x04)
+			req = append(req, ip.To16()...)
+		}
+	} else {
+		if len(host) > 255 {
+			return fmt.Errorf("%w: hostname too long", ErrProxyHandshake)
+		}
+		req = append(req, 0x03, byte(len(host)))
+		req = append(req, host...)
+	}
+	req = binary.BigEndian.AppendUint16(req, uint16(port))
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+
+	// Reply: VER REP RSV ATYP BND.ADDR BND.PORT
+	head := make([]byte, 4)
+	if _, err := io.ReadFull(conn, head); err != nil {
+		return fmt.Errorf("%w: reading reply: %v", ErrProxyHandshake, err)
+	}
+	if head[1] != 0x00 {
+		return fmt.Errorf("%w: connect refused (code %d)", ErrProxyHandshake, head[1])
+	}
+
+	var skip int
+	switch head[3] {
+	case 0x01:
+		skip = net.IPv4len
+	case 0x04:
+		skip = net.IPv6len
+	case 0x03:
+		l := make([]byte, 1)
+		if _, err := io.ReadFull(conn, l); err != nil {
+			return fmt.Errorf("%w: reading bound address: %v", ErrProxyHandshake, err)
+		}
+		skip = int(l[0])
+	default:
+		return fmt.Errorf("%w: unknown address type %d", ErrProxyHandshake, head[3])
+	}
+	if _, err := io.ReadFull(conn, make([]byte, skip+2)); err != nil {
+		return fmt.Errorf("%w: reading bound address: %v", ErrProxyHandshake, err)
+	}
+
+	return nil
+}
+
+// socks5Auth performs RFC 1929 username/password authentication.
+func socks5Auth(conn net.Conn, username, password string) error {
+	if len(username) > 255 || len(password) > 255 {
+		return fmt.Errorf("%w: credentials too long", ErrProxyHandshake)
+	}
+	req := []byte{0x01, byte(len(username))}
+	req = append(req, username...)
+	req = append(req, byte(len(password)))
+	req = append(req, password...)
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("%w: reading auth reply: %v", ErrProxyHandshake, err)
+	}
+	if reply[1] != 0x00 {
+		return fmt.Errorf("%w: authentication rejected", ErrProxyHandshake)
+	}
+	return nil
+}
+
+// httpConnect tunnels through an HTTP proxy using the CONNECT method.
+func httpConnect(conn net.Conn, addr, username, password string) error {
+	req := fmt.Sprintf("CONNECT %s HTTP/1.1\r\nHost: %s\r\n", addr, addr)
+	if username != "" {
+		cred := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+		req += "Proxy-Authorization: Basic " + cred + "\r\n"
+	}
+	req += "\r\n"
+	if _, err := io.WriteString(conn, req); err != nil {
+		return err
+	}
+
+	// Read byte-by-byte up to the end of headers so no tunneled data is consumed
+	var header []byte
+	buf := make([]byte, 1)
+	for !bytes.HasSuffix(header, []byte("\r\n\r\n")) {
+		if len(header) > maxProxyHeaderLen {
+			return fmt.Errorf("%w: response header too long", ErrProxyHandshake)
+		}
+		if _, err := io.ReadFull(conn, buf); err != nil {
+			return fmt.Errorf("%w: reading response: %v", ErrProxyHandshake, err)
+		}
+		header = append(header, buf[0])
+	}
+
+	// Status line: HTTP/1.x 200 Connection established
+	status, _, _ := strings.Cut(string(header), "\r\n")
+	fields := strings.Fields(status)
+	if len(fields) < 2 || !strings.HasPrefix(fields[0], "HTTP/") {
+		return fmt.Errorf("%w: malformed status line %q", ErrProxyHandshake, status)
+	}
+	if fields[1] != "200" {
+		return fmt.Errorf("%w: %s", ErrProxyHandshake, strings.Join(fields[1:], " "))
+	}
+	return nil
+}
+
+// DiagnoseDialError returns a short human-readable classification of a dial failure.
+func DiagnoseDialError(err error) string {
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	switch {
+	case errors.Is(err, ErrProxyHandshake):
+		return "proxy rejected the connection"
+	case errors.As(err, &dnsErr):
+		return "hostname could not be resolved"
+	case errors.As(err, &opErr) && opErr.Timeout():
+		return "connection timed out"
+	case errors.As(err, &opErr) && opErr.Op == "dial":
+		return "connection refused or network unreachable"
+	default:
+		return "unknown error"
+	}
+}
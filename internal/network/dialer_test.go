@@ -0,0 +1,182 @@
+package network
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+// startProxy runs handler for a single connection on a local listener.
+func startProxy(t *testing.T, handler func(conn net.Conn)) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		handler(conn)
+	}()
+	return ln.Addr().String()
+}
+
+func TestJoinHostPort(t *testing.T) {
+	tests := []struct {
+		host string
+		port int
+		want string
+	}{
+		{"127.0.0.1", 6900, "127.0.0.1:6900"},
+		{"login.example.com", 6900, "login.example.com:6900"},
+		{"::1", 6900, "[::1]:6900"},
+		{"2001:db8::1", 5121, "[2001:db8::1]:5121"},
+	}
+
+	for _, tt := range tests {
+		if got := JoinHostPort(tt.host, tt.port); got != tt.want {
+			t.Errorf("JoinHostPort(%q, %d) = %q, want %q", tt.host, tt.port, got, tt.want)
+		}
+	}
+}
+
+func TestDialerSOCKS5(t *testing.T) {
+	gotTarget := make(chan string, 1)
+	addr := startProxy(t, func(conn net.Conn) {
+		// Greeting: VER NMETHODS METHODS...
+		head := make([]byte, 2)
+		io.ReadFull(conn, head)
+		io.ReadFull(conn, make([]byte, head[1]))
+		conn.Write([]byte{0x05, 0x02})
+
+		// Username/password auth
+		ver := make([]byte, 2)
+		io.ReadFull(conn, ver)
+		user := make([]byte, ver[1])
+		io.ReadFull(conn, user)
+		plen := make([]byte, 1)
+		io.ReadFull(conn, plen)
+		pass := make([]byte, plen[0])
+		io.ReadFull(conn, pass)
+		status := byte(0x00)
+		if string(user) != "alice" || string(pass) != "secret" {
+			status = 0x01
+		}
+		conn.Write([]byte{0x01, status})
+
+		// CONNECT with domain name address type
+		req := make([]byte, 5)
+		io.ReadFull(conn, req)
+		host := make([]byte, req[4])
+		io.ReadFull(conn, host)
+		port := make([]byte, 2)
+		io.ReadFull(conn, port)
+		gotTarget <- JoinHostPort(string(host), int(binary.BigEndian.Uint16(port)))
+
+		conn.Write([]byte{0x05, 0x00, 0x00, 0x01, 127, 0, 0, 1, 0x1A, 0xF4})
+		conn.Write([]byte("ok"))
+	})
+
+	d := &Dialer{Proxy: ProxyConfig{Type: ProxySOCKS5, Address: addr, Username: "alice", Password: "secret"}}
+	conn, err := d.Dial("login.example.com:6900")
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+
+	if got := <-gotTarget; got != "login.example.com:6900" {
+		t.Errorf("proxy received target %q", got)
+	}
+
+	buf := make([]byte, 2)
+	if _, err := io.ReadFull(conn, buf); err != nil || string(buf) != "ok" {
+		t.Errorf("tunneled read = %q, %v", buf, err)
+	}
+}
+
+func TestDialerSOCKS5Refused(t *testing.T) {
+	addr := startProxy(t, func(conn net.Conn) {
+		io.ReadFull(conn, make([]byte, 3))
+		conn.Write([]byte{0x05, 0x00})
+		io.ReadFull(conn, make([]byte, 10))
+		conn.Write([]byte{0x05, 0x05, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+	})
+
+	d := &Dialer{Proxy: ProxyConfig{Type: ProxySOCKS5, Address: addr}}
+	_, err := d.Dial("10.0.0.1:6900")
+	if !errors.Is(err, ErrProxyHandshake) {
+		t.Fatalf("expected ErrProxyHandshake, got %v", err)
+	}
+	if DiagnoseDialError(err) != "proxy rejected the connection" {
+		t.Errorf("unexpected diagnosis %q", DiagnoseDialError(err))
+	}
+}
+
+func TestDialerHTTPConnect(t *testing.T) {
+	gotRequest := make(chan string, 1)
+	addr := startProxy(t, func(conn net.Conn) {
+		br := bufio.NewReader(conn)
+		line, _ := br.ReadString('\n')
+		gotRequest <- strings.TrimSpace(line)
+		for {
+			l, err := br.ReadString('\n')
+			if err != nil || l == "\r\n" {
+				break
+			}
+		}
+		conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\nok"))
+	})
+
+	d := &Dialer{Proxy: ProxyConfig{Type: ProxyHTTP, Address: addr}}
+	conn, err := d.Dial("[::1]:6900")
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+
+	if got := <-gotRequest; got != "CONNECT [::1]:6900 HTTP/1.1" {
+		t.Errorf("proxy received %q", got)
+	}
+
+	// Data after the header must not be swallowed by the handshake
+	buf := make([]byte, 2)
+	if _, err := io.ReadFull(conn, buf); err != nil || string(buf) != "ok" {
+		t.Errorf("tunneled read = %q, %v", buf, err)
+	}
+}
+
+func TestDialerHTTPConnectRejected(t *testing.T) {
+	addr := startProxy(t, func(conn net.Conn) {
+		br := bufio.NewReader(conn)
+		for {
+			l, err := br.ReadString('\n')
+			if err != nil || l == "\r\n" {
+				break
+			}
+		}
+		conn.Write([]byte("HTTP/1.1 407 Proxy Authentication Required\r\n\r\n"))
+	})
+
+	d := &Dialer{Proxy: ProxyConfig{Type: ProxyHTTP, Address: addr}}
+	_, err := d.Dial("127.0.0.1:6900")
+	if !errors.Is(err, ErrProxyHandshake) {
+		t.Fatalf("expected ErrProxyHandshake, got %v", err)
+	}
+}
+
+func TestDialerUnsupportedProxy(t *testing.T) {
+	d := &Dialer{Proxy: ProxyConfig{Type: "socks4", Address: "127.0.0.1:1080"}}
+	_, err := d.Dial("127.0.0.1:6900")
+	if !errors.Is(err, ErrUnsupportedProxy) {
+		t.Fatalf("expected ErrUnsupportedProxy, got %v", err)
+	}
+}
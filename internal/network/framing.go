@@ -0,0 +1,127 @@
+package network
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/Faultbox/midgard-ro/internal/network/packets"
+)
+
+// lengthVariable marks a packet of unhandledLengths whose length is in its
+// header.
+const lengthVariable = -1
+
+// unhandledLengths are the lengths of packets servers send for our
+// packetver that the client has no handler for, so they are skipped whole
+// rather than by a length guessed from their header.
+var unhandledLengths = map[uint16]int{
+	0x0086: 16,             // ZC_NOTIFY_MOVE
+	0x0088: 10,             // ZC_STOPMOVE
+	0x0095: 30,             // ZC_ACK_REQNAME
+	0x0097: lengthVariable, // ZC_WHISPER
+	0x00BD: 44,             // ZC_STATUS
+	0x00C0: 7,              // ZC_EMOTION
+	0x00C3: 8,              // ZC_SPRITE_CHANGE
+	0x0109: lengthVariable, // ZC_NOTIFY_CHAT_PARTY
+	0x010F: lengthVariable, // ZC_SKILLINFO_LIST
+	0x0121: 14,             // ZC_NOTIFY_CARTITEM_COUNTINFO
+	0x013A: 4,              // ZC_ATTACK_RANGE
+	0x0141: 14,             // ZC_COUPLESTATUS
+	0x017F: lengthVariable, // ZC_GUILD_CHAT
+	0x019B: 10,             // ZC_NOTIFY_EFFECT
+	0x01D0: 8,              // ZC_SPIRITS
+	0x01D7: 11,             // ZC_SPRITE_CHANGE2
+	0x02C9: 3,              // ZC_PARTY_CONFIG
+	0x043F: 25,             // ZC_MSG_STATE_CHANGE2
+	0x0977: 14,             // ZC_HP_INFO
+	0x09CB: 17,             // ZC_USE_SKILL2
+	0x0A3B: lengthVariable, // ZC_HAT_EFFECT
+	0x0ADE: 6,              // ZC_WEIGHT_LIMIT
+	0x0ADF: 58,             // ZC_ACK_REQNAME_TITLE
+	0x0AE2: 7,              // ZC_OPEN_UI
+	0x0B08: lengthVariable, // ZC_INVENTORY_START
+	0x0B0B: 4,              // ZC_INVENTORY_END
+	0x0B32: lengthVariable, // ZC_SKILLINFO_LIST (2019)
+}
+
+// maxGuessedLength is the longest header length believed for a packet of
+// an unknown ID. Known variable packets are listed, so longer ones are
+// taken for misread framing.
+const maxGuessedLength = 1024
+
+// mismatchResyncs is how often framing is lost on a connection before the
+// server is taken for one of another packetver.
+const mismatchResyncs = 3
+
+// framing tells how the length of a packet was found.
+type framing int
+
+const (
+	framed  framing = iota // Known ID
+	guessed                // Unknown ID, length from its header
+	short                  // More data is needed to tell
+	lost                   // The data isn't at a packet start
+)
+
+// knownLength returns the length of the packet at the start of data from
+// its ID, or 0 if the ID is unknown or data too short to tell.
+func (c *Client) knownLength(data []byte) int {
+	if len(data) < 2 {
+		return 0
+	}
+	id := binary.LittleEndian.Uint16(data)
+	if n := c.getPacketLength(id, data); n > 0 {
+		return n
+	}
+	n, ok := unhandledLengths[id]
+	if !ok {
+		return 0
+	}
+	if n == lengthVariable {
+		if len(data) < 4 {
+			return 0
+		}
+		if n = int(binary.LittleEndian.Uint16(data[2:4])); n < 4 {
+			return 0
+		}
+	}
+	return n
+}
+
+// frameLength returns the length of the packet at the start of data, and
+// how it was found.
+func (c *Client) frameLength(data []byte) (int, framing) {
+	if n := c.knownLength(data); n > 0 {
+		return n, framed
+	}
+	if len(data) < 4 {
+		return 0, short
+	}
+	if n := int(binary.LittleEndian.Uint16(data[2:4])); n >= 4 && n <= maxGuessedLength {
+		return n, guessed
+	}
+	return 0, lost
+}
+
+// resync returns how many bytes to drop from data, whose start isn't a
+// packet's, to reach the next likely packet start: a known ID whose packet
+// ends with the data, runs past it, or is followed by another known ID.
+// Without one, all but the last byte are dropped, as it may begin an ID.
+func (c *Client) resync(data []byte) int {
+	for p := 1; p+2 <= len(data); p++ {
+		n := c.knownLength(data[p:])
+		if n == 0 {
+			continue
+		}
+		next := p + n
+		if next+2 > len(data) || c.knownLength(data[next:]) > 0 {
+			return p
+		}
+	}
+	return max(len(data)-1, 0)
+}
+
+// MismatchWarning is shown when framing keeps being lost: the server
+// likely sends packets of another packetver than the client's.
+var MismatchWarning = fmt.Sprintf("Unreadable data from the server. It may use another packet version "+
+	"than this client's (%d); check the server's PACKETVER.", packets.PacketVer)
@@ -0,0 +1,58 @@
+package network
+
+import "testing"
+
+func TestFrameLength(t *testing.T) {
+	c := New()
+	tests := []struct {
+		name string
+		data []byte
+		n    int
+		how  framing
+	}{
+		{"handled", []byte{0x80, 0x00, 1, 2, 3, 4, 5}, 7, framed},
+		{"handled short", []byte{0x81, 0x00, 3}, 3, framed},
+		{"unhandled fixed", []byte{0x88, 0x00, 0xFF, 0xFF}, 10, framed},
+		{"unhandled variable", []byte{0x97, 0x00, 0x20, 0x00}, 32, framed},
+		{"variable short", []byte{0x97, 0x00, 0x20}, 0, short},
+		{"unknown", []byte{0x34, 0x12, 0x10, 0x00}, 16, guessed},
+		{"unknown short", []byte{0x34, 0x12}, 0, short},
+		{"lost", []byte{0x34, 0x12, 0xFF, 0xFF}, 0, lost},
+		{"lost tiny length", []byte{0x34, 0x12, 0x02, 0x00}, 0, lost},
+	}
+	for _, tt := range tests {
+		if n, how := c.frameLength(tt.data); n != tt.n || how != tt.how {
+			t.Errorf("%s: frameLength = %d, %d, want %d, %d", tt.name, n, how, tt.n, tt.how)
+		}
+	}
+}
+
+func TestResync(t *testing.T) {
+	c := New()
+	vanish := []byte{0x80, 0x00, 1, 2, 3, 4, 5} // ZC_NOTIFY_VANISH
+	stop := []byte{0x88, 0x00, 1, 2, 3, 4, 5, 6, 7, 8}
+
+	join := func(parts ...[]byte) []byte {
+		var b []byte
+		for _, p := range parts {
+			b = append(b, p...)
+		}
+		return b
+	}
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"before a packet", join([]byte{0xEE, 0xFF, 0xFF}, vanish), 3},
+		{"before two packets", join([]byte{0xEE, 0xFF, 0xFF}, vanish, stop), 3},
+		{"before a partial packet", join([]byte{0xEE, 0xFF}, stop[:5]), 2},
+		{"nothing known", []byte{0xEE, 0xFF, 0xFF, 0xEE, 0xDD}, 4},
+		{"empty", nil, 0},
+	}
+	for _, tt := range tests {
+		if got := c.resync(tt.data); got != tt.want {
+			t.Errorf("%s: resync = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package network
+
+import "time"
+
+// maxPendingBeats caps the unanswered requests a Heartbeat remembers; a
+// server that drops that many is stalled no matter how old the rest are.
+const maxPendingBeats = 8
+
+// Heartbeat paces the keep-alive requests a server needs to keep a session
+// open, and times the replies: their round trip is the latency, and a
+// request left unanswered too long means the server stopped responding.
+// Replies are matched to requests in order, as servers answer them.
+//
+// It isn't safe for concurrent use; the game loop drives it.
+type Heartbeat struct {
+	interval time.Duration // Between requests
+	timeout  time.Duration // Without a reply before the server is stalled
+
+	lastSent time.Time
+	pending  []time.Time   // Send times of unanswered requests, oldest first
+	latency  time.Duration // Smoothed round trip, 0 until the first reply
+}
+
+// NewHeartbeat creates a heartbeat sending a request every interval, and
+// counting the server as stalled after timeout without a reply.
+func NewHeartbeat(interval, timeout time.Duration) *Heartbeat {
+	return &Heartbeat{interval: interval, timeout: timeout}
+}
+
+// Due reports whether the next request should be sent at now. The first
+// one is due at once, so latency is known early.
+func (h *Heartbeat) Due(now time.Time) bool {
+	return h.lastSent.IsZero() || now.Sub(h.lastSent) >= h.interval
+}
+
+// Sent records a request sent at now.
+func (h *Heartbeat) Sent(now time.Time) {
+	h.lastSent = now
+	if len(h.pending) == maxPendingBeats {
+		h.pending = h.pending[1:]
+	}
+	h.pending = append(h.pending, now)
+}
+
+// Received records a reply at now and returns its round trip. Replies
+// with no request waiting, such as ones the server sends on its own, are
+// ignored.
+func (h *Heartbeat) Received(now time.Time) (time.Duration, bool) {
+	if len(h.pending) == 0 {
+		return 0, false
+	}
+	rtt := max(now.Sub(h.pending[0]), 0)
+	h.pending = h.pending[1:]
+
+	// Smoothed like TCP's round-trip estimate, so one slow reply doesn't
+	// swing the reading
+	if h.latency == 0 {
+		h.latency = rtt
+	} else {
+		h.latency = (7*h.latency + rtt) / 8
+	}
+	return rtt, true
+}
+
+// Latency returns the smoothed round trip, or 0 before the first reply.
+func (h *Heartbeat) Latency() time.Duration {
+	return h.latency
+}
+
+// Unanswered returns how long the oldest unanswered request has waited at
+// now, or 0 if every request was answered.
+func (h *Heartbeat) Unanswered(now time.Time) time.Duration {
+	if len(h.pending) == 0 {
+		return 0
+	}
+	return now.Sub(h.pending[0])
+}
+
+// Stalled reports whether a request has gone unanswered past the timeout.
+func (h *Heartbeat) Stalled(now time.Time) bool {
+	return h.Unanswered(now) > h.timeout || len(h.pending) == maxPendingBeats
+}
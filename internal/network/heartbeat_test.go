@@ -0,0 +1,77 @@
+package network
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHeartbeatPacing(t *testing.T) {
+	start := time.Unix(1000, 0)
+	h := NewHeartbeat(10*time.Second, 5*time.Second)
+	if !h.Due(start) {
+		t.Fatal("first request isn't due at once")
+	}
+	h.Sent(start)
+	if h.Due(start.Add(9 * time.Second)) {
+		t.Error("due before the interval")
+	}
+	if !h.Due(start.Add(10 * time.Second)) {
+		t.Error("not due after the interval")
+	}
+}
+
+func TestHeartbeatLatency(t *testing.T) {
+	start := time.Unix(1000, 0)
+	h := NewHeartbeat(10*time.Second, 5*time.Second)
+	if _, ok := h.Received(start); ok {
+		t.Error("reply with no request was matched")
+	}
+
+	h.Sent(start)
+	rtt, ok := h.Received(start.Add(80 * time.Millisecond))
+	if !ok || rtt != 80*time.Millisecond || h.Latency() != 80*time.Millisecond {
+		t.Fatalf("first reply: rtt %v, latency %v, want 80ms", rtt, h.Latency())
+	}
+
+	// One slow reply moves the reading an eighth of the way
+	h.Sent(start.Add(10 * time.Second))
+	h.Received(start.Add(10*time.Second + 880*time.Millisecond))
+	if want := 180 * time.Millisecond; h.Latency() != want {
+		t.Errorf("latency after a slow reply = %v, want %v", h.Latency(), want)
+	}
+}
+
+func TestHeartbeatStalled(t *testing.T) {
+	start := time.Unix(1000, 0)
+	h := NewHeartbeat(10*time.Second, 5*time.Second)
+	h.Sent(start)
+	if h.Stalled(start.Add(5 * time.Second)) {
+		t.Error("stalled at the timeout")
+	}
+	if !h.Stalled(start.Add(6 * time.Second)) {
+		t.Error("not stalled past the timeout")
+	}
+
+	// Replies answer the oldest request first
+	h.Sent(start.Add(10 * time.Second))
+	h.Received(start.Add(11 * time.Second))
+	if got := h.Unanswered(start.Add(12 * time.Second)); got != 2*time.Second {
+		t.Errorf("unanswered = %v, want the second request's 2s", got)
+	}
+	h.Received(start.Add(12 * time.Second))
+	if h.Stalled(start.Add(time.Hour)) || h.Unanswered(start.Add(time.Hour)) != 0 {
+		t.Error("stalled with every request answered")
+	}
+
+	// Too many unanswered requests stall even with a long timeout
+	h = NewHeartbeat(time.Second, time.Hour)
+	for i := range maxPendingBeats + 2 {
+		h.Sent(start.Add(time.Duration(i) * time.Second))
+	}
+	if !h.Stalled(start.Add(maxPendingBeats * time.Second)) {
+		t.Error("not stalled with the pending list full")
+	}
+	if got := h.Unanswered(start.Add(20 * time.Second)); got != 18*time.Second {
+		t.Errorf("unanswered = %v, want the oldest kept request's 18s", got)
+	}
+}
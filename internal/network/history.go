@@ -0,0 +1,70 @@
+package network
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// PacketHistorySize is how many packets are kept for crash reports.
+const PacketHistorySize = 100
+
+// Packet directions recorded in PacketRecord.
+const (
+	DirSend = "send"
+	DirRecv = "recv"
+)
+
+// PacketRecord is a single entry in the packet history.
+type PacketRecord struct {
+	Time time.Time
+	Dir  string // DirSend or DirRecv
+	ID   uint16
+	Len  int
+}
+
+// String formats the record as one line of a crash report.
+func (p PacketRecord) String() string {
+	return fmt.Sprintf("%s %s 0x%04X len=%d", p.Time.Format("15:04:05.000"), p.Dir, p.ID, p.Len)
+}
+
+// packetHistory is a fixed-size ring of recently sent and received packets.
+type packetHistory struct {
+	mu      sync.Mutex
+	records []PacketRecord
+	next    int
+	full    bool
+}
+
+func newPacketHistory(size int) *packetHistory {
+	return &packetHistory{records: make([]PacketRecord, size)}
+}
+
+func (h *packetHistory) add(dir string, id uint16, length int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records[h.next] = PacketRecord{Time: time.Now(), Dir: dir, ID: id, Len: length}
+	h.next = (h.next + 1) % len(h.records)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+func (h *packetHistory) snapshot() []PacketRecord {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.full {
+		return append([]PacketRecord(nil), h.records[:h.next]...)
+	}
+	out := make([]PacketRecord, 0, len(h.records))
+	out = append(out, h.records[h.next:]...)
+	return append(out, h.records[:h.next]...)
+}
+
+var history = newPacketHistory(PacketHistorySize)
+
+// RecentPackets returns the last PacketHistorySize packet opcodes
+// sent or received by any client, oldest first.
+func RecentPackets() []PacketRecord {
+	return history.snapshot()
+}
@@ -0,0 +1,41 @@
+package network
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPacketHistoryRing(t *testing.T) {
+	h := newPacketHistory(3)
+	if got := h.snapshot(); len(got) != 0 {
+		t.Fatalf("expected empty history, got %v", got)
+	}
+
+	h.add(DirSend, 0x0064, 55)
+	h.add(DirRecv, 0x0069, 79)
+	if got := h.snapshot(); len(got) != 2 || got[0].ID != 0x0064 || got[1].ID != 0x0069 {
+		t.Fatalf("unexpected partial history %v", got)
+	}
+
+	h.add(DirSend, 0x0065, 17)
+	h.add(DirRecv, 0x006B, 27)
+	got := h.snapshot()
+	want := []uint16{0x0069, 0x0065, 0x006B}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d records, got %d", len(want), len(got))
+	}
+	for i, id := range want {
+		if got[i].ID != id {
+			t.Errorf("record %d: got 0x%04X, want 0x%04X", i, got[i].ID, id)
+		}
+	}
+}
+
+func TestPacketRecordString(t *testing.T) {
+	h := newPacketHistory(1)
+	h.add(DirRecv, 0x0AC4, 64)
+	s := h.snapshot()[0].String()
+	if !strings.Contains(s, "recv 0x0AC4 len=64") {
+		t.Errorf("unexpected record string %q", s)
+	}
+}
@@ -1,7 +1,10 @@
 // Package packets defines Hercules protocol packets.
 package packets
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // Packet IDs for login server
 const (
@@ -18,6 +21,14 @@ const (
 	AC_REFUSE_LOGIN  uint16 = 0x006A // Login refused (old)
 	AC_REFUSE_LOGIN2 uint16 = 0x083E // Login refused (modern)
 	AC_NOTIFY_ERROR  uint16 = 0x0081 // Notify error
+	AC_LOGIN_QUEUE   uint16 = 0x0B2C // Queue position while the server is busy (queueing servers)
+)
+
+// Packet IDs any server can send.
+const (
+	// SC_NOTIFY_BAN ends the session: a kick, a duplicate login, the server
+	// shutting down. The login server's AC_NOTIFY_ERROR is the same packet.
+	SC_NOTIFY_BAN uint16 = 0x0081
 )
 
 // Packet IDs for character server
@@ -28,14 +39,25 @@ const (
 	CH_MAKE_CHAR   uint16 = 0x0067 // Create character
 	CH_DELETE_CHAR uint16 = 0x0068 // Delete character
 
+	CH_SECOND_PASSWD_ACK       uint16 = 0x08B8 // PIN code entered
+	CH_MAKE_SECOND_PASSWD      uint16 = 0x08BA // First PIN code
+	CH_EDIT_SECOND_PASSWD      uint16 = 0x08BE // Change PIN code
+	CH_AVAILABLE_SECOND_PASSWD uint16 = 0x08C5 // Ask to set up a PIN code
+
 	// Char Server -> Client
 	HC_ACCEPT_ENTER    uint16 = 0x006B // Enter accepted + char list
 	HC_REFUSE_ENTER    uint16 = 0x006C // Enter refused
 	HC_ACCEPT_MAKECHAR uint16 = 0x006D // Character created
 	HC_NOTIFY_ZONESVR  uint16 = 0x0071 // Map server info (old)
 	HC_NOTIFY_ZONESVR2 uint16 = 0x0AC5 // Map server info (modern rAthena)
+
+	HC_SECOND_PASSWD_LOGIN uint16 = 0x08B9 // PIN code state and keypad seed
 )
 
+// PacketVer is the packet version the client speaks, the date of the
+// client build whose packets it matches.
+const PacketVer = 20211103
+
 // Packet IDs for map server.
 //
 // rAthena shuffles packet IDs by packetver. The IDs below are the ones
@@ -50,6 +72,36 @@ const (
 	CZ_REQUEST_MOVE     uint16 = 0x035F // Request move (WalkToXY) — was 0x0085 pre-2010
 	CZ_REQUEST_TIME     uint16 = 0x0360 // Keep-alive (TickSend) — must be sent or session times out
 	CZ_NOTIFY_ACTORINIT uint16 = 0x007D // Loading complete
+	CZ_REQUEST_ACT2     uint16 = 0x0437 // Action request (attack, sit, stand) — was 0x0089 pre-2008
+	CZ_USE_SKILL        uint16 = 0x0438 // Use a skill on a unit — was 0x0113 pre-2008
+	CZ_REQ_EMOTION      uint16 = 0x00BF // Show an emotion bubble
+	CZ_REQ_USER_COUNT   uint16 = 0x00C1 // Ask how many players are online
+
+	// Client -> Map Server: player interaction
+	CZ_WHISPER             uint16 = 0x0096 // Private message by character name
+	CZ_SETTING_WHISPER_PC  uint16 = 0x00CF // Block/unblock whispers from a character
+	CZ_REQ_EXCHANGE_ITEM   uint16 = 0x00E4 // Trade request by account ID
+	CZ_PARTY_JOIN_REQ      uint16 = 0x02C4 // Party invite by character name
+	CZ_EQUIPWIN_MICROSCOPE uint16 = 0x02D6 // View another player's equipment
+	CZ_PARTY_JOIN_REQ_ACK  uint16 = 0x02C7 // Answer a party invitation
+	CZ_JOIN_GUILD          uint16 = 0x016B // Answer a guild invitation
+	CZ_ACK_EXCHANGE_ITEM   uint16 = 0x00E6 // Answer a trade request
+	CZ_RESTART             uint16 = 0x00B2 // Respawn at the save point, or return to character select
+	CZ_REQNAME2            uint16 = 0x0368 // Ask for a unit's party, guild and position names
+	CZ_REQ_DISCONNECT      uint16 = 0x018A // Log out; the server answers with ZC_ACK_REQ_DISCONNECT
+
+	// Client -> Map Server: NPCs
+	CZ_REQ_NEXT_SCRIPT uint16 = 0x00B9 // Next page of an NPC dialog
+	CZ_CLOSE_DIALOG    uint16 = 0x0146 // Close an NPC dialog that offered Close
+
+	// Client -> Map Server: items
+	CZ_ITEM_THROW        uint16 = 0x0363 // Drop an inventory item (DropItem) — was 0x00A2 pre-2010
+	CZ_REQ_WEAR_EQUIP    uint16 = 0x0998 // Equip an inventory item (PACKETVER >= 20120925)
+	CZ_REQ_TAKEOFF_EQUIP uint16 = 0x00AB // Unequip an item
+
+	// Client -> Map Server: vending
+	CZ_REQ_BUY_FROMMC               uint16 = 0x0130 // Open another player's shop by account ID
+	CZ_PC_PURCHASE_ITEMLIST_FROMMC2 uint16 = 0x0801 // Buy from a player's shop
 
 	// Map Server -> Client
 	ZC_ACCEPT_ENTER      uint16 = 0x0073 // Map enter accepted (old)
@@ -58,8 +110,97 @@ const (
 	ZC_NOTIFY_MOVEENTRY  uint16 = 0x007B // Entity spawn (moving)
 	ZC_NOTIFY_PLAYERMOVE uint16 = 0x0087 // Own player walk-OK (start_tick + packed positions)
 	ZC_NOTIFY_ACT        uint16 = 0x008A // Entity action
+	ZC_NOTIFY_ACT2       uint16 = 0x08C8 // Entity action, 32-bit damage (PACKETVER >= 20071113)
+	ZC_NOTIFY_SKILL2     uint16 = 0x01DE // Damage of a skill used on a unit
 	ZC_NPCACK_MAPMOVE    uint16 = 0x0091 // Map change (server-driven warp)
 	ZC_NOTIFY_TIME       uint16 = 0x007F // Server tick reply to CZ_REQUEST_TIME
+	ZC_USER_COUNT        uint16 = 0x00C2 // Players online, the reply to CZ_REQ_USER_COUNT
+	ZC_CHANGE_CELLTYPE   uint16 = 0x0192 // Runtime cell type change (Ice Wall, setcell)
+	ZC_PAR_CHANGE        uint16 = 0x00B0 // Status parameter change (int32)
+	ZC_LONGPAR_CHANGE    uint16 = 0x00B1 // Status parameter change (uint32: exp, zeny)
+	ZC_LONGPAR_CHANGE2   uint16 = 0x0ACB // Status parameter change (int64 exp, PACKETVER >= 20170830)
+
+	// Map Server -> Client: session
+	ZC_ACK_REQ_DISCONNECT uint16 = 0x018B // Logout allowed, or refused during combat
+	ZC_RESTART_ACK        uint16 = 0x00B3 // Answer to CZ_RESTART for character select
+	ZC_BROADCAST          uint16 = 0x009A // Server-wide announcement, e.g. a shutdown notice
+	ZC_BROADCAST2         uint16 = 0x01C3 // Announcement in a color and font size of the server's
+
+	// Map Server -> Client: chat
+	ZC_NOTIFY_CHAT       uint16 = 0x008D // A unit in view said something
+	ZC_NOTIFY_PLAYERCHAT uint16 = 0x008E // The player's own chat, echoed back
+
+	// Map Server -> Client: map rules
+	ZC_MAPPROPERTY_R2 uint16 = 0x099B // Map PVP/GvG mode and flags (PACKETVER >= 20121010)
+	ZC_NOTIFY_RANKING uint16 = 0x019A // The player's PVP rank on the map
+
+	// Map Server -> Client: units in view
+	ZC_NOTIFY_NEWENTRY11   uint16 = 0x09FE // Unit spawned in view (PACKETVER >= 20150513)
+	ZC_NOTIFY_STANDENTRY11 uint16 = 0x09FF // Idle unit came into view
+	ZC_NOTIFY_MOVEENTRY11  uint16 = 0x09FD // Walking unit came into view
+	ZC_NOTIFY_VANISH       uint16 = 0x0080 // Unit left view, died or logged out
+	ZC_STATE_CHANGE3       uint16 = 0x0229 // Unit option flags changed (cart, riding, hiding)
+	ZC_MSG_STATE_CHANGE    uint16 = 0x0196 // Status icon on or off, without a timer
+	ZC_MSG_STATE_CHANGE3   uint16 = 0x0983 // Status icon on or off, with its timer (PACKETVER >= 20120618)
+	ZC_CHANGESTATE_PET     uint16 = 0x01A4 // Pet state change; type 0 marks the player's own pet
+	ZC_ACK_REQNAMEALL2     uint16 = 0x0A30 // A player's party, guild and position names (PACKETVER >= 20150503)
+
+	// Map Server -> Client: items
+	ZC_INVENTORY_ITEMLIST_NORMAL uint16 = 0x0B09 // Stackable/usable inventory items (PACKETVER >= 20180912)
+	ZC_INVENTORY_ITEMLIST_EQUIP  uint16 = 0x0B39 // Equipment in the inventory (PACKETVER >= 20200916)
+	ZC_ITEM_THROW_ACK            uint16 = 0x00AF // Inventory item removed by a drop
+	ZC_ITEM_FALL_ENTRY           uint16 = 0x0ADD // Item dropped on the ground (PACKETVER >= 20180418)
+	ZC_ITEM_DISAPPEAR            uint16 = 0x00A1 // Ground item picked up or expired
+	ZC_REQ_WEAR_EQUIP_ACK        uint16 = 0x0999 // Answer to CZ_REQ_WEAR_EQUIP (PACKETVER >= 20120925)
+	ZC_REQ_TAKEOFF_EQUIP_ACK     uint16 = 0x099A // An item was unequipped, asked for or swapped out
+	ZC_ITEM_PICKUP_ACK           uint16 = 0x0B41 // Item added to the inventory (PACKETVER >= 20200916)
+
+	// Map Server -> Client: player requests
+	ZC_PARTY_JOIN_REQ     uint16 = 0x02C6 // Invitation to join a party
+	ZC_REQ_JOIN_GUILD     uint16 = 0x016A // Invitation to join a guild
+	ZC_REQ_EXCHANGE_ITEM2 uint16 = 0x01F4 // Trade request from another player
+
+	// Map Server -> Client: friends and guild
+	ZC_FRIENDS_STATE    uint16 = 0x0206 // A friend logged in or out (with a name, PACKETVER >= 20180221)
+	ZC_UPDATE_CHARSTAT2 uint16 = 0x01F2 // A guild member logged in or out
+
+	// Map Server -> Client: quests
+	ZC_UPDATE_MISSION_HUNT uint16 = 0x09FA // Hunt objective counts changed (PACKETVER >= 20150513)
+
+	// Map Server -> Client: vending
+	ZC_STORE_ENTRY                  uint16 = 0x0131 // Shop title board shown above a vendor
+	ZC_DISAPPEAR_ENTRY              uint16 = 0x0132 // Shop title board removed
+	ZC_PC_PURCHASE_RESULT_FROMMC    uint16 = 0x0135 // Purchase from a player's shop failed
+	ZC_PC_PURCHASE_ITEMLIST_FROMMC2 uint16 = 0x0800 // A player's shop contents (PACKETVER >= 20100105)
+
+	// Map Server -> Client: chat rooms
+	ZC_ROOM_NEWENTRY   uint16 = 0x00D7 // Chat room title board shown above its owner
+	ZC_DESTROY_ROOM    uint16 = 0x00D8 // Chat room closed
+	ZC_CHANGE_CHATROOM uint16 = 0x00DF // Chat room title, size or type changed
+
+	// Map Server -> Client: NPCs
+	ZC_SAY_DIALOG   uint16 = 0x00B4 // A line of an NPC's dialog
+	ZC_WAIT_DIALOG  uint16 = 0x00B5 // NPC dialog page ends with a Next button
+	ZC_SHOW_IMAGE2  uint16 = 0x01B3 // NPC illustration (cutin) shown or cleared
+	ZC_CLOSE_DIALOG uint16 = 0x00B6 // NPC dialog ended
+)
+
+// Status parameter IDs carried by ZC_PAR_CHANGE / ZC_LONGPAR_CHANGE
+// (rAthena SP_* constants).
+const (
+	VarBaseExp     uint16 = 1
+	VarJobExp      uint16 = 2
+	VarHP          uint16 = 5
+	VarMaxHP       uint16 = 6
+	VarSP          uint16 = 7
+	VarMaxSP       uint16 = 8
+	VarBaseLevel   uint16 = 11
+	VarZeny        uint16 = 20
+	VarNextBaseExp uint16 = 22
+	VarNextJobExp  uint16 = 23
+	VarWeight      uint16 = 24 // In tenths
+	VarMaxWeight   uint16 = 25 // In tenths
+	VarJobLevel    uint16 = 55
 )
 
 // LoginRequest (CA_LOGIN 0x0064)
@@ -418,6 +559,126 @@ func (p *MapServerInfo) GetIP() string {
 		byte(p.IP), byte(p.IP>>8), byte(p.IP>>16), byte(p.IP>>24))
 }
 
+// PIN code states (HC_SECOND_PASSWD_LOGIN).
+const (
+	PincodeOK      uint16 = 0 // The server doesn't use PIN codes
+	PincodeAsk     uint16 = 1 // Enter the PIN to unlock the characters
+	PincodeNotSet  uint16 = 2 // No PIN yet; one may be created
+	PincodeExpired uint16 = 3 // The PIN must be changed
+	PincodeNew     uint16 = 4 // A PIN must be created
+	PincodeIllegal uint16 = 5 // The new PIN was refused as too easy
+	PincodePassed  uint16 = 7 // Correct PIN, or none needed this session
+	PincodeWrong   uint16 = 8 // Wrong PIN
+)
+
+// PincodeLength is the number of digits in a PIN code.
+const PincodeLength = 4
+
+// PincodeLogin is the character server's PIN code state.
+type PincodeLogin struct {
+	Seed      uint32 // Shuffles the keypad for the next PIN sent
+	AccountID uint32
+	State     uint16 // Pincode* state
+}
+
+// DecodePincodeLogin parses HC_SECOND_PASSWD_LOGIN (12 bytes): header(2) +
+// seed(4) + account ID(4) + state(2). Returns false on short data.
+func DecodePincodeLogin(data []byte) (PincodeLogin, bool) {
+	if len(data) < 12 {
+		return PincodeLogin{}, false
+	}
+	return PincodeLogin{
+		Seed:      readU32(data, 2),
+		AccountID: readU32(data, 6),
+		State:     readU16(data, 10),
+	}, true
+}
+
+// PincodeKeypad returns the digit shown on each keypad button for a seed.
+// The server shuffles the buttons on every prompt and reads a PIN as the
+// buttons pressed, so the digits typed never cross the wire.
+func PincodeKeypad(seed uint32) [10]byte {
+	var keys [10]byte
+	for i := range keys {
+		keys[i] = byte(i)
+	}
+	for i := 1; i < len(keys); i++ {
+		seed = 0x881234 + seed*0x3498
+		j := seed % uint32(i+1)
+		keys[i], keys[j] = keys[j], keys[i]
+	}
+	return keys
+}
+
+// EncodePincode converts a PIN of decimal digits to the buttons pressed on
+// the seed's keypad, as the server expects it.
+func EncodePincode(seed uint32, pin string) [PincodeLength]byte {
+	keys := PincodeKeypad(seed)
+	var out [PincodeLength]byte
+	for i := range out {
+		out[i] = '0'
+		if i >= len(pin) {
+			continue
+		}
+		for button, digit := range keys {
+			if pin[i] == '0'+digit {
+				out[i] = '0' + byte(button)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// PincodeSubmit carries an account's PIN code: CH_SECOND_PASSWD_ACK (the
+// PIN) and CH_MAKE_SECOND_PASSWD (the first PIN). Both are from
+// EncodePincode.
+type PincodeSubmit struct {
+	PacketID  uint16
+	AccountID uint32
+	Pin       [PincodeLength]byte
+}
+
+// Size returns packet size.
+func (p *PincodeSubmit) Size() int {
+	return 10
+}
+
+// Encode encodes the packet.
+func (p *PincodeSubmit) Encode() []byte {
+	buf := make([]byte, p.Size())
+	buf[0] = byte(p.PacketID)
+	buf[1] = byte(p.PacketID >> 8)
+	writeU32(buf, 2, p.AccountID)
+	copy(buf[6:], p.Pin[:])
+	return buf
+}
+
+// PincodeChange (CH_EDIT_SECOND_PASSWD 0x08BE) replaces an account's PIN
+// code. Both PINs are from EncodePincode.
+type PincodeChange struct {
+	PacketID  uint16 // 0x08BE
+	AccountID uint32
+	OldPin    [PincodeLength]byte
+	NewPin    [PincodeLength]byte
+}
+
+// Size returns packet size.
+func (p *PincodeChange) Size() int {
+	return 14
+}
+
+// Encode encodes the packet.
+func (p *PincodeChange) Encode() []byte {
+	buf := make([]byte, p.Size())
+	buf[0] = byte(p.PacketID)
+	buf[1] = byte(p.PacketID >> 8)
+	writeU32(buf, 2, p.AccountID)
+	copy(buf[6:], p.OldPin[:])
+	copy(buf[10:], p.NewPin[:])
+	return buf
+}
+
 // MapEnter (CZ_ENTER 0x0072) packet.
 type MapEnter struct {
 	PacketID   uint16 // 0x0072
@@ -514,6 +775,26 @@ func (p *MapAccept) GetPosition() (x, y int, dir uint8) {
 	return
 }
 
+// MapMove is ZC_NPCACK_MAPMOVE: the player warped to a map of the same
+// map server.
+type MapMove struct {
+	MapName string // With its .gat extension, e.g. "prontera.gat"
+	X, Y    int
+}
+
+// DecodeMapMove parses ZC_NPCACK_MAPMOVE (22 bytes): header(2) + map
+// name(16) + x(2) + y(2). Returns nil on short data.
+func DecodeMapMove(data []byte) *MapMove {
+	if len(data) < 22 {
+		return nil
+	}
+	return &MapMove{
+		MapName: readString(data[2:18]),
+		X:       int(readU16(data, 18)),
+		Y:       int(readU16(data, 20)),
+	}
+}
+
 // MoveRequest (CZ_REQUEST_MOVE 0x035F for packetver 20211103) packet.
 type MoveRequest struct {
 	PacketID uint16  // 0x035F
@@ -587,14 +868,7 @@ func DecodePlayerMove(data []byte) *PlayerMove {
 		return nil
 	}
 	tick := uint32(data[2]) | uint32(data[3])<<8 | uint32(data[4])<<16 | uint32(data[5])<<24
-
-	// Unpack 6-byte position pair (rAthena RBUFPOS2 layout)
-	b := data[6:12]
-	x0 := int(b[0])<<2 | int(b[1])>>6
-	y0 := (int(b[1])&0x3F)<<4 | int(b[2])>>4
-	x1 := (int(b[2])&0x0F)<<6 | int(b[3])>>2
-	y1 := (int(b[3])&0x03)<<8 | int(b[4])
-
+	x0, y0, x1, y1 := unpackPos2(data[6:12])
 	return &PlayerMove{
 		StartTick: tick,
 		StartX:    x0,
@@ -604,35 +878,1721 @@ func DecodePlayerMove(data []byte) *PlayerMove {
 	}
 }
 
-// LoadingComplete (CZ_NOTIFY_ACTORINIT 0x007D) packet.
-type LoadingComplete struct {
-	PacketID uint16 // 0x007D
+// ChangeCellType (ZC_CHANGE_CELLTYPE 0x0192, 24 bytes) — the server changed
+// a cell's type at runtime, e.g. an Ice Wall segment appearing (type 5)
+// or melting (type 0). Type uses GAT cell type values.
+type ChangeCellType struct {
+	X       int
+	Y       int
+	Type    uint16
+	MapName [16]byte
+}
+
+// DecodeChangeCellType parses ZC_CHANGE_CELLTYPE. Returns nil on short data.
+//
+// Layout: header(2) + x(2) + y(2) + type(2) + map_name(16).
+func DecodeChangeCellType(data []byte) *ChangeCellType {
+	if len(data) < 24 {
+		return nil
+	}
+	p := &ChangeCellType{
+		X:    int(readU16(data, 2)),
+		Y:    int(readU16(data, 4)),
+		Type: readU16(data, 6),
+	}
+	copy(p.MapName[:], data[8:24])
+	return p
+}
+
+// GetMapName returns the map name as a string.
+func (p *ChangeCellType) GetMapName() string {
+	for i, b := range p.MapName {
+		if b == 0 {
+			return string(p.MapName[:i])
+		}
+	}
+	return string(p.MapName[:])
+}
+
+// ParChange is a decoded status parameter update (ZC_PAR_CHANGE,
+// ZC_LONGPAR_CHANGE or ZC_LONGPAR_CHANGE2). VarID is one of the Var* constants.
+type ParChange struct {
+	VarID uint16
+	Value int64
+}
+
+// DecodeParChange parses ZC_PAR_CHANGE. Returns nil on short data.
+//
+// Layout: header(2) + var_id(2) + value(4, signed).
+func DecodeParChange(data []byte) *ParChange {
+	if len(data) < 8 {
+		return nil
+	}
+	return &ParChange{
+		VarID: readU16(data, 2),
+		Value: int64(int32(readU32(data, 4))),
+	}
+}
+
+// DecodeLongParChange parses ZC_LONGPAR_CHANGE. Returns nil on short data.
+//
+// Layout: header(2) + var_id(2) + value(4, unsigned).
+func DecodeLongParChange(data []byte) *ParChange {
+	if len(data) < 8 {
+		return nil
+	}
+	return &ParChange{
+		VarID: readU16(data, 2),
+		Value: int64(readU32(data, 4)),
+	}
+}
+
+// DecodeLongParChange2 parses ZC_LONGPAR_CHANGE2. Returns nil on short data.
+//
+// Layout: header(2) + var_id(2) + value(8, signed).
+func DecodeLongParChange2(data []byte) *ParChange {
+	if len(data) < 12 {
+		return nil
+	}
+	return &ParChange{
+		VarID: readU16(data, 2),
+		Value: int64(uint64(readU32(data, 4)) | uint64(readU32(data, 8))<<32),
+	}
+}
+
+// NotifyTime (ZC_NOTIFY_TIME 0x007F, 6 bytes) is the server's reply to
+// CZ_REQUEST_TIME.
+type NotifyTime struct {
+	ServerTick uint32 // Milliseconds since the map server started
+}
+
+// DecodeNotifyTime parses ZC_NOTIFY_TIME. Returns nil on short data.
+func DecodeNotifyTime(data []byte) *NotifyTime {
+	if len(data) < 6 {
+		return nil
+	}
+	return &NotifyTime{ServerTick: readU32(data, 2)}
+}
+
+// Action types of NotifyAct (rAthena e_damage_type).
+const (
+	ActTypeNormal        uint8 = 0
+	ActTypePickup        uint8 = 1
+	ActTypeSit           uint8 = 2
+	ActTypeStand         uint8 = 3
+	ActTypeEndure        uint8 = 4
+	ActTypeSplash        uint8 = 5
+	ActTypeSkill         uint8 = 6
+	ActTypeMultiHit      uint8 = 8
+	ActTypeMultiEndure   uint8 = 9
+	ActTypeCritical      uint8 = 10
+	ActTypeLuckyDodge    uint8 = 11
+	ActTypeTouch         uint8 = 12
+	ActTypeMultiCritical uint8 = 13
+)
+
+// NotifyAct (ZC_NOTIFY_ACT 0x008A, 29 bytes; ZC_NOTIFY_ACT2 0x08C8, 34
+// bytes) is a unit's action: an attack, or sitting and standing.
+type NotifyAct struct {
+	SourceID       uint32
+	TargetID       uint32
+	StartTime      uint32 // Server tick the action started
+	AttackMotion   uint32 // Source's attack animation length (ms)
+	AttackedMotion uint32 // Target's flinch length (ms)
+	Damage         int32
+	Div            int16 // Number of hits
+	Type           uint8 // One of the ActType* constants
+	Damage2        int32 // Left-hand damage
+}
+
+// IsAttack returns true if the action is an attack, hit or missed.
+func (a *NotifyAct) IsAttack() bool {
+	switch a.Type {
+	case ActTypePickup, ActTypeSit, ActTypeStand, ActTypeTouch:
+		return false
+	}
+	return true
+}
+
+// DecodeNotifyAct parses ZC_NOTIFY_ACT or ZC_NOTIFY_ACT2. Returns nil on
+// short data.
+func DecodeNotifyAct(data []byte) *NotifyAct {
+	if len(data) < 29 {
+		return nil
+	}
+	a := &NotifyAct{
+		SourceID:       readU32(data, 2),
+		TargetID:       readU32(data, 6),
+		StartTime:      readU32(data, 10),
+		AttackMotion:   readU32(data, 14),
+		AttackedMotion: readU32(data, 18),
+	}
+	if readU16(data, 0) == ZC_NOTIFY_ACT2 {
+		if len(data) < 34 {
+			return nil
+		}
+		a.Damage = int32(readU32(data, 22))
+		a.Div = int16(readU16(data, 27)) // data[26] flags SP damage
+		a.Type = data[29]
+		a.Damage2 = int32(readU32(data, 30))
+		return a
+	}
+	a.Damage = int32(int16(readU16(data, 22)))
+	a.Div = int16(readU16(data, 24))
+	a.Type = data[26]
+	a.Damage2 = int32(int16(readU16(data, 27)))
+	return a
+}
+
+// NotifySkill (ZC_NOTIFY_SKILL2 0x01DE, 33 bytes) is the damage of a
+// skill one unit used on another.
+type NotifySkill struct {
+	SkillID        uint16
+	SourceID       uint32
+	TargetID       uint32
+	StartTime      uint32 // Server tick the skill started
+	AttackMotion   uint32 // Source's attack animation length (ms)
+	AttackedMotion uint32 // Target's flinch length (ms)
+	Damage         int32
+	Level          int16
+	Div            int16 // Number of hits
+	Type           uint8 // One of the ActType* constants
+}
+
+// DecodeNotifySkill parses ZC_NOTIFY_SKILL2. Returns nil on short data.
+func DecodeNotifySkill(data []byte) *NotifySkill {
+	if len(data) < 33 {
+		return nil
+	}
+	return &NotifySkill{
+		SkillID:        readU16(data, 2),
+		SourceID:       readU32(data, 4),
+		TargetID:       readU32(data, 8),
+		StartTime:      readU32(data, 12),
+		AttackMotion:   readU32(data, 16),
+		AttackedMotion: readU32(data, 20),
+		Damage:         int32(readU32(data, 24)),
+		Level:          int16(readU16(data, 28)),
+		Div:            int16(readU16(data, 30)),
+		Type:           data[32],
+	}
+}
+
+// Action types for ActionRequest.
+const (
+	ActionAttack       uint8 = 0
+	ActionSit          uint8 = 2
+	ActionStand        uint8 = 3
+	ActionAttackRepeat uint8 = 7
+)
+
+// ActionRequest (CZ_REQUEST_ACT2 0x0437) packet.
+type ActionRequest struct {
+	PacketID uint16 // 0x0437
+	TargetID uint32 // Ignored by the server for sit/stand
+	Action   uint8  // One of the Action* constants
 }
 
 // Size returns packet size.
-func (p *LoadingComplete) Size() int {
+func (p *ActionRequest) Size() int {
+	return 7
+}
+
+// Encode encodes the packet.
+func (p *ActionRequest) Encode() []byte {
+	buf := make([]byte, p.Size())
+	buf[0] = byte(p.PacketID)
+	buf[1] = byte(p.PacketID >> 8)
+	writeU32(buf, 2, p.TargetID)
+	buf[6] = p.Action
+	return buf
+}
+
+// SkillRequest (CZ_USE_SKILL 0x0438) packet.
+type SkillRequest struct {
+	PacketID uint16 // 0x0438
+	Level    uint16
+	SkillID  uint16
+	TargetID uint32 // The player's own ID for self skills
+}
+
+// Size returns packet size.
+func (p *SkillRequest) Size() int {
+	return 10
+}
+
+// Encode encodes the packet.
+func (p *SkillRequest) Encode() []byte {
+	buf := make([]byte, p.Size())
+	buf[0] = byte(p.PacketID)
+	buf[1] = byte(p.PacketID >> 8)
+	writeU16(buf, 2, p.Level)
+	writeU16(buf, 4, p.SkillID)
+	writeU32(buf, 6, p.TargetID)
+	return buf
+}
+
+// EmotionRequest (CZ_REQ_EMOTION 0x00BF) packet.
+type EmotionRequest struct {
+	PacketID uint16 // 0x00BF
+	Type     uint8  // Emotion index (ET_* in rAthena, 0 = "!")
+}
+
+// Size returns packet size.
+func (p *EmotionRequest) Size() int {
+	return 3
+}
+
+// Encode encodes the packet.
+func (p *EmotionRequest) Encode() []byte {
+	return []byte{byte(p.PacketID), byte(p.PacketID >> 8), p.Type}
+}
+
+// UserCountRequest (CZ_REQ_USER_COUNT 0x00C1) asks how many players are
+// online.
+type UserCountRequest struct {
+	PacketID uint16 // 0x00C1
+}
+
+// Size returns packet size.
+func (p *UserCountRequest) Size() int {
 	return 2
 }
 
 // Encode encodes the packet.
-func (p *LoadingComplete) Encode() []byte {
+func (p *UserCountRequest) Encode() []byte {
 	return []byte{byte(p.PacketID), byte(p.PacketID >> 8)}
 }
 
-// Helper functions for packet encoding/decoding
+// DecodeUserCount parses ZC_USER_COUNT (6 bytes): header(2) + players
+// online(4). Returns false for ok on short data.
+func DecodeUserCount(data []byte) (count int, ok bool) {
+	if len(data) < 6 {
+		return 0, false
+	}
+	return int(int32(readU32(data, 2))), true
+}
 
-func readU16(data []byte, offset int) uint16 {
-	return uint16(data[offset]) | uint16(data[offset+1])<<8
+// nameLen is the fixed size of character name fields, NUL included.
+const nameLen = 24
+
+// putName writes a NUL-terminated character name into a 24-byte field,
+// truncating names that don't fit.
+func putName(buf []byte, offset int, name string) {
+	n := copy(buf[offset:offset+nameLen-1], name)
+	buf[offset+n] = 0
 }
 
-func readU32(data []byte, offset int) uint32 {
-	return uint32(data[offset]) | uint32(data[offset+1])<<8 |
-		uint32(data[offset+2])<<16 | uint32(data[offset+3])<<24
+// Whisper (CZ_WHISPER 0x0096) is a private message, variable length.
+type Whisper struct {
+	PacketID uint16 // 0x0096
+	Target   string // Character name
+	Message  string
 }
 
-func writeU32(buf []byte, offset int, v uint32) {
-	buf[offset] = byte(v)
-	buf[offset+1] = byte(v >> 8)
-	buf[offset+2] = byte(v >> 16)
-	buf[offset+3] = byte(v >> 24)
+// Encode encodes the packet.
+//
+// Layout: header(2) + len(2) + name(24) + message + NUL.
+func (p *Whisper) Encode() []byte {
+	size := 4 + nameLen + len(p.Message) + 1
+	buf := make([]byte, size)
+	buf[0] = byte(p.PacketID)
+	buf[1] = byte(p.PacketID >> 8)
+	buf[2] = byte(size)
+	buf[3] = byte(size >> 8)
+	putName(buf, 4, p.Target)
+	copy(buf[4+nameLen:], p.Message)
+	return buf
+}
+
+// Whisper block settings for WhisperSetting.
+const (
+	WhisperBlock uint8 = 0
+	WhisperAllow uint8 = 1
+)
+
+// WhisperSetting (CZ_SETTING_WHISPER_PC 0x00CF) blocks or allows whispers
+// from one character.
+type WhisperSetting struct {
+	PacketID uint16 // 0x00CF
+	Name     string
+	Type     uint8 // WhisperBlock or WhisperAllow
+}
+
+// Size returns packet size.
+func (p *WhisperSetting) Size() int {
+	return 27
+}
+
+// Encode encodes the packet.
+func (p *WhisperSetting) Encode() []byte {
+	buf := make([]byte, p.Size())
+	buf[0] = byte(p.PacketID)
+	buf[1] = byte(p.PacketID >> 8)
+	putName(buf, 2, p.Name)
+	buf[26] = p.Type
+	return buf
+}
+
+// AccountRequest is a request carrying only a target account ID:
+// CZ_REQ_EXCHANGE_ITEM (trade), CZ_EQUIPWIN_MICROSCOPE (view equipment),
+// CZ_REQ_BUY_FROMMC (open a shop), CZ_REQNAME2 (guild and party names),
+// CH_AVAILABLE_SECOND_PASSWD (set up a PIN code), and with an NPC's ID
+// CZ_REQ_NEXT_SCRIPT and CZ_CLOSE_DIALOG (move an NPC dialog on).
+type AccountRequest struct {
+	PacketID  uint16
+	AccountID uint32
+}
+
+// Size returns packet size.
+func (p *AccountRequest) Size() int {
+	return 6
+}
+
+// Encode encodes the packet.
+func (p *AccountRequest) Encode() []byte {
+	buf := make([]byte, p.Size())
+	buf[0] = byte(p.PacketID)
+	buf[1] = byte(p.PacketID >> 8)
+	writeU32(buf, 2, p.AccountID)
+	return buf
+}
+
+// PartyInvite (CZ_PARTY_JOIN_REQ 0x02C4) invites a character by name.
+type PartyInvite struct {
+	PacketID uint16 // 0x02C4
+	Name     string
+}
+
+// Size returns packet size.
+func (p *PartyInvite) Size() int {
+	return 26
+}
+
+// Encode encodes the packet.
+func (p *PartyInvite) Encode() []byte {
+	buf := make([]byte, p.Size())
+	buf[0] = byte(p.PacketID)
+	buf[1] = byte(p.PacketID >> 8)
+	putName(buf, 2, p.Name)
+	return buf
+}
+
+// ItemThrow (CZ_ITEM_THROW 0x0363) drops an inventory item on the ground.
+type ItemThrow struct {
+	PacketID uint16 // 0x0363
+	Index    uint16 // Inventory index as sent by the server
+	Count    uint16
+}
+
+// Size returns packet size.
+func (p *ItemThrow) Size() int {
+	return 6
+}
+
+// Encode encodes the packet.
+func (p *ItemThrow) Encode() []byte {
+	buf := make([]byte, p.Size())
+	buf[0] = byte(p.PacketID)
+	buf[1] = byte(p.PacketID >> 8)
+	writeU16(buf, 2, p.Index)
+	writeU16(buf, 4, p.Count)
+	return buf
+}
+
+// InventoryItem is one entry of ZC_INVENTORY_ITEMLIST_NORMAL.
+type InventoryItem struct {
+	Index      uint16
+	ItemID     uint32
+	Type       uint8 // rAthena item_types (IT_*)
+	Count      uint16
+	WearState  uint32 // Equip location bits, 0 when not worn
+	Identified bool
+}
+
+// inventoryNormalSize is the size of NORMALITEM_INFO for our packetver:
+// index(2) + nameid(4) + type(1) + count(2) + wearState(4) + cards(16) +
+// hireExpire(4) + flags(1).
+const inventoryNormalSize = 34
+
+// DecodeInventoryNormal parses ZC_INVENTORY_ITEMLIST_NORMAL: header(2) +
+// len(2) + invType(1) followed by item entries. Returns nil on short data.
+func DecodeInventoryNormal(data []byte) []InventoryItem {
+	if len(data) < 5 {
+		return nil
+	}
+	end := min(int(readU16(data, 2)), len(data))
+
+	var items []InventoryItem
+	for off := 5; off+inventoryNormalSize <= end; off += inventoryNormalSize {
+		items = append(items, InventoryItem{
+			Index:      readU16(data, off),
+			ItemID:     readU32(data, off+2),
+			Type:       data[off+6],
+			Count:      readU16(data, off+7),
+			WearState:  readU32(data, off+9),
+			Identified: data[off+33]&0x01 != 0,
+		})
+	}
+	return items
+}
+
+// EquipItem is one entry of ZC_INVENTORY_ITEMLIST_EQUIP.
+type EquipItem struct {
+	Index      uint16
+	ItemID     uint32
+	Type       uint8  // rAthena item_types (IT_*)
+	Location   uint32 // Equip location bits the item fits
+	WearState  uint32 // Equip location bits worn, 0 when not worn
+	Refine     uint8
+	Cards      [4]uint32 // Cards slotted, or the maker for crafted items
+	Identified bool
+	Damaged    bool
+}
+
+// ItemPickupAck is ZC_ITEM_PICKUP_ACK, an item added to the inventory:
+// picked up, bought, traded or made.
+type ItemPickupAck struct {
+	Index      uint16
+	Amount     uint16
+	ItemID     uint32
+	Identified bool
+	Damaged    bool
+	Cards      [4]uint32
+	Location   uint32 // Equip location bits the item fits
+	Type       uint8  // rAthena item_types (IT_*)
+	Result     uint8  // PickupOK, or why the item wasn't added
+	Refine     uint8
+}
+
+// ZC_ITEM_PICKUP_ACK results (rAthena e_additem_result).
+const (
+	PickupOK         uint8 = 0
+	PickupOverweight uint8 = 2
+	PickupOverAmount uint8 = 4 // Too many of the item, or of items
+	PickupStackLimit uint8 = 5
+)
+
+// DecodeItemPickupAck parses ZC_ITEM_PICKUP_ACK (70 bytes): header(2) +
+// index(2) + amount(2) + nameid(4) + identified(1) + damaged(1) +
+// cards(16) + location(4) + type(1) + result(1) + hireExpire(4) +
+// bindOnEquip(2) + options(25) + favorite(1) + look(2) + refine(1) +
+// grade(1). Returns nil on short data.
+func DecodeItemPickupAck(data []byte) *ItemPickupAck {
+	if len(data) < 70 {
+		return nil
+	}
+	ack := &ItemPickupAck{
+		Index:      readU16(data, 2),
+		Amount:     readU16(data, 4),
+		ItemID:     readU32(data, 6),
+		Identified: data[10] != 0,
+		Damaged:    data[11] != 0,
+		Location:   readU32(data, 28),
+		Type:       data[32],
+		Result:     data[33],
+		Refine:     data[68],
+	}
+	for i := range ack.Cards {
+		ack.Cards[i] = readU32(data, 12+i*4)
+	}
+	return ack
+}
+
+// equipItemSize is the size of EQUIPITEM_INFO for our packetver:
+// index(2) + nameid(4) + type(1) + location(4) + wearState(4) + cards(16) +
+// hireExpire(4) + bindOnEquip(2) + sprite(2) + optionCount(1) +
+// options(25) + refine(1) + grade(1) + flags(1).
+const equipItemSize = 68
+
+// DecodeInventoryEquip parses ZC_INVENTORY_ITEMLIST_EQUIP: header(2) +
+// len(2) + invType(1) followed by item entries. Returns nil on short data.
+func DecodeInventoryEquip(data []byte) []EquipItem {
+	if len(data) < 5 {
+		return nil
+	}
+	end := min(int(readU16(data, 2)), len(data))
+
+	var items []EquipItem
+	for off := 5; off+equipItemSize <= end; off += equipItemSize {
+		item := EquipItem{
+			Index:      readU16(data, off),
+			ItemID:     readU32(data, off+2),
+			Type:       data[off+6],
+			Location:   readU32(data, off+7),
+			WearState:  readU32(data, off+11),
+			Refine:     data[off+65],
+			Identified: data[off+67]&0x01 != 0,
+			Damaged:    data[off+67]&0x02 != 0,
+		}
+		for i := range item.Cards {
+			item.Cards[i] = readU32(data, off+15+i*4)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// WearEquip (CZ_REQ_WEAR_EQUIP 0x0998) equips an inventory item. The
+// server takes off whatever the item replaces.
+type WearEquip struct {
+	PacketID uint16 // 0x0998
+	Index    uint16
+	Location uint32 // The item's equip location bits
+}
+
+// Size returns packet size.
+func (p *WearEquip) Size() int {
+	return 8
+}
+
+// Encode encodes the packet.
+func (p *WearEquip) Encode() []byte {
+	buf := make([]byte, p.Size())
+	buf[0] = byte(p.PacketID)
+	buf[1] = byte(p.PacketID >> 8)
+	writeU16(buf, 2, p.Index)
+	writeU32(buf, 4, p.Location)
+	return buf
+}
+
+// TakeoffEquip (CZ_REQ_TAKEOFF_EQUIP 0x00AB) unequips an item.
+type TakeoffEquip struct {
+	PacketID uint16 // 0x00AB
+	Index    uint16
+}
+
+// Size returns packet size.
+func (p *TakeoffEquip) Size() int {
+	return 4
+}
+
+// Encode encodes the packet.
+func (p *TakeoffEquip) Encode() []byte {
+	buf := make([]byte, p.Size())
+	buf[0] = byte(p.PacketID)
+	buf[1] = byte(p.PacketID >> 8)
+	writeU16(buf, 2, p.Index)
+	return buf
+}
+
+// Equip results (ZC_REQ_WEAR_EQUIP_ACK). Unequipping only uses EquipOK
+// and EquipFailed.
+const (
+	EquipOK          uint8 = 0
+	EquipFailed      uint8 = 1
+	EquipLevelTooLow uint8 = 2
+)
+
+// EquipAck answers an equip or unequip request.
+type EquipAck struct {
+	Index    uint16
+	Location uint32 // Equip location bits put on or taken off
+	Result   uint8  // Equip* result
+}
+
+// DecodeEquipAck parses ZC_REQ_WEAR_EQUIP_ACK and ZC_REQ_TAKEOFF_EQUIP_ACK
+// (9 bytes): header(2) + index(2) + location(4) + result(1). Returns false
+// on short data.
+func DecodeEquipAck(data []byte) (EquipAck, bool) {
+	if len(data) < 9 {
+		return EquipAck{}, false
+	}
+	return EquipAck{
+		Index:    readU16(data, 2),
+		Location: readU32(data, 4),
+		Result:   data[8],
+	}, true
+}
+
+// ItemThrowAck (ZC_ITEM_THROW_ACK 0x00AF, 6 bytes) removes dropped items
+// from the inventory.
+type ItemThrowAck struct {
+	Index uint16
+	Count uint16
+}
+
+// DecodeItemThrowAck parses ZC_ITEM_THROW_ACK. Returns nil on short data.
+func DecodeItemThrowAck(data []byte) *ItemThrowAck {
+	if len(data) < 6 {
+		return nil
+	}
+	return &ItemThrowAck{Index: readU16(data, 2), Count: readU16(data, 4)}
+}
+
+// ItemFallEntry (ZC_ITEM_FALL_ENTRY 0x0ADD, 24 bytes) announces an item
+// dropped on the ground, including the echo of our own drops.
+type ItemFallEntry struct {
+	ObjectID   uint32 // Ground item ID, used by pickup and ZC_ITEM_DISAPPEAR
+	ItemID     uint32
+	Type       uint16
+	Identified bool
+	X, Y       uint16 // Cell
+	SubX, SubY uint8  // Position within the cell, 0-11 of 12 sub-cells
+	Count      uint16
+	ShowEffect bool
+	EffectMode uint16
+}
+
+// DecodeItemFallEntry parses ZC_ITEM_FALL_ENTRY. Returns nil on short data.
+func DecodeItemFallEntry(data []byte) *ItemFallEntry {
+	if len(data) < 24 {
+		return nil
+	}
+	return &ItemFallEntry{
+		ObjectID:   readU32(data, 2),
+		ItemID:     readU32(data, 6),
+		Type:       readU16(data, 10),
+		Identified: data[12] != 0,
+		X:          readU16(data, 13),
+		Y:          readU16(data, 15),
+		SubX:       data[17],
+		SubY:       data[18],
+		Count:      readU16(data, 19),
+		ShowEffect: data[21] != 0,
+		EffectMode: readU16(data, 22),
+	}
+}
+
+// DecodeItemDisappear parses ZC_ITEM_DISAPPEAR (6 bytes) and returns the
+// ground item's object ID, or false on short data.
+func DecodeItemDisappear(data []byte) (uint32, bool) {
+	if len(data) < 6 {
+		return 0, false
+	}
+	return readU32(data, 2), true
+}
+
+// storeTitleLen is the fixed size of a vending shop title, NUL included.
+const storeTitleLen = 80
+
+// DecodeStoreEntry parses ZC_STORE_ENTRY (86 bytes): header(2) + vendor
+// account ID(4) + title(80). Returns false on short data.
+func DecodeStoreEntry(data []byte) (vendorID uint32, title string, ok bool) {
+	if len(data) < 6+storeTitleLen {
+		return 0, "", false
+	}
+	return readU32(data, 2), readString(data[6 : 6+storeTitleLen]), true
+}
+
+// DecodeDisappearEntry parses ZC_DISAPPEAR_ENTRY (6 bytes) and returns the
+// vendor's account ID, or false on short data.
+func DecodeDisappearEntry(data []byte) (uint32, bool) {
+	if len(data) < 6 {
+		return 0, false
+	}
+	return readU32(data, 2), true
+}
+
+// Chat room types of ChatRoom.
+const (
+	ChatRoomPrivate uint8 = 0 // Joined with a password
+	ChatRoomPublic  uint8 = 1
+	ChatRoomArena   uint8 = 2 // An NPC's waiting room, such as an event sign
+)
+
+// ChatRoom is ZC_ROOM_NEWENTRY or ZC_CHANGE_CHATROOM, a chat room's board.
+type ChatRoom struct {
+	OwnerID uint32 // Account ID of the player, or ID of the NPC
+	RoomID  uint32
+	Limit   uint16 // Most members
+	Users   uint16 // Members in the room
+	Type    uint8  // ChatRoomPrivate, ChatRoomPublic or ChatRoomArena
+	Title   string
+}
+
+// DecodeChatRoom parses ZC_ROOM_NEWENTRY and ZC_CHANGE_CHATROOM
+// (variable): header(2) + length(2) + owner ID(4) + room ID(4) + limit(2)
+// + users(2) + type(1) + title. Returns nil on short data.
+func DecodeChatRoom(data []byte) *ChatRoom {
+	if len(data) < 17 {
+		return nil
+	}
+	n := min(int(readU16(data, 2)), len(data))
+	if n < 17 {
+		return nil
+	}
+	return &ChatRoom{
+		OwnerID: readU32(data, 4),
+		RoomID:  readU32(data, 8),
+		Limit:   readU16(data, 12),
+		Users:   readU16(data, 14),
+		Type:    data[16],
+		Title:   readString(data[17:n]),
+	}
+}
+
+// DecodeDestroyRoom parses ZC_DESTROY_ROOM (6 bytes) and returns the
+// room's ID, or false on short data.
+func DecodeDestroyRoom(data []byte) (uint32, bool) {
+	if len(data) < 6 {
+		return 0, false
+	}
+	return readU32(data, 2), true
+}
+
+// VendingItem is one entry of ZC_PC_PURCHASE_ITEMLIST_FROMMC2.
+type VendingItem struct {
+	Price      uint32
+	Amount     uint16
+	Index      uint16 // Index in the vendor's cart, used to buy
+	Type       uint8  // rAthena item_types (IT_*)
+	ItemID     uint32
+	Identified bool
+	Damaged    bool
+	Refine     uint8
+	Cards      [4]uint32
+}
+
+// VendingItemList (ZC_PC_PURCHASE_ITEMLIST_FROMMC2 0x0800) is the contents
+// of another player's shop.
+type VendingItemList struct {
+	VendorID uint32 // Account ID
+	UniqueID uint32 // Shop ID, echoed back when buying
+	Items    []VendingItem
+}
+
+// vendingItemSize is the size of PACKET_ZC_PC_PURCHASE_ITEMLIST_FROMMC_sub
+// for our packetver: price(4) + amount(2) + index(2) + type(1) + nameid(4) +
+// identified(1) + damaged(1) + refine(1) + cards(16) + options(25) +
+// location(4) + viewSprite(2) + grade(1).
+const vendingItemSize = 64
+
+// DecodeVendingItemList parses ZC_PC_PURCHASE_ITEMLIST_FROMMC2: header(2) +
+// len(2) + vendor account ID(4) + shop ID(4) followed by item entries.
+// Returns nil on short data.
+func DecodeVendingItemList(data []byte) *VendingItemList {
+	if len(data) < 12 {
+		return nil
+	}
+	end := min(int(readU16(data, 2)), len(data))
+
+	list := &VendingItemList{VendorID: readU32(data, 4), UniqueID: readU32(data, 8)}
+	for off := 12; off+vendingItemSize <= end; off += vendingItemSize {
+		item := VendingItem{
+			Price:      readU32(data, off),
+			Amount:     readU16(data, off+4),
+			Index:      readU16(data, off+6),
+			Type:       data[off+8],
+			ItemID:     readU32(data, off+9),
+			Identified: data[off+13] != 0,
+			Damaged:    data[off+14] != 0,
+			Refine:     data[off+15],
+		}
+		for i := range item.Cards {
+			item.Cards[i] = readU32(data, off+16+i*4)
+		}
+		list.Items = append(list.Items, item)
+	}
+	return list
+}
+
+// VendingPurchaseItem is one line of a VendingPurchase.
+type VendingPurchaseItem struct {
+	Index  uint16 // VendingItem.Index
+	Amount uint16
+}
+
+// VendingPurchase (CZ_PC_PURCHASE_ITEMLIST_FROMMC2 0x0801) buys items from
+// another player's shop, variable length.
+type VendingPurchase struct {
+	PacketID uint16 // 0x0801
+	VendorID uint32
+	UniqueID uint32
+	Items    []VendingPurchaseItem
+}
+
+// Encode encodes the packet.
+//
+// Layout: header(2) + len(2) + vendor(4) + shop ID(4) + (amount(2) +
+// index(2)) per item.
+func (p *VendingPurchase) Encode() []byte {
+	size := 12 + 4*len(p.Items)
+	buf := make([]byte, size)
+	writeU16(buf, 0, p.PacketID)
+	writeU16(buf, 2, uint16(size))
+	writeU32(buf, 4, p.VendorID)
+	writeU32(buf, 8, p.UniqueID)
+	for i, item := range p.Items {
+		writeU16(buf, 12+4*i, item.Amount)
+		writeU16(buf, 14+4*i, item.Index)
+	}
+	return buf
+}
+
+// Purchase results carried by ZC_PC_PURCHASE_RESULT_FROMMC.
+const (
+	PurchaseOK           uint8 = 0
+	PurchaseNoZeny       uint8 = 1
+	PurchaseOverweight   uint8 = 2
+	PurchaseOutOfStock   uint8 = 4
+	PurchaseTrading      uint8 = 5
+	PurchaseStoreChanged uint8 = 6
+	PurchaseNoSalesInfo  uint8 = 7
+)
+
+// VendingPurchaseResult (ZC_PC_PURCHASE_RESULT_FROMMC 0x0135, 7 bytes)
+// reports a failed shop purchase.
+type VendingPurchaseResult struct {
+	Index  uint16
+	Amount uint16
+	Result uint8 // Purchase* constant
+}
+
+// DecodeVendingPurchaseResult parses ZC_PC_PURCHASE_RESULT_FROMMC. Returns
+// nil on short data.
+func DecodeVendingPurchaseResult(data []byte) *VendingPurchaseResult {
+	if len(data) < 7 {
+		return nil
+	}
+	return &VendingPurchaseResult{Index: readU16(data, 2), Amount: readU16(data, 4), Result: data[6]}
+}
+
+// Invitation is a party (ZC_PARTY_JOIN_REQ) or guild (ZC_REQ_JOIN_GUILD)
+// invitation.
+type Invitation struct {
+	ID   uint32 // Party or guild ID, echoed in the answer
+	Name string // Party or guild name
+}
+
+// DecodeInvitation parses ZC_PARTY_JOIN_REQ or ZC_REQ_JOIN_GUILD (30 bytes):
+// header(2) + ID(4) + name(24). Returns nil on short data.
+func DecodeInvitation(data []byte) *Invitation {
+	if len(data) < 6+nameLen {
+		return nil
+	}
+	return &Invitation{ID: readU32(data, 2), Name: readString(data[6 : 6+nameLen])}
+}
+
+// UnitNames is ZC_ACK_REQNAMEALL2, the names shown over a player.
+type UnitNames struct {
+	ID       uint32
+	Name     string
+	Party    string
+	Guild    string
+	Position string // Position (title) within the guild
+	TitleID  uint32 // Achievement title
+}
+
+// DecodeUnitNames parses ZC_ACK_REQNAMEALL2 (106 bytes): header(2) + ID(4)
+// + name, party, guild and position names(24 each) + title ID(4). Returns
+// nil on short data.
+func DecodeUnitNames(data []byte) *UnitNames {
+	if len(data) < 6+4*nameLen+4 {
+		return nil
+	}
+	field := func(i int) string {
+		off := 6 + i*nameLen
+		return readString(data[off : off+nameLen])
+	}
+	return &UnitNames{
+		ID:       readU32(data, 2),
+		Name:     field(0),
+		Party:    field(1),
+		Guild:    field(2),
+		Position: field(3),
+		TitleID:  readU32(data, 6+4*nameLen),
+	}
+}
+
+// TradeRequest is ZC_REQ_EXCHANGE_ITEM2, another player asking to trade.
+type TradeRequest struct {
+	Name   string
+	CharID uint32
+	Level  uint16
+}
+
+// FriendState is ZC_FRIENDS_STATE, a friend logging in or out.
+type FriendState struct {
+	AccountID uint32
+	CharID    uint32
+	Online    bool
+	Name      string
+}
+
+// DecodeFriendState parses ZC_FRIENDS_STATE (35 bytes): header(2) +
+// account ID(4) + char ID(4) + state(1, 0 when online) + name(24).
+// Returns nil on short data.
+func DecodeFriendState(data []byte) *FriendState {
+	if len(data) < 35 {
+		return nil
+	}
+	return &FriendState{
+		AccountID: readU32(data, 2),
+		CharID:    readU32(data, 6),
+		Online:    data[10] == 0,
+		Name:      readString(data[11 : 11+nameLen]),
+	}
+}
+
+// GuildMemberState is ZC_UPDATE_CHARSTAT2, a guild member logging in or
+// out.
+type GuildMemberState struct {
+	AccountID uint32
+	CharID    uint32
+	Online    bool
+}
+
+// DecodeGuildMemberState parses ZC_UPDATE_CHARSTAT2 (20 bytes): header(2)
+// + account ID(4) + char ID(4) + status(4, 1 when online) + sex(2) +
+// hair style(2) + hair color(2). Returns nil on short data.
+func DecodeGuildMemberState(data []byte) *GuildMemberState {
+	if len(data) < 20 {
+		return nil
+	}
+	return &GuildMemberState{
+		AccountID: readU32(data, 2),
+		CharID:    readU32(data, 6),
+		Online:    readU32(data, 10) == 1,
+	}
+}
+
+// QuestHunt is an entry of ZC_UPDATE_MISSION_HUNT, a quest's hunt
+// objective.
+type QuestHunt struct {
+	HuntID   uint32 // Quest ID * 1000 + objective index
+	MaxCount uint16
+	Count    uint16
+}
+
+// QuestID returns the ID of the quest the objective belongs to.
+func (h QuestHunt) QuestID() uint32 {
+	return h.HuntID / 1000
+}
+
+// DecodeMissionHunt parses ZC_UPDATE_MISSION_HUNT (variable): header(2) +
+// length(2) + count(2) + count * [hunt ID(4) + hunt ID again(4) + max
+// count(2) + count(2)]. Entries past the data are dropped.
+func DecodeMissionHunt(data []byte) []QuestHunt {
+	if len(data) < 6 {
+		return nil
+	}
+	const entryLen = 12
+	n := int(readU16(data, 4))
+	hunts := make([]QuestHunt, 0, min(n, (len(data)-6)/entryLen))
+	for off := 6; len(hunts) < n && off+entryLen <= len(data); off += entryLen {
+		hunts = append(hunts, QuestHunt{
+			HuntID:   readU32(data, off),
+			MaxCount: readU16(data, off+8),
+			Count:    readU16(data, off+10),
+		})
+	}
+	return hunts
+}
+
+// DecodeTradeRequest parses ZC_REQ_EXCHANGE_ITEM2 (32 bytes): header(2) +
+// name(24) + char ID(4) + base level(2). Returns nil on short data.
+func DecodeTradeRequest(data []byte) *TradeRequest {
+	if len(data) < 32 {
+		return nil
+	}
+	return &TradeRequest{
+		Name:   readString(data[2 : 2+nameLen]),
+		CharID: readU32(data, 26),
+		Level:  readU16(data, 30),
+	}
+}
+
+// Trade answers (CZ_ACK_EXCHANGE_ITEM result).
+const (
+	TradeAccept uint8 = 3
+	TradeRefuse uint8 = 4
+)
+
+// SC_NOTIFY_BAN reasons (rAthena clif_authfail_fd).
+const (
+	BanServerClosed    uint8 = 1  // Server shutting down or closed to players
+	BanDuplicateLogin  uint8 = 2  // Someone else logged in with this account
+	BanTimeout         uint8 = 3  // Too much lag, or out of sync
+	BanServerFull      uint8 = 4  // Server full
+	BanUnderaged       uint8 = 5  // Account too young for this server
+	BanStillOnline     uint8 = 8  // The server hasn't released the last session yet
+	BanIPLimit         uint8 = 9  // Too many connections from this IP
+	BanPaidTimeExpired uint8 = 10 // Out of paid play time
+	BanKicked          uint8 = 15 // Kicked by a GM, or by the server going down
+)
+
+// DecodeNotifyBan parses SC_NOTIFY_BAN (3 bytes) and returns the reason,
+// or false on short data.
+func DecodeNotifyBan(data []byte) (uint8, bool) {
+	if len(data) < 3 {
+		return 0, false
+	}
+	return data[2], true
+}
+
+// DisconnectRequest (CZ_REQ_DISCONNECT 0x018A) asks to log out.
+type DisconnectRequest struct {
+	PacketID uint16 // 0x018A
+	Type     uint16 // Always 0
+}
+
+// Size returns packet size.
+func (p *DisconnectRequest) Size() int {
+	return 4
+}
+
+// Encode encodes the packet.
+func (p *DisconnectRequest) Encode() []byte {
+	return []byte{byte(p.PacketID), byte(p.PacketID >> 8), byte(p.Type), byte(p.Type >> 8)}
+}
+
+// DecodeDisconnectAck parses ZC_ACK_REQ_DISCONNECT (4 bytes) and reports
+// whether the logout was allowed; the server refuses it shortly after
+// combat. Returns false for ok on short data.
+func DecodeDisconnectAck(data []byte) (allowed, ok bool) {
+	if len(data) < 4 {
+		return false, false
+	}
+	return readU16(data, 2) == 0, true
+}
+
+// DecodeRestartAck parses ZC_RESTART_ACK (3 bytes), the map server's
+// answer to returning to character select, and reports whether the char
+// server accepted it. Returns false for ok on short data.
+func DecodeRestartAck(data []byte) (accepted, ok bool) {
+	if len(data) < 3 {
+		return false, false
+	}
+	return data[2] == 1, true
+}
+
+// DecodeBroadcast parses ZC_BROADCAST (variable): header(2) + length(2) +
+// message. Returns false on short data.
+func DecodeBroadcast(data []byte) (string, bool) {
+	if len(data) < 4 {
+		return "", false
+	}
+	n := min(int(readU16(data, 2)), len(data))
+	if n < 4 {
+		return "", false
+	}
+	return readString(data[4:n]), true
+}
+
+// Announcement colors, as 0xRRGGBB.
+const (
+	BroadcastYellow uint32 = 0xFFFF00 // ZC_BROADCAST's default
+	BroadcastBlue   uint32 = 0x00FFFF // ZC_BROADCAST messages prefixed "blue"
+)
+
+// BroadcastDefaultFontSize is the font size of ZC_BROADCAST messages,
+// which carry none.
+const BroadcastDefaultFontSize = 12
+
+// Broadcast is a server-wide announcement with its style.
+type Broadcast struct {
+	Message  string
+	Color    uint32 // 0xRRGGBB
+	FontSize uint16 // In points; 12 is the chat font's
+}
+
+// PlainBroadcast styles a ZC_BROADCAST message. The server marks blue
+// ones with a "blue" prefix and some others with "ssss"; both are
+// stripped.
+func PlainBroadcast(msg string) Broadcast {
+	b := Broadcast{Message: msg, Color: BroadcastYellow, FontSize: BroadcastDefaultFontSize}
+	switch {
+	case strings.HasPrefix(msg, "blue"):
+		b.Message, b.Color = msg[4:], BroadcastBlue
+	case strings.HasPrefix(msg, "ssss"):
+		b.Message = msg[4:]
+	}
+	return b
+}
+
+// DecodeBroadcast2 parses ZC_BROADCAST2 (variable): header(2) + length(2)
+// + color(4, 0xRRGGBB) + font type(2) + font size(2) + alignment(2) +
+// y(2) + message. A zero font size is the default. Returns false on short
+// data.
+func DecodeBroadcast2(data []byte) (Broadcast, bool) {
+	if len(data) < 16 {
+		return Broadcast{}, false
+	}
+	n := min(int(readU16(data, 2)), len(data))
+	if n < 16 {
+		return Broadcast{}, false
+	}
+	b := Broadcast{
+		Message:  readString(data[16:n]),
+		Color:    readU32(data, 4) & 0xFFFFFF,
+		FontSize: readU16(data, 10),
+	}
+	if b.FontSize == 0 {
+		b.FontSize = BroadcastDefaultFontSize
+	}
+	return b, true
+}
+
+// UnitChat is what a unit in view said, as "Name : message".
+type UnitChat struct {
+	ID      uint32
+	Message string
+}
+
+// DecodeUnitChat parses ZC_NOTIFY_CHAT (variable): header(2) + length(2)
+// + unit ID(4) + message. Returns false on short data.
+func DecodeUnitChat(data []byte) (UnitChat, bool) {
+	if len(data) < 8 {
+		return UnitChat{}, false
+	}
+	n := min(int(readU16(data, 2)), len(data))
+	if n < 8 {
+		return UnitChat{}, false
+	}
+	return UnitChat{ID: readU32(data, 4), Message: readString(data[8:n])}, true
+}
+
+// DecodePlayerChat parses ZC_NOTIFY_PLAYERCHAT (variable): header(2) +
+// length(2) + message, laid out like ZC_BROADCAST. Returns false on short
+// data.
+func DecodePlayerChat(data []byte) (string, bool) {
+	return DecodeBroadcast(data)
+}
+
+// Map properties carried by ZC_MAPPROPERTY_R2 (rAthena MAPPROPERTY_*).
+const (
+	MapPropertyNothing       uint16 = 0 // Normal map
+	MapPropertyFreePVPZone   uint16 = 1 // PVP map
+	MapPropertyEventPVPZone  uint16 = 2 // Event PVP map
+	MapPropertyAgitZone      uint16 = 3 // War of Emperium castle or GvG map
+	MapPropertyPKServerZone  uint16 = 4 // PK server field
+	MapPropertyPVPServerZone uint16 = 5 // PVP server field
+	MapPropertyDenySkillZone uint16 = 6 // Skills disabled
+)
+
+// Map flags carried by ZC_MAPPROPERTY_R2.
+const (
+	MapFlagParty            uint32 = 1 << 0  // Players outside the party are attackable (PVP)
+	MapFlagGuild            uint32 = 1 << 1  // Players outside the guild are attackable (GvG)
+	MapFlagSiege            uint32 = 1 << 2  // Guild emblems show over heads (castles)
+	MapFlagSimpleEffect     uint32 = 1 << 3  // Reduced effects (/mineffect)
+	MapFlagDisableLockOn    uint32 = 1 << 4  // Players are only attacked with shift held
+	MapFlagCountPK          uint32 = 1 << 5  // The PVP rank counter shows
+	MapFlagNoPartyFormation uint32 = 1 << 6  // Parties can't be formed or changed
+	MapFlagBattlefield      uint32 = 1 << 7  // Battleground map
+	MapFlagNoCostume        uint32 = 1 << 8  // Costume sprites are hidden
+	MapFlagUseCart          uint32 = 1 << 9  // The cart can be opened
+	MapFlagSunMoonStar      uint32 = 1 << 10 // Star Gladiator's Miracle can trigger
+)
+
+// MapProperty is the current map's rules (ZC_MAPPROPERTY_R2).
+type MapProperty struct {
+	Property uint16 // MapProperty* value
+	Flags    uint32 // MapFlag* bits
+}
+
+// Has reports whether the map has all of the given MapFlag* bits.
+func (p MapProperty) Has(flags uint32) bool {
+	return p.Flags&flags == flags
+}
+
+// DecodeMapProperty parses ZC_MAPPROPERTY_R2 (8 bytes): header(2) +
+// property(2) + flags(4). Returns false on short data.
+func DecodeMapProperty(data []byte) (MapProperty, bool) {
+	if len(data) < 8 {
+		return MapProperty{}, false
+	}
+	return MapProperty{Property: readU16(data, 2), Flags: readU32(data, 4)}, true
+}
+
+// PVPRank is the player's PVP standing on the map (ZC_NOTIFY_RANKING).
+type PVPRank struct {
+	AccountID uint32
+	Rank      int // 1 for the best
+	Total     int // Players ranked on the map
+}
+
+// DecodePVPRank parses ZC_NOTIFY_RANKING (14 bytes): header(2) + aid(4) +
+// rank(4) + total(4). Returns false on short data.
+func DecodePVPRank(data []byte) (PVPRank, bool) {
+	if len(data) < 14 {
+		return PVPRank{}, false
+	}
+	return PVPRank{
+		AccountID: readU32(data, 2),
+		Rank:      int(readU32(data, 6)),
+		Total:     int(readU32(data, 10)),
+	}, true
+}
+
+// RefuseLogin is a login refusal, AC_REFUSE_LOGIN or AC_REFUSE_LOGIN2.
+type RefuseLogin struct {
+	Code      uint8
+	BlockDate string // End of a temporary ban, "" if none
+}
+
+// DecodeRefuseLogin parses AC_REFUSE_LOGIN (23 bytes: header(2) + code(1)
+// + date(20)) or AC_REFUSE_LOGIN2 (26 bytes: header(2) + code(4) +
+// date(20)). Returns nil on short data.
+func DecodeRefuseLogin(data []byte) *RefuseLogin {
+	if len(data) < 3 {
+		return nil
+	}
+	codeLen := 1
+	if readU16(data, 0) == AC_REFUSE_LOGIN2 {
+		codeLen = 4
+	}
+	r := &RefuseLogin{Code: data[2]}
+	if start := 2 + codeLen; len(data) > start {
+		r.BlockDate = readString(data[start:min(start+20, len(data))])
+	}
+	return r
+}
+
+// Login refusal codes that mean the server is busy rather than the login
+// being wrong: worth retrying after a while.
+const (
+	RefuseServerBusy uint8 = 7 // Server over populated
+)
+
+// LoginQueue is the player's place in a busy server's login queue.
+type LoginQueue struct {
+	Position int // 1 for next in line
+	// RetryAfter, if nonzero, asks the client to disconnect and log in
+	// again after that many seconds instead of waiting connected
+	RetryAfter int
+}
+
+// DecodeLoginQueue parses AC_LOGIN_QUEUE (10 bytes): header(2) +
+// position(4) + retry seconds(4). Returns false on short data.
+func DecodeLoginQueue(data []byte) (LoginQueue, bool) {
+	if len(data) < 10 {
+		return LoginQueue{}, false
+	}
+	return LoginQueue{
+		Position:   int(readU32(data, 2)),
+		RetryAfter: int(readU32(data, 6)),
+	}, true
+}
+
+// Restart types (CZ_RESTART).
+const (
+	RestartSavePoint  uint8 = 0
+	RestartCharSelect uint8 = 1
+)
+
+// ByteRequest is a request carrying a single byte: CZ_ACK_EXCHANGE_ITEM
+// (Trade* answer) and CZ_RESTART (Restart* type).
+type ByteRequest struct {
+	PacketID uint16
+	Value    uint8
+}
+
+// Size returns packet size.
+func (p *ByteRequest) Size() int {
+	return 3
+}
+
+// Encode encodes the packet.
+func (p *ByteRequest) Encode() []byte {
+	return []byte{byte(p.PacketID), byte(p.PacketID >> 8), p.Value}
+}
+
+// PartyInviteReply (CZ_PARTY_JOIN_REQ_ACK 0x02C7) answers a party
+// invitation.
+type PartyInviteReply struct {
+	PacketID uint16 // 0x02C7
+	PartyID  uint32
+	Accept   bool
+}
+
+// Size returns packet size.
+func (p *PartyInviteReply) Size() int {
+	return 7
+}
+
+// Encode encodes the packet.
+func (p *PartyInviteReply) Encode() []byte {
+	buf := make([]byte, p.Size())
+	writeU16(buf, 0, p.PacketID)
+	writeU32(buf, 2, p.PartyID)
+	if p.Accept {
+		buf[6] = 1
+	}
+	return buf
+}
+
+// GuildInviteReply (CZ_JOIN_GUILD 0x016B) answers a guild invitation.
+type GuildInviteReply struct {
+	PacketID uint16 // 0x016B
+	GuildID  uint32
+	Accept   bool
+}
+
+// Size returns packet size.
+func (p *GuildInviteReply) Size() int {
+	return 10
+}
+
+// Encode encodes the packet.
+func (p *GuildInviteReply) Encode() []byte {
+	buf := make([]byte, p.Size())
+	writeU16(buf, 0, p.PacketID)
+	writeU32(buf, 2, p.GuildID)
+	if p.Accept {
+		writeU32(buf, 6, 1)
+	}
+	return buf
+}
+
+// LoadingComplete (CZ_NOTIFY_ACTORINIT 0x007D) packet.
+type LoadingComplete struct {
+	PacketID uint16 // 0x007D
+}
+
+// Size returns packet size.
+func (p *LoadingComplete) Size() int {
+	return 2
+}
+
+// Encode encodes the packet.
+func (p *LoadingComplete) Encode() []byte {
+	return []byte{byte(p.PacketID), byte(p.PacketID >> 8)}
+}
+
+// Unit object types of the spawn entries (rAthena clif_bl_type).
+const (
+	UnitPC         uint8 = 0x0
+	UnitNPC        uint8 = 0x1
+	UnitItem       uint8 = 0x2
+	UnitSkill      uint8 = 0x3
+	UnitMonster    uint8 = 0x5
+	UnitEventNPC   uint8 = 0x6
+	UnitPet        uint8 = 0x7
+	UnitHomunculus uint8 = 0x8
+	UnitMercenary  uint8 = 0x9
+	UnitElemental  uint8 = 0xA
+)
+
+// Idle entry states (rAthena view_data dead_sit).
+const (
+	UnitStateStanding uint8 = 0
+	UnitStateDead     uint8 = 1
+	UnitStateSitting  uint8 = 2
+)
+
+// UnitEntry is a unit in view: ZC_NOTIFY_STANDENTRY11 (idle),
+// ZC_NOTIFY_NEWENTRY11 (just spawned) or ZC_NOTIFY_MOVEENTRY11 (walking).
+type UnitEntry struct {
+	ObjectType  uint8  // One of the Unit* constants
+	ID          uint32 // Account ID of players, game ID of other units
+	CharID      uint32
+	Speed       uint16 // Milliseconds per cell
+	BodyState   uint16
+	HealthState uint16
+	Option      uint32 // OPTION_* flags: cart, riding, hiding
+	Job         uint16 // Job of players, class of monsters, pets and NPCs
+	Head        uint16 // Hair style
+	Weapon      uint32
+	Shield      uint32
+	HeadBottom  uint16
+	HeadTop     uint16
+	HeadMid     uint16
+	HeadPalette uint16
+	BodyPalette uint16
+	HeadDir     uint16
+	Robe        uint16
+	GuildID     uint32
+	Male        bool
+	X, Y        int
+	Dir         uint8
+	Walking     bool // DestX, DestY are set
+	Spawned     bool // Just appeared, rather than came into view
+	DestX       int
+	DestY       int
+	State       uint8 // UnitState* of idle entries
+	Level       uint16
+	MaxHP       int32
+	HP          int32
+	Body        uint16 // Alternate body (costume) style
+	Name        string
+}
+
+// Sizes of the unit entries for our packetver.
+const (
+	unitIdleSize  = 108
+	unitSpawnSize = 107
+	unitWalkSize  = 114
+)
+
+// DecodeUnitEntry parses ZC_NOTIFY_STANDENTRY11, ZC_NOTIFY_NEWENTRY11 or
+// ZC_NOTIFY_MOVEENTRY11. The three share their first 35 bytes; walking
+// entries then insert a move start tick and carry a start and destination
+// cell instead of one. Returns nil on short data or another packet.
+func DecodeUnitEntry(data []byte) *UnitEntry {
+	if len(data) < 2 {
+		return nil
+	}
+	id := readU16(data, 0)
+	var size int
+	switch id {
+	case ZC_NOTIFY_STANDENTRY11:
+		size = unitIdleSize
+	case ZC_NOTIFY_NEWENTRY11:
+		size = unitSpawnSize
+	case ZC_NOTIFY_MOVEENTRY11:
+		size = unitWalkSize
+	default:
+		return nil
+	}
+	if len(data) < size {
+		return nil
+	}
+
+	u := &UnitEntry{
+		ObjectType:  data[4],
+		ID:          readU32(data, 5),
+		CharID:      readU32(data, 9),
+		Speed:       readU16(data, 13),
+		BodyState:   readU16(data, 15),
+		HealthState: readU16(data, 17),
+		Option:      readU32(data, 19),
+		Job:         readU16(data, 23),
+		Head:        readU16(data, 25),
+		Weapon:      readU32(data, 27),
+		Shield:      readU32(data, 31),
+		HeadBottom:  readU16(data, 35),
+	}
+
+	// Walking entries carry the move start tick after the first accessory
+	off := 37
+	if id == ZC_NOTIFY_MOVEENTRY11 {
+		off += 4
+	}
+	u.HeadTop = readU16(data, off)
+	u.HeadMid = readU16(data, off+2)
+	u.HeadPalette = readU16(data, off+4)
+	u.BodyPalette = readU16(data, off+6)
+	u.HeadDir = readU16(data, off+8)
+	u.Robe = readU16(data, off+10)
+	u.GuildID = readU32(data, off+12)
+	u.Male = data[off+25] != 0
+	off += 26
+
+	if id == ZC_NOTIFY_MOVEENTRY11 {
+		u.X, u.Y, u.DestX, u.DestY = unpackPos2(data[off : off+6])
+		u.Walking = true
+		off += 6
+	} else {
+		u.Spawned = id == ZC_NOTIFY_NEWENTRY11
+		u.X, u.Y, u.Dir = unpackPosDir(data[off : off+3])
+		off += 3
+	}
+	off += 2 // xSize, ySize
+	if id == ZC_NOTIFY_STANDENTRY11 {
+		u.State = data[off]
+		off++
+	}
+	u.Level = readU16(data, off)
+	u.MaxHP = int32(readU32(data, off+4))
+	u.HP = int32(readU32(data, off+8))
+	u.Body = readU16(data, off+13)
+	u.Name = readString(data[off+15 : off+15+nameLen])
+	return u
+}
+
+// Reasons a unit vanished (ZC_NOTIFY_VANISH type).
+const (
+	VanishOutOfSight uint8 = 0
+	VanishDied       uint8 = 1
+	VanishLoggedOut  uint8 = 2
+	VanishTeleported uint8 = 3
+)
+
+// DecodeVanish parses ZC_NOTIFY_VANISH (7 bytes): header(2) + ID(4) +
+// reason(1). Returns false on short data.
+func DecodeVanish(data []byte) (id uint32, reason uint8, ok bool) {
+	if len(data) < 7 {
+		return 0, 0, false
+	}
+	return readU32(data, 2), data[6], true
+}
+
+// StateChange (ZC_STATE_CHANGE3 0x0229, 15 bytes) is a unit's new status
+// and option flags, e.g. mounting a Peco Peco or renting a cart.
+type StateChange struct {
+	ID          uint32
+	BodyState   uint16
+	HealthState uint16
+	Option      uint32
+	PKMode      bool
+}
+
+// DecodeStateChange parses ZC_STATE_CHANGE3. Returns nil on short data.
+func DecodeStateChange(data []byte) *StateChange {
+	if len(data) < 15 {
+		return nil
+	}
+	return &StateChange{
+		ID:          readU32(data, 2),
+		BodyState:   readU16(data, 6),
+		HealthState: readU16(data, 8),
+		Option:      readU32(data, 10),
+		PKMode:      data[14] != 0,
+	}
+}
+
+// EFST_SKE is the status rAthena puts on every player for the night of
+// its day/night cycle, which official clients draw as darkness.
+const EFST_SKE uint16 = 149
+
+// StatusChange (ZC_MSG_STATE_CHANGE 0x0196, 9 bytes, or
+// ZC_MSG_STATE_CHANGE3 0x0983, 29 bytes) turns a unit's status on or off.
+// The values of ZC_MSG_STATE_CHANGE3 aren't kept.
+type StatusChange struct {
+	Status uint16 // EFST_* status ID
+	ID     uint32 // Unit the status is on
+	On     bool
+	Total  uint32 // Duration in ms, ZC_MSG_STATE_CHANGE3 only; 0 without a timer
+	Left   uint32 // Time left in ms, ZC_MSG_STATE_CHANGE3 only
+}
+
+// DecodeStatusChange parses ZC_MSG_STATE_CHANGE and ZC_MSG_STATE_CHANGE3.
+// Returns nil on short data.
+func DecodeStatusChange(data []byte) *StatusChange {
+	if len(data) < 9 {
+		return nil
+	}
+	sc := &StatusChange{
+		Status: readU16(data, 2),
+		ID:     readU32(data, 4),
+		On:     data[8] != 0,
+	}
+	if len(data) >= 17 {
+		sc.Total = readU32(data, 9)
+		sc.Left = readU32(data, 13)
+	}
+	return sc
+}
+
+// Pet state change types (ZC_CHANGESTATE_PET type).
+const (
+	PetStateOwned       uint8 = 0 // The pet is the player's own
+	PetStateIntimacy    uint8 = 1
+	PetStateHunger      uint8 = 2
+	PetStateAccessory   uint8 = 3
+	PetStatePerformance uint8 = 4
+	PetStateHairStyle   uint8 = 5
+)
+
+// PetState (ZC_CHANGESTATE_PET 0x01A4, 11 bytes) is a change of a pet's
+// state, one of the PetState* types.
+type PetState struct {
+	Type  uint8
+	ID    uint32
+	Value int32
+}
+
+// DecodePetState parses ZC_CHANGESTATE_PET. Returns nil on short data.
+func DecodePetState(data []byte) *PetState {
+	if len(data) < 11 {
+		return nil
+	}
+	return &PetState{Type: data[2], ID: readU32(data, 3), Value: int32(readU32(data, 7))}
+}
+
+// Cutin positions (ZC_SHOW_IMAGE2 type).
+const (
+	CutinBottomLeft   uint8 = 0
+	CutinBottomCenter uint8 = 1
+	CutinBottomRight  uint8 = 2
+	CutinWindow       uint8 = 3 // Centered, in a window the player can move
+	CutinCenter       uint8 = 4
+	CutinClear        uint8 = 255 // Hides the illustration shown
+)
+
+// cutinNameLen is the fixed size of a cutin image name, NUL included.
+const cutinNameLen = 64
+
+// DecodeShowImage parses ZC_SHOW_IMAGE2 (67 bytes): header(2) + image
+// name(64) + position(1). The name has no folder and usually no
+// extension. Returns false on short data.
+func DecodeShowImage(data []byte) (image string, position uint8, ok bool) {
+	if len(data) < 3+cutinNameLen {
+		return "", 0, false
+	}
+	return readString(data[2 : 2+cutinNameLen]), data[2+cutinNameLen], true
+}
+
+// DecodeSayDialog parses ZC_SAY_DIALOG: header(2) + length(2) + NPC ID(4)
+// + message. Returns false on short data.
+func DecodeSayDialog(data []byte) (npcID uint32, message string, ok bool) {
+	if len(data) < 8 {
+		return 0, "", false
+	}
+	end := min(int(readU16(data, 2)), len(data))
+	if end < 8 {
+		return 0, "", false
+	}
+	return readU32(data, 4), readString(data[8:end]), true
+}
+
+// DecodeWaitDialog parses ZC_WAIT_DIALOG (6 bytes) and returns the NPC's
+// ID, or false on short data.
+func DecodeWaitDialog(data []byte) (uint32, bool) {
+	if len(data) < 6 {
+		return 0, false
+	}
+	return readU32(data, 2), true
+}
+
+// DecodeCloseDialog parses ZC_CLOSE_DIALOG (6 bytes) and returns the
+// NPC's ID, or false on short data.
+func DecodeCloseDialog(data []byte) (uint32, bool) {
+	if len(data) < 6 {
+		return 0, false
+	}
+	return readU32(data, 2), true
+}
+
+// Helper functions for packet encoding/decoding
+
+func readU16(data []byte, offset int) uint16 {
+	return uint16(data[offset]) | uint16(data[offset+1])<<8
+}
+
+func readU32(data []byte, offset int) uint32 {
+	return uint32(data[offset]) | uint32(data[offset+1])<<8 |
+		uint32(data[offset+2])<<16 | uint32(data[offset+3])<<24
+}
+
+// readString returns a NUL-terminated string from a fixed-size field.
+func readString(field []byte) string {
+	for i, b := range field {
+		if b == 0 {
+			return string(field[:i])
+		}
+	}
+	return string(field)
+}
+
+func writeU16(buf []byte, offset int, v uint16) {
+	buf[offset] = byte(v)
+	buf[offset+1] = byte(v >> 8)
+}
+
+func writeU32(buf []byte, offset int, v uint32) {
+	buf[offset] = byte(v)
+	buf[offset+1] = byte(v >> 8)
+	buf[offset+2] = byte(v >> 16)
+	buf[offset+3] = byte(v >> 24)
+}
+
+// unpackPosDir unpacks a cell and direction (rAthena RBUFPOS layout):
+// x:10 | y:10 | dir:4.
+func unpackPosDir(b []byte) (x, y int, dir uint8) {
+	x = int(b[0])<<2 | int(b[1])>>6
+	y = (int(b[1])&0x3F)<<4 | int(b[2])>>4
+	return x, y, b[2] & 0x0F
+}
+
+// unpackPos2 unpacks a start and end cell (rAthena RBUFPOS2 layout):
+// x0:10 | y0:10 | x1:10 | y1:10 | sx:4 | sy:4.
+func unpackPos2(b []byte) (x0, y0, x1, y1 int) {
+	x0 = int(b[0])<<2 | int(b[1])>>6
+	y0 = (int(b[1])&0x3F)<<4 | int(b[2])>>4
+	x1 = (int(b[2])&0x0F)<<6 | int(b[3])>>2
+	y1 = (int(b[3])&0x03)<<8 | int(b[4])
+	return x0, y0, x1, y1
 }
@@ -2,6 +2,7 @@ package packets
 
 import (
 	"bytes"
+	"slices"
 	"testing"
 )
 
@@ -142,6 +143,109 @@ func TestTickSendEncode(t *testing.T) {
 	}
 }
 
+func TestActionRequestEncode(t *testing.T) {
+	pkt := &ActionRequest{PacketID: CZ_REQUEST_ACT2, TargetID: 0x01020304, Action: ActionSit}
+
+	data := pkt.Encode()
+
+	want := []byte{0x37, 0x04, 0x04, 0x03, 0x02, 0x01, 0x02}
+	if !bytes.Equal(data, want) {
+		t.Errorf("Encode() = % x, want % x", data, want)
+	}
+}
+
+func TestSkillRequestEncode(t *testing.T) {
+	pkt := &SkillRequest{PacketID: CZ_USE_SKILL, Level: 10, SkillID: 5, TargetID: 0x01020304}
+
+	data := pkt.Encode()
+
+	want := []byte{0x38, 0x04, 0x0A, 0x00, 0x05, 0x00, 0x04, 0x03, 0x02, 0x01}
+	if !bytes.Equal(data, want) {
+		t.Errorf("Encode() = % x, want % x", data, want)
+	}
+}
+
+func TestEmotionRequestEncode(t *testing.T) {
+	pkt := &EmotionRequest{PacketID: CZ_REQ_EMOTION, Type: 5}
+
+	if data := pkt.Encode(); !bytes.Equal(data, []byte{0xBF, 0x00, 0x05}) {
+		t.Errorf("Encode() = % x, want bf 00 05", data)
+	}
+}
+
+func TestUserCount(t *testing.T) {
+	if data := (&UserCountRequest{PacketID: CZ_REQ_USER_COUNT}).Encode(); !bytes.Equal(data, []byte{0xC1, 0x00}) {
+		t.Errorf("UserCountRequest = % x, want c1 00", data)
+	}
+	if n, ok := DecodeUserCount([]byte{0xC2, 0x00, 0x39, 0x05, 0, 0}); n != 1337 || !ok {
+		t.Errorf("DecodeUserCount = %d, %v, want 1337, true", n, ok)
+	}
+	if _, ok := DecodeUserCount([]byte{0xC2, 0x00, 0x39, 0x05, 0}); ok {
+		t.Error("DecodeUserCount accepted short data")
+	}
+}
+
+func TestPlayerInteractionEncode(t *testing.T) {
+	name := func(s string) []byte {
+		b := make([]byte, 24)
+		copy(b, s)
+		return b
+	}
+
+	tests := []struct {
+		name string
+		got  []byte
+		want []byte
+	}{
+		{
+			"whisper",
+			(&Whisper{PacketID: CZ_WHISPER, Target: "Alice", Message: "hi"}).Encode(),
+			append(append([]byte{0x96, 0x00, 31, 0x00}, name("Alice")...), 'h', 'i', 0),
+		},
+		{
+			"block whispers",
+			(&WhisperSetting{PacketID: CZ_SETTING_WHISPER_PC, Name: "Bob", Type: WhisperBlock}).Encode(),
+			append(append([]byte{0xCF, 0x00}, name("Bob")...), 0),
+		},
+		{
+			"trade",
+			(&AccountRequest{PacketID: CZ_REQ_EXCHANGE_ITEM, AccountID: 2000001}).Encode(),
+			[]byte{0xE4, 0x00, 0x81, 0x84, 0x1E, 0x00},
+		},
+		{
+			"party invite",
+			(&PartyInvite{PacketID: CZ_PARTY_JOIN_REQ, Name: "Carol"}).Encode(),
+			append([]byte{0xC4, 0x02}, name("Carol")...),
+		},
+		{
+			"long name is truncated and terminated",
+			(&PartyInvite{PacketID: CZ_PARTY_JOIN_REQ, Name: "ABCDEFGHIJKLMNOPQRSTUVWXYZ"}).Encode(),
+			append([]byte{0xC4, 0x02}, append([]byte("ABCDEFGHIJKLMNOPQRSTUVW"), 0)...),
+		},
+	}
+
+	for _, tt := range tests {
+		if !bytes.Equal(tt.got, tt.want) {
+			t.Errorf("%s: Encode() = % x, want % x", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestDecodeNotifyTime(t *testing.T) {
+	data := []byte{0x7F, 0x00, 0x78, 0x56, 0x34, 0x12}
+
+	pkt := DecodeNotifyTime(data)
+	if pkt == nil {
+		t.Fatal("DecodeNotifyTime returned nil")
+	}
+	if pkt.ServerTick != 0x12345678 {
+		t.Errorf("ServerTick = %#x, want 0x12345678", pkt.ServerTick)
+	}
+	if DecodeNotifyTime(data[:5]) != nil {
+		t.Error("expected nil for short data")
+	}
+}
+
 func TestDecodePlayerMove(t *testing.T) {
 	// Build a synthetic ZC_NOTIFY_PLAYERMOVE: header(0x0087) + tick(4) + packed positions(6)
 	// Pack (x0, y0, x1, y1) = (10, 20, 30, 40) using WBUFPOS2 layout.
@@ -174,6 +278,79 @@ func TestDecodePlayerMove(t *testing.T) {
 	}
 }
 
+func TestDecodeChangeCellType(t *testing.T) {
+	b := make([]byte, 24)
+	b[0], b[1] = 0x92, 0x01
+	b[2], b[3] = 0x2C, 0x01 // x = 300
+	b[4], b[5] = 0x96, 0x00 // y = 150
+	b[6], b[7] = 0x05, 0x00 // type = 5 (blocked, snipeable)
+	copy(b[8:], "prontera.gat")
+
+	p := DecodeChangeCellType(b)
+	if p == nil {
+		t.Fatal("DecodeChangeCellType returned nil")
+	}
+	if p.X != 300 || p.Y != 150 || p.Type != 5 {
+		t.Errorf("unexpected cell change %+v", p)
+	}
+	if p.GetMapName() != "prontera.gat" {
+		t.Errorf("expected map prontera.gat, got %q", p.GetMapName())
+	}
+
+	if DecodeChangeCellType(b[:23]) != nil {
+		t.Error("expected nil for short packet")
+	}
+}
+
+func TestDecodeParChange(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   []byte
+		decode func([]byte) *ParChange
+		want   ParChange
+	}{
+		{
+			name:   "base level",
+			data:   []byte{0xB0, 0x00, 0x0B, 0x00, 0x63, 0x00, 0x00, 0x00},
+			decode: DecodeParChange,
+			want:   ParChange{VarID: VarBaseLevel, Value: 99},
+		},
+		{
+			name:   "negative value",
+			data:   []byte{0xB0, 0x00, 0x05, 0x00, 0xFF, 0xFF, 0xFF, 0xFF},
+			decode: DecodeParChange,
+			want:   ParChange{VarID: VarHP, Value: -1},
+		},
+		{
+			name:   "long unsigned",
+			data:   []byte{0xB1, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x80},
+			decode: DecodeLongParChange,
+			want:   ParChange{VarID: VarBaseExp, Value: 0x80000000},
+		},
+		{
+			name:   "long int64",
+			data:   []byte{0xCB, 0x0A, 0x16, 0x00, 0x00, 0xF2, 0x05, 0x2A, 0x01, 0x00, 0x00, 0x00},
+			decode: DecodeLongParChange2,
+			want:   ParChange{VarID: VarNextBaseExp, Value: 5000000000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.decode(tt.data)
+			if p == nil {
+				t.Fatal("decode returned nil")
+			}
+			if *p != tt.want {
+				t.Errorf("got %+v, want %+v", *p, tt.want)
+			}
+			if tt.decode(tt.data[:len(tt.data)-1]) != nil {
+				t.Error("expected nil for short packet")
+			}
+		})
+	}
+}
+
 func TestMapAcceptDecode(t *testing.T) {
 	// Test packet with position (100, 150, dir 4)
 	// Position encoding in RO:
@@ -209,6 +386,25 @@ func TestMapAcceptDecode(t *testing.T) {
 	}
 }
 
+func TestDecodeMapMove(t *testing.T) {
+	data := make([]byte, 22)
+	data[0], data[1] = 0x91, 0x00
+	copy(data[2:], "geffen.gat")
+	data[18], data[19] = 0x77, 0x00
+	data[20], data[21] = 0x2C, 0x01
+
+	mv := DecodeMapMove(data)
+	if mv == nil {
+		t.Fatal("DecodeMapMove returned nil")
+	}
+	if *mv != (MapMove{MapName: "geffen.gat", X: 119, Y: 300}) {
+		t.Errorf("DecodeMapMove = %+v", *mv)
+	}
+	if DecodeMapMove(data[:21]) != nil {
+		t.Error("DecodeMapMove accepted short data")
+	}
+}
+
 func TestCharInfoDecode(t *testing.T) {
 	// Create a minimal char info packet
 	data := make([]byte, CharInfoSize)
@@ -294,3 +490,806 @@ func TestLoadingCompleteEncode(t *testing.T) {
 		t.Errorf("expected packet ID 0x007D, got %02x%02x", data[1], data[0])
 	}
 }
+
+func TestItemThrowEncode(t *testing.T) {
+	got := (&ItemThrow{PacketID: CZ_ITEM_THROW, Index: 5, Count: 300}).Encode()
+	want := []byte{0x63, 0x03, 0x05, 0x00, 0x2C, 0x01}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Encode() = % X, want % X", got, want)
+	}
+}
+
+func TestDecodeInventoryNormal(t *testing.T) {
+	entry := func(index uint16, itemID uint32, itemType uint8, count uint16, flags uint8) []byte {
+		b := make([]byte, inventoryNormalSize)
+		writeU16(b, 0, index)
+		writeU32(b, 2, itemID)
+		b[6] = itemType
+		writeU16(b, 7, count)
+		b[33] = flags
+		return b
+	}
+	data := []byte{0x09, 0x0B, 0, 0, 0}
+	data = append(data, entry(2, 501, 0, 30, 1)...)
+	data = append(data, entry(3, 909, 3, 1000, 1)...)
+	writeU16(data, 2, uint16(len(data)))
+
+	items := DecodeInventoryNormal(data)
+	if len(items) != 2 {
+		t.Fatalf("len(items) = %d, want 2", len(items))
+	}
+	want := InventoryItem{Index: 3, ItemID: 909, Type: 3, Count: 1000, Identified: true}
+	if items[1] != want {
+		t.Errorf("items[1] = %+v, want %+v", items[1], want)
+	}
+
+	// A truncated trailing entry is ignored
+	if items := DecodeInventoryNormal(data[:len(data)-1]); len(items) != 1 {
+		t.Errorf("truncated: len(items) = %d, want 1", len(items))
+	}
+}
+
+func TestDecodeItemPickupAck(t *testing.T) {
+	data := make([]byte, 70)
+	writeU16(data, 0, ZC_ITEM_PICKUP_ACK)
+	writeU16(data, 2, 12)
+	writeU16(data, 4, 3)
+	writeU32(data, 6, 1201)
+	data[10] = 1
+	data[11] = 1
+	writeU32(data, 12, 4001)
+	writeU32(data, 28, 0x0002)
+	data[32] = 5
+	data[33] = PickupOK
+	data[68] = 4
+
+	want := ItemPickupAck{Index: 12, Amount: 3, ItemID: 1201, Identified: true, Damaged: true,
+		Cards: [4]uint32{4001}, Location: 0x0002, Type: 5, Result: PickupOK, Refine: 4}
+	if ack := DecodeItemPickupAck(data); ack == nil || *ack != want {
+		t.Errorf("DecodeItemPickupAck = %+v, want %+v", ack, want)
+	}
+	if DecodeItemPickupAck(data[:69]) != nil {
+		t.Error("DecodeItemPickupAck accepted short data")
+	}
+}
+
+func TestDecodeInventoryEquip(t *testing.T) {
+	b := make([]byte, equipItemSize)
+	writeU16(b, 0, 5)
+	writeU32(b, 2, 2301)
+	b[6] = 4
+	writeU32(b, 7, 0x0010)
+	writeU32(b, 11, 0x0010)
+	writeU32(b, 15, 4001)
+	b[65] = 7
+	b[67] = 1
+	data := append([]byte{0x39, 0x0B, 0, 0, 0}, b...)
+	writeU16(data, 2, uint16(len(data)))
+
+	items := DecodeInventoryEquip(data)
+	want := EquipItem{Index: 5, ItemID: 2301, Type: 4, Location: 0x10, WearState: 0x10, Refine: 7, Cards: [4]uint32{4001}, Identified: true}
+	if len(items) != 1 || items[0] != want {
+		t.Errorf("DecodeInventoryEquip = %+v, want %+v", items, want)
+	}
+	if items := DecodeInventoryEquip(data[:len(data)-1]); len(items) != 0 {
+		t.Errorf("truncated: len(items) = %d, want 0", len(items))
+	}
+
+	wear := (&WearEquip{PacketID: CZ_REQ_WEAR_EQUIP, Index: 5, Location: 0x10}).Encode()
+	if !bytes.Equal(wear, []byte{0x98, 0x09, 5, 0, 0x10, 0, 0, 0}) {
+		t.Errorf("WearEquip = % x", wear)
+	}
+	off := (&TakeoffEquip{PacketID: CZ_REQ_TAKEOFF_EQUIP, Index: 5}).Encode()
+	if !bytes.Equal(off, []byte{0xAB, 0x00, 5, 0}) {
+		t.Errorf("TakeoffEquip = % x", off)
+	}
+	ack, ok := DecodeEquipAck([]byte{0x99, 0x09, 5, 0, 0x10, 0, 0, 0, EquipLevelTooLow})
+	if want := (EquipAck{Index: 5, Location: 0x10, Result: EquipLevelTooLow}); !ok || ack != want {
+		t.Errorf("DecodeEquipAck = %+v, %v", ack, ok)
+	}
+	if _, ok := DecodeEquipAck([]byte{0x99, 0x09, 5, 0}); ok {
+		t.Error("DecodeEquipAck accepted short data")
+	}
+}
+
+func TestDecodeItemPackets(t *testing.T) {
+	ack := DecodeItemThrowAck([]byte{0xAF, 0x00, 0x04, 0x00, 0x0A, 0x00})
+	if ack == nil || ack.Index != 4 || ack.Count != 10 {
+		t.Errorf("DecodeItemThrowAck = %+v", ack)
+	}
+
+	fall := make([]byte, 24)
+	writeU16(fall, 0, ZC_ITEM_FALL_ENTRY)
+	writeU32(fall, 2, 7001)
+	writeU32(fall, 6, 909)
+	fall[12] = 1
+	writeU16(fall, 13, 150)
+	writeU16(fall, 15, 180)
+	fall[17], fall[18] = 3, 9
+	writeU16(fall, 19, 25)
+	got := DecodeItemFallEntry(fall)
+	want := &ItemFallEntry{ObjectID: 7001, ItemID: 909, Identified: true, X: 150, Y: 180, SubX: 3, SubY: 9, Count: 25}
+	if got == nil || *got != *want {
+		t.Errorf("DecodeItemFallEntry = %+v, want %+v", got, want)
+	}
+	if DecodeItemFallEntry(fall[:23]) != nil {
+		t.Error("DecodeItemFallEntry accepted short data")
+	}
+
+	if id, ok := DecodeItemDisappear([]byte{0xA1, 0x00, 0x59, 0x1B, 0x00, 0x00}); !ok || id != 7001 {
+		t.Errorf("DecodeItemDisappear = %d, %v", id, ok)
+	}
+}
+
+func TestDecodeStoreEntry(t *testing.T) {
+	data := make([]byte, 86)
+	writeU16(data, 0, ZC_STORE_ENTRY)
+	writeU32(data, 2, 2000001)
+	copy(data[6:], "Cheap Potions")
+	id, title, ok := DecodeStoreEntry(data)
+	if !ok || id != 2000001 || title != "Cheap Potions" {
+		t.Errorf("DecodeStoreEntry = %d, %q, %v", id, title, ok)
+	}
+	if _, _, ok := DecodeStoreEntry(data[:85]); ok {
+		t.Error("DecodeStoreEntry accepted short data")
+	}
+
+	if id, ok := DecodeDisappearEntry(data[:6]); !ok || id != 2000001 {
+		t.Errorf("DecodeDisappearEntry = %d, %v", id, ok)
+	}
+}
+
+func TestDecodeChatRoom(t *testing.T) {
+	title := "Event queue"
+	data := make([]byte, 17+len(title)+1)
+	writeU16(data, 0, ZC_ROOM_NEWENTRY)
+	writeU16(data, 2, uint16(len(data)))
+	writeU32(data, 4, 110001)
+	writeU32(data, 8, 42)
+	writeU16(data, 12, 20)
+	writeU16(data, 14, 3)
+	data[16] = ChatRoomArena
+	copy(data[17:], title)
+
+	want := ChatRoom{OwnerID: 110001, RoomID: 42, Limit: 20, Users: 3, Type: ChatRoomArena, Title: title}
+	if room := DecodeChatRoom(data); room == nil || *room != want {
+		t.Errorf("DecodeChatRoom = %+v, want %+v", room, want)
+	}
+	if room := DecodeChatRoom(data[:20]); room == nil || room.Title != "Eve" {
+		t.Errorf("truncated DecodeChatRoom = %+v, want the title cut at the data", room)
+	}
+	if DecodeChatRoom(data[:16]) != nil {
+		t.Error("DecodeChatRoom accepted short data")
+	}
+
+	if id, ok := DecodeDestroyRoom([]byte{0xD8, 0x00, 42, 0, 0, 0}); !ok || id != 42 {
+		t.Errorf("DecodeDestroyRoom = %d, %v", id, ok)
+	}
+}
+
+func TestDecodeVendingItemList(t *testing.T) {
+	entry := func(price uint32, amount, index uint16, itemID uint32) []byte {
+		b := make([]byte, vendingItemSize)
+		writeU32(b, 0, price)
+		writeU16(b, 4, amount)
+		writeU16(b, 6, index)
+		b[8] = 0
+		writeU32(b, 9, itemID)
+		b[13] = 1
+		b[15] = 4
+		writeU32(b, 16, 4001)
+		writeU32(b, 20, 4001)
+		return b
+	}
+	data := make([]byte, 12)
+	writeU16(data, 0, ZC_PC_PURCHASE_ITEMLIST_FROMMC2)
+	writeU32(data, 4, 2000001)
+	writeU32(data, 8, 77)
+	data = append(data, entry(50, 100, 2, 501)...)
+	data = append(data, entry(1500000, 1, 5, 1201)...)
+	writeU16(data, 2, uint16(len(data)))
+
+	list := DecodeVendingItemList(data)
+	if list == nil || list.VendorID != 2000001 || list.UniqueID != 77 || len(list.Items) != 2 {
+		t.Fatalf("DecodeVendingItemList = %+v", list)
+	}
+	want := VendingItem{Price: 1500000, Amount: 1, Index: 5, ItemID: 1201, Identified: true, Refine: 4, Cards: [4]uint32{4001, 4001}}
+	if list.Items[1] != want {
+		t.Errorf("Items[1] = %+v, want %+v", list.Items[1], want)
+	}
+	if DecodeVendingItemList(data[:11]) != nil {
+		t.Error("DecodeVendingItemList accepted short data")
+	}
+}
+
+func TestVendingPurchaseEncode(t *testing.T) {
+	got := (&VendingPurchase{
+		PacketID: CZ_PC_PURCHASE_ITEMLIST_FROMMC2,
+		VendorID: 2000001,
+		UniqueID: 77,
+		Items:    []VendingPurchaseItem{{Index: 2, Amount: 10}},
+	}).Encode()
+	want := []byte{0x01, 0x08, 0x10, 0x00, 0x81, 0x84, 0x1E, 0x00, 0x4D, 0x00, 0x00, 0x00, 0x0A, 0x00, 0x02, 0x00}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Encode() = % X, want % X", got, want)
+	}
+
+	res := DecodeVendingPurchaseResult([]byte{0x35, 0x01, 0x02, 0x00, 0x0A, 0x00, PurchaseNoZeny})
+	if res == nil || res.Index != 2 || res.Amount != 10 || res.Result != PurchaseNoZeny {
+		t.Errorf("DecodeVendingPurchaseResult = %+v", res)
+	}
+}
+
+func TestDecodeRequests(t *testing.T) {
+	data := make([]byte, 30)
+	writeU16(data, 0, ZC_PARTY_JOIN_REQ)
+	writeU32(data, 2, 42)
+	copy(data[6:], "Adventurers")
+	inv := DecodeInvitation(data)
+	if inv == nil || inv.ID != 42 || inv.Name != "Adventurers" {
+		t.Errorf("DecodeInvitation = %+v", inv)
+	}
+	if DecodeInvitation(data[:29]) != nil {
+		t.Error("DecodeInvitation accepted short data")
+	}
+
+	data = make([]byte, 32)
+	writeU16(data, 0, ZC_REQ_EXCHANGE_ITEM2)
+	copy(data[2:], "Trader")
+	writeU32(data, 26, 150001)
+	writeU16(data, 30, 87)
+	want := TradeRequest{Name: "Trader", CharID: 150001, Level: 87}
+	if req := DecodeTradeRequest(data); req == nil || *req != want {
+		t.Errorf("DecodeTradeRequest = %+v, want %+v", req, want)
+	}
+	if DecodeTradeRequest(data[:31]) != nil {
+		t.Error("DecodeTradeRequest accepted short data")
+	}
+}
+
+func TestDecodeFriendAndGuildMemberState(t *testing.T) {
+	data := make([]byte, 35)
+	writeU16(data, 0, ZC_FRIENDS_STATE)
+	writeU32(data, 2, 2000001)
+	writeU32(data, 6, 150001)
+	copy(data[11:], "Buddy")
+	want := FriendState{AccountID: 2000001, CharID: 150001, Online: true, Name: "Buddy"}
+	if st := DecodeFriendState(data); st == nil || *st != want {
+		t.Errorf("DecodeFriendState = %+v, want %+v", st, want)
+	}
+	data[10] = 1
+	if st := DecodeFriendState(data); st == nil || st.Online {
+		t.Errorf("offline DecodeFriendState = %+v", st)
+	}
+	if DecodeFriendState(data[:34]) != nil {
+		t.Error("DecodeFriendState accepted short data")
+	}
+
+	data = make([]byte, 20)
+	writeU16(data, 0, ZC_UPDATE_CHARSTAT2)
+	writeU32(data, 2, 2000002)
+	writeU32(data, 6, 150002)
+	writeU32(data, 10, 1)
+	wantMember := GuildMemberState{AccountID: 2000002, CharID: 150002, Online: true}
+	if st := DecodeGuildMemberState(data); st == nil || *st != wantMember {
+		t.Errorf("DecodeGuildMemberState = %+v, want %+v", st, wantMember)
+	}
+	if DecodeGuildMemberState(data[:19]) != nil {
+		t.Error("DecodeGuildMemberState accepted short data")
+	}
+}
+
+func TestDecodeMissionHunt(t *testing.T) {
+	data := make([]byte, 6+2*12)
+	writeU16(data, 0, ZC_UPDATE_MISSION_HUNT)
+	writeU16(data, 2, uint16(len(data)))
+	writeU16(data, 4, 2)
+	writeU32(data, 6, 7128001)
+	writeU32(data, 10, 7128001)
+	writeU16(data, 14, 10)
+	writeU16(data, 16, 3)
+	writeU32(data, 18, 7128002)
+	writeU32(data, 22, 7128002)
+	writeU16(data, 26, 5)
+	writeU16(data, 28, 5)
+
+	hunts := DecodeMissionHunt(data)
+	want := []QuestHunt{{HuntID: 7128001, MaxCount: 10, Count: 3}, {HuntID: 7128002, MaxCount: 5, Count: 5}}
+	if !slices.Equal(hunts, want) {
+		t.Fatalf("DecodeMissionHunt = %+v, want %+v", hunts, want)
+	}
+	if id := hunts[0].QuestID(); id != 7128 {
+		t.Errorf("QuestID = %d, want 7128", id)
+	}
+	if got := DecodeMissionHunt(data[:20]); len(got) != 1 {
+		t.Errorf("truncated DecodeMissionHunt = %+v, want the whole first entry", got)
+	}
+}
+
+func TestDecodeUnitNames(t *testing.T) {
+	data := make([]byte, 106)
+	writeU16(data, 0, ZC_ACK_REQNAMEALL2)
+	writeU32(data, 2, 150001)
+	copy(data[6:], "Knight")
+	copy(data[30:], "Adventurers")
+	copy(data[54:], "Valkyries")
+	copy(data[78:], "Guild Master")
+	writeU32(data, 102, 1000)
+
+	want := UnitNames{ID: 150001, Name: "Knight", Party: "Adventurers", Guild: "Valkyries", Position: "Guild Master", TitleID: 1000}
+	if names := DecodeUnitNames(data); names == nil || *names != want {
+		t.Errorf("DecodeUnitNames = %+v, want %+v", names, want)
+	}
+	if DecodeUnitNames(data[:105]) != nil {
+		t.Error("DecodeUnitNames accepted short data")
+	}
+}
+
+func TestRequestRepliesEncode(t *testing.T) {
+	tests := []struct {
+		name string
+		got  []byte
+		want []byte
+	}{
+		{
+			"party accept",
+			(&PartyInviteReply{PacketID: CZ_PARTY_JOIN_REQ_ACK, PartyID: 42, Accept: true}).Encode(),
+			[]byte{0xC7, 0x02, 0x2A, 0x00, 0x00, 0x00, 0x01},
+		},
+		{
+			"guild refuse",
+			(&GuildInviteReply{PacketID: CZ_JOIN_GUILD, GuildID: 7}).Encode(),
+			[]byte{0x6B, 0x01, 0x07, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+		},
+		{
+			"guild accept",
+			(&GuildInviteReply{PacketID: CZ_JOIN_GUILD, GuildID: 7, Accept: true}).Encode(),
+			[]byte{0x6B, 0x01, 0x07, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00},
+		},
+		{
+			"trade refuse",
+			(&ByteRequest{PacketID: CZ_ACK_EXCHANGE_ITEM, Value: TradeRefuse}).Encode(),
+			[]byte{0xE6, 0x00, 0x04},
+		},
+		{
+			"restart",
+			(&ByteRequest{PacketID: CZ_RESTART, Value: RestartSavePoint}).Encode(),
+			[]byte{0xB2, 0x00, 0x00},
+		},
+	}
+	for _, tt := range tests {
+		if !bytes.Equal(tt.got, tt.want) {
+			t.Errorf("%s: Encode() = % X, want % X", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestDecodeUnitEntry(t *testing.T) {
+	idle := make([]byte, 108)
+	writeU16(idle, 0, ZC_NOTIFY_STANDENTRY11)
+	writeU16(idle, 2, 108)
+	idle[4] = UnitPC
+	writeU32(idle, 5, 2000001)
+	writeU32(idle, 9, 150001)
+	writeU16(idle, 13, 150)
+	writeU32(idle, 19, 0x20) // Riding
+	writeU16(idle, 23, 7)
+	writeU16(idle, 25, 12)
+	writeU16(idle, 37, 5) // Top headgear
+	idle[62] = 1
+	copy(idle[63:], []byte{37, 139, 68}) // 150, 180, dir 4
+	idle[68] = UnitStateSitting
+	writeU16(idle, 69, 55)
+	writeU32(idle, 77, 4200)
+	copy(idle[84:], "Knight")
+
+	got := DecodeUnitEntry(idle)
+	want := &UnitEntry{
+		ObjectType: UnitPC, ID: 2000001, CharID: 150001, Speed: 150, Option: 0x20,
+		Job: 7, Head: 12, HeadTop: 5, Male: true, X: 150, Y: 180, Dir: 4,
+		State: UnitStateSitting, Level: 55, HP: 4200, Name: "Knight",
+	}
+	if got == nil || *got != *want {
+		t.Errorf("DecodeUnitEntry(idle) = %+v, want %+v", got, want)
+	}
+	if DecodeUnitEntry(idle[:107]) != nil {
+		t.Error("DecodeUnitEntry accepted a short idle entry")
+	}
+
+	walk := make([]byte, 114)
+	writeU16(walk, 0, ZC_NOTIFY_MOVEENTRY11)
+	walk[4] = UnitPet
+	writeU32(walk, 5, 110000123)
+	writeU16(walk, 23, 1002)
+	copy(walk[67:], []byte{37, 139, 66, 108, 178, 0x88}) // 150, 180 -> 155, 178
+	copy(walk[90:], "Poring")
+
+	got = DecodeUnitEntry(walk)
+	want = &UnitEntry{
+		ObjectType: UnitPet, ID: 110000123, Job: 1002,
+		X: 150, Y: 180, Walking: true, DestX: 155, DestY: 178, Name: "Poring",
+	}
+	if got == nil || *got != *want {
+		t.Errorf("DecodeUnitEntry(walk) = %+v, want %+v", got, want)
+	}
+
+	spawn := make([]byte, 107)
+	writeU16(spawn, 0, ZC_NOTIFY_NEWENTRY11)
+	writeU32(spawn, 5, 150001)
+	if got := DecodeUnitEntry(spawn); got == nil || !got.Spawned || got.ID != 150001 {
+		t.Errorf("DecodeUnitEntry(spawn) = %+v, want a spawned unit", got)
+	}
+
+	if DecodeUnitEntry(make([]byte, 120)) != nil {
+		t.Error("DecodeUnitEntry accepted another packet")
+	}
+}
+
+func TestDecodeUnitStatePackets(t *testing.T) {
+	if id, reason, ok := DecodeVanish([]byte{0x80, 0x00, 0x81, 0x84, 0x1E, 0x00, VanishDied}); !ok || id != 2000001 || reason != VanishDied {
+		t.Errorf("DecodeVanish = %d, %d, %v", id, reason, ok)
+	}
+
+	state := make([]byte, 15)
+	writeU16(state, 0, ZC_STATE_CHANGE3)
+	writeU32(state, 2, 2000001)
+	writeU32(state, 10, 0x28)
+	got := DecodeStateChange(state)
+	if got == nil || got.ID != 2000001 || got.Option != 0x28 || got.PKMode {
+		t.Errorf("DecodeStateChange = %+v", got)
+	}
+	if DecodeStateChange(state[:14]) != nil {
+		t.Error("DecodeStateChange accepted short data")
+	}
+
+	pet := []byte{0xA4, 0x01, PetStateHunger, 0xFB, 0x77, 0x8E, 0x06, 0x37, 0x00, 0x00, 0x00}
+	if got := DecodePetState(pet); got == nil || *got != (PetState{Type: PetStateHunger, ID: 110000123, Value: 55}) {
+		t.Errorf("DecodePetState = %+v", got)
+	}
+}
+
+func TestDecodeStatusChange(t *testing.T) {
+	short := make([]byte, 9)
+	writeU16(short, 0, ZC_MSG_STATE_CHANGE)
+	writeU16(short, 2, EFST_SKE)
+	writeU32(short, 4, 2000001)
+	short[8] = 1
+
+	long := make([]byte, 29)
+	copy(long, short)
+	writeU16(long, 0, ZC_MSG_STATE_CHANGE3)
+	writeU32(long, 9, 60000)
+	writeU32(long, 13, 45000)
+
+	tests := []struct {
+		data []byte
+		want StatusChange
+	}{
+		{short, StatusChange{Status: EFST_SKE, ID: 2000001, On: true}},
+		{long, StatusChange{Status: EFST_SKE, ID: 2000001, On: true, Total: 60000, Left: 45000}},
+	}
+	for _, tt := range tests {
+		if got := DecodeStatusChange(tt.data); got == nil || *got != tt.want {
+			t.Errorf("DecodeStatusChange(%#04x) = %+v, want %+v", readU16(tt.data, 0), got, tt.want)
+		}
+	}
+	if DecodeStatusChange(short[:8]) != nil {
+		t.Error("DecodeStatusChange accepted short data")
+	}
+}
+
+func TestDecodeNotifyAct(t *testing.T) {
+	act := make([]byte, 29)
+	writeU16(act, 0, ZC_NOTIFY_ACT)
+	writeU32(act, 2, 110000001)
+	writeU32(act, 6, 2000001)
+	writeU32(act, 10, 123456)
+	writeU32(act, 14, 672)
+	writeU32(act, 18, 480)
+	writeU16(act, 22, 0xFFFF) // -1: old packets carry 16-bit damage
+	writeU16(act, 24, 2)
+	act[26] = ActTypeMultiHit
+	writeU16(act, 27, 15)
+
+	act2 := make([]byte, 34)
+	writeU16(act2, 0, ZC_NOTIFY_ACT2)
+	writeU32(act2, 2, 110000001)
+	writeU32(act2, 6, 2000001)
+	writeU32(act2, 10, 123456)
+	writeU32(act2, 14, 672)
+	writeU32(act2, 18, 480)
+	writeU32(act2, 22, 100000)
+	writeU16(act2, 27, 1)
+	act2[29] = ActTypeCritical
+
+	tests := []struct {
+		name string
+		data []byte
+		want *NotifyAct
+	}{
+		{"ZC_NOTIFY_ACT", act, &NotifyAct{
+			SourceID: 110000001, TargetID: 2000001, StartTime: 123456, AttackMotion: 672, AttackedMotion: 480,
+			Damage: -1, Div: 2, Type: ActTypeMultiHit, Damage2: 15,
+		}},
+		{"ZC_NOTIFY_ACT2", act2, &NotifyAct{
+			SourceID: 110000001, TargetID: 2000001, StartTime: 123456, AttackMotion: 672, AttackedMotion: 480,
+			Damage: 100000, Div: 1, Type: ActTypeCritical,
+		}},
+		{"short ZC_NOTIFY_ACT", act[:28], nil},
+		{"short ZC_NOTIFY_ACT2", act2[:33], nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DecodeNotifyAct(tt.data)
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("DecodeNotifyAct = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if (&NotifyAct{Type: ActTypeSit}).IsAttack() || !(&NotifyAct{Type: ActTypeLuckyDodge}).IsAttack() {
+		t.Error("IsAttack should tell attacks from sitting")
+	}
+}
+
+func TestDecodeNotifySkill(t *testing.T) {
+	data := make([]byte, 33)
+	writeU16(data, 0, ZC_NOTIFY_SKILL2)
+	writeU16(data, 2, 19) // Fire Bolt
+	writeU32(data, 4, 110000001)
+	writeU32(data, 8, 2000001)
+	writeU32(data, 12, 123456)
+	writeU32(data, 16, 1000)
+	writeU32(data, 20, 480)
+	writeU32(data, 24, 450)
+	writeU16(data, 28, 5)
+	writeU16(data, 30, 5)
+	data[32] = ActTypeMultiHit
+
+	want := NotifySkill{
+		SkillID: 19, SourceID: 110000001, TargetID: 2000001, StartTime: 123456, AttackMotion: 1000,
+		AttackedMotion: 480, Damage: 450, Level: 5, Div: 5, Type: ActTypeMultiHit,
+	}
+	if got := DecodeNotifySkill(data); got == nil || *got != want {
+		t.Errorf("DecodeNotifySkill = %+v, want %+v", got, want)
+	}
+	if DecodeNotifySkill(data[:32]) != nil {
+		t.Error("DecodeNotifySkill accepted short data")
+	}
+}
+
+func TestSessionPackets(t *testing.T) {
+	if code, ok := DecodeNotifyBan([]byte{0x81, 0x00, BanDuplicateLogin}); !ok || code != BanDuplicateLogin {
+		t.Errorf("DecodeNotifyBan = %d, %v", code, ok)
+	}
+	if _, ok := DecodeNotifyBan([]byte{0x81, 0x00}); ok {
+		t.Error("DecodeNotifyBan accepted short data")
+	}
+
+	req := (&DisconnectRequest{PacketID: CZ_REQ_DISCONNECT}).Encode()
+	if !bytes.Equal(req, []byte{0x8A, 0x01, 0, 0}) {
+		t.Errorf("DisconnectRequest = % x", req)
+	}
+	for _, tt := range []struct {
+		data        []byte
+		allowed, ok bool
+	}{
+		{[]byte{0x8B, 0x01, 0, 0}, true, true},
+		{[]byte{0x8B, 0x01, 1, 0}, false, true},
+		{[]byte{0x8B, 0x01, 0}, false, false},
+	} {
+		if allowed, ok := DecodeDisconnectAck(tt.data); allowed != tt.allowed || ok != tt.ok {
+			t.Errorf("DecodeDisconnectAck(% x) = %v, %v", tt.data, allowed, ok)
+		}
+	}
+	for _, tt := range []struct {
+		data         []byte
+		accepted, ok bool
+	}{
+		{[]byte{0xB3, 0x00, 1}, true, true},
+		{[]byte{0xB3, 0x00, 0}, false, true},
+		{[]byte{0xB3, 0x00}, false, false},
+	} {
+		if accepted, ok := DecodeRestartAck(tt.data); accepted != tt.accepted || ok != tt.ok {
+			t.Errorf("DecodeRestartAck(% x) = %v, %v", tt.data, accepted, ok)
+		}
+	}
+
+	msg := "Server shutting down in 5 minutes"
+	bc := make([]byte, 4+len(msg)+1)
+	writeU16(bc, 0, ZC_BROADCAST)
+	writeU16(bc, 2, uint16(len(bc)))
+	copy(bc[4:], msg)
+	if got, ok := DecodeBroadcast(bc); !ok || got != msg {
+		t.Errorf("DecodeBroadcast = %q, %v", got, ok)
+	}
+	if _, ok := DecodeBroadcast(bc[:3]); ok {
+		t.Error("DecodeBroadcast accepted short data")
+	}
+
+	for _, tt := range []struct {
+		msg  string
+		want Broadcast
+	}{
+		{"Hello", Broadcast{"Hello", BroadcastYellow, 12}},
+		{"blueEvent starts", Broadcast{"Event starts", BroadcastBlue, 12}},
+		{"ssssGM: hi", Broadcast{"GM: hi", BroadcastYellow, 12}},
+	} {
+		if got := PlainBroadcast(tt.msg); got != tt.want {
+			t.Errorf("PlainBroadcast(%q) = %+v, want %+v", tt.msg, got, tt.want)
+		}
+	}
+
+	msg = "WoE has begun"
+	bc2 := make([]byte, 16+len(msg)+1)
+	writeU16(bc2, 0, ZC_BROADCAST2)
+	writeU16(bc2, 2, uint16(len(bc2)))
+	writeU32(bc2, 4, 0xFF00FF80)
+	writeU16(bc2, 10, 18)
+	copy(bc2[16:], msg)
+	if got, ok := DecodeBroadcast2(bc2); !ok || got != (Broadcast{msg, 0x00FF80, 18}) {
+		t.Errorf("DecodeBroadcast2 = %+v, %v", got, ok)
+	}
+	writeU16(bc2, 10, 0)
+	if got, _ := DecodeBroadcast2(bc2); got.FontSize != BroadcastDefaultFontSize {
+		t.Errorf("DecodeBroadcast2 font size = %d, want the default", got.FontSize)
+	}
+	if _, ok := DecodeBroadcast2(bc2[:15]); ok {
+		t.Error("DecodeBroadcast2 accepted short data")
+	}
+
+	msg = "Poring : hi there"
+	uc := make([]byte, 8+len(msg)+1)
+	writeU16(uc, 0, ZC_NOTIFY_CHAT)
+	writeU16(uc, 2, uint16(len(uc)))
+	writeU32(uc, 4, 110000001)
+	copy(uc[8:], msg)
+	if got, ok := DecodeUnitChat(uc); !ok || got != (UnitChat{110000001, msg}) {
+		t.Errorf("DecodeUnitChat = %+v, %v", got, ok)
+	}
+	if _, ok := DecodeUnitChat(uc[:7]); ok {
+		t.Error("DecodeUnitChat accepted short data")
+	}
+}
+
+func TestDecodeMapProperty(t *testing.T) {
+	data := make([]byte, 8)
+	writeU16(data, 0, ZC_MAPPROPERTY_R2)
+	writeU16(data, 2, MapPropertyFreePVPZone)
+	writeU32(data, 4, MapFlagParty|MapFlagCountPK|MapFlagUseCart)
+
+	p, ok := DecodeMapProperty(data)
+	if !ok || p.Property != MapPropertyFreePVPZone {
+		t.Fatalf("DecodeMapProperty = %+v, %v", p, ok)
+	}
+	if !p.Has(MapFlagParty|MapFlagCountPK) || p.Has(MapFlagGuild) {
+		t.Errorf("flags = %#x, want party, count PK and cart only", p.Flags)
+	}
+	if _, ok := DecodeMapProperty(data[:7]); ok {
+		t.Error("DecodeMapProperty accepted short data")
+	}
+
+	rank := make([]byte, 14)
+	writeU16(rank, 0, ZC_NOTIFY_RANKING)
+	writeU32(rank, 2, 2000001)
+	writeU32(rank, 6, 3)
+	writeU32(rank, 10, 12)
+	if got, ok := DecodePVPRank(rank); !ok || got != (PVPRank{AccountID: 2000001, Rank: 3, Total: 12}) {
+		t.Errorf("DecodePVPRank = %+v, %v", got, ok)
+	}
+	if _, ok := DecodePVPRank(rank[:13]); ok {
+		t.Error("DecodePVPRank accepted short data")
+	}
+}
+
+func TestDecodeRefuseLogin(t *testing.T) {
+	old := make([]byte, 23)
+	writeU16(old, 0, AC_REFUSE_LOGIN)
+	old[2] = 6
+	copy(old[3:], "2026-12-01 10:00:00")
+
+	modern := make([]byte, 26)
+	writeU16(modern, 0, AC_REFUSE_LOGIN2)
+	writeU32(modern, 2, 6)
+	copy(modern[6:], "2026-12-01 10:00:00")
+
+	for _, data := range [][]byte{old, modern} {
+		r := DecodeRefuseLogin(data)
+		if r == nil || r.Code != 6 || r.BlockDate != "2026-12-01 10:00:00" {
+			t.Errorf("DecodeRefuseLogin(%04x) = %+v", readU16(data, 0), r)
+		}
+	}
+	if r := DecodeRefuseLogin(old[:3]); r == nil || r.Code != 6 || r.BlockDate != "" {
+		t.Errorf("DecodeRefuseLogin without date = %+v", r)
+	}
+	if DecodeRefuseLogin(old[:2]) != nil {
+		t.Error("DecodeRefuseLogin accepted short data")
+	}
+}
+
+func TestDecodeLoginQueue(t *testing.T) {
+	data := make([]byte, 10)
+	writeU16(data, 0, AC_LOGIN_QUEUE)
+	writeU32(data, 2, 42)
+	writeU32(data, 6, 30)
+	q, ok := DecodeLoginQueue(data)
+	if !ok || q.Position != 42 || q.RetryAfter != 30 {
+		t.Errorf("DecodeLoginQueue = %+v, %v", q, ok)
+	}
+	if _, ok := DecodeLoginQueue(data[:9]); ok {
+		t.Error("DecodeLoginQueue accepted short data")
+	}
+}
+
+func TestPincodePackets(t *testing.T) {
+	data := []byte{0xB9, 0x08, 0x39, 0x30, 0, 0, 0x80, 0x84, 0x1E, 0x00, byte(PincodeAsk), 0}
+	got, ok := DecodePincodeLogin(data)
+	if want := (PincodeLogin{Seed: 0x3039, AccountID: 2000000, State: PincodeAsk}); !ok || got != want {
+		t.Errorf("DecodePincodeLogin = %+v, %v, want %+v", got, ok, want)
+	}
+	if _, ok := DecodePincodeLogin(data[:11]); ok {
+		t.Error("DecodePincodeLogin accepted short data")
+	}
+
+	for _, seed := range []uint32{0, 1, 12345, 0xFFFE} {
+		keys := PincodeKeypad(seed)
+		var seen [10]bool
+		for _, d := range keys {
+			seen[d] = true
+		}
+		for d, ok := range seen {
+			if !ok {
+				t.Fatalf("PincodeKeypad(%d) = %v, missing %d", seed, keys, d)
+			}
+		}
+		// The server reads each byte as a button and looks up its digit
+		enc := EncodePincode(seed, "0917")
+		var dec []byte
+		for _, b := range enc {
+			dec = append(dec, '0'+keys[b-'0'])
+		}
+		if string(dec) != "0917" {
+			t.Errorf("EncodePincode(%d) = %q, decodes to %q", seed, enc, dec)
+		}
+	}
+
+	ack := (&PincodeSubmit{PacketID: CH_SECOND_PASSWD_ACK, AccountID: 1, Pin: [4]byte{'1', '2', '3', '4'}}).Encode()
+	if !bytes.Equal(ack, []byte{0xB8, 0x08, 1, 0, 0, 0, '1', '2', '3', '4'}) {
+		t.Errorf("PincodeSubmit = % x", ack)
+	}
+	edit := (&PincodeChange{PacketID: CH_EDIT_SECOND_PASSWD, AccountID: 1, OldPin: [4]byte{'1', '2', '3', '4'}, NewPin: [4]byte{'5', '6', '7', '8'}}).Encode()
+	if !bytes.Equal(edit, []byte{0xBE, 0x08, 1, 0, 0, 0, '1', '2', '3', '4', '5', '6', '7', '8'}) {
+		t.Errorf("PincodeChange = % x", edit)
+	}
+}
+
+func TestNPCPackets(t *testing.T) {
+	show := make([]byte, 67)
+	writeU16(show, 0, ZC_SHOW_IMAGE2)
+	copy(show[2:], "kafra_01")
+	show[66] = CutinBottomRight
+	if image, pos, ok := DecodeShowImage(show); !ok || image != "kafra_01" || pos != CutinBottomRight {
+		t.Errorf("DecodeShowImage = %q, %d, %v", image, pos, ok)
+	}
+	if _, _, ok := DecodeShowImage(show[:66]); ok {
+		t.Error("DecodeShowImage accepted short data")
+	}
+
+	if id, ok := DecodeCloseDialog([]byte{0xB6, 0x00, 0x5C, 0x00, 0x01, 0x00}); !ok || id != 65628 {
+		t.Errorf("DecodeCloseDialog = %d, %v", id, ok)
+	}
+	if id, ok := DecodeWaitDialog([]byte{0xB5, 0x00, 0x5C, 0x00, 0x01, 0x00}); !ok || id != 65628 {
+		t.Errorf("DecodeWaitDialog = %d, %v", id, ok)
+	}
+	if _, ok := DecodeWaitDialog([]byte{0xB5, 0x00, 0x5C}); ok {
+		t.Error("DecodeWaitDialog accepted short data")
+	}
+
+	say := []byte{0xB4, 0x00, 14, 0, 0x5C, 0x00, 0x01, 0x00, '[', 'K', 'a', 'f', 'r', 'a', 0}
+	if id, msg, ok := DecodeSayDialog(say[:14]); !ok || id != 65628 || msg != "[Kafra" {
+		t.Errorf("DecodeSayDialog = %d, %q, %v", id, msg, ok)
+	}
+	if _, _, ok := DecodeSayDialog(say[:7]); ok {
+		t.Error("DecodeSayDialog accepted short data")
+	}
+}
@@ -0,0 +1,126 @@
+package network
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+// SendPolicy is how the send queue treats one packet type.
+type SendPolicy struct {
+	// Coalesce replaces a queued packet of the type with a newer one, for
+	// requests where only the latest matters, such as walking.
+	Coalesce bool
+
+	// CoalesceKey, if set, picks out what a packet requests; a newer
+	// packet only replaces the last queued one of its type if they request
+	// the same, so requests that don't supersede each other all go out.
+	CoalesceKey func(data []byte) int
+
+	// MinInterval is the least time between two sends of the type. Packets
+	// sent sooner wait in the queue.
+	MinInterval time.Duration
+
+	// KeepAlive marks packets sent only to hold the session open, which
+	// don't count as player activity (see Stats.LastActiveAt).
+	KeepAlive bool
+}
+
+// DefaultSendPolicies throttle the requests rapid clicking repeats. The
+// server acts on the latest walk request, and on the latest attack until
+// a sit or stand comes between, so sending them faster than a character
+// can react only costs bandwidth; the intervals are close to what the
+// original client sends at.
+var DefaultSendPolicies = map[uint16]SendPolicy{
+	0x035F: {Coalesce: true, MinInterval: 100 * time.Millisecond},                         // CZ_REQUEST_MOVE
+	0x0437: {Coalesce: true, CoalesceKey: actionKey, MinInterval: 100 * time.Millisecond}, // CZ_REQUEST_ACT2
+	0x00BF: {Coalesce: true, MinInterval: time.Second},                                    // CZ_REQ_EMOTION
+	0x0360: {Coalesce: true, KeepAlive: true},                                             // CZ_REQUEST_TIME
+}
+
+// actionKey keys a CZ_REQUEST_ACT2 by its action, so a sit or stand
+// isn't replaced by an attack clicked after it.
+func actionKey(data []byte) int {
+	if len(data) < 7 {
+		return -1
+	}
+	return int(data[6]) // packets.ActionRequest.Action
+}
+
+// queuedPacket is a packet waiting to be written.
+type queuedPacket struct {
+	id       uint16
+	data     []byte
+	deferred bool // Held back by a rate limit at least once
+}
+
+// sendQueue holds outgoing packets between flushes. Packets leave in the
+// order they were queued, except that a rate-limited packet waits while
+// later packets of other types go ahead.
+type sendQueue struct {
+	policies map[uint16]SendPolicy
+	pending  []queuedPacket
+	lastSent map[uint16]time.Time // By packet ID, for rate-limited types
+
+	coalesced uint64 // Packets replaced by a newer one before sending
+	deferred  uint64 // Packets held back by a rate limit
+}
+
+func newSendQueue(policies map[uint16]SendPolicy) *sendQueue {
+	return &sendQueue{
+		policies: policies,
+		lastSent: make(map[uint16]time.Time),
+	}
+}
+
+// push queues a packet, replacing the last queued one of the same type
+// if the type coalesces and the two request the same.
+func (q *sendQueue) push(data []byte) {
+	var id uint16
+	if len(data) >= 2 {
+		id = binary.LittleEndian.Uint16(data[0:2])
+	}
+	if policy := q.policies[id]; policy.Coalesce {
+		for i := len(q.pending) - 1; i >= 0; i-- {
+			if q.pending[i].id != id {
+				continue
+			}
+			if policy.CoalesceKey == nil || policy.CoalesceKey(q.pending[i].data) == policy.CoalesceKey(data) {
+				q.pending[i].data = data
+				q.coalesced++
+				return
+			}
+			break
+		}
+	}
+	q.pending = append(q.pending, queuedPacket{id: id, data: data})
+}
+
+// take removes and returns the packets that may be sent at now.
+func (q *sendQueue) take(now time.Time) []queuedPacket {
+	var out []queuedPacket
+	kept := q.pending[:0]
+	for _, p := range q.pending {
+		if interval := q.policies[p.id].MinInterval; interval > 0 {
+			if last, ok := q.lastSent[p.id]; ok && now.Sub(last) < interval {
+				if !p.deferred {
+					p.deferred = true
+					q.deferred++
+				}
+				kept = append(kept, p)
+				continue
+			}
+			q.lastSent[p.id] = now
+		}
+		out = append(out, p)
+	}
+	clear(q.pending[len(kept):])
+	q.pending = kept
+	return out
+}
+
+// reset drops every queued packet, e.g. when the connection closes.
+func (q *sendQueue) reset() {
+	clear(q.pending)
+	q.pending = q.pending[:0]
+	clear(q.lastSent)
+}
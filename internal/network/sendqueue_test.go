@@ -0,0 +1,115 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Faultbox/midgard-ro/internal/network/packets"
+)
+
+func testPacket(id uint16, payload byte) []byte {
+	return []byte{byte(id), byte(id >> 8), payload}
+}
+
+func takenIDs(packets []queuedPacket) []uint16 {
+	ids := make([]uint16, len(packets))
+	for i, p := range packets {
+		ids[i] = p.id
+	}
+	return ids
+}
+
+func TestSendQueueCoalesce(t *testing.T) {
+	q := newSendQueue(map[uint16]SendPolicy{0x035F: {Coalesce: true}})
+	q.push(testPacket(0x035F, 1))
+	q.push(testPacket(0x0096, 1))
+	q.push(testPacket(0x035F, 2))
+	q.push(testPacket(0x0096, 2)) // Not coalesced
+
+	got := q.take(time.Now())
+	if len(got) != 3 {
+		t.Fatalf("take() = %v, want 3 packets", takenIDs(got))
+	}
+	if got[0].id != 0x035F || got[0].data[2] != 2 {
+		t.Errorf("first packet = 0x%04X payload %d, want the newer move in the first slot", got[0].id, got[0].data[2])
+	}
+	if q.coalesced != 1 {
+		t.Errorf("coalesced = %d, want 1", q.coalesced)
+	}
+	if len(q.pending) != 0 {
+		t.Errorf("%d packets left queued", len(q.pending))
+	}
+}
+
+func TestSendQueueRateLimit(t *testing.T) {
+	q := newSendQueue(map[uint16]SendPolicy{0x0437: {MinInterval: 100 * time.Millisecond}})
+	start := time.Now()
+
+	q.push(testPacket(0x0437, 1))
+	if got := q.take(start); len(got) != 1 {
+		t.Fatalf("first take() = %v, want the action", takenIDs(got))
+	}
+
+	q.push(testPacket(0x0437, 2))
+	q.push(testPacket(0x0096, 1))
+	got := q.take(start.Add(50 * time.Millisecond))
+	if len(got) != 1 || got[0].id != 0x0096 {
+		t.Fatalf("take() inside the interval = %v, want only 0x0096", takenIDs(got))
+	}
+	q.take(start.Add(60 * time.Millisecond)) // Still held; counted once
+	if q.deferred != 1 {
+		t.Errorf("deferred = %d, want 1", q.deferred)
+	}
+
+	got = q.take(start.Add(100 * time.Millisecond))
+	if len(got) != 1 || got[0].data[2] != 2 {
+		t.Errorf("take() after the interval = %v, want the held action", takenIDs(got))
+	}
+}
+
+func TestSendQueueReset(t *testing.T) {
+	q := newSendQueue(map[uint16]SendPolicy{0x0437: {MinInterval: time.Hour}})
+	now := time.Now()
+	q.push(testPacket(0x0437, 1))
+	q.take(now)
+	q.push(testPacket(0x0437, 2))
+	q.reset()
+
+	if len(q.pending) != 0 {
+		t.Errorf("%d packets queued after reset", len(q.pending))
+	}
+	q.push(testPacket(0x0437, 3))
+	if got := q.take(now); len(got) != 1 {
+		t.Errorf("take() after reset = %v, want the rate limit cleared", takenIDs(got))
+	}
+}
+
+func TestSendQueueCoalesceKey(t *testing.T) {
+	action := func(act byte, target byte) []byte {
+		return []byte{0x37, 0x04, target, 0, 0, 0, act}
+	}
+	q := newSendQueue(DefaultSendPolicies)
+	q.push(action(packets.ActionSit, 0))
+	q.push(action(packets.ActionAttack, 1))
+	q.push(action(packets.ActionAttack, 2)) // Replaces the first attack
+
+	got := q.take(time.Now())
+	if len(got) != 1 || got[0].data[6] != packets.ActionSit {
+		t.Fatalf("take() = %v, want the sit first", got)
+	}
+	got = q.take(time.Now().Add(time.Second))
+	if len(got) != 1 || got[0].data[6] != packets.ActionAttack || got[0].data[2] != 2 {
+		t.Fatalf("take() = %v, want the newer attack", got)
+	}
+	if q.coalesced != 1 {
+		t.Errorf("coalesced = %d, want 1", q.coalesced)
+	}
+
+	// An attack after a stand doesn't replace the one before it
+	q.push(action(packets.ActionAttack, 3))
+	q.push(action(packets.ActionStand, 0))
+	q.push(action(packets.ActionAttack, 4))
+	if len(q.pending) != 3 {
+		t.Errorf("%d packets queued, want attack, stand and attack", len(q.pending))
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"math"
 	"os"
+	"strings"
 )
 
 // ACT format errors.
@@ -33,6 +34,10 @@ type ACT struct {
 	Actions   []Action
 	Events    []string  // Event names (sound files, "atk", etc.)
 	Intervals []float32 // Animation delay per action (ms)
+
+	// Warnings lists what a damaged file lost: the frames and actions
+	// past the damage
+	Warnings []Warning
 }
 
 // Action represents an animation sequence.
@@ -45,6 +50,24 @@ type Frame struct {
 	Layers       []Layer
 	EventID      int32 // -1 = no event
 	AnchorPoints []AnchorPoint
+	Trigger      FrameTrigger // Resolved from the frame's event
+}
+
+// EventAttack is the event name marking the frame an attack lands on.
+const EventAttack = "atk"
+
+// FrameTrigger flags what happens when a frame is shown.
+type FrameTrigger uint8
+
+// Frame triggers.
+const (
+	TriggerAttack FrameTrigger = 1 << iota // The hit lands
+	TriggerSound                           // The event names a sound to play
+)
+
+// Has returns true if all flags of t2 are set in t.
+func (t FrameTrigger) Has(t2 FrameTrigger) bool {
+	return t&t2 == t2
 }
 
 // Layer represents a sprite layer in a frame.
@@ -103,11 +126,18 @@ func ParseACT(data []byte) (*ACT, error) {
 		Actions: make([]Action, 0, actionCount),
 	}
 
-	// Parse actions
+	// Parse actions. Past a damaged action the rest of the file can't be
+	// found; the actions read so far are kept
 	for i := uint16(0); i < actionCount; i++ {
 		action, err := parseAction(r, version)
 		if err != nil {
-			return nil, fmt.Errorf("parsing action %d: %w", i, err)
+			// The frames before the damage still play
+			if len(action.Frames) > 0 {
+				act.Actions = append(act.Actions, action)
+			}
+			warnf(&act.Warnings, fmt.Sprintf("action %d", i), "%v; %d frames read, the rest of the file dropped",
+				err, len(action.Frames))
+			return act, nil
 		}
 		act.Actions = append(act.Actions, action)
 	}
@@ -123,10 +153,13 @@ func ParseACT(data []byte) (*ACT, error) {
 		for i := int32(0); i < eventCount; i++ {
 			name, err := parseEventName(r)
 			if err != nil {
-				return nil, fmt.Errorf("parsing event %d: %w", i, err)
+				warnf(&act.Warnings, fmt.Sprintf("event %d", i), "%v; %d events dropped", err, eventCount-i)
+				act.resolveTriggers()
+				return act, nil
 			}
 			act.Events = append(act.Events, name)
 		}
+		act.resolveTriggers()
 	}
 
 	// Parse intervals (v0x202+)
@@ -145,6 +178,51 @@ func ParseACT(data []byte) (*ACT, error) {
 	return act, nil
 }
 
+// resolveTriggers sets the trigger flags of every frame from its event.
+func (a *ACT) resolveTriggers() {
+	for i := range a.Actions {
+		for j := range a.Actions[i].Frames {
+			f := &a.Actions[i].Frames[j]
+			f.Trigger = eventTrigger(a.EventName(f))
+		}
+	}
+}
+
+// eventTrigger returns the trigger flags of an event name.
+func eventTrigger(name string) FrameTrigger {
+	switch {
+	case name == "":
+		return 0
+	case strings.EqualFold(name, EventAttack):
+		return TriggerAttack
+	case strings.HasSuffix(strings.ToLower(name), ".wav"):
+		return TriggerSound
+	}
+	return 0
+}
+
+// EventName returns the name of a frame's event, or "" if it has none.
+func (a *ACT) EventName(f *Frame) string {
+	if f.EventID < 0 || int(f.EventID) >= len(a.Events) {
+		return ""
+	}
+	return a.Events[f.EventID]
+}
+
+// AttackFrame returns the index of the frame an action's hit lands on, or
+// -1 if the action has no attack trigger.
+func (a *ACT) AttackFrame(action int) int {
+	if action < 0 || action >= len(a.Actions) {
+		return -1
+	}
+	for i := range a.Actions[action].Frames {
+		if a.Actions[action].Frames[i].Trigger.Has(TriggerAttack) {
+			return i
+		}
+	}
+	return -1
+}
+
 // ParseACTFile parses an ACT file from disk.
 func ParseACTFile(path string) (*ACT, error) {
 	data, err := os.ReadFile(path)
@@ -154,21 +232,27 @@ func ParseACTFile(path string) (*ACT, error) {
 	return ParseACT(data)
 }
 
-// parseAction parses a single action.
+// actFrameMinSize is the size of a frame without layers: ranges, layer
+// count and event ID.
+const actFrameMinSize = 32 + 4 + 4
+
+// parseAction parses a single action. On damage it returns the frames
+// read before it with the error.
 func parseAction(r *bytes.Reader, version ACTVersion) (Action, error) {
 	var frameCount uint32
 	if err := binary.Read(r, binary.LittleEndian, &frameCount); err != nil {
 		return Action{}, fmt.Errorf("%w: reading frame count", ErrTruncatedACTData)
 	}
 
+	// A damaged count can't allocate more frames than the data holds
 	action := Action{
-		Frames: make([]Frame, 0, frameCount),
+		Frames: make([]Frame, 0, min(frameCount, uint32(r.Len()/actFrameMinSize))),
 	}
 
 	for i := uint32(0); i < frameCount; i++ {
 		frame, err := parseFrame(r, version)
 		if err != nil {
-			return Action{}, fmt.Errorf("parsing frame %d: %w", i, err)
+			return action, fmt.Errorf("parsing frame %d: %w", i, err)
 		}
 		action.Frames = append(action.Frames, frame)
 	}
@@ -189,7 +273,7 @@ func parseFrame(r *bytes.Reader, version ACTVersion) (Frame, error) {
 	}
 
 	frame := Frame{
-		Layers:  make([]Layer, 0, layerCount),
+		Layers:  make([]Layer, 0, min(layerCount, uint32(r.Len()/actLayerMinSize))),
 		EventID: -1,
 	}
 
@@ -226,6 +310,10 @@ func parseFrame(r *bytes.Reader, version ACTVersion) (Frame, error) {
 	return frame, nil
 }
 
+// actLayerMinSize is the size of a layer in the oldest version: position,
+// sprite, flags, color, scale, rotation and type.
+const actLayerMinSize = 32
+
 // parseLayer parses a single layer.
 func parseLayer(r *bytes.Reader, version ACTVersion) (Layer, error) {
 	layer := Layer{
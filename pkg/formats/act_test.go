@@ -196,6 +196,9 @@ func TestParseACT_GeneratedFile(t *testing.T) {
 	if len(act.Events) != 1 || act.Events[0] != "step.wav" {
 		t.Errorf("expected event 'step.wav', got %v", act.Events)
 	}
+	if act.Actions[0].Frames[0].Trigger != TriggerSound {
+		t.Errorf("expected idle frame to trigger a sound, got %d", act.Actions[0].Frames[0].Trigger)
+	}
 
 	// Check intervals
 	if len(act.Intervals) < 2 {
@@ -203,6 +206,64 @@ func TestParseACT_GeneratedFile(t *testing.T) {
 	}
 }
 
+func TestACT_Triggers(t *testing.T) {
+	act := &ACT{
+		Events: []string{"atk", "effect/hit.wav", "ATK", "unknown"},
+		Actions: []Action{
+			{Frames: []Frame{{EventID: -1}, {EventID: 1}, {EventID: 0}, {EventID: 3}}},
+			{Frames: []Frame{{EventID: -1}, {EventID: 9}}},
+			{Frames: []Frame{{EventID: 2}}},
+		},
+	}
+	act.resolveTriggers()
+
+	wantTriggers := [][]FrameTrigger{
+		{0, TriggerSound, TriggerAttack, 0},
+		{0, 0},
+		{TriggerAttack},
+	}
+	for i, action := range act.Actions {
+		for j, frame := range action.Frames {
+			if frame.Trigger != wantTriggers[i][j] {
+				t.Errorf("action %d frame %d: trigger = %d, want %d", i, j, frame.Trigger, wantTriggers[i][j])
+			}
+		}
+	}
+
+	tests := []struct {
+		action int
+		want   int
+	}{
+		{0, 2},
+		{1, -1},
+		{2, 0},
+		{-1, -1},
+		{5, -1},
+	}
+	for _, tt := range tests {
+		if got := act.AttackFrame(tt.action); got != tt.want {
+			t.Errorf("AttackFrame(%d) = %d, want %d", tt.action, got, tt.want)
+		}
+	}
+
+	if got := act.EventName(&act.Actions[0].Frames[1]); got != "effect/hit.wav" {
+		t.Errorf("EventName = %q, want effect/hit.wav", got)
+	}
+	if got := act.EventName(&act.Actions[1].Frames[1]); got != "" {
+		t.Errorf("EventName of out-of-range event = %q, want empty", got)
+	}
+}
+
+func TestFrameTrigger_Has(t *testing.T) {
+	both := TriggerAttack | TriggerSound
+	if !both.Has(TriggerAttack) || !both.Has(TriggerSound) {
+		t.Error("combined trigger should have both flags")
+	}
+	if TriggerSound.Has(TriggerAttack) {
+		t.Error("sound trigger should not have the attack flag")
+	}
+}
+
 // buildSyntheticACT creates a synthetic ACT file for testing.
 func buildSyntheticACT(version uint16) []byte {
 	var buf bytes.Buffer
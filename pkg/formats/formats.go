@@ -1,6 +1,27 @@
 // Package formats provides parsers for Ragnarok Online file formats.
 package formats
 
+import "fmt"
+
 // Note: GAT (Ground Altitude Table) is fully implemented in gat.go
 // Note: GND (Ground Mesh) is fully implemented in gnd.go
 // Note: RSW (Resource World) is fully implemented in rsw.go
+
+// Warning is damage a parser worked around: a part of the file it skipped
+// or repaired. Parsers only fail on headers they can't make sense of;
+// past those they keep what they could read, so a damaged asset still
+// shows, and list what they lost in Warnings.
+type Warning struct {
+	Section string // What was being read, e.g. "tile 1024" or "action 3"
+	Reason  string // What was wrong and what was done about it
+}
+
+// String returns the warning as "section: reason".
+func (w Warning) String() string {
+	return w.Section + ": " + w.Reason
+}
+
+// warnf adds a warning to a parse result's list.
+func warnf(warnings *[]Warning, section, format string, args ...any) {
+	*warnings = append(*warnings, Warning{Section: section, Reason: fmt.Sprintf(format, args...)})
+}
@@ -1,5 +1,77 @@
 package formats
 
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
 // Note: GAT tests are in gat_test.go
 // Note: GND tests are in gnd_test.go
 // Note: RSW tests are in rsw_test.go
+
+// The parsers keep what they read before damage and say what they lost.
+func TestParseDamaged(t *testing.T) {
+	t.Run("SPR", func(t *testing.T) {
+		data := buildSyntheticSPR(2, 1, 2, 0, true)
+		binary.LittleEndian.PutUint16(data[26:], 0xFFFF) // Image 1's compressed size
+		spr, err := ParseSPR(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(spr.Images) != 2 || spr.Images[0].Width != 4 || spr.Images[1].Width != 1 || len(spr.Warnings) != 1 {
+			t.Errorf("got %d images, warnings %v; want image 1 blank", len(spr.Images), spr.Warnings)
+		}
+	})
+
+	t.Run("ACT", func(t *testing.T) {
+		var frame bytes.Buffer
+		writeFrame(&frame, 0x205, 1, true)
+		data := buildSyntheticACT(0x205)[:16+4+frame.Len()+10] // Into frame 1
+		act, err := ParseACT(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(act.Actions) != 1 || len(act.Actions[0].Frames) != 1 || len(act.Warnings) != 1 {
+			t.Errorf("got %d actions, warnings %v; want action 0 with its first frame", len(act.Actions), act.Warnings)
+		}
+	})
+
+	t.Run("GND", func(t *testing.T) {
+		full := createTestGND(4, 4, []string{"a.bmp"})
+		gnd, err := ParseGND(full[:len(full)-3*28-5]) // Into tile 12
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(gnd.Tiles) != 16 || gnd.Tiles[15].TopSurface != -1 || len(gnd.Textures) != 1 || len(gnd.Warnings) != 1 {
+			t.Errorf("got %d tiles, warnings %v; want all 16 with the lost ones empty", len(gnd.Tiles), gnd.Warnings)
+		} else if w := gnd.Warnings[0]; w.Section != "tile 12" {
+			t.Errorf("warning = %v, want it in tile 12", w)
+		}
+	})
+
+	t.Run("RSM", func(t *testing.T) {
+		rsm, err := ParseRSM(makeMinimalRSMWithNode(1, 5)[:150]) // Into the node
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rsm.Textures) != 1 || len(rsm.Nodes) != 0 || len(rsm.Warnings) != 1 {
+			t.Errorf("got %d textures, %d nodes, warnings %v", len(rsm.Textures), len(rsm.Nodes), rsm.Warnings)
+		}
+	})
+
+	t.Run("RSW", func(t *testing.T) {
+		full := buildTestRSW(RSWVersion{Major: 1, Minor: 9})
+		want, err := ParseRSW(full)
+		if err != nil || len(want.Warnings) != 0 {
+			t.Fatalf("intact file: %v, warnings %v", err, want.Warnings)
+		}
+		rsw, err := ParseRSW(full[:len(full)-10]) // Into the last object
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rsw.Objects) != len(want.Objects)-1 || rsw.Water != want.Water || len(rsw.Warnings) != 1 {
+			t.Errorf("got %d of %d objects, warnings %v", len(rsw.Objects), len(want.Objects), rsw.Warnings)
+		}
+	})
+}
@@ -80,6 +80,12 @@ func (t GATCellType) IsSnipeable() bool {
 	return t == GATSnipeable || t == GATBlockedSnipe
 }
 
+// IsCliff returns true for cliffs and gaps: cells that can't be walked
+// on but can be attacked over.
+func (t GATCellType) IsCliff() bool {
+	return t.IsSnipeable() && !t.IsWalkable()
+}
+
 // GATCell represents a single cell in the GAT grid.
 type GATCell struct {
 	// Heights contains the altitude of each corner:
@@ -206,6 +212,45 @@ func ParseGATFile(path string) (*GAT, error) {
 	return ParseGAT(data)
 }
 
+// EncodeGAT serializes a GAT to the file format ParseGAT reads. A zero
+// version is written as 1.2.
+func EncodeGAT(g *GAT) ([]byte, error) {
+	if g.Width == 0 || g.Height == 0 || g.Width > 4096 || g.Height > 4096 {
+		return nil, fmt.Errorf("invalid GAT dimensions: %dx%d", g.Width, g.Height)
+	}
+	if len(g.Cells) != int(g.Width*g.Height) {
+		return nil, fmt.Errorf("GAT has %d cells, want %d for %dx%d", len(g.Cells), g.Width*g.Height, g.Width, g.Height)
+	}
+	version := g.Version
+	if version == (GATVersion{}) {
+		version = GATVersion{Major: 1, Minor: 2}
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, 14+len(g.Cells)*20))
+	buf.WriteString("GRAT")
+	buf.WriteByte(version.Minor)
+	buf.WriteByte(version.Major)
+	binary.Write(buf, binary.LittleEndian, g.Width)
+	binary.Write(buf, binary.LittleEndian, g.Height)
+	for _, cell := range g.Cells {
+		binary.Write(buf, binary.LittleEndian, cell.Heights)
+		binary.Write(buf, binary.LittleEndian, uint32(cell.Type))
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteGATFile encodes a GAT and writes it to disk.
+func WriteGATFile(path string, g *GAT) error {
+	data, err := EncodeGAT(g)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("writing GAT file: %w", err)
+	}
+	return nil
+}
+
 // CountByType returns the count of cells for each type.
 func (g *GAT) CountByType() map[GATCellType]int {
 	counts := make(map[GATCellType]int)
@@ -0,0 +1,87 @@
+package formats
+
+import "math"
+
+// Terrain editing for map tooling. Brushes are circles in cell units:
+// cell (x, y) spans [x, x+1] x [y, y+1], so its center is (x+0.5, y+0.5)
+// and its corners sit on the integer grid. Corners shared by neighboring
+// cells always get the same edit, keeping the terrain seamless.
+
+// gatCorners are the offsets of each Heights corner from a cell's origin,
+// in GATCell.Heights order.
+var gatCorners = [4][2]float32{{0, 0}, {1, 0}, {0, 1}, {1, 1}}
+
+// SetCellType sets the type of the cell at (x, y), reporting false when
+// it's out of bounds.
+func (g *GAT) SetCellType(x, y int, t GATCellType) bool {
+	cell := g.GetCell(x, y)
+	if cell == nil {
+		return false
+	}
+	cell.Type = t
+	return true
+}
+
+// PaintCellType sets the type of every cell whose center lies within
+// radius of (cx, cy) and returns how many cells it changed.
+func (g *GAT) PaintCellType(cx, cy, radius float32, t GATCellType) int {
+	changed := 0
+	g.eachCellNear(cx, cy, radius+0.5, func(x, y int, cell *GATCell) {
+		if dist(float32(x)+0.5, float32(y)+0.5, cx, cy) > radius || cell.Type == t {
+			return
+		}
+		cell.Type = t
+		changed++
+	})
+	return changed
+}
+
+// RaiseHeights raises the ground around (cx, cy) by amount at the center,
+// falling off linearly to nothing at radius; a negative amount lowers it.
+// GAT altitudes grow downward, so raising subtracts from them. It returns
+// how many cells it changed.
+func (g *GAT) RaiseHeights(cx, cy, radius, amount float32) int {
+	if radius <= 0 || amount == 0 {
+		return 0
+	}
+	changed := 0
+	g.eachCellNear(cx, cy, radius+1, func(x, y int, cell *GATCell) {
+		touched := false
+		for i, c := range gatCorners {
+			d := dist(float32(x)+c[0], float32(y)+c[1], cx, cy)
+			if d >= radius {
+				continue
+			}
+			cell.Heights[i] -= amount * (1 - d/radius)
+			touched = true
+		}
+		if touched {
+			changed++
+		}
+	})
+	return changed
+}
+
+// LowerHeights lowers the ground around (cx, cy); see RaiseHeights.
+func (g *GAT) LowerHeights(cx, cy, radius, amount float32) int {
+	return g.RaiseHeights(cx, cy, radius, -amount)
+}
+
+// eachCellNear calls f for every in-bounds cell within reach cells of
+// (cx, cy) on either axis.
+func (g *GAT) eachCellNear(cx, cy, reach float32, f func(x, y int, cell *GATCell)) {
+	x0 := max(int(math.Floor(float64(cx-reach))), 0)
+	y0 := max(int(math.Floor(float64(cy-reach))), 0)
+	x1 := min(int(math.Ceil(float64(cx+reach))), int(g.Width)-1)
+	y1 := min(int(math.Ceil(float64(cy+reach))), int(g.Height)-1)
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			f(x, y, &g.Cells[y*int(g.Width)+x])
+		}
+	}
+}
+
+// dist returns the distance between (x0, y0) and (x1, y1).
+func dist(x0, y0, x1, y1 float32) float32 {
+	return float32(math.Hypot(float64(x1-x0), float64(y1-y0)))
+}
@@ -0,0 +1,93 @@
+package formats
+
+import "testing"
+
+func newTestGAT(t *testing.T, width, height uint32) *GAT {
+	t.Helper()
+	gat, err := ParseGAT(createTestGAT(width, height, nil))
+	if err != nil {
+		t.Fatalf("ParseGAT failed: %v", err)
+	}
+	return gat
+}
+
+func TestGAT_SetCellType(t *testing.T) {
+	gat := newTestGAT(t, 4, 4)
+	if !gat.SetCellType(1, 2, GATWater) {
+		t.Fatal("SetCellType(1, 2) reported out of bounds")
+	}
+	if got := gat.GetCell(1, 2).Type; got != GATWater {
+		t.Errorf("cell (1,2) is %v, want Water", got)
+	}
+	if gat.SetCellType(4, 0, GATWater) || gat.SetCellType(0, -1, GATWater) {
+		t.Error("SetCellType out of bounds reported success")
+	}
+}
+
+func TestGAT_PaintCellType(t *testing.T) {
+	gat := newTestGAT(t, 8, 8)
+	if n := gat.PaintCellType(3.5, 3.5, 1, GATBlocked); n != 5 {
+		t.Errorf("painted %d cells, want the center and its 4 neighbors", n)
+	}
+	for _, c := range [][2]int{{3, 3}, {2, 3}, {4, 3}, {3, 2}, {3, 4}} {
+		if !gat.GetCell(c[0], c[1]).Type.IsBlocked() {
+			t.Errorf("cell %v not painted", c)
+		}
+	}
+	if gat.GetCell(2, 2).Type != GATWalkable {
+		t.Error("diagonal cell painted outside the radius")
+	}
+	if n := gat.PaintCellType(3.5, 3.5, 1, GATBlocked); n != 0 {
+		t.Errorf("repainting changed %d cells, want 0", n)
+	}
+
+	// Brushes past the edge are clipped
+	if n := gat.PaintCellType(0, 0, 1, GATWater); n != 1 {
+		t.Errorf("corner brush painted %d cells, want 1", n)
+	}
+}
+
+func TestGAT_RaiseHeights(t *testing.T) {
+	gat := newTestGAT(t, 8, 8)
+	if n := gat.RaiseHeights(4, 4, 2, 10); n != 16 {
+		t.Errorf("raised %d cells, want 16", n)
+	}
+
+	// The corner at the center rises fully, in every cell sharing it;
+	// GAT altitudes grow downward
+	for _, c := range []struct {
+		x, y, corner int
+	}{{3, 3, 3}, {4, 3, 2}, {3, 4, 1}, {4, 4, 0}} {
+		if got := gat.GetCell(c.x, c.y).Heights[c.corner]; got != -10 {
+			t.Errorf("cell (%d,%d) corner %d = %v, want -10", c.x, c.y, c.corner, got)
+		}
+	}
+	// Halfway out rises by half
+	if got := gat.GetCell(5, 4).Heights[0]; got != -5 {
+		t.Errorf("corner at distance 1 = %v, want -5", got)
+	}
+	// At the radius nothing changes
+	if got := gat.GetCell(6, 4).Heights[0]; got != 0 {
+		t.Errorf("corner at the radius = %v, want 0", got)
+	}
+
+	// Shared corners stay seamless
+	for y := range 7 {
+		for x := range 7 {
+			cell, right, up := gat.GetCell(x, y), gat.GetCell(x+1, y), gat.GetCell(x, y+1)
+			if cell.Heights[1] != right.Heights[0] || cell.Heights[3] != right.Heights[2] ||
+				cell.Heights[2] != up.Heights[0] || cell.Heights[3] != up.Heights[1] {
+				t.Fatalf("cell (%d,%d) corners don't match its neighbors", x, y)
+			}
+		}
+	}
+
+	gat.LowerHeights(4, 4, 2, 10)
+	min, max := gat.GetAltitudeRange()
+	if min != 0 || max != 0 {
+		t.Errorf("altitude range after lowering back = %v..%v, want flat", min, max)
+	}
+	if n := gat.RaiseHeights(4, 4, 0, 10); n != 0 {
+		t.Errorf("zero radius raised %d cells", n)
+	}
+}
@@ -163,6 +163,26 @@ func TestGATCellType_IsWater(t *testing.T) {
 	}
 }
 
+func TestGATCellType_IsCliff(t *testing.T) {
+	tests := []struct {
+		cellType GATCellType
+		expected bool
+	}{
+		{GATWalkable, false},
+		{GATBlocked, false},
+		{GATWater, false},
+		{GATWalkableWater, false},
+		{GATSnipeable, true},
+		{GATBlockedSnipe, true},
+	}
+
+	for _, tc := range tests {
+		if tc.cellType.IsCliff() != tc.expected {
+			t.Errorf("%v.IsCliff() = %v, expected %v", tc.cellType, tc.cellType.IsCliff(), tc.expected)
+		}
+	}
+}
+
 func TestGAT_GetCell(t *testing.T) {
 	data := createTestGAT(4, 4, nil)
 	gat, _ := ParseGAT(data)
@@ -265,3 +285,66 @@ func TestGATCellType_String(t *testing.T) {
 		}
 	}
 }
+
+func TestEncodeGAT_RoundTrip(t *testing.T) {
+	data := createTestGAT(3, 2, []GATCellType{GATWalkable, GATBlocked, GATWater})
+	gat, err := ParseGAT(data)
+	if err != nil {
+		t.Fatalf("ParseGAT failed: %v", err)
+	}
+	gat.Cells[4].Heights = [4]float32{-1.5, 2, 3.25, -4}
+	gat.Cells[5].Type = GATBlockedSnipe
+
+	encoded, err := EncodeGAT(gat)
+	if err != nil {
+		t.Fatalf("EncodeGAT failed: %v", err)
+	}
+	if len(encoded) != len(data) {
+		t.Fatalf("encoded %d bytes, want %d", len(encoded), len(data))
+	}
+	decoded, err := ParseGAT(encoded)
+	if err != nil {
+		t.Fatalf("ParseGAT of encoded data failed: %v", err)
+	}
+	if decoded.Version != gat.Version || decoded.Width != gat.Width || decoded.Height != gat.Height {
+		t.Errorf("header = %s %dx%d, want %s %dx%d", decoded.Version, decoded.Width, decoded.Height, gat.Version, gat.Width, gat.Height)
+	}
+	for i := range gat.Cells {
+		if decoded.Cells[i] != gat.Cells[i] {
+			t.Errorf("cell %d = %+v, want %+v", i, decoded.Cells[i], gat.Cells[i])
+		}
+	}
+
+	// Unchanged files encode byte for byte
+	original, _ := ParseGAT(data)
+	if reencoded, _ := EncodeGAT(original); !bytes.Equal(reencoded, data) {
+		t.Error("encoding a parsed file doesn't reproduce it")
+	}
+}
+
+func TestEncodeGAT_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		gat  GAT
+	}{
+		{"empty", GAT{}},
+		{"too few cells", GAT{Width: 2, Height: 2, Cells: make([]GATCell, 3)}},
+		{"too large", GAT{Width: 5000, Height: 1, Cells: make([]GATCell, 5000)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := EncodeGAT(&tt.gat); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	// A zero version is written as 1.2
+	data, err := EncodeGAT(&GAT{Width: 1, Height: 1, Cells: make([]GATCell, 1)})
+	if err != nil {
+		t.Fatalf("EncodeGAT failed: %v", err)
+	}
+	if gat, err := ParseGAT(data); err != nil || gat.Version != (GATVersion{Major: 1, Minor: 2}) {
+		t.Errorf("zero version parsed as %v (err %v), want 1.2", gat, err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 )
 
@@ -62,6 +63,10 @@ type GND struct {
 	LightmapHeight uint32
 	Surfaces       []GNDSurface
 	Tiles          []GNDTile
+
+	// Warnings lists what a damaged file lost; tiles past the damage are
+	// left empty, so Tiles always covers Width x Height
+	Warnings []Warning
 }
 
 // GetTile returns the tile at the given coordinates.
@@ -146,87 +151,135 @@ func ParseGND(data []byte) (*GND, error) {
 		Zoom:    zoom,
 	}
 
+	// Tiles past any damage stay empty: holes in the ground
+	gnd.Tiles = make([]GNDTile, width*height)
+	for i := range gnd.Tiles {
+		gnd.Tiles[i] = GNDTile{TopSurface: -1, FrontSurface: -1, RightSurface: -1}
+	}
+	if section, err := parseGNDBody(r, gnd); err != nil {
+		warnf(&gnd.Warnings, section, "%v; the rest of the ground left empty", err)
+	}
+	gnd.checkSurfaces()
+
+	return gnd, nil
+}
+
+// parseGNDBody reads everything after the dimensions into gnd, up to the
+// first damaged section, which it returns with the error.
+func parseGNDBody(r *bytes.Reader, gnd *GND) (string, error) {
 	// Read textures
 	var textureCount, textureNameLen uint32
 	if err := binary.Read(r, binary.LittleEndian, &textureCount); err != nil {
-		return nil, fmt.Errorf("%w: reading texture count", ErrTruncatedGNDData)
+		return "textures", fmt.Errorf("%w: reading texture count", ErrTruncatedGNDData)
 	}
 	if err := binary.Read(r, binary.LittleEndian, &textureNameLen); err != nil {
-		return nil, fmt.Errorf("%w: reading texture name length", ErrTruncatedGNDData)
+		return "textures", fmt.Errorf("%w: reading texture name length", ErrTruncatedGNDData)
+	}
+	if textureNameLen > 0 && uint64(textureCount)*uint64(textureNameLen) > uint64(r.Len()) {
+		return "textures", fmt.Errorf("%w: %d textures don't fit", ErrTruncatedGNDData, textureCount)
 	}
 
-	gnd.Textures = make([]string, textureCount)
 	for i := uint32(0); i < textureCount; i++ {
 		nameBytes := make([]byte, textureNameLen)
-		if _, err := r.Read(nameBytes); err != nil {
-			return nil, fmt.Errorf("%w: reading texture %d name", ErrTruncatedGNDData, i)
+		if _, err := io.ReadFull(r, nameBytes); err != nil {
+			return fmt.Sprintf("texture %d", i), fmt.Errorf("%w: reading name", ErrTruncatedGNDData)
 		}
 		// Find first null byte and extract name
 		if idx := bytes.IndexByte(nameBytes, 0); idx >= 0 {
-			gnd.Textures[i] = string(nameBytes[:idx])
+			gnd.Textures = append(gnd.Textures, string(nameBytes[:idx]))
 		} else {
-			gnd.Textures[i] = string(nameBytes)
+			gnd.Textures = append(gnd.Textures, string(nameBytes))
 		}
 	}
 
 	// Read lightmaps
 	var lightmapCount, lightmapWidth, lightmapHeight, lightmapCells uint32
 	if err := binary.Read(r, binary.LittleEndian, &lightmapCount); err != nil {
-		return nil, fmt.Errorf("%w: reading lightmap count", ErrTruncatedGNDData)
+		return "lightmaps", fmt.Errorf("%w: reading lightmap count", ErrTruncatedGNDData)
 	}
 	if err := binary.Read(r, binary.LittleEndian, &lightmapWidth); err != nil {
-		return nil, fmt.Errorf("%w: reading lightmap width", ErrTruncatedGNDData)
+		return "lightmaps", fmt.Errorf("%w: reading lightmap width", ErrTruncatedGNDData)
 	}
 	if err := binary.Read(r, binary.LittleEndian, &lightmapHeight); err != nil {
-		return nil, fmt.Errorf("%w: reading lightmap height", ErrTruncatedGNDData)
+		return "lightmaps", fmt.Errorf("%w: reading lightmap height", ErrTruncatedGNDData)
 	}
 	if err := binary.Read(r, binary.LittleEndian, &lightmapCells); err != nil {
-		return nil, fmt.Errorf("%w: reading lightmap cells", ErrTruncatedGNDData)
+		return "lightmaps", fmt.Errorf("%w: reading lightmap cells", ErrTruncatedGNDData)
 	}
 
 	gnd.LightmapWidth = lightmapWidth
 	gnd.LightmapHeight = lightmapHeight
 
-	pixelCount := lightmapWidth * lightmapHeight * lightmapCells
+	pixelCount := uint64(lightmapWidth) * uint64(lightmapHeight) * uint64(lightmapCells)
+	if pixelCount*4*uint64(lightmapCount) > uint64(r.Len()) {
+		return "lightmaps", fmt.Errorf("%w: %d lightmaps don't fit", ErrTruncatedGNDData, lightmapCount)
+	}
 	gnd.Lightmaps = make([]GNDLightmap, lightmapCount)
-	for i := uint32(0); i < lightmapCount; i++ {
-		gnd.Lightmaps[i].Brightness = make([]byte, pixelCount)
-		if _, err := r.Read(gnd.Lightmaps[i].Brightness); err != nil {
-			return nil, fmt.Errorf("%w: reading lightmap %d brightness", ErrTruncatedGNDData, i)
+	for i := range gnd.Lightmaps {
+		lm := &gnd.Lightmaps[i]
+		lm.Brightness = make([]byte, pixelCount)
+		lm.ColorRGB = make([]byte, pixelCount*3)
+		if _, err := io.ReadFull(r, lm.Brightness); err != nil {
+			return fmt.Sprintf("lightmap %d", i), fmt.Errorf("%w: reading brightness", ErrTruncatedGNDData)
 		}
-		gnd.Lightmaps[i].ColorRGB = make([]byte, pixelCount*3)
-		if _, err := r.Read(gnd.Lightmaps[i].ColorRGB); err != nil {
-			return nil, fmt.Errorf("%w: reading lightmap %d color", ErrTruncatedGNDData, i)
+		if _, err := io.ReadFull(r, lm.ColorRGB); err != nil {
+			return fmt.Sprintf("lightmap %d", i), fmt.Errorf("%w: reading color", ErrTruncatedGNDData)
 		}
 	}
 
 	// Read surfaces
 	var surfaceCount uint32
 	if err := binary.Read(r, binary.LittleEndian, &surfaceCount); err != nil {
-		return nil, fmt.Errorf("%w: reading surface count", ErrTruncatedGNDData)
+		return "surfaces", fmt.Errorf("%w: reading surface count", ErrTruncatedGNDData)
 	}
 
-	gnd.Surfaces = make([]GNDSurface, surfaceCount)
+	gnd.Surfaces = make([]GNDSurface, 0, min(surfaceCount, uint32(r.Len()/gndSurfaceSize)))
 	for i := uint32(0); i < surfaceCount; i++ {
 		surface, err := parseGNDSurface(r)
 		if err != nil {
-			return nil, fmt.Errorf("parsing surface %d: %w", i, err)
+			return fmt.Sprintf("surface %d", i), err
 		}
-		gnd.Surfaces[i] = surface
+		gnd.Surfaces = append(gnd.Surfaces, surface)
 	}
 
 	// Read tiles
-	tileCount := width * height
-	gnd.Tiles = make([]GNDTile, tileCount)
-	for i := uint32(0); i < tileCount; i++ {
+	for i := range gnd.Tiles {
 		tile, err := parseGNDTile(r)
 		if err != nil {
-			return nil, fmt.Errorf("parsing tile %d: %w", i, err)
+			return fmt.Sprintf("tile %d", i), err
 		}
 		gnd.Tiles[i] = tile
 	}
 
-	return gnd, nil
+	return "", nil
+}
+
+// gndSurfaceSize is the size of a surface in the file.
+const gndSurfaceSize = 4*4 + 4*4 + 2 + 2 + 4
+
+// checkSurfaces warns of tiles using surfaces the file doesn't have,
+// which are drawn open, and surfaces using textures it doesn't list.
+func (g *GND) checkSurfaces() {
+	missing := 0
+	for _, t := range g.Tiles {
+		for _, id := range [3]int32{t.TopSurface, t.FrontSurface, t.RightSurface} {
+			if int(id) >= len(g.Surfaces) {
+				missing++
+			}
+		}
+	}
+	if missing > 0 {
+		warnf(&g.Warnings, "tiles", "%d faces use surfaces past the %d in the file; left open", missing, len(g.Surfaces))
+	}
+	untextured := 0
+	for _, s := range g.Surfaces {
+		if int(s.TextureID) >= len(g.Textures) {
+			untextured++
+		}
+	}
+	if untextured > 0 {
+		warnf(&g.Warnings, "surfaces", "%d use textures past the %d in the file", untextured, len(g.Textures))
+	}
 }
 
 // parseGNDSurface parses a single GND surface.
@@ -254,7 +307,7 @@ func parseGNDSurface(r *bytes.Reader) (GNDSurface, error) {
 	}
 
 	// Read vertex color (BGRA)
-	if _, err := r.Read(surface.Color[:]); err != nil {
+	if _, err := io.ReadFull(r, surface.Color[:]); err != nil {
 		return GNDSurface{}, fmt.Errorf("%w: reading color", ErrTruncatedGNDData)
 	}
 
@@ -0,0 +1,137 @@
+package formats
+
+import (
+	"image"
+	"image/color"
+)
+
+// AverageColor returns the mean opaque color of a texture, skipping the
+// magenta (255, 0, 255) key and transparent pixels. It returns a zero color
+// for an image with no opaque pixels.
+func AverageColor(img image.Image) color.RGBA {
+	var r, g, b, n uint64
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if c.A == 0 || (c.R == 255 && c.G == 0 && c.B == 255) {
+				continue
+			}
+			r += uint64(c.R)
+			g += uint64(c.G)
+			b += uint64(c.B)
+			n++
+		}
+	}
+	if n == 0 {
+		return color.RGBA{}
+	}
+	return color.RGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n), A: 255}
+}
+
+// placeholderColor returns a stable color for a texture index whose
+// average color isn't known, so neighboring textures stay distinguishable.
+func placeholderColor(texture int) color.RGBA {
+	h := uint32(texture)*2654435761 + 0x9e3779b9 // Knuth multiplicative hash
+	return color.RGBA{
+		R: 96 + uint8(h>>24)%128,
+		G: 96 + uint8(h>>16)%128,
+		B: 96 + uint8(h>>8)%128,
+		A: 255,
+	}
+}
+
+// SurfaceColor returns the average color of a surface as seen from above:
+// its texture's color tinted by the surface's vertex color and shaded by
+// its lightmap. texColors holds the average color of each texture, e.g.
+// from AverageColor; textures missing from it get a placeholder color.
+// Surfaces without a texture, and unknown surface IDs, are transparent.
+func (g *GND) SurfaceColor(id int, texColors []color.RGBA) color.RGBA {
+	if id < 0 || id >= len(g.Surfaces) {
+		return color.RGBA{}
+	}
+	s := g.Surfaces[id]
+	if s.TextureID < 0 {
+		return color.RGBA{}
+	}
+
+	base := placeholderColor(int(s.TextureID))
+	if int(s.TextureID) < len(texColors) && texColors[s.TextureID].A != 0 {
+		base = texColors[s.TextureID]
+	}
+
+	// Vertex color is stored BGRA
+	r := float32(base.R) * float32(s.Color[2]) / 255
+	gr := float32(base.G) * float32(s.Color[1]) / 255
+	b := float32(base.B) * float32(s.Color[0]) / 255
+
+	if lm := int(s.LightmapID); lm >= 0 && lm < len(g.Lightmaps) {
+		shadow, add := g.Lightmaps[lm].average()
+		r = r*shadow + add[0]
+		gr = gr*shadow + add[1]
+		b = b*shadow + add[2]
+	}
+	return color.RGBA{R: clampByte(r), G: clampByte(gr), B: clampByte(b), A: 255}
+}
+
+// average returns a lightmap's mean brightness (0-1) and mean color.
+func (l GNDLightmap) average() (brightness float32, rgb [3]float32) {
+	if len(l.Brightness) == 0 {
+		return 1, rgb
+	}
+	var sum int
+	for _, v := range l.Brightness {
+		sum += int(v)
+	}
+	brightness = float32(sum) / float32(len(l.Brightness)) / 255
+
+	if n := len(l.ColorRGB) / 3; n > 0 {
+		var c [3]int
+		for i := range n {
+			c[0] += int(l.ColorRGB[i*3])
+			c[1] += int(l.ColorRGB[i*3+1])
+			c[2] += int(l.ColorRGB[i*3+2])
+		}
+		for i := range c {
+			rgb[i] = float32(c[i]) / float32(n)
+		}
+	}
+	return brightness, rgb
+}
+
+// RenderOverview rasterizes the ground seen from above into a width x
+// height image, one color per tile from its top surface (see
+// SurfaceColor). North is up: the last GND row is the image's first. Tiles
+// without a top surface are transparent. No GPU is needed, so it suits
+// tools and tests.
+func (g *GND) RenderOverview(width, height int, texColors []color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	if width <= 0 || height <= 0 || len(g.Tiles) < int(g.Width*g.Height) {
+		return img
+	}
+
+	// Each surface's color is computed once, on first use
+	colors := make([]color.RGBA, len(g.Surfaces))
+	known := make([]bool, len(g.Surfaces))
+
+	for py := range height {
+		ty := int(g.Height) - 1 - py*int(g.Height)/height
+		for px := range width {
+			tx := px * int(g.Width) / width
+			id := int(g.Tiles[ty*int(g.Width)+tx].TopSurface)
+			if id < 0 || id >= len(g.Surfaces) {
+				continue
+			}
+			if !known[id] {
+				colors[id] = g.SurfaceColor(id, texColors)
+				known[id] = true
+			}
+			img.SetRGBA(px, py, colors[id])
+		}
+	}
+	return img
+}
+
+func clampByte(v float32) uint8 {
+	return uint8(min(max(v, 0), 255))
+}
@@ -0,0 +1,94 @@
+package formats
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestAverageColor(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.SetRGBA(0, 0, color.RGBA{100, 0, 0, 255})
+	img.SetRGBA(1, 0, color.RGBA{200, 100, 50, 255})
+	img.SetRGBA(0, 1, color.RGBA{255, 0, 255, 255}) // Magenta key, skipped
+	// (1, 1) is transparent, skipped
+
+	want := color.RGBA{150, 50, 25, 255}
+	if got := AverageColor(img); got != want {
+		t.Errorf("AverageColor = %v, want %v", got, want)
+	}
+	if got := AverageColor(image.NewRGBA(image.Rect(0, 0, 1, 1))); got != (color.RGBA{}) {
+		t.Errorf("AverageColor(transparent) = %v, want zero", got)
+	}
+}
+
+func TestGND_SurfaceColor(t *testing.T) {
+	white := [4]uint8{255, 255, 255, 255}
+	g := &GND{
+		Surfaces: []GNDSurface{
+			{TextureID: 0, LightmapID: -1, Color: white},
+			{TextureID: 0, LightmapID: -1, Color: [4]uint8{0, 0, 255, 255}}, // Red tint (BGRA)
+			{TextureID: 0, LightmapID: 0, Color: white},
+			{TextureID: -1, LightmapID: -1, Color: white},
+			{TextureID: 7, LightmapID: -1, Color: white},
+		},
+		Lightmaps: []GNDLightmap{{
+			Brightness: []uint8{0, 255}, // Half shadow
+			ColorRGB:   []uint8{10, 0, 0, 10, 0, 0},
+		}},
+	}
+	texColors := []color.RGBA{{200, 100, 50, 255}}
+
+	tests := []struct {
+		name string
+		id   int
+		want color.RGBA
+	}{
+		{"plain", 0, color.RGBA{200, 100, 50, 255}},
+		{"vertex tint", 1, color.RGBA{200, 0, 0, 255}},
+		{"lightmap", 2, color.RGBA{110, 50, 25, 255}},
+		{"no texture", 3, color.RGBA{}},
+		{"unknown texture", 4, placeholderColor(7)},
+		{"unknown surface", 9, color.RGBA{}},
+	}
+	for _, tt := range tests {
+		if got := g.SurfaceColor(tt.id, texColors); got != tt.want {
+			t.Errorf("%s: SurfaceColor(%d) = %v, want %v", tt.name, tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestGND_RenderOverview(t *testing.T) {
+	g, err := ParseGND(createTestGND(2, 2, []string{"a.bmp", "b.bmp"}))
+	if err != nil {
+		t.Fatalf("ParseGND failed: %v", err)
+	}
+	white := [4]uint8{255, 255, 255, 255}
+	g.Surfaces = []GNDSurface{
+		{TextureID: 0, LightmapID: -1, Color: white},
+		{TextureID: 1, LightmapID: -1, Color: white},
+	}
+	g.GetTile(0, 0).TopSurface = 0 // South-west
+	g.GetTile(1, 1).TopSurface = 1 // North-east
+	red, blue := color.RGBA{255, 0, 0, 255}, color.RGBA{0, 0, 255, 255}
+
+	img := g.RenderOverview(4, 4, []color.RGBA{red, blue})
+	tests := []struct {
+		x, y int
+		want color.RGBA
+	}{
+		{0, 3, red},          // Bottom-left
+		{1, 2, red},          // Same tile, scaled up
+		{3, 0, blue},         // Top-right
+		{0, 0, color.RGBA{}}, // North-west has no top surface
+	}
+	for _, tt := range tests {
+		if got := img.RGBAAt(tt.x, tt.y); got != tt.want {
+			t.Errorf("pixel (%d, %d) = %v, want %v", tt.x, tt.y, got, tt.want)
+		}
+	}
+
+	if got := g.RenderOverview(0, 0, nil).Bounds().Empty(); !got {
+		t.Error("RenderOverview(0, 0) should be empty")
+	}
+}
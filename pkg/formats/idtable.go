@@ -0,0 +1,84 @@
+package formats
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Faultbox/midgard-ro/pkg/encoding"
+)
+
+// Item tables of the client, by item ID. Unidentified items show the
+// num2 tables' names and descriptions instead of the idnum2 ones.
+const (
+	ItemNameTablePath             = "data/idnum2itemdisplaynametable.txt"
+	ItemDescTablePath             = "data/idnum2itemdesctable.txt"
+	UnidentifiedItemNameTablePath = "data/num2itemdisplaynametable.txt"
+	UnidentifiedItemDescTablePath = "data/num2itemdesctable.txt"
+)
+
+// Card and slot tables of the client. Cards name the items they're slotted
+// in: by the prefix table's text, put after the item name instead for the
+// cards the postfix list holds. The slot count table has each item's card
+// slots as its text.
+const (
+	CardPrefixTablePath    = "data/cardprefixnametable.txt"
+	CardPostfixListPath    = "data/cardpostfixnametable.txt"
+	ItemSlotCountTablePath = "data/itemslotcounttable.txt"
+)
+
+// IDTable holds text by ID, as the item tables do: an ID, '#', the text,
+// which may span lines, and another '#'.
+type IDTable map[int]string
+
+// ParseIDTable parses an ID table such as idnum2itemdesctable.txt. Lines
+// starting with "//" are comments, and entries whose ID isn't a number are
+// skipped. Korean tables are EUC-KR; translated ones are usually UTF-8.
+func ParseIDTable(data []byte) IDTable {
+	text := string(data)
+	if !utf8.ValidString(text) {
+		text = encoding.EUCKRToUTF8(data)
+	}
+	var b strings.Builder
+	for line := range strings.Lines(text) {
+		if !strings.HasPrefix(strings.TrimSpace(line), "//") {
+			b.WriteString(line)
+		}
+	}
+
+	parts := strings.Split(b.String(), "#")
+	table := make(IDTable, len(parts)/2)
+	for i := 0; i+1 < len(parts)-1; i += 2 { // Text after the last '#' isn't an entry
+		id, err := strconv.Atoi(strings.TrimSpace(parts[i]))
+		if err != nil {
+			continue
+		}
+		table[id] = strings.Trim(parts[i+1], "\r\n")
+	}
+	return table
+}
+
+// Get returns the text of an ID, or false if the table doesn't have it or
+// it's empty.
+func (t IDTable) Get(id int) (string, bool) {
+	s, ok := t[id]
+	return s, ok && s != ""
+}
+
+// ParseIDList parses a list of IDs each followed by '#', such as
+// cardpostfixnametable.txt. Comments and IDs that aren't numbers are
+// skipped.
+func ParseIDList(data []byte) map[int]bool {
+	ids := make(map[int]bool)
+	for line := range strings.Lines(string(data)) {
+		if strings.HasPrefix(strings.TrimSpace(line), "//") {
+			continue
+		}
+		for field := range strings.SplitSeq(line, "#") {
+			if id, err := strconv.Atoi(strings.TrimSpace(field)); err == nil {
+				ids[id] = true
+			}
+		}
+	}
+	return ids
+}
@@ -0,0 +1,47 @@
+package formats
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseIDTable(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want IDTable
+	}{
+		{"names", "501#Red_Potion#\n502#Orange_Potion#\n", IDTable{501: "Red_Potion", 502: "Orange_Potion"}},
+		{"multiline", "501#\nA potion made from\r\nred herbs.\n#\n", IDTable{501: "A potion made from\r\nred herbs."}},
+		{"comments", "// Potions\n501#Red_Potion#\n  // 502#Orange_Potion#\n", IDTable{501: "Red_Potion"}},
+		{"bad id", "x#Nothing#\n503#Yellow_Potion#", IDTable{503: "Yellow_Potion"}},
+		{"unterminated tail", "501#Red_Potion#\n502#Orange", IDTable{501: "Red_Potion"}},
+		{"euc-kr", "501#\xbb\xa1\xb0\xa3\xc6\xf7\xbc\xc7#\n", IDTable{501: "빨간포션"}},
+		{"empty", "", IDTable{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseIDTable([]byte(tt.data)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseIDTable = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	table := IDTable{1: "a", 2: ""}
+	if s, ok := table.Get(1); !ok || s != "a" {
+		t.Errorf("Get(1) = %q, %v", s, ok)
+	}
+	for _, id := range []int{2, 3} {
+		if _, ok := table.Get(id); ok {
+			t.Errorf("Get(%d) found text", id)
+		}
+	}
+}
+
+func TestParseIDList(t *testing.T) {
+	data := "// Cards named after the item\n4035#\n4036#4037#\nx#\n  // 4038#\n"
+	want := map[int]bool{4035: true, 4036: true, 4037: true}
+	if got := ParseIDList([]byte(data)); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseIDList = %v, want %v", got, want)
+	}
+}
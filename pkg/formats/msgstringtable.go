@@ -0,0 +1,40 @@
+package formats
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Faultbox/midgard-ro/pkg/encoding"
+)
+
+// MsgStringTablePath is where the client's message table lives in the GRF.
+const MsgStringTablePath = "data/msgstringtable.txt"
+
+// MsgStringTable holds the client's UI messages (msgstringtable.txt),
+// indexed by line. Servers and the original client refer to messages by
+// these indexes, e.g. MsgStringTable[5] for a duplicate login.
+type MsgStringTable []string
+
+// ParseMsgStringTable parses msgstringtable.txt, where each message ends
+// with '#'. Korean tables are EUC-KR; translated ones are usually UTF-8.
+func ParseMsgStringTable(data []byte) MsgStringTable {
+	text := string(data)
+	if !utf8.ValidString(text) {
+		text = encoding.EUCKRToUTF8(data)
+	}
+	parts := strings.Split(text, "#")
+	table := make(MsgStringTable, 0, len(parts))
+	for _, p := range parts[:len(parts)-1] { // The text after the last '#' isn't a message
+		table = append(table, strings.TrimLeft(p, "\r\n"))
+	}
+	return table
+}
+
+// Get returns message index, or false if the table doesn't have it or it's
+// empty.
+func (t MsgStringTable) Get(index int) (string, bool) {
+	if index < 0 || index >= len(t) || t[index] == "" {
+		return "", false
+	}
+	return t[index], true
+}
@@ -0,0 +1,38 @@
+package formats
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMsgStringTable(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want MsgStringTable
+	}{
+		{"lines", "Server closed#\nSomeone has already logged in#\n", MsgStringTable{"Server closed", "Someone has already logged in"}},
+		{"crlf", "a#\r\nb#\r\n", MsgStringTable{"a", "b"}},
+		{"empty message", "a#\n#\nc#", MsgStringTable{"a", "", "c"}},
+		{"unterminated tail", "a#\nb", MsgStringTable{"a"}},
+		{"euc-kr", "\xbc\xad\xb9\xf6#\n", MsgStringTable{"서버"}},
+		{"empty", "", MsgStringTable{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseMsgStringTable([]byte(tt.data)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMsgStringTable = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	table := MsgStringTable{"a", ""}
+	if s, ok := table.Get(0); !ok || s != "a" {
+		t.Errorf("Get(0) = %q, %v", s, ok)
+	}
+	for _, i := range []int{-1, 1, 2} {
+		if _, ok := table.Get(i); ok {
+			t.Errorf("Get(%d) found a message", i)
+		}
+	}
+}
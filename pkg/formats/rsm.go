@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"slices"
 )
 
 // RSM format errors.
@@ -140,6 +141,10 @@ type RSM struct {
 	RootNode    string         // Root node name
 	Nodes       []RSMNode      // Node hierarchy
 	VolumeBoxes []RSMVolumeBox // Bounding volume boxes
+
+	// Warnings lists what a damaged file lost: nodes past the damage and
+	// faces pointing outside their node's mesh
+	Warnings []Warning
 }
 
 // ParseRSM parses RSM data from a byte slice.
@@ -192,8 +197,11 @@ func ParseRSM(data []byte) (*RSM, error) {
 	r.Seek(16, 1)
 
 	// Read texture count
-	var textureCount int32
-	binary.Read(r, binary.LittleEndian, &textureCount)
+	textureCount, err := rsmCount(r, 40, "texture")
+	if err != nil {
+		warnf(&rsm.Warnings, "textures", "%v; no nodes read", err)
+		return rsm, nil
+	}
 
 	// Read texture names
 	rsm.Textures = make([]string, textureCount)
@@ -212,14 +220,20 @@ func ParseRSM(data []byte) (*RSM, error) {
 		return nil, ErrInvalidNodeCount
 	}
 
-	// Parse nodes
-	rsm.Nodes = make([]RSMNode, nodeCount)
+	// Parse nodes. Past a damaged node the rest can't be found; the nodes
+	// read so far are kept
+	rsm.Nodes = make([]RSMNode, 0, nodeCount)
 	for i := int32(0); i < nodeCount; i++ {
 		node, err := parseRSMNode(r, rsm.Version)
 		if err != nil {
-			return nil, fmt.Errorf("parsing node %d: %w", i, err)
+			warnf(&rsm.Warnings, fmt.Sprintf("node %d", i), "%v; %d nodes dropped", err, nodeCount-i)
+			return rsm, nil
 		}
-		rsm.Nodes[i] = *node
+		if dropped := node.dropBrokenFaces(); dropped > 0 {
+			warnf(&rsm.Warnings, fmt.Sprintf("node %d (%s)", i, node.Name),
+				"%d faces use vertices or texture coordinates it doesn't have; dropped", dropped)
+		}
+		rsm.Nodes = append(rsm.Nodes, *node)
 	}
 
 	// Parse volume boxes (if data remains)
@@ -255,8 +269,10 @@ func parseRSMNode(r *bytes.Reader, version RSMVersion) (*RSMNode, error) {
 	node.Parent = readString40(r)
 
 	// Read texture indices
-	var textureCount int32
-	binary.Read(r, binary.LittleEndian, &textureCount)
+	textureCount, err := rsmCount(r, 4, "texture")
+	if err != nil {
+		return nil, err
+	}
 
 	if textureCount > 0 && textureCount < 1000 {
 		node.TextureIDs = make([]int32, textureCount)
@@ -284,8 +300,10 @@ func parseRSMNode(r *bytes.Reader, version RSMVersion) (*RSMNode, error) {
 	binary.Read(r, binary.LittleEndian, &node.Scale)
 
 	// Read vertices
-	var vertexCount int32
-	binary.Read(r, binary.LittleEndian, &vertexCount)
+	vertexCount, err := rsmCount(r, 12, "vertex")
+	if err != nil {
+		return nil, err
+	}
 
 	if vertexCount > 0 && vertexCount < 100000 {
 		node.Vertices = make([][3]float32, vertexCount)
@@ -295,8 +313,14 @@ func parseRSMNode(r *bytes.Reader, version RSMVersion) (*RSMNode, error) {
 	}
 
 	// Read texture coordinates
-	var texCoordCount int32
-	binary.Read(r, binary.LittleEndian, &texCoordCount)
+	texCoordSize := 8
+	if version.AtLeast(1, 2) {
+		texCoordSize += 4
+	}
+	texCoordCount, err := rsmCount(r, texCoordSize, "texture coordinate")
+	if err != nil {
+		return nil, err
+	}
 
 	if texCoordCount > 0 && texCoordCount < 100000 {
 		node.TexCoords = make([]RSMTexCoord, texCoordCount)
@@ -316,8 +340,14 @@ func parseRSMNode(r *bytes.Reader, version RSMVersion) (*RSMNode, error) {
 	}
 
 	// Read faces
-	var faceCount int32
-	binary.Read(r, binary.LittleEndian, &faceCount)
+	faceSize := 20
+	if version.AtLeast(1, 2) {
+		faceSize += 4
+	}
+	faceCount, err := rsmCount(r, faceSize, "face")
+	if err != nil {
+		return nil, err
+	}
 
 	if faceCount > 0 && faceCount < 100000 {
 		node.Faces = make([]RSMFace, faceCount)
@@ -384,6 +414,38 @@ func parseRSMNode(r *bytes.Reader, version RSMVersion) (*RSMNode, error) {
 	return node, nil
 }
 
+// rsmCount reads the count of a node's items of size bytes each, failing
+// when the data left can't hold them.
+func rsmCount(r *bytes.Reader, size int, what string) (int32, error) {
+	var n int32
+	if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+		return 0, fmt.Errorf("%w: reading %s count", ErrTruncatedRSMData, what)
+	}
+	if n < 0 || int64(n)*int64(size) > int64(r.Len()) {
+		return 0, fmt.Errorf("%w: %s count %d doesn't fit the data left", ErrTruncatedRSMData, what, n)
+	}
+	return n, nil
+}
+
+// dropBrokenFaces removes the faces using vertices or texture coordinates
+// the node doesn't have, returning how many there were. Nodes without
+// texture coordinates are left to the renderer's defaults.
+func (n *RSMNode) dropBrokenFaces() int {
+	before := len(n.Faces)
+	n.Faces = slices.DeleteFunc(n.Faces, func(f RSMFace) bool {
+		for i := range 3 {
+			if int(f.VertexIDs[i]) >= len(n.Vertices) {
+				return true
+			}
+			if len(n.TexCoords) > 0 && int(f.TexCoordIDs[i]) >= len(n.TexCoords) {
+				return true
+			}
+		}
+		return false
+	})
+	return before - len(n.Faces)
+}
+
 // ParseRSMFile parses an RSM file from disk.
 func ParseRSMFile(path string) (*RSM, error) {
 	data, err := os.ReadFile(path)
@@ -6,6 +6,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 )
 
@@ -98,15 +99,16 @@ type RSWGround struct {
 
 // RSWModel represents a 3D model placed in the world.
 type RSWModel struct {
-	Name      string     // Object instance name
-	AnimType  int32      // Animation type
-	AnimSpeed float32    // Animation playback speed
-	BlockType int32      // Collision type
-	ModelName string     // RSM model file name
-	NodeName  string     // Node name within model
-	Position  [3]float32 // World position (X, Y, Z)
-	Rotation  [3]float32 // Rotation angles (X, Y, Z)
-	Scale     [3]float32 // Scale factors (X, Y, Z)
+	Name          string     // Object instance name
+	AnimType      int32      // Animation type
+	AnimSpeed     float32    // Animation playback speed
+	BlockType     int32      // Collision type
+	CollisionFlag uint8      // Unknown byte after BlockType (v2.6.162+)
+	ModelName     string     // RSM model file name
+	NodeName      string     // Node name within model
+	Position      [3]float32 // World position (X, Y, Z)
+	Rotation      [3]float32 // Rotation angles (X, Y, Z)
+	Scale         [3]float32 // Scale factors (X, Y, Z)
 }
 
 // RSWLightSource represents a point light in the world.
@@ -158,7 +160,13 @@ type RSW struct {
 	Light    RSWLight
 	Ground   RSWGround
 	Objects  []RSWObject
-	Quadtree [][4]float32 // Scene partitioning (v2.1+)
+	Quadtree [][4]float32 // Scene partitioning (v2.1+), three entries per node; see BuildQuadtree
+
+	RenderFlag uint8 // Unknown header byte following the build number (v2.5+)
+
+	// Warnings lists what a damaged file lost: the settings and objects
+	// past the damage, which keep their defaults or are dropped
+	Warnings []Warning
 }
 
 // CountByType returns the count of objects for each type.
@@ -246,108 +254,97 @@ func ParseRSW(data []byte) (*RSW, error) {
 	if version.AtLeast(2, 2) {
 		if version.AtLeast(2, 5) {
 			// v2.5+ uses uint32 build number + uint8 unknown flag
+			if len(data) < offset+5 {
+				return nil, fmt.Errorf("%w: reading build number", ErrTruncatedRSWData)
+			}
 			rsw.Version.BuildNumber = binary.LittleEndian.Uint32(data[offset:])
 			offset += 4
-			offset++ // skip unknown render flag
+			rsw.RenderFlag = data[offset]
+			offset++
 		} else {
 			// v2.2-2.4 uses uint8 build number
+			if len(data) < offset+1 {
+				return nil, fmt.Errorf("%w: reading build number", ErrTruncatedRSWData)
+			}
 			rsw.Version.BuildNumber = uint32(data[offset])
 			offset++
 		}
 	}
 
-	// Read file references (each 40 bytes, null-terminated)
-	rsw.IniFile = readNullString(data[offset : offset+40])
-	offset += 40
-	rsw.GndFile = readNullString(data[offset : offset+40])
-	offset += 40
-
+	// Read file references (each 40 bytes, null-terminated).
 	// GAT and SRC files added in v1.4+
+	fileRefs := []*string{&rsw.IniFile, &rsw.GndFile}
 	if version.AtLeast(1, 4) {
-		rsw.GatFile = readNullString(data[offset : offset+40])
-		offset += 40
-		rsw.SrcFile = readNullString(data[offset : offset+40])
+		fileRefs = append(fileRefs, &rsw.GatFile, &rsw.SrcFile)
+	}
+	for _, ref := range fileRefs {
+		if len(data) < offset+40 {
+			return nil, fmt.Errorf("%w: reading file references", ErrTruncatedRSWData)
+		}
+		*ref = readNullString(data[offset : offset+40])
 		offset += 40
 	}
 
 	r := bytes.NewReader(data[offset:])
+	if section, err := parseRSWBody(r, rsw); err != nil {
+		warnf(&rsw.Warnings, section, "%v; the rest of the file dropped", err)
+	}
+	return rsw, nil
+}
+
+// parseRSWBody reads everything after the file references into rsw, up
+// to the first damaged section, which it returns with the error. Settings
+// not reached keep their defaults.
+func parseRSWBody(r *bytes.Reader, rsw *RSW) (string, error) {
+	version := rsw.Version
 
 	// Water settings (v1.3+ but not v2.6+ where it moved to GND)
+	rsw.Water = defaultRSWWater()
+	rsw.Light = defaultRSWLight()
+	rsw.Ground = defaultRSWGround()
 	if version.AtLeast(1, 3) && !version.AtLeast(2, 6) {
-		if err := binary.Read(r, binary.LittleEndian, &rsw.Water.Level); err != nil {
-			return nil, fmt.Errorf("%w: reading water level", ErrTruncatedRSWData)
-		}
-		if err := binary.Read(r, binary.LittleEndian, &rsw.Water.Type); err != nil {
-			return nil, fmt.Errorf("%w: reading water type", ErrTruncatedRSWData)
-		}
-		if err := binary.Read(r, binary.LittleEndian, &rsw.Water.WaveHeight); err != nil {
-			return nil, fmt.Errorf("%w: reading wave height", ErrTruncatedRSWData)
-		}
-		if err := binary.Read(r, binary.LittleEndian, &rsw.Water.WaveSpeed); err != nil {
-			return nil, fmt.Errorf("%w: reading wave speed", ErrTruncatedRSWData)
-		}
-		if err := binary.Read(r, binary.LittleEndian, &rsw.Water.WavePitch); err != nil {
-			return nil, fmt.Errorf("%w: reading wave pitch", ErrTruncatedRSWData)
-		}
-		if err := binary.Read(r, binary.LittleEndian, &rsw.Water.AnimSpeed); err != nil {
-			return nil, fmt.Errorf("%w: reading water anim speed", ErrTruncatedRSWData)
+		if err := parseRSWWater(r, version, &rsw.Water); err != nil {
+			return "water", err
 		}
 	}
 
-	// Light settings (v1.5+)
+	// Light settings (v1.5+), shadow opacity (v1.7+)
 	if version.AtLeast(1, 5) {
-		if err := binary.Read(r, binary.LittleEndian, &rsw.Light.Longitude); err != nil {
-			return nil, fmt.Errorf("%w: reading light longitude", ErrTruncatedRSWData)
-		}
-		if err := binary.Read(r, binary.LittleEndian, &rsw.Light.Latitude); err != nil {
-			return nil, fmt.Errorf("%w: reading light latitude", ErrTruncatedRSWData)
-		}
-		for i := 0; i < 3; i++ {
-			if err := binary.Read(r, binary.LittleEndian, &rsw.Light.Diffuse[i]); err != nil {
-				return nil, fmt.Errorf("%w: reading diffuse[%d]", ErrTruncatedRSWData, i)
-			}
-		}
-		for i := 0; i < 3; i++ {
-			if err := binary.Read(r, binary.LittleEndian, &rsw.Light.Ambient[i]); err != nil {
-				return nil, fmt.Errorf("%w: reading ambient[%d]", ErrTruncatedRSWData, i)
-			}
-		}
-	}
-
-	// Shadow opacity (v1.7+)
-	if version.AtLeast(1, 7) {
-		if err := binary.Read(r, binary.LittleEndian, &rsw.Light.Opacity); err != nil {
-			return nil, fmt.Errorf("%w: reading shadow opacity", ErrTruncatedRSWData)
+		if err := parseRSWLightSettings(r, version, &rsw.Light); err != nil {
+			return "light", err
 		}
 	}
 
 	// Ground bounds (v1.6+)
 	if version.AtLeast(1, 6) {
-		if err := binary.Read(r, binary.LittleEndian, &rsw.Ground.Top); err != nil {
-			return nil, fmt.Errorf("%w: reading ground top", ErrTruncatedRSWData)
+		var ground RSWGround
+		if err := binary.Read(r, binary.LittleEndian, &ground.Top); err != nil {
+			return "ground", fmt.Errorf("%w: reading ground top", ErrTruncatedRSWData)
 		}
-		if err := binary.Read(r, binary.LittleEndian, &rsw.Ground.Bottom); err != nil {
-			return nil, fmt.Errorf("%w: reading ground bottom", ErrTruncatedRSWData)
+		if err := binary.Read(r, binary.LittleEndian, &ground.Bottom); err != nil {
+			return "ground", fmt.Errorf("%w: reading ground bottom", ErrTruncatedRSWData)
 		}
-		if err := binary.Read(r, binary.LittleEndian, &rsw.Ground.Left); err != nil {
-			return nil, fmt.Errorf("%w: reading ground left", ErrTruncatedRSWData)
+		if err := binary.Read(r, binary.LittleEndian, &ground.Left); err != nil {
+			return "ground", fmt.Errorf("%w: reading ground left", ErrTruncatedRSWData)
 		}
-		if err := binary.Read(r, binary.LittleEndian, &rsw.Ground.Right); err != nil {
-			return nil, fmt.Errorf("%w: reading ground right", ErrTruncatedRSWData)
+		if err := binary.Read(r, binary.LittleEndian, &ground.Right); err != nil {
+			return "ground", fmt.Errorf("%w: reading ground right", ErrTruncatedRSWData)
 		}
+		rsw.Ground = ground
 	}
 
-	// Read objects
+	// Read objects. Past a damaged object the rest can't be found; the
+	// objects read so far are kept
 	var objectCount uint32
 	if err := binary.Read(r, binary.LittleEndian, &objectCount); err != nil {
-		return nil, fmt.Errorf("%w: reading object count", ErrTruncatedRSWData)
+		return "objects", fmt.Errorf("%w: reading object count", ErrTruncatedRSWData)
 	}
 
-	rsw.Objects = make([]RSWObject, 0, objectCount)
+	rsw.Objects = make([]RSWObject, 0, min(objectCount, uint32(r.Len()/rswObjectMinSize)))
 	for i := uint32(0); i < objectCount; i++ {
 		obj, err := parseRSWObject(r, rsw.Version)
 		if err != nil {
-			return nil, fmt.Errorf("parsing object %d: %w", i, err)
+			return fmt.Sprintf("object %d", i), fmt.Errorf("%w; %d objects dropped", err, objectCount-i)
 		}
 		rsw.Objects = append(rsw.Objects, obj)
 	}
@@ -365,7 +362,108 @@ func ParseRSW(data []byte) (*RSW, error) {
 		}
 	}
 
-	return rsw, nil
+	return "", nil
+}
+
+// rswObjectMinSize is the size of the smallest object, a light: type,
+// name, position, color and range.
+const rswObjectMinSize = 4 + 80 + 12 + 12 + 4
+
+// defaultRSWWater returns the water settings the client assumes when
+// the file predates the corresponding fields.
+func defaultRSWWater() RSWWater {
+	return RSWWater{
+		Level:      0,
+		Type:       0,
+		WaveHeight: 1.0,
+		WaveSpeed:  2.0,
+		WavePitch:  50.0,
+		AnimSpeed:  3,
+	}
+}
+
+// defaultRSWLight returns the global light used by maps older than v1.5.
+func defaultRSWLight() RSWLight {
+	return RSWLight{
+		Longitude: 45,
+		Latitude:  45,
+		Diffuse:   [3]float32{1, 1, 1},
+		Ambient:   [3]float32{0.3, 0.3, 0.3},
+		Opacity:   1.0,
+	}
+}
+
+// defaultRSWGround returns the ground bounds used by maps older than v1.6.
+func defaultRSWGround() RSWGround {
+	return RSWGround{
+		Top:    -500,
+		Bottom: 500,
+		Left:   -500,
+		Right:  500,
+	}
+}
+
+// parseRSWWater reads the water block. Fields were added incrementally:
+//
+//	v1.3: level
+//	v1.8: type, wave height, wave speed, wave pitch
+//	v1.9: texture animation speed
+func parseRSWWater(r *bytes.Reader, version RSWVersion, water *RSWWater) error {
+	if err := binary.Read(r, binary.LittleEndian, &water.Level); err != nil {
+		return fmt.Errorf("%w: reading water level", ErrTruncatedRSWData)
+	}
+
+	if version.AtLeast(1, 8) {
+		if err := binary.Read(r, binary.LittleEndian, &water.Type); err != nil {
+			return fmt.Errorf("%w: reading water type", ErrTruncatedRSWData)
+		}
+		if err := binary.Read(r, binary.LittleEndian, &water.WaveHeight); err != nil {
+			return fmt.Errorf("%w: reading wave height", ErrTruncatedRSWData)
+		}
+		if err := binary.Read(r, binary.LittleEndian, &water.WaveSpeed); err != nil {
+			return fmt.Errorf("%w: reading wave speed", ErrTruncatedRSWData)
+		}
+		if err := binary.Read(r, binary.LittleEndian, &water.WavePitch); err != nil {
+			return fmt.Errorf("%w: reading wave pitch", ErrTruncatedRSWData)
+		}
+	}
+
+	if version.AtLeast(1, 9) {
+		if err := binary.Read(r, binary.LittleEndian, &water.AnimSpeed); err != nil {
+			return fmt.Errorf("%w: reading water anim speed", ErrTruncatedRSWData)
+		}
+	}
+
+	return nil
+}
+
+// parseRSWLightSettings reads the global light block (v1.5+).
+// Shadow opacity follows the ambient color from v1.7 onward.
+func parseRSWLightSettings(r *bytes.Reader, version RSWVersion, light *RSWLight) error {
+	if err := binary.Read(r, binary.LittleEndian, &light.Longitude); err != nil {
+		return fmt.Errorf("%w: reading light longitude", ErrTruncatedRSWData)
+	}
+	if err := binary.Read(r, binary.LittleEndian, &light.Latitude); err != nil {
+		return fmt.Errorf("%w: reading light latitude", ErrTruncatedRSWData)
+	}
+	for i := 0; i < 3; i++ {
+		if err := binary.Read(r, binary.LittleEndian, &light.Diffuse[i]); err != nil {
+			return fmt.Errorf("%w: reading diffuse[%d]", ErrTruncatedRSWData, i)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if err := binary.Read(r, binary.LittleEndian, &light.Ambient[i]); err != nil {
+			return fmt.Errorf("%w: reading ambient[%d]", ErrTruncatedRSWData, i)
+		}
+	}
+
+	if version.AtLeast(1, 7) {
+		if err := binary.Read(r, binary.LittleEndian, &light.Opacity); err != nil {
+			return fmt.Errorf("%w: reading shadow opacity", ErrTruncatedRSWData)
+		}
+	}
+
+	return nil
 }
 
 // parseRSWObject parses a single RSW object.
@@ -416,41 +514,42 @@ func parseRSWObject(r *bytes.Reader, version RSWVersion) (RSWObject, error) {
 func parseRSWModel(r *bytes.Reader, version RSWVersion) (*RSWModel, error) {
 	model := &RSWModel{}
 
-	// Name (40 bytes in older versions, varies by version)
-	nameBytes := make([]byte, 40)
-	if _, err := r.Read(nameBytes); err != nil {
-		return nil, fmt.Errorf("%w: reading model name", ErrTruncatedRSWData)
-	}
-	model.Name = readNullStringBytes(nameBytes)
+	// Instance name, animation and collision fields (v1.3+)
+	if version.AtLeast(1, 3) {
+		nameBytes := make([]byte, 40)
+		if _, err := io.ReadFull(r, nameBytes); err != nil {
+			return nil, fmt.Errorf("%w: reading model name", ErrTruncatedRSWData)
+		}
+		model.Name = readNullStringBytes(nameBytes)
 
-	if err := binary.Read(r, binary.LittleEndian, &model.AnimType); err != nil {
-		return nil, fmt.Errorf("%w: reading anim type", ErrTruncatedRSWData)
-	}
-	if err := binary.Read(r, binary.LittleEndian, &model.AnimSpeed); err != nil {
-		return nil, fmt.Errorf("%w: reading anim speed", ErrTruncatedRSWData)
-	}
-	if err := binary.Read(r, binary.LittleEndian, &model.BlockType); err != nil {
-		return nil, fmt.Errorf("%w: reading block type", ErrTruncatedRSWData)
+		if err := binary.Read(r, binary.LittleEndian, &model.AnimType); err != nil {
+			return nil, fmt.Errorf("%w: reading anim type", ErrTruncatedRSWData)
+		}
+		if err := binary.Read(r, binary.LittleEndian, &model.AnimSpeed); err != nil {
+			return nil, fmt.Errorf("%w: reading anim speed", ErrTruncatedRSWData)
+		}
+		if err := binary.Read(r, binary.LittleEndian, &model.BlockType); err != nil {
+			return nil, fmt.Errorf("%w: reading block type", ErrTruncatedRSWData)
+		}
 	}
 
 	// v2.6.162+ adds an unknown byte after block type (collision flags)
 	if version.AtLeast(2, 6) && version.BuildNumber >= 162 {
-		var unknownByte uint8
-		if err := binary.Read(r, binary.LittleEndian, &unknownByte); err != nil {
+		if err := binary.Read(r, binary.LittleEndian, &model.CollisionFlag); err != nil {
 			return nil, fmt.Errorf("%w: reading model unknown byte", ErrTruncatedRSWData)
 		}
 	}
 
 	// Model name (80 bytes)
 	modelNameBytes := make([]byte, 80)
-	if _, err := r.Read(modelNameBytes); err != nil {
+	if _, err := io.ReadFull(r, modelNameBytes); err != nil {
 		return nil, fmt.Errorf("%w: reading model file name", ErrTruncatedRSWData)
 	}
 	model.ModelName = readNullStringBytes(modelNameBytes)
 
 	// Node name (80 bytes)
 	nodeNameBytes := make([]byte, 80)
-	if _, err := r.Read(nodeNameBytes); err != nil {
+	if _, err := io.ReadFull(r, nodeNameBytes); err != nil {
 		return nil, fmt.Errorf("%w: reading node name", ErrTruncatedRSWData)
 	}
 	model.NodeName = readNullStringBytes(nodeNameBytes)
@@ -481,7 +580,7 @@ func parseRSWLight(r *bytes.Reader, _ RSWVersion) (*RSWLightSource, error) {
 
 	// Name (80 bytes)
 	nameBytes := make([]byte, 80)
-	if _, err := r.Read(nameBytes); err != nil {
+	if _, err := io.ReadFull(r, nameBytes); err != nil {
 		return nil, fmt.Errorf("%w: reading light name", ErrTruncatedRSWData)
 	}
 	light.Name = readNullStringBytes(nameBytes)
@@ -513,14 +612,14 @@ func parseRSWSound(r *bytes.Reader, version RSWVersion) (*RSWSoundSource, error)
 
 	// Name (80 bytes)
 	nameBytes := make([]byte, 80)
-	if _, err := r.Read(nameBytes); err != nil {
+	if _, err := io.ReadFull(r, nameBytes); err != nil {
 		return nil, fmt.Errorf("%w: reading sound name", ErrTruncatedRSWData)
 	}
 	sound.Name = readNullStringBytes(nameBytes)
 
 	// File (80 bytes)
 	fileBytes := make([]byte, 80)
-	if _, err := r.Read(fileBytes); err != nil {
+	if _, err := io.ReadFull(r, fileBytes); err != nil {
 		return nil, fmt.Errorf("%w: reading sound file", ErrTruncatedRSWData)
 	}
 	sound.File = readNullStringBytes(fileBytes)
@@ -545,7 +644,8 @@ func parseRSWSound(r *bytes.Reader, version RSWVersion) (*RSWSoundSource, error)
 		return nil, fmt.Errorf("%w: reading sound range", ErrTruncatedRSWData)
 	}
 
-	// Cycle added in v2.0+
+	// Cycle added in v2.0+; older maps loop every 4 seconds
+	sound.Cycle = 4.0
 	if version.AtLeast(2, 0) {
 		if err := binary.Read(r, binary.LittleEndian, &sound.Cycle); err != nil {
 			return nil, fmt.Errorf("%w: reading sound cycle", ErrTruncatedRSWData)
@@ -561,7 +661,7 @@ func parseRSWEffect(r *bytes.Reader, _ RSWVersion) (*RSWEffectSource, error) {
 
 	// Name (80 bytes)
 	nameBytes := make([]byte, 80)
-	if _, err := r.Read(nameBytes); err != nil {
+	if _, err := io.ReadFull(r, nameBytes); err != nil {
 		return nil, fmt.Errorf("%w: reading effect name", ErrTruncatedRSWData)
 	}
 	effect.Name = readNullStringBytes(nameBytes)
@@ -614,3 +714,235 @@ func ParseRSWFile(path string) (*RSW, error) {
 	}
 	return ParseRSW(data)
 }
+
+// rswQuadtreeDepth is the number of levels below the root of the client's
+// quadtree, which has 1365 nodes.
+const rswQuadtreeDepth = 5
+
+// BuildQuadtree replaces the quadtree with one over the ground's extent, in
+// RSW coordinates: each node splits into quarters on X/Z, lower Z first,
+// and spans in Y the ground, the water level and the model boxes (min and
+// max corners) overlapping it. Each node is stored as its max, min, half
+// size and center, three entries.
+func (r *RSW) BuildQuadtree(gnd *GND, models [][2][3]float32) {
+	b := quadtreeBuilder{gnd: gnd, models: models, water: r.Water.Level}
+	w, h := float32(gnd.Width)*gnd.Zoom, float32(gnd.Height)*gnd.Zoom
+	b.node([2]float32{-w / 2, -h / 2}, [2]float32{w / 2, h / 2}, rswQuadtreeDepth)
+	r.Quadtree = b.nodes
+}
+
+// quadtreeBuilder accumulates quadtree nodes depth first.
+type quadtreeBuilder struct {
+	gnd    *GND
+	models [][2][3]float32
+	water  float32
+	nodes  [][4]float32
+}
+
+// node appends the node covering from..to on X/Z, then its children.
+func (b *quadtreeBuilder) node(from, to [2]float32, depth int) {
+	lo, hi := b.water, b.water
+	g := b.gnd
+	if g.Zoom > 0 {
+		// Tiles are numbered from the map's -X/-Z corner
+		ox, oz := float32(g.Width)*g.Zoom/2, float32(g.Height)*g.Zoom/2
+		x0, x1 := tileSpan(from[0]+ox, to[0]+ox, g.Zoom, int(g.Width))
+		y0, y1 := tileSpan(from[1]+oz, to[1]+oz, g.Zoom, int(g.Height))
+		for y := y0; y < y1; y++ {
+			for x := x0; x < x1; x++ {
+				for _, a := range g.Tiles[y*int(g.Width)+x].Altitude {
+					lo, hi = min(lo, a), max(hi, a)
+				}
+			}
+		}
+	}
+	for _, m := range b.models {
+		if m[0][0] <= to[0] && m[1][0] >= from[0] && m[0][2] <= to[1] && m[1][2] >= from[1] {
+			lo, hi = min(lo, m[0][1]), max(hi, m[1][1])
+		}
+	}
+
+	half := [3]float32{(to[0] - from[0]) / 2, (hi - lo) / 2, (to[1] - from[1]) / 2}
+	center := [3]float32{from[0] + half[0], lo + half[1], from[1] + half[2]}
+	b.nodes = append(b.nodes,
+		[4]float32{to[0], hi, to[1], from[0]},
+		[4]float32{lo, from[1], half[0], half[1]},
+		[4]float32{half[2], center[0], center[1], center[2]},
+	)
+	if depth == 0 {
+		return
+	}
+	mid := [2]float32{center[0], center[2]}
+	b.node(from, mid, depth-1)
+	b.node([2]float32{mid[0], from[1]}, [2]float32{to[0], mid[1]}, depth-1)
+	b.node([2]float32{from[0], mid[1]}, [2]float32{mid[0], to[1]}, depth-1)
+	b.node(mid, to, depth-1)
+}
+
+// tileSpan returns the tiles of a row of n, zoom units each, that lo..hi
+// overlaps, at least one.
+func tileSpan(lo, hi, zoom float32, n int) (first, end int) {
+	first = max(min(int(lo/zoom), n-1), 0)
+	end = min(max(int(hi/zoom+0.999), first+1), n)
+	return first, end
+}
+
+// EncodeRSW serializes a RSW to the file format ParseRSW reads, writing
+// only the fields present in its version. The quadtree is written as it is,
+// so callers that move models or the water rebuild it with BuildQuadtree
+// first.
+func EncodeRSW(rsw *RSW) ([]byte, error) {
+	v := rsw.Version
+	if v.Major < 1 || v.Major > 2 || (v.Major == 2 && v.Minor > 6) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedRSWVersion, v)
+	}
+
+	w := &rswWriter{}
+	w.buf.WriteString("GRSW")
+	w.write([]uint8{v.Major, v.Minor})
+	if v.AtLeast(2, 5) {
+		w.write(v.BuildNumber)
+		w.write(rsw.RenderFlag)
+	} else if v.AtLeast(2, 2) {
+		w.write(uint8(v.BuildNumber))
+	}
+
+	w.string(rsw.IniFile, 40)
+	w.string(rsw.GndFile, 40)
+	if v.AtLeast(1, 4) {
+		w.string(rsw.GatFile, 40)
+		w.string(rsw.SrcFile, 40)
+	}
+
+	if v.AtLeast(1, 3) && !v.AtLeast(2, 6) {
+		w.write(rsw.Water.Level)
+		if v.AtLeast(1, 8) {
+			w.write(rsw.Water.Type)
+			w.write([]float32{rsw.Water.WaveHeight, rsw.Water.WaveSpeed, rsw.Water.WavePitch})
+		}
+		if v.AtLeast(1, 9) {
+			w.write(rsw.Water.AnimSpeed)
+		}
+	}
+
+	if v.AtLeast(1, 5) {
+		w.write([]int32{rsw.Light.Longitude, rsw.Light.Latitude})
+		w.write(rsw.Light.Diffuse)
+		w.write(rsw.Light.Ambient)
+		if v.AtLeast(1, 7) {
+			w.write(rsw.Light.Opacity)
+		}
+	}
+
+	if v.AtLeast(1, 6) {
+		w.write([]int32{rsw.Ground.Top, rsw.Ground.Bottom, rsw.Ground.Left, rsw.Ground.Right})
+	}
+
+	if w.err != nil {
+		return nil, fmt.Errorf("encoding file references: %w", w.err)
+	}
+
+	w.write(uint32(len(rsw.Objects)))
+	for i, obj := range rsw.Objects {
+		if err := w.object(obj, v); err != nil {
+			return nil, fmt.Errorf("encoding object %d: %w", i, err)
+		}
+	}
+
+	if v.AtLeast(2, 1) {
+		w.write(rsw.Quadtree)
+	}
+	return w.buf.Bytes(), nil
+}
+
+// WriteRSWFile encodes a RSW and writes it to disk.
+func WriteRSWFile(path string, rsw *RSW) error {
+	data, err := EncodeRSW(rsw)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("writing RSW file: %w", err)
+	}
+	return nil
+}
+
+// rswWriter accumulates an encoded RSW, keeping the first error.
+type rswWriter struct {
+	buf bytes.Buffer
+	err error
+}
+
+// write appends a little-endian value.
+func (w *rswWriter) write(v any) {
+	_ = binary.Write(&w.buf, binary.LittleEndian, v)
+}
+
+// string appends s as a null-padded field of n bytes. Strings that don't
+// fit with their terminator are an error rather than silently cut.
+func (w *rswWriter) string(s string, n int) {
+	if len(s) >= n {
+		if w.err == nil {
+			w.err = fmt.Errorf("string %q longer than %d bytes", s, n-1)
+		}
+		s = s[:n-1]
+	}
+	field := make([]byte, n)
+	copy(field, s)
+	w.buf.Write(field)
+}
+
+// object appends an object in the layout parseRSWObject reads.
+func (w *rswWriter) object(obj RSWObject, v RSWVersion) error {
+	w.err = nil
+	w.write(obj.Type)
+	switch {
+	case obj.Type == RSWObjectModel && obj.Model != nil:
+		m := obj.Model
+		if v.AtLeast(1, 3) {
+			w.string(m.Name, 40)
+			w.write(m.AnimType)
+			w.write(m.AnimSpeed)
+			w.write(m.BlockType)
+		}
+		if v.AtLeast(2, 6) && v.BuildNumber >= 162 {
+			w.write(m.CollisionFlag)
+		}
+		w.string(m.ModelName, 80)
+		w.string(m.NodeName, 80)
+		w.write(m.Position)
+		w.write(m.Rotation)
+		w.write(m.Scale)
+
+	case obj.Type == RSWObjectLight && obj.Light != nil:
+		l := obj.Light
+		w.string(l.Name, 80)
+		w.write(l.Position)
+		w.write(l.Color)
+		w.write(l.Range)
+
+	case obj.Type == RSWObjectSound && obj.Sound != nil:
+		s := obj.Sound
+		w.string(s.Name, 80)
+		w.string(s.File, 80)
+		w.write(s.Position)
+		w.write(s.Volume)
+		w.write([]int32{s.Width, s.Height})
+		w.write(s.Range)
+		if v.AtLeast(2, 0) {
+			w.write(s.Cycle)
+		}
+
+	case obj.Type == RSWObjectEffect && obj.Effect != nil:
+		e := obj.Effect
+		w.string(e.Name, 80)
+		w.write(e.Position)
+		w.write(e.EffectID)
+		w.write(e.Delay)
+		w.write(e.Param)
+
+	default:
+		return fmt.Errorf("%w: %d without its data", ErrUnknownObjectType, obj.Type)
+	}
+	return w.err
+}
@@ -1,6 +1,9 @@
 package formats
 
 import (
+	"bytes"
+	"encoding/binary"
+	"errors"
 	"testing"
 )
 
@@ -274,8 +277,261 @@ func TestRSW_GetLights(t *testing.T) {
 	}
 }
 
+func TestParseRSW_VersionMatrix(t *testing.T) {
+	// One synthetic map per client era, each carrying a model and a sound so
+	// object layouts are exercised alongside the header blocks.
+	tests := []struct {
+		name     string
+		major    uint8
+		minor    uint8
+		build    uint32
+		wantGAT  string
+		wantWtr  RSWWater
+		wantOpac float32
+		wantGrnd RSWGround
+		wantCyc  float32
+		wantName string
+		wantQuad int
+	}{
+		{
+			name: "2002 client v1.2", major: 1, minor: 2,
+			wantWtr:  defaultRSWWater(),
+			wantOpac: 1.0, wantGrnd: defaultRSWGround(), wantCyc: 4.0,
+		},
+		{
+			name: "v1.3 water level only", major: 1, minor: 3,
+			wantWtr:  RSWWater{Level: -1.5, WaveHeight: 1.0, WaveSpeed: 2.0, WavePitch: 50.0, AnimSpeed: 3},
+			wantOpac: 1.0, wantGrnd: defaultRSWGround(), wantCyc: 4.0, wantName: "obj",
+		},
+		{
+			name: "v1.5 light without opacity", major: 1, minor: 5, wantGAT: "map.gat",
+			wantWtr:  RSWWater{Level: -1.5, WaveHeight: 1.0, WaveSpeed: 2.0, WavePitch: 50.0, AnimSpeed: 3},
+			wantOpac: 1.0, wantGrnd: defaultRSWGround(), wantCyc: 4.0, wantName: "obj",
+		},
+		{
+			name: "v1.8 wave fields", major: 1, minor: 8, wantGAT: "map.gat",
+			wantWtr:  RSWWater{Level: -1.5, Type: 2, WaveHeight: 0.5, WaveSpeed: 3.0, WavePitch: 40.0, AnimSpeed: 3},
+			wantOpac: 0.7, wantGrnd: testRSWGround, wantCyc: 4.0, wantName: "obj",
+		},
+		{
+			name: "2004 client v1.9", major: 1, minor: 9, wantGAT: "map.gat",
+			wantWtr:  RSWWater{Level: -1.5, Type: 2, WaveHeight: 0.5, WaveSpeed: 3.0, WavePitch: 40.0, AnimSpeed: 5},
+			wantOpac: 0.7, wantGrnd: testRSWGround, wantCyc: 4.0, wantName: "obj",
+		},
+		{
+			name: "v2.0 sound cycle", major: 2, minor: 0, wantGAT: "map.gat",
+			wantWtr:  RSWWater{Level: -1.5, Type: 2, WaveHeight: 0.5, WaveSpeed: 3.0, WavePitch: 40.0, AnimSpeed: 5},
+			wantOpac: 0.7, wantGrnd: testRSWGround, wantCyc: 8.0, wantName: "obj",
+		},
+		{
+			name: "v2.1 quadtree", major: 2, minor: 1, wantGAT: "map.gat",
+			wantWtr:  RSWWater{Level: -1.5, Type: 2, WaveHeight: 0.5, WaveSpeed: 3.0, WavePitch: 40.0, AnimSpeed: 5},
+			wantOpac: 0.7, wantGrnd: testRSWGround, wantCyc: 8.0, wantName: "obj", wantQuad: 2,
+		},
+		{
+			name: "2010s client v2.2", major: 2, minor: 2, build: 7, wantGAT: "map.gat",
+			wantWtr:  RSWWater{Level: -1.5, Type: 2, WaveHeight: 0.5, WaveSpeed: 3.0, WavePitch: 40.0, AnimSpeed: 5},
+			wantOpac: 0.7, wantGrnd: testRSWGround, wantCyc: 8.0, wantName: "obj", wantQuad: 2,
+		},
+		{
+			name: "v2.4", major: 2, minor: 4, build: 200, wantGAT: "map.gat",
+			wantWtr:  RSWWater{Level: -1.5, Type: 2, WaveHeight: 0.5, WaveSpeed: 3.0, WavePitch: 40.0, AnimSpeed: 5},
+			wantOpac: 0.7, wantGrnd: testRSWGround, wantCyc: 8.0, wantName: "obj", wantQuad: 2,
+		},
+		{
+			name: "2018 client v2.5", major: 2, minor: 5, build: 161, wantGAT: "map.gat",
+			wantWtr:  RSWWater{Level: -1.5, Type: 2, WaveHeight: 0.5, WaveSpeed: 3.0, WavePitch: 40.0, AnimSpeed: 5},
+			wantOpac: 0.7, wantGrnd: testRSWGround, wantCyc: 8.0, wantName: "obj", wantQuad: 2,
+		},
+		{
+			name: "2020 client v2.6", major: 2, minor: 6, build: 187, wantGAT: "map.gat",
+			wantWtr:  defaultRSWWater(),
+			wantOpac: 0.7, wantGrnd: testRSWGround, wantCyc: 8.0, wantName: "obj", wantQuad: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := RSWVersion{Major: tt.major, Minor: tt.minor, BuildNumber: tt.build}
+			rsw, err := ParseRSW(buildTestRSW(v))
+			if err != nil {
+				t.Fatalf("ParseRSW failed: %v", err)
+			}
+
+			if rsw.Version != v {
+				t.Errorf("Version = %s, want %s", rsw.Version, v)
+			}
+			if rsw.GndFile != "map.gnd" || rsw.GatFile != tt.wantGAT {
+				t.Errorf("files = %q/%q, want map.gnd/%q", rsw.GndFile, rsw.GatFile, tt.wantGAT)
+			}
+			if rsw.Water != tt.wantWtr {
+				t.Errorf("Water = %+v, want %+v", rsw.Water, tt.wantWtr)
+			}
+			if rsw.Light.Opacity != tt.wantOpac {
+				t.Errorf("Light.Opacity = %v, want %v", rsw.Light.Opacity, tt.wantOpac)
+			}
+			if rsw.Ground != tt.wantGrnd {
+				t.Errorf("Ground = %+v, want %+v", rsw.Ground, tt.wantGrnd)
+			}
+			if len(rsw.Quadtree) != tt.wantQuad {
+				t.Errorf("Quadtree has %d nodes, want %d", len(rsw.Quadtree), tt.wantQuad)
+			}
+
+			models := rsw.GetModels()
+			sounds := rsw.GetSounds()
+			if len(models) != 1 || len(sounds) != 1 {
+				t.Fatalf("got %d models, %d sounds, want 1 each", len(models), len(sounds))
+			}
+			if models[0].Name != tt.wantName || models[0].ModelName != "tree.rsm" {
+				t.Errorf("model = %q/%q, want %q/tree.rsm", models[0].Name, models[0].ModelName, tt.wantName)
+			}
+			if models[0].Scale != [3]float32{1, 1, 1} {
+				t.Errorf("model scale = %v, misaligned read", models[0].Scale)
+			}
+			if sounds[0].File != "wind.wav" || sounds[0].Cycle != tt.wantCyc {
+				t.Errorf("sound = %q cycle %v, want wind.wav cycle %v", sounds[0].File, sounds[0].Cycle, tt.wantCyc)
+			}
+		})
+	}
+}
+
+func TestParseRSW_V25_RenderFlag(t *testing.T) {
+	v := RSWVersion{Major: 2, Minor: 5, BuildNumber: 161}
+	data := buildTestRSW(v)
+	data[10] = 1 // flag byte follows the uint32 build number
+
+	rsw, err := ParseRSW(data)
+	if err != nil {
+		t.Fatalf("ParseRSW failed: %v", err)
+	}
+	if rsw.RenderFlag != 1 {
+		t.Errorf("RenderFlag = %d, want 1", rsw.RenderFlag)
+	}
+}
+
+func TestParseRSW_V26_CollisionFlag(t *testing.T) {
+	tests := []struct {
+		build uint32
+		want  uint8
+	}{
+		{161, 0}, // Byte not present before build 162
+		{162, testRSWCollisionFlag},
+		{197, testRSWCollisionFlag},
+	}
+
+	for _, tt := range tests {
+		v := RSWVersion{Major: 2, Minor: 6, BuildNumber: tt.build}
+		rsw, err := ParseRSW(buildTestRSW(v))
+		if err != nil {
+			t.Fatalf("build %d: ParseRSW failed: %v", tt.build, err)
+		}
+		if got := rsw.GetModels()[0].CollisionFlag; got != tt.want {
+			t.Errorf("build %d: CollisionFlag = %d, want %d", tt.build, got, tt.want)
+		}
+	}
+}
+
+func TestParseRSW_TruncatedHeader(t *testing.T) {
+	for _, v := range []RSWVersion{{1, 9, 0}, {2, 2, 1}, {2, 5, 100}} {
+		full := buildTestRSW(v)
+		// Cut inside the file reference block
+		_, err := ParseRSW(full[:30])
+		if !errors.Is(err, ErrTruncatedRSWData) {
+			t.Errorf("v%s: expected ErrTruncatedRSWData, got %v", v, err)
+		}
+	}
+}
+
 // Helper functions for creating test data
 
+var testRSWGround = RSWGround{Top: -100, Bottom: 100, Left: -120, Right: 120}
+
+const testRSWCollisionFlag = 3
+
+// buildTestRSW serializes a map with the fields present in the given version.
+func buildTestRSW(v RSWVersion) []byte {
+	var buf bytes.Buffer
+	w := func(val any) { _ = binary.Write(&buf, binary.LittleEndian, val) }
+	str := func(s string, n int) {
+		b := make([]byte, n)
+		copy(b, s)
+		buf.Write(b)
+	}
+
+	buf.WriteString("GRSW")
+	w([]uint8{v.Major, v.Minor})
+	if v.AtLeast(2, 5) {
+		w(v.BuildNumber)
+		w(uint8(0))
+	} else if v.AtLeast(2, 2) {
+		w(uint8(v.BuildNumber))
+	}
+
+	str("", 40)
+	str("map.gnd", 40)
+	if v.AtLeast(1, 4) {
+		str("map.gat", 40)
+		str("", 40)
+	}
+
+	if v.AtLeast(1, 3) && !v.AtLeast(2, 6) {
+		w(float32(-1.5))
+		if v.AtLeast(1, 8) {
+			w(int32(2))
+			w([]float32{0.5, 3.0, 40.0})
+		}
+		if v.AtLeast(1, 9) {
+			w(int32(5))
+		}
+	}
+
+	if v.AtLeast(1, 5) {
+		w([]int32{30, 60})
+		w([]float32{0.9, 0.9, 0.9, 0.4, 0.4, 0.4})
+		if v.AtLeast(1, 7) {
+			w(float32(0.7))
+		}
+	}
+
+	if v.AtLeast(1, 6) {
+		w([]int32{testRSWGround.Top, testRSWGround.Bottom, testRSWGround.Left, testRSWGround.Right})
+	}
+
+	w(uint32(2)) // Object count
+
+	// Model
+	w(int32(RSWObjectModel))
+	if v.AtLeast(1, 3) {
+		str("obj", 40)
+		w(int32(0))
+		w(float32(1))
+		w(int32(0))
+	}
+	if v.AtLeast(2, 6) && v.BuildNumber >= 162 {
+		w(uint8(testRSWCollisionFlag))
+	}
+	str("tree.rsm", 80)
+	str("", 80)
+	w([]float32{10, 0, 20, 0, 90, 0, 1, 1, 1})
+
+	// Sound
+	w(int32(RSWObjectSound))
+	str("wind", 80)
+	str("wind.wav", 80)
+	w([]float32{0, 0, 0, 0.8})
+	w([]int32{10, 10})
+	w(float32(100))
+	if v.AtLeast(2, 0) {
+		w(float32(8))
+	}
+
+	if v.AtLeast(2, 1) {
+		w([]float32{0, 0, 0, 0, 1, 1, 1, 1})
+	}
+
+	return buf.Bytes()
+}
+
 func makeRSWHeader(magic string, major, minor uint8) []byte {
 	// Minimum header that passes magic check
 	data := make([]byte, 500)
@@ -371,3 +627,137 @@ func makeMinimalRSW(major, minor uint8, buildNum uint32) []byte {
 
 	return data
 }
+
+func TestEncodeRSW_RoundTrip(t *testing.T) {
+	versions := []RSWVersion{
+		{Major: 1, Minor: 2}, {Major: 1, Minor: 3}, {Major: 1, Minor: 5}, {Major: 1, Minor: 8},
+		{Major: 1, Minor: 9}, {Major: 2, Minor: 0}, {Major: 2, Minor: 1}, {Major: 2, Minor: 2, BuildNumber: 7},
+		{Major: 2, Minor: 5, BuildNumber: 161}, {Major: 2, Minor: 6, BuildNumber: 161}, {Major: 2, Minor: 6, BuildNumber: 187},
+	}
+	for _, v := range versions {
+		t.Run(v.String(), func(t *testing.T) {
+			data := buildTestRSW(v)
+			rsw, err := ParseRSW(data)
+			if err != nil {
+				t.Fatalf("ParseRSW failed: %v", err)
+			}
+			encoded, err := EncodeRSW(rsw)
+			if err != nil {
+				t.Fatalf("EncodeRSW failed: %v", err)
+			}
+			if !bytes.Equal(encoded, data) {
+				t.Errorf("encoding a parsed file doesn't reproduce it (%d bytes, want %d)", len(encoded), len(data))
+			}
+		})
+	}
+}
+
+func TestEncodeRSW_EditedObjects(t *testing.T) {
+	rsw, err := ParseRSW(buildTestRSW(RSWVersion{Major: 2, Minor: 1}))
+	if err != nil {
+		t.Fatalf("ParseRSW failed: %v", err)
+	}
+	model := rsw.GetModels()[0]
+	model.Position = [3]float32{-5, 2, 7}
+	model.Rotation[1] = 45
+	dup := *model
+	dup.Name = "copy"
+	rsw.Objects = append(rsw.Objects,
+		RSWObject{Type: RSWObjectModel, Model: &dup},
+		RSWObject{Type: RSWObjectLight, Light: &RSWLightSource{Name: "lamp", Color: [3]float32{1, 0.5, 0}, Range: 30}},
+		RSWObject{Type: RSWObjectEffect, Effect: &RSWEffectSource{Name: "smoke", EffectID: 47, Param: [4]float32{1, 2, 3, 4}}},
+	)
+	rsw.Objects = append(rsw.Objects[:1], rsw.Objects[2:]...) // Delete the sound
+
+	data, err := EncodeRSW(rsw)
+	if err != nil {
+		t.Fatalf("EncodeRSW failed: %v", err)
+	}
+	decoded, err := ParseRSW(data)
+	if err != nil {
+		t.Fatalf("ParseRSW of encoded data failed: %v", err)
+	}
+
+	counts := decoded.CountByType()
+	if counts[RSWObjectModel] != 2 || counts[RSWObjectLight] != 1 || counts[RSWObjectEffect] != 1 || counts[RSWObjectSound] != 0 {
+		t.Fatalf("object counts = %v, want 2 models, 1 light, 1 effect", counts)
+	}
+	models := decoded.GetModels()
+	if *models[0] != *model || *models[1] != dup {
+		t.Errorf("models = %+v, %+v; want %+v, %+v", *models[0], *models[1], *model, dup)
+	}
+	if got := decoded.GetEffects()[0]; got.EffectID != 47 || got.Param != [4]float32{1, 2, 3, 4} {
+		t.Errorf("effect = %+v", got)
+	}
+	if len(decoded.Quadtree) != len(rsw.Quadtree) {
+		t.Errorf("quadtree has %d nodes, want %d", len(decoded.Quadtree), len(rsw.Quadtree))
+	}
+}
+
+func TestRSWBuildQuadtree(t *testing.T) {
+	rsw, err := ParseRSW(buildTestRSW(RSWVersion{Major: 2, Minor: 1}))
+	if err != nil {
+		t.Fatalf("ParseRSW failed: %v", err)
+	}
+	gnd := &GND{Width: 4, Height: 4, Zoom: 10, Tiles: make([]GNDTile, 16)}
+	for i := range gnd.Tiles {
+		gnd.Tiles[i].Altitude = [4]float32{5, 5, 5, 5}
+	}
+	gnd.Tiles[0].Altitude = [4]float32{-20, -20, -20, -20}
+	rsw.Water.Level = 2
+	model := [2][3]float32{{10, -50, 10}, {15, 0, 15}}
+
+	// yRange returns node i's min and max Y
+	yRange := func(quadtree [][4]float32, i int) (float32, float32) {
+		return quadtree[3*i+1][0], quadtree[3*i][1]
+	}
+
+	rsw.BuildQuadtree(gnd, [][2][3]float32{model})
+	if len(rsw.Quadtree) != 1365*3 {
+		t.Fatalf("quadtree has %d entries, want 1365 nodes of 3", len(rsw.Quadtree))
+	}
+	root := rsw.Quadtree[:3]
+	if root[0] != [4]float32{20, 5, 20, -20} || root[1] != [4]float32{-50, -20, 20, 27.5} {
+		t.Errorf("root = %v, want X/Z -20..20 and Y -50..5", root)
+	}
+	// The first leaf is the -X/-Z corner: tile 0 and the water, not the model
+	if lo, hi := yRange(rsw.Quadtree, rswQuadtreeDepth); lo != -20 || hi != 2 {
+		t.Errorf("corner leaf spans Y %v..%v, want -20..2", lo, hi)
+	}
+
+	// A model moved up is only covered once the quadtree is rebuilt
+	model[0][1] = -80
+	rsw.BuildQuadtree(gnd, [][2][3]float32{model})
+	data, err := EncodeRSW(rsw)
+	if err != nil {
+		t.Fatalf("EncodeRSW failed: %v", err)
+	}
+	decoded, err := ParseRSW(data)
+	if err != nil {
+		t.Fatalf("ParseRSW of encoded data failed: %v", err)
+	}
+	if lo, _ := yRange(decoded.Quadtree, 0); lo != -80 {
+		t.Errorf("root min Y after moving the model = %v, want -80", lo)
+	}
+}
+
+func TestEncodeRSW_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		rsw  RSW
+	}{
+		{"unsupported version", RSW{Version: RSWVersion{Major: 3}}},
+		{"file name too long", RSW{Version: RSWVersion{Major: 2, Minor: 1}, GndFile: string(make([]byte, 40))}},
+		{"object without data", RSW{Version: RSWVersion{Major: 2, Minor: 1}, Objects: []RSWObject{{Type: RSWObjectModel}}}},
+		{"model name too long", RSW{Version: RSWVersion{Major: 2, Minor: 1}, Objects: []RSWObject{
+			{Type: RSWObjectModel, Model: &RSWModel{ModelName: string(make([]byte, 80))}},
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := EncodeRSW(&tt.rsw); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
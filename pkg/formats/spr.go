@@ -34,6 +34,10 @@ type SPRImage struct {
 	Width  uint16
 	Height uint16
 	Pixels []byte // RGBA format, 4 bytes per pixel
+
+	// Indices holds the palette index of each pixel of an indexed image,
+	// for tools that inspect the source data. Nil for true-color images.
+	Indices []byte
 }
 
 // SPRColor represents an RGBA color.
@@ -52,6 +56,10 @@ type SPR struct {
 	Images       []SPRImage  // All images converted to RGBA
 	Palette      *SPRPalette // Original palette (nil for pure TGA sprites)
 	IndexedCount int         // Number of indexed (palette) images; RGBA images start after this
+
+	// Warnings lists the images a damaged file lost; they're blank so
+	// the others keep their indices
+	Warnings []Warning
 }
 
 // ParseSPR parses an SPR file from raw bytes.
@@ -110,12 +118,16 @@ func ParseSPR(data []byte) (*SPR, error) {
 	// Calculate where image data ends (before palette)
 	imageDataEnd := int64(len(data) - 1024 - 4) // -4 for header already consumed
 
-	// Parse indexed images
+	// Parse indexed images. Past a damaged image the rest can't be found,
+	// so they're left blank
 	useRLE := version.Major == 2 && version.Minor >= 1
 	for i := uint16(0); i < indexedCount; i++ {
 		img, err := parseIndexedImage(r, spr.Palette, useRLE)
 		if err != nil {
-			return nil, fmt.Errorf("parsing indexed image %d: %w", i, err)
+			warnf(&spr.Warnings, fmt.Sprintf("indexed image %d", i), "%v; %d images left blank",
+				err, int(indexedCount-i)+int(trueColorCount))
+			spr.Images = appendBlankImages(spr.Images, int(indexedCount-i)+int(trueColorCount))
+			return spr, nil
 		}
 		spr.Images = append(spr.Images, img)
 	}
@@ -130,7 +142,9 @@ func ParseSPR(data []byte) (*SPR, error) {
 
 		img, err := parseTrueColorImage(r)
 		if err != nil {
-			return nil, fmt.Errorf("parsing true-color image %d: %w", i, err)
+			warnf(&spr.Warnings, fmt.Sprintf("true-color image %d", i), "%v; %d images left blank", err, trueColorCount-i)
+			spr.Images = appendBlankImages(spr.Images, int(trueColorCount-i))
+			break
 		}
 		spr.Images = append(spr.Images, img)
 	}
@@ -138,6 +152,15 @@ func ParseSPR(data []byte) (*SPR, error) {
 	return spr, nil
 }
 
+// appendBlankImages appends n 1x1 transparent images, standing in for
+// images that couldn't be read.
+func appendBlankImages(images []SPRImage, n int) []SPRImage {
+	for range n {
+		images = append(images, SPRImage{Width: 1, Height: 1, Pixels: []byte{0, 0, 0, 0}})
+	}
+	return images
+}
+
 // ParseSPRFile parses an SPR file from disk.
 func ParseSPRFile(path string) (*SPR, error) {
 	data, err := os.ReadFile(path)
@@ -175,9 +198,10 @@ func parseIndexedImage(r *bytes.Reader, palette *SPRPalette, useRLE bool) (SPRIm
 	// Handle invalid/blank images
 	if width == 0 || height == 0 || width == 0xFFFF || height == 0xFFFF {
 		return SPRImage{
-			Width:  1,
-			Height: 1,
-			Pixels: []byte{0, 0, 0, 0}, // 1x1 transparent
+			Width:   1,
+			Height:  1,
+			Pixels:  []byte{0, 0, 0, 0}, // 1x1 transparent
+			Indices: []byte{0},
 		}, nil
 	}
 
@@ -227,9 +251,10 @@ func parseIndexedImage(r *bytes.Reader, palette *SPRPalette, useRLE bool) (SPRIm
 	}
 
 	return SPRImage{
-		Width:  width,
-		Height: height,
-		Pixels: pixels,
+		Width:   width,
+		Height:  height,
+		Pixels:  pixels,
+		Indices: indices,
 	}, nil
 }
 
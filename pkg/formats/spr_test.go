@@ -70,7 +70,15 @@ func TestParseSPR_Version20_Synthetic(t *testing.T) {
 	}
 
 	if len(parsed.Images) != 2 {
-		t.Errorf("expected 2 images, got %d", len(parsed.Images))
+		t.Fatalf("expected 2 images, got %d", len(parsed.Images))
+	}
+
+	// Palette indices are kept for the indexed image only
+	if got := parsed.Images[0].Indices; !bytes.Equal(got, []byte{0, 1, 2, 3}) {
+		t.Errorf("expected indices [0 1 2 3], got %v", got)
+	}
+	if parsed.Images[1].Indices != nil {
+		t.Errorf("expected no indices for true-color image, got %v", parsed.Images[1].Indices)
 	}
 }
 
@@ -96,6 +104,9 @@ func TestParseSPR_Version21_RLE(t *testing.T) {
 	if img.Width != 4 || img.Height != 4 {
 		t.Errorf("expected 4x4 image, got %dx%d", img.Width, img.Height)
 	}
+	if len(img.Indices) != 16 || img.Indices[4] != 1 || img.Indices[11] != 2 {
+		t.Errorf("unexpected decompressed indices %v", img.Indices)
+	}
 }
 
 func TestDecompressRLE(t *testing.T) {
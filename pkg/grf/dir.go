@@ -0,0 +1,195 @@
+package grf
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Dir is a loose data directory read like an archive: its files are named
+// by their paths under the root, e.g. data/texture/foo.bmp. It's meant for
+// development, where files are edited in place; Changes reports the ones
+// edited since it was last called.
+//
+// Changes are found by polling modification times, like the rest of the
+// tools watch files, so nothing platform-specific is needed. Walking a
+// large data folder every poll is slow, so frequent pollers call Poll,
+// which mostly stats just the directories.
+type Dir struct {
+	root string
+
+	mu      sync.RWMutex
+	files   map[string]dirFile   // Normalized name -> file on disk
+	dirs    map[string]time.Time // Directory on disk -> modification time
+	scanned time.Time            // Last full scan
+}
+
+// fullScanInterval is how often Poll rescans every file, for files edited
+// in place, which leave their directory's modification time alone.
+const fullScanInterval = 30 * time.Second
+
+// dirFile is a file of a Dir as of its last scan.
+type dirFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// OpenDir opens a loose data directory, listing its files.
+func OpenDir(root string) (*Dir, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("opening directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", root)
+	}
+	d := &Dir{root: root}
+	files, dirs, err := d.scan()
+	if err != nil {
+		return nil, err
+	}
+	d.files, d.dirs, d.scanned = files, dirs, time.Now()
+	return d, nil
+}
+
+// Root returns the directory the files are read from.
+func (d *Dir) Root() string {
+	return d.root
+}
+
+// Close does nothing; a Dir holds no open files.
+func (d *Dir) Close() error {
+	return nil
+}
+
+// List returns all file paths in the directory, normalized like an
+// Archive's.
+func (d *Dir) List() []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	result := make([]string, 0, len(d.files))
+	for name := range d.files {
+		result = append(result, name)
+	}
+	return result
+}
+
+// Contains checks if a file exists, as of the last scan.
+func (d *Dir) Contains(path string) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	_, ok := d.files[normalizePath(path)]
+	return ok
+}
+
+// Read reads a file from the directory. Files added since the last scan
+// are found too.
+func (d *Dir) Read(path string) ([]byte, error) {
+	name := normalizePath(path)
+	d.mu.RLock()
+	f, ok := d.files[name]
+	d.mu.RUnlock()
+	if !ok {
+		// Not scanned yet: the name's case on disk may still match
+		f.path = filepath.Join(d.root, filepath.FromSlash(name))
+	}
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("file not found: %s", path)
+	}
+	return data, nil
+}
+
+// Poll is Changes for polling every second or so. It stats only the
+// directories, rescanning the files when one of them had entries added,
+// removed or renamed, or when a full scan is due. Files edited in place
+// are found at the next full scan, every fullScanInterval; editors that
+// save through a temporary file rename it into place and show at once.
+func (d *Dir) Poll() ([]string, error) {
+	d.mu.RLock()
+	due := time.Since(d.scanned) >= fullScanInterval || d.dirsChanged()
+	d.mu.RUnlock()
+	if !due {
+		return nil, nil
+	}
+	return d.Changes()
+}
+
+// dirsChanged reports whether a directory was modified or removed since
+// the last scan.
+func (d *Dir) dirsChanged() bool {
+	for path, modTime := range d.dirs {
+		info, err := os.Stat(path)
+		if err != nil || !info.ModTime().Equal(modTime) {
+			return true
+		}
+	}
+	return false
+}
+
+// Changes rescans the directory and returns the normalized names of the
+// files added, modified or removed since the last scan, sorted.
+func (d *Dir) Changes() ([]string, error) {
+	files, dirs, err := d.scan()
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var changed []string
+	for name, f := range files {
+		old, ok := d.files[name]
+		if !ok || old.size != f.size || !old.modTime.Equal(f.modTime) {
+			changed = append(changed, name)
+		}
+	}
+	for name := range d.files {
+		if _, ok := files[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	d.files, d.dirs, d.scanned = files, dirs, time.Now()
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// scan lists the files and directories under the root.
+func (d *Dir) scan() (map[string]dirFile, map[string]time.Time, error) {
+	files := make(map[string]dirFile)
+	dirs := make(map[string]time.Time)
+	err := filepath.WalkDir(d.root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil // Removed while walking
+		}
+		if entry.IsDir() {
+			dirs[path] = info.ModTime()
+			return nil
+		}
+		rel, err := filepath.Rel(d.root, path)
+		if err != nil {
+			return err
+		}
+		files[normalizePath(filepath.ToSlash(rel))] = dirFile{path: path, size: info.Size(), modTime: info.ModTime()}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("scanning %s: %w", d.root, err)
+	}
+	return files, dirs, nil
+}
+
+// NormalizePath returns path in the form archives and directories name
+// files by: forward slashes, ASCII letters lower-cased.
+func NormalizePath(path string) string {
+	return normalizePath(path)
+}
@@ -0,0 +1,103 @@
+package grf
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDir(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string, mod time.Time) {
+		t.Helper()
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	start := time.Now().Add(-time.Hour)
+	write("data/texture/Wall.bmp", "wall", start)
+	write("data/sprite/poring.act", "act", start)
+
+	d, err := OpenDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Contains(`DATA\Texture\wall.bmp`) {
+		t.Error("Contains is not case and separator insensitive")
+	}
+	if data, err := d.Read(`data\texture\wall.bmp`); err != nil || string(data) != "wall" {
+		t.Errorf("Read = %q, %v", data, err)
+	}
+	if _, err := d.Read("data/missing.txt"); err == nil {
+		t.Error("Read(missing) succeeded")
+	}
+	if changed, err := d.Changes(); err != nil || len(changed) != 0 {
+		t.Errorf("Changes with nothing edited = %v, %v", changed, err)
+	}
+
+	write("data/texture/Wall.bmp", "new wall", start.Add(time.Minute))
+	write("data/sprite/poporing.spr", "spr", start)
+	os.Remove(filepath.Join(root, "data", "sprite", "poring.act"))
+
+	want := []string{"data/sprite/poporing.spr", "data/sprite/poring.act", "data/texture/wall.bmp"}
+	if changed, err := d.Changes(); err != nil || !reflect.DeepEqual(changed, want) {
+		t.Errorf("Changes = %v, %v; want %v", changed, err, want)
+	}
+	if data, err := d.Read("data/texture/wall.bmp"); err != nil || string(data) != "new wall" {
+		t.Errorf("Read after edit = %q, %v", data, err)
+	}
+	if d.Contains("data/sprite/poring.act") {
+		t.Error("removed file still listed")
+	}
+
+	if _, err := OpenDir(filepath.Join(root, "data", "texture", "Wall.bmp")); err == nil {
+		t.Error("OpenDir accepted a file")
+	}
+}
+
+func TestDirPoll(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "data", "a.txt")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	d, err := OpenDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Edited in place: the directory keeps its time until a full scan
+	if err := os.WriteFile(path, []byte("edited"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := d.Poll(); err != nil || len(changed) != 0 {
+		t.Errorf("Poll after an in-place edit = %v, %v; want nothing before the full scan", changed, err)
+	}
+	d.scanned = d.scanned.Add(-fullScanInterval)
+	if changed, err := d.Poll(); err != nil || !reflect.DeepEqual(changed, []string{"data/a.txt"}) {
+		t.Errorf("Poll with a full scan due = %v, %v; want [data/a.txt]", changed, err)
+	}
+
+	// Added: the directory's time changes
+	if err := os.WriteFile(filepath.Join(root, "data", "b.txt"), []byte("b"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := d.Poll(); err != nil || !reflect.DeepEqual(changed, []string{"data/b.txt"}) {
+		t.Errorf("Poll after adding a file = %v, %v; want [data/b.txt]", changed, err)
+	}
+	if changed, err := d.Poll(); err != nil || len(changed) != 0 {
+		t.Errorf("Poll with nothing edited = %v, %v", changed, err)
+	}
+}
@@ -1,4 +1,4 @@
-// Package grf provides reading functionality for Ragnarok Online GRF archives.
+// Package grf reads and writes Ragnarok Online GRF archives and GPF patches.
 package grf
 
 import (
@@ -18,6 +18,7 @@ type Archive struct {
 	file     *os.File
 	header   Header
 	fileList map[string]*Entry
+	mem      []byte // Stored file data of an in-memory archive, nil if on disk
 }
 
 // Header contains GRF file header information.
@@ -40,6 +41,29 @@ type Entry struct {
 	Offset           uint32
 }
 
+// Compressed reports whether the entry is stored zlib-compressed.
+func (e Entry) Compressed() bool {
+	return e.CompressedSize != e.UncompressedSize
+}
+
+// Encryption names how the entry is DES-encrypted: "none", "mixed" (the
+// whole entry) or "header" (its first blocks).
+func (e Entry) Encryption() string {
+	switch {
+	case e.Flags&flagMixCrypt != 0:
+		return "mixed"
+	case e.Flags&flagDES != 0:
+		return "header"
+	}
+	return "none"
+}
+
+// DataOffset returns where the entry's stored bytes start in the archive
+// file; Offset counts from the end of the header.
+func (e Entry) DataOffset() int64 {
+	return int64(e.Offset) + headerSize
+}
+
 // Open opens a GRF archive for reading.
 func Open(path string) (*Archive, error) {
 	file, err := os.Open(path)
@@ -86,7 +110,7 @@ func (a *Archive) readHeader() error {
 		return fmt.Errorf("invalid GRF magic")
 	}
 
-	if a.header.Version != 0x200 {
+	if a.header.Version != grfVersion {
 		return fmt.Errorf("unsupported GRF version: 0x%x", a.header.Version)
 	}
 
@@ -94,7 +118,7 @@ func (a *Archive) readHeader() error {
 }
 
 func (a *Archive) readFileTable() error {
-	tableOffset := int64(a.header.TableOffset) + 46
+	tableOffset := int64(a.header.TableOffset) + headerSize
 	if _, err := a.file.Seek(tableOffset, io.SeekStart); err != nil {
 		return err
 	}
@@ -112,7 +136,7 @@ func (a *Archive) readFileTable() error {
 	tableData := make([]byte, uncompressedSize)
 	io.ReadFull(reader, tableData)
 
-	fileCount := a.header.FileCount - a.header.Seed - 7
+	fileCount := a.header.FileCount - a.header.Seed - fileCountAdd
 	offset := 0
 
 	for i := uint32(0); i < fileCount; i++ {
@@ -137,7 +161,7 @@ func (a *Archive) readFileTable() error {
 		}
 		offset += 17
 
-		if entry.Flags&0x01 != 0 {
+		if entry.Flags&flagFile != 0 {
 			a.fileList[entry.Name] = entry
 		}
 	}
@@ -160,6 +184,15 @@ func (a *Archive) Contains(path string) bool {
 	return ok
 }
 
+// Stat returns a file's table entry: its sizes and storage flags.
+func (a *Archive) Stat(path string) (Entry, bool) {
+	entry, ok := a.fileList[normalizePath(path)]
+	if !ok {
+		return Entry{}, false
+	}
+	return *entry, true
+}
+
 // Read reads a file from the archive.
 func (a *Archive) Read(path string) ([]byte, error) {
 	entry, ok := a.fileList[normalizePath(path)]
@@ -167,13 +200,12 @@ func (a *Archive) Read(path string) ([]byte, error) {
 		return nil, fmt.Errorf("file not found: %s", path)
 	}
 
-	dataOffset := int64(entry.Offset) + 46
-	a.file.Seek(dataOffset, io.SeekStart)
-
-	compressedData := make([]byte, entry.AlignedSize)
-	io.ReadFull(a.file, compressedData)
+	compressedData, err := a.readRaw(entry)
+	if err != nil {
+		return nil, err
+	}
 
-	if entry.Flags&0x02 != 0 {
+	if entry.Flags&flagMixCrypt != 0 {
 		return nil, fmt.Errorf("encrypted files not yet supported")
 	}
 
@@ -192,6 +224,35 @@ func (a *Archive) Read(path string) ([]byte, error) {
 	return result, nil
 }
 
+// ReadRaw reads a file's bytes as stored: compressed, encrypted if the
+// entry is, and padded to its aligned size.
+func (a *Archive) ReadRaw(path string) ([]byte, error) {
+	entry, ok := a.fileList[normalizePath(path)]
+	if !ok {
+		return nil, fmt.Errorf("file not found: %s", path)
+	}
+	return a.readRaw(entry)
+}
+
+// readRaw reads an entry's stored (compressed, aligned) bytes.
+func (a *Archive) readRaw(entry *Entry) ([]byte, error) {
+	if a.mem != nil {
+		end := uint64(entry.Offset) + uint64(entry.AlignedSize)
+		if end > uint64(len(a.mem)) {
+			return nil, fmt.Errorf("reading %s: entry past end of archive", entry.Name)
+		}
+		return append([]byte(nil), a.mem[entry.Offset:end]...), nil
+	}
+	if _, err := a.file.Seek(int64(entry.Offset)+headerSize, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("seeking to %s: %w", entry.Name, err)
+	}
+	data := make([]byte, entry.AlignedSize)
+	if _, err := io.ReadFull(a.file, data); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", entry.Name, err)
+	}
+	return data, nil
+}
+
 func normalizePath(path string) string {
 	path = strings.ReplaceAll(path, "\\", "/")
 	return asciiToLower(path)
@@ -1,6 +1,9 @@
 package grf
 
 import (
+	"bytes"
+	"compress/zlib"
+	"io"
 	"testing"
 )
 
@@ -140,6 +143,92 @@ func TestReadNestedFile(t *testing.T) {
 	}
 }
 
+func TestStat(t *testing.T) {
+	archive, err := Open(testGRFPath())
+	if err != nil {
+		t.Fatalf("failed to open GRF: %v", err)
+	}
+	defer archive.Close()
+
+	data, err := archive.Read("data/test.txt")
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	entry, ok := archive.Stat("DATA\\Test.txt")
+	if !ok {
+		t.Fatal("Stat didn't find data/test.txt")
+	}
+	if int(entry.UncompressedSize) != len(data) {
+		t.Errorf("UncompressedSize = %d, want %d", entry.UncompressedSize, len(data))
+	}
+	if _, ok := archive.Stat("data/missing.txt"); ok {
+		t.Error("Stat found a missing file")
+	}
+}
+
+func TestReadRaw(t *testing.T) {
+	archive, err := Open(testGRFPath())
+	if err != nil {
+		t.Fatalf("failed to open GRF: %v", err)
+	}
+	defer archive.Close()
+
+	for _, path := range archive.List() {
+		entry, _ := archive.Stat(path)
+		raw, err := archive.ReadRaw(path)
+		if err != nil {
+			t.Fatalf("ReadRaw(%s): %v", path, err)
+		}
+		if len(raw) != int(entry.AlignedSize) {
+			t.Errorf("ReadRaw(%s) = %d bytes, want the aligned size %d", path, len(raw), entry.AlignedSize)
+		}
+		data, err := archive.Read(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !entry.Compressed() {
+			if !bytes.Equal(raw[:entry.UncompressedSize], data) {
+				t.Errorf("%s: stored bytes differ from the file", path)
+			}
+			continue
+		}
+		r, err := zlib.NewReader(bytes.NewReader(raw[:entry.CompressedSize]))
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		unpacked, _ := io.ReadAll(r)
+		if !bytes.Equal(unpacked, data) {
+			t.Errorf("%s: stored bytes don't unpack to the file", path)
+		}
+	}
+	if _, err := archive.ReadRaw("data/missing.txt"); err == nil {
+		t.Error("ReadRaw found a missing file")
+	}
+}
+
+func TestEntryInfo(t *testing.T) {
+	tests := []struct {
+		entry      Entry
+		compressed bool
+		encryption string
+	}{
+		{Entry{CompressedSize: 10, UncompressedSize: 10, Flags: flagFile}, false, "none"},
+		{Entry{CompressedSize: 4, UncompressedSize: 10, Flags: flagFile | flagMixCrypt}, true, "mixed"},
+		{Entry{CompressedSize: 4, UncompressedSize: 10, Flags: flagFile | flagDES}, true, "header"},
+	}
+	for _, tt := range tests {
+		if got := tt.entry.Compressed(); got != tt.compressed {
+			t.Errorf("%+v: Compressed = %v", tt.entry, got)
+		}
+		if got := tt.entry.Encryption(); got != tt.encryption {
+			t.Errorf("%+v: Encryption = %q, want %q", tt.entry, got, tt.encryption)
+		}
+	}
+	if got := (Entry{Offset: 100}).DataOffset(); got != 100+headerSize {
+		t.Errorf("DataOffset = %d", got)
+	}
+}
+
 func TestReadNonExistent(t *testing.T) {
 	archive, err := Open(testGRFPath())
 	if err != nil {
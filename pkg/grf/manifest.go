@@ -0,0 +1,265 @@
+package grf
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"unicode/utf8"
+)
+
+// Manifest lists SHA-256 hashes of archives and of every file inside them,
+// so a client installation can be checked against a known-good one.
+type Manifest struct {
+	Archives []ArchiveManifest `json:"archives"`
+}
+
+// ArchiveManifest holds the hashes of one archive.
+type ArchiveManifest struct {
+	Name   string         `json:"name"` // File name, relative to the client directory
+	Size   int64          `json:"size"`
+	SHA256 string         `json:"sha256"` // Of the whole archive file
+	Files  []ManifestFile `json:"files"`  // Sorted by name
+}
+
+// ManifestFile holds the hash of a file inside an archive. Names that
+// aren't valid UTF-8 (EUC-KR Korean names) are stored hex-encoded in
+// NameHex, since JSON would mangle them.
+type ManifestFile struct {
+	Name    string `json:"name,omitempty"`
+	NameHex string `json:"name_hex,omitempty"`
+	Size    uint32 `json:"size"`   // Uncompressed
+	SHA256  string `json:"sha256"` // Of the file's contents
+}
+
+// path returns the file's archive path.
+func (f *ManifestFile) path() (string, error) {
+	if f.NameHex == "" {
+		return f.Name, nil
+	}
+	name, err := hex.DecodeString(f.NameHex)
+	if err != nil {
+		return "", fmt.Errorf("bad name_hex %q: %w", f.NameHex, err)
+	}
+	return string(name), nil
+}
+
+func manifestFile(name string, size uint32, sum string) ManifestFile {
+	f := ManifestFile{Size: size, SHA256: sum}
+	if utf8.ValidString(name) {
+		f.Name = name
+	} else {
+		f.NameHex = hex.EncodeToString([]byte(name))
+	}
+	return f
+}
+
+// BuildManifest hashes the archives at paths. Archives are named by their
+// path relative to dir, which is where Verify looks for them, or by their
+// file name if they lie outside it.
+func BuildManifest(dir string, paths ...string) (*Manifest, error) {
+	m := &Manifest{}
+	for _, path := range paths {
+		am, err := buildArchiveManifest(path)
+		if err != nil {
+			return nil, err
+		}
+		am.Name = filepath.Base(path)
+		if rel, err := relPath(dir, path); err == nil && filepath.IsLocal(rel) {
+			am.Name = filepath.ToSlash(rel)
+		}
+		m.Archives = append(m.Archives, *am)
+	}
+	return m, nil
+}
+
+// relPath returns path relative to dir, working from absolute paths so a
+// relative dir and an absolute path can be mixed.
+func relPath(dir, path string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Rel(absDir, absPath)
+}
+
+func buildArchiveManifest(path string) (*ArchiveManifest, error) {
+	size, sum, err := hashFile(path)
+	if err != nil {
+		return nil, err
+	}
+	a, err := Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer a.Close()
+
+	files, err := a.fileHashes()
+	if err != nil {
+		return nil, fmt.Errorf("hashing %s: %w", path, err)
+	}
+	am := &ArchiveManifest{Size: size, SHA256: sum, Files: make([]ManifestFile, 0, len(files))}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		am.Files = append(am.Files, manifestFile(name, a.fileList[name].UncompressedSize, files[name]))
+	}
+	return am, nil
+}
+
+// fileHashes returns the hash of every file by name.
+func (a *Archive) fileHashes() (map[string]string, error) {
+	hashes := make(map[string]string, len(a.fileList))
+	for name, e := range a.fileList {
+		sum, err := a.entryHash(e)
+		if err != nil {
+			return nil, err
+		}
+		hashes[name] = sum
+	}
+	return hashes, nil
+}
+
+// entryHash hashes an entry's contents. Encrypted entries, which can't be
+// decoded yet, are hashed as stored.
+func (a *Archive) entryHash(e *Entry) (string, error) {
+	var data []byte
+	if e.Flags&flagEncrypted != 0 {
+		raw, err := a.readRaw(e)
+		if err != nil {
+			return "", err
+		}
+		data = raw[:e.CompressedSize]
+	} else {
+		var err error
+		if data, err = a.Read(e.Name); err != nil {
+			return "", fmt.Errorf("reading %s: %w", e.Name, err)
+		}
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// hashFile returns a file's size and SHA-256.
+func hashFile(path string) (int64, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, "", fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return 0, "", fmt.Errorf("reading %s: %w", path, err)
+	}
+	return n, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ReadManifest loads a manifest written by WriteManifest.
+func ReadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m := &Manifest{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return m, nil
+}
+
+// WriteManifest saves m as indented JSON.
+func WriteManifest(path string, m *Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// ArchiveCheck is the result of checking one archive against its manifest.
+type ArchiveCheck struct {
+	Name    string
+	Missing bool     // The archive itself is missing
+	Intact  bool     // The whole archive matches byte for byte
+	Changed []string // Files whose contents differ
+	Absent  []string // Files in the manifest but not the archive
+	Extra   []string // Files in the archive but not the manifest
+}
+
+// OK reports whether the archive's files all match the manifest. An archive
+// can be OK without being Intact, e.g. after being repacked.
+func (c *ArchiveCheck) OK() bool {
+	return !c.Missing && len(c.Changed) == 0 && len(c.Absent) == 0 && len(c.Extra) == 0
+}
+
+// Verify checks the archives under dir against the manifest. Archives that
+// match byte for byte skip the per-file check.
+func (m *Manifest) Verify(dir string) ([]ArchiveCheck, error) {
+	checks := make([]ArchiveCheck, 0, len(m.Archives))
+	for i := range m.Archives {
+		c, err := m.Archives[i].verify(filepath.Join(dir, filepath.FromSlash(m.Archives[i].Name)))
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, c)
+	}
+	return checks, nil
+}
+
+func (am *ArchiveManifest) verify(path string) (ArchiveCheck, error) {
+	c := ArchiveCheck{Name: am.Name}
+	size, sum, err := hashFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		c.Missing = true
+		return c, nil
+	}
+	if err != nil {
+		return c, err
+	}
+	if size == am.Size && sum == am.SHA256 {
+		c.Intact = true
+		return c, nil
+	}
+
+	a, err := Open(path)
+	if err != nil {
+		return c, fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer a.Close()
+	hashes, err := a.fileHashes()
+	if err != nil {
+		return c, fmt.Errorf("hashing %s: %w", path, err)
+	}
+	for i := range am.Files {
+		name, err := am.Files[i].path()
+		if err != nil {
+			return c, err
+		}
+		got, ok := hashes[name]
+		switch {
+		case !ok:
+			c.Absent = append(c.Absent, name)
+		case got != am.Files[i].SHA256:
+			c.Changed = append(c.Changed, name)
+		}
+		delete(hashes, name)
+	}
+	for name := range hashes {
+		c.Extra = append(c.Extra, name)
+	}
+	sort.Strings(c.Extra)
+	return c, nil
+}
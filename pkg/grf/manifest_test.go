@@ -0,0 +1,116 @@
+package grf
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestManifestRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	writeTestArchive(t, dir, "data.grf", map[string]string{
+		"data/a.txt":        "alpha",
+		"data/b.txt":        "beta",
+		"data/\xc7\xd1.bmp": "korean name",
+	})
+
+	m, err := BuildManifest(dir, filepath.Join(dir, "data.grf"))
+	if err != nil {
+		t.Fatalf("BuildManifest: %v", err)
+	}
+	if len(m.Archives) != 1 || m.Archives[0].Name != "data.grf" || len(m.Archives[0].Files) != 3 {
+		t.Fatalf("manifest = %+v", m)
+	}
+	if f := m.Archives[0].Files[2]; f.Name != "" || f.NameHex != "646174612fc7d12e626d70" {
+		t.Errorf("non-UTF-8 name stored as %+v, want hex", f)
+	}
+
+	path := filepath.Join(dir, "manifest.json")
+	if err := WriteManifest(path, m); err != nil {
+		t.Fatalf("WriteManifest: %v", err)
+	}
+	loaded, err := ReadManifest(path)
+	if err != nil {
+		t.Fatalf("ReadManifest: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, m) {
+		t.Errorf("loaded manifest differs:\n%+v\n%+v", loaded, m)
+	}
+
+	checks, err := loaded.Verify(dir)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if len(checks) != 1 || !checks[0].Intact || !checks[0].OK() {
+		t.Errorf("unchanged install: %+v", checks)
+	}
+}
+
+func TestManifestVerify(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"data/a.txt": "alpha",
+		"data/b.txt": "beta",
+		"data/c.txt": "gamma",
+	}
+	writeTestArchive(t, dir, "data.grf", files)
+	m, err := BuildManifest(dir, filepath.Join(dir, "data.grf"))
+	if err != nil {
+		t.Fatalf("BuildManifest: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		files map[string]string // nil removes the archive
+		want  ArchiveCheck
+		ok    bool
+	}{
+		{
+			"extra file",
+			map[string]string{"data/c.txt": "gamma", "data/b.txt": "beta", "data/a.txt": "alpha", "data/zz.txt": ""},
+			ArchiveCheck{Name: "data.grf", Extra: []string{"data/zz.txt"}},
+			false,
+		},
+		{
+			"same files, new order",
+			map[string]string{"data/c.txt": "gamma", "data/a.txt": "alpha", "data/b.txt": "beta"},
+			ArchiveCheck{Name: "data.grf"},
+			true,
+		},
+		{
+			"changed and absent",
+			map[string]string{"data/a.txt": "ALPHA", "data/c.txt": "gamma"},
+			ArchiveCheck{Name: "data.grf", Changed: []string{"data/a.txt"}, Absent: []string{"data/b.txt"}},
+			false,
+		},
+		{
+			"missing",
+			nil,
+			ArchiveCheck{Name: "data.grf", Missing: true},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			installDir := t.TempDir()
+			if tt.files != nil {
+				writeTestArchive(t, installDir, "data.grf", tt.files)
+			}
+			checks, err := m.Verify(installDir)
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if len(checks) != 1 {
+				t.Fatalf("got %d checks, want 1", len(checks))
+			}
+			got := checks[0]
+			got.Intact = false // Depends on the writer's map order
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("check = %+v, want %+v", got, tt.want)
+			}
+			if got.OK() != tt.ok {
+				t.Errorf("OK = %v, want %v", got.OK(), tt.ok)
+			}
+		})
+	}
+}
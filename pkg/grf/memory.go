@@ -0,0 +1,49 @@
+package grf
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrReadOnly is returned when adding files to an archive opened from disk.
+var ErrReadOnly = errors.New("grf: archive is read-only")
+
+// NewInMemory creates an empty archive held in memory, for test fixtures
+// and generated content. Files added with AddFile can be read back at
+// once; to save the archive, write it out through an Overlay:
+//
+//	grf.NewOverlay(a).WriteTo(path)
+func NewInMemory() *Archive {
+	return &Archive{
+		header:   Header{Version: grfVersion},
+		fileList: make(map[string]*Entry),
+		mem:      []byte{},
+	}
+}
+
+// AddFile compresses data and adds it to an in-memory archive under name.
+// Adding a name twice replaces the earlier file.
+func (a *Archive) AddFile(name string, data []byte) error {
+	if a.mem == nil {
+		return ErrReadOnly
+	}
+	compressed, err := compress(data)
+	if err != nil {
+		return fmt.Errorf("compressing %s: %w", name, err)
+	}
+
+	aligned := alignSize(uint32(len(compressed)))
+	entry := &Entry{
+		Name:             normalizePath(name),
+		CompressedSize:   uint32(len(compressed)),
+		AlignedSize:      aligned,
+		UncompressedSize: uint32(len(data)),
+		Flags:            flagFile,
+		Offset:           uint32(len(a.mem)),
+	}
+	a.mem = append(a.mem, compressed...)
+	a.mem = append(a.mem, make([]byte, aligned-entry.CompressedSize)...)
+	a.fileList[entry.Name] = entry
+	a.header.FileCount = uint32(len(a.fileList))
+	return nil
+}
@@ -0,0 +1,64 @@
+package grf
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestInMemory(t *testing.T) {
+	a := NewInMemory()
+	files := map[string]string{
+		"data\\Texture\\Grass.bmp": "grass",
+		"data/empty.txt":           "",
+	}
+	for name, content := range files {
+		if err := a.AddFile(name, []byte(content)); err != nil {
+			t.Fatalf("AddFile(%s): %v", name, err)
+		}
+	}
+	// Adding again replaces the file
+	if err := a.AddFile("data/texture/grass.bmp", []byte("new grass")); err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+
+	got := a.List()
+	sort.Strings(got)
+	if want := []string{"data/empty.txt", "data/texture/grass.bmp"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("List() = %v, want %v", got, want)
+	}
+	if data, err := a.Read("DATA/TEXTURE/GRASS.BMP"); err != nil || string(data) != "new grass" {
+		t.Errorf("Read = %q, %v; want new grass", data, err)
+	}
+	if data, err := a.Read("data/empty.txt"); err != nil || len(data) != 0 {
+		t.Errorf("Read(empty) = %q, %v", data, err)
+	}
+}
+
+func TestInMemoryWriteTo(t *testing.T) {
+	a := NewInMemory()
+	if err := a.AddFile("data/test.txt", []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "mem.grf")
+	if _, err := NewOverlay(a).WriteTo(path); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+	if got, want := readAll(t, path), map[string]string{"data/test.txt": "hello"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("written archive = %v, want %v", got, want)
+	}
+}
+
+func TestAddFileReadOnly(t *testing.T) {
+	path := writeTestArchive(t, t.TempDir(), "disk.grf", map[string]string{"a.txt": "a"})
+	a, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	if err := a.AddFile("b.txt", nil); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("AddFile on disk archive = %v, want ErrReadOnly", err)
+	}
+}
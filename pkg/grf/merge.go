@@ -0,0 +1,167 @@
+package grf
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// ErrMergeCanceled is returned by Merge.WriteTo when its progress callback
+// cancels the merge.
+var ErrMergeCanceled = errors.New("grf: merge canceled")
+
+// Conflict is a file that more than one archive of a merge holds.
+type Conflict struct {
+	Name     string
+	Archives []int // Archives holding it, lowest priority first
+}
+
+// Merge combines several archives into one. Where archives hold the same
+// file, the copy of the highest-priority archive is kept unless another
+// one is picked for that file.
+type Merge struct {
+	paths    []string
+	archives []*Archive
+	picks    map[string]int // Normalized name -> archive picked
+}
+
+// OpenMerge opens archives to merge, listed from lowest to highest
+// priority like an Overlay's.
+func OpenMerge(paths ...string) (*Merge, error) {
+	m := &Merge{picks: make(map[string]int)}
+	for _, path := range paths {
+		a, err := Open(path)
+		if err != nil {
+			m.Close()
+			return nil, fmt.Errorf("opening %s: %w", path, err)
+		}
+		m.paths = append(m.paths, path)
+		m.archives = append(m.archives, a)
+	}
+	return m, nil
+}
+
+// Close closes the archives.
+func (m *Merge) Close() error {
+	var first error
+	for _, a := range m.archives {
+		if err := a.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Paths returns the paths of the archives, lowest priority first.
+func (m *Merge) Paths() []string {
+	return m.paths
+}
+
+// Conflicts returns the files more than one archive holds, sorted by name.
+func (m *Merge) Conflicts() []Conflict {
+	holders := make(map[string][]int)
+	for i, a := range m.archives {
+		for name := range a.fileList {
+			holders[name] = append(holders[name], i)
+		}
+	}
+	var conflicts []Conflict
+	for name, archives := range holders {
+		if len(archives) > 1 {
+			conflicts = append(conflicts, Conflict{Name: name, Archives: archives})
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Name < conflicts[j].Name })
+	return conflicts
+}
+
+// Pick keeps the copy of path in the given archive, whatever its
+// priority. A negative archive, or one without the file, goes back to
+// priority order.
+func (m *Merge) Pick(path string, archive int) {
+	name := normalizePath(path)
+	if archive < 0 || archive >= len(m.archives) {
+		delete(m.picks, name)
+		return
+	}
+	if _, ok := m.archives[archive].fileList[name]; !ok {
+		delete(m.picks, name)
+		return
+	}
+	m.picks[name] = archive
+}
+
+// Source returns the archive whose copy of path is kept, or -1 if none
+// holds it.
+func (m *Merge) Source(path string) int {
+	name := normalizePath(path)
+	if i, ok := m.picks[name]; ok {
+		return i
+	}
+	for i := len(m.archives) - 1; i >= 0; i-- {
+		if _, ok := m.archives[i].fileList[name]; ok {
+			return i
+		}
+	}
+	return -1
+}
+
+// list returns every file across the archives, sorted.
+func (m *Merge) list() []string {
+	seen := make(map[string]struct{})
+	for _, a := range m.archives {
+		for name := range a.fileList {
+			seen[name] = struct{}{}
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WriteTo writes the merged archive to path, which must differ from every
+// input, and returns the number of files written. Entry data is copied
+// without recompression. progress, if set, is called after each file with
+// the files done out of the total; returning false cancels the merge,
+// removing the partial archive, and WriteTo returns ErrMergeCanceled.
+func (m *Merge) WriteTo(path string, progress func(done, total int) bool) (int, error) {
+	if len(m.archives) == 0 {
+		return 0, fmt.Errorf("no archives to merge")
+	}
+	for _, in := range m.paths {
+		if samePath(in, path) {
+			return 0, fmt.Errorf("output %s would overwrite an input archive", path)
+		}
+	}
+
+	w, err := Create(path)
+	if err != nil {
+		return 0, err
+	}
+	abort := func(err error) (int, error) {
+		w.Close()
+		os.Remove(path)
+		return 0, err
+	}
+
+	names := m.list()
+	for i, name := range names {
+		a := m.archives[m.Source(name)]
+		if err := copyEntry(w, a, a.fileList[name]); err != nil {
+			return abort(err)
+		}
+		if progress != nil && !progress(i+1, len(names)) {
+			return abort(ErrMergeCanceled)
+		}
+	}
+
+	total := w.Count()
+	if err := w.Close(); err != nil {
+		return 0, fmt.Errorf("finishing %s: %w", path, err)
+	}
+	return total, nil
+}
@@ -0,0 +1,95 @@
+package grf
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	dir := t.TempDir()
+	a := writeTestArchive(t, dir, "a.grf", map[string]string{
+		"data/only_a.txt": "a",
+		"data/both.txt":   "from a",
+		"data/all.txt":    "from a",
+	})
+	b := writeTestArchive(t, dir, "b.gpf", map[string]string{
+		"data/both.txt": "from b",
+		"data/all.txt":  "from b",
+	})
+	c := writeTestArchive(t, dir, "c.gpf", map[string]string{
+		"data/all.txt":    "from c",
+		"data/only_c.txt": "c",
+	})
+
+	m, err := OpenMerge(a, b, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	wantConflicts := []Conflict{
+		{Name: "data/all.txt", Archives: []int{0, 1, 2}},
+		{Name: "data/both.txt", Archives: []int{0, 1}},
+	}
+	if got := m.Conflicts(); !reflect.DeepEqual(got, wantConflicts) {
+		t.Errorf("Conflicts() = %+v, want %+v", got, wantConflicts)
+	}
+
+	m.Pick("data\\All.txt", 0)
+	m.Pick("data/both.txt", 2) // c doesn't hold it: priority order
+	if m.Source("data/all.txt") != 0 || m.Source("data/both.txt") != 1 || m.Source("data/none.txt") != -1 {
+		t.Errorf("Source() = %d, %d, %d", m.Source("data/all.txt"), m.Source("data/both.txt"), m.Source("data/none.txt"))
+	}
+
+	out := filepath.Join(dir, "merged.grf")
+	var calls int
+	n, err := m.WriteTo(out, func(done, total int) bool {
+		calls++
+		return done <= total
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"data/only_a.txt": "a",
+		"data/both.txt":   "from b",
+		"data/all.txt":    "from a",
+		"data/only_c.txt": "c",
+	}
+	if got := readAll(t, out); n != 4 || calls != 4 || !reflect.DeepEqual(got, want) {
+		t.Errorf("merged %d files in %d calls: %v, want %v", n, calls, got, want)
+	}
+
+	canceled := filepath.Join(dir, "canceled.grf")
+	if _, err := m.WriteTo(canceled, func(done, total int) bool { return done < 2 }); !errors.Is(err, ErrMergeCanceled) {
+		t.Errorf("canceled WriteTo() error = %v, want ErrMergeCanceled", err)
+	}
+	if _, err := os.Stat(canceled); !os.IsNotExist(err) {
+		t.Errorf("canceled merge left %s behind", canceled)
+	}
+
+	if _, err := m.WriteTo(b, nil); err == nil {
+		t.Error("expected error when output overwrites an input archive")
+	}
+}
+
+func TestMergeKeepsEncryptedEntries(t *testing.T) {
+	dir := t.TempDir()
+	a, raw := writeEncryptedArchive(t, dir, "a.grf")
+	b := writeTestArchive(t, dir, "b.gpf", map[string]string{"data/b.txt": "b"})
+
+	m, err := OpenMerge(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	out := filepath.Join(dir, "merged.grf")
+	if _, err := m.WriteTo(out, nil); err != nil {
+		t.Fatal(err)
+	}
+	checkEncryptedEntry(t, out, raw)
+}
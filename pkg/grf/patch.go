@@ -0,0 +1,260 @@
+package grf
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+)
+
+// Overlay is a read-only view over a base archive and patches applied on top
+// of it. Later archives take precedence, the same way the client resolves
+// files from data.grf plus GPF patches without merging them on disk.
+type Overlay struct {
+	layers []*Archive
+}
+
+// NewOverlay creates an overlay; archives are listed from lowest to highest
+// priority (base first, newest patch last).
+func NewOverlay(archives ...*Archive) *Overlay {
+	return &Overlay{layers: archives}
+}
+
+// OpenOverlay opens a base archive and its patches as an Overlay.
+func OpenOverlay(basePath string, patchPaths ...string) (*Overlay, error) {
+	o := &Overlay{}
+	for _, path := range append([]string{basePath}, patchPaths...) {
+		a, err := Open(path)
+		if err != nil {
+			o.Close()
+			return nil, fmt.Errorf("opening %s: %w", path, err)
+		}
+		o.layers = append(o.layers, a)
+	}
+	return o, nil
+}
+
+// Close closes all archives in the overlay.
+func (o *Overlay) Close() error {
+	var first error
+	for _, a := range o.layers {
+		if err := a.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// resolve returns the archive and entry that provide path.
+func (o *Overlay) resolve(path string) (*Archive, *Entry) {
+	name := normalizePath(path)
+	for i := len(o.layers) - 1; i >= 0; i-- {
+		if e, ok := o.layers[i].fileList[name]; ok {
+			return o.layers[i], e
+		}
+	}
+	return nil, nil
+}
+
+// Contains checks if any layer provides a file.
+func (o *Overlay) Contains(path string) bool {
+	a, _ := o.resolve(path)
+	return a != nil
+}
+
+// Read reads a file from the highest-priority layer that has it.
+func (o *Overlay) Read(path string) ([]byte, error) {
+	a, _ := o.resolve(path)
+	if a == nil {
+		return nil, fmt.Errorf("file not found: %s", path)
+	}
+	return a.Read(path)
+}
+
+// List returns all file paths across layers, sorted.
+func (o *Overlay) List() []string {
+	seen := make(map[string]struct{})
+	for _, a := range o.layers {
+		for name := range a.fileList {
+			seen[name] = struct{}{}
+		}
+	}
+	result := make([]string, 0, len(seen))
+	for name := range seen {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// PatchResult summarizes a patch application or creation.
+type PatchResult struct {
+	Added    []string // Files not present in the base/old archive
+	Replaced []string // Files that override or differ from the base/old archive
+	Removed  []string // Files only in the old archive (patch creation only)
+	Total    int      // Files written to the output archive
+}
+
+// WriteTo merges the overlay into a single archive at path. Entry data is
+// copied without recompression.
+func (o *Overlay) WriteTo(path string) (PatchResult, error) {
+	var result PatchResult
+	if len(o.layers) == 0 {
+		return result, fmt.Errorf("empty overlay")
+	}
+
+	w, err := Create(path)
+	if err != nil {
+		return result, err
+	}
+
+	base := o.layers[0]
+	for _, name := range o.List() {
+		a, e := o.resolve(name)
+		if err := copyEntry(w, a, e); err != nil {
+			w.Close()
+			return result, err
+		}
+		if a != base {
+			if _, inBase := base.fileList[name]; inBase {
+				result.Replaced = append(result.Replaced, name)
+			} else {
+				result.Added = append(result.Added, name)
+			}
+		}
+	}
+	result.Total = w.Count()
+
+	if err := w.Close(); err != nil {
+		return result, fmt.Errorf("finishing %s: %w", path, err)
+	}
+	return result, nil
+}
+
+// ApplyPatches merges patches (in order) over the base archive and writes
+// the result to outPath, which must differ from every input.
+func ApplyPatches(basePath string, patchPaths []string, outPath string) (PatchResult, error) {
+	for _, in := range append([]string{basePath}, patchPaths...) {
+		if samePath(in, outPath) {
+			return PatchResult{}, fmt.Errorf("output %s would overwrite an input archive", outPath)
+		}
+	}
+
+	o, err := OpenOverlay(basePath, patchPaths...)
+	if err != nil {
+		return PatchResult{}, err
+	}
+	defer o.Close()
+
+	return o.WriteTo(outPath)
+}
+
+// CreatePatch writes a GPF patch to outPath containing every file of newPath
+// that is missing from or differs from oldPath. GPF has no deletion records,
+// so files only present in the old archive are reported in Removed but not
+// encoded in the patch.
+func CreatePatch(oldPath, newPath, outPath string) (PatchResult, error) {
+	var result PatchResult
+
+	oldArchive, err := Open(oldPath)
+	if err != nil {
+		return result, fmt.Errorf("opening %s: %w", oldPath, err)
+	}
+	defer oldArchive.Close()
+
+	newArchive, err := Open(newPath)
+	if err != nil {
+		return result, fmt.Errorf("opening %s: %w", newPath, err)
+	}
+	defer newArchive.Close()
+
+	w, err := Create(outPath)
+	if err != nil {
+		return result, err
+	}
+
+	names := newArchive.List()
+	sort.Strings(names)
+	for _, name := range names {
+		newEntry := newArchive.fileList[name]
+		oldEntry, inOld := oldArchive.fileList[name]
+		if inOld {
+			same, err := sameContent(oldArchive, oldEntry, newArchive, newEntry)
+			if err != nil {
+				w.Close()
+				return result, err
+			}
+			if same {
+				continue
+			}
+			result.Replaced = append(result.Replaced, name)
+		} else {
+			result.Added = append(result.Added, name)
+		}
+		if err := copyEntry(w, newArchive, newEntry); err != nil {
+			w.Close()
+			return result, err
+		}
+	}
+
+	for name := range oldArchive.fileList {
+		if _, ok := newArchive.fileList[name]; !ok {
+			result.Removed = append(result.Removed, name)
+		}
+	}
+	sort.Strings(result.Removed)
+	result.Total = w.Count()
+
+	if err := w.Close(); err != nil {
+		return result, fmt.Errorf("finishing %s: %w", outPath, err)
+	}
+	return result, nil
+}
+
+// copyEntry copies an entry's stored bytes from a into w, unchanged up to
+// its aligned size.
+func copyEntry(w *Writer, a *Archive, e *Entry) error {
+	raw, err := a.readRaw(e)
+	if err != nil {
+		return err
+	}
+	return w.addRaw(e.Name, raw, *e)
+}
+
+// sameContent reports whether two entries hold the same file. Identical
+// stored bytes are a fast path; otherwise the decompressed data is compared
+// since the same file may have been compressed differently.
+func sameContent(a *Archive, ea *Entry, b *Archive, eb *Entry) (bool, error) {
+	if ea.UncompressedSize != eb.UncompressedSize {
+		return false, nil
+	}
+	rawA, err := a.readRaw(ea)
+	if err != nil {
+		return false, err
+	}
+	rawB, err := b.readRaw(eb)
+	if err != nil {
+		return false, err
+	}
+	if ea.Flags == eb.Flags && bytes.Equal(rawA[:ea.CompressedSize], rawB[:eb.CompressedSize]) {
+		return true, nil
+	}
+
+	dataA, errA := a.Read(ea.Name)
+	dataB, errB := b.Read(eb.Name)
+	if errA != nil || errB != nil {
+		// Encrypted entries can't be compared; treat them as changed
+		return false, nil
+	}
+	return bytes.Equal(dataA, dataB), nil
+}
+
+// samePath reports whether two paths refer to the same file location.
+func samePath(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return absA == absB
+}
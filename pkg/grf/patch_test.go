@@ -0,0 +1,234 @@
+package grf
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeTestArchive creates an archive in dir with the given files.
+func writeTestArchive(t *testing.T, dir, name string, files map[string]string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	w, err := Create(path)
+	if err != nil {
+		t.Fatalf("failed to create %s: %v", name, err)
+	}
+	for file, content := range files {
+		if err := w.Add(file, []byte(content)); err != nil {
+			t.Fatalf("failed to add %s: %v", file, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to close %s: %v", name, err)
+	}
+	return path
+}
+
+func readAll(t *testing.T, path string) map[string]string {
+	t.Helper()
+	archive, err := Open(path)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", path, err)
+	}
+	defer archive.Close()
+
+	result := make(map[string]string)
+	for _, name := range archive.List() {
+		data, err := archive.Read(name)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+		result[name] = string(data)
+	}
+	return result
+}
+
+func TestWriterRoundTrip(t *testing.T) {
+	files := map[string]string{
+		"data/test.txt":            "hello",
+		"data\\Sprite\\Poring.spr": "sprite bytes",
+		"data/empty.txt":           "",
+	}
+	path := writeTestArchive(t, t.TempDir(), "out.grf", files)
+
+	want := map[string]string{
+		"data/test.txt":          "hello",
+		"data/sprite/poring.spr": "sprite bytes",
+		"data/empty.txt":         "",
+	}
+	if got := readAll(t, path); !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip = %v, want %v", got, want)
+	}
+}
+
+func TestOverlayPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	base := writeTestArchive(t, dir, "base.grf", map[string]string{
+		"data/a.txt": "base a",
+		"data/b.txt": "base b",
+	})
+	patch := writeTestArchive(t, dir, "patch.gpf", map[string]string{
+		"data/b.txt": "patched b",
+		"data/c.txt": "new c",
+	})
+
+	o, err := OpenOverlay(base, patch)
+	if err != nil {
+		t.Fatalf("failed to open overlay: %v", err)
+	}
+	defer o.Close()
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"data/a.txt", "base a"},
+		{"DATA\\B.TXT", "patched b"},
+		{"data/c.txt", "new c"},
+	}
+	for _, tt := range tests {
+		data, err := o.Read(tt.path)
+		if err != nil {
+			t.Errorf("Read(%q) error: %v", tt.path, err)
+			continue
+		}
+		if string(data) != tt.want {
+			t.Errorf("Read(%q) = %q, want %q", tt.path, data, tt.want)
+		}
+	}
+
+	if got := o.List(); len(got) != 3 {
+		t.Errorf("List() = %v, want 3 files", got)
+	}
+	if o.Contains("data/missing.txt") {
+		t.Error("Contains(missing) = true, want false")
+	}
+}
+
+func TestCreateAndApplyPatch(t *testing.T) {
+	dir := t.TempDir()
+	oldFiles := map[string]string{
+		"data/same.txt":    "unchanged",
+		"data/changed.txt": "old",
+		"data/removed.txt": "gone",
+	}
+	newFiles := map[string]string{
+		"data/same.txt":    "unchanged",
+		"data/changed.txt": "new",
+		"data/added.txt":   "added",
+	}
+	oldPath := writeTestArchive(t, dir, "old.grf", oldFiles)
+	newPath := writeTestArchive(t, dir, "new.grf", newFiles)
+	patchPath := filepath.Join(dir, "update.gpf")
+
+	created, err := CreatePatch(oldPath, newPath, patchPath)
+	if err != nil {
+		t.Fatalf("CreatePatch error: %v", err)
+	}
+	if !reflect.DeepEqual(created.Added, []string{"data/added.txt"}) {
+		t.Errorf("Added = %v", created.Added)
+	}
+	if !reflect.DeepEqual(created.Replaced, []string{"data/changed.txt"}) {
+		t.Errorf("Replaced = %v", created.Replaced)
+	}
+	if !reflect.DeepEqual(created.Removed, []string{"data/removed.txt"}) {
+		t.Errorf("Removed = %v", created.Removed)
+	}
+	if created.Total != 2 {
+		t.Errorf("patch holds %d files, want 2", created.Total)
+	}
+
+	mergedPath := filepath.Join(dir, "merged.grf")
+	applied, err := ApplyPatches(oldPath, []string{patchPath}, mergedPath)
+	if err != nil {
+		t.Fatalf("ApplyPatches error: %v", err)
+	}
+	if applied.Total != 4 {
+		t.Errorf("merged archive holds %d files, want 4", applied.Total)
+	}
+
+	// GPF can't delete, so the removed file survives the merge
+	want := map[string]string{
+		"data/same.txt":    "unchanged",
+		"data/changed.txt": "new",
+		"data/added.txt":   "added",
+		"data/removed.txt": "gone",
+	}
+	if got := readAll(t, mergedPath); !reflect.DeepEqual(got, want) {
+		t.Errorf("merged = %v, want %v", got, want)
+	}
+}
+
+func TestApplyPatchesRejectsInputAsOutput(t *testing.T) {
+	dir := t.TempDir()
+	base := writeTestArchive(t, dir, "base.grf", map[string]string{"data/a.txt": "a"})
+
+	if _, err := ApplyPatches(base, nil, base); err == nil {
+		t.Error("expected error when output overwrites the base archive")
+	}
+}
+
+// writeEncryptedArchive creates an archive in dir holding one DES-encrypted
+// entry, whose last block runs past its compressed size, and returns the
+// archive's path and the entry's stored bytes.
+func writeEncryptedArchive(t *testing.T, dir, name string) (string, []byte) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	w, err := Create(path)
+	if err != nil {
+		t.Fatalf("failed to create %s: %v", name, err)
+	}
+	raw := []byte("0123456789abcdef")
+	err = w.addRaw("data/secret.txt", raw, Entry{
+		CompressedSize:   13,
+		AlignedSize:      uint32(len(raw)),
+		UncompressedSize: 20,
+		Flags:            flagFile | flagMixCrypt,
+	})
+	if err != nil {
+		t.Fatalf("failed to add encrypted entry: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to close %s: %v", name, err)
+	}
+	return path, raw
+}
+
+// checkEncryptedEntry checks that the archive at path holds the entry
+// written by writeEncryptedArchive, stored bytes and all.
+func checkEncryptedEntry(t *testing.T, path string, want []byte) {
+	t.Helper()
+	archive, err := Open(path)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", path, err)
+	}
+	defer archive.Close()
+
+	e, ok := archive.Stat("data/secret.txt")
+	if !ok {
+		t.Fatal("encrypted entry missing")
+	}
+	if e.Encryption() != "mixed" || e.CompressedSize != 13 || e.AlignedSize != uint32(len(want)) {
+		t.Errorf("entry = %+v, want mixed encryption, 13 compressed and %d aligned bytes", e, len(want))
+	}
+	raw, err := archive.ReadRaw("data/secret.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(raw) != string(want) {
+		t.Errorf("stored bytes = %q, want %q", raw, want)
+	}
+}
+
+func TestApplyPatchesKeepsEncryptedEntries(t *testing.T) {
+	dir := t.TempDir()
+	base, raw := writeEncryptedArchive(t, dir, "base.grf")
+	patch := writeTestArchive(t, dir, "update.gpf", map[string]string{"data/a.txt": "a"})
+
+	out := filepath.Join(dir, "merged.grf")
+	if _, err := ApplyPatches(base, []string{patch}, out); err != nil {
+		t.Fatalf("ApplyPatches error: %v", err)
+	}
+	checkEncryptedEntry(t, out, raw)
+}
@@ -0,0 +1,199 @@
+package grf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	headerSize    = 46
+	grfVersion    = 0x200
+	flagFile      = 0x01
+	flagMixCrypt  = 0x02 // DES over the whole entry, mixed with shuffling
+	flagDES       = 0x04 // DES over the entry's first blocks only
+	flagEncrypted = flagMixCrypt | flagDES
+	dataAlign     = 8
+	fileCountAdd  = 7 // FileCount in the header is count + seed + 7
+)
+
+// ErrWriterClosed is returned when adding files to a closed Writer.
+var ErrWriterClosed = errors.New("grf: writer closed")
+
+// Writer creates version 0x200 GRF archives. GPF patch files use the same
+// format, so Writer is used for both.
+type Writer struct {
+	file    *os.File
+	entries []*Entry
+	index   map[string]int // Normalized name -> entries index
+	offset  uint32         // Next data offset, relative to the header end
+	closed  bool
+}
+
+// Create creates a new archive at path. Files are written as they are added;
+// the file table and header are written by Close.
+func Create(path string) (*Writer, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("creating file: %w", err)
+	}
+
+	// Header placeholder, filled in by Close
+	if _, err := file.Write(make([]byte, headerSize)); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("writing header: %w", err)
+	}
+
+	return &Writer{
+		file:  file,
+		index: make(map[string]int),
+	}, nil
+}
+
+// Add compresses data and adds it under name. Adding a name twice replaces
+// the earlier entry.
+func (w *Writer) Add(name string, data []byte) error {
+	compressed, err := compress(data)
+	if err != nil {
+		return fmt.Errorf("compressing %s: %w", name, err)
+	}
+
+	return w.addRaw(name, compressed, Entry{
+		CompressedSize:   uint32(len(compressed)),
+		UncompressedSize: uint32(len(data)),
+		Flags:            flagFile,
+	})
+}
+
+// compress zlib-compresses a file's data the way archives store it.
+func compress(data []byte) ([]byte, error) {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return compressed.Bytes(), nil
+}
+
+// alignSize rounds a stored size up to the archive's data alignment.
+func alignSize(n uint32) uint32 {
+	if n%dataAlign != 0 {
+		n += dataAlign - n%dataAlign
+	}
+	return n
+}
+
+// addRaw writes already-compressed entry data as-is, so files copied between
+// archives keep their compression and encryption flags. An entry copied
+// from an archive keeps its AlignedSize: encrypted entries are DES blocks
+// spanning all of it, so raw must hold the whole aligned data.
+func (w *Writer) addRaw(name string, raw []byte, e Entry) error {
+	if w.closed {
+		return ErrWriterClosed
+	}
+
+	aligned := e.AlignedSize
+	if aligned < uint32(len(raw)) {
+		aligned = alignSize(uint32(len(raw)))
+	}
+
+	if _, err := w.file.Write(raw); err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	if pad := int(aligned) - len(raw); pad > 0 {
+		if _, err := w.file.Write(make([]byte, pad)); err != nil {
+			return fmt.Errorf("writing %s: %w", name, err)
+		}
+	}
+
+	entry := &Entry{
+		Name:             normalizePath(name),
+		CompressedSize:   e.CompressedSize,
+		AlignedSize:      aligned,
+		UncompressedSize: e.UncompressedSize,
+		Flags:            e.Flags,
+		Offset:           w.offset,
+	}
+	w.offset += aligned
+
+	if i, ok := w.index[entry.Name]; ok {
+		w.entries[i] = entry
+	} else {
+		w.index[entry.Name] = len(w.entries)
+		w.entries = append(w.entries, entry)
+	}
+	return nil
+}
+
+// Count returns the number of files added so far.
+func (w *Writer) Count() int {
+	return len(w.entries)
+}
+
+// Close writes the file table and header and closes the file.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	if err := w.writeTable(); err != nil {
+		w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
+
+func (w *Writer) writeTable() error {
+	var table bytes.Buffer
+	for _, e := range w.entries {
+		// Original archives store Windows-style paths
+		table.WriteString(strings.ReplaceAll(e.Name, "/", "\\"))
+		table.WriteByte(0)
+
+		var fields [17]byte
+		binary.LittleEndian.PutUint32(fields[0:], e.CompressedSize)
+		binary.LittleEndian.PutUint32(fields[4:], e.AlignedSize)
+		binary.LittleEndian.PutUint32(fields[8:], e.UncompressedSize)
+		fields[12] = e.Flags
+		binary.LittleEndian.PutUint32(fields[13:], e.Offset)
+		table.Write(fields[:])
+	}
+
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	zw.Write(table.Bytes())
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("compressing file table: %w", err)
+	}
+
+	sizes := [2]uint32{uint32(compressed.Len()), uint32(table.Len())}
+	if err := binary.Write(w.file, binary.LittleEndian, sizes); err != nil {
+		return fmt.Errorf("writing file table: %w", err)
+	}
+	if _, err := w.file.Write(compressed.Bytes()); err != nil {
+		return fmt.Errorf("writing file table: %w", err)
+	}
+
+	header := Header{
+		TableOffset: w.offset,
+		FileCount:   uint32(len(w.entries)) + fileCountAdd,
+		Version:     grfVersion,
+	}
+	copy(header.Magic[:], grfMagic)
+	if _, err := w.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("writing header: %w", err)
+	}
+	if err := binary.Write(w.file, binary.LittleEndian, &header); err != nil {
+		return fmt.Errorf("writing header: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package math
+
+import "math"
+
+// AABB is an axis-aligned bounding box.
+type AABB struct {
+	Min, Max Vec3
+}
+
+// EmptyAABB returns a box that contains nothing; growing it by a point
+// makes a box around just that point.
+func EmptyAABB() AABB {
+	inf := float32(math.Inf(1))
+	return AABB{
+		Min: Vec3{inf, inf, inf},
+		Max: Vec3{-inf, -inf, -inf},
+	}
+}
+
+// Grow returns the box extended to contain p.
+func (b AABB) Grow(p Vec3) AABB {
+	return AABB{
+		Min: Vec3{min(b.Min.X, p.X), min(b.Min.Y, p.Y), min(b.Min.Z, p.Z)},
+		Max: Vec3{max(b.Max.X, p.X), max(b.Max.Y, p.Y), max(b.Max.Z, p.Z)},
+	}
+}
+
+// Size returns the box's extent along each axis.
+func (b AABB) Size() Vec3 {
+	return b.Max.Sub(b.Min)
+}
+
+// Contains reports whether p lies inside the box or on its surface.
+func (b AABB) Contains(p Vec3) bool {
+	return p.X >= b.Min.X && p.X <= b.Max.X &&
+		p.Y >= b.Min.Y && p.Y <= b.Max.Y &&
+		p.Z >= b.Min.Z && p.Z <= b.Max.Z
+}
+
+// Transform returns the box around the eight corners of b transformed by m.
+func (b AABB) Transform(m Mat4) AABB {
+	out := EmptyAABB()
+	for i := range 8 {
+		corner := b.Min
+		if i&1 != 0 {
+			corner.X = b.Max.X
+		}
+		if i&2 != 0 {
+			corner.Y = b.Max.Y
+		}
+		if i&4 != 0 {
+			corner.Z = b.Max.Z
+		}
+		out = out.Grow(m.TransformVec3(corner))
+	}
+	return out
+}
+
+// SegmentHit returns where the segment from..to first enters the box, as a
+// fraction 0..1 of the way along it. A segment that starts inside the box
+// hits at 0.
+func (b AABB) SegmentHit(from, to Vec3) (t float32, hit bool) {
+	d := to.Sub(from)
+	tMin, tMax := float32(0), float32(1)
+	axes := [3][4]float32{
+		{from.X, d.X, b.Min.X, b.Max.X},
+		{from.Y, d.Y, b.Min.Y, b.Max.Y},
+		{from.Z, d.Z, b.Min.Z, b.Max.Z},
+	}
+	for _, a := range axes {
+		o, dir, lo, hi := a[0], a[1], a[2], a[3]
+		if dir == 0 {
+			if o < lo || o > hi {
+				return 0, false
+			}
+			continue
+		}
+		t1, t2 := (lo-o)/dir, (hi-o)/dir
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		tMin = max(tMin, t1)
+		tMax = min(tMax, t2)
+		if tMin > tMax {
+			return 0, false
+		}
+	}
+	return tMin, true
+}
+
+// Damp moves current toward target with exponential smoothing: rate is how
+// many e-folds of the gap close per second, independent of frame rate.
+func Damp(current, target, rate, dt float32) float32 {
+	return target + (current-target)*float32(math.Exp(float64(-rate*dt)))
+}
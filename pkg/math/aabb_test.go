@@ -0,0 +1,79 @@
+package math
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAABBSegmentHit(t *testing.T) {
+	box := AABB{Min: Vec3{0, 0, 0}, Max: Vec3{10, 10, 10}}
+	tests := []struct {
+		name     string
+		from, to Vec3
+		wantT    float32
+		wantHit  bool
+	}{
+		{"through", Vec3{-10, 5, 5}, Vec3{20, 5, 5}, 1.0 / 3, true},
+		{"reaches", Vec3{-10, 5, 5}, Vec3{0, 5, 5}, 1, true},
+		{"short", Vec3{-10, 5, 5}, Vec3{-1, 5, 5}, 0, false},
+		{"miss", Vec3{-10, 20, 5}, Vec3{20, 20, 5}, 0, false},
+		{"diagonal", Vec3{-10, -10, 5}, Vec3{10, 10, 5}, 0.5, true},
+		{"from inside", Vec3{5, 5, 5}, Vec3{50, 5, 5}, 0, true},
+		{"parallel outside", Vec3{-1, 0, 0}, Vec3{-1, 10, 0}, 0, false},
+		{"away", Vec3{-10, 5, 5}, Vec3{-20, 5, 5}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotT, gotHit := box.SegmentHit(tt.from, tt.to)
+			if gotHit != tt.wantHit || (gotHit && abs(gotT-tt.wantT) > 1e-5) {
+				t.Errorf("SegmentHit() = %v, %v, want %v, %v", gotT, gotHit, tt.wantT, tt.wantHit)
+			}
+		})
+	}
+}
+
+func TestAABBTransform(t *testing.T) {
+	box := AABB{Min: Vec3{-1, 0, -2}, Max: Vec3{1, 4, 2}}
+
+	got := box.Transform(Translate(10, 0, 0).Mul(RotateY(math.Pi / 2)))
+	want := AABB{Min: Vec3{8, 0, -1}, Max: Vec3{12, 4, 1}}
+	for _, p := range [][2]float32{
+		{got.Min.X, want.Min.X}, {got.Min.Y, want.Min.Y}, {got.Min.Z, want.Min.Z},
+		{got.Max.X, want.Max.X}, {got.Max.Y, want.Max.Y}, {got.Max.Z, want.Max.Z},
+	} {
+		if abs(p[0]-p[1]) > 1e-4 {
+			t.Fatalf("Transform() = %v, want %v", got, want)
+		}
+	}
+	if s := got.Size(); abs(s.X-4) > 1e-4 || abs(s.Y-4) > 1e-4 || abs(s.Z-2) > 1e-4 {
+		t.Errorf("Size() = %v, want {4 4 2}", s)
+	}
+	if !got.Contains(Vec3{10, 2, 0}) || got.Contains(Vec3{10, 5, 0}) {
+		t.Errorf("Contains() wrong for %v", got)
+	}
+}
+
+func TestEmptyAABBGrow(t *testing.T) {
+	b := EmptyAABB().Grow(Vec3{1, 2, 3})
+	if b.Min != (Vec3{1, 2, 3}) || b.Max != (Vec3{1, 2, 3}) {
+		t.Errorf("Grow() from empty = %v", b)
+	}
+	b = b.Grow(Vec3{-1, 5, 0})
+	if b.Min != (Vec3{-1, 2, 0}) || b.Max != (Vec3{1, 5, 3}) {
+		t.Errorf("Grow() = %v", b)
+	}
+}
+
+func TestDamp(t *testing.T) {
+	if got := Damp(0, 10, 5, 0); got != 0 {
+		t.Errorf("Damp() with no time = %v, want 0", got)
+	}
+	if got := Damp(0, 10, 5, 100); abs(got-10) > 1e-4 {
+		t.Errorf("Damp() after a long time = %v, want 10", got)
+	}
+	// Two half steps land where one full step does
+	half := Damp(Damp(0, 10, 3, 0.05), 10, 3, 0.05)
+	if full := Damp(0, 10, 3, 0.1); abs(half-full) > 1e-4 {
+		t.Errorf("Damp() depends on frame rate: %v vs %v", half, full)
+	}
+}